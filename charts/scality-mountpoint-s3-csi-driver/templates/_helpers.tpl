@@ -24,6 +24,29 @@ If release name contains chart name it will be used as a full name.
 {{- end -}}
 {{- end -}}
 
+{{/*
+Name of the driver instance, registered with kubelet and referenced by PersistentVolumes and StorageClasses.
+*/}}
+{{- define "scality-mountpoint-s3-csi-driver.driverName" -}}
+{{- default "s3.csi.scality.com" .Values.driverName -}}
+{{- end -}}
+
+{{/*
+Name of an object shared by the instances of the driver in the cluster, e.g. a ClusterRole, suffixed with the driver
+name of instances other than the default one so several instances can be installed side by side.
+Takes a list of the root context and the name of the object for the default instance.
+*/}}
+{{- define "scality-mountpoint-s3-csi-driver.instanceName" -}}
+{{- $root := index . 0 -}}
+{{- $name := index . 1 -}}
+{{- $driverName := include "scality-mountpoint-s3-csi-driver.driverName" $root -}}
+{{- if eq $driverName "s3.csi.scality.com" -}}
+{{- $name -}}
+{{- else -}}
+{{- printf "%s-%s" $name $driverName | trunc 253 | trimSuffix "-" -}}
+{{- end -}}
+{{- end -}}
+
 {{/*
 Create chart name and version as used by the chart label.
 */}}
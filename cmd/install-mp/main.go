@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/util"
 )
 
@@ -18,6 +19,9 @@ const (
 // $ cp $SOURCE_DIR/* $DESTDIR/
 // Written as a go program to avoid bash and cp dependencies in the container.
 // Does not handle nested directories or anything beyond the simple install.
+//
+// If the source directory has one directory per platform (e.g. `linux-arm64`), files of the directory of the node's
+// platform are copied. Files are verified against the checksum manifest of their directory if it has one.
 func main() {
 	binDir := os.Getenv(binDirKey)
 	installDir := os.Getenv(installDirKey)
@@ -25,29 +29,36 @@ func main() {
 		log.Fatalf("Missing environment variable, %s and %s required", binDirKey, installDirKey)
 	}
 
-	err := installFiles(binDir, installDir)
+	err := installFiles(binDir, installDir, mountpoint.Platform())
 	if err != nil {
 		log.Fatalf("failed install binDir %s installDir %s: %v", binDir, installDir, err)
 	}
 }
 
-func installFiles(binDir string, installDir string) error {
-	sd, err := os.Open(binDir)
+func installFiles(binDir string, installDir string, platform string) error {
+	binDir, err := mountpoint.ResolveBinDir(binDir, platform)
 	if err != nil {
-		return fmt.Errorf("failed to open source directory: %w", err)
+		return err
+	}
+
+	verified, err := mountpoint.VerifyChecksums(binDir)
+	if err != nil {
+		return fmt.Errorf("failed to verify files: %w", err)
+	}
+	if !verified {
+		log.Printf("Warning: no %s in %s, files are not verified", mountpoint.ChecksumManifest, binDir)
 	}
-	defer func() {
-		if closeErr := sd.Close(); closeErr != nil {
-			log.Printf("Warning: failed to close source directory: %v", closeErr)
-		}
-	}()
 
-	entries, err := sd.Readdirnames(0)
+	entries, err := os.ReadDir(binDir)
 	if err != nil {
 		return fmt.Errorf("failed to read source directory: %w", err)
 	}
 
-	for _, name := range entries {
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || name == mountpoint.ChecksumManifest {
+			continue
+		}
 		log.Printf("Copying file %s\n", name)
 		destFile := filepath.Join(installDir, name)
 
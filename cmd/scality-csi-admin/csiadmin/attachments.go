@@ -0,0 +1,100 @@
+package csiadmin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	s3v2 "github.com/scality/mountpoint-s3-csi-driver/pkg/client/clientset/versioned/typed/api/v2"
+)
+
+// AttachmentsOptions configures the MountpointS3PodAttachments listed by [Attachments].
+type AttachmentsOptions struct {
+	// Node only lists attachments of this node, if set.
+	Node string
+	// Volume only lists attachments of this PersistentVolume, if set.
+	Volume string
+	// Watch prints changes of the attachments after listing them, until the context is cancelled.
+	Watch bool
+}
+
+// Attachments writes the MountpointS3PodAttachments matching `opts` to `out`, one per line with their node,
+// PersistentVolume, number of Mountpoint Pods and workloads, and whether Mountpoint is ready.
+func Attachments(ctx context.Context, c s3v2.MountpointS3PodAttachmentsGetter, opts AttachmentsOptions, out io.Writer) error {
+	selector := fields.Set{}
+	if opts.Node != "" {
+		selector[crdv2.FieldNodeName] = opts.Node
+	}
+	if opts.Volume != "" {
+		selector[crdv2.FieldPersistentVolumeName] = opts.Volume
+	}
+	listOptions := metav1.ListOptions{FieldSelector: selector.AsSelector().String()}
+
+	list, err := c.MountpointS3PodAttachments().List(ctx, listOptions)
+	if err != nil {
+		return fmt.Errorf("failed to list MountpointS3PodAttachments: %w", err)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tNODE\tVOLUME\tMOUNTPOINT PODS\tWORKLOADS\tREADY")
+	for i := range list.Items {
+		writeAttachment(w, "", &list.Items[i])
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if !opts.Watch {
+		return nil
+	}
+
+	listOptions.ResourceVersion = list.ResourceVersion
+	watcher, err := c.MountpointS3PodAttachments().Watch(ctx, listOptions)
+	if err != nil {
+		return fmt.Errorf("failed to watch MountpointS3PodAttachments: %w", err)
+	}
+	defer watcher.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return errors.New("watch of MountpointS3PodAttachments closed by the API server")
+			}
+			if event.Type == watch.Error {
+				return fmt.Errorf("failed to watch MountpointS3PodAttachments: %v", event.Object)
+			}
+			s3pa, ok := event.Object.(*crdv2.MountpointS3PodAttachment)
+			if !ok {
+				continue
+			}
+			writeAttachment(w, string(event.Type)+" ", s3pa)
+			if err := w.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// writeAttachment writes a line describing `s3pa` to `w`, prefixed with `prefix`.
+func writeAttachment(w io.Writer, prefix string, s3pa *crdv2.MountpointS3PodAttachment) {
+	workloads := 0
+	for _, attachments := range s3pa.Spec.MountpointS3PodAttachments {
+		workloads += len(attachments)
+	}
+	ready := "Unknown"
+	if condition := meta.FindStatusCondition(s3pa.Status.Conditions, crdv2.ConditionMountpointReady); condition != nil {
+		ready = string(condition.Status)
+	}
+	fmt.Fprintf(w, "%s%s\t%s\t%s\t%d\t%d\t%s\n", prefix, s3pa.Name, s3pa.Spec.NodeName, s3pa.Spec.PersistentVolumeName,
+		len(s3pa.Spec.MountpointS3PodAttachments), workloads, ready)
+}
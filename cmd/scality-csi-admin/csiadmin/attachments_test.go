@@ -0,0 +1,88 @@
+package csiadmin_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/scality/mountpoint-s3-csi-driver/cmd/scality-csi-admin/csiadmin"
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/client/clientset/versioned/fake"
+)
+
+func TestAttachments(t *testing.T) {
+	ready := &crdv2.MountpointS3PodAttachment{
+		ObjectMeta: metav1.ObjectMeta{Name: "s3pa-1"},
+		Spec: crdv2.MountpointS3PodAttachmentSpec{
+			NodeName:             "node-1",
+			PersistentVolumeName: "pv-1",
+			MountpointS3PodAttachments: map[string][]crdv2.WorkloadAttachment{
+				"mp-1": {{WorkloadPodUID: "uid-1"}, {WorkloadPodUID: "uid-2"}},
+			},
+		},
+		Status: crdv2.MountpointS3PodAttachmentStatus{Conditions: []metav1.Condition{{
+			Type: crdv2.ConditionMountpointReady, Status: metav1.ConditionTrue,
+		}}},
+	}
+	pending := &crdv2.MountpointS3PodAttachment{
+		ObjectMeta: metav1.ObjectMeta{Name: "s3pa-2"},
+		Spec: crdv2.MountpointS3PodAttachmentSpec{
+			NodeName:             "node-2",
+			PersistentVolumeName: "pv-1",
+			MountpointS3PodAttachments: map[string][]crdv2.WorkloadAttachment{
+				"mp-2": {{WorkloadPodUID: "uid-3"}},
+			},
+		},
+	}
+
+	var out bytes.Buffer
+	client := fake.NewClientset(ready, pending)
+	if err := csiadmin.Attachments(context.Background(), client.S3V2(), csiadmin.AttachmentsOptions{}, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected a header and 2 attachments, got:\n%s", out.String())
+	}
+	for i, want := range [][]string{
+		{"NAME", "NODE", "VOLUME", "MOUNTPOINT", "PODS", "WORKLOADS", "READY"},
+		{"s3pa-1", "node-1", "pv-1", "1", "2", "True"},
+		{"s3pa-2", "node-2", "pv-1", "1", "1", "Unknown"},
+	} {
+		if got := strings.Fields(lines[i]); strings.Join(got, " ") != strings.Join(want, " ") {
+			t.Errorf("Expected line %d to be %v, got %v", i, want, got)
+		}
+	}
+}
+
+func TestAttachmentsWatch(t *testing.T) {
+	client := fake.NewClientset()
+	events := watch.NewFake()
+	client.PrependWatchReactor("mountpoints3podattachments", clienttesting.DefaultWatchReactor(events, nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() {
+		events.Add(&crdv2.MountpointS3PodAttachment{
+			ObjectMeta: metav1.ObjectMeta{Name: "s3pa-1"},
+			Spec:       crdv2.MountpointS3PodAttachmentSpec{NodeName: "node-1", PersistentVolumeName: "pv-1"},
+		})
+		events.Stop()
+	}()
+
+	var out bytes.Buffer
+	err := csiadmin.Attachments(ctx, client.S3V2(), csiadmin.AttachmentsOptions{Watch: true}, &out)
+	if err == nil || !strings.Contains(err.Error(), "closed") {
+		t.Fatalf("Expected an error once the watch is closed, got %v", err)
+	}
+	if !strings.Contains(out.String(), "ADDED s3pa-1") {
+		t.Errorf("Expected the added attachment in the output, got:\n%s", out.String())
+	}
+}
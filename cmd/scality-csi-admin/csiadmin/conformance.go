@@ -0,0 +1,31 @@
+package csiadmin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/conformance"
+)
+
+// Formats of the conformance report.
+const (
+	FormatJSON     = "json"
+	FormatMarkdown = "markdown"
+)
+
+// Conformance writes the report of the CSI capabilities, volume attributes and mount options supported by
+// this build of the driver to `out`, in `format`.
+func Conformance(format string, out io.Writer) error {
+	report := conformance.Build()
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case FormatMarkdown:
+		return report.WriteMarkdown(out)
+	default:
+		return fmt.Errorf("unknown format %q, must be %s or %s", format, FormatJSON, FormatMarkdown)
+	}
+}
@@ -0,0 +1,28 @@
+package csiadmin_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/scality/mountpoint-s3-csi-driver/cmd/scality-csi-admin/csiadmin"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/conformance"
+)
+
+func TestConformance(t *testing.T) {
+	out := &bytes.Buffer{}
+	if err := csiadmin.Conformance(csiadmin.FormatJSON, out); err != nil {
+		t.Fatalf("Failed to write the conformance report: %v", err)
+	}
+	var report conformance.Report
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("Failed to parse the conformance report: %v", err)
+	}
+	if len(report.MountOptions) == 0 || len(report.VolumeAttributes) == 0 {
+		t.Errorf("Expected mount options and volume attributes in the report, got %+v", report)
+	}
+
+	if err := csiadmin.Conformance("yaml", out); err == nil {
+		t.Errorf("Expected an error for an unknown format")
+	}
+}
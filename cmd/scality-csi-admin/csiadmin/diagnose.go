@@ -0,0 +1,221 @@
+// Package csiadmin implements administrative commands of `scality-csi-admin`.
+package csiadmin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/consistency"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+)
+
+// LabelDiagnosticMount is the label of Pods created for diagnostic mounts.
+const LabelDiagnosticMount = constants.DriverName + "/diagnostic-mount"
+
+const (
+	diagnosticVolumeName = "diagnostic"
+	diagnosticMountPath  = "/mnt/diagnostic"
+	// diagnosticMaxEntries is the number of entries listed to check a diagnostic mount.
+	diagnosticMaxEntries = 20
+	// diagnosticPollInterval is how often the diagnostic Pod is checked for completion.
+	diagnosticPollInterval = 2 * time.Second
+)
+
+// ErrDiagnosticMountFailed is returned when a bucket could not be mounted or listed in a diagnostic mount.
+var ErrDiagnosticMountFailed = errors.New("diagnostic mount failed")
+
+// DiagnoseMountOptions configures a diagnostic mount.
+type DiagnoseMountOptions struct {
+	// Namespace to create the diagnostic Pod in, it must be the diagnostic mount namespace configured in the driver.
+	Namespace string
+	// Node to mount the bucket on.
+	Node string
+	// Bucket to mount, with an optional Prefix.
+	Bucket string
+	Prefix string
+	// Secret is the name of a Secret in Namespace with credentials to mount the bucket with.
+	// Driver-level credentials are used if empty.
+	Secret string
+	// TTL bounds the lifetime of the diagnostic Pod.
+	TTL time.Duration
+	// Image of the diagnostic Pod, it must contain `scality-csi-checker`.
+	Image string
+	// Keep the diagnostic Pod after the check, to inspect it or exec into it until its TTL expires.
+	Keep bool
+	// DriverName is the name of the driver instance mounting the bucket, [constants.DriverName] if empty.
+	DriverName string
+}
+
+// DiagnoseMount checks whether a node can mount a bucket by creating a short-lived Pod on it with a diagnostic mount
+// of the bucket, which lists the mounted bucket. It writes a report to `out`, and returns [ErrDiagnosticMountFailed]
+// if the bucket could not be mounted or listed.
+func DiagnoseMount(ctx context.Context, c client.Client, opts DiagnoseMountOptions, out io.Writer) error {
+	pod := diagnosticPod(opts)
+	if err := c.Create(ctx, pod); err != nil {
+		return fmt.Errorf("failed to create diagnostic Pod: %w", err)
+	}
+	fmt.Fprintf(out, "Created diagnostic Pod %s/%s on node %s\n", pod.Namespace, pod.Name, opts.Node)
+
+	if !opts.Keep {
+		defer func() {
+			// Use a new context to delete the Pod even if `ctx` is cancelled
+			if err := c.Delete(context.WithoutCancel(ctx), pod); err != nil && !apierrors.IsNotFound(err) {
+				fmt.Fprintf(out, "Failed to delete diagnostic Pod %s/%s: %v\n", pod.Namespace, pod.Name, err)
+			}
+		}()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.TTL)
+	defer cancel()
+
+	start := time.Now()
+	terminated, err := waitForDiagnosticPod(ctx, c, pod)
+	if err != nil {
+		fmt.Fprintf(out, "Diagnostic Pod did not complete: %v\n", err)
+		reportPodEvents(context.WithoutCancel(ctx), c, pod, out)
+		return ErrDiagnosticMountFailed
+	}
+
+	listing, err := consistency.Decode(terminated.Message)
+	if err != nil {
+		fmt.Fprintf(out, "Diagnostic Pod exited with code %d without a listing: %s\n", terminated.ExitCode, terminated.Message)
+		return ErrDiagnosticMountFailed
+	}
+	if listing.Error != "" {
+		fmt.Fprintf(out, "Mounted bucket %q but failed to list it: %s\n", opts.Bucket, listing.Error)
+		return ErrDiagnosticMountFailed
+	}
+
+	fmt.Fprintf(out, "Mounted and listed bucket %q on node %s in %v\n", opts.Bucket, opts.Node, time.Since(start).Round(time.Second))
+	for _, entry := range listing.Entries {
+		fmt.Fprintf(out, "  %s\n", entry)
+	}
+	if listing.Truncated {
+		fmt.Fprintf(out, "  ... (more entries not shown)\n")
+	}
+	return nil
+}
+
+// driverNameOrDefault returns `driverName`, or the default [constants.DriverName] if empty.
+func driverNameOrDefault(driverName string) string {
+	if driverName == "" {
+		return constants.DriverName
+	}
+	return driverName
+}
+
+// diagnosticPod returns the Pod to create for a diagnostic mount.
+func diagnosticPod(opts DiagnoseMountOptions) *corev1.Pod {
+	attributes := map[string]string{
+		volumecontext.BucketName:           opts.Bucket,
+		volumecontext.Diagnostic:           "true",
+		volumecontext.AuthenticationSource: credentialprovider.AuthenticationSourceDriver,
+	}
+	if opts.Prefix != "" {
+		attributes[volumecontext.Prefix] = opts.Prefix
+	}
+
+	csi := &corev1.CSIVolumeSource{
+		Driver:           driverNameOrDefault(opts.DriverName),
+		ReadOnly:         ptr.To(true),
+		VolumeAttributes: attributes,
+	}
+	if opts.Secret != "" {
+		attributes[volumecontext.AuthenticationSource] = credentialprovider.AuthenticationSourceSecret
+		csi.NodePublishSecretRef = &corev1.LocalObjectReference{Name: opts.Secret}
+	}
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "s3-csi-diagnostic-",
+			Namespace:    opts.Namespace,
+			Labels:       map[string]string{LabelDiagnosticMount: "true"},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:              opts.Node,
+			RestartPolicy:         corev1.RestartPolicyNever,
+			ActiveDeadlineSeconds: ptr.To(int64(opts.TTL.Seconds())),
+			// Diagnose nodes regardless of their taints
+			Tolerations: []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+			Containers: []corev1.Container{{
+				Name:    "diagnostic",
+				Image:   opts.Image,
+				Command: []string{"/bin/scality-csi-checker"},
+				Args: []string{
+					"--path=" + diagnosticMountPath,
+					"--max-entries=" + strconv.Itoa(diagnosticMaxEntries),
+				},
+				// Mountpoint only allows root to access mounts without `fsGroup`
+				SecurityContext: &corev1.SecurityContext{
+					RunAsUser:                ptr.To(int64(0)),
+					AllowPrivilegeEscalation: ptr.To(false),
+					ReadOnlyRootFilesystem:   ptr.To(true),
+					Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+				},
+				VolumeMounts: []corev1.VolumeMount{{
+					Name:      diagnosticVolumeName,
+					MountPath: diagnosticMountPath,
+					ReadOnly:  true,
+				}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name:         diagnosticVolumeName,
+				VolumeSource: corev1.VolumeSource{CSI: csi},
+			}},
+		},
+	}
+}
+
+// waitForDiagnosticPod waits until the container of the diagnostic `pod` terminates.
+func waitForDiagnosticPod(ctx context.Context, c client.Client, pod *corev1.Pod) (*corev1.ContainerStateTerminated, error) {
+	ticker := time.NewTicker(diagnosticPollInterval)
+	defer ticker.Stop()
+
+	for {
+		current := &corev1.Pod{}
+		if err := c.Get(ctx, client.ObjectKeyFromObject(pod), current); err != nil {
+			return nil, fmt.Errorf("failed to get diagnostic Pod: %w", err)
+		}
+		for _, status := range current.Status.ContainerStatuses {
+			if status.State.Terminated != nil {
+				return status.State.Terminated, nil
+			}
+		}
+		if current.Status.Phase == corev1.PodFailed {
+			return nil, fmt.Errorf("diagnostic Pod failed: %s", current.Status.Message)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timed out waiting for diagnostic Pod: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// reportPodEvents writes events of `pod` to `out`, such as mount failures reported by kubelet.
+func reportPodEvents(ctx context.Context, c client.Client, pod *corev1.Pod, out io.Writer) {
+	events := &corev1.EventList{}
+	if err := c.List(ctx, events, client.InNamespace(pod.Namespace)); err != nil {
+		fmt.Fprintf(out, "Failed to list events of diagnostic Pod: %v\n", err)
+		return
+	}
+	for _, event := range events.Items {
+		if event.InvolvedObject.Kind != "Pod" || event.InvolvedObject.Name != pod.Name {
+			continue
+		}
+		fmt.Fprintf(out, "  %s %s: %s\n", event.Type, event.Reason, event.Message)
+	}
+}
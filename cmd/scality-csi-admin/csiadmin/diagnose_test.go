@@ -0,0 +1,128 @@
+package csiadmin_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/scality/mountpoint-s3-csi-driver/cmd/scality-csi-admin/csiadmin"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/consistency"
+)
+
+func TestDiagnoseMount(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       csiadmin.DiagnoseMountOptions
+		status     corev1.PodStatus
+		wantErr    error
+		wantOutput string
+		wantPods   int
+	}{
+		{
+			name:       "bucket is mounted and listed",
+			opts:       csiadmin.DiagnoseMountOptions{Secret: "creds"},
+			status:     terminatedStatus(consistency.Encode(consistency.Listing{Entries: []string{"a.txt", "dir"}})),
+			wantOutput: "Mounted and listed bucket \"bucket\" on node node-1",
+		},
+		{
+			name:       "bucket cannot be listed",
+			status:     terminatedStatus(consistency.Encode(consistency.Listing{Error: "permission denied"})),
+			wantErr:    csiadmin.ErrDiagnosticMountFailed,
+			wantOutput: "failed to list it: permission denied",
+		},
+		{
+			name:       "diagnostic Pod fails",
+			status:     corev1.PodStatus{Phase: corev1.PodFailed, Message: "Pod was active on the node longer than the specified deadline"},
+			wantErr:    csiadmin.ErrDiagnosticMountFailed,
+			wantOutput: "longer than the specified deadline",
+		},
+		{
+			name:       "diagnostic Pod is kept",
+			opts:       csiadmin.DiagnoseMountOptions{Keep: true},
+			status:     terminatedStatus(consistency.Encode(consistency.Listing{})),
+			wantOutput: "Mounted and listed",
+			wantPods:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+
+			var created *corev1.Pod
+			c := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithInterceptorFuncs(interceptor.Funcs{
+					Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+						pod := obj.(*corev1.Pod)
+						pod.Name = pod.GenerateName + "test"
+						created = pod.DeepCopy()
+						return c.Create(ctx, obj, opts...)
+					},
+					Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+						if err := c.Get(ctx, key, obj, opts...); err != nil {
+							return err
+						}
+						obj.(*corev1.Pod).Status = tt.status
+						return nil
+					},
+				}).
+				Build()
+
+			opts := tt.opts
+			opts.Namespace = "kube-system"
+			opts.Node = "node-1"
+			opts.Bucket = "bucket"
+			opts.TTL = time.Minute
+			opts.Image = "driver-image"
+
+			out := &bytes.Buffer{}
+			err := csiadmin.DiagnoseMount(context.Background(), c, opts, out)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if !strings.Contains(out.String(), tt.wantOutput) {
+				t.Fatalf("Expected output to contain %q, got:\n%s", tt.wantOutput, out.String())
+			}
+
+			// The diagnostic Pod mounts the bucket read-only on the requested node
+			if created.Spec.NodeName != "node-1" || *created.Spec.ActiveDeadlineSeconds != 60 {
+				t.Errorf("Unexpected diagnostic Pod spec: %+v", created.Spec)
+			}
+			csi := created.Spec.Volumes[0].CSI
+			if csi.VolumeAttributes["diagnostic"] != "true" || csi.VolumeAttributes["bucketName"] != "bucket" || !*csi.ReadOnly {
+				t.Errorf("Unexpected diagnostic volume: %+v", csi)
+			}
+			if opts.Secret != "" && (csi.NodePublishSecretRef == nil || csi.VolumeAttributes["authenticationSource"] != "secret") {
+				t.Errorf("Expected diagnostic volume to use secret %q: %+v", opts.Secret, csi)
+			}
+
+			pods := &corev1.PodList{}
+			if err := c.List(context.Background(), pods); err != nil {
+				t.Fatalf("Failed to list Pods: %v", err)
+			}
+			if len(pods.Items) != tt.wantPods {
+				t.Errorf("Expected %d Pods after the check, got %d", tt.wantPods, len(pods.Items))
+			}
+		})
+	}
+}
+
+func terminatedStatus(message []byte) corev1.PodStatus {
+	return corev1.PodStatus{
+		Phase: corev1.PodSucceeded,
+		ContainerStatuses: []corev1.ContainerStatus{{
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: string(message)}},
+		}},
+	}
+}
@@ -0,0 +1,252 @@
+package csiadmin
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+)
+
+const (
+	// tailLogsResolveInterval is how often Mountpoint Pods of the volume are resolved again while following logs,
+	// to follow Mountpoint Pods replacing deleted ones.
+	tailLogsResolveInterval = 5 * time.Second
+	// tailLogsRetryInterval is how long to wait before streaming logs of a Mountpoint Pod again once its stream
+	// ended, e.g. because its Mountpoint container restarted.
+	tailLogsRetryInterval = 2 * time.Second
+)
+
+// ErrNoMountpointPods is returned when no Mountpoint Pod serves the volume whose logs are requested.
+var ErrNoMountpointPods = errors.New("no Mountpoint Pods serve the volume")
+
+// TailLogsOptions configures the logs streamed by [TailLogs].
+type TailLogsOptions struct {
+	// Volume is the name of the PersistentVolume whose Mountpoint Pods logs are streamed.
+	Volume string
+	// Node only streams logs of the Mountpoint Pod on this node, if set.
+	Node string
+	// MountpointNamespace is the namespace of Mountpoint Pods.
+	MountpointNamespace string
+	// Follow streams new logs until the context is cancelled, following restarts and replacements of Mountpoint Pods.
+	Follow bool
+	// Since only streams logs newer than this duration, if set.
+	Since time.Duration
+	// TailLines is the number of last lines of logs streamed initially, all lines if zero.
+	TailLines int64
+}
+
+// TailLogs writes logs of the Mountpoint Pods serving `opts.Volume` to `out`, each line prefixed with the node and
+// the name of its Mountpoint Pod. Mountpoint Pods are found through the MountpointS3PodAttachments of the volume.
+func TailLogs(ctx context.Context, c client.Client, pods typedcorev1.PodsGetter, opts TailLogsOptions, out io.Writer) error {
+	mpPods, logDirectory, err := resolveMountpointPods(ctx, c, opts)
+	if err != nil {
+		return err
+	}
+	if len(mpPods) == 0 {
+		return fmt.Errorf("%w %s", ErrNoMountpointPods, opts.Volume)
+	}
+	if logDirectory != "" {
+		fmt.Fprintf(out, "Mountpoint logs of volume %s are written to files in %s inside Mountpoint containers, only their container logs are shown\n",
+			opts.Volume, logDirectory)
+	}
+
+	w := &lineWriter{out: out}
+	streamer := &logStreamer{pods: pods, opts: opts, out: w}
+	if !opts.Follow {
+		for _, name := range slices.Sorted(maps.Keys(mpPods)) {
+			if _, err := streamer.stream(ctx, name, mpPods[name], nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	// Mountpoint Pods whose logs are followed, a Mountpoint Pod recreated with the same name is followed again
+	var mu sync.Mutex
+	followed := make(map[string]bool)
+	ticker := time.NewTicker(tailLogsResolveInterval)
+	defer ticker.Stop()
+	for {
+		mu.Lock()
+		for name, node := range mpPods {
+			if followed[name] {
+				continue
+			}
+			followed[name] = true
+			wg.Go(func() {
+				streamer.follow(ctx, name, node)
+				mu.Lock()
+				delete(followed, name)
+				mu.Unlock()
+			})
+		}
+		mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		if mpPods, _, err = resolveMountpointPods(ctx, c, opts); err != nil && ctx.Err() == nil {
+			w.printf("Failed to resolve Mountpoint Pods of volume %s: %v\n", opts.Volume, err)
+		}
+	}
+}
+
+// resolveMountpointPods returns the names of the Mountpoint Pods serving `opts.Volume` with their node, and the log
+// directory Mountpoint is configured with, if any.
+func resolveMountpointPods(ctx context.Context, c client.Client, opts TailLogsOptions) (map[string]string, string, error) {
+	s3paList := &crdv2.MountpointS3PodAttachmentList{}
+	if err := c.List(ctx, s3paList, client.MatchingFields{crdv2.FieldPersistentVolumeName: opts.Volume}); err != nil {
+		return nil, "", fmt.Errorf("failed to list MountpointS3PodAttachments of volume %s: %w", opts.Volume, err)
+	}
+
+	mpPods := make(map[string]string)
+	logDirectory := ""
+	for _, s3pa := range s3paList.Items {
+		if opts.Node != "" && s3pa.Spec.NodeName != opts.Node {
+			continue
+		}
+		for name := range s3pa.Spec.MountpointS3PodAttachments {
+			mpPods[name] = s3pa.Spec.NodeName
+		}
+		args := mountpoint.ParseMountOptions(s3pa.Spec.MountOptions)
+		if dir, ok := args.Value(mountpoint.ArgLogDirectory); ok {
+			logDirectory = dir
+		}
+	}
+	return mpPods, logDirectory, nil
+}
+
+// A logStreamer streams logs of Mountpoint containers.
+type logStreamer struct {
+	pods typedcorev1.PodsGetter
+	opts TailLogsOptions
+	out  *lineWriter
+}
+
+// follow streams logs of Mountpoint Pod `name` until `ctx` is cancelled or the Mountpoint Pod is deleted. Streams
+// are resumed after the last streamed line when they end, e.g. because the Mountpoint container restarted.
+func (s *logStreamer) follow(ctx context.Context, name, node string) {
+	var since *time.Time
+	restarts := int32(-1)
+	for {
+		pod, err := s.pods.Pods(s.opts.MountpointNamespace).Get(ctx, name, metav1.GetOptions{})
+		switch {
+		case ctx.Err() != nil:
+			return
+		case apierrors.IsNotFound(err):
+			s.out.printf("[%s/%s] Mountpoint Pod deleted\n", node, name)
+			return
+		case err != nil:
+			s.out.printf("[%s/%s] Failed to get Mountpoint Pod: %v\n", node, name, err)
+		default:
+			if count := mountpointRestartCount(pod); restarts >= 0 && count > restarts {
+				s.out.printf("[%s/%s] Mountpoint container restarted (%d restarts)\n", node, name, count)
+				restarts = count
+			} else if restarts < 0 {
+				restarts = count
+			}
+			last, err := s.stream(ctx, name, node, since)
+			if err != nil && ctx.Err() == nil {
+				s.out.printf("[%s/%s] Log stream interrupted: %v\n", node, name, err)
+			}
+			if last != nil {
+				since = last
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(tailLogsRetryInterval):
+		}
+	}
+}
+
+// stream writes logs of the Mountpoint container of Mountpoint Pod `name` newer than `since` to the output, and
+// returns the timestamp of the last written line.
+func (s *logStreamer) stream(ctx context.Context, name, node string, since *time.Time) (*time.Time, error) {
+	logOptions := &corev1.PodLogOptions{
+		Container:  mppod.ContainerName,
+		Follow:     s.opts.Follow,
+		Timestamps: true,
+	}
+	switch {
+	case since != nil:
+		logOptions.SinceTime = &metav1.Time{Time: *since}
+	default:
+		if s.opts.Since > 0 {
+			logOptions.SinceSeconds = ptr.To(int64(s.opts.Since.Seconds()))
+		}
+		if s.opts.TailLines > 0 {
+			logOptions.TailLines = ptr.To(s.opts.TailLines)
+		}
+	}
+
+	logs, err := s.pods.Pods(s.opts.MountpointNamespace).GetLogs(name, logOptions).Stream(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stream logs of Mountpoint Pod %s: %w", name, err)
+	}
+	defer logs.Close()
+
+	var last *time.Time
+	scanner := bufio.NewScanner(logs)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		// Lines are prefixed with their RFC3339 timestamp, to resume streams without repeating lines
+		if timestamp, rest, ok := strings.Cut(line, " "); ok {
+			if at, err := time.Parse(time.RFC3339Nano, timestamp); err == nil {
+				if since != nil && !at.After(*since) {
+					continue
+				}
+				last, line = &at, rest
+			}
+		}
+		s.out.printf("[%s/%s] %s\n", node, name, line)
+	}
+	return last, scanner.Err()
+}
+
+// mountpointRestartCount returns the number of restarts of the Mountpoint container of `pod`.
+func mountpointRestartCount(pod *corev1.Pod) int32 {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == mppod.ContainerName {
+			return status.RestartCount
+		}
+	}
+	return 0
+}
+
+// A lineWriter writes lines of concurrent log streams to `out` without interleaving them.
+type lineWriter struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func (w *lineWriter) printf(format string, args ...any) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	fmt.Fprintf(w.out, format, args...)
+}
@@ -0,0 +1,91 @@
+package csiadmin_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/scality/mountpoint-s3-csi-driver/cmd/scality-csi-admin/csiadmin"
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+)
+
+func TestTailLogs(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       csiadmin.TailLogsOptions
+		wantErr    error
+		wantOutput []string
+	}{
+		{
+			name:       "logs of all Mountpoint Pods of the volume",
+			opts:       csiadmin.TailLogsOptions{Volume: "pv-1"},
+			wantOutput: []string{"[node-1/mp-1] fake logs", "[node-2/mp-2] fake logs", "written to files in /var/log/mountpoint"},
+		},
+		{
+			name:       "logs of the Mountpoint Pod on a node",
+			opts:       csiadmin.TailLogsOptions{Volume: "pv-1", Node: "node-2"},
+			wantOutput: []string{"[node-2/mp-2] fake logs"},
+		},
+		{
+			name:    "volume without Mountpoint Pods",
+			opts:    csiadmin.TailLogsOptions{Volume: "pv-2"},
+			wantErr: csiadmin.ErrNoMountpointPods,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = crdv2.AddToScheme(scheme)
+			c := fake.NewClientBuilder().WithScheme(scheme).
+				WithObjects(testS3PA("s3pa-1", "node-1", "mp-1", "log-directory=/var/log/mountpoint"), testS3PA("s3pa-2", "node-2", "mp-2", "")).
+				WithIndex(&crdv2.MountpointS3PodAttachment{}, crdv2.FieldPersistentVolumeName, func(obj client.Object) []string {
+					return []string{obj.(*crdv2.MountpointS3PodAttachment).Spec.PersistentVolumeName}
+				}).
+				Build()
+			clientset := kubefake.NewClientset(testMountpointPod("mp-1"), testMountpointPod("mp-2"))
+
+			tt.opts.MountpointNamespace = "mount-s3"
+			out := &bytes.Buffer{}
+			err := csiadmin.TailLogs(context.Background(), c, clientset.CoreV1(), tt.opts, out)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			for _, want := range tt.wantOutput {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("Expected output to contain %q, got %q", want, out.String())
+				}
+			}
+			if tt.opts.Node != "" && strings.Contains(out.String(), "mp-1") {
+				t.Errorf("Expected only logs of node %s, got %q", tt.opts.Node, out.String())
+			}
+		})
+	}
+}
+
+func testS3PA(name, node, mpPodName, mountOptions string) *crdv2.MountpointS3PodAttachment {
+	return &crdv2.MountpointS3PodAttachment{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: crdv2.MountpointS3PodAttachmentSpec{
+			NodeName:             node,
+			PersistentVolumeName: "pv-1",
+			MountOptions:         mountOptions,
+			MountpointS3PodAttachments: map[string][]crdv2.WorkloadAttachment{
+				mpPodName: {{WorkloadPodUID: "workload-uid"}},
+			},
+		},
+	}
+}
+
+func testMountpointPod(name string) *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "mount-s3"}}
+}
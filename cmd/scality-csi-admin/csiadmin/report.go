@@ -0,0 +1,412 @@
+package csiadmin
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+)
+
+const (
+	// nodePluginLabel and nodePluginContainer select the node plugin Pods of the driver and their container.
+	nodePluginLabel     = "app=s3-csi-node"
+	nodePluginContainer = "s3-plugin"
+	// nodePluginLogLines is the number of last lines of node plugin logs searched for lines about a volume.
+	nodePluginLogLines = 5000
+	// reportEvents is the maximum number of events reported per object.
+	reportEvents = 10
+)
+
+// ReportOptions configures the diagnostic report of [Report].
+type ReportOptions struct {
+	// Namespace of the workload Pod or PersistentVolumeClaim to report on.
+	Namespace string
+	// Pod is the name of the workload Pod to report on, exclusive with PVC.
+	Pod string
+	// PVC is the name of the PersistentVolumeClaim to report on, with the workload Pods using it.
+	PVC string
+	// MountpointNamespace is the namespace of Mountpoint Pods.
+	MountpointNamespace string
+	// DriverNamespace is the namespace of the driver's node plugin Pods.
+	DriverNamespace string
+	// DriverName is the name of the driver instance the volumes belong to, [constants.DriverName] if empty.
+	DriverName string
+	// LogLines is the number of last lines of logs reported per container.
+	LogLines int64
+}
+
+// Report writes a diagnostic report of the mounts of a workload Pod, or of the workload Pods using a
+// PersistentVolumeClaim, to `out`. For each volume of the driver it reports the effective mount options, the
+// MountpointS3PodAttachments, the state, `mount.err` and logs of Mountpoint Pods, and the lines of the node plugin
+// logs about the volume. Failures to collect a part of the report are reported in place.
+func Report(ctx context.Context, c client.Client, pods typedcorev1.PodsGetter, opts ReportOptions, out io.Writer) error {
+	workloads, claims, err := resolveReportTargets(ctx, c, opts)
+	if err != nil {
+		return err
+	}
+
+	r := &reporter{c: c, pods: pods, opts: opts, out: out}
+	r.section("Workload Pods")
+	if len(workloads) == 0 {
+		r.line(1, "No workload Pod uses the volume")
+	}
+	for _, pod := range workloads {
+		r.reportWorkloadPod(ctx, pod)
+	}
+
+	for _, claimName := range claims {
+		r.reportClaim(ctx, claimName, workloads)
+	}
+	return nil
+}
+
+// resolveReportTargets returns the workload Pods and the names of the PersistentVolumeClaims to report on.
+func resolveReportTargets(ctx context.Context, c client.Client, opts ReportOptions) ([]*corev1.Pod, []string, error) {
+	if opts.Pod != "" {
+		pod := &corev1.Pod{}
+		if err := c.Get(ctx, types.NamespacedName{Namespace: opts.Namespace, Name: opts.Pod}, pod); err != nil {
+			return nil, nil, fmt.Errorf("failed to get Pod %s/%s: %w", opts.Namespace, opts.Pod, err)
+		}
+		var claims []string
+		for _, vol := range pod.Spec.Volumes {
+			if vol.PersistentVolumeClaim != nil && !slices.Contains(claims, vol.PersistentVolumeClaim.ClaimName) {
+				claims = append(claims, vol.PersistentVolumeClaim.ClaimName)
+			}
+		}
+		return []*corev1.Pod{pod}, claims, nil
+	}
+
+	podList := &corev1.PodList{}
+	if err := c.List(ctx, podList, client.InNamespace(opts.Namespace)); err != nil {
+		return nil, nil, fmt.Errorf("failed to list Pods in namespace %s: %w", opts.Namespace, err)
+	}
+	var workloads []*corev1.Pod
+	for i := range podList.Items {
+		if slices.ContainsFunc(podList.Items[i].Spec.Volumes, func(vol corev1.Volume) bool {
+			return vol.PersistentVolumeClaim != nil && vol.PersistentVolumeClaim.ClaimName == opts.PVC
+		}) {
+			workloads = append(workloads, &podList.Items[i])
+		}
+	}
+	return workloads, []string{opts.PVC}, nil
+}
+
+// A reporter writes the sections of a diagnostic report.
+type reporter struct {
+	c    client.Client
+	pods typedcorev1.PodsGetter
+	opts ReportOptions
+	out  io.Writer
+}
+
+func (r *reporter) section(title string) {
+	fmt.Fprintf(r.out, "\n== %s ==\n", title)
+}
+
+func (r *reporter) line(indent int, format string, args ...any) {
+	fmt.Fprintf(r.out, "%s%s\n", strings.Repeat("  ", indent), fmt.Sprintf(format, args...))
+}
+
+func (r *reporter) reportWorkloadPod(ctx context.Context, pod *corev1.Pod) {
+	r.line(1, "Pod %s/%s (UID %s) on node %q: %s", pod.Namespace, pod.Name, pod.UID, pod.Spec.NodeName, pod.Status.Phase)
+	for _, condition := range pod.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			r.line(2, "%s=%s %s: %s", condition.Type, condition.Status, condition.Reason, condition.Message)
+		}
+	}
+	r.reportEvents(ctx, pod.Namespace, "Pod", pod.Name)
+
+	for _, vol := range pod.Spec.Volumes {
+		if vol.CSI != nil && vol.CSI.Driver == driverNameOrDefault(r.opts.DriverName) {
+			r.line(2, "Inline volume %s: attributes %v", vol.Name, vol.CSI.VolumeAttributes)
+		}
+	}
+}
+
+func (r *reporter) reportClaim(ctx context.Context, claimName string, workloads []*corev1.Pod) {
+	r.section("PersistentVolumeClaim " + r.opts.Namespace + "/" + claimName)
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := r.c.Get(ctx, types.NamespacedName{Namespace: r.opts.Namespace, Name: claimName}, pvc); err != nil {
+		r.line(1, "Failed to get PersistentVolumeClaim: %v", err)
+		return
+	}
+	r.line(1, "Phase %s, access modes %v, volume %q", pvc.Status.Phase, pvc.Spec.AccessModes, pvc.Spec.VolumeName)
+	for key, value := range pvc.Annotations {
+		if strings.HasPrefix(key, constants.DriverName+"/") {
+			r.line(1, "Annotation %s=%s", key, value)
+		}
+	}
+	r.reportEvents(ctx, pvc.Namespace, "PersistentVolumeClaim", pvc.Name)
+	if pvc.Spec.VolumeName == "" {
+		return
+	}
+
+	pv := &corev1.PersistentVolume{}
+	if err := r.c.Get(ctx, types.NamespacedName{Name: pvc.Spec.VolumeName}, pv); err != nil {
+		r.line(1, "Failed to get PersistentVolume: %v", err)
+		return
+	}
+	if driverName := driverNameOrDefault(r.opts.DriverName); pv.Spec.CSI == nil || pv.Spec.CSI.Driver != driverName {
+		r.line(1, "PersistentVolume %s is not a volume of %s", pv.Name, driverName)
+		return
+	}
+
+	r.section("PersistentVolume " + pv.Name)
+	r.line(1, "Volume handle %q, phase %s", pv.Spec.CSI.VolumeHandle, pv.Status.Phase)
+	r.line(1, "Volume attributes: %v", pv.Spec.CSI.VolumeAttributes)
+	r.line(1, "Mount options: %v", pv.Spec.MountOptions)
+	r.reportEvents(ctx, "", "PersistentVolume", pv.Name)
+
+	s3paList := &crdv2.MountpointS3PodAttachmentList{}
+	if err := r.c.List(ctx, s3paList, client.MatchingFields{crdv2.FieldPersistentVolumeName: pv.Name}); err != nil {
+		r.line(1, "Failed to list MountpointS3PodAttachments: %v", err)
+		return
+	}
+
+	workloadUIDs := make(map[string]bool)
+	for _, pod := range workloads {
+		workloadUIDs[string(pod.UID)] = true
+	}
+	reported := 0
+	for i := range s3paList.Items {
+		s3pa := &s3paList.Items[i]
+		if len(workloadUIDs) > 0 && !attachesAny(s3pa, workloadUIDs) {
+			continue
+		}
+		reported++
+		r.reportS3PodAttachment(ctx, s3pa, pv, workloadUIDs)
+	}
+	if reported == 0 {
+		r.line(1, "No MountpointS3PodAttachment attaches the workload Pods, they are not scheduled or the controller did not handle them yet")
+	}
+}
+
+func (r *reporter) reportS3PodAttachment(ctx context.Context, s3pa *crdv2.MountpointS3PodAttachment, pv *corev1.PersistentVolume, workloadUIDs map[string]bool) {
+	r.section("MountpointS3PodAttachment " + s3pa.Name)
+	r.line(1, "Node %q, fsGroup %q", s3pa.Spec.NodeName, s3pa.Spec.WorkloadFSGroup)
+	if s3pa.Spec.CredentialIdentity != "" {
+		r.line(1, "Credential identity: %q", s3pa.Spec.CredentialIdentity)
+	}
+	r.line(1, "Effective mount options: %q", s3pa.Spec.MountOptions)
+
+	nodePlugin, err := r.nodePluginPod(ctx, s3pa.Spec.NodeName)
+	if err != nil {
+		r.line(1, "Failed to find the node plugin Pod: %v", err)
+	}
+	var allowedEndpointURLs []string
+	if urls := containerEnv(nodePlugin, nodePluginContainer, "ALLOWED_ENDPOINT_URLS"); urls != "" {
+		allowedEndpointURLs = strings.Split(urls, ",")
+	}
+	warnings, err := mountpoint.ValidateMountOptions(strings.Split(s3pa.Spec.MountOptions, ","), allowedEndpointURLs)
+	for _, warning := range warnings {
+		r.line(2, "Warning: %s", warning)
+	}
+	if err != nil {
+		r.line(2, "Invalid: %v", err)
+	}
+
+	for _, condition := range s3pa.Status.Conditions {
+		r.line(1, "Condition %s=%s %s: %s", condition.Type, condition.Status, condition.Reason, condition.Message)
+	}
+
+	for _, mpPodName := range slices.Sorted(maps.Keys(s3pa.Spec.MountpointS3PodAttachments)) {
+		var workloads []string
+		for _, attachment := range s3pa.Spec.MountpointS3PodAttachments[mpPodName] {
+			workloads = append(workloads, attachment.WorkloadPodUID)
+		}
+		r.reportMountpointPod(ctx, mpPodName, workloads)
+	}
+
+	if nodePlugin != nil {
+		terms := []string{pv.Name, pv.Spec.CSI.VolumeHandle}
+		for uid := range workloadUIDs {
+			terms = append(terms, uid)
+		}
+		r.reportNodePluginLogs(ctx, nodePlugin, terms)
+	}
+}
+
+func (r *reporter) reportMountpointPod(ctx context.Context, name string, workloads []string) {
+	r.section("Mountpoint Pod " + r.opts.MountpointNamespace + "/" + name)
+	r.line(1, "Workload Pod UIDs: %v", workloads)
+
+	mpPod, err := r.pods.Pods(r.opts.MountpointNamespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		r.line(1, "Failed to get Mountpoint Pod: %v", err)
+		return
+	}
+	r.line(1, "Phase %s, node %q, Mountpoint %s", mpPod.Status.Phase, mpPod.Spec.NodeName, mpPod.Labels[mppod.LabelMountpointVersion])
+	for _, condition := range mpPod.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			r.line(2, "%s=%s %s: %s", condition.Type, condition.Status, condition.Reason, condition.Message)
+		}
+	}
+
+	restarted := false
+	for _, status := range mpPod.Status.ContainerStatuses {
+		if status.Name != mppod.ContainerName {
+			continue
+		}
+		r.line(1, "Container ready=%t, restarts %d", status.Ready, status.RestartCount)
+		restarted = status.RestartCount > 0
+		// Mountpoint's error output, also written to `mount.err`, is the termination message of failed containers
+		for _, terminated := range []*corev1.ContainerStateTerminated{status.State.Terminated, status.LastTerminationState.Terminated} {
+			if terminated != nil && terminated.ExitCode != 0 {
+				r.line(1, "Exited with code %d (%s), %s:", terminated.ExitCode, terminated.Reason, mppod.KnownPathMountError)
+				r.text(2, terminated.Message)
+				break
+			}
+		}
+	}
+	r.reportEvents(ctx, r.opts.MountpointNamespace, "Pod", name)
+
+	r.reportLogs(ctx, r.opts.MountpointNamespace, name, mppod.ContainerName, false)
+	if restarted {
+		r.reportLogs(ctx, r.opts.MountpointNamespace, name, mppod.ContainerName, true)
+	}
+}
+
+// reportLogs reports the last lines of logs of a container, or of its previous instance if `previous` is set.
+func (r *reporter) reportLogs(ctx context.Context, namespace, name, container string, previous bool) {
+	title := "Logs"
+	if previous {
+		title = "Logs before the last restart"
+	}
+	logs, err := r.pods.Pods(namespace).GetLogs(name, &corev1.PodLogOptions{
+		Container: container,
+		Previous:  previous,
+		TailLines: ptr.To(r.opts.LogLines),
+	}).DoRaw(ctx)
+	if err != nil {
+		r.line(1, "%s: failed to get logs: %v", title, err)
+		return
+	}
+	r.line(1, "%s (last %d lines):", title, r.opts.LogLines)
+	r.text(2, string(logs))
+}
+
+// reportNodePluginLogs reports the last lines of the node plugin logs containing any of `terms`.
+func (r *reporter) reportNodePluginLogs(ctx context.Context, nodePlugin *corev1.Pod, terms []string) {
+	r.section("Node plugin " + nodePlugin.Namespace + "/" + nodePlugin.Name)
+	logs, err := r.pods.Pods(nodePlugin.Namespace).GetLogs(nodePlugin.Name, &corev1.PodLogOptions{
+		Container: nodePluginContainer,
+		TailLines: ptr.To(int64(nodePluginLogLines)),
+	}).DoRaw(ctx)
+	if err != nil {
+		r.line(1, "Failed to get logs: %v", err)
+		return
+	}
+
+	var matches []string
+	scanner := bufio.NewScanner(strings.NewReader(string(logs)))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if slices.ContainsFunc(terms, func(term string) bool { return term != "" && strings.Contains(line, term) }) {
+			matches = append(matches, line)
+		}
+	}
+	if len(matches) > int(r.opts.LogLines) {
+		matches = matches[len(matches)-int(r.opts.LogLines):]
+	}
+	r.line(1, "Log lines about the volume (%d of the last %d lines):", len(matches), nodePluginLogLines)
+	r.text(2, strings.Join(matches, "\n"))
+}
+
+// reportEvents reports the last events of an object, such as mount failures reported by kubelet.
+func (r *reporter) reportEvents(ctx context.Context, namespace, kind, name string) {
+	events := &corev1.EventList{}
+	if err := r.c.List(ctx, events, client.InNamespace(namespace)); err != nil {
+		r.line(2, "Failed to list events: %v", err)
+		return
+	}
+	var matching []corev1.Event
+	for _, event := range events.Items {
+		if event.InvolvedObject.Kind == kind && event.InvolvedObject.Name == name {
+			matching = append(matching, event)
+		}
+	}
+	slices.SortFunc(matching, func(a, b corev1.Event) int { return a.LastTimestamp.Compare(b.LastTimestamp.Time) })
+	if len(matching) > reportEvents {
+		matching = matching[len(matching)-reportEvents:]
+	}
+	for _, event := range matching {
+		r.line(2, "Event %s %s (x%d): %s", event.Type, event.Reason, max(event.Count, 1), event.Message)
+	}
+}
+
+// text reports multi-line `text`, indented.
+func (r *reporter) text(indent int, text string) {
+	text = strings.TrimRight(text, "\n")
+	if text == "" {
+		r.line(indent, "(empty)")
+		return
+	}
+	for line := range strings.SplitSeq(text, "\n") {
+		r.line(indent, "%s", line)
+	}
+}
+
+// nodePluginPod returns the node plugin Pod running on `node`.
+func (r *reporter) nodePluginPod(ctx context.Context, node string) (*corev1.Pod, error) {
+	podList, err := r.pods.Pods(r.opts.DriverNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: nodePluginLabel,
+		FieldSelector: "spec.nodeName=" + node,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range podList.Items {
+		if podList.Items[i].Spec.NodeName == node {
+			return &podList.Items[i], nil
+		}
+	}
+	return nil, errors.New("no node plugin Pod runs on node " + node + " in namespace " + r.opts.DriverNamespace)
+}
+
+// containerEnv returns the value of environment variable `name` of `container` in `pod`, if set explicitly.
+func containerEnv(pod *corev1.Pod, container, name string) string {
+	if pod == nil {
+		return ""
+	}
+	for _, c := range pod.Spec.Containers {
+		if c.Name != container {
+			continue
+		}
+		for _, env := range c.Env {
+			if env.Name == name {
+				return env.Value
+			}
+		}
+	}
+	return ""
+}
+
+// attachesAny returns whether `s3pa` attaches any of `workloadUIDs`.
+func attachesAny(s3pa *crdv2.MountpointS3PodAttachment, workloadUIDs map[string]bool) bool {
+	for _, attachments := range s3pa.Spec.MountpointS3PodAttachments {
+		for _, attachment := range attachments {
+			if workloadUIDs[attachment.WorkloadPodUID] {
+				return true
+			}
+		}
+	}
+	return false
+}
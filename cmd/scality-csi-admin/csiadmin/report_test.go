@@ -0,0 +1,129 @@
+package csiadmin_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/scality/mountpoint-s3-csi-driver/cmd/scality-csi-admin/csiadmin"
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+)
+
+func TestReport(t *testing.T) {
+	workload := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", UID: "workload-uid"},
+		Spec: corev1.PodSpec{
+			NodeName: "node-1",
+			Volumes: []corev1.Volume{{
+				Name:         "data",
+				VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "claim"}},
+			}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodPending},
+	}
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default"},
+		Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pv-1"},
+		Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+	}
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv-1"},
+		Spec: corev1.PersistentVolumeSpec{
+			MountOptions: []string{"allow-delete", "profile=default"},
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: constants.DriverName, VolumeHandle: "bucket"},
+			},
+		},
+	}
+	s3pa := &crdv2.MountpointS3PodAttachment{
+		ObjectMeta: metav1.ObjectMeta{Name: "s3pa-1"},
+		Spec: crdv2.MountpointS3PodAttachmentSpec{
+			NodeName:             "node-1",
+			PersistentVolumeName: "pv-1",
+			MountOptions:         "allow-delete,profile=default",
+			MountpointS3PodAttachments: map[string][]crdv2.WorkloadAttachment{
+				"mp-1": {{WorkloadPodUID: "workload-uid"}},
+			},
+		},
+		Status: crdv2.MountpointS3PodAttachmentStatus{Conditions: []metav1.Condition{{
+			Type: crdv2.ConditionMountError, Status: metav1.ConditionTrue, Reason: "AccessDenied", Message: "Mountpoint failed",
+		}}},
+	}
+	mpPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "mp-1", Namespace: "mount-s3"},
+		Spec:       corev1.PodSpec{NodeName: "node-1"},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:         mppod.ContainerName,
+				RestartCount: 1,
+				LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					ExitCode: 1,
+					Reason:   "Error",
+					Message:  "Error: Failed to create S3 client\nCaused by: AccessDenied: Access Denied",
+				}},
+			}},
+		},
+	}
+	nodePlugin := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "s3-csi-node-abc", Namespace: "kube-system", Labels: map[string]string{"app": "s3-csi-node"}},
+		Spec:       corev1.PodSpec{NodeName: "node-1", Containers: []corev1.Container{{Name: "s3-plugin"}}},
+	}
+
+	tests := []struct {
+		name string
+		opts csiadmin.ReportOptions
+	}{
+		{name: "report of a Pod", opts: csiadmin.ReportOptions{Pod: "app"}},
+		{name: "report of a PersistentVolumeClaim", opts: csiadmin.ReportOptions{PVC: "claim"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+			_ = crdv2.AddToScheme(scheme)
+			c := fake.NewClientBuilder().WithScheme(scheme).
+				WithObjects(workload, pvc, pv, s3pa).
+				WithIndex(&crdv2.MountpointS3PodAttachment{}, crdv2.FieldPersistentVolumeName, func(obj client.Object) []string {
+					return []string{obj.(*crdv2.MountpointS3PodAttachment).Spec.PersistentVolumeName}
+				}).
+				Build()
+			clientset := kubefake.NewClientset(mpPod, nodePlugin)
+
+			tt.opts.Namespace = "default"
+			tt.opts.MountpointNamespace = "mount-s3"
+			tt.opts.DriverNamespace = "kube-system"
+			tt.opts.LogLines = 10
+			out := &bytes.Buffer{}
+			if err := csiadmin.Report(context.Background(), c, clientset.CoreV1(), tt.opts, out); err != nil {
+				t.Fatalf("Failed to report: %v", err)
+			}
+
+			for _, want := range []string{
+				"Pod default/app (UID workload-uid) on node \"node-1\"",
+				"Effective mount options: \"allow-delete,profile=default\"",
+				"Warning: --profile is ignored",
+				"Condition MountError=True AccessDenied",
+				"Caused by: AccessDenied: Access Denied",
+				"Logs before the last restart",
+				"fake logs",
+				"Node plugin kube-system/s3-csi-node-abc",
+			} {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("Expected report to contain %q, got:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+// `scality-csi-admin` provides administrative commands for clusters running the Scality CSI Driver for S3.
+// It is run by cluster administrators with their kubeconfig, not deployed in the cluster.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/scality/mountpoint-s3-csi-driver/cmd/scality-csi-admin/csiadmin"
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/client/clientset/versioned"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/version"
+)
+
+const usage = `Usage: scality-csi-admin [--kubeconfig=PATH] COMMAND [OPTIONS]
+
+Commands:
+  diagnose-mount  Check whether a node can mount a bucket with a short-lived diagnostic Pod
+  tail-logs       Stream logs of the Mountpoint Pods serving a volume
+  report          Print a diagnostic report of the mounts of a Pod or PersistentVolumeClaim
+  attachments     List or watch MountpointS3PodAttachments of a node or PersistentVolume
+  conformance     Print the CSI capabilities, volume attributes and mount options supported by this version
+
+Run "scality-csi-admin COMMAND --help" for the options of a command.
+`
+
+func main() {
+	flag.Usage = func() { fmt.Fprint(flag.CommandLine.Output(), usage) }
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	var err error
+	switch command, args := flag.Arg(0), flag.Args()[1:]; command {
+	case "diagnose-mount":
+		err = diagnoseMount(ctx, args)
+	case "tail-logs":
+		err = tailLogs(ctx, args)
+	case "report":
+		err = report(ctx, args)
+	case "attachments":
+		err = attachments(ctx, args)
+	case "conformance":
+		err = conformanceReport(args)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", command)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func diagnoseMount(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("diagnose-mount", flag.ExitOnError)
+	opts := csiadmin.DiagnoseMountOptions{}
+	fs.StringVar(&opts.Namespace, "namespace", "kube-system", "Namespace to create the diagnostic Pod in, must be the driver's diagnostic mount namespace.")
+	fs.StringVar(&opts.Node, "node", "", "Node to mount the bucket on.")
+	fs.StringVar(&opts.Bucket, "bucket", "", "Bucket to mount.")
+	fs.StringVar(&opts.Prefix, "prefix", "", "Prefix of the bucket to mount.")
+	fs.StringVar(&opts.Secret, "secret", "", "Secret in the namespace with credentials to mount the bucket with, driver-level credentials are used if empty.")
+	fs.DurationVar(&opts.TTL, "ttl", 5*time.Minute, "Maximum lifetime of the diagnostic Pod.")
+	fs.StringVar(&opts.Image, "image", defaultImage(), "Image of the diagnostic Pod, must contain scality-csi-checker.")
+	fs.BoolVar(&opts.Keep, "keep", false, "Keep the diagnostic Pod until its TTL expires instead of deleting it after the check.")
+	fs.StringVar(&opts.DriverName, "driver-name", constants.DriverName, "Name of the driver instance to mount the bucket with.")
+	_ = fs.Parse(args)
+
+	if opts.Node == "" || opts.Bucket == "" || opts.Image == "" {
+		fs.Usage()
+		return errors.New("--node, --bucket and --image are required")
+	}
+	if opts.TTL <= 0 {
+		return fmt.Errorf("--ttl must be positive, got %v", opts.TTL)
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	return csiadmin.DiagnoseMount(ctx, c, opts, os.Stdout)
+}
+
+func tailLogs(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("tail-logs", flag.ExitOnError)
+	opts := csiadmin.TailLogsOptions{}
+	fs.StringVar(&opts.Volume, "volume", "", "PersistentVolume whose Mountpoint Pods logs are streamed.")
+	fs.StringVar(&opts.Node, "node", "", "Only stream logs of the Mountpoint Pod on this node.")
+	fs.StringVar(&opts.MountpointNamespace, "mountpoint-namespace", "mount-s3", "Namespace of Mountpoint Pods.")
+	fs.BoolVar(&opts.Follow, "follow", false, "Keep streaming new logs, following restarts and replacements of Mountpoint Pods.")
+	fs.BoolVar(&opts.Follow, "f", false, "Shorthand for --follow.")
+	fs.DurationVar(&opts.Since, "since", 0, "Only stream logs newer than this duration, e.g. 10m.")
+	fs.Int64Var(&opts.TailLines, "tail", 0, "Number of last lines of logs to stream initially, all lines if zero.")
+	_ = fs.Parse(args)
+
+	if opts.Volume == "" {
+		fs.Usage()
+		return errors.New("--volume is required")
+	}
+	if opts.Since < 0 || opts.TailLines < 0 {
+		return errors.New("--since and --tail must not be negative")
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	clientset, err := newClientset()
+	if err != nil {
+		return err
+	}
+	return csiadmin.TailLogs(ctx, c, clientset.CoreV1(), opts, os.Stdout)
+}
+
+func report(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	opts := csiadmin.ReportOptions{}
+	fs.StringVar(&opts.Namespace, "namespace", "default", "Namespace of the Pod or PersistentVolumeClaim.")
+	fs.StringVar(&opts.Pod, "pod", "", "Pod whose mounts are reported.")
+	fs.StringVar(&opts.PVC, "pvc", "", "PersistentVolumeClaim whose mounts are reported, with the Pods using it.")
+	fs.StringVar(&opts.MountpointNamespace, "mountpoint-namespace", "mount-s3", "Namespace of Mountpoint Pods.")
+	fs.StringVar(&opts.DriverNamespace, "driver-namespace", "kube-system", "Namespace the driver is installed in.")
+	fs.StringVar(&opts.DriverName, "driver-name", constants.DriverName, "Name of the driver instance the volumes belong to.")
+	fs.Int64Var(&opts.LogLines, "log-lines", 50, "Number of last lines of logs reported per container.")
+	_ = fs.Parse(args)
+
+	if (opts.Pod == "") == (opts.PVC == "") {
+		fs.Usage()
+		return errors.New("exactly one of --pod and --pvc is required")
+	}
+	if opts.LogLines <= 0 {
+		return fmt.Errorf("--log-lines must be positive, got %d", opts.LogLines)
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	clientset, err := newClientset()
+	if err != nil {
+		return err
+	}
+	return csiadmin.Report(ctx, c, clientset.CoreV1(), opts, os.Stdout)
+}
+
+func attachments(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("attachments", flag.ExitOnError)
+	opts := csiadmin.AttachmentsOptions{}
+	fs.StringVar(&opts.Node, "node", "", "Only list attachments of this node.")
+	fs.StringVar(&opts.Volume, "volume", "", "Only list attachments of this PersistentVolume.")
+	fs.BoolVar(&opts.Watch, "watch", false, "Print changes of the attachments after listing them.")
+	fs.BoolVar(&opts.Watch, "w", false, "Shorthand for --watch.")
+	_ = fs.Parse(args)
+
+	clientset, err := newS3Clientset()
+	if err != nil {
+		return err
+	}
+	return csiadmin.Attachments(ctx, clientset.S3V2(), opts, os.Stdout)
+}
+
+func conformanceReport(args []string) error {
+	fs := flag.NewFlagSet("conformance", flag.ExitOnError)
+	format := fs.String("format", csiadmin.FormatMarkdown, "Format of the report, json or markdown.")
+	_ = fs.Parse(args)
+
+	return csiadmin.Conformance(*format, os.Stdout)
+}
+
+// defaultImage returns the driver image matching the version of this binary, if known.
+func defaultImage() string {
+	if v := version.GetVersion().DriverVersion; v != "" {
+		return "ghcr.io/scality/mountpoint-s3-csi-driver:" + v
+	}
+	return ""
+}
+
+func newClient() (client.Client, error) {
+	config, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := crdv2.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	return client.New(config, client.Options{Scheme: scheme})
+}
+
+// newClientset returns a Kubernetes clientset, used for the Pod logs subresource.
+func newClientset() (*kubernetes.Clientset, error) {
+	config, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	return clientset, nil
+}
+
+// newS3Clientset returns a typed clientset of the s3.csi.scality.com API group.
+func newS3Clientset() (*versioned.Clientset, error) {
+	config, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	clientset, err := versioned.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	return clientset, nil
+}
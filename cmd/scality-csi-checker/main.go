@@ -0,0 +1,31 @@
+// `scality-csi-checker` lists a directory of a Mountpoint mount and writes the result to the container's termination
+// message, to be compared by `scality-csi-controller` with a direct S3 listing.
+// Written as a Go program to avoid shell dependencies in the container.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/consistency"
+)
+
+var (
+	path       = flag.String("path", "", "Path of the directory to list.")
+	maxEntries = flag.Int("max-entries", 50, "Maximum number of entries to list.")
+	output     = flag.String("output", "/dev/termination-log", "Path to write the listing to.")
+)
+
+func main() {
+	flag.Parse()
+
+	if *path == "" {
+		log.Fatalf("--path is required")
+	}
+
+	listing := consistency.ListDir(*path, *maxEntries)
+	if err := os.WriteFile(*output, consistency.Encode(listing), 0o644); err != nil {
+		log.Fatalf("failed to write listing to %s: %v", *output, err)
+	}
+}
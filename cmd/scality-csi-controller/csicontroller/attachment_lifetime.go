@@ -0,0 +1,238 @@
+package csicontroller
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+)
+
+// EventReasonEvictedForCredentialRotation is the reason of events emitted on workloads evicted by the
+// [AttachmentLifetimeEnforcer].
+const EventReasonEvictedForCredentialRotation = "EvictedForCredentialRotation"
+
+const (
+	// attachmentLifetimeInterval is how often attachments are checked against their maximum lifetime.
+	attachmentLifetimeInterval = time.Minute
+	// attachmentLifetimeEvictionsPerRun is the maximum number of workloads evicted per check across the cluster, so
+	// Mountpoint Pods created at the same time are not all remounted at once.
+	attachmentLifetimeEvictionsPerRun = 5
+)
+
+// AttachmentLifetimeConfig configures the maximum lifetime of attachments.
+type AttachmentLifetimeConfig struct {
+	// MaxLifetime is the maximum time workloads stay attached to a Mountpoint Pod, and so use the credentials it
+	// was mounted with.
+	MaxLifetime time.Duration
+	// Stagger is the window before [AttachmentLifetimeConfig.MaxLifetime] over which remounts of Mountpoint Pods are
+	// spread, each Mountpoint Pod being remounted at a stable offset within the window.
+	Stagger time.Duration
+}
+
+// An AttachmentLifetimeEnforcer bounds the time workloads use the same credentials, by gracefully remounting
+// workloads attached to a Mountpoint Pod for longer than the maximum lifetime.
+//
+// Mountpoint gets its credentials when it is mounted, so the credentials of an attachment are as old as its
+// Mountpoint Pod. Expired Mountpoint Pods are annotated with [mppod.AnnotationNoNewWorkload], so new workloads get a
+// new Mountpoint Pod mounted with fresh credentials, and their workloads are evicted, so their controllers recreate
+// them on a new Mountpoint Pod. Evictions respect PodDisruptionBudgets, and workloads without a controller are never
+// evicted as nothing would recreate them. Remounts are staggered: each Mountpoint Pod expires at a stable offset
+// within [AttachmentLifetimeConfig.Stagger] before the maximum lifetime, and at most
+// [attachmentLifetimeEvictionsPerRun] workloads are evicted per check.
+type AttachmentLifetimeEnforcer struct {
+	reconciler *Reconciler
+	config     AttachmentLifetimeConfig
+	now        func() time.Time
+}
+
+// NewAttachmentLifetimeEnforcer creates a new [AttachmentLifetimeEnforcer].
+func NewAttachmentLifetimeEnforcer(reconciler *Reconciler, config AttachmentLifetimeConfig) *AttachmentLifetimeEnforcer {
+	return &AttachmentLifetimeEnforcer{
+		reconciler: reconciler,
+		config:     config,
+		now:        time.Now,
+	}
+}
+
+// Start begins the periodic enforcement of the maximum lifetime of attachments.
+func (e *AttachmentLifetimeEnforcer) Start(ctx context.Context) error {
+	log := logf.FromContext(ctx)
+	log.Info("Starting attachment lifetime enforcer", "interval", attachmentLifetimeInterval,
+		"maxLifetime", e.config.MaxLifetime, "stagger", e.config.Stagger)
+
+	ticker := time.NewTicker(attachmentLifetimeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Completed attachment lifetime enforcer")
+			return nil
+		case <-ticker.C:
+			if err := e.RunEnforcement(ctx); err != nil {
+				log.Error(err, "Failed to enforce the maximum lifetime of attachments")
+				// Continue running even if the enforcement fails
+			}
+		}
+	}
+}
+
+// expiredWorkload is a workload attached to a Mountpoint Pod past its remount deadline.
+type expiredWorkload struct {
+	pod        *corev1.Pod
+	mpPodName  string
+	mpPodSince time.Time
+}
+
+// RunEnforcement drains Mountpoint Pods past their remount deadline, evicts the oldest of their workloads and
+// reports the attachments exceeding the maximum lifetime.
+func (e *AttachmentLifetimeEnforcer) RunEnforcement(ctx context.Context) error {
+	log := logf.FromContext(ctx)
+	now := e.now()
+
+	s3paList := &crdv2.MountpointS3PodAttachmentList{}
+	if err := e.reconciler.List(ctx, s3paList); err != nil {
+		return err
+	}
+	podList := &corev1.PodList{}
+	if err := e.reconciler.List(ctx, podList); err != nil {
+		return err
+	}
+	podsByUID := make(map[string]*corev1.Pod, len(podList.Items))
+	for i := range podList.Items {
+		podsByUID[string(podList.Items[i].UID)] = &podList.Items[i]
+	}
+
+	exceeding := 0
+	oldest := time.Duration(0)
+	var expired []expiredWorkload
+	var errs []error
+	for _, s3pa := range s3paList.Items {
+		for mpPodName, attachments := range s3pa.Spec.MountpointS3PodAttachments {
+			mpPod, err := e.reconciler.getMountpointPod(ctx, mpPodName)
+			if err != nil {
+				if !apierrors.IsNotFound(err) {
+					errs = append(errs, err)
+				}
+				continue
+			}
+			age := now.Sub(mpPod.CreationTimestamp.Time)
+			oldest = max(oldest, age)
+			if age > e.config.MaxLifetime {
+				exceeding += len(attachments)
+			}
+			if now.Before(e.remountDeadline(mpPod)) {
+				continue
+			}
+
+			if err := e.drain(ctx, mpPod); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			for _, attachment := range attachments {
+				pod, ok := podsByUID[attachment.WorkloadPodUID]
+				if !ok || pod.DeletionTimestamp != nil {
+					continue
+				}
+				if metav1.GetControllerOf(pod) == nil {
+					log.Info("Workload exceeding the maximum attachment lifetime has no controller and is not evicted",
+						"pod", client.ObjectKeyFromObject(pod), "mountpointPodName", mpPodName)
+					continue
+				}
+				expired = append(expired, expiredWorkload{pod: pod, mpPodName: mpPodName, mpPodSince: mpPod.CreationTimestamp.Time})
+			}
+		}
+	}
+	attachmentsExceedingMaxLifetime.Set(float64(exceeding))
+	oldestAttachmentAgeSeconds.Set(oldest.Seconds())
+
+	// Oldest Mountpoint Pods first, one workload per Mountpoint Pod per run
+	slices.SortFunc(expired, func(a, b expiredWorkload) int {
+		return a.mpPodSince.Compare(b.mpPodSince)
+	})
+	evicted := make(map[string]bool)
+	for _, workload := range expired {
+		if len(evicted) >= attachmentLifetimeEvictionsPerRun {
+			break
+		}
+		if evicted[workload.mpPodName] {
+			continue
+		}
+		if err := e.evict(ctx, workload); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		evicted[workload.mpPodName] = true
+	}
+	return errors.Join(errs...)
+}
+
+// remountDeadline returns when workloads of `mpPod` are remounted: a stable offset within the stagger window before
+// the maximum lifetime, so Mountpoint Pods created at the same time are remounted at different times.
+func (e *AttachmentLifetimeEnforcer) remountDeadline(mpPod *corev1.Pod) time.Time {
+	stagger := min(e.config.Stagger, e.config.MaxLifetime)
+	var offset time.Duration
+	if stagger > 0 {
+		h := fnv.New64a()
+		h.Write([]byte(mpPod.UID))
+		offset = time.Duration(h.Sum64() % uint64(stagger))
+	}
+	return mpPod.CreationTimestamp.Add(e.config.MaxLifetime - offset)
+}
+
+// drain annotates `mpPod` so no new workloads are assigned to it.
+func (e *AttachmentLifetimeEnforcer) drain(ctx context.Context, mpPod *corev1.Pod) error {
+	if mpPod.Annotations[mppod.AnnotationNoNewWorkload] == "true" {
+		return nil
+	}
+	patch := client.MergeFrom(mpPod.DeepCopy())
+	if mpPod.Annotations == nil {
+		mpPod.Annotations = make(map[string]string)
+	}
+	mpPod.Annotations[mppod.AnnotationNoNewWorkload] = "true"
+	if err := e.reconciler.Patch(ctx, mpPod, patch); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	logf.FromContext(ctx).Info("Draining Mountpoint Pod reaching the maximum attachment lifetime", "mountpointPodName", mpPod.Name,
+		"age", e.now().Sub(mpPod.CreationTimestamp.Time))
+	return nil
+}
+
+// evict evicts `workload` so its controller recreates it on a Mountpoint Pod mounted with fresh credentials.
+func (e *AttachmentLifetimeEnforcer) evict(ctx context.Context, workload expiredWorkload) error {
+	log := logf.FromContext(ctx)
+	pod := workload.pod
+	err := e.reconciler.SubResource("eviction").Create(ctx, pod, &policyv1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{Namespace: pod.Namespace, Name: pod.Name},
+	})
+	switch {
+	case err == nil:
+		attachmentLifetimeEvictionsTotal.Inc()
+		log.Info("Evicted workload to remount it with fresh credentials", "pod", client.ObjectKeyFromObject(pod),
+			"mountpointPodName", workload.mpPodName, "age", e.now().Sub(workload.mpPodSince))
+		if e.reconciler.recorder != nil {
+			e.reconciler.recorder.Eventf(pod, corev1.EventTypeNormal, EventReasonEvictedForCredentialRotation,
+				"Evicted to remount its volumes with fresh credentials after the maximum attachment lifetime of %v", e.config.MaxLifetime)
+		}
+		return nil
+	case apierrors.IsTooManyRequests(err):
+		// Blocked by a PodDisruptionBudget, retried in the next run
+		log.Info("Eviction of workload blocked by a PodDisruptionBudget", "pod", client.ObjectKeyFromObject(pod))
+		return nil
+	case apierrors.IsNotFound(err):
+		return nil
+	default:
+		return err
+	}
+}
@@ -0,0 +1,113 @@
+package csicontroller_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/scality/mountpoint-s3-csi-driver/cmd/scality-csi-controller/csicontroller"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+)
+
+func TestAttachmentLifetimeEnforcer(t *testing.T) {
+	ctx := context.Background()
+	config := csicontroller.AttachmentLifetimeConfig{MaxLifetime: 24 * time.Hour, Stagger: time.Hour}
+
+	// setup creates a workload attached to a Mountpoint Pod created `age` ago
+	setup := func(t *testing.T, age time.Duration, controlled bool) (*csicontroller.Reconciler, client.Client, *record.FakeRecorder) {
+		t.Helper()
+		pod := createTestPod(testPodName, testNamespace, testNodeName, pvcVolumes())
+		pod.UID = "workload-uid"
+		if controlled {
+			pod.OwnerReferences = []metav1.OwnerReference{{
+				APIVersion: "apps/v1",
+				Kind:       "ReplicaSet",
+				Name:       "test-rs",
+				UID:        "test-rs-uid",
+				Controller: ptr.To(true),
+			}}
+		}
+		mpPod := createTestMountpointPod(nil)
+		mpPod.UID = "mp-pod-uid"
+		mpPod.CreationTimestamp = metav1.NewTime(time.Now().Add(-age))
+		s3pa := createTestS3PodAttachment("test-s3pa", "workload-uid", testMPPodName)
+		reconciler, c := testReconciler(pod, mpPod, s3pa)
+		recorder := record.NewFakeRecorder(10)
+		reconciler.SetEventRecorder(recorder)
+		return reconciler, c, recorder
+	}
+
+	draining := func(t *testing.T, c client.Client) bool {
+		t.Helper()
+		mpPod := &corev1.Pod{}
+		if err := c.Get(ctx, types.NamespacedName{Name: testMPPodName, Namespace: mountpointNamespace}, mpPod); err != nil {
+			t.Fatalf("Failed to get Mountpoint Pod: %v", err)
+		}
+		return mpPod.Annotations[mppod.AnnotationNoNewWorkload] == "true"
+	}
+
+	workloadExists := func(t *testing.T, c client.Client) bool {
+		t.Helper()
+		err := c.Get(ctx, types.NamespacedName{Name: testPodName, Namespace: testNamespace}, &corev1.Pod{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			t.Fatalf("Failed to get workload: %v", err)
+		}
+		return err == nil
+	}
+
+	t.Run("Workloads within the staggered lifetime are kept", func(t *testing.T) {
+		reconciler, c, _ := setup(t, 22*time.Hour, true)
+
+		if err := csicontroller.NewAttachmentLifetimeEnforcer(reconciler, config).RunEnforcement(ctx); err != nil {
+			t.Fatalf("Failed to enforce the maximum attachment lifetime: %v", err)
+		}
+		if draining(t, c) || !workloadExists(t, c) {
+			t.Fatal("Expected Mountpoint Pod within its lifetime to be kept")
+		}
+	})
+
+	t.Run("Expired Mountpoint Pods are drained and their workloads evicted", func(t *testing.T) {
+		reconciler, c, recorder := setup(t, 25*time.Hour, true)
+
+		if err := csicontroller.NewAttachmentLifetimeEnforcer(reconciler, config).RunEnforcement(ctx); err != nil {
+			t.Fatalf("Failed to enforce the maximum attachment lifetime: %v", err)
+		}
+		if !draining(t, c) {
+			t.Fatal("Expected expired Mountpoint Pod to be drained")
+		}
+		if workloadExists(t, c) {
+			t.Fatal("Expected workload of expired Mountpoint Pod to be evicted")
+		}
+		select {
+		case event := <-recorder.Events:
+			if !strings.Contains(event, csicontroller.EventReasonEvictedForCredentialRotation) {
+				t.Errorf("Expected %s event, got %q", csicontroller.EventReasonEvictedForCredentialRotation, event)
+			}
+		default:
+			t.Errorf("Expected %s event", csicontroller.EventReasonEvictedForCredentialRotation)
+		}
+	})
+
+	t.Run("Workloads without controller are never evicted", func(t *testing.T) {
+		reconciler, c, _ := setup(t, 25*time.Hour, false)
+
+		if err := csicontroller.NewAttachmentLifetimeEnforcer(reconciler, config).RunEnforcement(ctx); err != nil {
+			t.Fatalf("Failed to enforce the maximum attachment lifetime: %v", err)
+		}
+		if !draining(t, c) {
+			t.Fatal("Expected expired Mountpoint Pod to be drained")
+		}
+		if !workloadExists(t, c) {
+			t.Fatal("Expected workload without controller not to be evicted")
+		}
+	})
+}
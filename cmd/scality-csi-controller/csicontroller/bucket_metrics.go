@@ -0,0 +1,200 @@
+package csicontroller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/utapi"
+)
+
+// A BucketMetricsSource returns metrics of buckets over a time window, implemented by [utapi.Client].
+type BucketMetricsSource interface {
+	ListBucketMetrics(ctx context.Context, bucket string, window time.Duration) (utapi.BucketMetrics, error)
+}
+
+// BucketMetricsCollectorConfig holds the configuration of a [BucketMetricsCollector].
+type BucketMetricsCollectorConfig struct {
+	// Interval between collections.
+	Interval time.Duration
+	// Window over which request rates are averaged. UTAPI aggregates metrics in 15 minutes intervals,
+	// so windows are extended to start on an interval boundary.
+	Window time.Duration
+}
+
+// WorkloadBucketRates holds the share of the traffic of a bucket attributed to a workload Pod mounting it.
+type WorkloadBucketRates struct {
+	Namespace              string
+	Pod                    string
+	PersistentVolume       string
+	Bucket                 string
+	RequestsPerSecond      float64
+	IncomingBytesPerSecond float64
+	OutgoingBytesPerSecond float64
+	Consumers              int
+}
+
+// A BucketMetricsCollector periodically queries UTAPI request and traffic rates of the buckets mounted by
+// workload Pods, and exposes them as metrics labelled with the namespace and name of the consuming Pods.
+// This allows scaling consumers of a bucket with a HorizontalPodAutoscaler on the S3 traffic of their volumes,
+// through a custom metrics adapter.
+//
+// UTAPI measures the traffic of whole buckets, which is divided evenly between all Pods consuming a bucket.
+// The average over the Pods of a workload is then the traffic of the bucket per Pod, as the autoscaler expects.
+type BucketMetricsCollector struct {
+	client client.Client
+	source BucketMetricsSource
+	config BucketMetricsCollectorConfig
+}
+
+// NewBucketMetricsCollector creates a new [BucketMetricsCollector].
+func NewBucketMetricsCollector(client client.Client, source BucketMetricsSource, config BucketMetricsCollectorConfig) *BucketMetricsCollector {
+	return &BucketMetricsCollector{client: client, source: source, config: config}
+}
+
+// Start begins the periodic collection of bucket metrics.
+func (c *BucketMetricsCollector) Start(ctx context.Context) error {
+	log := logf.FromContext(ctx)
+	log.Info("Starting bucket metrics collector", "interval", c.config.Interval, "window", c.config.Window)
+
+	ticker := time.NewTicker(c.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Completed bucket metrics collector")
+			return nil
+		case <-ticker.C:
+			if _, err := c.RunCollection(ctx); err != nil {
+				log.Error(err, "Failed to collect bucket metrics")
+				// Continue running even if collection fails
+			}
+		}
+	}
+}
+
+// RunCollection queries the rates of all buckets mounted by running workload Pods, updates the exposed metrics
+// and returns them. Pods of buckets whose metrics could not be queried are left out.
+func (c *BucketMetricsCollector) RunCollection(ctx context.Context) ([]WorkloadBucketRates, error) {
+	consumers, err := c.bucketConsumers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var rates []WorkloadBucketRates
+	var errs []error
+	for bucket, workloads := range consumers {
+		metrics, err := c.source.ListBucketMetrics(ctx, bucket, c.config.Window)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to query metrics of bucket %q: %w", bucket, err))
+			continue
+		}
+		seconds := metrics.Duration().Seconds()
+		if seconds == 0 {
+			continue
+		}
+		share := seconds * float64(len(workloads))
+		for _, w := range workloads {
+			rates = append(rates, WorkloadBucketRates{
+				Namespace:              w.pod.Namespace,
+				Pod:                    w.pod.Name,
+				PersistentVolume:       w.pvName,
+				Bucket:                 bucket,
+				RequestsPerSecond:      float64(metrics.TotalOperations()) / share,
+				IncomingBytesPerSecond: float64(metrics.IncomingBytes) / share,
+				OutgoingBytesPerSecond: float64(metrics.OutgoingBytes) / share,
+				Consumers:              len(workloads),
+			})
+		}
+	}
+	sort.Slice(rates, func(i, j int) bool {
+		if rates[i].Namespace != rates[j].Namespace {
+			return rates[i].Namespace < rates[j].Namespace
+		}
+		if rates[i].Pod != rates[j].Pod {
+			return rates[i].Pod < rates[j].Pod
+		}
+		return rates[i].PersistentVolume < rates[j].PersistentVolume
+	})
+
+	workloadBucketRequestRate.Reset()
+	workloadBucketIncomingByteRate.Reset()
+	workloadBucketOutgoingByteRate.Reset()
+	for _, r := range rates {
+		labels := []string{r.Namespace, r.Pod, r.PersistentVolume, r.Bucket}
+		workloadBucketRequestRate.WithLabelValues(labels...).Set(r.RequestsPerSecond)
+		workloadBucketIncomingByteRate.WithLabelValues(labels...).Set(r.IncomingBytesPerSecond)
+		workloadBucketOutgoingByteRate.WithLabelValues(labels...).Set(r.OutgoingBytesPerSecond)
+	}
+	return rates, errors.Join(errs...)
+}
+
+// bucketConsumer is a running workload Pod mounting a bucket through Persistent Volume `pvName`.
+type bucketConsumer struct {
+	pod    *corev1.Pod
+	pvName string
+}
+
+// bucketConsumers returns the running workload Pods attached to Mountpoint Pods, grouped by mounted bucket.
+func (c *BucketMetricsCollector) bucketConsumers(ctx context.Context) (map[string][]bucketConsumer, error) {
+	s3paList := &crdv2.MountpointS3PodAttachmentList{}
+	if err := c.client.List(ctx, s3paList); err != nil {
+		return nil, err
+	}
+	if len(s3paList.Items) == 0 {
+		return nil, nil
+	}
+
+	podList := &corev1.PodList{}
+	if err := c.client.List(ctx, podList); err != nil {
+		return nil, err
+	}
+	podsByUID := make(map[string]*corev1.Pod, len(podList.Items))
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.Status.Phase == corev1.PodRunning {
+			podsByUID[string(pod.UID)] = pod
+		}
+	}
+
+	buckets := make(map[string]string)
+	consumers := make(map[string][]bucketConsumer)
+	for i := range s3paList.Items {
+		s3pa := &s3paList.Items[i]
+		pvName := s3pa.Spec.PersistentVolumeName
+		bucket, ok := buckets[pvName]
+		if !ok {
+			pv := &corev1.PersistentVolume{}
+			if err := c.client.Get(ctx, client.ObjectKey{Name: pvName}, pv); err != nil {
+				if client.IgnoreNotFound(err) != nil {
+					return nil, err
+				}
+			} else {
+				bucket = mppod.ExtractVolumeAttributes(pv)[volumecontext.BucketName]
+			}
+			buckets[pvName] = bucket
+		}
+		if bucket == "" {
+			continue
+		}
+
+		for _, workloads := range s3pa.Spec.MountpointS3PodAttachments {
+			for _, w := range workloads {
+				if pod, ok := podsByUID[w.WorkloadPodUID]; ok {
+					consumers[bucket] = append(consumers[bucket], bucketConsumer{pod: pod, pvName: pvName})
+				}
+			}
+		}
+	}
+	return consumers, nil
+}
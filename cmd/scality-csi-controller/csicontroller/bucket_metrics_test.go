@@ -0,0 +1,94 @@
+package csicontroller_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/scality/mountpoint-s3-csi-driver/cmd/scality-csi-controller/csicontroller"
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/utapi"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+type fakeBucketMetricsSource struct {
+	metrics map[string]utapi.BucketMetrics
+	windows []time.Duration
+}
+
+func (s *fakeBucketMetricsSource) ListBucketMetrics(_ context.Context, bucket string, window time.Duration) (utapi.BucketMetrics, error) {
+	s.windows = append(s.windows, window)
+	m, ok := s.metrics[bucket]
+	if !ok {
+		return utapi.BucketMetrics{}, errors.New("no such bucket")
+	}
+	return m, nil
+}
+
+func TestBucketMetricsCollector(t *testing.T) {
+	running := func(name string) *corev1.Pod {
+		pod := createTestPod(name, testNamespace, testNodeName, pvcVolumes())
+		pod.Status.Phase = corev1.PodRunning
+		return pod
+	}
+	pending := createTestPod("pending", testNamespace, testNodeName, pvcVolumes())
+
+	s3pa := &crdv2.MountpointS3PodAttachment{
+		Spec: crdv2.MountpointS3PodAttachmentSpec{
+			NodeName:             testNodeName,
+			PersistentVolumeName: testPVName,
+			MountpointS3PodAttachments: map[string][]crdv2.WorkloadAttachment{
+				"mp-1": {{WorkloadPodUID: "app-1-uid"}, {WorkloadPodUID: "app-2-uid"}, {WorkloadPodUID: "pending-uid"}},
+				"mp-2": {{WorkloadPodUID: "deleted-uid"}},
+			},
+		},
+	}
+	s3pa.Name = "s3pa-1"
+
+	tests := []struct {
+		name      string
+		metrics   map[string]utapi.BucketMetrics
+		wantRates []csicontroller.WorkloadBucketRates
+		wantErr   bool
+	}{
+		{
+			name: "rates of the bucket are divided between its running consumers",
+			metrics: map[string]utapi.BucketMetrics{"test-bucket": {
+				TimeRange:     []int64{0, 100_000},
+				IncomingBytes: 4000,
+				OutgoingBytes: 20000,
+				Operations:    map[string]int64{"s3:GetObject": 300, "s3:PutObject": 100},
+			}},
+			wantRates: []csicontroller.WorkloadBucketRates{
+				{Namespace: testNamespace, Pod: "app-1", PersistentVolume: testPVName, Bucket: "test-bucket", RequestsPerSecond: 2, IncomingBytesPerSecond: 20, OutgoingBytesPerSecond: 100, Consumers: 2},
+				{Namespace: testNamespace, Pod: "app-2", PersistentVolume: testPVName, Bucket: "test-bucket", RequestsPerSecond: 2, IncomingBytesPerSecond: 20, OutgoingBytesPerSecond: 100, Consumers: 2},
+			},
+		},
+		{
+			name:    "bucket metrics cannot be queried",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, c := testReconciler(running("app-1"), running("app-2"), pending, s3pa,
+				createTestPV(testPVName, testPVCName, testNamespace))
+			source := &fakeBucketMetricsSource{metrics: tt.metrics}
+			collector := csicontroller.NewBucketMetricsCollector(c, source, csicontroller.BucketMetricsCollectorConfig{
+				Interval: time.Minute,
+				Window:   15 * time.Minute,
+			})
+
+			rates, err := collector.RunCollection(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			assert.Equals(t, tt.wantRates, rates)
+			assert.Equals(t, []time.Duration{15 * time.Minute}, source.windows)
+		})
+	}
+}
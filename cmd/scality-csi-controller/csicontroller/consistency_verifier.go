@@ -0,0 +1,331 @@
+package csicontroller
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/consistency"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util"
+)
+
+// LabelConsistencyCheckFor is the label set on checker Pods with the name of the Mountpoint Pod being verified.
+const LabelConsistencyCheckFor = constants.DriverName + "/consistency-check-for"
+
+// Reasons of events emitted on Persistent Volumes by the [ConsistencyVerifier].
+const (
+	EventReasonMountDivergence = "MountDivergence"
+)
+
+// Results of consistency verifications, used as metric labels.
+const (
+	consistencyResultConsistent = "consistent"
+	consistencyResultDivergent  = "divergent"
+	consistencyResultSkipped    = "skipped"
+	consistencyResultError      = "error"
+)
+
+const (
+	checkerPollInterval = 2 * time.Second
+	checkerMountPath    = "/mnt/source"
+	// maxReportedEntries is the maximum number of divergent entries reported in events.
+	maxReportedEntries = 5
+)
+
+// ConsistencyVerifierConfig configures a [ConsistencyVerifier].
+type ConsistencyVerifierConfig struct {
+	// Interval between verification rounds.
+	Interval time.Duration
+	// SampleSize is the number of mounts verified in each round.
+	SampleSize int
+	// MaxEntries is the maximum number of entries compared per mount, larger directories are skipped.
+	MaxEntries int
+	// SettleTime is how long S3 objects might take to be shown by the mount after being modified.
+	SettleTime time.Duration
+	// CheckerTimeout is how long to wait for a checker Pod to complete.
+	CheckerTimeout time.Duration
+	// Namespace to create checker Pods in. Checker Pods use a `hostPath` volume, so this namespace must allow
+	// privileged Pods.
+	Namespace string
+	// MountpointNamespace is the namespace of Mountpoint Pods.
+	MountpointNamespace string
+	// KubeletPath is the kubelet root directory on the nodes.
+	KubeletPath string
+	// CheckerImage is the image containing `scality-csi-checker`.
+	CheckerImage           string
+	CheckerImagePullPolicy corev1.PullPolicy
+	CheckerCommand         string
+}
+
+// A ConsistencyVerifier periodically verifies a sample of mounts by comparing the root directory of the mount,
+// as listed by a checker Pod on the node, with a direct S3 listing of the mounted bucket and prefix.
+// Divergences (e.g., due to caching bugs or clock skew) are reported as events on the Persistent Volume and
+// as metrics, as an early warning of data visibility problems.
+type ConsistencyVerifier struct {
+	client   client.Client
+	s3       s3.ListObjectsV2APIClient
+	recorder record.EventRecorder
+	config   ConsistencyVerifierConfig
+	now      func() time.Time
+	shuffle  func(n int, swap func(i, j int))
+}
+
+// NewConsistencyVerifier creates a new [ConsistencyVerifier].
+func NewConsistencyVerifier(client client.Client, s3Client s3.ListObjectsV2APIClient, recorder record.EventRecorder, config ConsistencyVerifierConfig) *ConsistencyVerifier {
+	return &ConsistencyVerifier{
+		client:   client,
+		s3:       s3Client,
+		recorder: recorder,
+		config:   config,
+		now:      time.Now,
+		shuffle:  rand.Shuffle,
+	}
+}
+
+// Start begins the periodic verification process.
+func (v *ConsistencyVerifier) Start(ctx context.Context) error {
+	log := logf.FromContext(ctx)
+	log.Info("Starting consistency verifier", "interval", v.config.Interval, "sampleSize", v.config.SampleSize)
+
+	ticker := time.NewTicker(v.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Completed consistency verifier")
+			return nil
+		case <-ticker.C:
+			if err := v.RunVerification(ctx); err != nil {
+				log.Error(err, "Failed to run consistency verification")
+				// Continue running even if verification fails
+			}
+		}
+	}
+}
+
+// A verificationTarget is a mount to verify.
+type verificationTarget struct {
+	s3pa      *crdv2.MountpointS3PodAttachment
+	mpPodName string
+	mpPod     *corev1.Pod
+}
+
+// RunVerification verifies a random sample of mounts served by running Mountpoint Pods.
+func (v *ConsistencyVerifier) RunVerification(ctx context.Context) error {
+	log := logf.FromContext(ctx)
+
+	s3paList := &crdv2.MountpointS3PodAttachmentList{}
+	if err := v.client.List(ctx, s3paList); err != nil {
+		return err
+	}
+
+	var targets []verificationTarget
+	for i := range s3paList.Items {
+		s3pa := &s3paList.Items[i]
+		for mpPodName, workloads := range s3pa.Spec.MountpointS3PodAttachments {
+			if len(workloads) == 0 {
+				continue
+			}
+			targets = append(targets, verificationTarget{s3pa: s3pa, mpPodName: mpPodName})
+		}
+	}
+
+	v.shuffle(len(targets), func(i, j int) { targets[i], targets[j] = targets[j], targets[i] })
+
+	verified := 0
+	for _, target := range targets {
+		if verified >= v.config.SampleSize {
+			break
+		}
+
+		mpPod := &corev1.Pod{}
+		err := v.client.Get(ctx, types.NamespacedName{Namespace: v.config.MountpointNamespace, Name: target.mpPodName}, mpPod)
+		if err != nil || mpPod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		target.mpPod = mpPod
+		verified++
+
+		result, err := v.verify(ctx, target)
+		consistencyChecksTotal.WithLabelValues(result).Inc()
+		if err != nil {
+			log.Error(err, "Failed to verify mount consistency", "mpPod", mpPod.Name, "pv", target.s3pa.Spec.PersistentVolumeName)
+		}
+	}
+
+	return nil
+}
+
+// verify verifies a single mount and returns the result of the verification.
+func (v *ConsistencyVerifier) verify(ctx context.Context, target verificationTarget) (string, error) {
+	pvName := target.s3pa.Spec.PersistentVolumeName
+	log := logf.FromContext(ctx).WithValues("mpPod", target.mpPod.Name, "pv", pvName)
+
+	pv := &corev1.PersistentVolume{}
+	if err := v.client.Get(ctx, types.NamespacedName{Name: pvName}, pv); err != nil {
+		return consistencyResultError, fmt.Errorf("failed to get Persistent Volume: %w", err)
+	}
+	bucket := mppod.ExtractVolumeAttributes(pv)[volumecontext.BucketName]
+	if bucket == "" {
+		return consistencyResultSkipped, nil
+	}
+	args := mountpoint.ParseMountOptions(target.s3pa.Spec.MountOptions)
+	prefix, _ := args.Value(mountpoint.ArgPrefix)
+
+	startedAt := v.now()
+	listing, err := v.listMount(ctx, target.mpPod)
+	if err != nil {
+		return consistencyResultError, err
+	}
+	if listing.Error != "" {
+		return consistencyResultError, fmt.Errorf("checker failed to list mount: %s", listing.Error)
+	}
+
+	s3Entries, truncated, err := consistency.ListS3(ctx, v.s3, bucket, prefix, v.config.MaxEntries)
+	if err != nil {
+		return consistencyResultError, fmt.Errorf("failed to list bucket %q: %w", bucket, err)
+	}
+	if truncated || listing.Truncated {
+		log.V(debugLevel).Info("Skipping consistency verification of a directory with too many entries", "maxEntries", v.config.MaxEntries)
+		return consistencyResultSkipped, nil
+	}
+
+	divergence := consistency.Compare(listing.Entries, s3Entries, startedAt.Add(-v.config.SettleTime))
+	if divergence.Empty() {
+		log.V(debugLevel).Info("Mount is consistent with S3", "entries", len(listing.Entries))
+		return consistencyResultConsistent, nil
+	}
+
+	log.Info("Mount diverges from S3", "missingInMount", divergence.MissingInMount, "missingInS3", divergence.MissingInS3)
+	consistencyDivergentEntriesTotal.WithLabelValues("mount").Add(float64(len(divergence.MissingInMount)))
+	consistencyDivergentEntriesTotal.WithLabelValues("s3").Add(float64(len(divergence.MissingInS3)))
+	v.recorder.Eventf(pv, corev1.EventTypeWarning, EventReasonMountDivergence,
+		"Mount served by Mountpoint Pod %s on node %s diverges from bucket %q: %d S3 entries not shown by the mount (%s), %d mount entries not found in S3 (%s)",
+		target.mpPod.Name, target.mpPod.Spec.NodeName, bucket,
+		len(divergence.MissingInMount), summarizeEntries(divergence.MissingInMount),
+		len(divergence.MissingInS3), summarizeEntries(divergence.MissingInS3))
+	return consistencyResultDivergent, nil
+}
+
+// listMount lists the root directory of the mount served by `mpPod` with a checker Pod on the same node.
+func (v *ConsistencyVerifier) listMount(ctx context.Context, mpPod *corev1.Pod) (consistency.Listing, error) {
+	checkerPod := v.checkerPod(mpPod)
+	if err := v.client.Create(ctx, checkerPod); err != nil {
+		return consistency.Listing{}, fmt.Errorf("failed to create checker Pod: %w", err)
+	}
+	defer func() {
+		if err := v.client.Delete(context.WithoutCancel(ctx), checkerPod); err != nil && !apierrors.IsNotFound(err) {
+			logf.FromContext(ctx).Error(err, "Failed to delete checker Pod", "checkerPod", checkerPod.Name)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, v.config.CheckerTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(checkerPollInterval)
+	defer ticker.Stop()
+
+	for {
+		pod := &corev1.Pod{}
+		if err := v.client.Get(ctx, client.ObjectKeyFromObject(checkerPod), pod); err != nil && !apierrors.IsNotFound(err) {
+			return consistency.Listing{}, fmt.Errorf("failed to get checker Pod: %w", err)
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.State.Terminated != nil {
+				listing, err := consistency.Decode(status.State.Terminated.Message)
+				if err != nil {
+					return consistency.Listing{}, fmt.Errorf("failed to decode checker output %q: %w", status.State.Terminated.Message, err)
+				}
+				return listing, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return consistency.Listing{}, fmt.Errorf("checker Pod did not complete: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkerPod returns a Pod listing the source mount of `mpPod` on its node.
+func (v *ConsistencyVerifier) checkerPod(mpPod *corev1.Pod) *corev1.Pod {
+	sourcePath := filepath.Join(v.config.KubeletPath, "plugins", util.DriverName(), "mnt", mpPod.Name)
+	hostToContainer := corev1.MountPropagationHostToContainer
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "s3-csi-checker-",
+			Namespace:    v.config.Namespace,
+			Labels: map[string]string{
+				LabelConsistencyCheckFor: mpPod.Name,
+			},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:                     mpPod.Spec.NodeName,
+			RestartPolicy:                corev1.RestartPolicyNever,
+			AutomountServiceAccountToken: ptr.To(false),
+			Tolerations:                  []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+			Containers: []corev1.Container{{
+				Name:            "checker",
+				Image:           v.config.CheckerImage,
+				ImagePullPolicy: v.config.CheckerImagePullPolicy,
+				Command:         []string{v.config.CheckerCommand},
+				Args: []string{
+					"--path=" + checkerMountPath,
+					"--max-entries=" + strconv.Itoa(v.config.MaxEntries),
+				},
+				SecurityContext: &corev1.SecurityContext{
+					// Root is needed to access mounts that only allow root in addition to the Mountpoint Pod user
+					RunAsUser:                ptr.To(int64(0)),
+					AllowPrivilegeEscalation: ptr.To(false),
+					ReadOnlyRootFilesystem:   ptr.To(true),
+					Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+				},
+				VolumeMounts: []corev1.VolumeMount{{
+					Name:             "source",
+					MountPath:        checkerMountPath,
+					ReadOnly:         true,
+					MountPropagation: &hostToContainer,
+				}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name: "source",
+				VolumeSource: corev1.VolumeSource{
+					HostPath: &corev1.HostPathVolumeSource{
+						Path: sourcePath,
+						Type: ptr.To(corev1.HostPathDirectory),
+					},
+				},
+			}},
+		},
+	}
+}
+
+// summarizeEntries returns a short comma separated list of `entries` to include in events.
+func summarizeEntries(entries []string) string {
+	if len(entries) <= maxReportedEntries {
+		return strings.Join(entries, ", ")
+	}
+	return strings.Join(entries[:maxReportedEntries], ", ") + ", ..."
+}
@@ -0,0 +1,183 @@
+package csicontroller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/consistency"
+)
+
+type fakeListObjectsV2 struct {
+	output *s3.ListObjectsV2Output
+	inputs []*s3.ListObjectsV2Input
+}
+
+func (f *fakeListObjectsV2) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	f.inputs = append(f.inputs, params)
+	return f.output, nil
+}
+
+func TestConsistencyVerifier(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = crdv2.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	old := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name         string
+		mountEntries []string
+		s3Objects    []string
+		wantEvent    bool
+	}{
+		{
+			name:         "consistent mount",
+			mountEntries: []string{"a.txt", "b.txt"},
+			s3Objects:    []string{"data/a.txt", "data/b.txt"},
+		},
+		{
+			name:         "divergent mount",
+			mountEntries: []string{"a.txt"},
+			s3Objects:    []string{"data/a.txt", "data/b.txt"},
+			wantEvent:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s3pa := &crdv2.MountpointS3PodAttachment{
+				ObjectMeta: metav1.ObjectMeta{Name: "s3pa-1"},
+				Spec: crdv2.MountpointS3PodAttachmentSpec{
+					NodeName:             "node-1",
+					PersistentVolumeName: "pv-1",
+					MountOptions:         "prefix=data/,allow-other",
+					MountpointS3PodAttachments: map[string][]crdv2.WorkloadAttachment{
+						"mp-1": {{WorkloadPodUID: "workload-1"}},
+						// Lingering Mountpoint Pods are not verified
+						"mp-2": {},
+					},
+				},
+			}
+			pv := &corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: "pv-1"},
+				Spec: corev1.PersistentVolumeSpec{
+					PersistentVolumeSource: corev1.PersistentVolumeSource{
+						CSI: &corev1.CSIPersistentVolumeSource{
+							Driver:           mountpointCSIDriverName,
+							VolumeAttributes: map[string]string{"bucketName": "bucket"},
+						},
+					},
+				},
+			}
+			mpPod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "mp-1", Namespace: "mount-s3"},
+				Spec:       corev1.PodSpec{NodeName: "node-1"},
+				Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+			}
+
+			var checkerPods []*corev1.Pod
+			message := string(consistency.Encode(consistency.Listing{Entries: tt.mountEntries}))
+			k8sClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(s3pa, pv, mpPod).
+				WithInterceptorFuncs(interceptor.Funcs{
+					Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+						if pod, ok := obj.(*corev1.Pod); ok {
+							pod.Name = pod.GenerateName + "test"
+							checkerPods = append(checkerPods, pod.DeepCopy())
+						}
+						return c.Create(ctx, obj, opts...)
+					},
+					Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+						if err := c.Get(ctx, key, obj, opts...); err != nil {
+							return err
+						}
+						// Simulate checker Pod completion
+						if pod, ok := obj.(*corev1.Pod); ok && pod.Labels[LabelConsistencyCheckFor] != "" {
+							pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+								State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: message}},
+							}}
+						}
+						return nil
+					},
+				}).
+				Build()
+
+			var contents []types.Object
+			for _, key := range tt.s3Objects {
+				contents = append(contents, types.Object{Key: aws.String(key), LastModified: aws.Time(old)})
+			}
+			s3Client := &fakeListObjectsV2{output: &s3.ListObjectsV2Output{Contents: contents}}
+			recorder := record.NewFakeRecorder(10)
+
+			verifier := NewConsistencyVerifier(k8sClient, s3Client, recorder, ConsistencyVerifierConfig{
+				SampleSize:          1,
+				MaxEntries:          10,
+				SettleTime:          time.Minute,
+				CheckerTimeout:      time.Second,
+				Namespace:           "kube-system",
+				MountpointNamespace: "mount-s3",
+				KubeletPath:         "/var/lib/kubelet",
+				CheckerImage:        "checker-image",
+				CheckerCommand:      "/bin/scality-csi-checker",
+			})
+			if err := verifier.RunVerification(context.Background()); err != nil {
+				t.Fatalf("RunVerification failed: %v", err)
+			}
+
+			// Checker Pod lists the source mount of the Mountpoint Pod on its node
+			if len(checkerPods) != 1 {
+				t.Fatalf("Expected 1 checker Pod, got %d", len(checkerPods))
+			}
+			checkerPod := checkerPods[0]
+			if checkerPod.Namespace != "kube-system" || checkerPod.Spec.NodeName != "node-1" {
+				t.Fatalf("Unexpected checker Pod placement: namespace %q, node %q", checkerPod.Namespace, checkerPod.Spec.NodeName)
+			}
+			if got := checkerPod.Spec.Volumes[0].HostPath.Path; got != "/var/lib/kubelet/plugins/s3.csi.scality.com/mnt/mp-1" {
+				t.Fatalf("Unexpected checker Pod host path: %q", got)
+			}
+
+			// Checker Pod is cleaned up
+			pods := &corev1.PodList{}
+			if err := k8sClient.List(context.Background(), pods, client.InNamespace("kube-system")); err != nil {
+				t.Fatalf("Failed to list Pods: %v", err)
+			}
+			if len(pods.Items) != 0 {
+				t.Fatalf("Expected checker Pod to be deleted, found %d Pods", len(pods.Items))
+			}
+
+			// S3 is listed with the prefix of the mount
+			if len(s3Client.inputs) != 1 || aws.ToString(s3Client.inputs[0].Prefix) != "data/" {
+				t.Fatalf("Expected a single S3 listing with prefix data/, got %v", s3Client.inputs)
+			}
+
+			select {
+			case event := <-recorder.Events:
+				if !tt.wantEvent {
+					t.Fatalf("Unexpected event: %s", event)
+				}
+				if !strings.Contains(event, EventReasonMountDivergence) || !strings.Contains(event, "b.txt") {
+					t.Fatalf("Unexpected event: %s", event)
+				}
+			default:
+				if tt.wantEvent {
+					t.Fatal("Expected a divergence event")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package csicontroller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+)
+
+// defaultServiceAccountName is the service account of Pods not setting one.
+const defaultServiceAccountName = "default"
+
+// credentialIdentity returns the identity Mountpoint accesses the bucket of `pv` with for `workloadPod`, recorded in
+// the `credentialIdentity` of MountpointS3PodAttachments so only workloads with the same identity share a Mountpoint
+// Pod:
+//   - `webIdentity:<namespace>/<service account>` for `authenticationSource: webIdentity`, the role is assumed with
+//     the service account token of the workload
+//   - `role:<role ARN>` for `authenticationSource: role`
+//   - `secret:<namespace>/<name>` for `authenticationSource: secret`
+//
+// It is empty for the driver credentials and `authenticationSource: file`, shared by all workloads of the node.
+func credentialIdentity(workloadPod *corev1.Pod, pv *corev1.PersistentVolume) string {
+	csi := pv.Spec.CSI
+	volumeAttributes := csi.VolumeAttributes
+	switch credentialprovider.AuthenticationSource(volumeAttributes[volumecontext.AuthenticationSource]) {
+	case credentialprovider.AuthenticationSourceWebIdentity:
+		serviceAccountName := workloadPod.Spec.ServiceAccountName
+		if serviceAccountName == "" {
+			serviceAccountName = defaultServiceAccountName
+		}
+		return "webIdentity:" + workloadPod.Namespace + "/" + serviceAccountName
+	case credentialprovider.AuthenticationSourceRole:
+		return "role:" + volumeAttributes[volumecontext.RoleARN]
+	case credentialprovider.AuthenticationSourceSecret:
+		switch {
+		case csi.NodePublishSecretRef != nil:
+			return "secret:" + csi.NodePublishSecretRef.Namespace + "/" + csi.NodePublishSecretRef.Name
+		case csi.NodeStageSecretRef != nil:
+			return "secret:" + csi.NodeStageSecretRef.Namespace + "/" + csi.NodeStageSecretRef.Name
+		case volumeAttributes[volumecontext.SecretName] != "":
+			// Secrets of inline ephemeral volumes are in the namespace of their workload
+			return "secret:" + workloadPod.Namespace + "/" + volumeAttributes[volumecontext.SecretName]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,316 @@
+package csicontroller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/mountreport"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+)
+
+// A DivergenceWatchdog periodically compares Mountpoint Pods, MountpointS3PodAttachments and the mounts reported by
+// node plugins, and lists their divergences in the [crdv2.S3ReconciliationReport] singleton, to catch leaks that
+// individual control loops miss.
+//
+// Mountpoint Pods, attachments and mounts change independently, so a divergence is only reported once two
+// consecutive checks found it. With auto-repair, orphan Mountpoint Pods are marked for unmounting and dangling
+// attachments are removed. Divergences of node mounts are only reported, as only node plugins can repair them.
+type DivergenceWatchdog struct {
+	client              client.Client
+	mountpointNamespace string
+	interval            time.Duration
+	autoRepair          bool
+	now                 func() time.Time
+
+	// suspected are the keys of the divergences found by the previous check.
+	suspected map[string]bool
+}
+
+// NewDivergenceWatchdog creates a new [DivergenceWatchdog] checking Mountpoint Pods of `mountpointNamespace` every
+// `interval`, and repairing divergences if `autoRepair` is true.
+func NewDivergenceWatchdog(client client.Client, mountpointNamespace string, interval time.Duration, autoRepair bool) *DivergenceWatchdog {
+	return &DivergenceWatchdog{
+		client:              client,
+		mountpointNamespace: mountpointNamespace,
+		interval:            interval,
+		autoRepair:          autoRepair,
+		now:                 time.Now,
+	}
+}
+
+// Start begins the periodic divergence checks.
+func (w *DivergenceWatchdog) Start(ctx context.Context) error {
+	log := logf.FromContext(ctx)
+	log.Info("Starting divergence watchdog", "interval", w.interval, "autoRepair", w.autoRepair)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Completed divergence watchdog")
+			return nil
+		case <-ticker.C:
+			if err := w.RunCheck(ctx); err != nil {
+				log.Error(err, "Failed to check divergences")
+				// Continue running even if the check fails
+			}
+		}
+	}
+}
+
+// divergenceCandidate is a divergence found by a check, with the repair to apply if it is confirmed.
+type divergenceCandidate struct {
+	crdv2.Divergence
+	repair func(ctx context.Context) error
+}
+
+// key identifies the divergence across checks.
+func (c *divergenceCandidate) key() string {
+	return c.Type + "/" + c.NodeName + "/" + c.MountpointPod + "/" + c.MountpointS3PodAttachment
+}
+
+// RunCheck finds divergences, repairs them if enabled, and writes them to the [crdv2.S3ReconciliationReport]
+// singleton, creating it if needed.
+func (w *DivergenceWatchdog) RunCheck(ctx context.Context) error {
+	log := logf.FromContext(ctx)
+
+	status, candidates, err := w.check(ctx)
+	if err != nil {
+		return err
+	}
+
+	suspected := make(map[string]bool, len(candidates))
+	counts := make(map[string]int)
+	for _, candidate := range candidates {
+		key := candidate.key()
+		suspected[key] = true
+		if !w.suspected[key] {
+			continue
+		}
+
+		if w.autoRepair && candidate.repair != nil {
+			if err := candidate.repair(ctx); err != nil {
+				log.Error(err, "Failed to repair divergence", "type", candidate.Type, "mountpointPod", candidate.MountpointPod,
+					"s3pa", candidate.MountpointS3PodAttachment)
+			} else {
+				candidate.Repaired = true
+				log.Info("Repaired divergence", "type", candidate.Type, "mountpointPod", candidate.MountpointPod,
+					"s3pa", candidate.MountpointS3PodAttachment)
+			}
+		}
+
+		counts[candidate.Type]++
+		status.DivergenceCount++
+		if len(status.Divergences) < crdv2.MaxReportedDivergences {
+			status.Divergences = append(status.Divergences, candidate.Divergence)
+		}
+	}
+	w.suspected = suspected
+
+	for _, divergenceType := range []string{crdv2.DivergenceOrphanMountpointPod, crdv2.DivergenceDanglingAttachment,
+		crdv2.DivergenceUnmountedSource, crdv2.DivergenceLeakedMount, crdv2.DivergenceTargetCountMismatch} {
+		divergences.WithLabelValues(divergenceType).Set(float64(counts[divergenceType]))
+	}
+	if status.DivergenceCount > 0 {
+		log.Info("Found divergences between Mountpoint Pods, attachments and node mounts", "count", status.DivergenceCount)
+	}
+
+	name := crdv2.InstanceObjectName(crdv2.S3ReconciliationReportName, mountpointCSIDriverName)
+	report := &crdv2.S3ReconciliationReport{}
+	err = w.client.Get(ctx, types.NamespacedName{Name: name}, report)
+	if apierrors.IsNotFound(err) {
+		report = &crdv2.S3ReconciliationReport{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		err = w.client.Create(ctx, report)
+	}
+	if err != nil {
+		return err
+	}
+
+	report.Status = *status
+	return w.client.Status().Update(ctx, report)
+}
+
+// check returns the counts of Mountpoint Pods, attachments and node mounts, and the divergences between them.
+func (w *DivergenceWatchdog) check(ctx context.Context) (*crdv2.S3ReconciliationReportStatus, []*divergenceCandidate, error) {
+	mpPodList := &corev1.PodList{}
+	if err := w.client.List(ctx, mpPodList, client.InNamespace(w.mountpointNamespace), client.HasLabels{mppod.LabelVolumeName}); err != nil {
+		return nil, nil, err
+	}
+	s3paList := &crdv2.MountpointS3PodAttachmentList{}
+	if err := w.client.List(ctx, s3paList); err != nil {
+		return nil, nil, err
+	}
+	nodeList := &corev1.NodeList{}
+	if err := w.client.List(ctx, nodeList); err != nil {
+		return nil, nil, err
+	}
+
+	now := w.now()
+	status := &crdv2.S3ReconciliationReportStatus{LastCheckTime: metav1.NewTime(now)}
+	var candidates []*divergenceCandidate
+
+	mpPods := make(map[string]*corev1.Pod, len(mpPodList.Items))
+	for i := range mpPodList.Items {
+		mpPod := &mpPodList.Items[i]
+		mpPods[mpPod.Name] = mpPod
+		status.MountpointPods++
+	}
+
+	// Workloads attached to each Mountpoint Pod, Mountpoint Pods referred to without workloads are lingering
+	workloads := make(map[string]int32)
+	for i := range s3paList.Items {
+		s3pa := &s3paList.Items[i]
+		for mpPodName, attachments := range s3pa.Spec.MountpointS3PodAttachments {
+			workloads[mpPodName] += int32(len(attachments))
+			status.WorkloadAttachments += int32(len(attachments))
+			if _, ok := mpPods[mpPodName]; ok {
+				continue
+			}
+			candidates = append(candidates, &divergenceCandidate{
+				Divergence: crdv2.Divergence{
+					Type:                      crdv2.DivergenceDanglingAttachment,
+					NodeName:                  s3pa.Spec.NodeName,
+					MountpointPod:             mpPodName,
+					MountpointS3PodAttachment: s3pa.Name,
+					Message:                   fmt.Sprintf("%d workload(s) are attached to Mountpoint Pod %s which does not exist", len(attachments), mpPodName),
+				},
+				repair: w.removeAttachment(s3pa.Name, mpPodName),
+			})
+		}
+	}
+
+	for _, mpPod := range mpPods {
+		if _, ok := workloads[mpPod.Name]; ok || !mpPod.DeletionTimestamp.IsZero() || mpPod.Annotations[mppod.AnnotationNeedsUnmount] == "true" {
+			continue
+		}
+		candidates = append(candidates, &divergenceCandidate{
+			Divergence: crdv2.Divergence{
+				Type:          crdv2.DivergenceOrphanMountpointPod,
+				NodeName:      mpPod.Spec.NodeName,
+				MountpointPod: mpPod.Name,
+				Message:       fmt.Sprintf("Mountpoint Pod %s is not referred to by any MountpointS3PodAttachment", mpPod.Name),
+			},
+			repair: w.markForUnmount(mpPod.Name),
+		})
+	}
+
+	for i := range nodeList.Items {
+		node := &nodeList.Items[i]
+		report, err := mountreport.FromNode(node)
+		if err != nil {
+			logf.FromContext(ctx).Error(err, "Ignoring invalid mount report", "node", node.Name)
+			continue
+		}
+		if report == nil || now.Sub(report.Time.Time) > mountreport.MaxAge {
+			continue
+		}
+		status.NodesReporting++
+		candidates = append(candidates, nodeDivergences(node.Name, report, mpPods, workloads, status)...)
+	}
+
+	slices.SortFunc(candidates, func(a, b *divergenceCandidate) int {
+		if a.key() < b.key() {
+			return -1
+		}
+		if a.key() > b.key() {
+			return 1
+		}
+		return 0
+	})
+	return status, candidates, nil
+}
+
+// nodeDivergences returns the divergences between the mount report of node `nodeName` and the Mountpoint Pods
+// scheduled on it, and adds the reported mounts to `status`.
+func nodeDivergences(nodeName string, report *mountreport.Report, mpPods map[string]*corev1.Pod, workloads map[string]int32,
+	status *crdv2.S3ReconciliationReportStatus) []*divergenceCandidate {
+	var candidates []*divergenceCandidate
+	for mpPodName, mounts := range report.MountpointPods {
+		if mounts.SourceMounted {
+			status.ReportedSourceMounts++
+		}
+		status.ReportedTargets += mounts.Targets
+
+		mpPod, ok := mpPods[mpPodName]
+		if !ok || mpPod.Spec.NodeName != nodeName {
+			if mounts.SourceMounted || mounts.Targets > 0 {
+				candidates = append(candidates, &divergenceCandidate{Divergence: crdv2.Divergence{
+					Type:          crdv2.DivergenceLeakedMount,
+					NodeName:      nodeName,
+					MountpointPod: mpPodName,
+					Message: fmt.Sprintf("Node plugin reports %d target(s) of Mountpoint Pod %s which does not exist on the node",
+						mounts.Targets, mpPodName),
+				}})
+			}
+			continue
+		}
+		if attached, ok := workloads[mpPodName]; ok && attached != mounts.Targets {
+			candidates = append(candidates, &divergenceCandidate{Divergence: crdv2.Divergence{
+				Type:          crdv2.DivergenceTargetCountMismatch,
+				NodeName:      nodeName,
+				MountpointPod: mpPodName,
+				Message: fmt.Sprintf("Node plugin reports %d target(s) of Mountpoint Pod %s with %d attached workload(s)",
+					mounts.Targets, mpPodName, attached),
+			}})
+		}
+	}
+
+	for _, mpPod := range mpPods {
+		if mpPod.Spec.NodeName != nodeName || mpPod.Status.Phase != corev1.PodRunning || workloads[mpPod.Name] == 0 ||
+			report.MountpointPods[mpPod.Name].SourceMounted {
+			continue
+		}
+		candidates = append(candidates, &divergenceCandidate{Divergence: crdv2.Divergence{
+			Type:          crdv2.DivergenceUnmountedSource,
+			NodeName:      nodeName,
+			MountpointPod: mpPod.Name,
+			Message: fmt.Sprintf("Node plugin does not report the source of running Mountpoint Pod %s with %d attached workload(s) as mounted",
+				mpPod.Name, workloads[mpPod.Name]),
+		}})
+	}
+	return candidates
+}
+
+// markForUnmount returns a repair marking Mountpoint Pod `mpPodName` for unmounting, so it is torn down.
+func (w *DivergenceWatchdog) markForUnmount(mpPodName string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		mpPod := &corev1.Pod{}
+		if err := w.client.Get(ctx, types.NamespacedName{Namespace: w.mountpointNamespace, Name: mpPodName}, mpPod); err != nil {
+			return err
+		}
+		patch := client.MergeFrom(mpPod.DeepCopy())
+		if mpPod.Annotations == nil {
+			mpPod.Annotations = make(map[string]string)
+		}
+		mpPod.Annotations[mppod.AnnotationNeedsUnmount] = "true"
+		return w.client.Patch(ctx, mpPod, patch)
+	}
+}
+
+// removeAttachment returns a repair removing the entry of Mountpoint Pod `mpPodName` from MountpointS3PodAttachment
+// `s3paName`, deleting the MountpointS3PodAttachment if no entry remains.
+func (w *DivergenceWatchdog) removeAttachment(s3paName, mpPodName string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		s3pa := &crdv2.MountpointS3PodAttachment{}
+		if err := w.client.Get(ctx, types.NamespacedName{Name: s3paName}, s3pa); err != nil {
+			return err
+		}
+		delete(s3pa.Spec.MountpointS3PodAttachments, mpPodName)
+		if len(s3pa.Spec.MountpointS3PodAttachments) == 0 {
+			return w.client.Delete(ctx, s3pa)
+		}
+		return w.client.Update(ctx, s3pa)
+	}
+}
@@ -0,0 +1,128 @@
+package csicontroller
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/mountreport"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestDivergenceWatchdog(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = crdv2.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	now := time.Date(2025, time.June, 7, 12, 0, 0, 0, time.UTC)
+
+	mpPod := func(name, nodeName string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testInventoryNamespace, Labels: map[string]string{mppod.LabelVolumeName: "pv"}},
+			Spec:       corev1.PodSpec{NodeName: nodeName},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	s3pa := func(name string, workloads map[string]int) *crdv2.MountpointS3PodAttachment {
+		attachments := make(map[string][]crdv2.WorkloadAttachment)
+		for mpPodName, count := range workloads {
+			attachments[mpPodName] = []crdv2.WorkloadAttachment{}
+			for range count {
+				attachments[mpPodName] = append(attachments[mpPodName], crdv2.WorkloadAttachment{WorkloadPodUID: "uid"})
+			}
+		}
+		return &crdv2.MountpointS3PodAttachment{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       crdv2.MountpointS3PodAttachmentSpec{NodeName: "node-1", MountpointS3PodAttachments: attachments},
+		}
+	}
+	node := func(name string, reportTime time.Time, mounts map[string]mountreport.MountpointPodMounts) *corev1.Node {
+		report, err := json.Marshal(mountreport.Report{MountpointPods: mounts, Time: metav1.NewTime(reportTime)})
+		assert.NoError(t, err)
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: map[string]string{mountreport.Annotation: string(report)}}}
+	}
+
+	k8sClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&crdv2.S3ReconciliationReport{}).
+		WithObjects(
+			mpPod("mp-ok", "node-1"),
+			mpPod("mp-lingering", "node-1"),
+			mpPod("mp-orphan", "node-1"),
+			mpPod("mp-mismatch", "node-1"),
+			mpPod("mp-unmounted", "node-1"),
+			mpPod("mp-node-2", "node-2"),
+			s3pa("s3pa-1", map[string]int{"mp-ok": 1, "mp-lingering": 0, "mp-mismatch": 2, "mp-unmounted": 1, "mp-node-2": 1}),
+			s3pa("s3pa-dangling", map[string]int{"mp-missing": 1}),
+			node("node-1", now.Add(-time.Minute), map[string]mountreport.MountpointPodMounts{
+				"mp-ok":        {SourceMounted: true, Targets: 1},
+				"mp-lingering": {SourceMounted: true},
+				"mp-mismatch":  {SourceMounted: true, Targets: 1},
+				"mp-leaked":    {SourceMounted: true},
+			}),
+			// Stale reports are ignored
+			node("node-2", now.Add(-2*mountreport.MaxAge), map[string]mountreport.MountpointPodMounts{}),
+		).
+		Build()
+
+	watchdog := NewDivergenceWatchdog(k8sClient, testInventoryNamespace, time.Minute, true)
+	watchdog.now = func() time.Time { return now }
+
+	getReport := func() crdv2.S3ReconciliationReportStatus {
+		report := &crdv2.S3ReconciliationReport{}
+		assert.NoError(t, k8sClient.Get(context.Background(), types.NamespacedName{Name: crdv2.S3ReconciliationReportName}, report))
+		return report.Status
+	}
+
+	// Divergences are only reported and repaired once confirmed by a second check
+	assert.NoError(t, watchdog.RunCheck(context.Background()))
+	status := getReport()
+	assert.Equals(t, int32(0), status.DivergenceCount)
+	assert.Equals(t, int32(6), status.MountpointPods)
+	assert.Equals(t, int32(6), status.WorkloadAttachments)
+	assert.Equals(t, int32(1), status.NodesReporting)
+	assert.Equals(t, int32(4), status.ReportedSourceMounts)
+	assert.Equals(t, int32(2), status.ReportedTargets)
+
+	assert.NoError(t, watchdog.RunCheck(context.Background()))
+	status = getReport()
+	assert.Equals(t, int32(5), status.DivergenceCount)
+	type found struct {
+		Type          string
+		MountpointPod string
+		Repaired      bool
+	}
+	var divergences []found
+	for _, divergence := range status.Divergences {
+		divergences = append(divergences, found{divergence.Type, divergence.MountpointPod, divergence.Repaired})
+	}
+	assert.Equals(t, []found{
+		{crdv2.DivergenceDanglingAttachment, "mp-missing", true},
+		{crdv2.DivergenceLeakedMount, "mp-leaked", false},
+		{crdv2.DivergenceOrphanMountpointPod, "mp-orphan", true},
+		{crdv2.DivergenceTargetCountMismatch, "mp-mismatch", false},
+		{crdv2.DivergenceUnmountedSource, "mp-unmounted", false},
+	}, divergences)
+
+	orphan := &corev1.Pod{}
+	assert.NoError(t, k8sClient.Get(context.Background(), types.NamespacedName{Namespace: testInventoryNamespace, Name: "mp-orphan"}, orphan))
+	assert.Equals(t, "true", orphan.Annotations[mppod.AnnotationNeedsUnmount])
+	err := k8sClient.Get(context.Background(), types.NamespacedName{Name: "s3pa-dangling"}, &crdv2.MountpointS3PodAttachment{})
+	if !apierrors.IsNotFound(err) {
+		t.Fatalf("Expected dangling MountpointS3PodAttachment to be deleted, got %v", err)
+	}
+
+	// Repaired divergences are gone from the next report
+	assert.NoError(t, watchdog.RunCheck(context.Background()))
+	assert.Equals(t, int32(3), getReport().DivergenceCount)
+}
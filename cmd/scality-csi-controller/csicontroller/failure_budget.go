@@ -0,0 +1,278 @@
+package csicontroller
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mounterror"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+)
+
+// PVC annotations set when mount failures of a volume exhaust its failure budget, see [mppod.Config.MountFailureBudget].
+const (
+	// AnnotationMountFailureEscalation summarizes the failures and their most likely cause.
+	// Removing it lets the controller create Mountpoint Pods for the volume again.
+	AnnotationMountFailureEscalation = constants.DriverName + "/mount-failure-escalation"
+	// AnnotationMountFailureConfig is a fingerprint of the PV configuration the failures happened with.
+	// The escalation is lifted automatically once the PV configuration changes.
+	AnnotationMountFailureConfig = constants.DriverName + "/mount-failure-config"
+)
+
+// Reasons of events emitted on PersistentVolumeClaims by the [Reconciler].
+const (
+	EventReasonMountFailureEscalated = "MountFailureEscalated"
+)
+
+// Root causes of Mountpoint failures, classified from their termination state.
+const (
+	FailureCauseOutOfMemory         = "OutOfMemory"
+	FailureCauseAccessDenied        = "AccessDenied"
+	FailureCauseInvalidCredentials  = "InvalidCredentials"
+	FailureCauseBucketNotFound      = "BucketNotFound"
+	FailureCauseTLSError            = "TLSError"
+	FailureCauseEndpointUnreachable = "EndpointUnreachable"
+	FailureCauseBinaryIntegrity     = "BinaryIntegrity"
+	FailureCauseUnknown             = "Unknown"
+)
+
+// failureCauses maps classifications of Mountpoint error output to their root cause.
+var failureCauses = map[mounterror.Classification]string{
+	mounterror.ClassificationBinary:         FailureCauseBinaryIntegrity,
+	mounterror.ClassificationCredentials:    FailureCauseInvalidCredentials,
+	mounterror.ClassificationAccessDenied:   FailureCauseAccessDenied,
+	mounterror.ClassificationBucketNotFound: FailureCauseBucketNotFound,
+	mounterror.ClassificationTLS:            FailureCauseTLSError,
+	mounterror.ClassificationEndpoint:       FailureCauseEndpointUnreachable,
+}
+
+// A mountFailure is a termination of a Mountpoint container with a non-zero exit code.
+type mountFailure struct {
+	at    time.Time
+	cause string
+}
+
+// mountFailures tracks recent Mountpoint failures per PersistentVolume.
+type mountFailures struct {
+	mu sync.Mutex
+	// byVolume holds failures keyed by their Mountpoint Pod and termination time, to count each one once
+	// even though Mountpoint Pods are reconciled many times.
+	byVolume map[string]map[string]mountFailure
+}
+
+func newMountFailures() *mountFailures {
+	return &mountFailures{byVolume: make(map[string]map[string]mountFailure)}
+}
+
+// record records `failure` with `key` for `volume`, prunes failures older than `window`,
+// and returns the failures of `volume` within `window`.
+func (f *mountFailures) record(volume, key string, failure mountFailure, window time.Duration, now time.Time) []mountFailure {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	failures, ok := f.byVolume[volume]
+	if !ok {
+		failures = make(map[string]mountFailure)
+		f.byVolume[volume] = failures
+	}
+	failures[key] = failure
+
+	var recent []mountFailure
+	for k, failure := range failures {
+		if now.Sub(failure.at) > window {
+			delete(failures, k)
+			continue
+		}
+		recent = append(recent, failure)
+	}
+	return recent
+}
+
+// reset forgets failures of `volume`.
+func (f *mountFailures) reset(volume string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.byVolume, volume)
+}
+
+// SetEventRecorder sets the recorder used to emit events on PersistentVolumeClaims whose mounts keep failing.
+func (r *Reconciler) SetEventRecorder(recorder record.EventRecorder) {
+	r.recorder = recorder
+}
+
+// recordMountpointFailure records the last failure of Mountpoint Pod `mpPod`, if any, and escalates to the PVC
+// of its volume if failures exhaust the failure budget.
+func (r *Reconciler) recordMountpointFailure(ctx context.Context, mpPod *corev1.Pod) error {
+	budget, window := r.mountpointPodConfig.MountFailureBudget, r.mountpointPodConfig.MountFailureWindow
+	if budget <= 0 || window <= 0 {
+		return nil
+	}
+
+	terminated := lastMountpointTermination(mpPod)
+	if terminated == nil || terminated.ExitCode == 0 {
+		return nil
+	}
+	volumeName := mpPod.Labels[mppod.LabelVolumeName]
+	if volumeName == "" {
+		return nil
+	}
+
+	now := time.Now()
+	failure := mountFailure{at: terminated.FinishedAt.Time, cause: classifyMountFailure(terminated)}
+	if failure.at.IsZero() {
+		failure.at = now
+	}
+	key := mpPod.Name + "/" + terminated.FinishedAt.UTC().Format(time.RFC3339)
+	failures := r.mountFailures.record(volumeName, key, failure, window, now)
+	if len(failures) < budget {
+		return nil
+	}
+
+	return r.escalateMountFailures(ctx, volumeName, failures)
+}
+
+// escalateMountFailures annotates the PVC bound to `volumeName` and emits an event on it, so no new Mountpoint Pods
+// are created for the volume until its configuration changes.
+func (r *Reconciler) escalateMountFailures(ctx context.Context, volumeName string, failures []mountFailure) error {
+	log := logf.FromContext(ctx).WithValues("pv", volumeName)
+
+	pv := &corev1.PersistentVolume{}
+	if err := r.Get(ctx, types.NamespacedName{Name: volumeName}, pv); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	claimRef := pv.Spec.ClaimRef
+	if claimRef == nil {
+		return nil
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: claimRef.Namespace, Name: claimRef.Name}, pvc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if _, escalated := pvc.Annotations[AnnotationMountFailureEscalation]; escalated {
+		return nil
+	}
+
+	cause := mostFrequentCause(failures)
+	summary := fmt.Sprintf("%s: Mountpoint failed %d times in %v", cause, len(failures), r.mountpointPodConfig.MountFailureWindow)
+
+	if pvc.Annotations == nil {
+		pvc.Annotations = make(map[string]string)
+	}
+	pvc.Annotations[AnnotationMountFailureEscalation] = summary
+	pvc.Annotations[AnnotationMountFailureConfig] = volumeConfigFingerprint(pv)
+	if err := r.Update(ctx, pvc); err != nil {
+		log.Error(err, "Failed to annotate PVC with mount failure escalation", "pvc", pvc.Name)
+		return err
+	}
+
+	mountFailureEscalationsTotal.WithLabelValues(cause).Inc()
+	log.Info("Mount failures exhausted the failure budget, not creating Mountpoint Pods until the PV changes",
+		"pvc", types.NamespacedName{Namespace: pvc.Namespace, Name: pvc.Name}, "cause", cause, "failures", len(failures))
+	if r.recorder != nil {
+		r.recorder.Eventf(pvc, corev1.EventTypeWarning, EventReasonMountFailureEscalated,
+			"%s. No new Mountpoint Pods are created for volume %s until its PersistentVolume changes or the %s annotation is removed",
+			summary, volumeName, AnnotationMountFailureEscalation)
+	}
+	return nil
+}
+
+// mountFailuresEscalated returns whether mount failures of `pv` were escalated to `pvc`, in which case no
+// Mountpoint Pods should be created for it. The escalation is lifted if the configuration of `pv` changed since.
+func (r *Reconciler) mountFailuresEscalated(ctx context.Context, pvc *corev1.PersistentVolumeClaim, pv *corev1.PersistentVolume, log logr.Logger) (bool, error) {
+	summary, escalated := pvc.Annotations[AnnotationMountFailureEscalation]
+	if !escalated {
+		return false, nil
+	}
+
+	if pvc.Annotations[AnnotationMountFailureConfig] == volumeConfigFingerprint(pv) {
+		log.Info("Not creating Mountpoint Pods, mount failures of the volume were escalated to its PVC", "escalation", summary)
+		return true, nil
+	}
+
+	// The PV changed, give the new configuration a fresh budget
+	delete(pvc.Annotations, AnnotationMountFailureEscalation)
+	delete(pvc.Annotations, AnnotationMountFailureConfig)
+	if err := r.Update(ctx, pvc); err != nil {
+		log.Error(err, "Failed to clear mount failure escalation of PVC")
+		return true, err
+	}
+	r.mountFailures.reset(pv.Name)
+	log.Info("PV configuration changed, cleared mount failure escalation of PVC")
+	return false, nil
+}
+
+// lastMountpointTermination returns the last termination state of the Mountpoint container of `mpPod`, if any.
+func lastMountpointTermination(mpPod *corev1.Pod) *corev1.ContainerStateTerminated {
+	for _, status := range mpPod.Status.ContainerStatuses {
+		if status.Name != mppod.ContainerName {
+			continue
+		}
+		if status.State.Terminated != nil {
+			return status.State.Terminated
+		}
+		return status.LastTerminationState.Terminated
+	}
+	return nil
+}
+
+// classifyMountFailure returns the most likely root cause of Mountpoint termination `terminated`.
+// Mountpoint containers report the tail of their logs as termination message on failures.
+func classifyMountFailure(terminated *corev1.ContainerStateTerminated) string {
+	if terminated.Reason == "OOMKilled" {
+		return FailureCauseOutOfMemory
+	}
+	if cause, ok := failureCauses[mounterror.Classify(terminated.Message)]; ok {
+		return cause
+	}
+	return FailureCauseUnknown
+}
+
+// mostFrequentCause returns the most frequent cause of `failures`, ties are broken alphabetically.
+func mostFrequentCause(failures []mountFailure) string {
+	counts := make(map[string]int)
+	for _, failure := range failures {
+		counts[failure.cause]++
+	}
+	causes := slices.Sorted(maps.Keys(counts))
+	best := causes[0]
+	for _, cause := range causes {
+		if counts[cause] > counts[best] {
+			best = cause
+		}
+	}
+	return best
+}
+
+// volumeConfigFingerprint returns a fingerprint of the configuration of `pv` Mountpoint depends on.
+func volumeConfigFingerprint(pv *corev1.PersistentVolume) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%q\n", pv.Spec.MountOptions)
+	if csi := pv.Spec.CSI; csi != nil {
+		for _, key := range slices.Sorted(maps.Keys(csi.VolumeAttributes)) {
+			fmt.Fprintf(h, "%q=%q\n", key, csi.VolumeAttributes[key])
+		}
+		if ref := csi.NodePublishSecretRef; ref != nil {
+			fmt.Fprintf(h, "secret=%s/%s\n", ref.Namespace, ref.Name)
+		}
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))[:16]
+}
@@ -0,0 +1,173 @@
+package csicontroller_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/scality/mountpoint-s3-csi-driver/cmd/scality-csi-controller/csicontroller"
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+)
+
+const testFailingMPPodName = "mp-failing"
+
+func withMountFailureBudget(budget int) func(*mppod.Config) {
+	return func(c *mppod.Config) {
+		c.MountFailureBudget = budget
+		c.MountFailureWindow = 10 * time.Minute
+	}
+}
+
+func createFailingMountpointPod(finishedAt time.Time, message string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testFailingMPPodName,
+			Namespace: mountpointNamespace,
+			Labels: map[string]string{
+				mppod.LabelCSIDriverVersion: testCSIDriverVersion,
+				mppod.LabelVolumeName:       testPVName,
+			},
+		},
+		Spec: corev1.PodSpec{NodeName: testNodeName},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: mppod.ContainerName,
+				LastTerminationState: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{
+						ExitCode:   1,
+						FinishedAt: metav1.NewTime(finishedAt),
+						Message:    message,
+					},
+				},
+			}},
+		},
+	}
+}
+
+// failMountpointPod updates the last termination of the failing Mountpoint Pod and reconciles it.
+func failMountpointPod(t *testing.T, reconciler *csicontroller.Reconciler, c client.Client, finishedAt time.Time, message string) {
+	t.Helper()
+	ctx := context.Background()
+
+	mpPod := &corev1.Pod{}
+	if err := c.Get(ctx, types.NamespacedName{Name: testFailingMPPodName, Namespace: mountpointNamespace}, mpPod); err != nil {
+		t.Fatalf("Failed to get Mountpoint Pod: %v", err)
+	}
+	mpPod.Status = createFailingMountpointPod(finishedAt, message).Status
+	if err := c.Status().Update(ctx, mpPod); err != nil {
+		t.Fatalf("Failed to update Mountpoint Pod status: %v", err)
+	}
+
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: testFailingMPPodName, Namespace: mountpointNamespace}})
+	if err != nil {
+		t.Fatalf("Failed to reconcile Mountpoint Pod: %v", err)
+	}
+}
+
+func getTestPVC(t *testing.T, c client.Client) *corev1.PersistentVolumeClaim {
+	t.Helper()
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := c.Get(context.Background(), types.NamespacedName{Name: testPVCName, Namespace: testNamespace}, pvc); err != nil {
+		t.Fatalf("Failed to get PVC: %v", err)
+	}
+	return pvc
+}
+
+func TestReconciler_MountFailureEscalation(t *testing.T) {
+	ctx := context.Background()
+	workload := createTestPod(testPodName, testNamespace, testNodeName, pvcVolumes())
+	reconciler, c := testReconcilerWithConfig(withMountFailureBudget(2),
+		createTestPV(testPVName, testPVCName, testNamespace),
+		createTestPVC(testPVCName, testNamespace, testPVName),
+		createFailingMountpointPod(time.Now().Add(-2*time.Minute), ""),
+		workload,
+	)
+	recorder := record.NewFakeRecorder(10)
+	reconciler.SetEventRecorder(recorder)
+
+	// The same failure reconciled twice only counts once
+	finishedAt := time.Now().Add(-time.Minute)
+	failMountpointPod(t, reconciler, c, finishedAt, "Error: Failed to create S3 client: AccessDenied")
+	failMountpointPod(t, reconciler, c, finishedAt, "Error: Failed to create S3 client: AccessDenied")
+	if _, escalated := getTestPVC(t, c).Annotations[csicontroller.AnnotationMountFailureEscalation]; escalated {
+		t.Fatalf("Expected no escalation within the failure budget")
+	}
+
+	failMountpointPod(t, reconciler, c, time.Now(), "Error: Failed to create S3 client: AccessDenied")
+	escalation := getTestPVC(t, c).Annotations[csicontroller.AnnotationMountFailureEscalation]
+	if !strings.HasPrefix(escalation, csicontroller.FailureCauseAccessDenied+":") {
+		t.Fatalf("Expected escalation caused by %s, got %q", csicontroller.FailureCauseAccessDenied, escalation)
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, csicontroller.EventReasonMountFailureEscalated) {
+			t.Errorf("Expected %s event, got %q", csicontroller.EventReasonMountFailureEscalated, event)
+		}
+	default:
+		t.Errorf("Expected %s event", csicontroller.EventReasonMountFailureEscalated)
+	}
+
+	// No Mountpoint Pods are created for the workload while escalated
+	workloadRequest := reconcile.Request{NamespacedName: types.NamespacedName{Name: testPodName, Namespace: testNamespace}}
+	if _, err := reconciler.Reconcile(ctx, workloadRequest); err != nil {
+		t.Fatalf("Failed to reconcile workload: %v", err)
+	}
+	s3paList := &crdv2.MountpointS3PodAttachmentList{}
+	if err := c.List(ctx, s3paList); err != nil {
+		t.Fatalf("Failed to list S3PodAttachments: %v", err)
+	}
+	if len(s3paList.Items) != 0 {
+		t.Fatalf("Expected no S3PodAttachments while escalated, got %d", len(s3paList.Items))
+	}
+
+	// Changing the PV lifts the escalation
+	pv := &corev1.PersistentVolume{}
+	if err := c.Get(ctx, types.NamespacedName{Name: testPVName}, pv); err != nil {
+		t.Fatalf("Failed to get PV: %v", err)
+	}
+	pv.Spec.MountOptions = []string{"allow-other"}
+	if err := c.Update(ctx, pv); err != nil {
+		t.Fatalf("Failed to update PV: %v", err)
+	}
+	if _, err := reconciler.Reconcile(ctx, workloadRequest); err != nil {
+		t.Fatalf("Failed to reconcile workload: %v", err)
+	}
+	pvc := getTestPVC(t, c)
+	if _, escalated := pvc.Annotations[csicontroller.AnnotationMountFailureEscalation]; escalated {
+		t.Errorf("Expected escalation to be lifted after PV change")
+	}
+	if _, ok := pvc.Annotations[csicontroller.AnnotationMountFailureConfig]; ok {
+		t.Errorf("Expected config fingerprint to be removed after PV change")
+	}
+	if err := c.List(ctx, s3paList); err != nil {
+		t.Fatalf("Failed to list S3PodAttachments: %v", err)
+	}
+	if len(s3paList.Items) != 1 {
+		t.Errorf("Expected an S3PodAttachment after escalation is lifted, got %d", len(s3paList.Items))
+	}
+}
+
+func TestReconciler_MountFailureBudgetDisabled(t *testing.T) {
+	reconciler, c := testReconciler(
+		createTestPV(testPVName, testPVCName, testNamespace),
+		createTestPVC(testPVCName, testNamespace, testPVName),
+		createFailingMountpointPod(time.Now(), ""),
+	)
+
+	for i := range 5 {
+		failMountpointPod(t, reconciler, c, time.Now().Add(time.Duration(i)*time.Second), "AccessDenied")
+	}
+	if _, escalated := getTestPVC(t, c).Annotations[csicontroller.AnnotationMountFailureEscalation]; escalated {
+		t.Errorf("Expected no escalation with the failure budget disabled")
+	}
+}
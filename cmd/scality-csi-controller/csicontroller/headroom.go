@@ -0,0 +1,378 @@
+package csicontroller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+)
+
+// Lifecycle events of Headroom Pods, counted by the `scality_csi_controller_headroom_pods_total` metric.
+const (
+	headroomPodCreated  = "created"
+	headroomPodAdopted  = "adopted"
+	headroomPodConsumed = "consumed"
+	headroomPodExpired  = "expired"
+)
+
+// Reasons of the [crdv2.ConditionHeadroomReserved] condition.
+const (
+	ReasonHeadroomReserved = "Reserved"
+	ReasonHeadroomConsumed = "Consumed"
+	ReasonHeadroomExpired  = "Expired"
+)
+
+// headroomRecheckInterval is how often workload Pods with reserved headroom are reconciled again, to release their
+// Headroom Pods as soon as their Mountpoint Pods are scheduled.
+const headroomRecheckInterval = 5 * time.Second
+
+// headroomPodEndRetention is how long the end of a Headroom Pod is remembered to count it only once.
+const headroomPodEndRetention = time.Hour
+
+// headroomPodEnds tracks Headroom Pods whose end, consumed or expired, was already counted, as the end of a Headroom
+// Pod can be observed by several reconciliations until it is gone.
+type headroomPodEnds struct {
+	mu    sync.Mutex
+	ended map[types.UID]time.Time
+}
+
+func newHeadroomPodEnds() *headroomPodEnds {
+	return &headroomPodEnds{ended: make(map[types.UID]time.Time)}
+}
+
+// record returns whether the end of Headroom Pod `uid` is observed for the first time.
+func (e *headroomPodEnds) record(uid types.UID, now time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for ended, at := range e.ended {
+		if now.Sub(at) > headroomPodEndRetention {
+			delete(e.ended, ended)
+		}
+	}
+	if _, ok := e.ended[uid]; ok {
+		return false
+	}
+	e.ended[uid] = now
+	return true
+}
+
+// reserveHeadroomForMountpointPods creates or adopts Headroom Pods for the volumes of `workloadPod`, which uses
+// [mppod.SchedulingGateReserveHeadroomForMountpointPod], then labels and ungates it so it is scheduled alongside them.
+// Unbound claims get no headroom, as their volume is unknown until the workload is scheduled.
+func (r *Reconciler) reserveHeadroomForMountpointPods(ctx context.Context, workloadPod *corev1.Pod) (reconcile.Result, error) {
+	log := logf.FromContext(ctx).WithValues("workloadPod", types.NamespacedName{Namespace: workloadPod.Namespace, Name: workloadPod.Name})
+
+	volumes, _, err := r.getWorkloadVolumes(ctx, workloadPod)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if r.mountpointPodConfig.Container.HeadroomImage == "" {
+		log.Info("No Headroom Pod image is configured, ungating workload Pod without reserving headroom")
+		volumes = nil
+	}
+
+	for _, vol := range volumes {
+		if err := r.createOrAdoptHeadroomPod(ctx, workloadPod, vol.pv, log); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	if len(volumes) > 0 {
+		mppod.LabelWorkloadPodForHeadroomPod(workloadPod)
+	}
+	mppod.UngateHeadroomSchedulingGateForWorkloadPod(workloadPod)
+	if err := r.Update(ctx, workloadPod); err != nil {
+		if apierrors.IsConflict(err) {
+			log.Info("Failed to ungate workload Pod due to resource conflict, requeuing")
+			return reconcile.Result{Requeue: true}, nil
+		}
+		log.Error(err, "Failed to ungate workload Pod")
+		return reconcile.Result{}, err
+	}
+
+	log.Info("Workload Pod ungated", "headroomPods", len(volumes))
+	return reconcile.Result{}, nil
+}
+
+// createOrAdoptHeadroomPod creates the Headroom Pod of `workloadPod` for `pv`, or adopts it if it already exists,
+// e.g. if the workload Pod could not be ungated in a previous reconciliation.
+func (r *Reconciler) createOrAdoptHeadroomPod(ctx context.Context, workloadPod *corev1.Pod, pv *corev1.PersistentVolume, log logr.Logger) error {
+	hrPod, err := r.mountpointPodCreator.HeadroomPod(workloadPod, pv)
+	if err != nil {
+		return err
+	}
+	log = log.WithValues("headroomPod", hrPod.Name, "pv", pv.Name)
+
+	err = r.Create(ctx, hrPod)
+	switch {
+	case err == nil:
+		headroomPodsTotal.WithLabelValues(headroomPodCreated).Inc()
+		log.Info("Headroom Pod created")
+		return nil
+	case !apierrors.IsAlreadyExists(err):
+		log.Error(err, "Failed to create Headroom Pod")
+		return err
+	}
+
+	existing, err := r.getMountpointPod(ctx, hrPod.Name)
+	if err != nil {
+		return err
+	}
+	if existing.Labels[mppod.LabelHeadroomForPod] != string(workloadPod.UID) || existing.DeletionTimestamp != nil {
+		return fmt.Errorf("headroom Pod %s exists for another workload or is being deleted", hrPod.Name)
+	}
+	headroomPodsTotal.WithLabelValues(headroomPodAdopted).Inc()
+	log.Info("Adopted existing Headroom Pod")
+	return nil
+}
+
+// releaseHeadroomPods deletes the Headroom Pods of scheduled `workloadPod` once they are no longer needed, that is
+// once the Mountpoint Pods of its volumes are scheduled or it is running, or once it terminated.
+// It returns whether headroom is still reserved for some of its volumes.
+func (r *Reconciler) releaseHeadroomPods(ctx context.Context, workloadPod *corev1.Pod, volumes []*workloadVolume) (bool, error) {
+	log := logf.FromContext(ctx).WithValues("workloadPod", types.NamespacedName{Namespace: workloadPod.Namespace, Name: workloadPod.Name})
+	workloadUID := string(workloadPod.UID)
+
+	reserved := false
+	for _, vol := range volumes {
+		hrPodName := mppod.HeadroomPodNameFor(workloadPod, vol.pv)
+		hrPodLog := log.WithValues("headroomPod", hrPodName, "pv", vol.pv.Name)
+
+		s3pa, err := r.findHeadroomS3PodAttachment(ctx, workloadUID, vol.pv.Name)
+		if err != nil {
+			return reserved, err
+		}
+
+		event, message := "", ""
+		switch {
+		case !isPodActive(workloadPod):
+			event, message = headroomPodExpired, "Workload Pod terminated before using the headroom"
+		case isPodRunning(workloadPod):
+			event, message = headroomPodConsumed, "Workload Pod is running"
+		default:
+			scheduled, err := r.workloadMountpointPodScheduled(ctx, s3pa, workloadUID)
+			if err != nil {
+				return reserved, err
+			}
+			if scheduled {
+				event, message = headroomPodConsumed, "Mountpoint Pod is scheduled"
+			}
+		}
+
+		if event == "" {
+			hrPod, err := r.getMountpointPod(ctx, hrPodName)
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return reserved, err
+			}
+			reserved = true
+			r.setHeadroomCondition(ctx, s3pa, metav1.ConditionTrue, ReasonHeadroomReserved,
+				fmt.Sprintf("Headroom Pod %s reserves capacity for the Mountpoint Pod", hrPod.Name), hrPodLog)
+			continue
+		}
+
+		hrPod, err := r.getMountpointPod(ctx, hrPodName)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return reserved, err
+		}
+		if err := r.deleteHeadroomPod(ctx, hrPod, event, hrPodLog); err != nil {
+			return reserved, err
+		}
+		reason := ReasonHeadroomConsumed
+		if event == headroomPodExpired {
+			reason = ReasonHeadroomExpired
+		}
+		r.setHeadroomCondition(ctx, s3pa, metav1.ConditionFalse, reason,
+			fmt.Sprintf("Headroom Pod %s released: %s", hrPod.Name, message), hrPodLog)
+	}
+
+	if reserved || !isPodActive(workloadPod) {
+		return reserved, nil
+	}
+
+	// Headroom Pods are released, the label used for their inter-pod affinity is no longer needed
+	if mppod.UnlabelWorkloadPodForHeadroomPod(workloadPod) {
+		if err := r.Update(ctx, workloadPod); err != nil && !apierrors.IsNotFound(err) {
+			if apierrors.IsConflict(err) {
+				return true, nil
+			}
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// reconcileHeadroomPod garbage collects Headroom Pod `hrPod` if its workload Pod is gone or it outlived
+// [mppod.Config.HeadroomPodTTL], and counts its end if it was preempted or deleted by others.
+func (r *Reconciler) reconcileHeadroomPod(ctx context.Context, hrPod *corev1.Pod) (reconcile.Result, error) {
+	log := logf.FromContext(ctx).WithValues("headroomPod", hrPod.Name)
+
+	if hrPod.DeletionTimestamp != nil {
+		event := headroomPodExpired
+		if isPreemptedByScheduler(hrPod) {
+			// Preempted by the Mountpoint Pod it reserved capacity for
+			event = headroomPodConsumed
+		}
+		if r.headroomPodEnds.record(hrPod.UID, time.Now()) {
+			headroomPodsTotal.WithLabelValues(event).Inc()
+			log.Info("Headroom Pod is being deleted", "event", event)
+		}
+		return reconcile.Result{}, nil
+	}
+
+	workloadPod, err := r.headroomWorkloadPod(ctx, hrPod)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if workloadPod == nil || !isPodActive(workloadPod) {
+		log.Info("Workload Pod of Headroom Pod is gone, deleting Headroom Pod")
+		return reconcile.Result{}, r.deleteHeadroomPod(ctx, hrPod, headroomPodExpired, log)
+	}
+
+	ttl := r.mountpointPodConfig.HeadroomPodTTL
+	if ttl <= 0 {
+		return reconcile.Result{}, nil
+	}
+	if remaining := time.Until(hrPod.CreationTimestamp.Add(ttl)); remaining > 0 {
+		return reconcile.Result{RequeueAfter: remaining}, nil
+	}
+
+	log.Info("Headroom Pod was not consumed in time, deleting it", "ttl", ttl)
+	if err := r.deleteHeadroomPod(ctx, hrPod, headroomPodExpired, log); err != nil {
+		return reconcile.Result{}, err
+	}
+	s3pa, err := r.findHeadroomS3PodAttachment(ctx, string(workloadPod.UID), hrPod.Labels[mppod.LabelHeadroomForVolume])
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	r.setHeadroomCondition(ctx, s3pa, metav1.ConditionFalse, ReasonHeadroomExpired,
+		fmt.Sprintf("Headroom Pod %s released: not consumed within %s", hrPod.Name, ttl), log)
+	return reconcile.Result{}, nil
+}
+
+// deleteHeadroomPod deletes `hrPod` and counts its end as `event`.
+func (r *Reconciler) deleteHeadroomPod(ctx context.Context, hrPod *corev1.Pod, event string, log logr.Logger) error {
+	if err := r.Delete(ctx, hrPod, client.Preconditions{UID: &hrPod.UID}); err != nil {
+		if apierrors.IsNotFound(err) || apierrors.IsConflict(err) {
+			return nil
+		}
+		log.Error(err, "Failed to delete Headroom Pod")
+		return err
+	}
+	if r.headroomPodEnds.record(hrPod.UID, time.Now()) {
+		headroomPodsTotal.WithLabelValues(event).Inc()
+	}
+	log.Info("Headroom Pod deleted", "event", event)
+	return nil
+}
+
+// headroomWorkloadPod returns the workload Pod of `hrPod`, or nil if it no longer exists.
+func (r *Reconciler) headroomWorkloadPod(ctx context.Context, hrPod *corev1.Pod) (*corev1.Pod, error) {
+	namespace, name, ok := strings.Cut(hrPod.Annotations[mppod.AnnotationHeadroomForWorkload], "/")
+	if !ok {
+		return nil, nil
+	}
+	workloadPod := &corev1.Pod{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, workloadPod); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	// A workload Pod recreated with the same name does not use the Headroom Pod
+	if string(workloadPod.UID) != hrPod.Labels[mppod.LabelHeadroomForPod] {
+		return nil, nil
+	}
+	return workloadPod, nil
+}
+
+// findHeadroomS3PodAttachment returns the MountpointS3PodAttachment of volume `pvName` attaching `workloadUID`,
+// or nil if the workload is not attached yet.
+func (r *Reconciler) findHeadroomS3PodAttachment(ctx context.Context, workloadUID, pvName string) (*crdv2.MountpointS3PodAttachment, error) {
+	s3paList := &crdv2.MountpointS3PodAttachmentList{}
+	if err := r.List(ctx, s3paList, client.MatchingFields{crdv2.FieldPersistentVolumeName: pvName}); err != nil {
+		return nil, fmt.Errorf("failed to list MountpointS3PodAttachments: %w", err)
+	}
+	for i := range s3paList.Items {
+		if s3paContainsWorkload(&s3paList.Items[i], workloadUID) {
+			return &s3paList.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// workloadMountpointPodScheduled returns whether the Mountpoint Pod serving `workloadUID` in `s3pa` is scheduled.
+func (r *Reconciler) workloadMountpointPodScheduled(ctx context.Context, s3pa *crdv2.MountpointS3PodAttachment, workloadUID string) (bool, error) {
+	if s3pa == nil {
+		return false, nil
+	}
+	for mpPodName, attachments := range s3pa.Spec.MountpointS3PodAttachments {
+		for _, attachment := range attachments {
+			if attachment.WorkloadPodUID != workloadUID {
+				continue
+			}
+			mpPod, err := r.getMountpointPod(ctx, mpPodName)
+			if err != nil {
+				if apierrors.IsNotFound(err) {
+					return false, nil
+				}
+				return false, err
+			}
+			scheduled, _, _ := isMountpointPodScheduled(mpPod)
+			return scheduled, nil
+		}
+	}
+	return false, nil
+}
+
+// setHeadroomCondition sets the [crdv2.ConditionHeadroomReserved] condition of `s3pa`, if any.
+// Failures are only logged, as the condition is informational and set again on the next change.
+func (r *Reconciler) setHeadroomCondition(ctx context.Context, s3pa *crdv2.MountpointS3PodAttachment, status metav1.ConditionStatus, reason, message string, log logr.Logger) {
+	if s3pa == nil {
+		return
+	}
+	changed := meta.SetStatusCondition(&s3pa.Status.Conditions, metav1.Condition{
+		Type:               crdv2.ConditionHeadroomReserved,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: s3pa.Generation,
+	})
+	if !changed {
+		return
+	}
+	if err := r.Status().Update(ctx, s3pa); err != nil {
+		log.V(debugLevel).Info("Failed to update headroom condition of MountpointS3PodAttachment", "s3pa", s3pa.Name, "error", err)
+	}
+}
+
+// isPreemptedByScheduler returns whether `pod` is being deleted because the scheduler preempted it.
+func isPreemptedByScheduler(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.DisruptionTarget && condition.Status == corev1.ConditionTrue {
+			return condition.Reason == corev1.PodReasonPreemptionByScheduler
+		}
+	}
+	return false
+}
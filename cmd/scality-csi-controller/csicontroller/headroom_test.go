@@ -0,0 +1,155 @@
+package csicontroller_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/scality/mountpoint-s3-csi-driver/cmd/scality-csi-controller/csicontroller"
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+const testPreemptingPriorityClassName = "mount-s3-preempting"
+
+func configureHeadroom(ttl time.Duration) func(*mppod.Config) {
+	return func(config *mppod.Config) {
+		config.PreemptingPriorityClassName = testPreemptingPriorityClassName
+		config.HeadroomPriorityClassName = "mount-s3-headroom"
+		config.Container.HeadroomImage = "pause:latest"
+		config.HeadroomPodTTL = ttl
+	}
+}
+
+func TestHeadroomPodLifecycle(t *testing.T) {
+	ctx := context.Background()
+	workload := createTestPod(testPodName, testNamespace, "", pvcVolumes())
+	workload.Spec.SchedulingGates = []corev1.PodSchedulingGate{{Name: mppod.SchedulingGateReserveHeadroomForMountpointPod}}
+	pv := createTestPV(testPVName, testPVCName, testNamespace)
+	reconciler, c := testReconcilerWithConfig(configureHeadroom(5*time.Minute), workload,
+		createTestPVC(testPVCName, testNamespace, testPVName), pv)
+
+	reconcilePod := func(namespace, name string) reconcile.Result {
+		t.Helper()
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}})
+		assert.NoError(t, err)
+		return result
+	}
+	getPod := func(namespace, name string) (*corev1.Pod, error) {
+		pod := &corev1.Pod{}
+		return pod, c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, pod)
+	}
+	hrPodName := mppod.HeadroomPodNameFor(workload, pv)
+
+	// The gated workload Pod gets a Headroom Pod, and is labelled and ungated
+	reconcilePod(testNamespace, testPodName)
+	hrPod, err := getPod(mountpointNamespace, hrPodName)
+	assert.NoError(t, err)
+	assert.Equals(t, testNamespace+"/"+testPodName, hrPod.Annotations[mppod.AnnotationHeadroomForWorkload])
+	workload, err = getPod(testNamespace, testPodName)
+	assert.NoError(t, err)
+	assert.Equals(t, 0, len(workload.Spec.SchedulingGates))
+	assert.Equals(t, true, mppod.WorkloadHasLabelPodForHeadroomPod(workload))
+
+	// The Headroom Pod is adopted if the workload Pod is reconciled again before being ungated
+	workload.Spec.SchedulingGates = []corev1.PodSchedulingGate{{Name: mppod.SchedulingGateReserveHeadroomForMountpointPod}}
+	assert.NoError(t, c.Update(ctx, workload))
+	reconcilePod(testNamespace, testPodName)
+	hrPods := &corev1.PodList{}
+	assert.NoError(t, c.List(ctx, hrPods, client.InNamespace(mountpointNamespace)))
+	assert.Equals(t, 1, len(hrPods.Items))
+
+	// Once the workload Pod is scheduled, its Mountpoint Pod preempts the Headroom Pod if needed
+	workload, err = getPod(testNamespace, testPodName)
+	assert.NoError(t, err)
+	workload.Spec.NodeName = testNodeName
+	assert.NoError(t, c.Update(ctx, workload))
+	if result := reconcilePod(testNamespace, testPodName); result.RequeueAfter == 0 {
+		t.Errorf("Expected the workload Pod to be reconciled again while headroom is reserved, got %+v", result)
+	}
+	mpPod, err := getPod(mountpointNamespace, mppod.MountpointPodNameFor(string(workload.UID), testPVName))
+	assert.NoError(t, err)
+	assert.Equals(t, testPreemptingPriorityClassName, mpPod.Spec.PriorityClassName)
+	expectHeadroomCondition(t, c, metav1.ConditionTrue, csicontroller.ReasonHeadroomReserved)
+
+	// The Headroom Pod is consumed as soon as the Mountpoint Pod is scheduled
+	mpPod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodScheduled, Status: corev1.ConditionTrue}}
+	assert.NoError(t, c.Status().Update(ctx, mpPod))
+	reconcilePod(testNamespace, testPodName)
+	if _, err := getPod(mountpointNamespace, hrPodName); !apierrors.IsNotFound(err) {
+		t.Fatalf("Expected the Headroom Pod to be deleted, got %v", err)
+	}
+	expectHeadroomCondition(t, c, metav1.ConditionFalse, csicontroller.ReasonHeadroomConsumed)
+	workload, err = getPod(testNamespace, testPodName)
+	assert.NoError(t, err)
+	assert.Equals(t, false, mppod.WorkloadHasLabelPodForHeadroomPod(workload))
+}
+
+func TestHeadroomPodGarbageCollection(t *testing.T) {
+	tests := []struct {
+		name        string
+		workload    bool
+		age         time.Duration
+		wantDeleted bool
+	}{
+		{name: "workload Pod is gone", age: time.Second, wantDeleted: true},
+		{name: "Headroom Pod expired", workload: true, age: 10 * time.Minute, wantDeleted: true},
+		{name: "Headroom Pod within its TTL", workload: true, age: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			workload := createTestPod(testPodName, testNamespace, testNodeName, pvcVolumes())
+			pv := createTestPV(testPVName, testPVCName, testNamespace)
+			hrPod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              mppod.HeadroomPodNameFor(workload, pv),
+					Namespace:         mountpointNamespace,
+					CreationTimestamp: metav1.NewTime(time.Now().Add(-tt.age)),
+					Labels: map[string]string{
+						mppod.LabelHeadroomForPod:    string(workload.UID),
+						mppod.LabelHeadroomForVolume: testPVName,
+					},
+					Annotations: map[string]string{mppod.AnnotationHeadroomForWorkload: testNamespace + "/" + testPodName},
+				},
+			}
+			objects := []client.Object{hrPod, pv}
+			if tt.workload {
+				objects = append(objects, workload)
+			}
+			reconciler, c := testReconcilerWithConfig(configureHeadroom(5*time.Minute), objects...)
+
+			result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: mountpointNamespace, Name: hrPod.Name}})
+			assert.NoError(t, err)
+
+			err = c.Get(ctx, types.NamespacedName{Namespace: mountpointNamespace, Name: hrPod.Name}, &corev1.Pod{})
+			assert.Equals(t, tt.wantDeleted, apierrors.IsNotFound(err))
+			if !tt.wantDeleted && result.RequeueAfter == 0 {
+				t.Errorf("Expected the Headroom Pod to be reconciled again once it expires, got %+v", result)
+			}
+		})
+	}
+}
+
+func expectHeadroomCondition(t *testing.T, c client.Client, status metav1.ConditionStatus, reason string) {
+	t.Helper()
+	s3paList := &crdv2.MountpointS3PodAttachmentList{}
+	assert.NoError(t, c.List(context.Background(), s3paList))
+	if len(s3paList.Items) != 1 {
+		t.Fatalf("Expected a single MountpointS3PodAttachment, got %d", len(s3paList.Items))
+	}
+	condition := meta.FindStatusCondition(s3paList.Items[0].Status.Conditions, crdv2.ConditionHeadroomReserved)
+	if condition == nil || condition.Status != status || condition.Reason != reason {
+		t.Fatalf("Expected %s condition to be %s with reason %s, got %+v", crdv2.ConditionHeadroomReserved, status, reason, condition)
+	}
+}
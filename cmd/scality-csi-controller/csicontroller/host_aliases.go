@@ -0,0 +1,92 @@
+package csicontroller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+)
+
+// Reasons of events emitted on the host aliases ConfigMap by the [HostAliasesReconciler].
+const (
+	EventReasonHostAliasesUpdated = "HostAliasesUpdated"
+	EventReasonInvalidHostAliases = "InvalidHostAliases"
+)
+
+// A HostAliasesReconciler keeps the host aliases of Mountpoint Pods in sync with a ConfigMap of `hostname: IP`
+// entries, see [mppod.HostAliases]. New Mountpoint Pods get the current host aliases. Existing Mountpoint Pods
+// with outdated host aliases are drained by the [MountpointUpgrader], and reported by the
+// `MountpointPodsUpToDate` condition of their MountpointS3PodAttachments until remounted.
+//
+// A missing ConfigMap removes all host aliases, an invalid one keeps the last valid host aliases.
+type HostAliasesReconciler struct {
+	client      client.Client
+	recorder    record.EventRecorder
+	configMap   types.NamespacedName
+	hostAliases *mppod.HostAliases
+}
+
+// NewHostAliasesReconciler creates a new [HostAliasesReconciler] updating `hostAliases` from `configMap`.
+func NewHostAliasesReconciler(client client.Client, recorder record.EventRecorder, configMap types.NamespacedName, hostAliases *mppod.HostAliases) *HostAliasesReconciler {
+	return &HostAliasesReconciler{
+		client:      client,
+		recorder:    recorder,
+		configMap:   configMap,
+		hostAliases: hostAliases,
+	}
+}
+
+// SetupWithManager configures the reconciler to run with given `mgr`, watching only the host aliases ConfigMap.
+func (r *HostAliasesReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(Name+"-host-aliases").
+		For(&corev1.ConfigMap{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(o client.Object) bool {
+			return o.GetNamespace() == r.configMap.Namespace && o.GetName() == r.configMap.Name
+		}))).
+		Complete(r)
+}
+
+// Reconcile updates the host aliases from the host aliases ConfigMap.
+func (r *HostAliasesReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	return reconcile.Result{}, r.Sync(ctx, r.client)
+}
+
+// Sync updates the host aliases from the host aliases ConfigMap read with `reader`. It is called before the
+// manager starts with an uncached reader, so the first Mountpoint Pods get the host aliases too.
+func (r *HostAliasesReconciler) Sync(ctx context.Context, reader client.Reader) error {
+	log := logf.FromContext(ctx).WithValues("configMap", r.configMap)
+
+	configMap := &corev1.ConfigMap{}
+	err := reader.Get(ctx, r.configMap, configMap)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	changed, err := r.hostAliases.Set(configMap.Data)
+	if err != nil {
+		log.Error(err, "Invalid host aliases, keeping the previous ones")
+		r.recorder.Eventf(configMap, corev1.EventTypeWarning, EventReasonInvalidHostAliases, "Invalid host aliases, keeping the previous ones: %v", err)
+		return nil
+	}
+	if !changed {
+		return nil
+	}
+
+	hostAliases := r.hostAliases.Get()
+	log.Info("Host aliases of Mountpoint Pods updated", "hostAliases", hostAliases)
+	if configMap.UID != "" {
+		r.recorder.Eventf(configMap, corev1.EventTypeNormal, EventReasonHostAliasesUpdated,
+			"%d host alias(es) applied to new Mountpoint Pods, existing Mountpoint Pods are drained", len(hostAliases))
+	}
+	return nil
+}
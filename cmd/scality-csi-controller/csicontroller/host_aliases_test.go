@@ -0,0 +1,84 @@
+package csicontroller_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/scality/mountpoint-s3-csi-driver/cmd/scality-csi-controller/csicontroller"
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+const testHostAliasesConfigMapName = "mount-s3-host-aliases"
+
+func TestHostAliasesReconciler(t *testing.T) {
+	ctx := context.Background()
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: testHostAliasesConfigMapName, Namespace: mountpointNamespace, UID: "host-aliases-uid"},
+		Data:       map[string]string{"s3.example.com": "10.0.0.1"},
+	}
+	hostAliases := mppod.NewHostAliases()
+	reconciler, c := testReconcilerWithConfig(func(config *mppod.Config) {
+		config.HostAliases = hostAliases
+	}, configMap,
+		createTestPod(testPodName, testNamespace, testNodeName, pvcVolumes()),
+		createTestPVC(testPVCName, testNamespace, testPVName),
+		createTestPV(testPVName, testPVCName, testNamespace))
+	recorder := record.NewFakeRecorder(10)
+	hostAliasesReconciler := csicontroller.NewHostAliasesReconciler(c, recorder,
+		types.NamespacedName{Namespace: mountpointNamespace, Name: testHostAliasesConfigMapName}, hostAliases)
+	upgrader := csicontroller.NewMountpointUpgrader(reconciler)
+
+	reconcileHostAliases := func() {
+		t.Helper()
+		_, err := hostAliasesReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: mountpointNamespace, Name: testHostAliasesConfigMapName}})
+		assert.NoError(t, err)
+	}
+	expectUpToDate := func(want bool) {
+		t.Helper()
+		assert.NoError(t, upgrader.RunUpgrade(ctx))
+		s3paList := &crdv2.MountpointS3PodAttachmentList{}
+		assert.NoError(t, c.List(ctx, s3paList))
+		condition := meta.FindStatusCondition(s3paList.Items[0].Status.Conditions, crdv2.ConditionMountpointPodsUpToDate)
+		if condition == nil || (condition.Status == metav1.ConditionTrue) != want {
+			t.Fatalf("Expected Mountpoint Pods up to date to be %t, got %+v", want, condition)
+		}
+	}
+
+	// Mountpoint Pods are created with the host aliases of the ConfigMap
+	assert.NoError(t, hostAliasesReconciler.Sync(ctx, c))
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: testPodName}})
+	assert.NoError(t, err)
+	mpPod := getOnlyMountpointPod(t, c)
+	assert.Equals(t, []corev1.HostAlias{{IP: "10.0.0.1", Hostnames: []string{"s3.example.com"}}}, mpPod.Spec.HostAliases)
+	expectUpToDate(true)
+	if event := <-recorder.Events; !strings.Contains(event, csicontroller.EventReasonHostAliasesUpdated) {
+		t.Errorf("Expected %s event, got %q", csicontroller.EventReasonHostAliasesUpdated, event)
+	}
+
+	// Invalid host aliases are reported and ignored
+	configMap.Data = map[string]string{"s3.example.com": "s3.internal"}
+	assert.NoError(t, c.Update(ctx, configMap))
+	reconcileHostAliases()
+	if event := <-recorder.Events; !strings.Contains(event, csicontroller.EventReasonInvalidHostAliases) {
+		t.Errorf("Expected %s event, got %q", csicontroller.EventReasonInvalidHostAliases, event)
+	}
+	expectUpToDate(true)
+
+	// Mountpoint Pods with outdated host aliases are drained
+	configMap.Data = map[string]string{"s3.example.com": "10.0.0.2"}
+	assert.NoError(t, c.Update(ctx, configMap))
+	reconcileHostAliases()
+	expectUpToDate(false)
+	mpPod = getOnlyMountpointPod(t, c)
+	assert.Equals(t, "true", mpPod.Annotations[mppod.AnnotationNoNewWorkload])
+}
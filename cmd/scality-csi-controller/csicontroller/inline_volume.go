@@ -0,0 +1,51 @@
+package csicontroller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+)
+
+// inlineVolume returns a PersistentVolume describing the inline CSI volume `vol` of `workloadPod`, or nil if
+// `vol` is not an allowed diagnostic mount or inline ephemeral volume.
+//
+// The returned PersistentVolume only exists in memory. It is named after `vol` and uses the volume ID kubelet
+// generates for inline volumes, so the node plugin finds the MountpointS3PodAttachment with the volume name
+// and ID it gets from `NodePublishVolume` as for any other volume.
+func (r *Reconciler) inlineVolume(ctx context.Context, workloadPod *corev1.Pod, vol corev1.Volume) *corev1.PersistentVolume {
+	inline := vol.CSI
+	if inline.Driver != mountpointCSIDriverName {
+		return nil
+	}
+
+	readOnly := inline.ReadOnly != nil && *inline.ReadOnly
+	if volumecontext.IsDiagnostic(inline.VolumeAttributes) {
+		if namespace := r.mountpointPodConfig.DiagnosticMountNamespace; namespace == "" || workloadPod.Namespace != namespace {
+			logf.FromContext(ctx).Info("Ignoring diagnostic mount outside of the diagnostic namespace",
+				"volumeName", vol.Name, "diagnosticNamespace", namespace)
+			return nil
+		}
+		readOnly = true
+	} else if !r.mountpointPodConfig.EphemeralVolumes {
+		logf.FromContext(ctx).Info("Ignoring inline ephemeral volume, inline ephemeral volumes are disabled", "volumeName", vol.Name)
+		return nil
+	}
+
+	return &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: vol.Name},
+		Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{
+					Driver:           inline.Driver,
+					VolumeHandle:     volumecontext.EphemeralVolumeID(string(workloadPod.UID), vol.Name),
+					ReadOnly:         readOnly,
+					VolumeAttributes: inline.VolumeAttributes,
+				},
+			},
+		},
+	}
+}
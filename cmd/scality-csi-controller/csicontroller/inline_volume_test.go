@@ -0,0 +1,149 @@
+package csicontroller_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+)
+
+func TestReconciler_DiagnosticMount(t *testing.T) {
+	diagnosticVolume := corev1.Volume{
+		Name: "diagnostic",
+		VolumeSource: corev1.VolumeSource{
+			CSI: &corev1.CSIVolumeSource{
+				Driver: constants.DriverName,
+				VolumeAttributes: map[string]string{
+					volumecontext.BucketName: "test-bucket",
+					volumecontext.Diagnostic: "true",
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name                string
+		diagnosticNamespace string
+		expectedS3PAs       int
+	}{
+		{
+			name:                "diagnostic mount in the diagnostic namespace",
+			diagnosticNamespace: testNamespace,
+			expectedS3PAs:       1,
+		},
+		{
+			name:                "diagnostic mount outside of the diagnostic namespace",
+			diagnosticNamespace: "kube-system",
+		},
+		{
+			name: "diagnostic mounts disabled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workloadPod := createTestPod(testPodName, testNamespace, testNodeName, []corev1.Volume{diagnosticVolume})
+			reconciler, fakeClient := testReconcilerWithConfig(func(config *mppod.Config) {
+				config.DiagnosticMountNamespace = tt.diagnosticNamespace
+			}, workloadPod)
+
+			_, err := reconciler.Reconcile(context.Background(), reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: testPodName, Namespace: testNamespace},
+			})
+			if err != nil {
+				t.Fatalf("Reconcile() failed: %v", err)
+			}
+
+			s3paList := &crdv2.MountpointS3PodAttachmentList{}
+			if err := fakeClient.List(context.Background(), s3paList); err != nil {
+				t.Fatalf("Failed to list S3PodAttachments: %v", err)
+			}
+			if len(s3paList.Items) != tt.expectedS3PAs {
+				t.Fatalf("Expected %d S3PodAttachments, got %d", tt.expectedS3PAs, len(s3paList.Items))
+			}
+			if tt.expectedS3PAs == 0 {
+				return
+			}
+
+			// The node plugin looks up the attachment with the inline volume name and the volume ID generated by kubelet
+			spec := s3paList.Items[0].Spec
+			if spec.PersistentVolumeName != diagnosticVolume.Name {
+				t.Errorf("Expected PersistentVolumeName %q, got %q", diagnosticVolume.Name, spec.PersistentVolumeName)
+			}
+			if want := volumecontext.EphemeralVolumeID(string(workloadPod.UID), diagnosticVolume.Name); spec.VolumeID != want {
+				t.Errorf("Expected VolumeID %q, got %q", want, spec.VolumeID)
+			}
+
+			mpPods := &corev1.PodList{}
+			if err := fakeClient.List(context.Background(), mpPods, client.InNamespace(mountpointNamespace)); err != nil {
+				t.Fatalf("Failed to list Mountpoint Pods: %v", err)
+			}
+			if len(mpPods.Items) != 1 {
+				t.Errorf("Expected 1 Mountpoint Pod, got %d", len(mpPods.Items))
+			}
+		})
+	}
+}
+
+func TestReconciler_InlineEphemeralVolume(t *testing.T) {
+	inlineVolume := corev1.Volume{
+		Name: "inline",
+		VolumeSource: corev1.VolumeSource{
+			CSI: &corev1.CSIVolumeSource{
+				Driver: constants.DriverName,
+				VolumeAttributes: map[string]string{
+					volumecontext.BucketName: "test-bucket",
+					volumecontext.Prefix:     "data/",
+					volumecontext.SecretName: "s3-credentials",
+				},
+			},
+		},
+	}
+
+	for _, enabled := range []bool{true, false} {
+		t.Run(fmt.Sprintf("ephemeral volumes enabled: %t", enabled), func(t *testing.T) {
+			workloadPod := createTestPod(testPodName, testNamespace, testNodeName, []corev1.Volume{inlineVolume})
+			reconciler, fakeClient := testReconcilerWithConfig(func(config *mppod.Config) {
+				config.EphemeralVolumes = enabled
+			}, workloadPod)
+
+			_, err := reconciler.Reconcile(context.Background(), reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: testPodName, Namespace: testNamespace},
+			})
+			if err != nil {
+				t.Fatalf("Reconcile() failed: %v", err)
+			}
+
+			s3paList := &crdv2.MountpointS3PodAttachmentList{}
+			if err := fakeClient.List(context.Background(), s3paList); err != nil {
+				t.Fatalf("Failed to list S3PodAttachments: %v", err)
+			}
+			if !enabled {
+				if len(s3paList.Items) != 0 {
+					t.Fatalf("Expected no S3PodAttachments with ephemeral volumes disabled, got %d", len(s3paList.Items))
+				}
+				return
+			}
+			if len(s3paList.Items) != 1 {
+				t.Fatalf("Expected 1 S3PodAttachment, got %d", len(s3paList.Items))
+			}
+
+			spec := s3paList.Items[0].Spec
+			if spec.PersistentVolumeName != inlineVolume.Name {
+				t.Errorf("Expected PersistentVolumeName %q, got %q", inlineVolume.Name, spec.PersistentVolumeName)
+			}
+			if want := volumecontext.EphemeralVolumeID(string(workloadPod.UID), inlineVolume.Name); spec.VolumeID != want {
+				t.Errorf("Expected VolumeID %q, got %q", want, spec.VolumeID)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package csicontroller
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+)
+
+// inventoryInterval is how often the [crdv2.S3VolumeInventory] is updated.
+const inventoryInterval = time.Minute
+
+// An InventoryReporter periodically summarizes the S3 volumes of the cluster and their Mountpoint Pods in the
+// [crdv2.S3VolumeInventory] singleton, for dashboards to get a fleet view from a single object.
+type InventoryReporter struct {
+	client              client.Client
+	mountpointNamespace string
+	now                 func() time.Time
+}
+
+// NewInventoryReporter creates a new [InventoryReporter] counting Mountpoint Pods of `mountpointNamespace`.
+func NewInventoryReporter(client client.Client, mountpointNamespace string) *InventoryReporter {
+	return &InventoryReporter{
+		client:              client,
+		mountpointNamespace: mountpointNamespace,
+		now:                 time.Now,
+	}
+}
+
+// Start begins the periodic update of the inventory.
+func (r *InventoryReporter) Start(ctx context.Context) error {
+	log := logf.FromContext(ctx)
+	log.Info("Starting S3 volume inventory reporter", "interval", inventoryInterval)
+
+	ticker := time.NewTicker(inventoryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Completed S3 volume inventory reporter")
+			return nil
+		case <-ticker.C:
+			if err := r.RunReport(ctx); err != nil {
+				log.Error(err, "Failed to update S3 volume inventory")
+				// Continue running even if the update fails
+			}
+		}
+	}
+}
+
+// RunReport computes the inventory and writes it to the [crdv2.S3VolumeInventory] singleton, creating it if needed.
+func (r *InventoryReporter) RunReport(ctx context.Context) error {
+	status, err := r.inventory(ctx)
+	if err != nil {
+		return err
+	}
+
+	name := crdv2.InstanceObjectName(crdv2.S3VolumeInventoryName, mountpointCSIDriverName)
+	inventory := &crdv2.S3VolumeInventory{}
+	err = r.client.Get(ctx, types.NamespacedName{Name: name}, inventory)
+	if apierrors.IsNotFound(err) {
+		inventory = &crdv2.S3VolumeInventory{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		err = r.client.Create(ctx, inventory)
+	}
+	if err != nil {
+		return err
+	}
+
+	inventory.Status = *status
+	return r.client.Status().Update(ctx, inventory)
+}
+
+// inventory returns the counts of S3 volumes and Mountpoint Pods of the cluster.
+func (r *InventoryReporter) inventory(ctx context.Context) (*crdv2.S3VolumeInventoryStatus, error) {
+	pvList := &corev1.PersistentVolumeList{}
+	if err := r.client.List(ctx, pvList); err != nil {
+		return nil, err
+	}
+	s3paList := &crdv2.MountpointS3PodAttachmentList{}
+	if err := r.client.List(ctx, s3paList); err != nil {
+		return nil, err
+	}
+	mpPodList := &corev1.PodList{}
+	if err := r.client.List(ctx, mpPodList, client.InNamespace(r.mountpointNamespace), client.HasLabels{mppod.LabelVolumeName}); err != nil {
+		return nil, err
+	}
+
+	s3pasByVolume := make(map[string][]*crdv2.MountpointS3PodAttachment)
+	for i := range s3paList.Items {
+		s3pa := &s3paList.Items[i]
+		s3pasByVolume[s3pa.Spec.PersistentVolumeName] = append(s3pasByVolume[s3pa.Spec.PersistentVolumeName], s3pa)
+	}
+
+	status := &crdv2.S3VolumeInventoryStatus{
+		ByStorageClass:     make(map[string]int32),
+		ByNamespace:        make(map[string]int32),
+		ByHealth:           make(map[string]int32),
+		CSIDriverVersions:  make(map[string]int32),
+		MountpointVersions: make(map[string]int32),
+		LastUpdateTime:     metav1.NewTime(r.now()),
+	}
+	for i := range pvList.Items {
+		pv := &pvList.Items[i]
+		if pv.Spec.CSI == nil || pv.Spec.CSI.Driver != mountpointCSIDriverName {
+			continue
+		}
+		status.Volumes++
+		status.ByStorageClass[inventoryKey(pv.Spec.StorageClassName)]++
+		namespace := ""
+		if pv.Spec.ClaimRef != nil {
+			namespace = pv.Spec.ClaimRef.Namespace
+		}
+		status.ByNamespace[inventoryKey(namespace)]++
+		status.ByHealth[volumeHealth(s3pasByVolume[pv.Name])]++
+	}
+
+	for i := range mpPodList.Items {
+		mpPod := &mpPodList.Items[i]
+		status.MountpointPods++
+		status.CSIDriverVersions[inventoryKey(mpPod.Labels[mppod.LabelCSIDriverVersion])]++
+		status.MountpointVersions[inventoryKey(mpPod.Labels[mppod.LabelMountpointVersion])]++
+	}
+	return status, nil
+}
+
+// volumeHealth returns the health state of a volume from its MountpointS3PodAttachments `s3pas`.
+func volumeHealth(s3pas []*crdv2.MountpointS3PodAttachment) string {
+	mounted := false
+	for _, s3pa := range s3pas {
+		if len(s3pa.Spec.MountpointS3PodAttachments) == 0 {
+			continue
+		}
+		mounted = true
+		if meta.IsStatusConditionTrue(s3pa.Status.Conditions, crdv2.ConditionMountError) ||
+			meta.IsStatusConditionFalse(s3pa.Status.Conditions, crdv2.ConditionMountpointReady) {
+			return crdv2.VolumeHealthDegraded
+		}
+	}
+	if !mounted {
+		return crdv2.VolumeHealthUnmounted
+	}
+	return crdv2.VolumeHealthHealthy
+}
+
+// inventoryKey returns the key `value` is counted under in the inventory.
+func inventoryKey(value string) string {
+	if value == "" {
+		return crdv2.InventoryKeyNone
+	}
+	return value
+}
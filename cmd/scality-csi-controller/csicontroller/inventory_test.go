@@ -0,0 +1,104 @@
+package csicontroller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+const testInventoryNamespace = "mount-s3"
+
+func TestInventoryReporter(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = crdv2.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	pv := func(name, storageClass, namespace, driver string) *corev1.PersistentVolume {
+		pv := &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: corev1.PersistentVolumeSpec{
+				StorageClassName:       storageClass,
+				PersistentVolumeSource: corev1.PersistentVolumeSource{CSI: &corev1.CSIPersistentVolumeSource{Driver: driver}},
+			},
+		}
+		if namespace != "" {
+			pv.Spec.ClaimRef = &corev1.ObjectReference{Namespace: namespace, Name: "claim-" + name}
+		}
+		return pv
+	}
+	s3pa := func(name, pvName string, conditions ...metav1.Condition) *crdv2.MountpointS3PodAttachment {
+		return &crdv2.MountpointS3PodAttachment{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: crdv2.MountpointS3PodAttachmentSpec{
+				PersistentVolumeName:       pvName,
+				MountpointS3PodAttachments: map[string][]crdv2.WorkloadAttachment{"mp-" + name: {{WorkloadPodUID: "uid-" + name}}},
+			},
+			Status: crdv2.MountpointS3PodAttachmentStatus{Conditions: conditions},
+		}
+	}
+	mpPod := func(name, csiDriverVersion, mountpointVersion string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testInventoryNamespace, Labels: map[string]string{
+			mppod.LabelVolumeName:        "pv",
+			mppod.LabelCSIDriverVersion:  csiDriverVersion,
+			mppod.LabelMountpointVersion: mountpointVersion,
+		}}}
+	}
+
+	k8sClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&crdv2.S3VolumeInventory{}).
+		WithObjects(
+			pv("pv-healthy", "s3-sc", "team-a", mountpointCSIDriverName),
+			pv("pv-degraded", "s3-sc", "team-b", mountpointCSIDriverName),
+			pv("pv-unmounted", "", "", mountpointCSIDriverName),
+			pv("pv-other-driver", "ebs", "team-a", "ebs.csi.aws.com"),
+			s3pa("s3pa-healthy", "pv-healthy", metav1.Condition{Type: crdv2.ConditionMountpointReady, Status: metav1.ConditionTrue}),
+			s3pa("s3pa-degraded", "pv-degraded", metav1.Condition{Type: crdv2.ConditionMountError, Status: metav1.ConditionTrue}),
+			mpPod("mp-1", "2.0.0", "1.19.0"),
+			mpPod("mp-2", "2.0.0", "1.19.0"),
+			mpPod("mp-3", "1.9.0", "1.18.0"),
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "headroom", Namespace: testInventoryNamespace}},
+		).
+		Build()
+
+	now := time.Date(2025, time.June, 7, 12, 0, 0, 0, time.UTC)
+	reporter := NewInventoryReporter(k8sClient, testInventoryNamespace)
+	reporter.now = func() time.Time { return now }
+
+	// Creates the inventory, then updates it
+	for range 2 {
+		assert.NoError(t, reporter.RunReport(context.Background()))
+	}
+
+	inventory := &crdv2.S3VolumeInventory{}
+	assert.NoError(t, k8sClient.Get(context.Background(), types.NamespacedName{Name: crdv2.S3VolumeInventoryName}, inventory))
+	status := inventory.Status
+	assert.Equals(t, int32(3), status.Volumes)
+	assert.Equals(t, map[string]int32{"s3-sc": 2, crdv2.InventoryKeyNone: 1}, status.ByStorageClass)
+	assert.Equals(t, map[string]int32{"team-a": 1, "team-b": 1, crdv2.InventoryKeyNone: 1}, status.ByNamespace)
+	assert.Equals(t, map[string]int32{
+		crdv2.VolumeHealthHealthy:   1,
+		crdv2.VolumeHealthDegraded:  1,
+		crdv2.VolumeHealthUnmounted: 1,
+	}, status.ByHealth)
+	assert.Equals(t, int32(3), status.MountpointPods)
+	assert.Equals(t, map[string]int32{"2.0.0": 2, "1.9.0": 1}, status.CSIDriverVersions)
+	assert.Equals(t, map[string]int32{"1.19.0": 2, "1.18.0": 1}, status.MountpointVersions)
+	if !status.LastUpdateTime.Time.Equal(now) {
+		t.Errorf("Expected last update time %v, got %v", now, status.LastUpdateTime.Time)
+	}
+
+	inventories := &crdv2.S3VolumeInventoryList{}
+	assert.NoError(t, k8sClient.List(context.Background(), inventories))
+	assert.Equals(t, 1, len(inventories.Items))
+}
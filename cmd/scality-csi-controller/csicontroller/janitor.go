@@ -0,0 +1,166 @@
+package csicontroller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+)
+
+const (
+	janitorInterval = 5 * time.Minute
+	// unreferencedMountpointPodGracePeriod is how long a Mountpoint Pod must exist before being deleted if no
+	// MountpointS3PodAttachment references it, to not race with the reconciler creating attachments.
+	unreferencedMountpointPodGracePeriod = 5 * time.Minute
+)
+
+// Reasons of cleanups of the [MountpointPodJanitor], used as label of the `scality_csi_controller_janitor_cleanups_total` metric.
+const (
+	janitorReasonNodeDeleted  = "NodeDeleted"
+	janitorReasonUnreferenced = "Unreferenced"
+)
+
+// A MountpointPodJanitor periodically cleans up Mountpoint Pods and MountpointS3PodAttachments whose owner is gone:
+//   - Mountpoint Pods and MountpointS3PodAttachments of deleted nodes are deleted, and the
+//     [mppod.FinalizerMountCleanup] finalizer of their Mountpoint Pods removed as no node plugin is left to clean them up.
+//   - Mountpoint Pods no MountpointS3PodAttachment references that never started or already stopped are deleted, e.g.
+//     after the reconciler failed to create their attachment and to delete them. Running ones are unmounted by the
+//     node plugin once unused.
+//   - The finalizer of deleted Mountpoint Pods that were never scheduled is removed.
+type MountpointPodJanitor struct {
+	reconciler *Reconciler
+}
+
+// NewMountpointPodJanitor creates a new [MountpointPodJanitor] for the Mountpoint Pods of `reconciler`.
+func NewMountpointPodJanitor(reconciler *Reconciler) *MountpointPodJanitor {
+	return &MountpointPodJanitor{reconciler: reconciler}
+}
+
+// Start runs the janitor every [janitorInterval] until `ctx` is cancelled.
+func (j *MountpointPodJanitor) Start(ctx context.Context) error {
+	log := logf.FromContext(ctx)
+	log.Info("Starting Mountpoint Pod janitor")
+
+	ticker := time.NewTicker(janitorInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Completed Mountpoint Pod janitor")
+			return nil
+		case <-ticker.C:
+			if err := j.RunCleanup(ctx, time.Now()); err != nil {
+				log.Error(err, "Failed to run Mountpoint Pod janitor")
+			}
+		}
+	}
+}
+
+// RunCleanup cleans up Mountpoint Pods and MountpointS3PodAttachments whose owner is gone at `now`.
+func (j *MountpointPodJanitor) RunCleanup(ctx context.Context, now time.Time) error {
+	nodeList := &corev1.NodeList{}
+	if err := j.reconciler.List(ctx, nodeList); err != nil {
+		return err
+	}
+	nodes := make(map[string]bool, len(nodeList.Items))
+	for _, node := range nodeList.Items {
+		nodes[node.Name] = true
+	}
+
+	s3paList := &crdv2.MountpointS3PodAttachmentList{}
+	if err := j.reconciler.List(ctx, s3paList); err != nil {
+		return err
+	}
+	referenced := make(map[string]bool)
+	var errs []error
+	for i := range s3paList.Items {
+		s3pa := &s3paList.Items[i]
+		for mpPodName := range s3pa.Spec.MountpointS3PodAttachments {
+			referenced[mpPodName] = true
+		}
+		if !nodes[s3pa.Spec.NodeName] && s3pa.DeletionTimestamp == nil {
+			errs = append(errs, j.deleteS3PodAttachment(ctx, s3pa))
+		}
+	}
+
+	podList := &corev1.PodList{}
+	if err := j.reconciler.List(ctx, podList, client.InNamespace(j.reconciler.mountpointPodConfig.Namespace)); err != nil {
+		return err
+	}
+	for i := range podList.Items {
+		mpPod := &podList.Items[i]
+		if mppod.IsHeadroomPod(mpPod) {
+			continue
+		}
+		scheduled := mpPod.Spec.NodeName != ""
+		switch {
+		case !scheduled && mpPod.DeletionTimestamp != nil:
+			// Unscheduled Mountpoint Pods never mounted their volume, and no node plugin watches them
+			errs = append(errs, j.reconciler.removeMountCleanupFinalizer(ctx, mpPod))
+		case scheduled && !nodes[mpPod.Spec.NodeName]:
+			errs = append(errs, j.cleanupMountpointPodOfDeletedNode(ctx, mpPod))
+		case !referenced[mpPod.Name] && mpPod.DeletionTimestamp == nil && mpPod.Status.Phase != corev1.PodRunning &&
+			now.Sub(mpPod.CreationTimestamp.Time) > unreferencedMountpointPodGracePeriod:
+			errs = append(errs, j.deleteUnreferencedMountpointPod(ctx, mpPod))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// deleteS3PodAttachment deletes `s3pa` of a deleted node.
+func (j *MountpointPodJanitor) deleteS3PodAttachment(ctx context.Context, s3pa *crdv2.MountpointS3PodAttachment) error {
+	log := logf.FromContext(ctx).WithValues("s3pa", s3pa.Name, "node", s3pa.Spec.NodeName)
+	if err := j.reconciler.Delete(ctx, s3pa); client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	log.Info("Deleted MountpointS3PodAttachment of deleted node")
+	janitorCleanupsTotal.WithLabelValues(janitorReasonNodeDeleted).Inc()
+	return nil
+}
+
+// cleanupMountpointPodOfDeletedNode deletes `mpPod` of a deleted node, and removes its finalizer.
+func (j *MountpointPodJanitor) cleanupMountpointPodOfDeletedNode(ctx context.Context, mpPod *corev1.Pod) error {
+	log := logf.FromContext(ctx).WithValues("mountpointPod", mpPod.Name, "node", mpPod.Spec.NodeName)
+
+	if mpPod.DeletionTimestamp == nil {
+		if err := j.reconciler.Delete(ctx, mpPod); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		log.Info("Deleted Mountpoint Pod of deleted node")
+		janitorCleanupsTotal.WithLabelValues(janitorReasonNodeDeleted).Inc()
+	}
+
+	if controllerutil.ContainsFinalizer(mpPod, mppod.FinalizerMountCleanup) {
+		if err := j.reconciler.removeMountCleanupFinalizer(ctx, mpPod); err != nil {
+			return err
+		}
+		log.Info("Removed finalizer of Mountpoint Pod of deleted node")
+	}
+	return nil
+}
+
+// deleteUnreferencedMountpointPod deletes `mpPod` no MountpointS3PodAttachment references.
+func (j *MountpointPodJanitor) deleteUnreferencedMountpointPod(ctx context.Context, mpPod *corev1.Pod) error {
+	log := logf.FromContext(ctx).WithValues("mountpointPod", mpPod.Name)
+	var err error
+	if mpPod.Spec.NodeName == "" {
+		err = j.reconciler.deleteUnmountedMountpointPod(ctx, mpPod)
+	} else {
+		err = j.reconciler.Delete(ctx, mpPod)
+	}
+	if client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	log.Info("Deleted Mountpoint Pod not referenced by any MountpointS3PodAttachment", "phase", mpPod.Status.Phase)
+	janitorCleanupsTotal.WithLabelValues(janitorReasonUnreferenced).Inc()
+	return nil
+}
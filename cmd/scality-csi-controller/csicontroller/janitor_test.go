@@ -0,0 +1,80 @@
+package csicontroller_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/scality/mountpoint-s3-csi-driver/cmd/scality-csi-controller/csicontroller"
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestMountpointPodJanitor(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: testNodeName}}
+
+	pendingUnreferenced := createTestVolumeMountpointPod("mp-pending-unreferenced")
+	pendingUnreferenced.Status.Phase = corev1.PodPending
+	pendingRecent := createTestVolumeMountpointPod("mp-pending-recent")
+	pendingRecent.Status.Phase = corev1.PodPending
+	pendingRecent.CreationTimestamp = metav1.NewTime(now.Add(-time.Minute))
+	runningUnreferenced := createTestVolumeMountpointPod("mp-running-unreferenced")
+	referenced := createTestVolumeMountpointPod("mp-referenced")
+	referenced.Status.Phase = corev1.PodPending
+	goneNode := createTestVolumeMountpointPod("mp-gone-node")
+	goneNode.Spec.NodeName = "gone-node"
+	unscheduled := createTestVolumeMountpointPod("mp-unscheduled")
+	unscheduled.Spec.NodeName = ""
+	unscheduled.Status.Phase = corev1.PodPending
+
+	s3pa := createTestS3PodAttachment("s3pa", "workload-uid", "mp-referenced")
+	goneNodeS3PA := createTestS3PodAttachment("s3pa-gone-node", "workload-uid", "mp-gone-node")
+	goneNodeS3PA.Spec.NodeName = "gone-node"
+
+	reconciler, c := testReconciler(node, pendingUnreferenced, pendingRecent, runningUnreferenced, referenced, goneNode,
+		unscheduled, s3pa, goneNodeS3PA)
+	// Deleted before being scheduled, no node plugin removes its finalizer
+	assert.NoError(t, c.Delete(ctx, unscheduled))
+
+	janitor := csicontroller.NewMountpointPodJanitor(reconciler)
+	assert.NoError(t, janitor.RunCleanup(ctx, now))
+
+	tests := []struct {
+		name        string
+		wantRemoved bool
+		wantDeleted bool
+	}{
+		{name: "mp-pending-unreferenced", wantDeleted: true},
+		{name: "mp-pending-recent"},
+		{name: "mp-running-unreferenced"},
+		{name: "mp-referenced"},
+		{name: "mp-gone-node", wantRemoved: true},
+		{name: "mp-unscheduled", wantRemoved: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mpPod := &corev1.Pod{}
+			err := c.Get(ctx, types.NamespacedName{Namespace: mountpointNamespace, Name: tt.name}, mpPod)
+			if tt.wantRemoved {
+				assert.Equals(t, true, apierrors.IsNotFound(err))
+				return
+			}
+			assert.NoError(t, err)
+			// Deleted Mountpoint Pods wait for the node plugin to remove their finalizer
+			assert.Equals(t, tt.wantDeleted, mpPod.DeletionTimestamp != nil)
+		})
+	}
+
+	assert.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(s3pa), &crdv2.MountpointS3PodAttachment{}))
+	err := c.Get(ctx, client.ObjectKeyFromObject(goneNodeS3PA), &crdv2.MountpointS3PodAttachment{})
+	assert.Equals(t, true, apierrors.IsNotFound(err))
+}
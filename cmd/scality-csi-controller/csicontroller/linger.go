@@ -0,0 +1,176 @@
+package csicontroller
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+)
+
+// lingerMountpointPod tries to retain Mountpoint Pod `mpPodName` that just lost its last workload,
+// so it can be reused if the same volume is republished on the node within the linger window.
+//
+// It returns true if the Mountpoint Pod is lingering, in which case it must be kept in the MountpointS3PodAttachment
+// with zero workloads. It returns false if lingering is disabled or the Mountpoint Pod is not running,
+// in which case the caller should proceed with the regular unmount flow.
+func (r *Reconciler) lingerMountpointPod(ctx context.Context, mpPodName string, log logr.Logger) (bool, error) {
+	if r.mountpointPodConfig.LingerDuration <= 0 {
+		return false, nil
+	}
+
+	mpPod, err := r.getMountpointPod(ctx, mpPodName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		log.Error(err, "Failed to get Mountpoint Pod", "mountpointPodName", mpPodName)
+		return false, err
+	}
+
+	// Outdated Mountpoint Pods cannot be reused, see [MountpointUpgrader]
+	if !isPodRunning(mpPod) || mpPod.Annotations[mppod.AnnotationNeedsUnmount] == "true" || r.mountpointPodOutdatedReason(mpPod) != "" {
+		return false, nil
+	}
+
+	if _, ok := mpPod.Annotations[mppod.AnnotationLingeringSince]; ok {
+		// Already lingering, keep the original start of the window
+		return true, nil
+	}
+
+	if mpPod.Annotations == nil {
+		mpPod.Annotations = make(map[string]string)
+	}
+	mpPod.Annotations[mppod.AnnotationLingeringSince] = time.Now().UTC().Format(time.RFC3339)
+	if err := r.Update(ctx, mpPod); err != nil {
+		log.Error(err, "Failed to mark Mountpoint Pod as lingering", "mountpointPodName", mpPodName)
+		return false, err
+	}
+
+	log.Info("Mountpoint Pod has zero workload UIDs. Lingering for reuse",
+		"mountpointPodName", mpPodName, "lingerDuration", r.mountpointPodConfig.LingerDuration)
+	return true, nil
+}
+
+// lingerRemaining returns how long `mpPod` has left in its linger window, and whether it is lingering at all.
+// A malformed timestamp is treated as an expired window.
+func (r *Reconciler) lingerRemaining(mpPod *corev1.Pod, now time.Time) (time.Duration, bool) {
+	value, ok := mpPod.Annotations[mppod.AnnotationLingeringSince]
+	if !ok {
+		return 0, false
+	}
+
+	since, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return 0, true
+	}
+
+	return max(since.Add(r.mountpointPodConfig.LingerDuration).Sub(now), 0), true
+}
+
+// reconcileLingeringMountpointPod requeues lingering Mountpoint Pod `mpPod` until its linger window expires,
+// and tears it down if it has not been reused by then.
+func (r *Reconciler) reconcileLingeringMountpointPod(ctx context.Context, mpPod *corev1.Pod) (reconcile.Result, error) {
+	log := logf.FromContext(ctx).WithValues("mountpointPod", mpPod.Name)
+
+	remaining, _ := r.lingerRemaining(mpPod, time.Now().UTC())
+	if remaining > 0 {
+		log.V(debugLevel).Info("Mountpoint Pod is lingering", "remaining", remaining)
+		return reconcile.Result{RequeueAfter: remaining}, nil
+	}
+
+	s3pa, err := r.findS3PodAttachmentForMountpointPod(ctx, mpPod)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if s3pa != nil && len(s3pa.Spec.MountpointS3PodAttachments[mpPod.Name]) > 0 {
+		// The Mountpoint Pod got a new workload, but we failed to clear the annotation at that time
+		log.Info("Lingering Mountpoint Pod has workloads, clearing lingering annotation")
+		return reconcile.Result{}, r.clearLingeringAnnotation(ctx, mpPod)
+	}
+
+	log.Info("Linger window of Mountpoint Pod expired without reuse. Adding " + mppod.AnnotationNeedsUnmount + " annotation")
+	if err := r.addNeedsUnmountAnnotation(ctx, mpPod.Name, log); err != nil {
+		return reconcile.Result{}, err
+	}
+	lingerMissesTotal.Inc()
+
+	if s3pa == nil {
+		return reconcile.Result{}, nil
+	}
+
+	delete(s3pa.Spec.MountpointS3PodAttachments, mpPod.Name)
+	if len(s3pa.Spec.MountpointS3PodAttachments) == 0 {
+		err = r.Delete(ctx, s3pa)
+		if apierrors.IsNotFound(err) {
+			err = nil
+		}
+	} else {
+		err = r.Update(ctx, s3pa)
+	}
+	if err != nil {
+		if apierrors.IsConflict(err) {
+			log.Info("Failed to remove expired Mountpoint Pod from MountpointS3PodAttachment due to resource conflict, requeuing")
+			return reconcile.Result{Requeue: true}, nil
+		}
+		log.Error(err, "Failed to remove expired Mountpoint Pod from MountpointS3PodAttachment", "s3pa", s3pa.Name)
+		return reconcile.Result{}, err
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// findS3PodAttachmentForMountpointPod returns the MountpointS3PodAttachment referencing `mpPod`, or nil if there is none.
+func (r *Reconciler) findS3PodAttachmentForMountpointPod(ctx context.Context, mpPod *corev1.Pod) (*crdv2.MountpointS3PodAttachment, error) {
+	s3paList := &crdv2.MountpointS3PodAttachmentList{}
+	if err := r.List(ctx, s3paList, client.MatchingFields{crdv2.FieldNodeName: mountpointPodNodeName(mpPod)}); err != nil {
+		return nil, err
+	}
+
+	for i := range s3paList.Items {
+		if _, ok := s3paList.Items[i].Spec.MountpointS3PodAttachments[mpPod.Name]; ok {
+			return &s3paList.Items[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// mountpointPodNodeName returns the node of `mpPod`, which is only set in its spec once scheduled, or else the node
+// it is assigned to through its node affinity.
+func mountpointPodNodeName(mpPod *corev1.Pod) string {
+	if mpPod.Spec.NodeName != "" {
+		return mpPod.Spec.NodeName
+	}
+	if mpPod.Spec.Affinity == nil || mpPod.Spec.Affinity.NodeAffinity == nil ||
+		mpPod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return ""
+	}
+	for _, term := range mpPod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		for _, field := range term.MatchFields {
+			if field.Key == metav1.ObjectNameField && field.Operator == corev1.NodeSelectorOpIn && len(field.Values) == 1 {
+				return field.Values[0]
+			}
+		}
+	}
+	return ""
+}
+
+// clearLingeringAnnotation removes the lingering annotation from `mpPod` after it got reused.
+func (r *Reconciler) clearLingeringAnnotation(ctx context.Context, mpPod *corev1.Pod) error {
+	if _, ok := mpPod.Annotations[mppod.AnnotationLingeringSince]; !ok {
+		return nil
+	}
+
+	delete(mpPod.Annotations, mppod.AnnotationLingeringSince)
+	return r.Update(ctx, mpPod)
+}
@@ -0,0 +1,207 @@
+package csicontroller_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+)
+
+const (
+	testMPPodName       = "mp-lingering"
+	testLingerDuration  = time.Minute
+	testLingerS3PAName  = "s3pa-linger"
+	testLingerWorkload2 = "test-pod-2"
+)
+
+func withLingerDuration(d time.Duration) func(*mppod.Config) {
+	return func(c *mppod.Config) { c.LingerDuration = d }
+}
+
+func createTestMountpointPod(annotations map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        testMPPodName,
+			Namespace:   mountpointNamespace,
+			Annotations: annotations,
+			Labels: map[string]string{
+				mppod.LabelCSIDriverVersion: testCSIDriverVersion,
+			},
+		},
+		Spec:   corev1.PodSpec{NodeName: testNodeName},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func pvcVolumes() []corev1.Volume {
+	return []corev1.Volume{
+		{
+			Name: "test-volume",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: testPVCName},
+			},
+		},
+	}
+}
+
+func getMountpointPod(t *testing.T, c client.Client) *corev1.Pod {
+	t.Helper()
+	mpPod := &corev1.Pod{}
+	if err := c.Get(context.Background(), types.NamespacedName{Name: testMPPodName, Namespace: mountpointNamespace}, mpPod); err != nil {
+		t.Fatalf("Failed to get Mountpoint Pod: %v", err)
+	}
+	return mpPod
+}
+
+func TestReconciler_LingerAfterLastWorkloadRemoved(t *testing.T) {
+	tests := []struct {
+		name              string
+		lingerDuration    time.Duration
+		expectLingering   bool
+		expectS3PAPresent bool
+	}{
+		{name: "linger disabled - unmounts immediately", lingerDuration: 0, expectLingering: false, expectS3PAPresent: false},
+		{name: "linger enabled - retains Mountpoint Pod", lingerDuration: testLingerDuration, expectLingering: true, expectS3PAPresent: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workload := createTestPod(testPodName, testNamespace, testNodeName, pvcVolumes())
+			workload.Status.Phase = corev1.PodSucceeded
+
+			reconciler, c := testReconcilerWithConfig(withLingerDuration(tt.lingerDuration),
+				workload,
+				createTestPVC(testPVCName, testNamespace, testPVName),
+				createTestPV(testPVName, testPVCName, testNamespace),
+				createTestMountpointPod(nil),
+				createTestS3PodAttachment(testLingerS3PAName, string(workload.UID), testMPPodName),
+			)
+
+			_, err := reconciler.Reconcile(context.Background(), reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: testPodName, Namespace: testNamespace},
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			mpPod := getMountpointPod(t, c)
+			_, lingering := mpPod.Annotations[mppod.AnnotationLingeringSince]
+			if lingering != tt.expectLingering {
+				t.Errorf("Expected lingering=%v, got annotations %v", tt.expectLingering, mpPod.Annotations)
+			}
+			if needsUnmount := mpPod.Annotations[mppod.AnnotationNeedsUnmount] == "true"; needsUnmount == tt.expectLingering {
+				t.Errorf("Expected needs-unmount=%v, got annotations %v", !tt.expectLingering, mpPod.Annotations)
+			}
+
+			s3pa := &crdv2.MountpointS3PodAttachment{}
+			err = c.Get(context.Background(), types.NamespacedName{Name: testLingerS3PAName}, s3pa)
+			if tt.expectS3PAPresent {
+				if err != nil {
+					t.Fatalf("Expected MountpointS3PodAttachment to be retained, got: %v", err)
+				}
+				if attachments, ok := s3pa.Spec.MountpointS3PodAttachments[testMPPodName]; !ok || len(attachments) != 0 {
+					t.Errorf("Expected lingering Mountpoint Pod entry with zero workloads, got %v", s3pa.Spec.MountpointS3PodAttachments)
+				}
+			} else if !apierrors.IsNotFound(err) {
+				t.Errorf("Expected MountpointS3PodAttachment to be deleted, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestReconciler_ReuseLingeringMountpointPod(t *testing.T) {
+	workload := createTestPod(testLingerWorkload2, testNamespace, testNodeName, pvcVolumes())
+	s3pa := createTestS3PodAttachment(testLingerS3PAName, "", "")
+	s3pa.Spec.MountpointS3PodAttachments[testMPPodName] = []crdv2.WorkloadAttachment{}
+
+	reconciler, c := testReconcilerWithConfig(withLingerDuration(testLingerDuration),
+		workload,
+		createTestPVC(testPVCName, testNamespace, testPVName),
+		createTestPV(testPVName, testPVCName, testNamespace),
+		createTestMountpointPod(map[string]string{
+			mppod.AnnotationLingeringSince: time.Now().UTC().Format(time.RFC3339),
+		}),
+		s3pa,
+	)
+
+	_, err := reconciler.Reconcile(context.Background(), reconcile.Request{
+		NamespacedName: types.NamespacedName{Name: testLingerWorkload2, Namespace: testNamespace},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	updated := &crdv2.MountpointS3PodAttachment{}
+	if err := c.Get(context.Background(), types.NamespacedName{Name: testLingerS3PAName}, updated); err != nil {
+		t.Fatalf("Failed to get MountpointS3PodAttachment: %v", err)
+	}
+	attachments := updated.Spec.MountpointS3PodAttachments[testMPPodName]
+	if len(attachments) != 1 || attachments[0].WorkloadPodUID != string(workload.UID) {
+		t.Errorf("Expected workload to be assigned to lingering Mountpoint Pod, got %v", updated.Spec.MountpointS3PodAttachments)
+	}
+
+	if _, ok := getMountpointPod(t, c).Annotations[mppod.AnnotationLingeringSince]; ok {
+		t.Error("Expected lingering annotation to be cleared after reuse")
+	}
+}
+
+func TestReconciler_ReconcileLingeringMountpointPod(t *testing.T) {
+	tests := []struct {
+		name               string
+		lingeringSince     time.Time
+		expectRequeueAfter bool
+		expectNeedsUnmount bool
+	}{
+		{name: "within linger window - requeues", lingeringSince: time.Now(), expectRequeueAfter: true},
+		{name: "linger window expired - unmounts", lingeringSince: time.Now().Add(-2 * testLingerDuration), expectNeedsUnmount: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s3pa := createTestS3PodAttachment(testLingerS3PAName, "", "")
+			s3pa.Spec.MountpointS3PodAttachments[testMPPodName] = []crdv2.WorkloadAttachment{}
+
+			reconciler, c := testReconcilerWithConfig(withLingerDuration(testLingerDuration),
+				createTestMountpointPod(map[string]string{
+					mppod.AnnotationLingeringSince: tt.lingeringSince.UTC().Format(time.RFC3339),
+				}),
+				s3pa,
+			)
+
+			result, err := reconciler.Reconcile(context.Background(), reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: testMPPodName, Namespace: mountpointNamespace},
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if (result.RequeueAfter > 0) != tt.expectRequeueAfter {
+				t.Errorf("Expected RequeueAfter set=%v, got %v", tt.expectRequeueAfter, result.RequeueAfter)
+			}
+			if result.RequeueAfter > testLingerDuration {
+				t.Errorf("Expected RequeueAfter within linger duration, got %v", result.RequeueAfter)
+			}
+
+			mpPod := getMountpointPod(t, c)
+			if needsUnmount := mpPod.Annotations[mppod.AnnotationNeedsUnmount] == "true"; needsUnmount != tt.expectNeedsUnmount {
+				t.Errorf("Expected needs-unmount=%v, got annotations %v", tt.expectNeedsUnmount, mpPod.Annotations)
+			}
+
+			err = c.Get(context.Background(), types.NamespacedName{Name: testLingerS3PAName}, &crdv2.MountpointS3PodAttachment{})
+			if tt.expectNeedsUnmount && !apierrors.IsNotFound(err) {
+				t.Errorf("Expected MountpointS3PodAttachment to be deleted, got: %v", err)
+			}
+			if !tt.expectNeedsUnmount && err != nil {
+				t.Errorf("Expected MountpointS3PodAttachment to be retained, got: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package csicontroller
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Metrics about lingering Mountpoint Pods, exposed through the controller-runtime metrics endpoint.
+// The cache hit rate of the linger window is `hits / (hits + misses)`.
+var (
+	lingerHitsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "scality_csi_controller_mountpoint_pod_linger_hits_total",
+		Help: "Number of times a lingering Mountpoint Pod was reused by a republished volume.",
+	})
+	lingerMissesTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "scality_csi_controller_mountpoint_pod_linger_misses_total",
+		Help: "Number of lingering Mountpoint Pods torn down after their linger window expired without reuse.",
+	})
+)
+
+// Metrics about consistency verifications of mounts against direct S3 listings, see [ConsistencyVerifier].
+var (
+	consistencyChecksTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "scality_csi_controller_consistency_checks_total",
+		Help: "Number of mount consistency verifications by result (consistent, divergent, skipped, error).",
+	}, []string{"result"})
+	consistencyDivergentEntriesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "scality_csi_controller_consistency_divergent_entries_total",
+		Help: "Number of entries found missing in the mount or in S3 by mount consistency verifications.",
+	}, []string{"missing_in"})
+)
+
+// Metrics about mount failures exhausting their failure budget, see [Reconciler.escalateMountFailures].
+var (
+	mountFailureEscalationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "scality_csi_controller_mount_failure_escalations_total",
+		Help: "Number of volumes whose Mountpoint failures exhausted the failure budget, by most likely cause.",
+	}, []string{"cause"})
+)
+
+// Metrics about read-only windows of volumes, see [ReadOnlyWindowScheduler].
+var (
+	readOnlyWindowVolumes = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "scality_csi_controller_read_only_window_volumes",
+		Help: "Number of volumes currently in a read-only window.",
+	})
+)
+
+// Metrics about the usage of prefixes of volumes, see [PrefixQuotaEnforcer].
+var (
+	prefixUsageBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "scality_csi_controller_prefix_usage_bytes",
+		Help: "Total size of the objects under the prefix of a volume.",
+	}, []string{"persistentvolume", "bucket", "prefix"})
+	prefixQuotaBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "scality_csi_controller_prefix_quota_bytes",
+		Help: "Storage requested by the claim of a volume of a prefix, its soft quota.",
+	}, []string{"persistentvolume", "bucket", "prefix"})
+)
+
+// Metrics about the rollout of Mountpoint Pods after upgrades, see [MountpointUpgrader].
+var (
+	outdatedMountpointPods = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "scality_csi_controller_outdated_mountpoint_pods",
+		Help: "Number of Mountpoint Pods running a previous version, drained until their workloads terminate.",
+	})
+)
+
+// Metrics about the rollout of mount options changes to workloads, see [RollingRemounter].
+var (
+	outdatedMountOptionsWorkloads = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "scality_csi_controller_outdated_mount_options_workloads",
+		Help: "Number of workloads mounted with previous mount options of their volume.",
+	})
+)
+
+// Metrics about the compliance of attachments with their maximum lifetime, see [AttachmentLifetimeEnforcer].
+var (
+	attachmentsExceedingMaxLifetime = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "scality_csi_controller_attachments_exceeding_max_lifetime",
+		Help: "Number of workloads attached to a Mountpoint Pod for longer than the maximum attachment lifetime.",
+	})
+	oldestAttachmentAgeSeconds = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "scality_csi_controller_oldest_attachment_age_seconds",
+		Help: "Age in seconds of the oldest Mountpoint Pod with attached workloads, the age of their credentials.",
+	})
+	attachmentLifetimeEvictionsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "scality_csi_controller_attachment_lifetime_evictions_total",
+		Help: "Number of workloads evicted to remount them with fresh credentials after the maximum attachment lifetime.",
+	})
+)
+
+// Metrics about Headroom Pods reserving capacity for Mountpoint Pods of workloads using the headroom scheduling gate.
+// Headroom Pods are consumed once their Mountpoint Pod is scheduled or their workload runs, and expire if their
+// workload terminates or they outlive their TTL first.
+var (
+	headroomPodsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "scality_csi_controller_headroom_pods_total",
+		Help: "Number of Headroom Pods by lifecycle event (created, adopted, consumed, expired).",
+	}, []string{"event"})
+)
+
+// Metrics about Mountpoint Pods recreated after they could not be scheduled or their images could not be pulled,
+// see [Reconciler.retryStuckMountpointPod].
+var (
+	mountpointPodSchedulingRetriesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "scality_csi_controller_mountpoint_pod_scheduling_retries_total",
+		Help: "Number of stuck Mountpoint Pods recreated, by cause (InsufficientResources, Unschedulable, ImagePull).",
+	}, []string{"cause"})
+)
+
+// Metrics about the S3 traffic of the buckets mounted by workload Pods, see [BucketMetricsCollector].
+// Rates of a bucket are divided evenly between the Pods consuming it, and labelled with the namespace and name of
+// each Pod so custom metrics adapters can associate them with the Pods.
+var (
+	workloadBucketRequestRate = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "scality_csi_controller_workload_bucket_requests_per_second",
+		Help: "Share of the S3 request rate of a mounted bucket attributed to a consuming workload Pod, as reported by UTAPI.",
+	}, []string{"namespace", "pod", "persistentvolume", "bucket"})
+	workloadBucketIncomingByteRate = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "scality_csi_controller_workload_bucket_incoming_bytes_per_second",
+		Help: "Share of the bytes written per second to a mounted bucket attributed to a consuming workload Pod, as reported by UTAPI.",
+	}, []string{"namespace", "pod", "persistentvolume", "bucket"})
+	workloadBucketOutgoingByteRate = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "scality_csi_controller_workload_bucket_outgoing_bytes_per_second",
+		Help: "Share of the bytes read per second from a mounted bucket attributed to a consuming workload Pod, as reported by UTAPI.",
+	}, []string{"namespace", "pod", "persistentvolume", "bucket"})
+)
+
+// Metrics about the usage of the buckets of bound volumes, see [VolumeUsageCollector].
+var (
+	volumeUsedBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "scality_csi_controller_volume_used_bytes",
+		Help: "Storage used by the bucket of a bound volume, as reported by UTAPI.",
+	}, []string{"namespace", "persistentvolumeclaim", "persistentvolume", "bucket"})
+	volumeObjects = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "scality_csi_controller_volume_objects",
+		Help: "Number of objects in the bucket of a bound volume, as reported by UTAPI.",
+	}, []string{"namespace", "persistentvolumeclaim", "persistentvolume", "bucket"})
+	volumeRequestRate = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "scality_csi_controller_volume_requests_per_second",
+		Help: "S3 request rate of the bucket of a bound volume, as reported by UTAPI.",
+	}, []string{"namespace", "persistentvolumeclaim", "persistentvolume", "bucket"})
+)
+
+// Metrics about divergences between Mountpoint Pods, attachments and node mounts, see [DivergenceWatchdog].
+var (
+	divergences = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "scality_csi_controller_divergences",
+		Help: "Number of divergences found by the last divergence check, by type.",
+	}, []string{"type"})
+)
+
+// Metrics about Mountpoint Pods and attachments whose owner is gone, see [MountpointPodJanitor].
+var (
+	janitorCleanupsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "scality_csi_controller_janitor_cleanups_total",
+		Help: "Number of Mountpoint Pods and MountpointS3PodAttachments deleted by the janitor, by reason (NodeDeleted, Unreferenced).",
+	}, []string{"reason"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(lingerHitsTotal, lingerMissesTotal, consistencyChecksTotal, consistencyDivergentEntriesTotal,
+		mountFailureEscalationsTotal, readOnlyWindowVolumes, prefixUsageBytes, prefixQuotaBytes, outdatedMountpointPods, outdatedMountOptionsWorkloads, attachmentsExceedingMaxLifetime,
+		oldestAttachmentAgeSeconds, attachmentLifetimeEvictionsTotal, headroomPodsTotal, mountpointPodSchedulingRetriesTotal,
+		workloadBucketRequestRate, workloadBucketIncomingByteRate, workloadBucketOutgoingByteRate, volumeUsedBytes, volumeObjects, volumeRequestRate,
+		divergences, janitorCleanupsTotal)
+}
@@ -0,0 +1,170 @@
+package csicontroller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+)
+
+// Reasons of the [crdv2.ConditionMountpointPodsUpToDate] condition.
+const (
+	ReasonMountpointPodsUpToDate = "UpToDate"
+	ReasonMountpointPodsDraining = "Draining"
+)
+
+// upgradeInterval is how often Mountpoint Pods are checked against the current versions.
+const upgradeInterval = time.Minute
+
+// A MountpointUpgrader rolls Mountpoint Pods out to the current version of Mountpoint and the CSI Driver
+// after an upgrade of the driver, and to the current host aliases after they change.
+//
+// Outdated Mountpoint Pods are not restarted, as that would break the mounts of their workloads. Instead, they
+// are annotated with [mppod.AnnotationNoNewWorkload], so new workloads get a new Mountpoint Pod, and they are
+// unmounted once their last workload terminates. Progress is reported with the
+// [crdv2.ConditionMountpointPodsUpToDate] condition of each MountpointS3PodAttachment.
+type MountpointUpgrader struct {
+	reconciler *Reconciler
+}
+
+// NewMountpointUpgrader creates a new [MountpointUpgrader].
+func NewMountpointUpgrader(reconciler *Reconciler) *MountpointUpgrader {
+	return &MountpointUpgrader{
+		reconciler: reconciler,
+	}
+}
+
+// Start begins the periodic rollout of Mountpoint Pods.
+func (u *MountpointUpgrader) Start(ctx context.Context) error {
+	log := logf.FromContext(ctx)
+	log.Info("Starting Mountpoint Pod upgrader", "interval", upgradeInterval)
+
+	ticker := time.NewTicker(upgradeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Completed Mountpoint Pod upgrader")
+			return nil
+		case <-ticker.C:
+			if err := u.RunUpgrade(ctx); err != nil {
+				log.Error(err, "Failed to upgrade Mountpoint Pods")
+				// Continue running even if the rollout fails
+			}
+		}
+	}
+}
+
+// RunUpgrade drains outdated Mountpoint Pods of all MountpointS3PodAttachments and updates their conditions.
+func (u *MountpointUpgrader) RunUpgrade(ctx context.Context) error {
+	s3paList := &crdv2.MountpointS3PodAttachmentList{}
+	if err := u.reconciler.List(ctx, s3paList); err != nil {
+		return err
+	}
+
+	total := 0
+	var errs []error
+	for i := range s3paList.Items {
+		outdated, err := u.upgrade(ctx, &s3paList.Items[i])
+		if err != nil {
+			errs = append(errs, err)
+		}
+		total += outdated
+	}
+	outdatedMountpointPods.Set(float64(total))
+	return errors.Join(errs...)
+}
+
+// upgrade drains outdated Mountpoint Pods of `s3pa`, updates its condition and returns the number of outdated
+// Mountpoint Pods.
+func (u *MountpointUpgrader) upgrade(ctx context.Context, s3pa *crdv2.MountpointS3PodAttachment) (int, error) {
+	log := logf.FromContext(ctx).WithValues("s3pa", s3pa.Name)
+
+	outdated, workloads := 0, 0
+	for mpPodName, attachments := range s3pa.Spec.MountpointS3PodAttachments {
+		mpPod, err := u.reconciler.getMountpointPod(ctx, mpPodName)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return outdated, err
+		}
+
+		reason := u.reconciler.mountpointPodOutdatedReason(mpPod)
+		if reason == "" {
+			continue
+		}
+		outdated++
+		workloads += len(attachments)
+
+		if mpPod.Annotations[mppod.AnnotationNoNewWorkload] == "true" {
+			continue
+		}
+		patch := client.MergeFrom(mpPod.DeepCopy())
+		if mpPod.Annotations == nil {
+			mpPod.Annotations = make(map[string]string)
+		}
+		mpPod.Annotations[mppod.AnnotationNoNewWorkload] = "true"
+		if err := u.reconciler.Patch(ctx, mpPod, patch); err != nil {
+			return outdated, err
+		}
+		log.Info("Draining outdated Mountpoint Pod", "mountpointPodName", mpPodName, "reason", reason,
+			"workloads", len(attachments))
+	}
+
+	condition := metav1.Condition{
+		Type:               crdv2.ConditionMountpointPodsUpToDate,
+		Status:             metav1.ConditionTrue,
+		Reason:             ReasonMountpointPodsUpToDate,
+		Message:            "All Mountpoint Pods run the current version",
+		ObservedGeneration: s3pa.Generation,
+	}
+	if outdated > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ReasonMountpointPodsDraining
+		condition.Message = fmt.Sprintf("%d outdated Mountpoint Pod(s) receive no new workloads, waiting for %d workload(s) to terminate",
+			outdated, workloads)
+	}
+	if !meta.SetStatusCondition(&s3pa.Status.Conditions, condition) {
+		return outdated, nil
+	}
+	if err := u.reconciler.Status().Update(ctx, s3pa); err != nil {
+		if apierrors.IsConflict(err) || apierrors.IsNotFound(err) {
+			// Updated in the next run
+			return outdated, nil
+		}
+		return outdated, err
+	}
+	return outdated, nil
+}
+
+// mountpointPodOutdatedReason returns why `mpPod` does not run the current version of Mountpoint or the CSI Driver,
+// or does not have the current host aliases, or an empty string if it is up to date.
+func (r *Reconciler) mountpointPodOutdatedReason(mpPod *corev1.Pod) string {
+	if version := mpPod.Labels[mppod.LabelCSIDriverVersion]; version != r.mountpointPodConfig.CSIDriverVersion {
+		return fmt.Sprintf("created by CSI Driver version %q, current version is %q", version, r.mountpointPodConfig.CSIDriverVersion)
+	}
+	if version, ok := mpPod.Labels[mppod.LabelMountpointVersion]; ok && version != r.mountpointPodConfig.MountpointVersion {
+		return fmt.Sprintf("runs Mountpoint version %q, current version is %q", version, r.mountpointPodConfig.MountpointVersion)
+	}
+	for _, container := range mpPod.Spec.Containers {
+		if container.Name == mppod.ContainerName && container.Image != r.mountpointPodConfig.Container.Image {
+			return fmt.Sprintf("runs image %q, current image is %q", container.Image, r.mountpointPodConfig.Container.Image)
+		}
+	}
+	if hostAliases := r.mountpointPodConfig.HostAliases; hostAliases != nil && !hostAliases.UpToDate(mpPod) {
+		return "has outdated host aliases"
+	}
+	return ""
+}
@@ -0,0 +1,120 @@
+package csicontroller_test
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/scality/mountpoint-s3-csi-driver/cmd/scality-csi-controller/csicontroller"
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+)
+
+func TestMountpointUpgrader(t *testing.T) {
+	ctx := context.Background()
+	volumes := []corev1.Volume{{
+		Name: "test-volume",
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: testPVCName},
+		},
+	}}
+	oldWorkload := createTestPod("workload-1", testNamespace, testNodeName, volumes)
+	newWorkload := createTestPod("workload-2", testNamespace, testNodeName, volumes)
+
+	reconciler, c := testReconciler(oldWorkload, newWorkload,
+		createTestPVC(testPVCName, testNamespace, testPVName),
+		createTestPV(testPVName, testPVCName, testNamespace))
+	upgrader := csicontroller.NewMountpointUpgrader(reconciler)
+
+	reconcilePod := func(pod *corev1.Pod) {
+		t.Helper()
+		if _, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}}); err != nil {
+			t.Fatalf("Failed to reconcile %s: %v", pod.Name, err)
+		}
+	}
+	getS3PA := func() *crdv2.MountpointS3PodAttachment {
+		t.Helper()
+		s3paList := &crdv2.MountpointS3PodAttachmentList{}
+		if err := c.List(ctx, s3paList); err != nil {
+			t.Fatalf("Failed to list MountpointS3PodAttachments: %v", err)
+		}
+		if len(s3paList.Items) != 1 {
+			t.Fatalf("Expected a single MountpointS3PodAttachment, got %d", len(s3paList.Items))
+		}
+		return &s3paList.Items[0]
+	}
+	getMountpointPod := func(name string) *corev1.Pod {
+		t.Helper()
+		mpPod := &corev1.Pod{}
+		if err := c.Get(ctx, types.NamespacedName{Namespace: mountpointNamespace, Name: name}, mpPod); err != nil {
+			t.Fatalf("Failed to get Mountpoint Pod %s: %v", name, err)
+		}
+		return mpPod
+	}
+	runUpgrade := func() {
+		t.Helper()
+		if err := upgrader.RunUpgrade(ctx); err != nil {
+			t.Fatalf("Failed to run upgrade: %v", err)
+		}
+	}
+
+	reconcilePod(oldWorkload)
+	runUpgrade()
+	condition := meta.FindStatusCondition(getS3PA().Status.Conditions, crdv2.ConditionMountpointPodsUpToDate)
+	if condition == nil || condition.Reason != csicontroller.ReasonMountpointPodsUpToDate {
+		t.Fatalf("Expected Mountpoint Pods to be up to date, got %+v", condition)
+	}
+
+	// Simulate a Mountpoint Pod spawned by a previous version of the driver
+	var oldMPPodName string
+	for mpPodName := range getS3PA().Spec.MountpointS3PodAttachments {
+		oldMPPodName = mpPodName
+	}
+	oldMPPod := getMountpointPod(oldMPPodName)
+	oldMPPod.Labels[mppod.LabelMountpointVersion] = "1.9.0"
+	if err := c.Update(ctx, oldMPPod); err != nil {
+		t.Fatalf("Failed to update Mountpoint Pod: %v", err)
+	}
+
+	runUpgrade()
+	if getMountpointPod(oldMPPodName).Annotations[mppod.AnnotationNoNewWorkload] != "true" {
+		t.Fatalf("Expected outdated Mountpoint Pod to be drained")
+	}
+	condition = meta.FindStatusCondition(getS3PA().Status.Conditions, crdv2.ConditionMountpointPodsUpToDate)
+	if condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != csicontroller.ReasonMountpointPodsDraining {
+		t.Fatalf("Expected outdated Mountpoint Pods to be draining, got %+v", condition)
+	}
+
+	// New workloads get a new Mountpoint Pod
+	reconcilePod(newWorkload)
+	s3pa := getS3PA()
+	if len(s3pa.Spec.MountpointS3PodAttachments) != 2 {
+		t.Fatalf("Expected a new Mountpoint Pod for the new workload, got %+v", s3pa.Spec.MountpointS3PodAttachments)
+	}
+	for mpPodName, attachments := range s3pa.Spec.MountpointS3PodAttachments {
+		if mpPodName != oldMPPodName && (len(attachments) != 1 || attachments[0].WorkloadPodUID != string(newWorkload.UID)) {
+			t.Fatalf("Expected the new workload on the new Mountpoint Pod, got %+v", attachments)
+		}
+	}
+
+	// The outdated Mountpoint Pod is unmounted once its workload terminates
+	oldWorkload.Status.Phase = corev1.PodSucceeded
+	if err := c.Status().Update(ctx, oldWorkload); err != nil {
+		t.Fatalf("Failed to update workload: %v", err)
+	}
+	reconcilePod(oldWorkload)
+	if getMountpointPod(oldMPPodName).Annotations[mppod.AnnotationNeedsUnmount] != "true" {
+		t.Fatalf("Expected outdated Mountpoint Pod to be unmounted")
+	}
+
+	runUpgrade()
+	condition = meta.FindStatusCondition(getS3PA().Status.Conditions, crdv2.ConditionMountpointPodsUpToDate)
+	if condition == nil || condition.Status != metav1.ConditionTrue {
+		t.Fatalf("Expected Mountpoint Pods to be up to date after the rollout, got %+v", condition)
+	}
+}
@@ -0,0 +1,311 @@
+package csicontroller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/maintenance"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+)
+
+// AnnotationPrefixHardLimit is the PersistentVolume annotation with the hard limit of the usage of its prefix, as a
+// Kubernetes quantity, e.g. `12Gi`. Mounts of the volume are remounted read-only while the usage is above it.
+const AnnotationPrefixHardLimit = constants.DriverName + "/prefix-hard-limit"
+
+// Reasons of events emitted on Persistent Volume Claims and Persistent Volumes by the [PrefixQuotaEnforcer].
+const (
+	EventReasonPrefixQuotaExceeded      = "PrefixQuotaExceeded"
+	EventReasonPrefixHardLimitExceeded  = "PrefixHardLimitExceeded"
+	EventReasonPrefixHardLimitCleared   = "PrefixHardLimitCleared"
+	EventReasonInvalidPrefixHardLimit   = "InvalidPrefixHardLimit"
+	EventReasonPrefixUsageNotMeasurable = "PrefixUsageNotMeasurable"
+)
+
+// PrefixQuotaEnforcerConfig configures a [PrefixQuotaEnforcer].
+type PrefixQuotaEnforcerConfig struct {
+	// Interval between usage measurements.
+	Interval time.Duration
+	// MaxObjects is the maximum number of objects listed to measure the usage of a prefix, larger prefixes are
+	// not measured.
+	MaxObjects int
+}
+
+// prefixQuota is the quota of the prefix of a volume.
+type prefixQuota struct {
+	pv        *corev1.PersistentVolume
+	pvc       *corev1.PersistentVolumeClaim
+	bucket    string
+	prefix    string
+	request   int64
+	hardLimit int64
+}
+
+// A PrefixQuotaEnforcer periodically measures the usage of the prefixes of volumes sharing a bucket, i.e. volumes
+// with a `prefix` mount option, by listing their objects. The storage requested by the claim of a volume is a soft
+// quota: exceeding it is reported with a `PrefixQuotaExceeded` event on the claim. If the volume is annotated with
+// [AnnotationPrefixHardLimit], exceeding the hard limit marks the volume and its MountpointS3PodAttachments with
+// [maintenance.AnnotationQuotaExceeded], for node plugins to remount existing mounts of the volume read-only until
+// the usage is back under the limit.
+//
+// UTAPI measures the usage of whole buckets, so the usage of prefixes is measured with ListObjectsV2.
+type PrefixQuotaEnforcer struct {
+	client   client.Client
+	s3       s3.ListObjectsV2APIClient
+	recorder record.EventRecorder
+	config   PrefixQuotaEnforcerConfig
+	// exceeded holds the Persistent Volumes whose usage was above the soft quota at the last measurement, to report
+	// them once.
+	exceeded map[string]bool
+}
+
+// NewPrefixQuotaEnforcer creates a new [PrefixQuotaEnforcer].
+func NewPrefixQuotaEnforcer(client client.Client, s3Client s3.ListObjectsV2APIClient, recorder record.EventRecorder, config PrefixQuotaEnforcerConfig) *PrefixQuotaEnforcer {
+	return &PrefixQuotaEnforcer{
+		client:   client,
+		s3:       s3Client,
+		recorder: recorder,
+		config:   config,
+		exceeded: make(map[string]bool),
+	}
+}
+
+// Start begins the periodic enforcement of prefix quotas.
+func (e *PrefixQuotaEnforcer) Start(ctx context.Context) error {
+	log := logf.FromContext(ctx)
+	log.Info("Starting prefix quota enforcer", "interval", e.config.Interval, "maxObjects", e.config.MaxObjects)
+
+	ticker := time.NewTicker(e.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Completed prefix quota enforcer")
+			return nil
+		case <-ticker.C:
+			if err := e.RunEnforcement(ctx); err != nil {
+				log.Error(err, "Failed to enforce prefix quotas")
+				// Continue running even if enforcement fails
+			}
+		}
+	}
+}
+
+// RunEnforcement measures the usage of the prefixes of all bound volumes of the driver, updates the exposed metrics
+// and enforces their hard limits.
+func (e *PrefixQuotaEnforcer) RunEnforcement(ctx context.Context) error {
+	pvList := &corev1.PersistentVolumeList{}
+	if err := e.client.List(ctx, pvList); err != nil {
+		return err
+	}
+
+	prefixUsageBytes.Reset()
+	prefixQuotaBytes.Reset()
+	var errs []error
+	for i := range pvList.Items {
+		quota, err := e.prefixQuota(ctx, &pvList.Items[i])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if quota == nil {
+			continue
+		}
+		if err := e.enforce(ctx, quota); err != nil {
+			errs = append(errs, fmt.Errorf("failed to enforce quota of PV %s: %w", quota.pv.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// prefixQuota returns the quota of the prefix of `pv`, nil if `pv` is not a bound volume of a prefix.
+func (e *PrefixQuotaEnforcer) prefixQuota(ctx context.Context, pv *corev1.PersistentVolume) (*prefixQuota, error) {
+	if pv.Spec.CSI == nil || pv.Spec.CSI.Driver != mountpointCSIDriverName || pv.Spec.ClaimRef == nil {
+		return nil, nil
+	}
+	prefix, _ := mountpoint.NewArgsBuilder(pv.Spec.MountOptions).Value(mountpoint.ArgPrefix)
+	bucket := mppod.ExtractVolumeAttributes(pv)[volumecontext.BucketName]
+	if prefix == "" || bucket == "" {
+		return nil, nil
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	claimRef := pv.Spec.ClaimRef
+	if err := e.client.Get(ctx, types.NamespacedName{Namespace: claimRef.Namespace, Name: claimRef.Name}, pvc); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	quota := &prefixQuota{pv: pv, pvc: pvc, bucket: bucket, prefix: prefix}
+	if request, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+		quota.request = request.Value()
+	}
+
+	if value, ok := pv.Annotations[AnnotationPrefixHardLimit]; ok {
+		hardLimit, err := resource.ParseQuantity(value)
+		if err != nil || hardLimit.Sign() <= 0 {
+			e.recorder.Eventf(pv, corev1.EventTypeWarning, EventReasonInvalidPrefixHardLimit,
+				"Ignoring invalid %s %q, must be a positive quantity, e.g. 12Gi", AnnotationPrefixHardLimit, value)
+		} else {
+			quota.hardLimit = hardLimit.Value()
+		}
+	}
+	if quota.request == 0 && quota.hardLimit == 0 {
+		return nil, nil
+	}
+	return quota, nil
+}
+
+// enforce measures the usage of the prefix of `quota`, reports it and marks the volume read-only while its usage is
+// above the hard limit.
+func (e *PrefixQuotaEnforcer) enforce(ctx context.Context, quota *prefixQuota) error {
+	log := logf.FromContext(ctx).WithValues("pv", quota.pv.Name, "bucket", quota.bucket, "prefix", quota.prefix)
+
+	usage, truncated, err := e.measureUsage(ctx, quota.bucket, quota.prefix)
+	if err != nil {
+		return fmt.Errorf("failed to list prefix %q of bucket %q: %w", quota.prefix, quota.bucket, err)
+	}
+	if truncated {
+		// Keep the volume as is, a partial usage would lift the hard limit of large prefixes
+		log.Info("Skipping prefix with too many objects to measure its usage", "maxObjects", e.config.MaxObjects)
+		e.recorder.Eventf(quota.pv, corev1.EventTypeWarning, EventReasonPrefixUsageNotMeasurable,
+			"Usage of prefix %q of bucket %q is not measured, it has more than %d objects", quota.prefix, quota.bucket, e.config.MaxObjects)
+		return nil
+	}
+
+	labels := []string{quota.pv.Name, quota.bucket, quota.prefix}
+	prefixUsageBytes.WithLabelValues(labels...).Set(float64(usage))
+	if quota.request > 0 {
+		prefixQuotaBytes.WithLabelValues(labels...).Set(float64(quota.request))
+	}
+	log.V(debugLevel).Info("Measured usage of prefix", "usage", usage, "request", quota.request, "hardLimit", quota.hardLimit)
+
+	exceeded := quota.request > 0 && usage > quota.request
+	if exceeded && !e.exceeded[quota.pv.Name] {
+		log.Info("Usage of prefix exceeds the requested storage", "usage", usage, "request", quota.request)
+		e.recorder.Eventf(quota.pvc, corev1.EventTypeWarning, EventReasonPrefixQuotaExceeded,
+			"Usage of prefix %q of bucket %q is %s, above the requested storage %s of the claim",
+			quota.prefix, quota.bucket, formatBytes(usage), formatBytes(quota.request))
+	}
+	if exceeded {
+		e.exceeded[quota.pv.Name] = true
+	} else {
+		delete(e.exceeded, quota.pv.Name)
+	}
+
+	value := ""
+	if quota.hardLimit > 0 && usage > quota.hardLimit {
+		value = strconv.FormatInt(usage, 10)
+	}
+	return e.markQuotaExceeded(ctx, quota, usage, value)
+}
+
+// measureUsage returns the total size of the objects under `prefix` of `bucket`, and whether listing stopped after
+// [PrefixQuotaEnforcerConfig.MaxObjects] objects.
+func (e *PrefixQuotaEnforcer) measureUsage(ctx context.Context, bucket, prefix string) (int64, bool, error) {
+	paginator := s3.NewListObjectsV2Paginator(e.s3, &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	})
+	var usage int64
+	objects := 0
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, false, err
+		}
+		for _, object := range page.Contents {
+			usage += aws.ToInt64(object.Size)
+		}
+		objects += len(page.Contents)
+		if objects > e.config.MaxObjects {
+			return usage, true, nil
+		}
+	}
+	return usage, false, nil
+}
+
+// markQuotaExceeded sets [maintenance.AnnotationQuotaExceeded] of the volume of `quota` and its
+// MountpointS3PodAttachments to `value`, or removes it if `value` is empty.
+func (e *PrefixQuotaEnforcer) markQuotaExceeded(ctx context.Context, quota *prefixQuota, usage int64, value string) error {
+	s3paList := &crdv2.MountpointS3PodAttachmentList{}
+	if err := e.client.List(ctx, s3paList, client.MatchingFields{crdv2.FieldPersistentVolumeName: quota.pv.Name}); err != nil {
+		return err
+	}
+	var errs []error
+	for i := range s3paList.Items {
+		s3pa := &s3paList.Items[i]
+		if s3pa.Annotations[maintenance.AnnotationQuotaExceeded] == value {
+			continue
+		}
+		patch := client.MergeFrom(s3pa.DeepCopy())
+		setQuotaExceeded(s3pa, value)
+		if err := e.client.Patch(ctx, s3pa, patch); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	// Usage changes while the hard limit is exceeded are not reported
+	current := quota.pv.Annotations[maintenance.AnnotationQuotaExceeded]
+	if (current == "") == (value == "") {
+		return nil
+	}
+	patch := client.MergeFrom(quota.pv.DeepCopy())
+	setQuotaExceeded(quota.pv, value)
+	if err := e.client.Patch(ctx, quota.pv, patch); err != nil {
+		return err
+	}
+
+	if value != "" {
+		logf.FromContext(ctx).Info("Usage of prefix exceeds the hard limit, mounts of the volume are read-only", "pv", quota.pv.Name, "usage", usage, "hardLimit", quota.hardLimit)
+		for _, obj := range []client.Object{quota.pv, quota.pvc} {
+			e.recorder.Eventf(obj, corev1.EventTypeWarning, EventReasonPrefixHardLimitExceeded,
+				"Usage of prefix %q of bucket %q is %s, above the hard limit %s: mounts of the volume are read-only until objects are deleted",
+				quota.prefix, quota.bucket, formatBytes(usage), formatBytes(quota.hardLimit))
+		}
+		return nil
+	}
+	logf.FromContext(ctx).Info("Usage of prefix is back under the hard limit, mounts of the volume are writable again", "pv", quota.pv.Name, "usage", usage)
+	for _, obj := range []client.Object{quota.pv, quota.pvc} {
+		e.recorder.Eventf(obj, corev1.EventTypeNormal, EventReasonPrefixHardLimitCleared,
+			"Usage of prefix %q of bucket %q is %s, mounts of the volume are writable again", quota.prefix, quota.bucket, formatBytes(usage))
+	}
+	return nil
+}
+
+// setQuotaExceeded sets [maintenance.AnnotationQuotaExceeded] of `obj` to `value`, or removes it if `value` is empty.
+func setQuotaExceeded(obj client.Object, value string) {
+	annotations := obj.GetAnnotations()
+	if value == "" {
+		delete(annotations, maintenance.AnnotationQuotaExceeded)
+	} else {
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[maintenance.AnnotationQuotaExceeded] = value
+	}
+	obj.SetAnnotations(annotations)
+}
+
+// formatBytes formats `bytes` as a binary Kubernetes quantity, e.g. `12Gi`.
+func formatBytes(bytes int64) string {
+	return resource.NewQuantity(bytes, resource.BinarySI).String()
+}
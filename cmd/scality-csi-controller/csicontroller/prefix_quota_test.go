@@ -0,0 +1,153 @@
+package csicontroller_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/scality/mountpoint-s3-csi-driver/cmd/scality-csi-controller/csicontroller"
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/maintenance"
+)
+
+// prefixObjects lists `objects` of `size` bytes, in pages of 2 objects.
+type prefixObjects struct {
+	objects int
+	size    int64
+	prefix  string
+}
+
+func (p *prefixObjects) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	p.prefix = aws.ToString(params.Prefix)
+	start := 0
+	if token := aws.ToString(params.ContinuationToken); token != "" {
+		start = len(token)
+	}
+	output := &s3.ListObjectsV2Output{}
+	for i := start; i < min(start+2, p.objects); i++ {
+		output.Contents = append(output.Contents, s3types.Object{Key: aws.String("object"), Size: aws.Int64(p.size)})
+	}
+	if start+2 < p.objects {
+		output.IsTruncated = aws.Bool(true)
+		output.NextContinuationToken = aws.String(strings.Repeat("x", start+2))
+	}
+	return output, nil
+}
+
+func TestPrefixQuotaEnforcer(t *testing.T) {
+	ctx := context.Background()
+	config := csicontroller.PrefixQuotaEnforcerConfig{MaxObjects: 10}
+
+	// setup creates a volume of prefix `data/` whose claim requests 1Ki, with an attachment
+	setup := func(t *testing.T, hardLimit string) (client.Client, *record.FakeRecorder) {
+		t.Helper()
+		pv := createTestPV(testPVName, testPVCName, testNamespace)
+		pv.Spec.MountOptions = []string{"prefix=data/"}
+		if hardLimit != "" {
+			pv.Annotations = map[string]string{csicontroller.AnnotationPrefixHardLimit: hardLimit}
+		}
+		pvc := createTestPVC(testPVCName, testNamespace, testPVName)
+		pvc.Spec.Resources.Requests = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Ki")}
+		s3pa := createTestS3PodAttachment("test-s3pa", "workload-uid", "mp-pod")
+		_, c := testReconciler(pv, pvc, s3pa)
+		return c, record.NewFakeRecorder(10)
+	}
+
+	quotaExceeded := func(t *testing.T, c client.Client) (string, string) {
+		t.Helper()
+		pv := &corev1.PersistentVolume{}
+		if err := c.Get(ctx, types.NamespacedName{Name: testPVName}, pv); err != nil {
+			t.Fatalf("Failed to get PV: %v", err)
+		}
+		s3pa := &crdv2.MountpointS3PodAttachment{}
+		if err := c.Get(ctx, types.NamespacedName{Name: "test-s3pa"}, s3pa); err != nil {
+			t.Fatalf("Failed to get MountpointS3PodAttachment: %v", err)
+		}
+		return pv.Annotations[maintenance.AnnotationQuotaExceeded], s3pa.Annotations[maintenance.AnnotationQuotaExceeded]
+	}
+
+	expectEvent := func(t *testing.T, recorder *record.FakeRecorder, reason string) {
+		t.Helper()
+		select {
+		case event := <-recorder.Events:
+			if !strings.Contains(event, reason) {
+				t.Fatalf("Expected %s event, got %q", reason, event)
+			}
+		default:
+			t.Fatalf("Expected %s event", reason)
+		}
+	}
+
+	t.Run("Usage above the requested storage is reported once", func(t *testing.T) {
+		c, recorder := setup(t, "")
+		objects := &prefixObjects{objects: 5, size: 512}
+		enforcer := csicontroller.NewPrefixQuotaEnforcer(c, objects, recorder, config)
+
+		if err := enforcer.RunEnforcement(ctx); err != nil {
+			t.Fatalf("Failed to enforce prefix quotas: %v", err)
+		}
+		if objects.prefix != "data/" {
+			t.Fatalf("Expected prefix data/ to be listed, got %q", objects.prefix)
+		}
+		expectEvent(t, recorder, csicontroller.EventReasonPrefixQuotaExceeded)
+		if pvValue, s3paValue := quotaExceeded(t, c); pvValue != "" || s3paValue != "" {
+			t.Fatalf("Expected volume without hard limit not to be read-only, got %q and %q", pvValue, s3paValue)
+		}
+
+		if err := enforcer.RunEnforcement(ctx); err != nil {
+			t.Fatalf("Failed to enforce prefix quotas: %v", err)
+		}
+		if len(recorder.Events) != 0 {
+			t.Fatalf("Expected quota exceeded to be reported once, got %q", <-recorder.Events)
+		}
+	})
+
+	t.Run("Usage above the hard limit makes the volume read-only until it is back under", func(t *testing.T) {
+		c, recorder := setup(t, "2Ki")
+		objects := &prefixObjects{objects: 5, size: 512}
+		enforcer := csicontroller.NewPrefixQuotaEnforcer(c, objects, recorder, config)
+
+		if err := enforcer.RunEnforcement(ctx); err != nil {
+			t.Fatalf("Failed to enforce prefix quotas: %v", err)
+		}
+		if pvValue, s3paValue := quotaExceeded(t, c); pvValue != "2560" || s3paValue != "2560" {
+			t.Fatalf("Expected volume above its hard limit to be read-only, got %q and %q", pvValue, s3paValue)
+		}
+		expectEvent(t, recorder, csicontroller.EventReasonPrefixQuotaExceeded)
+		// On the volume and its claim
+		expectEvent(t, recorder, csicontroller.EventReasonPrefixHardLimitExceeded)
+		expectEvent(t, recorder, csicontroller.EventReasonPrefixHardLimitExceeded)
+
+		objects.objects = 3
+		if err := enforcer.RunEnforcement(ctx); err != nil {
+			t.Fatalf("Failed to enforce prefix quotas: %v", err)
+		}
+		if pvValue, s3paValue := quotaExceeded(t, c); pvValue != "" || s3paValue != "" {
+			t.Fatalf("Expected volume under its hard limit to be writable, got %q and %q", pvValue, s3paValue)
+		}
+		expectEvent(t, recorder, csicontroller.EventReasonPrefixHardLimitCleared)
+		expectEvent(t, recorder, csicontroller.EventReasonPrefixHardLimitCleared)
+	})
+
+	t.Run("Prefixes with too many objects are not measured", func(t *testing.T) {
+		c, recorder := setup(t, "1Ki")
+		enforcer := csicontroller.NewPrefixQuotaEnforcer(c, &prefixObjects{objects: 20, size: 512}, recorder, config)
+
+		if err := enforcer.RunEnforcement(ctx); err != nil {
+			t.Fatalf("Failed to enforce prefix quotas: %v", err)
+		}
+		expectEvent(t, recorder, csicontroller.EventReasonPrefixUsageNotMeasurable)
+		if pvValue, _ := quotaExceeded(t, c); pvValue != "" {
+			t.Fatalf("Expected volume not measured to be kept writable, got %q", pvValue)
+		}
+	})
+}
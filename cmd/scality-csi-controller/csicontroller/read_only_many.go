@@ -0,0 +1,94 @@
+package csicontroller
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
+)
+
+// AnnotationAccessPolicy is the PersistentVolume annotation restricting how workloads can mount the volume.
+const AnnotationAccessPolicy = constants.DriverName + "/access-policy"
+
+// AccessPolicyReadOnly only allows read-only mounts of a volume: workloads mounting it writable are rejected
+// instead of silently getting a read-only mount.
+const AccessPolicyReadOnly = "read-only"
+
+// Reasons of events emitted on workload Pods by the [Reconciler].
+const (
+	EventReasonWritableMountRejected = "WritableMountRejected"
+)
+
+// workloadMountOptions returns the comma separated mount options of the MountpointS3PodAttachment of a workload
+// using `pv` through `pvc`.
+//
+// Workloads with a ReadOnlyMany claim always mount read-only, whatever the mount options of the volume: `read-only`
+// is added to their mount options, so they never share a Mountpoint Pod with writable mounts of the volume, and the
+// node plugin mounts Mountpoint read-only for them.
+func workloadMountOptions(pvc *corev1.PersistentVolumeClaim, pv *corev1.PersistentVolume) string {
+	mountOptions := pv.Spec.MountOptions
+	// Mount options are kept as written rather than normalized, they identify the Mountpoint Pods of existing
+	// MountpointS3PodAttachments
+	if isReadOnlyManyClaim(pvc) && !hasReadOnlyMountOption(mountOptions) {
+		mountOptions = append(slices.Clone(mountOptions), strings.TrimPrefix(mountpoint.ArgReadOnly, "--"))
+	}
+	return strings.Join(mountOptions, ",")
+}
+
+// isReadOnlyManyClaim returns whether `pvc` only requests the ReadOnlyMany access mode.
+func isReadOnlyManyClaim(pvc *corev1.PersistentVolumeClaim) bool {
+	if pvc == nil || len(pvc.Spec.AccessModes) == 0 {
+		return false
+	}
+	for _, mode := range pvc.Spec.AccessModes {
+		if mode != corev1.ReadOnlyMany {
+			return false
+		}
+	}
+	return true
+}
+
+// hasReadOnlyMountOption returns whether `mountOptions` mount Mountpoint read-only.
+func hasReadOnlyMountOption(mountOptions []string) bool {
+	return mountpoint.NewArgsBuilder(mountOptions).Has(mountpoint.ArgReadOnly)
+}
+
+// isReadOnlyMount returns whether `workloadPod` mounts `pv` read-only, either through a ReadOnlyMany claim, a
+// read-only volume of the Pod, or the mount options of the volume.
+func isReadOnlyMount(workloadPod *corev1.Pod, pvc *corev1.PersistentVolumeClaim, pv *corev1.PersistentVolume) bool {
+	if isReadOnlyManyClaim(pvc) || hasReadOnlyMountOption(pv.Spec.MountOptions) {
+		return true
+	}
+	if pv.Spec.CSI != nil && pv.Spec.CSI.ReadOnly {
+		return true
+	}
+	if pvc == nil {
+		return false
+	}
+	for _, vol := range workloadPod.Spec.Volumes {
+		if claim := vol.PersistentVolumeClaim; claim != nil && claim.ClaimName == pvc.Name && !claim.ReadOnly {
+			return false
+		}
+	}
+	return true
+}
+
+// writableMountRejected returns whether `workloadPod` mounts `pv` writable although the access policy of `pv` only
+// allows read-only mounts, in which case no Mountpoint Pod should be created for it.
+func (r *Reconciler) writableMountRejected(workloadPod *corev1.Pod, pvc *corev1.PersistentVolumeClaim, pv *corev1.PersistentVolume, log logr.Logger) bool {
+	if pv.Annotations[AnnotationAccessPolicy] != AccessPolicyReadOnly || isReadOnlyMount(workloadPod, pvc, pv) {
+		return false
+	}
+
+	log.Info("Not creating Mountpoint Pods, the access policy of the volume only allows read-only mounts", "policy", AccessPolicyReadOnly)
+	if r.recorder != nil {
+		r.recorder.Eventf(workloadPod, corev1.EventTypeWarning, EventReasonWritableMountRejected,
+			"Volume %s only allows read-only mounts (%s=%s), use a ReadOnlyMany claim or set readOnly on the Pod volume",
+			pv.Name, AnnotationAccessPolicy, AccessPolicyReadOnly)
+	}
+	return true
+}
@@ -0,0 +1,96 @@
+package csicontroller_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/scality/mountpoint-s3-csi-driver/cmd/scality-csi-controller/csicontroller"
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+)
+
+func TestReconciler_ReadOnlyMany(t *testing.T) {
+	ctx := context.Background()
+
+	reconcileAndListS3PAs := func(t *testing.T, reconciler *csicontroller.Reconciler, c client.Client) []crdv2.MountpointS3PodAttachment {
+		t.Helper()
+		request := reconcile.Request{NamespacedName: types.NamespacedName{Name: testPodName, Namespace: testNamespace}}
+		if _, err := reconciler.Reconcile(ctx, request); err != nil {
+			t.Fatalf("Failed to reconcile workload: %v", err)
+		}
+		s3paList := &crdv2.MountpointS3PodAttachmentList{}
+		if err := c.List(ctx, s3paList); err != nil {
+			t.Fatalf("Failed to list MountpointS3PodAttachments: %v", err)
+		}
+		return s3paList.Items
+	}
+
+	t.Run("ReadOnlyMany claims are mounted read-only", func(t *testing.T) {
+		pvc := createTestPVC(testPVCName, testNamespace, testPVName)
+		pvc.Spec.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadOnlyMany}
+		pv := createTestPV(testPVName, testPVCName, testNamespace)
+		pv.Spec.MountOptions = []string{"allow-other"}
+		reconciler, c := testReconciler(createTestPod(testPodName, testNamespace, testNodeName, pvcVolumes()), pvc, pv)
+
+		s3pas := reconcileAndListS3PAs(t, reconciler, c)
+		if len(s3pas) != 1 {
+			t.Fatalf("Expected a MountpointS3PodAttachment, got %d", len(s3pas))
+		}
+		if got := s3pas[0].Spec.MountOptions; got != "allow-other,read-only" {
+			t.Fatalf("Expected read-only to be added to mount options, got %q", got)
+		}
+	})
+
+	t.Run("ReadWriteMany claims keep the mount options of the volume", func(t *testing.T) {
+		pvc := createTestPVC(testPVCName, testNamespace, testPVName)
+		pvc.Spec.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany, corev1.ReadOnlyMany}
+		pv := createTestPV(testPVName, testPVCName, testNamespace)
+		pv.Spec.MountOptions = []string{"allow-other"}
+		reconciler, c := testReconciler(createTestPod(testPodName, testNamespace, testNodeName, pvcVolumes()), pvc, pv)
+
+		s3pas := reconcileAndListS3PAs(t, reconciler, c)
+		if len(s3pas) != 1 || s3pas[0].Spec.MountOptions != "allow-other" {
+			t.Fatalf("Expected a MountpointS3PodAttachment with the mount options of the volume, got %+v", s3pas)
+		}
+	})
+
+	t.Run("Read-only access policy rejects writable mounts", func(t *testing.T) {
+		pv := createTestPV(testPVName, testPVCName, testNamespace)
+		pv.Annotations = map[string]string{csicontroller.AnnotationAccessPolicy: csicontroller.AccessPolicyReadOnly}
+		reconciler, c := testReconciler(createTestPod(testPodName, testNamespace, testNodeName, pvcVolumes()),
+			createTestPVC(testPVCName, testNamespace, testPVName), pv)
+		recorder := record.NewFakeRecorder(10)
+		reconciler.SetEventRecorder(recorder)
+
+		if s3pas := reconcileAndListS3PAs(t, reconciler, c); len(s3pas) != 0 {
+			t.Fatalf("Expected no MountpointS3PodAttachment for a writable mount, got %d", len(s3pas))
+		}
+		select {
+		case event := <-recorder.Events:
+			if !strings.Contains(event, csicontroller.EventReasonWritableMountRejected) {
+				t.Errorf("Expected %s event, got %q", csicontroller.EventReasonWritableMountRejected, event)
+			}
+		default:
+			t.Errorf("Expected %s event", csicontroller.EventReasonWritableMountRejected)
+		}
+	})
+
+	t.Run("Read-only access policy allows read-only Pod volumes", func(t *testing.T) {
+		pv := createTestPV(testPVName, testPVCName, testNamespace)
+		pv.Annotations = map[string]string{csicontroller.AnnotationAccessPolicy: csicontroller.AccessPolicyReadOnly}
+		volumes := pvcVolumes()
+		volumes[0].PersistentVolumeClaim.ReadOnly = true
+		reconciler, c := testReconciler(createTestPod(testPodName, testNamespace, testNodeName, volumes),
+			createTestPVC(testPVCName, testNamespace, testPVName), pv)
+
+		if s3pas := reconcileAndListS3PAs(t, reconciler, c); len(s3pas) != 1 {
+			t.Fatalf("Expected a MountpointS3PodAttachment for a read-only mount, got %d", len(s3pas))
+		}
+	})
+}
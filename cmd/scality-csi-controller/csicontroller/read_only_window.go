@@ -0,0 +1,180 @@
+package csicontroller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/maintenance"
+)
+
+// Reasons of events emitted on Persistent Volumes by the [ReadOnlyWindowScheduler].
+const (
+	EventReasonReadOnlyWindowStarted = "ReadOnlyWindowStarted"
+	EventReasonReadOnlyWindowEnded   = "ReadOnlyWindowEnded"
+	EventReasonInvalidReadOnlyWindow = "InvalidReadOnlyWindow"
+)
+
+// readOnlyWindowInterval is how often read-only windows are evaluated, windows are scheduled to the minute.
+const readOnlyWindowInterval = 30 * time.Second
+
+// A ReadOnlyWindowScheduler periodically evaluates read-only windows of Persistent Volumes, see [maintenance].
+// While a window is active, the volume and its MountpointS3PodAttachments are annotated with the end of the window,
+// for node plugins to remount existing mounts of the volume read-only. Annotations are removed once the window ends.
+type ReadOnlyWindowScheduler struct {
+	client   client.Client
+	recorder record.EventRecorder
+	now      func() time.Time
+	// invalidWindows holds the last invalid read-only windows reported per Persistent Volume, to report them once.
+	invalidWindows map[string]string
+}
+
+// NewReadOnlyWindowScheduler creates a new [ReadOnlyWindowScheduler].
+func NewReadOnlyWindowScheduler(client client.Client, recorder record.EventRecorder) *ReadOnlyWindowScheduler {
+	return &ReadOnlyWindowScheduler{
+		client:         client,
+		recorder:       recorder,
+		now:            time.Now,
+		invalidWindows: make(map[string]string),
+	}
+}
+
+// Start begins the periodic evaluation of read-only windows.
+func (s *ReadOnlyWindowScheduler) Start(ctx context.Context) error {
+	log := logf.FromContext(ctx)
+	log.Info("Starting read-only window scheduler", "interval", readOnlyWindowInterval)
+
+	ticker := time.NewTicker(readOnlyWindowInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Completed read-only window scheduler")
+			return nil
+		case <-ticker.C:
+			if err := s.RunSchedule(ctx); err != nil {
+				log.Error(err, "Failed to evaluate read-only windows")
+				// Continue running even if evaluation fails
+			}
+		}
+	}
+}
+
+// RunSchedule starts and ends read-only windows of all Persistent Volumes of the driver.
+func (s *ReadOnlyWindowScheduler) RunSchedule(ctx context.Context) error {
+	pvList := &corev1.PersistentVolumeList{}
+	if err := s.client.List(ctx, pvList); err != nil {
+		return err
+	}
+
+	now := s.now()
+	active := 0
+	var errs []error
+	for i := range pvList.Items {
+		pv := &pvList.Items[i]
+		if pv.Spec.CSI == nil || pv.Spec.CSI.Driver != mountpointCSIDriverName {
+			continue
+		}
+		readOnly, err := s.schedule(ctx, pv, now)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if readOnly {
+			active++
+		}
+	}
+	readOnlyWindowVolumes.Set(float64(active))
+	return errors.Join(errs...)
+}
+
+// schedule annotates `pv` and its MountpointS3PodAttachments according to its read-only windows at `now`,
+// and returns whether `pv` is in a read-only window.
+func (s *ReadOnlyWindowScheduler) schedule(ctx context.Context, pv *corev1.PersistentVolume, now time.Time) (bool, error) {
+	log := logf.FromContext(ctx).WithValues("pv", pv.Name)
+
+	var until string
+	if spec, ok := pv.Annotations[maintenance.AnnotationReadOnlyWindows]; ok {
+		windows, err := maintenance.ParseWindows(spec)
+		if err != nil {
+			// Keep the volume as is until the windows are fixed
+			if s.invalidWindows[pv.Name] != spec {
+				s.invalidWindows[pv.Name] = spec
+				log.Error(err, "Ignoring invalid read-only windows")
+				s.recorder.Event(pv, corev1.EventTypeWarning, EventReasonInvalidReadOnlyWindow, err.Error())
+			}
+			return maintenance.ReadOnly(pv.Annotations, now), nil
+		}
+		delete(s.invalidWindows, pv.Name)
+		if end, active := maintenance.ActiveUntil(windows, now); active {
+			until = maintenance.FormatUntil(end)
+		}
+	}
+
+	if err := s.annotateAttachments(ctx, pv.Name, until); err != nil {
+		return until != "", err
+	}
+
+	current := pv.Annotations[maintenance.AnnotationReadOnlyUntil]
+	if current == until {
+		return until != "", nil
+	}
+	patch := client.MergeFrom(pv.DeepCopy())
+	setReadOnlyUntil(pv, until)
+	if err := s.client.Patch(ctx, pv, patch); err != nil {
+		return until != "", err
+	}
+
+	switch {
+	case until == "":
+		log.Info("Read-only window ended")
+		s.recorder.Event(pv, corev1.EventTypeNormal, EventReasonReadOnlyWindowEnded, "Read-only window ended, mounts of the volume are writable again")
+	case current == "":
+		log.Info("Read-only window started", "until", until)
+		s.recorder.Eventf(pv, corev1.EventTypeNormal, EventReasonReadOnlyWindowStarted, "Read-only window started, mounts of the volume are read-only until %s", until)
+	}
+	return until != "", nil
+}
+
+// annotateAttachments sets the end of the read-only window on all MountpointS3PodAttachments of volume `pvName`,
+// or removes it if `until` is empty.
+func (s *ReadOnlyWindowScheduler) annotateAttachments(ctx context.Context, pvName, until string) error {
+	s3paList := &crdv2.MountpointS3PodAttachmentList{}
+	if err := s.client.List(ctx, s3paList, client.MatchingFields{crdv2.FieldPersistentVolumeName: pvName}); err != nil {
+		return err
+	}
+
+	var errs []error
+	for i := range s3paList.Items {
+		s3pa := &s3paList.Items[i]
+		if s3pa.Annotations[maintenance.AnnotationReadOnlyUntil] == until {
+			continue
+		}
+		patch := client.MergeFrom(s3pa.DeepCopy())
+		setReadOnlyUntil(s3pa, until)
+		if err := s.client.Patch(ctx, s3pa, patch); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// setReadOnlyUntil sets [maintenance.AnnotationReadOnlyUntil] of `obj` to `until`, or removes it if `until` is empty.
+func setReadOnlyUntil(obj client.Object, until string) {
+	annotations := obj.GetAnnotations()
+	if until == "" {
+		delete(annotations, maintenance.AnnotationReadOnlyUntil)
+	} else {
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[maintenance.AnnotationReadOnlyUntil] = until
+	}
+	obj.SetAnnotations(annotations)
+}
@@ -0,0 +1,144 @@
+package csicontroller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/maintenance"
+)
+
+func TestReadOnlyWindowScheduler(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = crdv2.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	// 2025-06-07 is a Saturday
+	saturday := time.Date(2025, time.June, 7, 0, 0, 0, 0, time.UTC)
+
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "pv-1",
+			Annotations: map[string]string{maintenance.AnnotationReadOnlyWindows: "0 2 * * 6 4h"},
+		},
+		Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: mountpointCSIDriverName},
+			},
+		},
+	}
+	otherPV := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv-2"},
+		Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: mountpointCSIDriverName},
+			},
+		},
+	}
+	s3pa := &crdv2.MountpointS3PodAttachment{
+		ObjectMeta: metav1.ObjectMeta{Name: "s3pa-1"},
+		Spec:       crdv2.MountpointS3PodAttachmentSpec{PersistentVolumeName: "pv-1"},
+	}
+	otherS3PA := &crdv2.MountpointS3PodAttachment{
+		ObjectMeta: metav1.ObjectMeta{Name: "s3pa-2"},
+		Spec:       crdv2.MountpointS3PodAttachmentSpec{PersistentVolumeName: "pv-2"},
+	}
+
+	k8sClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(pv, otherPV, s3pa, otherS3PA).
+		WithIndex(&crdv2.MountpointS3PodAttachment{}, crdv2.FieldPersistentVolumeName, func(o client.Object) []string {
+			return []string{o.(*crdv2.MountpointS3PodAttachment).Spec.PersistentVolumeName}
+		}).
+		Build()
+	recorder := record.NewFakeRecorder(10)
+	scheduler := NewReadOnlyWindowScheduler(k8sClient, recorder)
+
+	annotations := func(obj client.Object) map[string]string {
+		t.Helper()
+		if err := k8sClient.Get(context.Background(), types.NamespacedName{Name: obj.GetName()}, obj); err != nil {
+			t.Fatalf("Failed to get %s: %v", obj.GetName(), err)
+		}
+		return obj.GetAnnotations()
+	}
+	run := func(now time.Time) {
+		t.Helper()
+		scheduler.now = func() time.Time { return now }
+		if err := scheduler.RunSchedule(context.Background()); err != nil {
+			t.Fatalf("Failed to run schedule: %v", err)
+		}
+	}
+	expectEvent := func(reason string) {
+		t.Helper()
+		select {
+		case event := <-recorder.Events:
+			if !strings.Contains(event, reason) {
+				t.Errorf("Expected %s event, got %q", reason, event)
+			}
+		default:
+			t.Errorf("Expected %s event", reason)
+		}
+	}
+
+	// Before the window
+	run(saturday.Add(time.Hour))
+	if _, ok := annotations(&corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv-1"}})[maintenance.AnnotationReadOnlyUntil]; ok {
+		t.Errorf("Expected no read-only window before its start")
+	}
+
+	// Within the window
+	run(saturday.Add(3 * time.Hour))
+	until := maintenance.FormatUntil(saturday.Add(6 * time.Hour))
+	if got := annotations(&corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv-1"}})[maintenance.AnnotationReadOnlyUntil]; got != until {
+		t.Errorf("Expected PV to be read-only until %s, got %q", until, got)
+	}
+	if got := annotations(&crdv2.MountpointS3PodAttachment{ObjectMeta: metav1.ObjectMeta{Name: "s3pa-1"}})[maintenance.AnnotationReadOnlyUntil]; got != until {
+		t.Errorf("Expected S3PodAttachment to be read-only until %s, got %q", until, got)
+	}
+	if _, ok := annotations(&crdv2.MountpointS3PodAttachment{ObjectMeta: metav1.ObjectMeta{Name: "s3pa-2"}})[maintenance.AnnotationReadOnlyUntil]; ok {
+		t.Errorf("Expected S3PodAttachment of a volume without windows not to be read-only")
+	}
+	expectEvent(EventReasonReadOnlyWindowStarted)
+
+	// Still within the window, nothing changes
+	run(saturday.Add(4 * time.Hour))
+	if len(recorder.Events) != 0 {
+		t.Errorf("Expected no event within the window, got %d", len(recorder.Events))
+	}
+
+	// After the window
+	run(saturday.Add(6 * time.Hour))
+	if _, ok := annotations(&corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv-1"}})[maintenance.AnnotationReadOnlyUntil]; ok {
+		t.Errorf("Expected PV not to be read-only after the window")
+	}
+	if _, ok := annotations(&crdv2.MountpointS3PodAttachment{ObjectMeta: metav1.ObjectMeta{Name: "s3pa-1"}})[maintenance.AnnotationReadOnlyUntil]; ok {
+		t.Errorf("Expected S3PodAttachment not to be read-only after the window")
+	}
+	expectEvent(EventReasonReadOnlyWindowEnded)
+
+	// Invalid windows are reported once
+	invalid := &corev1.PersistentVolume{}
+	if err := k8sClient.Get(context.Background(), types.NamespacedName{Name: "pv-1"}, invalid); err != nil {
+		t.Fatalf("Failed to get PV: %v", err)
+	}
+	invalid.Annotations[maintenance.AnnotationReadOnlyWindows] = "every saturday"
+	if err := k8sClient.Update(context.Background(), invalid); err != nil {
+		t.Fatalf("Failed to update PV: %v", err)
+	}
+	run(saturday.Add(3 * time.Hour))
+	run(saturday.Add(3 * time.Hour))
+	expectEvent(EventReasonInvalidReadOnlyWindow)
+	if len(recorder.Events) != 0 {
+		t.Errorf("Expected invalid windows to be reported once, got %d more events", len(recorder.Events))
+	}
+}
@@ -4,30 +4,38 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/go-logr/logr" // For logr.Logger type used by controller-runtime
 	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
-	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/maintenance"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util"
 )
 
 const debugLevel = 4
 
-const (
-	mountpointCSIDriverName = constants.DriverName
-)
+// maxConcurrentVolumes is the maximum number of volumes of a workload Pod handled concurrently.
+const maxConcurrentVolumes = 8
+
+// mountpointCSIDriverName is the name of the driver instance whose volumes are reconciled, volumes of other instances
+// installed in the cluster are ignored.
+var mountpointCSIDriverName = util.DriverName()
 
 const (
 	Requeue     = true
@@ -54,15 +62,23 @@ type Reconciler struct {
 	//   1. In handleExistingS3PodAttachment() when a pending S3PA is found (it appeared in the cache)
 	//   2. In removeWorkloadFromS3PodAttachment() when deleting an S3PA with a stale pending expectation
 	//
-	// Note: Reconcile() processes events sequentially, eliminating concurrency concerns.
+	// Note: Reconcile() processes events sequentially, and volumes of a workload handled concurrently have distinct
+	// field filters, eliminating concurrency concerns.
 	s3paExpectations *expectations
+	// mountFailures tracks recent Mountpoint failures per volume to enforce [mppod.Config.MountFailureBudget].
+	mountFailures *mountFailures
+	// headroomPodEnds tracks Headroom Pods whose end was counted, see [Reconciler.reconcileHeadroomPod].
+	headroomPodEnds *headroomPodEnds
+	// replacementMountpointPods tracks stuck Mountpoint Pods being recreated, see [Reconciler.retryStuckMountpointPod].
+	replacementMountpointPods *replacementMountpointPods
+	recorder                  record.EventRecorder
 	client.Client
 }
 
 // NewReconciler returns a new reconciler created from `client` and `podConfig`.
 func NewReconciler(client client.Client, podConfig mppod.Config) *Reconciler {
 	creator := mppod.NewCreator(podConfig)
-	return &Reconciler{Client: client, mountpointPodConfig: podConfig, mountpointPodCreator: creator, s3paExpectations: newExpectations()}
+	return &Reconciler{Client: client, mountpointPodConfig: podConfig, mountpointPodCreator: creator, s3paExpectations: newExpectations(), mountFailures: newMountFailures(), headroomPodEnds: newHeadroomPodEnds(), replacementMountpointPods: newReplacementMountpointPods()}
 }
 
 // SetupWithManager configures reconciler to run with given `mgr`.
@@ -77,6 +93,7 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 // Reconcile reconciles either a Mountpoint- or a workload-Pod.
 //
 // For Mountpoint Pods, it deletes completed Pods and logs each status change.
+// For Headroom Pods, it deletes them once their workload Pod is gone or they expired.
 // For workload Pods, it decides if it needs to spawn a Mountpoint/Headroom Pod to provide a volume for the workload Pod.
 func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
 	log := logf.FromContext(ctx).WithValues("pod", req.NamespacedName)
@@ -87,6 +104,9 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		// This is not an error situation as sometimes we schedule retries for `req`s,
 		// and they might got deleted once we try to re-process them again.
 		if apierrors.IsNotFound(err) {
+			if r.replacementMountpointPods.get(req.NamespacedName) != nil {
+				return r.createReplacementMountpointPod(ctx, req.NamespacedName)
+			}
 			log.Info("Pod not found - ignoring")
 			return reconcile.Result{}, nil
 		}
@@ -95,6 +115,9 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 	}
 
 	if r.isInMountpointNamespace(pod) {
+		if mppod.IsHeadroomPod(pod) {
+			return r.reconcileHeadroomPod(ctx, pod)
+		}
 		return r.reconcileMountpointPod(ctx, pod)
 	}
 
@@ -105,12 +128,30 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 func (r *Reconciler) reconcileMountpointPod(ctx context.Context, pod *corev1.Pod) (reconcile.Result, error) {
 	log := logf.FromContext(ctx).WithValues("mountpointPod", pod.Name)
 
+	if err := r.recordMountpointFailure(ctx, pod); err != nil {
+		log.Error(err, "Failed to record Mountpoint failure")
+		return reconcile.Result{}, err
+	}
+
+	if err := r.updateS3PodAttachmentStatus(ctx, pod); err != nil {
+		log.Error(err, "Failed to update MountpointS3PodAttachment status")
+		return reconcile.Result{}, err
+	}
+
 	switch pod.Status.Phase {
 	case corev1.PodPending:
 		log.V(debugLevel).Info("Pod pending to be scheduled")
+		return r.retryStuckMountpointPod(ctx, pod)
 	case corev1.PodRunning:
 		log.V(debugLevel).Info("Pod is running")
+		if _, lingering := r.lingerRemaining(pod, time.Now()); lingering && pod.Annotations[mppod.AnnotationNeedsUnmount] != "true" {
+			return r.reconcileLingeringMountpointPod(ctx, pod)
+		}
 	case corev1.PodSucceeded:
+		if pod.DeletionTimestamp != nil {
+			log.V(debugLevel).Info("Pod succeeded and is being cleaned up by the node plugin")
+			break
+		}
 		err := r.deleteMountpointPod(ctx, pod)
 		if err != nil {
 			log.Error(err, "Failed to delete succeeded Pod")
@@ -136,6 +177,10 @@ func (r *Reconciler) reconcileWorkloadPod(ctx context.Context, pod *corev1.Pod)
 		return reconcile.Result{}, nil
 	}
 
+	if mppod.ShouldReserveHeadroomForMountpointPod(pod) {
+		return r.reserveHeadroomForMountpointPods(ctx, pod)
+	}
+
 	scheduled := isPodScheduled(pod)
 	if !scheduled {
 		log.V(debugLevel).Info("Pod is not scheduled to a node yet - ignoring")
@@ -147,26 +192,53 @@ func (r *Reconciler) reconcileWorkloadPod(ctx context.Context, pod *corev1.Pod)
 		return reconcile.Result{}, err
 	}
 
-	var errs []error
-
+	// Each volume has its own MountpointS3PodAttachment, so volumes are handled concurrently to create
+	// all attachments of Pods with many volumes in a single pass instead of one after the other
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		errs []error
+	)
+	sem := make(chan struct{}, maxConcurrentVolumes)
 	for _, vol := range volumes {
 		pv, pvc := vol.pv, vol.pvc
 
-		log.V(debugLevel).Info("Found bound PV for PVC", "pvc", pvc.Name, "volumeName", pv.Name)
-
-		needsRequeue, err := r.spawnOrDeleteMountpointPodIfNeeded(ctx, pod, pvc, pv)
-		requeue = requeue || needsRequeue
-		if err != nil {
-			errs = append(errs, err)
-			continue
+		if pvc != nil {
+			log.V(debugLevel).Info("Found bound PV for PVC", "pvc", pvc.Name, "volumeName", pv.Name)
+		} else {
+			log.V(debugLevel).Info("Found inline volume", "volumeName", pv.Name)
 		}
+
+		sem <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			needsRequeue, err := r.spawnOrDeleteMountpointPodIfNeeded(ctx, pod, pvc, pv)
+
+			mu.Lock()
+			defer mu.Unlock()
+			requeue = requeue || needsRequeue
+			if err != nil {
+				errs = append(errs, err)
+			}
+		})
 	}
+	wg.Wait()
 
 	err = errors.Join(errs...)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
 
+	if mppod.WorkloadHasLabelPodForHeadroomPod(pod) {
+		reserved, err := r.releaseHeadroomPods(ctx, pod, volumes)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if reserved {
+			return reconcile.Result{Requeue: requeue, RequeueAfter: headroomRecheckInterval}, nil
+		}
+	}
+
 	return reconcile.Result{Requeue: requeue}, nil
 }
 
@@ -179,8 +251,16 @@ func (r *Reconciler) getWorkloadVolumes(
 
 	var errs []error
 	var volumes []*workloadVolume
+	seen := make(map[string]bool)
 
 	for _, vol := range workloadPod.Spec.Volumes {
+		if vol.CSI != nil {
+			if pv := r.inlineVolume(ctx, workloadPod, vol); pv != nil {
+				volumes = append(volumes, &workloadVolume{pv: pv, csiSpec: pv.Spec.CSI})
+			}
+			continue
+		}
+
 		podPVC := vol.PersistentVolumeClaim
 		if podPVC == nil {
 			continue
@@ -204,6 +284,18 @@ func (r *Reconciler) getWorkloadVolumes(
 			continue
 		}
 
+		// A MountpointS3PodAttachment cannot be created for these mount options, retrying would not help
+		if length := len(strings.Join(pv.Spec.MountOptions, ",")); length > crdv2.MaxMountOptionsLength {
+			logf.FromContext(ctx).Error(errMountOptionsTooLong, "Ignoring volume", "pv", pv.Name, "length", length, "maxLength", crdv2.MaxMountOptionsLength)
+			continue
+		}
+
+		// The same claim can be used by several volumes of the Pod, they share a single attachment
+		if seen[pv.Name] {
+			continue
+		}
+		seen[pv.Name] = true
+
 		volumes = append(volumes, &workloadVolume{pv, pvc, csiSpec})
 	}
 
@@ -211,6 +303,7 @@ func (r *Reconciler) getWorkloadVolumes(
 }
 
 // A workloadVolume represents a workload's volume backed by the CSI Driver.
+// `pvc` is nil for inline volumes, whose `pv` is built from the Pod spec, see [Reconciler.inlineVolume].
 type workloadVolume struct {
 	pv      *corev1.PersistentVolume
 	pvc     *corev1.PersistentVolumeClaim
@@ -232,7 +325,7 @@ func (r *Reconciler) spawnOrDeleteMountpointPodIfNeeded(
 	pv *corev1.PersistentVolume,
 ) (bool, error) {
 	workloadUID := string(workloadPod.UID)
-	fieldFilters := r.buildFieldFilters(workloadPod, pv)
+	fieldFilters := r.buildFieldFilters(workloadPod, pvc, pv)
 	s3pa, err := r.getExistingS3PodAttachment(ctx, fieldFilters)
 	if err != nil {
 		return Requeue, err
@@ -243,6 +336,19 @@ func (r *Reconciler) spawnOrDeleteMountpointPodIfNeeded(
 		return r.handleInactivePod(ctx, s3pa, workloadUID, fieldFilters, log)
 	}
 
+	if pvc != nil {
+		escalated, err := r.mountFailuresEscalated(ctx, pvc, pv, log)
+		if err != nil || escalated {
+			// Retry with a backoff in case the escalation is lifted manually
+			return Requeue, err
+		}
+	}
+
+	if r.writableMountRejected(workloadPod, pvc, pv, log) {
+		// Retry with a backoff in case the access policy is changed
+		return Requeue, nil
+	}
+
 	if s3pa != nil {
 		return r.handleExistingS3PodAttachment(ctx, workloadPod, pv, s3pa, fieldFilters, log)
 	} else {
@@ -262,10 +368,13 @@ func (r *Reconciler) setupLogger(
 ) logr.Logger {
 	logger := logf.FromContext(ctx).WithValues(
 		"workloadPod", types.NamespacedName{Namespace: workloadPod.Namespace, Name: workloadPod.Name},
-		"pvc", pvc.Name,
 		"workloadUID", workloadUID,
 	)
 
+	if pvc != nil {
+		logger = logger.WithValues("pvc", pvc.Name)
+	}
+
 	if s3pa != nil {
 		logger = logger.WithValues("s3pa", s3pa.Name)
 	}
@@ -283,15 +392,16 @@ func (r *Reconciler) setupLogger(
 }
 
 // buildFieldFilters build appropriate matching field filters for List operation on MountpointS3PodAttachments
-func (r *Reconciler) buildFieldFilters(workloadPod *corev1.Pod, pv *corev1.PersistentVolume) client.MatchingFields {
+func (r *Reconciler) buildFieldFilters(workloadPod *corev1.Pod, pvc *corev1.PersistentVolumeClaim, pv *corev1.PersistentVolume) client.MatchingFields {
 	fsGroup := r.getFSGroup(workloadPod)
 
 	fieldFilters := client.MatchingFields{
 		crdv2.FieldNodeName:             workloadPod.Spec.NodeName,
 		crdv2.FieldPersistentVolumeName: pv.Name,
 		crdv2.FieldVolumeID:             pv.Spec.CSI.VolumeHandle,
-		crdv2.FieldMountOptions:         strings.Join(pv.Spec.MountOptions, ","),
+		crdv2.FieldMountOptions:         workloadMountOptions(pvc, pv),
 		crdv2.FieldWorkloadFSGroup:      fsGroup,
+		crdv2.FieldCredentialIdentity:   credentialIdentity(workloadPod, pv),
 	}
 
 	return fieldFilters
@@ -307,6 +417,7 @@ func (r *Reconciler) getFSGroup(workloadPod *corev1.Pod) string {
 }
 
 // getExistingS3PodAttachment retrieves a MountpointS3PodAttachment resource that matches the provided field filters.
+// MountpointS3PodAttachments being deleted are ignored, they wait for their Mountpoint Pods to be cleaned up.
 // It returns:
 // - The matching MountpointS3PodAttachment if exactly one is found
 // - nil if no matching resource is found
@@ -317,13 +428,20 @@ func (r *Reconciler) getExistingS3PodAttachment(ctx context.Context, fieldFilter
 		return nil, fmt.Errorf("failed to list MountpointS3PodAttachments: %w", err)
 	}
 
-	switch len(s3paList.Items) {
+	var s3pas []*crdv2.MountpointS3PodAttachment
+	for i := range s3paList.Items {
+		if s3paList.Items[i].DeletionTimestamp == nil {
+			s3pas = append(s3pas, &s3paList.Items[i])
+		}
+	}
+
+	switch len(s3pas) {
 	case 0:
 		return nil, nil
 	case 1:
-		return &s3paList.Items[0], nil
+		return s3pas[0], nil
 	default:
-		return nil, fmt.Errorf("found %d MountpointS3PodAttachments when expecting 0 or 1", len(s3paList.Items))
+		return nil, fmt.Errorf("found %d MountpointS3PodAttachments when expecting 0 or 1", len(s3pas))
 	}
 }
 
@@ -397,8 +515,8 @@ func (r *Reconciler) addWorkloadToS3PodAttachment(
 	if err != nil {
 		log.Error(err, "Failed to update MountpointS3PodAttachment, deleting spawned Mountpoint Pod", "mountpointPodName", mpPod.Name)
 
-		// Clean up spawned Mountpoint Pod
-		if deleteErr := r.Delete(ctx, mpPod); deleteErr != nil {
+		// Clean up spawned Mountpoint Pod, it did not mount its volume yet
+		if deleteErr := r.deleteUnmountedMountpointPod(ctx, mpPod); deleteErr != nil {
 			log.Error(deleteErr, "Failed to cleanup Mountpoint Pod after MountpointS3PodAttachment update failure", "mountpointPodName", mpPod.Name)
 		} else {
 			log.Info("Successfully cleaned up Mountpoint Pod after S3PodAttachment update failure", "mountpointPodName", mpPod.Name)
@@ -426,7 +544,7 @@ var errNoSuitableMountpointPodForTheWorkload = errors.New("no suitable Mountpoin
 func (r *Reconciler) assignWorkloadToAnExistingMountpointPod(ctx context.Context, s3pa *crdv2.MountpointS3PodAttachment, workloadUID string, log logr.Logger) (bool, error) {
 	log.Info("Trying to assign workload to an existing Mountpoint Pod")
 
-	found := false
+	var assignedMPPod *corev1.Pod
 
 	for mpPodName := range s3pa.Spec.MountpointS3PodAttachments {
 		mpPodLog := log.WithValues("mountpointPodName", mpPodName)
@@ -448,12 +566,12 @@ func (r *Reconciler) assignWorkloadToAnExistingMountpointPod(ctx context.Context
 			WorkloadPodUID: workloadUID,
 			AttachmentTime: metav1.NewTime(time.Now().UTC()),
 		})
-		found = true
+		assignedMPPod = mpPod
 		mpPodLog.Info("Found a suitable Mountpoint Pod to assign new workload")
 		break
 	}
 
-	if !found {
+	if assignedMPPod == nil {
 		return DontRequeue, errNoSuitableMountpointPodForTheWorkload
 	}
 
@@ -467,10 +585,20 @@ func (r *Reconciler) assignWorkloadToAnExistingMountpointPod(ctx context.Context
 		return Requeue, err
 	}
 
+	if _, lingering := r.lingerRemaining(assignedMPPod, time.Now()); lingering {
+		log.Info("Reusing lingering Mountpoint Pod for the workload", "mountpointPodName", assignedMPPod.Name)
+		lingerHitsTotal.Inc()
+		if err := r.clearLingeringAnnotation(ctx, assignedMPPod); err != nil {
+			// Not fatal, the annotation will be cleared once the linger window expires
+			log.Error(err, "Failed to clear lingering annotation of Mountpoint Pod", "mountpointPodName", assignedMPPod.Name)
+		}
+	}
+
 	return DontRequeue, nil
 }
 
 // removeWorkloadFromS3PodAttachment removes workload UID from MountpointS3PodAttachment map.
+// Mountpoint Pods left with zero workloads linger for reuse if a linger window is configured, otherwise they're unmounted.
 // It will delete MountpointS3PodAttachment if map becomes empty.
 func (r *Reconciler) removeWorkloadFromS3PodAttachment(ctx context.Context, s3pa *crdv2.MountpointS3PodAttachment, workloadUID string, fieldFilters client.MatchingFields, log logr.Logger) (bool, error) {
 	// Remove workload UID from mountpoint pods
@@ -503,9 +631,17 @@ func (r *Reconciler) removeWorkloadFromS3PodAttachment(ctx context.Context, s3pa
 	// Remove Mountpoint pods with zero workloads
 	for mpPodName, uids := range s3pa.Spec.MountpointS3PodAttachments {
 		if len(uids) == 0 {
+			lingering, err := r.lingerMountpointPod(ctx, mpPodName, log)
+			if err != nil {
+				return Requeue, err
+			}
+			if lingering {
+				continue
+			}
+
 			log.Info("Mountpoint pod has zero workload UIDs. Adding "+mppod.AnnotationNeedsUnmount+" annotation",
 				"mountpointPodName", mpPodName)
-			err := r.addNeedsUnmountAnnotation(ctx, mpPodName, log)
+			err = r.addNeedsUnmountAnnotation(ctx, mpPodName, log)
 			if err != nil {
 				return Requeue, err
 			}
@@ -570,7 +706,7 @@ func (r *Reconciler) handleNewS3PodAttachment(
 		return DontRequeue, nil
 	}
 
-	if err := r.createS3PodAttachmentWithMPPod(ctx, workloadPod, pv, log); err != nil {
+	if err := r.createS3PodAttachmentWithMPPod(ctx, workloadPod, pv, fieldFilters[crdv2.FieldMountOptions], log); err != nil {
 		return Requeue, err
 	}
 
@@ -579,10 +715,12 @@ func (r *Reconciler) handleNewS3PodAttachment(
 }
 
 // createS3PodAttachmentWithMPPod creates new MountpointS3PodAttachment resource and Mountpoint Pod for given workload and PV.
+// `mountOptions` are the mount options of the workload, see [workloadMountOptions].
 func (r *Reconciler) createS3PodAttachmentWithMPPod(
 	ctx context.Context,
 	workloadPod *corev1.Pod,
 	pv *corev1.PersistentVolume,
+	mountOptions string,
 	log logr.Logger,
 ) error {
 	mpPod, err := r.spawnMountpointPod(ctx, workloadPod, pv, log)
@@ -596,23 +734,37 @@ func (r *Reconciler) createS3PodAttachmentWithMPPod(
 			Labels: map[string]string{
 				mppod.LabelCSIDriverVersion: r.mountpointPodConfig.CSIDriverVersion,
 			},
+			Finalizers: []string{crdv2.FinalizerMountpointPodsCleanup},
 		},
 		Spec: crdv2.MountpointS3PodAttachmentSpec{
 			NodeName:             workloadPod.Spec.NodeName,
 			PersistentVolumeName: pv.Name,
 			VolumeID:             pv.Spec.CSI.VolumeHandle,
-			MountOptions:         strings.Join(pv.Spec.MountOptions, ","),
+			MountOptions:         mountOptions,
 			WorkloadFSGroup:      r.getFSGroup(workloadPod),
+			CredentialIdentity:   credentialIdentity(workloadPod, pv),
 			MountpointS3PodAttachments: map[string][]crdv2.WorkloadAttachment{
 				mpPod.Name: {{WorkloadPodUID: string(workloadPod.UID), AttachmentTime: metav1.NewTime(time.Now().UTC())}},
 			},
 		},
 	}
 
+	// Other driver instances installed in the cluster ignore the attachment, see [crdv2.DriverNameSelector]
+	maps.Copy(s3pa.Labels, crdv2.DriverNameLabels(mountpointCSIDriverName))
+
+	// Mounts of volumes in a read-only window are remounted read-only by the node, see [ReadOnlyWindowScheduler]
+	if maintenance.ReadOnly(pv.Annotations, time.Now()) {
+		setReadOnlyUntil(s3pa, pv.Annotations[maintenance.AnnotationReadOnlyUntil])
+	}
+	// Mounts of volumes above the hard limit of their prefix too, see [PrefixQuotaEnforcer]
+	if exceeded := pv.Annotations[maintenance.AnnotationQuotaExceeded]; exceeded != "" {
+		setQuotaExceeded(s3pa, exceeded)
+	}
+
 	err = r.Create(ctx, s3pa)
 	if err != nil {
 		log.Error(err, "Failed to create MountpointS3PodAttachment")
-		if deleteErr := r.Delete(ctx, mpPod); deleteErr != nil {
+		if deleteErr := r.deleteUnmountedMountpointPod(ctx, mpPod); deleteErr != nil {
 			log.Error(deleteErr, "Failed to cleanup Mountpoint Pod after MountpointS3PodAttachment creation failure", "mountpointPodName", mpPod.Name)
 		} else {
 			log.Info("Successfully cleaned up Mountpoint Pod after S3PodAttachment creation failure", "mountpointPodName", mpPod.Name)
@@ -635,9 +787,12 @@ func (r *Reconciler) spawnMountpointPod(
 ) (*corev1.Pod, error) {
 	log.Info("Spawning Mountpoint Pod")
 
-	mpPod := r.mountpointPodCreator.Create(workloadPod, pv)
+	mpPod, err := r.mountpointPodCreator.Create(workloadPod, pv)
+	if err != nil {
+		return nil, err
+	}
 
-	err := r.Create(ctx, mpPod)
+	err = r.Create(ctx, mpPod)
 	if err != nil {
 		return nil, err
 	}
@@ -666,6 +821,25 @@ func (r *Reconciler) deleteMountpointPod(ctx context.Context, mountpointPod *cor
 	return err
 }
 
+// deleteUnmountedMountpointPod deletes `mpPod` that never mounted its volume, so without waiting for the node plugin to
+// clean it up. `opts` are passed to the deletion.
+func (r *Reconciler) deleteUnmountedMountpointPod(ctx context.Context, mpPod *corev1.Pod, opts ...client.DeleteOption) error {
+	if err := r.removeMountCleanupFinalizer(ctx, mpPod); err != nil {
+		return err
+	}
+	return r.Delete(ctx, mpPod, opts...)
+}
+
+// removeMountCleanupFinalizer removes the [mppod.FinalizerMountCleanup] finalizer of `mpPod`, for Mountpoint Pods with
+// nothing left for the node plugin to clean up.
+func (r *Reconciler) removeMountCleanupFinalizer(ctx context.Context, mpPod *corev1.Pod) error {
+	patch := client.MergeFrom(mpPod.DeepCopy())
+	if !controllerutil.RemoveFinalizer(mpPod, mppod.FinalizerMountCleanup) {
+		return nil
+	}
+	return client.IgnoreNotFound(r.Patch(ctx, mpPod, patch))
+}
+
 // getMountpointPod tries to find Mountpoint Pod with given `name`.
 func (r *Reconciler) getMountpointPod(ctx context.Context, name string) (*corev1.Pod, error) {
 	mpPod := &corev1.Pod{}
@@ -678,6 +852,12 @@ func (r *Reconciler) getMountpointPod(ctx context.Context, name string) (*corev1
 
 // shouldAssignNewWorkloadToMountpointPod returns whether a new workload should be assigned to the Mountpoint Pod `mpPod`.
 func (r *Reconciler) shouldAssignNewWorkloadToMountpointPod(mpPod *corev1.Pod, log logr.Logger) bool {
+	if mpPod.DeletionTimestamp != nil {
+		// Deleted Mountpoint Pods only keep running until their workloads release their mount, e.g. on node drains
+		log.Info("Mountpoint Pod is being deleted - not suitable for a new workload")
+		return false
+	}
+
 	if mpPod.Annotations != nil {
 		if mpPod.Annotations[mppod.AnnotationNeedsUnmount] == "true" {
 			log.Info("Mountpoint Pod is annotated as 'needs-unmount' - not suitable for a new workload")
@@ -690,13 +870,9 @@ func (r *Reconciler) shouldAssignNewWorkloadToMountpointPod(mpPod *corev1.Pod, l
 		}
 	}
 
-	if mpPod.Labels != nil {
-		if mpPod.Labels[mppod.LabelCSIDriverVersion] != r.mountpointPodConfig.CSIDriverVersion {
-			log.Info("Mountpoint Pod is created with a different CSI Driver version - not suitable for a new workload",
-				"mountpointPodCreatedByCSIDriverVersion", mpPod.Labels[mppod.LabelCSIDriverVersion],
-				"currentCSIDriverVersion", r.mountpointPodConfig.CSIDriverVersion)
-			return false
-		}
+	if reason := r.mountpointPodOutdatedReason(mpPod); reason != "" {
+		log.Info("Mountpoint Pod is outdated - not suitable for a new workload", "reason", reason)
+		return false
 	}
 
 	return true
@@ -720,6 +896,7 @@ func (r *Reconciler) addNeedsUnmountAnnotation(ctx context.Context, mpPodName st
 		mpPod.Annotations = make(map[string]string)
 	}
 	mpPod.Annotations[mppod.AnnotationNeedsUnmount] = "true"
+	delete(mpPod.Annotations, mppod.AnnotationLingeringSince)
 
 	// Update the pod
 	err = r.Update(ctx, mpPod) // TODO: This probably needs to be a patch as we might've get a stale Mountpoint Pod.
@@ -741,6 +918,10 @@ func (r *Reconciler) isInMountpointNamespace(pod *corev1.Pod) bool {
 // to be retried later.
 var errPVCIsNotBoundToAPV = errors.New("PVC is not bound to a PV yet")
 
+// errMountOptionsTooLong is logged for volumes whose mount options exceed [crdv2.MaxMountOptionsLength].
+// This is a terminal error, as the PV's mount options need to be changed to mount the volume.
+var errMountOptionsTooLong = errors.New("mount options of the PV are too long")
+
 // getBoundPVForPodClaim tries to find bound PV and PVC from given `claim`.
 // It `errPVCIsNotBoundToAPV` if PVC is not bound to a PV yet to be eventually retried.
 func (r *Reconciler) getBoundPVForPodClaim(
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"runtime"
+	"strings"
 	"testing"
 	"time"
 
@@ -46,6 +47,11 @@ func init() {
 
 // testReconciler creates a test reconciler with a fake client
 func testReconciler(objects ...client.Object) (*csicontroller.Reconciler, client.Client) {
+	return testReconcilerWithConfig(func(*mppod.Config) {}, objects...)
+}
+
+// testReconcilerWithConfig creates a test reconciler with a fake client, allowing `configure` to alter the Mountpoint Pod config
+func testReconcilerWithConfig(configure func(*mppod.Config), objects ...client.Object) (*csicontroller.Reconciler, client.Client) {
 	s := k8sruntime.NewScheme()
 	_ = scheme.AddToScheme(s)
 	_ = crdv2.AddToScheme(s)
@@ -53,6 +59,7 @@ func testReconciler(objects ...client.Object) (*csicontroller.Reconciler, client
 	fakeClient := fake.NewClientBuilder().
 		WithScheme(s).
 		WithObjects(objects...).
+		WithStatusSubresource(&corev1.Pod{}, &crdv2.MountpointS3PodAttachment{}).
 		WithIndex(&crdv2.MountpointS3PodAttachment{}, crdv2.FieldNodeName, func(o client.Object) []string {
 			s3pa := o.(*crdv2.MountpointS3PodAttachment)
 			return []string{s3pa.Spec.NodeName}
@@ -73,6 +80,10 @@ func testReconciler(objects ...client.Object) (*csicontroller.Reconciler, client
 			s3pa := o.(*crdv2.MountpointS3PodAttachment)
 			return []string{s3pa.Spec.WorkloadFSGroup}
 		}).
+		WithIndex(&crdv2.MountpointS3PodAttachment{}, crdv2.FieldCredentialIdentity, func(o client.Object) []string {
+			s3pa := o.(*crdv2.MountpointS3PodAttachment)
+			return []string{s3pa.Spec.CredentialIdentity}
+		}).
 		Build()
 
 	config := mppod.Config{
@@ -87,6 +98,7 @@ func testReconciler(objects ...client.Object) (*csicontroller.Reconciler, client
 		CSIDriverVersion: testCSIDriverVersion,
 		ClusterVariant:   cluster.DefaultKubernetes,
 	}
+	configure(&config)
 
 	reconciler := csicontroller.NewReconciler(fakeClient, config)
 	return reconciler, fakeClient
@@ -318,6 +330,45 @@ func TestReconciler_Reconcile(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "Workload pod with S3 volume with too long mount options - should ignore",
+			objects: []client.Object{
+				createTestPod(testPodName, testNamespace, testNodeName, []corev1.Volume{
+					{
+						Name: "test-volume",
+						VolumeSource: corev1.VolumeSource{
+							PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+								ClaimName: testPVCName,
+							},
+						},
+					},
+				}),
+				createTestPVC(testPVCName, testNamespace, testPVName),
+				func() *corev1.PersistentVolume {
+					pv := createTestPV(testPVName, testPVCName, testNamespace)
+					pv.Spec.MountOptions = []string{"prefix=" + strings.Repeat("a", crdv2.MaxMountOptionsLength)}
+					return pv
+				}(),
+			},
+			request: reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      testPodName,
+					Namespace: testNamespace,
+				},
+			},
+			expectedResult: reconcile.Result{},
+			expectedError:  false,
+			validateFunc: func(t *testing.T, c client.Client) {
+				s3paList := &crdv2.MountpointS3PodAttachmentList{}
+				err := c.List(context.Background(), s3paList)
+				if err != nil {
+					t.Fatalf("Failed to list S3PodAttachments: %v", err)
+				}
+				if len(s3paList.Items) != 0 {
+					t.Errorf("Expected no S3PodAttachment, got %d", len(s3paList.Items))
+				}
+			},
+		},
 		{
 			name: "Inactive workload pod with S3PodAttachment - should remove from attachment",
 			objects: []client.Object{
@@ -682,6 +733,205 @@ func TestReconciler_IsPodActive(t *testing.T) {
 	}
 }
 
+// TestReconciler_SharesMountpointPod tests that workloads using the same volume on the same node share a Mountpoint Pod,
+// and that it is only unmounted once the last of them is gone.
+func TestReconciler_SharesMountpointPod(t *testing.T) {
+	ctx := context.Background()
+	volumes := []corev1.Volume{{
+		Name: "test-volume",
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: testPVCName},
+		},
+	}}
+	workload1 := createTestPod("workload-1", testNamespace, testNodeName, volumes)
+	workload2 := createTestPod("workload-2", testNamespace, testNodeName, volumes)
+	otherFSGroup := createTestPod("workload-3", testNamespace, testNodeName, volumes)
+	otherFSGroup.Spec.SecurityContext = &corev1.PodSecurityContext{FSGroup: ptr.To(int64(2000))}
+
+	reconciler, c := testReconciler(workload1, workload2, otherFSGroup,
+		createTestPVC(testPVCName, testNamespace, testPVName),
+		createTestPV(testPVName, testPVCName, testNamespace))
+
+	reconcilePod := func(pod *corev1.Pod) {
+		t.Helper()
+		if _, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}}); err != nil {
+			t.Fatalf("Failed to reconcile %s: %v", pod.Name, err)
+		}
+	}
+	listS3PAs := func() []crdv2.MountpointS3PodAttachment {
+		t.Helper()
+		s3paList := &crdv2.MountpointS3PodAttachmentList{}
+		if err := c.List(ctx, s3paList); err != nil {
+			t.Fatalf("Failed to list MountpointS3PodAttachments: %v", err)
+		}
+		return s3paList.Items
+	}
+	listMountpointPods := func() []corev1.Pod {
+		t.Helper()
+		podList := &corev1.PodList{}
+		if err := c.List(ctx, podList, client.InNamespace(mountpointNamespace)); err != nil {
+			t.Fatalf("Failed to list Mountpoint Pods: %v", err)
+		}
+		return podList.Items
+	}
+
+	reconcilePod(workload1)
+	reconcilePod(workload2)
+	s3pas := listS3PAs()
+	if len(s3pas) != 1 || len(s3pas[0].Spec.MountpointS3PodAttachments) != 1 {
+		t.Fatalf("Expected a single MountpointS3PodAttachment with a single Mountpoint Pod, got %+v", s3pas)
+	}
+	for mpPodName, attachments := range s3pas[0].Spec.MountpointS3PodAttachments {
+		if len(attachments) != 2 {
+			t.Fatalf("Expected both workloads to be attached to Mountpoint Pod %s, got %+v", mpPodName, attachments)
+		}
+	}
+
+	// Workloads with a different fsGroup need their own Mountpoint Pod
+	reconcilePod(otherFSGroup)
+	if got := len(listMountpointPods()); got != 2 {
+		t.Fatalf("Expected 2 Mountpoint Pods, got %d", got)
+	}
+
+	complete := func(pod *corev1.Pod) {
+		t.Helper()
+		pod.Status.Phase = corev1.PodSucceeded
+		if err := c.Status().Update(ctx, pod); err != nil {
+			t.Fatalf("Failed to update %s: %v", pod.Name, err)
+		}
+		reconcilePod(pod)
+	}
+	needsUnmount := func() int {
+		t.Helper()
+		count := 0
+		for _, mpPod := range listMountpointPods() {
+			if mpPod.Annotations[mppod.AnnotationNeedsUnmount] == "true" {
+				count++
+			}
+		}
+		return count
+	}
+
+	// The shared Mountpoint Pod is kept until its last workload is gone
+	complete(workload1)
+	if got := needsUnmount(); got != 0 {
+		t.Fatalf("Expected no Mountpoint Pod to be unmounted while a workload uses it, got %d", got)
+	}
+	complete(workload2)
+	if got := needsUnmount(); got != 1 {
+		t.Fatalf("Expected the shared Mountpoint Pod to be unmounted after its last workload, got %d", got)
+	}
+}
+
+// TestReconciler_SeparatesCredentialIdentities tests that workloads of a `webIdentity` volume with different service
+// accounts get their own Mountpoint Pod, as Mountpoint assumes the role with the token of a single service account.
+func TestReconciler_SeparatesCredentialIdentities(t *testing.T) {
+	ctx := context.Background()
+	volumes := []corev1.Volume{{
+		Name: "test-volume",
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: testPVCName},
+		},
+	}}
+	reader1 := createTestPod("reader-1", testNamespace, testNodeName, volumes)
+	reader1.Spec.ServiceAccountName = "reader"
+	reader2 := createTestPod("reader-2", testNamespace, testNodeName, volumes)
+	reader2.Spec.ServiceAccountName = "reader"
+	writer := createTestPod("writer", testNamespace, testNodeName, volumes)
+	writer.Spec.ServiceAccountName = "writer"
+	pv := createTestPV(testPVName, testPVCName, testNamespace)
+	pv.Spec.CSI.VolumeAttributes["authenticationSource"] = "webIdentity"
+	pv.Spec.CSI.VolumeAttributes["roleArn"] = "arn:aws:iam::123456789012:role/bucket"
+
+	reconciler, c := testReconciler(reader1, reader2, writer, createTestPVC(testPVCName, testNamespace, testPVName), pv)
+	for _, pod := range []*corev1.Pod{reader1, reader2, writer} {
+		if _, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}}); err != nil {
+			t.Fatalf("Failed to reconcile %s: %v", pod.Name, err)
+		}
+	}
+
+	s3paList := &crdv2.MountpointS3PodAttachmentList{}
+	if err := c.List(ctx, s3paList); err != nil {
+		t.Fatalf("Failed to list MountpointS3PodAttachments: %v", err)
+	}
+	workloadsByIdentity := map[string]int{}
+	for _, s3pa := range s3paList.Items {
+		for _, attachments := range s3pa.Spec.MountpointS3PodAttachments {
+			workloadsByIdentity[s3pa.Spec.CredentialIdentity] += len(attachments)
+		}
+	}
+	if len(workloadsByIdentity) != 2 || workloadsByIdentity["webIdentity:"+testNamespace+"/reader"] != 2 ||
+		workloadsByIdentity["webIdentity:"+testNamespace+"/writer"] != 1 {
+		t.Fatalf("Expected workloads to be attached by service account, got %v", workloadsByIdentity)
+	}
+
+	podList := &corev1.PodList{}
+	if err := c.List(ctx, podList, client.InNamespace(mountpointNamespace)); err != nil {
+		t.Fatalf("Failed to list Mountpoint Pods: %v", err)
+	}
+	if got := len(podList.Items); got != 2 {
+		t.Fatalf("Expected a Mountpoint Pod per service account, got %d", got)
+	}
+}
+
+func TestReconciler_ManyVolumes(t *testing.T) {
+	ctx := context.Background()
+	const numVolumes = 12
+
+	var volumes []corev1.Volume
+	var objects []client.Object
+	for i := range numVolumes {
+		pvcName, pvName := fmt.Sprintf("pvc-%d", i), fmt.Sprintf("pv-%d", i)
+		volumes = append(volumes, corev1.Volume{
+			Name: fmt.Sprintf("volume-%d", i),
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: pvcName},
+			},
+		})
+		objects = append(objects, createTestPVC(pvcName, testNamespace, pvName), createTestPV(pvName, pvcName, testNamespace))
+	}
+	// A claim used by several volumes of the Pod gets a single attachment
+	volumes = append(volumes, corev1.Volume{
+		Name: "volume-0-again",
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "pvc-0"},
+		},
+	})
+	pod := createTestPod(testPodName, testNamespace, testNodeName, volumes)
+	reconciler, c := testReconciler(append(objects, pod)...)
+
+	result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}})
+	if err != nil {
+		t.Fatalf("Failed to reconcile: %v", err)
+	}
+	if !result.Requeue {
+		t.Errorf("Expected a requeue until attachments appear in the cache")
+	}
+
+	s3paList := &crdv2.MountpointS3PodAttachmentList{}
+	if err := c.List(ctx, s3paList); err != nil {
+		t.Fatalf("Failed to list MountpointS3PodAttachments: %v", err)
+	}
+	if len(s3paList.Items) != numVolumes {
+		t.Fatalf("Expected %d MountpointS3PodAttachments in a single pass, got %d", numVolumes, len(s3paList.Items))
+	}
+	pvNames := make(map[string]bool)
+	for _, s3pa := range s3paList.Items {
+		pvNames[s3pa.Spec.PersistentVolumeName] = true
+	}
+	if len(pvNames) != numVolumes {
+		t.Fatalf("Expected a MountpointS3PodAttachment per volume, got %v", pvNames)
+	}
+
+	podList := &corev1.PodList{}
+	if err := c.List(ctx, podList, client.InNamespace(mountpointNamespace)); err != nil {
+		t.Fatalf("Failed to list Mountpoint Pods: %v", err)
+	}
+	if len(podList.Items) != numVolumes {
+		t.Fatalf("Expected %d Mountpoint Pods, got %d", numVolumes, len(podList.Items))
+	}
+}
+
 // TestReconciler_Performance tests that reconciliation completes within acceptable time limits
 func TestReconciler_Performance(t *testing.T) {
 	// Performance thresholds
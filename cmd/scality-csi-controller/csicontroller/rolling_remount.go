@@ -0,0 +1,227 @@
+package csicontroller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
+)
+
+// AnnotationRollingRemount opts a Persistent Volume in rolling remounts: when its mount options change, workloads
+// still mounting it with the previous options are evicted one at a time, so their controllers recreate them with the
+// current options.
+const AnnotationRollingRemount = constants.DriverName + "/rolling-remount"
+
+// Reasons of the [crdv2.ConditionMountOptionsUpToDate] condition.
+const (
+	ReasonMountOptionsUpToDate   = "UpToDate"
+	ReasonMountOptionsOutdated   = "Outdated"
+	ReasonMountOptionsRemounting = "Remounting"
+)
+
+// EventReasonEvictedForRemount is the reason of events emitted on workloads evicted by the [RollingRemounter].
+const EventReasonEvictedForRemount = "EvictedForRemount"
+
+// rollingRemountInterval is how often workloads are checked against the mount options of their volume, at most one
+// workload per volume is evicted per check.
+const rollingRemountInterval = 30 * time.Second
+
+// A RollingRemounter rolls workloads out to the current mount options of their Persistent Volume after they change.
+//
+// Mount options of a volume only apply to new mounts: the MountpointS3PodAttachments of existing workloads keep the
+// options they were mounted with, and new workloads get a new attachment. For volumes with [AnnotationRollingRemount],
+// workloads of outdated attachments are evicted one at a time, waiting for each evicted workload to terminate before
+// evicting the next one. Evictions respect PodDisruptionBudgets, and workloads without a controller are never evicted
+// as nothing would recreate them. Progress is reported with the [crdv2.ConditionMountOptionsUpToDate] condition of
+// each MountpointS3PodAttachment.
+type RollingRemounter struct {
+	reconciler *Reconciler
+}
+
+// NewRollingRemounter creates a new [RollingRemounter].
+func NewRollingRemounter(reconciler *Reconciler) *RollingRemounter {
+	return &RollingRemounter{
+		reconciler: reconciler,
+	}
+}
+
+// Start begins the periodic rolling remounts of workloads.
+func (rr *RollingRemounter) Start(ctx context.Context) error {
+	log := logf.FromContext(ctx)
+	log.Info("Starting rolling remounter", "interval", rollingRemountInterval)
+
+	ticker := time.NewTicker(rollingRemountInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Completed rolling remounter")
+			return nil
+		case <-ticker.C:
+			if err := rr.RunRemount(ctx); err != nil {
+				log.Error(err, "Failed to remount workloads")
+				// Continue running even if the rollout fails
+			}
+		}
+	}
+}
+
+// RunRemount evicts the next workload of each volume opted in rolling remounts whose mount options changed, and
+// updates the conditions of all MountpointS3PodAttachments of Persistent Volumes.
+func (rr *RollingRemounter) RunRemount(ctx context.Context) error {
+	s3paList := &crdv2.MountpointS3PodAttachmentList{}
+	if err := rr.reconciler.List(ctx, s3paList); err != nil {
+		return err
+	}
+	byPV := make(map[string][]*crdv2.MountpointS3PodAttachment)
+	for i := range s3paList.Items {
+		s3pa := &s3paList.Items[i]
+		if s3pa.Spec.PersistentVolumeName != "" {
+			byPV[s3pa.Spec.PersistentVolumeName] = append(byPV[s3pa.Spec.PersistentVolumeName], s3pa)
+		}
+	}
+	if len(byPV) == 0 {
+		outdatedMountOptionsWorkloads.Set(0)
+		return nil
+	}
+
+	podList := &corev1.PodList{}
+	if err := rr.reconciler.List(ctx, podList); err != nil {
+		return err
+	}
+	podsByUID := make(map[string]*corev1.Pod, len(podList.Items))
+	for i := range podList.Items {
+		podsByUID[string(podList.Items[i].UID)] = &podList.Items[i]
+	}
+
+	total := 0
+	var errs []error
+	for _, pvName := range slices.Sorted(maps.Keys(byPV)) {
+		outdated, err := rr.remount(ctx, pvName, byPV[pvName], podsByUID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to remount workloads of PV %s: %w", pvName, err))
+		}
+		total += outdated
+	}
+	outdatedMountOptionsWorkloads.Set(float64(total))
+	return errors.Join(errs...)
+}
+
+// remount evicts the next workload of `s3pas` of `pvName` mounted with previous mount options if the volume is opted
+// in rolling remounts, updates the conditions of `s3pas` and returns the number of outdated workloads.
+func (rr *RollingRemounter) remount(ctx context.Context, pvName string, s3pas []*crdv2.MountpointS3PodAttachment, podsByUID map[string]*corev1.Pod) (int, error) {
+	log := logf.FromContext(ctx).WithValues("pv", pvName)
+
+	pv := &corev1.PersistentVolume{}
+	if err := rr.reconciler.Get(ctx, types.NamespacedName{Name: pvName}, pv); err != nil {
+		return 0, client.IgnoreNotFound(err)
+	}
+	var pvc *corev1.PersistentVolumeClaim
+	if claimRef := pv.Spec.ClaimRef; claimRef != nil {
+		pvc = &corev1.PersistentVolumeClaim{}
+		if err := rr.reconciler.Get(ctx, types.NamespacedName{Namespace: claimRef.Namespace, Name: claimRef.Name}, pvc); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return 0, err
+			}
+			pvc = nil
+		}
+	}
+	current := workloadMountOptions(pvc, pv)
+	enabled := pv.Annotations[AnnotationRollingRemount] == "true"
+
+	// Workloads of outdated attachments, oldest first
+	var outdated, evictable []*corev1.Pod
+	terminating, unmanaged := 0, 0
+	for _, s3pa := range s3pas {
+		if s3pa.Spec.MountOptions == current {
+			continue
+		}
+		for _, attachments := range s3pa.Spec.MountpointS3PodAttachments {
+			for _, attachment := range attachments {
+				pod, ok := podsByUID[attachment.WorkloadPodUID]
+				if !ok {
+					continue
+				}
+				outdated = append(outdated, pod)
+				switch {
+				case pod.DeletionTimestamp != nil:
+					terminating++
+				case metav1.GetControllerOf(pod) != nil:
+					evictable = append(evictable, pod)
+				default:
+					unmanaged++
+				}
+			}
+		}
+	}
+	slices.SortFunc(evictable, func(a, b *corev1.Pod) int {
+		return a.CreationTimestamp.Compare(b.CreationTimestamp.Time)
+	})
+
+	if enabled && terminating == 0 && len(evictable) > 0 {
+		pod := evictable[0]
+		err := rr.reconciler.SubResource("eviction").Create(ctx, pod, &policyv1.Eviction{
+			ObjectMeta: metav1.ObjectMeta{Namespace: pod.Namespace, Name: pod.Name},
+		})
+		switch {
+		case err == nil:
+			terminating++
+			log.Info("Evicted workload to remount it with the current mount options", "pod", client.ObjectKeyFromObject(pod),
+				"mountOptions", current)
+			if rr.reconciler.recorder != nil {
+				rr.reconciler.recorder.Eventf(pod, corev1.EventTypeNormal, EventReasonEvictedForRemount,
+					"Evicted to remount volume %s with its current mount options", pvName)
+			}
+		case apierrors.IsTooManyRequests(err):
+			// Blocked by a PodDisruptionBudget, retried in the next run
+			log.Info("Eviction of workload blocked by a PodDisruptionBudget", "pod", client.ObjectKeyFromObject(pod))
+		case !apierrors.IsNotFound(err):
+			return len(outdated), err
+		}
+	}
+
+	var errs []error
+	for _, s3pa := range s3pas {
+		condition := metav1.Condition{
+			Type:               crdv2.ConditionMountOptionsUpToDate,
+			Status:             metav1.ConditionTrue,
+			Reason:             ReasonMountOptionsUpToDate,
+			Message:            "Workloads are mounted with the current mount options of the volume",
+			ObservedGeneration: s3pa.Generation,
+		}
+		if s3pa.Spec.MountOptions != current {
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = ReasonMountOptionsOutdated
+			condition.Message = fmt.Sprintf("The mount options of the volume changed, %d workload(s) of the volume are mounted with previous options. Annotate the volume with %s=true to remount them",
+				len(outdated), AnnotationRollingRemount)
+			if enabled {
+				condition.Reason = ReasonMountOptionsRemounting
+				condition.Message = fmt.Sprintf("Remounting %d workload(s) of the volume with its current mount options, %d terminating, %d without controller are not evicted",
+					len(outdated), terminating, unmanaged)
+			}
+		}
+		if !meta.SetStatusCondition(&s3pa.Status.Conditions, condition) {
+			continue
+		}
+		if err := rr.reconciler.Status().Update(ctx, s3pa); err != nil && !apierrors.IsConflict(err) && !apierrors.IsNotFound(err) {
+			// Conflicting updates are retried in the next run
+			errs = append(errs, err)
+		}
+	}
+	return len(outdated), errors.Join(errs...)
+}
@@ -0,0 +1,144 @@
+package csicontroller_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/scality/mountpoint-s3-csi-driver/cmd/scality-csi-controller/csicontroller"
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+)
+
+func TestRollingRemounter(t *testing.T) {
+	ctx := context.Background()
+
+	// setup reconciles a workload of the test volume mounted with `allow-other`, then changes the mount options of
+	// the volume.
+	setup := func(t *testing.T, controlled bool, annotate bool) (*csicontroller.Reconciler, client.Client, *record.FakeRecorder) {
+		t.Helper()
+		pod := createTestPod(testPodName, testNamespace, testNodeName, pvcVolumes())
+		if controlled {
+			pod.OwnerReferences = []metav1.OwnerReference{{
+				APIVersion: "apps/v1",
+				Kind:       "ReplicaSet",
+				Name:       "test-rs",
+				UID:        "test-rs-uid",
+				Controller: ptr.To(true),
+			}}
+		}
+		pv := createTestPV(testPVName, testPVCName, testNamespace)
+		pv.Spec.MountOptions = []string{"allow-other"}
+		reconciler, c := testReconciler(pod, createTestPVC(testPVCName, testNamespace, testPVName), pv)
+		recorder := record.NewFakeRecorder(10)
+		reconciler.SetEventRecorder(recorder)
+
+		request := reconcile.Request{NamespacedName: types.NamespacedName{Name: testPodName, Namespace: testNamespace}}
+		if _, err := reconciler.Reconcile(ctx, request); err != nil {
+			t.Fatalf("Failed to reconcile workload: %v", err)
+		}
+
+		if err := c.Get(ctx, types.NamespacedName{Name: testPVName}, pv); err != nil {
+			t.Fatalf("Failed to get PV: %v", err)
+		}
+		pv.Spec.MountOptions = []string{"allow-other", "allow-delete"}
+		if annotate {
+			pv.Annotations = map[string]string{csicontroller.AnnotationRollingRemount: "true"}
+		}
+		if err := c.Update(ctx, pv); err != nil {
+			t.Fatalf("Failed to update PV: %v", err)
+		}
+		return reconciler, c, recorder
+	}
+
+	assertCondition := func(t *testing.T, c client.Client, status metav1.ConditionStatus, reason string) {
+		t.Helper()
+		s3paList := &crdv2.MountpointS3PodAttachmentList{}
+		if err := c.List(ctx, s3paList); err != nil {
+			t.Fatalf("Failed to list MountpointS3PodAttachments: %v", err)
+		}
+		if len(s3paList.Items) != 1 {
+			t.Fatalf("Expected a MountpointS3PodAttachment, got %d", len(s3paList.Items))
+		}
+		condition := meta.FindStatusCondition(s3paList.Items[0].Status.Conditions, crdv2.ConditionMountOptionsUpToDate)
+		if condition == nil || condition.Status != status || condition.Reason != reason {
+			t.Fatalf("Expected %s condition %s with reason %s, got %+v", crdv2.ConditionMountOptionsUpToDate, status, reason, condition)
+		}
+	}
+
+	assertPodExists := func(t *testing.T, c client.Client, exists bool) {
+		t.Helper()
+		err := c.Get(ctx, types.NamespacedName{Name: testPodName, Namespace: testNamespace}, &corev1.Pod{})
+		if exists && err != nil {
+			t.Fatalf("Expected workload not to be evicted, got %v", err)
+		}
+		if !exists && !apierrors.IsNotFound(err) {
+			t.Fatalf("Expected workload to be evicted, got %v", err)
+		}
+	}
+
+	t.Run("Workloads of volumes not opted in are reported outdated", func(t *testing.T) {
+		reconciler, c, _ := setup(t, true, false)
+
+		if err := csicontroller.NewRollingRemounter(reconciler).RunRemount(ctx); err != nil {
+			t.Fatalf("Failed to run rolling remount: %v", err)
+		}
+		assertPodExists(t, c, true)
+		assertCondition(t, c, metav1.ConditionFalse, csicontroller.ReasonMountOptionsOutdated)
+	})
+
+	t.Run("Controlled workloads of opted-in volumes are evicted", func(t *testing.T) {
+		reconciler, c, recorder := setup(t, true, true)
+
+		if err := csicontroller.NewRollingRemounter(reconciler).RunRemount(ctx); err != nil {
+			t.Fatalf("Failed to run rolling remount: %v", err)
+		}
+		assertPodExists(t, c, false)
+		assertCondition(t, c, metav1.ConditionFalse, csicontroller.ReasonMountOptionsRemounting)
+		select {
+		case event := <-recorder.Events:
+			if !strings.Contains(event, csicontroller.EventReasonEvictedForRemount) {
+				t.Errorf("Expected %s event, got %q", csicontroller.EventReasonEvictedForRemount, event)
+			}
+		default:
+			t.Errorf("Expected %s event", csicontroller.EventReasonEvictedForRemount)
+		}
+	})
+
+	t.Run("Workloads without controller are never evicted", func(t *testing.T) {
+		reconciler, c, _ := setup(t, false, true)
+
+		if err := csicontroller.NewRollingRemounter(reconciler).RunRemount(ctx); err != nil {
+			t.Fatalf("Failed to run rolling remount: %v", err)
+		}
+		assertPodExists(t, c, true)
+		assertCondition(t, c, metav1.ConditionFalse, csicontroller.ReasonMountOptionsRemounting)
+	})
+
+	t.Run("Workloads mounted with the current options are up to date", func(t *testing.T) {
+		reconciler, c, _ := setup(t, true, true)
+		pv := &corev1.PersistentVolume{}
+		if err := c.Get(ctx, types.NamespacedName{Name: testPVName}, pv); err != nil {
+			t.Fatalf("Failed to get PV: %v", err)
+		}
+		pv.Spec.MountOptions = []string{"allow-other"}
+		if err := c.Update(ctx, pv); err != nil {
+			t.Fatalf("Failed to update PV: %v", err)
+		}
+
+		if err := csicontroller.NewRollingRemounter(reconciler).RunRemount(ctx); err != nil {
+			t.Fatalf("Failed to run rolling remount: %v", err)
+		}
+		assertPodExists(t, c, true)
+		assertCondition(t, c, metav1.ConditionTrue, csicontroller.ReasonMountOptionsUpToDate)
+	})
+}
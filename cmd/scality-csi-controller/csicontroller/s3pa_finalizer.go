@@ -0,0 +1,151 @@
+package csicontroller
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+)
+
+// s3paFinalizerRecheckInterval is the interval between checks of the Mountpoint Pods a deleted
+// MountpointS3PodAttachment waits for.
+const s3paFinalizerRecheckInterval = 10 * time.Second
+
+// An S3PodAttachmentFinalizer ensures MountpointS3PodAttachments carry the [crdv2.FinalizerMountpointPodsCleanup]
+// finalizer, and only removes it once the Mountpoint Pods of a deleted MountpointS3PodAttachment are gone.
+//
+// Mountpoint Pods of a MountpointS3PodAttachment are the Mountpoint Pods of its node and volume no other
+// MountpointS3PodAttachment references, including the ones removed from it after their last workload left.
+// They are marked for unmounting, and removed once the node plugin unmounted them and cleaned up their credentials,
+// see [mppod.FinalizerMountCleanup]. So no Mountpoint Pod is stranded when a workload Pod or a PersistentVolume is
+// deleted while its Mountpoint Pods are torn down.
+type S3PodAttachmentFinalizer struct {
+	reconciler *Reconciler
+}
+
+// NewS3PodAttachmentFinalizer creates a new [S3PodAttachmentFinalizer] for the Mountpoint Pods of `reconciler`.
+func NewS3PodAttachmentFinalizer(reconciler *Reconciler) *S3PodAttachmentFinalizer {
+	return &S3PodAttachmentFinalizer{reconciler: reconciler}
+}
+
+// SetupWithManager configures the finalizer to run with given `mgr`, watching MountpointS3PodAttachments.
+func (f *S3PodAttachmentFinalizer) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(Name + "-s3pa-finalizer").
+		For(&crdv2.MountpointS3PodAttachment{}).
+		Complete(f)
+}
+
+// Reconcile adds the finalizer to a MountpointS3PodAttachment, or removes it once its Mountpoint Pods are gone if
+// it is deleted.
+func (f *S3PodAttachmentFinalizer) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	log := logf.FromContext(ctx).WithValues("s3pa", req.Name)
+
+	s3pa := &crdv2.MountpointS3PodAttachment{}
+	if err := f.reconciler.Get(ctx, req.NamespacedName, s3pa); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if s3pa.DeletionTimestamp == nil {
+		// MountpointS3PodAttachments created by previous versions have no finalizer
+		if !controllerutil.AddFinalizer(s3pa, crdv2.FinalizerMountpointPodsCleanup) {
+			return reconcile.Result{}, nil
+		}
+		return f.update(ctx, s3pa)
+	}
+
+	if !controllerutil.ContainsFinalizer(s3pa, crdv2.FinalizerMountpointPodsCleanup) {
+		return reconcile.Result{}, nil
+	}
+
+	remaining, err := f.cleanupMountpointPods(ctx, s3pa)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if remaining > 0 {
+		log.V(debugLevel).Info("Waiting for Mountpoint Pods to be cleaned up", "remaining", remaining)
+		return reconcile.Result{RequeueAfter: s3paFinalizerRecheckInterval}, nil
+	}
+
+	log.Info("Mountpoint Pods of MountpointS3PodAttachment cleaned up, removing finalizer")
+	controllerutil.RemoveFinalizer(s3pa, crdv2.FinalizerMountpointPodsCleanup)
+	return f.update(ctx, s3pa)
+}
+
+// update updates `s3pa`, and requeues it on conflicts.
+func (f *S3PodAttachmentFinalizer) update(ctx context.Context, s3pa *crdv2.MountpointS3PodAttachment) (reconcile.Result, error) {
+	err := f.reconciler.Update(ctx, s3pa)
+	if apierrors.IsConflict(err) {
+		return reconcile.Result{Requeue: true}, nil
+	}
+	return reconcile.Result{}, client.IgnoreNotFound(err)
+}
+
+// cleanupMountpointPods marks the remaining Mountpoint Pods of the deleted `s3pa` for unmounting, and returns how
+// many remain.
+func (f *S3PodAttachmentFinalizer) cleanupMountpointPods(ctx context.Context, s3pa *crdv2.MountpointS3PodAttachment) (int, error) {
+	log := logf.FromContext(ctx).WithValues("s3pa", s3pa.Name)
+
+	referenced, err := f.referencedByOthers(ctx, s3pa)
+	if err != nil {
+		return 0, err
+	}
+
+	podList := &corev1.PodList{}
+	if err := f.reconciler.List(ctx, podList, client.InNamespace(f.reconciler.mountpointPodConfig.Namespace),
+		client.MatchingLabels{mppod.LabelVolumeName: s3pa.Spec.PersistentVolumeName}); err != nil {
+		return 0, err
+	}
+
+	remaining := 0
+	for i := range podList.Items {
+		mpPod := &podList.Items[i]
+		// Mountpoint Pods created after the deletion started serve a new MountpointS3PodAttachment being created
+		if mpPod.Spec.NodeName != s3pa.Spec.NodeName || referenced[mpPod.Name] || mppod.IsHeadroomPod(mpPod) ||
+			mpPod.CreationTimestamp.After(s3pa.DeletionTimestamp.Time) {
+			continue
+		}
+
+		remaining++
+		if mpPod.DeletionTimestamp != nil || mpPod.Annotations[mppod.AnnotationNeedsUnmount] == "true" {
+			continue
+		}
+		log.Info("Marking Mountpoint Pod of deleted MountpointS3PodAttachment for unmounting", "mountpointPodName", mpPod.Name)
+		if err := f.reconciler.addNeedsUnmountAnnotation(ctx, mpPod.Name, log); err != nil {
+			return 0, err
+		}
+	}
+	return remaining, nil
+}
+
+// referencedByOthers returns the names of the Mountpoint Pods other MountpointS3PodAttachments of the node and volume
+// of `s3pa` reference.
+func (f *S3PodAttachmentFinalizer) referencedByOthers(ctx context.Context, s3pa *crdv2.MountpointS3PodAttachment) (map[string]bool, error) {
+	s3paList := &crdv2.MountpointS3PodAttachmentList{}
+	if err := f.reconciler.List(ctx, s3paList, client.MatchingFields{
+		crdv2.FieldNodeName:             s3pa.Spec.NodeName,
+		crdv2.FieldPersistentVolumeName: s3pa.Spec.PersistentVolumeName,
+	}); err != nil {
+		return nil, err
+	}
+
+	referenced := make(map[string]bool)
+	for _, other := range s3paList.Items {
+		if other.Name == s3pa.Name {
+			continue
+		}
+		for mpPodName := range other.Spec.MountpointS3PodAttachments {
+			referenced[mpPodName] = true
+		}
+	}
+	return referenced, nil
+}
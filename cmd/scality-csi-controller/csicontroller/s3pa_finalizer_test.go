@@ -0,0 +1,136 @@
+package csicontroller_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/scality/mountpoint-s3-csi-driver/cmd/scality-csi-controller/csicontroller"
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func createTestVolumeMountpointPod(name string) *corev1.Pod {
+	mpPod := createTestMountpointPod(nil)
+	mpPod.Name = name
+	mpPod.Labels[mppod.LabelVolumeName] = testPVName
+	mpPod.Finalizers = []string{mppod.FinalizerMountCleanup}
+	mpPod.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+	return mpPod
+}
+
+func TestS3PodAttachmentFinalizer(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Adds the finalizer to MountpointS3PodAttachments without it", func(t *testing.T) {
+		s3pa := createTestS3PodAttachment("s3pa-old", "workload-uid", "mp-1")
+		reconciler, c := testReconciler(s3pa)
+		finalizer := csicontroller.NewS3PodAttachmentFinalizer(reconciler)
+
+		_, err := finalizer.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: s3pa.Name}})
+		assert.NoError(t, err)
+
+		assert.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(s3pa), s3pa))
+		assert.Equals(t, true, controllerutil.ContainsFinalizer(s3pa, crdv2.FinalizerMountpointPodsCleanup))
+	})
+
+	t.Run("Waits for the Mountpoint Pods of a deleted MountpointS3PodAttachment", func(t *testing.T) {
+		s3pa := createTestS3PodAttachment("s3pa-deleted", "", "")
+		s3pa.Finalizers = []string{crdv2.FinalizerMountpointPodsCleanup}
+		other := createTestS3PodAttachment("s3pa-other", "workload-uid", "mp-shared")
+		other.Spec.MountOptions = "ro"
+		reconciler, c := testReconciler(s3pa, other, createTestVolumeMountpointPod("mp-removed"), createTestVolumeMountpointPod("mp-shared"))
+		finalizer := csicontroller.NewS3PodAttachmentFinalizer(reconciler)
+		assert.NoError(t, c.Delete(ctx, s3pa))
+
+		request := reconcile.Request{NamespacedName: types.NamespacedName{Name: s3pa.Name}}
+		result, err := finalizer.Reconcile(ctx, request)
+		assert.NoError(t, err)
+		assert.Equals(t, true, result.RequeueAfter > 0)
+
+		// Only the Mountpoint Pod no other MountpointS3PodAttachment references is unmounted
+		mpPod := &corev1.Pod{}
+		assert.NoError(t, c.Get(ctx, types.NamespacedName{Namespace: mountpointNamespace, Name: "mp-removed"}, mpPod))
+		assert.Equals(t, "true", mpPod.Annotations[mppod.AnnotationNeedsUnmount])
+		assert.NoError(t, c.Get(ctx, types.NamespacedName{Namespace: mountpointNamespace, Name: "mp-shared"}, mpPod))
+		assert.Equals(t, "", mpPod.Annotations[mppod.AnnotationNeedsUnmount])
+		assert.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(s3pa), s3pa))
+
+		// The node plugin cleaned up the Mountpoint Pod
+		assert.NoError(t, c.Get(ctx, types.NamespacedName{Namespace: mountpointNamespace, Name: "mp-removed"}, mpPod))
+		mpPod.Finalizers = nil
+		assert.NoError(t, c.Update(ctx, mpPod))
+		assert.NoError(t, c.Delete(ctx, mpPod))
+
+		result, err = finalizer.Reconcile(ctx, request)
+		assert.NoError(t, err)
+		assert.Equals(t, reconcile.Result{}, result)
+		err = c.Get(ctx, client.ObjectKeyFromObject(s3pa), s3pa)
+		assert.Equals(t, true, apierrors.IsNotFound(err))
+	})
+}
+
+func TestReconciler_IgnoresDeletedS3PodAttachments(t *testing.T) {
+	ctx := context.Background()
+	s3pa := createTestS3PodAttachment("s3pa-deleted", "previous-workload-uid", "mp-previous")
+	s3pa.Finalizers = []string{crdv2.FinalizerMountpointPodsCleanup}
+	reconciler, c := testReconciler(
+		s3pa,
+		createTestPod(testPodName, testNamespace, testNodeName, pvcVolumes()),
+		createTestPVC(testPVCName, testNamespace, testPVName),
+		createTestPV(testPVName, testPVCName, testNamespace),
+	)
+	assert.NoError(t, c.Delete(ctx, s3pa))
+
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: testPodName}})
+	assert.NoError(t, err)
+
+	// A new MountpointS3PodAttachment is created with the finalizer, instead of reusing the deleted one
+	s3paList := &crdv2.MountpointS3PodAttachmentList{}
+	assert.NoError(t, c.List(ctx, s3paList))
+	assert.Equals(t, 2, len(s3paList.Items))
+	for _, item := range s3paList.Items {
+		if item.Name == s3pa.Name {
+			continue
+		}
+		assert.Equals(t, []string{crdv2.FinalizerMountpointPodsCleanup}, item.Finalizers)
+	}
+	mpPod := getOnlyMountpointPod(t, c)
+	assert.Equals(t, []string{mppod.FinalizerMountCleanup}, mpPod.Finalizers)
+}
+
+func TestReconciler_DoesNotAssignWorkloadsToDeletedMountpointPods(t *testing.T) {
+	ctx := context.Background()
+	workload := createTestPod(testLingerWorkload2, testNamespace, testNodeName, pvcVolumes())
+	s3pa := createTestS3PodAttachment(testLingerS3PAName, "draining-workload-uid", testMPPodName)
+	// The Mountpoint Pod keeps running until the workloads release its mount, e.g. during a node drain
+	mpPod := createTestMountpointPod(nil)
+	mpPod.Finalizers = []string{mppod.FinalizerMountCleanup}
+
+	reconciler, c := testReconciler(
+		workload,
+		createTestPVC(testPVCName, testNamespace, testPVName),
+		createTestPV(testPVName, testPVCName, testNamespace),
+		mpPod,
+		s3pa,
+	)
+	assert.NoError(t, c.Delete(ctx, mpPod))
+
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{
+		NamespacedName: types.NamespacedName{Name: testLingerWorkload2, Namespace: testNamespace},
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(s3pa), s3pa))
+	assert.Equals(t, 2, len(s3pa.Spec.MountpointS3PodAttachments))
+	assert.Equals(t, 1, len(s3pa.Spec.MountpointS3PodAttachments[testMPPodName]))
+}
@@ -0,0 +1,259 @@
+package csicontroller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+)
+
+// Reasons of the [crdv2.ConditionMountpointPodScheduled], [crdv2.ConditionMountpointReady] and
+// [crdv2.ConditionMountError] conditions. Mount errors use the cause of the failure as reason, e.g.
+// [FailureCauseAccessDenied].
+const (
+	ReasonScheduled        = "Scheduled"
+	ReasonPending          = "Pending"
+	ReasonMountpointReady  = "Running"
+	ReasonMountpointFailed = "MountpointFailed"
+	ReasonNotRunning       = "NotRunning"
+	ReasonNoMountError     = "NoError"
+)
+
+// Reasons of events emitted on workload Pods when the status of their MountpointS3PodAttachment changes.
+const (
+	EventReasonMountpointPodUnschedulable = "MountpointPodUnschedulable"
+	EventReasonMountpointFailed           = "MountpointFailed"
+	EventReasonMountpointReady            = "MountpointReady"
+)
+
+// maxConditionMessageLength is the maximum length of Mountpoint's error output in the [crdv2.ConditionMountError]
+// condition, to keep MountpointS3PodAttachments and events small.
+const maxConditionMessageLength = 4096
+
+// A mountpointPodState is the state of a Mountpoint Pod of a MountpointS3PodAttachment, reported by one of its
+// conditions.
+type mountpointPodState struct {
+	mpPodName string
+	condition metav1.Condition
+}
+
+// updateS3PodAttachmentStatus updates the conditions of the MountpointS3PodAttachment referencing `mpPod` from the
+// state of its Mountpoint Pods, and emits events on the workloads of `mpPod` when they change.
+func (r *Reconciler) updateS3PodAttachmentStatus(ctx context.Context, mpPod *corev1.Pod) error {
+	s3pa, err := r.findS3PodAttachmentForMountpointPod(ctx, mpPod)
+	if err != nil || s3pa == nil {
+		return err
+	}
+	log := logf.FromContext(ctx).WithValues("s3pa", s3pa.Name)
+
+	mpPods := make([]*corev1.Pod, 0, len(s3pa.Spec.MountpointS3PodAttachments))
+	for _, mpPodName := range slices.Sorted(maps.Keys(s3pa.Spec.MountpointS3PodAttachments)) {
+		if mpPodName == mpPod.Name {
+			mpPods = append(mpPods, mpPod)
+			continue
+		}
+		pod, err := r.getMountpointPod(ctx, mpPodName)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		mpPods = append(mpPods, pod)
+	}
+
+	var changed []mountpointPodState
+	for _, state := range mountpointPodStates(mpPods) {
+		state.condition.ObservedGeneration = s3pa.Generation
+		if meta.SetStatusCondition(&s3pa.Status.Conditions, state.condition) {
+			changed = append(changed, state)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	if err := r.Status().Update(ctx, s3pa); err != nil {
+		if apierrors.IsConflict(err) || apierrors.IsNotFound(err) {
+			// Updated on the next change of the Mountpoint Pod
+			log.V(debugLevel).Info("Failed to update MountpointS3PodAttachment status, will be updated on the next change", "error", err)
+			return nil
+		}
+		return err
+	}
+
+	for _, state := range changed {
+		log.Info("MountpointS3PodAttachment condition changed", "type", state.condition.Type,
+			"status", state.condition.Status, "reason", state.condition.Reason, "mountpointPodName", state.mpPodName)
+		r.emitS3PodAttachmentEvent(ctx, s3pa, state)
+	}
+	return nil
+}
+
+// mountpointPodStates returns the [crdv2.ConditionMountpointPodScheduled], [crdv2.ConditionMountpointReady] and
+// [crdv2.ConditionMountError] conditions of a MountpointS3PodAttachment with `mpPods`, each with the Mountpoint Pod
+// it reports the state of. Conditions are only true if they are for all Mountpoint Pods, except mount errors which
+// are reported as soon as one Mountpoint Pod failed.
+func mountpointPodStates(mpPods []*corev1.Pod) []mountpointPodState {
+	scheduled := mountpointPodState{condition: metav1.Condition{
+		Type:    crdv2.ConditionMountpointPodScheduled,
+		Status:  metav1.ConditionTrue,
+		Reason:  ReasonScheduled,
+		Message: "All Mountpoint Pods are scheduled",
+	}}
+	ready := mountpointPodState{condition: metav1.Condition{
+		Type:    crdv2.ConditionMountpointReady,
+		Status:  metav1.ConditionTrue,
+		Reason:  ReasonMountpointReady,
+		Message: "Mountpoint runs in all Mountpoint Pods",
+	}}
+	mountError := mountpointPodState{condition: metav1.Condition{
+		Type:    crdv2.ConditionMountError,
+		Status:  metav1.ConditionFalse,
+		Reason:  ReasonNoMountError,
+		Message: "Mountpoint did not fail",
+	}}
+
+	for _, mpPod := range mpPods {
+		if scheduled.condition.Status == metav1.ConditionTrue {
+			if podScheduled, reason, message := isMountpointPodScheduled(mpPod); !podScheduled {
+				scheduled.mpPodName = mpPod.Name
+				scheduled.condition.Status = metav1.ConditionFalse
+				scheduled.condition.Reason = reason
+				scheduled.condition.Message = fmt.Sprintf("Mountpoint Pod %s is not scheduled: %s", mpPod.Name, message)
+			}
+		}
+
+		terminated := lastMountpointTermination(mpPod)
+		failed := terminated != nil && terminated.ExitCode != 0
+		if failed && mountError.condition.Status == metav1.ConditionFalse {
+			mountError.mpPodName = mpPod.Name
+			mountError.condition.Status = metav1.ConditionTrue
+			mountError.condition.Reason = classifyMountFailure(terminated)
+			mountError.condition.Message = fmt.Sprintf("Mountpoint in Pod %s exited with code %d: %s",
+				mpPod.Name, terminated.ExitCode, truncateConditionMessage(terminated.Message))
+		}
+
+		if ready.condition.Status == metav1.ConditionTrue && !isMountpointRunning(mpPod) {
+			ready.mpPodName = mpPod.Name
+			ready.condition.Status = metav1.ConditionFalse
+			ready.condition.Reason = ReasonNotRunning
+			ready.condition.Message = fmt.Sprintf("Mountpoint Pod %s is %s", mpPod.Name, mpPod.Status.Phase)
+			if failed {
+				ready.condition.Reason = ReasonMountpointFailed
+				ready.condition.Message = fmt.Sprintf("Mountpoint failed in Pod %s, see the %s condition", mpPod.Name, crdv2.ConditionMountError)
+			}
+		}
+	}
+
+	return []mountpointPodState{scheduled, ready, mountError}
+}
+
+// isMountpointPodScheduled returns whether `mpPod` is scheduled, and the reason and message of its PodScheduled
+// condition otherwise.
+func isMountpointPodScheduled(mpPod *corev1.Pod) (bool, string, string) {
+	for _, condition := range mpPod.Status.Conditions {
+		if condition.Type != corev1.PodScheduled {
+			continue
+		}
+		if condition.Status == corev1.ConditionTrue {
+			return true, "", ""
+		}
+		reason := condition.Reason
+		if reason == "" {
+			reason = ReasonPending
+		}
+		return false, reason, condition.Message
+	}
+	if mpPod.Spec.NodeName != "" {
+		return true, "", ""
+	}
+	return false, ReasonPending, "waiting to be scheduled"
+}
+
+// isMountpointRunning returns whether the Mountpoint container of `mpPod` is running.
+func isMountpointRunning(mpPod *corev1.Pod) bool {
+	if mpPod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for _, status := range mpPod.Status.ContainerStatuses {
+		if status.Name == mppod.ContainerName {
+			return status.State.Running != nil
+		}
+	}
+	return false
+}
+
+// emitS3PodAttachmentEvent emits an event for the changed condition `state` on the workloads of its Mountpoint Pod.
+func (r *Reconciler) emitS3PodAttachmentEvent(ctx context.Context, s3pa *crdv2.MountpointS3PodAttachment, state mountpointPodState) {
+	if r.recorder == nil {
+		return
+	}
+
+	eventType, reason := corev1.EventTypeWarning, ""
+	switch {
+	case state.condition.Type == crdv2.ConditionMountpointPodScheduled && state.condition.Status == metav1.ConditionFalse:
+		reason = EventReasonMountpointPodUnschedulable
+	case state.condition.Type == crdv2.ConditionMountError && state.condition.Status == metav1.ConditionTrue:
+		reason = EventReasonMountpointFailed
+	case state.condition.Type == crdv2.ConditionMountpointReady && state.condition.Status == metav1.ConditionTrue:
+		eventType, reason = corev1.EventTypeNormal, EventReasonMountpointReady
+	default:
+		return
+	}
+
+	for _, workloadPod := range r.workloadPodsOf(ctx, s3pa, state.mpPodName) {
+		r.recorder.Eventf(workloadPod, eventType, reason, "Volume %s: %s (MountpointS3PodAttachment %s)",
+			s3pa.Spec.PersistentVolumeName, state.condition.Message, s3pa.Name)
+	}
+}
+
+// workloadPodsOf returns the workload Pods attached to Mountpoint Pod `mpPodName` of `s3pa`, or to any of its
+// Mountpoint Pods if `mpPodName` is empty.
+func (r *Reconciler) workloadPodsOf(ctx context.Context, s3pa *crdv2.MountpointS3PodAttachment, mpPodName string) []*corev1.Pod {
+	uids := make(map[string]bool)
+	for name, attachments := range s3pa.Spec.MountpointS3PodAttachments {
+		if mpPodName != "" && name != mpPodName {
+			continue
+		}
+		for _, attachment := range attachments {
+			uids[attachment.WorkloadPodUID] = true
+		}
+	}
+	if len(uids) == 0 {
+		return nil
+	}
+
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list workload Pods to emit events", "s3pa", s3pa.Name)
+		return nil
+	}
+	var workloadPods []*corev1.Pod
+	for i := range podList.Items {
+		if uids[string(podList.Items[i].UID)] {
+			workloadPods = append(workloadPods, &podList.Items[i])
+		}
+	}
+	return workloadPods
+}
+
+// truncateConditionMessage returns Mountpoint's error output `message`, truncated to [maxConditionMessageLength]
+// while keeping its end, as the actual failure reason is usually logged last.
+func truncateConditionMessage(message string) string {
+	message = strings.TrimSpace(message)
+	if len(message) <= maxConditionMessageLength {
+		return message
+	}
+	return "... (truncated) " + strings.ToValidUTF8(message[len(message)-maxConditionMessageLength:], "")
+}
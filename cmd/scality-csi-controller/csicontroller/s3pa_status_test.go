@@ -0,0 +1,143 @@
+package csicontroller_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/scality/mountpoint-s3-csi-driver/cmd/scality-csi-controller/csicontroller"
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+)
+
+func TestS3PodAttachmentStatus(t *testing.T) {
+	ctx := context.Background()
+	workload := createTestPod(testPodName, testNamespace, testNodeName, pvcVolumes())
+	reconciler, c := testReconciler(workload,
+		createTestPVC(testPVCName, testNamespace, testPVName),
+		createTestPV(testPVName, testPVCName, testNamespace))
+	recorder := record.NewFakeRecorder(10)
+	reconciler.SetEventRecorder(recorder)
+
+	reconcilePod := func(namespace, name string) {
+		t.Helper()
+		if _, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}}); err != nil {
+			t.Fatalf("Failed to reconcile %s: %v", name, err)
+		}
+	}
+	getS3PA := func() *crdv2.MountpointS3PodAttachment {
+		t.Helper()
+		s3paList := &crdv2.MountpointS3PodAttachmentList{}
+		if err := c.List(ctx, s3paList); err != nil {
+			t.Fatalf("Failed to list MountpointS3PodAttachments: %v", err)
+		}
+		if len(s3paList.Items) != 1 {
+			t.Fatalf("Expected a single MountpointS3PodAttachment, got %d", len(s3paList.Items))
+		}
+		return &s3paList.Items[0]
+	}
+	// updateMountpointPod sets the status of the Mountpoint Pod and reconciles it
+	updateMountpointPod := func(name string, status corev1.PodStatus) {
+		t.Helper()
+		mpPod := &corev1.Pod{}
+		if err := c.Get(ctx, types.NamespacedName{Namespace: mountpointNamespace, Name: name}, mpPod); err != nil {
+			t.Fatalf("Failed to get Mountpoint Pod: %v", err)
+		}
+		mpPod.Status = status
+		if err := c.Status().Update(ctx, mpPod); err != nil {
+			t.Fatalf("Failed to update Mountpoint Pod status: %v", err)
+		}
+		reconcilePod(mountpointNamespace, name)
+	}
+	expectCondition := func(conditionType string, status metav1.ConditionStatus, reason string) *metav1.Condition {
+		t.Helper()
+		condition := meta.FindStatusCondition(getS3PA().Status.Conditions, conditionType)
+		if condition == nil || condition.Status != status || condition.Reason != reason {
+			t.Fatalf("Expected %s condition to be %s with reason %s, got %+v", conditionType, status, reason, condition)
+		}
+		return condition
+	}
+	expectEvent := func(reason string) string {
+		t.Helper()
+		select {
+		case event := <-recorder.Events:
+			if !strings.Contains(event, reason) {
+				t.Fatalf("Expected %s event, got %q", reason, event)
+			}
+			return event
+		default:
+			t.Fatalf("Expected %s event", reason)
+			return ""
+		}
+	}
+
+	reconcilePod(testNamespace, testPodName)
+	var mpPodName string
+	for name := range getS3PA().Spec.MountpointS3PodAttachments {
+		mpPodName = name
+	}
+
+	updateMountpointPod(mpPodName, corev1.PodStatus{
+		Phase: corev1.PodPending,
+		Conditions: []corev1.PodCondition{{
+			Type:    corev1.PodScheduled,
+			Status:  corev1.ConditionFalse,
+			Reason:  corev1.PodReasonUnschedulable,
+			Message: "0/1 nodes are available: 1 Insufficient memory.",
+		}},
+	})
+	condition := expectCondition(crdv2.ConditionMountpointPodScheduled, metav1.ConditionFalse, corev1.PodReasonUnschedulable)
+	if !strings.Contains(condition.Message, "Insufficient memory") {
+		t.Errorf("Expected the scheduling failure in the condition message, got %q", condition.Message)
+	}
+	expectCondition(crdv2.ConditionMountpointReady, metav1.ConditionFalse, csicontroller.ReasonNotRunning)
+	expectEvent(csicontroller.EventReasonMountpointPodUnschedulable)
+
+	updateMountpointPod(mpPodName, corev1.PodStatus{
+		Phase:      corev1.PodRunning,
+		Conditions: []corev1.PodCondition{{Type: corev1.PodScheduled, Status: corev1.ConditionTrue}},
+		ContainerStatuses: []corev1.ContainerStatus{{
+			Name:  mppod.ContainerName,
+			State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+		}},
+	})
+	expectCondition(crdv2.ConditionMountpointPodScheduled, metav1.ConditionTrue, csicontroller.ReasonScheduled)
+	expectCondition(crdv2.ConditionMountpointReady, metav1.ConditionTrue, csicontroller.ReasonMountpointReady)
+	expectCondition(crdv2.ConditionMountError, metav1.ConditionFalse, csicontroller.ReasonNoMountError)
+	expectEvent(csicontroller.EventReasonMountpointReady)
+
+	updateMountpointPod(mpPodName, corev1.PodStatus{
+		Phase:      corev1.PodFailed,
+		Conditions: []corev1.PodCondition{{Type: corev1.PodScheduled, Status: corev1.ConditionTrue}},
+		ContainerStatuses: []corev1.ContainerStatus{{
+			Name: mppod.ContainerName,
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+				ExitCode: 1,
+				Message:  "Error: Failed to create S3 client\nCaused by: AccessDenied: Access Denied",
+			}},
+		}},
+	})
+	condition = expectCondition(crdv2.ConditionMountError, metav1.ConditionTrue, csicontroller.FailureCauseAccessDenied)
+	if !strings.Contains(condition.Message, "AccessDenied: Access Denied") {
+		t.Errorf("Expected the Mountpoint error in the condition message, got %q", condition.Message)
+	}
+	expectCondition(crdv2.ConditionMountpointReady, metav1.ConditionFalse, csicontroller.ReasonMountpointFailed)
+	if event := expectEvent(csicontroller.EventReasonMountpointFailed); !strings.Contains(event, "Access Denied") {
+		t.Errorf("Expected the Mountpoint error in the event, got %q", event)
+	}
+
+	// Conditions do not change when the Mountpoint Pod is reconciled again
+	reconcilePod(mountpointNamespace, mpPodName)
+	select {
+	case event := <-recorder.Events:
+		t.Errorf("Expected no event for unchanged conditions, got %q", event)
+	default:
+	}
+}
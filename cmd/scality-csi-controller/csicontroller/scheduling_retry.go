@@ -0,0 +1,177 @@
+package csicontroller
+
+import (
+	"context"
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+)
+
+// Backoff of the recreation of Mountpoint Pods that cannot be scheduled or whose images cannot be pulled.
+// The delay before the n-th recreation is `schedulingRetryBaseDelay * 2^n`, capped to `schedulingRetryMaxDelay`,
+// with up to `schedulingRetryJitter` of jitter so Mountpoint Pods stuck together are not recreated together.
+const (
+	schedulingRetryBaseDelay = 30 * time.Second
+	schedulingRetryMaxDelay  = 10 * time.Minute
+	schedulingRetryJitter    = 0.2
+	maxSchedulingRetries     = 5
+)
+
+// replacementRecheckInterval is how often the creation of a replacement Mountpoint Pod is retried while the stuck
+// Mountpoint Pod it replaces is being deleted.
+const replacementRecheckInterval = time.Second
+
+// replacementMountpointPods tracks Mountpoint Pods to create in place of deleted stuck Mountpoint Pods of the same
+// name, until the deletion completes.
+type replacementMountpointPods struct {
+	mu   sync.Mutex
+	pods map[types.NamespacedName]*corev1.Pod
+}
+
+func newReplacementMountpointPods() *replacementMountpointPods {
+	return &replacementMountpointPods{pods: make(map[types.NamespacedName]*corev1.Pod)}
+}
+
+func (p *replacementMountpointPods) get(name types.NamespacedName) *corev1.Pod {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.pods[name]
+}
+
+func (p *replacementMountpointPods) set(pod *corev1.Pod) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pods[types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}] = pod
+}
+
+func (p *replacementMountpointPods) clear(name types.NamespacedName) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.pods, name)
+}
+
+// retryStuckMountpointPod recreates pending `mpPod` if it could not be scheduled or its images could not be pulled
+// for longer than its backoff delay. The new Mountpoint Pod is created from the current configuration, so fixing
+// the image or resources of Mountpoint Pods applies to stuck Mountpoint Pods. Mountpoint Pods are left stuck after
+// [maxSchedulingRetries], node plugins report the cause of the failure to kubelet.
+func (r *Reconciler) retryStuckMountpointPod(ctx context.Context, mpPod *corev1.Pod) (reconcile.Result, error) {
+	log := logf.FromContext(ctx).WithValues("mountpointPod", mpPod.Name)
+
+	stuck := mppod.StuckState(mpPod)
+	if stuck == nil || mpPod.DeletionTimestamp != nil {
+		return reconcile.Result{}, nil
+	}
+
+	retries := schedulingRetries(mpPod)
+	if retries >= maxSchedulingRetries {
+		log.V(debugLevel).Info("Mountpoint Pod is stuck, not recreating it anymore", "cause", stuck.Cause, "retries", retries)
+		return reconcile.Result{}, nil
+	}
+	if wait := time.Until(stuck.Since.Add(schedulingRetryDelay(mpPod, retries))); wait > 0 {
+		return reconcile.Result{RequeueAfter: wait}, nil
+	}
+
+	replacement, err := r.replacementMountpointPod(ctx, mpPod, retries+1)
+	if err != nil || replacement == nil {
+		return reconcile.Result{}, err
+	}
+
+	// Stuck Mountpoint Pods never ran Mountpoint, they are deleted without grace period nor waiting for the node plugin
+	// to reuse their name right away
+	err = r.deleteUnmountedMountpointPod(ctx, mpPod, client.GracePeriodSeconds(0), client.Preconditions{UID: &mpPod.UID})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	}
+	r.replacementMountpointPods.set(replacement)
+	mountpointPodSchedulingRetriesTotal.WithLabelValues(string(stuck.Cause)).Inc()
+	log.Info("Recreating stuck Mountpoint Pod", "cause", stuck.Cause, "message", stuck.Message, "retry", retries+1)
+
+	return r.createReplacementMountpointPod(ctx, types.NamespacedName{Namespace: mpPod.Namespace, Name: mpPod.Name})
+}
+
+// createReplacementMountpointPod creates the Mountpoint Pod replacing stuck Mountpoint Pod `name` if any, once the
+// stuck Mountpoint Pod is deleted.
+func (r *Reconciler) createReplacementMountpointPod(ctx context.Context, name types.NamespacedName) (reconcile.Result, error) {
+	replacement := r.replacementMountpointPods.get(name)
+	if replacement == nil {
+		return reconcile.Result{}, nil
+	}
+
+	err := r.Create(ctx, replacement.DeepCopy())
+	if apierrors.IsAlreadyExists(err) {
+		// The stuck Mountpoint Pod is still being deleted
+		return reconcile.Result{RequeueAfter: replacementRecheckInterval}, nil
+	}
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	r.replacementMountpointPods.clear(name)
+	logf.FromContext(ctx).Info("Stuck Mountpoint Pod recreated", "mountpointPod", name.Name)
+	return reconcile.Result{}, nil
+}
+
+// replacementMountpointPod returns a new Mountpoint Pod to replace stuck `mpPod`, created for the same workload Pod
+// and volume. It returns nil if the workload Pod or the volume is gone.
+func (r *Reconciler) replacementMountpointPod(ctx context.Context, mpPod *corev1.Pod, retries int) (*corev1.Pod, error) {
+	pv := &corev1.PersistentVolume{}
+	if err := r.Get(ctx, types.NamespacedName{Name: mpPod.Labels[mppod.LabelVolumeName]}, pv); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList); err != nil {
+		return nil, err
+	}
+	for i := range podList.Items {
+		workloadPod := &podList.Items[i]
+		if string(workloadPod.UID) != mpPod.Labels[mppod.LabelPodUID] {
+			continue
+		}
+		replacement, err := r.mountpointPodCreator.Create(workloadPod, pv)
+		if err != nil {
+			return nil, err
+		}
+		if replacement.Name != mpPod.Name {
+			// Created for another volume of the workload Pod, e.g. an inline volume with a generated name
+			return nil, nil
+		}
+		if replacement.Annotations == nil {
+			replacement.Annotations = make(map[string]string)
+		}
+		replacement.Annotations[mppod.AnnotationSchedulingRetries] = strconv.Itoa(retries)
+		return replacement, nil
+	}
+	return nil, nil
+}
+
+// schedulingRetries returns how many times `mpPod` was recreated after being stuck.
+func schedulingRetries(mpPod *corev1.Pod) int {
+	retries, err := strconv.Atoi(mpPod.Annotations[mppod.AnnotationSchedulingRetries])
+	if err != nil {
+		return 0
+	}
+	return retries
+}
+
+// schedulingRetryDelay returns how long `mpPod` is left stuck before being recreated, after `retries` recreations.
+// The jitter is derived from the UID of `mpPod` to be stable across reconciliations of a Mountpoint Pod.
+func schedulingRetryDelay(mpPod *corev1.Pod, retries int) time.Duration {
+	delay := schedulingRetryMaxDelay
+	if retries < 16 {
+		delay = min(schedulingRetryBaseDelay<<retries, schedulingRetryMaxDelay)
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(mpPod.UID))
+	jitter := float64(h.Sum32()%1000) / 1000 * schedulingRetryJitter
+	return delay + time.Duration(float64(delay)*jitter)
+}
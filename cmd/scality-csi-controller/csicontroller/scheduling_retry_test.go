@@ -0,0 +1,79 @@
+package csicontroller_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestStuckMountpointPodRetries(t *testing.T) {
+	tests := []struct {
+		name        string
+		stuckFor    time.Duration
+		retries     string
+		wantRetries string
+		wantRequeue bool
+	}{
+		{name: "stuck longer than the backoff", stuckFor: time.Hour, wantRetries: "1"},
+		{name: "stuck shorter than the backoff", stuckFor: time.Second, wantRequeue: true},
+		{name: "stuck after a retry", stuckFor: time.Hour, retries: "2", wantRetries: "3"},
+		{name: "stuck after the last retry", stuckFor: time.Hour, retries: "5", wantRetries: "5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			reconciler, c := testReconciler(
+				createTestPod(testPodName, testNamespace, testNodeName, pvcVolumes()),
+				createTestPVC(testPVCName, testNamespace, testPVName),
+				createTestPV(testPVName, testPVCName, testNamespace),
+			)
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: testPodName}})
+			assert.NoError(t, err)
+
+			mpPod := getOnlyMountpointPod(t, c)
+			if tt.retries != "" {
+				metav1.SetMetaDataAnnotation(&mpPod.ObjectMeta, mppod.AnnotationSchedulingRetries, tt.retries)
+				assert.NoError(t, c.Update(ctx, mpPod))
+			}
+			mpPod.Status = corev1.PodStatus{
+				Phase: corev1.PodPending,
+				Conditions: []corev1.PodCondition{{
+					Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonUnschedulable,
+					Message:            "0/3 nodes are available: 1 Insufficient memory.",
+					LastTransitionTime: metav1.NewTime(time.Now().Add(-tt.stuckFor)),
+				}},
+			}
+			assert.NoError(t, c.Status().Update(ctx, mpPod))
+
+			result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(mpPod)})
+			assert.NoError(t, err)
+			assert.Equals(t, tt.wantRequeue, result.RequeueAfter > 0)
+
+			mpPod = getOnlyMountpointPod(t, c)
+			assert.Equals(t, tt.wantRetries, mpPod.Annotations[mppod.AnnotationSchedulingRetries])
+			recreated := tt.wantRetries != "" && tt.wantRetries != tt.retries
+			// A recreated Mountpoint Pod has no status yet
+			assert.Equals(t, recreated, mpPod.Status.Phase == "")
+		})
+	}
+}
+
+func getOnlyMountpointPod(t *testing.T, c client.Client) *corev1.Pod {
+	t.Helper()
+	podList := &corev1.PodList{}
+	assert.NoError(t, c.List(context.Background(), podList, client.InNamespace(mountpointNamespace)))
+	if len(podList.Items) != 1 {
+		t.Fatalf("Expected a single Mountpoint Pod, got %d", len(podList.Items))
+	}
+	return &podList.Items[0]
+}
@@ -9,6 +9,7 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
 )
 
 const (
@@ -126,20 +127,30 @@ func (cm *StaleAttachmentCleaner) cleanupStaleWorkloads(
 		}
 
 		if len(validWorkloads) == 0 {
-			// No valid workloads, mark Mountpoint Pod for deletion
-			mpPodsToDelete = append(mpPodsToDelete, mpPodName)
-
-			// Add unmount annotation to Mountpoint Pod
 			mpPod := &corev1.Pod{}
 			mpPodKey := types.NamespacedName{
 				Namespace: cm.reconciler.mountpointPodConfig.Namespace,
 				Name:      mpPodName,
 			}
-			if err := cm.reconciler.Get(ctx, mpPodKey, mpPod); err == nil {
+			err := cm.reconciler.Get(ctx, mpPodKey, mpPod)
+
+			// Lingering Mountpoint Pods have no workloads by design, the reconciler tears them down once their window expires
+			if err == nil && len(workloads) == 0 {
+				if remaining, lingering := cm.reconciler.lingerRemaining(mpPod, now); lingering && remaining > 0 {
+					continue
+				}
+			}
+
+			// No valid workloads, mark Mountpoint Pod for deletion
+			mpPodsToDelete = append(mpPodsToDelete, mpPodName)
+
+			// Add unmount annotation to Mountpoint Pod
+			if err == nil {
 				if mpPod.Annotations == nil {
 					mpPod.Annotations = make(map[string]string)
 				}
 				mpPod.Annotations["s3.csi.scality.com/needs-unmount"] = "true"
+				delete(mpPod.Annotations, mppod.AnnotationLingeringSince)
 				if err := cm.reconciler.Update(ctx, mpPod); err != nil {
 					log.Error(err, "Failed to add unmount annotation", "mpPod", mpPodName)
 				}
@@ -0,0 +1,203 @@
+package csicontroller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/utapi"
+)
+
+// Annotations of Persistent Volume Claims with the last-known usage of their volume, see [VolumeUsageCollector].
+const (
+	// AnnotationUsedBytes is the storage used by the bucket of the volume, in bytes.
+	AnnotationUsedBytes = constants.DriverName + "/used-bytes"
+	// AnnotationObjectCount is the number of objects in the bucket of the volume.
+	AnnotationObjectCount = constants.DriverName + "/object-count"
+	// AnnotationUsageTime is the end of the UTAPI time range the usage was last updated from, in RFC 3339 format.
+	AnnotationUsageTime = constants.DriverName + "/usage-time"
+)
+
+// VolumeUsageCollectorConfig holds the configuration of a [VolumeUsageCollector].
+type VolumeUsageCollectorConfig struct {
+	// Interval between collections.
+	Interval time.Duration
+	// Window over which request rates are averaged, see [BucketMetricsCollectorConfig.Window].
+	Window time.Duration
+	// AnnotateClaims annotates claims with the last-known usage of their volume.
+	AnnotateClaims bool
+}
+
+// VolumeUsage holds the usage of the bucket of a bound volume.
+type VolumeUsage struct {
+	Namespace             string
+	PersistentVolumeClaim string
+	PersistentVolume      string
+	Bucket                string
+	UsedBytes             int64
+	Objects               int64
+	RequestsPerSecond     float64
+}
+
+// A VolumeUsageCollector periodically queries UTAPI for the storage used by the buckets of bound volumes and their
+// request rates, and exposes them as metrics labelled with the namespace and name of the claims of the volumes.
+// Claims are optionally annotated with the last-known usage of their volume, see [AnnotationUsedBytes].
+//
+// UTAPI measures whole buckets without listing their objects. Volumes of a prefix are left out, their usage is
+// measured by the [PrefixQuotaEnforcer].
+type VolumeUsageCollector struct {
+	client client.Client
+	source BucketMetricsSource
+	config VolumeUsageCollectorConfig
+}
+
+// NewVolumeUsageCollector creates a new [VolumeUsageCollector].
+func NewVolumeUsageCollector(client client.Client, source BucketMetricsSource, config VolumeUsageCollectorConfig) *VolumeUsageCollector {
+	return &VolumeUsageCollector{client: client, source: source, config: config}
+}
+
+// Start begins the periodic collection of volume usage.
+func (c *VolumeUsageCollector) Start(ctx context.Context) error {
+	log := logf.FromContext(ctx)
+	log.Info("Starting volume usage collector", "interval", c.config.Interval, "annotateClaims", c.config.AnnotateClaims)
+
+	ticker := time.NewTicker(c.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Completed volume usage collector")
+			return nil
+		case <-ticker.C:
+			if _, err := c.RunCollection(ctx); err != nil {
+				log.Error(err, "Failed to collect volume usage")
+				// Continue running even if collection fails
+			}
+		}
+	}
+}
+
+// RunCollection queries the usage of the buckets of all bound volumes of the driver, updates the exposed metrics and
+// the annotations of their claims, and returns the usages. Volumes of buckets whose metrics could not be queried
+// are left out.
+func (c *VolumeUsageCollector) RunCollection(ctx context.Context) ([]VolumeUsage, error) {
+	pvList := &corev1.PersistentVolumeList{}
+	if err := c.client.List(ctx, pvList); err != nil {
+		return nil, err
+	}
+
+	var usages []VolumeUsage
+	var errs []error
+	metricsByBucket := make(map[string]*utapi.BucketMetrics)
+	for i := range pvList.Items {
+		pv := &pvList.Items[i]
+		bucket := bucketOfVolume(pv)
+		if bucket == "" {
+			continue
+		}
+
+		metrics, queried := metricsByBucket[bucket]
+		if !queried {
+			m, err := c.source.ListBucketMetrics(ctx, bucket, c.config.Window)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to query metrics of bucket %q: %w", bucket, err))
+			} else {
+				metrics = &m
+			}
+			metricsByBucket[bucket] = metrics
+		}
+		if metrics == nil {
+			continue
+		}
+
+		usage := VolumeUsage{
+			Namespace:             pv.Spec.ClaimRef.Namespace,
+			PersistentVolumeClaim: pv.Spec.ClaimRef.Name,
+			PersistentVolume:      pv.Name,
+			Bucket:                bucket,
+			UsedBytes:             metrics.CurrentStorageUtilized(),
+			Objects:               metrics.CurrentNumberOfObjects(),
+		}
+		if seconds := metrics.Duration().Seconds(); seconds > 0 {
+			usage.RequestsPerSecond = float64(metrics.TotalOperations()) / seconds
+		}
+		usages = append(usages, usage)
+
+		if c.config.AnnotateClaims {
+			if err := c.annotateClaim(ctx, usage, *metrics); err != nil {
+				errs = append(errs, fmt.Errorf("failed to annotate claim %s/%s: %w", usage.Namespace, usage.PersistentVolumeClaim, err))
+			}
+		}
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].Namespace != usages[j].Namespace {
+			return usages[i].Namespace < usages[j].Namespace
+		}
+		return usages[i].PersistentVolumeClaim < usages[j].PersistentVolumeClaim
+	})
+
+	volumeUsedBytes.Reset()
+	volumeObjects.Reset()
+	volumeRequestRate.Reset()
+	for _, u := range usages {
+		labels := []string{u.Namespace, u.PersistentVolumeClaim, u.PersistentVolume, u.Bucket}
+		volumeUsedBytes.WithLabelValues(labels...).Set(float64(u.UsedBytes))
+		volumeObjects.WithLabelValues(labels...).Set(float64(u.Objects))
+		volumeRequestRate.WithLabelValues(labels...).Set(u.RequestsPerSecond)
+	}
+	return usages, errors.Join(errs...)
+}
+
+// bucketOfVolume returns the bucket of `pv`, empty if `pv` is not a bound volume of a whole bucket.
+func bucketOfVolume(pv *corev1.PersistentVolume) string {
+	if pv.Spec.CSI == nil || pv.Spec.CSI.Driver != mountpointCSIDriverName || pv.Spec.ClaimRef == nil {
+		return ""
+	}
+	if prefix, _ := mountpoint.NewArgsBuilder(pv.Spec.MountOptions).Value(mountpoint.ArgPrefix); prefix != "" {
+		return ""
+	}
+	return mppod.ExtractVolumeAttributes(pv)[volumecontext.BucketName]
+}
+
+// annotateClaim annotates the claim of `usage` with its usage, if it changed since the last annotation.
+func (c *VolumeUsageCollector) annotateClaim(ctx context.Context, usage VolumeUsage, metrics utapi.BucketMetrics) error {
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := c.client.Get(ctx, types.NamespacedName{Namespace: usage.Namespace, Name: usage.PersistentVolumeClaim}, pvc); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if pvc.Spec.VolumeName != usage.PersistentVolume {
+		return nil
+	}
+
+	usedBytes := strconv.FormatInt(usage.UsedBytes, 10)
+	objects := strconv.FormatInt(usage.Objects, 10)
+	if pvc.Annotations[AnnotationUsedBytes] == usedBytes && pvc.Annotations[AnnotationObjectCount] == objects {
+		return nil
+	}
+
+	patch := client.MergeFrom(pvc.DeepCopy())
+	if pvc.Annotations == nil {
+		pvc.Annotations = make(map[string]string)
+	}
+	pvc.Annotations[AnnotationUsedBytes] = usedBytes
+	pvc.Annotations[AnnotationObjectCount] = objects
+	if len(metrics.TimeRange) == 2 {
+		pvc.Annotations[AnnotationUsageTime] = time.UnixMilli(metrics.TimeRange[1]).UTC().Format(time.RFC3339)
+	}
+	logf.FromContext(ctx).V(debugLevel).Info("Annotating claim with volume usage", "pvc", usage.Namespace+"/"+usage.PersistentVolumeClaim,
+		"usedBytes", usedBytes, "objects", objects)
+	return client.IgnoreNotFound(c.client.Patch(ctx, pvc, patch))
+}
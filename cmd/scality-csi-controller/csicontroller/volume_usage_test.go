@@ -0,0 +1,90 @@
+package csicontroller_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/scality/mountpoint-s3-csi-driver/cmd/scality-csi-controller/csicontroller"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/utapi"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestVolumeUsageCollector(t *testing.T) {
+	ctx := context.Background()
+	bucketMetrics := utapi.BucketMetrics{
+		TimeRange:       []int64{0, 100_000},
+		StorageUtilized: []int64{1000, 4096},
+		NumberOfObjects: []int64{1, 4},
+		Operations:      map[string]int64{"s3:GetObject": 300, "s3:PutObject": 100},
+	}
+
+	prefixPV := createTestPV("prefix-pv", "prefix-pvc", testNamespace)
+	prefixPV.Spec.MountOptions = []string{"prefix=data/"}
+	unboundPV := createTestPV("unbound-pv", "", "")
+	unboundPV.Spec.ClaimRef = nil
+
+	tests := []struct {
+		name            string
+		metrics         map[string]utapi.BucketMetrics
+		annotate        bool
+		wantUsages      []csicontroller.VolumeUsage
+		wantAnnotations map[string]string
+		wantErr         bool
+	}{
+		{
+			name:    "usage of the bucket of bound volumes of whole buckets",
+			metrics: map[string]utapi.BucketMetrics{"test-bucket": bucketMetrics},
+			wantUsages: []csicontroller.VolumeUsage{
+				{Namespace: testNamespace, PersistentVolumeClaim: testPVCName, PersistentVolume: testPVName, Bucket: "test-bucket", UsedBytes: 4096, Objects: 4, RequestsPerSecond: 4},
+			},
+		},
+		{
+			name:     "claims are annotated with the usage of their volume",
+			metrics:  map[string]utapi.BucketMetrics{"test-bucket": bucketMetrics},
+			annotate: true,
+			wantUsages: []csicontroller.VolumeUsage{
+				{Namespace: testNamespace, PersistentVolumeClaim: testPVCName, PersistentVolume: testPVName, Bucket: "test-bucket", UsedBytes: 4096, Objects: 4, RequestsPerSecond: 4},
+			},
+			wantAnnotations: map[string]string{
+				csicontroller.AnnotationUsedBytes:   "4096",
+				csicontroller.AnnotationObjectCount: "4",
+				csicontroller.AnnotationUsageTime:   "1970-01-01T00:01:40Z",
+			},
+		},
+		{
+			name:     "bucket metrics cannot be queried",
+			annotate: true,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, c := testReconciler(createTestPV(testPVName, testPVCName, testNamespace), createTestPVC(testPVCName, testNamespace, testPVName),
+				prefixPV, createTestPVC("prefix-pvc", testNamespace, "prefix-pv"), unboundPV)
+			source := &fakeBucketMetricsSource{metrics: tt.metrics}
+			collector := csicontroller.NewVolumeUsageCollector(c, source, csicontroller.VolumeUsageCollectorConfig{
+				Interval:       time.Minute,
+				Window:         15 * time.Minute,
+				AnnotateClaims: tt.annotate,
+			})
+
+			usages, err := collector.RunCollection(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			assert.Equals(t, tt.wantUsages, usages)
+			assert.Equals(t, []time.Duration{15 * time.Minute}, source.windows)
+
+			pvc := &corev1.PersistentVolumeClaim{}
+			if err := c.Get(ctx, types.NamespacedName{Namespace: testNamespace, Name: testPVCName}, pvc); err != nil {
+				t.Fatalf("Failed to get PVC: %v", err)
+			}
+			assert.Equals(t, tt.wantAnnotations, pvc.Annotations)
+		})
+	}
+}
@@ -4,26 +4,48 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
+	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/go-logr/logr"
+	uzap "go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	"github.com/scality/mountpoint-s3-csi-driver/cmd/scality-csi-controller/csicontroller"
 	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/cluster"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/version"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driverconfig"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/utapi"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util"
 )
 
 var (
@@ -35,15 +57,62 @@ var (
 	mountpointImage                       = flag.String("mountpoint-image", os.Getenv("MOUNTPOINT_IMAGE"), "Image of Mountpoint to use in spawned Mountpoint Pods.")
 	headroomImage                         = flag.String("headroom-image", os.Getenv("MOUNTPOINT_HEADROOM_IMAGE"), "Image of a pause container to use in spawned Headroom Pods.")
 	mountpointImagePullPolicy             = flag.String("mountpoint-image-pull-policy", os.Getenv("MOUNTPOINT_IMAGE_PULL_POLICY"), "Pull policy of Mountpoint images.")
+	mountpointMaxRestarts                 = flag.String("mountpoint-max-restarts", os.Getenv("MOUNTPOINT_MAX_RESTARTS"), "Number of times Mountpoint is restarted in its container after crashing, with a new FUSE device from the node plugin. Empty or zero disables restarts.")
+	mountpointShutdownTimeout             = flag.String("mountpoint-shutdown-timeout", os.Getenv("MOUNTPOINT_SHUTDOWN_TIMEOUT"), "Time Mountpoint has to flush pending uploads and exit after an unmount, before it is terminated. Empty or zero waits for Mountpoint indefinitely.")
+	mountpointBinaryDigests               = flag.String("mountpoint-binary-digests", os.Getenv("MOUNTPOINT_BINARY_DIGESTS"), "Expected SHA-256 digests of the Mountpoint binary in the Mountpoint image, as a single digest or comma-separated <platform>=<digest> pairs. Empty disables verification.")
 	mountpointContainerCommand            = flag.String("mountpoint-container-command", "/bin/scality-s3-csi-mounter", "Entrypoint command of the Mountpoint Pods.")
+	mountpointResourcesReqCPU             = flag.String("mountpoint-resources-req-cpu", os.Getenv("MOUNTPOINT_RESOURCES_REQUESTS_CPU"), "Default CPU request of Mountpoint containers.")
+	mountpointResourcesReqMemory          = flag.String("mountpoint-resources-req-memory", os.Getenv("MOUNTPOINT_RESOURCES_REQUESTS_MEMORY"), "Default memory request of Mountpoint containers.")
+	mountpointResourcesLimCPU             = flag.String("mountpoint-resources-lim-cpu", os.Getenv("MOUNTPOINT_RESOURCES_LIMITS_CPU"), "Default CPU limit of Mountpoint containers.")
+	mountpointResourcesLimMemory          = flag.String("mountpoint-resources-lim-memory", os.Getenv("MOUNTPOINT_RESOURCES_LIMITS_MEMORY"), "Default memory limit of Mountpoint containers.")
+	mountpointPodTolerations              = flag.String("mountpoint-pod-tolerations", os.Getenv("MOUNTPOINT_POD_TOLERATIONS"), "Tolerations of Mountpoint Pods as a JSON list. Empty tolerates all taints.")
+	mountpointPodLabels                   = flag.String("mountpoint-pod-labels", os.Getenv("MOUNTPOINT_POD_LABELS"), "Labels added to Mountpoint Pods as a JSON object.")
+	mountpointPodAnnotations              = flag.String("mountpoint-pod-annotations", os.Getenv("MOUNTPOINT_POD_ANNOTATIONS"), "Annotations added to Mountpoint Pods as a JSON object.")
+	mountpointPodTopologySpread           = flag.String("mountpoint-pod-topology-spread-constraints", os.Getenv("MOUNTPOINT_POD_TOPOLOGY_SPREAD_CONSTRAINTS"), "Topology spread constraints of Mountpoint Pods as a JSON list.")
+	mountpointPodLingerDuration           = flag.String("mountpoint-pod-linger-duration", os.Getenv("MOUNTPOINT_POD_LINGER_DURATION"), "How long Mountpoint Pods are retained for reuse after their last workload is gone. Zero disables lingering.")
+	mountpointPodDrainTimeout             = flag.String("mountpoint-pod-drain-timeout", os.Getenv("MOUNTPOINT_POD_DRAIN_TIMEOUT"), "How long deleted Mountpoint Pods keep Mountpoint running for workloads still using their mount, before it is detached from them. Empty or zero disables waiting for workloads.")
+	headroomPodTTL                        = flag.String("headroom-pod-ttl", os.Getenv("MOUNTPOINT_HEADROOM_POD_TTL"), "How long Headroom Pods are retained at most before being deleted if not consumed. Empty or zero retains them until their workload starts or terminates.")
+	mountFailureBudget                    = flag.String("mount-failure-budget", os.Getenv("MOUNT_FAILURE_BUDGET"), "Number of Mountpoint failures of a volume within the failure window after which no new Mountpoint Pods are created for it. Empty or zero disables the budget.")
+	mountFailureWindow                    = flag.String("mount-failure-window", os.Getenv("MOUNT_FAILURE_WINDOW"), "Window in which Mountpoint failures of a volume are counted against its failure budget.")
+	diagnosticMountNamespace              = flag.String("diagnostic-mount-namespace", os.Getenv("DIAGNOSTIC_MOUNT_NAMESPACE"), "Only namespace where Pods can use diagnostic mounts. Empty disables diagnostic mounts.")
+	ephemeralVolumes                      = flag.Bool("ephemeral-volumes", os.Getenv("EPHEMERAL_VOLUMES_ENABLED") == "true", "Create Mountpoint Pods for inline ephemeral volumes other than diagnostic mounts.")
+	mountHealthChecks                     = flag.Bool("mount-health-checks", os.Getenv("MOUNT_HEALTH_CHECKS_ENABLED") == "true", "Add a liveness probe to Mountpoint containers, restarting them once the node plugin reports their mount unresponsive.")
+	consistencyCheckInterval              = flag.String("consistency-check-interval", os.Getenv("CONSISTENCY_CHECK_INTERVAL"), "Interval between mount consistency verifications. Empty or zero disables verifications.")
+	consistencyCheckSampleSize            = flag.Int("consistency-check-sample-size", 1, "Number of mounts verified in each consistency verification round.")
+	consistencyCheckMaxEntries            = flag.Int("consistency-check-max-entries", 50, "Maximum number of entries compared per mount during consistency verifications.")
+	consistencyCheckNamespace             = flag.String("consistency-check-namespace", os.Getenv("CONSISTENCY_CHECK_NAMESPACE"), "Namespace to create consistency checker Pods in.")
+	bucketMetricsUTAPIEndpointURL         = flag.String("bucket-metrics-utapi-endpoint-url", os.Getenv("BUCKET_METRICS_UTAPI_ENDPOINT_URL"), "Scality UTAPI endpoint to query request rates of mounted buckets from. Empty disables bucket metrics.")
+	bucketMetricsInterval                 = flag.String("bucket-metrics-interval", os.Getenv("BUCKET_METRICS_INTERVAL"), "Interval between queries of request rates of mounted buckets.")
+	bucketMetricsWindow                   = flag.Duration("bucket-metrics-window", 15*time.Minute, "Window over which request rates of mounted buckets are averaged.")
+	volumeUsageMetrics                    = flag.Bool("volume-usage-metrics", os.Getenv("VOLUME_USAGE_METRICS_ENABLED") == "true", "Expose the usage of the buckets of bound volumes queried from UTAPI, labelled with their claims. Requires a bucket metrics UTAPI endpoint.")
+	volumeUsageAnnotations                = flag.Bool("volume-usage-annotations", os.Getenv("VOLUME_USAGE_ANNOTATIONS_ENABLED") == "true", "Annotate claims with the last-known usage of their volume, with volume usage metrics.")
+	prefixQuotaInterval                   = flag.String("prefix-quota-interval", os.Getenv("PREFIX_QUOTA_INTERVAL"), "Interval between usage measurements of prefixes of volumes against their requested storage. Empty or zero disables prefix quotas.")
+	prefixQuotaMaxObjects                 = flag.Int("prefix-quota-max-objects", 100000, "Maximum number of objects listed to measure the usage of a prefix, larger prefixes are not measured.")
+	divergenceWatchdogInterval            = flag.String("divergence-watchdog-interval", os.Getenv("DIVERGENCE_WATCHDOG_INTERVAL"), "Interval between checks of divergences between Mountpoint Pods, attachments and node mounts. Empty or zero disables the checks.")
+	divergenceWatchdogAutoRepair          = flag.Bool("divergence-watchdog-auto-repair", os.Getenv("DIVERGENCE_WATCHDOG_AUTO_REPAIR") == "true", "Mark orphan Mountpoint Pods for unmounting and remove dangling attachments found by divergence checks.")
+	rollingRemounts                       = flag.Bool("rolling-remounts", os.Getenv("ROLLING_REMOUNTS_ENABLED") == "true", "Evict workloads of volumes annotated for rolling remounts one at a time after the mount options of their volume change.")
+	attachmentMaxLifetime                 = flag.String("attachment-max-lifetime", os.Getenv("ATTACHMENT_MAX_LIFETIME"), "Maximum time workloads stay attached to a Mountpoint Pod before being evicted to remount their volumes with fresh credentials. Empty or zero disables the maximum lifetime.")
+	attachmentLifetimeStagger             = flag.String("attachment-lifetime-stagger", os.Getenv("ATTACHMENT_LIFETIME_STAGGER"), "Window before the maximum attachment lifetime over which remounts of Mountpoint Pods are spread.")
+	hostAliasesConfigMap                  = flag.String("host-aliases-configmap", os.Getenv("MOUNTPOINT_HOST_ALIASES_CONFIGMAP"), "Name of the ConfigMap of hostname to IP overrides of Mountpoint Pods in the Mountpoint namespace. Empty disables host aliases.")
+	kubeletPath                           = flag.String("kubelet-path", util.KubeletPath(), "Kubelet root directory on the nodes.")
 	tlsCACertConfigMap                    = flag.String("tls-ca-cert-configmap", os.Getenv("TLS_CA_CERT_CONFIGMAP"), "Name of ConfigMap containing custom CA certificate(s).")
 	tlsInitImage                          = flag.String("tls-init-image", os.Getenv("TLS_INIT_IMAGE"), "Image for CA certificate installation initContainer.")
 	tlsInitImagePullPolicy                = flag.String("tls-init-image-pull-policy", os.Getenv("TLS_INIT_IMAGE_PULL_POLICY"), "Pull policy for TLS init image.")
 	tlsInitResourcesReqCPU                = flag.String("tls-init-resources-req-cpu", os.Getenv("TLS_INIT_RESOURCES_REQUESTS_CPU"), "CPU request for TLS init container.")
 	tlsInitResourcesReqMemory             = flag.String("tls-init-resources-req-memory", os.Getenv("TLS_INIT_RESOURCES_REQUESTS_MEMORY"), "Memory request for TLS init container.")
 	tlsInitResourcesLimMemory             = flag.String("tls-init-resources-lim-memory", os.Getenv("TLS_INIT_RESOURCES_LIMITS_MEMORY"), "Memory limit for TLS init container.")
+	leaderElection                        = flag.Bool("leader-elect", os.Getenv("LEADER_ELECTION_ENABLED") == "true", "Elect a leader among replicas of the controller with a Lease, only the leader reconciles and runs background tasks.")
+	leaderElectionNamespace               = flag.String("leader-election-namespace", os.Getenv("LEADER_ELECTION_NAMESPACE"), "Namespace of the leader election Lease. Empty uses the namespace of the controller.")
+	leaderElectionLeaseName               = flag.String("leader-election-lease-name", os.Getenv("LEADER_ELECTION_LEASE_NAME"), "Name of the leader election Lease. Empty uses \""+defaultLeaderElectionLeaseName+"\".")
+	kubeAPIQPS                            = flag.String("kube-api-qps", os.Getenv(util.EnvKubeAPIQPS), "Requests per second of the controller to the Kubernetes API server. Empty keeps the default of controller-runtime.")
+	kubeAPIBurst                          = flag.String("kube-api-burst", os.Getenv(util.EnvKubeAPIBurst), "Bursts of requests of the controller to the Kubernetes API server. Empty keeps the default of controller-runtime.")
+	configFile                            = flag.String("config-file", os.Getenv(driverconfig.EnvConfigFile), "Driver configuration file, reloaded when it changes. Empty disables the configuration file.")
+	healthProbeBindAddress                = flag.String("health-probe-bind-address", ":8081", "Address the /healthz and /readyz endpoints bind to. \"0\" disables them.")
 )
 
+// defaultLeaderElectionLeaseName is the name of the leader election Lease if none is configured.
+const defaultLeaderElectionLeaseName = "s3-csi-controller-leader"
+
 var scheme = runtime.NewScheme()
 
 func init() {
@@ -54,18 +123,30 @@ func init() {
 func main() {
 	flag.Parse()
 
-	logf.SetLogger(zap.New())
+	// The log level is atomic so the driver configuration file can change it while running
+	logLevel := uzap.NewAtomicLevel()
+	logf.SetLogger(zap.New(zap.Level(logLevel)))
 
 	log := logf.Log.WithName(csicontroller.Name)
 	conf := config.GetConfigOrDie()
+	if err := util.SetKubeAPIRateLimit(conf, *kubeAPIQPS, *kubeAPIBurst); err != nil {
+		log.Error(err, "invalid Kubernetes API rate limit")
+		os.Exit(1)
+	}
 
-	mgr, err := manager.New(conf, manager.Options{
-		Scheme: scheme,
-	})
+	mgr, err := manager.New(conf, buildManagerOptions(log))
 	if err != nil {
 		log.Error(err, "failed to create a new manager")
 		os.Exit(1)
 	}
+	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
+		log.Error(err, "failed to add health check")
+		os.Exit(1)
+	}
+	if err := mgr.AddReadyzCheck("readyz", cacheSyncedCheck(mgr.GetCache())); err != nil {
+		log.Error(err, "failed to add readiness check")
+		os.Exit(1)
+	}
 
 	// Setup field indexers for MountpointS3PodAttachment CRDs
 	if err := crdv2.SetupManagerIndices(mgr); err != nil {
@@ -84,35 +165,574 @@ func main() {
 			Image:           *mountpointImage,
 			HeadroomImage:   *headroomImage,
 			ImagePullPolicy: corev1.PullPolicy(*mountpointImagePullPolicy),
+			BinaryDigests:   validateBinaryDigests(log),
+			MaxRestarts:     parseMountpointMaxRestarts(log),
+			ShutdownTimeout: parseMountpointShutdownTimeout(log),
 		},
 		CSIDriverVersion: version.GetVersion().DriverVersion,
 		ClusterVariant:   cluster.DetectVariant(conf, log),
 		TLS:              buildTLSConfig(log),
+		LingerDuration:   parseLingerDuration(log),
+		DrainTimeout:     parseDrainTimeout(log),
+		HeadroomPodTTL:   parseHeadroomPodTTL(log),
+		Resources:        buildMountpointResources(log),
+		PodOptions:       buildMountpointPodOptions(log),
+
+		DiagnosticMountNamespace: *diagnosticMountNamespace,
+		EphemeralVolumes:         *ephemeralVolumes,
+		MountHealthChecks:        *mountHealthChecks,
+	}
+	podConfig.MountFailureBudget, podConfig.MountFailureWindow = parseMountFailureBudget(log)
+
+	// Setup signal handler once and share context
+	ctx := signals.SetupSignalHandler()
+
+	// Apply the log level of the driver configuration file, and again each time its ConfigMap changes
+	if *configFile != "" {
+		configWatcher := newConfigWatcher(log, mgr.GetEventRecorderFor(csicontroller.Name), logLevel)
+		if _, err := configWatcher.Reload(); err != nil {
+			log.Error(err, "invalid driver configuration, flags and environment variables are used until it is fixed")
+		}
+		go configWatcher.Start(ctx.Done(), driverconfig.ReloadInterval)
+	}
+
+	// Setup the host aliases reconciler, and load the host aliases before the first Mountpoint Pod is created
+	if *hostAliasesConfigMap != "" {
+		podConfig.HostAliases = mppod.NewHostAliases()
+		hostAliasesReconciler := csicontroller.NewHostAliasesReconciler(mgr.GetClient(), mgr.GetEventRecorderFor(csicontroller.Name),
+			types.NamespacedName{Namespace: podConfig.Namespace, Name: *hostAliasesConfigMap}, podConfig.HostAliases)
+		if err := hostAliasesReconciler.Sync(ctx, mgr.GetAPIReader()); err != nil {
+			log.Error(err, "failed to load host aliases")
+			os.Exit(1)
+		}
+		if err := hostAliasesReconciler.SetupWithManager(mgr); err != nil {
+			log.Error(err, "failed to create host aliases reconciler")
+			os.Exit(1)
+		}
+		log.Info("Host aliases of Mountpoint Pods enabled", "configmap", *hostAliasesConfigMap)
 	}
 
 	// Setup the pod reconciler that will create MountpointS3PodAttachments
 	reconciler := csicontroller.NewReconciler(mgr.GetClient(), podConfig)
+	reconciler.SetEventRecorder(mgr.GetEventRecorderFor(csicontroller.Name))
 	err = reconciler.SetupWithManager(mgr)
 	if err != nil {
 		log.Error(err, "failed to create pod reconciler")
 		os.Exit(1)
 	}
 
-	// Setup signal handler once and share context
-	ctx := signals.SetupSignalHandler()
+	// Setup the finalizer of MountpointS3PodAttachments, removing them only once their Mountpoint Pods are gone
+	if err := csicontroller.NewS3PodAttachmentFinalizer(reconciler).SetupWithManager(mgr); err != nil {
+		log.Error(err, "failed to create MountpointS3PodAttachment finalizer")
+		os.Exit(1)
+	}
 
 	// Start stale attachment cleaner in background
 	cleaner := csicontroller.NewStaleAttachmentCleaner(reconciler)
-	go func() {
-		if err := cleaner.Start(ctx); err != nil {
-			log.Error(err, "stale attachment cleaner failed")
+	addBackgroundTask(mgr, log, "stale attachment cleaner", cleaner)
+
+	// Start Mountpoint Pod janitor in background
+	janitor := csicontroller.NewMountpointPodJanitor(reconciler)
+	addBackgroundTask(mgr, log, "Mountpoint Pod janitor", janitor)
+
+	// Start Mountpoint Pod upgrader in background
+	upgrader := csicontroller.NewMountpointUpgrader(reconciler)
+	addBackgroundTask(mgr, log, "Mountpoint Pod upgrader", upgrader)
+
+	// Start rolling remounter in background, if enabled
+	if *rollingRemounts {
+		remounter := csicontroller.NewRollingRemounter(reconciler)
+		addBackgroundTask(mgr, log, "rolling remounter", remounter)
+	}
+
+	// Start attachment lifetime enforcer in background, if enabled
+	if lifetimeConfig := buildAttachmentLifetimeConfig(log); lifetimeConfig != nil {
+		enforcer := csicontroller.NewAttachmentLifetimeEnforcer(reconciler, *lifetimeConfig)
+		addBackgroundTask(mgr, log, "attachment lifetime enforcer", enforcer)
+	}
+
+	// Start read-only window scheduler in background
+	readOnlyWindowScheduler := csicontroller.NewReadOnlyWindowScheduler(mgr.GetClient(), mgr.GetEventRecorderFor(csicontroller.Name))
+	addBackgroundTask(mgr, log, "read-only window scheduler", readOnlyWindowScheduler)
+
+	// Start S3 volume inventory reporter in background
+	inventoryReporter := csicontroller.NewInventoryReporter(mgr.GetClient(), podConfig.Namespace)
+	addBackgroundTask(mgr, log, "S3 volume inventory reporter", inventoryReporter)
+
+	// Start divergence watchdog in background, if enabled
+	if interval := parseDivergenceWatchdogInterval(log); interval > 0 {
+		watchdog := csicontroller.NewDivergenceWatchdog(mgr.GetClient(), podConfig.Namespace, interval, *divergenceWatchdogAutoRepair)
+		addBackgroundTask(mgr, log, "divergence watchdog", watchdog)
+	}
+
+	// Start mount consistency verifier in background, if enabled
+	if verifierConfig := buildConsistencyVerifierConfig(log); verifierConfig != nil {
+		s3Client, err := newConsistencyCheckS3Client(ctx)
+		if err != nil {
+			log.Error(err, "failed to create S3 client for consistency verifications")
+			os.Exit(1)
 		}
-	}()
+		verifierConfig.MountpointNamespace = podConfig.Namespace
+		verifierConfig.CheckerImage = podConfig.Container.Image
+		verifierConfig.CheckerImagePullPolicy = podConfig.Container.ImagePullPolicy
+		verifier := csicontroller.NewConsistencyVerifier(mgr.GetClient(), s3Client, mgr.GetEventRecorderFor(csicontroller.Name), *verifierConfig)
+		addBackgroundTask(mgr, log, "consistency verifier", verifier)
+	}
 
+	// Start prefix quota enforcer in background, if enabled
+	if quotaConfig := buildPrefixQuotaEnforcerConfig(log); quotaConfig != nil {
+		s3Client, err := newConsistencyCheckS3Client(ctx)
+		if err != nil {
+			log.Error(err, "failed to create S3 client for prefix quotas")
+			os.Exit(1)
+		}
+		enforcer := csicontroller.NewPrefixQuotaEnforcer(mgr.GetClient(), s3Client, mgr.GetEventRecorderFor(csicontroller.Name), *quotaConfig)
+		addBackgroundTask(mgr, log, "prefix quota enforcer", enforcer)
+	}
+
+	// Start bucket metrics collector in background, if enabled
+	if collectorConfig := buildBucketMetricsCollectorConfig(log); collectorConfig != nil {
+		utapiClient, err := newBucketMetricsUTAPIClient(ctx)
+		if err != nil {
+			log.Error(err, "failed to create UTAPI client for bucket metrics")
+			os.Exit(1)
+		}
+		collector := csicontroller.NewBucketMetricsCollector(mgr.GetClient(), utapiClient, *collectorConfig)
+		addBackgroundTask(mgr, log, "bucket metrics collector", collector)
+
+		if *volumeUsageMetrics {
+			usageCollector := csicontroller.NewVolumeUsageCollector(mgr.GetClient(), utapiClient, csicontroller.VolumeUsageCollectorConfig{
+				Interval:       collectorConfig.Interval,
+				Window:         collectorConfig.Window,
+				AnnotateClaims: *volumeUsageAnnotations,
+			})
+			addBackgroundTask(mgr, log, "volume usage collector", usageCollector)
+		}
+	}
+
+	// The manager stops once the leader election Lease is lost, the controller exits to restart as a candidate
 	if err := mgr.Start(ctx); err != nil {
-		log.Error(err, "failed to start manager")
+		log.Error(err, "manager stopped")
+		os.Exit(1)
+	}
+}
+
+// buildManagerOptions returns the options of the manager, electing a leader among replicas of the controller if enabled.
+// The leader releases its Lease when stopped, so another replica takes over without waiting for the Lease to expire.
+func buildManagerOptions(log logr.Logger) manager.Options {
+	options := manager.Options{
+		Scheme:                 scheme,
+		Cache:                  buildCacheOptions(),
+		HealthProbeBindAddress: *healthProbeBindAddress,
+	}
+	if !*leaderElection {
+		return options
+	}
+
+	options.LeaderElection = true
+	options.LeaderElectionNamespace = *leaderElectionNamespace
+	options.LeaderElectionID = *leaderElectionLeaseName
+	if options.LeaderElectionID == "" {
+		options.LeaderElectionID = defaultLeaderElectionLeaseName
+	}
+	options.LeaderElectionReleaseOnCancel = true
+	log.Info("Leader election enabled", "namespace", options.LeaderElectionNamespace, "lease", options.LeaderElectionID)
+	return options
+}
+
+// newConfigWatcher returns a watcher of the driver configuration file applying its log level to the controller logs
+// through `logLevel`, going back to the default level if unset. Log levels are logr verbosities, e.g. `logLevel: 4`
+// enables `log.V(4)` logs.
+func newConfigWatcher(log logr.Logger, events record.EventRecorder, logLevel uzap.AtomicLevel) *driverconfig.Watcher {
+	configWatcher := driverconfig.NewWatcher(*configFile)
+	metrics.Registry.MustRegister(driverconfig.ReloadsTotal, driverconfig.ConfigValid)
+	if configMap := os.Getenv(driverconfig.EnvConfigMap); configMap != "" {
+		if err := configWatcher.SetEventRecorder(events, configMap); err != nil {
+			log.Error(err, "invalid driver configuration ConfigMap, invalid configurations are not reported in events")
+		}
+	}
+	configWatcher.OnChange(driverconfig.KlogLevelHandler())
+	configWatcher.OnChange(func(config *driverconfig.Config) {
+		level := zapcore.InfoLevel
+		if config.LogLevel != nil {
+			level = zapcore.Level(-*config.LogLevel)
+		}
+		logLevel.SetLevel(level)
+	})
+	return configWatcher
+}
+
+// cacheSyncedCheck returns a readiness check passing once the informers of `c` are synced.
+func cacheSyncedCheck(c cache.Cache) healthz.Checker {
+	return func(req *http.Request) error {
+		if !c.WaitForCacheSync(req.Context()) {
+			return errors.New("informers not synced")
+		}
+		return nil
+	}
+}
+
+// addBackgroundTask adds `task` to the manager, which starts it once this replica is elected leader and stops it
+// when the manager stops. Errors of the task are logged without stopping the manager.
+func addBackgroundTask(mgr manager.Manager, log logr.Logger, name string, task manager.Runnable) {
+	err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		if err := task.Start(ctx); err != nil {
+			log.Error(err, name+" failed")
+		}
+		return nil
+	}))
+	if err != nil {
+		log.Error(err, "failed to add "+name)
+		os.Exit(1)
+	}
+}
+
+// buildCacheOptions restricts the cache of MountpointS3PodAttachments to the ones of this driver instance, and the
+// cache of ConfigMaps to the host aliases ConfigMap, the only ConfigMap watched.
+func buildCacheOptions() cache.Options {
+	options := cache.Options{
+		ByObject: map[client.Object]cache.ByObject{
+			&crdv2.MountpointS3PodAttachment{}: {
+				Label: crdv2.DriverNameSelector(util.DriverName()),
+			},
+		},
+	}
+	if *hostAliasesConfigMap != "" {
+		options.ByObject[&corev1.ConfigMap{}] = cache.ByObject{
+			Namespaces: map[string]cache.Config{*mountpointNamespace: {}},
+			Field:      fields.OneTermEqualSelector("metadata.name", *hostAliasesConfigMap),
+		}
+	}
+	return options
+}
+
+// parseLingerDuration parses the Mountpoint Pod linger duration from flags/env vars. Returns zero if not set.
+func parseLingerDuration(log logr.Logger) time.Duration {
+	if *mountpointPodLingerDuration == "" {
+		return 0
+	}
+
+	lingerDuration, err := time.ParseDuration(*mountpointPodLingerDuration)
+	if err != nil || lingerDuration < 0 {
+		log.Error(err, "invalid Mountpoint Pod linger duration", "value", *mountpointPodLingerDuration)
+		os.Exit(1)
+	}
+
+	if lingerDuration > 0 {
+		log.Info("Mountpoint Pod lingering enabled", "lingerDuration", lingerDuration)
+	}
+	return lingerDuration
+}
+
+// parseDrainTimeout parses the Mountpoint Pod drain timeout from flags/env vars. Returns zero if not set.
+func parseDrainTimeout(log logr.Logger) time.Duration {
+	if *mountpointPodDrainTimeout == "" {
+		return 0
+	}
+
+	drainTimeout, err := time.ParseDuration(*mountpointPodDrainTimeout)
+	if err != nil || drainTimeout < 0 {
+		log.Error(err, "invalid Mountpoint Pod drain timeout", "value", *mountpointPodDrainTimeout)
+		os.Exit(1)
+	}
+
+	if drainTimeout > 0 {
+		log.Info("Mountpoint Pod draining enabled", "drainTimeout", drainTimeout)
+	}
+	return drainTimeout
+}
+
+// parseHeadroomPodTTL parses the Headroom Pod TTL from flags/env vars. Returns zero if not set.
+func parseHeadroomPodTTL(log logr.Logger) time.Duration {
+	if *headroomPodTTL == "" {
+		return 0
+	}
+
+	ttl, err := time.ParseDuration(*headroomPodTTL)
+	if err != nil || ttl < 0 {
+		log.Error(err, "invalid Headroom Pod TTL", "value", *headroomPodTTL)
+		os.Exit(1)
+	}
+	return ttl
+}
+
+// parseMountpointMaxRestarts returns the number of restarts of Mountpoint after crashes from flags/env vars, and
+// exits if it is invalid.
+func parseMountpointMaxRestarts(log logr.Logger) int {
+	if *mountpointMaxRestarts == "" {
+		return 0
+	}
+	maxRestarts, err := strconv.Atoi(*mountpointMaxRestarts)
+	if err != nil || maxRestarts < 0 {
+		log.Error(err, "invalid number of Mountpoint restarts", "value", *mountpointMaxRestarts)
+		os.Exit(1)
+	}
+	return maxRestarts
+}
+
+// parseMountpointShutdownTimeout parses the time Mountpoint has to exit after an unmount from flags/env vars. Returns
+// zero if not set.
+func parseMountpointShutdownTimeout(log logr.Logger) time.Duration {
+	if *mountpointShutdownTimeout == "" {
+		return 0
+	}
+	shutdownTimeout, err := time.ParseDuration(*mountpointShutdownTimeout)
+	if err != nil || shutdownTimeout < 0 {
+		log.Error(err, "invalid Mountpoint shutdown timeout", "value", *mountpointShutdownTimeout)
+		os.Exit(1)
+	}
+	return shutdownTimeout
+}
+
+// parseMountFailureBudget parses the mount failure budget and window from flags/env vars. Returns zeros if not set.
+func parseMountFailureBudget(log logr.Logger) (int, time.Duration) {
+	if *mountFailureBudget == "" {
+		return 0, 0
+	}
+
+	budget, err := strconv.Atoi(*mountFailureBudget)
+	if err != nil || budget < 0 {
+		log.Error(err, "invalid mount failure budget", "value", *mountFailureBudget)
+		os.Exit(1)
+	}
+	if budget == 0 {
+		return 0, 0
+	}
+
+	window := 10 * time.Minute
+	if *mountFailureWindow != "" {
+		window, err = time.ParseDuration(*mountFailureWindow)
+		if err != nil || window <= 0 {
+			log.Error(err, "invalid mount failure window", "value", *mountFailureWindow)
+			os.Exit(1)
+		}
+	}
+
+	log.Info("Mount failure budget enabled", "budget", budget, "window", window)
+	return budget, window
+}
+
+// validateBinaryDigests returns the expected digests of the Mountpoint binary from flags/env vars, after checking
+// they can be parsed by Mountpoint Pods.
+func validateBinaryDigests(log logr.Logger) string {
+	if _, err := mountpoint.ParseBinaryDigests(*mountpointBinaryDigests); err != nil {
+		log.Error(err, "invalid Mountpoint binary digests")
+		os.Exit(1)
+	}
+	return strings.TrimSpace(*mountpointBinaryDigests)
+}
+
+// buildMountpointPodOptions parses the default tolerations, labels, annotations and topology spread constraints of
+// Mountpoint Pods from flags/env vars, overridden per volume with volume attributes.
+func buildMountpointPodOptions(log logr.Logger) mppod.PodOptions {
+	options, err := mppod.ParsePodOptions(map[string]string{
+		volumecontext.MountpointPodTolerations:               *mountpointPodTolerations,
+		volumecontext.MountpointPodLabels:                    *mountpointPodLabels,
+		volumecontext.MountpointPodAnnotations:               *mountpointPodAnnotations,
+		volumecontext.MountpointPodTopologySpreadConstraints: *mountpointPodTopologySpread,
+	})
+	if err != nil {
+		log.Error(err, "invalid Mountpoint Pod options")
 		os.Exit(1)
 	}
+	return options
+}
+
+// buildMountpointResources constructs default resources of Mountpoint containers from flags/env vars.
+// Unset values are left empty, to be set per volume with volume attributes.
+func buildMountpointResources(log logr.Logger) corev1.ResourceRequirements {
+	resources := corev1.ResourceRequirements{}
+	for _, r := range []struct {
+		value string
+		list  *corev1.ResourceList
+		name  corev1.ResourceName
+	}{
+		{*mountpointResourcesReqCPU, &resources.Requests, corev1.ResourceCPU},
+		{*mountpointResourcesReqMemory, &resources.Requests, corev1.ResourceMemory},
+		{*mountpointResourcesLimCPU, &resources.Limits, corev1.ResourceCPU},
+		{*mountpointResourcesLimMemory, &resources.Limits, corev1.ResourceMemory},
+	} {
+		if r.value == "" {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(r.value)
+		if err != nil {
+			log.Error(err, "invalid Mountpoint container resources", "resource", r.name, "value", r.value)
+			os.Exit(1)
+		}
+		if *r.list == nil {
+			*r.list = make(corev1.ResourceList)
+		}
+		(*r.list)[r.name] = quantity
+	}
+	return resources
+}
+
+// parseDivergenceWatchdogInterval parses the interval of divergence checks from flags/env vars. Returns zero if not set.
+func parseDivergenceWatchdogInterval(log logr.Logger) time.Duration {
+	if *divergenceWatchdogInterval == "" {
+		return 0
+	}
+
+	interval, err := time.ParseDuration(*divergenceWatchdogInterval)
+	if err != nil || interval < 0 {
+		log.Error(err, "invalid divergence watchdog interval", "value", *divergenceWatchdogInterval)
+		os.Exit(1)
+	}
+
+	if interval > 0 {
+		log.Info("Divergence watchdog enabled", "interval", interval, "autoRepair", *divergenceWatchdogAutoRepair)
+	}
+	return interval
+}
+
+// buildConsistencyVerifierConfig constructs a ConsistencyVerifierConfig from flags/env vars.
+// Returns nil if consistency verifications are disabled.
+func buildConsistencyVerifierConfig(log logr.Logger) *csicontroller.ConsistencyVerifierConfig {
+	if *consistencyCheckInterval == "" {
+		return nil
+	}
+
+	interval, err := time.ParseDuration(*consistencyCheckInterval)
+	if err != nil || interval < 0 {
+		log.Error(err, "invalid consistency check interval", "value", *consistencyCheckInterval)
+		os.Exit(1)
+	}
+	if interval == 0 {
+		return nil
+	}
+
+	if *consistencyCheckNamespace == "" {
+		log.Error(nil, "consistency check namespace must be set to enable consistency verifications")
+		os.Exit(1)
+	}
+
+	log.Info("Mount consistency verifications enabled", "interval", interval, "sampleSize", *consistencyCheckSampleSize)
+
+	return &csicontroller.ConsistencyVerifierConfig{
+		Interval:       interval,
+		SampleSize:     *consistencyCheckSampleSize,
+		MaxEntries:     *consistencyCheckMaxEntries,
+		SettleTime:     time.Minute,
+		CheckerTimeout: 2 * time.Minute,
+		Namespace:      *consistencyCheckNamespace,
+		KubeletPath:    *kubeletPath,
+		CheckerCommand: "/bin/scality-csi-checker",
+	}
+}
+
+// buildPrefixQuotaEnforcerConfig constructs a PrefixQuotaEnforcerConfig from flags/env vars.
+// Returns nil if prefix quotas are disabled.
+func buildPrefixQuotaEnforcerConfig(log logr.Logger) *csicontroller.PrefixQuotaEnforcerConfig {
+	if *prefixQuotaInterval == "" {
+		return nil
+	}
+
+	interval, err := time.ParseDuration(*prefixQuotaInterval)
+	if err != nil || interval < 0 {
+		log.Error(err, "invalid prefix quota interval", "value", *prefixQuotaInterval)
+		os.Exit(1)
+	}
+	if interval == 0 {
+		return nil
+	}
+	if *prefixQuotaMaxObjects <= 0 {
+		log.Error(nil, "invalid prefix quota maximum number of objects", "value", *prefixQuotaMaxObjects)
+		os.Exit(1)
+	}
+
+	log.Info("Prefix quotas enabled", "interval", interval, "maxObjects", *prefixQuotaMaxObjects)
+
+	return &csicontroller.PrefixQuotaEnforcerConfig{
+		Interval:   interval,
+		MaxObjects: *prefixQuotaMaxObjects,
+	}
+}
+
+// buildAttachmentLifetimeConfig constructs an AttachmentLifetimeConfig from flags/env vars.
+// Returns nil if the maximum attachment lifetime is disabled.
+func buildAttachmentLifetimeConfig(log logr.Logger) *csicontroller.AttachmentLifetimeConfig {
+	if *attachmentMaxLifetime == "" {
+		return nil
+	}
+
+	maxLifetime, err := time.ParseDuration(*attachmentMaxLifetime)
+	if err != nil || maxLifetime < 0 {
+		log.Error(err, "invalid maximum attachment lifetime", "value", *attachmentMaxLifetime)
+		os.Exit(1)
+	}
+	if maxLifetime == 0 {
+		return nil
+	}
+
+	stagger := time.Duration(0)
+	if *attachmentLifetimeStagger != "" {
+		stagger, err = time.ParseDuration(*attachmentLifetimeStagger)
+		if err != nil || stagger < 0 || stagger > maxLifetime {
+			log.Error(err, "invalid attachment lifetime stagger, must be between zero and the maximum attachment lifetime", "value", *attachmentLifetimeStagger)
+			os.Exit(1)
+		}
+	}
+
+	log.Info("Maximum attachment lifetime enabled", "maxLifetime", maxLifetime, "stagger", stagger)
+
+	return &csicontroller.AttachmentLifetimeConfig{
+		MaxLifetime: maxLifetime,
+		Stagger:     stagger,
+	}
+}
+
+// newConsistencyCheckS3Client creates an S3 client from the driver-level credentials and endpoint in env vars.
+func newConsistencyCheckS3Client(ctx context.Context) (*s3.Client, error) {
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		o.UsePathStyle = true
+	}), nil
+}
+
+// buildBucketMetricsCollectorConfig constructs a BucketMetricsCollectorConfig from flags/env vars.
+// Returns nil if no UTAPI endpoint is set.
+func buildBucketMetricsCollectorConfig(log logr.Logger) *csicontroller.BucketMetricsCollectorConfig {
+	if *bucketMetricsUTAPIEndpointURL == "" {
+		return nil
+	}
+
+	interval := time.Minute
+	if *bucketMetricsInterval != "" {
+		var err error
+		interval, err = time.ParseDuration(*bucketMetricsInterval)
+		if err != nil || interval <= 0 {
+			log.Error(err, "invalid bucket metrics interval", "value", *bucketMetricsInterval)
+			os.Exit(1)
+		}
+	}
+	if *bucketMetricsWindow <= 0 {
+		log.Error(nil, "invalid bucket metrics window", "value", *bucketMetricsWindow)
+		os.Exit(1)
+	}
+
+	log.Info("Bucket metrics enabled", "utapiEndpointURL", *bucketMetricsUTAPIEndpointURL, "interval", interval)
+
+	return &csicontroller.BucketMetricsCollectorConfig{
+		Interval: interval,
+		Window:   *bucketMetricsWindow,
+	}
+}
+
+// newBucketMetricsUTAPIClient creates a UTAPI client from the driver-level credentials and region in env vars.
+func newBucketMetricsUTAPIClient(ctx context.Context) (*utapi.Client, error) {
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return utapi.New(utapi.Config{
+		EndpointURL: *bucketMetricsUTAPIEndpointURL,
+		Region:      awsCfg.Region,
+		Credentials: awsCfg.Credentials,
+	})
 }
 
 // buildTLSConfig constructs a TLSConfig from flags/env vars. Returns nil if no ConfigMap name is set.
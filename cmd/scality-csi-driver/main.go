@@ -21,8 +21,13 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
 
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/mounter"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/version"
 	"k8s.io/klog/v2"
 )
@@ -35,10 +40,18 @@ const (
 
 func main() {
 	var (
-		endpoint     = flag.String("endpoint", "unix://tmp/csi.sock", "CSI Endpoint")
-		printVersion = flag.Bool("version", false, "Print the version and exit")
-		mpVersion    = flag.String("mp-version", os.Getenv("MOUNTPOINT_VERSION"), "mp version to report in service name")
-		nodeID       = flag.String("node-id", os.Getenv(NodeIDEnvVar), "node-id to report in NodeGetInfo RPC")
+		endpoint      = flag.String("endpoint", "unix://tmp/csi.sock", "CSI Endpoint")
+		printVersion  = flag.Bool("version", false, "Print the version and exit")
+		mpVersion     = flag.String("mp-version", os.Getenv("MOUNTPOINT_VERSION"), "mp version to report in service name")
+		nodeID        = flag.String("node-id", os.Getenv(NodeIDEnvVar), "node-id to report in NodeGetInfo RPC")
+		telemetryTags = flag.String("telemetry-tags", os.Getenv(mounter.EnvTelemetryTags),
+			"comma-separated tags added to the user-agent of Mountpoint: `name=value`, `name-label=<label key of the workload Pod>` or `namespace`")
+		maxConcurrentMounts = flag.Int("max-concurrent-mounts", envInt(mounter.EnvMaxConcurrentMounts),
+			"maximum number of volumes mounted at the same time by the pod mounter, other mounts are queued in arrival order. Zero disables the limit")
+		credentialsFileDir = flag.String("credentials-file-dir", os.Getenv(credentialprovider.EnvCredentialsFileDir),
+			"directory holding credentials of volumes with `authenticationSource: file`, one subdirectory per credentials name. Disabled if empty")
+		tokenAudience = flag.String("service-account-token-audience", os.Getenv(credentialprovider.EnvServiceAccountTokenAudience),
+			"audience of the service account tokens volumes with `authenticationSource: webIdentity` assume their role with, unless overridden by their `serviceAccountTokenAudience` attribute. Defaults to "+credentialprovider.DefaultServiceAccountTokenAudience)
 	)
 	klog.InitFlags(nil)
 	// Set logging to stderr false otherwise klog won't call our logger set via
@@ -69,16 +82,44 @@ func main() {
 		klog.Fatalln("node-id is required")
 	}
 
-	drv, err := driver.NewDriver(*endpoint, *mpVersion, *nodeID)
+	tags, err := mounter.ParseTelemetryTags(*telemetryTags)
+	if err != nil {
+		klog.Fatalf("invalid telemetry-tags: %s", err)
+	}
+
+	if *maxConcurrentMounts < 0 {
+		klog.Fatalf("invalid max-concurrent-mounts %d, must not be negative", *maxConcurrentMounts)
+	}
+
+	drv, err := driver.NewDriver(*endpoint, *mpVersion, *nodeID, driver.Options{
+		TelemetryTags:               tags,
+		MaxConcurrentMounts:         *maxConcurrentMounts,
+		CredentialsFileDir:          *credentialsFileDir,
+		ServiceAccountTokenAudience: *tokenAudience,
+	})
 	if err != nil {
 		klog.Fatalf("failed to create driver: %s", err)
 	}
 
+	// Stop the driver on termination, so it labels its Node as not ready before exiting
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+		<-signals
+		drv.Stop()
+	}()
+
 	if err := drv.Run(); err != nil {
 		klog.Fatalln(err)
 	}
 }
 
+// envInt returns the integer value of environment variable `name`, zero if it is not set or not an integer.
+func envInt(name string) int {
+	value, _ := strconv.Atoi(os.Getenv(name))
+	return value
+}
+
 var (
 	newline       = []byte("\n")
 	newlineEscape = []byte("")
@@ -0,0 +1,43 @@
+package csimounter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/google/renameio"
+	"k8s.io/klog/v2"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mountoptions"
+)
+
+// serveControl serves the control socket at `sockPath` until `ctx` is done, rewriting credential files in
+// `credentialsDir` with the refreshed credentials pushed by the CSI Driver Node Pod.
+func serveControl(ctx context.Context, sockPath, credentialsDir string) {
+	err := mountoptions.ServeControl(ctx, sockPath, func(update mountoptions.CredentialUpdate) error {
+		return writeCredentialFiles(credentialsDir, update)
+	})
+	if err != nil {
+		klog.Errorf("failed to serve control socket %s, refreshed credentials will be written by the CSI Driver Node Pod: %v", sockPath, err)
+	}
+}
+
+// writeCredentialFiles atomically writes the files of `update` in `credentialsDir`, so Mountpoint never reads partial
+// credentials.
+func writeCredentialFiles(credentialsDir string, update mountoptions.CredentialUpdate) error {
+	var errs []error
+	for _, file := range update.Files {
+		if file.Name == "" || file.Name != filepath.Base(file.Name) || file.Name == "." || file.Name == ".." {
+			errs = append(errs, fmt.Errorf("invalid credential file name %q", file.Name))
+			continue
+		}
+		path := filepath.Join(credentialsDir, file.Name)
+		if err := renameio.WriteFile(path, file.Data, file.Perm.Perm()); err != nil {
+			errs = append(errs, fmt.Errorf("failed to write credential file %s: %w", path, err))
+			continue
+		}
+		klog.Infof("Rewrote refreshed credential file %s", path)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,37 @@
+package csimounter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mountoptions"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestWriteCredentialFiles(t *testing.T) {
+	dir := t.TempDir()
+	credentialsDir := filepath.Join(dir, "credentials")
+	assert.NoError(t, os.Mkdir(credentialsDir, 0o770))
+	path := filepath.Join(credentialsDir, "vol-s3-csi-credentials")
+	assert.NoError(t, os.WriteFile(path, []byte("expired"), 0o640))
+
+	err := writeCredentialFiles(credentialsDir, mountoptions.CredentialUpdate{Files: []mountoptions.CredentialFile{
+		{Name: "vol-s3-csi-credentials", Data: []byte("refreshed"), Perm: 0o640},
+		// Files outside of the credentials directory are never written
+		{Name: "../mount.err", Data: []byte("invalid")},
+		{Name: "..", Data: []byte("invalid")},
+		{Name: "", Data: []byte("invalid")},
+	}})
+	assert.Equals(t, true, err != nil)
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equals(t, "refreshed", string(data))
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equals(t, os.FileMode(0o640), info.Mode().Perm())
+
+	_, err = os.Stat(filepath.Join(dir, "mount.err"))
+	assert.Equals(t, true, os.IsNotExist(err))
+}
@@ -1,15 +1,21 @@
 package csimounter
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
+	"time"
 
 	"k8s.io/klog/v2"
 
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint/runner"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mounterror"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mountmetrics"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mountoptions"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
 )
 
 var mountErrorFileperm = fs.FileMode(0o600) // only owner readable and writeable
@@ -18,13 +24,38 @@ var mountErrorFileperm = fs.FileMode(0o600) // only owner readable and writeable
 // so Kubernetes doesn't have to restart it and transition the Pod into `Succeeded` state.
 const successExitCode = 0
 
+// setupFailureExitCode is the exit code returned from `scality-s3-csi-mounter` when Mountpoint could not be started,
+// so the failure is reported in the status of the Mountpoint container.
+const setupFailureExitCode = 1
+
 // An Options represents options to use while mounting Mountpoint.
 type Options struct {
 	MountpointPath string
 	MountExitPath  string
 	MountErrPath   string
-	MountOptions   mountoptions.Options
-	CmdRunner      runner.CmdRunner
+	// TerminationLogPath is where Mountpoint's error output is also written on failures, to be reported as the
+	// termination message of the Mountpoint container. Not written if empty.
+	TerminationLogPath string
+	MountOptions       mountoptions.Options
+	CmdRunner          runner.CmdRunner
+	// ShutdownTimeout bounds the time Mountpoint has to flush pending uploads and exit once `mount.exit` is written.
+	// Zero means Mountpoint is waited for indefinitely.
+	ShutdownTimeout time.Duration
+	// ShutdownGracePeriod is how long Mountpoint has to exit after SIGTERM once `ShutdownTimeout` is exceeded,
+	// before getting killed.
+	ShutdownGracePeriod time.Duration
+	// Supervise restarts Mountpoint when it crashes if `Supervise.MaxRestarts` is set.
+	Supervise SuperviseOptions
+	// ControlSockPath is the control socket served while Mountpoint runs, on which refreshed credentials are
+	// received and rewritten in `CredentialsDir`. Not served if empty.
+	ControlSockPath string
+	CredentialsDir  string
+	// MetricsPath is where the metrics Mountpoint logs with `--log-metrics` are relayed to the CSI Driver Node Pod.
+	// Not written if empty.
+	MetricsPath string
+	// MountpointVersion is the release of the Mountpoint binary, mount options it does not support are removed or
+	// fail the mount, see [mountpoint.GateArgs]. Mount options are passed as is if nil.
+	MountpointVersion *mountpoint.Version
 }
 
 // Run runs Mountpoint with given options until completion and returns its exit code and its error (if any).
@@ -36,52 +67,155 @@ func Run(options Options) (int, error) {
 	mountOptions := options.MountOptions
 	mountpointArgs := mountpoint.ParseArgs(mountOptions.Args)
 
-	// TODO: This is a temporary workaround to create a cache folder if caching is enabled,
-	// ideally we should create a volume (`emptyDir` by default) in the Mountpoint Pod and use that.
+	// The cache volume of the Mountpoint Pod is used if it has one, a temporary directory is created otherwise.
 	mountpointArgs, err := createCacheDir(mountpointArgs)
 	if err != nil {
-		return 0, fmt.Errorf("failed to create cache dir: %w", err)
+		err = fmt.Errorf("failed to create cache dir: %w", err)
+		WriteSetupError(options.MountErrPath, options.TerminationLogPath, err)
+		return setupFailureExitCode, err
 	}
 
-	exitCode, stdErr, err := runner.RunInForeground(runner.ForegroundOptions{
-		BinaryPath: options.MountpointPath,
-		BucketName: mountOptions.BucketName,
-		Fd:         mountOptions.Fd,
-		Args:       mountpointArgs,
-		Env:        mountOptions.Env,
-		CmdRunner:  options.CmdRunner,
-	})
-	if err != nil {
-		// If Mountpoint fails, write it to `options.MountErrPath` to let `PodMounter` running in the same node know.
-		if writeErr := os.WriteFile(options.MountErrPath, stdErr, mountErrorFileperm); writeErr != nil {
-			klog.Errorf("failed to write mount error logs to %s: %v\n", options.MountErrPath, err)
+	if options.MountpointVersion != nil {
+		stripped, err := mountpoint.GateArgs(&mountpointArgs, *options.MountpointVersion)
+		if err != nil {
+			WriteSetupError(options.MountErrPath, options.TerminationLogPath, err)
+			return setupFailureExitCode, err
+		}
+		for _, key := range stripped {
+			since, _ := mountpoint.SupportedSince(key)
+			klog.Warningf("Mount option %s is ignored: it requires Mountpoint %s or later, running %s", key, since, options.MountpointVersion)
 		}
-		return exitCode, err
 	}
 
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+
+	watcher := &exitWatcher{}
+	watchCtx, stopWatching := context.WithCancel(ctx)
+	if options.MountExitPath != "" && options.ShutdownTimeout > 0 {
+		go watcher.watch(watchCtx, options.MountExitPath, options.ShutdownTimeout, stop)
+	}
+
+	if options.ControlSockPath != "" {
+		go serveControl(ctx, options.ControlSockPath, options.CredentialsDir)
+	}
+
+	progress := newProgressTracker()
+	var output io.Writer = progress
+	if options.MetricsPath != "" {
+		recorder := mountmetrics.NewRecorder()
+		output = io.MultiWriter(progress, recorder)
+		relayCtx, stopRelaying := context.WithCancel(ctx)
+		relayDone := make(chan struct{})
+		go func() {
+			relayMetrics(relayCtx, recorder, options.MetricsPath, metricsRelayInterval)
+			close(relayDone)
+		}()
+		// The last metrics of Mountpoint are relayed before exiting
+		defer func() {
+			stopRelaying()
+			<-relayDone
+		}()
+	}
+	fd := mountOptions.Fd
+	var exitCode int
+	var stdErr []byte
+	for restart := 1; ; restart++ {
+		startedAt := time.Now()
+		exitCode, stdErr, err = runner.RunInForeground(runner.ForegroundOptions{
+			BinaryPath:  options.MountpointPath,
+			BucketName:  mountOptions.BucketName,
+			Fd:          fd,
+			Args:        mountpoint.ParseArgs(mountpointArgs.SortedList()),
+			Env:         mountOptions.Env,
+			CmdRunner:   options.CmdRunner,
+			Context:     ctx,
+			GracePeriod: options.ShutdownGracePeriod,
+			Output:      output,
+		})
+		if err == nil || checkIfFileExists(options.MountExitPath) || !options.Supervise.shouldRestart(restart, time.Since(startedAt)) {
+			break
+		}
+
+		klog.Warningf("Mountpoint crashed with exit code %d, restarting it (restart %d/%d)", exitCode, restart, options.Supervise.MaxRestarts)
+		newFd, reconnectErr := options.Supervise.reconnect(ctx, restart, exitCode)
+		if reconnectErr != nil {
+			klog.Errorf("failed to restart Mountpoint: %v", reconnectErr)
+			break
+		}
+		fd = newFd
+	}
+	stopWatching()
+
 	if checkIfFileExists(options.MountExitPath) {
 		// If `mount.exit` is exists, that means the CSI Driver Node Pod unmounted the filesystem
-		// and we should cleanly exit regardless of Mountpoint's exit-code.
+		// and we should cleanly exit regardless of Mountpoint's exit-code, after reporting how the shutdown went.
+		requestedAt, forced := watcher.result()
+		report := ShutdownReport{
+			FlushCompleted:   err == nil && !forced && exitCode == successExitCode,
+			Forced:           forced,
+			ExitCode:         exitCode,
+			PendingUploads:   progress.value(metricWriteHandles),
+			InFlightRequests: progress.value(metricInFlightRequests),
+		}
+		if err != nil {
+			report.Error = err.Error()
+		}
+		if !requestedAt.IsZero() {
+			report.ShutdownDuration = time.Since(requestedAt).Round(time.Millisecond).String()
+		}
+		writeShutdownReport(options, report)
 		return successExitCode, nil
 	}
 
+	if err != nil {
+		// If Mountpoint fails, write it to `options.MountErrPath` to let `PodMounter` running in the same node know.
+		writeMountError(options.MountErrPath, options.TerminationLogPath, mounterror.New(mounterror.PhaseMount, exitCode, stdErr), stdErr)
+		return exitCode, err
+	}
+
 	return exitCode, nil
 }
 
+// WriteSetupError writes `err`, failing the mount before Mountpoint runs, to `mountErrPath` and to
+// `terminationLogPath`, to let the CSI Driver Node Pod and the controller report it as no Mountpoint process will.
+func WriteSetupError(mountErrPath, terminationLogPath string, err error) {
+	writeMountError(mountErrPath, terminationLogPath, mounterror.New(mounterror.PhaseSetup, 0, []byte(err.Error())), []byte(err.Error()))
+}
+
+// writeMountError writes `mountErr` to `mountErrPath` for the CSI Driver Node Pod, and `message` to
+// `terminationLogPath` if set, for the controller to report it in the MountpointS3PodAttachment status.
+func writeMountError(mountErrPath, terminationLogPath string, mountErr *mounterror.Error, message []byte) {
+	if writeErr := mountErr.Write(mountErrPath); writeErr != nil {
+		klog.Errorf("failed to write mount error to %s: %v\n", mountErrPath, writeErr)
+	}
+	if terminationLogPath == "" {
+		return
+	}
+	if writeErr := os.WriteFile(terminationLogPath, message, mountErrorFileperm); writeErr != nil {
+		klog.Errorf("failed to write mount error to %s: %v\n", terminationLogPath, writeErr)
+	}
+}
+
 // checkIfFileExists checks whether given `path` exists.
 func checkIfFileExists(path string) bool {
 	_, err := os.Stat(path)
 	return err == nil
 }
 
-// createCacheDir creates a temporary directory to use as a cache directory if caching is enabled in given `args`.
+// createCacheDir creates a temporary directory to use as a cache directory if caching is enabled in given `args`,
+// unless `--cache` points to the cache volume of the Mountpoint Pod, see [mppod.CacheDirPath].
 // It will replace the value of `--cache` with the created random directory.
 func createCacheDir(args mountpoint.Args) (mountpoint.Args, error) {
-	_, ok := args.Remove(mountpoint.ArgCache)
+	cacheDir, ok := args.Value(mountpoint.ArgCache)
 	if !ok {
 		// Caching is not enabled
 		return args, nil
 	}
+	if cacheDir == mppod.CacheDirPath {
+		return args, nil
+	}
+	args.Remove(mountpoint.ArgCache)
 
 	// Caching is enabled, so create a temporary directory and pass it to `args`
 	cacheDir, err := os.MkdirTemp(os.TempDir(), "mountpoint-s3-cache")
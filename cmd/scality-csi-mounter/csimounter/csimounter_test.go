@@ -1,18 +1,26 @@
 package csimounter_test
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp/cmpopts"
 
 	"github.com/scality/mountpoint-s3-csi-driver/cmd/scality-csi-mounter/csimounter"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/mounter/mountertest"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint/runner"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mounterror"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mountoptions"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
 )
 
@@ -115,6 +123,30 @@ func TestRunningMountpoint(t *testing.T) {
 		assert.Equals(t, 0, exitCode)
 	})
 
+	t.Run("Passes the cache volume of the Mountpoint Pod as is", func(t *testing.T) {
+		runner := func(c *exec.Cmd) (runner.ExitCode, error) {
+			assert.Equals(t, []string{
+				mountpointPath,
+				"test-bucket", "/dev/fd/3",
+				"--cache=" + mppod.CacheDirPath,
+				"--foreground",
+			}, c.Args)
+			return 0, nil
+		}
+
+		exitCode, err := csimounter.Run(csimounter.Options{
+			MountpointPath: mountpointPath,
+			MountOptions: mountoptions.Options{
+				Fd:         int(mountertest.OpenDevNull(t).Fd()),
+				BucketName: "test-bucket",
+				Args:       []string{"--cache=" + mppod.CacheDirPath},
+			},
+			CmdRunner: runner,
+		})
+		assert.NoError(t, err)
+		assert.Equals(t, 0, exitCode)
+	})
+
 	t.Run("Fails if file descriptor is invalid", func(t *testing.T) {
 		_, err := csimounter.Run(csimounter.Options{
 			MountpointPath: mountpointPath,
@@ -129,6 +161,7 @@ func TestRunningMountpoint(t *testing.T) {
 	t.Run("Writes `mount.err` file if Mountpoint fails", func(t *testing.T) {
 		basepath := t.TempDir()
 		mountErrPath := filepath.Join(basepath, "mount.err")
+		terminationLogPath := filepath.Join(basepath, "termination-log")
 		mountpointErr := errors.New("Mountpoint failed due to missing credentials")
 
 		dev := mountertest.OpenDevNull(t)
@@ -145,8 +178,9 @@ func TestRunningMountpoint(t *testing.T) {
 		}
 
 		exitCode, err := csimounter.Run(csimounter.Options{
-			MountpointPath: mountpointPath,
-			MountErrPath:   mountErrPath,
+			MountpointPath:     mountpointPath,
+			MountErrPath:       mountErrPath,
+			TerminationLogPath: terminationLogPath,
 			MountOptions: mountoptions.Options{
 				Fd:         int(dev.Fd()),
 				BucketName: "test-bucket",
@@ -158,7 +192,66 @@ func TestRunningMountpoint(t *testing.T) {
 
 		errMsg, err := os.ReadFile(mountErrPath)
 		assert.NoError(t, err)
-		assert.Equals(t, mountpointErr.Error(), string(errMsg))
+		assert.Equals(t, mounterror.Error{
+			Phase:          mounterror.PhaseMount,
+			ExitCode:       1,
+			Classification: mounterror.ClassificationUnknown,
+			Output:         mountpointErr.Error(),
+		}, *mounterror.Parse(errMsg))
+
+		// Also reported as the termination message of the Mountpoint container
+		terminationMsg, err := os.ReadFile(terminationLogPath)
+		assert.NoError(t, err)
+		assert.Equals(t, mountpointErr.Error(), string(terminationMsg))
+	})
+
+	t.Run("Removes or rejects mount options unsupported by Mountpoint", func(t *testing.T) {
+		cmdRunner := func(c *exec.Cmd) (runner.ExitCode, error) {
+			assert.Equals(t, []string{mountpointPath, "test-bucket", "/dev/fd/3", "--allow-delete", "--foreground"}, c.Args)
+			return 0, nil
+		}
+		exitCode, err := csimounter.Run(csimounter.Options{
+			MountpointPath: mountpointPath,
+			MountOptions: mountoptions.Options{
+				Fd:         int(mountertest.OpenDevNull(t).Fd()),
+				BucketName: "test-bucket",
+				Args:       []string{"--allow-delete", "--max-memory-target=1024"},
+			},
+			CmdRunner:         cmdRunner,
+			MountpointVersion: &mountpoint.Version{Major: 1, Minor: 9, Patch: 0},
+		})
+		assert.NoError(t, err)
+		assert.Equals(t, 0, exitCode)
+
+		basepath := t.TempDir()
+		mountErrPath := filepath.Join(basepath, "mount.err")
+		terminationLogPath := filepath.Join(basepath, "termination-log")
+		exitCode, err = csimounter.Run(csimounter.Options{
+			MountpointPath:     mountpointPath,
+			MountErrPath:       mountErrPath,
+			TerminationLogPath: terminationLogPath,
+			MountOptions: mountoptions.Options{
+				Fd:         int(mountertest.OpenDevNull(t).Fd()),
+				BucketName: "test-bucket",
+				Args:       []string{"--sse=AES256"},
+			},
+			CmdRunner: func(c *exec.Cmd) (runner.ExitCode, error) {
+				t.Fatal("Mountpoint must not run with unsupported mount options")
+				return 0, nil
+			},
+			MountpointVersion: &mountpoint.Version{Major: 1, Minor: 3, Patch: 0},
+		})
+		var unsupportedErr *mountpoint.UnsupportedArgsError
+		if !errors.As(err, &unsupportedErr) {
+			t.Fatalf("Expected an UnsupportedArgsError, got %v", err)
+		}
+		assert.Equals(t, 1, exitCode)
+		errMsg, err := os.ReadFile(mountErrPath)
+		assert.NoError(t, err)
+		assert.Equals(t, mounterror.ClassificationUnsupportedOption, mounterror.Parse(errMsg).Classification)
+		terminationMsg, err := os.ReadFile(terminationLogPath)
+		assert.NoError(t, err)
+		assert.Equals(t, unsupportedErr.Error(), string(terminationMsg))
 	})
 
 	t.Run("Exists with zero code if `mount.exit` file exist", func(t *testing.T) {
@@ -190,4 +283,180 @@ func TestRunningMountpoint(t *testing.T) {
 		// Should be `0` even though Mountpoint exited with a different exit code
 		assert.Equals(t, 0, exitCode)
 	})
+
+	t.Run("Reports upload progress to `mount.exit` on shutdown", func(t *testing.T) {
+		basepath := t.TempDir()
+		mountExitPath := filepath.Join(basepath, "mount.exit")
+		mountErrPath := filepath.Join(basepath, "mount.err")
+
+		runner := func(c *exec.Cmd) (runner.ExitCode, error) {
+			_, _ = c.Stderr.Write([]byte("INFO mountpoint_s3::metrics: fs.current_handles[type=write]: 2\n"))
+			_, _ = c.Stderr.Write([]byte("INFO mountpoint_s3::metrics: fs.current_handles[type=write]: 0\n"))
+			return 0, nil
+		}
+
+		_, err := os.OpenFile(mountExitPath, os.O_RDONLY|os.O_CREATE, 0o666)
+		assert.NoError(t, err)
+
+		exitCode, err := csimounter.Run(csimounter.Options{
+			MountpointPath: mountpointPath,
+			MountExitPath:  mountExitPath,
+			MountErrPath:   mountErrPath,
+			MountOptions: mountoptions.Options{
+				Fd:         int(mountertest.OpenDevNull(t).Fd()),
+				BucketName: "test-bucket",
+			},
+			CmdRunner:       runner,
+			ShutdownTimeout: time.Minute,
+		})
+		assert.NoError(t, err)
+		assert.Equals(t, 0, exitCode)
+
+		var report csimounter.ShutdownReport
+		data, err := os.ReadFile(mountExitPath)
+		assert.NoError(t, err)
+		assert.NoError(t, json.Unmarshal(data, &report))
+		assert.Equals(t, true, report.FlushCompleted)
+		assert.Equals(t, false, report.Forced)
+		assert.Equals(t, int64(0), report.PendingUploads)
+		assert.Equals(t, int64(-1), report.InFlightRequests)
+
+		_, err = os.Stat(mountErrPath)
+		assert.Equals(t, true, errors.Is(err, fs.ErrNotExist))
+	})
+
+	t.Run("Terminates Mountpoint if it does not exit within shutdown timeout", func(t *testing.T) {
+		basepath := t.TempDir()
+		mountExitPath := filepath.Join(basepath, "mount.exit")
+		mountErrPath := filepath.Join(basepath, "mount.err")
+
+		// A fake Mountpoint never exiting by itself
+		slowMountpointPath := filepath.Join(basepath, "mount-s3")
+		assert.NoError(t, os.WriteFile(slowMountpointPath, []byte("#!/bin/sh\nexec sleep 60\n"), 0o755))
+
+		_, err := os.OpenFile(mountExitPath, os.O_RDONLY|os.O_CREATE, 0o666)
+		assert.NoError(t, err)
+
+		exitCode, err := csimounter.Run(csimounter.Options{
+			MountpointPath: slowMountpointPath,
+			MountExitPath:  mountExitPath,
+			MountErrPath:   mountErrPath,
+			MountOptions: mountoptions.Options{
+				Fd:         int(mountertest.OpenDevNull(t).Fd()),
+				BucketName: "test-bucket",
+			},
+			ShutdownTimeout:     100 * time.Millisecond,
+			ShutdownGracePeriod: time.Second,
+		})
+		assert.NoError(t, err)
+		assert.Equals(t, 0, exitCode)
+
+		for _, path := range []string{mountExitPath, mountErrPath} {
+			var report csimounter.ShutdownReport
+			data, err := os.ReadFile(path)
+			assert.NoError(t, err)
+			assert.NoError(t, json.Unmarshal(data, &report))
+			assert.Equals(t, false, report.FlushCompleted)
+			assert.Equals(t, true, report.Forced)
+			assert.Equals(t, int64(-1), report.PendingUploads)
+		}
+	})
+	t.Run("Restarts Mountpoint with a new FUSE device if it crashes", func(t *testing.T) {
+		basepath := t.TempDir()
+		reconnectPath := filepath.Join(basepath, "mount.reconnect")
+		reconnectSockPath := filepath.Join(basepath, "mount.reconnect.sock")
+
+		newDev, err := os.CreateTemp(basepath, "fuse")
+		assert.NoError(t, err)
+		defer newDev.Close()
+
+		sent := make(chan error, 1)
+		go func() {
+			for !fileExists(reconnectPath) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			request, err := mountoptions.ReadReconnectRequest(reconnectPath)
+			if err != nil {
+				sent <- err
+				return
+			}
+			if request.Restart != 1 || request.ExitCode != 1 {
+				sent <- fmt.Errorf("unexpected reconnect request %+v", request)
+				return
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			sent <- mountoptions.Send(ctx, reconnectSockPath, mountoptions.Options{Fd: int(newDev.Fd())})
+		}()
+
+		runs := 0
+		runner := func(c *exec.Cmd) (runner.ExitCode, error) {
+			runs++
+			assert.Equals(t, []string{mountpointPath, "test-bucket", "/dev/fd/3", "--foreground", "--read-only"}, c.Args)
+			if runs == 1 {
+				return 1, errors.New("Mountpoint crashed")
+			}
+			mountertest.AssertSameFile(t, newDev, c.ExtraFiles[0])
+			return 0, nil
+		}
+
+		exitCode, err := csimounter.Run(csimounter.Options{
+			MountpointPath: mountpointPath,
+			MountOptions: mountoptions.Options{
+				Fd:         int(mountertest.OpenDevNull(t).Fd()),
+				BucketName: "test-bucket",
+				Args:       []string{"--read-only"},
+			},
+			CmdRunner: runner,
+			Supervise: csimounter.SuperviseOptions{
+				MaxRestarts:          1,
+				ReconnectRequestPath: reconnectPath,
+				ReconnectSockPath:    reconnectSockPath,
+				ReconnectTimeout:     5 * time.Second,
+			},
+		})
+		assert.NoError(t, err)
+		assert.Equals(t, 0, exitCode)
+		assert.Equals(t, 2, runs)
+		assert.NoError(t, <-sent)
+		assert.Equals(t, false, fileExists(reconnectPath))
+	})
+
+	t.Run("Does not restart Mountpoint crashing before minimum uptime", func(t *testing.T) {
+		basepath := t.TempDir()
+		reconnectPath := filepath.Join(basepath, "mount.reconnect")
+
+		mountpointErr := errors.New("Mountpoint failed due to missing credentials")
+		runs := 0
+		runner := func(c *exec.Cmd) (runner.ExitCode, error) {
+			runs++
+			return 1, mountpointErr
+		}
+
+		exitCode, err := csimounter.Run(csimounter.Options{
+			MountpointPath: mountpointPath,
+			MountErrPath:   filepath.Join(basepath, "mount.err"),
+			MountOptions: mountoptions.Options{
+				Fd:         int(mountertest.OpenDevNull(t).Fd()),
+				BucketName: "test-bucket",
+			},
+			CmdRunner: runner,
+			Supervise: csimounter.SuperviseOptions{
+				MaxRestarts:          3,
+				MinUptime:            time.Minute,
+				ReconnectRequestPath: reconnectPath,
+				ReconnectSockPath:    filepath.Join(basepath, "mount.reconnect.sock"),
+				ReconnectTimeout:     time.Second,
+			},
+		})
+		assert.Equals(t, mountpointErr, err)
+		assert.Equals(t, 1, exitCode)
+		assert.Equals(t, 1, runs)
+		assert.Equals(t, false, fileExists(reconnectPath))
+	})
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
 }
@@ -0,0 +1,42 @@
+package csimounter
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mountmetrics"
+)
+
+// metricsRelayInterval is how often the metrics recorded from Mountpoint's output are written to `mount.metrics`.
+// Mountpoint logs its metrics every 5 seconds with `--log-metrics`.
+const metricsRelayInterval = 10 * time.Second
+
+// relayMetrics writes the metrics recorded by `recorder` to `path` every `interval` until `ctx` is done, and once more
+// afterwards, for the CSI Driver Node Pod to export them. Nothing is written until Mountpoint logs a metric.
+func relayMetrics(ctx context.Context, recorder *mountmetrics.Recorder, path string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var written time.Time
+	for {
+		done := false
+		select {
+		case <-ctx.Done():
+			done = true
+		case <-ticker.C:
+		}
+
+		if snapshot := recorder.Snapshot(); snapshot.UpdatedAt.After(written) {
+			if err := snapshot.Write(path); err != nil {
+				klog.Errorf("failed to write Mountpoint metrics to %s: %v", path, err)
+			} else {
+				written = snapshot.UpdatedAt
+			}
+		}
+		if done {
+			return
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package csimounter
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mountmetrics"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestRelayMetrics(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mount.metrics")
+	recorder := mountmetrics.NewRecorder()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		relayMetrics(ctx, recorder, path, 10*time.Millisecond)
+		close(done)
+	}()
+
+	// Nothing is written before Mountpoint logs a metric
+	time.Sleep(30 * time.Millisecond)
+	if _, err := mountmetrics.Read(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Expected no metrics to be relayed, got %v", err)
+	}
+
+	_, err := recorder.Write([]byte("INFO mountpoint_s3::metrics: s3.requests[op=GetObject]: 3 (n=3)\n"))
+	assert.NoError(t, err)
+	cancel()
+	<-done
+
+	// The last metrics are relayed once Mountpoint exits
+	snapshot, err := mountmetrics.Read(path)
+	assert.NoError(t, err)
+	assert.Equals(t, []mountmetrics.Sample{{Name: "s3.requests", Labels: map[string]string{"op": "GetObject"}, Value: 3}}, snapshot.Counters)
+}
@@ -0,0 +1,36 @@
+package csimounter
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// PreStop blocks the termination of the Mountpoint container until the CSI Driver Node Pod writes `exitPath`,
+// which it does once no workload uses the mount anymore or once the drain timeout of the Mountpoint Pod expired,
+// so Mountpoint is not stopped under running workloads. It gives up after `timeout`, and returns whether the exit
+// was requested.
+//
+// Once the exit is requested, it keeps blocking for up to `shutdownTimeout`: the container stops as soon as
+// Mountpoint exits after flushing pending uploads, while returning earlier would terminate Mountpoint right away.
+func PreStop(exitPath string, timeout, shutdownTimeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	klog.Infof("Waiting up to %v for the CSI Driver Node Pod to unmount Mountpoint", timeout)
+	ticker := time.NewTicker(exitFilePollInterval)
+	defer ticker.Stop()
+
+	for !checkIfFileExists(exitPath) {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+
+	klog.Infof("Mountpoint unmounted, waiting up to %v for it to exit", shutdownTimeout)
+	time.Sleep(shutdownTimeout)
+	return true
+}
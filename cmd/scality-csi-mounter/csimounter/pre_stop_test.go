@@ -0,0 +1,29 @@
+package csimounter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestPreStop(t *testing.T) {
+	t.Run("Returns once the exit is requested", func(t *testing.T) {
+		exitPath := filepath.Join(t.TempDir(), "mount.exit")
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			_ = os.WriteFile(exitPath, nil, 0o600)
+		}()
+
+		start := time.Now()
+		assert.Equals(t, true, PreStop(exitPath, time.Minute, 50*time.Millisecond))
+		assert.Equals(t, true, time.Since(start) < 30*time.Second)
+	})
+
+	t.Run("Gives up after timeout", func(t *testing.T) {
+		exitPath := filepath.Join(t.TempDir(), "mount.exit")
+		assert.Equals(t, false, PreStop(exitPath, 100*time.Millisecond, time.Minute))
+	})
+}
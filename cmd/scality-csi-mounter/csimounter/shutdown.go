@@ -0,0 +1,200 @@
+package csimounter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// exitFilePollInterval is how often the `mount.exit` file is checked while Mountpoint is running.
+const exitFilePollInterval = time.Second
+
+// Metrics emitted by Mountpoint when running with `--log-metrics`, used to assess pending uploads at shutdown.
+const (
+	// metricWriteHandles is the number of open write file handles, each one being an upload not completed yet.
+	metricWriteHandles = "fs.current_handles[type=write]"
+	// metricInFlightRequests is the number of S3 requests being processed by the S3 client.
+	metricInFlightRequests = "s3.client.num_requests_being_processed"
+)
+
+// unknownProgress is reported for pending uploads or requests when Mountpoint did not log the corresponding metric.
+const unknownProgress = -1
+
+// A ShutdownReport describes how Mountpoint shut down after the CSI Driver Node Pod requested it to exit,
+// to assess potential data loss after forced unmounts.
+type ShutdownReport struct {
+	// FlushCompleted is true if Mountpoint exited cleanly on its own within the shutdown timeout.
+	FlushCompleted bool `json:"flushCompleted"`
+	// Forced is true if Mountpoint had to be terminated because it did not exit within the shutdown timeout.
+	Forced bool `json:"forced"`
+	// ExitCode of Mountpoint process.
+	ExitCode int `json:"exitCode"`
+	// Error returned while running Mountpoint, if any.
+	Error string `json:"error,omitempty"`
+	// ShutdownDuration is the time between the exit request and Mountpoint termination.
+	ShutdownDuration string `json:"shutdownDuration"`
+	// PendingUploads is the number of uploads (open write handles) last reported by Mountpoint, -1 if unknown.
+	PendingUploads int64 `json:"pendingUploads"`
+	// InFlightRequests is the number of S3 requests last reported in progress by Mountpoint, -1 if unknown.
+	InFlightRequests int64 `json:"inFlightRequests"`
+}
+
+// String returns a human-readable summary of the report.
+func (r ShutdownReport) String() string {
+	return fmt.Sprintf("flushCompleted=%t forced=%t exitCode=%d shutdownDuration=%s pendingUploads=%s inFlightRequests=%s",
+		r.FlushCompleted, r.Forced, r.ExitCode, r.ShutdownDuration, formatProgress(r.PendingUploads), formatProgress(r.InFlightRequests))
+}
+
+func formatProgress(value int64) string {
+	if value == unknownProgress {
+		return "unknown"
+	}
+	return strconv.FormatInt(value, 10)
+}
+
+// A progressTracker consumes Mountpoint's output and keeps the last reported values of upload-related metrics.
+type progressTracker struct {
+	mu      sync.Mutex
+	partial []byte
+	values  map[string]int64
+}
+
+func newProgressTracker() *progressTracker {
+	return &progressTracker{values: make(map[string]int64)}
+}
+
+// Write implements [io.Writer].
+func (t *progressTracker) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.partial = append(t.partial, p...)
+	for {
+		idx := bytes.IndexByte(t.partial, '\n')
+		if idx == -1 {
+			break
+		}
+		t.parseLine(string(t.partial[:idx]))
+		t.partial = t.partial[idx+1:]
+	}
+	return len(p), nil
+}
+
+// parseLine parses metric lines formatted as `... <name>: <value>`.
+func (t *progressTracker) parseLine(line string) {
+	for _, name := range []string{metricWriteHandles, metricInFlightRequests} {
+		idx := strings.LastIndex(line, name+": ")
+		if idx == -1 {
+			continue
+		}
+		fields := strings.Fields(line[idx+len(name)+2:])
+		if len(fields) == 0 {
+			continue
+		}
+		if value, err := strconv.ParseInt(fields[0], 10, 64); err == nil {
+			t.values[name] = value
+		}
+	}
+}
+
+func (t *progressTracker) value(name string) int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if value, ok := t.values[name]; ok {
+		return value
+	}
+	return unknownProgress
+}
+
+// An exitWatcher waits for the `mount.exit` file and bounds the time Mountpoint has to shut down afterwards.
+type exitWatcher struct {
+	mu          sync.Mutex
+	requestedAt time.Time
+	forced      bool
+}
+
+// watch polls `exitPath` until `ctx` is done. Once the file appears, it calls `stop` if Mountpoint is still
+// running after `timeout`.
+func (w *exitWatcher) watch(ctx context.Context, exitPath string, timeout time.Duration, stop context.CancelFunc) {
+	ticker := time.NewTicker(exitFilePollInterval)
+	defer ticker.Stop()
+
+	for !checkIfFileExists(exitPath) {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+
+	w.mu.Lock()
+	w.requestedAt = time.Now()
+	w.mu.Unlock()
+	klog.Infof("Exit requested, waiting up to %v for Mountpoint to flush pending uploads and exit", timeout)
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(timeout):
+		w.mu.Lock()
+		w.forced = true
+		w.mu.Unlock()
+		klog.Warningf("Mountpoint did not exit within %v after exit request, terminating it", timeout)
+		stop()
+	}
+}
+
+// result returns when the exit was requested (zero if not seen yet) and whether Mountpoint was forcibly terminated.
+func (w *exitWatcher) result() (time.Time, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.requestedAt, w.forced
+}
+
+// writeShutdownReport logs `report` and appends it to `mount.exit`, and to `mount.err` if the flush did not complete.
+func writeShutdownReport(options Options, report ShutdownReport) {
+	if report.FlushCompleted {
+		klog.Infof("Mountpoint shut down: %s", report)
+	} else {
+		klog.Warningf("Mountpoint shut down without completing flush, data written since last upload might be lost: %s", report)
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		klog.Errorf("failed to encode shutdown report: %v", err)
+		return
+	}
+	data = append(data, '\n')
+
+	paths := []string{options.MountExitPath}
+	if !report.FlushCompleted {
+		paths = append(paths, options.MountErrPath)
+	}
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		if err := appendFile(path, data); err != nil {
+			klog.Errorf("failed to write shutdown report to %s: %v", path, err)
+		}
+	}
+}
+
+func appendFile(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, mountErrorFileperm)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,43 @@
+package csimounter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestProgressTracker(t *testing.T) {
+	tracker := newProgressTracker()
+	assert.Equals(t, int64(unknownProgress), tracker.value(metricWriteHandles))
+
+	output := "2025-01-01T00:00:00Z  INFO mountpoint_s3::metrics: fs.current_handles[type=write]: 3\n" +
+		"2025-01-01T00:00:00Z  INFO mountpoint_s3::metrics: fs.current_handles[type=read]: 7\n" +
+		"2025-01-01T00:00:00Z  INFO mountpoint_s3::metrics: s3.client.num_requests_being_processed: 12\n" +
+		"2025-01-01T00:00:05Z  INFO mountpoint_s3::metrics: fs.current_handles[type=write]: 1\n"
+
+	// Feed output in small chunks to exercise partial line handling
+	for chunk := range strings.SplitSeq(output, "s3") {
+		_, err := tracker.Write([]byte(chunk))
+		assert.NoError(t, err)
+		if !strings.HasSuffix(output, chunk) {
+			_, err = tracker.Write([]byte("s3"))
+			assert.NoError(t, err)
+		}
+	}
+
+	assert.Equals(t, int64(1), tracker.value(metricWriteHandles))
+	assert.Equals(t, int64(12), tracker.value(metricInFlightRequests))
+}
+
+func TestShutdownReportString(t *testing.T) {
+	report := ShutdownReport{
+		FlushCompleted:   false,
+		Forced:           true,
+		ExitCode:         0,
+		ShutdownDuration: "2m0s",
+		PendingUploads:   2,
+		InFlightRequests: unknownProgress,
+	}
+	assert.Equals(t, "flushCompleted=false forced=true exitCode=0 shutdownDuration=2m0s pendingUploads=2 inFlightRequests=unknown", report.String())
+}
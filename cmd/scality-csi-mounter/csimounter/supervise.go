@@ -0,0 +1,71 @@
+package csimounter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mountoptions"
+)
+
+// A SuperviseOptions configures restarts of Mountpoint by [Run] after it crashes.
+//
+// Once Mountpoint crashes, its FUSE mount is disconnected and cannot be served again. [Run] requests a new FUSE file
+// descriptor from the CSI Driver Node Pod by writing a [mountoptions.ReconnectRequest] to `ReconnectRequestPath`, and
+// receives it on `ReconnectSockPath`. Mountpoint is then restarted with the new file descriptor and its original mount
+// options, while the CSI Driver Node Pod bind-mounts the new mount to the targets of the volume again.
+type SuperviseOptions struct {
+	// MaxRestarts is the number of times Mountpoint is restarted after crashing. Zero disables restarts.
+	MaxRestarts int
+	// MinUptime is how long Mountpoint must have run for its exit to be a crash. Mountpoint exiting earlier
+	// failed to mount, e.g. due to invalid credentials, and is not restarted.
+	MinUptime time.Duration
+	// Backoff is the wait before the first restart, doubled for each following restart.
+	Backoff              time.Duration
+	ReconnectRequestPath string
+	ReconnectSockPath    string
+	// ReconnectTimeout bounds the wait for a new FUSE file descriptor.
+	ReconnectTimeout time.Duration
+}
+
+// maxRestartBackoff bounds the wait between restarts of Mountpoint.
+const maxRestartBackoff = time.Minute
+
+// shouldRestart returns whether Mountpoint, having run for `uptime`, must be restarted for the `restart`-th time.
+func (o SuperviseOptions) shouldRestart(restart int, uptime time.Duration) bool {
+	return restart <= o.MaxRestarts && uptime >= o.MinUptime
+}
+
+// reconnect requests a new FUSE file descriptor for the `restart`-th restart of Mountpoint after it exited with
+// `exitCode`, and returns it.
+func (o SuperviseOptions) reconnect(ctx context.Context, restart, exitCode int) (int, error) {
+	backoff := o.Backoff << (restart - 1)
+	if backoff > maxRestartBackoff || backoff < 0 {
+		backoff = maxRestartBackoff
+	}
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case <-time.After(backoff):
+	}
+
+	request := mountoptions.ReconnectRequest{Restart: restart, ExitCode: exitCode, RequestedAt: time.Now()}
+	if err := request.Write(o.ReconnectRequestPath); err != nil {
+		return 0, fmt.Errorf("failed to write reconnect request to %s: %w", o.ReconnectRequestPath, err)
+	}
+	defer func() {
+		_ = os.Remove(o.ReconnectRequestPath)
+	}()
+
+	recvCtx, cancel := context.WithTimeout(ctx, o.ReconnectTimeout)
+	defer cancel()
+	klog.Infof("Waiting for a new FUSE file descriptor on %s", o.ReconnectSockPath)
+	options, err := mountoptions.Recv(recvCtx, o.ReconnectSockPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to receive a new FUSE file descriptor from %s: %w", o.ReconnectSockPath, err)
+	}
+	return options.Fd, nil
+}
@@ -11,40 +11,113 @@ import (
 	"flag"
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	"k8s.io/klog/v2"
 
 	"github.com/scality/mountpoint-s3-csi-driver/cmd/scality-csi-mounter/csimounter"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mounthealth"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mountoptions"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
 )
 
 var (
 	mountSockRecvTimeout = flag.Duration("mount-sock-recv-timeout", 2*time.Minute, "Timeout for receiving mount options from passed Unix socket.")
-	mountpointBinDir     = flag.String("mountpoint-bin-dir", os.Getenv("MOUNTPOINT_BIN_DIR"), "Directory of mount-s3 binary.")
+	mountpointBinDir     = flag.String("mountpoint-bin-dir", os.Getenv("MOUNTPOINT_BIN_DIR"), "Directory of mount-s3 binary, or of one directory per platform (e.g. linux-arm64) containing it.")
+	binaryDigests        = flag.String("mountpoint-binary-digests", os.Getenv(mppod.EnvBinaryDigests), "Expected SHA-256 digests of mount-s3, as a single digest or comma-separated <platform>=<digest> pairs. Empty disables verification.")
+	shutdownTimeout      = flag.Duration("shutdown-timeout", envDuration(mppod.EnvShutdownTimeout), "Time given to mount-s3 to flush pending uploads and exit after an unmount is requested, zero to wait indefinitely.")
+	shutdownGracePeriod  = flag.Duration("shutdown-grace-period", 10*time.Second, "Time given to mount-s3 to exit after SIGTERM once shutdown timeout is exceeded, before it gets killed.")
+	maxRestarts          = flag.Int("max-restarts", envInt(mppod.EnvMaxRestarts), "Number of times mount-s3 is restarted with a new FUSE device from the node plugin after crashing, zero disables restarts.")
+	restartMinUptime     = flag.Duration("restart-min-uptime", 10*time.Second, "How long mount-s3 must have run for its exit to be a crash it is restarted after, earlier exits are mount failures.")
+	restartBackoff       = flag.Duration("restart-backoff", time.Second, "Wait before the first restart of mount-s3, doubled for each following restart.")
+	reconnectTimeout     = flag.Duration("reconnect-timeout", 2*time.Minute, "Timeout for receiving a new FUSE device from the node plugin after a crash of mount-s3.")
+	checkHealth          = flag.Bool("check-health", false, "Exit with a non-zero exit code if the mount is reported unhealthy by the CSI Driver Node Pod, and zero otherwise. Used as liveness probe of the Mountpoint container.")
+	preStop              = flag.Bool("pre-stop", false, "Wait until the CSI Driver Node Pod unmounts Mountpoint after its workloads released the mount, and exit. Used as preStop hook of the Mountpoint container.")
+	preStopTimeout       = flag.Duration("pre-stop-timeout", 2*time.Minute, "Maximum time the preStop hook waits for the CSI Driver Node Pod to unmount Mountpoint.")
 )
 
 var (
-	mountSockPath  = mppod.PathInsideMountpointPod(mppod.KnownPathMountSock)
-	mountExitPath  = mppod.PathInsideMountpointPod(mppod.KnownPathMountExit)
-	mountErrorPath = mppod.PathInsideMountpointPod(mppod.KnownPathMountError)
+	mountSockPath   = mppod.PathInsideMountpointPod(mppod.KnownPathMountSock)
+	mountExitPath   = mppod.PathInsideMountpointPod(mppod.KnownPathMountExit)
+	mountErrorPath  = mppod.PathInsideMountpointPod(mppod.KnownPathMountError)
+	mountHealthPath = mppod.PathInsideMountpointPod(mppod.KnownPathMountHealth)
+
+	mountReconnectPath     = mppod.PathInsideMountpointPod(mppod.KnownPathMountReconnect)
+	mountReconnectSockPath = mppod.PathInsideMountpointPod(mppod.KnownPathMountReconnectSock)
+	mountControlSockPath   = mppod.PathInsideMountpointPod(mppod.KnownPathMountControlSock)
+	credentialsDir         = mppod.PathInsideMountpointPod(mppod.KnownPathCredentials)
+	mountMetricsPath       = mppod.PathInsideMountpointPod(mppod.KnownPathMountMetrics)
 )
 
-const mountpointBin = "mount-s3"
+const terminationLogPath = "/dev/termination-log"
 
 func main() {
 	klog.InitFlags(nil)
 	flag.Parse()
 
-	mountpointBinFullPath := filepath.Join(*mountpointBinDir, mountpointBin)
+	if *checkHealth {
+		if err := mounthealth.Check(mountHealthPath); err != nil {
+			klog.Fatalf("%v\n", err)
+		}
+		os.Exit(0)
+	}
+
+	if *preStop {
+		shutdownWait := *shutdownTimeout + *shutdownGracePeriod
+		if *shutdownTimeout <= 0 {
+			// Mountpoint is waited for until the end of the termination grace period of the Mountpoint Pod
+			shutdownWait = mppod.ShutdownPeriod
+		}
+		if !csimounter.PreStop(mountExitPath, *preStopTimeout, shutdownWait) {
+			klog.Warningf("Mountpoint was not unmounted within %v, terminating it\n", *preStopTimeout)
+		}
+		os.Exit(0)
+	}
+
+	// The mount reported unhealthy, if any, was the one of the previous container
+	if err := mounthealth.Reset(mountHealthPath); err != nil {
+		klog.Errorf("failed to reset mount health at %s: %v\n", mountHealthPath, err)
+	}
+
 	mountOptions := recvMountOptions()
+	mountpointBinFullPath := resolveMountpointBin()
+	var mountpointVersion *mountpoint.Version
+	if version, err := mountpoint.DetectVersion(context.Background(), mountpointBinFullPath); err != nil {
+		klog.Warningf("Failed to detect the version of Mountpoint, mount options are passed without checking they are supported: %v", err)
+	} else {
+		klog.Infof("Running Mountpoint %s", version)
+		mountpointVersion = &version
+	}
+
+	restarts := *maxRestarts
+	if restarts > 0 && !mountOptions.Handshake.Supports(mountoptions.CapabilityReconnect) {
+		// The CSI Driver Node Pod would never answer reconnect requests
+		klog.Warningf("CSI Driver Node Pod does not support reconnecting Mountpoint, it will not be restarted if it crashes")
+		restarts = 0
+	}
 
 	exitCode, err := csimounter.Run(csimounter.Options{
-		MountpointPath: mountpointBinFullPath,
-		MountExitPath:  mountExitPath,
-		MountErrPath:   mountErrorPath,
-		MountOptions:   mountOptions,
+		MountpointPath:      mountpointBinFullPath,
+		MountExitPath:       mountExitPath,
+		MountErrPath:        mountErrorPath,
+		TerminationLogPath:  terminationLogPath,
+		MountOptions:        mountOptions,
+		ShutdownTimeout:     *shutdownTimeout,
+		ShutdownGracePeriod: *shutdownGracePeriod,
+		Supervise: csimounter.SuperviseOptions{
+			MaxRestarts:          restarts,
+			MinUptime:            *restartMinUptime,
+			Backoff:              *restartBackoff,
+			ReconnectRequestPath: mountReconnectPath,
+			ReconnectSockPath:    mountReconnectSockPath,
+			ReconnectTimeout:     *reconnectTimeout,
+		},
+		ControlSockPath:   mountControlSockPath,
+		CredentialsDir:    credentialsDir,
+		MetricsPath:       mountMetricsPath,
+		MountpointVersion: mountpointVersion,
 	})
 	if err != nil {
 		klog.Fatalf("failed to run Mountpoint: %v\n", err)
@@ -61,6 +134,54 @@ func recvMountOptions() mountoptions.Options {
 	if err != nil {
 		klog.Fatalf("failed to receive mount options from %s: %v\n", mountSockPath, err)
 	}
-	klog.Infof("Mount options has been received from %s", mountSockPath)
+	klog.Infof("Mount options has been received from %s, CSI Driver Node Pod speaks protocol %s", mountSockPath, options.Handshake)
 	return options
 }
+
+// resolveMountpointBin returns the path of the Mountpoint binary for the platform of the node, after verifying its
+// digest if expected digests are set.
+func resolveMountpointBin() string {
+	platform := mountpoint.Platform()
+	binDir, err := mountpoint.ResolveBinDir(*mountpointBinDir, platform)
+	if err != nil {
+		failMount("failed to find Mountpoint binary", err)
+	}
+	binPath := filepath.Join(binDir, mountpoint.BinaryName)
+
+	if *binaryDigests == "" {
+		return binPath
+	}
+	digests, err := mountpoint.ParseBinaryDigests(*binaryDigests)
+	if err == nil {
+		err = mountpoint.VerifyBinaryDigest(binPath, platform, digests)
+	}
+	if err != nil {
+		failMount("refusing to run Mountpoint", err)
+	}
+	klog.Infof("Verified digest of Mountpoint binary %s", binPath)
+	return binPath
+}
+
+// failMount writes `err` to `mount.err` and to the termination log with [csimounter.WriteSetupError], and exits.
+func failMount(msg string, err error) {
+	csimounter.WriteSetupError(mountErrorPath, terminationLogPath, err)
+	klog.Fatalf("%s: %v\n", msg, err)
+}
+
+// envInt returns the integer value of the environment variable `name`, zero if it is not set or invalid.
+func envInt(name string) int {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+// envDuration returns the duration value of the environment variable `name`, zero if it is not set or invalid.
+func envDuration(name string) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(name))
+	if err != nil {
+		return 0
+	}
+	return value
+}
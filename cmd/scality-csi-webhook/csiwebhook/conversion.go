@@ -0,0 +1,39 @@
+package csiwebhook
+
+import (
+	"fmt"
+	"net/http"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
+
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	crdv3 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v3"
+)
+
+// ConversionPath is the path the conversion webhook of the CustomResourceDefinitions of the driver is served on.
+const ConversionPath = "/convert"
+
+// AddConvertibleToScheme adds all versions of the custom resources of the driver to `scheme`.
+func AddConvertibleToScheme(scheme *runtime.Scheme) error {
+	if err := crdv2.AddToScheme(scheme); err != nil {
+		return err
+	}
+	return crdv3.AddToScheme(scheme)
+}
+
+// NewConversionHandler returns a handler converting custom resources of the driver between their versions through
+// their hub version. It fails if a custom resource in `scheme` has several versions but no hub, or a version that
+// cannot convert from and to the hub, so a missing conversion is caught at startup instead of on upgrades.
+func NewConversionHandler(scheme *runtime.Scheme) (http.Handler, error) {
+	for _, obj := range []runtime.Object{&crdv2.MountpointS3PodAttachment{}} {
+		convertible, err := conversion.IsConvertible(scheme, obj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check conversion of %T: %w", obj, err)
+		}
+		if !convertible {
+			return nil, fmt.Errorf("%T has no hub version to convert through", obj)
+		}
+	}
+	return conversion.NewWebhookHandler(scheme), nil
+}
@@ -0,0 +1,87 @@
+package csiwebhook_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/scality/mountpoint-s3-csi-driver/cmd/scality-csi-webhook/csiwebhook"
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	crdv3 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v3"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func convert(t *testing.T, handler http.Handler, desiredAPIVersion string, obj runtime.Object) *apiextensionsv1.ConversionReview {
+	t.Helper()
+	raw, err := json.Marshal(obj)
+	assert.NoError(t, err)
+	body, err := json.Marshal(&apiextensionsv1.ConversionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apiextensions.k8s.io/v1", Kind: "ConversionReview"},
+		Request: &apiextensionsv1.ConversionRequest{
+			UID:               "test-uid",
+			DesiredAPIVersion: desiredAPIVersion,
+			Objects:           []runtime.RawExtension{{Raw: raw}},
+		},
+	})
+	assert.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, csiwebhook.ConversionPath, bytes.NewReader(body)))
+	assert.Equals(t, http.StatusOK, recorder.Code)
+
+	review := &apiextensionsv1.ConversionReview{}
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), review))
+	assert.Equals(t, metav1.StatusSuccess, review.Response.Result.Status)
+	return review
+}
+
+func TestConversionHandler(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, csiwebhook.AddConvertibleToScheme(scheme))
+	handler, err := csiwebhook.NewConversionHandler(scheme)
+	assert.NoError(t, err)
+
+	s3pa := &crdv2.MountpointS3PodAttachment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: crdv2.GroupVersion.String(), Kind: "MountpointS3PodAttachment"},
+		ObjectMeta: metav1.ObjectMeta{Name: "s3pa-test"},
+		Spec: crdv2.MountpointS3PodAttachmentSpec{
+			NodeName:             "test-node",
+			PersistentVolumeName: "test-pv",
+			VolumeID:             "test-volume",
+			MountOptions:         "allow-delete",
+			WorkloadFSGroup:      "1000",
+			MountpointS3PodAttachments: map[string][]crdv2.WorkloadAttachment{
+				"mp-1": {{WorkloadPodUID: "workload-1"}},
+			},
+		},
+	}
+
+	review := convert(t, handler, crdv3.GroupVersion.String(), s3pa)
+	assert.Equals(t, 1, len(review.Response.ConvertedObjects))
+	converted := &crdv3.MountpointS3PodAttachment{}
+	assert.NoError(t, json.Unmarshal(review.Response.ConvertedObjects[0].Raw, converted))
+	assert.Equals(t, crdv3.GroupVersion.String(), converted.APIVersion)
+	assert.Equals(t, crdv3.SharingKey{MountOptions: "allow-delete", WorkloadFSGroup: "1000"}, converted.Spec.SharingKey)
+
+	review = convert(t, handler, crdv2.GroupVersion.String(), converted)
+	back := &crdv2.MountpointS3PodAttachment{}
+	assert.NoError(t, json.Unmarshal(review.Response.ConvertedObjects[0].Raw, back))
+	assert.Equals(t, s3pa.Spec, back.Spec)
+}
+
+func TestConversionHandlerRequiresHub(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, crdv2.AddToScheme(scheme))
+
+	// Only the hub version is registered, nothing converts through it
+	_, err := csiwebhook.NewConversionHandler(scheme)
+	if err == nil {
+		t.Fatal("Expected an error without convertible versions")
+	}
+}
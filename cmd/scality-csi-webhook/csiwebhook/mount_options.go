@@ -0,0 +1,133 @@
+// Package csiwebhook implements the validating admission webhook of the CSI Driver, rejecting PersistentVolumes
+// and StorageClasses of the driver whose mount options would fail the mount, instead of only discovering them once
+// workloads fail to start.
+package csiwebhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util"
+)
+
+// Name is the name of the webhook component.
+const Name = "scality-csi-webhook"
+
+// MountOptionsPath is the path the [MountOptionsValidator] is served on.
+const MountOptionsPath = "/validate-mount-options"
+
+// A Mode is how the [MountOptionsValidator] handles invalid mount options.
+type Mode string
+
+const (
+	// ModeEnforce rejects objects with invalid mount options.
+	ModeEnforce Mode = "Enforce"
+	// ModeWarn admits objects with invalid mount options, and returns the problems as warnings to the client.
+	ModeWarn Mode = "Warn"
+)
+
+// ParseMode parses a [Mode].
+func ParseMode(mode string) (Mode, error) {
+	switch Mode(mode) {
+	case ModeEnforce, ModeWarn:
+		return Mode(mode), nil
+	default:
+		return "", fmt.Errorf("invalid validation mode %q, only %q and %q are supported", mode, ModeEnforce, ModeWarn)
+	}
+}
+
+// A MountOptionsValidator validates mount options of PersistentVolumes and StorageClasses of the driver against
+// the arguments Mountpoint and the driver support. Arguments the driver ignores are always returned as warnings.
+type MountOptionsValidator struct {
+	decoder             admission.Decoder
+	mode                Mode
+	allowedEndpointURLs []string
+}
+
+// NewMountOptionsValidator returns a new [MountOptionsValidator]. `allowedEndpointURLs` are the endpoint URLs the
+// node plugin allows volumes to use.
+func NewMountOptionsValidator(decoder admission.Decoder, mode Mode, allowedEndpointURLs []string) *MountOptionsValidator {
+	return &MountOptionsValidator{decoder: decoder, mode: mode, allowedEndpointURLs: allowedEndpointURLs}
+}
+
+// Handle implements [admission.Handler].
+func (v *MountOptionsValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	mountOptions, oldMountOptions, ok, err := v.mountOptions(req)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if !ok {
+		return admission.Allowed("")
+	}
+	// Only validate changed mount options, so objects created before the webhook can still be updated, e.g. to
+	// remove finalizers
+	if req.Operation == admissionv1.Update && slices.Equal(mountOptions, oldMountOptions) {
+		return admission.Allowed("")
+	}
+
+	warnings, err := mountpoint.ValidateMountOptions(mountOptions, v.allowedEndpointURLs)
+	if length := len(strings.Join(mountOptions, ",")); length > crdv2.MaxMountOptionsLength {
+		err = errors.Join(err, fmt.Errorf("mount options are too long: %d bytes, maximum is %d bytes", length, crdv2.MaxMountOptionsLength))
+	}
+	if err == nil {
+		return admission.Allowed("").WithWarnings(warnings...)
+	}
+
+	message := fmt.Sprintf("invalid mount options of %s %s: %s", req.Kind.Kind, req.Name, strings.ReplaceAll(err.Error(), "\n", "; "))
+	logf.FromContext(ctx).Info("Invalid mount options", "kind", req.Kind.Kind, "name", req.Name, "mode", v.mode, "error", err)
+	if v.mode == ModeWarn {
+		return admission.Allowed("").WithWarnings(append(warnings, message)...)
+	}
+	return admission.Denied(message).WithWarnings(warnings...)
+}
+
+// mountOptions returns the mount options of the object of `req` and of its old version on updates, and whether the
+// object is a PersistentVolume or a StorageClass of the driver.
+func (v *MountOptionsValidator) mountOptions(req admission.Request) ([]string, []string, bool, error) {
+	switch req.Kind.Kind {
+	case "PersistentVolume":
+		pv, oldPV := &corev1.PersistentVolume{}, &corev1.PersistentVolume{}
+		if err := v.decode(req, pv, oldPV); err != nil {
+			return nil, nil, false, err
+		}
+		if pv.Spec.CSI == nil || pv.Spec.CSI.Driver != util.DriverName() {
+			return nil, nil, false, nil
+		}
+		return pv.Spec.MountOptions, oldPV.Spec.MountOptions, true, nil
+	case "StorageClass":
+		sc, oldSC := &storagev1.StorageClass{}, &storagev1.StorageClass{}
+		if err := v.decode(req, sc, oldSC); err != nil {
+			return nil, nil, false, err
+		}
+		if sc.Provisioner != util.DriverName() {
+			return nil, nil, false, nil
+		}
+		return sc.MountOptions, oldSC.MountOptions, true, nil
+	default:
+		return nil, nil, false, nil
+	}
+}
+
+// decode decodes the object of `req` into `obj`, and its old version into `oldObj` on updates.
+func (v *MountOptionsValidator) decode(req admission.Request, obj, oldObj runtime.Object) error {
+	if err := v.decoder.Decode(req, obj); err != nil {
+		return err
+	}
+	if req.Operation == admissionv1.Update {
+		return v.decoder.DecodeRaw(req.OldObject, oldObj)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package csiwebhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/scality/mountpoint-s3-csi-driver/cmd/scality-csi-webhook/csiwebhook"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func testPV(driver string, mountOptions ...string) *corev1.PersistentVolume {
+	return &corev1.PersistentVolume{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "PersistentVolume"},
+		ObjectMeta: metav1.ObjectMeta{Name: "s3-pv"},
+		Spec: corev1.PersistentVolumeSpec{
+			MountOptions: mountOptions,
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: driver, VolumeHandle: "s3-csi-driver-volume"},
+			},
+		},
+	}
+}
+
+func testStorageClass(mountOptions ...string) *storagev1.StorageClass {
+	return &storagev1.StorageClass{
+		TypeMeta:     metav1.TypeMeta{APIVersion: "storage.k8s.io/v1", Kind: "StorageClass"},
+		ObjectMeta:   metav1.ObjectMeta{Name: "s3-sc"},
+		Provisioner:  constants.DriverName,
+		MountOptions: mountOptions,
+	}
+}
+
+func request(t *testing.T, operation admissionv1.Operation, obj, oldObj runtime.Object) admission.Request {
+	t.Helper()
+	raw := func(obj runtime.Object) runtime.RawExtension {
+		if obj == nil {
+			return runtime.RawExtension{}
+		}
+		data, err := json.Marshal(obj)
+		assert.NoError(t, err)
+		return runtime.RawExtension{Raw: data}
+	}
+	kind := obj.GetObjectKind().GroupVersionKind()
+	return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Operation: operation,
+		Kind:      metav1.GroupVersionKind{Group: kind.Group, Version: kind.Version, Kind: kind.Kind},
+		Name:      "test",
+		Object:    raw(obj),
+		OldObject: raw(oldObj),
+	}}
+}
+
+func TestMountOptionsValidator(t *testing.T) {
+	ctx := context.Background()
+	decoder := admission.NewDecoder(clientgoscheme.Scheme)
+	enforce := csiwebhook.NewMountOptionsValidator(decoder, csiwebhook.ModeEnforce, nil)
+	warn := csiwebhook.NewMountOptionsValidator(decoder, csiwebhook.ModeWarn, nil)
+
+	t.Run("valid mount options are allowed", func(t *testing.T) {
+		resp := enforce.Handle(ctx, request(t, admissionv1.Create, testPV(constants.DriverName, "allow-delete", "uid=1000"), nil))
+		assert.Equals(t, true, resp.Allowed)
+		assert.Equals(t, 0, len(resp.Warnings))
+	})
+
+	t.Run("invalid mount options are denied", func(t *testing.T) {
+		resp := enforce.Handle(ctx, request(t, admissionv1.Create, testPV(constants.DriverName, "allow-delte"), nil))
+		assert.Equals(t, false, resp.Allowed)
+		if !strings.Contains(resp.Result.Message, "unknown mount option --allow-delte") {
+			t.Errorf("Expected the unknown mount option in the message, got %q", resp.Result.Message)
+		}
+
+		resp = enforce.Handle(ctx, request(t, admissionv1.Create, testStorageClass("file-mode=964"), nil))
+		assert.Equals(t, false, resp.Allowed)
+	})
+
+	t.Run("invalid mount options are warned about in warn mode", func(t *testing.T) {
+		resp := warn.Handle(ctx, request(t, admissionv1.Create, testStorageClass("allow-delte"), nil))
+		assert.Equals(t, true, resp.Allowed)
+		assert.Equals(t, 1, len(resp.Warnings))
+	})
+
+	t.Run("ignored mount options are warned about", func(t *testing.T) {
+		resp := enforce.Handle(ctx, request(t, admissionv1.Create, testPV(constants.DriverName, "storage-class=GLACIER"), nil))
+		assert.Equals(t, true, resp.Allowed)
+		assert.Equals(t, []string{"--storage-class is ignored: only STANDARD is supported by the CSI driver"}, resp.Warnings)
+	})
+
+	t.Run("volumes of other drivers are ignored", func(t *testing.T) {
+		resp := enforce.Handle(ctx, request(t, admissionv1.Create, testPV("ebs.csi.aws.com", "allow-delte"), nil))
+		assert.Equals(t, true, resp.Allowed)
+	})
+
+	t.Run("updates without mount option changes are allowed", func(t *testing.T) {
+		pv := testPV(constants.DriverName, "allow-delte")
+		updated := pv.DeepCopy()
+		updated.Finalizers = nil
+		resp := enforce.Handle(ctx, request(t, admissionv1.Update, updated, pv))
+		assert.Equals(t, true, resp.Allowed)
+
+		updated.Spec.MountOptions = []string{"allow-delte", "debug"}
+		resp = enforce.Handle(ctx, request(t, admissionv1.Update, updated, pv))
+		assert.Equals(t, false, resp.Allowed)
+	})
+}
+
+func TestParseMode(t *testing.T) {
+	mode, err := csiwebhook.ParseMode("Warn")
+	assert.NoError(t, err)
+	assert.Equals(t, csiwebhook.ModeWarn, mode)
+	if _, err := csiwebhook.ParseMode("Audit"); err == nil {
+		t.Errorf("Expected unknown modes to be rejected")
+	}
+}
@@ -0,0 +1,93 @@
+// `scality-csi-webhook` is the entrypoint binary for the CSI Driver's validating admission webhook.
+// It checks mount options of PersistentVolumes and StorageClasses of the driver at creation time, so typos and
+// unsupported arguments are reported to the user instead of failing workload Pods later. It also serves the
+// conversion webhook of the custom resources of the driver.
+package main
+
+import (
+	"flag"
+	"os"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/scality/mountpoint-s3-csi-driver/cmd/scality-csi-webhook/csiwebhook"
+)
+
+var (
+	port                = flag.Int("port", 9443, "Port to serve the webhook on.")
+	certDir             = flag.String("cert-dir", "/etc/webhook/certs", "Directory containing the tls.crt and tls.key serving certificate of the webhook.")
+	healthProbeAddr     = flag.String("health-probe-bind-address", ":8081", "Address to serve health probes on.")
+	validationMode      = flag.String("validation-mode", string(csiwebhook.ModeEnforce), "How invalid mount options are handled: Enforce rejects them, Warn only returns warnings.")
+	allowedEndpointURLs = flag.String("allowed-endpoint-urls", os.Getenv("ALLOWED_ENDPOINT_URLS"), "Comma-separated S3 endpoint URLs volumes can use with the endpoint-url mount option.")
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(csiwebhook.AddConvertibleToScheme(scheme))
+}
+
+func main() {
+	flag.Parse()
+
+	logf.SetLogger(zap.New())
+
+	log := logf.Log.WithName(csiwebhook.Name)
+
+	mode, err := csiwebhook.ParseMode(*validationMode)
+	if err != nil {
+		log.Error(err, "invalid validation mode")
+		os.Exit(1)
+	}
+
+	mgr, err := manager.New(config.GetConfigOrDie(), manager.Options{
+		Scheme:                 scheme,
+		Metrics:                metricsserver.Options{BindAddress: "0"},
+		HealthProbeBindAddress: *healthProbeAddr,
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Port:    *port,
+			CertDir: *certDir,
+		}),
+	})
+	if err != nil {
+		log.Error(err, "failed to create a new manager")
+		os.Exit(1)
+	}
+
+	var endpointURLs []string
+	if *allowedEndpointURLs != "" {
+		endpointURLs = strings.Split(*allowedEndpointURLs, ",")
+	}
+	validator := csiwebhook.NewMountOptionsValidator(admission.NewDecoder(scheme), mode, endpointURLs)
+	mgr.GetWebhookServer().Register(csiwebhook.MountOptionsPath, &webhook.Admission{Handler: validator})
+
+	converter, err := csiwebhook.NewConversionHandler(scheme)
+	if err != nil {
+		log.Error(err, "failed to create conversion handler")
+		os.Exit(1)
+	}
+	mgr.GetWebhookServer().Register(csiwebhook.ConversionPath, converter)
+
+	if err := mgr.AddReadyzCheck("webhook", mgr.GetWebhookServer().StartedChecker()); err != nil {
+		log.Error(err, "failed to add readiness check")
+		os.Exit(1)
+	}
+
+	log.Info("Serving mount options validation", "mode", mode, "port", *port)
+	if err := mgr.Start(signals.SetupSignalHandler()); err != nil {
+		log.Error(err, "failed to start manager")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,140 @@
+//go:build mage
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/magefile/mage/sh"
+)
+
+// =============================================================================
+// Benchmark Constants
+// =============================================================================
+
+const (
+	benchNamespace  = "default"
+	benchPodName    = "bench-workload"
+	benchBinaryPath = "bin/bench"
+)
+
+const benchStorageClassYAML = `apiVersion: storage.k8s.io/v1
+kind: StorageClass
+metadata:
+  name: bench-sc
+provisioner: s3.csi.scality.com
+reclaimPolicy: Delete
+volumeBindingMode: Immediate
+mountOptions:
+  - allow-delete`
+
+const benchPVCYAML = `apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: bench-pvc
+  namespace: default
+spec:
+  accessModes: [ReadWriteMany]
+  storageClassName: bench-sc
+  resources:
+    requests:
+      storage: 10Gi`
+
+const benchPodYAML = `apiVersion: v1
+kind: Pod
+metadata:
+  name: bench-workload
+  namespace: default
+  labels:
+    app: bench
+spec:
+  containers:
+    - name: bench
+      image: busybox:latest
+      command: ["sleep", "86400"]
+      volumeMounts:
+        - name: s3-volume
+          mountPath: /data
+  volumes:
+    - name: s3-volume
+      persistentVolumeClaim:
+        claimName: bench-pvc`
+
+// GetBenchResultsPath returns where the JSON results of `mage e2e:bench` are written.
+func GetBenchResultsPath() string {
+	if path := os.Getenv("BENCH_RESULTS_PATH"); path != "" {
+		return path
+	}
+	return "bench-results.json"
+}
+
+// =============================================================================
+// Public Mage Targets (Entry Points)
+// =============================================================================
+
+// Bench runs the data path benchmark suite against a volume of the installed CSI driver, and writes its JSON
+// results to BENCH_RESULTS_PATH (default: bench-results.json). Extra flags of the benchmark, e.g.
+// `--file-size=104857600`, are read from BENCH_ARGS.
+func (E2E) Bench() error {
+	if err := verifyCSIInstallation(); err != nil {
+		return fmt.Errorf("verification failed, cannot run benchmarks: %v", err)
+	}
+	defer cleanupBench()
+
+	fmt.Println("Building benchmark binary...")
+	env := map[string]string{"CGO_ENABLED": "0", "GOOS": "linux"}
+	if err := sh.RunWith(env, "go", "build", "-o", benchBinaryPath, "./tests/bench/cmd/bench"); err != nil {
+		return fmt.Errorf("failed to build benchmark binary: %v", err)
+	}
+
+	fmt.Println("Applying benchmark manifests (StorageClass, PVC, Pod)...")
+	for _, manifest := range []string{benchStorageClassYAML, benchPVCYAML, benchPodYAML} {
+		if err := pipeToKubectlApply(manifest); err != nil {
+			return fmt.Errorf("failed to apply benchmark manifests: %v", err)
+		}
+	}
+	if err := waitForPodRunning(benchPodName, benchNamespace, 5*time.Minute); err != nil {
+		return err
+	}
+
+	if err := sh.RunV("kubectl", "cp", benchBinaryPath, fmt.Sprintf("%s/%s:/tmp/bench", benchNamespace, benchPodName)); err != nil {
+		return fmt.Errorf("failed to copy benchmark binary: %v", err)
+	}
+
+	args := []string{"exec", "-n", benchNamespace, benchPodName, "--", "/tmp/bench", "--dir=/data",
+		"--label=csiImageRepository=" + GetCSIImageRepository(), "--label=csiImageTag=" + GetCSIImageTag()}
+	args = append(args, strings.Fields(os.Getenv("BENCH_ARGS"))...)
+	fmt.Println("Running benchmarks...")
+	results, err := sh.Output("kubectl", args...)
+	if err != nil {
+		return fmt.Errorf("benchmark failed: %v\n%s", err, results)
+	}
+
+	path := GetBenchResultsPath()
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create results directory: %v", err)
+		}
+	}
+	if err := os.WriteFile(path, []byte(results+"\n"), 0o644); err != nil {
+		return fmt.Errorf("failed to write benchmark results: %v", err)
+	}
+	fmt.Printf("Benchmark results written to %s\n", path)
+	return nil
+}
+
+// =============================================================================
+// Private Helpers
+// =============================================================================
+
+// cleanupBench deletes the benchmark Pod, PVC and StorageClass.
+func cleanupBench() {
+	fmt.Println("Cleaning up benchmark resources...")
+	_ = sh.Run("kubectl", "delete", "pod", benchPodName, "-n", benchNamespace, "--ignore-not-found=true")
+	_ = sh.Run("kubectl", "delete", "pvc", "bench-pvc", "-n", benchNamespace, "--ignore-not-found=true")
+	_ = sh.Run("kubectl", "delete", "storageclass", "bench-sc", "--ignore-not-found=true")
+}
@@ -0,0 +1,36 @@
+//go:build mage
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// GetChaosTimeout returns the Ginkgo timeout of `mage e2e:chaos`, from CHAOS_TIMEOUT (default: 30m).
+func GetChaosTimeout() string {
+	if timeout := os.Getenv("CHAOS_TIMEOUT"); timeout != "" {
+		return timeout
+	}
+	return "30m"
+}
+
+// =============================================================================
+// Public Mage Targets (Entry Points)
+// =============================================================================
+
+// Chaos runs the chaos test suite against the installed CSI driver: Mountpoint Pods are randomly deleted from the
+// mount-s3 namespace while workloads read and write a volume, and the suite asserts that I/O fails fast or recovers
+// without corrupting completed writes. Flags of the suite, e.g. `--chaos-duration=10m --chaos-kill-interval=15s
+// --chaos-seed=42`, are read from CHAOS_ARGS.
+func (E2E) Chaos() error {
+	if err := verifyCSIInstallation(); err != nil {
+		return fmt.Errorf("verification failed, cannot run chaos tests: %v", err)
+	}
+	args := append([]string{"--chaos"}, strings.Fields(os.Getenv("CHAOS_ARGS"))...)
+	if err := runGinkgoTests("", "", 1, GetChaosTimeout(), args...); err != nil {
+		return fmt.Errorf("chaos tests failed: %w", err)
+	}
+	return nil
+}
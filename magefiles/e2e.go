@@ -1178,7 +1178,8 @@ func (E2E) UninstallForce() error {
 
 // runGinkgoTests invokes Ginkgo to run E2E tests.
 // procs and timeout control parallelism and test timeout. Pass 0/"" to use defaults (8 procs, 15m).
-func runGinkgoTests(s3EndpointURL, junitReportPath string, procs int, timeout string) error {
+// testArgs are passed through to the test binary after the S3 endpoint, e.g. to select a test mode.
+func runGinkgoTests(s3EndpointURL, junitReportPath string, procs int, timeout string, testArgs ...string) error {
 	if s3EndpointURL == "" {
 		s3EndpointURL = os.Getenv("S3_ENDPOINT_URL")
 	}
@@ -1235,6 +1236,7 @@ func runGinkgoTests(s3EndpointURL, junitReportPath string, procs int, timeout st
 
 	// Add test packages and passthrough args
 	args = append(args, "./...", "--", fmt.Sprintf("--s3-endpoint-url=%s", s3EndpointURL))
+	args = append(args, testArgs...)
 
 	// Resolve KUBECONFIG
 	kubeconfig := os.Getenv("KUBECONFIG")
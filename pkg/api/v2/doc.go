@@ -0,0 +1,5 @@
+// Package v2 contains API Schema definitions for the s3.csi.scality.com v2 API group.
+// +kubebuilder:object:generate=true
+// +groupName=s3.csi.scality.com
+// +groupGoName=S3
+package v2
@@ -0,0 +1,47 @@
+package v2
+
+import (
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
+)
+
+// LabelDriverName is set on MountpointS3PodAttachments created by a driver instance other than the default one, with
+// the name of that instance. Several instances installed in a cluster share the CRDs, each only handles its own
+// attachments, see [DriverNameSelector].
+const LabelDriverName = constants.DriverName + "/driver-name"
+
+// DriverNameLabels returns the labels to set on MountpointS3PodAttachments created by the driver instance
+// `driverName`, nil for the default instance.
+func DriverNameLabels(driverName string) map[string]string {
+	if driverName == constants.DriverName {
+		return nil
+	}
+	return map[string]string{LabelDriverName: driverName}
+}
+
+// DriverNameSelector returns the selector of the MountpointS3PodAttachments of the driver instance `driverName`.
+// Attachments of the default instance have no [LabelDriverName], so the attachments created before other instances
+// were installed are still handled by the default instance.
+func DriverNameSelector(driverName string) labels.Selector {
+	operator, values := selection.Equals, []string{driverName}
+	if driverName == constants.DriverName {
+		operator, values = selection.DoesNotExist, nil
+	}
+	requirement, err := labels.NewRequirement(LabelDriverName, operator, values)
+	if err != nil {
+		// `driverName` is not a valid label value, no attachment can match
+		return labels.Nothing()
+	}
+	return labels.NewSelector().Add(*requirement)
+}
+
+// InstanceObjectName returns the name of the cluster-scoped object `name` maintained by the driver instance
+// `driverName`, like [S3VolumeInventoryName]: `name` for the default instance and `name-<driverName>` otherwise.
+func InstanceObjectName(name, driverName string) string {
+	if driverName == constants.DriverName {
+		return name
+	}
+	return name + "-" + driverName
+}
@@ -0,0 +1,28 @@
+package v2_test
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/labels"
+
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestDriverNameSelector(t *testing.T) {
+	const lab = "lab.s3.csi.scality.com"
+	defaultAttachment := labels.Set(crdv2.DriverNameLabels(constants.DriverName))
+	labAttachment := labels.Set(crdv2.DriverNameLabels(lab))
+
+	assert.Equals(t, true, crdv2.DriverNameSelector(constants.DriverName).Matches(defaultAttachment))
+	assert.Equals(t, false, crdv2.DriverNameSelector(constants.DriverName).Matches(labAttachment))
+	assert.Equals(t, true, crdv2.DriverNameSelector(lab).Matches(labAttachment))
+	assert.Equals(t, false, crdv2.DriverNameSelector(lab).Matches(defaultAttachment))
+	assert.Equals(t, false, crdv2.DriverNameSelector("not a label value!").Matches(labels.Set{}))
+}
+
+func TestInstanceObjectName(t *testing.T) {
+	assert.Equals(t, "cluster", crdv2.InstanceObjectName(crdv2.S3VolumeInventoryName, constants.DriverName))
+	assert.Equals(t, "cluster-lab.s3.csi.scality.com", crdv2.InstanceObjectName(crdv2.S3VolumeInventoryName, "lab.s3.csi.scality.com"))
+}
@@ -1,6 +1,3 @@
-// Package v2 contains API Schema definitions for the s3.csi.scality.com v2 API group.
-// +kubebuilder:object:generate=true
-// +groupName=s3.csi.scality.com
 package v2
 
 import (
@@ -14,9 +11,17 @@ var (
 	// GroupVersion is group version used to register these objects.
 	GroupVersion = schema.GroupVersion{Group: constants.DriverName, Version: "v2"}
 
+	// SchemeGroupVersion is an alias of [GroupVersion] for the generated clients in pkg/client.
+	SchemeGroupVersion = GroupVersion
+
 	// SchemeBuilder is used to add go types to the GroupVersionKind scheme.
 	SchemeBuilder = &scheme.Builder{GroupVersion: GroupVersion}
 
 	// AddToScheme adds the types in this group-version to the given scheme.
 	AddToScheme = SchemeBuilder.AddToScheme
 )
+
+// Resource takes an unqualified resource and returns a group qualified GroupResource.
+func Resource(resource string) schema.GroupResource {
+	return GroupVersion.WithResource(resource).GroupResource()
+}
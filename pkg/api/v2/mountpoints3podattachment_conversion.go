@@ -0,0 +1,5 @@
+package v2
+
+// Hub marks v2 as the hub of conversions of MountpointS3PodAttachments: every other version converts from and to v2,
+// which is also the version stored by the API server.
+func (*MountpointS3PodAttachment) Hub() {}
@@ -17,6 +17,7 @@ func getIndexFields() map[string]func(*MountpointS3PodAttachment) string {
 		FieldVolumeID:             func(cr *MountpointS3PodAttachment) string { return cr.Spec.VolumeID },
 		FieldMountOptions:         func(cr *MountpointS3PodAttachment) string { return cr.Spec.MountOptions },
 		FieldWorkloadFSGroup:      func(cr *MountpointS3PodAttachment) string { return cr.Spec.WorkloadFSGroup },
+		FieldCredentialIdentity:   func(cr *MountpointS3PodAttachment) string { return cr.Spec.CredentialIdentity },
 	}
 }
 
@@ -15,8 +15,18 @@ const (
 	FieldVolumeID             = "spec.volumeID"
 	FieldMountOptions         = "spec.mountOptions"
 	FieldWorkloadFSGroup      = "spec.workloadFSGroup"
+	FieldCredentialIdentity   = "spec.credentialIdentity"
 )
 
+// FinalizerMountpointPodsCleanup is added by the controller to MountpointS3PodAttachments, so they are only removed
+// once the Mountpoint Pods they served are gone, and so their mounts and credentials cleaned up by the node plugin.
+const FinalizerMountpointPodsCleanup = "s3.csi.scality.com/mountpoint-pods-cleanup"
+
+// MaxMountOptionsLength is the maximum length of comma separated mount options of a volume.
+// Mount options are used as a selectable field and are passed to Mountpoint over a Unix socket,
+// so they are bounded to keep both well within their size limits.
+const MaxMountOptionsLength = 16384
+
 // MountpointS3PodAttachmentSpec defines the desired state of MountpointS3PodAttachment.
 type MountpointS3PodAttachmentSpec struct {
 	// Important: Run "make generate" to regenerate code after modifying this file
@@ -31,11 +41,17 @@ type MountpointS3PodAttachmentSpec struct {
 	VolumeID string `json:"volumeID"`
 
 	// Comma separated mount options taken from volume.
+	// +kubebuilder:validation:MaxLength=16384
 	MountOptions string `json:"mountOptions"`
 
 	// Workload pod's `fsGroup` from pod security context
 	WorkloadFSGroup string `json:"workloadFSGroup"`
 
+	// Identity Mountpoint accesses the bucket with for the workloads, e.g. `webIdentity:<namespace>/<service account>`,
+	// `role:<role ARN>` or `secret:<namespace>/<name>`. Empty for credentials of the node, e.g. the driver credentials.
+	// +optional
+	CredentialIdentity string `json:"credentialIdentity,omitempty"`
+
 	// Maps each Mountpoint S3 pod name to its workload attachments
 	MountpointS3PodAttachments map[string][]WorkloadAttachment `json:"mountpointS3PodAttachments"`
 }
@@ -49,7 +65,47 @@ type WorkloadAttachment struct {
 	AttachmentTime metav1.Time `json:"attachmentTime"`
 }
 
+// Condition types of MountpointS3PodAttachments.
+const (
+	// ConditionMountpointPodsUpToDate is true if all Mountpoint Pods of the attachment run the current version of
+	// Mountpoint and the CSI Driver. Outdated Mountpoint Pods are drained: no new workloads are assigned to them,
+	// and they are unmounted once their workloads terminate.
+	ConditionMountpointPodsUpToDate = "MountpointPodsUpToDate"
+	// ConditionMountpointPodScheduled is true if all Mountpoint Pods of the attachment are scheduled to their node.
+	ConditionMountpointPodScheduled = "MountpointPodScheduled"
+	// ConditionMountpointReady is true if Mountpoint runs in all Mountpoint Pods of the attachment.
+	ConditionMountpointReady = "MountpointReady"
+	// ConditionMountError is true if Mountpoint failed in a Mountpoint Pod of the attachment, its message contains
+	// the error output of Mountpoint.
+	ConditionMountError = "MountError"
+	// ConditionHeadroomReserved is true while Headroom Pods reserve capacity for the Mountpoint Pods of the
+	// attachment, its reason tells whether they were consumed or expired once released. It is only set for
+	// workloads requesting headroom.
+	ConditionHeadroomReserved = "HeadroomReserved"
+	// ConditionMountOptionsUpToDate is true if the workloads of the attachment are mounted with the current mount
+	// options of their Persistent Volume. Workloads of volumes opted in rolling remounts are evicted one at a time
+	// while it is false.
+	ConditionMountOptionsUpToDate = "MountOptionsUpToDate"
+)
+
+// MountpointS3PodAttachmentStatus defines the observed state of MountpointS3PodAttachment.
+type MountpointS3PodAttachmentStatus struct {
+	// Conditions of the attachment.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// MountGeneration of the volume on the node, reported by the node plugin when a Mountpoint Pod of the attachment
+	// mounts it. It is incremented every time the volume is mounted on the node with other options or credentials.
+	// +optional
+	MountGeneration int64 `json:"mountGeneration,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
 // +kubebuilder:object:root=true
+// +kubebuilder:storageversion
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Cluster,shortName=s3pa
 // +kubebuilder:selectablefield:JSONPath=`.spec.nodeName`
@@ -57,9 +113,11 @@ type WorkloadAttachment struct {
 // +kubebuilder:selectablefield:JSONPath=`.spec.volumeID`
 // +kubebuilder:selectablefield:JSONPath=`.spec.mountOptions`
 // +kubebuilder:selectablefield:JSONPath=`.spec.workloadFSGroup`
+// +kubebuilder:selectablefield:JSONPath=`.spec.credentialIdentity`
 // +kubebuilder:printcolumn:name="Node",type=string,JSONPath=`.spec.nodeName`,description="The node where the volume is mounted"
 // +kubebuilder:printcolumn:name="PV Name",type=string,JSONPath=`.spec.persistentVolumeName`,description="The persistent volume name"
 // +kubebuilder:printcolumn:name="Mount Options",type=string,JSONPath=`.spec.mountOptions`,description="Comma separated mount options"
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="MountpointReady")].status`,description="Whether Mountpoint runs in all Mountpoint Pods"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // MountpointS3PodAttachment is the Schema for the mountpoints3podattachments API.
@@ -67,7 +125,8 @@ type MountpointS3PodAttachment struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec MountpointS3PodAttachmentSpec `json:"spec,omitempty"`
+	Spec   MountpointS3PodAttachmentSpec   `json:"spec,omitempty"`
+	Status MountpointS3PodAttachmentStatus `json:"status,omitempty"`
 }
 
 // +kubebuilder:object:root=true
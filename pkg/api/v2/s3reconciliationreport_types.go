@@ -0,0 +1,118 @@
+package v2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// S3ReconciliationReportName is the name of the only S3ReconciliationReport, maintained by the controller. Other
+// driver instances installed in the cluster maintain their own, see [InstanceObjectName].
+const S3ReconciliationReportName = "cluster"
+
+// MaxReportedDivergences is the maximum number of divergences listed in an S3ReconciliationReport, all of them are
+// counted.
+const MaxReportedDivergences = 100
+
+// Types of divergences between Mountpoint Pods, MountpointS3PodAttachments and mounts reported by node plugins.
+const (
+	// DivergenceOrphanMountpointPod is a Mountpoint Pod no MountpointS3PodAttachment refers to.
+	DivergenceOrphanMountpointPod = "OrphanMountpointPod"
+	// DivergenceDanglingAttachment is a MountpointS3PodAttachment referring to a Mountpoint Pod that does not exist.
+	DivergenceDanglingAttachment = "DanglingAttachment"
+	// DivergenceUnmountedSource is a running Mountpoint Pod with workloads whose node plugin does not report its
+	// source as mounted.
+	DivergenceUnmountedSource = "UnmountedSource"
+	// DivergenceLeakedMount is a source reported as mounted by a node plugin for a Mountpoint Pod that does not exist.
+	DivergenceLeakedMount = "LeakedMount"
+	// DivergenceTargetCountMismatch is a Mountpoint Pod whose node plugin reports a number of targets different
+	// from its number of workloads.
+	DivergenceTargetCountMismatch = "TargetCountMismatch"
+)
+
+// A Divergence is an inconsistency between Mountpoint Pods, MountpointS3PodAttachments and mounts reported by node
+// plugins.
+type Divergence struct {
+	// Type of the divergence, e.g. `OrphanMountpointPod`.
+	Type string `json:"type"`
+
+	// Node the divergence was found on.
+	// +optional
+	NodeName string `json:"nodeName,omitempty"`
+
+	// Mountpoint Pod the divergence is about.
+	// +optional
+	MountpointPod string `json:"mountpointPod,omitempty"`
+
+	// MountpointS3PodAttachment the divergence is about.
+	// +optional
+	MountpointS3PodAttachment string `json:"mountpointS3PodAttachment,omitempty"`
+
+	// Human-readable description of the divergence.
+	Message string `json:"message"`
+
+	// Repaired is true if the controller repaired the divergence.
+	// +optional
+	Repaired bool `json:"repaired,omitempty"`
+}
+
+// S3ReconciliationReportStatus compares Mountpoint Pods, MountpointS3PodAttachments and mounts reported by node
+// plugins.
+type S3ReconciliationReportStatus struct {
+	// Number of Mountpoint Pods.
+	MountpointPods int32 `json:"mountpointPods"`
+
+	// Number of workloads attached to Mountpoint Pods by MountpointS3PodAttachments.
+	WorkloadAttachments int32 `json:"workloadAttachments"`
+
+	// Number of nodes with a current mount report.
+	NodesReporting int32 `json:"nodesReporting"`
+
+	// Number of sources reported as mounted by node plugins.
+	ReportedSourceMounts int32 `json:"reportedSourceMounts"`
+
+	// Number of targets reported as mounted by node plugins.
+	ReportedTargets int32 `json:"reportedTargets"`
+
+	// Number of divergences found by the last check.
+	DivergenceCount int32 `json:"divergenceCount"`
+
+	// Divergences found by the last check, at most 100.
+	// +optional
+	Divergences []Divergence `json:"divergences,omitempty"`
+
+	// Last time the check ran.
+	// +optional
+	LastCheckTime metav1.Time `json:"lastCheckTime,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,shortName=s3recon
+// +kubebuilder:printcolumn:name="Divergences",type=integer,JSONPath=`.status.divergenceCount`,description="Number of divergences found by the last check"
+// +kubebuilder:printcolumn:name="Mountpoint Pods",type=integer,JSONPath=`.status.mountpointPods`,description="Number of Mountpoint Pods"
+// +kubebuilder:printcolumn:name="Attachments",type=integer,JSONPath=`.status.workloadAttachments`,description="Number of workloads attached to Mountpoint Pods"
+// +kubebuilder:printcolumn:name="Nodes Reporting",type=integer,JSONPath=`.status.nodesReporting`,description="Number of nodes with a current mount report"
+// +kubebuilder:printcolumn:name="Checked",type="date",JSONPath=".status.lastCheckTime"
+
+// S3ReconciliationReport lists divergences between Mountpoint Pods, MountpointS3PodAttachments and mounts reported
+// by node plugins, maintained by the controller as a singleton named [S3ReconciliationReportName].
+type S3ReconciliationReport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status S3ReconciliationReportStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// S3ReconciliationReportList contains a list of S3ReconciliationReport.
+type S3ReconciliationReportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []S3ReconciliationReport `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&S3ReconciliationReport{}, &S3ReconciliationReportList{})
+}
@@ -0,0 +1,88 @@
+package v2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// S3VolumeInventoryName is the name of the only S3VolumeInventory, maintained by the controller. Other driver
+// instances installed in the cluster maintain their own, see [InstanceObjectName].
+const S3VolumeInventoryName = "cluster"
+
+// InventoryKeyNone is the key volumes without a StorageClass or a claim are counted under.
+const InventoryKeyNone = "<none>"
+
+// Health states of volumes counted by S3VolumeInventories.
+const (
+	// VolumeHealthHealthy volumes are mounted, and Mountpoint runs in all their Mountpoint Pods.
+	VolumeHealthHealthy = "Healthy"
+	// VolumeHealthDegraded volumes are mounted, but Mountpoint failed or does not run yet in one of their Mountpoint Pods.
+	VolumeHealthDegraded = "Degraded"
+	// VolumeHealthUnmounted volumes are not mounted by any workload.
+	VolumeHealthUnmounted = "Unmounted"
+)
+
+// S3VolumeInventoryStatus summarizes the S3 volumes of the cluster.
+type S3VolumeInventoryStatus struct {
+	// Number of Persistent Volumes of the driver.
+	Volumes int32 `json:"volumes"`
+
+	// Number of Persistent Volumes per StorageClass.
+	// +optional
+	ByStorageClass map[string]int32 `json:"byStorageClass,omitempty"`
+
+	// Number of Persistent Volumes per namespace of their claim.
+	// +optional
+	ByNamespace map[string]int32 `json:"byNamespace,omitempty"`
+
+	// Number of Persistent Volumes per health state: `Healthy`, `Degraded` or `Unmounted`.
+	// +optional
+	ByHealth map[string]int32 `json:"byHealth,omitempty"`
+
+	// Number of Mountpoint Pods.
+	MountpointPods int32 `json:"mountpointPods"`
+
+	// Number of Mountpoint Pods per version of the CSI Driver that created them.
+	// +optional
+	CSIDriverVersions map[string]int32 `json:"csiDriverVersions,omitempty"`
+
+	// Number of Mountpoint Pods per version of Mountpoint.
+	// +optional
+	MountpointVersions map[string]int32 `json:"mountpointVersions,omitempty"`
+
+	// Last time the inventory was updated.
+	// +optional
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,shortName=s3inv
+// +kubebuilder:printcolumn:name="Volumes",type=integer,JSONPath=`.status.volumes`,description="Number of Persistent Volumes of the driver"
+// +kubebuilder:printcolumn:name="Healthy",type=integer,JSONPath=`.status.byHealth.Healthy`,description="Number of mounted volumes Mountpoint runs for"
+// +kubebuilder:printcolumn:name="Degraded",type=integer,JSONPath=`.status.byHealth.Degraded`,description="Number of mounted volumes Mountpoint failed or does not run yet for"
+// +kubebuilder:printcolumn:name="Mountpoint Pods",type=integer,JSONPath=`.status.mountpointPods`,description="Number of Mountpoint Pods"
+// +kubebuilder:printcolumn:name="Updated",type="date",JSONPath=".status.lastUpdateTime"
+
+// S3VolumeInventory is a fleet view of the S3 volumes of the cluster, maintained by the controller as a singleton
+// named [S3VolumeInventoryName].
+type S3VolumeInventory struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status S3VolumeInventoryStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// S3VolumeInventoryList contains a list of S3VolumeInventory.
+type S3VolumeInventoryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []S3VolumeInventory `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&S3VolumeInventory{}, &S3VolumeInventoryList{})
+}
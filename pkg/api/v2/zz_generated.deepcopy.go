@@ -5,15 +5,32 @@
 package v2
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Divergence) DeepCopyInto(out *Divergence) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Divergence.
+func (in *Divergence) DeepCopy() *Divergence {
+	if in == nil {
+		return nil
+	}
+	out := new(Divergence)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MountpointS3PodAttachment) DeepCopyInto(out *MountpointS3PodAttachment) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MountpointS3PodAttachment.
@@ -99,6 +116,216 @@ func (in *MountpointS3PodAttachmentSpec) DeepCopy() *MountpointS3PodAttachmentSp
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MountpointS3PodAttachmentStatus) DeepCopyInto(out *MountpointS3PodAttachmentStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MountpointS3PodAttachmentStatus.
+func (in *MountpointS3PodAttachmentStatus) DeepCopy() *MountpointS3PodAttachmentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MountpointS3PodAttachmentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3ReconciliationReport) DeepCopyInto(out *S3ReconciliationReport) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3ReconciliationReport.
+func (in *S3ReconciliationReport) DeepCopy() *S3ReconciliationReport {
+	if in == nil {
+		return nil
+	}
+	out := new(S3ReconciliationReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *S3ReconciliationReport) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3ReconciliationReportList) DeepCopyInto(out *S3ReconciliationReportList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]S3ReconciliationReport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3ReconciliationReportList.
+func (in *S3ReconciliationReportList) DeepCopy() *S3ReconciliationReportList {
+	if in == nil {
+		return nil
+	}
+	out := new(S3ReconciliationReportList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *S3ReconciliationReportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3ReconciliationReportStatus) DeepCopyInto(out *S3ReconciliationReportStatus) {
+	*out = *in
+	if in.Divergences != nil {
+		in, out := &in.Divergences, &out.Divergences
+		*out = make([]Divergence, len(*in))
+		copy(*out, *in)
+	}
+	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3ReconciliationReportStatus.
+func (in *S3ReconciliationReportStatus) DeepCopy() *S3ReconciliationReportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(S3ReconciliationReportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3VolumeInventory) DeepCopyInto(out *S3VolumeInventory) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3VolumeInventory.
+func (in *S3VolumeInventory) DeepCopy() *S3VolumeInventory {
+	if in == nil {
+		return nil
+	}
+	out := new(S3VolumeInventory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *S3VolumeInventory) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3VolumeInventoryList) DeepCopyInto(out *S3VolumeInventoryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]S3VolumeInventory, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3VolumeInventoryList.
+func (in *S3VolumeInventoryList) DeepCopy() *S3VolumeInventoryList {
+	if in == nil {
+		return nil
+	}
+	out := new(S3VolumeInventoryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *S3VolumeInventoryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3VolumeInventoryStatus) DeepCopyInto(out *S3VolumeInventoryStatus) {
+	*out = *in
+	if in.ByStorageClass != nil {
+		in, out := &in.ByStorageClass, &out.ByStorageClass
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ByNamespace != nil {
+		in, out := &in.ByNamespace, &out.ByNamespace
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ByHealth != nil {
+		in, out := &in.ByHealth, &out.ByHealth
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.CSIDriverVersions != nil {
+		in, out := &in.CSIDriverVersions, &out.CSIDriverVersions
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MountpointVersions != nil {
+		in, out := &in.MountpointVersions, &out.MountpointVersions
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3VolumeInventoryStatus.
+func (in *S3VolumeInventoryStatus) DeepCopy() *S3VolumeInventoryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(S3VolumeInventoryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkloadAttachment) DeepCopyInto(out *WorkloadAttachment) {
 	*out = *in
@@ -0,0 +1,26 @@
+// Package v3 contains API Schema definitions for the s3.csi.scality.com v3 API group.
+//
+// v3 is not served by the CustomResourceDefinitions yet. Its objects are converted from and to the v2 hub by the
+// conversion webhook of the driver, so v3 can be served once all components of the driver can convert it.
+// +kubebuilder:object:generate=true
+// +kubebuilder:skipversion
+// +groupName=s3.csi.scality.com
+package v3
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/scheme"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
+)
+
+var (
+	// GroupVersion is group version used to register these objects.
+	GroupVersion = schema.GroupVersion{Group: constants.DriverName, Version: "v3"}
+
+	// SchemeBuilder is used to add go types to the GroupVersionKind scheme.
+	SchemeBuilder = &scheme.Builder{GroupVersion: GroupVersion}
+
+	// AddToScheme adds the types in this group-version to the given scheme.
+	AddToScheme = SchemeBuilder.AddToScheme
+)
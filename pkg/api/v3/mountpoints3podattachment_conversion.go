@@ -0,0 +1,83 @@
+package v3
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+)
+
+// ConvertTo converts this MountpointS3PodAttachment to the v2 hub version.
+func (src *MountpointS3PodAttachment) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*crdv2.MountpointS3PodAttachment)
+	if !ok {
+		return fmt.Errorf("unsupported conversion of MountpointS3PodAttachment to %T", dstRaw)
+	}
+
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = crdv2.MountpointS3PodAttachmentSpec{
+		NodeName:             src.Spec.NodeName,
+		PersistentVolumeName: src.Spec.PersistentVolumeName,
+		VolumeID:             src.Spec.VolumeID,
+		MountOptions:         src.Spec.SharingKey.MountOptions,
+		WorkloadFSGroup:      src.Spec.SharingKey.WorkloadFSGroup,
+		CredentialIdentity:   src.Spec.SharingKey.CredentialIdentity,
+	}
+	if src.Spec.MountpointS3PodAttachments != nil {
+		dst.Spec.MountpointS3PodAttachments = make(map[string][]crdv2.WorkloadAttachment, len(src.Spec.MountpointS3PodAttachments))
+		for mpPodName, attachments := range src.Spec.MountpointS3PodAttachments {
+			converted := make([]crdv2.WorkloadAttachment, len(attachments))
+			for i, attachment := range attachments {
+				converted[i] = crdv2.WorkloadAttachment{
+					WorkloadPodUID: attachment.WorkloadPodUID,
+					AttachmentTime: attachment.AttachmentTime,
+				}
+			}
+			dst.Spec.MountpointS3PodAttachments[mpPodName] = converted
+		}
+	}
+	dst.Status = crdv2.MountpointS3PodAttachmentStatus{
+		Conditions:      src.Status.Conditions,
+		MountGeneration: src.Status.MountGeneration,
+	}
+	return nil
+}
+
+// ConvertFrom converts from the v2 hub version to this MountpointS3PodAttachment.
+func (dst *MountpointS3PodAttachment) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*crdv2.MountpointS3PodAttachment)
+	if !ok {
+		return fmt.Errorf("unsupported conversion of %T to MountpointS3PodAttachment", srcRaw)
+	}
+
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = MountpointS3PodAttachmentSpec{
+		NodeName:             src.Spec.NodeName,
+		PersistentVolumeName: src.Spec.PersistentVolumeName,
+		VolumeID:             src.Spec.VolumeID,
+		SharingKey: SharingKey{
+			MountOptions:       src.Spec.MountOptions,
+			WorkloadFSGroup:    src.Spec.WorkloadFSGroup,
+			CredentialIdentity: src.Spec.CredentialIdentity,
+		},
+	}
+	if src.Spec.MountpointS3PodAttachments != nil {
+		dst.Spec.MountpointS3PodAttachments = make(map[string][]WorkloadAttachment, len(src.Spec.MountpointS3PodAttachments))
+		for mpPodName, attachments := range src.Spec.MountpointS3PodAttachments {
+			converted := make([]WorkloadAttachment, len(attachments))
+			for i, attachment := range attachments {
+				converted[i] = WorkloadAttachment{
+					WorkloadPodUID: attachment.WorkloadPodUID,
+					AttachmentTime: attachment.AttachmentTime,
+				}
+			}
+			dst.Spec.MountpointS3PodAttachments[mpPodName] = converted
+		}
+	}
+	dst.Status = MountpointS3PodAttachmentStatus{
+		Conditions:      src.Status.Conditions,
+		MountGeneration: src.Status.MountGeneration,
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package v3_test
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	crdv3 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v3"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+var attachmentTime = metav1.NewTime(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+
+func testS3PodAttachmentV2() *crdv2.MountpointS3PodAttachment {
+	return &crdv2.MountpointS3PodAttachment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "s3pa-test",
+			Labels:     map[string]string{"team": "storage"},
+			Finalizers: []string{crdv2.FinalizerMountpointPodsCleanup},
+		},
+		Spec: crdv2.MountpointS3PodAttachmentSpec{
+			NodeName:             "test-node",
+			PersistentVolumeName: "test-pv",
+			VolumeID:             "test-volume",
+			MountOptions:         "allow-delete,region=us-east-1",
+			WorkloadFSGroup:      "1000",
+			CredentialIdentity:   "webIdentity:default/reader",
+			MountpointS3PodAttachments: map[string][]crdv2.WorkloadAttachment{
+				"mp-1": {
+					{WorkloadPodUID: "workload-1", AttachmentTime: attachmentTime},
+					{WorkloadPodUID: "workload-2", AttachmentTime: attachmentTime},
+				},
+				"mp-2": {},
+			},
+		},
+		Status: crdv2.MountpointS3PodAttachmentStatus{
+			Conditions: []metav1.Condition{{
+				Type:               crdv2.ConditionMountpointReady,
+				Status:             metav1.ConditionTrue,
+				Reason:             "MountpointRunning",
+				LastTransitionTime: attachmentTime,
+			}},
+			MountGeneration: 3,
+		},
+	}
+}
+
+func TestConvertFromHub(t *testing.T) {
+	s3pa := &crdv3.MountpointS3PodAttachment{}
+	assert.NoError(t, s3pa.ConvertFrom(testS3PodAttachmentV2()))
+
+	assert.Equals(t, "s3pa-test", s3pa.Name)
+	assert.Equals(t, "test-node", s3pa.Spec.NodeName)
+	assert.Equals(t, crdv3.SharingKey{
+		MountOptions:       "allow-delete,region=us-east-1",
+		WorkloadFSGroup:    "1000",
+		CredentialIdentity: "webIdentity:default/reader",
+	}, s3pa.Spec.SharingKey)
+	assert.Equals(t, []crdv3.WorkloadAttachment{
+		{WorkloadPodUID: "workload-1", AttachmentTime: attachmentTime},
+		{WorkloadPodUID: "workload-2", AttachmentTime: attachmentTime},
+	}, s3pa.Spec.MountpointS3PodAttachments["mp-1"])
+	assert.Equals(t, int64(3), s3pa.Status.MountGeneration)
+}
+
+func TestConversionRoundTrip(t *testing.T) {
+	tests := map[string]*crdv2.MountpointS3PodAttachment{
+		"full attachment": testS3PodAttachmentV2(),
+		"empty attachment": {
+			ObjectMeta: metav1.ObjectMeta{Name: "s3pa-empty"},
+		},
+		"without fsGroup": func() *crdv2.MountpointS3PodAttachment {
+			s3pa := testS3PodAttachmentV2()
+			s3pa.Spec.WorkloadFSGroup = ""
+			return s3pa
+		}(),
+	}
+	for name, hub := range tests {
+		t.Run(name, func(t *testing.T) {
+			spoke := &crdv3.MountpointS3PodAttachment{}
+			assert.NoError(t, spoke.ConvertFrom(hub.DeepCopy()))
+			got := &crdv2.MountpointS3PodAttachment{}
+			assert.NoError(t, spoke.ConvertTo(got))
+			assert.Equals(t, hub, got)
+
+			// And back again from the converted hub
+			spokeAgain := &crdv3.MountpointS3PodAttachment{}
+			assert.NoError(t, spokeAgain.ConvertFrom(got))
+			assert.Equals(t, spoke, spokeAgain)
+		})
+	}
+}
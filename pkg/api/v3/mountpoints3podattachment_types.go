@@ -0,0 +1,100 @@
+package v3
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MountpointS3PodAttachmentSpec defines the desired state of MountpointS3PodAttachment.
+type MountpointS3PodAttachmentSpec struct {
+	// Important: Run "make generate" to regenerate code after modifying this file
+
+	// Name of the node.
+	NodeName string `json:"nodeName"`
+
+	// Name of the Persistent Volume.
+	PersistentVolumeName string `json:"persistentVolumeName"`
+
+	// Volume ID.
+	VolumeID string `json:"volumeID"`
+
+	// Criteria workloads of the volume on the node must match to share the Mountpoint Pods of the attachment.
+	SharingKey SharingKey `json:"sharingKey"`
+
+	// Maps each Mountpoint S3 pod name to its workload attachments
+	MountpointS3PodAttachments map[string][]WorkloadAttachment `json:"mountpointS3PodAttachments"`
+}
+
+// SharingKey contains the criteria, besides the node and the volume, workloads must match to share Mountpoint Pods.
+type SharingKey struct {
+	// Comma separated mount options taken from volume.
+	// +kubebuilder:validation:MaxLength=16384
+	MountOptions string `json:"mountOptions"`
+
+	// Workload pod's `fsGroup` from pod security context
+	// +optional
+	WorkloadFSGroup string `json:"workloadFSGroup,omitempty"`
+
+	// Identity Mountpoint accesses the bucket with for the workloads, e.g. `webIdentity:<namespace>/<service account>`,
+	// `role:<role ARN>` or `secret:<namespace>/<name>`. Empty for credentials of the node, e.g. the driver credentials.
+	// +optional
+	CredentialIdentity string `json:"credentialIdentity,omitempty"`
+}
+
+// WorkloadAttachment represents the attachment details of a workload pod to a Mountpoint S3 pod.
+type WorkloadAttachment struct {
+	// WorkloadPodUID is the unique identifier of the attached workload pod
+	WorkloadPodUID string `json:"workloadPodUID"`
+
+	// AttachmentTime represents when the workload pod was attached to the Mountpoint S3 pod
+	AttachmentTime metav1.Time `json:"attachmentTime"`
+}
+
+// MountpointS3PodAttachmentStatus defines the observed state of MountpointS3PodAttachment.
+type MountpointS3PodAttachmentStatus struct {
+	// Conditions of the attachment.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// MountGeneration of the volume on the node, reported by the node plugin when a Mountpoint Pod of the attachment
+	// mounts it. It is incremented every time the volume is mounted on the node with other options or credentials.
+	// +optional
+	MountGeneration int64 `json:"mountGeneration,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,shortName=s3pa
+// +kubebuilder:selectablefield:JSONPath=`.spec.nodeName`
+// +kubebuilder:selectablefield:JSONPath=`.spec.persistentVolumeName`
+// +kubebuilder:selectablefield:JSONPath=`.spec.volumeID`
+// +kubebuilder:selectablefield:JSONPath=`.spec.sharingKey.mountOptions`
+// +kubebuilder:selectablefield:JSONPath=`.spec.sharingKey.workloadFSGroup`
+// +kubebuilder:printcolumn:name="Node",type=string,JSONPath=`.spec.nodeName`,description="The node where the volume is mounted"
+// +kubebuilder:printcolumn:name="PV Name",type=string,JSONPath=`.spec.persistentVolumeName`,description="The persistent volume name"
+// +kubebuilder:printcolumn:name="Mount Options",type=string,JSONPath=`.spec.sharingKey.mountOptions`,description="Comma separated mount options"
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="MountpointReady")].status`,description="Whether Mountpoint runs in all Mountpoint Pods"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// MountpointS3PodAttachment is the Schema for the mountpoints3podattachments API.
+type MountpointS3PodAttachment struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MountpointS3PodAttachmentSpec   `json:"spec,omitempty"`
+	Status MountpointS3PodAttachmentStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// MountpointS3PodAttachmentList contains a list of MountpointS3PodAttachment.
+type MountpointS3PodAttachmentList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MountpointS3PodAttachment `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MountpointS3PodAttachment{}, &MountpointS3PodAttachmentList{})
+}
@@ -0,0 +1,68 @@
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v2
+
+// DivergenceApplyConfiguration represents a declarative configuration of the Divergence type for use
+// with apply.
+type DivergenceApplyConfiguration struct {
+	Type                      *string `json:"type,omitempty"`
+	NodeName                  *string `json:"nodeName,omitempty"`
+	MountpointPod             *string `json:"mountpointPod,omitempty"`
+	MountpointS3PodAttachment *string `json:"mountpointS3PodAttachment,omitempty"`
+	Message                   *string `json:"message,omitempty"`
+	Repaired                  *bool   `json:"repaired,omitempty"`
+}
+
+// DivergenceApplyConfiguration constructs a declarative configuration of the Divergence type for use with
+// apply.
+func Divergence() *DivergenceApplyConfiguration {
+	return &DivergenceApplyConfiguration{}
+}
+
+// WithType sets the Type field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Type field is set to the value of the last call.
+func (b *DivergenceApplyConfiguration) WithType(value string) *DivergenceApplyConfiguration {
+	b.Type = &value
+	return b
+}
+
+// WithNodeName sets the NodeName field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the NodeName field is set to the value of the last call.
+func (b *DivergenceApplyConfiguration) WithNodeName(value string) *DivergenceApplyConfiguration {
+	b.NodeName = &value
+	return b
+}
+
+// WithMountpointPod sets the MountpointPod field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the MountpointPod field is set to the value of the last call.
+func (b *DivergenceApplyConfiguration) WithMountpointPod(value string) *DivergenceApplyConfiguration {
+	b.MountpointPod = &value
+	return b
+}
+
+// WithMountpointS3PodAttachment sets the MountpointS3PodAttachment field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the MountpointS3PodAttachment field is set to the value of the last call.
+func (b *DivergenceApplyConfiguration) WithMountpointS3PodAttachment(value string) *DivergenceApplyConfiguration {
+	b.MountpointS3PodAttachment = &value
+	return b
+}
+
+// WithMessage sets the Message field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Message field is set to the value of the last call.
+func (b *DivergenceApplyConfiguration) WithMessage(value string) *DivergenceApplyConfiguration {
+	b.Message = &value
+	return b
+}
+
+// WithRepaired sets the Repaired field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Repaired field is set to the value of the last call.
+func (b *DivergenceApplyConfiguration) WithRepaired(value bool) *DivergenceApplyConfiguration {
+	b.Repaired = &value
+	return b
+}
@@ -0,0 +1,41 @@
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v2
+
+import (
+	v1 "k8s.io/client-go/applyconfigurations/meta/v1"
+)
+
+// MountpointS3PodAttachmentStatusApplyConfiguration represents a declarative configuration of the MountpointS3PodAttachmentStatus type for use
+// with apply.
+type MountpointS3PodAttachmentStatusApplyConfiguration struct {
+	Conditions      []v1.ConditionApplyConfiguration `json:"conditions,omitempty"`
+	MountGeneration *int64                           `json:"mountGeneration,omitempty"`
+}
+
+// MountpointS3PodAttachmentStatusApplyConfiguration constructs a declarative configuration of the MountpointS3PodAttachmentStatus type for use with
+// apply.
+func MountpointS3PodAttachmentStatus() *MountpointS3PodAttachmentStatusApplyConfiguration {
+	return &MountpointS3PodAttachmentStatusApplyConfiguration{}
+}
+
+// WithConditions adds the given value to the Conditions field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Conditions field.
+func (b *MountpointS3PodAttachmentStatusApplyConfiguration) WithConditions(values ...*v1.ConditionApplyConfiguration) *MountpointS3PodAttachmentStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithConditions")
+		}
+		b.Conditions = append(b.Conditions, *values[i])
+	}
+	return b
+}
+
+// WithMountGeneration sets the MountGeneration field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the MountGeneration field is set to the value of the last call.
+func (b *MountpointS3PodAttachmentStatusApplyConfiguration) WithMountGeneration(value int64) *MountpointS3PodAttachmentStatusApplyConfiguration {
+	b.MountGeneration = &value
+	return b
+}
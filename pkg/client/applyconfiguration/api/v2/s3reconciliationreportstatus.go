@@ -0,0 +1,95 @@
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v2
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// S3ReconciliationReportStatusApplyConfiguration represents a declarative configuration of the S3ReconciliationReportStatus type for use
+// with apply.
+type S3ReconciliationReportStatusApplyConfiguration struct {
+	MountpointPods       *int32                         `json:"mountpointPods,omitempty"`
+	WorkloadAttachments  *int32                         `json:"workloadAttachments,omitempty"`
+	NodesReporting       *int32                         `json:"nodesReporting,omitempty"`
+	ReportedSourceMounts *int32                         `json:"reportedSourceMounts,omitempty"`
+	ReportedTargets      *int32                         `json:"reportedTargets,omitempty"`
+	DivergenceCount      *int32                         `json:"divergenceCount,omitempty"`
+	Divergences          []DivergenceApplyConfiguration `json:"divergences,omitempty"`
+	LastCheckTime        *v1.Time                       `json:"lastCheckTime,omitempty"`
+}
+
+// S3ReconciliationReportStatusApplyConfiguration constructs a declarative configuration of the S3ReconciliationReportStatus type for use with
+// apply.
+func S3ReconciliationReportStatus() *S3ReconciliationReportStatusApplyConfiguration {
+	return &S3ReconciliationReportStatusApplyConfiguration{}
+}
+
+// WithMountpointPods sets the MountpointPods field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the MountpointPods field is set to the value of the last call.
+func (b *S3ReconciliationReportStatusApplyConfiguration) WithMountpointPods(value int32) *S3ReconciliationReportStatusApplyConfiguration {
+	b.MountpointPods = &value
+	return b
+}
+
+// WithWorkloadAttachments sets the WorkloadAttachments field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the WorkloadAttachments field is set to the value of the last call.
+func (b *S3ReconciliationReportStatusApplyConfiguration) WithWorkloadAttachments(value int32) *S3ReconciliationReportStatusApplyConfiguration {
+	b.WorkloadAttachments = &value
+	return b
+}
+
+// WithNodesReporting sets the NodesReporting field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the NodesReporting field is set to the value of the last call.
+func (b *S3ReconciliationReportStatusApplyConfiguration) WithNodesReporting(value int32) *S3ReconciliationReportStatusApplyConfiguration {
+	b.NodesReporting = &value
+	return b
+}
+
+// WithReportedSourceMounts sets the ReportedSourceMounts field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ReportedSourceMounts field is set to the value of the last call.
+func (b *S3ReconciliationReportStatusApplyConfiguration) WithReportedSourceMounts(value int32) *S3ReconciliationReportStatusApplyConfiguration {
+	b.ReportedSourceMounts = &value
+	return b
+}
+
+// WithReportedTargets sets the ReportedTargets field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ReportedTargets field is set to the value of the last call.
+func (b *S3ReconciliationReportStatusApplyConfiguration) WithReportedTargets(value int32) *S3ReconciliationReportStatusApplyConfiguration {
+	b.ReportedTargets = &value
+	return b
+}
+
+// WithDivergenceCount sets the DivergenceCount field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the DivergenceCount field is set to the value of the last call.
+func (b *S3ReconciliationReportStatusApplyConfiguration) WithDivergenceCount(value int32) *S3ReconciliationReportStatusApplyConfiguration {
+	b.DivergenceCount = &value
+	return b
+}
+
+// WithDivergences adds the given value to the Divergences field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Divergences field.
+func (b *S3ReconciliationReportStatusApplyConfiguration) WithDivergences(values ...*DivergenceApplyConfiguration) *S3ReconciliationReportStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithDivergences")
+		}
+		b.Divergences = append(b.Divergences, *values[i])
+	}
+	return b
+}
+
+// WithLastCheckTime sets the LastCheckTime field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the LastCheckTime field is set to the value of the last call.
+func (b *S3ReconciliationReportStatusApplyConfiguration) WithLastCheckTime(value v1.Time) *S3ReconciliationReportStatusApplyConfiguration {
+	b.LastCheckTime = &value
+	return b
+}
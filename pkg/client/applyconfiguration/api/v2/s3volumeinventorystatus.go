@@ -0,0 +1,120 @@
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v2
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// S3VolumeInventoryStatusApplyConfiguration represents a declarative configuration of the S3VolumeInventoryStatus type for use
+// with apply.
+type S3VolumeInventoryStatusApplyConfiguration struct {
+	Volumes            *int32           `json:"volumes,omitempty"`
+	ByStorageClass     map[string]int32 `json:"byStorageClass,omitempty"`
+	ByNamespace        map[string]int32 `json:"byNamespace,omitempty"`
+	ByHealth           map[string]int32 `json:"byHealth,omitempty"`
+	MountpointPods     *int32           `json:"mountpointPods,omitempty"`
+	CSIDriverVersions  map[string]int32 `json:"csiDriverVersions,omitempty"`
+	MountpointVersions map[string]int32 `json:"mountpointVersions,omitempty"`
+	LastUpdateTime     *v1.Time         `json:"lastUpdateTime,omitempty"`
+}
+
+// S3VolumeInventoryStatusApplyConfiguration constructs a declarative configuration of the S3VolumeInventoryStatus type for use with
+// apply.
+func S3VolumeInventoryStatus() *S3VolumeInventoryStatusApplyConfiguration {
+	return &S3VolumeInventoryStatusApplyConfiguration{}
+}
+
+// WithVolumes sets the Volumes field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Volumes field is set to the value of the last call.
+func (b *S3VolumeInventoryStatusApplyConfiguration) WithVolumes(value int32) *S3VolumeInventoryStatusApplyConfiguration {
+	b.Volumes = &value
+	return b
+}
+
+// WithByStorageClass puts the entries into the ByStorageClass field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the ByStorageClass field,
+// overwriting an existing map entries in ByStorageClass field with the same key.
+func (b *S3VolumeInventoryStatusApplyConfiguration) WithByStorageClass(entries map[string]int32) *S3VolumeInventoryStatusApplyConfiguration {
+	if b.ByStorageClass == nil && len(entries) > 0 {
+		b.ByStorageClass = make(map[string]int32, len(entries))
+	}
+	for k, v := range entries {
+		b.ByStorageClass[k] = v
+	}
+	return b
+}
+
+// WithByNamespace puts the entries into the ByNamespace field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the ByNamespace field,
+// overwriting an existing map entries in ByNamespace field with the same key.
+func (b *S3VolumeInventoryStatusApplyConfiguration) WithByNamespace(entries map[string]int32) *S3VolumeInventoryStatusApplyConfiguration {
+	if b.ByNamespace == nil && len(entries) > 0 {
+		b.ByNamespace = make(map[string]int32, len(entries))
+	}
+	for k, v := range entries {
+		b.ByNamespace[k] = v
+	}
+	return b
+}
+
+// WithByHealth puts the entries into the ByHealth field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the ByHealth field,
+// overwriting an existing map entries in ByHealth field with the same key.
+func (b *S3VolumeInventoryStatusApplyConfiguration) WithByHealth(entries map[string]int32) *S3VolumeInventoryStatusApplyConfiguration {
+	if b.ByHealth == nil && len(entries) > 0 {
+		b.ByHealth = make(map[string]int32, len(entries))
+	}
+	for k, v := range entries {
+		b.ByHealth[k] = v
+	}
+	return b
+}
+
+// WithMountpointPods sets the MountpointPods field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the MountpointPods field is set to the value of the last call.
+func (b *S3VolumeInventoryStatusApplyConfiguration) WithMountpointPods(value int32) *S3VolumeInventoryStatusApplyConfiguration {
+	b.MountpointPods = &value
+	return b
+}
+
+// WithCSIDriverVersions puts the entries into the CSIDriverVersions field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the CSIDriverVersions field,
+// overwriting an existing map entries in CSIDriverVersions field with the same key.
+func (b *S3VolumeInventoryStatusApplyConfiguration) WithCSIDriverVersions(entries map[string]int32) *S3VolumeInventoryStatusApplyConfiguration {
+	if b.CSIDriverVersions == nil && len(entries) > 0 {
+		b.CSIDriverVersions = make(map[string]int32, len(entries))
+	}
+	for k, v := range entries {
+		b.CSIDriverVersions[k] = v
+	}
+	return b
+}
+
+// WithMountpointVersions puts the entries into the MountpointVersions field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the MountpointVersions field,
+// overwriting an existing map entries in MountpointVersions field with the same key.
+func (b *S3VolumeInventoryStatusApplyConfiguration) WithMountpointVersions(entries map[string]int32) *S3VolumeInventoryStatusApplyConfiguration {
+	if b.MountpointVersions == nil && len(entries) > 0 {
+		b.MountpointVersions = make(map[string]int32, len(entries))
+	}
+	for k, v := range entries {
+		b.MountpointVersions[k] = v
+	}
+	return b
+}
+
+// WithLastUpdateTime sets the LastUpdateTime field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the LastUpdateTime field is set to the value of the last call.
+func (b *S3VolumeInventoryStatusApplyConfiguration) WithLastUpdateTime(value v1.Time) *S3VolumeInventoryStatusApplyConfiguration {
+	b.LastUpdateTime = &value
+	return b
+}
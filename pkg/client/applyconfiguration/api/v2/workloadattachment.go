@@ -0,0 +1,36 @@
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v2
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WorkloadAttachmentApplyConfiguration represents a declarative configuration of the WorkloadAttachment type for use
+// with apply.
+type WorkloadAttachmentApplyConfiguration struct {
+	WorkloadPodUID *string  `json:"workloadPodUID,omitempty"`
+	AttachmentTime *v1.Time `json:"attachmentTime,omitempty"`
+}
+
+// WorkloadAttachmentApplyConfiguration constructs a declarative configuration of the WorkloadAttachment type for use with
+// apply.
+func WorkloadAttachment() *WorkloadAttachmentApplyConfiguration {
+	return &WorkloadAttachmentApplyConfiguration{}
+}
+
+// WithWorkloadPodUID sets the WorkloadPodUID field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the WorkloadPodUID field is set to the value of the last call.
+func (b *WorkloadAttachmentApplyConfiguration) WithWorkloadPodUID(value string) *WorkloadAttachmentApplyConfiguration {
+	b.WorkloadPodUID = &value
+	return b
+}
+
+// WithAttachmentTime sets the AttachmentTime field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the AttachmentTime field is set to the value of the last call.
+func (b *WorkloadAttachmentApplyConfiguration) WithAttachmentTime(value v1.Time) *WorkloadAttachmentApplyConfiguration {
+	b.AttachmentTime = &value
+	return b
+}
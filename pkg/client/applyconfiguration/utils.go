@@ -0,0 +1,44 @@
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package applyconfiguration
+
+import (
+	v2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	apiv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/client/applyconfiguration/api/v2"
+	internal "github.com/scality/mountpoint-s3-csi-driver/pkg/client/applyconfiguration/internal"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	testing "k8s.io/client-go/testing"
+)
+
+// ForKind returns an apply configuration type for the given GroupVersionKind, or nil if no
+// apply configuration type exists for the given GroupVersionKind.
+func ForKind(kind schema.GroupVersionKind) interface{} {
+	switch kind {
+	// Group=s3.csi.scality.com, Version=v2
+	case v2.SchemeGroupVersion.WithKind("Divergence"):
+		return &apiv2.DivergenceApplyConfiguration{}
+	case v2.SchemeGroupVersion.WithKind("MountpointS3PodAttachment"):
+		return &apiv2.MountpointS3PodAttachmentApplyConfiguration{}
+	case v2.SchemeGroupVersion.WithKind("MountpointS3PodAttachmentSpec"):
+		return &apiv2.MountpointS3PodAttachmentSpecApplyConfiguration{}
+	case v2.SchemeGroupVersion.WithKind("MountpointS3PodAttachmentStatus"):
+		return &apiv2.MountpointS3PodAttachmentStatusApplyConfiguration{}
+	case v2.SchemeGroupVersion.WithKind("S3ReconciliationReport"):
+		return &apiv2.S3ReconciliationReportApplyConfiguration{}
+	case v2.SchemeGroupVersion.WithKind("S3ReconciliationReportStatus"):
+		return &apiv2.S3ReconciliationReportStatusApplyConfiguration{}
+	case v2.SchemeGroupVersion.WithKind("S3VolumeInventory"):
+		return &apiv2.S3VolumeInventoryApplyConfiguration{}
+	case v2.SchemeGroupVersion.WithKind("S3VolumeInventoryStatus"):
+		return &apiv2.S3VolumeInventoryStatusApplyConfiguration{}
+	case v2.SchemeGroupVersion.WithKind("WorkloadAttachment"):
+		return &apiv2.WorkloadAttachmentApplyConfiguration{}
+
+	}
+	return nil
+}
+
+func NewTypeConverter(scheme *runtime.Scheme) *testing.TypeConverter {
+	return &testing.TypeConverter{Scheme: scheme, TypeResolver: internal.Parser()}
+}
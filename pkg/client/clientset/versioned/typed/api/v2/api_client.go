@@ -0,0 +1,95 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package v2
+
+import (
+	http "net/http"
+
+	apiv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	scheme "github.com/scality/mountpoint-s3-csi-driver/pkg/client/clientset/versioned/scheme"
+	rest "k8s.io/client-go/rest"
+)
+
+type S3V2Interface interface {
+	RESTClient() rest.Interface
+	MountpointS3PodAttachmentsGetter
+	S3ReconciliationReportsGetter
+	S3VolumeInventoriesGetter
+}
+
+// S3V2Client is used to interact with features provided by the s3.csi.scality.com group.
+type S3V2Client struct {
+	restClient rest.Interface
+}
+
+func (c *S3V2Client) MountpointS3PodAttachments() MountpointS3PodAttachmentInterface {
+	return newMountpointS3PodAttachments(c)
+}
+
+func (c *S3V2Client) S3ReconciliationReports() S3ReconciliationReportInterface {
+	return newS3ReconciliationReports(c)
+}
+
+func (c *S3V2Client) S3VolumeInventories() S3VolumeInventoryInterface {
+	return newS3VolumeInventories(c)
+}
+
+// NewForConfig creates a new S3V2Client for the given config.
+// NewForConfig is equivalent to NewForConfigAndClient(c, httpClient),
+// where httpClient was generated with rest.HTTPClientFor(c).
+func NewForConfig(c *rest.Config) (*S3V2Client, error) {
+	config := *c
+	setConfigDefaults(&config)
+	httpClient, err := rest.HTTPClientFor(&config)
+	if err != nil {
+		return nil, err
+	}
+	return NewForConfigAndClient(&config, httpClient)
+}
+
+// NewForConfigAndClient creates a new S3V2Client for the given config and http client.
+// Note the http client provided takes precedence over the configured transport values.
+func NewForConfigAndClient(c *rest.Config, h *http.Client) (*S3V2Client, error) {
+	config := *c
+	setConfigDefaults(&config)
+	client, err := rest.RESTClientForConfigAndClient(&config, h)
+	if err != nil {
+		return nil, err
+	}
+	return &S3V2Client{client}, nil
+}
+
+// NewForConfigOrDie creates a new S3V2Client for the given config and
+// panics if there is an error in the config.
+func NewForConfigOrDie(c *rest.Config) *S3V2Client {
+	client, err := NewForConfig(c)
+	if err != nil {
+		panic(err)
+	}
+	return client
+}
+
+// New creates a new S3V2Client for the given RESTClient.
+func New(c rest.Interface) *S3V2Client {
+	return &S3V2Client{c}
+}
+
+func setConfigDefaults(config *rest.Config) {
+	gv := apiv2.SchemeGroupVersion
+	config.GroupVersion = &gv
+	config.APIPath = "/apis"
+	config.NegotiatedSerializer = rest.CodecFactoryForGeneratedClient(scheme.Scheme, scheme.Codecs).WithoutConversion()
+
+	if config.UserAgent == "" {
+		config.UserAgent = rest.DefaultKubernetesUserAgent()
+	}
+}
+
+// RESTClient returns a RESTClient that is used to communicate
+// with API server by this client implementation.
+func (c *S3V2Client) RESTClient() rest.Interface {
+	if c == nil {
+		return nil
+	}
+	return c.restClient
+}
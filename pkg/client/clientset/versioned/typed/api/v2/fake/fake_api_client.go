@@ -0,0 +1,32 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v2 "github.com/scality/mountpoint-s3-csi-driver/pkg/client/clientset/versioned/typed/api/v2"
+	rest "k8s.io/client-go/rest"
+	testing "k8s.io/client-go/testing"
+)
+
+type FakeS3V2 struct {
+	*testing.Fake
+}
+
+func (c *FakeS3V2) MountpointS3PodAttachments() v2.MountpointS3PodAttachmentInterface {
+	return newFakeMountpointS3PodAttachments(c)
+}
+
+func (c *FakeS3V2) S3ReconciliationReports() v2.S3ReconciliationReportInterface {
+	return newFakeS3ReconciliationReports(c)
+}
+
+func (c *FakeS3V2) S3VolumeInventories() v2.S3VolumeInventoryInterface {
+	return newFakeS3VolumeInventories(c)
+}
+
+// RESTClient returns a RESTClient that is used to communicate
+// with API server by this client implementation.
+func (c *FakeS3V2) RESTClient() rest.Interface {
+	var ret *rest.RESTClient
+	return ret
+}
@@ -0,0 +1,37 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	apiv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/client/applyconfiguration/api/v2"
+	typedapiv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/client/clientset/versioned/typed/api/v2"
+	gentype "k8s.io/client-go/gentype"
+)
+
+// fakeMountpointS3PodAttachments implements MountpointS3PodAttachmentInterface
+type fakeMountpointS3PodAttachments struct {
+	*gentype.FakeClientWithListAndApply[*v2.MountpointS3PodAttachment, *v2.MountpointS3PodAttachmentList, *apiv2.MountpointS3PodAttachmentApplyConfiguration]
+	Fake *FakeS3V2
+}
+
+func newFakeMountpointS3PodAttachments(fake *FakeS3V2) typedapiv2.MountpointS3PodAttachmentInterface {
+	return &fakeMountpointS3PodAttachments{
+		gentype.NewFakeClientWithListAndApply[*v2.MountpointS3PodAttachment, *v2.MountpointS3PodAttachmentList, *apiv2.MountpointS3PodAttachmentApplyConfiguration](
+			fake.Fake,
+			"",
+			v2.SchemeGroupVersion.WithResource("mountpoints3podattachments"),
+			v2.SchemeGroupVersion.WithKind("MountpointS3PodAttachment"),
+			func() *v2.MountpointS3PodAttachment { return &v2.MountpointS3PodAttachment{} },
+			func() *v2.MountpointS3PodAttachmentList { return &v2.MountpointS3PodAttachmentList{} },
+			func(dst, src *v2.MountpointS3PodAttachmentList) { dst.ListMeta = src.ListMeta },
+			func(list *v2.MountpointS3PodAttachmentList) []*v2.MountpointS3PodAttachment {
+				return gentype.ToPointerSlice(list.Items)
+			},
+			func(list *v2.MountpointS3PodAttachmentList, items []*v2.MountpointS3PodAttachment) {
+				list.Items = gentype.FromPointerSlice(items)
+			},
+		),
+		fake,
+	}
+}
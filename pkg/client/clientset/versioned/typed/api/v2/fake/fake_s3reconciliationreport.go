@@ -0,0 +1,37 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	apiv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/client/applyconfiguration/api/v2"
+	typedapiv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/client/clientset/versioned/typed/api/v2"
+	gentype "k8s.io/client-go/gentype"
+)
+
+// fakeS3ReconciliationReports implements S3ReconciliationReportInterface
+type fakeS3ReconciliationReports struct {
+	*gentype.FakeClientWithListAndApply[*v2.S3ReconciliationReport, *v2.S3ReconciliationReportList, *apiv2.S3ReconciliationReportApplyConfiguration]
+	Fake *FakeS3V2
+}
+
+func newFakeS3ReconciliationReports(fake *FakeS3V2) typedapiv2.S3ReconciliationReportInterface {
+	return &fakeS3ReconciliationReports{
+		gentype.NewFakeClientWithListAndApply[*v2.S3ReconciliationReport, *v2.S3ReconciliationReportList, *apiv2.S3ReconciliationReportApplyConfiguration](
+			fake.Fake,
+			"",
+			v2.SchemeGroupVersion.WithResource("s3reconciliationreports"),
+			v2.SchemeGroupVersion.WithKind("S3ReconciliationReport"),
+			func() *v2.S3ReconciliationReport { return &v2.S3ReconciliationReport{} },
+			func() *v2.S3ReconciliationReportList { return &v2.S3ReconciliationReportList{} },
+			func(dst, src *v2.S3ReconciliationReportList) { dst.ListMeta = src.ListMeta },
+			func(list *v2.S3ReconciliationReportList) []*v2.S3ReconciliationReport {
+				return gentype.ToPointerSlice(list.Items)
+			},
+			func(list *v2.S3ReconciliationReportList, items []*v2.S3ReconciliationReport) {
+				list.Items = gentype.FromPointerSlice(items)
+			},
+		),
+		fake,
+	}
+}
@@ -0,0 +1,37 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	apiv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/client/applyconfiguration/api/v2"
+	typedapiv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/client/clientset/versioned/typed/api/v2"
+	gentype "k8s.io/client-go/gentype"
+)
+
+// fakeS3VolumeInventories implements S3VolumeInventoryInterface
+type fakeS3VolumeInventories struct {
+	*gentype.FakeClientWithListAndApply[*v2.S3VolumeInventory, *v2.S3VolumeInventoryList, *apiv2.S3VolumeInventoryApplyConfiguration]
+	Fake *FakeS3V2
+}
+
+func newFakeS3VolumeInventories(fake *FakeS3V2) typedapiv2.S3VolumeInventoryInterface {
+	return &fakeS3VolumeInventories{
+		gentype.NewFakeClientWithListAndApply[*v2.S3VolumeInventory, *v2.S3VolumeInventoryList, *apiv2.S3VolumeInventoryApplyConfiguration](
+			fake.Fake,
+			"",
+			v2.SchemeGroupVersion.WithResource("s3volumeinventories"),
+			v2.SchemeGroupVersion.WithKind("S3VolumeInventory"),
+			func() *v2.S3VolumeInventory { return &v2.S3VolumeInventory{} },
+			func() *v2.S3VolumeInventoryList { return &v2.S3VolumeInventoryList{} },
+			func(dst, src *v2.S3VolumeInventoryList) { dst.ListMeta = src.ListMeta },
+			func(list *v2.S3VolumeInventoryList) []*v2.S3VolumeInventory {
+				return gentype.ToPointerSlice(list.Items)
+			},
+			func(list *v2.S3VolumeInventoryList, items []*v2.S3VolumeInventory) {
+				list.Items = gentype.FromPointerSlice(items)
+			},
+		),
+		fake,
+	}
+}
@@ -0,0 +1,9 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package v2
+
+type MountpointS3PodAttachmentExpansion interface{}
+
+type S3ReconciliationReportExpansion interface{}
+
+type S3VolumeInventoryExpansion interface{}
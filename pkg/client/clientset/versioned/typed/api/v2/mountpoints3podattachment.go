@@ -0,0 +1,58 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package v2
+
+import (
+	context "context"
+
+	apiv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	applyconfigurationapiv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/client/applyconfiguration/api/v2"
+	scheme "github.com/scality/mountpoint-s3-csi-driver/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	gentype "k8s.io/client-go/gentype"
+)
+
+// MountpointS3PodAttachmentsGetter has a method to return a MountpointS3PodAttachmentInterface.
+// A group's client should implement this interface.
+type MountpointS3PodAttachmentsGetter interface {
+	MountpointS3PodAttachments() MountpointS3PodAttachmentInterface
+}
+
+// MountpointS3PodAttachmentInterface has methods to work with MountpointS3PodAttachment resources.
+type MountpointS3PodAttachmentInterface interface {
+	Create(ctx context.Context, mountpointS3PodAttachment *apiv2.MountpointS3PodAttachment, opts v1.CreateOptions) (*apiv2.MountpointS3PodAttachment, error)
+	Update(ctx context.Context, mountpointS3PodAttachment *apiv2.MountpointS3PodAttachment, opts v1.UpdateOptions) (*apiv2.MountpointS3PodAttachment, error)
+	// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+	UpdateStatus(ctx context.Context, mountpointS3PodAttachment *apiv2.MountpointS3PodAttachment, opts v1.UpdateOptions) (*apiv2.MountpointS3PodAttachment, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*apiv2.MountpointS3PodAttachment, error)
+	List(ctx context.Context, opts v1.ListOptions) (*apiv2.MountpointS3PodAttachmentList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *apiv2.MountpointS3PodAttachment, err error)
+	Apply(ctx context.Context, mountpointS3PodAttachment *applyconfigurationapiv2.MountpointS3PodAttachmentApplyConfiguration, opts v1.ApplyOptions) (result *apiv2.MountpointS3PodAttachment, err error)
+	// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+	ApplyStatus(ctx context.Context, mountpointS3PodAttachment *applyconfigurationapiv2.MountpointS3PodAttachmentApplyConfiguration, opts v1.ApplyOptions) (result *apiv2.MountpointS3PodAttachment, err error)
+	MountpointS3PodAttachmentExpansion
+}
+
+// mountpointS3PodAttachments implements MountpointS3PodAttachmentInterface
+type mountpointS3PodAttachments struct {
+	*gentype.ClientWithListAndApply[*apiv2.MountpointS3PodAttachment, *apiv2.MountpointS3PodAttachmentList, *applyconfigurationapiv2.MountpointS3PodAttachmentApplyConfiguration]
+}
+
+// newMountpointS3PodAttachments returns a MountpointS3PodAttachments
+func newMountpointS3PodAttachments(c *S3V2Client) *mountpointS3PodAttachments {
+	return &mountpointS3PodAttachments{
+		gentype.NewClientWithListAndApply[*apiv2.MountpointS3PodAttachment, *apiv2.MountpointS3PodAttachmentList, *applyconfigurationapiv2.MountpointS3PodAttachmentApplyConfiguration](
+			"mountpoints3podattachments",
+			c.RESTClient(),
+			scheme.ParameterCodec,
+			"",
+			func() *apiv2.MountpointS3PodAttachment { return &apiv2.MountpointS3PodAttachment{} },
+			func() *apiv2.MountpointS3PodAttachmentList { return &apiv2.MountpointS3PodAttachmentList{} },
+		),
+	}
+}
@@ -0,0 +1,58 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package v2
+
+import (
+	context "context"
+
+	apiv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	applyconfigurationapiv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/client/applyconfiguration/api/v2"
+	scheme "github.com/scality/mountpoint-s3-csi-driver/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	gentype "k8s.io/client-go/gentype"
+)
+
+// S3ReconciliationReportsGetter has a method to return a S3ReconciliationReportInterface.
+// A group's client should implement this interface.
+type S3ReconciliationReportsGetter interface {
+	S3ReconciliationReports() S3ReconciliationReportInterface
+}
+
+// S3ReconciliationReportInterface has methods to work with S3ReconciliationReport resources.
+type S3ReconciliationReportInterface interface {
+	Create(ctx context.Context, s3ReconciliationReport *apiv2.S3ReconciliationReport, opts v1.CreateOptions) (*apiv2.S3ReconciliationReport, error)
+	Update(ctx context.Context, s3ReconciliationReport *apiv2.S3ReconciliationReport, opts v1.UpdateOptions) (*apiv2.S3ReconciliationReport, error)
+	// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+	UpdateStatus(ctx context.Context, s3ReconciliationReport *apiv2.S3ReconciliationReport, opts v1.UpdateOptions) (*apiv2.S3ReconciliationReport, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*apiv2.S3ReconciliationReport, error)
+	List(ctx context.Context, opts v1.ListOptions) (*apiv2.S3ReconciliationReportList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *apiv2.S3ReconciliationReport, err error)
+	Apply(ctx context.Context, s3ReconciliationReport *applyconfigurationapiv2.S3ReconciliationReportApplyConfiguration, opts v1.ApplyOptions) (result *apiv2.S3ReconciliationReport, err error)
+	// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+	ApplyStatus(ctx context.Context, s3ReconciliationReport *applyconfigurationapiv2.S3ReconciliationReportApplyConfiguration, opts v1.ApplyOptions) (result *apiv2.S3ReconciliationReport, err error)
+	S3ReconciliationReportExpansion
+}
+
+// s3ReconciliationReports implements S3ReconciliationReportInterface
+type s3ReconciliationReports struct {
+	*gentype.ClientWithListAndApply[*apiv2.S3ReconciliationReport, *apiv2.S3ReconciliationReportList, *applyconfigurationapiv2.S3ReconciliationReportApplyConfiguration]
+}
+
+// newS3ReconciliationReports returns a S3ReconciliationReports
+func newS3ReconciliationReports(c *S3V2Client) *s3ReconciliationReports {
+	return &s3ReconciliationReports{
+		gentype.NewClientWithListAndApply[*apiv2.S3ReconciliationReport, *apiv2.S3ReconciliationReportList, *applyconfigurationapiv2.S3ReconciliationReportApplyConfiguration](
+			"s3reconciliationreports",
+			c.RESTClient(),
+			scheme.ParameterCodec,
+			"",
+			func() *apiv2.S3ReconciliationReport { return &apiv2.S3ReconciliationReport{} },
+			func() *apiv2.S3ReconciliationReportList { return &apiv2.S3ReconciliationReportList{} },
+		),
+	}
+}
@@ -0,0 +1,58 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package v2
+
+import (
+	context "context"
+
+	apiv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	applyconfigurationapiv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/client/applyconfiguration/api/v2"
+	scheme "github.com/scality/mountpoint-s3-csi-driver/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	gentype "k8s.io/client-go/gentype"
+)
+
+// S3VolumeInventoriesGetter has a method to return a S3VolumeInventoryInterface.
+// A group's client should implement this interface.
+type S3VolumeInventoriesGetter interface {
+	S3VolumeInventories() S3VolumeInventoryInterface
+}
+
+// S3VolumeInventoryInterface has methods to work with S3VolumeInventory resources.
+type S3VolumeInventoryInterface interface {
+	Create(ctx context.Context, s3VolumeInventory *apiv2.S3VolumeInventory, opts v1.CreateOptions) (*apiv2.S3VolumeInventory, error)
+	Update(ctx context.Context, s3VolumeInventory *apiv2.S3VolumeInventory, opts v1.UpdateOptions) (*apiv2.S3VolumeInventory, error)
+	// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+	UpdateStatus(ctx context.Context, s3VolumeInventory *apiv2.S3VolumeInventory, opts v1.UpdateOptions) (*apiv2.S3VolumeInventory, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*apiv2.S3VolumeInventory, error)
+	List(ctx context.Context, opts v1.ListOptions) (*apiv2.S3VolumeInventoryList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *apiv2.S3VolumeInventory, err error)
+	Apply(ctx context.Context, s3VolumeInventory *applyconfigurationapiv2.S3VolumeInventoryApplyConfiguration, opts v1.ApplyOptions) (result *apiv2.S3VolumeInventory, err error)
+	// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+	ApplyStatus(ctx context.Context, s3VolumeInventory *applyconfigurationapiv2.S3VolumeInventoryApplyConfiguration, opts v1.ApplyOptions) (result *apiv2.S3VolumeInventory, err error)
+	S3VolumeInventoryExpansion
+}
+
+// s3VolumeInventories implements S3VolumeInventoryInterface
+type s3VolumeInventories struct {
+	*gentype.ClientWithListAndApply[*apiv2.S3VolumeInventory, *apiv2.S3VolumeInventoryList, *applyconfigurationapiv2.S3VolumeInventoryApplyConfiguration]
+}
+
+// newS3VolumeInventories returns a S3VolumeInventories
+func newS3VolumeInventories(c *S3V2Client) *s3VolumeInventories {
+	return &s3VolumeInventories{
+		gentype.NewClientWithListAndApply[*apiv2.S3VolumeInventory, *apiv2.S3VolumeInventoryList, *applyconfigurationapiv2.S3VolumeInventoryApplyConfiguration](
+			"s3volumeinventories",
+			c.RESTClient(),
+			scheme.ParameterCodec,
+			"",
+			func() *apiv2.S3VolumeInventory { return &apiv2.S3VolumeInventory{} },
+			func() *apiv2.S3VolumeInventoryList { return &apiv2.S3VolumeInventoryList{} },
+		),
+	}
+}
@@ -0,0 +1,43 @@
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v2
+
+import (
+	internalinterfaces "github.com/scality/mountpoint-s3-csi-driver/pkg/client/informers/externalversions/internalinterfaces"
+)
+
+// Interface provides access to all the informers in this group version.
+type Interface interface {
+	// MountpointS3PodAttachments returns a MountpointS3PodAttachmentInformer.
+	MountpointS3PodAttachments() MountpointS3PodAttachmentInformer
+	// S3ReconciliationReports returns a S3ReconciliationReportInformer.
+	S3ReconciliationReports() S3ReconciliationReportInformer
+	// S3VolumeInventories returns a S3VolumeInventoryInformer.
+	S3VolumeInventories() S3VolumeInventoryInformer
+}
+
+type version struct {
+	factory          internalinterfaces.SharedInformerFactory
+	namespace        string
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+}
+
+// New returns a new Interface.
+func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakListOptions internalinterfaces.TweakListOptionsFunc) Interface {
+	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
+}
+
+// MountpointS3PodAttachments returns a MountpointS3PodAttachmentInformer.
+func (v *version) MountpointS3PodAttachments() MountpointS3PodAttachmentInformer {
+	return &mountpointS3PodAttachmentInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// S3ReconciliationReports returns a S3ReconciliationReportInformer.
+func (v *version) S3ReconciliationReports() S3ReconciliationReportInformer {
+	return &s3ReconciliationReportInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// S3VolumeInventories returns a S3VolumeInventoryInformer.
+func (v *version) S3VolumeInventories() S3VolumeInventoryInformer {
+	return &s3VolumeInventoryInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
@@ -0,0 +1,85 @@
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v2
+
+import (
+	context "context"
+	time "time"
+
+	pkgapiv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	versioned "github.com/scality/mountpoint-s3-csi-driver/pkg/client/clientset/versioned"
+	internalinterfaces "github.com/scality/mountpoint-s3-csi-driver/pkg/client/informers/externalversions/internalinterfaces"
+	apiv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/client/listers/api/v2"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// MountpointS3PodAttachmentInformer provides access to a shared informer and lister for
+// MountpointS3PodAttachments.
+type MountpointS3PodAttachmentInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() apiv2.MountpointS3PodAttachmentLister
+}
+
+type mountpointS3PodAttachmentInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+}
+
+// NewMountpointS3PodAttachmentInformer constructs a new informer for MountpointS3PodAttachment type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewMountpointS3PodAttachmentInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredMountpointS3PodAttachmentInformer(client, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredMountpointS3PodAttachmentInformer constructs a new informer for MountpointS3PodAttachment type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredMountpointS3PodAttachmentInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.S3V2().MountpointS3PodAttachments().List(context.Background(), options)
+			},
+			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.S3V2().MountpointS3PodAttachments().Watch(context.Background(), options)
+			},
+			ListWithContextFunc: func(ctx context.Context, options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.S3V2().MountpointS3PodAttachments().List(ctx, options)
+			},
+			WatchFuncWithContext: func(ctx context.Context, options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.S3V2().MountpointS3PodAttachments().Watch(ctx, options)
+			},
+		},
+		&pkgapiv2.MountpointS3PodAttachment{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *mountpointS3PodAttachmentInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredMountpointS3PodAttachmentInformer(client, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *mountpointS3PodAttachmentInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&pkgapiv2.MountpointS3PodAttachment{}, f.defaultInformer)
+}
+
+func (f *mountpointS3PodAttachmentInformer) Lister() apiv2.MountpointS3PodAttachmentLister {
+	return apiv2.NewMountpointS3PodAttachmentLister(f.Informer().GetIndexer())
+}
@@ -0,0 +1,85 @@
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v2
+
+import (
+	context "context"
+	time "time"
+
+	pkgapiv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	versioned "github.com/scality/mountpoint-s3-csi-driver/pkg/client/clientset/versioned"
+	internalinterfaces "github.com/scality/mountpoint-s3-csi-driver/pkg/client/informers/externalversions/internalinterfaces"
+	apiv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/client/listers/api/v2"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// S3ReconciliationReportInformer provides access to a shared informer and lister for
+// S3ReconciliationReports.
+type S3ReconciliationReportInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() apiv2.S3ReconciliationReportLister
+}
+
+type s3ReconciliationReportInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+}
+
+// NewS3ReconciliationReportInformer constructs a new informer for S3ReconciliationReport type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewS3ReconciliationReportInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredS3ReconciliationReportInformer(client, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredS3ReconciliationReportInformer constructs a new informer for S3ReconciliationReport type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredS3ReconciliationReportInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.S3V2().S3ReconciliationReports().List(context.Background(), options)
+			},
+			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.S3V2().S3ReconciliationReports().Watch(context.Background(), options)
+			},
+			ListWithContextFunc: func(ctx context.Context, options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.S3V2().S3ReconciliationReports().List(ctx, options)
+			},
+			WatchFuncWithContext: func(ctx context.Context, options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.S3V2().S3ReconciliationReports().Watch(ctx, options)
+			},
+		},
+		&pkgapiv2.S3ReconciliationReport{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *s3ReconciliationReportInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredS3ReconciliationReportInformer(client, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *s3ReconciliationReportInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&pkgapiv2.S3ReconciliationReport{}, f.defaultInformer)
+}
+
+func (f *s3ReconciliationReportInformer) Lister() apiv2.S3ReconciliationReportLister {
+	return apiv2.NewS3ReconciliationReportLister(f.Informer().GetIndexer())
+}
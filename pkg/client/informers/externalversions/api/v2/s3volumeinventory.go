@@ -0,0 +1,85 @@
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v2
+
+import (
+	context "context"
+	time "time"
+
+	pkgapiv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	versioned "github.com/scality/mountpoint-s3-csi-driver/pkg/client/clientset/versioned"
+	internalinterfaces "github.com/scality/mountpoint-s3-csi-driver/pkg/client/informers/externalversions/internalinterfaces"
+	apiv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/client/listers/api/v2"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// S3VolumeInventoryInformer provides access to a shared informer and lister for
+// S3VolumeInventories.
+type S3VolumeInventoryInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() apiv2.S3VolumeInventoryLister
+}
+
+type s3VolumeInventoryInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+}
+
+// NewS3VolumeInventoryInformer constructs a new informer for S3VolumeInventory type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewS3VolumeInventoryInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredS3VolumeInventoryInformer(client, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredS3VolumeInventoryInformer constructs a new informer for S3VolumeInventory type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredS3VolumeInventoryInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.S3V2().S3VolumeInventories().List(context.Background(), options)
+			},
+			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.S3V2().S3VolumeInventories().Watch(context.Background(), options)
+			},
+			ListWithContextFunc: func(ctx context.Context, options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.S3V2().S3VolumeInventories().List(ctx, options)
+			},
+			WatchFuncWithContext: func(ctx context.Context, options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.S3V2().S3VolumeInventories().Watch(ctx, options)
+			},
+		},
+		&pkgapiv2.S3VolumeInventory{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *s3VolumeInventoryInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredS3VolumeInventoryInformer(client, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *s3VolumeInventoryInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&pkgapiv2.S3VolumeInventory{}, f.defaultInformer)
+}
+
+func (f *s3VolumeInventoryInformer) Lister() apiv2.S3VolumeInventoryLister {
+	return apiv2.NewS3VolumeInventoryLister(f.Informer().GetIndexer())
+}
@@ -0,0 +1,50 @@
+// Code generated by informer-gen. DO NOT EDIT.
+
+package externalversions
+
+import (
+	fmt "fmt"
+
+	v2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// GenericInformer is type of SharedIndexInformer which will locate and delegate to other
+// sharedInformers based on type
+type GenericInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() cache.GenericLister
+}
+
+type genericInformer struct {
+	informer cache.SharedIndexInformer
+	resource schema.GroupResource
+}
+
+// Informer returns the SharedIndexInformer.
+func (f *genericInformer) Informer() cache.SharedIndexInformer {
+	return f.informer
+}
+
+// Lister returns the GenericLister.
+func (f *genericInformer) Lister() cache.GenericLister {
+	return cache.NewGenericLister(f.Informer().GetIndexer(), f.resource)
+}
+
+// ForResource gives generic access to a shared informer of the matching type
+// TODO extend this to unknown resources with a client pool
+func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
+	switch resource {
+	// Group=s3.csi.scality.com, Version=v2
+	case v2.SchemeGroupVersion.WithResource("mountpoints3podattachments"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.S3().V2().MountpointS3PodAttachments().Informer()}, nil
+	case v2.SchemeGroupVersion.WithResource("s3reconciliationreports"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.S3().V2().S3ReconciliationReports().Informer()}, nil
+	case v2.SchemeGroupVersion.WithResource("s3volumeinventories"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.S3().V2().S3VolumeInventories().Informer()}, nil
+
+	}
+
+	return nil, fmt.Errorf("no informer found for %v", resource)
+}
@@ -0,0 +1,15 @@
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v2
+
+// MountpointS3PodAttachmentListerExpansion allows custom methods to be added to
+// MountpointS3PodAttachmentLister.
+type MountpointS3PodAttachmentListerExpansion interface{}
+
+// S3ReconciliationReportListerExpansion allows custom methods to be added to
+// S3ReconciliationReportLister.
+type S3ReconciliationReportListerExpansion interface{}
+
+// S3VolumeInventoryListerExpansion allows custom methods to be added to
+// S3VolumeInventoryLister.
+type S3VolumeInventoryListerExpansion interface{}
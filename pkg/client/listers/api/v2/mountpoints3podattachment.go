@@ -0,0 +1,32 @@
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v2
+
+import (
+	apiv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	labels "k8s.io/apimachinery/pkg/labels"
+	listers "k8s.io/client-go/listers"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// MountpointS3PodAttachmentLister helps list MountpointS3PodAttachments.
+// All objects returned here must be treated as read-only.
+type MountpointS3PodAttachmentLister interface {
+	// List lists all MountpointS3PodAttachments in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*apiv2.MountpointS3PodAttachment, err error)
+	// Get retrieves the MountpointS3PodAttachment from the index for a given name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*apiv2.MountpointS3PodAttachment, error)
+	MountpointS3PodAttachmentListerExpansion
+}
+
+// mountpointS3PodAttachmentLister implements the MountpointS3PodAttachmentLister interface.
+type mountpointS3PodAttachmentLister struct {
+	listers.ResourceIndexer[*apiv2.MountpointS3PodAttachment]
+}
+
+// NewMountpointS3PodAttachmentLister returns a new MountpointS3PodAttachmentLister.
+func NewMountpointS3PodAttachmentLister(indexer cache.Indexer) MountpointS3PodAttachmentLister {
+	return &mountpointS3PodAttachmentLister{listers.New[*apiv2.MountpointS3PodAttachment](indexer, apiv2.Resource("mountpoints3podattachment"))}
+}
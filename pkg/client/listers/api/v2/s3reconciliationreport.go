@@ -0,0 +1,32 @@
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v2
+
+import (
+	apiv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	labels "k8s.io/apimachinery/pkg/labels"
+	listers "k8s.io/client-go/listers"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// S3ReconciliationReportLister helps list S3ReconciliationReports.
+// All objects returned here must be treated as read-only.
+type S3ReconciliationReportLister interface {
+	// List lists all S3ReconciliationReports in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*apiv2.S3ReconciliationReport, err error)
+	// Get retrieves the S3ReconciliationReport from the index for a given name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*apiv2.S3ReconciliationReport, error)
+	S3ReconciliationReportListerExpansion
+}
+
+// s3ReconciliationReportLister implements the S3ReconciliationReportLister interface.
+type s3ReconciliationReportLister struct {
+	listers.ResourceIndexer[*apiv2.S3ReconciliationReport]
+}
+
+// NewS3ReconciliationReportLister returns a new S3ReconciliationReportLister.
+func NewS3ReconciliationReportLister(indexer cache.Indexer) S3ReconciliationReportLister {
+	return &s3ReconciliationReportLister{listers.New[*apiv2.S3ReconciliationReport](indexer, apiv2.Resource("s3reconciliationreport"))}
+}
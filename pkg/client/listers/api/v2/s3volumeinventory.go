@@ -0,0 +1,32 @@
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v2
+
+import (
+	apiv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	labels "k8s.io/apimachinery/pkg/labels"
+	listers "k8s.io/client-go/listers"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// S3VolumeInventoryLister helps list S3VolumeInventories.
+// All objects returned here must be treated as read-only.
+type S3VolumeInventoryLister interface {
+	// List lists all S3VolumeInventories in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*apiv2.S3VolumeInventory, err error)
+	// Get retrieves the S3VolumeInventory from the index for a given name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*apiv2.S3VolumeInventory, error)
+	S3VolumeInventoryListerExpansion
+}
+
+// s3VolumeInventoryLister implements the S3VolumeInventoryLister interface.
+type s3VolumeInventoryLister struct {
+	listers.ResourceIndexer[*apiv2.S3VolumeInventory]
+}
+
+// NewS3VolumeInventoryLister returns a new S3VolumeInventoryLister.
+func NewS3VolumeInventoryLister(indexer cache.Indexer) S3VolumeInventoryLister {
+	return &s3VolumeInventoryLister{listers.New[*apiv2.S3VolumeInventory](indexer, apiv2.Resource("s3volumeinventory"))}
+}
@@ -0,0 +1,169 @@
+// Package consistency provides utilities to verify that a Mountpoint mount shows the same entries as a direct S3
+// listing of the mounted bucket and prefix.
+//
+// Mounted directories are listed by `scality-csi-checker` running on the node, which reports its [Listing] through
+// the container's termination message. S3 is listed directly by the controller, and both are then compared with
+// [Compare].
+package consistency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// MaxMessageSize is the maximum size of a container termination message, as enforced by kubelet.
+const MaxMessageSize = 4096
+
+// A Listing is the result of listing a directory through a mount.
+type Listing struct {
+	// Entries are names of the files and directories listed.
+	Entries []string `json:"entries,omitempty"`
+	// Truncated is true if the directory contained more entries than requested or they could not be reported.
+	Truncated bool `json:"truncated,omitempty"`
+	// Error is set if the directory could not be listed.
+	Error string `json:"error,omitempty"`
+}
+
+// ListDir lists up to `maxEntries` entries of the directory at `path`.
+func ListDir(path string, maxEntries int) Listing {
+	dir, err := os.Open(path)
+	if err != nil {
+		return Listing{Error: err.Error()}
+	}
+	defer dir.Close()
+
+	// Read one extra entry to know if the directory has more than `maxEntries`
+	entries, err := dir.ReadDir(maxEntries + 1)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return Listing{Error: err.Error()}
+	}
+
+	listing := Listing{Entries: make([]string, 0, len(entries))}
+	for _, entry := range entries {
+		listing.Entries = append(listing.Entries, entry.Name())
+	}
+	if len(listing.Entries) > maxEntries {
+		listing.Entries = listing.Entries[:maxEntries]
+		listing.Truncated = true
+	}
+	return listing
+}
+
+// Encode encodes `listing` to fit in a termination message.
+// Entries are dropped and the listing is marked as truncated if it does not fit in [MaxMessageSize].
+func Encode(listing Listing) []byte {
+	data, err := json.Marshal(listing)
+	if err == nil && len(data) <= MaxMessageSize {
+		return data
+	}
+	if err != nil {
+		listing.Error = err.Error()
+	}
+	listing.Entries = nil
+	listing.Truncated = true
+	data, _ = json.Marshal(listing)
+	return data
+}
+
+// Decode decodes a listing encoded with [Encode].
+func Decode(data string) (Listing, error) {
+	var listing Listing
+	err := json.Unmarshal([]byte(data), &listing)
+	return listing, err
+}
+
+// An Entry is a file or a directory listed directly from S3.
+type Entry struct {
+	// Name of the entry relative to the listed prefix, without trailing `/` for directories.
+	Name string
+	// LastModified is the last modification time of the object, zero for directories.
+	LastModified time.Time
+}
+
+// ListS3 lists up to `maxEntries` files and directories under `prefix` in `bucket`, as Mountpoint would show them
+// at the root of a mount with `--prefix` set to `prefix`. It returns whether there were more entries.
+func ListS3(ctx context.Context, client s3.ListObjectsV2APIClient, bucket, prefix string, maxEntries int) ([]Entry, bool, error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket:    aws.String(bucket),
+		Delimiter: aws.String("/"),
+		MaxKeys:   aws.Int32(int32(maxEntries + 1)),
+	}
+	if prefix != "" {
+		input.Prefix = aws.String(prefix)
+	}
+
+	output, err := client.ListObjectsV2(ctx, input)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var entries []Entry
+	for _, commonPrefix := range output.CommonPrefixes {
+		name := strings.TrimSuffix(strings.TrimPrefix(aws.ToString(commonPrefix.Prefix), prefix), "/")
+		if name != "" {
+			entries = append(entries, Entry{Name: name})
+		}
+	}
+	for _, object := range output.Contents {
+		name := strings.TrimPrefix(aws.ToString(object.Key), prefix)
+		if name == "" {
+			// Directory marker of the prefix itself
+			continue
+		}
+		entries = append(entries, Entry{Name: name, LastModified: aws.ToTime(object.LastModified)})
+	}
+
+	truncated := aws.ToBool(output.IsTruncated) || len(entries) > maxEntries
+	return entries, truncated, nil
+}
+
+// A Divergence describes differences between a mount and S3.
+type Divergence struct {
+	// MissingInMount are entries listed in S3 but not shown by the mount.
+	MissingInMount []string
+	// MissingInS3 are entries shown by the mount but not listed in S3.
+	MissingInS3 []string
+}
+
+// Empty returns whether the mount and S3 showed the same entries.
+func (d Divergence) Empty() bool {
+	return len(d.MissingInMount) == 0 && len(d.MissingInS3) == 0
+}
+
+// Compare compares entries shown by a mount with entries listed in S3.
+// Objects modified after `settledBefore` are ignored if they are not shown by the mount yet,
+// as the mount might have been listed before they were created.
+func Compare(mountEntries []string, s3Entries []Entry, settledBefore time.Time) Divergence {
+	var divergence Divergence
+
+	inS3 := make(map[string]bool, len(s3Entries))
+	for _, entry := range s3Entries {
+		inS3[entry.Name] = true
+	}
+	inMount := make(map[string]bool, len(mountEntries))
+	for _, name := range mountEntries {
+		inMount[name] = true
+		if !inS3[name] {
+			divergence.MissingInS3 = append(divergence.MissingInS3, name)
+		}
+	}
+	for _, entry := range s3Entries {
+		if inMount[entry.Name] || entry.LastModified.After(settledBefore) {
+			continue
+		}
+		divergence.MissingInMount = append(divergence.MissingInMount, entry.Name)
+	}
+
+	slices.Sort(divergence.MissingInMount)
+	slices.Sort(divergence.MissingInS3)
+	return divergence
+}
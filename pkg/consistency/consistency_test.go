@@ -0,0 +1,130 @@
+package consistency_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/consistency"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestListDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o644))
+	}
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "c"), 0o755))
+
+	listing := consistency.ListDir(dir, 3)
+	assert.Equals(t, "", listing.Error)
+	assert.Equals(t, false, listing.Truncated)
+	assert.Equals(t, 3, len(listing.Entries))
+
+	listing = consistency.ListDir(dir, 2)
+	assert.Equals(t, true, listing.Truncated)
+	assert.Equals(t, 2, len(listing.Entries))
+
+	listing = consistency.ListDir(filepath.Join(dir, "missing"), 2)
+	if listing.Error == "" {
+		t.Fatal("Expected an error listing a missing directory")
+	}
+}
+
+func TestEncode(t *testing.T) {
+	listing := consistency.Listing{Entries: []string{"a", "b"}}
+	decoded, err := consistency.Decode(string(consistency.Encode(listing)))
+	assert.NoError(t, err)
+	assert.Equals(t, listing, decoded)
+
+	// Entries not fitting in a termination message are dropped
+	listing = consistency.Listing{Entries: []string{strings.Repeat("a", consistency.MaxMessageSize)}}
+	data := consistency.Encode(listing)
+	if len(data) > consistency.MaxMessageSize {
+		t.Fatalf("Expected encoded listing to fit in %d bytes, got %d", consistency.MaxMessageSize, len(data))
+	}
+	decoded, err = consistency.Decode(string(data))
+	assert.NoError(t, err)
+	assert.Equals(t, consistency.Listing{Truncated: true}, decoded)
+}
+
+type mockListObjectsV2 struct {
+	output *s3.ListObjectsV2Output
+	input  *s3.ListObjectsV2Input
+}
+
+func (m *mockListObjectsV2) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	m.input = params
+	return m.output, nil
+}
+
+func TestListS3(t *testing.T) {
+	modified := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	client := &mockListObjectsV2{output: &s3.ListObjectsV2Output{
+		CommonPrefixes: []types.CommonPrefix{{Prefix: aws.String("data/dir/")}},
+		Contents: []types.Object{
+			{Key: aws.String("data/"), LastModified: aws.Time(modified)},
+			{Key: aws.String("data/file.txt"), LastModified: aws.Time(modified)},
+		},
+	}}
+
+	entries, truncated, err := consistency.ListS3(context.Background(), client, "bucket", "data/", 10)
+	assert.NoError(t, err)
+	assert.Equals(t, false, truncated)
+	assert.Equals(t, []consistency.Entry{{Name: "dir"}, {Name: "file.txt", LastModified: modified}}, entries)
+	assert.Equals(t, "/", aws.ToString(client.input.Delimiter))
+	assert.Equals(t, "data/", aws.ToString(client.input.Prefix))
+	assert.Equals(t, int32(11), aws.ToInt32(client.input.MaxKeys))
+
+	client.output.IsTruncated = aws.Bool(true)
+	_, truncated, err = consistency.ListS3(context.Background(), client, "bucket", "data/", 10)
+	assert.NoError(t, err)
+	assert.Equals(t, true, truncated)
+}
+
+func TestCompare(t *testing.T) {
+	now := time.Now()
+	settledBefore := now.Add(-time.Minute)
+
+	tests := []struct {
+		name         string
+		mountEntries []string
+		s3Entries    []consistency.Entry
+		want         consistency.Divergence
+	}{
+		{
+			name:         "consistent",
+			mountEntries: []string{"dir", "file"},
+			s3Entries:    []consistency.Entry{{Name: "file", LastModified: now.Add(-time.Hour)}, {Name: "dir"}},
+		},
+		{
+			name:         "divergent",
+			mountEntries: []string{"dir", "deleted"},
+			s3Entries:    []consistency.Entry{{Name: "dir"}, {Name: "missing", LastModified: now.Add(-time.Hour)}},
+			want: consistency.Divergence{
+				MissingInMount: []string{"missing"},
+				MissingInS3:    []string{"deleted"},
+			},
+		},
+		{
+			name:         "recently modified objects are ignored",
+			mountEntries: []string{},
+			s3Entries:    []consistency.Entry{{Name: "new", LastModified: now}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := consistency.Compare(tt.mountEntries, tt.s3Entries, settledBefore)
+			assert.Equals(t, tt.want, got)
+			assert.Equals(t, tt.want.Empty(), got.Empty())
+		})
+	}
+}
@@ -23,6 +23,12 @@ const (
 	NodePublishSecretNameKey      = "csi.storage.k8s.io/node-publish-secret-name"
 	NodePublishSecretNamespaceKey = "csi.storage.k8s.io/node-publish-secret-namespace"
 
+	// CSI external-provisioner metadata parameters (for controller operations)
+	// These are only passed in CreateVolume parameters when the provisioner runs with `--extra-create-metadata`
+	PVCNameKey      = "csi.storage.k8s.io/pvc/name"
+	PVCNamespaceKey = "csi.storage.k8s.io/pvc/namespace"
+	PVNameKey       = "csi.storage.k8s.io/pv/name"
+
 	// Volume context keys for storing credential metadata
 	// Used to pass credential information from controller to node
 	VolumeContextProvisionerSecretNameKey      = "provisioner-secret-name"
@@ -0,0 +1,130 @@
+// Package conformance reports the CSI capabilities, volume attributes and mount options supported by this build
+// of the driver. The report is built from the registrations the driver serves, so it cannot drift from the code.
+package conformance
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/storageclass"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/version"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
+)
+
+// Report lists what this build of the driver supports.
+type Report struct {
+	DriverName    string `json:"driverName"`
+	DriverVersion string `json:"driverVersion,omitempty"`
+	GitCommit     string `json:"gitCommit,omitempty"`
+
+	PluginCapabilities     []string `json:"pluginCapabilities"`
+	ControllerCapabilities []string `json:"controllerCapabilities"`
+	// NodeCapabilities are advertised by node plugins mounting volumes with Mountpoint Pods.
+	NodeCapabilities []string `json:"nodeCapabilities"`
+	// OptionalNodeCapabilities are only advertised when enabled in the node plugin configuration.
+	OptionalNodeCapabilities []string `json:"optionalNodeCapabilities"`
+	AccessModes              []string `json:"accessModes"`
+
+	VolumeAttributes       []volumecontext.Attribute `json:"volumeAttributes"`
+	StorageClassParameters []string                  `json:"storageClassParameters"`
+	MountOptions           []mountpoint.SupportedArg `json:"mountOptions"`
+}
+
+// Build returns the report of this build of the driver.
+func Build() Report {
+	nodeCaps := node.NodeCapabilities(true, false, false, false)
+	report := Report{
+		DriverName:               constants.DriverName,
+		DriverVersion:            version.GetVersion().DriverVersion,
+		GitCommit:                version.GetVersion().GitCommit,
+		PluginCapabilities:       names(driver.PluginCapabilities()),
+		ControllerCapabilities:   names(driver.ControllerCapabilities()),
+		NodeCapabilities:         names(nodeCaps),
+		AccessModes:              names(driver.SupportedAccessModes()),
+		VolumeAttributes:         volumecontext.Attributes(),
+		StorageClassParameters:   storageclass.SupportedParameters(),
+		MountOptions:             mountpoint.SupportedArgs(),
+		OptionalNodeCapabilities: []string{},
+	}
+	for _, cap := range node.NodeCapabilities(true, true, true, true) {
+		if !slices.Contains(nodeCaps, cap) {
+			report.OptionalNodeCapabilities = append(report.OptionalNodeCapabilities, cap.String())
+		}
+	}
+	return report
+}
+
+// WriteMarkdown writes the report as a Markdown document.
+func (r Report) WriteMarkdown(w io.Writer) error {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "# Supported CSI Capabilities and Options\n\n")
+	fmt.Fprintf(b, "<!-- Generated by `make conformance-docs` from the driver code, do not edit. -->\n\n")
+	fmt.Fprintf(b, "This page lists the CSI capabilities, volume attributes and mount options supported by the `%s` driver.\n", r.DriverName)
+	fmt.Fprintf(b, "Run `scality-csi-admin conformance --format json` for a machine-readable version, the report of a release is also available at `/conformance.json` in its image.\n")
+	if r.DriverVersion != "" {
+		fmt.Fprintf(b, "\nDriver version: `%s` (commit `%s`).\n", r.DriverVersion, r.GitCommit)
+	}
+
+	fmt.Fprintf(b, "\n## CSI Capabilities\n\n")
+	fmt.Fprintf(b, "| Service | Capability |\n|---------|------------|\n")
+	for _, section := range []struct {
+		service string
+		caps    []string
+	}{
+		{"Identity", r.PluginCapabilities},
+		{"Controller", r.ControllerCapabilities},
+		{"Node", r.NodeCapabilities},
+		{"Node (optional)", r.OptionalNodeCapabilities},
+		{"Access modes", r.AccessModes},
+	} {
+		for _, cap := range section.caps {
+			fmt.Fprintf(b, "| %s | `%s` |\n", section.service, cap)
+		}
+	}
+
+	fmt.Fprintf(b, "\n## Volume Attributes\n\n")
+	fmt.Fprintf(b, "| Attribute | Description | Inline ephemeral volumes | Deprecation |\n|-----------|-------------|--------------------------|-------------|\n")
+	for _, a := range r.VolumeAttributes {
+		fmt.Fprintf(b, "| `%s` | %s | %s | %s |\n", a.Key, a.Description, yesNo(a.Ephemeral), a.Deprecated)
+	}
+
+	fmt.Fprintf(b, "\n## StorageClass Parameters\n\n")
+	for _, param := range r.StorageClassParameters {
+		fmt.Fprintf(b, "- `%s`\n", param)
+	}
+
+	fmt.Fprintf(b, "\n## Mount Options\n\n")
+	fmt.Fprintf(b, "Options added after Mountpoint 1.0.0 are removed from mount options on older releases if they only tune performance, other ones fail the mount with an `UNSUPPORTED_OPTION` error.\n\n")
+	fmt.Fprintf(b, "| Option | Takes a value | Ignored by the driver | Minimum Mountpoint version |\n|--------|---------------|-----------------------|----------------------------|\n")
+	for _, arg := range r.MountOptions {
+		since := arg.Since
+		if since != "" && arg.StrippedBefore {
+			since += " (removed before)"
+		}
+		fmt.Fprintf(b, "| `%s` | %s | %s | %s |\n", strings.TrimPrefix(arg.Key, "--"), yesNo(arg.TakesValue), arg.Ignored, since)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func names[T fmt.Stringer](values []T) []string {
+	names := make([]string, 0, len(values))
+	for _, v := range values {
+		names = append(names, v.String())
+	}
+	return names
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "Yes"
+	}
+	return "No"
+}
@@ -0,0 +1,48 @@
+package conformance_test
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/conformance"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
+)
+
+func TestBuild(t *testing.T) {
+	report := conformance.Build()
+
+	if !slices.Contains(report.ControllerCapabilities, "CREATE_DELETE_VOLUME") {
+		t.Errorf("Expected CREATE_DELETE_VOLUME controller capability, got %v", report.ControllerCapabilities)
+	}
+	if !slices.Contains(report.OptionalNodeCapabilities, "GET_VOLUME_STATS") || slices.Contains(report.NodeCapabilities, "GET_VOLUME_STATS") {
+		t.Errorf("Expected GET_VOLUME_STATS to be an optional node capability, got %v and %v", report.NodeCapabilities, report.OptionalNodeCapabilities)
+	}
+	if !slices.ContainsFunc(report.VolumeAttributes, func(a volumecontext.Attribute) bool { return a.Key == volumecontext.BucketName }) {
+		t.Errorf("Expected %s volume attribute, got %v", volumecontext.BucketName, report.VolumeAttributes)
+	}
+	i := slices.IndexFunc(report.MountOptions, func(a mountpoint.SupportedArg) bool { return a.Key == mountpoint.ArgProfile })
+	if i < 0 || !report.MountOptions[i].TakesValue || report.MountOptions[i].Ignored == "" {
+		t.Errorf("Expected %s mount option to take a value and be ignored, got %v", mountpoint.ArgProfile, report.MountOptions)
+	}
+}
+
+// TestDocsUpToDate fails if the conformance documentation is out of date, run `make conformance-docs` to update it.
+func TestDocsUpToDate(t *testing.T) {
+	docs, err := os.ReadFile("../../../docs/concepts-and-reference/conformance.md")
+	if err != nil {
+		t.Fatalf("Failed to read the conformance documentation: %v", err)
+	}
+
+	report := conformance.Build()
+	report.DriverVersion, report.GitCommit = "", ""
+	want := &strings.Builder{}
+	if err := report.WriteMarkdown(want); err != nil {
+		t.Fatalf("Failed to write the conformance report: %v", err)
+	}
+	if string(docs) != want.String() {
+		t.Errorf("docs/concepts-and-reference/conformance.md is out of date, run `make conformance-docs` to update it")
+	}
+}
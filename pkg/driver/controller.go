@@ -19,6 +19,8 @@ package driver
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -28,6 +30,8 @@ import (
 	"github.com/kubernetes-csi/csi-lib-utils/protosanitizer"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
@@ -56,6 +60,7 @@ func (d *Driver) CreateVolume(ctx context.Context, req *csi.CreateVolumeRequest)
 		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("failed to parse StorageClass parameters: %v", err))
 	}
 	klog.V(4).Infof("CreateVolume: parsed parameters - HasProvisionerSecret: %v, HasNodePublishSecret: %v", params.HasProvisionerSecret(), params.HasNodePublishSecret())
+	d.reportIgnoredParameters(ctx, req.GetParameters(), params.Warnings)
 
 	volumeID := generateVolumeID()
 	klog.V(4).Infof("Generated volume ID: %s", volumeID)
@@ -92,14 +97,51 @@ func (d *Driver) CreateVolume(ctx context.Context, req *csi.CreateVolumeRequest)
 		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to create S3 client: %v", err))
 	}
 
-	if err := s3Client.CreateBucket(ctx, volumeID); err != nil {
+	if err := s3Client.CreateBucket(ctx, volumeID, params.Bucket); err != nil {
 		klog.Errorf("CreateVolume: bucket creation failed for volume %s: %v", volumeID, err)
+		// Do not leave behind a bucket without the requested protection, retries create another bucket
+		if deleteErr := s3Client.DeleteBucket(ctx, volumeID); deleteErr != nil {
+			klog.Warningf("CreateVolume: failed to delete bucket %s after failed creation: %v", volumeID, deleteErr)
+		}
 		return nil, status.Error(codes.Internal, fmt.Sprintf("bucket creation failed: %v", err))
 	}
 
 	volumeContext := map[string]string{
-		"dynamicProvisioning": "true",
-		"bucketName":          volumeID,
+		volumecontext.DynamicProvisioning: "true",
+		"bucketName":                      volumeID,
+	}
+
+	// Mountpoint Pod resources, cache and options are read by the controller from the volume attributes of the PV, server-side
+	// encryption by the node plugin
+	for key, value := range params.MountpointContainerResources {
+		volumeContext[key] = value
+	}
+	for key, value := range params.MountpointCache {
+		volumeContext[key] = value
+	}
+	for key, value := range params.MountpointPodOptions {
+		volumeContext[key] = value
+	}
+	for key, value := range params.Encryption {
+		volumeContext[key] = value
+	}
+
+	// PVC Metadata Propagation
+	//
+	// Allow-listed PVC labels/annotations are copied into the volume context (and thus onto the PV)
+	// and as bucket tags, so governance tooling can follow business metadata through the storage chain.
+	// Propagation is best-effort: failures are logged and do not fail the provisioning.
+	pvcMetadata, err := d.propagatedPVCMetadata(ctx, req.GetParameters())
+	if err != nil {
+		klog.Warningf("CreateVolume: failed to resolve PVC metadata to propagate for volume %s: %v", volumeID, err)
+	}
+	for key, value := range pvcMetadata {
+		volumeContext[volumecontext.PVCMetadataPrefix+key] = value
+	}
+	if tags := bucketTagsFromPVCMetadata(pvcMetadata); len(tags) > 0 {
+		if err := s3Client.PutBucketTagging(ctx, volumeID, tags); err != nil {
+			klog.Warningf("CreateVolume: failed to propagate PVC metadata as bucket tags for volume %s: %v", volumeID, err)
+		}
 	}
 
 	// Authentication Source Configuration for Dynamic Provisioning
@@ -154,6 +196,32 @@ func (d *Driver) CreateVolume(ctx context.Context, req *csi.CreateVolumeRequest)
 	}, nil
 }
 
+// ReasonIgnoredStorageClassParameters is the reason of events recorded on claims provisioned with StorageClass
+// parameters the driver does not know, most likely misspelled.
+const ReasonIgnoredStorageClassParameters = "IgnoredStorageClassParameters"
+
+// reportIgnoredParameters logs `warnings` about ignored StorageClass parameters, and records them as an event on the
+// claim being provisioned, identified with the parameters the external-provisioner adds with `--extra-create-metadata`.
+func (d *Driver) reportIgnoredParameters(ctx context.Context, parameters map[string]string, warnings []string) {
+	if len(warnings) == 0 {
+		return
+	}
+	for _, warning := range warnings {
+		klog.Warningf("CreateVolume: StorageClass %s", warning)
+	}
+
+	name, namespace := parameters[constants.PVCNameKey], parameters[constants.PVCNamespaceKey]
+	if d.events == nil || d.Clientset == nil || name == "" || namespace == "" {
+		return
+	}
+	pvc, err := d.Clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		klog.Warningf("CreateVolume: failed to get PVC %s/%s to report ignored StorageClass parameters: %v", namespace, name, err)
+		return
+	}
+	d.events.Eventf(pvc, corev1.EventTypeWarning, ReasonIgnoredStorageClassParameters, "StorageClass parameters: %s", strings.Join(warnings, "; "))
+}
+
 func (d *Driver) DeleteVolume(ctx context.Context, req *csi.DeleteVolumeRequest) (*csi.DeleteVolumeResponse, error) {
 	klog.V(4).Infof("DeleteVolume: called with args: %s", protosanitizer.StripSecrets(req))
 
@@ -207,13 +275,20 @@ func (d *Driver) ControllerUnpublishVolume(ctx context.Context, req *csi.Control
 	return nil, status.Error(codes.Unimplemented, "")
 }
 
+// controllerCaps are the capabilities of the controller service.
+var controllerCaps = []csi.ControllerServiceCapability_RPC_Type{
+	csi.ControllerServiceCapability_RPC_CREATE_DELETE_VOLUME,
+}
+
+// ControllerCapabilities returns the capabilities advertised by the controller service.
+func ControllerCapabilities() []csi.ControllerServiceCapability_RPC_Type {
+	return slices.Clone(controllerCaps)
+}
+
 func (d *Driver) ControllerGetCapabilities(ctx context.Context, req *csi.ControllerGetCapabilitiesRequest) (*csi.ControllerGetCapabilitiesResponse, error) {
 	klog.V(4).Infof("ControllerGetCapabilities: called with args %s", protosanitizer.StripSecrets(req))
-	caps := []csi.ControllerServiceCapability_RPC_Type{
-		csi.ControllerServiceCapability_RPC_CREATE_DELETE_VOLUME,
-	}
 	var capsResponse []*csi.ControllerServiceCapability
-	for _, cap := range caps {
+	for _, cap := range controllerCaps {
 		c := &csi.ControllerServiceCapability{
 			Type: &csi.ControllerServiceCapability_Rpc{
 				Rpc: &csi.ControllerServiceCapability_RPC{
@@ -236,9 +311,32 @@ func (d *Driver) ListVolumes(ctx context.Context, req *csi.ListVolumesRequest) (
 	return nil, status.Error(codes.Unimplemented, "")
 }
 
+// ValidateVolumeCapabilities confirms `req.VolumeCapabilities` if they are all supported by S3 volumes, see
+// [validateVolumeCapability]. Whether the bucket of the volume exists is not checked, as it would require
+// the credentials of the volume.
 func (d *Driver) ValidateVolumeCapabilities(ctx context.Context, req *csi.ValidateVolumeCapabilitiesRequest) (*csi.ValidateVolumeCapabilitiesResponse, error) {
 	klog.V(4).Infof("ValidateVolumeCapabilities: called with args %s", protosanitizer.StripSecrets(req))
-	return &csi.ValidateVolumeCapabilitiesResponse{}, nil
+
+	if req.GetVolumeId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Volume ID not provided")
+	}
+	if len(req.GetVolumeCapabilities()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Volume capabilities not provided")
+	}
+
+	for _, cap := range req.GetVolumeCapabilities() {
+		if err := validateVolumeCapability(cap); err != nil {
+			return &csi.ValidateVolumeCapabilitiesResponse{Message: err.Error()}, nil
+		}
+	}
+
+	return &csi.ValidateVolumeCapabilitiesResponse{
+		Confirmed: &csi.ValidateVolumeCapabilitiesResponse_Confirmed{
+			VolumeContext:      req.GetVolumeContext(),
+			VolumeCapabilities: req.GetVolumeCapabilities(),
+			Parameters:         req.GetParameters(),
+		},
+	}, nil
 }
 
 func (d *Driver) CreateSnapshot(ctx context.Context, req *csi.CreateSnapshotRequest) (*csi.CreateSnapshotResponse, error) {
@@ -280,20 +378,40 @@ func validateCreateVolumeRequest(req *csi.CreateVolumeRequest) error {
 	}
 
 	for _, cap := range req.GetVolumeCapabilities() {
-		if cap.GetAccessMode() == nil {
-			return fmt.Errorf("volume capability access mode is required")
-		}
-		mode := cap.GetAccessMode().GetMode()
-		// S3 is naturally multi-node read-write storage
-		// ReadOnlyMany behavior is achieved via mount options, not access modes
-		// Only support ReadWriteMany access mode - use mount options for read-only behavior
-		if mode != csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER {
-			return fmt.Errorf("S3 volumes only support ReadWriteMany access mode, got %v. Use mount options for read-only behavior", mode)
+		if err := validateVolumeCapability(cap); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
+// supportedAccessModes are the access modes of S3 volumes. S3 is naturally multi-node read-write storage,
+// ReadOnlyMany volumes are always mounted read-only by the node plugin.
+var supportedAccessModes = []csi.VolumeCapability_AccessMode_Mode{
+	csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+	csi.VolumeCapability_AccessMode_MULTI_NODE_READER_ONLY,
+}
+
+// SupportedAccessModes returns the access modes of S3 volumes.
+func SupportedAccessModes() []csi.VolumeCapability_AccessMode_Mode {
+	return slices.Clone(supportedAccessModes)
+}
+
+// validateVolumeCapability returns an error if `cap` is not supported by S3 volumes.
+func validateVolumeCapability(cap *csi.VolumeCapability) error {
+	if cap.GetAccessMode() == nil {
+		return fmt.Errorf("volume capability access mode is required")
+	}
+	if cap.GetBlock() != nil {
+		return fmt.Errorf("S3 volumes do not support block access type")
+	}
+	mode := cap.GetAccessMode().GetMode()
+	if !slices.Contains(supportedAccessModes, mode) {
+		return fmt.Errorf("S3 volumes only support ReadWriteMany and ReadOnlyMany access modes, got %v", mode)
+	}
+	return nil
+}
+
 func validateDeleteVolumeRequest(req *csi.DeleteVolumeRequest) error {
 	if req == nil {
 		return fmt.Errorf("request is nil")
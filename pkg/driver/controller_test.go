@@ -15,6 +15,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
 
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
 	controllerCredProvider "github.com/scality/mountpoint-s3-csi-driver/pkg/driver/controller/credentialprovider"
@@ -23,13 +24,14 @@ import (
 
 // Mock S3 client for testing
 type mockS3Client struct {
-	createBucketFunc func(ctx context.Context, bucket string) error
+	createBucketFunc func(ctx context.Context, bucket string, opts s3client.BucketOptions) error
 	deleteBucketFunc func(ctx context.Context, bucket string) error
+	putTaggingFunc   func(ctx context.Context, bucket string, tags map[string]string) error
 }
 
-func (m *mockS3Client) CreateBucket(ctx context.Context, bucket string) error {
+func (m *mockS3Client) CreateBucket(ctx context.Context, bucket string, opts s3client.BucketOptions) error {
 	if m.createBucketFunc != nil {
-		return m.createBucketFunc(ctx, bucket)
+		return m.createBucketFunc(ctx, bucket, opts)
 	}
 	return nil
 }
@@ -41,6 +43,13 @@ func (m *mockS3Client) DeleteBucket(ctx context.Context, bucket string) error {
 	return nil
 }
 
+func (m *mockS3Client) PutBucketTagging(ctx context.Context, bucket string, tags map[string]string) error {
+	if m.putTaggingFunc != nil {
+		return m.putTaggingFunc(ctx, bucket, tags)
+	}
+	return nil
+}
+
 func TestCreateVolume(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -134,7 +143,7 @@ func TestCreateVolume(t *testing.T) {
 			errorContains: "provisioner secret name provided but namespace is missing",
 		},
 		{
-			name: "unsupported access mode - ReadOnlyMany should use mount options",
+			name: "ReadOnlyMany access mode",
 			req: &csi.CreateVolumeRequest{
 				Name: "test-volume-readonly-access-mode",
 				VolumeCapabilities: []*csi.VolumeCapability{
@@ -145,8 +154,22 @@ func TestCreateVolume(t *testing.T) {
 					},
 				},
 			},
+			expectedError: codes.OK,
+		},
+		{
+			name: "unsupported access mode",
+			req: &csi.CreateVolumeRequest{
+				Name: "test-volume-single-writer-access-mode",
+				VolumeCapabilities: []*csi.VolumeCapability{
+					{
+						AccessMode: &csi.VolumeCapability_AccessMode{
+							Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER,
+						},
+					},
+				},
+			},
 			expectedError: codes.InvalidArgument,
-			errorContains: "S3 volumes only support ReadWriteMany access mode",
+			errorContains: "S3 volumes only support ReadWriteMany and ReadOnlyMany access modes",
 		},
 		{
 			name: "missing volume capability access mode",
@@ -183,6 +206,60 @@ func TestCreateVolume(t *testing.T) {
 			},
 			expectedError: codes.OK,
 		},
+		{
+			name: "with Mountpoint Pod resources",
+			req: &csi.CreateVolumeRequest{
+				Name: "test-volume-resources",
+				Parameters: map[string]string{
+					"mountpointContainerResourcesRequestsMemory": "2Gi",
+					"mountpointContainerResourcesLimitsMemory":   "4Gi",
+				},
+				VolumeCapabilities: []*csi.VolumeCapability{
+					{
+						AccessMode: &csi.VolumeCapability_AccessMode{
+							Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+						},
+					},
+				},
+			},
+			expectedError: codes.OK,
+		},
+		{
+			name: "with Mountpoint cache",
+			req: &csi.CreateVolumeRequest{
+				Name: "test-volume-cache",
+				Parameters: map[string]string{
+					"cache":          "ephemeralPVC",
+					"cacheSizeLimit": "10Gi",
+				},
+				VolumeCapabilities: []*csi.VolumeCapability{
+					{
+						AccessMode: &csi.VolumeCapability_AccessMode{
+							Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+						},
+					},
+				},
+			},
+			expectedError: codes.OK,
+		},
+		{
+			name: "with invalid Mountpoint Pod resources",
+			req: &csi.CreateVolumeRequest{
+				Name: "test-volume-invalid-resources",
+				Parameters: map[string]string{
+					"mountpointContainerResourcesLimitsMemory": "lots",
+				},
+				VolumeCapabilities: []*csi.VolumeCapability{
+					{
+						AccessMode: &csi.VolumeCapability_AccessMode{
+							Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+						},
+					},
+				},
+			},
+			expectedError: codes.InvalidArgument,
+			errorContains: "invalid quantity",
+		},
 	}
 
 	for _, tc := range tests {
@@ -277,11 +354,195 @@ func TestCreateVolume(t *testing.T) {
 					t.Fatalf("Expected authenticationSource %q, got %q",
 						expectedAuthSource, resp.Volume.VolumeContext["authenticationSource"])
 				}
+
+				// Mountpoint Pod resources and cache are propagated into the volume context
+				for key, value := range tc.req.Parameters {
+					if (strings.HasPrefix(key, "mountpointContainerResources") || strings.HasPrefix(key, "cache")) && resp.Volume.VolumeContext[key] != value {
+						t.Fatalf("Expected %s %q in volume context, got %q", key, value, resp.Volume.VolumeContext[key])
+					}
+				}
 			}
 		})
 	}
 }
 
+func TestCreateVolumeBucketProtection(t *testing.T) {
+	t.Setenv("AWS_ENDPOINT_URL", "http://s3.example.com")
+	t.Setenv("AWS_REGION", "us-east-1")
+
+	req := &csi.CreateVolumeRequest{
+		Name: "test-volume",
+		Parameters: map[string]string{
+			"objectLock":    "compliance",
+			"retentionDays": "30",
+		},
+		VolumeCapabilities: []*csi.VolumeCapability{
+			{
+				AccessMode: &csi.VolumeCapability_AccessMode{
+					Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+				},
+			},
+		},
+	}
+
+	var created s3client.BucketOptions
+	var deleted []string
+	var createErr error
+	mockS3 := &mockS3Client{
+		createBucketFunc: func(ctx context.Context, bucket string, opts s3client.BucketOptions) error {
+			created = opts
+			return createErr
+		},
+		deleteBucketFunc: func(ctx context.Context, bucket string) error {
+			deleted = append(deleted, bucket)
+			return nil
+		},
+	}
+	driver := &Driver{
+		controllerCredProvider: controllerCredProvider.New(fake.NewSimpleClientset()),
+		testS3ClientFactory: func(ctx context.Context, awsConfig *aws.Config) (s3client.Client, error) {
+			return mockS3, nil
+		},
+	}
+
+	if _, err := driver.CreateVolume(context.Background(), req); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !created.Versioning || created.ObjectLock == nil || created.ObjectLock.Mode != "COMPLIANCE" || created.ObjectLock.RetentionDays != 30 {
+		t.Fatalf("Expected a versioned bucket with a 30 days compliance retention, got %+v", created)
+	}
+
+	// Buckets whose protection could not be configured are deleted
+	createErr = fmt.Errorf("object lock not supported")
+	_, err := driver.CreateVolume(context.Background(), req)
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("Expected Internal error, got %v", err)
+	}
+	if len(deleted) != 1 || !strings.HasPrefix(deleted[0], "csi-s3-") {
+		t.Fatalf("Expected the bucket to be deleted, got %v", deleted)
+	}
+}
+
+func TestCreateVolumeReportsIgnoredParameters(t *testing.T) {
+	t.Setenv("AWS_ENDPOINT_URL", "http://s3.example.com")
+	t.Setenv("AWS_REGION", "us-east-1")
+
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "team-a", UID: "pvc-uid"}}
+	clientset := fake.NewSimpleClientset(pvc)
+	events := record.NewFakeRecorder(1)
+	driver := &Driver{
+		Clientset:              clientset,
+		controllerCredProvider: controllerCredProvider.New(clientset),
+		events:                 events,
+		testS3ClientFactory: func(ctx context.Context, awsConfig *aws.Config) (s3client.Client, error) {
+			return &mockS3Client{}, nil
+		},
+	}
+
+	req := &csi.CreateVolumeRequest{
+		Name: "test-volume",
+		Parameters: map[string]string{
+			constants.PVCNameKey:      "data",
+			constants.PVCNamespaceKey: "team-a",
+			"versionning":             "enabled",
+		},
+		VolumeCapabilities: []*csi.VolumeCapability{
+			{
+				AccessMode: &csi.VolumeCapability_AccessMode{
+					Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+				},
+			},
+		},
+	}
+	if _, err := driver.CreateVolume(context.Background(), req); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := `Warning IgnoredStorageClassParameters StorageClass parameters: unknown parameter "versionning" is ignored, did you mean "versioning"?`
+	select {
+	case event := <-events.Events:
+		if event != want {
+			t.Fatalf("Expected event %q, got %q", want, event)
+		}
+	default:
+		t.Fatalf("Expected event %q, got none", want)
+	}
+
+	// Invalid values fail the provisioning
+	req.Parameters = map[string]string{"versioning": "on"}
+	if _, err := driver.CreateVolume(context.Background(), req); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected InvalidArgument error, got %v", err)
+	}
+}
+
+func TestValidateVolumeCapabilities(t *testing.T) {
+	mountCapability := func(mode csi.VolumeCapability_AccessMode_Mode) *csi.VolumeCapability {
+		return &csi.VolumeCapability{
+			AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+			AccessMode: &csi.VolumeCapability_AccessMode{Mode: mode},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		capabilities  []*csi.VolumeCapability
+		wantConfirmed bool
+	}{
+		{
+			name:          "ReadWriteMany",
+			capabilities:  []*csi.VolumeCapability{mountCapability(csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER)},
+			wantConfirmed: true,
+		},
+		{
+			name: "ReadWriteMany and ReadOnlyMany",
+			capabilities: []*csi.VolumeCapability{
+				mountCapability(csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER),
+				mountCapability(csi.VolumeCapability_AccessMode_MULTI_NODE_READER_ONLY),
+			},
+			wantConfirmed: true,
+		},
+		{
+			name:         "ReadWriteOnce",
+			capabilities: []*csi.VolumeCapability{mountCapability(csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER)},
+		},
+		{
+			name: "block access type",
+			capabilities: []*csi.VolumeCapability{{
+				AccessType: &csi.VolumeCapability_Block{Block: &csi.VolumeCapability_BlockVolume{}},
+				AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_READER_ONLY},
+			}},
+		},
+	}
+
+	d := &Driver{}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := d.ValidateVolumeCapabilities(context.Background(), &csi.ValidateVolumeCapabilitiesRequest{
+				VolumeId:           "csi-s3-test",
+				VolumeCapabilities: tc.capabilities,
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if confirmed := resp.GetConfirmed() != nil; confirmed != tc.wantConfirmed {
+				t.Fatalf("Expected confirmed=%t, got %t (message: %q)", tc.wantConfirmed, confirmed, resp.GetMessage())
+			}
+			if tc.wantConfirmed && len(resp.GetConfirmed().GetVolumeCapabilities()) != len(tc.capabilities) {
+				t.Fatalf("Expected all volume capabilities to be confirmed, got %v", resp.GetConfirmed().GetVolumeCapabilities())
+			}
+		})
+	}
+
+	t.Run("missing volume ID", func(t *testing.T) {
+		_, err := d.ValidateVolumeCapabilities(context.Background(), &csi.ValidateVolumeCapabilitiesRequest{
+			VolumeCapabilities: []*csi.VolumeCapability{mountCapability(csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER)},
+		})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("Expected InvalidArgument, got %v", err)
+		}
+	})
+}
+
 func TestGenerateVolumeID(t *testing.T) {
 	// Test multiple generations to ensure uniqueness and UUID-based format
 	generated := make(map[string]bool)
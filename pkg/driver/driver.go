@@ -27,17 +27,35 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
-	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
 	controllerCredProvider "github.com/scality/mountpoint-s3-csi-driver/pkg/driver/controller/credentialprovider"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/bucketpolicy"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/bucketregion"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/endpointfailover"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/endpointprobe"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+	nodemetrics "github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/metrics"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/mountaudit"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/mounter"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/mountreport"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/nodelabel"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/prefixmarker"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/pressure"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/problemreport"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/registration"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/scopedclient"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecondition"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumestats"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/version"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driverconfig"
 	mppodmounter "github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint/mounter"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod/watcher"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/s3client"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util"
 	"google.golang.org/grpc"
+	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	apiextensionsclientsetscheme "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/scheme"
@@ -47,8 +65,10 @@ import (
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	"k8s.io/mount-utils"
 	ctrlcache "sigs.k8s.io/controller-runtime/pkg/cache"
@@ -66,17 +86,22 @@ func init() {
 }
 
 const (
-	driverName = constants.DriverName
-
 	grpcServerMaxReceiveMessageSize = 1024 * 1024 * 2 // 2MB
 
 	unixSocketPerm = os.FileMode(0o700) // only owner can write and read.
 
 	podWatcherResyncPeriod = time.Minute
+
+	// nodeLabelStopTimeout bounds the time spent labeling the Node as not ready when the driver stops.
+	nodeLabelStopTimeout = 5 * time.Second
 )
 
 var mountpointPodNamespace = os.Getenv("MOUNTPOINT_NAMESPACE")
 
+// driverName is the name of this instance of the driver, several instances with distinct names can be installed in a
+// cluster.
+var driverName = util.DriverName()
+
 // Test seams: allow overriding external dependencies in unit tests.
 var (
 	inClusterConfigFn        = rest.InClusterConfig
@@ -158,6 +183,7 @@ func setupS3PodAttachmentCache(config *rest.Config, stopCh <-chan struct{}, node
 		klog.Info("Using `spec.nodeName` filter for caching MountpointS3PodAttachment as the cluster supports it")
 		options.ByObject = map[client.Object]ctrlcache.ByObject{
 			&crdv2.MountpointS3PodAttachment{}: {
+				Label: crdv2.DriverNameSelector(driverName),
 				Field: fields.OneTermEqualSelector("spec.nodeName", nodeID),
 			},
 		}
@@ -165,7 +191,9 @@ func setupS3PodAttachmentCache(config *rest.Config, stopCh <-chan struct{}, node
 		klog.Info("Cluster doesn't support selectable fields, falling back to client-side filtering")
 		// Client-side filtering - cache all but filter in application
 		options.ByObject = map[client.Object]ctrlcache.ByObject{
-			&crdv2.MountpointS3PodAttachment{}: {},
+			&crdv2.MountpointS3PodAttachment{}: {
+				Label: crdv2.DriverNameSelector(driverName),
+			},
 		}
 	}
 
@@ -202,6 +230,38 @@ func setupS3PodAttachmentCache(config *rest.Config, stopCh <-chan struct{}, node
 	return s3paCache
 }
 
+// scopedClientConfigs returns the configs of the clients reading Secrets and accessing MountpointS3PodAttachments.
+// They act as dedicated service accounts if scoped clients are enabled, see [scopedclient], or are `config` otherwise.
+func scopedClientConfigs(config *rest.Config, clientset kubernetes.Interface) (*rest.Config, *rest.Config, error) {
+	modeValue := os.Getenv(scopedclient.EnvMode)
+	if modeValue == "" {
+		return config, config, nil
+	}
+	mode, err := scopedclient.ParseMode(modeValue)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	scopedConfig := func(env, purpose string) (*rest.Config, error) {
+		sa, err := scopedclient.ParseServiceAccount(os.Getenv(env))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", env, err)
+		}
+		klog.Infof("Accessing %s as service account %s/%s (scoped clients mode: %s)", purpose, sa.Namespace, sa.Name, mode)
+		return scopedclient.Config(config, clientset.CoreV1(), sa, mode), nil
+	}
+
+	secretsConfig, err := scopedConfig(scopedclient.EnvSecretsServiceAccount, "Secrets")
+	if err != nil {
+		return nil, nil, err
+	}
+	attachmentsConfig, err := scopedConfig(scopedclient.EnvAttachmentsServiceAccount, "MountpointS3PodAttachments")
+	if err != nil {
+		return nil, nil, err
+	}
+	return secretsConfig, attachmentsConfig, nil
+}
+
 // checkIfMountpointS3PodAttachmentHasNodeNameSelectableFieldInCurrentVersion returns whether
 // MountpointS3PodAttachment CRD definition contains `spec.nodeName` as a `selectableField` in its current version.
 func checkIfMountpointS3PodAttachmentHasNodeNameSelectableFieldInCurrentVersion(ctx context.Context, config *rest.Config) (bool, error) {
@@ -242,11 +302,20 @@ type Driver struct {
 	// Controller credential provider for dynamic provisioning
 	controllerCredProvider *controllerCredProvider.Provider
 
+	// Allow-list of PVC label/annotation keys propagated to dynamically provisioned volumes
+	pvcMetadataPropagationKeys []string
+
 	// Test S3 client factory for dependency injection in tests.
 	// When set, this function is used instead of the real S3 client to enable
 	// mocking during unit tests, preventing real S3 API calls in unit test scenarios.
 	testS3ClientFactory func(context.Context, *aws.Config) (s3client.Client, error)
 
+	// nodeLabeler labels the Node with the readiness of the driver, nil if Node labels are disabled.
+	nodeLabeler *nodelabel.Labeler
+
+	// events records events on claims being provisioned, nil on nodes.
+	events record.EventRecorder
+
 	stopCh chan struct{}
 
 	// Embed the unimplemented servers to satisfy the interface
@@ -254,7 +323,22 @@ type Driver struct {
 	csi.UnimplementedControllerServer
 }
 
-func NewDriver(endpoint string, mpVersion string, nodeID string) (*Driver, error) {
+// Options are the settings of the node plugin set by flags of the driver. The zero value disables all of them.
+type Options struct {
+	// TelemetryTags are added to the user-agent of Mountpoint.
+	TelemetryTags mounter.TelemetryTags
+	// MaxConcurrentMounts is the number of volumes mounted at the same time, other mounts are queued. Zero disables
+	// the limit.
+	MaxConcurrentMounts int
+	// CredentialsFileDir holds credentials of volumes with `authenticationSource: file`. Disabled if empty.
+	CredentialsFileDir string
+	// ServiceAccountTokenAudience is the audience of service account tokens volumes with
+	// `authenticationSource: webIdentity` assume their role with. Defaults to
+	// [credentialprovider.DefaultServiceAccountTokenAudience] if empty.
+	ServiceAccountTokenAudience string
+}
+
+func NewDriver(endpoint string, mpVersion string, nodeID string, options Options) (*Driver, error) {
 	// Validate that AWS_ENDPOINT_URL is set
 	if os.Getenv(envprovider.EnvEndpointURL) == "" {
 		return nil, fmt.Errorf("AWS_ENDPOINT_URL environment variable must be set for the CSI driver to function")
@@ -264,12 +348,21 @@ func NewDriver(endpoint string, mpVersion string, nodeID string) (*Driver, error
 	if err != nil {
 		return nil, fmt.Errorf("cannot create in-cluster config: %w", err)
 	}
+	// Limit requests to the API server during node-wide churn, e.g. many workloads scheduled on the node at once
+	if err := util.SetKubeAPIRateLimit(config, os.Getenv(util.EnvKubeAPIQPS), os.Getenv(util.EnvKubeAPIBurst)); err != nil {
+		return nil, err
+	}
 
 	clientset, err := newKubernetesForConfigFn(config)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create kubernetes clientset: %w", err)
 	}
 
+	secretsConfig, attachmentsConfig, err := scopedClientConfigs(config, clientset)
+	if err != nil {
+		return nil, err
+	}
+
 	kubernetesVersion, err := kubernetesVersionFn(clientset)
 	if err != nil {
 		klog.Errorf("failed to get kubernetes version: %v", err)
@@ -284,12 +377,21 @@ func NewDriver(endpoint string, mpVersion string, nodeID string) (*Driver, error
 	stopCh := make(chan struct{})
 
 	var mounterImpl mounter.Mounter
+	var endpointProber *endpointprobe.Prober
+	var nodeLabeler *nodelabel.Labeler
+	var mountHealth *mounter.MountHealthChecker
+	var nodeEvents record.EventRecorder
+	var controllerEvents record.EventRecorder
 
 	// Check if running in controller-only mode
 	if os.Getenv("CSI_CONTROLLER_ONLY") == "true" {
 		klog.Infoln("Running in controller-only mode, skipping mounter initialization")
 		// No mounter needed for controller-only mode
 		mounterImpl = nil
+		// Report ignored StorageClass parameters on the claims being provisioned
+		eventBroadcaster := record.NewBroadcaster()
+		eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: clientset.CoreV1().Events("")})
+		controllerEvents = eventBroadcaster.NewRecorder(scheme, corev1.EventSource{Component: "s3-csi-controller"})
 	} else {
 		// Always use pod mounter (v2 only supports pod mounter)
 		// Pass nodeID to watcher to filter pods scheduled on this node only
@@ -301,13 +403,23 @@ func NewDriver(endpoint string, mpVersion string, nodeID string) (*Driver, error
 
 		// Setup S3PodAttachment cache - mandatory for production use
 		// Fail-fast approach: if cache cannot be created, the driver should not start
-		s3paCache := setupCacheFn(config, stopCh, nodeID, kubernetesVersion)
+		s3paCache := setupCacheFn(attachmentsConfig, stopCh, nodeID, kubernetesVersion)
 
 		// Create PodUnmounter for cleanup of dangling mounts
 		// Use the mountpoint mounter which implements the required MountInterface
 		mountpointMounter := mppodmounter.NewDefaultMounter()
 		unmounter := mounter.NewPodUnmounter(nodeID, mountpointMounter, podWatcher, credProvider)
 
+		// Also clean up mounts of Mountpoint Pods no MountpointS3PodAttachment references anymore,
+		// e.g., after the node or kubelet lost track of them, and report every cleanup
+		eventBroadcaster := record.NewBroadcaster()
+		eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: clientset.CoreV1().Events("")})
+		unmounter.SetAttachmentReader(s3paCache)
+		nodeEvents = eventBroadcaster.NewRecorder(scheme, corev1.EventSource{Component: "s3-csi-node", Host: nodeID})
+		unmounter.SetEventRecorder(nodeEvents)
+		// Clean up mounts and credentials of deleted Mountpoint Pods before they are removed
+		unmounter.SetPodClient(clientset.CoreV1().Pods(mountpointPodNamespace))
+
 		// Register event handler for immediate cleanup when pods are updated
 		// This enables immediate response to pod state changes
 		_, err = podWatcher.AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -318,32 +430,286 @@ func NewDriver(endpoint string, mpVersion string, nodeID string) (*Driver, error
 		}
 
 		// Start periodic cleanup for dangling mounts
-		// The cleanup runs every 2 minutes as defined in the pod unmounter
+		// The cleanup runs on startup, and then every 2 minutes as defined in the pod unmounter
 		go unmounter.StartPeriodicCleanup(stopCh)
 
-		mounterImpl, err = mounter.NewPodMounter(podWatcher, credProvider, mount.New(""), nil, nil, kubernetesVersion, s3paCache)
+		// Reload driver-level credentials from the mounted Secret to support key rotation without restarts
+		if dir := os.Getenv(credentialprovider.EnvDriverCredentialsDir); dir != "" {
+			interval := credentialsReloadInterval(credentialprovider.EnvDriverCredentialsReloadInterval)
+			go credProvider.WatchDriverCredentials(stopCh, dir, interval)
+		}
+
+		// Read credentials of volumes with `authenticationSource: file` from files projected into the node plugin,
+		// e.g. by the Secrets Store CSI driver, and reload them so rotated credentials are used without remounts
+		if options.CredentialsFileDir != "" {
+			credProvider.SetCredentialsFileDir(options.CredentialsFileDir)
+			interval := credentialsReloadInterval(credentialprovider.EnvCredentialsFileReloadInterval)
+			go credProvider.WatchFileCredentials(stopCh, interval)
+		}
+
+		// Assume roles of volumes using `authenticationSource: webIdentity` with service account tokens of this audience
+		if options.ServiceAccountTokenAudience != "" {
+			credProvider.SetServiceAccountTokenAudience(options.ServiceAccountTokenAudience)
+		}
+
+		// Refresh credentials of volumes using `authenticationSource: role` or `webIdentity` before they expire
+		go credProvider.WatchRoleCredentials(stopCh, credentialprovider.RoleCredentialsRefreshInterval)
+
+		// Trust CA bundles of Secrets referenced by volumes, and rewrite them when the Secrets change
+		if os.Getenv(credentialprovider.EnvVolumeCABundlesEnabled) == "true" {
+			secretsClientset, err := newKubernetesForConfigFn(secretsConfig)
+			if err != nil {
+				return nil, fmt.Errorf("cannot create kubernetes clientset for Secrets: %w", err)
+			}
+			credProvider.SetSecretsClient(secretsClientset.CoreV1())
+			go credProvider.WatchCABundles(stopCh, credentialprovider.CABundleReloadInterval)
+			klog.Infoln("Volume CA bundles enabled")
+		}
+
+		// Report node-level problems to Node Problem Detector
+		if os.Getenv(problemreport.EnvProblemReportsEnabled) == "true" {
+			reporter := problemreport.NewReporter(util.KubeletPath(), os.Getenv(envprovider.EnvEndpointURL))
+			go reporter.Start(stopCh, problemreport.CheckInterval)
+			klog.Infof("Reporting node problems to %s", problemreport.Dir(util.KubeletPath()))
+		}
+
+		// Probe the S3 endpoint, to gate the readiness of the node plugin and explain mount failures
+		if os.Getenv(endpointprobe.EnvEndpointProbeEnabled) == "true" {
+			endpointURL := os.Getenv(envprovider.EnvEndpointURL)
+			endpointProber, err = endpointprobe.NewProber(endpointURL, os.Getenv(endpointprobe.EnvCABundle))
+			if err != nil {
+				klog.Fatalf("Failed to create S3 endpoint prober: %v", err)
+			}
+			go endpointProber.Start(stopCh, endpointprobe.ProbeInterval)
+			if addr := os.Getenv(endpointprobe.EnvReadinessAddress); addr != "" {
+				go endpointProber.ServeReadiness(addr, stopCh)
+			}
+			klog.Infof("Probing S3 endpoint %s every %v", endpointURL, endpointprobe.ProbeInterval)
+		}
+
+		// Supervise the registration of the driver with kubelet, re-registering it after kubelet restarts
+		var registrationSupervisor *registration.Supervisor
+		if os.Getenv(registration.EnvRegistrationSupervisorEnabled) == "true" {
+			csiSocket := unixSocketPath(endpoint)
+			if csiSocket == "" {
+				klog.Fatalf("Cannot supervise the registration of the driver with kubelet: endpoint %s is not a unix socket", endpoint)
+			}
+			registrationSupervisor = registration.NewSupervisor(driverName, nodeID, csiSocket, util.KubeletPath(), clientset.StorageV1())
+			if endpointProber != nil {
+				registrationSupervisor.AddReadinessCheck(endpointProber.Err)
+			}
+			go registrationSupervisor.Start(stopCh, registration.CheckInterval)
+			if addr := os.Getenv(registration.EnvReadinessAddress); addr != "" {
+				go registrationSupervisor.ServeReadiness(addr, stopCh)
+			}
+			klog.Infof("Supervising the registration of the driver with kubelet through %s", registration.RegistrationSocket(util.KubeletPath(), driverName))
+		}
+
+		// Label the Node with the readiness and version of the driver, for node affinities of workloads
+		if os.Getenv(nodelabel.EnvNodeLabelsEnabled) == "true" {
+			nodeLabeler = nodelabel.NewLabeler(clientset, nodeID, version.DriverVersion)
+			if registrationSupervisor != nil {
+				nodeLabeler.SetReadinessCheck(registrationSupervisor.Err)
+			} else if endpointProber != nil {
+				nodeLabeler.SetReadinessCheck(endpointProber.Err)
+			}
+			go nodeLabeler.Start(stopCh, nodelabel.CheckInterval)
+			readyLabel, versionLabel := nodelabel.Labels(driverName)
+			klog.Infof("Labeling node %s with %s and %s", nodeID, readyLabel, versionLabel)
+		}
+
+		// Remount mounts of volumes in a read-only window read-only, and writable again once it ends
+		go mounter.NewReadOnlyWindowEnforcer(s3paCache, nodeID).Start(stopCh, mounter.ReadOnlyWindowEnforceInterval)
+
+		podMounter, err := mounter.NewPodMounter(podWatcher, credProvider, mount.New(""), nil, nil, kubernetesVersion, s3paCache)
 		if err != nil {
 			klog.Fatalf("Failed to create pod mounter: %v", err)
 		}
 
+		busyUnmountConfig, err := mounter.BusyUnmountConfigFromEnv()
+		if err != nil {
+			klog.Fatalf("Invalid busy unmount configuration: %v", err)
+		}
+		podMounter.SetBusyUnmountConfig(busyUnmountConfig)
+		klog.Infof("Busy targets are unmounted with the %q policy", busyUnmountConfig.Policy)
+		if os.Getenv(mounter.EnvForcedCleanupEnabled) == "true" {
+			podMounter.SetForcedCleanup(clientset.CoreV1().Pods(mountpointPodNamespace))
+			klog.Infof("Wedged targets are detached on unmount, with their source and Mountpoint Pod if wedged too")
+		}
+		mountTimeouts, err := mounter.MountTimeoutsFromEnv()
+		if err != nil {
+			klog.Fatalf("Invalid mount timeouts: %v", err)
+		}
+		podMounter.SetMountTimeouts(mountTimeouts)
+		var mountLimiter *mounter.MountLimiter
+		if options.MaxConcurrentMounts > 0 {
+			mountLimiter = mounter.NewMountLimiter(options.MaxConcurrentMounts)
+			podMounter.SetMountLimiter(mountLimiter)
+			klog.Infof("At most %d volumes are mounted at the same time, other mounts are queued", options.MaxConcurrentMounts)
+		}
+		// Apply the tunables of the driver configuration file, and again each time its ConfigMap changes
+		if configFile := os.Getenv(driverconfig.EnvConfigFile); configFile != "" {
+			if mountLimiter == nil {
+				mountLimiter = mounter.NewMountLimiter(0)
+				podMounter.SetMountLimiter(mountLimiter)
+			}
+			configWatcher := newNodeConfigWatcher(configFile, nodeEvents, mountLimiter, options.MaxConcurrentMounts)
+			if _, err := configWatcher.Reload(); err != nil {
+				klog.Errorf("Invalid driver configuration, flags and environment variables are used until it is fixed: %v", err)
+			}
+			go configWatcher.Start(stopCh, driverconfig.ReloadInterval)
+		}
+		if attachmentStatus, err := client.New(attachmentsConfig, client.Options{Scheme: scheme}); err != nil {
+			klog.Warningf("Failed to create client of MountpointS3PodAttachments, mount generations are not reported in their status: %v", err)
+		} else {
+			podMounter.SetAttachmentStatusWriter(attachmentStatus)
+		}
+		if os.Getenv(pressure.EnvAdaptiveConcurrencyEnabled) == "true" {
+			pressureConfig, err := pressure.ConfigFromEnv()
+			if err != nil {
+				klog.Fatalf("Invalid adaptive concurrency configuration: %v", err)
+			}
+			pressureMonitor := pressure.NewMonitor(pressure.DefaultPath, pressureConfig)
+			if err := pressureMonitor.Check(); err != nil {
+				klog.Warningf("Pressure stall information of the node is not available, concurrency of mounts is not adapted: %v", err)
+			} else {
+				go pressureMonitor.Start(stopCh, pressure.CheckInterval)
+				podMounter.SetPressureMonitor(pressureMonitor)
+				klog.Infof("Mounts made while IO or memory pressure of the node is above %.1f%% are limited to --max-threads=%d", pressureConfig.Threshold, pressureConfig.MaxThreads)
+			}
+		}
+		if len(options.TelemetryTags) > 0 {
+			podMounter.SetTelemetryTags(options.TelemetryTags, clientset.CoreV1())
+			klog.Infof("Telemetry tags %v are added to the user-agent of Mountpoint", options.TelemetryTags.Names())
+		}
+		if os.Getenv(mountreport.EnvMountReportsEnabled) == "true" {
+			go mountreport.NewReporter(clientset.CoreV1(), nodeID, podMounter.MountReport).Start(stopCh, mountreport.CheckInterval)
+			klog.Infof("Reporting mounts in the %s annotation of node %s", mountreport.Annotation, nodeID)
+		}
+		// Report mounts not responding to statfs to their Mountpoint Pod, whose liveness probe then fails
+		if os.Getenv(mounter.EnvMountHealthChecksEnabled) == "true" {
+			mountHealth = mounter.NewMountHealthChecker(podMounter)
+			go mountHealth.Start(stopCh, mounter.MountHealthCheckInterval)
+			klog.Infof("Checking mounts every %v, mounts not responding for %d consecutive checks are reported unhealthy", mounter.MountHealthCheckInterval, mounter.MountHealthFailureThreshold)
+		}
+		// Mountpoint Pods request a new FUSE device when Mountpoint crashes and is restarted in supervised mode
+		go mounter.NewMountReconnector(podMounter).Start(stopCh, mounter.ReconnectCheckInterval)
+		mounterImpl = podMounter
+
+		// Mountpoint Pods relay the metrics Mountpoint logs, exported per volume with the metrics of the node plugin
+		if os.Getenv(mounter.EnvMountpointMetricsEnabled) == "true" {
+			mountMetrics := mounter.NewMountMetricsCollector(podMounter)
+			podMounter.SetMountMetricsCollector(mountMetrics)
+			nodemetrics.Registry.MustRegister(mountMetrics)
+			klog.Infoln("Exporting metrics of Mountpoint per volume")
+		}
+		if addr := os.Getenv(nodemetrics.EnvMetricsAddress); addr != "" {
+			go nodemetrics.Serve(addr, stopCh)
+		}
+
 		klog.Infoln("Using pod mounter with S3PodAttachment cache and unmounter")
 	}
 
 	var nodeServer *node.S3NodeServer
 	if mounterImpl != nil {
 		nodeServer = node.NewS3NodeServer(nodeID, mounterImpl)
+		nodeServer.MountTable = mount.New("")
+		nodeServer.EndpointProber = endpointProber
+		nodeServer.MountHealth = mountHealth
+		nodeServer.Events = nodeEvents
+		nodeServer.AWSCompatibilityMode = os.Getenv(volumecontext.EnvAWSCompatibilityMode) == "true"
+		if nodeServer.AWSCompatibilityMode {
+			klog.Infoln("AWS compatibility mode enabled, AWS CSI Driver volume attributes will be translated")
+		}
+
+		nodeServer.DiagnosticMountNamespace = os.Getenv(volumecontext.EnvDiagnosticMountNamespace)
+		if nodeServer.DiagnosticMountNamespace != "" {
+			klog.Infof("Diagnostic mounts enabled for Pods in namespace %s", nodeServer.DiagnosticMountNamespace)
+		}
+
+		nodeServer.EphemeralVolumes = os.Getenv(volumecontext.EnvEphemeralVolumesEnabled) == "true"
+		if nodeServer.EphemeralVolumes {
+			secretsClientset, err := newKubernetesForConfigFn(secretsConfig)
+			if err != nil {
+				return nil, fmt.Errorf("cannot create kubernetes clientset for Secrets: %w", err)
+			}
+			nodeServer.Secrets = secretsClientset.CoreV1()
+			klog.Infoln("Inline ephemeral volumes enabled")
+		}
+
+		if os.Getenv(node.EnvFSGroupPropagationEnabled) == "true" {
+			nodeServer.WorkloadPods = clientset.CoreV1()
+			klog.Infoln("Propagating the fsGroup of workload Pods to the group of their volumes")
+		}
+
+		if os.Getenv(mounter.EnvVolumeStagingEnabled) == "true" {
+			if stager, ok := mounterImpl.(mounter.Stager); ok {
+				nodeServer.Stager = stager
+				klog.Infoln("Volume staging enabled, volumes are mounted once per node in NodeStageVolume")
+			} else {
+				klog.Warningln("Volume staging is only supported by the pod mounter, volumes are mounted in NodePublishVolume")
+			}
+		}
+
+		if policyFile := os.Getenv(bucketpolicy.EnvPolicyFile); policyFile != "" {
+			nodeServer.BucketPolicy = bucketpolicy.NewFileLoader(policyFile)
+			if _, err := nodeServer.BucketPolicy.Policy(); err != nil {
+				klog.Errorf("Invalid namespace bucket policy, mounts will be rejected until it is fixed: %v", err)
+			}
+			klog.Infof("Namespace bucket policy enabled from %s", policyFile)
+		}
+
+		nodeServer.RegionResolver, err = bucketregion.NewResolverFromEnv(context.Background())
+		if err != nil {
+			klog.Errorf("Failed to set up bucket region discovery, buckets are mounted without discovering their region: %v", err)
+		}
+
+		nodeServer.PrefixMarker, err = prefixmarker.NewMarkerFromEnv(context.Background())
+		if err != nil {
+			klog.Errorf("Failed to set up prefix checks, volumes with %s fail to mount: %v", volumecontext.EnsurePrefix, err)
+		}
+
+		var auditInterval time.Duration
+		nodeServer.MountAudit, auditInterval, err = mountaudit.NewLogFromEnv(context.Background(), nodeID)
+		if err != nil {
+			klog.Errorf("Failed to set up the mount audit log, mounts are not audited: %v", err)
+		} else if nodeServer.MountAudit != nil {
+			go nodeServer.MountAudit.Start(stopCh, auditInterval)
+		}
+
+		// Mount volumes with the first reachable of their endpoints, and remount them against the next one if enabled
+		remounts := os.Getenv(endpointfailover.EnvFailoverRemountEnabled) == "true"
+		nodeServer.EndpointFailover, err = endpointfailover.NewSelector(os.Getenv(endpointprobe.EnvCABundle), remounts)
+		if err != nil {
+			klog.Errorf("Failed to set up endpoint failover, volumes are mounted with their first endpoint: %v", err)
+		} else if remounts {
+			go nodeServer.EndpointFailover.Start(stopCh, endpointfailover.CheckInterval)
+			klog.Infof("Remounting volumes whose S3 endpoint is unreachable for %d consecutive probes", endpointfailover.FailureThreshold)
+		}
+
+		nodeServer.VolumeStats, err = volumestats.NewProviderFromEnv(context.Background())
+		if err != nil {
+			klog.Errorf("Failed to set up volume statistics, NodeGetVolumeStats will not be available: %v", err)
+		}
+		if os.Getenv(volumecondition.EnvEnabled) == "true" {
+			nodeServer.VolumeCondition = volumecondition.NewChecker(volumecondition.CheckTimeout)
+			klog.Infof("Reporting volumes whose mount does not respond to statfs within %v as abnormal", volumecondition.CheckTimeout)
+		}
 	}
 
 	// Initialize controller credential provider for dynamic provisioning
 	controllerCredProvider := controllerCredProvider.New(clientset)
 
 	return &Driver{
-		Endpoint:               endpoint,
-		NodeID:                 nodeID,
-		NodeServer:             nodeServer,
-		Clientset:              clientset,
-		controllerCredProvider: controllerCredProvider,
-		stopCh:                 stopCh,
+		Endpoint:                   endpoint,
+		NodeID:                     nodeID,
+		NodeServer:                 nodeServer,
+		Clientset:                  clientset,
+		controllerCredProvider:     controllerCredProvider,
+		pvcMetadataPropagationKeys: pvcMetadataPropagationKeysFromEnv(),
+		nodeLabeler:                nodeLabeler,
+		events:                     controllerEvents,
+		stopCh:                     stopCh,
 	}, nil
 }
 
@@ -389,15 +755,8 @@ func (d *Driver) Run() error {
 		}
 	}
 
-	logErr := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
-		resp, err := handler(ctx, req)
-		if err != nil {
-			klog.Errorf("GRPC error: %v", err)
-		}
-		return resp, err
-	}
 	opts := []grpc.ServerOption{
-		grpc.UnaryInterceptor(logErr),
+		grpc.UnaryInterceptor(logRequest),
 		grpc.MaxRecvMsgSize(grpcServerMaxReceiveMessageSize),
 	}
 	d.Srv = grpc.NewServer(opts...)
@@ -424,6 +783,13 @@ func (d *Driver) Stop() {
 		close(d.stopCh)
 		d.stopCh = nil
 	}
+	if d.nodeLabeler != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), nodeLabelStopTimeout)
+		if err := d.nodeLabeler.MarkNotReady(ctx); err != nil {
+			klog.Warningf("Failed to label node %s as not ready: %v", d.NodeID, err)
+		}
+		cancel()
+	}
 	if d.Srv != nil {
 		d.Srv.Stop()
 	}
@@ -437,3 +803,43 @@ func kubernetesVersion(clientset kubernetes.Interface) (string, error) {
 
 	return version.String(), nil
 }
+
+// credentialsReloadInterval returns the interval to reload credentials configured by environment variable `name`,
+// the default interval if it is not set or invalid.
+func credentialsReloadInterval(name string) time.Duration {
+	interval := credentialprovider.DefaultDriverCredentialsReloadInterval
+	if value := os.Getenv(name); value != "" {
+		if parsed, err := time.ParseDuration(value); err != nil || parsed <= 0 {
+			klog.Errorf("Invalid %s %q, using default of %v", name, value, interval)
+		} else {
+			interval = parsed
+		}
+	}
+	return interval
+}
+
+// newNodeConfigWatcher returns a watcher of the driver configuration file at `path`, applying the log level, the
+// limit of concurrent mounts of `mountLimiter` and the mount args policy of the node plugin. Unset tunables go back
+// to their startup value, e.g. `maxConcurrentMounts` for the limit of concurrent mounts.
+func newNodeConfigWatcher(path string, events record.EventRecorder, mountLimiter *mounter.MountLimiter, maxConcurrentMounts int) *driverconfig.Watcher {
+	configWatcher := driverconfig.NewWatcher(path)
+	nodemetrics.Registry.MustRegister(driverconfig.ReloadsTotal, driverconfig.ConfigValid)
+	if configMap := os.Getenv(driverconfig.EnvConfigMap); configMap != "" && events != nil {
+		if err := configWatcher.SetEventRecorder(events, configMap); err != nil {
+			klog.Errorf("Invalid driver configuration ConfigMap, invalid configurations are not reported in events: %v", err)
+		}
+	}
+	configWatcher.OnChange(driverconfig.KlogLevelHandler())
+	configWatcher.OnChange(func(config *driverconfig.Config) {
+		limit := maxConcurrentMounts
+		if config.MaxConcurrentMounts != nil {
+			limit = *config.MaxConcurrentMounts
+		}
+		mountLimiter.SetLimit(limit)
+		mounter.SetMountArgsPolicy(mounter.MountArgsPolicy{
+			AllowedEndpointURLs: config.AllowedEndpointURLs,
+			DeniedArgs:          config.DeniedMountArgs,
+		})
+	})
+	return configWatcher
+}
@@ -82,7 +82,7 @@ func TestNewDriverEndpointURLValidation(t *testing.T) {
 
 		// Try to create a new driver without setting the endpoint URL
 		// We expect this to fail with a specific error
-		_, err := driver.NewDriver("unix:///tmp/test.sock", "test-mp-version", "test-node-id")
+		_, err := driver.NewDriver("unix:///tmp/test.sock", "test-mp-version", "test-node-id", driver.Options{})
 
 		// Check that we got the expected error
 		if err == nil {
@@ -100,7 +100,7 @@ func TestNewDriverEndpointURLValidation(t *testing.T) {
 
 		// Try to create a new driver with endpoint URL set
 		// This will still fail, but with a different error (about Kubernetes, not about endpoint URL)
-		_, err := driver.NewDriver("unix:///tmp/test.sock", "test-mp-version", "test-node-id")
+		_, err := driver.NewDriver("unix:///tmp/test.sock", "test-mp-version", "test-node-id", driver.Options{})
 
 		// Check that we got an error, but NOT the endpoint URL error
 		if err == nil {
@@ -189,7 +189,7 @@ func TestControllerOnlyAffectsMounterCreation(t *testing.T) {
 
 	// 1) controller-only path: NodeServer should be nil
 	_ = os.Setenv("CSI_CONTROLLER_ONLY", "true")
-	d1, err := driver.NewDriver("unix:///tmp/test.sock", "mpv", "node-1")
+	d1, err := driver.NewDriver("unix:///tmp/test.sock", "mpv", "node-1", driver.Options{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -203,7 +203,7 @@ func TestControllerOnlyAffectsMounterCreation(t *testing.T) {
 	_ = os.Setenv("CSI_CONTROLLER_ONLY", "false")
 	_ = os.Setenv("MOUNTPOINT_NAMESPACE", "mount-s3") // Required for pod mounter
 	_ = os.Setenv("NODE_NAME", "test-node")           // Required for pod mounter with CRD support
-	d2, err := driver.NewDriver("unix:///tmp/test.sock", "mpv", "node-2")
+	d2, err := driver.NewDriver("unix:///tmp/test.sock", "mpv", "node-2", driver.Options{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -18,6 +18,7 @@ package driver
 
 import (
 	"context"
+	"slices"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/version"
@@ -33,17 +34,26 @@ func (d *Driver) GetPluginInfo(ctx context.Context, req *csi.GetPluginInfoReques
 	return resp, nil
 }
 
+// pluginCaps are the services of the plugin advertised by the identity service.
+var pluginCaps = []csi.PluginCapability_Service_Type{
+	csi.PluginCapability_Service_CONTROLLER_SERVICE,
+}
+
+// PluginCapabilities returns the services advertised by the identity service.
+func PluginCapabilities() []csi.PluginCapability_Service_Type {
+	return slices.Clone(pluginCaps)
+}
+
 func (d *Driver) GetPluginCapabilities(ctx context.Context, req *csi.GetPluginCapabilitiesRequest) (*csi.GetPluginCapabilitiesResponse, error) {
-	resp := &csi.GetPluginCapabilitiesResponse{
-		Capabilities: []*csi.PluginCapability{
-			{
-				Type: &csi.PluginCapability_Service_{
-					Service: &csi.PluginCapability_Service{
-						Type: csi.PluginCapability_Service_CONTROLLER_SERVICE,
-					},
+	resp := &csi.GetPluginCapabilitiesResponse{}
+	for _, cap := range pluginCaps {
+		resp.Capabilities = append(resp.Capabilities, &csi.PluginCapability{
+			Type: &csi.PluginCapability_Service_{
+				Service: &csi.PluginCapability_Service{
+					Type: cap,
 				},
 			},
-		},
+		})
 	}
 
 	return resp, nil
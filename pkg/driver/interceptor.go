@@ -0,0 +1,69 @@
+package driver
+
+import (
+	"context"
+	"path"
+	"regexp"
+	"slices"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+
+	nodemetrics "github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/metrics"
+)
+
+// frequentMethods are CSI calls made periodically by kubelet and the sidecars, e.g. for liveness probes. They are
+// only logged at verbosity 4, so request logs stay an audit trail of volume operations.
+var frequentMethods = []string{
+	"Probe",
+	"GetPluginInfo",
+	"GetPluginCapabilities",
+	"NodeGetCapabilities",
+	"NodeGetInfo",
+	"ControllerGetCapabilities",
+}
+
+// credentialPattern matches credentials in error messages, e.g. `secret_access_key=...` or `SessionToken: ...`,
+// the value being the last capture group.
+var credentialPattern = regexp.MustCompile(`(?i)((?:access[_-]?key(?:[_-]?id)?|secret[_-]?(?:access[_-]?)?key|session[_-]?token|token|password)["']?\s*[:=]\s*["']?)([^\s"',;&]+)`)
+
+// sanitize returns `message` with the values of credentials scrubbed.
+func sanitize(message string) string {
+	return credentialPattern.ReplaceAllString(message, "${1}***")
+}
+
+// volumeRequest is implemented by CSI requests of a volume.
+type volumeRequest interface {
+	GetVolumeId() string
+}
+
+// logRequest is a gRPC interceptor logging every CSI call with its volume, duration and status code, with
+// credentials scrubbed from errors, and recording its duration in [nodemetrics.GRPCRequestDurationSeconds].
+// Requests are not logged as they may contain secrets, see `protosanitizer` for the debug logs of requests.
+func logRequest(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	duration := time.Since(start)
+
+	method := path.Base(info.FullMethod)
+	code := status.Code(err)
+	nodemetrics.GRPCRequestDurationSeconds.WithLabelValues(method, code.String()).Observe(duration.Seconds())
+
+	keysAndValues := []any{"method", method}
+	if volumeReq, ok := req.(volumeRequest); ok && volumeReq.GetVolumeId() != "" {
+		keysAndValues = append(keysAndValues, "volumeID", volumeReq.GetVolumeId())
+	}
+	keysAndValues = append(keysAndValues, "duration", duration, "code", code.String())
+	if err != nil {
+		klog.ErrorS(nil, "GRPC error", append(keysAndValues, "error", sanitize(status.Convert(err).Message()))...)
+		return resp, err
+	}
+	if slices.Contains(frequentMethods, method) {
+		klog.V(4).InfoS("GRPC request", keysAndValues...)
+	} else {
+		klog.InfoS("GRPC request", keysAndValues...)
+	}
+	return resp, err
+}
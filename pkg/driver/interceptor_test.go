@@ -0,0 +1,83 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	nodemetrics "github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/metrics"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestSanitize(t *testing.T) {
+	for _, test := range []struct {
+		message  string
+		expected string
+	}{
+		{
+			message:  "Could not mount: bucket not found",
+			expected: "Could not mount: bucket not found",
+		},
+		{
+			message:  "invalid credentials access_key_id=AKIAEXAMPLE secret_access_key=wJalrXUtnFEMI/K7MDENG",
+			expected: "invalid credentials access_key_id=*** secret_access_key=***",
+		},
+		{
+			message:  `failed to write {"SessionToken": "FwoGZXIvYXdzEBY", "region": "us-east-1"}`,
+			expected: `failed to write {"SessionToken": "***", "region": "us-east-1"}`,
+		},
+	} {
+		assert.Equals(t, test.expected, sanitize(test.message))
+	}
+}
+
+func TestLogRequest(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/csi.v1.Node/NodePublishVolume"}
+	req := &csi.NodePublishVolumeRequest{VolumeId: "vol-1", Secrets: map[string]string{"secret_access_key": "secret"}}
+	before := grpcRequestCount(t, "NodePublishVolume")
+	resp, err := logRequest(context.Background(), req, info, func(ctx context.Context, req any) (any, error) {
+		return &csi.NodePublishVolumeResponse{}, nil
+	})
+	assert.NoError(t, err)
+	if resp == nil {
+		t.Fatal("Expected the response of the handler")
+	}
+
+	handlerErr := status.Error(codes.Internal, "Could not mount: secret_access_key=secret")
+	_, err = logRequest(context.Background(), req, info, func(ctx context.Context, req any) (any, error) {
+		return nil, handlerErr
+	})
+	if !errors.Is(err, handlerErr) || !strings.Contains(err.Error(), "secret_access_key=secret") {
+		t.Fatalf("Expected the error of the handler to be returned unchanged, got %v", err)
+	}
+	if after := grpcRequestCount(t, "NodePublishVolume"); after != before+2 {
+		t.Fatalf("Expected durations of both calls to be recorded, got %d from %d", after, before)
+	}
+}
+
+// grpcRequestCount returns the number of calls of `method` recorded by [nodemetrics.GRPCRequestDurationSeconds].
+func grpcRequestCount(t *testing.T, method string) uint64 {
+	t.Helper()
+	families, err := nodemetrics.Registry.Gather()
+	assert.NoError(t, err)
+	count := uint64(0)
+	for _, family := range families {
+		if family.GetName() != "scality_csi_node_grpc_request_duration_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "method" && label.GetValue() == method {
+					count += metric.GetHistogram().GetSampleCount()
+				}
+			}
+		}
+	}
+	return count
+}
@@ -0,0 +1,65 @@
+package node
+
+import (
+	"net"
+	"net/url"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
+)
+
+// addressedBucket returns the name Mountpoint addresses `bucket` with, its [volumecontext.BucketAlias] if the volume
+// with `volumeCtx` sets one.
+func addressedBucket(volumeCtx map[string]string, bucket string) (string, error) {
+	alias, err := volumecontext.ParseBucketAlias(volumeCtx)
+	if err != nil {
+		return "", status.Errorf(codes.InvalidArgument, "Invalid bucket alias: %v", err)
+	}
+	if alias == "" {
+		return bucket, nil
+	}
+	return alias, nil
+}
+
+// checkAddressing returns an error if `bucket` cannot be addressed with the addressing style in `args` on the S3
+// endpoint of the volume: its `--endpoint-url`, or the driver-level endpoint. Virtual-hosted addressing puts the
+// bucket in the host name of requests, so the endpoint must be a host name and the bucket a valid DNS label, without
+// dots on HTTPS endpoints as wildcard certificates do not match them.
+func checkAddressing(bucket string, args mountpoint.Args) error {
+	if args.Has(mountpoint.ArgForcePathStyle) {
+		return nil
+	}
+
+	if !volumecontext.IsDNSCompatibleBucketName(bucket) {
+		return status.Errorf(codes.InvalidArgument, "Bucket %q cannot be addressed with %s: %s, it is not DNS-compatible. Set a %s or use %s: %s",
+			bucket, volumecontext.AddressingStyle, volumecontext.AddressingStyleVirtual, volumecontext.BucketAlias, volumecontext.AddressingStyle, volumecontext.AddressingStylePath)
+	}
+
+	endpointURL, ok := args.Value(mountpoint.ArgEndpointURL)
+	if !ok {
+		endpointURL = os.Getenv(envprovider.EnvEndpointURL)
+	}
+	if endpointURL == "" {
+		return nil
+	}
+	u, err := url.Parse(endpointURL)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "Invalid S3 endpoint %q: %v", endpointURL, err)
+	}
+	host := u.Hostname()
+	if net.ParseIP(host) != nil || host == "localhost" {
+		return status.Errorf(codes.InvalidArgument, "%s: %s requires an S3 endpoint with a host name, got %q. Use %s: %s",
+			volumecontext.AddressingStyle, volumecontext.AddressingStyleVirtual, endpointURL, volumecontext.AddressingStyle, volumecontext.AddressingStylePath)
+	}
+	if u.Scheme == "https" && strings.Contains(bucket, ".") {
+		return status.Errorf(codes.InvalidArgument, "Bucket %q cannot be addressed with %s: %s on HTTPS endpoint %q, TLS certificates do not match bucket names with dots. Set a %s or use %s: %s",
+			bucket, volumecontext.AddressingStyle, volumecontext.AddressingStyleVirtual, endpointURL, volumecontext.BucketAlias, volumecontext.AddressingStyle, volumecontext.AddressingStylePath)
+	}
+	return nil
+}
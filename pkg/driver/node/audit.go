@@ -0,0 +1,59 @@
+package node
+
+import (
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"google.golang.org/grpc/status"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/mountaudit"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
+)
+
+// auditPublish records the mount of `req` in the mount audit log, failed if `err` is set.
+func (ns *S3NodeServer) auditPublish(req *csi.NodePublishVolumeRequest, err error) {
+	if ns.MountAudit == nil {
+		return
+	}
+
+	volumeCtx := req.GetVolumeContext()
+	options := req.GetVolumeCapability().GetMount().GetMountFlags()
+	args := mountpoint.ParseArgs(options)
+	prefix, _ := args.Value(mountpoint.ArgPrefix)
+	if prefix == "" {
+		prefix = volumeCtx[volumecontext.Prefix]
+	}
+	ns.MountAudit.Record(auditResult(mountaudit.Record{
+		Operation:      mountaudit.OperationMount,
+		VolumeID:       req.GetVolumeId(),
+		Target:         req.GetTargetPath(),
+		Pod:            volumeCtx[volumecontext.CSIPodName],
+		Namespace:      volumeCtx[volumecontext.CSIPodNamespace],
+		ServiceAccount: volumeCtx[volumecontext.CSIServiceAccountName],
+		Bucket:         volumeCtx[volumecontext.BucketName],
+		Prefix:         prefix,
+		Options:        options,
+	}, err))
+}
+
+// auditUnpublish records the unmount of `req` in the mount audit log, failed if `err` is set.
+func (ns *S3NodeServer) auditUnpublish(req *csi.NodeUnpublishVolumeRequest, err error) {
+	if ns.MountAudit == nil {
+		return
+	}
+	ns.MountAudit.Record(auditResult(mountaudit.Record{
+		Operation: mountaudit.OperationUnmount,
+		VolumeID:  req.GetVolumeId(),
+		Target:    req.GetTargetPath(),
+	}, err))
+}
+
+// auditResult returns `record` with the result of an operation failed with `err`, successful if nil.
+func auditResult(record mountaudit.Record, err error) mountaudit.Record {
+	if err != nil {
+		record.Result = mountaudit.ResultFailure
+		record.Code = status.Code(err).String()
+		return record
+	}
+	record.Result = mountaudit.ResultSuccess
+	return record
+}
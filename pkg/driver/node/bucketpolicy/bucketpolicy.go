@@ -0,0 +1,134 @@
+// Package bucketpolicy restricts the buckets Pods can mount depending on their namespace, so platform teams can
+// enforce multi-tenancy guardrails such as "Pods in `team-a` can only mount buckets matching `team-a-*`".
+package bucketpolicy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EnvPolicyFile is the environment variable containing the path of the namespace bucket policy file.
+// The policy is disabled if unset.
+const EnvPolicyFile = "NAMESPACE_BUCKET_POLICY_FILE"
+
+// A Policy maps namespaces to the buckets and prefixes their Pods can mount.
+type Policy struct {
+	// AllowUnlistedNamespaces allows Pods in namespaces not listed in Namespaces to mount any bucket.
+	AllowUnlistedNamespaces bool `json:"allowUnlistedNamespaces"`
+	// Namespaces maps namespace names to their policy.
+	Namespaces map[string]NamespacePolicy `json:"namespaces"`
+}
+
+// A NamespacePolicy lists the buckets and prefixes Pods of a namespace can mount.
+type NamespacePolicy struct {
+	// Buckets are the patterns of bucket names the namespace can mount, see [path.Match] for their syntax.
+	// Any bucket can be mounted if empty.
+	Buckets []string `json:"buckets,omitempty"`
+	// Prefixes are the bucket prefixes mounts of the namespace must be restricted to, with the `prefix` mount option.
+	// Whole buckets can be mounted if empty.
+	Prefixes []string `json:"prefixes,omitempty"`
+}
+
+// Parse parses and validates a policy in JSON.
+func Parse(data []byte) (*Policy, error) {
+	policy := &Policy{}
+	if err := json.Unmarshal(data, policy); err != nil {
+		return nil, fmt.Errorf("invalid namespace bucket policy: %w", err)
+	}
+	for namespace, nsPolicy := range policy.Namespaces {
+		for _, pattern := range nsPolicy.Buckets {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid bucket pattern %q for namespace %s: %w", pattern, namespace, err)
+			}
+		}
+		for _, prefix := range nsPolicy.Prefixes {
+			if prefix == "" {
+				return nil, fmt.Errorf("empty prefix for namespace %s", namespace)
+			}
+		}
+	}
+	return policy, nil
+}
+
+// Check returns an error if Pods in `namespace` cannot mount `prefix` of `bucket`.
+// `prefix` is empty for mounts of the whole bucket.
+func (p *Policy) Check(namespace, bucket, prefix string) error {
+	nsPolicy, ok := p.Namespaces[namespace]
+	if !ok {
+		if p.AllowUnlistedNamespaces {
+			return nil
+		}
+		return fmt.Errorf("namespace %s is not allowed to mount buckets", namespace)
+	}
+
+	if len(nsPolicy.Buckets) > 0 && !matchesAny(bucket, nsPolicy.Buckets) {
+		return fmt.Errorf("namespace %s is not allowed to mount bucket %q, allowed buckets are %s", namespace, bucket, strings.Join(nsPolicy.Buckets, ", "))
+	}
+	if len(nsPolicy.Prefixes) > 0 && !hasAnyPrefix(prefix, nsPolicy.Prefixes) {
+		return fmt.Errorf("namespace %s is not allowed to mount prefix %q of bucket %q, allowed prefixes are %s", namespace, prefix, bucket, strings.Join(nsPolicy.Prefixes, ", "))
+	}
+	return nil
+}
+
+func matchesAny(bucket string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, bucket); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAnyPrefix(prefix string, allowed []string) bool {
+	for _, a := range allowed {
+		if prefix != "" && strings.HasPrefix(prefix, a) {
+			return true
+		}
+	}
+	return false
+}
+
+// A FileLoader reads a policy from a file, typically a mounted ConfigMap, and reads it again when it changes.
+type FileLoader struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	policy  *Policy
+}
+
+// NewFileLoader returns a loader of the policy in the file at `path`.
+func NewFileLoader(path string) *FileLoader {
+	return &FileLoader{path: path}
+}
+
+// Policy returns the current policy. It returns an error if the file cannot be read or is invalid,
+// so mounts are rejected rather than allowed by a broken policy.
+func (l *FileLoader) Policy() (*Policy, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	info, err := os.Stat(l.path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read namespace bucket policy: %w", err)
+	}
+	if l.policy != nil && info.ModTime().Equal(l.modTime) {
+		return l.policy, nil
+	}
+
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read namespace bucket policy: %w", err)
+	}
+	policy, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	l.policy, l.modTime = policy, info.ModTime()
+	return policy, nil
+}
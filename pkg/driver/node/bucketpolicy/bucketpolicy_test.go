@@ -0,0 +1,83 @@
+package bucketpolicy_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/bucketpolicy"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+const testPolicy = `{
+  "namespaces": {
+    "team-a": {"buckets": ["team-a-*"]},
+    "team-b": {"buckets": ["shared"], "prefixes": ["team-b/"]},
+    "platform": {}
+  }
+}`
+
+func TestCheck(t *testing.T) {
+	policy, err := bucketpolicy.Parse([]byte(testPolicy))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		namespace     string
+		bucket        string
+		prefix        string
+		allowUnlisted bool
+		wantErr       bool
+	}{
+		{name: "matching bucket", namespace: "team-a", bucket: "team-a-logs"},
+		{name: "bucket of another team", namespace: "team-a", bucket: "team-b-logs", wantErr: true},
+		{name: "allowed prefix", namespace: "team-b", bucket: "shared", prefix: "team-b/data/"},
+		{name: "whole bucket with mandatory prefix", namespace: "team-b", bucket: "shared", wantErr: true},
+		{name: "prefix of another team", namespace: "team-b", bucket: "shared", prefix: "team-a/", wantErr: true},
+		{name: "namespace without restrictions", namespace: "platform", bucket: "anything"},
+		{name: "unlisted namespace", namespace: "default", bucket: "anything", wantErr: true},
+		{name: "allowed unlisted namespace", namespace: "default", bucket: "anything", allowUnlisted: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy.AllowUnlistedNamespaces = tt.allowUnlisted
+			err := policy.Check(tt.namespace, tt.bucket, tt.prefix)
+			assert.Equals(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func TestParseInvalidPolicy(t *testing.T) {
+	for _, data := range []string{
+		`not json`,
+		`{"namespaces": {"team-a": {"buckets": ["team-a-["]}}}`,
+		`{"namespaces": {"team-a": {"prefixes": [""]}}}`,
+	} {
+		if _, err := bucketpolicy.Parse([]byte(data)); err == nil {
+			t.Errorf("Expected an error for policy %s", data)
+		}
+	}
+}
+
+func TestFileLoader(t *testing.T) {
+	policyFile := filepath.Join(t.TempDir(), "policy.json")
+	loader := bucketpolicy.NewFileLoader(policyFile)
+
+	if _, err := loader.Policy(); err == nil {
+		t.Fatal("Expected an error for a missing policy file")
+	}
+
+	assert.NoError(t, os.WriteFile(policyFile, []byte(testPolicy), 0o600))
+	policy, err := loader.Policy()
+	assert.NoError(t, err)
+	assert.NoError(t, policy.Check("team-a", "team-a-logs", ""))
+
+	// The policy is read again once the file changes
+	assert.NoError(t, os.WriteFile(policyFile, []byte(`{"allowUnlistedNamespaces": true}`), 0o600))
+	assert.NoError(t, os.Chtimes(policyFile, time.Now(), time.Now().Add(time.Minute)))
+	policy, err = loader.Policy()
+	assert.NoError(t, err)
+	assert.NoError(t, policy.Check("default", "anything", ""))
+}
@@ -0,0 +1,191 @@
+// Package bucketregion discovers the region of buckets mounted without a `--region` mount option, so Mountpoint signs
+// its requests for the right region and mounts of missing or forbidden buckets fail with a clear error instead of an
+// opaque Mountpoint error.
+package bucketregion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"k8s.io/klog/v2"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+)
+
+// EnvRegionDiscoveryEnabled is the environment variable enabling the discovery of bucket regions.
+const EnvRegionDiscoveryEnabled = "REGION_DISCOVERY_ENABLED"
+
+// CacheTTL is how long discovered regions are cached, buckets cannot move to another region but can be recreated.
+const CacheTTL = time.Hour
+
+const (
+	// defaultRegion is the region of buckets whose location constraint is empty, and the region requests
+	// discovering regions are signed for.
+	defaultRegion = "us-east-1"
+	// resolveTimeout bounds the time spent discovering the region of a bucket.
+	resolveTimeout = 10 * time.Second
+)
+
+var (
+	// ErrBucketNotFound is returned when the bucket does not exist on the S3 endpoint.
+	ErrBucketNotFound = errors.New("bucket does not exist")
+	// ErrAccessDenied is returned when the credentials of the volume are not allowed to access the bucket.
+	ErrAccessDenied = errors.New("access to the bucket is denied")
+)
+
+// API is the subset of the S3 client used to discover bucket regions.
+type API interface {
+	GetBucketLocation(ctx context.Context, params *s3.GetBucketLocationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLocationOutput, error)
+	HeadBucket(ctx context.Context, params *s3.HeadBucketInput, optFns ...func(*s3.Options)) (*s3.HeadBucketOutput, error)
+}
+
+type cacheEntry struct {
+	region  string
+	expires time.Time
+}
+
+// A Resolver discovers the region of buckets with `GetBucketLocation`, falling back to `HeadBucket` if the
+// credentials of the volume are not allowed to get the location, and caches discovered regions per endpoint, bucket
+// and access key, so a bucket discovered with some credentials is still checked with other ones.
+type Resolver struct {
+	client API
+	now    func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cacheEntry
+}
+
+// NewResolver creates a new [Resolver] using `client`.
+func NewResolver(client API) *Resolver {
+	return &Resolver{client: client, now: time.Now, cache: make(map[string]cacheEntry)}
+}
+
+// NewResolverFromEnv returns a new [Resolver] for the driver-level S3 endpoint, nil if region discovery is not
+// enabled.
+func NewResolverFromEnv(ctx context.Context) (*Resolver, error) {
+	if os.Getenv(EnvRegionDiscoveryEnabled) != "true" {
+		return nil, nil
+	}
+	awsCfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(defaultRegion))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		o.UsePathStyle = true
+		o.BaseEndpoint = aws.String(os.Getenv(envprovider.EnvEndpointURL))
+	})
+	klog.Infoln("bucketregion: Regions of buckets mounted without --region are discovered")
+	return NewResolver(client), nil
+}
+
+// Resolve returns the region of `bucket` on `endpointURL`, the driver-level endpoint if empty, accessed with
+// `credentials`. It returns [ErrBucketNotFound] or [ErrAccessDenied] if the bucket does not exist or cannot be
+// accessed.
+func (r *Resolver) Resolve(ctx context.Context, bucket, endpointURL string, credentials aws.CredentialsProvider) (string, error) {
+	creds, err := credentials.Retrieve(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve credentials to discover the region of bucket %s: %w", bucket, err)
+	}
+	key := endpointURL + "/" + bucket + "/" + creds.AccessKeyID
+	r.mu.Lock()
+	entry, ok := r.cache[key]
+	r.mu.Unlock()
+	if ok && r.now().Before(entry.expires) {
+		return entry.region, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, resolveTimeout)
+	defer cancel()
+	optFn := func(o *s3.Options) {
+		o.Credentials = credentials
+		if endpointURL != "" {
+			o.BaseEndpoint = aws.String(endpointURL)
+		}
+	}
+
+	region, err := r.getBucketLocation(ctx, bucket, optFn)
+	if errors.Is(err, ErrAccessDenied) {
+		// Getting the location requires its own permission, HeadBucket only requires listing the bucket
+		klog.V(4).Infof("bucketregion: Cannot get the location of bucket %s, falling back to HeadBucket: %v", bucket, err)
+		region, err = r.headBucket(ctx, bucket, optFn)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	r.mu.Lock()
+	r.cache[key] = cacheEntry{region: region, expires: r.now().Add(CacheTTL)}
+	r.mu.Unlock()
+	klog.V(4).Infof("bucketregion: Bucket %s is in region %s", bucket, region)
+	return region, nil
+}
+
+func (r *Resolver) getBucketLocation(ctx context.Context, bucket string, optFn func(*s3.Options)) (string, error) {
+	output, err := r.client.GetBucketLocation(ctx, &s3.GetBucketLocationInput{Bucket: aws.String(bucket)}, optFn)
+	if err != nil {
+		return "", classify(bucket, err)
+	}
+	if output.LocationConstraint == "" {
+		return defaultRegion, nil
+	}
+	return string(output.LocationConstraint), nil
+}
+
+func (r *Resolver) headBucket(ctx context.Context, bucket string, optFn func(*s3.Options)) (string, error) {
+	output, err := r.client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(bucket)}, optFn)
+	if err != nil {
+		return "", classify(bucket, err)
+	}
+	if output.BucketRegion == nil || *output.BucketRegion == "" {
+		return defaultRegion, nil
+	}
+	return *output.BucketRegion, nil
+}
+
+// classify wraps `err` with [ErrBucketNotFound] or [ErrAccessDenied] depending on its HTTP status.
+func classify(bucket string, err error) error {
+	var statusErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &statusErr) {
+		switch statusErr.HTTPStatusCode() {
+		case http.StatusNotFound:
+			return fmt.Errorf("%w: %s: %v", ErrBucketNotFound, bucket, err)
+		case http.StatusForbidden:
+			return fmt.Errorf("%w: %s: %v", ErrAccessDenied, bucket, err)
+		}
+	}
+	if message := strings.ToLower(err.Error()); strings.Contains(message, "nosuchbucket") {
+		return fmt.Errorf("%w: %s: %v", ErrBucketNotFound, bucket, err)
+	}
+	return fmt.Errorf("failed to discover the region of bucket %s: %w", bucket, err)
+}
+
+// Credentials returns the credentials of a volume to discover the region of its bucket: the `access_key_id`,
+// `secret_access_key` and optional `session_token` of `secretData` if set, the driver-level credentials otherwise.
+func Credentials(secretData map[string]string) aws.CredentialsProvider {
+	accessKeyID := strings.TrimSpace(secretData["access_key_id"])
+	secretAccessKey := strings.TrimSpace(secretData["secret_access_key"])
+	sessionToken := strings.TrimSpace(secretData["session_token"])
+	if accessKeyID != "" && secretAccessKey != "" {
+		return aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: accessKeyID, SecretAccessKey: secretAccessKey, SessionToken: sessionToken, Source: "VolumeSecret"}, nil
+		})
+	}
+	// Read credentials from the environment on every call, as they might be rotated while the driver is running
+	return aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+		return aws.Credentials{
+			AccessKeyID:     os.Getenv(envprovider.EnvAccessKeyID),
+			SecretAccessKey: os.Getenv(envprovider.EnvSecretAccessKey),
+			SessionToken:    os.Getenv(envprovider.EnvSessionToken),
+			Source:          "DriverEnvironment",
+		}, nil
+	})
+}
@@ -0,0 +1,151 @@
+package bucketregion_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/bucketregion"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+// statusError is an error of the S3 client with an HTTP status.
+type statusError struct{ code int }
+
+func (e statusError) Error() string       { return http.StatusText(e.code) }
+func (e statusError) HTTPStatusCode() int { return e.code }
+
+type fakeS3 struct {
+	location        types.BucketLocationConstraint
+	locationErr     error
+	headRegion      *string
+	headErr         error
+	locationCalls   int
+	headBucketCalls int
+}
+
+func (f *fakeS3) GetBucketLocation(ctx context.Context, params *s3.GetBucketLocationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLocationOutput, error) {
+	f.locationCalls++
+	if f.locationErr != nil {
+		return nil, f.locationErr
+	}
+	return &s3.GetBucketLocationOutput{LocationConstraint: f.location}, nil
+}
+
+func (f *fakeS3) HeadBucket(ctx context.Context, params *s3.HeadBucketInput, optFns ...func(*s3.Options)) (*s3.HeadBucketOutput, error) {
+	f.headBucketCalls++
+	if f.headErr != nil {
+		return nil, f.headErr
+	}
+	return &s3.HeadBucketOutput{BucketRegion: f.headRegion}, nil
+}
+
+func TestResolve(t *testing.T) {
+	credentials := bucketregion.Credentials(nil)
+
+	t.Run("location constraint", func(t *testing.T) {
+		client := &fakeS3{location: "eu-west-1"}
+		resolver := bucketregion.NewResolver(client)
+
+		region, err := resolver.Resolve(context.Background(), "bucket", "", credentials)
+		assert.NoError(t, err)
+		assert.Equals(t, "eu-west-1", region)
+
+		// Cached
+		region, err = resolver.Resolve(context.Background(), "bucket", "", credentials)
+		assert.NoError(t, err)
+		assert.Equals(t, "eu-west-1", region)
+		assert.Equals(t, 1, client.locationCalls)
+	})
+
+	t.Run("cached per credentials", func(t *testing.T) {
+		client := &fakeS3{location: "eu-west-1"}
+		resolver := bucketregion.NewResolver(client)
+
+		_, err := resolver.Resolve(context.Background(), "bucket", "", credentials)
+		assert.NoError(t, err)
+
+		// Other credentials might not be allowed to access the bucket
+		client.locationErr = statusError{http.StatusForbidden}
+		client.headErr = statusError{http.StatusForbidden}
+		_, err = resolver.Resolve(context.Background(), "bucket", "", bucketregion.Credentials(map[string]string{
+			"access_key_id":     "volume-key",
+			"secret_access_key": "volume-secret",
+		}))
+		assert.Equals(t, true, errors.Is(err, bucketregion.ErrAccessDenied))
+		assert.Equals(t, 2, client.locationCalls)
+	})
+
+	t.Run("empty location constraint", func(t *testing.T) {
+		region, err := bucketregion.NewResolver(&fakeS3{}).Resolve(context.Background(), "bucket", "", credentials)
+		assert.NoError(t, err)
+		assert.Equals(t, "us-east-1", region)
+	})
+
+	t.Run("falls back to HeadBucket", func(t *testing.T) {
+		client := &fakeS3{locationErr: statusError{http.StatusForbidden}, headRegion: aws.String("ap-south-1")}
+		region, err := bucketregion.NewResolver(client).Resolve(context.Background(), "bucket", "", credentials)
+		assert.NoError(t, err)
+		assert.Equals(t, "ap-south-1", region)
+		assert.Equals(t, 1, client.headBucketCalls)
+	})
+
+	t.Run("bucket not found", func(t *testing.T) {
+		client := &fakeS3{locationErr: statusError{http.StatusNotFound}}
+		_, err := bucketregion.NewResolver(client).Resolve(context.Background(), "bucket", "", credentials)
+		assert.Equals(t, true, errors.Is(err, bucketregion.ErrBucketNotFound))
+		assert.Equals(t, 0, client.headBucketCalls)
+	})
+
+	t.Run("access denied", func(t *testing.T) {
+		client := &fakeS3{locationErr: statusError{http.StatusForbidden}, headErr: statusError{http.StatusForbidden}}
+		_, err := bucketregion.NewResolver(client).Resolve(context.Background(), "bucket", "", credentials)
+		assert.Equals(t, true, errors.Is(err, bucketregion.ErrAccessDenied))
+	})
+
+	t.Run("other errors are not cached", func(t *testing.T) {
+		client := &fakeS3{locationErr: errors.New("connection refused")}
+		resolver := bucketregion.NewResolver(client)
+		_, err := resolver.Resolve(context.Background(), "bucket", "", credentials)
+		if err == nil || errors.Is(err, bucketregion.ErrBucketNotFound) || errors.Is(err, bucketregion.ErrAccessDenied) {
+			t.Fatalf("Expected a discovery error, got %v", err)
+		}
+
+		client.locationErr = nil
+		client.location = "eu-west-1"
+		region, err := resolver.Resolve(context.Background(), "bucket", "", credentials)
+		assert.NoError(t, err)
+		assert.Equals(t, "eu-west-1", region)
+	})
+}
+
+func TestCredentials(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "driverAccessKey")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "driverSecret")
+
+	creds, err := bucketregion.Credentials(nil).Retrieve(context.Background())
+	assert.NoError(t, err)
+	assert.Equals(t, "driverAccessKey", creds.AccessKeyID)
+
+	creds, err = bucketregion.Credentials(map[string]string{
+		"access_key_id":     "volumeAccessKey",
+		"secret_access_key": "volumeSecret",
+	}).Retrieve(context.Background())
+	assert.NoError(t, err)
+	assert.Equals(t, "volumeAccessKey", creds.AccessKeyID)
+	assert.Equals(t, "volumeSecret", creds.SecretAccessKey)
+	assert.Equals(t, "", creds.SessionToken)
+
+	creds, err = bucketregion.Credentials(map[string]string{
+		"access_key_id":     "volumeAccessKey",
+		"secret_access_key": "volumeSecret",
+		"session_token":     "volumeToken",
+	}).Retrieve(context.Background())
+	assert.NoError(t, err)
+	assert.Equals(t, "volumeToken", creds.SessionToken)
+}
@@ -0,0 +1,43 @@
+package node
+
+import (
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+)
+
+// caBundleSecret returns the Secret holding the CA bundle of the volume with `volumeCtx`, see
+// [volumecontext.CABundleSecretRef]. Its name is empty if the volume uses the driver-level CAs.
+//
+// Inline ephemeral volumes are defined by users, they can only reference Secrets in their Pod's namespace.
+func caBundleSecret(volumeCtx map[string]string, ephemeral bool) (types.NamespacedName, error) {
+	ref := volumeCtx[volumecontext.CABundleSecretRef]
+	if ref == "" {
+		return types.NamespacedName{}, nil
+	}
+
+	podNamespace := volumeCtx[volumecontext.CSIPodNamespace]
+	secret := types.NamespacedName{Namespace: podNamespace, Name: ref}
+	if namespace, name, ok := strings.Cut(ref, "/"); ok {
+		secret = types.NamespacedName{Namespace: namespace, Name: name}
+	}
+
+	if secret.Namespace == "" {
+		return types.NamespacedName{}, status.Errorf(codes.InvalidArgument, "%s %q has no namespace and Pod namespace not provided", volumecontext.CABundleSecretRef, ref)
+	}
+	if errs := validation.IsDNS1123Label(secret.Namespace); len(errs) > 0 {
+		return types.NamespacedName{}, status.Errorf(codes.InvalidArgument, "Invalid namespace in %s %q: %s", volumecontext.CABundleSecretRef, ref, strings.Join(errs, ", "))
+	}
+	if errs := validation.IsDNS1123Subdomain(secret.Name); len(errs) > 0 {
+		return types.NamespacedName{}, status.Errorf(codes.InvalidArgument, "Invalid Secret name in %s %q: %s", volumecontext.CABundleSecretRef, ref, strings.Join(errs, ", "))
+	}
+	if ephemeral && secret.Namespace != podNamespace {
+		return types.NamespacedName{}, status.Errorf(codes.InvalidArgument, "%s of inline ephemeral volumes must be in the Pod's namespace %q", volumecontext.CABundleSecretRef, podNamespace)
+	}
+	return secret, nil
+}
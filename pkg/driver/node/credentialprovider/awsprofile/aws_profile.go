@@ -54,6 +54,17 @@ type Settings struct {
 	Prefix string
 	// FilePerm specifies the file permissions for created profile files
 	FilePerm fs.FileMode
+	// WriteFile writes profile files, they are written in place with [renameio.WriteFile] if nil.
+	// It must replace files atomically, as Mountpoint might read them at any time.
+	WriteFile func(path string, data []byte, perm fs.FileMode) error
+}
+
+// writeFile writes `content` at `path` with [Settings.WriteFile] if set.
+func (s *Settings) writeFile(path string, content string) error {
+	if s.WriteFile != nil {
+		return s.WriteFile(path, []byte(content), s.FilePerm)
+	}
+	return writeAWSProfileFile(path, content, s.FilePerm)
 }
 
 // prefixed prepends the Settings prefix to the given suffix
@@ -82,14 +93,14 @@ func Create(settings Settings, credentials Credentials) (Profile, error) {
 
 	configFilename := settings.prefixed(awsProfileConfigFilenameSuffix)
 	configPath := settings.path(configFilename)
-	err := writeAWSProfileFile(configPath, configFileContents(name), settings.FilePerm)
+	err := settings.writeFile(configPath, configFileContents(name))
 	if err != nil {
 		return Profile{}, fmt.Errorf("aws-profile: Failed to create config file %s: %v", configPath, err)
 	}
 
 	credentialsFilename := settings.prefixed(awsProfileCredentialsFilenameSuffix)
 	credentialsPath := settings.path(credentialsFilename)
-	err = writeAWSProfileFile(credentialsPath, credentialsFileContents(name, credentials), settings.FilePerm)
+	err = settings.writeFile(credentialsPath, credentialsFileContents(name, credentials))
 	if err != nil {
 		return Profile{}, fmt.Errorf("aws-profile: Failed to create credentials file %s: %v", credentialsPath, err)
 	}
@@ -91,6 +91,34 @@ func TestCreateProfile_InvalidCredentials(t *testing.T) {
 	}
 }
 
+func TestCreateProfile_WithWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	written := map[string]fs.FileMode{}
+	settings := awsprofile.Settings{
+		Basepath: dir,
+		Prefix:   "pushed-",
+		FilePerm: testFilePerm,
+		WriteFile: func(path string, data []byte, perm fs.FileMode) error {
+			written[filepath.Base(path)] = perm
+			return nil
+		},
+	}
+
+	profile, err := awsprofile.Create(settings, awsprofile.Credentials{
+		AccessKeyID:     testAccessKeyID,
+		SecretAccessKey: testSecretAccessKey,
+	})
+	assert.NoError(t, err)
+	assert.Equals(t, map[string]fs.FileMode{
+		profile.ConfigFilename:      testFilePerm,
+		profile.CredentialsFilename: testFilePerm,
+	}, written)
+
+	// Files are only written by `WriteFile`
+	_, err = os.Stat(filepath.Join(dir, profile.CredentialsFilename))
+	assert.Equals(t, true, errors.Is(err, fs.ErrNotExist))
+}
+
 // ------------------------------------------------------------------
 // Cleanup
 // ------------------------------------------------------------------
@@ -0,0 +1,173 @@
+package credentialprovider
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8sv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+)
+
+// EnvVolumeCABundlesEnabled is the environment variable allowing volumes to trust the CA bundle of a Secret,
+// see [ProvideContext.CABundleSecret].
+const EnvVolumeCABundlesEnabled = "VOLUME_CA_BUNDLES_ENABLED"
+
+// CABundleKey is the key of the PEM CA bundle in Secrets referenced by volumes.
+const CABundleKey = "ca-bundle.crt"
+
+// CABundleReloadInterval is how often CA bundles of active mounts are read again from their Secrets.
+const CABundleReloadInterval = time.Minute
+
+// caBundleFilename is the suffix of CA bundle files written for volumes.
+const caBundleFilename = "ca-bundle.crt"
+
+// A caBundle is a CA bundle written for a volume from a Secret.
+type caBundle struct {
+	secret types.NamespacedName
+	data   []byte
+}
+
+// SetSecretsClient sets the client used to read CA bundle Secrets of volumes. Volumes with a CA bundle fail to mount
+// if it is not set.
+func (c *Provider) SetSecretsClient(secrets k8sv1.SecretsGetter) {
+	c.caBundlesMu.Lock()
+	defer c.caBundlesMu.Unlock()
+	c.secrets = secrets
+}
+
+// provideCABundle writes the CA bundle of [ProvideContext.CABundleSecret] in [ProvideContext.WritePath], and returns
+// the environment variable pointing Mountpoint to it.
+func (c *Provider) provideCABundle(ctx context.Context, provideCtx ProvideContext) (envprovider.Environment, error) {
+	c.caBundlesMu.Lock()
+	defer c.caBundlesMu.Unlock()
+
+	if c.secrets == nil {
+		return nil, fmt.Errorf("credentialprovider: CA bundle Secret %s cannot be read, volume CA bundles are disabled", provideCtx.CABundleSecret)
+	}
+	data, err := c.readCABundle(ctx, provideCtx.CABundleSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(provideCtx.WritePath, caBundleFilePrefix(provideCtx.PodID, provideCtx.VolumeID)+caBundleFilename)
+	if err := writeCABundle(path, data); err != nil {
+		return nil, err
+	}
+	if c.caBundles == nil {
+		c.caBundles = make(map[string]*caBundle)
+	}
+	c.caBundles[path] = &caBundle{secret: provideCtx.CABundleSecret, data: data}
+	klog.V(4).Infof("credentialprovider: volume %s trusts the CA bundle of Secret %s", provideCtx.VolumeID, provideCtx.CABundleSecret)
+
+	return envprovider.Environment{
+		envprovider.EnvCABundle: filepath.Join(provideCtx.EnvPath, filepath.Base(path)),
+	}, nil
+}
+
+// WatchCABundles rewrites CA bundles of active mounts whose Secret changed every `interval` until `stopCh` is closed.
+// See [Provider.ReloadCABundles].
+func (c *Provider) WatchCABundles(stopCh <-chan struct{}, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			if err := c.ReloadCABundles(ctx); err != nil {
+				klog.Errorf("credentialprovider: Failed to reload CA bundles: %v", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// ReloadCABundles reads the Secrets of CA bundles written for active mounts, and rewrites the bundles that changed.
+//
+// Mountpoint loads its CA bundle when it starts, rotated CAs are used by Mountpoint Pods started afterwards.
+// Rotating a CA therefore requires a bundle trusting both the old and new CAs until every Mountpoint Pod using the
+// volume was restarted.
+func (c *Provider) ReloadCABundles(ctx context.Context) error {
+	c.caBundlesMu.Lock()
+	defer c.caBundlesMu.Unlock()
+
+	var errs []error
+	for path, bundle := range c.caBundles {
+		data, err := c.readCABundle(ctx, bundle.secret)
+		if err != nil {
+			// Keep the current bundle, e.g. if the Secret is being updated
+			errs = append(errs, err)
+			continue
+		}
+		if bytes.Equal(data, bundle.data) {
+			continue
+		}
+		if err := writeCABundle(path, data); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		bundle.data = data
+		klog.Infof("credentialprovider: CA bundle of Secret %s rotated, rewrote %s", bundle.secret, path)
+	}
+	return errors.Join(errs...)
+}
+
+// cleanupCABundle removes the CA bundle written for the volume of `cleanupCtx`, if any.
+func (c *Provider) cleanupCABundle(cleanupCtx CleanupContext) error {
+	c.caBundlesMu.Lock()
+	defer c.caBundlesMu.Unlock()
+
+	path := filepath.Join(cleanupCtx.WritePath, caBundleFilePrefix(cleanupCtx.PodID, cleanupCtx.VolumeID)+caBundleFilename)
+	delete(c.caBundles, path)
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("credentialprovider: failed to remove CA bundle %s: %w", path, err)
+	}
+	return nil
+}
+
+// readCABundle reads the CA bundle of `secret`, it must contain at least one certificate.
+// It must be called with `caBundlesMu` held.
+func (c *Provider) readCABundle(ctx context.Context, secret types.NamespacedName) ([]byte, error) {
+	object, err := c.secrets.Secrets(secret.Namespace).Get(ctx, secret.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("credentialprovider: failed to get CA bundle Secret %s: %w", secret, err)
+	}
+	data, ok := object.Data[CABundleKey]
+	if !ok {
+		return nil, fmt.Errorf("credentialprovider: CA bundle Secret %s has no %q key", secret, CABundleKey)
+	}
+	if !x509.NewCertPool().AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("credentialprovider: no certificate found in %q of CA bundle Secret %s", CABundleKey, secret)
+	}
+	return data, nil
+}
+
+// writeCABundle atomically writes `data` at `path`, so Mountpoint never reads a partial bundle.
+func writeCABundle(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, CredentialFilePerm); err != nil {
+		return fmt.Errorf("credentialprovider: failed to write CA bundle %s: %w", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("credentialprovider: failed to write CA bundle %s: %w", path, err)
+	}
+	return nil
+}
+
+// caBundleFilePrefix returns the prefix of the CA bundle file of a volume, the same as its AWS profile.
+func caBundleFilePrefix(podID, volumeID string) string {
+	return escapedVolumeIdentifier(podID, volumeID) + "-"
+}
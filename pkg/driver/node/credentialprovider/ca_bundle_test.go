@@ -0,0 +1,147 @@
+package credentialprovider_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io/fs"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestProvideCABundle(t *testing.T) {
+	setEnvForLongTermCredentials(t)
+	firstCA, secondCA := testCACertificate(t, "first"), testCACertificate(t, "second")
+
+	secretRef := types.NamespacedName{Namespace: "storage", Name: "site-b-ca"}
+	client := fake.NewClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: secretRef.Namespace, Name: secretRef.Name},
+		Data:       map[string][]byte{credentialprovider.CABundleKey: firstCA},
+	})
+	provider := credentialprovider.New(nil)
+
+	writePath := t.TempDir()
+	provideCtx := credentialprovider.ProvideContext{
+		AuthenticationSource: credentialprovider.AuthenticationSourceDriver,
+		WritePath:            writePath,
+		EnvPath:              testEnvPath,
+		PodID:                testPodID,
+		VolumeID:             testVolumeID,
+		CABundleSecret:       secretRef,
+	}
+
+	// Volume CA bundles are disabled without a Secrets client
+	_, _, err := provider.Provide(context.Background(), provideCtx)
+	if err == nil {
+		t.Fatal("Expected an error without a Secrets client")
+	}
+
+	provider.SetSecretsClient(client.CoreV1())
+	env, _, err := provider.Provide(context.Background(), provideCtx)
+	assert.NoError(t, err)
+	assert.Equals(t, "/test-env/"+testProfilePrefix+"ca-bundle.crt", env[envprovider.EnvCABundle])
+	assert.Equals(t, testProfilePrefix+"s3-csi", env[envprovider.EnvProfile])
+
+	bundlePath := filepath.Join(writePath, testProfilePrefix+"ca-bundle.crt")
+	assertCABundle(t, bundlePath, firstCA)
+
+	// Unchanged Secrets are not rewritten, rotated ones are
+	assert.NoError(t, provider.ReloadCABundles(context.Background()))
+	assertCABundle(t, bundlePath, firstCA)
+
+	rotated := append(append([]byte{}, firstCA...), secondCA...)
+	_, err = client.CoreV1().Secrets(secretRef.Namespace).Update(context.Background(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: secretRef.Namespace, Name: secretRef.Name},
+		Data:       map[string][]byte{credentialprovider.CABundleKey: rotated},
+	}, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, provider.ReloadCABundles(context.Background()))
+	assertCABundle(t, bundlePath, rotated)
+
+	// Invalid bundles are rejected, the current one is kept
+	_, err = client.CoreV1().Secrets(secretRef.Namespace).Update(context.Background(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: secretRef.Namespace, Name: secretRef.Name},
+		Data:       map[string][]byte{credentialprovider.CABundleKey: []byte("not a certificate")},
+	}, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	if err := provider.ReloadCABundles(context.Background()); err == nil {
+		t.Fatal("Expected an error for an invalid CA bundle")
+	}
+	assertCABundle(t, bundlePath, rotated)
+
+	assert.NoError(t, provider.Cleanup(credentialprovider.CleanupContext{WritePath: writePath, PodID: testPodID, VolumeID: testVolumeID}))
+	if _, err := os.Stat(bundlePath); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Expected CA bundle to be removed, got %v", err)
+	}
+	// Removed bundles are not reloaded anymore
+	assert.NoError(t, provider.ReloadCABundles(context.Background()))
+}
+
+func TestProvideCABundleWithMissingSecret(t *testing.T) {
+	setEnvForLongTermCredentials(t)
+	provider := credentialprovider.New(nil)
+	provider.SetSecretsClient(fake.NewClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "storage", Name: "no-bundle"},
+		Data:       map[string][]byte{"tls.crt": testCACertificate(t, "ca")},
+	}).CoreV1())
+
+	for _, name := range []string{"missing", "no-bundle"} {
+		_, _, err := provider.Provide(context.Background(), credentialprovider.ProvideContext{
+			WritePath:      t.TempDir(),
+			EnvPath:        testEnvPath,
+			PodID:          testPodID,
+			VolumeID:       testVolumeID,
+			CABundleSecret: types.NamespacedName{Namespace: "storage", Name: name},
+		})
+		if err == nil {
+			t.Fatalf("Expected an error for Secret %s", name)
+		}
+	}
+}
+
+func assertCABundle(t *testing.T, path string, expected []byte) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equals(t, string(expected), string(data))
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equals(t, credentialprovider.CredentialFilePerm, info.Mode().Perm())
+}
+
+// testCACertificate returns a PEM self-signed CA certificate with `commonName`.
+func testCACertificate(t *testing.T, commonName string) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
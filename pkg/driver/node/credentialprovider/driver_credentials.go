@@ -0,0 +1,148 @@
+package credentialprovider
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider/awsprofile"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+)
+
+// EnvDriverCredentialsDir is the environment variable pointing to a directory where the driver-level credentials
+// Secret is mounted. If set, driver-level credentials are periodically reloaded from this directory,
+// so rotated credentials are used without restarting the CSI Driver Node Pod.
+const EnvDriverCredentialsDir = "DRIVER_CREDENTIALS_DIR"
+
+// EnvDriverCredentialsReloadInterval is the environment variable configuring how often
+// driver-level credentials are reloaded from [EnvDriverCredentialsDir].
+const EnvDriverCredentialsReloadInterval = "DRIVER_CREDENTIALS_RELOAD_INTERVAL"
+
+// DefaultDriverCredentialsReloadInterval is the default interval to reload driver-level credentials.
+const DefaultDriverCredentialsReloadInterval = 30 * time.Second
+
+// Filenames of driver-level credentials in [EnvDriverCredentialsDir].
+const (
+	driverCredentialsAccessKeyIDFile     = "access_key_id"
+	driverCredentialsSecretAccessKeyFile = "secret_access_key"
+	driverCredentialsSessionTokenFile    = "session_token"
+)
+
+// WatchDriverCredentials reloads driver-level credentials from `dir` every `interval` until `stopCh` is closed.
+// See [Provider.ReloadDriverCredentials].
+func (c *Provider) WatchDriverCredentials(stopCh <-chan struct{}, dir string, interval time.Duration) {
+	klog.Infof("credentialprovider: Watching driver-level credentials in %s every %v", dir, interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			if _, err := c.ReloadDriverCredentials(dir); err != nil {
+				klog.Errorf("credentialprovider: Failed to reload driver-level credentials from %s: %v", dir, err)
+			}
+		}
+	}
+}
+
+// ReloadDriverCredentials reads driver-level credentials from `dir` and, if they differ from the ones in use,
+// updates the driver's environment variables and rewrites all AWS profiles previously written with driver-level
+// credentials. It returns whether the credentials have changed.
+//
+// Mountpoint reads its AWS profile again when its cached credentials are refreshed, so existing mounts
+// pick up rotated credentials without being remounted.
+func (c *Provider) ReloadDriverCredentials(dir string) (bool, error) {
+	accessKeyID, err := readCredentialFile(dir, driverCredentialsAccessKeyIDFile)
+	if err != nil {
+		return false, err
+	}
+	secretAccessKey, err := readCredentialFile(dir, driverCredentialsSecretAccessKeyFile)
+	if err != nil {
+		return false, err
+	}
+	sessionToken, err := readCredentialFile(dir, driverCredentialsSessionTokenFile)
+	if err != nil {
+		return false, err
+	}
+	if accessKeyID == "" || secretAccessKey == "" {
+		// Most likely in the middle of a Secret update, keep using current credentials
+		return false, fmt.Errorf("access key ID or secret access key is empty in %s", dir)
+	}
+
+	if accessKeyID == os.Getenv(envprovider.EnvAccessKeyID) &&
+		secretAccessKey == os.Getenv(envprovider.EnvSecretAccessKey) &&
+		sessionToken == os.Getenv(envprovider.EnvSessionToken) {
+		return false, nil
+	}
+
+	credentials := awsprofile.Credentials{
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: secretAccessKey,
+		SessionToken:    sessionToken,
+	}
+
+	// Update the environment first so new mounts use rotated credentials
+	for key, value := range map[string]string{
+		envprovider.EnvAccessKeyID:     accessKeyID,
+		envprovider.EnvSecretAccessKey: secretAccessKey,
+		envprovider.EnvSessionToken:    sessionToken,
+	} {
+		if err := os.Setenv(key, value); err != nil {
+			return false, fmt.Errorf("failed to set %s: %w", key, err)
+		}
+	}
+
+	c.driverProfilesMu.Lock()
+	defer c.driverProfilesMu.Unlock()
+
+	var errs []error
+	for _, settings := range c.driverProfiles {
+		if _, err := awsprofile.Create(settings, credentials); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	klog.Infof("credentialprovider: Driver-level credentials rotated, rewrote %d AWS profile(s) for active mounts", len(c.driverProfiles)-len(errs))
+	return true, errors.Join(errs...)
+}
+
+// trackDriverProfile records an AWS profile written with driver-level credentials.
+func (c *Provider) trackDriverProfile(settings awsprofile.Settings) {
+	c.driverProfilesMu.Lock()
+	defer c.driverProfilesMu.Unlock()
+	if c.driverProfiles == nil {
+		c.driverProfiles = make(map[string]awsprofile.Settings)
+	}
+	c.driverProfiles[driverProfileKey(settings)] = settings
+}
+
+// untrackDriverProfile forgets an AWS profile recorded with [Provider.trackDriverProfile].
+func (c *Provider) untrackDriverProfile(settings awsprofile.Settings) {
+	c.driverProfilesMu.Lock()
+	defer c.driverProfilesMu.Unlock()
+	delete(c.driverProfiles, driverProfileKey(settings))
+}
+
+func driverProfileKey(settings awsprofile.Settings) string {
+	return filepath.Join(settings.Basepath, settings.Prefix)
+}
+
+// readCredentialFile reads `name` in `dir`, returning an empty string if the file does not exist.
+func readCredentialFile(dir, name string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(dir, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
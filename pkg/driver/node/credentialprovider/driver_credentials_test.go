@@ -0,0 +1,100 @@
+package credentialprovider_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider/awsprofile/awsprofiletest"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+const (
+	rotatedAccessKeyID     = "rotatedAccessKeyID"
+	rotatedSecretAccessKey = "rotated-secret-access-key"
+)
+
+func TestReloadDriverCredentials(t *testing.T) {
+	setEnvForLongTermCredentials(t)
+	provider := credentialprovider.New(nil)
+
+	// Two active mounts using driver-level credentials
+	writePaths := []string{t.TempDir(), t.TempDir()}
+	for _, writePath := range writePaths {
+		_, _, err := provider.Provide(context.Background(), credentialprovider.ProvideContext{
+			AuthenticationSource: credentialprovider.AuthenticationSourceDriver,
+			WritePath:            writePath,
+			EnvPath:              testEnvPath,
+			PodID:                testPodID,
+			VolumeID:             testVolumeID,
+		})
+		assert.NoError(t, err)
+	}
+
+	// The second mount is unmounted
+	assert.NoError(t, provider.Cleanup(credentialprovider.CleanupContext{
+		WritePath: writePaths[1],
+		PodID:     testPodID,
+		VolumeID:  testVolumeID,
+	}))
+
+	dir := t.TempDir()
+	writeDriverCredentials(t, dir, testAccessKeyID, testSecretAccessKey, testSessionToken)
+
+	t.Run("unchanged credentials", func(t *testing.T) {
+		changed, err := provider.ReloadDriverCredentials(dir)
+		assert.NoError(t, err)
+		assert.Equals(t, false, changed)
+		assertLongTermCredentials(t, writePaths[0])
+	})
+
+	t.Run("rotated credentials", func(t *testing.T) {
+		writeDriverCredentials(t, dir, rotatedAccessKeyID, rotatedSecretAccessKey, "")
+		assert.NoError(t, os.Remove(filepath.Join(dir, "session_token")))
+
+		changed, err := provider.ReloadDriverCredentials(dir)
+		assert.NoError(t, err)
+		assert.Equals(t, true, changed)
+
+		assert.Equals(t, rotatedAccessKeyID, os.Getenv("AWS_ACCESS_KEY_ID"))
+		assert.Equals(t, rotatedSecretAccessKey, os.Getenv("AWS_SECRET_ACCESS_KEY"))
+		assert.Equals(t, "", os.Getenv("AWS_SESSION_TOKEN"))
+
+		credentials, err := awsprofiletest.ReadCredentials(filepath.Join(writePaths[0], testProfilePrefix+"s3-csi-credentials"))
+		assert.NoError(t, err)
+		assert.Equals(t, map[string]map[string]string{
+			testProfilePrefix + "s3-csi": {
+				"aws_access_key_id":     rotatedAccessKeyID,
+				"aws_secret_access_key": rotatedSecretAccessKey,
+			},
+		}, credentials)
+
+		// Cleaned up profiles must not be recreated
+		_, err = os.Stat(filepath.Join(writePaths[1], testProfilePrefix+"s3-csi-credentials"))
+		assert.Equals(t, true, os.IsNotExist(err))
+	})
+
+	t.Run("incomplete credentials are ignored", func(t *testing.T) {
+		writeDriverCredentials(t, dir, "", "", "")
+
+		changed, err := provider.ReloadDriverCredentials(dir)
+		if err == nil {
+			t.Fatal("Expected an error for empty credentials")
+		}
+		assert.Equals(t, false, changed)
+		assert.Equals(t, rotatedAccessKeyID, os.Getenv("AWS_ACCESS_KEY_ID"))
+	})
+}
+
+func writeDriverCredentials(t *testing.T, dir, accessKeyID, secretAccessKey, sessionToken string) {
+	t.Helper()
+	for name, value := range map[string]string{
+		"access_key_id":     accessKeyID,
+		"secret_access_key": secretAccessKey,
+		"session_token":     sessionToken,
+	} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(value+"\n"), 0o600))
+	}
+}
@@ -4,14 +4,18 @@ package credentialprovider
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/fs"
 	"strings"
+	"sync"
 
+	"k8s.io/apimachinery/pkg/types"
 	k8sv1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/klog/v2"
 	k8sstrings "k8s.io/utils/strings"
 
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider/awsprofile"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
 )
 
@@ -22,10 +26,10 @@ const CredentialFilePerm = fs.FileMode(0o640)
 
 // CredentialDirPerm is the default permissions to be used for credential directories.
 // It's only readable, listable (execute bit), and writeable by the owner and group.
-// Group access is needed as Mountpoint Pod is run as non-root user
-const CredentialDirPerm = fs.FileMode(0o750)
+// Group access is needed as Mountpoint Pod is run as non-root user, and rewrites refreshed credentials
+const CredentialDirPerm = fs.FileMode(0o770)
 
-// An AuthenticationSource represents the source (i.e., driver-level or secret-level) where the credentials was obtained.
+// An AuthenticationSource represents the source (i.e., driver-level, secret-level, assumed role or files) where the credentials was obtained.
 type AuthenticationSource = string
 
 const (
@@ -34,6 +38,16 @@ const (
 	AuthenticationSourceUnspecified AuthenticationSource = ""
 	AuthenticationSourceDriver      AuthenticationSource = "driver"
 	AuthenticationSourceSecret      AuthenticationSource = "secret"
+	// AuthenticationSourceRole assumes the role from the volume context using driver-level credentials,
+	// and provides temporary credentials scoped to that role.
+	AuthenticationSourceRole AuthenticationSource = "role"
+	// AuthenticationSourceFile reads credentials from files of the CSI Driver Node Pod, e.g. projected from an
+	// external secret store, see [EnvCredentialsFileDir].
+	AuthenticationSourceFile AuthenticationSource = "file"
+	// AuthenticationSourceWebIdentity assumes the role from the volume context with the projected service account
+	// token of the workload Pod, e.g. with OIDC federation on Scality Vault, and provides temporary credentials scoped
+	// to that role.
+	AuthenticationSourceWebIdentity AuthenticationSource = "webIdentity"
 )
 
 // MountKind represents the type of mount operation
@@ -49,6 +63,33 @@ const (
 // A Provider provides methods for accessing AWS credentials.
 type Provider struct {
 	client k8sv1.CoreV1Interface
+
+	// driverProfiles keeps track of AWS profiles written with driver-level credentials, keyed by their
+	// credentials file path, to rewrite them when driver-level credentials are rotated.
+	driverProfilesMu sync.Mutex
+	driverProfiles   map[string]awsprofile.Settings
+
+	// roleProfiles keeps track of AWS profiles written with assumed role credentials, keyed by their
+	// credentials file path, to refresh them before they expire.
+	roleProfilesMu sync.Mutex
+	roleProfiles   map[string]*roleProfile
+	stsClient      AssumeRoleAPIClient
+	// webIdentityClient assumes roles with service account tokens of the default `tokenAudience`, unless the
+	// volume context overrides it.
+	webIdentityClient AssumeRoleWithWebIdentityAPIClient
+	tokenAudience     string
+
+	// fileProfiles keeps track of AWS profiles written with credentials read from `credentialsFileDir`, keyed by
+	// their credentials file path, to rewrite them when the files change.
+	fileProfilesMu     sync.Mutex
+	fileProfiles       map[string]*fileProfile
+	credentialsFileDir string
+
+	// caBundles keeps track of CA bundles written for volumes, keyed by their file path, to rewrite them when
+	// their Secrets change.
+	caBundlesMu sync.Mutex
+	caBundles   map[string]*caBundle
+	secrets     k8sv1.SecretsGetter
 }
 
 // A ProvideContext contains parameters needed to provide credentials for a volume mount.
@@ -66,17 +107,40 @@ type ProvideContext struct {
 	WritePath string
 	// EnvPath is basepath to use while creating environment variables to pass Mountpoint.
 	EnvPath string
+	// WriteFile writes credential files in [WritePath], now and when they are refreshed, e.g. through the running
+	// Mountpoint Pod. They are written in place if nil.
+	WriteFile func(path string, data []byte, perm fs.FileMode) error
 
 	PodID    string
 	VolumeID string
+	// MountKind is the kind of mount credentials are provided for, [WritePath] is specific to a Mountpoint Pod
+	// shared by workloads with the same credentials if it is [MountKindPod].
+	MountKind MountKind
 
 	// The following values are provided from CSI volume context.
 	AuthenticationSource AuthenticationSource
 	PodNamespace         string
+	// PodName is the name of the workload Pod, it is only used to look up its labels for telemetry tags.
+	PodName string
+	// ServiceAccountName is the service account of the workload Pod.
+	ServiceAccountName string
 	// BucketRegion is the `--region` parameter passed via mount options.
 	BucketRegion string
 	// SecretData is a map of key-value pairs from the Kubernetes Secret referenced by nodePublishSecretRef.
 	SecretData map[string]string
+	// RoleARN is the role to assume if [AuthenticationSource] is `role` or `webIdentity`.
+	RoleARN string
+	// ServiceAccountTokens is the JSON-encoded service account tokens of the workload Pod, keyed by audience, as
+	// requested by the `tokenRequests` of the CSIDriver.
+	ServiceAccountTokens string
+	// ServiceAccountTokenAudience is the audience of the token used if [AuthenticationSource] is `webIdentity`,
+	// the driver-level audience if empty.
+	ServiceAccountTokenAudience string
+	// CredentialsName is the subdirectory of the credentials file directory to read credentials from if
+	// [AuthenticationSource] is `file`.
+	CredentialsName string
+	// CABundleSecret is the Secret holding the CA bundle Mountpoint trusts for this volume, none if its name is empty.
+	CABundleSecret types.NamespacedName
 }
 
 // SetWriteAndEnvPath sets `WritePath` and `EnvPath` for `ctx`.
@@ -96,7 +160,7 @@ type CleanupContext struct {
 
 // New creates a new [Provider] with given client.
 func New(client k8sv1.CoreV1Interface) *Provider {
-	return &Provider{client}
+	return &Provider{client: client}
 }
 
 // Provide provides credentials for given context.
@@ -105,6 +169,20 @@ func New(client k8sv1.CoreV1Interface) *Provider {
 // - If secret authentication is requested but no node-publish secrets are available, falls back to driver credentials
 // - This is because the node service cannot access provisioner secrets (CSI spec limitation)
 func (c *Provider) Provide(ctx context.Context, provideCtx ProvideContext) (envprovider.Environment, AuthenticationSource, error) {
+	env, authenticationSource, err := c.provideCredentials(ctx, provideCtx)
+	if err != nil || provideCtx.CABundleSecret.Name == "" {
+		return env, authenticationSource, err
+	}
+	caBundleEnv, err := c.provideCABundle(ctx, provideCtx)
+	if err != nil {
+		return nil, authenticationSource, err
+	}
+	env.Merge(caBundleEnv)
+	return env, authenticationSource, nil
+}
+
+// provideCredentials provides credentials of the authentication source of `provideCtx`.
+func (c *Provider) provideCredentials(ctx context.Context, provideCtx ProvideContext) (envprovider.Environment, AuthenticationSource, error) {
 	authenticationSource := provideCtx.AuthenticationSource
 	switch authenticationSource {
 	case AuthenticationSourceSecret:
@@ -121,17 +199,26 @@ func (c *Provider) Provide(ctx context.Context, provideCtx ProvideContext) (envp
 		}
 		env, err := c.provideFromSecret(ctx, provideCtx)
 		return env, AuthenticationSourceSecret, err
+	case AuthenticationSourceRole:
+		env, err := c.provideFromRole(ctx, provideCtx)
+		return env, AuthenticationSourceRole, err
+	case AuthenticationSourceFile:
+		env, err := c.provideFromFile(provideCtx)
+		return env, AuthenticationSourceFile, err
+	case AuthenticationSourceWebIdentity:
+		env, err := c.provideFromWebIdentity(ctx, provideCtx)
+		return env, AuthenticationSourceWebIdentity, err
 	case AuthenticationSourceUnspecified, AuthenticationSourceDriver:
 		env, err := c.provideFromDriver(provideCtx)
 		return env, AuthenticationSourceDriver, err
 	default:
-		return nil, AuthenticationSourceUnspecified, fmt.Errorf("unknown `authenticationSource`: %s, only `driver` (default option if not specified) and `secret` supported", authenticationSource)
+		return nil, AuthenticationSourceUnspecified, fmt.Errorf("unknown `authenticationSource`: %s, only `driver` (default option if not specified), `secret`, `role`, `file` and `webIdentity` supported", authenticationSource)
 	}
 }
 
 // Cleanup cleans any previously created credential files for given context.
 func (c *Provider) Cleanup(cleanupCtx CleanupContext) error {
-	return c.cleanupFromDriver(cleanupCtx)
+	return errors.Join(c.cleanupFromDriver(cleanupCtx), c.cleanupCABundle(cleanupCtx))
 }
 
 // escapedVolumeIdentifier returns "{podID}-{volumeID}" as a unique identifier for this volume.
@@ -1,6 +1,7 @@
 package credentialprovider
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -25,52 +26,66 @@ func (c *Provider) provideFromDriver(provideCtx ProvideContext) (envprovider.Env
 	}
 
 	sessionToken := os.Getenv(envprovider.EnvSessionToken)
-	longTermCredsEnv, err := provideLongTermCredentialsFromDriver(provideCtx, accessKeyID, secretAccessKey, sessionToken)
+	longTermCredsEnv, settings, err := provideLongTermCredentialsFromDriver(provideCtx, accessKeyID, secretAccessKey, sessionToken)
 	if err != nil {
 		klog.V(4).ErrorS(err, "credentialprovider: Failed to provide static IAM credentials")
 		return nil, err
 	}
+	c.trackDriverProfile(settings)
 
 	env.Merge(longTermCredsEnv)
 	return env, nil
 }
 
-// cleanupFromDriver removes any credential files that were created for driver-level authentication via [Provider.provideFromDriver].
+// cleanupFromDriver removes any credential files that were created for driver-level authentication via [Provider.provideFromDriver],
+// or for role and file authentication via [Provider.provideFromRole] and [Provider.provideFromFile] as they use the same filenames.
+// Profiles with assumed role credentials of a Mountpoint Pod are all removed with it, see [Provider.provideFromWebIdentity].
 func (c *Provider) cleanupFromDriver(cleanupCtx CleanupContext) error {
 	prefix := driverLevelLongTermCredentialsProfilePrefix(cleanupCtx.PodID, cleanupCtx.VolumeID)
-	return awsprofile.Cleanup(awsprofile.Settings{
+	settings := awsprofile.Settings{
 		Basepath: cleanupCtx.WritePath,
 		Prefix:   prefix,
-	})
+	}
+	c.untrackDriverProfile(settings)
+	c.untrackRoleProfile(settings)
+	c.untrackFileProfile(settings)
+	if cleanupCtx.MountKind == MountKindPod {
+		return errors.Join(c.cleanupRoleProfilesIn(cleanupCtx.WritePath), awsprofile.Cleanup(settings))
+	}
+	return awsprofile.Cleanup(settings)
 }
 
 // provideLongTermCredentialsFromDriver provides long-term AWS credentials from the driver's environment variables.
 // These variables injected to driver's Pod from a configured Kubernetes secret if configured, here it basically
 // created a AWS Profile from these credentials in [provideCtx.WritePath].
-func provideLongTermCredentialsFromDriver(provideCtx ProvideContext, accessKeyID, secretAccessKey, sessionToken string) (envprovider.Environment, error) {
+// It also returns settings used to create the profile, to rewrite it if credentials are rotated.
+func provideLongTermCredentialsFromDriver(provideCtx ProvideContext, accessKeyID, secretAccessKey, sessionToken string) (envprovider.Environment, awsprofile.Settings, error) {
 	prefix := driverLevelLongTermCredentialsProfilePrefix(provideCtx.PodID, provideCtx.VolumeID)
-	awsProfile, err := awsprofile.Create(awsprofile.Settings{
-		Basepath: provideCtx.WritePath,
-		Prefix:   prefix,
-		FilePerm: CredentialFilePerm,
-	}, awsprofile.Credentials{
+	settings := awsprofile.Settings{
+		Basepath:  provideCtx.WritePath,
+		Prefix:    prefix,
+		FilePerm:  CredentialFilePerm,
+		WriteFile: provideCtx.WriteFile,
+	}
+	awsProfile, err := awsprofile.Create(settings, awsprofile.Credentials{
 		AccessKeyID:     accessKeyID,
 		SecretAccessKey: secretAccessKey,
 		SessionToken:    sessionToken,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("credentialprovider: long-term: failed to create aws profile: %w", err)
+		return nil, settings, fmt.Errorf("credentialprovider: long-term: failed to create aws profile: %w", err)
 	}
 
-	profile := awsProfile.Name
-	configFile := filepath.Join(provideCtx.EnvPath, awsProfile.ConfigFilename)
-	credentialsFile := filepath.Join(provideCtx.EnvPath, awsProfile.CredentialsFilename)
+	return profileEnvironment(provideCtx, awsProfile), settings, nil
+}
 
+// profileEnvironment returns environment variables to pass Mountpoint to use `awsProfile` written in [provideCtx.WritePath].
+func profileEnvironment(provideCtx ProvideContext, awsProfile awsprofile.Profile) envprovider.Environment {
 	return envprovider.Environment{
-		envprovider.EnvProfile:               profile,
-		envprovider.EnvConfigFile:            configFile,
-		envprovider.EnvSharedCredentialsFile: credentialsFile,
-	}, nil
+		envprovider.EnvProfile:               awsProfile.Name,
+		envprovider.EnvConfigFile:            filepath.Join(provideCtx.EnvPath, awsProfile.ConfigFilename),
+		envprovider.EnvSharedCredentialsFile: filepath.Join(provideCtx.EnvPath, awsProfile.CredentialsFilename),
+	}
 }
 
 // driverLevelLongTermCredentialsProfilePrefix generates a prefix for AWS credential profile names
@@ -0,0 +1,163 @@
+package credentialprovider
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider/awsprofile"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+)
+
+// EnvCredentialsFileDir is the environment variable pointing to the directory of the CSI Driver Node Pod holding
+// credentials of volumes with `authenticationSource: file`, e.g. a Secrets Store CSI volume or files written by a
+// Vault agent. Credentials named `name` are read from the `access_key_id`, `secret_access_key` and optional
+// `session_token` files of its `name` subdirectory.
+const EnvCredentialsFileDir = "CREDENTIALS_FILE_DIR"
+
+// EnvCredentialsFileReloadInterval is the environment variable configuring how often credentials of volumes with
+// `authenticationSource: file` are read again from [EnvCredentialsFileDir].
+const EnvCredentialsFileReloadInterval = "CREDENTIALS_FILE_RELOAD_INTERVAL"
+
+// A fileProfile is an AWS profile written with credentials read from the credentials file directory.
+type fileProfile struct {
+	settings    awsprofile.Settings
+	name        string
+	credentials awsprofile.Credentials
+}
+
+// SetCredentialsFileDir sets the directory credentials of volumes with `authenticationSource: file` are read from,
+// see [EnvCredentialsFileDir]. The authentication source is rejected if it is not set.
+func (c *Provider) SetCredentialsFileDir(dir string) {
+	c.fileProfilesMu.Lock()
+	defer c.fileProfilesMu.Unlock()
+	c.credentialsFileDir = dir
+}
+
+// provideFromFile provides the credentials named by the volume context from the credentials file directory to
+// Mountpoint through an AWS profile. The profile is rewritten when the files change, see
+// [Provider.ReloadFileCredentials].
+func (c *Provider) provideFromFile(provideCtx ProvideContext) (envprovider.Environment, error) {
+	name := provideCtx.CredentialsName
+	if name == "" {
+		return nil, fmt.Errorf("credentialprovider: `authenticationSource` is `file` but no credentials name provided")
+	}
+	if name != filepath.Base(name) || name == "." || name == ".." {
+		return nil, fmt.Errorf("credentialprovider: invalid credentials name %q, must be a single path segment", name)
+	}
+	klog.V(4).Infof("credentialprovider: Using credentials %s from files for volume %s", name, provideCtx.VolumeID)
+
+	c.fileProfilesMu.Lock()
+	defer c.fileProfilesMu.Unlock()
+
+	if c.credentialsFileDir == "" {
+		return nil, fmt.Errorf("credentialprovider: `authenticationSource` is `file` but no credentials file directory is configured on the node")
+	}
+	credentials, err := readCredentialFiles(filepath.Join(c.credentialsFileDir, name))
+	if err != nil {
+		return nil, fmt.Errorf("credentialprovider: failed to read credentials %s: %w", name, err)
+	}
+
+	profile := &fileProfile{
+		// Use the same filenames as driver-level credentials, as a volume only uses one authentication source
+		// and they are removed the same way on unmount.
+		settings: awsprofile.Settings{
+			Basepath:  provideCtx.WritePath,
+			Prefix:    driverLevelLongTermCredentialsProfilePrefix(provideCtx.PodID, provideCtx.VolumeID),
+			FilePerm:  CredentialFilePerm,
+			WriteFile: provideCtx.WriteFile,
+		},
+		name:        name,
+		credentials: credentials,
+	}
+	awsProfile, err := awsprofile.Create(profile.settings, credentials)
+	if err != nil {
+		return nil, fmt.Errorf("credentialprovider: file: failed to create aws profile: %w", err)
+	}
+	if c.fileProfiles == nil {
+		c.fileProfiles = make(map[string]*fileProfile)
+	}
+	c.fileProfiles[driverProfileKey(profile.settings)] = profile
+
+	return profileEnvironment(provideCtx, awsProfile), nil
+}
+
+// WatchFileCredentials reloads credentials of volumes with `authenticationSource: file` every `interval` until
+// `stopCh` is closed. See [Provider.ReloadFileCredentials].
+func (c *Provider) WatchFileCredentials(stopCh <-chan struct{}, interval time.Duration) {
+	klog.Infof("credentialprovider: Watching credentials files in %s every %v", c.credentialsFileDir, interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			if err := c.ReloadFileCredentials(); err != nil {
+				klog.Errorf("credentialprovider: Failed to reload credentials files: %v", err)
+			}
+		}
+	}
+}
+
+// ReloadFileCredentials reads again credentials of all AWS profiles written for volumes with
+// `authenticationSource: file`, and rewrites the profiles whose credentials have changed.
+//
+// Mountpoint reads its AWS profile again when its cached credentials are refreshed, so existing mounts
+// pick up rotated credentials without being remounted.
+func (c *Provider) ReloadFileCredentials() error {
+	c.fileProfilesMu.Lock()
+	defer c.fileProfilesMu.Unlock()
+
+	var errs []error
+	for _, profile := range c.fileProfiles {
+		credentials, err := readCredentialFiles(filepath.Join(c.credentialsFileDir, profile.name))
+		if err != nil {
+			// Most likely in the middle of an update of the files, keep using current credentials
+			errs = append(errs, fmt.Errorf("credentials %s: %w", profile.name, err))
+			continue
+		}
+		if credentials == profile.credentials {
+			continue
+		}
+		if _, err := awsprofile.Create(profile.settings, credentials); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		profile.credentials = credentials
+		klog.Infof("credentialprovider: Credentials %s rotated, rewrote AWS profile in %s", profile.name, profile.settings.Basepath)
+	}
+	return errors.Join(errs...)
+}
+
+// untrackFileProfile forgets an AWS profile written with credentials read from files, if any.
+func (c *Provider) untrackFileProfile(settings awsprofile.Settings) {
+	c.fileProfilesMu.Lock()
+	defer c.fileProfilesMu.Unlock()
+	delete(c.fileProfiles, driverProfileKey(settings))
+}
+
+// readCredentialFiles reads credentials from the files of `dir`, named as driver-level credentials.
+func readCredentialFiles(dir string) (awsprofile.Credentials, error) {
+	var credentials awsprofile.Credentials
+	for name, value := range map[string]*string{
+		driverCredentialsAccessKeyIDFile:     &credentials.AccessKeyID,
+		driverCredentialsSecretAccessKeyFile: &credentials.SecretAccessKey,
+		driverCredentialsSessionTokenFile:    &credentials.SessionToken,
+	} {
+		content, err := readCredentialFile(dir, name)
+		if err != nil {
+			return awsprofile.Credentials{}, err
+		}
+		*value = content
+	}
+	if credentials.AccessKeyID == "" || credentials.SecretAccessKey == "" {
+		return awsprofile.Credentials{}, fmt.Errorf("access key ID or secret access key is missing in %s", dir)
+	}
+	return credentials, nil
+}
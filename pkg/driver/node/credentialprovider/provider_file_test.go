@@ -0,0 +1,116 @@
+package credentialprovider_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider/awsprofile/awsprofiletest"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+const testCredentialsName = "tenant-a"
+
+func TestProvideWithFileAuthSource(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, testCredentialsName), 0o700))
+	writeDriverCredentials(t, filepath.Join(dir, testCredentialsName), testAccessKeyID, testSecretAccessKey, testSessionToken)
+
+	provider := credentialprovider.New(nil)
+	provider.SetCredentialsFileDir(dir)
+
+	writePath := t.TempDir()
+	provideCtx := credentialprovider.ProvideContext{
+		AuthenticationSource: credentialprovider.AuthenticationSourceFile,
+		CredentialsName:      testCredentialsName,
+		WritePath:            writePath,
+		EnvPath:              testEnvPath,
+		PodID:                testPodID,
+		VolumeID:             testVolumeID,
+	}
+
+	env, source, err := provider.Provide(context.Background(), provideCtx)
+	assert.NoError(t, err)
+	assert.Equals(t, credentialprovider.AuthenticationSourceFile, source)
+	assert.Equals(t, envprovider.Environment{
+		"AWS_PROFILE":                 testProfilePrefix + "s3-csi",
+		"AWS_CONFIG_FILE":             filepath.Join(testEnvPath, testProfilePrefix+"s3-csi-config"),
+		"AWS_SHARED_CREDENTIALS_FILE": filepath.Join(testEnvPath, testProfilePrefix+"s3-csi-credentials"),
+	}, env)
+	assertLongTermCredentials(t, writePath)
+
+	t.Run("unchanged credentials", func(t *testing.T) {
+		assert.NoError(t, provider.ReloadFileCredentials())
+		assertLongTermCredentials(t, writePath)
+	})
+
+	t.Run("incomplete credentials are ignored", func(t *testing.T) {
+		writeDriverCredentials(t, filepath.Join(dir, testCredentialsName), "", "", "")
+		if err := provider.ReloadFileCredentials(); err == nil {
+			t.Fatal("Expected an error for empty credentials")
+		}
+		assertLongTermCredentials(t, writePath)
+	})
+
+	t.Run("rotated credentials", func(t *testing.T) {
+		writeDriverCredentials(t, filepath.Join(dir, testCredentialsName), rotatedAccessKeyID, rotatedSecretAccessKey, "")
+		assert.NoError(t, provider.ReloadFileCredentials())
+
+		credentials, err := awsprofiletest.ReadCredentials(filepath.Join(writePath, testProfilePrefix+"s3-csi-credentials"))
+		assert.NoError(t, err)
+		assert.Equals(t, map[string]map[string]string{
+			testProfilePrefix + "s3-csi": {
+				"aws_access_key_id":     rotatedAccessKeyID,
+				"aws_secret_access_key": rotatedSecretAccessKey,
+			},
+		}, credentials)
+	})
+
+	t.Run("cleaned up profiles are not recreated", func(t *testing.T) {
+		assert.NoError(t, provider.Cleanup(credentialprovider.CleanupContext{
+			WritePath: writePath,
+			PodID:     testPodID,
+			VolumeID:  testVolumeID,
+		}))
+		writeDriverCredentials(t, filepath.Join(dir, testCredentialsName), testAccessKeyID, testSecretAccessKey, testSessionToken)
+		assert.NoError(t, provider.ReloadFileCredentials())
+
+		_, err := os.Stat(filepath.Join(writePath, testProfilePrefix+"s3-csi-credentials"))
+		assert.Equals(t, true, os.IsNotExist(err))
+	})
+}
+
+func TestProvideWithFileAuthSourceFailures(t *testing.T) {
+	dir := t.TempDir()
+	tests := map[string]struct {
+		credentialsFileDir string
+		credentialsName    string
+	}{
+		"no credentials name":             {credentialsFileDir: dir},
+		"credentials name with a path":    {credentialsFileDir: dir, credentialsName: "../" + testCredentialsName},
+		"credentials name of parent":      {credentialsFileDir: dir, credentialsName: ".."},
+		"missing credentials":             {credentialsFileDir: dir, credentialsName: "unknown"},
+		"no credentials file dir on node": {credentialsName: testCredentialsName},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			provider := credentialprovider.New(nil)
+			provider.SetCredentialsFileDir(test.credentialsFileDir)
+
+			_, _, err := provider.Provide(context.Background(), credentialprovider.ProvideContext{
+				AuthenticationSource: credentialprovider.AuthenticationSourceFile,
+				CredentialsName:      test.credentialsName,
+				WritePath:            t.TempDir(),
+				EnvPath:              testEnvPath,
+				PodID:                testPodID,
+				VolumeID:             testVolumeID,
+			})
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,281 @@
+package credentialprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/aws-sdk-go-v2/service/sts/types"
+	"k8s.io/klog/v2"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider/awsprofile"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+)
+
+// EnvSTSEndpointURL is the environment variable configuring the STS endpoint (e.g., Scality Vault) used to assume
+// roles for volumes with `authenticationSource: role`. If unset, the S3 endpoint is used.
+const EnvSTSEndpointURL = "STS_ENDPOINT_URL"
+
+// EnvAllowedRoleARNs is the environment variable containing a comma-separated allowlist of the roles volumes with
+// `authenticationSource: role` can assume with driver-level credentials. Entries are role ARNs where `*` matches any
+// characters, e.g. `arn:aws:iam::123456789012:role/s3-*`. No role can be assumed if it is unset: roles are taken from
+// volume attributes, which must not let users assume any role the driver identity is trusted by.
+const EnvAllowedRoleARNs = "ALLOWED_ROLE_ARNS"
+
+const (
+	// roleCredentialsDuration is the requested lifetime of assumed role credentials.
+	roleCredentialsDuration = time.Hour
+	// roleCredentialsRefreshWindow is how long before expiry assumed role credentials are refreshed.
+	// It leaves enough time for Mountpoint to pick up refreshed credentials from its AWS profile.
+	roleCredentialsRefreshWindow = 15 * time.Minute
+	// RoleCredentialsRefreshInterval is how often assumed role credentials are checked for expiry.
+	RoleCredentialsRefreshInterval = time.Minute
+	// defaultSTSRegion is used to sign STS requests if no region is configured, Scality Vault ignores it.
+	defaultSTSRegion = "us-east-1"
+	// maxRoleSessionNameLen is the maximum length of a role session name accepted by STS.
+	maxRoleSessionNameLen = 64
+)
+
+// invalidRoleSessionNameChars matches characters not allowed in a role session name.
+var invalidRoleSessionNameChars = regexp.MustCompile(`[^\w+=,.@-]`)
+
+// AssumeRoleAPIClient is the subset of the STS client used to assume roles.
+type AssumeRoleAPIClient interface {
+	AssumeRole(ctx context.Context, params *sts.AssumeRoleInput, optFns ...func(*sts.Options)) (*sts.AssumeRoleOutput, error)
+}
+
+// A roleProfile is an AWS profile written with assumed role credentials.
+type roleProfile struct {
+	settings    awsprofile.Settings
+	roleARN     string
+	sessionName string
+	expiration  time.Time
+	// webIdentityToken is the service account token the role is assumed with, with driver-level credentials if nil.
+	webIdentityToken *serviceAccountToken
+}
+
+// SetSTSClient sets the client used to assume roles. By default, a client is created on first use from the
+// driver-level credentials and [EnvSTSEndpointURL].
+func (c *Provider) SetSTSClient(client AssumeRoleAPIClient) {
+	c.roleProfilesMu.Lock()
+	defer c.roleProfilesMu.Unlock()
+	c.stsClient = client
+}
+
+// provideFromRole assumes the role from the volume context using driver-level credentials, and provides the
+// temporary credentials to Mountpoint through an AWS profile. The profile is rewritten with fresh credentials
+// before they expire, see [Provider.RefreshRoleCredentials].
+func (c *Provider) provideFromRole(ctx context.Context, provideCtx ProvideContext) (envprovider.Environment, error) {
+	if provideCtx.RoleARN == "" {
+		return nil, fmt.Errorf("credentialprovider: `authenticationSource` is `role` but no role ARN provided")
+	}
+	if !IsAllowedRoleARN(provideCtx.RoleARN) {
+		return nil, fmt.Errorf("credentialprovider: role %s is not in the driver's allowed role ARNs", provideCtx.RoleARN)
+	}
+	klog.V(4).Infof("credentialprovider: Assuming role %s for volume %s", provideCtx.RoleARN, provideCtx.VolumeID)
+
+	profile := &roleProfile{
+		// Use the same filenames as driver-level credentials, as a volume only uses one authentication source
+		// and they are removed the same way on unmount.
+		settings: awsprofile.Settings{
+			Basepath:  provideCtx.WritePath,
+			Prefix:    driverLevelLongTermCredentialsProfilePrefix(provideCtx.PodID, provideCtx.VolumeID),
+			FilePerm:  CredentialFilePerm,
+			WriteFile: provideCtx.WriteFile,
+		},
+		roleARN:     provideCtx.RoleARN,
+		sessionName: roleSessionName(provideCtx.PodID, provideCtx.VolumeID),
+	}
+
+	c.roleProfilesMu.Lock()
+	defer c.roleProfilesMu.Unlock()
+
+	awsProfile, err := c.assumeRole(ctx, profile)
+	if err != nil {
+		return nil, err
+	}
+	if c.roleProfiles == nil {
+		c.roleProfiles = make(map[string]*roleProfile)
+	}
+	c.roleProfiles[driverProfileKey(profile.settings)] = profile
+
+	return profileEnvironment(provideCtx, awsProfile), nil
+}
+
+// IsAllowedRoleARN returns whether `roleARN` matches an entry of [EnvAllowedRoleARNs].
+func IsAllowedRoleARN(roleARN string) bool {
+	for _, pattern := range strings.Split(os.Getenv(EnvAllowedRoleARNs), ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" && matchRoleARN(pattern, roleARN) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchRoleARN returns whether `roleARN` matches `pattern`, where `*` matches any characters.
+func matchRoleARN(pattern, roleARN string) bool {
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, ".*") + "$").MatchString(roleARN)
+}
+
+// WatchRoleCredentials refreshes assumed role credentials every `interval` until `stopCh` is closed.
+// See [Provider.RefreshRoleCredentials].
+func (c *Provider) WatchRoleCredentials(stopCh <-chan struct{}, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			if err := c.RefreshRoleCredentials(context.Background()); err != nil {
+				klog.Errorf("credentialprovider: Failed to refresh assumed role credentials: %v", err)
+			}
+		}
+	}
+}
+
+// RefreshRoleCredentials assumes roles again for all AWS profiles written with assumed role credentials that
+// expire within the refresh window, and rewrites them with the new credentials.
+func (c *Provider) RefreshRoleCredentials(ctx context.Context) error {
+	c.roleProfilesMu.Lock()
+	defer c.roleProfilesMu.Unlock()
+
+	var errs []error
+	for _, profile := range c.roleProfiles {
+		if time.Until(profile.expiration) > roleCredentialsRefreshWindow {
+			continue
+		}
+		if _, err := c.assumeRole(ctx, profile); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		klog.V(4).Infof("credentialprovider: Refreshed credentials for role %s in %s", profile.roleARN, profile.settings.Basepath)
+	}
+	return errors.Join(errs...)
+}
+
+// untrackRoleProfile forgets an AWS profile written with assumed role credentials, if any.
+func (c *Provider) untrackRoleProfile(settings awsprofile.Settings) {
+	c.roleProfilesMu.Lock()
+	defer c.roleProfilesMu.Unlock()
+	delete(c.roleProfiles, driverProfileKey(settings))
+}
+
+// cleanupRoleProfilesIn forgets and removes AWS profiles written with assumed role credentials in `basepath`, the
+// credentials directory of a Mountpoint Pod, as they are named after its workloads or their service account.
+func (c *Provider) cleanupRoleProfilesIn(basepath string) error {
+	c.roleProfilesMu.Lock()
+	defer c.roleProfilesMu.Unlock()
+
+	var errs []error
+	for key, profile := range c.roleProfiles {
+		if profile.settings.Basepath != basepath {
+			continue
+		}
+		delete(c.roleProfiles, key)
+		errs = append(errs, awsprofile.Cleanup(profile.settings))
+	}
+	return errors.Join(errs...)
+}
+
+// assumeRole assumes the role of `profile` and writes the obtained credentials to its AWS profile.
+// It must be called with `roleProfilesMu` held.
+func (c *Provider) assumeRole(ctx context.Context, profile *roleProfile) (awsprofile.Profile, error) {
+	if profile.webIdentityToken != nil {
+		return c.assumeRoleWithWebIdentity(ctx, profile)
+	}
+	if c.stsClient == nil {
+		client, err := newSTSClient(ctx)
+		if err != nil {
+			return awsprofile.Profile{}, err
+		}
+		c.stsClient = client
+	}
+
+	output, err := c.stsClient.AssumeRole(ctx, &sts.AssumeRoleInput{
+		RoleArn:         aws.String(profile.roleARN),
+		RoleSessionName: aws.String(profile.sessionName),
+		DurationSeconds: aws.Int32(int32(roleCredentialsDuration.Seconds())),
+	})
+	if err != nil {
+		return awsprofile.Profile{}, fmt.Errorf("credentialprovider: failed to assume role %s: %w", profile.roleARN, err)
+	}
+	return writeRoleCredentials(profile, output.Credentials)
+}
+
+// writeRoleCredentials writes `credentials` obtained by assuming the role of `profile` to its AWS profile.
+func writeRoleCredentials(profile *roleProfile, credentials *types.Credentials) (awsprofile.Profile, error) {
+	if credentials == nil {
+		return awsprofile.Profile{}, fmt.Errorf("credentialprovider: no credentials returned when assuming role %s", profile.roleARN)
+	}
+
+	awsProfile, err := awsprofile.Create(profile.settings, awsprofile.Credentials{
+		AccessKeyID:     aws.ToString(credentials.AccessKeyId),
+		SecretAccessKey: aws.ToString(credentials.SecretAccessKey),
+		SessionToken:    aws.ToString(credentials.SessionToken),
+	})
+	if err != nil {
+		return awsprofile.Profile{}, fmt.Errorf("credentialprovider: role: failed to create aws profile: %w", err)
+	}
+
+	profile.expiration = aws.ToTime(credentials.Expiration)
+	if profile.expiration.IsZero() {
+		profile.expiration = time.Now().Add(roleCredentialsDuration)
+	}
+	return awsProfile, nil
+}
+
+// newSTSClient creates an STS client authenticated with driver-level credentials.
+func newSTSClient(ctx context.Context) (AssumeRoleAPIClient, error) {
+	if os.Getenv(envprovider.EnvAccessKeyID) == "" || os.Getenv(envprovider.EnvSecretAccessKey) == "" {
+		return nil, fmt.Errorf("credentialprovider: assuming roles requires driver-level credentials via %s and %s", envprovider.EnvAccessKeyID, envprovider.EnvSecretAccessKey)
+	}
+	// Read credentials from the environment again every minute, as they might be rotated while the driver is running
+	creds := aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+		return aws.Credentials{
+			AccessKeyID:     os.Getenv(envprovider.EnvAccessKeyID),
+			SecretAccessKey: os.Getenv(envprovider.EnvSecretAccessKey),
+			SessionToken:    os.Getenv(envprovider.EnvSessionToken),
+			Source:          "DriverEnvironment",
+			CanExpire:       true,
+			Expires:         time.Now().Add(time.Minute),
+		}, nil
+	})
+
+	region := os.Getenv(envprovider.EnvRegion)
+	if region == "" {
+		region = defaultSTSRegion
+	}
+
+	awsCfg, err := config.LoadDefaultConfig(ctx, config.WithCredentialsProvider(creds), config.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("credentialprovider: failed to load AWS config: %w", err)
+	}
+
+	return sts.NewFromConfig(awsCfg, func(o *sts.Options) {
+		if endpoint := os.Getenv(EnvSTSEndpointURL); endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+	}), nil
+}
+
+// roleSessionName returns a role session name identifying the workload Pod and volume, for auditing.
+func roleSessionName(podID, volumeID string) string {
+	name := invalidRoleSessionNameChars.ReplaceAllString("s3-csi-"+podID+"-"+volumeID, "-")
+	if len(name) > maxRoleSessionNameLen {
+		name = name[:maxRoleSessionNameLen]
+	}
+	return name
+}
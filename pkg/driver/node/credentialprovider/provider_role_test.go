@@ -0,0 +1,162 @@
+package credentialprovider_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/aws-sdk-go-v2/service/sts/types"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider/awsprofile/awsprofiletest"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+const testRoleARN = "arn:aws:iam::123456789012:role/s3-reader"
+
+type fakeSTSClient struct {
+	calls    []*sts.AssumeRoleInput
+	lifetime time.Duration
+}
+
+func (f *fakeSTSClient) AssumeRole(ctx context.Context, params *sts.AssumeRoleInput, optFns ...func(*sts.Options)) (*sts.AssumeRoleOutput, error) {
+	f.calls = append(f.calls, params)
+	n := len(f.calls)
+	return &sts.AssumeRoleOutput{
+		Credentials: &types.Credentials{
+			AccessKeyId:     aws.String(fmt.Sprintf("roleAccessKey%d", n)),
+			SecretAccessKey: aws.String(fmt.Sprintf("role-secret-%d", n)),
+			SessionToken:    aws.String(fmt.Sprintf("role-token-%d", n)),
+			Expiration:      aws.Time(time.Now().Add(f.lifetime)),
+		},
+	}, nil
+}
+
+func TestProvideWithRoleAuthSource(t *testing.T) {
+	t.Setenv(credentialprovider.EnvAllowedRoleARNs, testRoleARN)
+	stsClient := &fakeSTSClient{lifetime: time.Hour}
+	provider := credentialprovider.New(nil)
+	provider.SetSTSClient(stsClient)
+
+	writePath := t.TempDir()
+	provideCtx := credentialprovider.ProvideContext{
+		AuthenticationSource: credentialprovider.AuthenticationSourceRole,
+		RoleARN:              testRoleARN,
+		WritePath:            writePath,
+		EnvPath:              testEnvPath,
+		PodID:                testPodID,
+		VolumeID:             testVolumeID,
+	}
+
+	env, source, err := provider.Provide(context.Background(), provideCtx)
+	assert.NoError(t, err)
+	assert.Equals(t, credentialprovider.AuthenticationSourceRole, source)
+	assert.Equals(t, envprovider.Environment{
+		"AWS_PROFILE":                 testProfilePrefix + "s3-csi",
+		"AWS_CONFIG_FILE":             filepath.Join(testEnvPath, testProfilePrefix+"s3-csi-config"),
+		"AWS_SHARED_CREDENTIALS_FILE": filepath.Join(testEnvPath, testProfilePrefix+"s3-csi-credentials"),
+	}, env)
+
+	assert.Equals(t, 1, len(stsClient.calls))
+	assert.Equals(t, testRoleARN, aws.ToString(stsClient.calls[0].RoleArn))
+	assert.Equals(t, "s3-csi-"+testPodID+"-"+testVolumeID, aws.ToString(stsClient.calls[0].RoleSessionName))
+	assertRoleCredentials(t, writePath, 1)
+
+	t.Run("credentials not expiring are kept", func(t *testing.T) {
+		assert.NoError(t, provider.RefreshRoleCredentials(context.Background()))
+		assert.Equals(t, 1, len(stsClient.calls))
+		assertRoleCredentials(t, writePath, 1)
+	})
+
+	t.Run("expiring credentials are refreshed", func(t *testing.T) {
+		// Assume the role again with credentials expiring soon
+		stsClient.lifetime = time.Minute
+		_, _, err := provider.Provide(context.Background(), provideCtx)
+		assert.NoError(t, err)
+		assertRoleCredentials(t, writePath, 2)
+
+		assert.NoError(t, provider.RefreshRoleCredentials(context.Background()))
+		assert.Equals(t, 3, len(stsClient.calls))
+		assertRoleCredentials(t, writePath, 3)
+	})
+
+	t.Run("credentials are not refreshed after cleanup", func(t *testing.T) {
+		assert.NoError(t, provider.Cleanup(credentialprovider.CleanupContext{
+			WritePath: writePath,
+			PodID:     testPodID,
+			VolumeID:  testVolumeID,
+		}))
+
+		assert.NoError(t, provider.RefreshRoleCredentials(context.Background()))
+		assert.Equals(t, 3, len(stsClient.calls))
+		_, err := awsprofiletest.ReadCredentials(filepath.Join(writePath, testProfilePrefix+"s3-csi-credentials"))
+		assert.Equals(t, true, err != nil)
+	})
+}
+
+func TestProvideWithRoleAuthSourceWithoutRoleARN(t *testing.T) {
+	provider := credentialprovider.New(nil)
+	provider.SetSTSClient(&fakeSTSClient{lifetime: time.Hour})
+
+	_, _, err := provider.Provide(context.Background(), credentialprovider.ProvideContext{
+		AuthenticationSource: credentialprovider.AuthenticationSourceRole,
+		WritePath:            t.TempDir(),
+		EnvPath:              testEnvPath,
+		PodID:                testPodID,
+		VolumeID:             testVolumeID,
+	})
+	assert.Equals(t, true, err != nil)
+}
+
+func TestProvideWithRoleAuthSourceNotAllowed(t *testing.T) {
+	t.Setenv(credentialprovider.EnvAllowedRoleARNs, "arn:aws:iam::123456789012:role/s3-writer")
+	stsClient := &fakeSTSClient{lifetime: time.Hour}
+	provider := credentialprovider.New(nil)
+	provider.SetSTSClient(stsClient)
+
+	_, _, err := provider.Provide(context.Background(), credentialprovider.ProvideContext{
+		AuthenticationSource: credentialprovider.AuthenticationSourceRole,
+		RoleARN:              testRoleARN,
+		WritePath:            t.TempDir(),
+		EnvPath:              testEnvPath,
+		PodID:                testPodID,
+		VolumeID:             testVolumeID,
+	})
+	assert.Equals(t, true, err != nil)
+	assert.Equals(t, 0, len(stsClient.calls))
+}
+
+func TestIsAllowedRoleARN(t *testing.T) {
+	t.Setenv(credentialprovider.EnvAllowedRoleARNs, "arn:aws:iam::123456789012:role/s3-reader, arn:aws:iam::210987654321:role/team-*")
+	for roleARN, want := range map[string]bool{
+		"arn:aws:iam::123456789012:role/s3-reader":        true,
+		"arn:aws:iam::123456789012:role/s3-reader-admin":  false,
+		"arn:aws:iam::210987654321:role/team-a":           true,
+		"arn:aws:iam::210987654321:role/team-a/path/role": true,
+		"arn:aws:iam::210987654321:role/admin":            false,
+		"arn:aws:iam::123456789012:role/team-a":           false,
+	} {
+		assert.Equals(t, want, credentialprovider.IsAllowedRoleARN(roleARN))
+	}
+
+	t.Setenv(credentialprovider.EnvAllowedRoleARNs, "")
+	assert.Equals(t, false, credentialprovider.IsAllowedRoleARN("arn:aws:iam::123456789012:role/s3-reader"))
+}
+
+func assertRoleCredentials(t *testing.T, basepath string, n int) {
+	t.Helper()
+	credentials, err := awsprofiletest.ReadCredentials(filepath.Join(basepath, testProfilePrefix+"s3-csi-credentials"))
+	assert.NoError(t, err)
+	assert.Equals(t, map[string]map[string]string{
+		testProfilePrefix + "s3-csi": {
+			"aws_access_key_id":     fmt.Sprintf("roleAccessKey%d", n),
+			"aws_secret_access_key": fmt.Sprintf("role-secret-%d", n),
+			"aws_session_token":     fmt.Sprintf("role-token-%d", n),
+		},
+	}, credentials)
+}
@@ -2,7 +2,9 @@ package credentialprovider
 
 import (
 	"context"
+	"net/url"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"unicode/utf8"
@@ -17,17 +19,31 @@ const (
 	// Keys expected in the Secret map from NodePublishVolumeRequest.
 	accessKeyID     = "access_key_id"
 	secretAccessKey = "secret_access_key"
+	// Optional keys of the Secret map: the session token of temporary credentials, and the region and S3 endpoint
+	// the volume is mounted with unless its mount options or volume attributes set them.
+	SecretKeySessionToken = "session_token"
+	SecretKeyRegion       = "region"
+	SecretKeyEndpointURL  = "endpoint_url"
 
 	// Upper limits support IAM credentials and other providers.
 	maxAccessKeyIDLen     = 128
 	maxSecretAccessKeyLen = 128
+	maxSessionTokenLen    = 4096
+	maxRegionLen          = 64
+	maxEndpointURLLen     = 2048
 )
 
+// secretKeys are the keys of the Secret map, in the order they are reported.
+var secretKeys = []string{accessKeyID, secretAccessKey, SecretKeySessionToken, SecretKeyRegion, SecretKeyEndpointURL}
+
 /*
 Validation rules support IAM credentials, test credentials, and other S3 providers:
 
 	access_key_id     – 1 … 128 chars, alphanumeric (A-Z, a-z, 0-9)
 	secret_access_key – 1 … 128 chars, alphanumeric + base64 chars (/, +, =) + hyphens (-) for UUID support
+	session_token     – 1 … 4096 chars, alphanumeric + base64 chars (/, +, =) + URL-safe base64 chars (-, _) + dots (.)
+	region            – 1 … 64 chars, alphanumeric + hyphens (-)
+	endpoint_url      – 1 … 2048 chars, absolute http or https URL without credentials
 
 The patterns accommodate IAM (20-char access keys), shorter test keys, UUID-formatted keys (e.g., Scaleway), and other providers.
 */
@@ -35,17 +51,75 @@ var (
 	// accept upper‑ or lower‑case letters so test keys like "accessKey2" pass
 	accessKeyIDRe     = regexp.MustCompile(`^[A-Za-z0-9]{1,` + strconv.Itoa(maxAccessKeyIDLen) + `}$`)
 	secretAccessKeyRe = regexp.MustCompile(`^[A-Za-z0-9/+\-=]{1,` + strconv.Itoa(maxSecretAccessKeyLen) + `}$`)
+	// session tokens exceed the maximum repeat count of regexp, their length is checked separately
+	sessionTokenRe = regexp.MustCompile(`^[A-Za-z0-9/+\-=_.]+$`)
+	regionRe       = regexp.MustCompile(`^[A-Za-z0-9-]{1,` + strconv.Itoa(maxRegionLen) + `}$`)
 )
 
+// SecretRegion returns the region of `secretData`, empty if it has none or it is invalid.
+func SecretRegion(secretData map[string]string) string {
+	region := strings.TrimSpace(secretData[SecretKeyRegion])
+	if !regionRe.MatchString(region) {
+		return ""
+	}
+	return region
+}
+
+// SecretEndpointURL returns the S3 endpoint of `secretData`, empty if it has none or it is invalid.
+func SecretEndpointURL(secretData map[string]string) string {
+	endpointURL := strings.TrimSpace(secretData[SecretKeyEndpointURL])
+	if !isValidEndpointURL(endpointURL) {
+		return ""
+	}
+	return endpointURL
+}
+
+// isValidEndpointURL returns whether `endpointURL` is an absolute http or https URL without credentials.
+func isValidEndpointURL(endpointURL string) bool {
+	if endpointURL == "" || len(endpointURL) > maxEndpointURLLen {
+		return false
+	}
+	u, err := url.Parse(endpointURL)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && u.User == nil
+}
+
+// MapSecretKeys returns `secretData` with the keys of Secrets using other names renamed after `mapping`, from the
+// keys of the Secret map (e.g. `access_key_id`) to the names used by the Secret (e.g. `accessKeyID`). Mapped names
+// take precedence over keys of the Secret map also present in the Secret.
+func MapSecretKeys(secretData map[string]string, mapping map[string]string) (map[string]string, error) {
+	if len(mapping) == 0 {
+		return secretData, nil
+	}
+	mapped := make(map[string]bool, len(mapping))
+	for key, name := range mapping {
+		if !slices.Contains(secretKeys, key) {
+			return nil, status.Errorf(codes.InvalidArgument, "credentialprovider: cannot map unknown Secret key %q, only %s are supported", key, strings.Join(secretKeys, ", "))
+		}
+		mapped[name] = true
+	}
+
+	result := make(map[string]string, len(secretData))
+	for name, value := range secretData {
+		if !mapped[name] {
+			result[name] = value
+		}
+	}
+	for key, name := range mapping {
+		if value, ok := secretData[name]; ok {
+			result[key] = value
+		}
+	}
+	return result, nil
+}
+
 // provideFromSecret validates credentials from a Kubernetes Secret.
 func (c *Provider) provideFromSecret(_ context.Context, provideCtx ProvideContext) (envprovider.Environment, error) {
 	env := envprovider.Environment{}
 
-	valid := map[string]struct{}{accessKeyID: {}, secretAccessKey: {}}
 	for k := range provideCtx.SecretData {
-		if _, ok := valid[k]; !ok {
-			klog.Warningf("credentialprovider: Secret contains unexpected key %q (ignored). Only %q and %q are supported.",
-				k, accessKeyID, secretAccessKey)
+		if !slices.Contains(secretKeys, k) {
+			klog.Warningf("credentialprovider: Secret contains unexpected key %q (ignored). Only %s are supported.",
+				k, strings.Join(secretKeys, ", "))
 		}
 	}
 
@@ -70,9 +144,30 @@ func (c *Provider) provideFromSecret(_ context.Context, provideCtx ProvideContex
 		}
 	}
 
-	if okID && okSec {
+	var invalid []string
+	token, okToken := provideCtx.SecretData[SecretKeySessionToken]
+	if okToken {
+		token = strings.TrimSpace(token)
+		if len(token) > maxSessionTokenLen || !sessionTokenRe.MatchString(token) {
+			klog.Warningf("credentialprovider: session_token is invalid or exceeds %d chars", maxSessionTokenLen)
+			invalid = append(invalid, SecretKeySessionToken)
+		}
+	}
+	if region, ok := provideCtx.SecretData[SecretKeyRegion]; ok && SecretRegion(provideCtx.SecretData) == "" {
+		klog.Warningf("credentialprovider: region %q is not alphanumeric or exceeds %d chars", region, maxRegionLen)
+		invalid = append(invalid, SecretKeyRegion)
+	}
+	if _, ok := provideCtx.SecretData[SecretKeyEndpointURL]; ok && SecretEndpointURL(provideCtx.SecretData) == "" {
+		klog.Warningf("credentialprovider: endpoint_url is not an http or https URL or exceeds %d chars", maxEndpointURLLen)
+		invalid = append(invalid, SecretKeyEndpointURL)
+	}
+
+	if okID && okSec && len(invalid) == 0 {
 		env.Set(envprovider.EnvAccessKeyID, id)
 		env.Set(envprovider.EnvSecretAccessKey, sec)
+		if okToken {
+			env.Set(envprovider.EnvSessionToken, token)
+		}
 
 		// FULL access_key_id logged (no masking) for audit purposes.
 		klog.V(3).Infof("credentialprovider: volume %s authenticated with access_key_id %s",
@@ -91,6 +186,6 @@ func (c *Provider) provideFromSecret(_ context.Context, provideCtx ProvideContex
 	return nil, status.Errorf(
 		codes.InvalidArgument,
 		"credentialprovider: missing or invalid keys in Kubernetes Secret: %s",
-		strings.Join(missing, ", "),
+		strings.Join(append(missing, invalid...), ", "),
 	)
 }
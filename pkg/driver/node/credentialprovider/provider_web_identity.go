@@ -0,0 +1,187 @@
+package credentialprovider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"k8s.io/klog/v2"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider/awsprofile"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+)
+
+// EnvServiceAccountTokenAudience is the environment variable configuring the audience of the service account tokens
+// used by volumes with `authenticationSource: webIdentity`, unless their volume context overrides it. The CSIDriver
+// must request tokens for every audience used.
+const EnvServiceAccountTokenAudience = "SERVICE_ACCOUNT_TOKEN_AUDIENCE"
+
+// DefaultServiceAccountTokenAudience is the audience of service account tokens if none is configured.
+const DefaultServiceAccountTokenAudience = "sts.amazonaws.com"
+
+// defaultServiceAccountName is the service account of workload Pods not setting one.
+const defaultServiceAccountName = "default"
+
+// AssumeRoleWithWebIdentityAPIClient is the subset of the STS client used to assume roles with service account tokens.
+type AssumeRoleWithWebIdentityAPIClient interface {
+	AssumeRoleWithWebIdentity(ctx context.Context, params *sts.AssumeRoleWithWebIdentityInput, optFns ...func(*sts.Options)) (*sts.AssumeRoleWithWebIdentityOutput, error)
+}
+
+// A serviceAccountToken is a service account token of a workload Pod, as passed by kubelet in the volume context.
+type serviceAccountToken struct {
+	Token               string    `json:"token"`
+	ExpirationTimestamp time.Time `json:"expirationTimestamp"`
+}
+
+// SetServiceAccountTokenAudience sets the audience of the service account tokens used by volumes with
+// `authenticationSource: webIdentity`, see [EnvServiceAccountTokenAudience].
+func (c *Provider) SetServiceAccountTokenAudience(audience string) {
+	c.roleProfilesMu.Lock()
+	defer c.roleProfilesMu.Unlock()
+	c.tokenAudience = audience
+}
+
+// SetWebIdentitySTSClient sets the client used to assume roles with service account tokens. By default, an
+// unauthenticated client is created on first use from [EnvSTSEndpointURL].
+func (c *Provider) SetWebIdentitySTSClient(client AssumeRoleWithWebIdentityAPIClient) {
+	c.roleProfilesMu.Lock()
+	defer c.roleProfilesMu.Unlock()
+	c.webIdentityClient = client
+}
+
+// provideFromWebIdentity assumes the role from the volume context with the service account token of the workload Pod
+// for the configured audience, and provides the temporary credentials to Mountpoint through an AWS profile. Kubelet
+// republishes the volume with fresh tokens, the profile is rewritten with new credentials every time, and before they
+// expire, see [Provider.RefreshRoleCredentials].
+//
+// A Mountpoint Pod is shared by workloads with the same service account, its environment points to the profile of
+// the first one. The profile is therefore named after the service account rather than the workload Pod, so every
+// workload publishing the volume rewrites it with its token, and it keeps being refreshed with the tokens of the
+// remaining workloads once the first one is gone.
+func (c *Provider) provideFromWebIdentity(ctx context.Context, provideCtx ProvideContext) (envprovider.Environment, error) {
+	if provideCtx.RoleARN == "" {
+		return nil, fmt.Errorf("credentialprovider: `authenticationSource` is `webIdentity` but no role ARN provided")
+	}
+
+	c.roleProfilesMu.Lock()
+	defer c.roleProfilesMu.Unlock()
+
+	audience := provideCtx.ServiceAccountTokenAudience
+	if audience == "" {
+		audience = c.tokenAudience
+	}
+	if audience == "" {
+		audience = DefaultServiceAccountTokenAudience
+	}
+	token, err := parseServiceAccountToken(provideCtx.ServiceAccountTokens, audience)
+	if err != nil {
+		return nil, err
+	}
+	klog.V(4).Infof("credentialprovider: Assuming role %s with a service account token for audience %s for volume %s", provideCtx.RoleARN, audience, provideCtx.VolumeID)
+
+	profile := &roleProfile{
+		settings: awsprofile.Settings{
+			Basepath:  provideCtx.WritePath,
+			Prefix:    webIdentityProfilePrefix(provideCtx),
+			FilePerm:  CredentialFilePerm,
+			WriteFile: provideCtx.WriteFile,
+		},
+		roleARN:          provideCtx.RoleARN,
+		sessionName:      roleSessionName(provideCtx.PodID, provideCtx.VolumeID),
+		webIdentityToken: token,
+	}
+
+	awsProfile, err := c.assumeRole(ctx, profile)
+	if err != nil {
+		return nil, err
+	}
+	if c.roleProfiles == nil {
+		c.roleProfiles = make(map[string]*roleProfile)
+	}
+	c.roleProfiles[driverProfileKey(profile.settings)] = profile
+
+	return profileEnvironment(provideCtx, awsProfile), nil
+}
+
+// webIdentityProfilePrefix returns the prefix of the AWS profile written for `provideCtx`. Profiles in the credentials
+// directory of a Mountpoint Pod are named after the service account of the workload Pod, see
+// [Provider.provideFromWebIdentity], other ones are named as driver-level credentials, as every workload has its own
+// Mountpoint and its profile is removed the same way on unmount.
+func webIdentityProfilePrefix(provideCtx ProvideContext) string {
+	if provideCtx.MountKind != MountKindPod {
+		return driverLevelLongTermCredentialsProfilePrefix(provideCtx.PodID, provideCtx.VolumeID)
+	}
+	serviceAccountName := provideCtx.ServiceAccountName
+	if serviceAccountName == "" {
+		serviceAccountName = defaultServiceAccountName
+	}
+	return escapedVolumeIdentifier(provideCtx.PodNamespace+"/"+serviceAccountName, provideCtx.VolumeID) + "-"
+}
+
+// parseServiceAccountToken returns the token for `audience` of JSON-encoded service account tokens `tokens`.
+func parseServiceAccountToken(tokens, audience string) (*serviceAccountToken, error) {
+	if tokens == "" {
+		return nil, fmt.Errorf("credentialprovider: `authenticationSource` is `webIdentity` but no service account tokens provided, the CSIDriver must request tokens for audience %q", audience)
+	}
+	var byAudience map[string]serviceAccountToken
+	if err := json.Unmarshal([]byte(tokens), &byAudience); err != nil {
+		return nil, fmt.Errorf("credentialprovider: failed to parse service account tokens: %w", err)
+	}
+	token, ok := byAudience[audience]
+	if !ok || token.Token == "" {
+		return nil, fmt.Errorf("credentialprovider: no service account token for audience %q, the CSIDriver must request tokens for it", audience)
+	}
+	return &token, nil
+}
+
+// assumeRoleWithWebIdentity assumes the role of `profile` with its service account token and writes the obtained
+// credentials to its AWS profile. It must be called with `roleProfilesMu` held.
+func (c *Provider) assumeRoleWithWebIdentity(ctx context.Context, profile *roleProfile) (awsprofile.Profile, error) {
+	token := profile.webIdentityToken
+	if !token.ExpirationTimestamp.IsZero() && time.Now().After(token.ExpirationTimestamp) {
+		return awsprofile.Profile{}, fmt.Errorf("credentialprovider: service account token to assume role %s expired at %v, waiting for kubelet to republish the volume", profile.roleARN, token.ExpirationTimestamp)
+	}
+	if c.webIdentityClient == nil {
+		client, err := newWebIdentitySTSClient(ctx)
+		if err != nil {
+			return awsprofile.Profile{}, err
+		}
+		c.webIdentityClient = client
+	}
+
+	output, err := c.webIdentityClient.AssumeRoleWithWebIdentity(ctx, &sts.AssumeRoleWithWebIdentityInput{
+		RoleArn:          aws.String(profile.roleARN),
+		RoleSessionName:  aws.String(profile.sessionName),
+		WebIdentityToken: aws.String(token.Token),
+		DurationSeconds:  aws.Int32(int32(roleCredentialsDuration.Seconds())),
+	})
+	if err != nil {
+		return awsprofile.Profile{}, fmt.Errorf("credentialprovider: failed to assume role %s with web identity: %w", profile.roleARN, err)
+	}
+	return writeRoleCredentials(profile, output.Credentials)
+}
+
+// newWebIdentitySTSClient creates an STS client for `AssumeRoleWithWebIdentity`, which is not signed: the service
+// account token authenticates the request.
+func newWebIdentitySTSClient(ctx context.Context) (AssumeRoleWithWebIdentityAPIClient, error) {
+	region := os.Getenv(envprovider.EnvRegion)
+	if region == "" {
+		region = defaultSTSRegion
+	}
+
+	awsCfg, err := config.LoadDefaultConfig(ctx, config.WithCredentialsProvider(aws.AnonymousCredentials{}), config.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("credentialprovider: failed to load AWS config: %w", err)
+	}
+
+	return sts.NewFromConfig(awsCfg, func(o *sts.Options) {
+		if endpoint := os.Getenv(EnvSTSEndpointURL); endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+	}), nil
+}
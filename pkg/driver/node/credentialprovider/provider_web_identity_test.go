@@ -0,0 +1,245 @@
+package credentialprovider_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/aws-sdk-go-v2/service/sts/types"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider/awsprofile/awsprofiletest"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+type fakeWebIdentitySTSClient struct {
+	calls    []*sts.AssumeRoleWithWebIdentityInput
+	lifetime time.Duration
+}
+
+func (f *fakeWebIdentitySTSClient) AssumeRoleWithWebIdentity(ctx context.Context, params *sts.AssumeRoleWithWebIdentityInput, optFns ...func(*sts.Options)) (*sts.AssumeRoleWithWebIdentityOutput, error) {
+	f.calls = append(f.calls, params)
+	n := len(f.calls)
+	return &sts.AssumeRoleWithWebIdentityOutput{
+		Credentials: &types.Credentials{
+			AccessKeyId:     aws.String(fmt.Sprintf("roleAccessKey%d", n)),
+			SecretAccessKey: aws.String(fmt.Sprintf("role-secret-%d", n)),
+			SessionToken:    aws.String(fmt.Sprintf("role-token-%d", n)),
+			Expiration:      aws.Time(time.Now().Add(f.lifetime)),
+		},
+	}, nil
+}
+
+// serviceAccountTokens returns service account tokens as passed by kubelet in the volume context, one per audience
+// of `tokens`, expiring at `expiration`.
+func serviceAccountTokens(t *testing.T, tokens map[string]string, expiration time.Time) string {
+	t.Helper()
+	byAudience := map[string]any{}
+	for audience, token := range tokens {
+		byAudience[audience] = map[string]any{"token": token, "expirationTimestamp": expiration}
+	}
+	data, err := json.Marshal(byAudience)
+	assert.NoError(t, err)
+	return string(data)
+}
+
+func TestProvideWithWebIdentityAuthSource(t *testing.T) {
+	stsClient := &fakeWebIdentitySTSClient{lifetime: time.Hour}
+	provider := credentialprovider.New(nil)
+	provider.SetWebIdentitySTSClient(stsClient)
+
+	writePath := t.TempDir()
+	provideCtx := credentialprovider.ProvideContext{
+		AuthenticationSource: credentialprovider.AuthenticationSourceWebIdentity,
+		RoleARN:              testRoleARN,
+		ServiceAccountTokens: serviceAccountTokens(t, map[string]string{
+			credentialprovider.DefaultServiceAccountTokenAudience: "aws-token",
+			"vault": "vault-token-1",
+		}, time.Now().Add(time.Hour)),
+		WritePath: writePath,
+		EnvPath:   testEnvPath,
+		PodID:     testPodID,
+		VolumeID:  testVolumeID,
+	}
+
+	t.Run("default audience", func(t *testing.T) {
+		env, source, err := provider.Provide(context.Background(), provideCtx)
+		assert.NoError(t, err)
+		assert.Equals(t, credentialprovider.AuthenticationSourceWebIdentity, source)
+		assert.Equals(t, envprovider.Environment{
+			"AWS_PROFILE":                 testProfilePrefix + "s3-csi",
+			"AWS_CONFIG_FILE":             filepath.Join(testEnvPath, testProfilePrefix+"s3-csi-config"),
+			"AWS_SHARED_CREDENTIALS_FILE": filepath.Join(testEnvPath, testProfilePrefix+"s3-csi-credentials"),
+		}, env)
+
+		assert.Equals(t, 1, len(stsClient.calls))
+		assert.Equals(t, testRoleARN, aws.ToString(stsClient.calls[0].RoleArn))
+		assert.Equals(t, "s3-csi-"+testPodID+"-"+testVolumeID, aws.ToString(stsClient.calls[0].RoleSessionName))
+		assert.Equals(t, "aws-token", aws.ToString(stsClient.calls[0].WebIdentityToken))
+		assertRoleCredentials(t, writePath, 1)
+	})
+
+	t.Run("driver-level audience", func(t *testing.T) {
+		provider.SetServiceAccountTokenAudience("vault")
+		_, _, err := provider.Provide(context.Background(), provideCtx)
+		assert.NoError(t, err)
+		assert.Equals(t, "vault-token-1", aws.ToString(stsClient.calls[1].WebIdentityToken))
+		assertRoleCredentials(t, writePath, 2)
+	})
+
+	t.Run("volume audience", func(t *testing.T) {
+		volumeCtx := provideCtx
+		volumeCtx.ServiceAccountTokenAudience = credentialprovider.DefaultServiceAccountTokenAudience
+		_, _, err := provider.Provide(context.Background(), volumeCtx)
+		assert.NoError(t, err)
+		assert.Equals(t, "aws-token", aws.ToString(stsClient.calls[2].WebIdentityToken))
+	})
+
+	t.Run("republished tokens are used on refresh", func(t *testing.T) {
+		stsClient.lifetime = time.Minute
+		republished := provideCtx
+		republished.ServiceAccountTokens = serviceAccountTokens(t, map[string]string{"vault": "vault-token-2"}, time.Now().Add(time.Hour))
+		_, _, err := provider.Provide(context.Background(), republished)
+		assert.NoError(t, err)
+
+		assert.NoError(t, provider.RefreshRoleCredentials(context.Background()))
+		assert.Equals(t, 5, len(stsClient.calls))
+		assert.Equals(t, "vault-token-2", aws.ToString(stsClient.calls[4].WebIdentityToken))
+		assertRoleCredentials(t, writePath, 5)
+	})
+}
+
+func TestProvideWithWebIdentityAuthSourceErrors(t *testing.T) {
+	testCases := []struct {
+		name        string
+		roleARN     string
+		tokens      string
+		audience    string
+		errContains string
+	}{
+		{
+			name:        "no role ARN",
+			tokens:      `{"sts.amazonaws.com":{"token":"token"}}`,
+			errContains: "no role ARN",
+		},
+		{
+			name:        "no tokens",
+			roleARN:     testRoleARN,
+			errContains: "no service account tokens provided",
+		},
+		{
+			name:        "malformed tokens",
+			roleARN:     testRoleARN,
+			tokens:      "not-json",
+			errContains: "failed to parse service account tokens",
+		},
+		{
+			name:        "no token for audience",
+			roleARN:     testRoleARN,
+			tokens:      `{"sts.amazonaws.com":{"token":"token"}}`,
+			audience:    "vault",
+			errContains: `no service account token for audience "vault"`,
+		},
+		{
+			name:        "expired token",
+			roleARN:     testRoleARN,
+			tokens:      `{"sts.amazonaws.com":{"token":"token","expirationTimestamp":"2020-01-01T00:00:00Z"}}`,
+			errContains: "expired",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stsClient := &fakeWebIdentitySTSClient{lifetime: time.Hour}
+			provider := credentialprovider.New(nil)
+			provider.SetWebIdentitySTSClient(stsClient)
+
+			_, _, err := provider.Provide(context.Background(), credentialprovider.ProvideContext{
+				AuthenticationSource:        credentialprovider.AuthenticationSourceWebIdentity,
+				RoleARN:                     tc.roleARN,
+				ServiceAccountTokens:        tc.tokens,
+				ServiceAccountTokenAudience: tc.audience,
+				WritePath:                   t.TempDir(),
+				EnvPath:                     testEnvPath,
+				PodID:                       testPodID,
+				VolumeID:                    testVolumeID,
+			})
+			if err == nil || !strings.Contains(err.Error(), tc.errContains) {
+				t.Fatalf("Expected an error containing %q, got %v", tc.errContains, err)
+			}
+			assert.Equals(t, 0, len(stsClient.calls))
+		})
+	}
+}
+
+func TestProvideWithWebIdentityAuthSourceSharedMountpointPod(t *testing.T) {
+	stsClient := &fakeWebIdentitySTSClient{lifetime: time.Hour}
+	provider := credentialprovider.New(nil)
+	provider.SetWebIdentitySTSClient(stsClient)
+
+	writePath := t.TempDir()
+	provideCtx := func(podID, token string) credentialprovider.ProvideContext {
+		return credentialprovider.ProvideContext{
+			AuthenticationSource: credentialprovider.AuthenticationSourceWebIdentity,
+			RoleARN:              testRoleARN,
+			ServiceAccountTokens: serviceAccountTokens(t, map[string]string{
+				credentialprovider.DefaultServiceAccountTokenAudience: token,
+			}, time.Now().Add(time.Hour)),
+			WritePath:          writePath,
+			EnvPath:            testEnvPath,
+			MountKind:          credentialprovider.MountKindPod,
+			PodID:              podID,
+			PodNamespace:       "ns",
+			ServiceAccountName: "reader",
+			VolumeID:           testVolumeID,
+		}
+	}
+	profilePrefix := "ns~reader-" + testVolumeID + "-"
+
+	// The Mountpoint Pod is started with the environment of the first workload
+	envA, _, err := provider.Provide(context.Background(), provideCtx("pod-a", "token-a"))
+	assert.NoError(t, err)
+	assert.Equals(t, envprovider.Environment{
+		"AWS_PROFILE":                 profilePrefix + "s3-csi",
+		"AWS_CONFIG_FILE":             filepath.Join(testEnvPath, profilePrefix+"s3-csi-config"),
+		"AWS_SHARED_CREDENTIALS_FILE": filepath.Join(testEnvPath, profilePrefix+"s3-csi-credentials"),
+	}, envA)
+
+	envB, _, err := provider.Provide(context.Background(), provideCtx("pod-b", "token-b"))
+	assert.NoError(t, err)
+	assert.Equals(t, envA, envB)
+
+	// The first workload is unmounted, only the second one keeps republishing the volume
+	stsClient.lifetime = time.Minute
+	_, _, err = provider.Provide(context.Background(), provideCtx("pod-b", "token-b-2"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, provider.RefreshRoleCredentials(context.Background()))
+	assert.Equals(t, 4, len(stsClient.calls))
+	assert.Equals(t, "token-b-2", aws.ToString(stsClient.calls[3].WebIdentityToken))
+	credentials, err := awsprofiletest.ReadCredentials(filepath.Join(writePath, profilePrefix+"s3-csi-credentials"))
+	assert.NoError(t, err)
+	assert.Equals(t, "roleAccessKey4", credentials[profilePrefix+"s3-csi"]["aws_access_key_id"])
+
+	// Credentials are removed with the Mountpoint Pod, and no longer refreshed
+	assert.NoError(t, provider.Cleanup(credentialprovider.CleanupContext{
+		WritePath: writePath,
+		PodID:     "mountpoint-pod",
+		VolumeID:  testVolumeID,
+		MountKind: credentialprovider.MountKindPod,
+	}))
+	_, err = os.Stat(filepath.Join(writePath, profilePrefix+"s3-csi-credentials"))
+	assert.Equals(t, true, errors.Is(err, fs.ErrNotExist))
+	assert.NoError(t, provider.RefreshRoleCredentials(context.Background()))
+	assert.Equals(t, 4, len(stsClient.calls))
+}
@@ -0,0 +1,95 @@
+package node
+
+import (
+	"context"
+	"os"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/endpointfailover"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/mounter"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
+)
+
+// selectEndpoint sets `--endpoint-url` in `args` to the first reachable endpoint of the volume: the endpoints of its
+// `endpointUrls` attribute, or the driver-level endpoint followed by the failover endpoints of the driver if the
+// volume has no endpoint of its own. It returns the endpoints of the volume, nil if it has a single endpoint.
+//
+// Endpoints not allowed by the cluster administrator are ignored. If no endpoint is reachable, the volume is mounted
+// with the first one and Mountpoint reports the failure.
+func (ns *S3NodeServer) selectEndpoint(ctx context.Context, volumeCtx map[string]string, args *mountpoint.Args) ([]string, error) {
+	if ns.EndpointFailover == nil {
+		return nil, nil
+	}
+
+	var endpointURLs []string
+	if volumeEndpointURLs := volumeCtx[volumecontext.EndpointURLs]; volumeEndpointURLs != "" {
+		if args.Has(mountpoint.ArgEndpointURL) {
+			return nil, status.Errorf(codes.InvalidArgument, "Endpoints are set by both the %s volume attribute and %s or %s, only use one", volumecontext.EndpointURLs, volumecontext.EndpointURL, mountpoint.ArgEndpointURL)
+		}
+		for _, endpointURL := range endpointfailover.ParseEndpointURLs(volumeEndpointURLs) {
+			if !mounter.IsAllowedEndpointURL(endpointURL) {
+				klog.Warningf("Endpoint %q of %s ignored: it is not in the driver's allowed endpoint URLs", endpointURL, volumecontext.EndpointURLs)
+				continue
+			}
+			endpointURLs = append(endpointURLs, endpointURL)
+		}
+		if len(endpointURLs) == 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "None of the endpoints of the %s volume attribute is allowed by the driver", volumecontext.EndpointURLs)
+		}
+	} else if !args.Has(mountpoint.ArgEndpointURL) {
+		endpointURLs = endpointfailover.DriverEndpointURLs()
+	}
+	if len(endpointURLs) == 1 {
+		setEndpointURL(args, endpointURLs[0])
+	}
+	if len(endpointURLs) < 2 {
+		return nil, nil
+	}
+
+	endpointURL, err := ns.EndpointFailover.Select(ctx, endpointURLs)
+	if err != nil {
+		klog.Warningf("Mounting with S3 endpoint %s: %v", endpointURL, err)
+	} else if endpointURL != endpointURLs[0] {
+		klog.Infof("S3 endpoint %s is unreachable, mounting with %s", endpointURLs[0], endpointURL)
+	}
+	setEndpointURL(args, endpointURL)
+	return endpointURLs, nil
+}
+
+// trackEndpoint watches the endpoint of the volume about to be mounted at `target` with `endpointURLs`, to remount it
+// against the next endpoint if its endpoint stays unreachable. It must be called before mounting the volume, as
+// mounters modify `args`, and [endpointfailover.Selector.Untrack] if the mount fails.
+func (ns *S3NodeServer) trackEndpoint(endpointURLs []string, bucket, target string, credentialCtx credentialprovider.ProvideContext, args mountpoint.Args, fsGroup string) {
+	if ns.EndpointFailover == nil || len(endpointURLs) == 0 {
+		return
+	}
+	endpointURL, ok := args.Value(mountpoint.ArgEndpointURL)
+	if !ok {
+		endpointURL = os.Getenv(envprovider.EnvEndpointURL)
+	}
+	args = mountpoint.ParseArgs(args.SortedList())
+	ns.EndpointFailover.Track(target, endpointfailover.Mount{
+		EndpointURLs: endpointURLs,
+		EndpointURL:  endpointURL,
+		Remount: func(ctx context.Context, endpointURL string) error {
+			args := mountpoint.ParseArgs(args.SortedList())
+			setEndpointURL(&args, endpointURL)
+			return ns.remount(ctx, bucket, target, credentialCtx, args, fsGroup)
+		},
+	})
+}
+
+// setEndpointURL sets `--endpoint-url` to `endpointURL`, or removes it if it is the driver-level endpoint.
+func setEndpointURL(args *mountpoint.Args, endpointURL string) {
+	if endpointURL == os.Getenv(envprovider.EnvEndpointURL) {
+		args.Remove(mountpoint.ArgEndpointURL)
+		return
+	}
+	args.Set(mountpoint.ArgEndpointURL, endpointURL)
+}
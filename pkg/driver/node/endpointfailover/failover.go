@@ -0,0 +1,220 @@
+// Package endpointfailover selects the S3 endpoint volumes are mounted with from an ordered list of endpoints, e.g.
+// the S3 connectors of a RING, so mounts do not fail while the first endpoint is down, and optionally remounts
+// volumes against the next endpoint when the endpoint they are mounted with stays unreachable.
+package endpointfailover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/endpointprobe"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+)
+
+const (
+	// EnvFailoverEndpointURLs is the environment variable containing a comma-separated ordered list of S3 endpoint
+	// URLs tried after the driver-level endpoint when it is unreachable.
+	EnvFailoverEndpointURLs = "FAILOVER_ENDPOINT_URLS"
+	// EnvFailoverRemountEnabled is the environment variable enabling remounts of volumes against the next endpoint
+	// when the endpoint they are mounted with stays unreachable.
+	EnvFailoverRemountEnabled = "FAILOVER_REMOUNT_ENABLED"
+)
+
+const (
+	// CheckInterval is how often endpoints of mounted volumes are probed when remounts are enabled.
+	CheckInterval = 30 * time.Second
+	// FailureThreshold is the number of consecutive failed probes of the endpoint of a volume after which the volume
+	// is remounted against the next reachable endpoint.
+	FailureThreshold = 3
+)
+
+// ErrNoReachableEndpoint is returned when none of the endpoints of a volume is reachable.
+var ErrNoReachableEndpoint = errors.New("no reachable S3 endpoint")
+
+// DriverEndpointURLs returns the driver-level endpoint followed by [EnvFailoverEndpointURLs].
+func DriverEndpointURLs() []string {
+	return ParseEndpointURLs(os.Getenv(envprovider.EnvEndpointURL) + "," + os.Getenv(EnvFailoverEndpointURLs))
+}
+
+// ParseEndpointURLs parses a comma-separated list of endpoints.
+func ParseEndpointURLs(endpointURLs string) []string {
+	var urls []string
+	for _, url := range strings.Split(endpointURLs, ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+// A Mount is a volume mounted at a target with one of `EndpointURLs`.
+type Mount struct {
+	EndpointURLs []string
+	// EndpointURL is the endpoint the volume is currently mounted with.
+	EndpointURL string
+	// Remount remounts the volume with `endpointURL`.
+	Remount func(ctx context.Context, endpointURL string) error
+}
+
+type trackedMount struct {
+	Mount
+	failures int
+}
+
+// A Selector picks the first reachable endpoint of volumes at mount time and, if started, remounts volumes whose
+// endpoint stays unreachable against the next reachable one.
+type Selector struct {
+	client   *http.Client
+	remounts bool
+
+	mu     sync.Mutex
+	mounts map[string]*trackedMount
+}
+
+// NewSelector creates a new [Selector] trusting the CAs in the PEM file at `caBundlePath` in addition to the system
+// CAs if it is not empty. Volumes are only remounted if `remounts` is true.
+func NewSelector(caBundlePath string, remounts bool) (*Selector, error) {
+	client, err := endpointprobe.NewHTTPClient(caBundlePath)
+	if err != nil {
+		return nil, err
+	}
+	return &Selector{client: client, remounts: remounts, mounts: make(map[string]*trackedMount)}, nil
+}
+
+// Select returns the first reachable endpoint of `endpointURLs`, in order. It returns the first endpoint and
+// [ErrNoReachableEndpoint] if none is reachable.
+func (s *Selector) Select(ctx context.Context, endpointURLs []string) (string, error) {
+	if len(endpointURLs) == 0 {
+		return "", ErrNoReachableEndpoint
+	}
+	var errs []error
+	for _, url := range endpointURLs {
+		err := endpointprobe.Head(ctx, s.client, url)
+		if err == nil {
+			return url, nil
+		}
+		klog.V(4).Infof("endpointfailover: S3 endpoint %s is unreachable: %v", url, err)
+		errs = append(errs, fmt.Errorf("%s: %w", url, err))
+	}
+	return endpointURLs[0], fmt.Errorf("%w: %w", ErrNoReachableEndpoint, errors.Join(errs...))
+}
+
+// Track watches the endpoint of the volume mounted at `target`, for remounts. Volumes with a single endpoint are not
+// tracked.
+func (s *Selector) Track(target string, mount Mount) {
+	if !s.remounts || mount.Remount == nil || len(mount.EndpointURLs) < 2 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mounts[target] = &trackedMount{Mount: mount}
+}
+
+// Untrack stops watching the endpoint of the volume mounted at `target`.
+func (s *Selector) Untrack(target string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.mounts, target)
+}
+
+// Start probes the endpoints of tracked volumes every `interval` until `stopCh` is closed.
+func (s *Selector) Start(stopCh <-chan struct{}, interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			s.Run(ctx)
+		}
+	}
+}
+
+// Run probes the endpoint of each tracked volume, and remounts volumes whose endpoint failed [FailureThreshold]
+// consecutive probes against the next reachable endpoint.
+func (s *Selector) Run(ctx context.Context) {
+	s.mu.Lock()
+	targets := make(map[string]Mount, len(s.mounts))
+	for target, mount := range s.mounts {
+		targets[target] = mount.Mount
+	}
+	s.mu.Unlock()
+
+	// Probe each endpoint once, even if many volumes are mounted with it
+	reachable := make(map[string]bool)
+	for target, mount := range targets {
+		ok, probed := reachable[mount.EndpointURL]
+		if !probed {
+			ok = endpointprobe.Head(ctx, s.client, mount.EndpointURL) == nil
+			reachable[mount.EndpointURL] = ok
+		}
+		if failures := s.recordProbe(target, ok); failures >= FailureThreshold {
+			s.failover(ctx, target, mount)
+		}
+	}
+}
+
+// recordProbe records whether the endpoint of the volume at `target` was reachable, and returns its number of
+// consecutive failed probes.
+func (s *Selector) recordProbe(target string, reachable bool) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	mount, ok := s.mounts[target]
+	if !ok {
+		return 0
+	}
+	if reachable {
+		mount.failures = 0
+	} else {
+		mount.failures++
+	}
+	return mount.failures
+}
+
+// failover remounts the volume at `target` against the endpoint following its current one that is reachable.
+func (s *Selector) failover(ctx context.Context, target string, mount Mount) {
+	next, err := s.Select(ctx, rotate(mount.EndpointURLs, mount.EndpointURL))
+	if err != nil || next == mount.EndpointURL {
+		klog.Warningf("endpointfailover: S3 endpoint %s of volume at %s is unreachable, no other endpoint to remount it with: %v", mount.EndpointURL, target, err)
+		return
+	}
+
+	klog.Warningf("endpointfailover: S3 endpoint %s of volume at %s is unreachable, remounting it with %s", mount.EndpointURL, target, next)
+	if err := mount.Remount(ctx, next); err != nil {
+		klog.Errorf("endpointfailover: Failed to remount volume at %s with %s: %v", target, next, err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if tracked, ok := s.mounts[target]; ok {
+		tracked.EndpointURL = next
+		tracked.failures = 0
+	}
+}
+
+// rotate returns `endpointURLs` starting with the endpoint following `current`, and ending with `current`.
+func rotate(endpointURLs []string, current string) []string {
+	for i, url := range endpointURLs {
+		if url == current {
+			return append(append([]string{}, endpointURLs[i+1:]...), endpointURLs[:i+1]...)
+		}
+	}
+	return endpointURLs
+}
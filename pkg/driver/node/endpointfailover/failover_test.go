@@ -0,0 +1,83 @@
+package endpointfailover_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/endpointfailover"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+// newEndpoints returns a reachable endpoint and an unreachable one.
+func newEndpoints(t *testing.T) (string, string) {
+	reachable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Unauthenticated requests are rejected, the endpoint is reachable nonetheless
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	t.Cleanup(reachable.Close)
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+	return reachable.URL, unreachable.URL
+}
+
+func TestSelect(t *testing.T) {
+	reachable, unreachable := newEndpoints(t)
+	selector, err := endpointfailover.NewSelector("", false)
+	assert.NoError(t, err)
+
+	endpointURL, err := selector.Select(context.Background(), []string{unreachable, reachable})
+	assert.NoError(t, err)
+	assert.Equals(t, reachable, endpointURL)
+
+	endpointURL, err = selector.Select(context.Background(), []string{reachable, unreachable})
+	assert.NoError(t, err)
+	assert.Equals(t, reachable, endpointURL)
+
+	endpointURL, err = selector.Select(context.Background(), []string{unreachable})
+	assert.Equals(t, true, errors.Is(err, endpointfailover.ErrNoReachableEndpoint))
+	assert.Equals(t, unreachable, endpointURL)
+}
+
+func TestRemount(t *testing.T) {
+	reachable, unreachable := newEndpoints(t)
+	selector, err := endpointfailover.NewSelector("", true)
+	assert.NoError(t, err)
+
+	var remounts []string
+	selector.Track("/target", endpointfailover.Mount{
+		EndpointURLs: []string{unreachable, reachable},
+		EndpointURL:  unreachable,
+		Remount: func(ctx context.Context, endpointURL string) error {
+			remounts = append(remounts, endpointURL)
+			return nil
+		},
+	})
+
+	for range endpointfailover.FailureThreshold - 1 {
+		selector.Run(context.Background())
+	}
+	assert.Equals(t, 0, len(remounts))
+	selector.Run(context.Background())
+	assert.Equals(t, []string{reachable}, remounts)
+
+	// The volume is now mounted with a reachable endpoint
+	for range endpointfailover.FailureThreshold {
+		selector.Run(context.Background())
+	}
+	assert.Equals(t, 1, len(remounts))
+
+	selector.Untrack("/target")
+	selector.Run(context.Background())
+	assert.Equals(t, 1, len(remounts))
+}
+
+func TestParseEndpointURLs(t *testing.T) {
+	assert.Equals(t, []string{"https://s3-1.example.com", "https://s3-2.example.com"}, endpointfailover.ParseEndpointURLs(" https://s3-1.example.com,,https://s3-2.example.com "))
+
+	t.Setenv("AWS_ENDPOINT_URL", "https://s3-1.example.com")
+	t.Setenv(endpointfailover.EnvFailoverEndpointURLs, "https://s3-2.example.com")
+	assert.Equals(t, []string{"https://s3-1.example.com", "https://s3-2.example.com"}, endpointfailover.DriverEndpointURLs())
+}
@@ -0,0 +1,221 @@
+// Package endpointprobe probes the reachability of the S3 endpoint from the node plugin, so unreachable endpoints are
+// surfaced through the readiness of the node plugin and a metric, and mount failures they cause can be told apart
+// from credential errors.
+package endpointprobe
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	nodemetrics "github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/metrics"
+)
+
+const (
+	// EnvEndpointProbeEnabled is the environment variable enabling endpoint probes.
+	EnvEndpointProbeEnabled = "ENDPOINT_PROBE_ENABLED"
+	// EnvCABundle is the environment variable with the path of the PEM CA bundle trusted by probes, in addition to
+	// the system CAs.
+	EnvCABundle = "ENDPOINT_PROBE_CA_BUNDLE"
+	// EnvReadinessAddress is the environment variable with the address readiness is served on, e.g. `:9810`.
+	// Readiness is not served if empty.
+	EnvReadinessAddress = "ENDPOINT_PROBE_READINESS_ADDRESS"
+)
+
+// ProbeInterval is how often the endpoint is probed.
+const ProbeInterval = 30 * time.Second
+
+const probeTimeout = 5 * time.Second
+
+// Reasons of events on workload Pods whose mount failed, depending on the most likely cause.
+const (
+	ReasonEndpointUnreachable = "S3EndpointUnreachable"
+	ReasonCredentialsRejected = "S3CredentialsRejected"
+)
+
+// credentialErrorPatterns are substrings of mount errors caused by credentials rejected by the S3 endpoint.
+var credentialErrorPatterns = []string{"invalidaccesskeyid", "signaturedoesnotmatch", "no credentials", "accessdenied", "access denied", "forbidden"}
+
+// endpointErrorPatterns are substrings of mount errors caused by an unreachable S3 endpoint.
+var endpointErrorPatterns = []string{"dns error", "connection refused", "failed to connect", "no route to host"}
+
+// A Prober periodically sends a HEAD request to the S3 endpoint. Any HTTP response, including errors of
+// unauthenticated requests, means the endpoint is reachable.
+type Prober struct {
+	endpointURL string
+	client      *http.Client
+
+	mu sync.RWMutex
+	// err is the error of the last probe, nil if the endpoint was reachable or was not probed yet.
+	err error
+}
+
+// NewProber creates a new [Prober] of `endpointURL`, trusting the CAs in the PEM file at `caBundlePath` in addition
+// to the system CAs if it is not empty.
+func NewProber(endpointURL, caBundlePath string) (*Prober, error) {
+	if endpointURL == "" {
+		return nil, errors.New("S3 endpoint URL not configured")
+	}
+	client, err := NewHTTPClient(caBundlePath)
+	if err != nil {
+		return nil, err
+	}
+	return &Prober{endpointURL: endpointURL, client: client}, nil
+}
+
+// NewHTTPClient creates a new HTTP client probing S3 endpoints with [Head], trusting the CAs in the PEM file at
+// `caBundlePath` in addition to the system CAs if it is not empty.
+func NewHTTPClient(caBundlePath string) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if caBundlePath != "" {
+		pem, err := os.ReadFile(caBundlePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle %q: %w", caBundlePath, err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in CA bundle %q", caBundlePath)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   probeTimeout,
+		// Redirects are responses of the endpoint, they are not followed
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}, nil
+}
+
+// Start probes the endpoint on startup, and then every `interval` until `stopCh` is closed.
+func (p *Prober) Start(stopCh <-chan struct{}, interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		_ = p.Probe(ctx)
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Probe sends a HEAD request to the endpoint and records whether it is reachable.
+func (p *Prober) Probe(ctx context.Context) error {
+	err := p.head(ctx)
+
+	p.mu.Lock()
+	previous := p.err
+	p.err = err
+	p.mu.Unlock()
+
+	if err != nil {
+		nodemetrics.S3EndpointReachable.Set(0)
+		if previous == nil {
+			klog.Warningf("S3 endpoint %s is unreachable: %v", p.endpointURL, err)
+		}
+	} else {
+		nodemetrics.S3EndpointReachable.Set(1)
+		if previous != nil {
+			klog.Infof("S3 endpoint %s is reachable again", p.endpointURL)
+		}
+	}
+	return err
+}
+
+// head sends a HEAD request to the endpoint.
+func (p *Prober) head(ctx context.Context) error {
+	return Head(ctx, p.client, p.endpointURL)
+}
+
+// Head sends a HEAD request to `endpointURL` with `client`. It returns nil on any HTTP response, as errors of
+// unauthenticated requests mean the endpoint is reachable.
+func Head(ctx context.Context, client *http.Client, endpointURL string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, endpointURL, nil)
+	if err != nil {
+		return fmt.Errorf("invalid S3 endpoint %q: %w", endpointURL, err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// Err returns the error of the last probe, nil if the endpoint was reachable.
+func (p *Prober) Err() error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.err
+}
+
+// MountFailureReason returns the reason of the event reporting mount failure `err`: [ReasonEndpointUnreachable] if
+// the endpoint is unreachable, [ReasonCredentialsRejected] if the endpoint rejected the credentials, or an empty string
+// if the failure has another cause.
+func (p *Prober) MountFailureReason(err error) string {
+	message := strings.ToLower(err.Error())
+	if p.Err() != nil || containsAny(message, endpointErrorPatterns) {
+		return ReasonEndpointUnreachable
+	}
+	if containsAny(message, credentialErrorPatterns) {
+		return ReasonCredentialsRejected
+	}
+	return ""
+}
+
+// containsAny returns whether `s` contains any of `substrings`.
+func containsAny(s string, substrings []string) bool {
+	for _, substring := range substrings {
+		if strings.Contains(s, substring) {
+			return true
+		}
+	}
+	return false
+}
+
+// ServeReadiness serves the reachability of the endpoint at `/readyz` on `addr` until `stopCh` is closed, for the
+// readiness probe of the node plugin.
+func (p *Prober) ServeReadiness(addr string, stopCh <-chan struct{}) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/readyz", p.handleReadiness)
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-stopCh
+		_ = server.Close()
+	}()
+
+	klog.Infof("Serving S3 endpoint readiness on %s", addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		klog.Errorf("Failed to serve S3 endpoint readiness on %s: %v", addr, err)
+	}
+}
+
+// handleReadiness responds with the reachability of the endpoint.
+func (p *Prober) handleReadiness(w http.ResponseWriter, _ *http.Request) {
+	if err := p.Err(); err != nil {
+		http.Error(w, fmt.Sprintf("S3 endpoint %s is unreachable: %v", p.endpointURL, err), http.StatusServiceUnavailable)
+		return
+	}
+	_, _ = w.Write([]byte("ok"))
+}
@@ -0,0 +1,87 @@
+package endpointprobe
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestProbe(t *testing.T) {
+	var method string
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		// Unauthenticated requests are rejected, the endpoint is reachable nonetheless
+		w.WriteHeader(http.StatusForbidden)
+	}))
+
+	prober, err := NewProber(endpoint.URL, "")
+	assert.NoError(t, err)
+	assert.NoError(t, prober.Probe(context.Background()))
+	assert.Equals(t, http.MethodHead, method)
+	assert.NoError(t, prober.Err())
+
+	readiness := httptest.NewRecorder()
+	prober.handleReadiness(readiness, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equals(t, http.StatusOK, readiness.Code)
+
+	endpoint.Close()
+	if err := prober.Probe(context.Background()); err == nil {
+		t.Fatal("Expected an error probing a closed endpoint")
+	}
+	if prober.Err() == nil {
+		t.Fatal("Expected the endpoint to be unreachable")
+	}
+
+	readiness = httptest.NewRecorder()
+	prober.handleReadiness(readiness, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equals(t, http.StatusServiceUnavailable, readiness.Code)
+}
+
+func TestProbeWithCABundle(t *testing.T) {
+	endpoint := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	defer endpoint.Close()
+
+	// The certificate of the endpoint is not trusted without the CA bundle
+	prober, err := NewProber(endpoint.URL, "")
+	assert.NoError(t, err)
+	if err := prober.Probe(context.Background()); err == nil {
+		t.Fatal("Expected an error probing an endpoint with an untrusted certificate")
+	}
+
+	caBundle := filepath.Join(t.TempDir(), "ca-bundle.crt")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: endpoint.Certificate().Raw})
+	assert.NoError(t, os.WriteFile(caBundle, cert, 0o600))
+	prober, err = NewProber(endpoint.URL, caBundle)
+	assert.NoError(t, err)
+	assert.NoError(t, prober.Probe(context.Background()))
+
+	assert.NoError(t, os.WriteFile(caBundle, []byte("not a certificate"), 0o600))
+	if _, err := NewProber(endpoint.URL, caBundle); err == nil {
+		t.Fatal("Expected an error for a CA bundle without certificates")
+	}
+}
+
+func TestMountFailureReason(t *testing.T) {
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	defer endpoint.Close()
+	prober, err := NewProber(endpoint.URL, "")
+	assert.NoError(t, err)
+	assert.NoError(t, prober.Probe(context.Background()))
+
+	assert.Equals(t, ReasonCredentialsRejected, prober.MountFailureReason(errors.New("Mountpoint failed: SignatureDoesNotMatch")))
+	assert.Equals(t, ReasonCredentialsRejected, prober.MountFailureReason(errors.New("Mountpoint failed: Access Denied")))
+	assert.Equals(t, ReasonEndpointUnreachable, prober.MountFailureReason(errors.New("Mountpoint failed: dns error")))
+	assert.Equals(t, "", prober.MountFailureReason(errors.New("Mountpoint failed: NoSuchBucket")))
+
+	// Failures are attributed to the endpoint while it is unreachable
+	endpoint.Close()
+	_ = prober.Probe(context.Background())
+	assert.Equals(t, ReasonEndpointUnreachable, prober.MountFailureReason(errors.New("Mountpoint failed: Access Denied")))
+}
@@ -18,7 +18,9 @@ const (
 	EnvAccessKeyID           = "AWS_ACCESS_KEY_ID"
 	EnvSecretAccessKey       = "AWS_SECRET_ACCESS_KEY"
 	EnvSessionToken          = "AWS_SESSION_TOKEN"
+	EnvCABundle              = "AWS_CA_BUNDLE"
 	EnvMountpointCacheKey    = "UNSTABLE_MOUNTPOINT_CACHE_KEY"
+	EnvMountpointLog         = "MOUNTPOINT_LOG"
 )
 
 // Key represents an environment variable name.
@@ -0,0 +1,66 @@
+package node
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+)
+
+// validateEphemeralVolume checks that the inline ephemeral volume with `volumeCtx`, other than a diagnostic mount,
+// only uses credentials from a Secret of its Pod's namespace.
+//
+// Any Pod can declare an inline volume, so the driver credentials, roles assumed with them and credentials files of
+// the node plugin must never be used by inline volumes.
+func validateEphemeralVolume(volumeCtx map[string]string) error {
+	if source := credentialprovider.AuthenticationSource(volumeCtx[volumecontext.AuthenticationSource]); source != credentialprovider.AuthenticationSourceUnspecified && source != credentialprovider.AuthenticationSourceSecret {
+		return status.Errorf(codes.InvalidArgument, "Inline ephemeral volumes only support %s=%s, got %q", volumecontext.AuthenticationSource, credentialprovider.AuthenticationSourceSecret, source)
+	}
+	if volumeCtx[volumecontext.SecretName] == "" {
+		return status.Errorf(codes.InvalidArgument, "Inline ephemeral volumes require %s, the Secret in the Pod's namespace holding their credentials", volumecontext.SecretName)
+	}
+	return nil
+}
+
+// provideEphemeralVolumeSecret sets the credentials of the inline ephemeral volume with `volumeCtx` in `credentialCtx`,
+// checked by [validateEphemeralVolume].
+//
+// kubelet passes no node-publish secret for inline volumes, so the Secret named by [volumecontext.SecretName] is
+// read from the Pod's namespace.
+func (ns *S3NodeServer) provideEphemeralVolumeSecret(ctx context.Context, volumeCtx map[string]string, credentialCtx *credentialprovider.ProvideContext) error {
+	secretName := volumeCtx[volumecontext.SecretName]
+	if ns.Secrets == nil {
+		return status.Error(codes.FailedPrecondition, "Secrets of inline ephemeral volumes cannot be read")
+	}
+
+	namespace := volumeCtx[volumecontext.CSIPodNamespace]
+	if namespace == "" {
+		return status.Errorf(codes.InvalidArgument, "Pod namespace not provided, %s requires podInfoOnMount", volumecontext.SecretName)
+	}
+
+	secret, err := ns.Secrets.Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return status.Errorf(codes.NotFound, "Secret %s/%s not found", namespace, secretName)
+	} else if apierrors.IsForbidden(err) {
+		// With scoped clients, Secrets can only be read in the namespaces allowed by the administrator
+		return status.Errorf(codes.PermissionDenied, "Secret %s/%s cannot be read by the node plugin: %v", namespace, secretName, err)
+	} else if err != nil {
+		return status.Errorf(codes.Internal, "Could not get Secret %s/%s: %v", namespace, secretName, err)
+	}
+
+	secretData := make(map[string]string, len(secret.Data))
+	for key, value := range secret.Data {
+		secretData[key] = string(value)
+	}
+	klog.V(4).Infof("NodePublishVolume: volume %s uses credentials from Secret %s/%s", credentialCtx.VolumeID, namespace, secretName)
+
+	credentialCtx.AuthenticationSource = credentialprovider.AuthenticationSourceSecret
+	credentialCtx.SecretData = secretData
+	return nil
+}
@@ -0,0 +1,55 @@
+package node
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+)
+
+// EnvFSGroupPropagationEnabled enables reading the fsGroup of workload Pods when kubelet does not pass it, see
+// [S3NodeServer.WorkloadPods].
+const EnvFSGroupPropagationEnabled = "FSGROUP_PROPAGATION_ENABLED"
+
+// workloadVolumeCapability returns `volCap` with the fsGroup of the workload Pod of `volumeCtx` as its volume mount
+// group, so the volume is mounted with a matching `--gid` and `--allow-other` as if kubelet passed it.
+//
+// kubelet only passes the fsGroup of volumes allowed by the `fsGroupPolicy` of the CSIDriver, by default volumes
+// with a single node writer access mode. `volCap` is returned unchanged if kubelet passed a volume mount group, if
+// fsGroups are not propagated or if the workload Pod has no fsGroup.
+func (ns *S3NodeServer) workloadVolumeCapability(ctx context.Context, volumeCtx map[string]string, volCap *csi.VolumeCapability) (*csi.VolumeCapability, error) {
+	capMount := volCap.GetMount()
+	if ns.WorkloadPods == nil || capMount == nil || capMount.GetVolumeMountGroup() != "" {
+		return volCap, nil
+	}
+
+	namespace, name := volumeCtx[volumecontext.CSIPodNamespace], volumeCtx[volumecontext.CSIPodName]
+	if namespace == "" || name == "" {
+		klog.V(4).Infof("NodePublishVolume: Pod not provided, the fsGroup of the workload cannot be propagated without podInfoOnMount")
+		return volCap, nil
+	}
+
+	pod, err := ns.WorkloadPods.Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, status.Errorf(codes.NotFound, "Pod %s/%s not found", namespace, name)
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "Could not get Pod %s/%s to propagate its fsGroup: %v", namespace, name, err)
+	}
+	if pod.Spec.SecurityContext == nil || pod.Spec.SecurityContext.FSGroup == nil {
+		return volCap, nil
+	}
+
+	fsGroup := strconv.FormatInt(*pod.Spec.SecurityContext.FSGroup, 10)
+	klog.V(4).Infof("NodePublishVolume: propagating fsGroup %s of Pod %s/%s", fsGroup, namespace, name)
+	volCap = proto.Clone(volCap).(*csi.VolumeCapability)
+	volCap.GetMount().VolumeMountGroup = fsGroup
+	return volCap, nil
+}
@@ -0,0 +1,142 @@
+// Package metrics holds the Prometheus metrics of the node plugin, served over HTTP if [EnvMetricsAddress] is set.
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"k8s.io/klog/v2"
+)
+
+// EnvMetricsAddress is the address the node plugin serves its metrics on, e.g. `:9809`. Metrics are not served if empty.
+const EnvMetricsAddress = "NODE_METRICS_ADDRESS"
+
+// Registry holds the metrics of the node plugin.
+var Registry = prometheus.NewRegistry()
+
+// Metrics about unmounts of targets still used by processes, see [mounter.BusyUnmounter].
+// A high rate of busy unmounts of a workload hints at containers leaking open files or processes.
+var (
+	BusyUnmountsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "scality_csi_node_busy_unmounts_total",
+		Help: "Number of unmounts of targets with open files, by busy unmount policy and outcome (released, lazy, failed).",
+	}, []string{"policy", "outcome"})
+)
+
+// Metrics about forced cleanups of wedged targets and sources, see [mounter.PodMounter.SetForcedCleanup].
+var (
+	ForcedUnmountsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "scality_csi_node_forced_unmounts_total",
+		Help: "Number of wedged mounts detached on NodeUnpublishVolume, by mount (target, source) and outcome (detached, failed).",
+	}, []string{"mount", "outcome"})
+)
+
+// Metrics about the reachability of the S3 endpoint from the node, see [endpointprobe.Prober].
+var (
+	S3EndpointReachable = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "scality_csi_node_s3_endpoint_reachable",
+		Help: "Whether the last probe of the S3 endpoint from the node got a response (1) or not (0).",
+	})
+)
+
+// Metrics about mount phases of the pod mounter that timed out, see [mounter.MountTimeouts].
+var (
+	MountPhaseTimeoutsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "scality_csi_node_mount_phase_timeouts_total",
+		Help: "Number of mounts that failed as a mount phase timed out, by phase.",
+	}, []string{"phase"})
+)
+
+// Metrics about the pressure of the node and the concurrency of mounts made under pressure, see [pressure.Monitor].
+var (
+	PressureStallPercent = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "scality_csi_node_pressure_stall_percent",
+		Help: "Share of time some tasks of the node stalled on a resource (io, memory) over the last 10 seconds.",
+	}, []string{"resource"})
+	AdaptiveConcurrencyDecisionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "scality_csi_node_adaptive_concurrency_decisions_total",
+		Help: "Number of mounts whose concurrency was adapted to the pressure of the node, by decision (throttled, unchanged).",
+	}, []string{"decision"})
+)
+
+// Metrics about mounts waiting for a slot of the mount limiter, see [mounter.MountLimiter].
+var (
+	MountQueueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "scality_csi_node_mount_queue_depth",
+		Help: "Number of mounts waiting for a slot of the mount limiter of the node.",
+	})
+	MountQueueWaitSeconds = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "scality_csi_node_mount_queue_wait_seconds",
+		Help:    "Time mounts waited for a slot of the mount limiter of the node.",
+		Buckets: []float64{0.1, 0.5, 1, 5, 10, 30, 60, 120},
+	})
+)
+
+// Metrics about mounts of Mountpoint Pods that stopped responding, see [mounter.MountHealthChecker].
+var (
+	UnresponsiveMountsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "scality_csi_node_unresponsive_mounts_total",
+		Help: "Number of mounts of Mountpoint Pods reported unhealthy as they stopped responding to statfs.",
+	})
+	MountHealthRemountsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "scality_csi_node_mount_health_remounts_total",
+		Help: "Number of targets remounted after their Mountpoint container was restarted for an unresponsive mount, by outcome (remounted, failed).",
+	}, []string{"outcome"})
+	MountReconnectsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "scality_csi_node_mount_reconnects_total",
+		Help: "Number of sources of Mountpoint Pods mounted again with a new FUSE device after a crash of Mountpoint, by outcome (reconnected, failed).",
+	}, []string{"outcome"})
+)
+
+// Metrics about the availability of the Kubernetes API server to the Mountpoint Pod watcher, see [watcher.Watcher].
+// While the API server is unavailable, mounts are served from a cache that gets stale.
+var (
+	PodWatcherAPIAvailable = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "scality_csi_node_pod_watcher_api_available",
+		Help: "Whether the Kubernetes API server is available to the Mountpoint Pod watcher (1) or its circuit breaker is open (0).",
+	})
+	PodWatcherAPIFailuresTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "scality_csi_node_pod_watcher_api_failures_total",
+		Help: "Number of failed list and watch requests of the Mountpoint Pod watcher to the Kubernetes API server.",
+	})
+	PodWatcherCacheStalenessSeconds = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "scality_csi_node_pod_watcher_cache_staleness_seconds",
+		Help: "Time since the Mountpoint Pod cache of the node plugin was last synced with the Kubernetes API server, 0 while it is available.",
+	})
+)
+
+// Metrics about CSI calls served by the driver, recorded by its gRPC interceptor. Methods are the short names of the CSI
+// RPCs, e.g. `NodePublishVolume`, and codes the gRPC status codes of their responses.
+var (
+	GRPCRequestDurationSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "scality_csi_node_grpc_request_duration_seconds",
+		Help:    "Duration of CSI calls served by the node plugin, by method and gRPC status code.",
+		Buckets: []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 120},
+	}, []string{"method", "code"})
+)
+
+func init() {
+	Registry.MustRegister(BusyUnmountsTotal, ForcedUnmountsTotal, S3EndpointReachable, MountPhaseTimeoutsTotal, PressureStallPercent, AdaptiveConcurrencyDecisionsTotal,
+		MountQueueDepth, MountQueueWaitSeconds, UnresponsiveMountsTotal, MountHealthRemountsTotal, MountReconnectsTotal,
+		PodWatcherAPIAvailable, PodWatcherAPIFailuresTotal, PodWatcherCacheStalenessSeconds, GRPCRequestDurationSeconds)
+}
+
+// Serve serves the metrics of [Registry] at `/metrics` on `addr` until `stopCh` is closed.
+func Serve(addr string, stopCh <-chan struct{}) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(Registry, promhttp.HandlerOpts{}))
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-stopCh
+		_ = server.Close()
+	}()
+
+	klog.Infof("Serving node plugin metrics on %s", addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		klog.Errorf("Failed to serve node plugin metrics on %s: %v", addr, err)
+	}
+}
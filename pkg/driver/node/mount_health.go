@@ -0,0 +1,30 @@
+package node
+
+import (
+	"context"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
+)
+
+// trackMountHealth remounts the volume about to be mounted at `target` once its Mountpoint container is restarted
+// for an unresponsive mount, see [mounter.MountHealthChecker]. Like [S3NodeServer.trackEndpoint], it must be called
+// before mounting the volume, and [mounter.MountHealthChecker.Untrack] if the mount fails.
+func (ns *S3NodeServer) trackMountHealth(bucket, target string, credentialCtx credentialprovider.ProvideContext, args mountpoint.Args, fsGroup string) {
+	if ns.MountHealth == nil {
+		return
+	}
+	args = mountpoint.ParseArgs(args.SortedList())
+	ns.MountHealth.Track(target, func(ctx context.Context) error {
+		return ns.remount(ctx, bucket, target, credentialCtx, mountpoint.ParseArgs(args.SortedList()), fsGroup)
+	})
+}
+
+// remount unmounts the volume at `target` and mounts it again with `args`.
+func (ns *S3NodeServer) remount(ctx context.Context, bucket, target string, credentialCtx credentialprovider.ProvideContext, args mountpoint.Args, fsGroup string) error {
+	cleanupCtx := credentialprovider.CleanupContext{VolumeID: credentialCtx.VolumeID, PodID: credentialCtx.PodID}
+	if err := ns.Mounter.Unmount(ctx, target, cleanupCtx); err != nil {
+		return err
+	}
+	return ns.Mounter.Mount(ctx, bucket, target, credentialCtx, args, fsGroup)
+}
@@ -0,0 +1,224 @@
+// Package mountaudit records the mounts and unmounts of volumes made by the node plugin as NDJSON, uploaded
+// periodically to an audit bucket, so compliance teams know which workloads accessed which buckets.
+//
+// Records are chained: each one holds the SHA-256 hash of the previous record of the node plugin, so a record removed
+// or modified after its upload breaks the chain. Chains start again with the node plugin.
+package mountaudit
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"k8s.io/klog/v2"
+)
+
+// DefaultUploadInterval is how often records are uploaded if not configured.
+const DefaultUploadInterval = 5 * time.Minute
+
+// maxPendingRecords bounds the records kept while the audit bucket cannot be reached, the oldest are dropped first.
+const maxPendingRecords = 10000
+
+// Operations of records.
+const (
+	OperationMount   = "mount"
+	OperationUnmount = "unmount"
+)
+
+// Results of records.
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// A Record is a mount or unmount of a volume.
+type Record struct {
+	// Sequence is the position of the record in the chain of the node plugin, starting at 1.
+	Sequence  uint64    `json:"sequence"`
+	Time      time.Time `json:"time"`
+	Node      string    `json:"node"`
+	Operation string    `json:"operation"`
+	VolumeID  string    `json:"volumeID"`
+	Target    string    `json:"target"`
+	// Pod, Namespace and ServiceAccount are the workload Pod using the volume, empty without `podInfoOnMount`.
+	Pod            string `json:"pod,omitempty"`
+	Namespace      string `json:"namespace,omitempty"`
+	ServiceAccount string `json:"serviceAccount,omitempty"`
+	Bucket         string `json:"bucket,omitempty"`
+	Prefix         string `json:"prefix,omitempty"`
+	// Options are the mount options of the volume.
+	Options []string `json:"options,omitempty"`
+	Result  string   `json:"result"`
+	// Code is the gRPC status code of failed operations, their error is only logged by the node plugin as it may
+	// contain credentials.
+	Code string `json:"code,omitempty"`
+	// PreviousHash is the hash of the previous record, empty for the first record of the chain.
+	PreviousHash string `json:"previousHash"`
+	// Hash is the SHA-256 hash of the record encoded with an empty hash.
+	Hash string `json:"hash"`
+}
+
+// API is the subset of the S3 client used to upload records.
+type API interface {
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+}
+
+// A Log records mounts and unmounts of a node and uploads them to the audit bucket.
+type Log struct {
+	client API
+	bucket string
+	prefix string
+	node   string
+	now    func() time.Time
+
+	mu       sync.Mutex
+	pending  []Record
+	sequence uint64
+	lastHash string
+	// mounts are the successful mount records of each target, to complete unmount records
+	mounts map[string]Record
+}
+
+// NewLog creates a new [Log] of `node` uploading records to `bucket` under the directory `prefix` with `client`.
+func NewLog(client API, bucket, prefix, node string) *Log {
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return &Log{client: client, bucket: bucket, prefix: prefix, node: node, now: time.Now, mounts: make(map[string]Record)}
+}
+
+// Record adds `record` to the chain. Unmount records are completed with the workload, bucket and options of the
+// mount of their target if it was recorded. Successful mounts of targets already mounted are not recorded, kubelet
+// publishes volumes again periodically to refresh their credentials.
+func (l *Log) Record(record Record) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, mounted := l.mounts[record.Target]; mounted && record.Operation == OperationMount && record.Result == ResultSuccess {
+		return
+	}
+	if record.Operation == OperationUnmount {
+		if mount, ok := l.mounts[record.Target]; ok {
+			record.Pod, record.Namespace, record.ServiceAccount = mount.Pod, mount.Namespace, mount.ServiceAccount
+			record.Bucket, record.Prefix, record.Options = mount.Bucket, mount.Prefix, mount.Options
+		}
+		if record.Result == ResultSuccess {
+			delete(l.mounts, record.Target)
+		}
+	}
+
+	l.sequence++
+	record.Sequence = l.sequence
+	record.Time = l.now().UTC()
+	record.Node = l.node
+	record.PreviousHash = l.lastHash
+	record.Hash = ""
+	record.Hash = hash(record)
+	l.lastHash = record.Hash
+
+	if record.Operation == OperationMount && record.Result == ResultSuccess {
+		l.mounts[record.Target] = record
+	}
+	if len(l.pending) >= maxPendingRecords {
+		klog.Warningf("mountaudit: %d records are pending upload, dropping record %d", len(l.pending), l.pending[0].Sequence)
+		l.pending = l.pending[1:]
+	}
+	l.pending = append(l.pending, record)
+}
+
+// Flush uploads the pending records as a single NDJSON object, named after the node, the time of its first record
+// and its sequence. Records are kept pending if the upload fails.
+func (l *Log) Flush(ctx context.Context) error {
+	l.mu.Lock()
+	records := l.pending
+	l.pending = nil
+	l.mu.Unlock()
+	if len(records) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("failed to encode audit record %d: %w", record.Sequence, err)
+		}
+	}
+
+	first := records[0]
+	key := fmt.Sprintf("%s%s/%s-%08d.ndjson", l.prefix, l.node, first.Time.Format("20060102T150405Z"), first.Sequence)
+	_, err := l.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(l.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(body.Bytes()),
+		ContentType: aws.String("application/x-ndjson"),
+	})
+	if err != nil {
+		l.mu.Lock()
+		l.pending = append(records, l.pending...)
+		if excess := len(l.pending) - maxPendingRecords; excess > 0 {
+			klog.Warningf("mountaudit: %d records are pending upload, dropping the %d oldest", len(l.pending), excess)
+			l.pending = l.pending[excess:]
+		}
+		l.mu.Unlock()
+		return fmt.Errorf("failed to upload %d audit records to s3://%s/%s: %w", len(records), l.bucket, key, err)
+	}
+	klog.V(4).Infof("mountaudit: Uploaded %d audit records to s3://%s/%s", len(records), l.bucket, key)
+	return nil
+}
+
+// Start uploads records every `interval` until `stopCh` is closed, and uploads the remaining records once stopped.
+func (l *Log) Start(stopCh <-chan struct{}, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			if err := l.Flush(ctx); err != nil {
+				klog.Errorf("mountaudit: %v", err)
+			}
+			cancel()
+			return
+		case <-ticker.C:
+			if err := l.Flush(context.Background()); err != nil {
+				klog.Errorf("mountaudit: %v", err)
+			}
+		}
+	}
+}
+
+// hash returns the hex-encoded SHA-256 hash of the JSON encoding of `record`.
+func hash(record Record) string {
+	data, _ := json.Marshal(record)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Verify checks `records` are a chain of consecutive records, starting with `previousHash`, and that none was
+// modified. It returns an error naming the first record breaking the chain.
+func Verify(records []Record, previousHash string) error {
+	for i, record := range records {
+		if i > 0 && record.Sequence != records[i-1].Sequence+1 {
+			return fmt.Errorf("record %d follows record %d, records are missing", record.Sequence, records[i-1].Sequence)
+		}
+		if record.PreviousHash != previousHash {
+			return fmt.Errorf("record %d does not follow the previous record", record.Sequence)
+		}
+		want := record.Hash
+		record.Hash = ""
+		if hash(record) != want {
+			return fmt.Errorf("record %d was modified", record.Sequence)
+		}
+		previousHash = want
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package mountaudit_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/mountaudit"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+type fakeS3 struct {
+	err     error
+	objects map[string][]byte
+}
+
+func (f *fakeS3) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	body, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.objects[*params.Bucket+"/"+*params.Key] = body
+	return &s3.PutObjectOutput{}, nil
+}
+
+// uploadedRecords returns the records of the single object uploaded to `client`.
+func uploadedRecords(t *testing.T, client *fakeS3) (string, []mountaudit.Record) {
+	t.Helper()
+	assert.Equals(t, 1, len(client.objects))
+	for key, body := range client.objects {
+		var records []mountaudit.Record
+		scanner := bufio.NewScanner(bytes.NewReader(body))
+		for scanner.Scan() {
+			var record mountaudit.Record
+			if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+				t.Fatalf("Invalid NDJSON line %q: %v", scanner.Text(), err)
+			}
+			records = append(records, record)
+		}
+		return key, records
+	}
+	return "", nil
+}
+
+func TestLog(t *testing.T) {
+	t.Run("uploads chained records", func(t *testing.T) {
+		client := &fakeS3{objects: map[string][]byte{}}
+		log := mountaudit.NewLog(client, "audit", "csi", "node-1")
+
+		log.Record(mountaudit.Record{
+			Operation: mountaudit.OperationMount, VolumeID: "vol", Target: "/target",
+			Pod: "app", Namespace: "team-a", ServiceAccount: "default", Bucket: "bucket", Prefix: "data/",
+			Options: []string{"allow-delete"}, Result: mountaudit.ResultSuccess,
+		})
+		// Republished by kubelet
+		log.Record(mountaudit.Record{Operation: mountaudit.OperationMount, VolumeID: "vol", Target: "/target", Result: mountaudit.ResultSuccess})
+		log.Record(mountaudit.Record{Operation: mountaudit.OperationUnmount, VolumeID: "vol", Target: "/target", Result: mountaudit.ResultSuccess})
+		log.Record(mountaudit.Record{Operation: mountaudit.OperationMount, VolumeID: "vol", Target: "/other", Result: mountaudit.ResultFailure, Code: "PermissionDenied"})
+		assert.NoError(t, log.Flush(context.Background()))
+
+		key, records := uploadedRecords(t, client)
+		if !strings.HasPrefix(key, "audit/csi/node-1/") || !strings.HasSuffix(key, "-00000001.ndjson") {
+			t.Fatalf("Unexpected object key %q", key)
+		}
+		assert.Equals(t, 3, len(records))
+		assert.NoError(t, mountaudit.Verify(records, ""))
+
+		unmount := records[1]
+		assert.Equals(t, mountaudit.OperationUnmount, unmount.Operation)
+		assert.Equals(t, "team-a", unmount.Namespace)
+		assert.Equals(t, "bucket", unmount.Bucket)
+		assert.Equals(t, "node-1", unmount.Node)
+		assert.Equals(t, "PermissionDenied", records[2].Code)
+
+		// Nothing left to upload
+		client.objects = map[string][]byte{}
+		assert.NoError(t, log.Flush(context.Background()))
+		assert.Equals(t, 0, len(client.objects))
+	})
+
+	t.Run("keeps records if the upload fails", func(t *testing.T) {
+		client := &fakeS3{objects: map[string][]byte{}, err: errors.New("connection refused")}
+		log := mountaudit.NewLog(client, "audit", "", "node-1")
+		log.Record(mountaudit.Record{Operation: mountaudit.OperationMount, Target: "/target", Result: mountaudit.ResultSuccess})
+		if err := log.Flush(context.Background()); err == nil {
+			t.Fatalf("Expected the upload to fail")
+		}
+
+		client.err = nil
+		log.Record(mountaudit.Record{Operation: mountaudit.OperationUnmount, Target: "/target", Result: mountaudit.ResultSuccess})
+		assert.NoError(t, log.Flush(context.Background()))
+		_, records := uploadedRecords(t, client)
+		assert.Equals(t, 2, len(records))
+		assert.NoError(t, mountaudit.Verify(records, ""))
+	})
+}
+
+func TestVerify(t *testing.T) {
+	client := &fakeS3{objects: map[string][]byte{}}
+	log := mountaudit.NewLog(client, "audit", "", "node-1")
+	for _, target := range []string{"/a", "/b", "/c"} {
+		log.Record(mountaudit.Record{Operation: mountaudit.OperationMount, Target: target, Bucket: "bucket", Result: mountaudit.ResultSuccess})
+	}
+	assert.NoError(t, log.Flush(context.Background()))
+	_, records := uploadedRecords(t, client)
+
+	modified := append([]mountaudit.Record(nil), records...)
+	modified[1].Bucket = "other-bucket"
+	if err := mountaudit.Verify(modified, ""); err == nil || !strings.Contains(err.Error(), "record 2 was modified") {
+		t.Fatalf("Expected record 2 to be reported modified, got %v", err)
+	}
+
+	removed := []mountaudit.Record{records[0], records[2]}
+	if err := mountaudit.Verify(removed, ""); err == nil || !strings.Contains(err.Error(), "records are missing") {
+		t.Fatalf("Expected missing records to be reported, got %v", err)
+	}
+
+	// Later records are verified with the hash of the last verified one
+	assert.NoError(t, mountaudit.Verify(records[1:], records[0].Hash))
+}
@@ -0,0 +1,70 @@
+package mountaudit
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"k8s.io/klog/v2"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+)
+
+// Environment variables configuring the mount audit log.
+const (
+	EnvBucket         = "MOUNT_AUDIT_BUCKET"
+	EnvPrefix         = "MOUNT_AUDIT_PREFIX"
+	EnvUploadInterval = "MOUNT_AUDIT_UPLOAD_INTERVAL"
+)
+
+const defaultRegion = "us-east-1"
+
+// NewLogFromEnv returns a new [Log] of `node` configured from the driver's environment variables, and the interval
+// to upload its records. It returns nil if mounts are not audited.
+//
+// Records are uploaded with the driver-level credentials to the driver-level endpoint.
+func NewLogFromEnv(ctx context.Context, node string) (*Log, time.Duration, error) {
+	bucket := os.Getenv(EnvBucket)
+	if bucket == "" {
+		return nil, 0, nil
+	}
+
+	interval := DefaultUploadInterval
+	if value := os.Getenv(EnvUploadInterval); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			return nil, 0, fmt.Errorf("invalid %s %q, must be a positive duration", EnvUploadInterval, value)
+		}
+		interval = parsed
+	}
+
+	// Read credentials from the environment on every call, as they might be rotated while the driver is running
+	creds := aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+		return aws.Credentials{
+			AccessKeyID:     os.Getenv(envprovider.EnvAccessKeyID),
+			SecretAccessKey: os.Getenv(envprovider.EnvSecretAccessKey),
+			SessionToken:    os.Getenv(envprovider.EnvSessionToken),
+			Source:          "DriverEnvironment",
+		}, nil
+	})
+	region := os.Getenv(envprovider.EnvRegion)
+	if region == "" {
+		region = defaultRegion
+	}
+	awsCfg, err := config.LoadDefaultConfig(ctx, config.WithCredentialsProvider(creds), config.WithRegion(region))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		o.UsePathStyle = true
+		o.BaseEndpoint = aws.String(os.Getenv(envprovider.EnvEndpointURL))
+	})
+
+	prefix := os.Getenv(EnvPrefix)
+	klog.Infof("mountaudit: Uploading mount audit records to s3://%s/%s every %v", bucket, prefix, interval)
+	return NewLog(client, bucket, prefix, node), interval, nil
+}
@@ -0,0 +1,146 @@
+package mounter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/metrics"
+	mpmounter "github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint/mounter"
+)
+
+// A BusyUnmountPolicy is how targets still used by processes, e.g. files leaked open by misbehaving containers,
+// are unmounted on NodeUnpublishVolume.
+type BusyUnmountPolicy string
+
+// Busy unmount policies.
+const (
+	// BusyUnmountLazy detaches busy targets right away, processes keep using the files they opened.
+	BusyUnmountLazy BusyUnmountPolicy = "lazy"
+	// BusyUnmountWait waits up to the busy unmount timeout for processes to release busy targets, and detaches
+	// them afterwards.
+	BusyUnmountWait BusyUnmountPolicy = "wait"
+	// BusyUnmountFail fails unmounts of busy targets, kubelet retries them until processes release the targets.
+	BusyUnmountFail BusyUnmountPolicy = "fail"
+)
+
+// Environment variables configuring the busy unmount policy of the node plugin.
+const (
+	EnvBusyUnmountPolicy  = "BUSY_UNMOUNT_POLICY"
+	EnvBusyUnmountTimeout = "BUSY_UNMOUNT_TIMEOUT"
+)
+
+// DefaultBusyUnmountTimeout is how long [BusyUnmountWait] waits for busy targets to be released by default.
+const DefaultBusyUnmountTimeout = 30 * time.Second
+
+// busyUnmountRetryInterval is how often unmounts of busy targets are retried with [BusyUnmountWait].
+const busyUnmountRetryInterval = time.Second
+
+// ErrTargetBusy is returned when a target is not unmounted as processes still use it.
+var ErrTargetBusy = errors.New("target is busy")
+
+// A BusyUnmountConfig configures unmounts of busy targets.
+type BusyUnmountConfig struct {
+	Policy BusyUnmountPolicy
+	// Timeout is how long [BusyUnmountWait] waits for busy targets to be released.
+	Timeout time.Duration
+}
+
+// BusyUnmountConfigFromEnv returns the busy unmount configuration of [EnvBusyUnmountPolicy] and
+// [EnvBusyUnmountTimeout], defaulting to [BusyUnmountLazy] and [DefaultBusyUnmountTimeout].
+func BusyUnmountConfigFromEnv() (BusyUnmountConfig, error) {
+	config := BusyUnmountConfig{Policy: BusyUnmountLazy, Timeout: DefaultBusyUnmountTimeout}
+	if value := os.Getenv(EnvBusyUnmountPolicy); value != "" {
+		switch policy := BusyUnmountPolicy(value); policy {
+		case BusyUnmountLazy, BusyUnmountWait, BusyUnmountFail:
+			config.Policy = policy
+		default:
+			return config, fmt.Errorf("invalid %s %q, must be one of %q, %q or %q", EnvBusyUnmountPolicy, value, BusyUnmountLazy, BusyUnmountWait, BusyUnmountFail)
+		}
+	}
+	if value := os.Getenv(EnvBusyUnmountTimeout); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return config, fmt.Errorf("invalid %s %q, must be a positive duration", EnvBusyUnmountTimeout, value)
+		}
+		config.Timeout = timeout
+	}
+	return config, nil
+}
+
+// A BusyUnmounter unmounts targets, applying its [BusyUnmountPolicy] to targets still used by processes.
+type BusyUnmounter struct {
+	config      BusyUnmountConfig
+	unmount     func(target string) error
+	lazyUnmount func(target string) error
+}
+
+// NewBusyUnmounter creates a new [BusyUnmounter] unmounting targets with `unmount`.
+func NewBusyUnmounter(config BusyUnmountConfig, unmount func(target string) error) *BusyUnmounter {
+	return &BusyUnmounter{
+		config:      config,
+		unmount:     unmount,
+		lazyUnmount: mpmounter.UnmountLazy,
+	}
+}
+
+// Unmount unmounts `target`. If processes still use it, `target` is detached, unmounted once released or not
+// unmounted depending on the policy, and the outcome is counted in [metrics.BusyUnmountsTotal].
+func (u *BusyUnmounter) Unmount(ctx context.Context, target string) error {
+	err := u.unmount(target)
+	if !mpmounter.IsBusy(err) {
+		return err
+	}
+
+	switch u.config.Policy {
+	case BusyUnmountFail:
+		u.count("failed")
+		return fmt.Errorf("%w, processes still have files open on %s: %v", ErrTargetBusy, target, err)
+	case BusyUnmountWait:
+		klog.V(4).Infof("Target %s is busy, waiting up to %v for processes to release it", target, u.config.Timeout)
+		if released, err := u.waitUntilReleased(ctx, target); released || err != nil {
+			return err
+		}
+	}
+
+	klog.Warningf("Target %s is busy, unmounting it lazily. Processes with files open on it keep them until they exit", target)
+	if err := u.lazyUnmount(target); err != nil {
+		u.count("failed")
+		return err
+	}
+	u.count("lazy")
+	return nil
+}
+
+// waitUntilReleased retries unmounting busy `target` until it succeeds, and returns whether it did before the
+// busy unmount timeout.
+func (u *BusyUnmounter) waitUntilReleased(ctx context.Context, target string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.config.Timeout)
+	defer cancel()
+
+	var unmountErr error
+	err := wait.PollUntilContextCancel(ctx, busyUnmountRetryInterval, false, func(context.Context) (bool, error) {
+		unmountErr = u.unmount(target)
+		if mpmounter.IsBusy(unmountErr) {
+			return false, nil
+		}
+		return true, unmountErr
+	})
+	if unmountErr != nil && !mpmounter.IsBusy(unmountErr) {
+		return false, unmountErr
+	}
+	if err != nil {
+		return false, nil
+	}
+	u.count("released")
+	return true, nil
+}
+
+func (u *BusyUnmounter) count(outcome string) {
+	metrics.BusyUnmountsTotal.WithLabelValues(string(u.config.Policy), outcome).Inc()
+}
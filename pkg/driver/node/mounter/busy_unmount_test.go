@@ -0,0 +1,83 @@
+package mounter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestBusyUnmounter(t *testing.T) {
+	const target = "/var/lib/kubelet/pods/workload-1/volumes/kubernetes.io~csi/pv-1/mount"
+	busyErr := fmt.Errorf("unmount failed: exit status 32\nOutput: umount: %s: target is busy", target)
+
+	tests := []struct {
+		name string
+		// busyAttempts is the number of unmount attempts failing as the target is busy
+		busyAttempts int
+		config       BusyUnmountConfig
+		wantErr      error
+		wantLazy     bool
+	}{
+		{name: "target not busy", config: BusyUnmountConfig{Policy: BusyUnmountFail}},
+		{name: "lazy", busyAttempts: 1, config: BusyUnmountConfig{Policy: BusyUnmountLazy}, wantLazy: true},
+		{name: "fail", busyAttempts: 1, config: BusyUnmountConfig{Policy: BusyUnmountFail}, wantErr: ErrTargetBusy},
+		{name: "wait until released", busyAttempts: 1, config: BusyUnmountConfig{Policy: BusyUnmountWait, Timeout: 5 * time.Second}},
+		{name: "wait until timeout", busyAttempts: 100, config: BusyUnmountConfig{Policy: BusyUnmountWait, Timeout: 10 * time.Millisecond}, wantLazy: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts, lazy := 0, false
+			u := NewBusyUnmounter(tt.config, func(string) error {
+				attempts++
+				if attempts <= tt.busyAttempts {
+					return busyErr
+				}
+				return nil
+			})
+			u.lazyUnmount = func(string) error {
+				lazy = true
+				return nil
+			}
+
+			err := u.Unmount(context.Background(), target)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			assert.Equals(t, tt.wantLazy, lazy)
+		})
+	}
+
+	t.Run("other errors are returned", func(t *testing.T) {
+		u := NewBusyUnmounter(BusyUnmountConfig{Policy: BusyUnmountLazy}, func(string) error { return syscall.EINVAL })
+		u.lazyUnmount = func(string) error {
+			t.Fatalf("Expected no lazy unmount")
+			return nil
+		}
+		if err := u.Unmount(context.Background(), target); !errors.Is(err, syscall.EINVAL) {
+			t.Fatalf("Expected EINVAL, got %v", err)
+		}
+	})
+}
+
+func TestBusyUnmountConfigFromEnv(t *testing.T) {
+	config, err := BusyUnmountConfigFromEnv()
+	assert.NoError(t, err)
+	assert.Equals(t, BusyUnmountConfig{Policy: BusyUnmountLazy, Timeout: DefaultBusyUnmountTimeout}, config)
+
+	t.Setenv(EnvBusyUnmountPolicy, "wait")
+	t.Setenv(EnvBusyUnmountTimeout, "2m")
+	config, err = BusyUnmountConfigFromEnv()
+	assert.NoError(t, err)
+	assert.Equals(t, BusyUnmountConfig{Policy: BusyUnmountWait, Timeout: 2 * time.Minute}, config)
+
+	t.Setenv(EnvBusyUnmountPolicy, "force")
+	if _, err := BusyUnmountConfigFromEnv(); err == nil {
+		t.Fatalf("Expected an invalid policy to be rejected")
+	}
+}
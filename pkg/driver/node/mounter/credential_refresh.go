@@ -0,0 +1,50 @@
+package mounter
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"time"
+
+	"github.com/google/renameio"
+	"k8s.io/klog/v2"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mountoptions"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+)
+
+// credentialUpdateTimeout bounds pushing a credential file to a running Mountpoint Pod.
+const credentialUpdateTimeout = 10 * time.Second
+
+// credentialFileWriter returns the writer of credential files of the Mountpoint Pod `mpPodName` at `podPath`.
+//
+// Credential files are pushed to the Mountpoint Pod on its control socket once it advertised
+// [mountoptions.CapabilityCredentialRefresh] when its source was mounted, the Mountpoint Pod then rewrites them
+// atomically in its credentials directory. They are written in place otherwise, e.g. before the source is mounted,
+// for Mountpoint Pods of previous versions, or if the Mountpoint Pod cannot be reached.
+func (pm *PodMounter) credentialFileWriter(ctx context.Context, mpPodName, podPath string) func(path string, data []byte, perm fs.FileMode) error {
+	credentialsDir := pm.credentialsDir(podPath)
+	controlSockPath := mppod.PathOnHost(podPath, mppod.KnownPathMountControlSock)
+	return func(path string, data []byte, perm fs.FileMode) error {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("not writing credential file %s: %w", filepath.Base(path), context.Cause(ctx))
+		}
+		handshake, ok := pm.registry.Handshake(mpPodName)
+		if !ok || !handshake.Supports(mountoptions.CapabilityCredentialRefresh) || filepath.Dir(path) != credentialsDir {
+			return renameio.WriteFile(path, data, perm)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, credentialUpdateTimeout)
+		defer cancel()
+		err := mountoptions.SendCredentialUpdate(ctx, controlSockPath, mountoptions.CredentialUpdate{
+			Files: []mountoptions.CredentialFile{{Name: filepath.Base(path), Data: data, Perm: perm}},
+		})
+		if err != nil {
+			klog.Warningf("Failed to push credential file %s to Mountpoint Pod %s, writing it in place: %v", filepath.Base(path), mpPodName, err)
+			return renameio.WriteFile(path, data, perm)
+		}
+		klog.V(4).Infof("Pushed credential file %s to Mountpoint Pod %s", filepath.Base(path), mpPodName)
+		return nil
+	}
+}
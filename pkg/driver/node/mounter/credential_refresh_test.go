@@ -0,0 +1,82 @@
+package mounter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mountoptions"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+)
+
+func TestCredentialFileWriter(t *testing.T) {
+	dir := t.TempDir()
+	// Keep the relative path of the control socket shorter than 108 characters
+	t.Chdir(dir)
+	registry, _, err := LoadMountRegistry(filepath.Join(dir, "mounts.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pm := &PodMounter{registry: registry}
+	podPath := filepath.Join(dir, "pods", "mp-1-uid")
+	credentialsDir := pm.credentialsDir(podPath)
+	if err := os.MkdirAll(credentialsDir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(credentialsDir, "vol-s3-csi-credentials")
+	write := pm.credentialFileWriter(context.Background(), "mp-1", podPath)
+
+	// The source of the Mountpoint Pod is not mounted yet, the file is written in place
+	if err := write(path, []byte("initial"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	assertFileContent(t, path, "initial")
+
+	handshake := mountoptions.LocalHandshake()
+	if err := registry.Add(MountRecord{Target: "/pods/a/mount", Source: "/mnt/mp-1", MountpointPod: "mp-1", Handshake: &handshake}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The Mountpoint Pod is not reachable, the file is written in place
+	if err := write(path, []byte("unreachable"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	assertFileContent(t, path, "unreachable")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pushed := make(chan mountoptions.CredentialUpdate, 1)
+	controlSockPath := mppod.PathOnHost(podPath, mppod.KnownPathMountControlSock)
+	go func() {
+		_ = mountoptions.ServeControl(ctx, controlSockPath, func(update mountoptions.CredentialUpdate) error {
+			pushed <- update
+			return nil
+		})
+	}()
+	waitFor(t, func() bool {
+		_, err := os.Stat(controlSockPath)
+		return err == nil
+	})
+
+	if err := write(path, []byte("refreshed"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	update := <-pushed
+	if len(update.Files) != 1 || update.Files[0].Name != "vol-s3-csi-credentials" || string(update.Files[0].Data) != "refreshed" {
+		t.Fatalf("Expected refreshed credential file to be pushed, got %+v", update)
+	}
+	// The Mountpoint Pod writes the file
+	assertFileContent(t, path, "unreachable")
+}
+
+func assertFileContent(t *testing.T, path, want string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != want {
+		t.Fatalf("Expected %s to contain %q, got %q", path, want, data)
+	}
+}
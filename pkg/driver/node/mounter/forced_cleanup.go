@@ -0,0 +1,120 @@
+package mounter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/metrics"
+	mpmounter "github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint/mounter"
+)
+
+// EnvForcedCleanupEnabled is the environment variable enabling the forced cleanup of wedged targets on
+// NodeUnpublishVolume, see [PodMounter.SetForcedCleanup].
+const EnvForcedCleanupEnabled = "FORCED_CLEANUP_ENABLED"
+
+// forcedCleanup cleans up targets whose FUSE mount is wedged, which cannot be unmounted normally.
+type forcedCleanup struct {
+	// pods deletes the Mountpoint Pods of wedged sources.
+	pods typedcorev1.PodInterface
+	// lazyUnmount detaches wedged mounts.
+	lazyUnmount func(target string) error
+	// stat accesses mounts to find whether they are wedged.
+	stat func(path string) error
+}
+
+// SetForcedCleanup makes [PodMounter.Unmount] clean up targets whose FUSE mount is wedged, e.g. `transport endpoint
+// is not connected` once Mountpoint crashed, instead of failing until the workload Pod is stuck terminating: the
+// target is detached, and if the source it is bind-mounted from is wedged too, the source is detached, the credentials
+// of the workload are removed from the Mountpoint Pod of the source, and the Mountpoint Pod is deleted with `pods`.
+// The controller creates a new Mountpoint Pod for the workloads still using the volume.
+func (pm *PodMounter) SetForcedCleanup(pods typedcorev1.PodInterface) {
+	pm.forcedCleanup = &forcedCleanup{
+		pods:        pods,
+		lazyUnmount: mpmounter.UnmountLazy,
+		stat: func(path string) error {
+			_, err := os.Stat(path)
+			return err
+		},
+	}
+}
+
+// forceUnmount cleans up wedged `target` which failed to unmount with `unmountErr`, unpublished by the workload of
+// `credentialCtx`.
+func (pm *PodMounter) forceUnmount(ctx context.Context, target string, credentialCtx credentialprovider.CleanupContext, unmountErr error) error {
+	fc := pm.forcedCleanup
+	klog.Warningf("Target %s is wedged (%v), detaching it", target, unmountErr)
+	if err := detach(fc.lazyUnmount, target); err != nil {
+		metrics.ForcedUnmountsTotal.WithLabelValues("target", "failed").Inc()
+		return fmt.Errorf("failed to detach wedged target %q: %w", target, err)
+	}
+	metrics.ForcedUnmountsTotal.WithLabelValues("target", "detached").Inc()
+
+	record, ok := pm.registry.Get(target)
+	if !ok || record.Source == "" {
+		return nil
+	}
+	if err := fc.stat(record.Source); !mpmounter.IsWedged(err) {
+		// Other workloads keep using the source
+		return nil
+	}
+
+	klog.Warningf("Source %s of Mountpoint Pod %s is wedged, detaching it and deleting the Mountpoint Pod", record.Source, record.MountpointPod)
+	if err := detach(fc.lazyUnmount, record.Source); err != nil {
+		metrics.ForcedUnmountsTotal.WithLabelValues("source", "failed").Inc()
+		return fmt.Errorf("failed to detach wedged source %q: %w", record.Source, err)
+	}
+	metrics.ForcedUnmountsTotal.WithLabelValues("source", "detached").Inc()
+
+	// The target is unmounted at this point, failures to clean up the Mountpoint Pod are left to the pod unmounter
+	if err := pm.deleteWedgedMountpointPod(ctx, record.MountpointPod, credentialCtx); err != nil {
+		klog.Errorf("Failed to delete Mountpoint Pod %s of wedged source %s: %v", record.MountpointPod, record.Source, err)
+	}
+	return nil
+}
+
+// deleteWedgedMountpointPod removes the credentials written for the workload of `credentialCtx` in Mountpoint Pod
+// `mpPodName` and deletes it.
+func (pm *PodMounter) deleteWedgedMountpointPod(ctx context.Context, mpPodName string, credentialCtx credentialprovider.CleanupContext) error {
+	pods := pm.forcedCleanup.pods
+	if pods == nil {
+		return nil
+	}
+	mpPod, err := pods.Get(ctx, mpPodName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	credentialCtx.WritePath = pm.credentialsDir(pm.podPath(mpPod))
+	credentialCtx.MountKind = credentialprovider.MountKindPod
+	if err := pm.credProvider.Cleanup(credentialCtx); err != nil {
+		klog.Warningf("Failed to clean up credentials of Mountpoint Pod %s: %v", mpPodName, err)
+	}
+
+	err = pods.Delete(ctx, mpPodName, metav1.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &mpPod.UID}})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	klog.Infof("Deleted Mountpoint Pod %s of a wedged source", mpPodName)
+	return nil
+}
+
+// detach lazily unmounts `path` with `lazyUnmount`, paths that are not mounted anymore are detached already.
+func detach(lazyUnmount func(string) error, path string) error {
+	err := lazyUnmount(path)
+	if errors.Is(err, syscall.EINVAL) || errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,121 @@
+package mounter
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/mount-utils"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestForcedCleanup(t *testing.T) {
+	const (
+		target    = "/var/lib/kubelet/pods/workload-uid/volumes/kubernetes.io~csi/pv/mount"
+		source    = "/var/lib/kubelet/plugins/s3.csi.scality.com/mnt/mp-1"
+		mpPodName = "mp-1"
+		mpPodUID  = "mp-1-uid"
+	)
+	wedged := &os.PathError{Op: "unmount", Path: target, Err: syscall.ENOTCONN}
+
+	setup := func(t *testing.T, sourceErr error) (*PodMounter, *fake.Clientset, *[]string) {
+		kubeletPath := t.TempDir()
+		registry, _, err := LoadMountRegistry(filepath.Join(kubeletPath, "mounts.json"))
+		assert.NoError(t, err)
+		assert.NoError(t, registry.Add(MountRecord{Target: target, VolumeID: "vol", Source: source, MountpointPod: mpPodName}))
+
+		fakeMounter := mount.NewFakeMounter([]mount.MountPoint{{Path: target}})
+		fakeMounter.UnmountFunc = func(string) error { return wedged }
+
+		client := fake.NewClientset(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: mpPodName, Namespace: "mount-s3", UID: types.UID(mpPodUID)}})
+		pm := &PodMounter{
+			mount:        fakeMounter,
+			kubeletPath:  kubeletPath,
+			registry:     registry,
+			credProvider: credentialprovider.New(client.CoreV1()),
+		}
+		pm.SetForcedCleanup(client.CoreV1().Pods("mount-s3"))
+
+		var detached []string
+		pm.forcedCleanup.lazyUnmount = func(path string) error {
+			detached = append(detached, path)
+			return nil
+		}
+		pm.forcedCleanup.stat = func(string) error { return sourceErr }
+		return pm, client, &detached
+	}
+	cleanupCtx := credentialprovider.CleanupContext{VolumeID: "vol", PodID: "workload-uid"}
+	mpPodExists := func(client *fake.Clientset) bool {
+		_, err := client.CoreV1().Pods("mount-s3").Get(context.Background(), mpPodName, metav1.GetOptions{})
+		return err == nil
+	}
+
+	t.Run("Detaches wedged targets and keeps their responsive source", func(t *testing.T) {
+		pm, client, detached := setup(t, nil)
+
+		assert.NoError(t, pm.Unmount(context.Background(), target, cleanupCtx))
+		assert.Equals(t, []string{target}, *detached)
+		assert.Equals(t, true, mpPodExists(client))
+		_, recorded := pm.registry.Get(target)
+		assert.Equals(t, false, recorded)
+	})
+
+	t.Run("Detaches wedged sources and deletes their Mountpoint Pod", func(t *testing.T) {
+		pm, client, detached := setup(t, &os.PathError{Op: "stat", Path: source, Err: syscall.ENOTCONN})
+
+		// Credentials of the workload in the Mountpoint Pod
+		credentialsDir := pm.credentialsDir(filepath.Join(pm.kubeletPath, "pods", mpPodUID))
+		assert.NoError(t, os.MkdirAll(credentialsDir, 0o750))
+		t.Setenv("AWS_ACCESS_KEY_ID", "test-access-key")
+		t.Setenv("AWS_SECRET_ACCESS_KEY", "test-secret-key")
+		_, _, err := pm.credProvider.Provide(context.Background(), credentialprovider.ProvideContext{
+			WritePath: credentialsDir,
+			EnvPath:   credentialsDir,
+			PodID:     "workload-uid",
+			VolumeID:  "vol",
+		})
+		assert.NoError(t, err)
+		files, err := os.ReadDir(credentialsDir)
+		assert.NoError(t, err)
+		if len(files) == 0 {
+			t.Fatal("Expected credentials to be written")
+		}
+
+		assert.NoError(t, pm.Unmount(context.Background(), target, cleanupCtx))
+		assert.Equals(t, []string{target, source}, *detached)
+		assert.Equals(t, false, mpPodExists(client))
+		files, err = os.ReadDir(credentialsDir)
+		assert.NoError(t, err)
+		assert.Equals(t, 0, len(files))
+	})
+
+	t.Run("Fails unmounts of wedged targets if disabled", func(t *testing.T) {
+		pm, _, _ := setup(t, nil)
+		pm.forcedCleanup = nil
+
+		err := pm.Unmount(context.Background(), target, cleanupCtx)
+		if !errors.Is(err, syscall.ENOTCONN) {
+			t.Fatalf("Expected the unmount to fail on the wedged target, got %v", err)
+		}
+	})
+
+	t.Run("Does not detach targets failing to unmount for other reasons", func(t *testing.T) {
+		pm, _, detached := setup(t, nil)
+		pm.mount.(*mount.FakeMounter).UnmountFunc = func(string) error { return syscall.EPERM }
+
+		err := pm.Unmount(context.Background(), target, cleanupCtx)
+		if !errors.Is(err, syscall.EPERM) {
+			t.Fatalf("Expected the unmount to fail, got %v", err)
+		}
+		assert.Equals(t, 0, len(*detached))
+	})
+}
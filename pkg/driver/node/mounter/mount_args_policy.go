@@ -1,38 +1,76 @@
 package mounter
 
 import (
+	"os"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/endpointfailover"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
 	"k8s.io/klog/v2"
 )
 
+// EnvAllowedEndpointURLs is the environment variable containing a comma-separated allowlist of S3 endpoint URLs
+// volumes can use instead of the driver-level endpoint, e.g. to reach another RING site.
+const EnvAllowedEndpointURLs = "ALLOWED_ENDPOINT_URLS"
+
+// A MountArgsPolicy restricts Mountpoint args of volumes further than the CSI driver does, as set in the driver
+// configuration file.
+type MountArgsPolicy struct {
+	// AllowedEndpointURLs are allowed in addition to the endpoint URLs of [EnvAllowedEndpointURLs].
+	AllowedEndpointURLs []string
+	// DeniedArgs are stripped from the args of volumes.
+	DeniedArgs []mountpoint.ArgKey
+}
+
+// mountArgsPolicy is the policy set with [SetMountArgsPolicy], nil if unset.
+var mountArgsPolicy atomic.Pointer[MountArgsPolicy]
+
+// SetMountArgsPolicy enforces `policy` on mounts made from now on, replacing the previous policy.
+func SetMountArgsPolicy(policy MountArgsPolicy) {
+	mountArgsPolicy.Store(&policy)
+}
+
 // enforceCSIDriverMountArgPolicy strips Mountpoint args the CSI driver does not support.
-// Reasons include platform limitations, unsupported backend features, and product scope choices.
+// Reasons include platform limitations, unsupported backend features, and product scope choices,
+// see [mountpoint.UnsupportedArgs].
 func enforceCSIDriverMountArgPolicy(args *mountpoint.Args) {
-	// The profile flag is not supported in our authentication model
-	if _, ok := args.Remove(mountpoint.ArgProfile); ok {
-		klog.Warningf("--profile ignored: only static keys are supported by the CSI driver")
+	// Volume-specific endpoint overrides are only supported for endpoints allowed by the cluster administrator
+	if endpointURL, ok := args.Remove(mountpoint.ArgEndpointURL); ok {
+		if IsAllowedEndpointURL(endpointURL) {
+			args.Set(mountpoint.ArgEndpointURL, endpointURL)
+		} else {
+			klog.Warningf("--endpoint-url ignored: %q is not in the driver's allowed endpoint URLs", endpointURL)
+		}
 	}
 
-	// Volume-specific endpoint overrides are not supported
-	if _, ok := args.Remove(mountpoint.ArgEndpointURL); ok {
-		klog.Warningf("--endpoint-url ignored: driver does not support per-volume endpoint overrides")
+	keys := make([]mountpoint.ArgKey, 0, len(mountpoint.UnsupportedArgs))
+	for key := range mountpoint.UnsupportedArgs {
+		keys = append(keys, key)
 	}
-
-	// These features are not supported by our backend as they are specific to Express One Zone
-	if _, ok := args.Remove(mountpoint.ArgExpressOneZoneCache); ok {
-		klog.Warningf("--cache-xz ignored: S3 Express One Zone cache is not supported by backend")
-	}
-	if _, ok := args.Remove(mountpoint.ArgExpressOneZoneIncrementalUpload); ok {
-		klog.Warningf("--incremental-upload ignored: S3 Express One Zone append not supported by backend")
+	slices.Sort(keys)
+	for _, key := range keys {
+		if _, ok := args.Remove(key); ok {
+			klog.Warningf("%s ignored: %s", key, mountpoint.UnsupportedArgs[key])
+		}
 	}
 
-	// This driver only supports STANDARD storage class for now so we do not allow the user to override it
-	if _, ok := args.Remove(mountpoint.ArgStorageClass); ok {
-		klog.Warningf("--storage-class ignored: only STANDARD is supported by the CSI driver")
+	if policy := mountArgsPolicy.Load(); policy != nil {
+		for _, key := range policy.DeniedArgs {
+			if _, ok := args.Remove(key); ok {
+				klog.Warningf("%s ignored: denied by the driver configuration", key)
+			}
+		}
 	}
+}
 
-	// This driver does not support fs-tab
-	if _, ok := args.Remove(mountpoint.ArgFsTab); ok {
-		klog.Warningf("-o ignored: driver does not support fs-tab")
+// IsAllowedEndpointURL returns whether `endpointURL` is in [EnvAllowedEndpointURLs] or the [MountArgsPolicy], or is a
+// failover endpoint of the driver, see [endpointfailover.EnvFailoverEndpointURLs].
+func IsAllowedEndpointURL(endpointURL string) bool {
+	allowed := append(strings.Split(os.Getenv(EnvAllowedEndpointURLs), ","), endpointfailover.DriverEndpointURLs()...)
+	if policy := mountArgsPolicy.Load(); policy != nil {
+		allowed = append(allowed, policy.AllowedEndpointURLs...)
 	}
+	return mountpoint.IsAllowedEndpointURL(endpointURL, allowed)
 }
@@ -0,0 +1,50 @@
+package mounter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
+)
+
+func FuzzEnforceCSIDriverMountArgPolicy(f *testing.F) {
+	for _, seed := range []string{
+		"--profile=default,--storage-class GLACIER,-o rw,--cache-xz bucket,--incremental-upload",
+		"--endpoint-url=https://s3.site-a.example.com,--endpoint-url=https://attacker.example.com",
+		"endpoint-url https://s3.site-a.example.com.attacker.example.com,\tprofile\n=default",
+		"--endpoint-url=https://user@s3.site-a.example.com,--endpoint-url=HTTPS://S3.SITE-A.EXAMPLE.COM/",
+		"--profile,--profile=,profile  x,--storage-class= STANDARD",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, mountOptions string) {
+		t.Setenv(EnvAllowedEndpointURLs, "https://s3.site-a.example.com")
+		args := mountpoint.ParseArgs(strings.Split(mountOptions, ","))
+		enforceCSIDriverMountArgPolicy(&args)
+
+		for _, a := range args.SortedList() {
+			key, value, _ := strings.Cut(a, "=")
+			if _, denied := mountpoint.UnsupportedArgs[key]; denied {
+				t.Fatalf("Unsupported %q from %q is passed to Mountpoint", a, mountOptions)
+			}
+			if key == mountpoint.ArgEndpointURL && !mountpoint.IsAllowedEndpointURL(value, []string{"https://s3.site-a.example.com"}) {
+				t.Fatalf("Endpoint URL %q from %q is not allowed but passed to Mountpoint", value, mountOptions)
+			}
+		}
+	})
+}
+
+func TestEnforceMountArgsPolicy(t *testing.T) {
+	t.Cleanup(func() { mountArgsPolicy.Store(nil) })
+	SetMountArgsPolicy(MountArgsPolicy{
+		AllowedEndpointURLs: []string{"https://s3.site-b.example.com"},
+		DeniedArgs:          []mountpoint.ArgKey{"--cache"},
+	})
+
+	args := mountpoint.ParseArgs([]string{"--cache=/tmp", "--read-only", "--endpoint-url=https://s3.site-b.example.com"})
+	enforceCSIDriverMountArgPolicy(&args)
+	if got, want := strings.Join(args.SortedList(), " "), "--endpoint-url=https://s3.site-b.example.com --read-only"; got != want {
+		t.Errorf("Expected args %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,167 @@
+package mounter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/google/renameio"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util"
+)
+
+// MountGenerationsPath returns the path of the mount generations of the node plugin, next to its mount registry.
+func MountGenerationsPath(kubeletPath string) string {
+	return filepath.Join(kubeletPath, "plugins", util.DriverName(), "mount-generations.json")
+}
+
+// A MountGeneration is the mount generation of a volume on the node, with the fingerprint of the options and
+// credentials it was last mounted with.
+type MountGeneration struct {
+	Generation  int64  `json:"generation"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// MountGenerations persists the mount generation of each volume on the node, keyed by volume ID. The generation of a
+// volume is incremented every time it is mounted by a Mountpoint Pod with other options or credentials than the
+// previous time, so applications can tell their view of the bucket may have changed and invalidate their caches.
+// Remounts with the same options and credentials, e.g. after a Mountpoint Pod upgrade, keep the generation.
+type MountGenerations struct {
+	mu          sync.Mutex
+	path        string
+	generations map[string]MountGeneration
+}
+
+// LoadMountGenerations loads the mount generations at `path`. Missing generations are empty.
+func LoadMountGenerations(path string) (*MountGenerations, error) {
+	g := &MountGenerations{path: path, generations: make(map[string]MountGeneration)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return g, nil
+	}
+	if err != nil {
+		return g, fmt.Errorf("failed to read mount generations %q: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &g.generations); err != nil {
+		return g, fmt.Errorf("failed to parse mount generations %q: %w", path, err)
+	}
+	return g, nil
+}
+
+// Get returns the mount generation of `volumeID`, 0 if it was never mounted on the node.
+func (g *MountGenerations) Get(volumeID string) int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.generations[volumeID].Generation
+}
+
+// Observe records that `volumeID` was mounted with `fingerprint`, and returns its mount generation: incremented if
+// `fingerprint` differs from the previous mount, starting at 1.
+func (g *MountGenerations) Observe(volumeID, fingerprint string) (int64, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	current := g.generations[volumeID]
+	if current.Generation > 0 && current.Fingerprint == fingerprint {
+		return current.Generation, nil
+	}
+	next := MountGeneration{Generation: current.Generation + 1, Fingerprint: fingerprint}
+	g.generations[volumeID] = next
+	if err := g.save(); err != nil {
+		return next.Generation, err
+	}
+	return next.Generation, nil
+}
+
+func (g *MountGenerations) save() error {
+	data, err := json.Marshal(g.generations)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(g.path), targetDirPerm); err != nil {
+		return fmt.Errorf("failed to create directory of mount generations %q: %w", g.path, err)
+	}
+	if err := renameio.WriteFile(g.path, data, mountRegistryFilePerm); err != nil {
+		return fmt.Errorf("failed to write mount generations %q: %w", g.path, err)
+	}
+	return nil
+}
+
+// mountFingerprint returns a fingerprint of the options and credentials `bucketName` is mounted with. The user-agent
+// and concurrency are ignored, they change with the version of the driver and Kubernetes or the pressure of the node
+// but not the view of the bucket. Credentials are
+// identified by their source and identity, not their secrets, as rotated secrets of the same identity see the same
+// objects.
+func mountFingerprint(bucketName string, args mountpoint.Args, credentialCtx credentialprovider.ProvideContext, authenticationSource credentialprovider.AuthenticationSource) string {
+	var argList []string
+	for _, arg := range args.SortedList() {
+		if !strings.HasPrefix(arg, mountpoint.ArgUserAgentPrefix) && !strings.HasPrefix(arg, mountpoint.ArgMaxThreads) {
+			argList = append(argList, arg)
+		}
+	}
+
+	identity := ""
+	switch authenticationSource {
+	case credentialprovider.AuthenticationSourceSecret:
+		identity = credentialCtx.SecretData["access_key_id"]
+	case credentialprovider.AuthenticationSourceRole, credentialprovider.AuthenticationSourceWebIdentity:
+		identity = credentialCtx.RoleARN
+	case credentialprovider.AuthenticationSourceFile:
+		identity = credentialCtx.CredentialsName
+	default:
+		identity = os.Getenv(envprovider.EnvAccessKeyID)
+	}
+
+	data, _ := json.Marshal(struct {
+		Bucket               string   `json:"bucket"`
+		Args                 []string `json:"args"`
+		AuthenticationSource string   `json:"authenticationSource"`
+		Identity             string   `json:"identity"`
+		CABundleSecret       string   `json:"caBundleSecret"`
+	}{bucketName, argList, authenticationSource, identity, credentialCtx.CABundleSecret.String()})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// SetAttachmentStatusWriter sets the client used to report mount generations in the status of
+// MountpointS3PodAttachments. Mount generations are only recorded on the node if it is not set.
+func (pm *PodMounter) SetAttachmentStatusWriter(writer client.StatusClient) {
+	pm.attachmentStatus = writer
+}
+
+// observeMountGeneration records that `volumeID` was mounted by a Mountpoint Pod of `s3pa` with `fingerprint`, and
+// reports its mount generation in the status of `s3pa`. Failures are logged, they never fail the mount.
+func (pm *PodMounter) observeMountGeneration(ctx context.Context, s3pa *crdv2.MountpointS3PodAttachment, volumeID, fingerprint string) {
+	if pm.generations == nil {
+		return
+	}
+	generation, err := pm.generations.Observe(volumeID, fingerprint)
+	if err != nil {
+		klog.Warningf("Failed to record mount generation %d of volume %s: %v", generation, volumeID, err)
+	}
+	klog.V(4).Infof("Volume %s is mounted with generation %d", volumeID, generation)
+
+	if pm.attachmentStatus == nil || s3pa.Status.MountGeneration == generation {
+		return
+	}
+	patched := s3pa.DeepCopy()
+	patched.Status.MountGeneration = generation
+	if err := pm.attachmentStatus.Status().Patch(ctx, patched, client.MergeFrom(s3pa)); err != nil {
+		klog.Warningf("Failed to report mount generation %d of volume %s in MountpointS3PodAttachment %s: %v", generation, volumeID, s3pa.Name, err)
+	}
+}
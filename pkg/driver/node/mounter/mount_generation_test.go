@@ -0,0 +1,72 @@
+package mounter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestMountGenerations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plugins", "mount-generations.json")
+	generations, err := LoadMountGenerations(path)
+	assert.NoError(t, err)
+	assert.Equals(t, int64(0), generations.Get("vol-1"))
+
+	for _, test := range []struct {
+		volumeID, fingerprint string
+		expected              int64
+	}{
+		{"vol-1", "a", 1},
+		{"vol-1", "a", 1},
+		{"vol-2", "a", 1},
+		{"vol-1", "b", 2},
+		{"vol-1", "a", 3},
+	} {
+		generation, err := generations.Observe(test.volumeID, test.fingerprint)
+		assert.NoError(t, err)
+		assert.Equals(t, test.expected, generation)
+	}
+
+	// Generations survive restarts of the node plugin
+	generations, err = LoadMountGenerations(path)
+	assert.NoError(t, err)
+	assert.Equals(t, int64(3), generations.Get("vol-1"))
+	generation, err := generations.Observe("vol-1", "a")
+	assert.NoError(t, err)
+	assert.Equals(t, int64(3), generation)
+
+	// Corrupted generations are reported and restart from scratch
+	assert.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+	generations, err = LoadMountGenerations(path)
+	if err == nil {
+		t.Fatal("Expected an error for corrupted mount generations")
+	}
+	assert.Equals(t, int64(0), generations.Get("vol-1"))
+}
+
+func TestMountFingerprint(t *testing.T) {
+	credentialCtx := credentialprovider.ProvideContext{SecretData: map[string]string{"access_key_id": "AKIA1", "secret_access_key": "secret1"}}
+	fingerprint := func(args []string, credentialCtx credentialprovider.ProvideContext) string {
+		return mountFingerprint("bucket", mountpoint.ParseArgs(args), credentialCtx, credentialprovider.AuthenticationSourceSecret)
+	}
+	base := fingerprint([]string{"--allow-delete", "--user-agent-prefix=s3-csi-driver/1.0"}, credentialCtx)
+
+	// The user-agent, concurrency and rotated secrets of the same identity keep the fingerprint
+	assert.Equals(t, base, fingerprint([]string{"--user-agent-prefix=s3-csi-driver/2.0", "--allow-delete"}, credentialCtx))
+	assert.Equals(t, base, fingerprint([]string{"--allow-delete", "--max-threads=4"}, credentialCtx))
+	rotated := credentialprovider.ProvideContext{SecretData: map[string]string{"access_key_id": "AKIA1", "secret_access_key": "secret2"}}
+	assert.Equals(t, base, fingerprint([]string{"--allow-delete"}, rotated))
+
+	// Other options or identities change it
+	if base == fingerprint([]string{"--allow-delete", "--read-only"}, credentialCtx) {
+		t.Fatal("Expected other options to change the fingerprint")
+	}
+	other := credentialprovider.ProvideContext{SecretData: map[string]string{"access_key_id": "AKIA2", "secret_access_key": "secret1"}}
+	if base == fingerprint([]string{"--allow-delete"}, other) {
+		t.Fatal("Expected other credentials to change the fingerprint")
+	}
+}
@@ -0,0 +1,306 @@
+package mounter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+	"k8s.io/klog/v2"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/metrics"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mounthealth"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+)
+
+// EnvMountHealthChecksEnabled is the environment variable enabling health checks of the mounts of Mountpoint Pods.
+const EnvMountHealthChecksEnabled = "MOUNT_HEALTH_CHECKS_ENABLED"
+
+const (
+	// MountHealthCheckInterval is how often mounts of Mountpoint Pods are checked.
+	MountHealthCheckInterval = 30 * time.Second
+	// MountHealthCheckTimeout is how long a mount has to respond to statfs before the check fails.
+	MountHealthCheckTimeout = 10 * time.Second
+	// MountHealthFailureThreshold is the number of consecutive failed checks after which a mount is reported
+	// unhealthy to its Mountpoint Pod.
+	MountHealthFailureThreshold = 2
+)
+
+// errMountUnresponsive is the outcome of checks of mounts not responding to statfs within the check timeout.
+var errMountUnresponsive = errors.New("mount did not respond to statfs")
+
+// A MountRemount unmounts and mounts again a target, see [MountHealthChecker.Track].
+type MountRemount func(ctx context.Context) error
+
+// mountHealth is the health of the source mount of a Mountpoint Pod.
+type mountHealth struct {
+	failures int
+	// checking is set while a statfs of the source has not returned, a hung mount blocks it indefinitely.
+	checking bool
+	// reported is set once the mount is reported unhealthy to its Mountpoint Pod, for kubelet to restart its container.
+	reported bool
+}
+
+// A MountHealthChecker checks that the source mount of each Mountpoint Pod in the mount registry responds to statfs.
+//
+// The check runs in the node plugin, as FUSE mounts are only accessible to the user mounting them. A mount not
+// responding for [MountHealthFailureThreshold] consecutive checks is reported unhealthy in [mppod.KnownPathMountHealth]
+// of its Mountpoint Pod, failing the liveness probe of the Mountpoint container. kubelet then restarts the container,
+// which kills the hung Mountpoint process and fails I/O of workloads with `ENOTCONN` instead of blocking them forever.
+// Once the mount is disconnected, targets bind-mounted from it are remounted if they are tracked with [Track].
+type MountHealthChecker struct {
+	registry *MountRegistry
+	// statusPath returns the path of the health status of the Mountpoint Pod `mpPodName` on the host.
+	statusPath func(mpPodName string) (string, error)
+	statfs     func(path string) error
+	timeout    time.Duration
+	now        func() time.Time
+
+	mu       sync.Mutex
+	health   map[string]*mountHealth
+	remounts map[string]MountRemount
+}
+
+// NewMountHealthChecker creates a new [MountHealthChecker] for the mounts of `pm`.
+func NewMountHealthChecker(pm *PodMounter) *MountHealthChecker {
+	return &MountHealthChecker{
+		registry: pm.registry,
+		statusPath: func(mpPodName string) (string, error) {
+			mpPod, err := pm.podWatcher.Get(mpPodName)
+			if err != nil {
+				return "", fmt.Errorf("failed to get Mountpoint Pod %s: %w", mpPodName, err)
+			}
+			return mppod.PathOnHost(pm.podPath(mpPod), mppod.KnownPathMountHealth), nil
+		},
+		statfs: func(path string) error {
+			var stat unix.Statfs_t
+			return unix.Statfs(path, &stat)
+		},
+		timeout:  MountHealthCheckTimeout,
+		now:      time.Now,
+		health:   make(map[string]*mountHealth),
+		remounts: make(map[string]MountRemount),
+	}
+}
+
+// Track remounts `target` with `remount` once its Mountpoint container is restarted for an unresponsive mount.
+func (c *MountHealthChecker) Track(target string, remount MountRemount) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remounts[target] = remount
+}
+
+// Untrack stops remounting `target`.
+func (c *MountHealthChecker) Untrack(target string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.remounts, target)
+}
+
+// Start checks mounts every `interval` until `stopCh` is closed.
+func (c *MountHealthChecker) Start(stopCh <-chan struct{}, interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			c.Run(ctx)
+		}
+	}
+}
+
+// Run checks the source mount of each Mountpoint Pod once. Unresponsive mounts are reported unhealthy after
+// [MountHealthFailureThreshold] consecutive failed checks, and tracked targets of reported mounts are remounted
+// once the mount is disconnected.
+func (c *MountHealthChecker) Run(ctx context.Context) {
+	sources := c.registry.Sources()
+	outcomes := c.check(sources)
+
+	for source, err := range outcomes {
+		mpPodName := sources[source]
+		switch {
+		case err == nil:
+			c.recordHealthy(source)
+		case errors.Is(err, errMountUnresponsive):
+			if c.recordUnresponsive(source) {
+				c.report(source, mpPodName)
+			}
+		default:
+			// Only mounts reported unhealthy are remounted, disconnected mounts are otherwise handled on the next
+			// mount of their volume
+			if c.isReported(source) {
+				klog.Infof("Source %s of Mountpoint Pod %s is disconnected after its restart: %v", source, mpPodName, err)
+				if c.remount(ctx, c.registry.Targets(source)) {
+					c.forget(source)
+				}
+			}
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for source := range c.health {
+		if _, ok := sources[source]; !ok {
+			delete(c.health, source)
+		}
+	}
+}
+
+// check runs statfs on each of `sources` concurrently, and returns their outcome. Sources not responding within
+// the check timeout, or still not responding since a previous check, fail with [errMountUnresponsive].
+func (c *MountHealthChecker) check(sources map[string]string) map[string]error {
+	type result struct {
+		source string
+		err    error
+	}
+	outcomes := make(map[string]error, len(sources))
+	results := make(chan result, len(sources))
+	pending := 0
+	for source := range sources {
+		if !c.startCheck(source) {
+			outcomes[source] = errMountUnresponsive
+			continue
+		}
+		pending++
+		go func() {
+			err := c.statfs(source)
+			c.finishCheck(source)
+			results <- result{source, err}
+		}()
+	}
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+	for ; pending > 0; pending-- {
+		select {
+		case r := <-results:
+			outcomes[r.source] = r.err
+		case <-timer.C:
+			for source := range sources {
+				if _, ok := outcomes[source]; !ok {
+					outcomes[source] = errMountUnresponsive
+				}
+			}
+			return outcomes
+		}
+	}
+	return outcomes
+}
+
+// startCheck marks a check of `source` as running, and returns false if a previous check is still running.
+func (c *MountHealthChecker) startCheck(source string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	health := c.healthOf(source)
+	if health.checking {
+		return false
+	}
+	health.checking = true
+	return true
+}
+
+func (c *MountHealthChecker) finishCheck(source string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if health, ok := c.health[source]; ok {
+		health.checking = false
+	}
+}
+
+func (c *MountHealthChecker) recordHealthy(source string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	health := c.healthOf(source)
+	health.failures = 0
+	health.reported = false
+}
+
+// recordUnresponsive counts a failed check of `source`, and returns true if it must be reported unhealthy.
+func (c *MountHealthChecker) recordUnresponsive(source string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	health := c.healthOf(source)
+	health.failures++
+	return health.failures >= MountHealthFailureThreshold && !health.reported
+}
+
+func (c *MountHealthChecker) isReported(source string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	health, ok := c.health[source]
+	return ok && health.reported
+}
+
+func (c *MountHealthChecker) forget(source string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if health, ok := c.health[source]; ok && !health.checking {
+		delete(c.health, source)
+	}
+}
+
+// healthOf returns the health of `source`, `c.mu` must be held.
+func (c *MountHealthChecker) healthOf(source string) *mountHealth {
+	health, ok := c.health[source]
+	if !ok {
+		health = &mountHealth{}
+		c.health[source] = health
+	}
+	return health
+}
+
+// report writes the unhealthy status of `source` to its Mountpoint Pod `mpPodName`.
+func (c *MountHealthChecker) report(source, mpPodName string) {
+	klog.Warningf("Source %s of Mountpoint Pod %s did not respond to statfs for %d consecutive checks, reporting it unhealthy to restart its Mountpoint container", source, mpPodName, MountHealthFailureThreshold)
+	path, err := c.statusPath(mpPodName)
+	if err == nil {
+		status := mounthealth.Status{
+			Reason: fmt.Sprintf("%s within %v for %d consecutive checks", errMountUnresponsive, c.timeout, MountHealthFailureThreshold),
+			Since:  c.now(),
+		}
+		err = status.Write(path)
+	}
+	if err != nil {
+		klog.Errorf("Failed to report unresponsive source %s to Mountpoint Pod %s: %v", source, mpPodName, err)
+		return
+	}
+	metrics.UnresponsiveMountsTotal.Inc()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.healthOf(source).reported = true
+}
+
+// remount remounts the tracked ones of `targets`, and returns true if all of them were remounted.
+func (c *MountHealthChecker) remount(ctx context.Context, targets []string) bool {
+	remounted := true
+	for _, target := range targets {
+		c.mu.Lock()
+		remount, ok := c.remounts[target]
+		c.mu.Unlock()
+		if !ok {
+			klog.Warningf("Target %s is not tracked for remounts, its workload must be restarted to use its volume again", target)
+			continue
+		}
+		if err := remount(ctx); err != nil {
+			klog.Errorf("Failed to remount target %s: %v", target, err)
+			metrics.MountHealthRemountsTotal.WithLabelValues("failed").Inc()
+			remounted = false
+			continue
+		}
+		klog.Infof("Remounted target %s", target)
+		metrics.MountHealthRemountsTotal.WithLabelValues("remounted").Inc()
+	}
+	return remounted
+}
@@ -0,0 +1,141 @@
+package mounter
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mounthealth"
+)
+
+// fakeStatfs is a statfs whose outcome is set per path. Hung paths block until released.
+type fakeStatfs struct {
+	mu      sync.Mutex
+	errs    map[string]error
+	hung    map[string]chan struct{}
+	checked []string
+}
+
+func (f *fakeStatfs) statfs(path string) error {
+	f.mu.Lock()
+	f.checked = append(f.checked, path)
+	hung := f.hung[path]
+	f.mu.Unlock()
+	if hung != nil {
+		<-hung
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.errs[path]
+}
+
+func (f *fakeStatfs) checkedPaths() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	checked := slices.Clone(f.checked)
+	f.checked = nil
+	slices.Sort(checked)
+	return slices.Compact(checked)
+}
+
+func TestMountHealthChecker(t *testing.T) {
+	dir := t.TempDir()
+	registry, _, err := LoadMountRegistry(filepath.Join(dir, "mounts.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, record := range []MountRecord{
+		{Target: "/pods/a/mount", Source: "/mnt/mp-1", MountpointPod: "mp-1"},
+		{Target: "/pods/b/mount", Source: "/mnt/mp-1", MountpointPod: "mp-1"},
+		// A staged volume, the staging path is bind-mounted to its targets
+		{Target: "/staging/vol-2", Source: "/mnt/mp-2", MountpointPod: "mp-2"},
+		{Target: "/pods/c/mount", Source: "/staging/vol-2", MountpointPod: "mp-2"},
+	} {
+		if err := registry.Add(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	release := make(chan struct{})
+	fake := &fakeStatfs{errs: map[string]error{}, hung: map[string]chan struct{}{"/mnt/mp-1": release}}
+	checker := &MountHealthChecker{
+		registry: registry,
+		statusPath: func(mpPodName string) (string, error) {
+			return filepath.Join(dir, mpPodName+".health"), nil
+		},
+		statfs:   fake.statfs,
+		timeout:  50 * time.Millisecond,
+		now:      time.Now,
+		health:   make(map[string]*mountHealth),
+		remounts: make(map[string]MountRemount),
+	}
+	var remounted []string
+	checker.Track("/pods/a/mount", func(ctx context.Context) error {
+		remounted = append(remounted, "/pods/a/mount")
+		return nil
+	})
+	statusPath := filepath.Join(dir, "mp-1.health")
+
+	// The hung mount is not reported before the failure threshold
+	checker.Run(context.Background())
+	if got, want := fake.checkedPaths(), []string{"/mnt/mp-1", "/mnt/mp-2"}; !slices.Equal(got, want) {
+		t.Fatalf("Expected sources %v to be checked, got %v", want, got)
+	}
+	if err := mounthealth.Check(statusPath); err != nil {
+		t.Fatalf("Expected mount not to be reported yet, got %v", err)
+	}
+
+	// The statfs of the previous check is still blocked, the mount is reported unhealthy without checking it again
+	checker.Run(context.Background())
+	if got, want := fake.checkedPaths(), []string{"/mnt/mp-2"}; !slices.Equal(got, want) {
+		t.Fatalf("Expected sources %v to be checked, got %v", want, got)
+	}
+	if err := mounthealth.Check(statusPath); !errors.Is(err, mounthealth.ErrUnhealthy) {
+		t.Fatalf("Expected mount to be reported unhealthy, got %v", err)
+	}
+	if err := mounthealth.Check(filepath.Join(dir, "mp-2.health")); err != nil {
+		t.Fatalf("Expected healthy mount not to be reported, got %v", err)
+	}
+
+	// The Mountpoint container is restarted, disconnecting the mount
+	fake.mu.Lock()
+	fake.errs["/mnt/mp-1"] = syscall.ENOTCONN
+	delete(fake.hung, "/mnt/mp-1")
+	fake.mu.Unlock()
+	close(release)
+	waitFor(t, func() bool { return !checker.isChecking("/mnt/mp-1") })
+
+	checker.Run(context.Background())
+	if !slices.Equal(remounted, []string{"/pods/a/mount"}) {
+		t.Fatalf("Expected tracked target to be remounted, got %v", remounted)
+	}
+
+	// Disconnected mounts not reported unhealthy are not remounted
+	checker.Run(context.Background())
+	if len(remounted) != 1 {
+		t.Fatalf("Expected no other remount, got %v", remounted)
+	}
+}
+
+func (c *MountHealthChecker) isChecking(source string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	health, ok := c.health[source]
+	return ok && health.checking
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for condition")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,122 @@
+package mounter
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/metrics"
+)
+
+// EnvMaxConcurrentMounts is the environment variable with the maximum number of mounts the pod mounter makes at the
+// same time on the node, the default of `--max-concurrent-mounts`. Mounts are not limited if zero.
+const EnvMaxConcurrentMounts = "MAX_CONCURRENT_MOUNTS"
+
+// A MountLimiter limits the number of mounts made at the same time on the node, so bursts of workloads scheduled on
+// the node do not overwhelm kubelet and the node plugin with simultaneous Mountpoint Pod startups and socket
+// handshakes. Mounts over the limit wait in a first-in first-out queue, so no mount is starved by later ones.
+type MountLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	active int
+	// waiters are the channels of queued mounts, closed when a slot is handed over to them.
+	waiters list.List
+}
+
+// NewMountLimiter creates a new [MountLimiter] of `limit` concurrent mounts, mounts are not limited if zero.
+func NewMountLimiter(limit int) *MountLimiter {
+	return &MountLimiter{limit: limit}
+}
+
+// SetLimit changes the limit to `limit` concurrent mounts, mounts are not limited if zero. Queued mounts get the
+// slots a higher limit frees, and mounts already made over a lower limit keep their slot until released.
+func (l *MountLimiter) SetLimit(limit int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = limit
+	for front := l.waiters.Front(); front != nil && l.hasFreeSlot(); front = l.waiters.Front() {
+		l.waiters.Remove(front)
+		l.active++
+		close(front.Value.(chan struct{}))
+	}
+	metrics.MountQueueDepth.Set(float64(l.waiters.Len()))
+}
+
+// hasFreeSlot returns whether fewer mounts than the limit are made, l.mu must be held.
+func (l *MountLimiter) hasFreeSlot() bool {
+	return l.limit == 0 || l.active < l.limit
+}
+
+// Acquire waits for a mount slot in arrival order, and returns the function releasing it once the mount is made.
+// It fails with the error of `ctx` if `ctx` is done before a slot is free.
+func (l *MountLimiter) Acquire(ctx context.Context) (func(), error) {
+	start := time.Now()
+	l.mu.Lock()
+	if l.hasFreeSlot() && l.waiters.Len() == 0 {
+		l.active++
+		l.mu.Unlock()
+		metrics.MountQueueWaitSeconds.Observe(0)
+		return l.releaseFunc(), nil
+	}
+	ready := make(chan struct{})
+	waiter := l.waiters.PushBack(ready)
+	metrics.MountQueueDepth.Set(float64(l.waiters.Len()))
+	l.mu.Unlock()
+
+	select {
+	case <-ready:
+		metrics.MountQueueWaitSeconds.Observe(time.Since(start).Seconds())
+		return l.releaseFunc(), nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		select {
+		case <-ready:
+			// The slot was handed over while giving up, pass it on to the next mount
+			l.mu.Unlock()
+			l.release()
+		default:
+			l.waiters.Remove(waiter)
+			metrics.MountQueueDepth.Set(float64(l.waiters.Len()))
+			l.mu.Unlock()
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// releaseFunc returns a function releasing a slot once, however many times it is called.
+func (l *MountLimiter) releaseFunc() func() {
+	var once sync.Once
+	return func() { once.Do(l.release) }
+}
+
+// release hands a slot over to the first queued mount, or frees it if no mount is queued or the limit was lowered
+// below the number of mounts made.
+func (l *MountLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if front := l.waiters.Front(); front != nil && (l.limit == 0 || l.active <= l.limit) {
+		l.waiters.Remove(front)
+		metrics.MountQueueDepth.Set(float64(l.waiters.Len()))
+		close(front.Value.(chan struct{}))
+		return
+	}
+	l.active--
+}
+
+// SetMountLimiter limits the number of mounts made at the same time with `limiter`. Time spent waiting for a slot
+// is bounded by the timeout of [MountPhaseQueue], and does not count towards the timeouts of later phases.
+func (pm *PodMounter) SetMountLimiter(limiter *MountLimiter) {
+	pm.limiter = limiter
+}
+
+// acquireMountSlot waits for a slot of the mount limiter, if set, and returns the function releasing it.
+func (pm *PodMounter) acquireMountSlot(ctx context.Context) (func(), error) {
+	if pm.limiter == nil {
+		return func() {}, nil
+	}
+	queueCtx, cancel := pm.withPhaseTimeout(ctx, MountPhaseQueue)
+	defer cancel()
+	release, err := pm.limiter.Acquire(queueCtx)
+	return release, phaseError(queueCtx, err)
+}
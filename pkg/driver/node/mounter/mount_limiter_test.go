@@ -0,0 +1,168 @@
+package mounter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+// acquireAsync acquires a slot of `limiter` in the background, and returns the channel receiving its release function.
+func acquireAsync(t *testing.T, ctx context.Context, limiter *MountLimiter) <-chan func() {
+	t.Helper()
+	acquired := make(chan func(), 1)
+	go func() {
+		release, err := limiter.Acquire(ctx)
+		if err == nil {
+			acquired <- release
+		}
+	}()
+	return acquired
+}
+
+// waitForWaiters waits until `n` mounts are queued on `limiter`.
+func waitForWaiters(t *testing.T, limiter *MountLimiter, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		limiter.mu.Lock()
+		queued := limiter.waiters.Len()
+		limiter.mu.Unlock()
+		if queued == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("Expected %d queued mounts", n)
+}
+
+func expectAcquired(t *testing.T, acquired <-chan func()) func() {
+	t.Helper()
+	select {
+	case release := <-acquired:
+		return release
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected a mount slot to be acquired")
+		return nil
+	}
+}
+
+func expectQueued(t *testing.T, acquired <-chan func()) {
+	t.Helper()
+	select {
+	case <-acquired:
+		t.Fatal("Expected the mount to wait for a slot")
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestMountLimiter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Mounts over the limit are queued in arrival order", func(t *testing.T) {
+		limiter := NewMountLimiter(2)
+		first, err := limiter.Acquire(ctx)
+		assert.NoError(t, err)
+		second, err := limiter.Acquire(ctx)
+		assert.NoError(t, err)
+
+		third := acquireAsync(t, ctx, limiter)
+		waitForWaiters(t, limiter, 1)
+		fourth := acquireAsync(t, ctx, limiter)
+		waitForWaiters(t, limiter, 2)
+		expectQueued(t, third)
+
+		first()
+		releaseThird := expectAcquired(t, third)
+		expectQueued(t, fourth)
+
+		second()
+		releaseFourth := expectAcquired(t, fourth)
+		releaseThird()
+		releaseFourth()
+		assert.Equals(t, 0, limiter.active)
+	})
+
+	t.Run("Releasing twice frees a single slot", func(t *testing.T) {
+		limiter := NewMountLimiter(1)
+		release, err := limiter.Acquire(ctx)
+		assert.NoError(t, err)
+		release()
+		release()
+		assert.Equals(t, 0, limiter.active)
+	})
+
+	t.Run("Mounts giving up leave the queue", func(t *testing.T) {
+		limiter := NewMountLimiter(1)
+		release, err := limiter.Acquire(ctx)
+		assert.NoError(t, err)
+
+		waitCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		if _, err := limiter.Acquire(waitCtx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Expected the mount to give up waiting, got %v", err)
+		}
+		waitForWaiters(t, limiter, 0)
+
+		next := acquireAsync(t, ctx, limiter)
+		waitForWaiters(t, limiter, 1)
+		release()
+		expectAcquired(t, next)()
+		assert.Equals(t, 0, limiter.active)
+	})
+
+	t.Run("Raising the limit hands slots over to queued mounts", func(t *testing.T) {
+		limiter := NewMountLimiter(1)
+		release, err := limiter.Acquire(ctx)
+		assert.NoError(t, err)
+		second := acquireAsync(t, ctx, limiter)
+		waitForWaiters(t, limiter, 1)
+		third := acquireAsync(t, ctx, limiter)
+		waitForWaiters(t, limiter, 2)
+
+		limiter.SetLimit(0)
+		releaseSecond := expectAcquired(t, second)
+		releaseThird := expectAcquired(t, third)
+		release()
+		releaseSecond()
+		releaseThird()
+		assert.Equals(t, 0, limiter.active)
+	})
+
+	t.Run("Lowering the limit frees slots of released mounts", func(t *testing.T) {
+		limiter := NewMountLimiter(2)
+		first, err := limiter.Acquire(ctx)
+		assert.NoError(t, err)
+		second, err := limiter.Acquire(ctx)
+		assert.NoError(t, err)
+
+		limiter.SetLimit(1)
+		third := acquireAsync(t, ctx, limiter)
+		waitForWaiters(t, limiter, 1)
+		first()
+		expectQueued(t, third)
+		second()
+		expectAcquired(t, third)()
+		assert.Equals(t, 0, limiter.active)
+	})
+}
+
+func TestAcquireMountSlot(t *testing.T) {
+	pm := &PodMounter{timeouts: MountTimeouts{MountPhaseQueue: 10 * time.Millisecond}}
+	release, err := pm.acquireMountSlot(context.Background())
+	assert.NoError(t, err)
+	release()
+
+	pm.SetMountLimiter(NewMountLimiter(1))
+	release, err = pm.acquireMountSlot(context.Background())
+	assert.NoError(t, err)
+	defer release()
+
+	_, err = pm.acquireMountSlot(context.Background())
+	var timeoutErr *PhaseTimeoutError
+	if !errors.As(err, &timeoutErr) || timeoutErr.Phase != MountPhaseQueue {
+		t.Fatalf("Expected a queue phase timeout, got %v", err)
+	}
+}
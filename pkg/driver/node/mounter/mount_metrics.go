@@ -0,0 +1,137 @@
+package mounter
+
+import (
+	"errors"
+	"io/fs"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/klog/v2"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mountmetrics"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+)
+
+// EnvMountpointMetricsEnabled is the environment variable enabling the relay of Mountpoint metrics, exported per
+// volume by the node plugin.
+const EnvMountpointMetricsEnabled = "MOUNTPOINT_METRICS_ENABLED"
+
+// mountpointMetricPrefix prefixes the names of Mountpoint metrics exported by the node plugin.
+const mountpointMetricPrefix = "scality_csi_mountpoint_"
+
+// Labels added to each Mountpoint metric exported by the node plugin.
+const (
+	metricLabelVolume        = "volume"
+	metricLabelMountpointPod = "mountpoint_pod"
+)
+
+// A MountMetricsCollector exports the metrics Mountpoint Pods of the node relay from Mountpoint, see [mountmetrics].
+//
+// Mountpoint metrics are exported as `scality_csi_mountpoint_<name>`, e.g. `s3.requests[op=GetObject]` as
+// `scality_csi_mountpoint_s3_requests_total{op="GetObject"}`, with the PersistentVolume and Mountpoint Pod they come
+// from. Metrics are read from `mount.metrics` of each Mountpoint Pod of the mount registry on every scrape.
+type MountMetricsCollector struct {
+	registry *MountRegistry
+	// snapshotPath returns the path of the metrics snapshot of the Mountpoint Pod `mpPodName` on the host, and the name
+	// of the PersistentVolume it serves.
+	snapshotPath func(mpPodName string) (path string, volume string, err error)
+}
+
+// NewMountMetricsCollector creates a new [MountMetricsCollector] for the mounts of `pm`.
+func NewMountMetricsCollector(pm *PodMounter) *MountMetricsCollector {
+	return &MountMetricsCollector{
+		registry: pm.registry,
+		snapshotPath: func(mpPodName string) (string, string, error) {
+			mpPod, err := pm.podWatcher.Get(mpPodName)
+			if err != nil {
+				return "", "", err
+			}
+			return mppod.PathOnHost(pm.podPath(mpPod), mppod.KnownPathMountMetrics), mpPod.Labels[mppod.LabelVolumeName], nil
+		},
+	}
+}
+
+// SetMountMetricsCollector makes Mountpoint log its metrics with `--log-metrics` on new mounts, for Mountpoint Pods to
+// relay them to `collector`.
+func (pm *PodMounter) SetMountMetricsCollector(collector *MountMetricsCollector) {
+	pm.mountMetrics = collector
+}
+
+// Describe implements [prometheus.Collector]. Metrics of Mountpoint are not known in advance, the collector is
+// unchecked.
+func (c *MountMetricsCollector) Describe(chan<- *prometheus.Desc) {}
+
+// Collect implements [prometheus.Collector].
+func (c *MountMetricsCollector) Collect(ch chan<- prometheus.Metric) {
+	// Label names of each exported metric, series with other label names are dropped as Prometheus rejects them
+	labelNames := make(map[string][]string)
+
+	mpPodNames := slices.Sorted(maps.Values(c.registry.Sources()))
+	for _, mpPodName := range mpPodNames {
+		path, volume, err := c.snapshotPath(mpPodName)
+		if err != nil {
+			continue
+		}
+		snapshot, err := mountmetrics.Read(path)
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				klog.V(4).Infof("Failed to read Mountpoint metrics of Mountpoint Pod %s: %v", mpPodName, err)
+			}
+			continue
+		}
+
+		constLabels := prometheus.Labels{metricLabelVolume: volume, metricLabelMountpointPod: mpPodName}
+		for _, sample := range snapshot.Counters {
+			c.collect(ch, labelNames, sample, prometheus.CounterValue, constLabels)
+		}
+		for _, sample := range snapshot.Gauges {
+			c.collect(ch, labelNames, sample, prometheus.GaugeValue, constLabels)
+		}
+	}
+}
+
+func (c *MountMetricsCollector) collect(ch chan<- prometheus.Metric, labelNames map[string][]string, sample mountmetrics.Sample, valueType prometheus.ValueType, constLabels prometheus.Labels) {
+	name := mountpointMetricPrefix + sanitizeMetricName(sample.Name)
+	if valueType == prometheus.CounterValue {
+		name += "_total"
+	}
+
+	labels := make(prometheus.Labels, len(sample.Labels))
+	for label, value := range sample.Labels {
+		label = sanitizeMetricName(label)
+		if _, ok := constLabels[label]; ok {
+			label = "mountpoint_" + label
+		}
+		labels[label] = value
+	}
+	names := slices.Sorted(maps.Keys(labels))
+	if known, ok := labelNames[name]; !ok {
+		labelNames[name] = names
+	} else if !slices.Equal(known, names) {
+		return
+	}
+
+	values := make([]string, len(names))
+	for i, label := range names {
+		values[i] = labels[label]
+	}
+	desc := prometheus.NewDesc(name, "Metric "+sample.Name+" of Mountpoint, relayed by Mountpoint Pods.", names, constLabels)
+	metric, err := prometheus.NewConstMetric(desc, valueType, sample.Value, values...)
+	if err != nil {
+		klog.V(4).Infof("Failed to export Mountpoint metric %s: %v", sample.Name, err)
+		return
+	}
+	ch <- metric
+}
+
+// sanitizeMetricName replaces characters not allowed in Prometheus metric and label names with underscores.
+func sanitizeMetricName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+}
@@ -0,0 +1,108 @@
+package mounter
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mountmetrics"
+)
+
+func TestMountMetricsCollector(t *testing.T) {
+	dir := t.TempDir()
+	registry, _, err := LoadMountRegistry(filepath.Join(dir, "mounts.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, record := range []MountRecord{
+		{Target: "/pods/a/mount", Source: "/mnt/mp-1", MountpointPod: "mp-1"},
+		{Target: "/pods/b/mount", Source: "/mnt/mp-2", MountpointPod: "mp-2"},
+		// Not relaying metrics, e.g. as it runs an older version
+		{Target: "/pods/c/mount", Source: "/mnt/mp-3", MountpointPod: "mp-3"},
+		// Deleted
+		{Target: "/pods/d/mount", Source: "/mnt/mp-4", MountpointPod: "mp-4"},
+	} {
+		if err := registry.Add(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	snapshots := map[string]mountmetrics.Snapshot{
+		"mp-1": {
+			Counters: []mountmetrics.Sample{
+				{Name: "s3.requests", Labels: map[string]string{"op": "GetObject"}, Value: 20},
+				// Conflicts with the label added by the collector
+				{Name: "fuse.ops", Labels: map[string]string{"volume": "x"}, Value: 1},
+			},
+			Gauges: []mountmetrics.Sample{{Name: "s3.client.num_requests_being_processed", Value: 3}},
+		},
+		"mp-2": {
+			Counters: []mountmetrics.Sample{
+				{Name: "s3.requests", Labels: map[string]string{"op": "PutObject"}, Value: 5},
+				// Other label names than the same metric of mp-1, it is dropped
+				{Name: "s3.requests", Labels: map[string]string{"op": "PutObject", "type": "Default"}, Value: 1},
+			},
+		},
+	}
+	for mpPodName, snapshot := range snapshots {
+		if err := snapshot.Write(filepath.Join(dir, mpPodName+".metrics")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	collector := &MountMetricsCollector{
+		registry: registry,
+		snapshotPath: func(mpPodName string) (string, string, error) {
+			if mpPodName == "mp-4" {
+				return "", "", errors.New("not found")
+			}
+			return filepath.Join(dir, mpPodName+".metrics"), "pv-" + mpPodName, nil
+		},
+	}
+	promRegistry := prometheus.NewRegistry()
+	promRegistry.MustRegister(collector)
+	families, err := promRegistry.Gather()
+	if err != nil {
+		t.Fatalf("Failed to gather metrics: %v", err)
+	}
+
+	got := make(map[string][]string)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			labels := ""
+			for _, label := range metric.GetLabel() {
+				labels += label.GetName() + "=" + label.GetValue() + ","
+			}
+			value := metric.GetCounter().GetValue()
+			if family.GetType().String() == "GAUGE" {
+				value = metric.GetGauge().GetValue()
+			}
+			got[family.GetName()] = append(got[family.GetName()], fmt.Sprintf("%s%g", labels, value))
+		}
+	}
+
+	want := map[string][]string{
+		"scality_csi_mountpoint_s3_requests_total": {
+			"mountpoint_pod=mp-1,op=GetObject,volume=pv-mp-1,20",
+			"mountpoint_pod=mp-2,op=PutObject,volume=pv-mp-2,5",
+		},
+		"scality_csi_mountpoint_fuse_ops_total": {
+			"mountpoint_pod=mp-1,mountpoint_volume=x,volume=pv-mp-1,1",
+		},
+		"scality_csi_mountpoint_s3_client_num_requests_being_processed": {
+			"mountpoint_pod=mp-1,volume=pv-mp-1,3",
+		},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected metrics %v, got %v", want, got)
+	}
+	for name, series := range want {
+		if !slices.Equal(got[name], series) {
+			t.Fatalf("Expected %s to be %v, got %v", name, series, got[name])
+		}
+	}
+}
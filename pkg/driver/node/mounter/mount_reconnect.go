@@ -0,0 +1,183 @@
+package mounter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/metrics"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
+	mpmounter "github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint/mounter"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mountoptions"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+)
+
+const (
+	// ReconnectCheckInterval is how often Mountpoint Pods are checked for requests of a new FUSE file descriptor.
+	ReconnectCheckInterval = 2 * time.Second
+	// reconnectTimeout bounds the reconnection of a Mountpoint Pod, from sending it a new FUSE file descriptor to
+	// Mountpoint serving the new mount.
+	reconnectTimeout = time.Minute
+)
+
+// A MountReconnector mounts the source of Mountpoint Pods whose Mountpoint process crashed again, when they request
+// it with a [mountoptions.ReconnectRequest], and bind-mounts the new mount to the targets of the old one.
+//
+// The FUSE mount of a crashed Mountpoint process is disconnected and cannot be served again. The source is mounted
+// with a new FUSE file descriptor, with the same options as the disconnected mount, which is passed to the restarted
+// Mountpoint on the reconnect socket of its Mountpoint Pod. Workloads see the new mount with
+// `mountPropagation: HostToContainer`, others keep failing with `ENOTCONN` until they are restarted.
+type MountReconnector struct {
+	pm *PodMounter
+}
+
+// NewMountReconnector creates a new [MountReconnector] for the mounts of `pm`.
+func NewMountReconnector(pm *PodMounter) *MountReconnector {
+	return &MountReconnector{pm: pm}
+}
+
+// Start checks for reconnect requests every `interval` until `stopCh` is closed.
+func (r *MountReconnector) Start(stopCh <-chan struct{}, interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			r.Run(ctx)
+		}
+	}
+}
+
+// Run reconnects the Mountpoint Pods of recorded mounts that requested a new FUSE file descriptor.
+func (r *MountReconnector) Run(ctx context.Context) {
+	for source, mpPodName := range r.pm.registry.Sources() {
+		mpPod, err := r.pm.podWatcher.Get(mpPodName)
+		if err != nil {
+			continue
+		}
+		podPath := r.pm.podPath(mpPod)
+		requestPath := mppod.PathOnHost(podPath, mppod.KnownPathMountReconnect)
+		request, err := mountoptions.ReadReconnectRequest(requestPath)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		// Claim the request, so it is handled once
+		_ = os.Remove(requestPath)
+		if err != nil {
+			klog.Errorf("Failed to read reconnect request of Mountpoint Pod %s: %v", mpPodName, err)
+			continue
+		}
+
+		klog.Warningf("Mountpoint of Mountpoint Pod %s crashed with exit code %d, reconnecting source %s (restart %d)", mpPodName, request.ExitCode, source, request.Restart)
+		if err := r.reconnect(ctx, source, mpPodName, podPath); err != nil {
+			klog.Errorf("Failed to reconnect source %s of Mountpoint Pod %s: %v", source, mpPodName, err)
+			metrics.MountReconnectsTotal.WithLabelValues("failed").Inc()
+			continue
+		}
+		klog.Infof("Reconnected source %s of Mountpoint Pod %s", source, mpPodName)
+		metrics.MountReconnectsTotal.WithLabelValues("reconnected").Inc()
+	}
+}
+
+// reconnect mounts `source` again with a new FUSE file descriptor passed to the Mountpoint Pod `mpPodName`, and
+// bind-mounts it to its targets again.
+func (r *MountReconnector) reconnect(ctx context.Context, source, mpPodName, podPath string) error {
+	ctx, cancel := context.WithTimeout(ctx, reconnectTimeout)
+	defer cancel()
+
+	unlockMountpointPod := lockMountpointPod(mpPodName)
+	defer unlockMountpointPod()
+
+	args, err := r.pm.fuseMountArgs(source)
+	if err != nil {
+		return err
+	}
+	if err := r.pm.unmountTarget(source); err != nil {
+		if err := mpmounter.UnmountLazy(source); err != nil {
+			return fmt.Errorf("failed to unmount disconnected source %s: %w", source, err)
+		}
+	}
+
+	fuseDeviceFD, err := r.pm.mountSyscallWithDefault(source, args)
+	if err != nil {
+		return fmt.Errorf("failed to mount source %s: %w", source, err)
+	}
+	defer mpmounter.CloseFUSEDevice(fuseDeviceFD)
+
+	podMountErrorPath := mppod.PathOnHost(podPath, mppod.KnownPathMountError)
+	_ = os.Remove(podMountErrorPath)
+
+	err = mountoptions.Send(ctx, mppod.PathOnHost(podPath, mppod.KnownPathMountReconnectSock), mountoptions.Options{Fd: fuseDeviceFD})
+	if err == nil {
+		err = r.pm.waitForMount(ctx, source, mpPodName, podMountErrorPath)
+	}
+	if err != nil {
+		if unmountErr := r.pm.unmountTarget(source); unmountErr != nil {
+			klog.V(4).ErrorS(unmountErr, "failed to unmount source %s\n", source)
+		}
+		return err
+	}
+
+	return r.rebind(ctx, source)
+}
+
+// rebind bind-mounts `source` to its targets again, and the targets bind-mounted from them in turn, e.g. the targets
+// of a staging path.
+func (r *MountReconnector) rebind(ctx context.Context, source string) error {
+	var errs []error
+	for _, target := range r.pm.registry.Targets(source) {
+		// Workloads may still hold open files of the disconnected mount
+		if err := r.pm.unmountTarget(target); err != nil {
+			if err := mpmounter.UnmountLazy(target); err != nil {
+				errs = append(errs, fmt.Errorf("failed to unmount disconnected target %s: %w", target, err))
+				continue
+			}
+		}
+		if err := r.pm.bindMountWithTimeout(ctx, source, target); err != nil {
+			errs = append(errs, fmt.Errorf("failed to bind mount %s to target %s: %w", source, target, err))
+			continue
+		}
+		if err := r.rebind(ctx, target); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// fuseMountArgs returns the arguments changing the options of the FUSE mount at `source`, as read from the mount
+// table, to mount it again the same way.
+func (pm *PodMounter) fuseMountArgs(source string) (mountpoint.Args, error) {
+	mountPoints, err := pm.mount.List()
+	if err != nil {
+		return mountpoint.Args{}, fmt.Errorf("failed to list mounts: %w", err)
+	}
+	for _, mp := range mountPoints {
+		if mp.Path != source {
+			continue
+		}
+		var args []string
+		if slices.Contains(mp.Opts, "ro") {
+			args = append(args, mountpoint.ArgReadOnly)
+		}
+		if slices.Contains(mp.Opts, "allow_other") {
+			args = append(args, mountpoint.ArgAllowOther)
+		}
+		return mountpoint.ParseArgs(args), nil
+	}
+	return mountpoint.Args{}, fmt.Errorf("source %s is not mounted", source)
+}
@@ -0,0 +1,171 @@
+package mounter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/google/renameio"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mountoptions"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util"
+)
+
+// mountRegistryFilePerm is the permission of the mount registry file, it is only read by the node plugin.
+const mountRegistryFilePerm = fs.FileMode(0o600)
+
+// MountRegistryPath returns the path of the mount registry of the node plugin. It is next to source mount points
+// under the kubelet plugin directory, so it survives restarts of the node plugin like the mounts it records.
+func MountRegistryPath(kubeletPath string) string {
+	return filepath.Join(kubeletPath, "plugins", util.DriverName(), "mounts.json")
+}
+
+// A MountRecord is a target mounted by the node plugin, with the source it is bind-mounted from and the Mountpoint
+// Pod serving the source.
+type MountRecord struct {
+	Target        string `json:"target"`
+	VolumeID      string `json:"volumeID"`
+	Source        string `json:"source"`
+	MountpointPod string `json:"mountpointPod"`
+	// Handshake of the Mountpoint Pod, replied when its source was mounted. Nil for sources mounted by versions of
+	// the node plugin not recording it.
+	Handshake *mountoptions.Handshake `json:"handshake,omitempty"`
+}
+
+// A MountRegistry persists the targets mounted by the node plugin, so they are managed the same way after a restart
+// of the node plugin as before. It is safe for concurrent use, and every change is written to disk atomically.
+type MountRegistry struct {
+	mu      sync.Mutex
+	path    string
+	records map[string]MountRecord
+}
+
+// LoadMountRegistry loads the mount registry at `path`, and returns whether it existed. A missing registry is empty.
+func LoadMountRegistry(path string) (*MountRegistry, bool, error) {
+	r := &MountRegistry{path: path, records: make(map[string]MountRecord)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return r, false, nil
+	}
+	if err != nil {
+		return r, false, fmt.Errorf("failed to read mount registry %q: %w", path, err)
+	}
+
+	var records []MountRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return r, true, fmt.Errorf("failed to parse mount registry %q: %w", path, err)
+	}
+	for _, record := range records {
+		r.records[record.Target] = record
+	}
+	return r, true, nil
+}
+
+// Get returns the record of `target`, if any.
+func (r *MountRegistry) Get(target string) (MountRecord, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	record, ok := r.records[target]
+	return record, ok
+}
+
+// List returns all records, sorted by target.
+func (r *MountRegistry) List() []MountRecord {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.list()
+}
+
+// Sources returns the source mounts of Mountpoint Pods bind-mounted to recorded targets, with the name of their
+// Mountpoint Pod. Staging paths are not sources, the source they are bind-mounted from is.
+func (r *MountRegistry) Sources() map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sources := make(map[string]string)
+	for _, record := range r.records {
+		if _, isTarget := r.records[record.Source]; record.Source == "" || record.MountpointPod == "" || isTarget {
+			continue
+		}
+		sources[record.Source] = record.MountpointPod
+	}
+	return sources
+}
+
+// Handshake returns the recorded handshake of the Mountpoint Pod `mpPodName`, if any.
+func (r *MountRegistry) Handshake(mpPodName string) (mountoptions.Handshake, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, record := range r.records {
+		if record.MountpointPod == mpPodName && record.Handshake != nil {
+			return *record.Handshake, true
+		}
+	}
+	return mountoptions.Handshake{}, false
+}
+
+// Targets returns the targets bind-mounted from `source`, sorted.
+func (r *MountRegistry) Targets(source string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var targets []string
+	for _, record := range r.list() {
+		if record.Source == source {
+			targets = append(targets, record.Target)
+		}
+	}
+	return targets
+}
+
+// Add records `record`, replacing the previous record of its target.
+func (r *MountRegistry) Add(record MountRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if previous, ok := r.records[record.Target]; ok && previous == record {
+		return nil
+	}
+	r.records[record.Target] = record
+	return r.save()
+}
+
+// Remove removes the record of `target`, if any.
+func (r *MountRegistry) Remove(target string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.records[target]; !ok {
+		return nil
+	}
+	delete(r.records, target)
+	return r.save()
+}
+
+func (r *MountRegistry) list() []MountRecord {
+	records := make([]MountRecord, 0, len(r.records))
+	for _, record := range r.records {
+		records = append(records, record)
+	}
+	slices.SortFunc(records, func(a, b MountRecord) int {
+		return strings.Compare(a.Target, b.Target)
+	})
+	return records
+}
+
+func (r *MountRegistry) save() error {
+	data, err := json.Marshal(r.list())
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), targetDirPerm); err != nil {
+		return fmt.Errorf("failed to create directory of mount registry %q: %w", r.path, err)
+	}
+	if err := renameio.WriteFile(r.path, data, mountRegistryFilePerm); err != nil {
+		return fmt.Errorf("failed to write mount registry %q: %w", r.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package mounter
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mountoptions"
+)
+
+func TestMountRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plugins", "mounts.json")
+
+	registry, existed, err := LoadMountRegistry(path)
+	if err != nil || existed {
+		t.Fatalf("LoadMountRegistry(%q) = %v, %v; expected a new empty registry", path, existed, err)
+	}
+
+	first := MountRecord{Target: "/kubelet/pods/a/mount", VolumeID: "vol-1", Source: "/kubelet/plugins/mnt/mp-1", MountpointPod: "mp-1"}
+	second := MountRecord{Target: "/kubelet/pods/b/mount", VolumeID: "vol-1", Source: "/kubelet/plugins/mnt/mp-1", MountpointPod: "mp-1"}
+	for _, record := range []MountRecord{second, first, first} {
+		if err := registry.Add(record); err != nil {
+			t.Fatalf("Add(%+v) returned error: %v", record, err)
+		}
+	}
+	if got, ok := registry.Get(first.Target); !ok || got != first {
+		t.Fatalf("Get(%q) = %+v, %v; expected %+v", first.Target, got, ok, first)
+	}
+
+	// The registry survives restarts
+	reloaded, existed, err := LoadMountRegistry(path)
+	if err != nil || !existed {
+		t.Fatalf("LoadMountRegistry(%q) = %v, %v; expected the saved registry", path, existed, err)
+	}
+	if got, want := reloaded.List(), []MountRecord{first, second}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("List() = %+v; expected %+v", got, want)
+	}
+
+	if err := reloaded.Remove(first.Target); err != nil {
+		t.Fatalf("Remove(%q) returned error: %v", first.Target, err)
+	}
+	if err := reloaded.Remove("/unknown"); err != nil {
+		t.Fatalf("Remove of unknown target returned error: %v", err)
+	}
+	reloaded, _, err = LoadMountRegistry(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := reloaded.List(), []MountRecord{second}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("List() = %+v; expected %+v", got, want)
+	}
+}
+
+func TestLoadCorruptedMountRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mounts.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	registry, existed, err := LoadMountRegistry(path)
+	if err == nil || !existed {
+		t.Fatalf("LoadMountRegistry(%q) = %v, %v; expected a parse error", path, existed, err)
+	}
+	if got := registry.List(); len(got) != 0 {
+		t.Fatalf("List() = %+v; expected an empty registry", got)
+	}
+}
+
+func TestMountRegistryHandshake(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mounts.json")
+	registry, _, err := LoadMountRegistry(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handshake := mountoptions.LocalHandshake()
+	for _, record := range []MountRecord{
+		// Recorded by a previous version of the node plugin
+		{Target: "/kubelet/pods/a/mount", Source: "/kubelet/plugins/mnt/mp-1", MountpointPod: "mp-1"},
+		{Target: "/kubelet/pods/b/mount", Source: "/kubelet/plugins/mnt/mp-1", MountpointPod: "mp-1", Handshake: &handshake},
+		{Target: "/kubelet/pods/c/mount", Source: "/kubelet/plugins/mnt/mp-2", MountpointPod: "mp-2"},
+	} {
+		if err := registry.Add(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reloaded, _, err := LoadMountRegistry(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := reloaded.Handshake("mp-1"); !ok || got != handshake {
+		t.Fatalf("Handshake(%q) = %+v, %v; expected %+v", "mp-1", got, ok, handshake)
+	}
+	if got, ok := reloaded.Handshake("mp-2"); ok {
+		t.Fatalf("Handshake(%q) = %+v; expected no handshake", "mp-2", got)
+	}
+}
@@ -0,0 +1,113 @@
+package mounter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/metrics"
+)
+
+// A MountPhase is a step of mounts of the pod mounter with its own timeout.
+type MountPhase string
+
+// Mount phases, in order.
+const (
+	// MountPhaseAttachment is waiting for the controller to assign a Mountpoint Pod to the workload.
+	MountPhaseAttachment MountPhase = "attachment"
+	// MountPhaseQueue is waiting for a slot of the mount limiter of the node, see [MountLimiter].
+	MountPhaseQueue MountPhase = "queue"
+	// MountPhasePodSchedule is waiting for the Mountpoint Pod to be scheduled on the node.
+	MountPhasePodSchedule MountPhase = "podSchedule"
+	// MountPhaseImagePull is waiting for the scheduled Mountpoint Pod to pull its image and start running.
+	MountPhaseImagePull MountPhase = "imagePull"
+	// MountPhaseSocketReady is waiting for Mountpoint to accept mount options on its socket.
+	MountPhaseSocketReady MountPhase = "socketReady"
+	// MountPhaseFUSEReady is waiting for Mountpoint to serve the FUSE mount at the source.
+	MountPhaseFUSEReady MountPhase = "fuseReady"
+	// MountPhaseBindMount is bind-mounting the source at the target.
+	MountPhaseBindMount MountPhase = "bindMount"
+)
+
+// mountPhaseEnvs are the environment variables configuring the timeout of each mount phase.
+var mountPhaseEnvs = map[MountPhase]string{
+	MountPhaseAttachment:  "MOUNT_TIMEOUT_ATTACHMENT",
+	MountPhaseQueue:       "MOUNT_TIMEOUT_QUEUE",
+	MountPhasePodSchedule: "MOUNT_TIMEOUT_POD_SCHEDULE",
+	MountPhaseImagePull:   "MOUNT_TIMEOUT_IMAGE_PULL",
+	MountPhaseSocketReady: "MOUNT_TIMEOUT_SOCKET_READY",
+	MountPhaseFUSEReady:   "MOUNT_TIMEOUT_FUSE_READY",
+	MountPhaseBindMount:   "MOUNT_TIMEOUT_BIND_MOUNT",
+}
+
+// MountTimeouts are the timeouts of each [MountPhase]. Phases are also bounded by the deadline of the
+// NodePublishVolume call, kubelet retries calls that timed out.
+type MountTimeouts map[MountPhase]time.Duration
+
+// DefaultMountTimeouts returns the default timeout of each [MountPhase].
+func DefaultMountTimeouts() MountTimeouts {
+	return MountTimeouts{
+		MountPhaseAttachment:  2 * time.Minute,
+		MountPhaseQueue:       2 * time.Minute,
+		MountPhasePodSchedule: 2 * time.Minute,
+		MountPhaseImagePull:   2 * time.Minute,
+		MountPhaseSocketReady: time.Minute,
+		MountPhaseFUSEReady:   time.Minute,
+		MountPhaseBindMount:   30 * time.Second,
+	}
+}
+
+// MountTimeoutsFromEnv returns the timeouts of mount phases configured by their environment variables, defaulting to
+// [DefaultMountTimeouts].
+func MountTimeoutsFromEnv() (MountTimeouts, error) {
+	timeouts := DefaultMountTimeouts()
+	for phase, env := range mountPhaseEnvs {
+		value := os.Getenv(env)
+		if value == "" {
+			continue
+		}
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return timeouts, fmt.Errorf("invalid %s %q, must be a positive duration", env, value)
+		}
+		timeouts[phase] = timeout
+	}
+	return timeouts, nil
+}
+
+// A PhaseTimeoutError is returned when a mount phase did not complete within its timeout.
+type PhaseTimeoutError struct {
+	Phase   MountPhase
+	Timeout time.Duration
+}
+
+func (e *PhaseTimeoutError) Error() string {
+	return fmt.Sprintf("mount phase %s timed out after %v (%s)", e.Phase, e.Timeout, mountPhaseEnvs[e.Phase])
+}
+
+// withPhaseTimeout returns a context cancelled after the timeout of `phase`, with a [*PhaseTimeoutError] cause.
+func (pm *PodMounter) withPhaseTimeout(ctx context.Context, phase MountPhase) (context.Context, context.CancelFunc) {
+	timeout := pm.timeouts[phase]
+	if timeout <= 0 {
+		timeout = DefaultMountTimeouts()[phase]
+	}
+	return context.WithTimeoutCause(ctx, timeout, &PhaseTimeoutError{Phase: phase, Timeout: timeout})
+}
+
+// phaseError returns `err` of a phase run with `phaseCtx` of [PodMounter.withPhaseTimeout], prefixed by the
+// [*PhaseTimeoutError] if the phase timed out.
+func phaseError(phaseCtx context.Context, err error) error {
+	var timeoutErr *PhaseTimeoutError
+	if err == nil || !errors.As(context.Cause(phaseCtx), &timeoutErr) {
+		return err
+	}
+	metrics.MountPhaseTimeoutsTotal.WithLabelValues(string(timeoutErr.Phase)).Inc()
+	return fmt.Errorf("%w: %w", timeoutErr, err)
+}
+
+// SetMountTimeouts sets the timeouts of mount phases, phases without a timeout use their default.
+func (pm *PodMounter) SetMountTimeouts(timeouts MountTimeouts) {
+	pm.timeouts = timeouts
+}
@@ -0,0 +1,52 @@
+package mounter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestMountTimeoutsFromEnv(t *testing.T) {
+	timeouts, err := MountTimeoutsFromEnv()
+	assert.NoError(t, err)
+	assert.Equals(t, DefaultMountTimeouts(), timeouts)
+
+	t.Setenv("MOUNT_TIMEOUT_IMAGE_PULL", "10m")
+	timeouts, err = MountTimeoutsFromEnv()
+	assert.NoError(t, err)
+	assert.Equals(t, 10*time.Minute, timeouts[MountPhaseImagePull])
+	assert.Equals(t, time.Minute, timeouts[MountPhaseFUSEReady])
+
+	t.Setenv("MOUNT_TIMEOUT_FUSE_READY", "0s")
+	if _, err := MountTimeoutsFromEnv(); err == nil {
+		t.Fatalf("Expected a zero timeout to be rejected")
+	}
+}
+
+func TestPhaseError(t *testing.T) {
+	pm := &PodMounter{timeouts: MountTimeouts{MountPhaseSocketReady: time.Millisecond}}
+
+	ctx, cancel := pm.withPhaseTimeout(context.Background(), MountPhaseSocketReady)
+	defer cancel()
+	<-ctx.Done()
+	err := phaseError(ctx, ctx.Err())
+	var timeoutErr *PhaseTimeoutError
+	if !errors.As(err, &timeoutErr) || timeoutErr.Phase != MountPhaseSocketReady {
+		t.Fatalf("Expected a socketReady phase timeout, got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the phase error to wrap the original error, got %v", err)
+	}
+
+	// Errors of phases cancelled by their parent context are not timeouts of the phase
+	parent, cancelParent := context.WithCancel(context.Background())
+	ctx, cancel = pm.withPhaseTimeout(parent, MountPhaseBindMount)
+	defer cancel()
+	cancelParent()
+	if err := phaseError(ctx, ctx.Err()); errors.As(err, &timeoutErr) && timeoutErr.Phase == MountPhaseBindMount {
+		t.Fatalf("Expected no phase timeout for a cancelled parent, got %v", err)
+	}
+}
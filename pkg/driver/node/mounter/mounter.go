@@ -6,10 +6,10 @@ import (
 	"os"
 	"path/filepath"
 
-	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/system"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util"
 )
 
 type ServiceRunner interface {
@@ -40,5 +40,5 @@ func MountS3Path() string {
 // SourceMountDir returns the internal S3 CSI Driver directory for source mount points.
 // This is where Mountpoint Pods mount S3 buckets before they are bind-mounted to targets.
 func SourceMountDir(kubeletPath string) string {
-	return filepath.Join(kubeletPath, "plugins", constants.DriverName, "mnt")
+	return filepath.Join(kubeletPath, "plugins", util.DriverName(), "mnt")
 }
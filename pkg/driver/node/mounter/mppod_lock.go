@@ -1,6 +1,7 @@
 package mounter
 
 import (
+	"context"
 	"sync"
 
 	"k8s.io/klog/v2"
@@ -9,7 +10,8 @@ import (
 // MPPodLock represents a reference-counted mutex lock for Mountpoint Pod.
 // It ensures synchronized access to pod-specific resources.
 type MPPodLock struct {
-	mutex    sync.Mutex
+	// held has a value while the lock is held, it is a channel so waits for the lock can be cancelled.
+	held     chan struct{}
 	refCount int
 }
 
@@ -35,12 +37,25 @@ var (
 //	unlock := lockMountpointPod(mpPodName)
 //	defer unlock()
 func lockMountpointPod(mpPodName string) func() {
+	unlock, _ := lockMountpointPodContext(context.Background(), mpPodName)
+	return unlock
+}
+
+// lockMountpointPodContext acquires the lock of `mpPodName` like [lockMountpointPod], unless `ctx` is done first, so
+// mounts kubelet gave up on do not queue behind a slow mount of the same Mountpoint Pod. The returned function must
+// be called to release the lock if there is no error.
+func lockMountpointPodContext(ctx context.Context, mpPodName string) (func(), error) {
 	mpPodLock := getMPPodLock(mpPodName)
-	mpPodLock.mutex.Lock()
-	return func() {
-		mpPodLock.mutex.Unlock()
+	select {
+	case mpPodLock.held <- struct{}{}:
+	case <-ctx.Done():
 		releaseMPPodLock(mpPodName)
+		return nil, context.Cause(ctx)
 	}
+	return func() {
+		<-mpPodLock.held
+		releaseMPPodLock(mpPodName)
+	}, nil
 }
 
 // getMPPodLock retrieves or creates a lock for the specified pod name.
@@ -52,7 +67,7 @@ func getMPPodLock(mpPodName string) *MPPodLock {
 
 	lock, exists := mpPodLocks[mpPodName]
 	if !exists {
-		lock = &MPPodLock{refCount: 1}
+		lock = &MPPodLock{held: make(chan struct{}, 1), refCount: 1}
 		mpPodLocks[mpPodName] = lock
 	} else {
 		lock.refCount++
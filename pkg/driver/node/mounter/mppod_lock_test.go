@@ -1,6 +1,8 @@
 package mounter
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"testing"
@@ -121,3 +123,23 @@ func TestLockMountpointPod_DifferentPodsParallel(t *testing.T) {
 		t.Errorf("Expected parallel execution (~%v) but took %v, suggesting serialization", holdTime, elapsed)
 	}
 }
+
+func TestLockMountpointPodContext(t *testing.T) {
+	mpPodLocks = make(map[string]*MPPodLock)
+
+	unlock := lockMountpointPod("pod-1")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := lockMountpointPodContext(ctx, "pod-1")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the wait for the lock to time out, got %v", err)
+	}
+	assert.Equals(t, 1, mpPodLocks["pod-1"].refCount)
+
+	unlock()
+	unlock, err = lockMountpointPodContext(context.Background(), "pod-1")
+	assert.NoError(t, err)
+	unlock()
+	assert.Equals(t, 0, len(mpPodLocks))
+}
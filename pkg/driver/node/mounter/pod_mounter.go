@@ -7,21 +7,27 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/klog/v2"
 	"k8s.io/mount-utils"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
-	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/mountreport"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/pressure"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/targetpath"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
 	mpmounter "github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint/mounter"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mounterror"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mountoptions"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod/watcher"
@@ -51,7 +57,8 @@ type mountSyscall func(target string, args mountpoint.Args) (fd int, err error)
 // Parameters:
 //   - source: The source directory with mounted S3 bucket
 //   - target: The target directory requested by the container
-type bindMountSyscall func(source, target string) error
+//   - options: Mount options of the bind mount besides `bind`, e.g. `ro`
+type bindMountSyscall func(source, target string, options []string) error
 
 // A PodMounter is a [Mounter] that mounts Mountpoint on pre-created Kubernetes Pod running in the same node.
 // It implements a source/bind mount architecture where:
@@ -69,6 +76,26 @@ type PodMounter struct {
 	credProvider      *credentialprovider.Provider
 	k8sClient         client.Reader // Changed to Reader to support both client.Client and cache.Cache
 	nodeName          string
+	// busyUnmounter unmounts targets on [PodMounter.Unmount] if set, to apply a policy to busy targets
+	busyUnmounter *BusyUnmounter
+	// forcedCleanup cleans up wedged targets on [PodMounter.Unmount] if set
+	forcedCleanup *forcedCleanup
+	// registry records mounted targets, to manage them the same way across restarts of the node plugin
+	registry *MountRegistry
+	// telemetryTags are appended to the user-agent of Mountpoint, labels are read from workload Pods with `workloadPods`
+	telemetryTags TelemetryTags
+	workloadPods  typedcorev1.PodsGetter
+	// timeouts are the timeouts of mount phases
+	timeouts MountTimeouts
+	// generations records mount generations of volumes, reported in the status of attachments with `attachmentStatus`
+	generations      *MountGenerations
+	attachmentStatus client.StatusClient
+	// pressure lowers the concurrency of new mounts while the node is under pressure if set
+	pressure *pressure.Monitor
+	// limiter limits the number of mounts made at the same time if set
+	limiter *MountLimiter
+	// mountMetrics exports metrics relayed by Mountpoint Pods if set, Mountpoint then logs them with `--log-metrics`
+	mountMetrics *MountMetricsCollector
 }
 
 // NewPodMounter creates a new [PodMounter] with given Kubernetes client.
@@ -90,7 +117,11 @@ func NewPodMounter(podWatcher *watcher.Watcher, credProvider *credentialprovider
 		// NODE_NAME is required only when using CRD mode (k8sClient is provided)
 		return nil, fmt.Errorf("NODE_NAME environment variable must be set when using CRD mode")
 	}
-	return &PodMounter{
+	registry, existed, err := LoadMountRegistry(MountRegistryPath(kubeletPath))
+	if err != nil {
+		klog.Errorf("Failed to load mount registry, rebuilding it from existing mounts: %v", err)
+	}
+	pm := &PodMounter{
 		podWatcher:        podWatcher,
 		credProvider:      credProvider,
 		mount:             mount,
@@ -100,22 +131,119 @@ func NewPodMounter(podWatcher *watcher.Watcher, credProvider *credentialprovider
 		kubernetesVersion: kubernetesVersion,
 		k8sClient:         k8sClient,
 		nodeName:          nodeName,
-	}, nil
+		registry:          registry,
+		timeouts:          DefaultMountTimeouts(),
+	}
+	if !existed || err != nil {
+		pm.migrateMountRegistry()
+	}
+	pm.generations, err = LoadMountGenerations(MountGenerationsPath(kubeletPath))
+	if err != nil {
+		klog.Errorf("Failed to load mount generations, generations of volumes restart from 1: %v", err)
+	}
+	return pm, nil
+}
+
+// migrateMountRegistry records the existing mounts of the node plugin in its mount registry, for mounts made by
+// versions of the node plugin without a mount registry. Targets are found from the bind mounts of mounted sources.
+func (pm *PodMounter) migrateMountRegistry() {
+	sourceMountDir := SourceMountDir(pm.kubeletPath)
+	entries, err := os.ReadDir(sourceMountDir)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			klog.Warningf("Failed to read source mount directory %q to migrate mount registry: %v", sourceMountDir, err)
+		}
+		return
+	}
+
+	migrated := 0
+	for _, entry := range entries {
+		source := filepath.Join(sourceMountDir, entry.Name())
+		if mounted, err := mpmounter.CheckMountpoint(pm.mount, source); err != nil || !mounted {
+			continue
+		}
+		targets, err := pm.mount.GetMountRefs(source)
+		if err != nil {
+			klog.Warningf("Failed to find targets of source %q to migrate mount registry: %v", source, err)
+			continue
+		}
+
+		volumeID := ""
+		if pm.podWatcher != nil {
+			if mpPod, err := pm.podWatcher.Get(entry.Name()); err == nil {
+				volumeID = mpPod.Labels[mppod.LabelVolumeId]
+			}
+		}
+		for _, target := range targets {
+			if strings.HasPrefix(target, sourceMountDir+string(filepath.Separator)) {
+				continue
+			}
+			err := pm.registry.Add(MountRecord{Target: target, VolumeID: volumeID, Source: source, MountpointPod: entry.Name()})
+			if err != nil {
+				klog.Warningf("Failed to migrate target %q to mount registry: %v", target, err)
+				continue
+			}
+			migrated++
+		}
+	}
+	klog.Infof("Migrated %d existing mount(s) to mount registry %s", migrated, pm.registry.path)
+}
+
+// MountReport returns the mounts of each Mountpoint Pod on the node: whether Mountpoint is mounted at its source,
+// and the number of targets recorded in the mount registry bind-mounted from it. Staging paths are not counted as
+// targets, the targets bind-mounted from them are.
+func (pm *PodMounter) MountReport() (map[string]mountreport.MountpointPodMounts, error) {
+	mountPoints, err := pm.mount.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mounts: %w", err)
+	}
+	mounted := make(map[string]bool, len(mountPoints))
+	for _, mp := range mountPoints {
+		mounted[mp.Path] = true
+	}
+
+	mounts := make(map[string]mountreport.MountpointPodMounts)
+	sourceMountDir := SourceMountDir(pm.kubeletPath)
+	entries, err := os.ReadDir(sourceMountDir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read source mount directory %q: %w", sourceMountDir, err)
+	}
+	for _, entry := range entries {
+		if mounted[filepath.Join(sourceMountDir, entry.Name())] {
+			mounts[entry.Name()] = mountreport.MountpointPodMounts{SourceMounted: true}
+		}
+	}
+
+	records := pm.registry.List()
+	sources := make(map[string]bool, len(records))
+	for _, record := range records {
+		sources[record.Source] = true
+	}
+	for _, record := range records {
+		if record.MountpointPod == "" || sources[record.Target] || !mounted[record.Target] {
+			continue
+		}
+		podMounts := mounts[record.MountpointPod]
+		podMounts.Targets++
+		mounts[record.MountpointPod] = podMounts
+	}
+	return mounts, nil
 }
 
 // waitForMountpointPodAttachment waits for a MountpointS3PodAttachment CRD to be created by the controller.
-// It continuously polls until the CRD is found or the context times out.
+// It continuously polls until the CRD is found or the context times out, and returns the attachment with the name
+// of the Mountpoint Pod assigned to the workload.
 //
 // The CRD-based coordination enables:
 // - Controller to determine optimal Mountpoint Pod placement
 // - Sharing of Mountpoint Pods across multiple workload pods
 // - Better resource utilization and scheduling decisions
-func (pm *PodMounter) waitForMountpointPodAttachment(ctx context.Context, podID, volumeName, volumeID string, credentialCtx credentialprovider.ProvideContext, fsGroup string) (string, error) {
+func (pm *PodMounter) waitForMountpointPodAttachment(ctx context.Context, podID, volumeName, volumeID string, credentialCtx credentialprovider.ProvideContext, fsGroup string) (*crdv2.MountpointS3PodAttachment, string, error) {
 	if pm.k8sClient == nil {
-		return "", fmt.Errorf("k8sClient is required for pod mounter operations")
+		return nil, "", fmt.Errorf("k8sClient is required for pod mounter operations")
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	ctx, cancel := pm.withPhaseTimeout(ctx, MountPhaseAttachment)
 	defer cancel()
 
 	// Build field filters for searching MountpointS3PodAttachments
@@ -131,13 +259,25 @@ func (pm *PodMounter) waitForMountpointPodAttachment(ctx context.Context, podID,
 	if fsGroup != "" {
 		fieldFilters[crdv2.FieldWorkloadFSGroup] = fsGroup
 	}
+	// The credential identity is not filtered on: the controller records it from the service account of the workload
+	// or the Secret reference of the volume, unknown here. The attachment of the workload UID already belongs to the
+	// MountpointS3PodAttachment of its identity.
 
 	klog.V(4).Infof("Waiting for MountpointS3PodAttachment for podID=%s, volumeName=%s, volumeID=%s", podID, volumeName, volumeID)
 
+	s3pa, mpPodName, _, err := pm.pollMountpointPodAttachment(ctx, fieldFilters, func(attachment crdv2.WorkloadAttachment) bool {
+		return attachment.WorkloadPodUID == podID
+	})
+	return s3pa, mpPodName, phaseError(ctx, err)
+}
+
+// pollMountpointPodAttachment polls MountpointS3PodAttachments matching `fieldFilters` until one of them has a
+// workload attachment matching `matches`, and returns it with its Mountpoint Pod.
+func (pm *PodMounter) pollMountpointPodAttachment(ctx context.Context, fieldFilters client.MatchingFields, matches func(crdv2.WorkloadAttachment) bool) (*crdv2.MountpointS3PodAttachment, string, crdv2.WorkloadAttachment, error) {
 	for {
 		select {
 		case <-ctx.Done():
-			return "", fmt.Errorf("timed out waiting for MountpointS3PodAttachment: %w", ctx.Err())
+			return nil, "", crdv2.WorkloadAttachment{}, fmt.Errorf("timed out waiting for MountpointS3PodAttachment: %w", ctx.Err())
 		default:
 		}
 
@@ -145,15 +285,16 @@ func (pm *PodMounter) waitForMountpointPodAttachment(ctx context.Context, podID,
 		err := pm.k8sClient.List(ctx, s3paList, fieldFilters)
 		if err != nil {
 			klog.Errorf("Failed to list MountpointS3PodAttachments: %v", err)
-			return "", err
+			return nil, "", crdv2.WorkloadAttachment{}, err
 		}
 
-		for _, s3pa := range s3paList.Items {
+		for i := range s3paList.Items {
+			s3pa := &s3paList.Items[i]
 			for mpPodName, attachments := range s3pa.Spec.MountpointS3PodAttachments {
 				for _, attachment := range attachments {
-					if attachment.WorkloadPodUID == podID {
+					if matches(attachment) {
 						klog.V(4).Infof("Found MountpointS3PodAttachment %s with Mountpoint Pod %s", s3pa.Name, mpPodName)
-						return mpPodName, nil
+						return s3pa, mpPodName, attachment, nil
 					}
 				}
 			}
@@ -161,7 +302,7 @@ func (pm *PodMounter) waitForMountpointPodAttachment(ctx context.Context, podID,
 
 		select {
 		case <-ctx.Done():
-			return "", fmt.Errorf("timed out waiting for MountpointS3PodAttachment: %w", ctx.Err())
+			return nil, "", crdv2.WorkloadAttachment{}, fmt.Errorf("timed out waiting for MountpointS3PodAttachment: %w", ctx.Err())
 		case <-time.After(2 * time.Second):
 			// Poll every 2 seconds
 		}
@@ -199,7 +340,7 @@ func (pm *PodMounter) Mount(ctx context.Context, bucketName string, target strin
 
 			// Use the host plugin directory for systemd credential path
 			// This matches where systemd mounter would have stored credentials
-			hostPluginDir := filepath.Join(pm.kubeletPath, "plugins", constants.DriverName)
+			hostPluginDir := filepath.Join(pm.kubeletPath, "plugins", util.DriverName())
 			credentialCtx.SetWriteAndEnvPath(hostPluginDir, hostPluginDir)
 
 			// Only refresh credentials, don't attempt to remount
@@ -241,13 +382,28 @@ func (pm *PodMounter) Mount(ctx context.Context, bucketName string, target strin
 	// Step 1: Determine which Mountpoint Pod to use via MountpointS3PodAttachment CRD
 	// Controller assigns optimal pod based on scheduling and resource constraints
 	klog.V(4).Infof("Looking for pod with podID=%s, volumeName=%s, volumeID=%s", podID, volumeName, volumeID)
-	mpPodName, err := pm.waitForMountpointPodAttachment(ctx, podID, volumeName, volumeID, credentialCtx, fsGroup)
+	s3pa, mpPodName, err := pm.waitForMountpointPodAttachment(ctx, podID, volumeName, volumeID, credentialCtx, fsGroup)
 	if err != nil {
 		klog.Errorf("failed to wait for MountpointS3PodAttachment for %q: %v. %s", target, err, pm.helpMessageForGettingControllerLogs())
 		return fmt.Errorf("failed to wait for MountpointS3PodAttachment for %q: %w. %s", target, err, pm.helpMessageForGettingControllerLogs())
 	}
 	klog.V(4).Infof("Using Mountpoint Pod name: %s", mpPodName)
 
+	return pm.mountFromAttachment(ctx, bucketName, target, s3pa, mpPodName, credentialCtx, args)
+}
+
+// mountFromAttachment mounts `bucketName` at `target` with Mountpoint Pod `mpPodName` assigned by `s3pa`: the bucket
+// is mounted at the source path of the Mountpoint Pod if it is not already, then `target` is bind-mounted from it.
+func (pm *PodMounter) mountFromAttachment(ctx context.Context, bucketName string, target string, s3pa *crdv2.MountpointS3PodAttachment, mpPodName string, credentialCtx credentialprovider.ProvideContext, args mountpoint.Args) error {
+	volumeID := credentialCtx.VolumeID
+
+	release, err := pm.acquireMountSlot(ctx)
+	if err != nil {
+		klog.Errorf("failed to wait for a mount slot for %q: %v", target, err)
+		return fmt.Errorf("failed to wait for a mount slot for %q: %w", target, err)
+	}
+	defer release()
+
 	// Step 2: Setup source and target mount directories
 	source := filepath.Join(SourceMountDir(pm.kubeletPath), mpPodName)
 
@@ -272,7 +428,10 @@ func (pm *PodMounter) Mount(ctx context.Context, bucketName string, target strin
 		return fmt.Errorf("failed to wait for Mountpoint Pod to be ready for %q: %w", target, err)
 	}
 
-	unlockMountpointPod := lockMountpointPod(mpPodName)
+	unlockMountpointPod, err := lockMountpointPodContext(ctx, mpPodName)
+	if err != nil {
+		return fmt.Errorf("failed to wait for other mounts of Mountpoint Pod %s for %q: %w", mpPodName, target, err)
+	}
 	defer unlockMountpointPod()
 
 	// Check if source is already mounted — must be inside the lock so concurrent
@@ -289,6 +448,8 @@ func (pm *PodMounter) Mount(ctx context.Context, bucketName string, target strin
 	}
 
 	credentialCtx.SetWriteAndEnvPath(podCredentialsPath, mppod.PathInsideMountpointPod(mppod.KnownPathCredentials))
+	credentialCtx.WriteFile = pm.credentialFileWriter(ctx, mpPodName, podPath)
+	credentialCtx.MountKind = credentialprovider.MountKindPod
 
 	// Always provide credentials to ensure they're up-to-date
 	credEnv, authenticationSource, err := pm.credProvider.Provide(ctx, credentialCtx)
@@ -299,34 +460,58 @@ func (pm *PodMounter) Mount(ctx context.Context, bucketName string, target strin
 
 	// Step 3: Mount S3 bucket to source directory (if not already mounted)
 	// This creates the shared mount point that multiple containers can use
+	var handshake *mountoptions.Handshake
 	if !isSourceMounted {
 		env := envprovider.Default()
 		env.Merge(credEnv)
 
+		builder := mountpoint.BuilderOf(args)
+		// The controller adds `read-only` to mount options of workloads with a ReadOnlyMany claim, their Mountpoint
+		// Pods are always mounted read-only
+		if attachmentArgs := mountpoint.ParseMountOptions(s3pa.Spec.MountOptions); attachmentArgs.Has(mountpoint.ArgReadOnly) {
+			builder.WithReadOnly()
+		}
+
 		// Move `--aws-max-attempts` to env if provided
-		if maxAttempts, ok := args.Remove(mountpoint.ArgAWSMaxAttempts); ok {
+		if maxAttempts, ok := builder.Take(mountpoint.ArgAWSMaxAttempts); ok {
 			env.Set(envprovider.EnvMaxAttempts, maxAttempts)
 		}
 
-		enforceCSIDriverMountArgPolicy(&args)
-
-		// Remove the read-only argument from the list as mount-s3 does not support it when using FUSE
-		if args.Has(mountpoint.ArgReadOnly) {
-			args.Remove(mountpoint.ArgReadOnly)
+		builder.Apply(enforceCSIDriverMountArgPolicy)
+		configureCacheArgs(pod, builder)
+		configureLogArgs(pod, builder, env)
+		if pm.mountMetrics != nil {
+			builder.WithLogMetrics()
+		}
+		if pm.pressure != nil {
+			builder.Apply(pm.pressure.Adapt)
 		}
 
-		args.Set(mountpoint.ArgUserAgentPrefix, UserAgent(authenticationSource, pm.kubernetesVersion))
+		builder.WithUserAgentPrefix(UserAgent(authenticationSource, pm.kubernetesVersion, pm.renderTelemetryTags(ctx, credentialCtx)))
+		args, err = builder.Build()
+		if err != nil {
+			return fmt.Errorf("invalid Mountpoint arguments for source %s: %w", source, err)
+		}
 		podMountSockPath := mppod.PathOnHost(podPath, mppod.KnownPathMountSock)
 		podMountErrorPath := mppod.PathOnHost(podPath, mppod.KnownPathMountError)
 
+		// Do not mount the source once kubelet gave up on the call, its retry would find a source nobody serves
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("not mounting source %s: %w", source, context.Cause(ctx))
+		}
+
 		klog.V(4).Infof("Mounting S3 bucket to source %s for %s", source, pod.Name)
 
+		// The FUSE mount is made read-only by the kernel with `--read-only`
 		fuseDeviceFD, err := pm.mountSyscallWithDefault(source, args)
 		if err != nil {
 			klog.Errorf("failed to mount source %s: %v", source, err)
 			return fmt.Errorf("failed to mount source %s: %w", source, err)
 		}
 
+		// Remove the read-only argument from the list as mount-s3 does not support it when using FUSE
+		args.Remove(mountpoint.ArgReadOnly)
+
 		// This will set to false in the success condition. This is set to `true` by default to
 		// ensure we don't leave `source` mounted if Mountpoint is not started to serve requests for it.
 		unmountSource := true
@@ -350,18 +535,25 @@ func (pm *PodMounter) Mount(ctx context.Context, bucketName string, target strin
 
 		klog.V(4).Infof("Sending mount options to Mountpoint Pod %s on %s", pod.Name, podMountSockPath)
 
-		err = mountoptions.Send(ctx, podMountSockPath, mountoptions.Options{
+		socketCtx, cancelSocket := pm.withPhaseTimeout(ctx, MountPhaseSocketReady)
+		peer, err := mountoptions.Exchange(socketCtx, podMountSockPath, mountoptions.Options{
 			Fd:         fuseDeviceFD,
 			BucketName: bucketName,
 			Args:       args.SortedList(),
 			Env:        env.List(),
 		})
+		err = phaseError(socketCtx, err)
+		cancelSocket()
 		if err != nil {
 			klog.Errorf("failed to send mount option to Mountpoint Pod %s for source %s: %v\n%s", pod.Name, source, err, pm.helpMessageForGettingMountpointLogs(pod))
 			return fmt.Errorf("failed to send mount options to Mountpoint Pod %s for source %s: %w\n%s", pod.Name, source, err, pm.helpMessageForGettingMountpointLogs(pod))
 		}
+		klog.V(4).Infof("Mountpoint Pod %s speaks mount options protocol %s", pod.Name, peer)
+		handshake = &peer
 
-		err = pm.waitForMount(ctx, source, pod.Name, podMountErrorPath)
+		fuseCtx, cancelFUSE := pm.withPhaseTimeout(ctx, MountPhaseFUSEReady)
+		err = phaseError(fuseCtx, pm.waitForMount(fuseCtx, source, pod.Name, podMountErrorPath))
+		cancelFUSE()
 		if err != nil {
 			klog.Errorf("failed to wait for Mountpoint Pod %s to be ready for source %s: %v\n%s", pod.Name, source, err, pm.helpMessageForGettingMountpointLogs(pod))
 			return fmt.Errorf("failed to wait for Mountpoint Pod %s to be ready for source %s: %w\n%s", pod.Name, source, err, pm.helpMessageForGettingMountpointLogs(pod))
@@ -370,14 +562,21 @@ func (pm *PodMounter) Mount(ctx context.Context, bucketName string, target strin
 		// Mountpoint successfully started at source, so don't unmount it
 		unmountSource = false
 		klog.V(4).Infof("Successfully mounted S3 bucket to source %s", source)
+		pm.observeMountGeneration(ctx, s3pa, volumeID, mountFingerprint(bucketName, args, credentialCtx, authenticationSource))
 	} else {
 		klog.V(4).Infof("Source %s is already mounted, reusing existing mount", source)
+		if recorded, ok := pm.registry.Handshake(mpPodName); ok {
+			handshake = &recorded
+		}
 	}
 
+	record := MountRecord{Target: target, VolumeID: volumeID, Source: source, MountpointPod: mpPodName, Handshake: handshake}
+
 	// Step 4: Create bind mount from source to target
 	// Skip if target already has a bind mount (idempotency)
 	if isTargetMounted {
 		klog.V(4).Infof("Target path %q is already bind-mounted", target)
+		pm.recordMount(record)
 		return nil
 	}
 
@@ -385,16 +584,25 @@ func (pm *PodMounter) Mount(ctx context.Context, bucketName string, target strin
 	// This allows the container to access S3 at its requested path while sharing
 	// the underlying S3 mount with other containers
 	klog.V(4).Infof("Creating bind mount from source %s to target %s", source, target)
-	err = pm.bindMountSyscallWithDefault(source, target)
+	err = pm.bindMountWithTimeout(ctx, source, target)
 	if err != nil {
 		klog.Errorf("failed to bind mount %q to target %q: %v", source, target, err)
 		return fmt.Errorf("failed to bind mount %q to target %q: %w", source, target, err)
 	}
 
 	klog.V(4).Infof("Successfully created bind mount to target %s from source %s", target, source)
+	pm.recordMount(record)
 	return nil
 }
 
+// recordMount records mounted target of `record` in the mount registry. A failure to record a mount does not fail
+// it, the target is then managed without its record.
+func (pm *PodMounter) recordMount(record MountRecord) {
+	if err := pm.registry.Add(record); err != nil {
+		klog.Warningf("Failed to record mount of target %q: %v", record.Target, err)
+	}
+}
+
 // Unmount unmounts only the bind mount point at `target`.
 //
 // Important: This only removes the bind mount, NOT the source mount.
@@ -410,19 +618,79 @@ func (pm *PodMounter) Mount(ctx context.Context, bucketName string, target strin
 // - During node shutdown or driver restart
 func (pm *PodMounter) Unmount(ctx context.Context, target string, credentialCtx credentialprovider.CleanupContext) error {
 	// Only unmount the bind mount at target, preserve the shared source mount
-	err := pm.unmountTarget(target)
+	var err error
+	if pm.busyUnmounter != nil {
+		err = pm.busyUnmounter.Unmount(ctx, target)
+	} else {
+		err = pm.unmountTarget(target)
+	}
+	if err != nil && pm.forcedCleanup != nil && mpmounter.IsWedged(err) {
+		err = pm.forceUnmount(ctx, target, credentialCtx, err)
+	}
 	if err != nil {
 		klog.Errorf("failed to unmount target %q: %v", target, err)
 		return fmt.Errorf("failed to unmount target %q: %w", target, err)
 	}
 
 	klog.V(4).Infof("Target %q successfully unmounted (bind mount removed)", target)
+	if err := pm.registry.Remove(target); err != nil {
+		klog.Warningf("Failed to remove record of unmounted target %q: %v", target, err)
+	}
+
+	if volumeName, err := pm.volumeNameFromTargetPath(target); err == nil &&
+		credentialCtx.VolumeID == volumecontext.EphemeralVolumeID(credentialCtx.PodID, volumeName) {
+		pm.cleanupEphemeralVolumeCredentials(ctx, volumeName, credentialCtx)
+	}
 	return nil
 }
 
+// cleanupEphemeralVolumeCredentials removes credentials written for the inline ephemeral volume `volumeName`.
+// Mountpoint Pods of inline ephemeral volumes are never shared, as their volume ID is unique to their workload,
+// so their credentials are not needed once the workload unpublished the volume.
+func (pm *PodMounter) cleanupEphemeralVolumeCredentials(ctx context.Context, volumeName string, credentialCtx credentialprovider.CleanupContext) {
+	if pm.k8sClient == nil {
+		return
+	}
+
+	s3paList := &crdv2.MountpointS3PodAttachmentList{}
+	err := pm.k8sClient.List(ctx, s3paList, client.MatchingFields{
+		crdv2.FieldNodeName:             pm.nodeName,
+		crdv2.FieldPersistentVolumeName: volumeName,
+		crdv2.FieldVolumeID:             credentialCtx.VolumeID,
+	})
+	if err != nil {
+		klog.Warningf("Failed to list MountpointS3PodAttachments to clean up credentials of volume %s: %v", credentialCtx.VolumeID, err)
+		return
+	}
+
+	for _, s3pa := range s3paList.Items {
+		for mpPodName := range s3pa.Spec.MountpointS3PodAttachments {
+			mpPod, err := pm.podWatcher.Get(mpPodName)
+			if err != nil {
+				// Credentials are removed with the Mountpoint Pod otherwise
+				klog.V(4).Infof("Mountpoint Pod %s of volume %s not found, skipping credentials cleanup: %v", mpPodName, credentialCtx.VolumeID, err)
+				continue
+			}
+
+			credentialCtx.WritePath = pm.credentialsDir(pm.podPath(mpPod))
+			credentialCtx.MountKind = credentialprovider.MountKindPod
+			if err := pm.credProvider.Cleanup(credentialCtx); err != nil {
+				klog.Warningf("Failed to clean up credentials of volume %s in Mountpoint Pod %s: %v", credentialCtx.VolumeID, mpPodName, err)
+				continue
+			}
+			klog.V(4).Infof("Cleaned up credentials of inline ephemeral volume %s in Mountpoint Pod %s", credentialCtx.VolumeID, mpPodName)
+		}
+	}
+}
+
 // IsMountPoint returns whether given `target` is a mount point.
-// It checks for both mountpoint-s3 mounts and bind mounts.
+// It checks for both mountpoint-s3 mounts and bind mounts. Targets recorded in the mount registry are looked up in
+// the mount table without accessing them, so they are found even if their Mountpoint Pod is gone.
 func (pm *PodMounter) IsMountPoint(target string) (bool, error) {
+	if _, ok := pm.registry.Get(target); ok {
+		return pm.isRecordedMountPoint(target)
+	}
+
 	// First check if it's a mountpoint-s3 mount
 	isMpMount, err := mpmounter.CheckMountpoint(pm.mount, target)
 	if err != nil {
@@ -445,12 +713,48 @@ func (pm *PodMounter) IsMountPoint(target string) (bool, error) {
 	return !notMnt, nil
 }
 
+// isRecordedMountPoint returns whether `target` recorded in the mount registry is in the mount table, and removes
+// its record otherwise.
+func (pm *PodMounter) isRecordedMountPoint(target string) (bool, error) {
+	mountPoints, err := pm.mount.List()
+	if err != nil {
+		return false, fmt.Errorf("failed to list mounts: %w", err)
+	}
+	resolvedTarget, err := filepath.EvalSymlinks(target)
+	if err != nil {
+		resolvedTarget = target
+	}
+	for _, mp := range mountPoints {
+		if mp.Path == target || mp.Path == resolvedTarget {
+			return true, nil
+		}
+	}
+
+	klog.V(4).Infof("Recorded target %q is not mounted anymore, removing its record", target)
+	if err := pm.registry.Remove(target); err != nil {
+		klog.Warningf("Failed to remove record of target %q: %v", target, err)
+	}
+	if _, err := os.Stat(target); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
 // waitForMountpointPod waints until Mountpoint Pod for given `podID` and `volumeName` is in `Running` state.
 // It returns found Mountpoint Pod and it's base directory.
 func (pm *PodMounter) waitForMountpointPod(ctx context.Context, podName string) (*corev1.Pod, string, error) {
-	pod, err := pm.podWatcher.Wait(ctx, podName)
+	scheduleCtx, cancelSchedule := pm.withPhaseTimeout(ctx, MountPhasePodSchedule)
+	defer cancelSchedule()
+	if _, err := pm.podWatcher.WaitScheduled(scheduleCtx, podName); err != nil {
+		return nil, "", phaseError(scheduleCtx, err)
+	}
+
+	// The image pull phase starts once the Pod is scheduled, slow pulls do not shorten the time allowed to schedule it
+	startCtx, cancelStart := pm.withPhaseTimeout(ctx, MountPhaseImagePull)
+	defer cancelStart()
+	pod, err := pm.podWatcher.Wait(startCtx, podName)
 	if err != nil {
-		return nil, "", err
+		return nil, "", phaseError(startCtx, err)
 	}
 
 	klog.V(4).Infof("Mountpoint Pod %s/%s is running with id %s", pod.Namespace, podName, pod.UID)
@@ -477,7 +781,7 @@ func (pm *PodMounter) waitForMount(parentCtx context.Context, target, podName, p
 				return false, nil
 			}
 
-			mountResultCh <- fmt.Errorf("mountpoint Pod %s failed: %s", podName, res)
+			mountResultCh <- fmt.Errorf("mountpoint Pod %s failed: %w", podName, mounterror.Parse(res))
 			return true, nil
 		})
 	}()
@@ -545,6 +849,12 @@ func (pm *PodMounter) ensureCredentialsDirExists(podPath string) (string, error)
 		klog.V(4).Infof("failed to create credentials directory for pod %s: %v", podPath, err)
 		return "", err
 	}
+	// Regardless of the umask, and of directories created by previous versions, Mountpoint Pods need group write
+	// access to rewrite refreshed credentials
+	if err := os.Chmod(credentialsBasepath, credentialprovider.CredentialDirPerm); err != nil {
+		klog.V(4).Infof("failed to set permissions of credentials directory for pod %s: %v", podPath, err)
+		return "", err
+	}
 
 	return credentialsBasepath, nil
 }
@@ -569,13 +879,70 @@ func (pm *PodMounter) mountSyscallWithDefault(target string, args mountpoint.Arg
 }
 
 // bindMountSyscallWithDefault delegates to `bindMountSyscall` if set, or fallbacks to platform-native bind mount.
-func (pm *PodMounter) bindMountSyscallWithDefault(source, target string) error {
+// bindMountWithTimeout bind-mounts `source` at `target`, failing if it does not complete within the timeout of
+// [MountPhaseBindMount], e.g. if the mount syscall hangs on an unresponsive FUSE mount. The bind mount keeps running
+// in the background after the timeout, kubelet retries the mount.
+func (pm *PodMounter) bindMountWithTimeout(ctx context.Context, source, target string, options ...string) error {
+	ctx, cancel := pm.withPhaseTimeout(ctx, MountPhaseBindMount)
+	defer cancel()
+
+	result := make(chan error, 1)
+	go func() {
+		result <- pm.bindMountSyscallWithDefault(source, target, options)
+	}()
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return phaseError(ctx, ctx.Err())
+	}
+}
+
+func (pm *PodMounter) bindMountSyscallWithDefault(source, target string, options []string) error {
 	if pm.bindMountSyscall != nil {
-		return pm.bindMountSyscall(source, target)
+		return pm.bindMountSyscall(source, target, options)
 	}
 
-	// Default bind mount using mount-utils
-	return pm.mount.Mount(source, target, "", []string{"bind"})
+	// Default bind mount using mount-utils, which remounts the bind mount with `options` if any
+	return pm.mount.Mount(source, target, "", append([]string{"bind"}, options...))
+}
+
+// SetBusyUnmountConfig applies `config` to targets still used by processes on [PodMounter.Unmount].
+// Busy targets fail to unmount otherwise.
+func (pm *PodMounter) SetBusyUnmountConfig(config BusyUnmountConfig) {
+	pm.busyUnmounter = NewBusyUnmounter(config, pm.unmountTarget)
+}
+
+// SetPressureMonitor lowers the concurrency of mounts made while `monitor` reports the node under pressure.
+func (pm *PodMounter) SetPressureMonitor(monitor *pressure.Monitor) {
+	pm.pressure = monitor
+}
+
+// SetTelemetryTags sets the telemetry tags appended to the user-agent of Mountpoint. `workloadPods` reads labels of
+// workload Pods, it is only required if `tags` contain labels.
+func (pm *PodMounter) SetTelemetryTags(tags TelemetryTags, workloadPods typedcorev1.PodsGetter) {
+	pm.telemetryTags = tags
+	pm.workloadPods = workloadPods
+}
+
+// renderTelemetryTags returns the telemetry tags of the workload Pod of `credentialCtx`. Mountpoint Pods are shared
+// between workloads, so the tags of the workload Pod that caused the mount apply to all workloads sharing it.
+// Tags of labels are omitted if the workload Pod cannot be read, telemetry never fails a mount.
+func (pm *PodMounter) renderTelemetryTags(ctx context.Context, credentialCtx credentialprovider.ProvideContext) []string {
+	if len(pm.telemetryTags) == 0 {
+		return nil
+	}
+
+	var labels map[string]string
+	if pm.telemetryTags.NeedsLabels() && pm.workloadPods != nil && credentialCtx.PodName != "" {
+		pod, err := pm.workloadPods.Pods(credentialCtx.PodNamespace).Get(ctx, credentialCtx.PodName, metav1.GetOptions{})
+		if err != nil {
+			klog.Warningf("Failed to get workload Pod %s/%s for telemetry tags, omitting its labels: %v", credentialCtx.PodNamespace, credentialCtx.PodName, err)
+		} else {
+			labels = pod.Labels
+		}
+	}
+	return pm.telemetryTags.Render(credentialCtx.PodNamespace, labels)
 }
 
 // unmountTarget calls `unmount` syscall on `target`.
@@ -632,3 +999,54 @@ func (pm *PodMounter) IsSystemDMountpoint(target string) bool {
 	// Pod mounter would have at least one reference (the bind mount)
 	return len(references) == 1 // Only the mount itself, no bind references
 }
+
+// configureCacheArgs points Mountpoint to the cache volume of `mpPod`, if any. The cache size is capped to the size
+// limit of the volume unless `--max-cache-size` is set, as Mountpoint only sizes its cache from the free space of the
+// underlying filesystem, which is the node's disk or memory for `emptyDir` volumes.
+func configureCacheArgs(mpPod *corev1.Pod, builder *mountpoint.ArgsBuilder) {
+	cache := mppod.CacheOf(mpPod)
+	if cache == nil {
+		return
+	}
+
+	if cacheDir, ok := builder.Value(mountpoint.ArgCache); ok && cacheDir != mppod.CacheDirPath {
+		klog.Warningf("%s=%s ignored: the Mountpoint cache is in the %s cache volume of the Mountpoint Pod", mountpoint.ArgCache, cacheDir, cache.Type)
+	}
+	var maxSizeMiB int64
+	if cache.SizeLimit != nil {
+		maxSizeMiB = cache.SizeLimit.Value() / (1024 * 1024)
+	}
+	builder.WithCache(mppod.CacheDirPath, maxSizeMiB)
+}
+
+// configureLogArgs points Mountpoint to the volume of `mpPod` holding its log files, if any, and moves the log level
+// of `--log-level` to the environment of Mountpoint.
+func configureLogArgs(mpPod *corev1.Pod, builder *mountpoint.ArgsBuilder, env envprovider.Environment) {
+	if mppod.HasLogsVolume(mpPod) {
+		if logDir, ok := builder.Value(mountpoint.ArgLogDirectory); ok && logDir != mppod.LogsDirPath {
+			klog.Warningf("%s=%s ignored: Mountpoint logs to the log volume of the Mountpoint Pod", mountpoint.ArgLogDirectory, logDir)
+		}
+		builder.WithLogDirectory(mppod.LogsDirPath)
+	}
+
+	level, ok := builder.Take(mountpoint.ArgLogLevel)
+	if !ok {
+		return
+	}
+	switch level {
+	case volumecontext.LogLevelError, volumecontext.LogLevelWarn, volumecontext.LogLevelTrace:
+		env.Set(envprovider.EnvMountpointLog, mountpointLogFilter(level, builder.Has(mountpoint.ArgDebugCRT)))
+	default:
+		klog.Warningf("%s=%s ignored: must be %s, %s or %s", mountpoint.ArgLogLevel, level, volumecontext.LogLevelError, volumecontext.LogLevelWarn, volumecontext.LogLevelTrace)
+	}
+}
+
+// mountpointLogFilter returns the log filter of Mountpoint logging at `level`. Mountpoint replaces its own filter with
+// [envprovider.EnvMountpointLog], which must filter out logs of the AWS Common Runtime like it does unless `debugCRT`.
+func mountpointLogFilter(level string, debugCRT bool) string {
+	crtLevel := "off"
+	if debugCRT {
+		crtLevel = level
+	}
+	return level + ",awscrt=" + crtLevel
+}
@@ -2,10 +2,14 @@ package mounter_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -15,6 +19,7 @@ import (
 
 	"github.com/google/uuid"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8sruntime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -29,7 +34,10 @@ import (
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/mounter"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/mounter/mountertest"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/mountreport"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mounterror"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mountoptions"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod/watcher"
@@ -81,7 +89,7 @@ type testCtx struct {
 	k8sClient        client.Client
 	mount            *mount.FakeMounter
 	mountSyscall     func(target string, args mountpoint.Args) (fd int, err error)
-	bindMountSyscall func(source, target string) error
+	bindMountSyscall func(source, target string, options []string) error
 
 	bucketName  string
 	kubeletPath string
@@ -162,12 +170,12 @@ func setup(t *testing.T) *testCtx {
 		return int(mountertest.OpenDevNull(t).Fd()), nil
 	}
 
-	bindMountSyscall := func(source, target string) error {
+	bindMountSyscall := func(source, target string, options []string) error {
 		if testCtx.bindMountSyscall != nil {
-			return testCtx.bindMountSyscall(source, target)
+			return testCtx.bindMountSyscall(source, target, options)
 		}
 		// Default: simulate bind mount with fake mounter
-		return mount.Mount(source, target, "", []string{"bind"})
+		return mount.Mount(source, target, "", append([]string{"bind"}, options...))
 	}
 
 	credProvider := credentialprovider.New(client.CoreV1())
@@ -214,7 +222,7 @@ func TestPodMounter(t *testing.T) {
 			}
 
 			var bindMountCalled bool
-			testCtx.bindMountSyscall = func(source, target string) error {
+			testCtx.bindMountSyscall = func(source, target string, options []string) error {
 				bindMountCalled = true
 				assert.Equals(t, testCtx.sourcePath, source)
 				assert.Equals(t, testCtx.targetPath, target)
@@ -256,7 +264,46 @@ func TestPodMounter(t *testing.T) {
 			assertMountOptionsEqual(t, mountoptions.Options{
 				BucketName: testCtx.bucketName,
 				Args: []string{
-					"--user-agent-prefix=" + mounter.UserAgent(credentialprovider.AuthenticationSourceDriver, testK8sVersion),
+					"--user-agent-prefix=" + mounter.UserAgent(credentialprovider.AuthenticationSourceDriver, testK8sVersion, nil),
+				},
+				Env: envprovider.Default().List(),
+			}, got)
+		})
+
+		t.Run("Adds telemetry tags to the user agent", func(t *testing.T) {
+			testCtx := setup(t)
+
+			_, err := testCtx.client.CoreV1().Pods("team-a").Create(testCtx.ctx, &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "workload", Namespace: "team-a", Labels: map[string]string{"app.kubernetes.io/name": "web"}},
+			}, metav1.CreateOptions{})
+			assert.NoError(t, err)
+			tags, err := mounter.ParseTelemetryTags("cluster=prod,team-label=app.kubernetes.io/name,namespace")
+			assert.NoError(t, err)
+			testCtx.podMounter.SetTelemetryTags(tags, testCtx.client.CoreV1())
+
+			mountRes := make(chan error)
+			go func() {
+				mountRes <- testCtx.podMounter.Mount(testCtx.ctx, testCtx.bucketName, testCtx.targetPath, credentialprovider.ProvideContext{
+					AuthenticationSource: credentialprovider.AuthenticationSourceDriver,
+					VolumeID:             testCtx.volumeID,
+					PodID:                testCtx.podUID,
+					PodNamespace:         "team-a",
+					PodName:              "workload",
+				}, mountpoint.ParseArgs(nil), "")
+			}()
+
+			mpPod := createMountpointPod(testCtx)
+			mpPod.runWithCRD()
+
+			got := mpPod.receiveAndMount(testCtx.ctx)
+			assert.NoError(t, <-mountRes)
+
+			got.Fd = 0
+			assertMountOptionsEqual(t, mountoptions.Options{
+				BucketName: testCtx.bucketName,
+				Args: []string{
+					"--user-agent-prefix=" + mounter.UserAgent(credentialprovider.AuthenticationSourceDriver, testK8sVersion,
+						[]string{"cluster#prod", "team#web", "namespace#team-a"}),
 				},
 				Env: envprovider.Default().List(),
 			}, got)
@@ -419,7 +466,7 @@ func TestPodMounter(t *testing.T) {
 			assertMountOptionsEqual(t, mountoptions.Options{
 				BucketName: testCtx.bucketName,
 				Args: []string{
-					"--user-agent-prefix=" + mounter.UserAgent(credentialprovider.AuthenticationSourceDriver, testK8sVersion),
+					"--user-agent-prefix=" + mounter.UserAgent(credentialprovider.AuthenticationSourceDriver, testK8sVersion, nil),
 				},
 				Env: envprovider.Default().List(),
 			}, got)
@@ -733,6 +780,14 @@ func TestPodMounter(t *testing.T) {
 		t.Run("Strips --read-only flag for FUSE compatibility", func(t *testing.T) {
 			testCtx := setup(t)
 
+			devNull := mountertest.OpenDevNull(t)
+			var fuseReadOnly bool
+			testCtx.mountSyscall = func(target string, args mountpoint.Args) (fd int, err error) {
+				fuseReadOnly = args.Has(mountpoint.ArgReadOnly)
+				_ = testCtx.mount.Mount("mountpoint-s3", target, "fuse", nil)
+				return syscall.Dup(int(devNull.Fd()))
+			}
+
 			args := mountpoint.ParseArgs([]string{mountpoint.ArgReadOnly})
 
 			mountRes := make(chan error)
@@ -758,6 +813,132 @@ func TestPodMounter(t *testing.T) {
 					t.Error("Expected --read-only to be stripped from args")
 				}
 			}
+			// The FUSE mount itself is read-only
+			assert.Equals(t, true, fuseReadOnly)
+		})
+
+		t.Run("Points Mountpoint to the cache volume of the Mountpoint Pod", func(t *testing.T) {
+			testCtx := setup(t)
+
+			mountRes := make(chan error)
+			go func() {
+				err := testCtx.podMounter.Mount(testCtx.ctx, testCtx.bucketName, testCtx.targetPath, credentialprovider.ProvideContext{
+					AuthenticationSource: credentialprovider.AuthenticationSourceDriver,
+					VolumeID:             testCtx.volumeID,
+					PodID:                testCtx.podUID,
+				}, mountpoint.ParseArgs([]string{"cache=/tmp/cache"}), "")
+				mountRes <- err
+			}()
+
+			mpPod := createMountpointPodWithVolumes(testCtx, []corev1.Volume{{
+				Name: mppod.CacheVolumeName,
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{
+					SizeLimit: resource.NewQuantity(2*1024*1024*1024, resource.BinarySI),
+				}},
+			}})
+			mpPod.runWithCRD()
+			got := mpPod.receiveAndMount(testCtx.ctx)
+
+			assert.NoError(t, <-mountRes)
+			args := mountpoint.ParseArgs(got.Args)
+			cacheDir, _ := args.Value(mountpoint.ArgCache)
+			assert.Equals(t, mppod.CacheDirPath, cacheDir)
+			maxCacheSize, _ := args.Value(mountpoint.ArgMaxCacheSize)
+			assert.Equals(t, "2048", maxCacheSize)
+		})
+
+		t.Run("Points Mountpoint to the log volume of the Mountpoint Pod and sets its log level", func(t *testing.T) {
+			testCtx := setup(t)
+
+			mountRes := make(chan error)
+			go func() {
+				err := testCtx.podMounter.Mount(testCtx.ctx, testCtx.bucketName, testCtx.targetPath, credentialprovider.ProvideContext{
+					AuthenticationSource: credentialprovider.AuthenticationSourceDriver,
+					VolumeID:             testCtx.volumeID,
+					PodID:                testCtx.podUID,
+				}, mountpoint.ParseArgs([]string{"--debug", "--log-level=trace", "log-directory=/tmp/logs"}), "")
+				mountRes <- err
+			}()
+
+			mpPod := createMountpointPodWithVolumes(testCtx, []corev1.Volume{{
+				Name:         mppod.LogsVolumeName,
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			}})
+			mpPod.runWithCRD()
+			got := mpPod.receiveAndMount(testCtx.ctx)
+
+			assert.NoError(t, <-mountRes)
+			args := mountpoint.ParseArgs(got.Args)
+			logDir, _ := args.Value(mountpoint.ArgLogDirectory)
+			assert.Equals(t, mppod.LogsDirPath, logDir)
+			assert.Equals(t, false, args.Has(mountpoint.ArgLogLevel))
+			assert.Equals(t, true, slices.Contains(got.Env, "MOUNTPOINT_LOG=trace,awscrt=off"))
+		})
+
+		t.Run("Makes Mountpoint log its metrics if they are relayed", func(t *testing.T) {
+			testCtx := setup(t)
+			testCtx.podMounter.SetMountMetricsCollector(mounter.NewMountMetricsCollector(testCtx.podMounter))
+
+			mountRes := make(chan error)
+			go func() {
+				err := testCtx.podMounter.Mount(testCtx.ctx, testCtx.bucketName, testCtx.targetPath, credentialprovider.ProvideContext{
+					AuthenticationSource: credentialprovider.AuthenticationSourceDriver,
+					VolumeID:             testCtx.volumeID,
+					PodID:                testCtx.podUID,
+				}, mountpoint.ParseArgs(nil), "")
+				mountRes <- err
+			}()
+
+			mpPod := createMountpointPod(testCtx)
+			mpPod.runWithCRD()
+			got := mpPod.receiveAndMount(testCtx.ctx)
+
+			assert.NoError(t, <-mountRes)
+			args := mountpoint.ParseArgs(got.Args)
+			assert.Equals(t, true, args.Has(mountpoint.ArgLogMetrics))
+		})
+
+		t.Run("Mounts read-only for read-only attachments", func(t *testing.T) {
+			testCtx := setup(t)
+
+			devNull := mountertest.OpenDevNull(t)
+			var fuseReadOnly bool
+			testCtx.mountSyscall = func(target string, args mountpoint.Args) (fd int, err error) {
+				fuseReadOnly = args.Has(mountpoint.ArgReadOnly)
+				_ = testCtx.mount.Mount("mountpoint-s3", target, "fuse", nil)
+				return syscall.Dup(int(devNull.Fd()))
+			}
+
+			mountRes := make(chan error)
+			go func() {
+				err := testCtx.podMounter.Mount(testCtx.ctx, testCtx.bucketName, testCtx.targetPath, credentialprovider.ProvideContext{
+					AuthenticationSource: credentialprovider.AuthenticationSourceDriver,
+					VolumeID:             testCtx.volumeID,
+					PodID:                testCtx.podUID,
+				}, mountpoint.ParseArgs(nil), "")
+				mountRes <- err
+			}()
+
+			// The controller adds read-only to mount options of workloads with a ReadOnlyMany claim
+			mpPod := createMountpointPod(testCtx)
+			mpPod.run()
+			s3pa := &crdv2.MountpointS3PodAttachment{
+				ObjectMeta: metav1.ObjectMeta{Name: "s3pa-read-only"},
+				Spec: crdv2.MountpointS3PodAttachmentSpec{
+					NodeName:             "test-node",
+					PersistentVolumeName: testCtx.pvName,
+					VolumeID:             testCtx.volumeID,
+					MountOptions:         "read-only",
+					MountpointS3PodAttachments: map[string][]crdv2.WorkloadAttachment{
+						mpPod.pod.Name: {{WorkloadPodUID: testCtx.podUID}},
+					},
+				},
+			}
+			assert.NoError(t, testCtx.k8sClient.Create(testCtx.ctx, s3pa))
+			mpPod.receiveAndMount(testCtx.ctx)
+
+			assert.NoError(t, <-mountRes)
+			assert.Equals(t, true, fuseReadOnly)
 		})
 
 		t.Run("Does not duplicate mounts if target is already mounted", func(t *testing.T) {
@@ -937,6 +1118,77 @@ func TestPodMounter(t *testing.T) {
 			}
 		})
 
+		t.Run("Fails with the mount phase that timed out", func(t *testing.T) {
+			testCtx := setup(t)
+			timeouts := mounter.DefaultMountTimeouts()
+			timeouts[mounter.MountPhaseFUSEReady] = 100 * time.Millisecond
+			testCtx.podMounter.SetMountTimeouts(timeouts)
+
+			testCtx.mountSyscall = func(target string, args mountpoint.Args) (fd int, err error) {
+				// Mountpoint never serves the mount
+				return int(mountertest.OpenDevNull(t).Fd()), nil
+			}
+
+			go func() {
+				mpPod := createMountpointPod(testCtx)
+				mpPod.runWithCRD()
+				mpPod.receiveMountOptions(testCtx.ctx)
+			}()
+
+			err := testCtx.podMounter.Mount(testCtx.ctx, testCtx.bucketName, testCtx.targetPath, credentialprovider.ProvideContext{
+				VolumeID: testCtx.volumeID,
+				PodID:    testCtx.podUID,
+			}, mountpoint.ParseArgs(nil), "")
+			var timeoutErr *mounter.PhaseTimeoutError
+			if !errors.As(err, &timeoutErr) {
+				t.Fatalf("Expected a mount phase timeout, got %v", err)
+			}
+			assert.Equals(t, mounter.MountPhaseFUSEReady, timeoutErr.Phase)
+			assert.Equals(t, 100*time.Millisecond, timeoutErr.Timeout)
+		})
+
+		t.Run("Unmounts source if the mount is cancelled during the handshake", func(t *testing.T) {
+			testCtx := setup(t)
+			ctx, cancel := context.WithCancel(testCtx.ctx)
+			defer cancel()
+
+			done := make(chan struct{})
+			defer close(done)
+			go func() {
+				mpPod := createMountpointPod(testCtx)
+				mpPod.runWithCRD()
+
+				// Mountpoint reads the mount options but hangs before replying, until kubelet gives up on the call
+				mountSock, err := filepath.Rel(testCtx.kubeletPath, mppod.PathOnHost(mpPod.podPath, mppod.KnownPathMountSock))
+				assert.NoError(t, err)
+				l, err := net.Listen("unix", mountSock)
+				assert.NoError(t, err)
+				defer l.Close()
+				conn, err := l.Accept()
+				assert.NoError(t, err)
+				defer conn.Close()
+				_, _ = io.Copy(io.Discard, conn)
+				cancel()
+				<-done
+			}()
+
+			err := testCtx.podMounter.Mount(ctx, testCtx.bucketName, testCtx.targetPath, credentialprovider.ProvideContext{
+				VolumeID: testCtx.volumeID,
+				PodID:    testCtx.podUID,
+			}, mountpoint.ParseArgs(nil), "")
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("Expected the mount to be cancelled, got %v", err)
+			}
+
+			for _, path := range []string{testCtx.sourcePath, testCtx.targetPath} {
+				ok, err := testCtx.mount.IsMountPoint(path)
+				assert.NoError(t, err)
+				if ok {
+					t.Errorf("%s should not be left mounted by a cancelled mount", path)
+				}
+			}
+		})
+
 		t.Run("Adds a help message to see Mountpoint logs if Mountpoint Pod fails to start", func(t *testing.T) {
 			testCtx := setup(t)
 
@@ -976,6 +1228,68 @@ func TestPodMounter(t *testing.T) {
 				t.Errorf("it should unmount the target path if Mountpoint fails to start")
 			}
 		})
+
+		t.Run("Truncates large Mountpoint error output", func(t *testing.T) {
+			testCtx := setup(t)
+
+			testCtx.mountSyscall = func(target string, args mountpoint.Args) (fd int, err error) {
+				// Does not do real mounting
+				return int(mountertest.OpenDevNull(t).Fd()), nil
+			}
+
+			go func() {
+				mpPod := createMountpointPod(testCtx)
+				mpPod.runWithCRD()
+				mpPod.receiveMountOptions(testCtx.ctx)
+
+				// Emulate that Mountpoint failed to mount with a lot of output
+				mountErrorPath := mppod.PathOnHost(mpPod.podPath, mppod.KnownPathMountError)
+				err := os.WriteFile(mountErrorPath, []byte(strings.Repeat("x", 1024*1024)+"access denied"), 0o777)
+				assert.NoError(t, err)
+			}()
+
+			err := testCtx.podMounter.Mount(testCtx.ctx, testCtx.bucketName, testCtx.targetPath, credentialprovider.ProvideContext{
+				VolumeID: testCtx.volumeID,
+				PodID:    testCtx.podUID,
+			}, mountpoint.ParseArgs(nil), "")
+			if err == nil {
+				t.Fatalf("mount shouldn't succeeded if Mountpoint fails to start")
+			}
+			if !strings.Contains(err.Error(), "access denied") || len(err.Error()) > 16*1024 {
+				t.Errorf("Expected a truncated error message keeping the failure reason, got %d bytes", len(err.Error()))
+			}
+		})
+
+		t.Run("Returns the classified error of Mountpoint", func(t *testing.T) {
+			testCtx := setup(t)
+
+			testCtx.mountSyscall = func(target string, args mountpoint.Args) (fd int, err error) {
+				// Does not do real mounting
+				return int(mountertest.OpenDevNull(t).Fd()), nil
+			}
+
+			go func() {
+				mpPod := createMountpointPod(testCtx)
+				mpPod.runWithCRD()
+				mpPod.receiveMountOptions(testCtx.ctx)
+
+				// Emulate that Mountpoint failed to mount because the bucket does not exist
+				mountErrorPath := mppod.PathOnHost(mpPod.podPath, mppod.KnownPathMountError)
+				err := mounterror.New(mounterror.PhaseMount, 1, []byte("Error: NoSuchBucket")).Write(mountErrorPath)
+				assert.NoError(t, err)
+			}()
+
+			err := testCtx.podMounter.Mount(testCtx.ctx, testCtx.bucketName, testCtx.targetPath, credentialprovider.ProvideContext{
+				VolumeID: testCtx.volumeID,
+				PodID:    testCtx.podUID,
+			}, mountpoint.ParseArgs(nil), "")
+			var mountErr *mounterror.Error
+			if !errors.As(err, &mountErr) {
+				t.Fatalf("Expected a Mountpoint error, got %v", err)
+			}
+			assert.Equals(t, mounterror.ClassificationBucketNotFound, mountErr.Classification)
+			assert.Equals(t, 1, mountErr.ExitCode)
+		})
 	})
 
 	// Tests for S3PA WorkloadFSGroup matching behavior.
@@ -1071,6 +1385,218 @@ func TestPodMounter(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equals(t, false, ok)
 	})
+
+	t.Run("Records mounted targets across restarts", func(t *testing.T) {
+		testCtx := setup(t)
+
+		go func() {
+			mpPod := createMountpointPod(testCtx)
+			mpPod.runWithCRD()
+			mpPod.receiveAndMount(testCtx.ctx)
+		}()
+
+		err := testCtx.podMounter.Mount(testCtx.ctx, testCtx.bucketName, testCtx.targetPath, credentialprovider.ProvideContext{
+			VolumeID: testCtx.volumeID,
+			PodID:    testCtx.podUID,
+		}, mountpoint.ParseArgs(nil), "")
+		assert.NoError(t, err)
+
+		registry, existed, err := mounter.LoadMountRegistry(mounter.MountRegistryPath(testCtx.kubeletPath))
+		assert.NoError(t, err)
+		assert.Equals(t, true, existed)
+		handshake := mountoptions.LocalHandshake()
+		assert.Equals(t, []mounter.MountRecord{{
+			Target:        testCtx.targetPath,
+			VolumeID:      testCtx.volumeID,
+			Source:        testCtx.sourcePath,
+			MountpointPod: mppod.MountpointPodNameFor(testCtx.podUID, testCtx.pvName),
+			Handshake:     &handshake,
+		}}, registry.List())
+
+		// A restarted node plugin finds the target from its record, and removes the record on unmount
+		restarted, err := mounter.NewPodMounter(testCtx.podMounter.GetPodWatcher(), testCtx.podMounter.GetCredentialProvider(), testCtx.mount, nil, nil, testK8sVersion, testCtx.k8sClient)
+		assert.NoError(t, err)
+		ok, err := restarted.IsMountPoint(testCtx.targetPath)
+		assert.NoError(t, err)
+		assert.Equals(t, true, ok)
+
+		err = restarted.Unmount(testCtx.ctx, testCtx.targetPath, credentialprovider.CleanupContext{
+			VolumeID: testCtx.volumeID,
+			PodID:    testCtx.podUID,
+		})
+		assert.NoError(t, err)
+
+		registry, _, err = mounter.LoadMountRegistry(mounter.MountRegistryPath(testCtx.kubeletPath))
+		assert.NoError(t, err)
+		assert.Equals(t, 0, len(registry.List()))
+	})
+
+	t.Run("Reports mounts of Mountpoint Pods", func(t *testing.T) {
+		testCtx := setup(t)
+
+		go func() {
+			mpPod := createMountpointPod(testCtx)
+			mpPod.runWithCRD()
+			mpPod.receiveAndMount(testCtx.ctx)
+		}()
+
+		err := testCtx.podMounter.Mount(testCtx.ctx, testCtx.bucketName, testCtx.targetPath, credentialprovider.ProvideContext{
+			VolumeID: testCtx.volumeID,
+			PodID:    testCtx.podUID,
+		}, mountpoint.ParseArgs(nil), "")
+		assert.NoError(t, err)
+
+		mpPodName := mppod.MountpointPodNameFor(testCtx.podUID, testCtx.pvName)
+		mounts, err := testCtx.podMounter.MountReport()
+		assert.NoError(t, err)
+		assert.Equals(t, map[string]mountreport.MountpointPodMounts{mpPodName: {SourceMounted: true, Targets: 1}}, mounts)
+
+		assert.NoError(t, testCtx.podMounter.Unmount(testCtx.ctx, testCtx.targetPath, credentialprovider.CleanupContext{
+			VolumeID: testCtx.volumeID,
+			PodID:    testCtx.podUID,
+		}))
+		mounts, err = testCtx.podMounter.MountReport()
+		assert.NoError(t, err)
+		assert.Equals(t, map[string]mountreport.MountpointPodMounts{mpPodName: {SourceMounted: true}}, mounts)
+	})
+
+	t.Run("Migrates existing mounts to the mount registry", func(t *testing.T) {
+		testCtx := setup(t)
+
+		// Mounts of a node plugin without a mount registry
+		assert.NoError(t, os.MkdirAll(testCtx.sourcePath, 0o750))
+		assert.NoError(t, os.MkdirAll(testCtx.targetPath, 0o750))
+		assert.NoError(t, testCtx.mount.Mount("mountpoint-s3", testCtx.sourcePath, "fuse", nil))
+		assert.NoError(t, testCtx.mount.Mount(testCtx.sourcePath, testCtx.targetPath, "", []string{"bind"}))
+
+		_, err := mounter.NewPodMounter(testCtx.podMounter.GetPodWatcher(), testCtx.podMounter.GetCredentialProvider(), testCtx.mount, nil, nil, testK8sVersion, testCtx.k8sClient)
+		assert.NoError(t, err)
+
+		registry, existed, err := mounter.LoadMountRegistry(mounter.MountRegistryPath(testCtx.kubeletPath))
+		assert.NoError(t, err)
+		assert.Equals(t, true, existed)
+		assert.Equals(t, []mounter.MountRecord{{
+			Target:        testCtx.targetPath,
+			Source:        testCtx.sourcePath,
+			MountpointPod: mppod.MountpointPodNameFor(testCtx.podUID, testCtx.pvName),
+		}}, registry.List())
+	})
+
+	t.Run("Stages volumes and publishes them from the staging path", func(t *testing.T) {
+		testCtx := setup(t)
+		stagingPath := filepath.Join(testCtx.kubeletPath, "plugins", "kubernetes.io", "csi", "s3.csi.scality.com", "staging-hash", "globalmount")
+
+		go func() {
+			mpPod := createMountpointPod(testCtx)
+			mpPod.runWithCRD()
+			mpPod.receiveAndMount(testCtx.ctx)
+		}()
+
+		err := testCtx.podMounter.Stage(testCtx.ctx, testCtx.bucketName, stagingPath, credentialprovider.ProvideContext{
+			VolumeID: testCtx.volumeID,
+		}, mountpoint.ParseArgs(nil), "")
+		assert.NoError(t, err)
+
+		// Publishing is a bind mount of the staging path, repeated calls are no-ops
+		for range 2 {
+			assert.NoError(t, testCtx.podMounter.Publish(testCtx.ctx, stagingPath, testCtx.targetPath, testCtx.volumeID, false))
+		}
+		ok, err := testCtx.podMounter.IsMountPoint(testCtx.targetPath)
+		assert.NoError(t, err)
+		assert.Equals(t, true, ok)
+
+		mpPodName := mppod.MountpointPodNameFor(testCtx.podUID, testCtx.pvName)
+		registry, _, err := mounter.LoadMountRegistry(mounter.MountRegistryPath(testCtx.kubeletPath))
+		assert.NoError(t, err)
+		handshake := mountoptions.LocalHandshake()
+		assert.Equals(t, []mounter.MountRecord{
+			{Target: stagingPath, VolumeID: testCtx.volumeID, Source: testCtx.sourcePath, MountpointPod: mpPodName, Handshake: &handshake},
+			{Target: testCtx.targetPath, VolumeID: testCtx.volumeID, Source: stagingPath, MountpointPod: mpPodName},
+		}, registry.List())
+
+		assert.NoError(t, testCtx.podMounter.Unmount(testCtx.ctx, testCtx.targetPath, credentialprovider.CleanupContext{
+			VolumeID: testCtx.volumeID,
+			PodID:    testCtx.podUID,
+		}))
+		assert.NoError(t, testCtx.podMounter.Unstage(testCtx.ctx, stagingPath))
+		ok, err = testCtx.podMounter.IsMountPoint(stagingPath)
+		assert.NoError(t, err)
+		assert.Equals(t, false, ok)
+
+		// The source stays mounted until its Mountpoint Pod is terminated
+		ok, err = testCtx.podMounter.IsMountPoint(testCtx.sourcePath)
+		assert.NoError(t, err)
+		assert.Equals(t, true, ok)
+	})
+
+	t.Run("Publishes read-only bind mounts of the staging path", func(t *testing.T) {
+		testCtx := setup(t)
+		stagingPath := filepath.Join(testCtx.kubeletPath, "plugins", "kubernetes.io", "csi", "s3.csi.scality.com", "staging-hash", "globalmount")
+
+		go func() {
+			mpPod := createMountpointPod(testCtx)
+			mpPod.runWithCRD()
+			mpPod.receiveAndMount(testCtx.ctx)
+		}()
+
+		err := testCtx.podMounter.Stage(testCtx.ctx, testCtx.bucketName, stagingPath, credentialprovider.ProvideContext{
+			VolumeID: testCtx.volumeID,
+		}, mountpoint.ParseArgs(nil), "")
+		assert.NoError(t, err)
+
+		var bindOptions []string
+		testCtx.bindMountSyscall = func(source, target string, options []string) error {
+			bindOptions = options
+			return testCtx.mount.Mount(source, target, "", append([]string{"bind"}, options...))
+		}
+		assert.NoError(t, testCtx.podMounter.Publish(testCtx.ctx, stagingPath, testCtx.targetPath, testCtx.volumeID, true))
+		assert.Equals(t, []string{"ro"}, bindOptions)
+	})
+
+	t.Run("Does not publish volumes that are not staged", func(t *testing.T) {
+		testCtx := setup(t)
+		stagingPath := filepath.Join(testCtx.kubeletPath, "plugins", "kubernetes.io", "csi", "s3.csi.scality.com", "staging-hash", "globalmount")
+		assert.NoError(t, os.MkdirAll(stagingPath, 0o750))
+
+		err := testCtx.podMounter.Publish(testCtx.ctx, stagingPath, testCtx.targetPath, testCtx.volumeID, false)
+		if err == nil {
+			t.Fatal("Expected publishing a volume that is not staged to fail")
+		}
+	})
+
+	t.Run("Unmounting an inline ephemeral volume cleans up its credentials", func(t *testing.T) {
+		testCtx := setup(t)
+		testCtx.volumeID = volumecontext.EphemeralVolumeID(testCtx.podUID, testCtx.pvName)
+
+		mpPod := createMountpointPod(testCtx)
+		go func() {
+			mpPod.runWithCRD()
+			mpPod.receiveAndMount(testCtx.ctx)
+		}()
+
+		err := testCtx.podMounter.Mount(testCtx.ctx, testCtx.bucketName, testCtx.targetPath, credentialprovider.ProvideContext{
+			VolumeID: testCtx.volumeID,
+			PodID:    testCtx.podUID,
+		}, mountpoint.ParseArgs(nil), "")
+		assert.NoError(t, err)
+
+		credentialsDir := mppod.PathOnHost(mpPod.podPath, mppod.KnownPathCredentials)
+		entries, err := os.ReadDir(credentialsDir)
+		assert.NoError(t, err)
+		if len(entries) == 0 {
+			t.Fatalf("Expected driver-level credentials to be written to %s", credentialsDir)
+		}
+
+		err = testCtx.podMounter.Unmount(testCtx.ctx, testCtx.targetPath, credentialprovider.CleanupContext{
+			VolumeID: testCtx.volumeID,
+			PodID:    testCtx.podUID,
+		})
+		assert.NoError(t, err)
+
+		entries, err = os.ReadDir(credentialsDir)
+		assert.NoError(t, err)
+		assert.Equals(t, 0, len(entries))
+	})
 }
 
 type mountpointPod struct {
@@ -1080,6 +1606,12 @@ type mountpointPod struct {
 }
 
 func createMountpointPod(testCtx *testCtx) *mountpointPod {
+	testCtx.t.Helper()
+	return createMountpointPodWithVolumes(testCtx, nil)
+}
+
+// createMountpointPodWithVolumes creates a Mountpoint Pod with `volumes` in its spec.
+func createMountpointPodWithVolumes(testCtx *testCtx, volumes []corev1.Volume) *mountpointPod {
 	t := testCtx.t
 	t.Helper()
 
@@ -1092,6 +1624,7 @@ func createMountpointPod(testCtx *testCtx) *mountpointPod {
 		},
 		Spec: corev1.PodSpec{
 			NodeName: "test-node",
+			Volumes:  volumes,
 		},
 	}
 	pod, err := testCtx.client.CoreV1().Pods(mountpointPodNamespace).Create(context.TODO(), pod, metav1.CreateOptions{})
@@ -1292,7 +1825,7 @@ func TestPodMounterComponents(t *testing.T) {
 			return int(devNull.Fd()), nil
 		}
 
-		bindMountSyscall := func(source, target string) error {
+		bindMountSyscall := func(source, target string, options []string) error {
 			bindMountSyscallWouldBeCalled = true
 			return nil
 		}
@@ -1383,7 +1916,7 @@ func TestPodMounterComponents(t *testing.T) {
 		}
 
 		// Create with mixed nil and non-nil syscalls
-		customBindMount := func(source, target string) error {
+		customBindMount := func(source, target string, options []string) error {
 			return nil // Custom implementation
 		}
 
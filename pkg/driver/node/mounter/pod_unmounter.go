@@ -7,19 +7,30 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"time"
 
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+	mpmounter "github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint/mounter"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 // PodWatcher defines the interface for watching and retrieving pods
 type PodWatcher interface {
 	Get(name string) (*corev1.Pod, error)
+	APIServerAvailable() bool
 }
 
 // CredentialProvider defines the interface for credential management
@@ -43,6 +54,18 @@ const (
 
 	waitUntilMountpointIsUnusedTimeout  = 30 * time.Second
 	waitUntilMountpointIsUnusedInterval = 5 * time.Second
+
+	// orphanedMountGracePeriod is how long a Mountpoint Pod must exist before its mount is considered orphaned
+	// if no MountpointS3PodAttachment references it, to not race with the controller creating attachments.
+	orphanedMountGracePeriod = 5 * time.Minute
+)
+
+// Reasons of events emitted by the [PodUnmounter] when cleaning up orphaned mounts.
+const (
+	EventReasonOrphanedMountCleanedUp = "OrphanedMountCleanedUp"
+	// EventReasonDrainTimedOut is emitted on deleted Mountpoint Pods whose mount was detached from the workloads
+	// still using it once their drain timeout expired.
+	EventReasonDrainTimedOut = "DrainTimedOut"
 )
 
 // PodUnmounter handles unmounting of Mountpoint Pods and cleanup of associated resources
@@ -52,6 +75,18 @@ type PodUnmounter struct {
 	kubeletPath  string
 	podWatcher   PodWatcher
 	credProvider CredentialProvider
+
+	// s3paReader is used to find mounts of Mountpoint Pods no MountpointS3PodAttachment references anymore.
+	// Only mounts of deleted Mountpoint Pods are cleaned up if nil.
+	s3paReader client.Reader
+	// recorder reports cleaned up orphaned mounts, on their Mountpoint Pod or on the node if it is gone.
+	recorder record.EventRecorder
+	// pods removes the [mppod.FinalizerMountCleanup] finalizer of deleted Mountpoint Pods once cleaned up.
+	// Deleted Mountpoint Pods are not cleaned up if nil.
+	pods typedcorev1.PodInterface
+	// lazyUnmount detaches the mounts of workloads still using the mount of deleted Mountpoint Pods once their drain
+	// timeout expired.
+	lazyUnmount func(target string) error
 }
 
 // NewPodUnmounter creates a new PodUnmounter instance with the given parameters
@@ -71,9 +106,27 @@ func NewPodUnmounter(
 		kubeletPath:  kubeletPath,
 		podWatcher:   podWatcher,
 		credProvider: credProvider,
+		lazyUnmount:  mpmounter.UnmountLazy,
 	}
 }
 
+// SetAttachmentReader sets the reader of MountpointS3PodAttachments used to find orphaned mounts
+// of Mountpoint Pods that still exist.
+func (u *PodUnmounter) SetAttachmentReader(reader client.Reader) {
+	u.s3paReader = reader
+}
+
+// SetEventRecorder sets the recorder used to report cleaned up orphaned mounts.
+func (u *PodUnmounter) SetEventRecorder(recorder record.EventRecorder) {
+	u.recorder = recorder
+}
+
+// SetPodClient sets the client of Mountpoint Pods used to remove the [mppod.FinalizerMountCleanup] finalizer of
+// deleted Mountpoint Pods once their mount and credentials are cleaned up.
+func (u *PodUnmounter) SetPodClient(pods typedcorev1.PodInterface) {
+	u.pods = pods
+}
+
 // HandleMountpointPodUpdate is a Pod Update handler that triggers unmounting
 // if the Mountpoint Pod is marked for unmounting via annotations
 func (u *PodUnmounter) HandleMountpointPodUpdate(old, new any) {
@@ -85,10 +138,15 @@ func (u *PodUnmounter) HandleMountpointPodUpdate(old, new any) {
 	u.unmountMountpointPodIfNeeded(mpPod)
 }
 
-// StartPeriodicCleanup begins periodic cleanup of dangling mounts
+// StartPeriodicCleanup begins periodic cleanup of dangling mounts, starting with an immediate one to clean up
+// mounts left over by a crash of the node or of the node plugin.
 // This is needed in case when `HandleMountpointPodUpdate()` missed an update event to trigger cleanup.
 // stopCh: Channel to signal stopping of the cleanup routine
 func (u *PodUnmounter) StartPeriodicCleanup(stopCh <-chan struct{}) {
+	if err := u.CleanupDanglingMounts(); err != nil {
+		klog.Errorf("Failed to run clean up of dangling mounts: %v", err)
+	}
+
 	ticker := time.NewTicker(danglingMountpointCleanupInterval)
 	defer ticker.Stop()
 
@@ -105,8 +163,15 @@ func (u *PodUnmounter) StartPeriodicCleanup(stopCh <-chan struct{}) {
 }
 
 // CleanupDanglingMounts scans the source mount directory for potential dangling mounts
-// and cleans them up. It also unmounts any Mountpoint Pods marked for unmounting.
+// and cleans them up. It also unmounts any Mountpoint Pods marked for unmounting, and orphaned
+// Mountpoint Pods no MountpointS3PodAttachment references anymore.
 func (u *PodUnmounter) CleanupDanglingMounts() error {
+	// Mountpoint Pods missing from a stale cache are not necessarily deleted, wait for the API server to clean up
+	if !u.podWatcher.APIServerAvailable() {
+		klog.Warningf("Kubernetes API server is unavailable, skipping clean up of dangling mounts")
+		return nil
+	}
+
 	sourceMountDir := SourceMountDir(u.kubeletPath)
 	entries, err := os.ReadDir(sourceMountDir)
 	if err != nil {
@@ -118,6 +183,12 @@ func (u *PodUnmounter) CleanupDanglingMounts() error {
 		return fmt.Errorf("failed to read source mount directory %q: %w", sourceMountDir, err)
 	}
 
+	attached, err := u.attachedMountpointPods()
+	if err != nil {
+		// Keep cleaning up mounts of deleted and unmounted Mountpoint Pods
+		klog.Errorf("Failed to list MountpointS3PodAttachments, skipping orphaned Mountpoint Pods: %v", err)
+	}
+
 	for _, file := range entries {
 		if !file.IsDir() {
 			continue
@@ -129,10 +200,13 @@ func (u *PodUnmounter) CleanupDanglingMounts() error {
 		if err != nil {
 			if apierrors.IsNotFound(err) {
 				klog.Infof("Found a dangling Mountpoint mount %q, cleaning up", mpPodName)
-				if _, err := u.unmountAndRemoveMountpointSource(source); err != nil {
+				if wasMountpoint, err := u.unmountAndRemoveMountpointSource(source); err != nil {
 					klog.Errorf("Failed to unmount and remove Mountpoint %q: %v", source, err)
 				} else {
 					klog.Infof("Successfully cleaned dangling Mountpoint mount %q", mpPodName)
+					if wasMountpoint {
+						u.recordOrphanedMountCleanup(u.nodeReference(), "Unmounted %s of deleted Mountpoint Pod %s", source, mpPodName)
+					}
 				}
 				continue
 			}
@@ -140,14 +214,85 @@ func (u *PodUnmounter) CleanupDanglingMounts() error {
 			return fmt.Errorf("failed to check existence of Mountpoint Pod %q: %w", mpPodName, err)
 		}
 
+		if attached != nil && u.isOrphaned(mpPod, attached, source) {
+			klog.Infof("Found an orphaned Mountpoint Pod %q not referenced by any MountpointS3PodAttachment, cleaning up", mpPodName)
+			unlockMountpointPod := lockMountpointPod(mpPod.Name)
+			if u.cleanUnmount(mpPod) {
+				u.recordOrphanedMountCleanup(mpPod, "Unmounted %s, the Mountpoint Pod is not referenced by any MountpointS3PodAttachment", source)
+			}
+			unlockMountpointPod()
+			continue
+		}
+
 		u.unmountMountpointPodIfNeeded(mpPod)
 	}
 
 	return nil
 }
 
-// unmountMountpointPodIfNeeded unmounts `mpPod` if and only if annotated with "needs-unmount".
+// attachedMountpointPods returns names of Mountpoint Pods referenced by MountpointS3PodAttachments of the node,
+// or nil if orphaned Mountpoint Pods should not be looked for.
+func (u *PodUnmounter) attachedMountpointPods() (map[string]bool, error) {
+	if u.s3paReader == nil {
+		return nil, nil
+	}
+
+	s3paList := &crdv2.MountpointS3PodAttachmentList{}
+	if err := u.s3paReader.List(context.Background(), s3paList, client.MatchingFields{crdv2.FieldNodeName: u.nodeID}); err != nil {
+		return nil, err
+	}
+
+	attached := make(map[string]bool)
+	for _, s3pa := range s3paList.Items {
+		for mpPodName := range s3pa.Spec.MountpointS3PodAttachments {
+			attached[mpPodName] = true
+		}
+	}
+	return attached, nil
+}
+
+// isOrphaned returns whether the mount of `mpPod` at `source` is orphaned: `mpPod` is not referenced by any
+// MountpointS3PodAttachment, and no workload uses the mount anymore.
+func (u *PodUnmounter) isOrphaned(mpPod *corev1.Pod, attached map[string]bool, source string) bool {
+	if attached[mpPod.Name] || mpPod.Annotations[mppod.AnnotationNeedsUnmount] == "true" || mpPod.DeletionTimestamp != nil {
+		return false
+	}
+	if time.Since(mpPod.CreationTimestamp.Time) < orphanedMountGracePeriod {
+		return false
+	}
+
+	references, err := u.mount.FindReferencesToMountpoint(source)
+	if err != nil {
+		klog.Errorf("Failed to find references to Mountpoint %q: %v", source, err)
+		return false
+	}
+	return len(references) == 0
+}
+
+// recordOrphanedMountCleanup emits an event on `obj` about a cleaned up orphaned mount.
+func (u *PodUnmounter) recordOrphanedMountCleanup(obj runtime.Object, messageFmt string, args ...any) {
+	if u.recorder == nil {
+		return
+	}
+	u.recorder.Eventf(obj, corev1.EventTypeNormal, EventReasonOrphanedMountCleanedUp, messageFmt, args...)
+}
+
+// nodeReference returns a reference to the node for events, as kubelet does.
+func (u *PodUnmounter) nodeReference() *corev1.ObjectReference {
+	return &corev1.ObjectReference{Kind: "Node", Name: u.nodeID, UID: types.UID(u.nodeID)}
+}
+
+// unmountMountpointPodIfNeeded unmounts `mpPod` if and only if annotated with "needs-unmount", or cleans it up if
+// deleted.
 func (u *PodUnmounter) unmountMountpointPodIfNeeded(mpPod *corev1.Pod) {
+	if mpPod.DeletionTimestamp != nil && controllerutil.ContainsFinalizer(mpPod, mppod.FinalizerMountCleanup) {
+		unlockMountpointPod := lockMountpointPod(mpPod.Name)
+		defer unlockMountpointPod()
+
+		u.cleanupDeletedMountpointPod(mpPod)
+		return
+	}
+
 	if mpPod.Annotations[mppod.AnnotationNeedsUnmount] != "true" {
 		// Not marked for unmount, skip it
 		return
@@ -159,8 +304,8 @@ func (u *PodUnmounter) unmountMountpointPodIfNeeded(mpPod *corev1.Pod) {
 	u.cleanUnmount(mpPod)
 }
 
-// cleanUnmount performs a clean unmount for `mpPod`.
-func (u *PodUnmounter) cleanUnmount(mpPod *corev1.Pod) {
+// cleanUnmount performs a clean unmount for `mpPod`, and returns whether `mpPod` was mounted and got unmounted.
+func (u *PodUnmounter) cleanUnmount(mpPod *corev1.Pod) bool {
 	klog.V(5).Infof("Starting unmount procedure for Mountpoint Pod %q", mpPod.Name)
 
 	source := u.mountpointPodSourcePath(mpPod.Name)
@@ -171,7 +316,7 @@ func (u *PodUnmounter) cleanUnmount(mpPod *corev1.Pod) {
 		if !errors.Is(err, fs.ErrNotExist) {
 			klog.Errorf("Failed to write exit file for Mountpoint Pod %q: %v", mpPod.Name, err)
 		}
-		return
+		return false
 	}
 
 	// Now unmount and remove `source`
@@ -182,17 +327,107 @@ func (u *PodUnmounter) cleanUnmount(mpPod *corev1.Pod) {
 		} else {
 			klog.Errorf("Failed to unmount and remove Mountpoint Pod %q: %v", mpPod.Name, err)
 		}
-		return
+		return false
 	}
 
 	if err := u.cleanupCredentials(mpPod); err != nil {
 		klog.Errorf("Failed to cleanup credentials of Mountpoint Pod %q: %v", mpPod.Name, err)
-		return
+		return false
 	}
 
 	if wasMountpoint {
 		klog.Infof("Mountpoint Pod %q successfully unmounted", mpPod.Name)
 	}
+	return wasMountpoint
+}
+
+// cleanupDeletedMountpointPod unmounts and removes the source mount and the credentials of the deleted `mpPod`, then
+// removes its [mppod.FinalizerMountCleanup] finalizer so it can be removed. Its mount is unmounted once no workload
+// uses it anymore or once its drain timeout expired, the clean up is retried on the next update or periodic cleanup
+// until then.
+func (u *PodUnmounter) cleanupDeletedMountpointPod(mpPod *corev1.Pod) {
+	if u.pods == nil {
+		return
+	}
+
+	source := u.mountpointPodSourcePath(mpPod.Name)
+	if err := u.drainDeletedMountpointPod(mpPod, source); err != nil {
+		if errors.Is(err, errMountpointIsStillInUse) {
+			klog.Infof("Deleted Mountpoint Pod %q is still in use, will retry later", mpPod.Name)
+		} else {
+			klog.Errorf("Failed to drain deleted Mountpoint Pod %q: %v", mpPod.Name, err)
+		}
+		return
+	}
+
+	// The preStop hook of `mpPod` keeps Mountpoint running until `mount.exit` is written
+	if err := u.writeExitFile(u.podPath(string(mpPod.UID))); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		klog.Errorf("Failed to write exit file for deleted Mountpoint Pod %q: %v", mpPod.Name, err)
+		return
+	}
+
+	if _, err := u.unmountAndRemoveMountpointSource(source); err != nil {
+		klog.Errorf("Failed to unmount and remove deleted Mountpoint Pod %q: %v", mpPod.Name, err)
+		return
+	}
+
+	if err := u.cleanupCredentials(mpPod); err != nil {
+		klog.Errorf("Failed to cleanup credentials of deleted Mountpoint Pod %q: %v", mpPod.Name, err)
+		return
+	}
+
+	if err := u.removeCleanupFinalizer(mpPod); err != nil {
+		klog.Errorf("Failed to remove finalizer of deleted Mountpoint Pod %q: %v", mpPod.Name, err)
+		return
+	}
+	klog.Infof("Deleted Mountpoint Pod %q cleaned up", mpPod.Name)
+}
+
+// drainDeletedMountpointPod waits until no workload uses the mount of the deleted `mpPod` at `source` anymore, and
+// returns [errMountpointIsStillInUse] otherwise. Once the drain deadline of `mpPod` passed, the mounts of the
+// workloads still using it are detached lazily instead: they keep the files they opened until Mountpoint exits.
+func (u *PodUnmounter) drainDeletedMountpointPod(mpPod *corev1.Pod, source string) error {
+	isMountpoint, err := u.mount.CheckMountpoint(source)
+	if err != nil || !isMountpoint {
+		// Missing or corrupted mounts are handled by `unmountAndRemoveMountpointSource`
+		return nil
+	}
+
+	if deadline, ok := mppod.DrainDeadline(mpPod); !ok || time.Now().Before(deadline) {
+		return u.waitUntilMountpointIsUnused(source)
+	}
+
+	references, err := u.mount.FindReferencesToMountpoint(source)
+	if err != nil || len(references) == 0 {
+		return err
+	}
+	klog.Warningf("Drain timeout of deleted Mountpoint Pod %q expired, detaching its mount from %d workload targets still using it", mpPod.Name, len(references))
+	for _, target := range references {
+		if err := u.lazyUnmount(target); err != nil {
+			return fmt.Errorf("failed to detach workload target %q: %w", target, err)
+		}
+	}
+	if u.recorder != nil {
+		u.recorder.Eventf(mpPod, corev1.EventTypeWarning, EventReasonDrainTimedOut,
+			"Workloads did not release the mount within the drain timeout, detached it from %d workload targets", len(references))
+	}
+	return nil
+}
+
+// removeCleanupFinalizer removes the [mppod.FinalizerMountCleanup] finalizer of `mpPod`. The patch fails if the
+// finalizers of `mpPod` changed since it was read, to not remove other finalizers.
+func (u *PodUnmounter) removeCleanupFinalizer(mpPod *corev1.Pod) error {
+	idx := slices.Index(mpPod.Finalizers, mppod.FinalizerMountCleanup)
+	if idx == -1 {
+		return nil
+	}
+	patch := fmt.Sprintf(`[{"op":"test","path":"/metadata/finalizers/%d","value":%q},{"op":"remove","path":"/metadata/finalizers/%d"}]`,
+		idx, mppod.FinalizerMountCleanup, idx)
+	_, err := u.pods.Patch(context.Background(), mpPod.Name, types.JSONPatchType, []byte(patch), metav1.PatchOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
 }
 
 // unmountAndRemoveMountpointSource unmounts Mountpoint at `source`, and then removes the (empty) directory.
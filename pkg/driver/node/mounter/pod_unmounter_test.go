@@ -4,16 +4,26 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 // Helper functions for tests
@@ -48,6 +58,8 @@ func getExitFilePath(podPath string) string {
 type mockPodWatcher struct {
 	pods map[string]*corev1.Pod
 	err  error
+	// apiServerUnavailable simulates an open circuit breaker of the watcher
+	apiServerUnavailable bool
 	// For tracking calls made during periodic cleanup
 	getCallCount int32
 }
@@ -71,6 +83,10 @@ func (m *mockPodWatcher) Get(name string) (*corev1.Pod, error) {
 	return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, name)
 }
 
+func (m *mockPodWatcher) APIServerAvailable() bool {
+	return !m.apiServerUnavailable
+}
+
 // mockCredentialProvider implements CredentialProvider interface for unit testing
 type mockCredentialProvider struct {
 	cleanupErr   error
@@ -440,6 +456,114 @@ func TestCleanupDanglingMounts(t *testing.T) {
 	}
 }
 
+// mockMountWithReferences is a [mockMountInterface] with references per source
+type mockMountWithReferences struct {
+	*mockMountInterface
+	references map[string][]string
+}
+
+func (m *mockMountWithReferences) FindReferencesToMountpoint(source string) ([]string, error) {
+	return m.references[source], nil
+}
+
+func TestCleanupOrphanedMounts(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = crdv2.AddToScheme(scheme)
+
+	tempDir := t.TempDir()
+	createdAt := metav1.NewTime(time.Now().Add(-time.Hour))
+	pods := map[string]*corev1.Pod{}
+	for _, name := range []string{"mp-attached", "mp-orphan", "mp-new", "mp-in-use"} {
+		pods[name] = &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			UID:               types.UID(name + "-uid"),
+			CreationTimestamp: createdAt,
+		}}
+		setupTestDirectories(t, tempDir, name+"-uid", name)
+	}
+	pods["mp-new"].CreationTimestamp = metav1.Now()
+	_, deletedSource := setupTestDirectories(t, tempDir, "mp-deleted-uid", "mp-deleted")
+
+	s3paReader := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(&crdv2.MountpointS3PodAttachment{
+			ObjectMeta: metav1.ObjectMeta{Name: "s3pa"},
+			Spec: crdv2.MountpointS3PodAttachmentSpec{
+				NodeName:                   "test-node",
+				MountpointS3PodAttachments: map[string][]crdv2.WorkloadAttachment{"mp-attached": {{WorkloadPodUID: "workload"}}},
+			},
+		}).
+		WithIndex(&crdv2.MountpointS3PodAttachment{}, crdv2.FieldNodeName, func(o client.Object) []string {
+			return []string{o.(*crdv2.MountpointS3PodAttachment).Spec.NodeName}
+		}).
+		Build()
+	recorder := record.NewFakeRecorder(10)
+
+	mockMount := &mockMountWithReferences{
+		mockMountInterface: &mockMountInterface{useNewFields: true, checkMountpointReturn: true},
+		references: map[string][]string{
+			filepath.Join(SourceMountDir(tempDir), "mp-in-use"): {"/var/lib/kubelet/pods/workload/volumes/kubernetes.io~csi/pv/mount"},
+		},
+	}
+	unmounter := &PodUnmounter{
+		nodeID:       "test-node",
+		mount:        mockMount,
+		kubeletPath:  tempDir,
+		podWatcher:   &mockPodWatcher{pods: pods},
+		credProvider: &mockCredentialProvider{},
+	}
+	unmounter.SetAttachmentReader(s3paReader)
+	unmounter.SetEventRecorder(recorder)
+
+	if err := unmounter.CleanupDanglingMounts(); err != nil {
+		t.Fatalf("CleanupDanglingMounts() failed: %v", err)
+	}
+
+	orphanSource := filepath.Join(SourceMountDir(tempDir), "mp-orphan")
+	assert.Equals(t, []string{deletedSource, orphanSource}, mockMount.unmountCalls)
+	for _, name := range []string{"mp-attached", "mp-new", "mp-in-use"} {
+		if _, err := os.Stat(filepath.Join(SourceMountDir(tempDir), name)); err != nil {
+			t.Errorf("Expected mount of %s to remain: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(getExitFilePath(filepath.Join(tempDir, "pods", "mp-orphan-uid"))); err != nil {
+		t.Errorf("Expected orphaned Mountpoint Pod to be signaled to exit: %v", err)
+	}
+
+	assert.Equals(t, 2, len(recorder.Events))
+	for range 2 {
+		if event := <-recorder.Events; !strings.Contains(event, EventReasonOrphanedMountCleanedUp) {
+			t.Errorf("Expected %s event, got %q", EventReasonOrphanedMountCleanedUp, event)
+		}
+	}
+}
+
+func TestCleanupDanglingMountsWithAPIServerUnavailable(t *testing.T) {
+	tempDir := t.TempDir()
+	source := filepath.Join(SourceMountDir(tempDir), "mp-missing-from-cache")
+	if err := os.MkdirAll(source, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	mockMount := &mockMountInterface{useNewFields: true, checkMountpointReturn: true}
+	mockWatcher := &mockPodWatcher{apiServerUnavailable: true}
+	unmounter := &PodUnmounter{
+		nodeID:       "test-node",
+		mount:        mockMount,
+		kubeletPath:  tempDir,
+		podWatcher:   mockWatcher,
+		credProvider: &mockCredentialProvider{},
+	}
+
+	if err := unmounter.CleanupDanglingMounts(); err != nil {
+		t.Fatalf("CleanupDanglingMounts() failed: %v", err)
+	}
+	assert.Equals(t, int32(0), mockWatcher.GetCallCount())
+	if _, err := os.Stat(source); err != nil {
+		t.Errorf("Expected mount of a Mountpoint Pod missing from a stale cache to remain: %v", err)
+	}
+}
+
 // mockPodUnmounterForPeriodic wraps PodUnmounter to track cleanup calls
 type mockPodUnmounterForPeriodic struct {
 	*PodUnmounter
@@ -902,3 +1026,123 @@ func TestCleanUnmount(t *testing.T) {
 		}
 	})
 }
+
+func TestCleanupDeletedMountpointPod(t *testing.T) {
+	newDeletedMountpointPod := func() *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "mp-deleted-pod",
+				Namespace:         "mount-s3",
+				UID:               "deleted-uid",
+				DeletionTimestamp: &metav1.Time{Time: time.Now()},
+				Finalizers:        []string{"example.com/other", mppod.FinalizerMountCleanup},
+				Labels: map[string]string{
+					mppod.LabelVolumeId: "test-volume",
+				},
+			},
+		}
+	}
+
+	t.Run("removes the finalizer once unmounted", func(t *testing.T) {
+		mpPod := newDeletedMountpointPod()
+		clientset := k8sfake.NewClientset(mpPod.DeepCopy())
+		mockMount := &mockMountInterface{useNewFields: true}
+		mockCredProvider := &mockCredentialProvider{}
+		tmpDir := t.TempDir()
+		unmounter := &PodUnmounter{
+			nodeID:       "test-node",
+			mount:        mockMount,
+			kubeletPath:  tmpDir,
+			credProvider: mockCredProvider,
+		}
+		unmounter.SetPodClient(clientset.CoreV1().Pods("mount-s3"))
+		_, sourcePath := setupTestDirectories(t, tmpDir, string(mpPod.UID), mpPod.Name)
+
+		unmounter.cleanupDeletedMountpointPod(mpPod)
+
+		if _, err := os.Stat(sourcePath); !os.IsNotExist(err) {
+			t.Errorf("Expected source directory to be removed, got %v", err)
+		}
+		assert.Equals(t, 1, len(mockCredProvider.cleanupCalls))
+		got, err := clientset.CoreV1().Pods("mount-s3").Get(t.Context(), mpPod.Name, metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equals(t, []string{"example.com/other"}, got.Finalizers)
+	})
+
+	t.Run("keeps the finalizer if unmount fails", func(t *testing.T) {
+		mpPod := newDeletedMountpointPod()
+		clientset := k8sfake.NewClientset(mpPod.DeepCopy())
+		mockMount := &mockMountInterface{
+			checkMountpointReturn: true,
+			unmountError:          errors.New("unmount failed"),
+			useNewFields:          true,
+		}
+		mockCredProvider := &mockCredentialProvider{}
+		tmpDir := t.TempDir()
+		unmounter := &PodUnmounter{
+			nodeID:       "test-node",
+			mount:        mockMount,
+			kubeletPath:  tmpDir,
+			credProvider: mockCredProvider,
+		}
+		unmounter.SetPodClient(clientset.CoreV1().Pods("mount-s3"))
+		setupTestDirectories(t, tmpDir, string(mpPod.UID), mpPod.Name)
+
+		unmounter.cleanupDeletedMountpointPod(mpPod)
+
+		assert.Equals(t, 0, len(mockCredProvider.cleanupCalls))
+		got, err := clientset.CoreV1().Pods("mount-s3").Get(t.Context(), mpPod.Name, metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equals(t, mpPod.Finalizers, got.Finalizers)
+	})
+}
+
+func TestCleanupDeletedMountpointPodDrainTimeout(t *testing.T) {
+	mpPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:                       "mp-drained-pod",
+			Namespace:                  "mount-s3",
+			UID:                        "drained-uid",
+			DeletionTimestamp:          &metav1.Time{Time: time.Now().Add(2 * time.Minute)},
+			DeletionGracePeriodSeconds: ptr.To(int64(600)),
+			Annotations:                map[string]string{mppod.AnnotationDrainTimeout: "5m"},
+			Finalizers:                 []string{mppod.FinalizerMountCleanup},
+		},
+	}
+	clientset := k8sfake.NewClientset(mpPod.DeepCopy())
+	mockMount := &mockMountInterface{
+		checkMountpointReturn: true,
+		findReferencesReturn:  []string{"/var/lib/kubelet/pods/workload/volumes/target"},
+		useNewFields:          true,
+	}
+	var detached []string
+	tmpDir := t.TempDir()
+	recorder := record.NewFakeRecorder(1)
+	unmounter := &PodUnmounter{
+		nodeID:       "test-node",
+		mount:        mockMount,
+		kubeletPath:  tmpDir,
+		credProvider: &mockCredentialProvider{},
+		recorder:     recorder,
+		lazyUnmount: func(target string) error {
+			detached = append(detached, target)
+			mockMount.findReferencesReturn = nil
+			return nil
+		},
+	}
+	unmounter.SetPodClient(clientset.CoreV1().Pods("mount-s3"))
+	podPath, sourcePath := setupTestDirectories(t, tmpDir, string(mpPod.UID), mpPod.Name)
+
+	// Deleted 8 minutes ago with a drain timeout of 5 minutes
+	unmounter.cleanupDeletedMountpointPod(mpPod)
+
+	assert.Equals(t, []string{"/var/lib/kubelet/pods/workload/volumes/target"}, detached)
+	assert.Equals(t, []string{sourcePath}, mockMount.unmountCalls)
+	if _, err := os.Stat(getExitFilePath(podPath)); err != nil {
+		t.Errorf("Expected exit file to be written: %v", err)
+	}
+	assert.Equals(t, true, strings.HasPrefix(<-recorder.Events, "Warning "+EventReasonDrainTimedOut))
+	got, err := clientset.CoreV1().Pods("mount-s3").Get(t.Context(), mpPod.Name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equals(t, 0, len(got.Finalizers))
+}
@@ -0,0 +1,150 @@
+package mounter
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+	"k8s.io/mount-utils"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/maintenance"
+	mpmounter "github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint/mounter"
+)
+
+// ReadOnlyWindowEnforceInterval is how often mounts are checked against read-only windows of their volumes.
+const ReadOnlyWindowEnforceInterval = 15 * time.Second
+
+const procMountInfoPath = "/proc/self/mountinfo"
+
+// A ReadOnlyWindowEnforcer remounts mounts of volumes in a read-only window read-only, and restores them once
+// the window ends. Read-only windows are set on MountpointS3PodAttachments by the controller, see [maintenance].
+// Mounts of volumes above the hard limit of their prefix are remounted read-only the same way.
+//
+// Both the source mount of each Mountpoint Pod and the bind mounts of it to workload Pods are remounted, so
+// existing workloads keep their mounts and get `EROFS` on writes, without restarting Mountpoint.
+type ReadOnlyWindowEnforcer struct {
+	k8sClient     client.Reader
+	nodeName      string
+	kubeletPath   string
+	mountInfoPath string
+	remount       func(target string, readOnly bool, mountOptions []string) error
+	now           func() time.Time
+
+	mu sync.Mutex
+	// readOnlyMounts are mount points remounted read-only by the enforcer. Only these are made writable again
+	// once windows end, to keep mounts of read-only volumes read-only.
+	readOnlyMounts map[string]bool
+}
+
+// NewReadOnlyWindowEnforcer creates a new [ReadOnlyWindowEnforcer] for MountpointS3PodAttachments of `nodeName`.
+func NewReadOnlyWindowEnforcer(k8sClient client.Reader, nodeName string) *ReadOnlyWindowEnforcer {
+	kubeletPath := os.Getenv("KUBELET_PATH")
+	if kubeletPath == "" {
+		kubeletPath = "/var/lib/kubelet"
+	}
+	return &ReadOnlyWindowEnforcer{
+		k8sClient:      k8sClient,
+		nodeName:       nodeName,
+		kubeletPath:    kubeletPath,
+		mountInfoPath:  procMountInfoPath,
+		remount:        mpmounter.RemountBind,
+		now:            time.Now,
+		readOnlyMounts: make(map[string]bool),
+	}
+}
+
+// Start enforces read-only windows every `interval` until `stopCh` is closed.
+func (e *ReadOnlyWindowEnforcer) Start(stopCh <-chan struct{}, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			if err := e.Enforce(context.Background()); err != nil {
+				klog.Errorf("Failed to enforce read-only windows: %v", err)
+			}
+		}
+	}
+}
+
+// Enforce remounts mounts of Mountpoint Pods read-only if their MountpointS3PodAttachment is in a read-only
+// window or above its quota, or writable if the enforcer made them read-only and the window ended.
+func (e *ReadOnlyWindowEnforcer) Enforce(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	s3paList := &crdv2.MountpointS3PodAttachmentList{}
+	if err := e.k8sClient.List(ctx, s3paList, client.MatchingFields{crdv2.FieldNodeName: e.nodeName}); err != nil {
+		return err
+	}
+
+	now := e.now()
+	readOnlySources := make(map[string]bool)
+	for _, s3pa := range s3paList.Items {
+		readOnly := maintenance.ReadOnly(s3pa.Annotations, now) || maintenance.QuotaExceeded(s3pa.Annotations)
+		for mpPodName := range s3pa.Spec.MountpointS3PodAttachments {
+			readOnlySources[filepath.Join(SourceMountDir(e.kubeletPath), mpPodName)] = readOnly
+		}
+	}
+
+	mountInfos, err := mount.ParseMountInfo(e.mountInfoPath)
+	if err != nil {
+		return err
+	}
+
+	// Bind mounts of a source mount share its device
+	type device struct{ major, minor int }
+	readOnlyDevices := make(map[device]bool)
+	for _, info := range mountInfos {
+		if readOnly, ok := readOnlySources[info.MountPoint]; ok {
+			readOnlyDevices[device{info.Major, info.Minor}] = readOnly
+		}
+	}
+
+	var errs []error
+	present := make(map[string]bool)
+	for _, info := range mountInfos {
+		present[info.MountPoint] = true
+		readOnly, ok := readOnlyDevices[device{info.Major, info.Minor}]
+		if !ok {
+			continue
+		}
+
+		isReadOnly := slices.Contains(info.MountOptions, "ro")
+		switch {
+		case readOnly && !isReadOnly:
+			if err := e.remount(info.MountPoint, true, info.MountOptions); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			e.readOnlyMounts[info.MountPoint] = true
+			klog.Infof("Remounted %s read-only for a read-only window", info.MountPoint)
+		case !readOnly && isReadOnly && e.readOnlyMounts[info.MountPoint]:
+			if err := e.remount(info.MountPoint, false, info.MountOptions); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			delete(e.readOnlyMounts, info.MountPoint)
+			klog.Infof("Remounted %s writable after a read-only window", info.MountPoint)
+		}
+	}
+
+	// Forget unmounted mount points, a new mount at the same path should not be made writable
+	for mountPoint := range e.readOnlyMounts {
+		if !present[mountPoint] {
+			delete(e.readOnlyMounts, mountPoint)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,109 @@
+package mounter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/maintenance"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestReadOnlyWindowEnforcer(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = crdv2.AddToScheme(scheme)
+
+	now := time.Date(2025, time.June, 7, 3, 0, 0, 0, time.UTC)
+	s3pa := &crdv2.MountpointS3PodAttachment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "s3pa-1",
+			Annotations: map[string]string{maintenance.AnnotationReadOnlyUntil: maintenance.FormatUntil(now.Add(time.Hour))},
+		},
+		Spec: crdv2.MountpointS3PodAttachmentSpec{
+			NodeName:                   "node-1",
+			MountpointS3PodAttachments: map[string][]crdv2.WorkloadAttachment{"mp-1": {{WorkloadPodUID: "workload-1"}}},
+		},
+	}
+	otherS3PA := &crdv2.MountpointS3PodAttachment{
+		ObjectMeta: metav1.ObjectMeta{Name: "s3pa-2"},
+		Spec: crdv2.MountpointS3PodAttachmentSpec{
+			NodeName:                   "node-1",
+			MountpointS3PodAttachments: map[string][]crdv2.WorkloadAttachment{"mp-2": {{WorkloadPodUID: "workload-2"}}},
+		},
+	}
+	k8sClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(s3pa, otherS3PA).
+		WithIndex(&crdv2.MountpointS3PodAttachment{}, crdv2.FieldNodeName, func(o client.Object) []string {
+			return []string{o.(*crdv2.MountpointS3PodAttachment).Spec.NodeName}
+		}).
+		Build()
+
+	mountInfoPath := filepath.Join(t.TempDir(), "mountinfo")
+	writeMountInfo := func(lines ...string) {
+		t.Helper()
+		assert.NoError(t, os.WriteFile(mountInfoPath, []byte(strings.Join(lines, "\n")+"\n"), 0o644))
+	}
+	const (
+		source1   = "/var/lib/kubelet/plugins/s3.csi.scality.com/mnt/mp-1"
+		target1   = "/var/lib/kubelet/pods/workload-1/volumes/kubernetes.io~csi/pv-1/mount"
+		targetRO  = "/var/lib/kubelet/pods/workload-3/volumes/kubernetes.io~csi/pv-1/mount"
+		source2   = "/var/lib/kubelet/plugins/s3.csi.scality.com/mnt/mp-2"
+		target2   = "/var/lib/kubelet/pods/workload-2/volumes/kubernetes.io~csi/pv-2/mount"
+		otherRoot = "/"
+	)
+	mountInfoLine := func(id, minor int, mountPoint, options string) string {
+		return fmt.Sprintf("%d 1 0:%d / %s %s shared:1 - fuse mountpoint-s3 rw,user_id=0,group_id=0", id, minor, mountPoint, options)
+	}
+
+	var calls []string
+	enforcer := &ReadOnlyWindowEnforcer{
+		k8sClient:     k8sClient,
+		nodeName:      "node-1",
+		kubeletPath:   "/var/lib/kubelet",
+		mountInfoPath: mountInfoPath,
+		remount: func(target string, readOnly bool, mountOptions []string) error {
+			calls = append(calls, fmt.Sprintf("%s read-only=%t", target, readOnly))
+			return nil
+		},
+		now:            func() time.Time { return now },
+		readOnlyMounts: make(map[string]bool),
+	}
+
+	// Mounts of the volume in a read-only window are remounted read-only
+	writeMountInfo(
+		mountInfoLine(0, 9, otherRoot, "rw"),
+		mountInfoLine(1, 1, source1, "rw,nosuid,nodev,noatime"),
+		mountInfoLine(2, 1, target1, "rw,nosuid,nodev,noatime"),
+		mountInfoLine(3, 1, targetRO, "ro,nosuid,nodev,noatime"),
+		mountInfoLine(4, 2, source2, "rw,nosuid,nodev,noatime"),
+		mountInfoLine(5, 2, target2, "rw,nosuid,nodev,noatime"),
+	)
+	assert.NoError(t, enforcer.Enforce(context.Background()))
+	assert.Equals(t, []string{source1 + " read-only=true", target1 + " read-only=true"}, calls)
+
+	// Mounts are made writable again once the window ends, except the ones that were already read-only
+	calls = nil
+	now = now.Add(2 * time.Hour)
+	writeMountInfo(
+		mountInfoLine(0, 9, otherRoot, "rw"),
+		mountInfoLine(1, 1, source1, "ro,nosuid,nodev,noatime"),
+		mountInfoLine(2, 1, target1, "ro,nosuid,nodev,noatime"),
+		mountInfoLine(3, 1, targetRO, "ro,nosuid,nodev,noatime"),
+		mountInfoLine(4, 2, source2, "rw,nosuid,nodev,noatime"),
+		mountInfoLine(5, 2, target2, "rw,nosuid,nodev,noatime"),
+	)
+	assert.NoError(t, enforcer.Enforce(context.Background()))
+	assert.Equals(t, []string{source1 + " read-only=false", target1 + " read-only=false"}, calls)
+	assert.Equals(t, 0, len(enforcer.readOnlyMounts))
+}
@@ -0,0 +1,134 @@
+package mounter
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"k8s.io/klog/v2"
+	"k8s.io/mount-utils"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
+)
+
+// EnvVolumeStagingEnabled is the environment variable enabling volume staging: volumes are mounted once per node in
+// `NodeStageVolume`, and `NodePublishVolume` only bind-mounts them to targets.
+const EnvVolumeStagingEnabled = "VOLUME_STAGING_ENABLED"
+
+// A Stager mounts volumes at staging paths shared by all targets of a volume on the node.
+type Stager interface {
+	// Stage mounts `bucketName` at `stagingTarget`.
+	Stage(ctx context.Context, bucketName string, stagingTarget string, credentialCtx credentialprovider.ProvideContext, args mountpoint.Args, fsGroup string) error
+	// Publish bind-mounts `stagingTarget` of volume `volumeID` to `target`, read-only if `readOnly` is set.
+	Publish(ctx context.Context, stagingTarget string, target string, volumeID string, readOnly bool) error
+	// Unstage unmounts `stagingTarget`.
+	Unstage(ctx context.Context, stagingTarget string) error
+}
+
+var _ Stager = &PodMounter{}
+
+// Stage mounts `bucketName` at `stagingTarget` with the Mountpoint Pod assigned to the volume on this node.
+//
+// kubelet stages a volume before publishing it to its first workload on the node, so the MountpointS3PodAttachment
+// of that workload is used. Credentials are provided for that workload, `authenticationSource: secret` volumes
+// get their Secret from the `nodeStageSecretRef` of the volume.
+func (pm *PodMounter) Stage(ctx context.Context, bucketName string, stagingTarget string, credentialCtx credentialprovider.ProvideContext, args mountpoint.Args, fsGroup string) error {
+	if pm.k8sClient == nil {
+		return fmt.Errorf("k8sClient is required for pod mounter operations")
+	}
+
+	waitCtx, cancel := pm.withPhaseTimeout(ctx, MountPhaseAttachment)
+	defer cancel()
+
+	// kubelet does not provide the name of the Persistent Volume to stage, the volume ID identifies it on the node
+	fieldFilters := client.MatchingFields{
+		crdv2.FieldNodeName: pm.nodeName,
+		crdv2.FieldVolumeID: credentialCtx.VolumeID,
+	}
+	if fsGroup != "" {
+		fieldFilters[crdv2.FieldWorkloadFSGroup] = fsGroup
+	}
+
+	klog.V(4).Infof("Waiting for MountpointS3PodAttachment to stage volume %s", credentialCtx.VolumeID)
+	s3pa, mpPodName, attachment, err := pm.pollMountpointPodAttachment(waitCtx, fieldFilters, func(crdv2.WorkloadAttachment) bool {
+		return true
+	})
+	err = phaseError(waitCtx, err)
+	if err != nil {
+		klog.Errorf("failed to wait for MountpointS3PodAttachment to stage %q: %v. %s", stagingTarget, err, pm.helpMessageForGettingControllerLogs())
+		return fmt.Errorf("failed to wait for MountpointS3PodAttachment to stage %q: %w. %s", stagingTarget, err, pm.helpMessageForGettingControllerLogs())
+	}
+	klog.V(4).Infof("Staging volume %s with Mountpoint Pod %s", credentialCtx.VolumeID, mpPodName)
+
+	credentialCtx.PodID = attachment.WorkloadPodUID
+	return pm.mountFromAttachment(ctx, bucketName, stagingTarget, s3pa, mpPodName, credentialCtx, args)
+}
+
+// Publish bind-mounts `stagingTarget` to `target`. The staging path must be mounted. The staging path is shared by
+// all targets of the volume, so only the bind mount is read-only if `readOnly` is set, e.g. for `readOnly` volume
+// mounts of workloads.
+func (pm *PodMounter) Publish(ctx context.Context, stagingTarget string, target string, volumeID string, readOnly bool) error {
+	isStaged, err := pm.IsMountPoint(stagingTarget)
+	if err != nil {
+		return fmt.Errorf("could not check if staging path %q is a mount point: %w", stagingTarget, err)
+	}
+	if !isStaged {
+		return fmt.Errorf("staging path %q is not mounted", stagingTarget)
+	}
+
+	if err := pm.verifyOrSetupMountTarget(target); err != nil {
+		return fmt.Errorf("failed to verify target path can be used as a mount point %q: %w", target, err)
+	}
+
+	staging, _ := pm.registry.Get(stagingTarget)
+	record := MountRecord{Target: target, VolumeID: volumeID, Source: stagingTarget, MountpointPod: staging.MountpointPod}
+
+	isTargetMounted, err := pm.IsMountPoint(target)
+	if err != nil {
+		return fmt.Errorf("could not check if target %q is already a mount point: %w", target, err)
+	}
+	if isTargetMounted {
+		klog.V(4).Infof("Target path %q is already bind-mounted", target)
+		pm.recordMount(record)
+		return nil
+	}
+
+	var options []string
+	if readOnly {
+		options = append(options, "ro")
+	}
+	klog.V(4).Infof("Creating bind mount from staging path %s to target %s with options %v", stagingTarget, target, options)
+	if err := pm.bindMountWithTimeout(ctx, stagingTarget, target, options...); err != nil {
+		klog.Errorf("failed to bind mount %q to target %q: %v", stagingTarget, target, err)
+		return fmt.Errorf("failed to bind mount %q to target %q: %w", stagingTarget, target, err)
+	}
+	pm.recordMount(record)
+	return nil
+}
+
+// Unstage unmounts `stagingTarget`. kubelet only unstages a volume once it is unpublished from all targets, the
+// source mount of the Mountpoint Pod is kept until the Mountpoint Pod is terminated like with [PodMounter.Unmount].
+func (pm *PodMounter) Unstage(ctx context.Context, stagingTarget string) error {
+	isStaged, err := pm.IsMountPoint(stagingTarget)
+	if err != nil && mount.IsCorruptedMnt(err) {
+		klog.V(4).Infof("Staging path %q is corrupted: %v, will try to unmount", stagingTarget, err)
+		isStaged = true
+	} else if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("could not check if staging path %q is a mount point: %w", stagingTarget, err)
+	}
+	if isStaged {
+		if err := pm.unmountTarget(stagingTarget); err != nil {
+			klog.Errorf("failed to unmount staging path %q: %v", stagingTarget, err)
+			return fmt.Errorf("failed to unmount staging path %q: %w", stagingTarget, err)
+		}
+		klog.V(4).Infof("Staging path %q successfully unmounted", stagingTarget)
+	}
+
+	if err := pm.registry.Remove(stagingTarget); err != nil {
+		klog.Warningf("Failed to remove record of staging path %q: %v", stagingTarget, err)
+	}
+	return nil
+}
@@ -10,12 +10,12 @@ import (
 	"k8s.io/klog/v2"
 	"k8s.io/mount-utils"
 
-	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
 	mpmounter "github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint/mounter"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/system"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util"
 )
 
 type SystemdMounter struct {
@@ -134,7 +134,7 @@ func (m *SystemdMounter) Mount(ctx context.Context, bucketName string, target st
 
 	enforceCSIDriverMountArgPolicy(&args)
 
-	args.Set(mountpoint.ArgUserAgentPrefix, UserAgent(authenticationSource, m.kubernetesVersion))
+	args.Set(mountpoint.ArgUserAgentPrefix, UserAgent(authenticationSource, m.kubernetesVersion, nil))
 
 	output, err := m.Runner.StartService(timeoutCtx, &system.ExecConfig{
 		Name:        "mount-s3-" + m.MpVersion + "-" + uuid.New().String() + ".service",
@@ -192,7 +192,7 @@ func (m *SystemdMounter) credentialWriteAndEnvPath() (writePath string, envPath
 func hostPluginDirWithDefault() string {
 	hostPluginDir := os.Getenv("HOST_PLUGIN_DIR")
 	if hostPluginDir == "" {
-		hostPluginDir = "/var/lib/kubelet/plugins/" + constants.DriverName + "/"
+		hostPluginDir = "/var/lib/kubelet/plugins/" + util.DriverName() + "/"
 	}
 	return hostPluginDir
 }
@@ -172,6 +172,42 @@ func TestS3MounterMount(t *testing.T) {
 				})
 			},
 		},
+		{
+			name:       "success: keeps endpoint-url allowed by the driver",
+			bucketName: testBucketName,
+			targetPath: testTargetPath,
+			provideCtx: credentialprovider.ProvideContext{},
+			options:    []string{"--endpoint-url=https://S3.site-b.example.com/"},
+			before: func(t *testing.T, env *mounterTestEnv) {
+				t.Setenv(mounter.EnvAllowedEndpointURLs, "https://s3.site-a.example.com, https://s3.site-b.example.com")
+
+				env.mockRunner.EXPECT().StartService(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, config *system.ExecConfig) (string, error) {
+					if !slices.Contains(config.Args, "--endpoint-url=https://S3.site-b.example.com/") {
+						t.Fatalf("Allowed endpoint-url should be passed to mountpoint-s3, got %v", config.Args)
+					}
+					return "success", nil
+				})
+			},
+		},
+		{
+			name:       "success: removes endpoint-url not allowed by the driver",
+			bucketName: testBucketName,
+			targetPath: testTargetPath,
+			provideCtx: credentialprovider.ProvideContext{},
+			options:    []string{"--endpoint-url=https://s3.site-b.example.com.malicious.example.com"},
+			before: func(t *testing.T, env *mounterTestEnv) {
+				t.Setenv(mounter.EnvAllowedEndpointURLs, "https://s3.site-b.example.com")
+
+				env.mockRunner.EXPECT().StartService(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, config *system.ExecConfig) (string, error) {
+					for _, arg := range config.Args {
+						if strings.Contains(arg, "--endpoint-url") {
+							t.Fatal("endpoint-url not in the allowlist should be removed from mount options")
+						}
+					}
+					return "success", nil
+				})
+			},
+		},
 		{
 			name:        "failure: fails on mount failure",
 			bucketName:  testBucketName,
@@ -0,0 +1,121 @@
+package mounter
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// EnvTelemetryTags is the environment variable providing the default of the `--telemetry-tags` flag of the node plugin.
+const EnvTelemetryTags = "TELEMETRY_TAGS"
+
+const (
+	// telemetryTagLabelSuffix suffixes names of tags whose value is a label of the workload Pod.
+	telemetryTagLabelSuffix = "-label"
+	// telemetryTagNamespace is the name of the tag whose value is the namespace of the workload Pod.
+	telemetryTagNamespace = "namespace"
+)
+
+// A TelemetryTag is a `name#value` token appended to the user-agent of Mountpoint, so S3 access logs and UTAPI can
+// attribute traffic to Kubernetes workloads. Its value is either static, the namespace of the workload Pod, or a label
+// of the workload Pod.
+type TelemetryTag struct {
+	Name string
+	// Value is the static value of the tag, if neither [TelemetryTag.Namespace] nor [TelemetryTag.Label] is set.
+	Value string
+	// Namespace is whether the value of the tag is the namespace of the workload Pod.
+	Namespace bool
+	// Label is the key of the label of the workload Pod holding the value of the tag.
+	Label string
+}
+
+// TelemetryTags are the [TelemetryTag]s of the node plugin, in the order they are configured.
+type TelemetryTags []TelemetryTag
+
+// ParseTelemetryTags parses comma-separated tags:
+//   - `name=value` is a static tag, e.g. `cluster=prod`,
+//   - `name-label=key` is tag `name` with the value of label `key` of the workload Pod, e.g. `team-label=app.kubernetes.io/name`,
+//   - `namespace` is the namespace of the workload Pod.
+func ParseTelemetryTags(value string) (TelemetryTags, error) {
+	var tags TelemetryTags
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		var tag TelemetryTag
+		name, tagValue, hasValue := strings.Cut(entry, "=")
+		switch {
+		case !hasValue && name == telemetryTagNamespace:
+			tag = TelemetryTag{Name: name, Namespace: true}
+		case !hasValue:
+			return nil, fmt.Errorf("invalid telemetry tag %q: expected `name=value`, `name-label=key` or `namespace`", entry)
+		case strings.HasSuffix(name, telemetryTagLabelSuffix):
+			tag = TelemetryTag{Name: strings.TrimSuffix(name, telemetryTagLabelSuffix), Label: tagValue}
+			if errs := validation.IsQualifiedName(tagValue); len(errs) > 0 {
+				return nil, fmt.Errorf("invalid label key %q of telemetry tag %q: %s", tagValue, tag.Name, strings.Join(errs, ", "))
+			}
+		default:
+			tag = TelemetryTag{Name: name, Value: tagValue}
+			// Label values only contain characters allowed in user-agent tokens
+			if errs := validation.IsValidLabelValue(tagValue); len(errs) > 0 || tagValue == "" {
+				return nil, fmt.Errorf("invalid value %q of telemetry tag %q: must be a non-empty valid label value", tagValue, tag.Name)
+			}
+		}
+
+		if errs := validation.IsDNS1123Label(tag.Name); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid telemetry tag name %q: %s", tag.Name, strings.Join(errs, ", "))
+		}
+		if tag.Name+"#" == userAgentCredentialSourcePrefix {
+			return nil, fmt.Errorf("invalid telemetry tag name %q: reserved by the CSI driver", tag.Name)
+		}
+		if seen[tag.Name] {
+			return nil, fmt.Errorf("duplicate telemetry tag %q", tag.Name)
+		}
+		seen[tag.Name] = true
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// NeedsLabels returns whether any of the tags is a label of the workload Pod.
+func (t TelemetryTags) NeedsLabels() bool {
+	for _, tag := range t {
+		if tag.Label != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Names returns the names of the tags.
+func (t TelemetryTags) Names() []string {
+	var names []string
+	for _, tag := range t {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+// Render returns the `name#value` tokens of the tags for a workload Pod in `namespace` with `labels`.
+// Tags of labels the workload Pod does not have are omitted.
+func (t TelemetryTags) Render(namespace string, labels map[string]string) []string {
+	var tokens []string
+	for _, tag := range t {
+		value := tag.Value
+		switch {
+		case tag.Namespace:
+			value = namespace
+		case tag.Label != "":
+			value = labels[tag.Label]
+		}
+		if value == "" {
+			continue
+		}
+		tokens = append(tokens, tag.Name+"#"+value)
+	}
+	return tokens
+}
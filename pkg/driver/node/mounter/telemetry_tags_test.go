@@ -0,0 +1,65 @@
+package mounter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTelemetryTags(t *testing.T) {
+	tests := map[string]struct {
+		value   string
+		want    TelemetryTags
+		wantErr bool
+	}{
+		"empty": {},
+		"static, label and namespace tags": {
+			value: "cluster=prod, team-label=app.kubernetes.io/name,namespace",
+			want: TelemetryTags{
+				{Name: "cluster", Value: "prod"},
+				{Name: "team", Label: "app.kubernetes.io/name"},
+				{Name: "namespace", Namespace: true},
+			},
+		},
+		"missing value":         {value: "cluster", wantErr: true},
+		"empty value":           {value: "cluster=", wantErr: true},
+		"value with spaces":     {value: "cluster=prod east", wantErr: true},
+		"invalid label key":     {value: "team-label=app/name/x", wantErr: true},
+		"invalid name":          {value: "Cluster=prod", wantErr: true},
+		"reserved name":         {value: "credential-source=pod", wantErr: true},
+		"duplicate name":        {value: "cluster=prod,cluster-label=cluster", wantErr: true},
+		"trailing empty entry":  {value: "cluster=prod,", want: TelemetryTags{{Name: "cluster", Value: "prod"}}},
+		"namespace static tag":  {value: "namespace=shared", want: TelemetryTags{{Name: "namespace", Value: "shared"}}},
+		"label suffix only tag": {value: "-label=app", wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseTelemetryTags(test.value)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("ParseTelemetryTags(%q) returned no error", test.value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTelemetryTags(%q) returned error: %v", test.value, err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("ParseTelemetryTags(%q) returned %+v; expected %+v", test.value, got, test.want)
+			}
+		})
+	}
+}
+
+func TestRenderTelemetryTags(t *testing.T) {
+	tags, err := ParseTelemetryTags("cluster=prod,team-label=team,app-label=app,namespace")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := tags.Render("default", map[string]string{"app": "web"})
+	want := []string{"cluster#prod", "app#web", "namespace#default"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Render returned %v; expected %v", got, want)
+	}
+}
@@ -28,8 +28,8 @@ const (
 	userAgentCredentialSourcePrefix = "credential-source#"
 )
 
-// UserAgent returns user-agent for the CSI driver.
-func UserAgent(authenticationSource string, kubernetesVersion string) string {
+// UserAgent returns user-agent for the CSI driver, followed by `telemetryTags` rendered by [TelemetryTags.Render].
+func UserAgent(authenticationSource string, kubernetesVersion string, telemetryTags []string) string {
 	var b strings.Builder
 
 	// s3-csi-driver/v0.0.0
@@ -48,5 +48,11 @@ func UserAgent(authenticationSource string, kubernetesVersion string) string {
 		b.WriteString(kubernetesVersion)
 	}
 
+	// cluster#prod team#web
+	for _, tag := range telemetryTags {
+		b.WriteRune(' ')
+		b.WriteString(tag)
+	}
+
 	return b.String()
 }
@@ -26,6 +26,7 @@ func TestUserAgent(t *testing.T) {
 	tests := map[string]struct {
 		k8sVersion           string
 		authenticationSource string
+		telemetryTags        []string
 		result               string
 	}{
 		"empty versions": {
@@ -44,12 +45,18 @@ func TestUserAgent(t *testing.T) {
 			authenticationSource: credentialprovider.AuthenticationSourceDriver,
 			result:               "s3-csi-driver/ credential-source#driver k8s/v1.30.2-eks-db838b0",
 		},
+		"telemetry tags": {
+			k8sVersion:           "v1.30.2",
+			authenticationSource: credentialprovider.AuthenticationSourceDriver,
+			telemetryTags:        []string{"cluster#prod", "team#web"},
+			result:               "s3-csi-driver/ credential-source#driver k8s/v1.30.2 cluster#prod team#web",
+		},
 	}
 
 	for name, test := range tests {
 		test := test
 		t.Run(name, func(t *testing.T) {
-			if got, expected := UserAgent(test.authenticationSource, test.k8sVersion), test.result; got != expected {
+			if got, expected := UserAgent(test.authenticationSource, test.k8sVersion, test.telemetryTags), test.result; got != expected {
 				t.Fatalf("UserAgent(%q, %q) returned %q; expected %q", test.authenticationSource, test.k8sVersion, got, expected)
 			}
 		})
@@ -0,0 +1,142 @@
+// Package mountreport reports the mounts of the node plugin on its Node, so the controller can compare them with
+// Mountpoint Pods and MountpointS3PodAttachments and catch leaks that individual control loops miss.
+//
+// The report is a JSON annotation of the Node, updated when mounts change and refreshed every [RefreshInterval]
+// so the controller can tell stale reports of stopped node plugins apart.
+package mountreport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util"
+)
+
+// EnvMountReportsEnabled is the environment variable enabling mount reports.
+const EnvMountReportsEnabled = "MOUNT_REPORTS_ENABLED"
+
+// Annotation is the annotation of Nodes containing the mount report of their node plugin. It is prefixed with the
+// name of the driver instance, so several instances installed in a cluster report their mounts on the same Node.
+var Annotation = util.DriverName() + "/mount-report"
+
+const (
+	// CheckInterval is how often mounts are checked for changes.
+	CheckInterval = time.Minute
+	// RefreshInterval is how often an unchanged report is refreshed.
+	RefreshInterval = 10 * time.Minute
+	// MaxAge is the age after which a report is stale, its node plugin is likely not running.
+	MaxAge = 3 * RefreshInterval
+)
+
+// MountpointPodMounts are the mounts of a Mountpoint Pod on the node.
+type MountpointPodMounts struct {
+	// SourceMounted is true if Mountpoint is mounted at the source directory of the Mountpoint Pod.
+	SourceMounted bool `json:"sourceMounted"`
+	// Targets is the number of targets bind-mounted from the source of the Mountpoint Pod.
+	Targets int32 `json:"targets"`
+}
+
+// A Report lists the mounts of a node plugin.
+type Report struct {
+	// MountpointPods are the mounts of each Mountpoint Pod, by name.
+	MountpointPods map[string]MountpointPodMounts `json:"mountpointPods"`
+	// Time is when the report was made.
+	Time metav1.Time `json:"time"`
+}
+
+// FromNode returns the mount report of `node`, nil if it has none.
+func FromNode(node *corev1.Node) (*Report, error) {
+	value, ok := node.Annotations[Annotation]
+	if !ok {
+		return nil, nil
+	}
+	report := &Report{}
+	if err := json.Unmarshal([]byte(value), report); err != nil {
+		return nil, fmt.Errorf("failed to parse mount report of node %s: %w", node.Name, err)
+	}
+	return report, nil
+}
+
+// A Collector returns the mounts of each Mountpoint Pod on the node.
+type Collector func() (map[string]MountpointPodMounts, error)
+
+// A Reporter periodically writes the mounts of the node plugin to the [Annotation] of its Node.
+type Reporter struct {
+	nodes    typedcorev1.NodesGetter
+	nodeName string
+	collect  Collector
+	now      func() time.Time
+
+	lastMounts map[string]MountpointPodMounts
+	lastReport time.Time
+}
+
+// NewReporter creates a new [Reporter] of the mounts returned by `collect` on Node `nodeName`.
+func NewReporter(nodes typedcorev1.NodesGetter, nodeName string, collect Collector) *Reporter {
+	return &Reporter{
+		nodes:    nodes,
+		nodeName: nodeName,
+		collect:  collect,
+		now:      time.Now,
+	}
+}
+
+// Start reports mounts every `interval` until `stopCh` is closed.
+func (r *Reporter) Start(stopCh <-chan struct{}, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.Run(context.Background()); err != nil {
+			klog.Warningf("Failed to report mounts on node %s: %v", r.nodeName, err)
+		}
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Run writes the mount report if mounts changed since the last report, or if the last report is due for a refresh.
+func (r *Reporter) Run(ctx context.Context) error {
+	mounts, err := r.collect()
+	if err != nil {
+		return err
+	}
+
+	now := r.now()
+	if r.lastMounts != nil && maps.Equal(mounts, r.lastMounts) && now.Sub(r.lastReport) < RefreshInterval {
+		return nil
+	}
+
+	report, err := json.Marshal(Report{MountpointPods: mounts, Time: metav1.NewTime(now)})
+	if err != nil {
+		return err
+	}
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]string{Annotation: string(report)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := r.nodes.Nodes().Patch(ctx, r.nodeName, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return err
+	}
+
+	klog.V(4).Infof("Reported mounts of %d Mountpoint Pods on node %s", len(mounts), r.nodeName)
+	r.lastMounts = mounts
+	r.lastReport = now
+	return nil
+}
@@ -0,0 +1,73 @@
+package mountreport
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestReporter(t *testing.T) {
+	client := fake.NewClientset(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}})
+	mounts := map[string]MountpointPodMounts{"mp-1": {SourceMounted: true, Targets: 2}}
+	reporter := NewReporter(client.CoreV1(), "node-1", func() (map[string]MountpointPodMounts, error) {
+		return mounts, nil
+	})
+	now := time.Date(2025, time.June, 7, 12, 0, 0, 0, time.UTC)
+	reporter.now = func() time.Time { return now }
+
+	patches := func() int {
+		count := 0
+		for _, action := range client.Actions() {
+			if _, ok := action.(k8stesting.PatchAction); ok {
+				count++
+			}
+		}
+		return count
+	}
+	report := func() *Report {
+		node, err := client.CoreV1().Nodes().Get(context.Background(), "node-1", metav1.GetOptions{})
+		assert.NoError(t, err)
+		report, err := FromNode(node)
+		assert.NoError(t, err)
+		return report
+	}
+
+	assert.NoError(t, reporter.Run(context.Background()))
+	assert.Equals(t, 1, patches())
+	assert.Equals(t, mounts, report().MountpointPods)
+
+	// Unchanged mounts are only reported again once the report is due for a refresh
+	now = now.Add(CheckInterval)
+	assert.NoError(t, reporter.Run(context.Background()))
+	assert.Equals(t, 1, patches())
+	now = now.Add(RefreshInterval)
+	assert.NoError(t, reporter.Run(context.Background()))
+	assert.Equals(t, 2, patches())
+	assert.Equals(t, true, report().Time.Time.Equal(now))
+
+	mounts = map[string]MountpointPodMounts{"mp-1": {SourceMounted: true, Targets: 1}, "mp-2": {}}
+	now = now.Add(CheckInterval)
+	assert.NoError(t, reporter.Run(context.Background()))
+	assert.Equals(t, 3, patches())
+	assert.Equals(t, mounts, report().MountpointPods)
+}
+
+func TestFromNodeWithoutReport(t *testing.T) {
+	report, err := FromNode(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}})
+	assert.NoError(t, err)
+	if report != nil {
+		t.Fatalf("Expected no report, got %+v", report)
+	}
+
+	_, err = FromNode(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1", Annotations: map[string]string{Annotation: "{"}}})
+	if err == nil {
+		t.Fatal("Expected an error for an invalid report")
+	}
+}
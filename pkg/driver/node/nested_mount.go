@@ -0,0 +1,76 @@
+package node
+
+import (
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/mount-utils"
+
+	mpmounter "github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint/mounter"
+)
+
+// A MountLister lists the mounts of the node, implemented by [mount.Interface].
+type MountLister interface {
+	List() ([]mount.MountPoint, error)
+}
+
+// checkNotNested returns an error if `target` is inside another Mountpoint mount, or if Mountpoint mounts exist
+// inside `target` that a mount at `target` would shadow. The unmount order of nested mounts is undefined: unmounting
+// the outer mount first fails as it is busy, or leaves the inner mount unreachable.
+func (ns *S3NodeServer) checkNotNested(target string) error {
+	mounts, err := ns.MountTable.List()
+	if err != nil {
+		return status.Errorf(codes.Internal, "Could not list mounts to check %q is not nested: %v", target, err)
+	}
+	for _, m := range mountpointMounts(mounts) {
+		switch {
+		case isUnder(target, m.Path):
+			return status.Errorf(codes.FailedPrecondition, "Target path %q is inside the S3 volume mounted at %q, nested S3 volumes are not supported", target, m.Path)
+		case isUnder(m.Path, target):
+			return status.Errorf(codes.FailedPrecondition, "Target path %q contains the S3 volume mounted at %q, nested S3 volumes are not supported", target, m.Path)
+		}
+	}
+	return nil
+}
+
+// checkNoNestedMounts returns an error if Mountpoint mounts exist inside `target`, they must be unmounted before
+// `target` is. Mounts sharing a parent directory with `target` are not affected.
+func (ns *S3NodeServer) checkNoNestedMounts(target string) error {
+	mounts, err := ns.MountTable.List()
+	if err != nil {
+		return status.Errorf(codes.Internal, "Could not list mounts to check %q has no nested mounts: %v", target, err)
+	}
+	var nested []string
+	for _, m := range mountpointMounts(mounts) {
+		if isUnder(m.Path, target) {
+			nested = append(nested, m.Path)
+		}
+	}
+	if len(nested) > 0 {
+		return status.Errorf(codes.FailedPrecondition, "Could not unmount %q: S3 volumes are mounted inside it and must be unmounted first: %s", target, strings.Join(nested, ", "))
+	}
+	return nil
+}
+
+// mountpointMounts returns the Mountpoint mounts in `mounts`, including bind mounts of Mountpoint mounts.
+func mountpointMounts(mounts []mount.MountPoint) []mount.MountPoint {
+	var result []mount.MountPoint
+	for _, m := range mounts {
+		if m.Device == mpmounter.MountpointDeviceName {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// isUnder returns whether `path` is strictly inside directory `parent`.
+// A sibling sharing a name prefix, like `/mnt/a-2` for `/mnt/a`, is not inside it.
+func isUnder(path, parent string) bool {
+	rel, err := filepath.Rel(filepath.Clean(parent), filepath.Clean(path))
+	if err != nil {
+		return false
+	}
+	return rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
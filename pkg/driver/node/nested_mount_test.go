@@ -0,0 +1,120 @@
+package node_test
+
+import (
+	"context"
+	"testing"
+
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/golang/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/mount-utils"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+const (
+	testParentTarget = "/var/lib/kubelet/pods/uid-1/volumes/kubernetes.io~csi/outer/mount"
+	testTarget       = "/var/lib/kubelet/pods/uid-1/volumes/kubernetes.io~csi/outer/mount/inner"
+	testSiblingPath  = "/var/lib/kubelet/pods/uid-1/volumes/kubernetes.io~csi/outer/mount-2"
+)
+
+func TestNodePublishVolumeNestedMounts(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		mounts   []mount.MountPoint
+		wantCode codes.Code
+	}{
+		{
+			name:     "target inside another S3 volume",
+			target:   testTarget,
+			mounts:   []mount.MountPoint{{Device: "mountpoint-s3", Path: testParentTarget}},
+			wantCode: codes.FailedPrecondition,
+		},
+		{
+			name:     "target containing another S3 volume",
+			target:   testParentTarget,
+			mounts:   []mount.MountPoint{{Device: "mountpoint-s3", Path: testTarget}},
+			wantCode: codes.FailedPrecondition,
+		},
+		{
+			name:   "target inside a mount of another file system",
+			target: testTarget,
+			mounts: []mount.MountPoint{{Device: "tmpfs", Path: testParentTarget}},
+		},
+		{
+			name:   "sibling S3 volume sharing a name prefix",
+			target: testParentTarget,
+			mounts: []mount.MountPoint{{Device: "mountpoint-s3", Path: testSiblingPath}},
+		},
+		{
+			name:   "target already mounted",
+			target: testParentTarget,
+			mounts: []mount.MountPoint{{Device: "mountpoint-s3", Path: testParentTarget}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodeTestEnv := initNodeServerTestEnv(t)
+			nodeTestEnv.server.MountTable = mount.NewFakeMounter(tt.mounts)
+			if tt.wantCode == codes.OK {
+				nodeTestEnv.mockMounter.EXPECT().Mount(gomock.Any(), gomock.Any(), gomock.Eq(tt.target), gomock.Any(), gomock.Any(), gomock.Any())
+			}
+
+			_, err := nodeTestEnv.server.NodePublishVolume(context.Background(), &csi.NodePublishVolumeRequest{
+				VolumeId: "test-volume-id",
+				VolumeCapability: &csi.VolumeCapability{
+					AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+					AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER},
+				},
+				TargetPath:    tt.target,
+				VolumeContext: map[string]string{"bucketName": "test-bucket"},
+			})
+			assert.Equals(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
+
+func TestNodeUnpublishVolumeNestedMounts(t *testing.T) {
+	tests := []struct {
+		name     string
+		mounts   []mount.MountPoint
+		wantCode codes.Code
+	}{
+		{
+			name: "S3 volume mounted inside the target",
+			mounts: []mount.MountPoint{
+				{Device: "mountpoint-s3", Path: testParentTarget},
+				{Device: "mountpoint-s3", Path: testTarget},
+			},
+			wantCode: codes.FailedPrecondition,
+		},
+		{
+			name: "sibling S3 volume sharing a name prefix",
+			mounts: []mount.MountPoint{
+				{Device: "mountpoint-s3", Path: testParentTarget},
+				{Device: "mountpoint-s3", Path: testSiblingPath},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			nodeTestEnv := initNodeServerTestEnv(t)
+			nodeTestEnv.server.MountTable = mount.NewFakeMounter(tt.mounts)
+			nodeTestEnv.mockMounter.EXPECT().IsMountPoint(gomock.Eq(testParentTarget)).Return(true, nil)
+			if tt.wantCode == codes.OK {
+				nodeTestEnv.mockMounter.EXPECT().Unmount(gomock.Eq(ctx), gomock.Eq(testParentTarget), gomock.Any())
+			}
+
+			_, err := nodeTestEnv.server.NodeUnpublishVolume(ctx, &csi.NodeUnpublishVolumeRequest{
+				VolumeId:   "test-volume-id",
+				TargetPath: testParentTarget,
+			})
+			assert.Equals(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
@@ -18,21 +18,41 @@ package node
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	"github.com/kubernetes-csi/csi-lib-utils/protosanitizer"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	"k8s.io/mount-utils"
 
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/bucketpolicy"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/bucketregion"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/endpointfailover"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/endpointprobe"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/mountaudit"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/mounter"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/prefixmarker"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/targetpath"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecondition"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumestats"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mounterror"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod/watcher"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/util"
 )
 
@@ -63,6 +83,45 @@ const (
 type S3NodeServer struct {
 	NodeID  string
 	Mounter mounter.Mounter
+	// VolumeStats serves `NodeGetVolumeStats` calls, nil if volume statistics are disabled
+	VolumeStats *volumestats.Provider
+	// VolumeCondition reports the health of volumes in `NodeGetVolumeStats` calls, nil if volume conditions are disabled
+	VolumeCondition *volumecondition.Checker
+	// AWSCompatibilityMode translates volume attributes written for the AWS CSI Driver, see [volumecontext.TranslateAWSAliases]
+	AWSCompatibilityMode bool
+	// DiagnosticMountNamespace is the only namespace where Pods can use diagnostic mounts, see [volumecontext.Diagnostic].
+	// Diagnostic mounts are rejected if empty.
+	DiagnosticMountNamespace string
+	// EphemeralVolumes enables inline ephemeral volumes other than diagnostic mounts, see [volumecontext.SecretName].
+	EphemeralVolumes bool
+	// Secrets reads credentials of inline ephemeral volumes from the namespace of their Pods.
+	Secrets typedcorev1.SecretsGetter
+	// WorkloadPods reads the fsGroup of workload Pods to mount their volumes with a matching group when kubelet does
+	// not pass it, nil if fsGroups are only taken from kubelet.
+	WorkloadPods typedcorev1.PodsGetter
+	// BucketPolicy restricts the buckets Pods can mount depending on their namespace, nil if mounts are not restricted.
+	BucketPolicy *bucketpolicy.FileLoader
+	// MountTable lists the mounts of the node to refuse nested S3 volumes, nil if nesting is not checked.
+	MountTable MountLister
+	// Stager mounts volumes once per node in `NodeStageVolume`, nil if volume staging is disabled and volumes are
+	// mounted in `NodePublishVolume`.
+	Stager mounter.Stager
+	// EndpointProber tells mount failures due to an unreachable S3 endpoint from credential errors, reported as
+	// events on workload Pods with [Events]. Mount failures are not reported if nil.
+	EndpointProber *endpointprobe.Prober
+	// RegionResolver discovers the region of buckets mounted without `--region`, nil if regions are not discovered.
+	RegionResolver *bucketregion.Resolver
+	// PrefixMarker ensures prefixes of volumes with [volumecontext.EnsurePrefix] exist before mounting them.
+	PrefixMarker *prefixmarker.Marker
+	// EndpointFailover mounts volumes with the first reachable of their endpoints, nil if endpoints are not selected.
+	EndpointFailover *endpointfailover.Selector
+	// MountHealth remounts volumes once their Mountpoint container is restarted for an unresponsive mount, nil if
+	// mount health checks are disabled.
+	MountHealth *mounter.MountHealthChecker
+	// Events records events on workload Pods.
+	Events record.EventRecorder
+	// MountAudit records mounts and unmounts of volumes for compliance, nil if they are not audited.
+	MountAudit *mountaudit.Log
 
 	// Embed the unimplemented server to satisfy the interface
 	csi.UnimplementedNodeServer
@@ -72,16 +131,144 @@ func NewS3NodeServer(nodeID string, mounter mounter.Mounter) *S3NodeServer {
 	return &S3NodeServer{NodeID: nodeID, Mounter: mounter}
 }
 
+// NodeStageVolume mounts the volume at its staging path when volume staging is enabled, it is then bind-mounted to
+// the targets of all Pods using the volume on the node by `NodePublishVolume`.
 func (ns *S3NodeServer) NodeStageVolume(ctx context.Context, req *csi.NodeStageVolumeRequest) (*csi.NodeStageVolumeResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "")
+	if ns.Stager == nil {
+		return nil, status.Error(codes.Unimplemented, "")
+	}
+	klog.V(4).Infof("NodeStageVolume: new request: %s", protosanitizer.StripSecrets(req))
+
+	volumeID := req.GetVolumeId()
+	if len(volumeID) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Volume ID not provided")
+	}
+
+	stagingTarget := req.GetStagingTargetPath()
+	if len(stagingTarget) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Staging target path not provided")
+	}
+
+	volumeCtx := req.GetVolumeContext()
+	if ns.AWSCompatibilityMode {
+		var warnings []string
+		var err error
+		volumeCtx, warnings, err = volumecontext.TranslateAWSAliases(volumeCtx)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Invalid volume attributes: %v", err)
+		}
+		for _, warning := range warnings {
+			klog.Warningf("NodeStageVolume: volume %s: %s", volumeID, warning)
+		}
+	}
+
+	if size := volumecontext.Size(volumeCtx); size > volumecontext.MaxSize {
+		return nil, status.Errorf(codes.InvalidArgument, "Volume context is too large: %d bytes, maximum is %d bytes", size, volumecontext.MaxSize)
+	}
+	if err := ns.validateVolumeContext("NodeStageVolume", volumeID, volumeCtx); err != nil {
+		return nil, err
+	}
+	if err := checkRoleARN(volumeCtx); err != nil {
+		return nil, err
+	}
+
+	bucket, ok := volumeCtx[volumecontext.BucketName]
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "Bucket name not provided")
+	}
+	bucket, err := addressedBucket(volumeCtx, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	volCap := req.GetVolumeCapability()
+	if volCap == nil {
+		return nil, status.Error(codes.InvalidArgument, "Volume capability not provided")
+	}
+	if !ns.isValidVolumeCapabilities([]*csi.VolumeCapability{volCap}) {
+		return nil, status.Error(codes.InvalidArgument, "Volume capability not supported")
+	}
+
+	args, fsGroup, err := mountpointArgs(volumeCtx, volCap, false, false, false)
+	if err != nil {
+		return nil, err
+	}
+
+	klog.V(4).Infof("NodeStageVolume: mounting %s at %s with options %v", bucket, stagingTarget, args.SortedList())
+
+	bucketRegion, _ := args.Value(mountpoint.ArgRegion)
+	credentialCtx := credentialprovider.ProvideContext{
+		VolumeID:             volumeID,
+		AuthenticationSource: volumeCtx[volumecontext.AuthenticationSource],
+		BucketRegion:         bucketRegion,
+		SecretData:           req.GetSecrets(),
+		RoleARN:              volumeCtx[volumecontext.RoleARN],
+		CredentialsName:      volumeCtx[volumecontext.CredentialsName],
+
+		ServiceAccountTokenAudience: volumeCtx[volumecontext.ServiceAccountTokenAudience],
+	}
+	credentialCtx.CABundleSecret, err = caBundleSecret(volumeCtx, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyVolumeSecret(volumeCtx, &args, &credentialCtx); err != nil {
+		return nil, err
+	}
+	if err := ns.discoverRegion(ctx, bucket, &args, &credentialCtx); err != nil {
+		return nil, err
+	}
+	if _, err := ns.selectEndpoint(ctx, volumeCtx, &args); err != nil {
+		return nil, err
+	}
+	if err := checkAddressing(bucket, args); err != nil {
+		return nil, err
+	}
+	if err := ns.ensurePrefix(ctx, volumeCtx, bucket, args, credentialCtx); err != nil {
+		return nil, err
+	}
+
+	if err := ns.Stager.Stage(ctx, bucket, stagingTarget, credentialCtx, args, fsGroup); err != nil {
+		return nil, status.Errorf(mountErrorCode(err), "Could not mount %q at %q: %v", bucket, stagingTarget, err)
+	}
+	klog.V(4).Infof("NodeStageVolume: %s was mounted", stagingTarget)
+
+	return &csi.NodeStageVolumeResponse{}, nil
 }
 
+// NodeUnstageVolume unmounts the staging path of the volume when volume staging is enabled.
 func (ns *S3NodeServer) NodeUnstageVolume(ctx context.Context, req *csi.NodeUnstageVolumeRequest) (*csi.NodeUnstageVolumeResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "")
+	if ns.Stager == nil {
+		return nil, status.Error(codes.Unimplemented, "")
+	}
+	klog.V(4).Infof("NodeUnstageVolume: called with args %s", protosanitizer.StripSecrets(req))
+
+	if len(req.GetVolumeId()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Volume ID not provided")
+	}
+
+	stagingTarget := req.GetStagingTargetPath()
+	if len(stagingTarget) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Staging target path not provided")
+	}
+
+	if err := ns.Stager.Unstage(ctx, stagingTarget); err != nil {
+		return nil, status.Errorf(codes.Internal, "Could not unmount %q: %v", stagingTarget, err)
+	}
+	klog.V(4).Infof("NodeUnstageVolume: %s was unmounted", stagingTarget)
+
+	return &csi.NodeUnstageVolumeResponse{}, nil
 }
 
 func (ns *S3NodeServer) NodePublishVolume(ctx context.Context, req *csi.NodePublishVolumeRequest) (*csi.NodePublishVolumeResponse, error) {
-	klog.V(4).Infof("NodePublishVolume: new request: %s", protosanitizer.StripSecrets(req))
+	resp, err := ns.publishVolume(ctx, req)
+	ns.auditPublish(req, err)
+	return resp, err
+}
+
+// publishVolume mounts the volume of `req` at its target, recorded by [S3NodeServer.NodePublishVolume] in the mount
+// audit log.
+func (ns *S3NodeServer) publishVolume(ctx context.Context, req *csi.NodePublishVolumeRequest) (*csi.NodePublishVolumeResponse, error) {
+	klog.V(4).Infof("NodePublishVolume: new request: %s", logSafePublishRequest(req))
 
 	volumeID := req.GetVolumeId()
 	if len(volumeID) == 0 {
@@ -89,6 +276,42 @@ func (ns *S3NodeServer) NodePublishVolume(ctx context.Context, req *csi.NodePubl
 	}
 
 	volumeCtx := req.GetVolumeContext()
+	if ns.AWSCompatibilityMode {
+		var warnings []string
+		var err error
+		volumeCtx, warnings, err = volumecontext.TranslateAWSAliases(volumeCtx)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Invalid volume attributes: %v", err)
+		}
+		for _, warning := range warnings {
+			klog.Warningf("NodePublishVolume: volume %s: %s", volumeID, warning)
+		}
+	}
+
+	if size := volumecontext.Size(volumeCtx); size > volumecontext.MaxSize {
+		return nil, status.Errorf(codes.InvalidArgument, "Volume context is too large: %d bytes, maximum is %d bytes", size, volumecontext.MaxSize)
+	}
+	if err := ns.validateVolumeContext("NodePublishVolume", volumeID, volumeCtx); err != nil {
+		return nil, err
+	}
+
+	ephemeral := volumecontext.IsEphemeral(volumeCtx)
+	diagnostic := ephemeral && volumecontext.IsDiagnostic(volumeCtx)
+	if diagnostic {
+		if err := ns.validateDiagnosticMount(volumeCtx); err != nil {
+			return nil, err
+		}
+	} else if ephemeral {
+		if !ns.EphemeralVolumes {
+			return nil, status.Errorf(codes.InvalidArgument, "Inline ephemeral volumes are disabled, only diagnostic mounts with %s=true are supported", volumecontext.Diagnostic)
+		}
+		if err := validateEphemeralVolume(volumeCtx); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkRoleARN(volumeCtx); err != nil {
+		return nil, err
+	}
 
 	bucket, ok := volumeCtx[volumecontext.BucketName]
 	if !ok {
@@ -109,61 +332,362 @@ func (ns *S3NodeServer) NodePublishVolume(ctx context.Context, req *csi.NodePubl
 		return nil, status.Error(codes.InvalidArgument, "Volume capability not provided")
 	}
 
-	if !ns.isValidVolumeCapabilities([]*csi.VolumeCapability{volCap}) {
+	// kubelet requests a single node writer access mode for ephemeral volumes, diagnostic mounts are always read-only
+	if !ephemeral && !ns.isValidVolumeCapabilities([]*csi.VolumeCapability{volCap}) {
 		return nil, status.Error(codes.InvalidArgument, "Volume capability not supported")
 	}
 
-	mountpointArgs := []string{}
-	if req.GetReadonly() || volCap.GetAccessMode().GetMode() == csi.VolumeCapability_AccessMode_MULTI_NODE_READER_ONLY {
-		mountpointArgs = append(mountpointArgs, mountpoint.ArgReadOnly)
+	// Staged volumes are mounted by NodeStageVolume, before their workload Pods are known
+	if ns.Stager == nil || ephemeral {
+		var err error
+		volCap, err = ns.workloadVolumeCapability(ctx, volumeCtx, volCap)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	if capMount := volCap.GetMount(); capMount != nil {
-		mountFlags := capMount.GetMountFlags()
-		mountpointArgs = append(mountpointArgs, mountFlags...)
+	args, fsGroup, err := mountpointArgs(volumeCtx, volCap, diagnostic || req.GetReadonly(), ephemeral, diagnostic)
+	if err != nil {
+		return nil, err
+	}
+	verifyProbe, err := volumecontext.ParseVerifyMount(volumeCtx)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid mount verification: %v", err)
 	}
 
-	args := mountpoint.ParseArgs(mountpointArgs)
+	addressed, err := addressedBucket(volumeCtx, bucket)
+	if err != nil {
+		return nil, err
+	}
 
-	fsGroup := ""
-	if capMount := volCap.GetMount(); capMount != nil {
-		if volumeMountGroup := capMount.GetVolumeMountGroup(); volumeMountGroup != "" {
-			fsGroup = volumeMountGroup
-			// We need to add the following flags to support fsGroup
-			// Only apply FSGroup defaults if gid is not already set in mount options
-			// This prevents conflicts when user has explicitly set gid in PV mountOptions
-			if !args.Has(mountpoint.ArgGid) {
-				args.SetIfAbsent(mountpoint.ArgGid, volumeMountGroup)
-				args.SetIfAbsent(mountpoint.ArgAllowOther, mountpoint.ArgNoValue)
-				args.SetIfAbsent(mountpoint.ArgDirMode, filePerm770)
-				args.SetIfAbsent(mountpoint.ArgFileMode, filePerm660)
+	// Diagnostic mounts are restricted to the driver's namespace, they are not subject to the namespace bucket policy
+	if ns.BucketPolicy != nil && !diagnostic {
+		if err := ns.checkBucketPolicy(volumeCtx, bucket, args); err != nil {
+			return nil, err
+		}
+		// The alias could address another bucket, it must be allowed too
+		if addressed != bucket {
+			if err := ns.checkBucketPolicy(volumeCtx, addressed, args); err != nil {
+				return nil, err
 			}
 		}
 	}
+	bucket = addressed
 
-	if !args.Has(mountpoint.ArgAllowOther) {
-		// If customer container is running as root we need to add --allow-root as Mountpoint Pod is not run as root
-		// This is needed for both systemd and pod mounter for consistency
-		args.SetIfAbsent(mountpoint.ArgAllowRoot, mountpoint.ArgNoValue)
+	if ns.MountTable != nil {
+		if err := ns.checkNotNested(target); err != nil {
+			return nil, err
+		}
 	}
 
-	// Ensure path-style addressing is used by default unless the caller already
-	// specified it explicitly.
-	args.SetIfAbsent(mountpoint.ArgForcePathStyle, mountpoint.ArgNoValue)
+	// Inline ephemeral volumes are not staged by kubelet, they are always mounted at their target
+	if ns.Stager != nil && !ephemeral {
+		stagingTarget := req.GetStagingTargetPath()
+		if len(stagingTarget) == 0 {
+			return nil, status.Error(codes.InvalidArgument, "Staging target path not provided")
+		}
 
-	klog.V(4).Infof("NodePublishVolume: mounting %s at %s with options %v", bucket, target, args.SortedList())
+		klog.V(4).Infof("NodePublishVolume: bind mounting staging path %s at %s", stagingTarget, target)
+		if err := ns.Stager.Publish(ctx, stagingTarget, target, volumeID, req.GetReadonly()); err != nil {
+			_ = os.Remove(target)
+			return nil, status.Errorf(codes.Internal, "Could not mount %q at %q: %v", bucket, target, err)
+		}
+	} else {
+		klog.V(4).Infof("NodePublishVolume: mounting %s at %s with options %v", bucket, target, args.SortedList())
 
-	credentialCtx := credentialProvideContextFromPublishRequest(req, args)
+		credentialCtx := credentialProvideContextFromPublishRequest(req, volumeCtx, args)
+		credentialCtx.CABundleSecret, err = caBundleSecret(volumeCtx, ephemeral)
+		if err != nil {
+			return nil, err
+		}
+		if ephemeral && !diagnostic {
+			if err := ns.provideEphemeralVolumeSecret(ctx, volumeCtx, &credentialCtx); err != nil {
+				return nil, err
+			}
+		}
+		if err := applyVolumeSecret(volumeCtx, &args, &credentialCtx); err != nil {
+			return nil, err
+		}
+		if err := ns.discoverRegion(ctx, bucket, &args, &credentialCtx); err != nil {
+			return nil, err
+		}
+		endpointURLs, err := ns.selectEndpoint(ctx, volumeCtx, &args)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkAddressing(bucket, args); err != nil {
+			return nil, err
+		}
+		if err := ns.ensurePrefix(ctx, volumeCtx, bucket, args, credentialCtx); err != nil {
+			return nil, err
+		}
+		ns.trackEndpoint(endpointURLs, bucket, target, credentialCtx, args, fsGroup)
+		ns.trackMountHealth(bucket, target, credentialCtx, args, fsGroup)
 
-	if err := ns.Mounter.Mount(ctx, bucket, target, credentialCtx, args, fsGroup); err != nil {
-		_ = os.Remove(target)
-		return nil, status.Errorf(codes.Internal, "Could not mount %q at %q: %v", bucket, target, err)
+		if err := ns.Mounter.Mount(ctx, bucket, target, credentialCtx, args, fsGroup); err != nil {
+			if ns.EndpointFailover != nil {
+				ns.EndpointFailover.Untrack(target)
+			}
+			if ns.MountHealth != nil {
+				ns.MountHealth.Untrack(target)
+			}
+			_ = os.Remove(target)
+			ns.reportMountFailure(volumeCtx, bucket, err)
+			return nil, status.Errorf(mountErrorCode(err), "Could not mount %q at %q: %v", bucket, target, err)
+		}
 	}
 	klog.V(4).Infof("NodePublishVolume: %s was mounted", target)
 
+	if err := ns.verifyMount(ctx, volumeID, bucket, target, verifyProbe); err != nil {
+		return nil, err
+	}
+
+	// Statistics are computed with the driver-level endpoint, they would be wrong for volumes using another endpoint
+	if ns.VolumeStats != nil && !args.Has(mountpoint.ArgEndpointURL) {
+		prefix, _ := args.Value(mountpoint.ArgPrefix)
+		ns.VolumeStats.Register(target, volumestats.Volume{Bucket: bucket, Prefix: prefix})
+	}
+
 	return &csi.NodePublishVolumeResponse{}, nil
 }
 
+// mountpointArgs returns the Mountpoint arguments and the fsGroup to mount a volume with capability `volCap` and
+// context `volumeCtx`.
+func mountpointArgs(volumeCtx map[string]string, volCap *csi.VolumeCapability, readOnly, ephemeral, diagnostic bool) (mountpoint.Args, string, error) {
+	var mountOptions []string
+	fsGroup := ""
+	if capMount := volCap.GetMount(); capMount != nil {
+		mountOptions = capMount.GetMountFlags()
+		if length := len(strings.Join(mountOptions, ",")); length > crdv2.MaxMountOptionsLength {
+			return mountpoint.Args{}, "", status.Errorf(codes.InvalidArgument, "Mount options are too long: %d bytes, maximum is %d bytes", length, crdv2.MaxMountOptionsLength)
+		}
+		fsGroup = capMount.GetVolumeMountGroup()
+	}
+
+	encryption, err := volumecontext.ParseEncryption(volumeCtx)
+	if err != nil {
+		return mountpoint.Args{}, "", status.Errorf(codes.InvalidArgument, "Invalid server-side encryption: %v", err)
+	}
+	performance, err := volumecontext.ParsePerformanceProfile(volumeCtx)
+	if err != nil {
+		return mountpoint.Args{}, "", status.Errorf(codes.InvalidArgument, "Invalid performance profile: %v", err)
+	}
+	dualAuth, err := volumecontext.ParseDualAuth(volumeCtx)
+	if err != nil {
+		return mountpoint.Args{}, "", status.Errorf(codes.InvalidArgument, "Invalid dual-auth volume: %v", err)
+	}
+	// Log files are written to a volume of the Mountpoint Pod, its `--log-directory` is set by the mounter
+	logging, err := volumecontext.ParseLogging(volumeCtx)
+	if err != nil {
+		return mountpoint.Args{}, "", status.Errorf(codes.InvalidArgument, "Invalid logging: %v", err)
+	}
+	addressingStyle, err := volumecontext.ParseAddressingStyle(volumeCtx)
+	if err != nil {
+		return mountpoint.Args{}, "", status.Errorf(codes.InvalidArgument, "Invalid addressing style: %v", err)
+	}
+
+	builder := mountpoint.NewArgsBuilder(mountOptions)
+	if readOnly || volCap.GetAccessMode().GetMode() == csi.VolumeCapability_AccessMode_MULTI_NODE_READER_ONLY {
+		builder.WithReadOnly()
+	}
+
+	// Endpoint overrides not allowed by the driver configuration are stripped by the mounter, like `--endpoint-url`
+	// in mount options
+	builder.WithEndpointURL(volumeCtx[volumecontext.EndpointURL])
+
+	if encryption.SSE != "" {
+		builder.Exclusive("Server-side encryption", "the "+volumecontext.ServerSideEncryption+" volume attribute", mountpoint.ArgSSE, mountpoint.ArgSSEKMSKeyID).
+			WithSSE(encryption.SSE, encryption.KMSKeyID)
+	}
+	if performance.Profile != "" {
+		// `--max-threads` is lowered under node memory pressure like in mount options
+		builder.Exclusive("Metadata caching and concurrency", "the "+volumecontext.PerformanceProfile+" volume attribute", mountpoint.ArgMetadataTTL, mountpoint.ArgAllowOverwrite, mountpoint.ArgMaxThreads).
+			WithMetadataTTL(performance.MetadataTTL).
+			WithAllowOverwrite(performance.AllowOverwrite).
+			WithMaxThreads(performance.MaxThreads)
+	}
+
+	switch dualAuth {
+	case volumecontext.DualAuthRead:
+		// Reads are made with the broad identity, which must never be used to write
+		builder.WithReadOnly()
+	case volumecontext.DualAuthWrite:
+		if builder.Has(mountpoint.ArgReadOnly) {
+			return mountpoint.Args{}, "", status.Errorf(codes.InvalidArgument, "Invalid dual-auth volume: %s: %s cannot be mounted read-only, mount the %s side instead", volumecontext.DualAuth, volumecontext.DualAuthWrite, volumecontext.DualAuthRead)
+		}
+	}
+
+	if ephemeral {
+		builder.WithPrefix(volumeCtx[volumecontext.Prefix])
+	}
+	// Diagnostic mounts log verbosely to help finding why a bucket cannot be mounted
+	if diagnostic || logging.Verbose() {
+		builder.WithDebug(logging.Verbose() && !logging.FiltersCRTDebug())
+	}
+	if logging.Level != volumecontext.LogLevelInfo && logging.Level != volumecontext.LogLevelDebug {
+		// Mountpoint only has `--debug` to change its log level, others are set in its environment by the mounter
+		builder.WithLogLevel(logging.Level)
+	}
+
+	// fsGroup defaults are not applied if `--gid` is set in mount options, to not conflict with it
+	builder.WithFSGroup(fsGroup, filePerm770, filePerm660)
+	// If customer container is running as root we need to add --allow-root as Mountpoint Pod is not run as root
+	// This is needed for both systemd and pod mounter for consistency
+	builder.WithAllowRoot()
+
+	if addressingStyle == volumecontext.AddressingStyleVirtual {
+		builder.WithVirtualHostedStyle("the " + volumecontext.AddressingStyle + " volume attribute")
+	} else {
+		// Ensure path-style addressing is used by default unless the caller already
+		// specified it explicitly.
+		builder.WithPathStyle()
+	}
+
+	args, err := builder.Build()
+	if err != nil {
+		return mountpoint.Args{}, "", status.Errorf(codes.InvalidArgument, "Invalid mount options: %v", err)
+	}
+	return args, fsGroup, nil
+}
+
+// checkRoleARN returns an error if the volume with `volumeCtx` assumes a role with driver-level credentials that is not
+// allowed by [credentialprovider.EnvAllowedRoleARNs].
+func checkRoleARN(volumeCtx map[string]string) error {
+	if credentialprovider.AuthenticationSource(volumeCtx[volumecontext.AuthenticationSource]) != credentialprovider.AuthenticationSourceRole {
+		return nil
+	}
+	if roleARN := volumeCtx[volumecontext.RoleARN]; roleARN != "" && !credentialprovider.IsAllowedRoleARN(roleARN) {
+		return status.Errorf(codes.PermissionDenied, "Role %s cannot be assumed: it is not in the driver's allowed role ARNs", roleARN)
+	}
+	return nil
+}
+
+// checkBucketPolicy returns an error if the namespace bucket policy does not allow the Pod to mount `bucket`
+// with the prefix in `args`.
+func (ns *S3NodeServer) checkBucketPolicy(volumeCtx map[string]string, bucket string, args mountpoint.Args) error {
+	namespace := volumeCtx[volumecontext.CSIPodNamespace]
+	if namespace == "" {
+		return status.Error(codes.FailedPrecondition, "Pod namespace not provided, the namespace bucket policy requires podInfoOnMount")
+	}
+	policy, err := ns.BucketPolicy.Policy()
+	if err != nil {
+		return status.Errorf(codes.FailedPrecondition, "Could not check the namespace bucket policy: %v", err)
+	}
+	prefix, _ := args.Value(mountpoint.ArgPrefix)
+	if err := policy.Check(namespace, bucket, prefix); err != nil {
+		return status.Errorf(codes.PermissionDenied, "Mount rejected by the namespace bucket policy: %v", err)
+	}
+	return nil
+}
+
+// Reasons of events recorded on workload Pods for failures of Mountpoint classified in `mount.err`, in addition to
+// the reasons of [endpointprobe].
+const (
+	ReasonS3AccessDenied         = "S3AccessDenied"
+	ReasonS3BucketNotFound       = "S3BucketNotFound"
+	ReasonS3TLSError             = "S3TLSError"
+	ReasonMountpointBinaryFailed = "MountpointBinaryFailed"
+	ReasonMountOptionUnsupported = "MountOptionUnsupported"
+)
+
+// reportMountFailure records an event on the workload Pod of `volumeCtx` if the cause of mount failure `err` is
+// known, kubelet only reports the raw error. The cause is the classification of the Mountpoint failure, or the
+// result of the S3 endpoint probe.
+func (ns *S3NodeServer) reportMountFailure(volumeCtx map[string]string, bucket string, err error) {
+	if ns.Events == nil || volumeCtx[volumecontext.CSIPodName] == "" {
+		return
+	}
+	pod := workloadPodRef(volumeCtx)
+
+	reason := ""
+	var mountErr *mounterror.Error
+	if errors.As(err, &mountErr) {
+		reason = mountFailureReasons[mountErr.Classification]
+	}
+	if reason == "" && ns.EndpointProber != nil {
+		reason = ns.EndpointProber.MountFailureReason(err)
+	}
+
+	switch reason {
+	case endpointprobe.ReasonEndpointUnreachable:
+		ns.Events.Eventf(pod, corev1.EventTypeWarning, reason, "Could not mount bucket %q, the S3 endpoint is unreachable from node %s", bucket, ns.NodeID)
+	case endpointprobe.ReasonCredentialsRejected:
+		ns.Events.Eventf(pod, corev1.EventTypeWarning, reason, "Could not mount bucket %q, the S3 endpoint rejected the credentials of the volume", bucket)
+	case ReasonS3AccessDenied:
+		ns.Events.Eventf(pod, corev1.EventTypeWarning, reason, "Could not mount bucket %q, the credentials of the volume are not allowed to access it", bucket)
+	case ReasonS3BucketNotFound:
+		ns.Events.Eventf(pod, corev1.EventTypeWarning, reason, "Could not mount bucket %q, it does not exist on the S3 endpoint", bucket)
+	case ReasonS3TLSError:
+		ns.Events.Eventf(pod, corev1.EventTypeWarning, reason, "Could not mount bucket %q, the TLS certificate of the S3 endpoint is not trusted: %s", bucket, mountErr.Output)
+	case ReasonMountpointBinaryFailed:
+		ns.Events.Eventf(pod, corev1.EventTypeWarning, reason, "Could not mount bucket %q, Mountpoint cannot run on node %s: %s", bucket, ns.NodeID, mountErr.Output)
+	case ReasonMountOptionUnsupported:
+		ns.Events.Eventf(pod, corev1.EventTypeWarning, reason, "Could not mount bucket %q, %s", bucket, mountErr.Output)
+	}
+}
+
+// workloadPodRef returns a reference to the workload Pod of `volumeCtx`, to record events on it.
+func workloadPodRef(volumeCtx map[string]string) *corev1.ObjectReference {
+	return &corev1.ObjectReference{
+		Kind:       "Pod",
+		APIVersion: "v1",
+		Namespace:  volumeCtx[volumecontext.CSIPodNamespace],
+		Name:       volumeCtx[volumecontext.CSIPodName],
+		UID:        types.UID(volumeCtx[volumecontext.CSIPodUID]),
+	}
+}
+
+// mountFailureReasons are the reasons of events for classified failures of Mountpoint.
+var mountFailureReasons = map[mounterror.Classification]string{
+	mounterror.ClassificationEndpoint:          endpointprobe.ReasonEndpointUnreachable,
+	mounterror.ClassificationCredentials:       endpointprobe.ReasonCredentialsRejected,
+	mounterror.ClassificationAccessDenied:      ReasonS3AccessDenied,
+	mounterror.ClassificationBucketNotFound:    ReasonS3BucketNotFound,
+	mounterror.ClassificationTLS:               ReasonS3TLSError,
+	mounterror.ClassificationBinary:            ReasonMountpointBinaryFailed,
+	mounterror.ClassificationUnsupportedOption: ReasonMountOptionUnsupported,
+}
+
+// mountFailureCodes are the gRPC codes of classified failures of Mountpoint.
+var mountFailureCodes = map[mounterror.Classification]codes.Code{
+	mounterror.ClassificationEndpoint:          codes.Unavailable,
+	mounterror.ClassificationCredentials:       codes.Unauthenticated,
+	mounterror.ClassificationAccessDenied:      codes.PermissionDenied,
+	mounterror.ClassificationBucketNotFound:    codes.NotFound,
+	mounterror.ClassificationTLS:               codes.FailedPrecondition,
+	mounterror.ClassificationBinary:            codes.FailedPrecondition,
+	mounterror.ClassificationUnsupportedOption: codes.InvalidArgument,
+}
+
+// mountErrorCode returns the gRPC code of mount failure `err`. Mountpoint Pods that cannot start are reported with
+// their cause, to tell missing capacity from misconfigurations like untolerated taints or wrong images. Failures of
+// Mountpoint are reported with their classification, and other mount phases that timed out are reported as such.
+func mountErrorCode(err error) codes.Code {
+	var stuck *mppod.Stuck
+	if errors.As(err, &stuck) {
+		if stuck.Cause == mppod.StuckInsufficientResources {
+			return codes.ResourceExhausted
+		}
+		return codes.FailedPrecondition
+	}
+	var mountErr *mounterror.Error
+	if errors.As(err, &mountErr) {
+		if code, ok := mountFailureCodes[mountErr.Classification]; ok {
+			return code
+		}
+		return codes.Internal
+	}
+	// Kubelet retries the mount once the API server is available again
+	if errors.Is(err, watcher.ErrAPIServerUnavailable) {
+		return codes.Unavailable
+	}
+	var timeoutErr *mounter.PhaseTimeoutError
+	if errors.As(err, &timeoutErr) {
+		return codes.DeadlineExceeded
+	}
+	return codes.Internal
+}
+
 func (ns *S3NodeServer) NodeUnpublishVolume(ctx context.Context, req *csi.NodeUnpublishVolumeRequest) (*csi.NodeUnpublishVolumeResponse, error) {
 	klog.V(4).Infof("NodeUnpublishVolume: called with args %s", protosanitizer.StripSecrets(req))
 
@@ -192,35 +716,118 @@ func (ns *S3NodeServer) NodeUnpublishVolume(ctx context.Context, req *csi.NodeUn
 		return &csi.NodeUnpublishVolumeResponse{}, nil
 	}
 
+	if ns.MountTable != nil {
+		if err := ns.checkNoNestedMounts(target); err != nil {
+			return nil, err
+		}
+	}
+
 	credentialCtx := credentialCleanupContextFromUnpublishRequest(req)
 
+	// Do not remount the volume while it is unmounted
+	if ns.EndpointFailover != nil {
+		ns.EndpointFailover.Untrack(target)
+	}
+	if ns.MountHealth != nil {
+		ns.MountHealth.Untrack(target)
+	}
+
 	klog.V(4).Infof("NodeUnpublishVolume: unmounting %s", target)
 	err = ns.Mounter.Unmount(ctx, target, credentialCtx)
+	ns.auditUnpublish(req, err)
+	if errors.Is(err, mounter.ErrTargetBusy) {
+		return nil, status.Errorf(codes.FailedPrecondition, "Could not unmount %q: %v", target, err)
+	}
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "Could not unmount %q: %v", target, err)
 	}
 
+	if ns.VolumeStats != nil {
+		ns.VolumeStats.Unregister(target)
+	}
+
 	return &csi.NodeUnpublishVolumeResponse{}, nil
 }
 
+// NodeGetVolumeStats reports the used bytes and the number of objects (as inodes) of the volume published at the given
+// path, and its condition if volume conditions are enabled. S3 has no capacity, so total and available values are not
+// reported, and usage is not reported for abnormal volumes.
 func (ns *S3NodeServer) NodeGetVolumeStats(ctx context.Context, req *csi.NodeGetVolumeStatsRequest) (*csi.NodeGetVolumeStatsResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "")
+	if ns.VolumeStats == nil && ns.VolumeCondition == nil {
+		return nil, status.Error(codes.Unimplemented, "")
+	}
+
+	if len(req.GetVolumeId()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Volume ID not provided")
+	}
+
+	volumePath := req.GetVolumePath()
+	if len(volumePath) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Volume path not provided")
+	}
+
+	resp := &csi.NodeGetVolumeStatsResponse{}
+	if ns.VolumeCondition != nil {
+		condition, err := ns.VolumeCondition.Check(ctx, volumePath)
+		if err != nil {
+			if errors.Is(err, volumecondition.ErrVolumeNotFound) {
+				return nil, status.Errorf(codes.NotFound, "Volume %q is not published at %q", req.GetVolumeId(), volumePath)
+			}
+			return nil, status.Errorf(codes.Internal, "Could not check condition of volume %q: %v", req.GetVolumeId(), err)
+		}
+		resp.VolumeCondition = &csi.VolumeCondition{Abnormal: condition.Abnormal, Message: condition.Message}
+		if condition.Abnormal {
+			klog.Warningf("NodeGetVolumeStats: Volume %q published at %q is abnormal: %s", req.GetVolumeId(), volumePath, condition.Message)
+			return resp, nil
+		}
+	}
+	if ns.VolumeStats == nil {
+		return resp, nil
+	}
+
+	usage, err := ns.VolumeStats.Usage(ctx, volumePath)
+	if err != nil {
+		if errors.Is(err, volumestats.ErrVolumeNotFound) {
+			return nil, status.Errorf(codes.NotFound, "Volume %q is not published at %q", req.GetVolumeId(), volumePath)
+		}
+		return nil, status.Errorf(codes.Internal, "Could not get stats of volume %q: %v", req.GetVolumeId(), err)
+	}
+
+	resp.Usage = []*csi.VolumeUsage{
+		{Unit: csi.VolumeUsage_BYTES, Used: usage.UsedBytes},
+		{Unit: csi.VolumeUsage_INODES, Used: usage.Objects},
+	}
+	return resp, nil
 }
 
 func (ns *S3NodeServer) NodeExpandVolume(ctx context.Context, req *csi.NodeExpandVolumeRequest) (*csi.NodeExpandVolumeResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "")
 }
 
+// NodeCapabilities returns the capabilities advertised by the node service, depending on whether it mounts
+// volumes with Mountpoint Pods, whether volume statistics and conditions are enabled and whether volumes are staged.
+func NodeCapabilities(podMounter, volumeStats, volumeCondition, staging bool) []csi.NodeServiceCapability_RPC_Type {
+	nodeCaps := systemdNodeCaps
+	if podMounter {
+		nodeCaps = podMounterNodeCaps
+	}
+	nodeCaps = slices.Clone(nodeCaps)
+	if volumeStats || volumeCondition {
+		nodeCaps = append(nodeCaps, csi.NodeServiceCapability_RPC_GET_VOLUME_STATS)
+	}
+	if volumeCondition {
+		nodeCaps = append(nodeCaps, csi.NodeServiceCapability_RPC_VOLUME_CONDITION)
+	}
+	if staging {
+		nodeCaps = append(nodeCaps, csi.NodeServiceCapability_RPC_STAGE_UNSTAGE_VOLUME)
+	}
+	return nodeCaps
+}
+
 func (ns *S3NodeServer) NodeGetCapabilities(ctx context.Context, req *csi.NodeGetCapabilitiesRequest) (*csi.NodeGetCapabilitiesResponse, error) {
 	klog.V(4).Infof("NodeGetCapabilities: called with args %s", protosanitizer.StripSecrets(req))
 	var caps []*csi.NodeServiceCapability
-	var nodeCaps []csi.NodeServiceCapability_RPC_Type
-	if util.UsePodMounter() {
-		nodeCaps = podMounterNodeCaps
-	} else {
-		nodeCaps = systemdNodeCaps
-	}
-	for _, cap := range nodeCaps {
+	for _, cap := range NodeCapabilities(util.UsePodMounter(), ns.VolumeStats != nil, ns.VolumeCondition != nil, ns.Stager != nil) {
 		c := &csi.NodeServiceCapability{
 			Type: &csi.NodeServiceCapability_Rpc{
 				Rpc: &csi.NodeServiceCapability_RPC{
@@ -241,6 +848,21 @@ func (ns *S3NodeServer) NodeGetInfo(ctx context.Context, req *csi.NodeGetInfoReq
 	}, nil
 }
 
+// validateDiagnosticMount checks that an inline ephemeral volume is a diagnostic mount allowed on this node.
+// Diagnostic mounts are restricted to a single namespace, as they can mount any bucket with driver-level credentials.
+func (ns *S3NodeServer) validateDiagnosticMount(volumeCtx map[string]string) error {
+	if !volumecontext.IsDiagnostic(volumeCtx) {
+		return status.Errorf(codes.InvalidArgument, "Inline ephemeral volumes are only supported for diagnostic mounts with %s=true", volumecontext.Diagnostic)
+	}
+	if ns.DiagnosticMountNamespace == "" {
+		return status.Error(codes.FailedPrecondition, "Diagnostic mounts are disabled")
+	}
+	if podNamespace := volumeCtx[volumecontext.CSIPodNamespace]; podNamespace != ns.DiagnosticMountNamespace {
+		return status.Errorf(codes.PermissionDenied, "Diagnostic mounts are only allowed in namespace %q, not in %q", ns.DiagnosticMountNamespace, podNamespace)
+	}
+	return nil
+}
+
 func (ns *S3NodeServer) isValidVolumeCapabilities(volCaps []*csi.VolumeCapability) bool {
 	hasSupport := func(cap *csi.VolumeCapability) bool {
 		for _, c := range volumeCaps {
@@ -260,9 +882,7 @@ func (ns *S3NodeServer) isValidVolumeCapabilities(volCaps []*csi.VolumeCapabilit
 	return foundAll
 }
 
-func credentialProvideContextFromPublishRequest(req *csi.NodePublishVolumeRequest, args mountpoint.Args) credentialprovider.ProvideContext {
-	volumeCtx := req.GetVolumeContext()
-
+func credentialProvideContextFromPublishRequest(req *csi.NodePublishVolumeRequest, volumeCtx map[string]string, args mountpoint.Args) credentialprovider.ProvideContext {
 	podID := volumeCtx[volumecontext.CSIPodUID]
 	if podID == "" {
 		podID, _ = podIDFromTargetPath(req.GetTargetPath())
@@ -275,9 +895,26 @@ func credentialProvideContextFromPublishRequest(req *csi.NodePublishVolumeReques
 		VolumeID:             req.GetVolumeId(),
 		AuthenticationSource: volumeCtx[volumecontext.AuthenticationSource],
 		PodNamespace:         volumeCtx[volumecontext.CSIPodNamespace],
+		PodName:              volumeCtx[volumecontext.CSIPodName],
 		BucketRegion:         bucketRegion,
 		SecretData:           req.GetSecrets(),
+		RoleARN:              volumeCtx[volumecontext.RoleARN],
+		CredentialsName:      volumeCtx[volumecontext.CredentialsName],
+
+		ServiceAccountName:          volumeCtx[volumecontext.CSIServiceAccountName],
+		ServiceAccountTokens:        volumeCtx[volumecontext.CSIServiceAccountTokens],
+		ServiceAccountTokenAudience: volumeCtx[volumecontext.ServiceAccountTokenAudience],
+	}
+}
+
+// logSafePublishRequest returns `req` to be logged, without its secrets and the service account tokens of its Pod.
+func logSafePublishRequest(req *csi.NodePublishVolumeRequest) fmt.Stringer {
+	if _, ok := req.GetVolumeContext()[volumecontext.CSIServiceAccountTokens]; !ok {
+		return protosanitizer.StripSecrets(req)
 	}
+	safe := proto.Clone(req).(*csi.NodePublishVolumeRequest)
+	safe.VolumeContext[volumecontext.CSIServiceAccountTokens] = "***stripped***"
+	return protosanitizer.StripSecrets(safe)
 }
 
 func credentialCleanupContextFromUnpublishRequest(req *csi.NodeUnpublishVolumeRequest) credentialprovider.CleanupContext {
@@ -2,19 +2,51 @@ package node_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 	csi "github.com/container-storage-interface/spec/lib/go/csi"
 	"github.com/golang/mock/gomock"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/bucketpolicy"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/bucketregion"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/endpointfailover"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/endpointprobe"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/mountaudit"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/mounter"
 	mock_driver "github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/mounter/mocks"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/prefixmarker"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecondition"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumestats"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mounterror"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
 )
 
 type nodeServerTestEnv struct {
@@ -195,6 +227,488 @@ func TestNodePublishVolume(t *testing.T) {
 				nodeTestEnv.mockCtl.Finish()
 			},
 		},
+		{
+			name: "success: endpoint URL volume attribute is passed as mount option",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId:         volumeId,
+					VolumeCapability: stdVolCap,
+					TargetPath:       targetPath,
+					VolumeContext: map[string]string{
+						"bucketName":  bucketName,
+						"endpointUrl": "https://s3.site-b.example.com",
+					},
+				}
+
+				nodeTestEnv.mockMounter.EXPECT().Mount(
+					gomock.Eq(context.Background()),
+					gomock.Eq(bucketName),
+					gomock.Eq(targetPath),
+					gomock.Eq(credentialprovider.ProvideContext{
+						VolumeID: volumeId,
+					}),
+					gomock.Eq(mountpoint.ParseArgs([]string{"--endpoint-url=https://s3.site-b.example.com", "--allow-root", "--force-path-style"})),
+					gomock.Eq(""))
+				_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodePublishVolume is failed: %v", err)
+				}
+
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: CA bundle Secret defaults to the Pod's namespace",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId:         volumeId,
+					VolumeCapability: stdVolCap,
+					TargetPath:       targetPath,
+					VolumeContext: map[string]string{
+						"bucketName":                       bucketName,
+						"caBundleSecretRef":                "site-b-ca",
+						"csi.storage.k8s.io/pod.namespace": "team-a",
+					},
+				}
+
+				nodeTestEnv.mockMounter.EXPECT().Mount(
+					gomock.Eq(context.Background()),
+					gomock.Eq(bucketName),
+					gomock.Eq(targetPath),
+					gomock.Eq(credentialprovider.ProvideContext{
+						VolumeID:       volumeId,
+						PodNamespace:   "team-a",
+						CABundleSecret: types.NamespacedName{Namespace: "team-a", Name: "site-b-ca"},
+					}),
+					gomock.Eq(mountpoint.ParseArgs([]string{"--allow-root", "--force-path-style"})),
+					gomock.Eq(""))
+				_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodePublishVolume is failed: %v", err)
+				}
+
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: invalid CA bundle Secret reference",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				ctx := context.Background()
+				for _, ref := range []string{"site-b-ca", "storage/Site_B", "/site-b-ca"} {
+					req := &csi.NodePublishVolumeRequest{
+						VolumeId:         volumeId,
+						VolumeCapability: stdVolCap,
+						TargetPath:       targetPath,
+						VolumeContext: map[string]string{
+							"bucketName":        bucketName,
+							"caBundleSecretRef": ref,
+						},
+					}
+					_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+					if status.Code(err) != codes.InvalidArgument {
+						t.Fatalf("Expected InvalidArgument for %q, got %v", ref, err)
+					}
+				}
+
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: converts server-side encryption attributes into Mountpoint arguments",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId:         volumeId,
+					VolumeCapability: stdVolCap,
+					TargetPath:       targetPath,
+					VolumeContext: map[string]string{
+						"bucketName":           bucketName,
+						"serverSideEncryption": "SSE-KMS",
+						"sseKmsKeyId":          "arn:aws:kms:us-east-1:000000000000:key/volume",
+					},
+				}
+
+				nodeTestEnv.mockMounter.EXPECT().Mount(
+					gomock.Eq(context.Background()),
+					gomock.Eq(bucketName),
+					gomock.Eq(targetPath),
+					gomock.Eq(credentialprovider.ProvideContext{
+						VolumeID: volumeId,
+					}),
+					gomock.Eq(mountpoint.ParseArgs([]string{"--sse=aws:kms", "--sse-kms-key-id=arn:aws:kms:us-east-1:000000000000:key/volume", "--allow-root", "--force-path-style"})),
+					gomock.Eq(""))
+				_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodePublishVolume is failed: %v", err)
+				}
+
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: invalid server-side encryption",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				ctx := context.Background()
+				for _, tc := range []struct {
+					volumeCtx    map[string]string
+					mountOptions []string
+				}{
+					{volumeCtx: map[string]string{"serverSideEncryption": "SSE-C"}},
+					{volumeCtx: map[string]string{"sseCustomerKey": "c2VjcmV0"}},
+					{volumeCtx: map[string]string{"sseKmsKeyId": "volume"}},
+					{volumeCtx: map[string]string{"serverSideEncryption": "SSE-S3"}, mountOptions: []string{"sse=aws:kms"}},
+				} {
+					tc.volumeCtx["bucketName"] = bucketName
+					req := &csi.NodePublishVolumeRequest{
+						VolumeId: volumeId,
+						VolumeCapability: &csi.VolumeCapability{
+							AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{MountFlags: tc.mountOptions}},
+							AccessMode: stdVolCap.AccessMode,
+						},
+						TargetPath:    targetPath,
+						VolumeContext: tc.volumeCtx,
+					}
+					_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+					if status.Code(err) != codes.InvalidArgument {
+						t.Fatalf("Expected InvalidArgument for %v with mount options %v, got %v", tc.volumeCtx, tc.mountOptions, err)
+					}
+				}
+
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: converts logging attribute into Mountpoint arguments",
+			testFunc: func(t *testing.T) {
+				for _, tc := range []struct {
+					logging  string
+					wantArgs []string
+				}{
+					{logging: `{"level": "debug"}`, wantArgs: []string{"--debug"}},
+					{logging: `{"level": "debug", "filterCrtDebug": false}`, wantArgs: []string{"--debug", "--debug-crt"}},
+					{logging: `{"level": "trace", "destination": "file"}`, wantArgs: []string{"--debug", "--log-level=trace"}},
+					{logging: `{"level": "warn"}`, wantArgs: []string{"--log-level=warn"}},
+					{logging: `{"destination": "file"}`},
+				} {
+					nodeTestEnv := initNodeServerTestEnv(t)
+					req := &csi.NodePublishVolumeRequest{
+						VolumeId:         volumeId,
+						VolumeCapability: stdVolCap,
+						TargetPath:       targetPath,
+						VolumeContext: map[string]string{
+							"bucketName": bucketName,
+							"logging":    tc.logging,
+						},
+					}
+
+					nodeTestEnv.mockMounter.EXPECT().Mount(
+						gomock.Eq(context.Background()),
+						gomock.Eq(bucketName),
+						gomock.Eq(targetPath),
+						gomock.Eq(credentialprovider.ProvideContext{
+							VolumeID: volumeId,
+						}),
+						gomock.Eq(mountpoint.ParseArgs(append(tc.wantArgs, "--allow-root", "--force-path-style"))),
+						gomock.Eq(""))
+					if _, err := nodeTestEnv.server.NodePublishVolume(context.Background(), req); err != nil {
+						t.Fatalf("NodePublishVolume is failed for logging %s: %v", tc.logging, err)
+					}
+
+					nodeTestEnv.mockCtl.Finish()
+				}
+			},
+		},
+		{
+			name: "fail: invalid logging attribute",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId:         volumeId,
+					VolumeCapability: stdVolCap,
+					TargetPath:       targetPath,
+					VolumeContext: map[string]string{
+						"bucketName": bucketName,
+						"logging":    `{"level": "verbose"}`,
+					},
+				}
+				_, err := nodeTestEnv.server.NodePublishVolume(context.Background(), req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Expected InvalidArgument, got %v", err)
+				}
+
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: converts performance profile attribute into Mountpoint arguments",
+			testFunc: func(t *testing.T) {
+				for _, tc := range []struct {
+					performanceProfile string
+					wantArgs           []string
+				}{
+					{performanceProfile: `{}`, wantArgs: []string{"--metadata-ttl=minimal"}},
+					{performanceProfile: `{"profile": "throughput"}`, wantArgs: []string{"--metadata-ttl=60", "--max-threads=64"}},
+					{performanceProfile: `{"profile": "consistency", "allowOverwrite": true, "maxThreads": 8}`, wantArgs: []string{"--metadata-ttl=minimal", "--allow-overwrite", "--max-threads=8"}},
+				} {
+					nodeTestEnv := initNodeServerTestEnv(t)
+					req := &csi.NodePublishVolumeRequest{
+						VolumeId:         volumeId,
+						VolumeCapability: stdVolCap,
+						TargetPath:       targetPath,
+						VolumeContext: map[string]string{
+							"bucketName":         bucketName,
+							"performanceProfile": tc.performanceProfile,
+						},
+					}
+
+					nodeTestEnv.mockMounter.EXPECT().Mount(
+						gomock.Eq(context.Background()),
+						gomock.Eq(bucketName),
+						gomock.Eq(targetPath),
+						gomock.Eq(credentialprovider.ProvideContext{
+							VolumeID: volumeId,
+						}),
+						gomock.Eq(mountpoint.ParseArgs(append(tc.wantArgs, "--allow-root", "--force-path-style"))),
+						gomock.Eq(""))
+					if _, err := nodeTestEnv.server.NodePublishVolume(context.Background(), req); err != nil {
+						t.Fatalf("NodePublishVolume is failed for performance profile %s: %v", tc.performanceProfile, err)
+					}
+
+					nodeTestEnv.mockCtl.Finish()
+				}
+			},
+		},
+		{
+			name: "fail: invalid performance profile attribute",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				for _, tc := range []struct {
+					performanceProfile string
+					mountOptions       []string
+				}{
+					{performanceProfile: `{"maxThreads": 1024}`},
+					{performanceProfile: `{"profile": "throughput"}`, mountOptions: []string{"metadata-ttl=indefinite"}},
+					{performanceProfile: `{"allowOverwrite": true}`, mountOptions: []string{"allow-overwrite"}},
+				} {
+					req := &csi.NodePublishVolumeRequest{
+						VolumeId: volumeId,
+						VolumeCapability: &csi.VolumeCapability{
+							AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{MountFlags: tc.mountOptions}},
+							AccessMode: stdVolCap.AccessMode,
+						},
+						TargetPath: targetPath,
+						VolumeContext: map[string]string{
+							"bucketName":         bucketName,
+							"performanceProfile": tc.performanceProfile,
+						},
+					}
+					_, err := nodeTestEnv.server.NodePublishVolume(context.Background(), req)
+					if status.Code(err) != codes.InvalidArgument {
+						t.Fatalf("Expected InvalidArgument for %s with mount options %v, got %v", tc.performanceProfile, tc.mountOptions, err)
+					}
+				}
+
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: mounts the read side of dual-auth volumes read-only",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				t.Setenv(credentialprovider.EnvAllowedRoleARNs, "arn:aws:iam::123456789012:role/reader")
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId:         volumeId,
+					VolumeCapability: stdVolCap,
+					TargetPath:       targetPath,
+					VolumeContext: map[string]string{
+						"bucketName":           bucketName,
+						"dualAuth":             "read",
+						"authenticationSource": "role",
+						"roleArn":              "arn:aws:iam::123456789012:role/reader",
+					},
+				}
+
+				nodeTestEnv.mockMounter.EXPECT().Mount(
+					gomock.Eq(context.Background()),
+					gomock.Eq(bucketName),
+					gomock.Eq(targetPath),
+					gomock.Eq(credentialprovider.ProvideContext{
+						VolumeID:             volumeId,
+						AuthenticationSource: credentialprovider.AuthenticationSourceRole,
+						RoleARN:              "arn:aws:iam::123456789012:role/reader",
+					}),
+					gomock.Eq(mountpoint.ParseArgs([]string{"--read-only", "--allow-root", "--force-path-style"})),
+					gomock.Eq(""))
+				_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodePublishVolume is failed: %v", err)
+				}
+
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: role not allowed by the driver",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				t.Setenv(credentialprovider.EnvAllowedRoleARNs, "arn:aws:iam::123456789012:role/team-a-*")
+				ctx := context.Background()
+				for _, roleARN := range []string{"arn:aws:iam::123456789012:role/admin", "arn:aws:iam::210987654321:role/team-a-reader"} {
+					req := &csi.NodePublishVolumeRequest{
+						VolumeId:         volumeId,
+						VolumeCapability: stdVolCap,
+						TargetPath:       targetPath,
+						VolumeContext: map[string]string{
+							"bucketName":           bucketName,
+							"authenticationSource": "role",
+							"roleArn":              roleARN,
+						},
+					}
+
+					_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+					if status.Code(err) != codes.PermissionDenied {
+						t.Fatalf("Expected PermissionDenied for %s, got: %v", roleARN, err)
+					}
+				}
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: invalid dual-auth volumes",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				ctx := context.Background()
+				for _, tc := range []struct {
+					volumeCtx    map[string]string
+					mountOptions []string
+				}{
+					{volumeCtx: map[string]string{"dualAuth": "read", "authenticationSource": "secret"}},
+					{volumeCtx: map[string]string{"dualAuth": "write", "authenticationSource": "driver"}},
+					{volumeCtx: map[string]string{"dualAuth": "write"}},
+					{volumeCtx: map[string]string{"dualAuth": "write", "authenticationSource": "secret"}, mountOptions: []string{"read-only"}},
+					{volumeCtx: map[string]string{"dualAuth": "both", "authenticationSource": "secret"}},
+				} {
+					tc.volumeCtx["bucketName"] = bucketName
+					req := &csi.NodePublishVolumeRequest{
+						VolumeId: volumeId,
+						VolumeCapability: &csi.VolumeCapability{
+							AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{MountFlags: tc.mountOptions}},
+							AccessMode: stdVolCap.AccessMode,
+						},
+						TargetPath:    targetPath,
+						VolumeContext: tc.volumeCtx,
+					}
+					_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+					if status.Code(err) != codes.InvalidArgument {
+						t.Fatalf("Expected InvalidArgument for %v with mount options %v, got %v", tc.volumeCtx, tc.mountOptions, err)
+					}
+				}
+
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: translates AWS volume attributes in AWS compatibility mode",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				nodeTestEnv.server.AWSCompatibilityMode = true
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId:         volumeId,
+					VolumeCapability: stdVolCap,
+					TargetPath:       targetPath,
+					VolumeContext: map[string]string{
+						"bucketName":           bucketName,
+						"authenticationSource": "secret",
+						"stsRegion":            "us-west-2",
+					},
+					Secrets: map[string]string{
+						"access_key_id":     "ACCESSKEY",
+						"secret_access_key": "SECRETKEY",
+					},
+				}
+
+				nodeTestEnv.mockMounter.EXPECT().Mount(
+					gomock.Eq(context.Background()),
+					gomock.Eq(bucketName),
+					gomock.Eq(targetPath),
+					gomock.Eq(credentialprovider.ProvideContext{
+						VolumeID:             volumeId,
+						AuthenticationSource: credentialprovider.AuthenticationSourceSecret,
+						SecretData: map[string]string{
+							"access_key_id":     "ACCESSKEY",
+							"secret_access_key": "SECRETKEY",
+						},
+					}),
+					gomock.Any(),
+					gomock.Eq(""))
+				_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodePublishVolume is failed: %v", err)
+				}
+
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: pod authentication source in AWS compatibility mode",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				nodeTestEnv.server.AWSCompatibilityMode = true
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId:         volumeId,
+					VolumeCapability: stdVolCap,
+					TargetPath:       targetPath,
+					VolumeContext:    map[string]string{"bucketName": bucketName, "authenticationSource": "pod"},
+				}
+
+				// Workloads asking for their own identity must not get the driver credentials
+				_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Expected InvalidArgument, got: %v", err)
+				}
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: does not translate AWS volume attributes by default",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId:         volumeId,
+					VolumeCapability: stdVolCap,
+					TargetPath:       targetPath,
+					VolumeContext:    map[string]string{"bucketName": bucketName, "authenticationSource": "pod"},
+				}
+
+				nodeTestEnv.mockMounter.EXPECT().Mount(
+					gomock.Eq(context.Background()),
+					gomock.Eq(bucketName),
+					gomock.Eq(targetPath),
+					gomock.Eq(credentialprovider.ProvideContext{
+						VolumeID:             volumeId,
+						AuthenticationSource: "pod",
+					}),
+					gomock.Any(),
+					gomock.Eq(""))
+				_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodePublishVolume is failed: %v", err)
+				}
+
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
 		{
 			name: "fail: missing volume id",
 			testFunc: func(t *testing.T) {
@@ -203,16 +717,399 @@ func TestNodePublishVolume(t *testing.T) {
 				req := &csi.NodePublishVolumeRequest{
 					VolumeCapability: stdVolCap,
 					TargetPath:       targetPath,
-					VolumeContext:    map[string]string{"bucketName": bucketName},
+					VolumeContext:    map[string]string{"bucketName": bucketName},
+				}
+
+				_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+				if err == nil {
+					t.Fatalf("NodePublishVolume is failed: %v", err)
+				}
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: diagnostic mount is read-only with debug logs",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				nodeTestEnv.server.DiagnosticMountNamespace = "kube-system"
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId: volumeId,
+					VolumeCapability: &csi.VolumeCapability{
+						AccessType: &csi.VolumeCapability_Mount{
+							Mount: &csi.VolumeCapability_MountVolume{},
+						},
+						AccessMode: &csi.VolumeCapability_AccessMode{
+							Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER,
+						},
+					},
+					TargetPath: targetPath,
+					VolumeContext: map[string]string{
+						"bucketName":                       bucketName,
+						"prefix":                           "data/",
+						"diagnostic":                       "true",
+						"csi.storage.k8s.io/ephemeral":     "true",
+						"csi.storage.k8s.io/pod.namespace": "kube-system",
+					},
+				}
+
+				nodeTestEnv.mockMounter.EXPECT().Mount(
+					gomock.Eq(context.Background()),
+					gomock.Eq(bucketName),
+					gomock.Eq(targetPath),
+					gomock.Eq(credentialprovider.ProvideContext{
+						VolumeID:     volumeId,
+						PodNamespace: "kube-system",
+					}),
+					gomock.Eq(mountpoint.ParseArgs([]string{"--read-only", "--debug", "--prefix=data/", "--allow-root", "--force-path-style"})),
+					gomock.Eq(""))
+				_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodePublishVolume is failed: %v", err)
+				}
+
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: diagnostic mount outside of the diagnostic namespace",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				nodeTestEnv.server.DiagnosticMountNamespace = "kube-system"
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId:         volumeId,
+					VolumeCapability: stdVolCap,
+					TargetPath:       targetPath,
+					VolumeContext: map[string]string{
+						"bucketName":                       bucketName,
+						"diagnostic":                       "true",
+						"csi.storage.k8s.io/ephemeral":     "true",
+						"csi.storage.k8s.io/pod.namespace": "default",
+					},
+				}
+
+				_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+				if status.Code(err) != codes.PermissionDenied {
+					t.Fatalf("Expected PermissionDenied, got: %v", err)
+				}
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: ephemeral volume without diagnostic attribute",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				nodeTestEnv.server.DiagnosticMountNamespace = "kube-system"
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId:         volumeId,
+					VolumeCapability: stdVolCap,
+					TargetPath:       targetPath,
+					VolumeContext: map[string]string{
+						"bucketName":                       bucketName,
+						"csi.storage.k8s.io/ephemeral":     "true",
+						"csi.storage.k8s.io/pod.namespace": "kube-system",
+					},
+				}
+
+				_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Expected InvalidArgument, got: %v", err)
+				}
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: inline ephemeral volume with credentials from a Secret",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				nodeTestEnv.server.EphemeralVolumes = true
+				nodeTestEnv.server.Secrets = fake.NewClientset(&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "s3-credentials", Namespace: "default"},
+					Data: map[string][]byte{
+						"access_key_id":     []byte("ACCESSKEY"),
+						"secret_access_key": []byte("SECRETKEY"),
+					},
+				}).CoreV1()
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId: volumeId,
+					VolumeCapability: &csi.VolumeCapability{
+						AccessType: &csi.VolumeCapability_Mount{
+							Mount: &csi.VolumeCapability_MountVolume{},
+						},
+						AccessMode: &csi.VolumeCapability_AccessMode{
+							Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER,
+						},
+					},
+					TargetPath: targetPath,
+					VolumeContext: map[string]string{
+						"bucketName":                       bucketName,
+						"prefix":                           "data/",
+						"secretName":                       "s3-credentials",
+						"csi.storage.k8s.io/ephemeral":     "true",
+						"csi.storage.k8s.io/pod.namespace": "default",
+					},
+				}
+
+				nodeTestEnv.mockMounter.EXPECT().Mount(
+					gomock.Eq(context.Background()),
+					gomock.Eq(bucketName),
+					gomock.Eq(targetPath),
+					gomock.Eq(credentialprovider.ProvideContext{
+						VolumeID:             volumeId,
+						PodNamespace:         "default",
+						AuthenticationSource: credentialprovider.AuthenticationSourceSecret,
+						SecretData: map[string]string{
+							"access_key_id":     "ACCESSKEY",
+							"secret_access_key": "SECRETKEY",
+						},
+					}),
+					gomock.Eq(mountpoint.ParseArgs([]string{"--prefix=data/", "--allow-root", "--force-path-style"})),
+					gomock.Eq(""))
+				_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodePublishVolume is failed: %v", err)
+				}
+
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: inline ephemeral volume with a missing Secret",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				nodeTestEnv.server.EphemeralVolumes = true
+				nodeTestEnv.server.Secrets = fake.NewClientset().CoreV1()
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId:         volumeId,
+					VolumeCapability: stdVolCap,
+					TargetPath:       targetPath,
+					VolumeContext: map[string]string{
+						"bucketName":                       bucketName,
+						"secretName":                       "s3-credentials",
+						"csi.storage.k8s.io/ephemeral":     "true",
+						"csi.storage.k8s.io/pod.namespace": "default",
+					},
 				}
 
 				_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
-				if err == nil {
+				if status.Code(err) != codes.NotFound {
+					t.Fatalf("Expected NotFound, got: %v", err)
+				}
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: inline ephemeral volume without a Secret",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				nodeTestEnv.server.EphemeralVolumes = true
+				nodeTestEnv.server.Secrets = fake.NewClientset().CoreV1()
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId:         volumeId,
+					VolumeCapability: stdVolCap,
+					TargetPath:       targetPath,
+					VolumeContext: map[string]string{
+						"bucketName":                       bucketName,
+						"csi.storage.k8s.io/ephemeral":     "true",
+						"csi.storage.k8s.io/pod.namespace": "default",
+					},
+				}
+
+				// The driver credentials must not be used by volumes any Pod can declare
+				_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Expected InvalidArgument, got: %v", err)
+				}
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: inline ephemeral volume with an authentication source other than secret",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				nodeTestEnv.server.EphemeralVolumes = true
+				nodeTestEnv.server.Secrets = fake.NewClientset().CoreV1()
+				ctx := context.Background()
+				for _, volumeCtx := range []map[string]string{
+					{"authenticationSource": "driver"},
+					{"authenticationSource": "role", "roleArn": "arn:aws:iam::123456789012:role/other-tenant"},
+					{"authenticationSource": "file", "credentialsName": "other-tenant"},
+					{"authenticationSource": "webIdentity", "roleArn": "arn:aws:iam::123456789012:role/other-tenant"},
+				} {
+					volumeCtx["bucketName"] = bucketName
+					volumeCtx["secretName"] = "s3-credentials"
+					volumeCtx["csi.storage.k8s.io/ephemeral"] = "true"
+					volumeCtx["csi.storage.k8s.io/pod.namespace"] = "default"
+					req := &csi.NodePublishVolumeRequest{
+						VolumeId:         volumeId,
+						VolumeCapability: stdVolCap,
+						TargetPath:       targetPath,
+						VolumeContext:    volumeCtx,
+					}
+
+					_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+					if status.Code(err) != codes.InvalidArgument {
+						t.Fatalf("Expected InvalidArgument for %s, got: %v", volumeCtx["authenticationSource"], err)
+					}
+				}
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: mount allowed by the namespace bucket policy",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				nodeTestEnv.server.BucketPolicy = testBucketPolicy(t)
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId:         volumeId,
+					VolumeCapability: stdVolCap,
+					TargetPath:       targetPath,
+					VolumeContext: map[string]string{
+						"bucketName":                       "team-a-data",
+						"csi.storage.k8s.io/pod.namespace": "team-a",
+					},
+				}
+
+				nodeTestEnv.mockMounter.EXPECT().Mount(gomock.Any(), gomock.Eq("team-a-data"), gomock.Eq(targetPath), gomock.Any(), gomock.Any(), gomock.Eq(""))
+				if _, err := nodeTestEnv.server.NodePublishVolume(ctx, req); err != nil {
 					t.Fatalf("NodePublishVolume is failed: %v", err)
 				}
 				nodeTestEnv.mockCtl.Finish()
 			},
 		},
+		{
+			name: "fail: mount rejected by the namespace bucket policy",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				nodeTestEnv.server.BucketPolicy = testBucketPolicy(t)
+				ctx := context.Background()
+				for _, namespace := range []string{"team-b", ""} {
+					req := &csi.NodePublishVolumeRequest{
+						VolumeId:         volumeId,
+						VolumeCapability: stdVolCap,
+						TargetPath:       targetPath,
+						VolumeContext: map[string]string{
+							"bucketName":                       "team-a-data",
+							"csi.storage.k8s.io/pod.namespace": namespace,
+						},
+					}
+
+					_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+					if code := status.Code(err); code != codes.PermissionDenied && code != codes.FailedPrecondition {
+						t.Fatalf("Expected the mount to be rejected for namespace %q, got: %v", namespace, err)
+					}
+				}
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: bucket alias rejected by the namespace bucket policy",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				nodeTestEnv.server.BucketPolicy = testBucketPolicy(t)
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId:         volumeId,
+					VolumeCapability: stdVolCap,
+					TargetPath:       targetPath,
+					VolumeContext: map[string]string{
+						"bucketName":                       "team-a-data",
+						"bucketAlias":                      "team-b-data",
+						"csi.storage.k8s.io/pod.namespace": "team-a",
+					},
+				}
+
+				_, err := nodeTestEnv.server.NodePublishVolume(context.Background(), req)
+				assert.Equals(t, codes.PermissionDenied, status.Code(err))
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: volume context too large",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId:         volumeId,
+					VolumeCapability: stdVolCap,
+					TargetPath:       targetPath,
+					VolumeContext: map[string]string{
+						"bucketName": bucketName,
+						"large":      strings.Repeat("a", volumecontext.MaxSize),
+					},
+				}
+
+				_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Expected InvalidArgument, got: %v", err)
+				}
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: mount options too long",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId: volumeId,
+					VolumeCapability: &csi.VolumeCapability{
+						AccessType: &csi.VolumeCapability_Mount{
+							Mount: &csi.VolumeCapability_MountVolume{
+								MountFlags: []string{"prefix=" + strings.Repeat("a", crdv2.MaxMountOptionsLength)},
+							},
+						},
+						AccessMode: &csi.VolumeCapability_AccessMode{
+							Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+						},
+					},
+					TargetPath:    targetPath,
+					VolumeContext: map[string]string{"bucketName": bucketName},
+				}
+
+				_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Expected InvalidArgument, got: %v", err)
+				}
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: invalid file mode",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId: volumeId,
+					VolumeCapability: &csi.VolumeCapability{
+						AccessType: &csi.VolumeCapability_Mount{
+							Mount: &csi.VolumeCapability_MountVolume{
+								MountFlags: []string{"file-mode=964", "uid=1000"},
+							},
+						},
+						AccessMode: &csi.VolumeCapability_AccessMode{
+							Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+						},
+					},
+					TargetPath:    targetPath,
+					VolumeContext: map[string]string{"bucketName": bucketName},
+				}
+
+				_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Expected InvalidArgument, got: %v", err)
+				}
+				if !strings.Contains(err.Error(), "--file-mode=0644") {
+					t.Fatalf("Expected an example of a valid file mode, got: %v", err)
+				}
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -225,6 +1122,14 @@ func TestNodePublishVolumeForPodMounter(t *testing.T) {
 	var (
 		volumeId   = "test-volume-id"
 		bucketName = "test-bucket-name"
+		stdVolCap  = &csi.VolumeCapability{
+			AccessType: &csi.VolumeCapability_Mount{
+				Mount: &csi.VolumeCapability_MountVolume{},
+			},
+			AccessMode: &csi.VolumeCapability_AccessMode{
+				Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+			},
+		}
 		targetPath = "/target/path"
 	)
 	testCases := []struct {
@@ -388,40 +1293,152 @@ func TestNodePublishVolumeForPodMounter(t *testing.T) {
 			},
 		},
 		{
-			name: "success: uses gid, allow-other, dir-mode, file-mode from mountOptions if fsGroup is set and these flags are provided in mountOptions",
+			name: "success: uses gid, allow-other, dir-mode, file-mode from mountOptions if fsGroup is set and these flags are provided in mountOptions",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				ctx := context.Background()
+				mountFlags := []string{"--gid 456", "--allow-other", "--dir-mode=555", "--file-mode=444", "--force-path-style"}
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId: volumeId,
+					VolumeCapability: &csi.VolumeCapability{
+						AccessType: &csi.VolumeCapability_Mount{
+							Mount: &csi.VolumeCapability_MountVolume{
+								MountFlags:       mountFlags,
+								VolumeMountGroup: "123",
+							},
+						},
+						AccessMode: &csi.VolumeCapability_AccessMode{
+							Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+						},
+					},
+					VolumeContext: map[string]string{"bucketName": bucketName},
+					TargetPath:    targetPath,
+				}
+
+				nodeTestEnv.mockMounter.EXPECT().Mount(
+					gomock.Eq(context.Background()),
+					gomock.Eq(bucketName),
+					gomock.Eq(targetPath),
+					gomock.Eq(credentialprovider.ProvideContext{
+						VolumeID: volumeId,
+					}),
+					gomock.Eq(mountpoint.ParseArgs(mountFlags)),
+					gomock.Eq("123")).Return(nil)
+				_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodePublishVolume is failed: %v", err)
+				}
+
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: propagates the fsGroup of the workload Pod if kubelet does not provide it",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				ctx := context.Background()
+				nodeTestEnv.server.WorkloadPods = fake.NewClientset(&corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: "workload", Namespace: "team-a"},
+					Spec:       corev1.PodSpec{SecurityContext: &corev1.PodSecurityContext{FSGroup: ptr.To(int64(456))}},
+				}).CoreV1()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId:         volumeId,
+					VolumeCapability: stdVolCap,
+					VolumeContext: map[string]string{
+						"bucketName":                  bucketName,
+						volumecontext.CSIPodName:      "workload",
+						volumecontext.CSIPodNamespace: "team-a",
+					},
+					TargetPath: targetPath,
+				}
+
+				nodeTestEnv.mockMounter.EXPECT().Mount(
+					gomock.Eq(context.Background()),
+					gomock.Eq(bucketName),
+					gomock.Eq(targetPath),
+					gomock.Any(),
+					gomock.Eq(mountpoint.ParseArgs([]string{"--gid=456", "--allow-other", "--dir-mode=770", "--file-mode=660", "--force-path-style"})),
+					gomock.Eq("456")).Return(nil)
+				_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodePublishVolume is failed: %v", err)
+				}
+				if stdVolCap.GetMount().GetVolumeMountGroup() != "" {
+					t.Fatalf("Expected the volume capability of the request to be left unchanged")
+				}
+
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: prefers the fsGroup provided by kubelet and mounts Pods without fsGroup as before",
+			testFunc: func(t *testing.T) {
+				nodeTestEnv := initNodeServerTestEnv(t)
+				ctx := context.Background()
+				nodeTestEnv.server.WorkloadPods = fake.NewClientset(
+					&corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{Name: "workload", Namespace: "team-a"},
+						Spec:       corev1.PodSpec{SecurityContext: &corev1.PodSecurityContext{FSGroup: ptr.To(int64(456))}},
+					},
+					&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "no-fsgroup", Namespace: "team-a"}},
+				).CoreV1()
+
+				for _, tc := range []struct {
+					podName          string
+					volumeMountGroup string
+					wantArgs         []string
+					wantFSGroup      string
+				}{
+					{podName: "workload", volumeMountGroup: "123", wantArgs: []string{"--gid=123", "--allow-other", "--dir-mode=770", "--file-mode=660", "--force-path-style"}, wantFSGroup: "123"},
+					{podName: "no-fsgroup", wantArgs: []string{"--allow-root", "--force-path-style"}},
+				} {
+					req := &csi.NodePublishVolumeRequest{
+						VolumeId: volumeId,
+						VolumeCapability: &csi.VolumeCapability{
+							AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{VolumeMountGroup: tc.volumeMountGroup}},
+							AccessMode: stdVolCap.AccessMode,
+						},
+						VolumeContext: map[string]string{
+							"bucketName":                  bucketName,
+							volumecontext.CSIPodName:      tc.podName,
+							volumecontext.CSIPodNamespace: "team-a",
+						},
+						TargetPath: targetPath,
+					}
+
+					nodeTestEnv.mockMounter.EXPECT().Mount(
+						gomock.Eq(context.Background()),
+						gomock.Eq(bucketName),
+						gomock.Eq(targetPath),
+						gomock.Any(),
+						gomock.Eq(mountpoint.ParseArgs(tc.wantArgs)),
+						gomock.Eq(tc.wantFSGroup)).Return(nil)
+					if _, err := nodeTestEnv.server.NodePublishVolume(ctx, req); err != nil {
+						t.Fatalf("NodePublishVolume is failed for Pod %s: %v", tc.podName, err)
+					}
+				}
+
+				nodeTestEnv.mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: workload Pod of fsGroup propagation not found",
 			testFunc: func(t *testing.T) {
 				nodeTestEnv := initNodeServerTestEnv(t)
-				ctx := context.Background()
-				mountFlags := []string{"--gid 456", "--allow-other", "--dir-mode=555", "--file-mode=444", "--force-path-style"}
+				nodeTestEnv.server.WorkloadPods = fake.NewClientset().CoreV1()
 				req := &csi.NodePublishVolumeRequest{
-					VolumeId: volumeId,
-					VolumeCapability: &csi.VolumeCapability{
-						AccessType: &csi.VolumeCapability_Mount{
-							Mount: &csi.VolumeCapability_MountVolume{
-								MountFlags:       mountFlags,
-								VolumeMountGroup: "123",
-							},
-						},
-						AccessMode: &csi.VolumeCapability_AccessMode{
-							Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
-						},
+					VolumeId:         volumeId,
+					VolumeCapability: stdVolCap,
+					VolumeContext: map[string]string{
+						"bucketName":                  bucketName,
+						volumecontext.CSIPodName:      "workload",
+						volumecontext.CSIPodNamespace: "team-a",
 					},
-					VolumeContext: map[string]string{"bucketName": bucketName},
-					TargetPath:    targetPath,
+					TargetPath: targetPath,
 				}
-
-				nodeTestEnv.mockMounter.EXPECT().Mount(
-					gomock.Eq(context.Background()),
-					gomock.Eq(bucketName),
-					gomock.Eq(targetPath),
-					gomock.Eq(credentialprovider.ProvideContext{
-						VolumeID: volumeId,
-					}),
-					gomock.Eq(mountpoint.ParseArgs(mountFlags)),
-					gomock.Eq("123")).Return(nil)
-				_, err := nodeTestEnv.server.NodePublishVolume(ctx, req)
-				if err != nil {
-					t.Fatalf("NodePublishVolume is failed: %v", err)
+				_, err := nodeTestEnv.server.NodePublishVolume(context.Background(), req)
+				if status.Code(err) != codes.NotFound {
+					t.Fatalf("Expected NotFound, got %v", err)
 				}
 
 				nodeTestEnv.mockCtl.Finish()
@@ -592,3 +1609,947 @@ func (d *dummyMounter) Unmount(ctx context.Context, target string, cleanupCtx cr
 func (d *dummyMounter) IsMountPoint(target string) (bool, error) {
 	return true, nil
 }
+
+var _ mounter.Stager = &fakeStager{}
+
+type fakeStager struct {
+	bucketName    string
+	credentialCtx credentialprovider.ProvideContext
+	args          mountpoint.Args
+	fsGroup       string
+	published     []string
+	unstaged      []string
+}
+
+func (f *fakeStager) Stage(ctx context.Context, bucketName string, stagingTarget string, credentialCtx credentialprovider.ProvideContext, args mountpoint.Args, fsGroup string) error {
+	f.bucketName, f.credentialCtx, f.args, f.fsGroup = bucketName, credentialCtx, args, fsGroup
+	return nil
+}
+
+func (f *fakeStager) Publish(ctx context.Context, stagingTarget string, target string, volumeID string, readOnly bool) error {
+	if readOnly {
+		target += " (ro)"
+	}
+	f.published = append(f.published, stagingTarget+" -> "+target)
+	return nil
+}
+
+func (f *fakeStager) Unstage(ctx context.Context, stagingTarget string) error {
+	f.unstaged = append(f.unstaged, stagingTarget)
+	return nil
+}
+
+type fakeUsageSource struct {
+	volumes []volumestats.Volume
+}
+
+func (f *fakeUsageSource) Usage(ctx context.Context, volume volumestats.Volume) (volumestats.Usage, error) {
+	f.volumes = append(f.volumes, volume)
+	return volumestats.Usage{UsedBytes: 4096, Objects: 3}, nil
+}
+
+func TestNodeGetVolumeStats(t *testing.T) {
+	const (
+		volumeID   = "test-volume-id"
+		targetPath = "/target/path"
+	)
+	ctx := context.Background()
+	statsReq := &csi.NodeGetVolumeStatsRequest{VolumeId: volumeID, VolumePath: targetPath}
+
+	t.Run("unimplemented when disabled", func(t *testing.T) {
+		server := node.NewS3NodeServer("test-nodeID", &dummyMounter{})
+		_, err := server.NodeGetVolumeStats(ctx, statsReq)
+		assert.Equals(t, codes.Unimplemented, status.Code(err))
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		server := node.NewS3NodeServer("test-nodeID", &dummyMounter{})
+		server.VolumeStats = volumestats.NewProvider(&fakeUsageSource{}, time.Minute)
+		_, err := server.NodeGetVolumeStats(ctx, &csi.NodeGetVolumeStatsRequest{VolumePath: targetPath})
+		assert.Equals(t, codes.InvalidArgument, status.Code(err))
+		_, err = server.NodeGetVolumeStats(ctx, &csi.NodeGetVolumeStatsRequest{VolumeId: volumeID})
+		assert.Equals(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("reports usage of published volumes", func(t *testing.T) {
+		source := &fakeUsageSource{}
+		server := node.NewS3NodeServer("test-nodeID", &dummyMounter{})
+		server.VolumeStats = volumestats.NewProvider(source, time.Minute)
+
+		_, err := server.NodeGetVolumeStats(ctx, statsReq)
+		assert.Equals(t, codes.NotFound, status.Code(err))
+
+		_, err = server.NodePublishVolume(ctx, &csi.NodePublishVolumeRequest{
+			VolumeId: volumeID,
+			VolumeCapability: &csi.VolumeCapability{
+				AccessType: &csi.VolumeCapability_Mount{
+					Mount: &csi.VolumeCapability_MountVolume{MountFlags: []string{"prefix data/"}},
+				},
+				AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER},
+			},
+			TargetPath:    targetPath,
+			VolumeContext: map[string]string{"bucketName": "test-bucket"},
+		})
+		assert.NoError(t, err)
+
+		resp, err := server.NodeGetVolumeStats(ctx, statsReq)
+		assert.NoError(t, err)
+		assert.Equals(t, []*csi.VolumeUsage{
+			{Unit: csi.VolumeUsage_BYTES, Used: 4096},
+			{Unit: csi.VolumeUsage_INODES, Used: 3},
+		}, resp.GetUsage())
+		assert.Equals(t, []volumestats.Volume{{Bucket: "test-bucket", Prefix: "data/"}}, source.volumes)
+
+		_, err = server.NodeUnpublishVolume(ctx, &csi.NodeUnpublishVolumeRequest{VolumeId: volumeID, TargetPath: targetPath})
+		assert.NoError(t, err)
+
+		_, err = server.NodeGetVolumeStats(ctx, statsReq)
+		assert.Equals(t, codes.NotFound, status.Code(err))
+	})
+}
+
+func TestNodeGetVolumeStatsCondition(t *testing.T) {
+	ctx := context.Background()
+	source := &fakeUsageSource{}
+	server := node.NewS3NodeServer("test-nodeID", &dummyMounter{})
+	server.VolumeStats = volumestats.NewProvider(source, time.Minute)
+	server.VolumeCondition = volumecondition.NewChecker(time.Second)
+
+	_, err := server.NodeGetVolumeStats(ctx, &csi.NodeGetVolumeStatsRequest{
+		VolumeId:   "test-volume-id",
+		VolumePath: filepath.Join(t.TempDir(), "missing"),
+	})
+	assert.Equals(t, codes.NotFound, status.Code(err))
+
+	// A target without a FUSE mount is reported abnormal, without computing its usage
+	resp, err := server.NodeGetVolumeStats(ctx, &csi.NodeGetVolumeStatsRequest{
+		VolumeId:   "test-volume-id",
+		VolumePath: t.TempDir(),
+	})
+	assert.NoError(t, err)
+	assert.Equals(t, true, resp.GetVolumeCondition().GetAbnormal())
+	assert.Equals(t, "Volume is not mounted", resp.GetVolumeCondition().GetMessage())
+	assert.Equals(t, 0, len(resp.GetUsage()))
+	assert.Equals(t, 0, len(source.volumes))
+}
+
+func TestNodeGetCapabilitiesWithVolumeCondition(t *testing.T) {
+	t.Setenv("MOUNTER_KIND", "pod")
+	server := node.NewS3NodeServer("test-nodeID", &dummyMounter{})
+	server.VolumeCondition = volumecondition.NewChecker(time.Second)
+
+	resp, err := server.NodeGetCapabilities(context.Background(), &csi.NodeGetCapabilitiesRequest{})
+	assert.NoError(t, err)
+
+	var types []csi.NodeServiceCapability_RPC_Type
+	for _, c := range resp.GetCapabilities() {
+		types = append(types, c.GetRpc().GetType())
+	}
+	assert.Equals(t, []csi.NodeServiceCapability_RPC_Type{
+		csi.NodeServiceCapability_RPC_VOLUME_MOUNT_GROUP,
+		csi.NodeServiceCapability_RPC_GET_VOLUME_STATS,
+		csi.NodeServiceCapability_RPC_VOLUME_CONDITION,
+	}, types)
+}
+
+func TestNodeGetCapabilitiesWithVolumeStats(t *testing.T) {
+	t.Setenv("MOUNTER_KIND", "pod")
+	server := node.NewS3NodeServer("test-nodeID", &dummyMounter{})
+	server.VolumeStats = volumestats.NewProvider(&fakeUsageSource{}, time.Minute)
+
+	resp, err := server.NodeGetCapabilities(context.Background(), &csi.NodeGetCapabilitiesRequest{})
+	assert.NoError(t, err)
+
+	var types []csi.NodeServiceCapability_RPC_Type
+	for _, c := range resp.GetCapabilities() {
+		types = append(types, c.GetRpc().GetType())
+	}
+	assert.Equals(t, []csi.NodeServiceCapability_RPC_Type{
+		csi.NodeServiceCapability_RPC_VOLUME_MOUNT_GROUP,
+		csi.NodeServiceCapability_RPC_GET_VOLUME_STATS,
+	}, types)
+}
+
+func TestNodeStageVolume(t *testing.T) {
+	t.Setenv("MOUNTER_KIND", "pod")
+	stager := &fakeStager{}
+	server := node.NewS3NodeServer("test-nodeID", &dummyMounter{})
+	server.Stager = stager
+
+	resp, err := server.NodeGetCapabilities(context.Background(), &csi.NodeGetCapabilitiesRequest{})
+	assert.NoError(t, err)
+	var types []csi.NodeServiceCapability_RPC_Type
+	for _, c := range resp.GetCapabilities() {
+		types = append(types, c.GetRpc().GetType())
+	}
+	assert.Equals(t, []csi.NodeServiceCapability_RPC_Type{
+		csi.NodeServiceCapability_RPC_VOLUME_MOUNT_GROUP,
+		csi.NodeServiceCapability_RPC_STAGE_UNSTAGE_VOLUME,
+	}, types)
+
+	volCap := &csi.VolumeCapability{
+		AccessType: &csi.VolumeCapability_Mount{
+			Mount: &csi.VolumeCapability_MountVolume{MountFlags: []string{"--region=us-east-1"}, VolumeMountGroup: "123"},
+		},
+		AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER},
+	}
+	_, err = server.NodeStageVolume(context.Background(), &csi.NodeStageVolumeRequest{
+		VolumeId:          "test-volume-id",
+		StagingTargetPath: "/staging/path",
+		VolumeCapability:  volCap,
+		VolumeContext:     map[string]string{"bucketName": "test-bucket-name", "authenticationSource": "secret"},
+		Secrets:           map[string]string{"access_key_id": "key"},
+	})
+	assert.NoError(t, err)
+	assert.Equals(t, "test-bucket-name", stager.bucketName)
+	assert.Equals(t, credentialprovider.ProvideContext{
+		VolumeID:             "test-volume-id",
+		AuthenticationSource: "secret",
+		BucketRegion:         "us-east-1",
+		SecretData:           map[string]string{"access_key_id": "key"},
+	}, stager.credentialCtx)
+	wantArgs := mountpoint.ParseArgs([]string{"--region=us-east-1", "--gid=123", "--allow-other", "--dir-mode=770", "--file-mode=660", "--force-path-style"})
+	assert.Equals(t, wantArgs.SortedList(), stager.args.SortedList())
+	assert.Equals(t, "123", stager.fsGroup)
+
+	// Publishing only bind-mounts the staging path
+	_, err = server.NodePublishVolume(context.Background(), &csi.NodePublishVolumeRequest{
+		VolumeId:          "test-volume-id",
+		StagingTargetPath: "/staging/path",
+		TargetPath:        "/target/path",
+		VolumeCapability:  volCap,
+		VolumeContext:     map[string]string{"bucketName": "test-bucket-name"},
+	})
+	assert.NoError(t, err)
+	assert.Equals(t, []string{"/staging/path -> /target/path"}, stager.published)
+
+	// Read-only volume mounts of workloads are read-only bind mounts of the staging path
+	_, err = server.NodePublishVolume(context.Background(), &csi.NodePublishVolumeRequest{
+		VolumeId:          "test-volume-id",
+		StagingTargetPath: "/staging/path",
+		TargetPath:        "/target/read-only",
+		VolumeCapability:  volCap,
+		Readonly:          true,
+		VolumeContext:     map[string]string{"bucketName": "test-bucket-name"},
+	})
+	assert.NoError(t, err)
+	assert.Equals(t, []string{"/staging/path -> /target/path", "/staging/path -> /target/read-only (ro)"}, stager.published)
+
+	_, err = server.NodeUnstageVolume(context.Background(), &csi.NodeUnstageVolumeRequest{
+		VolumeId:          "test-volume-id",
+		StagingTargetPath: "/staging/path",
+	})
+	assert.NoError(t, err)
+	assert.Equals(t, []string{"/staging/path"}, stager.unstaged)
+
+	_, err = server.NodeStageVolume(context.Background(), &csi.NodeStageVolumeRequest{
+		VolumeId:         "test-volume-id",
+		VolumeCapability: volCap,
+		VolumeContext:    map[string]string{"bucketName": "test-bucket-name"},
+	})
+	assert.Equals(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestNodeStageVolumeWithoutStaging(t *testing.T) {
+	server := node.NewS3NodeServer("test-nodeID", &dummyMounter{})
+
+	_, err := server.NodeStageVolume(context.Background(), &csi.NodeStageVolumeRequest{})
+	assert.Equals(t, codes.Unimplemented, status.Code(err))
+	_, err = server.NodeUnstageVolume(context.Background(), &csi.NodeUnstageVolumeRequest{})
+	assert.Equals(t, codes.Unimplemented, status.Code(err))
+}
+
+func TestNodePublishVolumeStuckMountpointPod(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+	}{
+		{name: "insufficient resources", err: &mppod.Stuck{Cause: mppod.StuckInsufficientResources}, wantCode: codes.ResourceExhausted},
+		{name: "untolerated taint", err: &mppod.Stuck{Cause: mppod.StuckUnschedulable}, wantCode: codes.FailedPrecondition},
+		{name: "image pull failure", err: &mppod.Stuck{Cause: mppod.StuckImagePull}, wantCode: codes.FailedPrecondition},
+		{name: "other failure", err: errors.New("mount failed"), wantCode: codes.Internal},
+		{name: "rejected credentials", err: mounterror.New(mounterror.PhaseMount, 1, []byte("InvalidAccessKeyId")), wantCode: codes.Unauthenticated},
+		{name: "missing bucket", err: mounterror.New(mounterror.PhaseMount, 1, []byte("NoSuchBucket")), wantCode: codes.NotFound},
+		{name: "unreachable endpoint", err: mounterror.New(mounterror.PhaseMount, 1, []byte("dns error")), wantCode: codes.Unavailable},
+		{name: "unclassified Mountpoint failure", err: mounterror.New(mounterror.PhaseMount, 1, []byte("panic")), wantCode: codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodeTestEnv := initNodeServerTestEnv(t)
+			targetPath := filepath.Join(t.TempDir(), "target")
+			nodeTestEnv.mockMounter.EXPECT().
+				Mount(gomock.Any(), gomock.Any(), gomock.Eq(targetPath), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(fmt.Errorf("failed to wait for Mountpoint Pod: %w", tt.err))
+
+			_, err := nodeTestEnv.server.NodePublishVolume(context.Background(), &csi.NodePublishVolumeRequest{
+				VolumeId: "test-volume-id",
+				VolumeCapability: &csi.VolumeCapability{
+					AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+					AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER},
+				},
+				TargetPath:    targetPath,
+				VolumeContext: map[string]string{"bucketName": "test-bucket"},
+			})
+			assert.Equals(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
+
+func TestNodePublishVolumeReportsMountFailures(t *testing.T) {
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	defer endpoint.Close()
+
+	tests := []struct {
+		name      string
+		reachable bool
+		err       error
+		wantCode  codes.Code
+		wantEvent string
+	}{
+		{name: "unreachable endpoint", reachable: false, err: errors.New("mountpoint Pod failed"), wantCode: codes.Internal, wantEvent: "Warning S3EndpointUnreachable"},
+		{name: "rejected credentials", reachable: true, err: errors.New("mountpoint Pod failed: InvalidAccessKeyId"), wantCode: codes.Internal, wantEvent: "Warning S3CredentialsRejected"},
+		{name: "other failure", reachable: true, err: errors.New("mountpoint Pod failed: NoSuchBucket"), wantCode: codes.Internal},
+		{
+			name:      "classified missing bucket",
+			reachable: true,
+			err:       fmt.Errorf("mountpoint Pod failed: %w", mounterror.New(mounterror.PhaseMount, 1, []byte("NoSuchBucket"))),
+			wantCode:  codes.NotFound,
+			wantEvent: "Warning " + node.ReasonS3BucketNotFound,
+		},
+		{
+			name:      "classified binary failure",
+			reachable: true,
+			err:       mounterror.New(mounterror.PhaseSetup, 0, []byte("integrity verification of the Mountpoint binary failed")),
+			wantCode:  codes.FailedPrecondition,
+			wantEvent: "Warning " + node.ReasonMountpointBinaryFailed,
+		},
+		{
+			name:      "classified unsupported mount option",
+			reachable: true,
+			err:       mounterror.New(mounterror.PhaseSetup, 0, []byte("mount options not supported by Mountpoint 1.0.0: --sse requires Mountpoint 1.4.0 or later")),
+			wantCode:  codes.InvalidArgument,
+			wantEvent: "Warning " + node.ReasonMountOptionUnsupported,
+		},
+		{
+			name:      "classified unreachable endpoint despite probe",
+			reachable: true,
+			err:       mounterror.New(mounterror.PhaseMount, 1, []byte("connection refused")),
+			wantCode:  codes.Unavailable,
+			wantEvent: "Warning S3EndpointUnreachable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodeTestEnv := initNodeServerTestEnv(t)
+			endpointURL := endpoint.URL
+			if !tt.reachable {
+				endpointURL = "http://127.0.0.1:1"
+			}
+			prober, err := endpointprobe.NewProber(endpointURL, "")
+			assert.NoError(t, err)
+			_ = prober.Probe(context.Background())
+			events := record.NewFakeRecorder(1)
+			nodeTestEnv.server.EndpointProber = prober
+			nodeTestEnv.server.Events = events
+
+			targetPath := filepath.Join(t.TempDir(), "target")
+			nodeTestEnv.mockMounter.EXPECT().
+				Mount(gomock.Any(), gomock.Any(), gomock.Eq(targetPath), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(tt.err)
+
+			_, err = nodeTestEnv.server.NodePublishVolume(context.Background(), &csi.NodePublishVolumeRequest{
+				VolumeId: "test-volume-id",
+				VolumeCapability: &csi.VolumeCapability{
+					AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+					AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER},
+				},
+				TargetPath: targetPath,
+				VolumeContext: map[string]string{
+					"bucketName":                  "test-bucket",
+					volumecontext.CSIPodName:      "workload",
+					volumecontext.CSIPodNamespace: "default",
+				},
+			})
+			assert.Equals(t, tt.wantCode, status.Code(err))
+
+			select {
+			case event := <-events.Events:
+				if tt.wantEvent == "" || !strings.HasPrefix(event, tt.wantEvent) {
+					t.Fatalf("Expected event %q, got %q", tt.wantEvent, event)
+				}
+			default:
+				if tt.wantEvent != "" {
+					t.Fatalf("Expected event %q, got none", tt.wantEvent)
+				}
+			}
+		})
+	}
+}
+
+func TestNodePublishVolumeValidatesVolumeAttributes(t *testing.T) {
+	tests := []struct {
+		name      string
+		volumeCtx map[string]string
+		wantCode  codes.Code
+		wantEvent string
+	}{
+		{
+			name:      "unknown attributes are reported on the workload Pod",
+			volumeCtx: map[string]string{"bucketName": "test-bucket", "ensurePrefx": "true"},
+			wantCode:  codes.OK,
+			wantEvent: `Warning UnknownVolumeAttributes Volume test-volume-id: unknown parameter "ensurePrefx" is ignored, did you mean "ensurePrefix"?`,
+		},
+		{
+			name:      "invalid values fail the mount",
+			volumeCtx: map[string]string{"bucketName": "test-bucket", volumecontext.Cache: "disk"},
+			wantCode:  codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodeTestEnv := initNodeServerTestEnv(t)
+			events := record.NewFakeRecorder(1)
+			nodeTestEnv.server.Events = events
+
+			targetPath := filepath.Join(t.TempDir(), "target")
+			if tt.wantCode == codes.OK {
+				nodeTestEnv.mockMounter.EXPECT().
+					Mount(gomock.Any(), gomock.Any(), gomock.Eq(targetPath), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil)
+			}
+
+			tt.volumeCtx[volumecontext.CSIPodName] = "workload"
+			tt.volumeCtx[volumecontext.CSIPodNamespace] = "default"
+			_, err := nodeTestEnv.server.NodePublishVolume(context.Background(), &csi.NodePublishVolumeRequest{
+				VolumeId: "test-volume-id",
+				VolumeCapability: &csi.VolumeCapability{
+					AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+					AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER},
+				},
+				TargetPath:    targetPath,
+				VolumeContext: tt.volumeCtx,
+			})
+			assert.Equals(t, tt.wantCode, status.Code(err))
+
+			select {
+			case event := <-events.Events:
+				assert.Equals(t, tt.wantEvent, event)
+			default:
+				if tt.wantEvent != "" {
+					t.Fatalf("Expected event %q, got none", tt.wantEvent)
+				}
+			}
+		})
+	}
+}
+
+// fakeRegionAPI serves the location of buckets in `regions`, other buckets do not exist.
+type fakeRegionAPI struct {
+	regions map[string]string
+	calls   int
+}
+
+type notFoundError struct{}
+
+func (notFoundError) Error() string       { return "NoSuchBucket" }
+func (notFoundError) HTTPStatusCode() int { return http.StatusNotFound }
+
+func (f *fakeRegionAPI) GetBucketLocation(ctx context.Context, params *s3.GetBucketLocationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLocationOutput, error) {
+	f.calls++
+	region, ok := f.regions[*params.Bucket]
+	if !ok {
+		return nil, notFoundError{}
+	}
+	return &s3.GetBucketLocationOutput{LocationConstraint: s3types.BucketLocationConstraint(region)}, nil
+}
+
+func (f *fakeRegionAPI) HeadBucket(ctx context.Context, params *s3.HeadBucketInput, optFns ...func(*s3.Options)) (*s3.HeadBucketOutput, error) {
+	return nil, notFoundError{}
+}
+
+func TestNodePublishVolumeDiscoversRegion(t *testing.T) {
+	tests := []struct {
+		name         string
+		bucket       string
+		mountOptions []string
+		volumeCtx    map[string]string
+		wantRegion   string
+		wantCode     codes.Code
+	}{
+		{name: "discovered region", bucket: "eu-bucket", wantRegion: "eu-west-1"},
+		{name: "region mount option", bucket: "eu-bucket", mountOptions: []string{"region=us-west-2"}, wantRegion: "us-west-2"},
+		{name: "missing bucket", bucket: "missing-bucket", wantCode: codes.NotFound},
+		{name: "role credentials", bucket: "missing-bucket", volumeCtx: map[string]string{"authenticationSource": "role", "roleArn": "arn:aws:iam::123456789012:role/volume"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodeTestEnv := initNodeServerTestEnv(t)
+			t.Setenv(credentialprovider.EnvAllowedRoleARNs, "arn:aws:iam::123456789012:role/volume")
+			api := &fakeRegionAPI{regions: map[string]string{"eu-bucket": "eu-west-1"}}
+			nodeTestEnv.server.RegionResolver = bucketregion.NewResolver(api)
+
+			targetPath := filepath.Join(t.TempDir(), "target")
+			if tt.wantCode == codes.OK {
+				nodeTestEnv.mockMounter.EXPECT().
+					Mount(gomock.Any(), gomock.Eq(tt.bucket), gomock.Eq(targetPath), gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, _, _ string, credentialCtx credentialprovider.ProvideContext, args mountpoint.Args, _ string) error {
+						region, _ := args.Value(mountpoint.ArgRegion)
+						assert.Equals(t, tt.wantRegion, region)
+						assert.Equals(t, tt.wantRegion, credentialCtx.BucketRegion)
+						return nil
+					})
+			}
+
+			volumeCtx := map[string]string{"bucketName": tt.bucket}
+			for key, value := range tt.volumeCtx {
+				volumeCtx[key] = value
+			}
+			_, err := nodeTestEnv.server.NodePublishVolume(context.Background(), &csi.NodePublishVolumeRequest{
+				VolumeId: "test-volume-id",
+				VolumeCapability: &csi.VolumeCapability{
+					AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{MountFlags: tt.mountOptions}},
+					AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER},
+				},
+				TargetPath:    targetPath,
+				VolumeContext: volumeCtx,
+			})
+			assert.Equals(t, tt.wantCode, status.Code(err))
+			if tt.mountOptions != nil || tt.volumeCtx != nil {
+				assert.Equals(t, 0, api.calls)
+			}
+		})
+	}
+}
+
+type forbiddenError struct{}
+
+func (forbiddenError) Error() string       { return "AccessDenied" }
+func (forbiddenError) HTTPStatusCode() int { return http.StatusForbidden }
+
+type fakePrefixAPI struct {
+	keys   map[string]bool
+	putErr error
+	puts   []string
+}
+
+func (f *fakePrefixAPI) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	output := &s3.ListObjectsV2Output{}
+	for key := range f.keys {
+		if strings.HasPrefix(key, *params.Prefix) {
+			output.Contents = append(output.Contents, s3types.Object{Key: aws.String(key)})
+		}
+	}
+	return output, nil
+}
+
+func (f *fakePrefixAPI) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	if f.putErr != nil {
+		return nil, f.putErr
+	}
+	f.puts = append(f.puts, *params.Key)
+	return &s3.PutObjectOutput{}, nil
+}
+
+func TestNodePublishVolumeEnsuresPrefix(t *testing.T) {
+	tests := []struct {
+		name         string
+		mountOptions []string
+		volumeCtx    map[string]string
+		putErr       error
+		wantPuts     []string
+		wantCode     codes.Code
+	}{
+		{name: "existing prefix", mountOptions: []string{"prefix=existing/"}},
+		{name: "creates the directory marker", mountOptions: []string{"prefix=new/"}, wantPuts: []string{"new/"}},
+		{name: "read-only volume", mountOptions: []string{"prefix=new/", "read-only"}, wantCode: codes.NotFound},
+		{name: "PutObject denied", mountOptions: []string{"prefix=new/"}, putErr: forbiddenError{}, wantCode: codes.PermissionDenied},
+		{name: "no prefix", wantCode: codes.InvalidArgument},
+		{name: "role credentials", mountOptions: []string{"prefix=new/"}, volumeCtx: map[string]string{"authenticationSource": "role", "roleArn": "arn:aws:iam::123456789012:role/volume"}, wantCode: codes.InvalidArgument},
+		{name: "invalid value", mountOptions: []string{"prefix=new/"}, volumeCtx: map[string]string{"ensurePrefix": "yes"}, wantCode: codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodeTestEnv := initNodeServerTestEnv(t)
+			t.Setenv(credentialprovider.EnvAllowedRoleARNs, "arn:aws:iam::123456789012:role/volume")
+			api := &fakePrefixAPI{keys: map[string]bool{"existing/file": true}, putErr: tt.putErr}
+			nodeTestEnv.server.PrefixMarker = prefixmarker.NewMarker(api)
+
+			targetPath := filepath.Join(t.TempDir(), "target")
+			if tt.wantCode == codes.OK {
+				nodeTestEnv.mockMounter.EXPECT().
+					Mount(gomock.Any(), gomock.Eq("bucket"), gomock.Eq(targetPath), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil)
+			}
+
+			volumeCtx := map[string]string{"bucketName": "bucket", "ensurePrefix": "true"}
+			for key, value := range tt.volumeCtx {
+				volumeCtx[key] = value
+			}
+			_, err := nodeTestEnv.server.NodePublishVolume(context.Background(), &csi.NodePublishVolumeRequest{
+				VolumeId: "test-volume-id",
+				VolumeCapability: &csi.VolumeCapability{
+					AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{MountFlags: tt.mountOptions}},
+					AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER},
+				},
+				TargetPath:    targetPath,
+				VolumeContext: volumeCtx,
+			})
+			assert.Equals(t, tt.wantCode, status.Code(err))
+			assert.Equals(t, len(tt.wantPuts), len(api.puts))
+			for i := range tt.wantPuts {
+				assert.Equals(t, tt.wantPuts[i], api.puts[i])
+			}
+		})
+	}
+}
+
+func TestNodePublishVolumeVerifiesMount(t *testing.T) {
+	tests := []struct {
+		name        string
+		verifyMount string
+		// mountFile makes the mock mount a file at the target, which cannot be accessed like a directory
+		mountFile   bool
+		wantUnmount bool
+		wantCode    codes.Code
+	}{
+		{name: "list", verifyMount: "list"},
+		{name: "head", verifyMount: "head"},
+		{name: "off", verifyMount: "off", mountFile: true},
+		{name: "failing list", verifyMount: "list", mountFile: true, wantUnmount: true, wantCode: codes.FailedPrecondition},
+		{name: "failing head", verifyMount: "head", mountFile: true, wantUnmount: true, wantCode: codes.FailedPrecondition},
+		{name: "invalid value", verifyMount: "stat", wantCode: codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodeTestEnv := initNodeServerTestEnv(t)
+
+			targetPath := filepath.Join(t.TempDir(), "target")
+			if tt.wantCode != codes.InvalidArgument {
+				nodeTestEnv.mockMounter.EXPECT().
+					Mount(gomock.Any(), gomock.Eq("bucket"), gomock.Eq(targetPath), gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, _, target string, _ credentialprovider.ProvideContext, _ mountpoint.Args, _ string) error {
+						if tt.mountFile {
+							return os.WriteFile(target, nil, 0o644)
+						}
+						return os.Mkdir(target, 0o755)
+					})
+			}
+			if tt.wantUnmount {
+				nodeTestEnv.mockMounter.EXPECT().
+					Unmount(gomock.Any(), gomock.Eq(targetPath), gomock.Eq(credentialprovider.CleanupContext{VolumeID: "test-volume-id"})).
+					Return(nil)
+			}
+
+			_, err := nodeTestEnv.server.NodePublishVolume(context.Background(), &csi.NodePublishVolumeRequest{
+				VolumeId: "test-volume-id",
+				VolumeCapability: &csi.VolumeCapability{
+					AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+					AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER},
+				},
+				TargetPath:    targetPath,
+				VolumeContext: map[string]string{"bucketName": "bucket", "verifyMount": tt.verifyMount},
+			})
+			assert.Equals(t, tt.wantCode, status.Code(err))
+			if tt.wantUnmount {
+				if _, err := os.Stat(targetPath); !errors.Is(err, fs.ErrNotExist) {
+					t.Fatalf("Expected target to be removed, got %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestNodePublishVolumeAddressing(t *testing.T) {
+	tests := []struct {
+		name         string
+		volumeCtx    map[string]string
+		mountOptions []string
+		endpointURL  string
+		wantBucket   string
+		wantArgs     []string
+		wantCode     codes.Code
+	}{
+		{name: "path-style by default", wantBucket: "bucket", wantArgs: []string{"--allow-root", "--force-path-style"}},
+		{name: "path-style", volumeCtx: map[string]string{"addressingStyle": "path"}, wantBucket: "bucket", wantArgs: []string{"--allow-root", "--force-path-style"}},
+		{name: "virtual-hosted", volumeCtx: map[string]string{"addressingStyle": "virtual"}, endpointURL: "https://s3.example.com", wantBucket: "bucket", wantArgs: []string{"--allow-root"}},
+		{name: "bucket alias", volumeCtx: map[string]string{"bucketAlias": "bucket-alias"}, wantBucket: "bucket-alias", wantArgs: []string{"--allow-root", "--force-path-style"}},
+		{name: "virtual-hosted bucket alias of a bucket with dots", volumeCtx: map[string]string{"bucketName": "my.bucket", "bucketAlias": "my-bucket", "addressingStyle": "virtual"}, endpointURL: "https://s3.example.com", wantBucket: "my-bucket", wantArgs: []string{"--allow-root"}},
+		{name: "virtual-hosted bucket with dots on HTTPS", volumeCtx: map[string]string{"bucketName": "my.bucket", "addressingStyle": "virtual"}, endpointURL: "https://s3.example.com", wantCode: codes.InvalidArgument},
+		{name: "virtual-hosted on an IP address", volumeCtx: map[string]string{"addressingStyle": "virtual"}, endpointURL: "http://10.0.0.1:8000", wantCode: codes.InvalidArgument},
+		{name: "virtual-hosted with force-path-style", volumeCtx: map[string]string{"addressingStyle": "virtual"}, mountOptions: []string{"force-path-style"}, wantCode: codes.InvalidArgument},
+		{name: "invalid addressing style", volumeCtx: map[string]string{"addressingStyle": "host"}, wantCode: codes.InvalidArgument},
+		{name: "invalid bucket alias", volumeCtx: map[string]string{"bucketAlias": "Bucket_Alias"}, wantCode: codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("AWS_ENDPOINT_URL", tt.endpointURL)
+			nodeTestEnv := initNodeServerTestEnv(t)
+
+			targetPath := filepath.Join(t.TempDir(), "target")
+			if tt.wantCode == codes.OK {
+				nodeTestEnv.mockMounter.EXPECT().
+					Mount(gomock.Any(), gomock.Eq(tt.wantBucket), gomock.Eq(targetPath), gomock.Any(), gomock.Eq(mountpoint.ParseArgs(tt.wantArgs)), gomock.Any()).
+					Return(nil)
+			}
+
+			volumeCtx := map[string]string{"bucketName": "bucket"}
+			for key, value := range tt.volumeCtx {
+				volumeCtx[key] = value
+			}
+			_, err := nodeTestEnv.server.NodePublishVolume(context.Background(), &csi.NodePublishVolumeRequest{
+				VolumeId: "test-volume-id",
+				VolumeCapability: &csi.VolumeCapability{
+					AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{MountFlags: tt.mountOptions}},
+					AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER},
+				},
+				TargetPath:    targetPath,
+				VolumeContext: volumeCtx,
+			})
+			assert.Equals(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
+
+func TestNodePublishVolumeWithSecretSettings(t *testing.T) {
+	tests := []struct {
+		name           string
+		volumeCtx      map[string]string
+		mountOptions   []string
+		secrets        map[string]string
+		wantSecretData map[string]string
+		wantArgs       []string
+		wantCode       codes.Code
+	}{
+		{
+			name:           "canonical keys",
+			volumeCtx:      map[string]string{"authenticationSource": "secret"},
+			secrets:        map[string]string{"access_key_id": "key", "secret_access_key": "secret"},
+			wantSecretData: map[string]string{"access_key_id": "key", "secret_access_key": "secret"},
+			wantArgs:       []string{"--allow-root", "--force-path-style"},
+		},
+		{
+			name:           "mapped keys",
+			volumeCtx:      map[string]string{"authenticationSource": "secret", "secretKeyMapping": "access_key_id=accessKeyID, secret_access_key=secretAccessKey"},
+			secrets:        map[string]string{"accessKeyID": "key", "secretAccessKey": "secret"},
+			wantSecretData: map[string]string{"access_key_id": "key", "secret_access_key": "secret"},
+			wantArgs:       []string{"--allow-root", "--force-path-style"},
+		},
+		{
+			name:           "region and endpoint of the Secret",
+			volumeCtx:      map[string]string{"authenticationSource": "secret"},
+			secrets:        map[string]string{"access_key_id": "key", "secret_access_key": "secret", "region": "eu-west-1", "endpoint_url": "https://s3.other.example.com"},
+			wantSecretData: map[string]string{"access_key_id": "key", "secret_access_key": "secret", "region": "eu-west-1", "endpoint_url": "https://s3.other.example.com"},
+			wantArgs:       []string{"--allow-root", "--force-path-style", "--region=eu-west-1", "--endpoint-url=https://s3.other.example.com"},
+		},
+		{
+			name:           "region and endpoint of mount options",
+			volumeCtx:      map[string]string{"authenticationSource": "secret"},
+			mountOptions:   []string{"region=us-east-1", "endpoint-url=https://s3.example.com"},
+			secrets:        map[string]string{"access_key_id": "key", "secret_access_key": "secret", "region": "eu-west-1", "endpoint_url": "https://s3.other.example.com"},
+			wantSecretData: map[string]string{"access_key_id": "key", "secret_access_key": "secret", "region": "eu-west-1", "endpoint_url": "https://s3.other.example.com"},
+			wantArgs:       []string{"--allow-root", "--force-path-style", "--region=us-east-1", "--endpoint-url=https://s3.example.com"},
+		},
+		{
+			name:           "Secret settings ignored without authenticationSource: secret",
+			volumeCtx:      map[string]string{"secretKeyMapping": "access_key_id=accessKeyID"},
+			secrets:        map[string]string{"accessKeyID": "key", "region": "eu-west-1"},
+			wantSecretData: map[string]string{"accessKeyID": "key", "region": "eu-west-1"},
+			wantArgs:       []string{"--allow-root", "--force-path-style"},
+		},
+		{
+			name:      "invalid mapping",
+			volumeCtx: map[string]string{"authenticationSource": "secret", "secretKeyMapping": "access_key_id"},
+			secrets:   map[string]string{"access_key_id": "key", "secret_access_key": "secret"},
+			wantCode:  codes.InvalidArgument,
+		},
+		{
+			name:      "mapping of an unknown key",
+			volumeCtx: map[string]string{"authenticationSource": "secret", "secretKeyMapping": "password=secretAccessKey"},
+			secrets:   map[string]string{"access_key_id": "key", "secretAccessKey": "secret"},
+			wantCode:  codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodeTestEnv := initNodeServerTestEnv(t)
+
+			targetPath := filepath.Join(t.TempDir(), "target")
+			if tt.wantCode == codes.OK {
+				nodeTestEnv.mockMounter.EXPECT().
+					Mount(gomock.Any(), gomock.Eq("bucket"), gomock.Eq(targetPath), gomock.Any(), gomock.Eq(mountpoint.ParseArgs(tt.wantArgs)), gomock.Any()).
+					DoAndReturn(func(_ context.Context, _, _ string, credentialCtx credentialprovider.ProvideContext, _ mountpoint.Args, _ string) error {
+						assert.Equals(t, tt.wantSecretData, credentialCtx.SecretData)
+						return nil
+					})
+			}
+
+			volumeCtx := map[string]string{"bucketName": "bucket"}
+			for key, value := range tt.volumeCtx {
+				volumeCtx[key] = value
+			}
+			_, err := nodeTestEnv.server.NodePublishVolume(context.Background(), &csi.NodePublishVolumeRequest{
+				VolumeId: "test-volume-id",
+				VolumeCapability: &csi.VolumeCapability{
+					AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{MountFlags: tt.mountOptions}},
+					AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER},
+				},
+				TargetPath:    targetPath,
+				VolumeContext: volumeCtx,
+				Secrets:       tt.secrets,
+			})
+			assert.Equals(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
+
+type fakeAuditAPI struct {
+	bodies [][]byte
+}
+
+func (f *fakeAuditAPI) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	body, err := io.ReadAll(params.Body)
+	f.bodies = append(f.bodies, body)
+	return &s3.PutObjectOutput{}, err
+}
+
+func TestNodePublishVolumeRecordsMountAudit(t *testing.T) {
+	nodeTestEnv := initNodeServerTestEnv(t)
+	api := &fakeAuditAPI{}
+	nodeTestEnv.server.MountAudit = mountaudit.NewLog(api, "audit", "", "node-1")
+	ctx := context.Background()
+	targetPath := filepath.Join(t.TempDir(), "target")
+	volCap := &csi.VolumeCapability{
+		AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{MountFlags: []string{"prefix=data/"}}},
+		AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER},
+	}
+
+	nodeTestEnv.mockMounter.EXPECT().Mount(gomock.Any(), gomock.Eq("bucket"), gomock.Eq(targetPath), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	_, err := nodeTestEnv.server.NodePublishVolume(ctx, &csi.NodePublishVolumeRequest{
+		VolumeId:         "test-volume-id",
+		VolumeCapability: volCap,
+		TargetPath:       targetPath,
+		VolumeContext: map[string]string{
+			"bucketName":                        "bucket",
+			volumecontext.CSIPodName:            "app",
+			volumecontext.CSIPodNamespace:       "team-a",
+			volumecontext.CSIServiceAccountName: "default",
+		},
+	})
+	assert.NoError(t, err)
+
+	_, err = nodeTestEnv.server.NodePublishVolume(ctx, &csi.NodePublishVolumeRequest{
+		VolumeId:         "test-volume-id",
+		VolumeCapability: volCap,
+		TargetPath:       targetPath,
+	})
+	assert.Equals(t, codes.InvalidArgument, status.Code(err))
+
+	nodeTestEnv.mockMounter.EXPECT().IsMountPoint(gomock.Eq(targetPath)).Return(true, nil)
+	nodeTestEnv.mockMounter.EXPECT().Unmount(gomock.Eq(ctx), gomock.Eq(targetPath), gomock.Any()).Return(nil)
+	_, err = nodeTestEnv.server.NodeUnpublishVolume(ctx, &csi.NodeUnpublishVolumeRequest{VolumeId: "test-volume-id", TargetPath: targetPath})
+	assert.NoError(t, err)
+
+	assert.NoError(t, nodeTestEnv.server.MountAudit.Flush(ctx))
+	assert.Equals(t, 1, len(api.bodies))
+	var records []mountaudit.Record
+	for _, line := range strings.Split(strings.TrimSpace(string(api.bodies[0])), "\n") {
+		var record mountaudit.Record
+		assert.NoError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	assert.Equals(t, 3, len(records))
+	assert.NoError(t, mountaudit.Verify(records, ""))
+
+	mount := records[0]
+	assert.Equals(t, mountaudit.OperationMount, mount.Operation)
+	assert.Equals(t, mountaudit.ResultSuccess, mount.Result)
+	assert.Equals(t, "team-a", mount.Namespace)
+	assert.Equals(t, "default", mount.ServiceAccount)
+	assert.Equals(t, "data/", mount.Prefix)
+	assert.Equals(t, mountaudit.ResultFailure, records[1].Result)
+	assert.Equals(t, codes.InvalidArgument.String(), records[1].Code)
+	assert.Equals(t, mountaudit.OperationUnmount, records[2].Operation)
+	assert.Equals(t, "app", records[2].Pod)
+}
+
+func TestNodePublishVolumeSelectsEndpoint(t *testing.T) {
+	reachable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer reachable.Close()
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+
+	tests := []struct {
+		name            string
+		volumeCtx       map[string]string
+		mountOptions    []string
+		wantEndpointURL string
+		wantCode        codes.Code
+	}{
+		{name: "failover endpoint of the driver", wantEndpointURL: reachable.URL},
+		{name: "endpoints of the volume", volumeCtx: map[string]string{"endpointUrls": "https://s3.other.example.com, " + reachable.URL}, wantEndpointURL: reachable.URL},
+		{name: "endpoint of the volume", mountOptions: []string{"endpoint-url=https://s3.other.example.com"}, wantEndpointURL: "https://s3.other.example.com"},
+		{name: "endpoint and endpoints of the volume", volumeCtx: map[string]string{"endpointUrls": reachable.URL}, mountOptions: []string{"endpoint-url=https://s3.other.example.com"}, wantCode: codes.InvalidArgument},
+		{name: "endpoints of the volume not allowed", volumeCtx: map[string]string{"endpointUrls": "https://s3.malicious.example.com"}, wantCode: codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("AWS_ENDPOINT_URL", unreachable.URL)
+			t.Setenv(endpointfailover.EnvFailoverEndpointURLs, reachable.URL)
+			t.Setenv(mounter.EnvAllowedEndpointURLs, "https://s3.other.example.com")
+
+			nodeTestEnv := initNodeServerTestEnv(t)
+			selector, err := endpointfailover.NewSelector("", false)
+			assert.NoError(t, err)
+			nodeTestEnv.server.EndpointFailover = selector
+
+			targetPath := filepath.Join(t.TempDir(), "target")
+			if tt.wantCode == codes.OK {
+				nodeTestEnv.mockMounter.EXPECT().
+					Mount(gomock.Any(), gomock.Eq("bucket"), gomock.Eq(targetPath), gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, _, _ string, _ credentialprovider.ProvideContext, args mountpoint.Args, _ string) error {
+						endpointURL, _ := args.Value(mountpoint.ArgEndpointURL)
+						assert.Equals(t, tt.wantEndpointURL, endpointURL)
+						return nil
+					})
+			}
+
+			volumeCtx := map[string]string{"bucketName": "bucket"}
+			for key, value := range tt.volumeCtx {
+				volumeCtx[key] = value
+			}
+			_, err = nodeTestEnv.server.NodePublishVolume(context.Background(), &csi.NodePublishVolumeRequest{
+				VolumeId: "test-volume-id",
+				VolumeCapability: &csi.VolumeCapability{
+					AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{MountFlags: tt.mountOptions}},
+					AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER},
+				},
+				TargetPath:    targetPath,
+				VolumeContext: volumeCtx,
+			})
+			assert.Equals(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
+
+// testBucketPolicy returns a namespace bucket policy allowing the namespace `team-a` to mount buckets matching `team-a-*`.
+func testBucketPolicy(t *testing.T) *bucketpolicy.FileLoader {
+	t.Helper()
+	policyFile := filepath.Join(t.TempDir(), "policy.json")
+	policy := `{"namespaces": {"team-a": {"buckets": ["team-a-*"]}}}`
+	if err := os.WriteFile(policyFile, []byte(policy), 0o600); err != nil {
+		t.Fatalf("Failed to write the namespace bucket policy: %v", err)
+	}
+	return bucketpolicy.NewFileLoader(policyFile)
+}
@@ -0,0 +1,182 @@
+// Package nodelabel labels the Node of the node plugin with the readiness and version of the driver, so workloads
+// using S3 volumes can require nodes where the driver is ready with a node affinity.
+package nodelabel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util"
+)
+
+// EnvNodeLabelsEnabled is the environment variable enabling labels of the Node of the node plugin.
+const EnvNodeLabelsEnabled = "NODE_LABELS_ENABLED"
+
+// Labels of the Node of the node plugin, of the default driver instance. Other instances use the same labels prefixed
+// with their driver name instead, see [Labels].
+const (
+	// LabelReady is `true` while the driver is registered with kubelet and, if probed, the S3 endpoint is reachable,
+	// `false` otherwise.
+	LabelReady = constants.DriverName + "/ready"
+	// LabelVersion is the version of the driver running on the node.
+	LabelVersion = constants.DriverName + "/version"
+)
+
+// Labels returns the ready and version labels of the driver instance `driverName`, [LabelReady] and [LabelVersion]
+// for the default instance, so several instances installed in a cluster label the same Node independently.
+func Labels(driverName string) (ready, version string) {
+	return driverName + "/ready", driverName + "/version"
+}
+
+// CheckInterval is how often the labels are checked for changes.
+const CheckInterval = 30 * time.Second
+
+// maxLabelValueLen is the maximum length of a label value.
+const maxLabelValueLen = 63
+
+// invalidLabelValueChars matches characters not allowed in a label value.
+var invalidLabelValueChars = regexp.MustCompile(`[^-A-Za-z0-9_.]`)
+
+// A Labeler keeps [LabelReady] and [LabelVersion] of the Node of the node plugin current.
+type Labeler struct {
+	client   kubernetes.Interface
+	nodeName string
+	version  string
+	// readyLabel and versionLabel are the labels of the driver instance, see [Labels].
+	readyLabel   string
+	versionLabel string
+	// readinessCheck returns an error if the node plugin cannot serve mounts for a reason other than its registration,
+	// e.g. an unreachable S3 endpoint. Only the registration is checked if nil.
+	readinessCheck func() error
+
+	mu         sync.Mutex
+	lastLabels map[string]string
+	// stopped is set by [Labeler.MarkNotReady], the labels are not updated anymore afterwards.
+	stopped bool
+}
+
+// NewLabeler creates a new [Labeler] of Node `nodeName` running `version` of the driver.
+func NewLabeler(client kubernetes.Interface, nodeName, version string) *Labeler {
+	readyLabel, versionLabel := Labels(util.DriverName())
+	return &Labeler{
+		client:       client,
+		nodeName:     nodeName,
+		version:      labelValue(version),
+		readyLabel:   readyLabel,
+		versionLabel: versionLabel,
+	}
+}
+
+// SetReadinessCheck sets a check the node plugin must pass to be ready, in addition to its registration with kubelet.
+func (l *Labeler) SetReadinessCheck(check func() error) {
+	l.readinessCheck = check
+}
+
+// Start updates the labels every `interval` until `stopCh` is closed.
+func (l *Labeler) Start(stopCh <-chan struct{}, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := l.Run(context.Background()); err != nil {
+			klog.Warningf("Failed to label node %s: %v", l.nodeName, err)
+		}
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Run updates the labels if the readiness or version of the driver changed since they were last written.
+func (l *Labeler) Run(ctx context.Context) error {
+	ready, reason := l.ready(ctx)
+	if !ready {
+		klog.V(4).Infof("Node plugin on node %s is not ready: %s", l.nodeName, reason)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.stopped {
+		return nil
+	}
+	return l.patch(ctx, map[string]string{
+		l.readyLabel:   fmt.Sprint(ready),
+		l.versionLabel: l.version,
+	})
+}
+
+// MarkNotReady sets [LabelReady] to `false` for good, when the node plugin is stopping.
+func (l *Labeler) MarkNotReady(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stopped = true
+	return l.patch(ctx, map[string]string{
+		l.readyLabel:   "false",
+		l.versionLabel: l.version,
+	})
+}
+
+// ready returns whether the driver is registered with kubelet and passes the readiness check, or why not.
+func (l *Labeler) ready(ctx context.Context) (bool, string) {
+	csiNode, err := l.client.StorageV1().CSINodes().Get(ctx, l.nodeName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, "CSINode not found"
+	}
+	if err != nil {
+		return false, fmt.Sprintf("failed to get CSINode: %v", err)
+	}
+	if !slices.ContainsFunc(csiNode.Spec.Drivers, func(d storagev1.CSINodeDriver) bool { return d.Name == util.DriverName() }) {
+		return false, "driver not registered with kubelet"
+	}
+	if l.readinessCheck != nil {
+		if err := l.readinessCheck(); err != nil {
+			return false, err.Error()
+		}
+	}
+	return true, ""
+}
+
+// patch writes `labels` if they differ from the last ones written. It must be called with `mu` held.
+func (l *Labeler) patch(ctx context.Context, labels map[string]string) error {
+	if maps.Equal(labels, l.lastLabels) {
+		return nil
+	}
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{"labels": labels},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := l.client.CoreV1().Nodes().Patch(ctx, l.nodeName, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return err
+	}
+	klog.Infof("Labeled node %s with %s=%s, %s=%s", l.nodeName, l.readyLabel, labels[l.readyLabel], l.versionLabel, labels[l.versionLabel])
+	l.lastLabels = labels
+	return nil
+}
+
+// labelValue returns `value` made a valid label value, e.g. `v2.2.0+dirty` becomes `v2.2.0-dirty`.
+func labelValue(value string) string {
+	value = invalidLabelValueChars.ReplaceAllString(value, "-")
+	if len(value) > maxLabelValueLen {
+		value = value[:maxLabelValueLen]
+	}
+	return strings.Trim(value, "-_.")
+}
@@ -0,0 +1,98 @@
+package nodelabel
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestLabeler(t *testing.T) {
+	client := fake.NewClientset(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}})
+	labeler := NewLabeler(client, "node-1", "v2.2.0")
+	ctx := context.Background()
+
+	patches := func() int {
+		count := 0
+		for _, action := range client.Actions() {
+			if _, ok := action.(k8stesting.PatchAction); ok {
+				count++
+			}
+		}
+		return count
+	}
+	labels := func() map[string]string {
+		node, err := client.CoreV1().Nodes().Get(ctx, "node-1", metav1.GetOptions{})
+		assert.NoError(t, err)
+		return node.Labels
+	}
+
+	// The driver is not registered with kubelet yet
+	assert.NoError(t, labeler.Run(ctx))
+	assert.Equals(t, map[string]string{LabelReady: "false", LabelVersion: "v2.2.0"}, labels())
+
+	_, err := client.StorageV1().CSINodes().Create(ctx, &storagev1.CSINode{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Spec:       storagev1.CSINodeSpec{Drivers: []storagev1.CSINodeDriver{{Name: constants.DriverName, NodeID: "node-1"}}},
+	}, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, labeler.Run(ctx))
+	assert.Equals(t, map[string]string{LabelReady: "true", LabelVersion: "v2.2.0"}, labels())
+
+	// Unchanged labels are not patched again
+	assert.NoError(t, labeler.Run(ctx))
+	assert.Equals(t, 2, patches())
+
+	// Failing readiness check
+	labeler.SetReadinessCheck(func() error { return errors.New("S3 endpoint unreachable") })
+	assert.NoError(t, labeler.Run(ctx))
+	assert.Equals(t, "false", labels()[LabelReady])
+
+	labeler.SetReadinessCheck(nil)
+	assert.NoError(t, labeler.Run(ctx))
+	assert.Equals(t, "true", labels()[LabelReady])
+
+	// Stopping node plugin
+	assert.NoError(t, labeler.MarkNotReady(ctx))
+	assert.Equals(t, "false", labels()[LabelReady])
+	assert.NoError(t, labeler.Run(ctx))
+	assert.Equals(t, "false", labels()[LabelReady])
+}
+
+func TestLabelerOfOtherDriverInstance(t *testing.T) {
+	t.Setenv("CSI_DRIVER_NAME", "lab.s3.csi.scality.com")
+	ctx := context.Background()
+	client := fake.NewClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{LabelReady: "true"}}},
+		&storagev1.CSINode{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Spec:       storagev1.CSINodeSpec{Drivers: []storagev1.CSINodeDriver{{Name: constants.DriverName, NodeID: "node-1"}}},
+		},
+	)
+
+	// The default instance is registered, not this one, and its labels are left untouched
+	assert.NoError(t, NewLabeler(client, "node-1", "v2.2.0").Run(ctx))
+	node, err := client.CoreV1().Nodes().Get(ctx, "node-1", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equals(t, map[string]string{
+		LabelReady:                       "true",
+		"lab.s3.csi.scality.com/ready":   "false",
+		"lab.s3.csi.scality.com/version": "v2.2.0",
+	}, node.Labels)
+}
+
+func TestLabelValue(t *testing.T) {
+	assert.Equals(t, "v2.2.0", labelValue("v2.2.0"))
+	assert.Equals(t, "v2.2.0-dirty", labelValue("v2.2.0+dirty"))
+	assert.Equals(t, "abc", labelValue("-abc-"))
+	assert.Equals(t, 63, len(labelValue(strings.Repeat("a", 100))))
+}
@@ -0,0 +1,76 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/bucketregion"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/prefixmarker"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
+)
+
+// ensurePrefix checks the `--prefix` in `args` has objects in `bucket` if the volume with `volumeCtx` sets
+// [volumecontext.EnsurePrefix], and creates its directory marker if it has none and the volume is not read-only.
+//
+// The prefix is accessed with the credentials of the volume, only driver-level credentials and Secrets are
+// supported, and only on the driver-level endpoint, like region discovery.
+func (ns *S3NodeServer) ensurePrefix(ctx context.Context, volumeCtx map[string]string, bucket string, args mountpoint.Args, credentialCtx credentialprovider.ProvideContext) error {
+	ensure, err := volumecontext.ParseEnsurePrefix(volumeCtx)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "Invalid prefix: %v", err)
+	}
+	if !ensure {
+		return nil
+	}
+
+	prefix, _ := args.Value(mountpoint.ArgPrefix)
+	if prefix == "" || !strings.HasSuffix(prefix, "/") {
+		return status.Errorf(codes.InvalidArgument, "%s requires a prefix ending with /, got %q", volumecontext.EnsurePrefix, prefix)
+	}
+	if args.Has(mountpoint.ArgEndpointURL) {
+		return status.Errorf(codes.InvalidArgument, "%s is only supported on the driver-level S3 endpoint", volumecontext.EnsurePrefix)
+	}
+
+	var secretData map[string]string
+	switch credentialCtx.AuthenticationSource {
+	case credentialprovider.AuthenticationSourceUnspecified, credentialprovider.AuthenticationSourceDriver:
+	case credentialprovider.AuthenticationSourceSecret:
+		secretData = credentialCtx.SecretData
+	default:
+		return status.Errorf(codes.InvalidArgument, "%s is only supported with %s: driver or secret, got %q", volumecontext.EnsurePrefix, volumecontext.AuthenticationSource, credentialCtx.AuthenticationSource)
+	}
+	if ns.PrefixMarker == nil {
+		return status.Errorf(codes.FailedPrecondition, "Prefixes cannot be ensured by the node plugin")
+	}
+
+	volume := prefixmarker.Volume{Bucket: bucket, Prefix: prefix}
+	volume.Region, _ = args.Value(mountpoint.ArgRegion)
+	volume.SSE, _ = args.Value(mountpoint.ArgSSE)
+	volume.SSEKMSKeyID, _ = args.Value(mountpoint.ArgSSEKMSKeyID)
+	readOnly := args.Has(mountpoint.ArgReadOnly)
+
+	created, err := ns.PrefixMarker.Ensure(ctx, volume, bucketregion.Credentials(secretData), !readOnly)
+	switch {
+	case errors.Is(err, prefixmarker.ErrPrefixNotFound):
+		return status.Errorf(codes.NotFound, "Prefix %q of bucket %q has no objects and the volume is read-only, create it before mounting the volume", prefix, bucket)
+	case errors.Is(err, prefixmarker.ErrBucketNotFound):
+		return status.Errorf(codes.NotFound, "Bucket %q does not exist on the S3 endpoint: %v", bucket, err)
+	case errors.Is(err, prefixmarker.ErrListDenied):
+		return status.Errorf(codes.PermissionDenied, "Credentials of the volume are not allowed to list prefix %q of bucket %q, grant them s3:ListBucket on it: %v", prefix, bucket, err)
+	case errors.Is(err, prefixmarker.ErrPutDenied):
+		return status.Errorf(codes.PermissionDenied, "Credentials of the volume are not allowed to create the directory marker of prefix %q in bucket %q, grant them s3:PutObject on it or create the prefix before mounting the volume: %v", prefix, bucket, err)
+	case err != nil:
+		return status.Errorf(codes.Unavailable, "Could not ensure prefix %q of bucket %q: %v", prefix, bucket, err)
+	}
+	if created {
+		klog.V(4).Infof("Created the directory marker of prefix %q of bucket %s", prefix, bucket)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+// Package prefixmarker ensures the prefix of a volume exists before it is mounted, so a prefix without objects is not
+// silently mounted as an empty directory. Prefixes without objects get a zero-byte directory marker, the object
+// S3 consoles and Mountpoint create for empty directories.
+package prefixmarker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"k8s.io/klog/v2"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+)
+
+const (
+	// defaultRegion is the region requests are signed for if the region of the bucket is unknown.
+	defaultRegion = "us-east-1"
+	// ensureTimeout bounds the time spent ensuring the prefix of a volume.
+	ensureTimeout = 10 * time.Second
+)
+
+var (
+	// ErrPrefixNotFound is returned when the prefix has no objects and its directory marker is not created.
+	ErrPrefixNotFound = errors.New("prefix has no objects")
+	// ErrBucketNotFound is returned when the bucket does not exist on the S3 endpoint.
+	ErrBucketNotFound = errors.New("bucket does not exist")
+	// ErrListDenied is returned when the credentials of the volume are not allowed to list the prefix.
+	ErrListDenied = errors.New("listing the prefix is denied")
+	// ErrPutDenied is returned when the credentials of the volume are not allowed to create the directory marker.
+	ErrPutDenied = errors.New("creating the directory marker is denied")
+)
+
+// API is the subset of the S3 client used to ensure prefixes.
+type API interface {
+	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+}
+
+// A Volume is the prefix of a bucket to ensure.
+type Volume struct {
+	Bucket string
+	// Prefix ends with a `/`, like the `--prefix` of Mountpoint.
+	Prefix string
+	// Region is the region of the bucket, requests are signed for `us-east-1` if empty.
+	Region string
+	// SSE and SSEKMSKeyID are the server-side encryption of the directory marker, as `--sse` and `--sse-kms-key-id`
+	// of Mountpoint, so bucket policies requiring encryption accept it.
+	SSE         string
+	SSEKMSKeyID string
+}
+
+// A Marker ensures prefixes of the driver-level S3 endpoint exist.
+type Marker struct {
+	client API
+}
+
+// NewMarker creates a new [Marker] using `client`.
+func NewMarker(client API) *Marker {
+	return &Marker{client: client}
+}
+
+// NewMarkerFromEnv returns a new [Marker] for the driver-level S3 endpoint.
+func NewMarkerFromEnv(ctx context.Context) (*Marker, error) {
+	awsCfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(defaultRegion))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		o.UsePathStyle = true
+		o.BaseEndpoint = aws.String(os.Getenv(envprovider.EnvEndpointURL))
+	})
+	return NewMarker(client), nil
+}
+
+// Ensure checks the prefix of `volume` has objects, accessed with `credentials`, and creates its directory marker if
+// it has none and `create` is set. It returns whether the marker was created, [ErrPrefixNotFound] if the prefix has
+// no objects and `create` is unset, or [ErrBucketNotFound], [ErrListDenied] or [ErrPutDenied] if the bucket does not
+// exist or the credentials lack permissions.
+func (m *Marker) Ensure(ctx context.Context, volume Volume, credentials aws.CredentialsProvider, create bool) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, ensureTimeout)
+	defer cancel()
+	optFn := func(o *s3.Options) {
+		o.Credentials = credentials
+		if volume.Region != "" {
+			o.Region = volume.Region
+		}
+	}
+
+	output, err := m.client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+		Bucket:  aws.String(volume.Bucket),
+		Prefix:  aws.String(volume.Prefix),
+		MaxKeys: aws.Int32(1),
+	}, optFn)
+	if err != nil {
+		return false, classify(volume, ErrListDenied, err)
+	}
+	if len(output.Contents) > 0 {
+		return false, nil
+	}
+	if !create {
+		return false, fmt.Errorf("%w: %s/%s", ErrPrefixNotFound, volume.Bucket, volume.Prefix)
+	}
+
+	input := &s3.PutObjectInput{
+		Bucket:        aws.String(volume.Bucket),
+		Key:           aws.String(volume.Prefix),
+		Body:          strings.NewReader(""),
+		ContentLength: aws.Int64(0),
+	}
+	if volume.SSE != "" {
+		input.ServerSideEncryption = types.ServerSideEncryption(volume.SSE)
+		if volume.SSEKMSKeyID != "" {
+			input.SSEKMSKeyId = aws.String(volume.SSEKMSKeyID)
+		}
+	}
+	if _, err := m.client.PutObject(ctx, input, optFn); err != nil {
+		return false, classify(volume, ErrPutDenied, err)
+	}
+	klog.V(4).Infof("prefixmarker: Created the directory marker of %s/%s", volume.Bucket, volume.Prefix)
+	return true, nil
+}
+
+// classify wraps `err` with [ErrBucketNotFound], or with `denied` if it is an access denied error.
+func classify(volume Volume, denied, err error) error {
+	var statusErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &statusErr) {
+		switch statusErr.HTTPStatusCode() {
+		case http.StatusNotFound:
+			return fmt.Errorf("%w: %s: %v", ErrBucketNotFound, volume.Bucket, err)
+		case http.StatusForbidden:
+			return fmt.Errorf("%w: %s/%s: %v", denied, volume.Bucket, volume.Prefix, err)
+		}
+	}
+	return fmt.Errorf("failed to ensure prefix %s/%s: %w", volume.Bucket, volume.Prefix, err)
+}
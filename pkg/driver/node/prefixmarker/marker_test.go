@@ -0,0 +1,110 @@
+package prefixmarker_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/bucketregion"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/prefixmarker"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+// statusError is an error of the S3 client with an HTTP status.
+type statusError struct{ code int }
+
+func (e statusError) Error() string       { return http.StatusText(e.code) }
+func (e statusError) HTTPStatusCode() int { return e.code }
+
+type fakeS3 struct {
+	keys    []string
+	listErr error
+	putErr  error
+	puts    []*s3.PutObjectInput
+}
+
+func (f *fakeS3) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	if f.listErr != nil {
+		return nil, f.listErr
+	}
+	output := &s3.ListObjectsV2Output{}
+	for _, key := range f.keys {
+		output.Contents = append(output.Contents, types.Object{Key: aws.String(key)})
+	}
+	return output, nil
+}
+
+func (f *fakeS3) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	if f.putErr != nil {
+		return nil, f.putErr
+	}
+	if body, _ := io.ReadAll(params.Body); len(body) != 0 {
+		return nil, errors.New("directory markers must be empty")
+	}
+	f.puts = append(f.puts, params)
+	return &s3.PutObjectOutput{}, nil
+}
+
+func TestEnsure(t *testing.T) {
+	credentials := bucketregion.Credentials(nil)
+	volume := prefixmarker.Volume{Bucket: "bucket", Prefix: "team-a/data/"}
+
+	t.Run("prefix with objects", func(t *testing.T) {
+		client := &fakeS3{keys: []string{"team-a/data/file"}}
+		created, err := prefixmarker.NewMarker(client).Ensure(context.Background(), volume, credentials, true)
+		assert.NoError(t, err)
+		assert.Equals(t, false, created)
+		assert.Equals(t, 0, len(client.puts))
+	})
+
+	t.Run("creates the directory marker", func(t *testing.T) {
+		client := &fakeS3{}
+		encrypted := volume
+		encrypted.SSE, encrypted.SSEKMSKeyID = "aws:kms", "key-id"
+		created, err := prefixmarker.NewMarker(client).Ensure(context.Background(), encrypted, credentials, true)
+		assert.NoError(t, err)
+		assert.Equals(t, true, created)
+		assert.Equals(t, 1, len(client.puts))
+		assert.Equals(t, "team-a/data/", *client.puts[0].Key)
+		assert.Equals(t, types.ServerSideEncryptionAwsKms, client.puts[0].ServerSideEncryption)
+		assert.Equals(t, "key-id", *client.puts[0].SSEKMSKeyId)
+	})
+
+	t.Run("only checks the prefix", func(t *testing.T) {
+		client := &fakeS3{}
+		_, err := prefixmarker.NewMarker(client).Ensure(context.Background(), volume, credentials, false)
+		if !errors.Is(err, prefixmarker.ErrPrefixNotFound) {
+			t.Fatalf("Expected ErrPrefixNotFound, got %v", err)
+		}
+		assert.Equals(t, 0, len(client.puts))
+	})
+
+	for name, tc := range map[string]struct {
+		client  *fakeS3
+		wantErr error
+	}{
+		"bucket not found": {client: &fakeS3{listErr: statusError{http.StatusNotFound}}, wantErr: prefixmarker.ErrBucketNotFound},
+		"list denied":      {client: &fakeS3{listErr: statusError{http.StatusForbidden}}, wantErr: prefixmarker.ErrListDenied},
+		"put denied":       {client: &fakeS3{putErr: statusError{http.StatusForbidden}}, wantErr: prefixmarker.ErrPutDenied},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := prefixmarker.NewMarker(tc.client).Ensure(context.Background(), volume, credentials, true)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Expected %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+
+	t.Run("other errors", func(t *testing.T) {
+		_, err := prefixmarker.NewMarker(&fakeS3{putErr: errors.New("connection reset")}).Ensure(context.Background(), volume, credentials, true)
+		if err == nil || errors.Is(err, prefixmarker.ErrPutDenied) {
+			t.Fatalf("Expected an unclassified error, got %v", err)
+		}
+	})
+}
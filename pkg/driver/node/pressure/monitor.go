@@ -0,0 +1,207 @@
+// Package pressure monitors the pressure stall information (PSI) of the node, so new mounts lower the concurrency of
+// Mountpoint while IO or memory pressure is high, protecting co-located latency-sensitive workloads.
+package pressure
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	nodemetrics "github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/metrics"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
+)
+
+const (
+	// EnvAdaptiveConcurrencyEnabled is the environment variable enabling adaptive concurrency of new mounts.
+	EnvAdaptiveConcurrencyEnabled = "ADAPTIVE_CONCURRENCY_ENABLED"
+	// EnvPressureThreshold is the environment variable with the share of time (in percent, over the last 10 seconds)
+	// some tasks of the node stalled on IO or memory above which the node is under pressure.
+	EnvPressureThreshold = "ADAPTIVE_CONCURRENCY_PRESSURE_THRESHOLD"
+	// EnvThrottledMaxThreads is the environment variable with the `--max-threads` of mounts made under pressure.
+	EnvThrottledMaxThreads = "ADAPTIVE_CONCURRENCY_MAX_THREADS"
+)
+
+const (
+	// DefaultPath is where the kernel exposes the pressure stall information of the node.
+	DefaultPath = "/proc/pressure"
+	// DefaultPressureThreshold is the default [EnvPressureThreshold].
+	DefaultPressureThreshold = 20.0
+	// DefaultThrottledMaxThreads is the default [EnvThrottledMaxThreads].
+	DefaultThrottledMaxThreads = 4
+	// CheckInterval is how often the pressure of the node is read.
+	CheckInterval = 10 * time.Second
+)
+
+// mountpointMaxThreads is the default `--max-threads` of Mountpoint.
+const mountpointMaxThreads = 16
+
+// Resources whose pressure is monitored, named after their file in [DefaultPath].
+const (
+	ResourceIO     = "io"
+	ResourceMemory = "memory"
+)
+
+// Decisions of [Monitor.Adapt], labels of [nodemetrics.AdaptiveConcurrencyDecisionsTotal].
+const (
+	DecisionThrottled = "throttled"
+	DecisionUnchanged = "unchanged"
+)
+
+// Config configures when and how much the concurrency of new mounts is lowered.
+type Config struct {
+	// Threshold is the share of time in percent some tasks stalled on a resource above which the node is under pressure.
+	Threshold float64
+	// MaxThreads is the `--max-threads` of mounts made under pressure.
+	MaxThreads int
+}
+
+// ConfigFromEnv returns the [Config] set by [EnvPressureThreshold] and [EnvThrottledMaxThreads], with defaults for
+// unset values.
+func ConfigFromEnv() (Config, error) {
+	config := Config{Threshold: DefaultPressureThreshold, MaxThreads: DefaultThrottledMaxThreads}
+	if value := os.Getenv(EnvPressureThreshold); value != "" {
+		threshold, err := strconv.ParseFloat(value, 64)
+		if err != nil || threshold <= 0 || threshold >= 100 {
+			return Config{}, fmt.Errorf("invalid %s %q, must be a percentage between 0 and 100", EnvPressureThreshold, value)
+		}
+		config.Threshold = threshold
+	}
+	if value := os.Getenv(EnvThrottledMaxThreads); value != "" {
+		maxThreads, err := strconv.Atoi(value)
+		if err != nil || maxThreads < 1 {
+			return Config{}, fmt.Errorf("invalid %s %q, must be a positive integer", EnvThrottledMaxThreads, value)
+		}
+		config.MaxThreads = maxThreads
+	}
+	return config, nil
+}
+
+// A Monitor periodically reads the IO and memory pressure of the node. Mountpoint cannot change the concurrency of a
+// running mount, so only mounts made while the node is under pressure are adapted, see [Monitor.Adapt].
+type Monitor struct {
+	path   string
+	config Config
+
+	mu sync.RWMutex
+	// pressure is the share of time some tasks stalled on each resource over the last 10 seconds, as of the last check.
+	pressure map[string]float64
+}
+
+// NewMonitor creates a new [Monitor] of the pressure stall information in `path`, usually [DefaultPath].
+func NewMonitor(path string, config Config) *Monitor {
+	return &Monitor{path: path, config: config, pressure: make(map[string]float64)}
+}
+
+// Start checks the pressure of the node every `interval` until `stopCh` is closed.
+func (m *Monitor) Start(stopCh <-chan struct{}, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			if err := m.Check(); err != nil {
+				klog.Warningf("Failed to read pressure stall information of the node: %v", err)
+			}
+		}
+	}
+}
+
+// Check reads the current pressure of the node. It fails if the kernel does not expose pressure stall information,
+// e.g. if it was booted with `psi=0`.
+func (m *Monitor) Check() error {
+	pressure := make(map[string]float64)
+	var errs []error
+	for _, resource := range []string{ResourceIO, ResourceMemory} {
+		avg10, err := readSomeAvg10(filepath.Join(m.path, resource))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		pressure[resource] = avg10
+		nodemetrics.PressureStallPercent.WithLabelValues(resource).Set(avg10)
+	}
+
+	m.mu.Lock()
+	m.pressure = pressure
+	m.mu.Unlock()
+	return errors.Join(errs...)
+}
+
+// UnderPressure returns the most stalled resource of the node and its pressure, and whether it is above the threshold.
+func (m *Monitor) UnderPressure() (string, float64, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	resource, highest := "", 0.0
+	for _, r := range []string{ResourceIO, ResourceMemory} {
+		if avg10, ok := m.pressure[r]; ok && (resource == "" || avg10 > highest) {
+			resource, highest = r, avg10
+		}
+	}
+	return resource, highest, resource != "" && highest >= m.config.Threshold
+}
+
+// Adapt lowers `--max-threads` of `args` to the configured maximum if the node is under pressure. Lower values set in
+// mount options are kept.
+func (m *Monitor) Adapt(args *mountpoint.Args) {
+	resource, avg10, underPressure := m.UnderPressure()
+	if !underPressure {
+		nodemetrics.AdaptiveConcurrencyDecisionsTotal.WithLabelValues(DecisionUnchanged).Inc()
+		return
+	}
+
+	maxThreads := mountpointMaxThreads
+	if value, ok := args.Value(mountpoint.ArgMaxThreads); ok {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			maxThreads = parsed
+		}
+	}
+	if maxThreads <= m.config.MaxThreads {
+		nodemetrics.AdaptiveConcurrencyDecisionsTotal.WithLabelValues(DecisionUnchanged).Inc()
+		return
+	}
+
+	args.Set(mountpoint.ArgMaxThreads, strconv.Itoa(m.config.MaxThreads))
+	nodemetrics.AdaptiveConcurrencyDecisionsTotal.WithLabelValues(DecisionThrottled).Inc()
+	klog.Infof("Node is under %s pressure (%.2f%% stalled), lowering --max-threads of the mount from %d to %d", resource, avg10, maxThreads, m.config.MaxThreads)
+}
+
+// readSomeAvg10 returns the share of time some tasks stalled over the last 10 seconds from the pressure stall
+// information file at `path`, whose lines look like `some avg10=1.53 avg60=0.87 avg300=0.22 total=12345`.
+func readSomeAvg10(path string) (float64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] != "some" {
+			continue
+		}
+		for _, field := range fields[1:] {
+			if value, ok := strings.CutPrefix(field, "avg10="); ok {
+				avg10, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					return 0, fmt.Errorf("invalid pressure stall information in %q: %w", path, err)
+				}
+				return avg10, nil
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("no pressure stall information of some tasks in %q", path)
+}
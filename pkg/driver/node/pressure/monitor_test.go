@@ -0,0 +1,89 @@
+package pressure_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/pressure"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func writePressure(t *testing.T, dir, resource string, avg10 string) {
+	t.Helper()
+	data := "some avg10=" + avg10 + " avg60=0.50 avg300=0.10 total=123456\nfull avg10=0.00 avg60=0.00 avg300=0.00 total=0\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, resource), []byte(data), 0o600))
+}
+
+func TestMonitor(t *testing.T) {
+	dir := t.TempDir()
+	writePressure(t, dir, pressure.ResourceIO, "5.00")
+	writePressure(t, dir, pressure.ResourceMemory, "1.25")
+	monitor := pressure.NewMonitor(dir, pressure.Config{Threshold: 20, MaxThreads: 4})
+
+	assert.NoError(t, monitor.Check())
+	resource, avg10, underPressure := monitor.UnderPressure()
+	assert.Equals(t, pressure.ResourceIO, resource)
+	assert.Equals(t, 5.0, avg10)
+	assert.Equals(t, false, underPressure)
+
+	args := mountpoint.ParseArgs([]string{"--allow-delete"})
+	monitor.Adapt(&args)
+	assert.Equals(t, false, args.Has(mountpoint.ArgMaxThreads))
+
+	writePressure(t, dir, pressure.ResourceMemory, "42.10")
+	assert.NoError(t, monitor.Check())
+	resource, avg10, underPressure = monitor.UnderPressure()
+	assert.Equals(t, pressure.ResourceMemory, resource)
+	assert.Equals(t, 42.1, avg10)
+	assert.Equals(t, true, underPressure)
+
+	for _, test := range []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"default concurrency is lowered", []string{"--allow-delete"}, "4"},
+		{"higher concurrency is lowered", []string{"--max-threads=64"}, "4"},
+		{"lower concurrency is kept", []string{"--max-threads=2"}, "2"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			args := mountpoint.ParseArgs(test.args)
+			monitor.Adapt(&args)
+			maxThreads, _ := args.Value(mountpoint.ArgMaxThreads)
+			assert.Equals(t, test.expected, maxThreads)
+		})
+	}
+}
+
+func TestMonitorWithoutPressureStallInformation(t *testing.T) {
+	monitor := pressure.NewMonitor(filepath.Join(t.TempDir(), "missing"), pressure.Config{Threshold: 20, MaxThreads: 4})
+	if err := monitor.Check(); err == nil {
+		t.Fatal("Expected an error without pressure stall information")
+	}
+	_, _, underPressure := monitor.UnderPressure()
+	assert.Equals(t, false, underPressure)
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	config, err := pressure.ConfigFromEnv()
+	assert.NoError(t, err)
+	assert.Equals(t, pressure.Config{Threshold: pressure.DefaultPressureThreshold, MaxThreads: pressure.DefaultThrottledMaxThreads}, config)
+
+	t.Setenv(pressure.EnvPressureThreshold, "35.5")
+	t.Setenv(pressure.EnvThrottledMaxThreads, "2")
+	config, err = pressure.ConfigFromEnv()
+	assert.NoError(t, err)
+	assert.Equals(t, pressure.Config{Threshold: 35.5, MaxThreads: 2}, config)
+
+	t.Setenv(pressure.EnvPressureThreshold, "150")
+	if _, err := pressure.ConfigFromEnv(); err == nil {
+		t.Fatal("Expected an error for a threshold above 100%")
+	}
+	t.Setenv(pressure.EnvPressureThreshold, "")
+	t.Setenv(pressure.EnvThrottledMaxThreads, "0")
+	if _, err := pressure.ConfigFromEnv(); err == nil {
+		t.Fatal("Expected an error for no threads")
+	}
+}
@@ -0,0 +1,207 @@
+// Package problemreport reports node-level problems preventing the CSI Driver from mounting volumes, so
+// Node Problem Detector can set a NodeCondition that schedulers and alerts can act on.
+//
+// Each active problem is written to a file named after its reason in [Dir], containing a single line message.
+// The file is removed once the problem is resolved. Node Problem Detector reads these files with a custom plugin
+// monitor, see the `node.problemReports` Helm values.
+package problemreport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+	"k8s.io/klog/v2"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util"
+)
+
+// EnvProblemReportsEnabled is the environment variable enabling problem reports.
+const EnvProblemReportsEnabled = "PROBLEM_REPORTS_ENABLED"
+
+// CheckInterval is how often node-level problems are checked.
+const CheckInterval = time.Minute
+
+// Reasons of node-level problems, used as file names in [Dir] and as NodeCondition reasons.
+const (
+	ReasonFuseUnavailable       = "FuseUnavailable"
+	ReasonEndpointUnreachable   = "EndpointUnreachable"
+	ReasonCredentialDirReadOnly = "CredentialDirReadOnly"
+)
+
+const (
+	devFusePath     = "/dev/fuse"
+	endpointTimeout = 5 * time.Second
+)
+
+// Dir returns the directory problem reports are written to on the host.
+func Dir(kubeletPath string) string {
+	return filepath.Join(kubeletPath, "plugins", util.DriverName(), "problems")
+}
+
+// A check returns an error describing a node-level problem, or nil if there is none.
+type check struct {
+	reason string
+	run    func(ctx context.Context) error
+}
+
+// A Reporter periodically checks for node-level problems and writes a report for each active one.
+type Reporter struct {
+	dir    string
+	checks []check
+
+	mu sync.Mutex
+	// active are reasons of problems currently reported.
+	active map[string]bool
+}
+
+// NewReporter creates a new [Reporter] checking that FUSE is available, that `endpointURL` is reachable, and that
+// credentials can be written to Mountpoint Pods under `kubeletPath`.
+func NewReporter(kubeletPath, endpointURL string) *Reporter {
+	return &Reporter{
+		dir: Dir(kubeletPath),
+		checks: []check{
+			{reason: ReasonFuseUnavailable, run: func(context.Context) error { return checkFuse(devFusePath) }},
+			{reason: ReasonEndpointUnreachable, run: func(ctx context.Context) error { return checkEndpoint(ctx, endpointURL) }},
+			// Credentials are written to the `emptyDir` volumes of Mountpoint Pods, in kubelet's pods directory
+			{reason: ReasonCredentialDirReadOnly, run: func(context.Context) error { return checkWritable(filepath.Join(kubeletPath, "pods")) }},
+		},
+		active: make(map[string]bool),
+	}
+}
+
+// Start reports node-level problems on startup, and then every `interval` until `stopCh` is closed.
+func (r *Reporter) Start(stopCh <-chan struct{}, interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.Report(ctx); err != nil {
+			klog.Errorf("Failed to report node problems: %v", err)
+		}
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Report runs all checks, and writes or removes the report of each problem accordingly.
+func (r *Reporter) Report(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := os.MkdirAll(r.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create problem reports directory %q: %w", r.dir, err)
+	}
+
+	var errs []error
+	for _, c := range r.checks {
+		problem := c.run(ctx)
+		if problem == nil {
+			if err := r.resolve(c.reason); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if err := r.report(c.reason, problem.Error()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// report writes the report of the problem `reason` with `message`.
+func (r *Reporter) report(reason, message string) error {
+	// Node Problem Detector uses the first line of the output of its plugins as condition message
+	message = strings.ReplaceAll(message, "\n", " ")
+	path := filepath.Join(r.dir, reason)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(message+"\n"), 0o644); err != nil {
+		return fmt.Errorf("failed to write problem report %q: %w", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write problem report %q: %w", path, err)
+	}
+
+	if !r.active[reason] {
+		r.active[reason] = true
+		klog.InfoS("Node problem detected", "reason", reason, "message", message)
+	}
+	return nil
+}
+
+// resolve removes the report of the problem `reason` if any.
+func (r *Reporter) resolve(reason string) error {
+	path := filepath.Join(r.dir, reason)
+	err := os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove problem report %q: %w", path, err)
+	}
+
+	// Also log reports left by a previous run of the node plugin
+	if r.active[reason] || err == nil {
+		delete(r.active, reason)
+		klog.InfoS("Node problem resolved", "reason", reason)
+	}
+	return nil
+}
+
+// checkFuse checks that the FUSE device at `path` can be opened, Mountpoint cannot mount without it.
+func checkFuse(path string) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("FUSE device unavailable: %w", err)
+	}
+	return f.Close()
+}
+
+// checkEndpoint checks that a TCP connection to the S3 endpoint `endpointURL` can be established.
+func checkEndpoint(ctx context.Context, endpointURL string) error {
+	if endpointURL == "" {
+		return nil
+	}
+	u, err := url.Parse(endpointURL)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid S3 endpoint %q", endpointURL)
+	}
+	address := u.Host
+	if u.Port() == "" {
+		port := "443"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+		address = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	dialer := net.Dialer{Timeout: endpointTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return fmt.Errorf("S3 endpoint %s unreachable: %w", endpointURL, err)
+	}
+	return conn.Close()
+}
+
+// checkWritable checks that `dir` is writable, without writing to it.
+func checkWritable(dir string) error {
+	if err := unix.Access(dir, unix.W_OK); err != nil {
+		return fmt.Errorf("credential directory %s not writable: %w", dir, err)
+	}
+	return nil
+}
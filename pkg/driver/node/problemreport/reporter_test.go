@@ -0,0 +1,73 @@
+package problemreport
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestReporter(t *testing.T) {
+	kubeletPath := t.TempDir()
+	reporter := NewReporter(kubeletPath, "")
+
+	var fuseErr error
+	reporter.checks = []check{{reason: ReasonFuseUnavailable, run: func(context.Context) error { return fuseErr }}}
+	reportPath := filepath.Join(Dir(kubeletPath), ReasonFuseUnavailable)
+
+	// No report without a problem
+	assert.NoError(t, reporter.Report(context.Background()))
+	_, err := os.Stat(reportPath)
+	assert.Equals(t, true, os.IsNotExist(err))
+
+	// Active problems are reported with a single line message
+	fuseErr = errors.New("FUSE device unavailable:\nno such file or directory")
+	assert.NoError(t, reporter.Report(context.Background()))
+	report, err := os.ReadFile(reportPath)
+	assert.NoError(t, err)
+	assert.Equals(t, "FUSE device unavailable: no such file or directory\n", string(report))
+
+	// Reports are removed once problems are resolved
+	fuseErr = nil
+	assert.NoError(t, reporter.Report(context.Background()))
+	_, err = os.Stat(reportPath)
+	assert.Equals(t, true, os.IsNotExist(err))
+	assert.Equals(t, 0, len(reporter.active))
+}
+
+func TestChecks(t *testing.T) {
+	t.Run("FUSE device", func(t *testing.T) {
+		if err := checkFuse(filepath.Join(t.TempDir(), "fuse")); err == nil {
+			t.Fatalf("Expected a missing FUSE device to be reported")
+		}
+		device := filepath.Join(t.TempDir(), "fuse")
+		assert.NoError(t, os.WriteFile(device, nil, 0o644))
+		assert.NoError(t, checkFuse(device))
+	})
+
+	t.Run("S3 endpoint", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+		endpointURL := "http://" + listener.Addr().String()
+		assert.NoError(t, checkEndpoint(context.Background(), endpointURL))
+
+		assert.NoError(t, listener.Close())
+		if err := checkEndpoint(context.Background(), endpointURL); err == nil {
+			t.Fatalf("Expected an unreachable endpoint to be reported")
+		}
+		if err := checkEndpoint(context.Background(), "://invalid"); err == nil {
+			t.Fatalf("Expected an invalid endpoint to be reported")
+		}
+	})
+
+	t.Run("Credential directory", func(t *testing.T) {
+		assert.NoError(t, checkWritable(t.TempDir()))
+		if err := checkWritable(filepath.Join(t.TempDir(), "missing")); err == nil {
+			t.Fatalf("Expected a missing credential directory to be reported")
+		}
+	})
+}
@@ -0,0 +1,52 @@
+package node
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/bucketregion"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
+)
+
+// discoverRegion sets `--region` in `args` and `credentialCtx` to the discovered region of `bucket` if the volume
+// is mounted without one. Mounts of buckets that do not exist or cannot be accessed fail with a clear error, other
+// discovery failures are left to Mountpoint.
+//
+// Volumes with their own endpoint are not discovered, as their endpoint might not be allowed and requests to it
+// would be signed with the credentials of the volume. Neither are volumes with `role`, `webIdentity` or `file`
+// authentication sources, as the credentials Mountpoint uses are not the driver-level ones the bucket would be
+// accessed with, and Mountpoint resolves the region itself.
+func (ns *S3NodeServer) discoverRegion(ctx context.Context, bucket string, args *mountpoint.Args, credentialCtx *credentialprovider.ProvideContext) error {
+	if ns.RegionResolver == nil || args.Has(mountpoint.ArgRegion) || args.Has(mountpoint.ArgEndpointURL) {
+		return nil
+	}
+
+	var secretData map[string]string
+	switch credentialCtx.AuthenticationSource {
+	case credentialprovider.AuthenticationSourceUnspecified, credentialprovider.AuthenticationSourceDriver:
+	case credentialprovider.AuthenticationSourceSecret:
+		// Falls back to driver-level credentials without node-publish secrets, as Mountpoint does
+		secretData = credentialCtx.SecretData
+	default:
+		return nil
+	}
+	region, err := ns.RegionResolver.Resolve(ctx, bucket, "", bucketregion.Credentials(secretData))
+	switch {
+	case errors.Is(err, bucketregion.ErrBucketNotFound):
+		return status.Errorf(codes.NotFound, "Bucket %q does not exist on the S3 endpoint: %v", bucket, err)
+	case errors.Is(err, bucketregion.ErrAccessDenied):
+		return status.Errorf(codes.PermissionDenied, "Credentials of the volume are not allowed to access bucket %q, check its bucket policy and the permissions of the credentials: %v", bucket, err)
+	case err != nil:
+		klog.Warningf("Could not discover the region of bucket %s, mounting it without --region: %v", bucket, err)
+		return nil
+	}
+
+	args.Set(mountpoint.ArgRegion, region)
+	credentialCtx.BucketRegion = region
+	return nil
+}
@@ -0,0 +1,251 @@
+// Package registration supervises the registration of the node plugin with kubelet, so the driver does not stay
+// unregistered after kubelet restarts until the node plugin Pod is recreated.
+//
+// The node-driver-registrar sidecar registers the driver through its registration socket in the plugin registry of
+// kubelet. Kubelet registers the sockets of the registry when it starts, and the sidecar writes a registration status
+// file once kubelet reports the driver registered. A kubelet restarting while the sidecar is not serving its socket
+// does not retry, so the [Supervisor] re-announces the socket to kubelet when the registration did not follow a restart.
+package registration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	typedstoragev1 "k8s.io/client-go/kubernetes/typed/storage/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// EnvRegistrationSupervisorEnabled is the environment variable enabling the supervision of the registration.
+	EnvRegistrationSupervisorEnabled = "REGISTRATION_SUPERVISOR_ENABLED"
+	// EnvReadinessAddress is the environment variable with the address readiness is served on, e.g. `:9811`.
+	EnvReadinessAddress = "REGISTRATION_READINESS_ADDRESS"
+)
+
+// CheckInterval is how often the registration is checked.
+const CheckInterval = 10 * time.Second
+
+// GracePeriod is how long kubelet is given to register the driver after it starts, or after the registration socket
+// was re-announced, before the socket is re-announced.
+const GracePeriod = 30 * time.Second
+
+// dialTimeout is the timeout of connections checking sockets accept connections.
+const dialTimeout = 2 * time.Second
+
+// RegistrationSocket returns the registration socket of the node-driver-registrar of `driverName` in the plugin
+// registry of kubelet.
+func RegistrationSocket(kubeletPath, driverName string) string {
+	return filepath.Join(kubeletPath, "plugins_registry", driverName+"-reg.sock")
+}
+
+// statusFile returns the file the node-driver-registrar writes once kubelet reports the driver registered, next to
+// the CSI socket of the driver in the plugin directory of kubelet.
+func statusFile(kubeletPath, driverName string) string {
+	return filepath.Join(kubeletPath, "plugins", driverName, "registration")
+}
+
+// kubeletSocket returns a socket kubelet recreates when it starts, its modification time is the start of kubelet.
+func kubeletSocket(kubeletPath string) string {
+	return filepath.Join(kubeletPath, "device-plugins", "kubelet.sock")
+}
+
+// A Supervisor checks that the CSI socket and the registration socket of the driver accept connections and that
+// kubelet registered the driver since it last started, re-announcing the registration socket if it did not.
+type Supervisor struct {
+	driverName         string
+	nodeName           string
+	csiSocket          string
+	registrationSocket string
+	statusFile         string
+	kubeletSocket      string
+	csiNodes           typedstoragev1.CSINodesGetter
+	// readinessChecks must pass for the node plugin to be ready, in addition to its registration.
+	readinessChecks []func() error
+	now             func() time.Time
+
+	mu  sync.Mutex
+	err error
+	// announcedAt is when the registration socket was last re-announced.
+	announcedAt time.Time
+}
+
+// NewSupervisor creates a new [Supervisor] of the registration of `driverName` on node `nodeName`, serving CSI on
+// the unix socket `csiSocket`. The registration is not checked until the first check.
+func NewSupervisor(driverName, nodeName, csiSocket, kubeletPath string, csiNodes typedstoragev1.CSINodesGetter) *Supervisor {
+	return &Supervisor{
+		driverName:         driverName,
+		nodeName:           nodeName,
+		csiSocket:          csiSocket,
+		registrationSocket: RegistrationSocket(kubeletPath, driverName),
+		statusFile:         statusFile(kubeletPath, driverName),
+		kubeletSocket:      kubeletSocket(kubeletPath),
+		csiNodes:           csiNodes,
+		now:                time.Now,
+		err:                errors.New("registration not checked yet"),
+	}
+}
+
+// AddReadinessCheck adds a check the node plugin must pass to be ready, in addition to its registration.
+func (s *Supervisor) AddReadinessCheck(check func() error) {
+	s.readinessChecks = append(s.readinessChecks, check)
+}
+
+// Err returns why the node plugin was not ready at the last check, nil if it was.
+func (s *Supervisor) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Start checks the registration every `interval` until `stopCh` is closed.
+func (s *Supervisor) Start(stopCh <-chan struct{}, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.Check(context.Background())
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check checks the sockets and the registration of the driver, re-announces the registration socket if kubelet did
+// not register the driver within [GracePeriod] of its start, and returns why the node plugin is not ready.
+func (s *Supervisor) Check(ctx context.Context) error {
+	err := s.check(ctx)
+	if err == nil {
+		for _, check := range s.readinessChecks {
+			if err = check(); err != nil {
+				break
+			}
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil && (s.err == nil || s.err.Error() != err.Error()) {
+		klog.Warningf("Node plugin is not ready: %v", err)
+	} else if err == nil && s.err != nil {
+		klog.Infof("Node plugin is registered with kubelet and ready")
+	}
+	s.err = err
+	return err
+}
+
+// check returns why the driver is not registered with kubelet, nil if it is.
+func (s *Supervisor) check(ctx context.Context) error {
+	if err := dial(s.csiSocket); err != nil {
+		return fmt.Errorf("CSI socket %s is not accepting connections: %w", s.csiSocket, err)
+	}
+	if err := dial(s.registrationSocket); err != nil {
+		return fmt.Errorf("registration socket %s is not accepting connections: %w", s.registrationSocket, err)
+	}
+
+	if err := s.checkRegistration(); err != nil {
+		return err
+	}
+
+	csiNode, err := s.csiNodes.CSINodes().Get(ctx, s.nodeName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("CSINode %s not found", s.nodeName)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get CSINode %s: %w", s.nodeName, err)
+	}
+	if !slices.ContainsFunc(csiNode.Spec.Drivers, func(d storagev1.CSINodeDriver) bool { return d.Name == s.driverName }) {
+		return fmt.Errorf("driver %s is not listed in CSINode %s", s.driverName, s.nodeName)
+	}
+	return nil
+}
+
+// checkRegistration returns an error if kubelet did not register the driver since it last started, re-announcing
+// the registration socket once the grace period is over.
+func (s *Supervisor) checkRegistration() error {
+	var kubeletStart time.Time
+	if info, err := os.Stat(s.kubeletSocket); err == nil {
+		kubeletStart = info.ModTime()
+	}
+
+	info, err := os.Stat(s.statusFile)
+	if err == nil && !info.ModTime().Before(kubeletStart) {
+		return nil
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read registration status %s: %w", s.statusFile, err)
+	}
+
+	waitingSince := kubeletStart
+	if s.announcedAt.After(waitingSince) {
+		waitingSince = s.announcedAt
+	}
+	if s.now().Sub(waitingSince) >= GracePeriod {
+		if err := s.announce(); err != nil {
+			return fmt.Errorf("driver is not registered with kubelet, failed to re-announce its registration socket: %w", err)
+		}
+		klog.Infof("Driver %s was not registered with kubelet since it started at %s, re-announced registration socket %s",
+			s.driverName, kubeletStart.Format(time.RFC3339), s.registrationSocket)
+	}
+	return errors.New("driver is not registered with kubelet")
+}
+
+// announce makes kubelet register the registration socket again. Kubelet watches the creation of sockets in its
+// plugin registry, moving the socket away and back is seen as a new socket while the node-driver-registrar keeps
+// serving it.
+func (s *Supervisor) announce() error {
+	s.announcedAt = s.now()
+	moved := s.registrationSocket + ".reannounce"
+	if err := os.Rename(s.registrationSocket, moved); err != nil {
+		return err
+	}
+	return os.Rename(moved, s.registrationSocket)
+}
+
+// dial returns an error if the unix socket `path` does not accept connections.
+func dial(path string) error {
+	conn, err := net.DialTimeout("unix", path, dialTimeout)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// ServeReadiness serves the readiness of the node plugin at `/readyz` on `addr` until `stopCh` is closed, for the
+// readiness probe of the node plugin.
+func (s *Supervisor) ServeReadiness(addr string, stopCh <-chan struct{}) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/readyz", s.handleReadiness)
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-stopCh
+		_ = server.Close()
+	}()
+
+	klog.Infof("Serving registration readiness on %s", addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		klog.Errorf("Failed to serve registration readiness on %s: %v", addr, err)
+	}
+}
+
+// handleReadiness responds with the result of the last check.
+func (s *Supervisor) handleReadiness(w http.ResponseWriter, _ *http.Request) {
+	if err := s.Err(); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	_, _ = w.Write([]byte("ok"))
+}
@@ -0,0 +1,123 @@
+package registration
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+const testDriverName = "s3.csi.scality.com"
+
+// listen serves the unix socket `path` until the test ends.
+func listen(t *testing.T, path string) net.Listener {
+	t.Helper()
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	listener, err := net.Listen("unix", path)
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+	return listener
+}
+
+// touch creates or updates the modification time of `path` to `mtime`.
+func touch(t *testing.T, path string, mtime time.Time) {
+	t.Helper()
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		assert.NoError(t, os.WriteFile(path, nil, 0o644))
+	}
+	assert.NoError(t, os.Chtimes(path, mtime, mtime))
+}
+
+func TestSupervisor(t *testing.T) {
+	// Unix socket paths are limited to about 100 characters, shorter than test directories
+	kubeletPath, err := os.MkdirTemp("", "kubelet")
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(kubeletPath) })
+	csiSocket := filepath.Join(kubeletPath, "plugins", testDriverName, "csi.sock")
+
+	client := fake.NewClientset()
+	supervisor := NewSupervisor(testDriverName, "node-1", csiSocket, kubeletPath, client.StorageV1())
+	now := time.Now()
+	supervisor.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	expectErr := func(contains string) {
+		t.Helper()
+		err := supervisor.Check(ctx)
+		if err == nil || !strings.Contains(err.Error(), contains) {
+			t.Fatalf("Expected an error containing %q, got %v", contains, err)
+		}
+		assert.Equals(t, err, supervisor.Err())
+	}
+
+	expectErr("CSI socket")
+
+	listen(t, csiSocket)
+	expectErr("registration socket")
+
+	registrationSocket := RegistrationSocket(kubeletPath, testDriverName)
+	listen(t, registrationSocket)
+	kubeletStart := now.Add(-time.Second)
+	touch(t, kubeletSocket(kubeletPath), kubeletStart)
+	expectErr("not registered with kubelet")
+
+	// Registered before kubelet last started
+	touch(t, statusFile(kubeletPath, testDriverName), kubeletStart.Add(-time.Minute))
+	expectErr("not registered with kubelet")
+	assert.Equals(t, time.Time{}, supervisor.announcedAt)
+
+	// The registration socket is re-announced once the grace period is over, and not again during the next one
+	now = now.Add(GracePeriod)
+	expectErr("not registered with kubelet")
+	assert.Equals(t, now, supervisor.announcedAt)
+	announcedAt := now
+	now = now.Add(GracePeriod / 2)
+	expectErr("not registered with kubelet")
+	assert.Equals(t, announcedAt, supervisor.announcedAt)
+
+	// The re-announced socket is still served by the registrar
+	conn, err := net.Dial("unix", registrationSocket)
+	assert.NoError(t, err)
+	assert.NoError(t, conn.Close())
+
+	// Registered since kubelet last started
+	touch(t, statusFile(kubeletPath, testDriverName), now)
+	expectErr("CSINode node-1 not found")
+
+	_, err = client.StorageV1().CSINodes().Create(ctx, &storagev1.CSINode{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Spec:       storagev1.CSINodeSpec{Drivers: []storagev1.CSINodeDriver{{Name: testDriverName, NodeID: "node-1"}}},
+	}, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, supervisor.Check(ctx))
+
+	// Failing readiness check
+	supervisor.AddReadinessCheck(func() error { return errors.New("S3 endpoint unreachable") })
+	expectErr("S3 endpoint unreachable")
+}
+
+func TestHandleReadiness(t *testing.T) {
+	supervisor := NewSupervisor(testDriverName, "node-1", "/csi/csi.sock", "/var/lib/kubelet", fake.NewClientset().StorageV1())
+
+	recorder := httptest.NewRecorder()
+	supervisor.handleReadiness(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equals(t, http.StatusServiceUnavailable, recorder.Code)
+
+	supervisor.err = nil
+	recorder = httptest.NewRecorder()
+	supervisor.handleReadiness(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equals(t, http.StatusOK, recorder.Code)
+}
@@ -0,0 +1,131 @@
+// Package scopedclient creates Kubernetes clients of the node plugin acting as dedicated, narrowly scoped service
+// accounts, so a compromised node plugin token alone cannot read Secrets or write MountpointS3PodAttachments.
+//
+// The node plugin's own service account is only allowed to act as the scoped service accounts, either by requesting
+// short-lived tokens for them with the TokenRequest API ([ModeToken]), or by impersonating them ([ModeImpersonate]).
+package scopedclient
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/transport"
+	"k8s.io/utils/ptr"
+)
+
+// Environment variables configuring scoped clients.
+const (
+	// EnvMode is how scoped clients act as their service accounts, scoped clients are disabled if unset.
+	EnvMode = "SCOPED_CLIENTS_MODE"
+	// EnvSecretsServiceAccount is the `namespace/name` of the service account reading Secrets.
+	EnvSecretsServiceAccount = "SECRETS_SERVICE_ACCOUNT"
+	// EnvAttachmentsServiceAccount is the `namespace/name` of the service account accessing MountpointS3PodAttachments.
+	EnvAttachmentsServiceAccount = "ATTACHMENTS_SERVICE_ACCOUNT"
+)
+
+// A Mode is how a scoped client acts as its service account.
+type Mode string
+
+const (
+	// ModeToken requests short-lived tokens of the service account, which requires the `create` verb on its
+	// `serviceaccounts/token` subresource.
+	ModeToken Mode = "token"
+	// ModeImpersonate impersonates the service account, which requires the `impersonate` verb on it.
+	ModeImpersonate Mode = "impersonate"
+)
+
+// tokenExpiration is the requested lifetime of service account tokens in [ModeToken].
+// Tokens are renewed once 80% of their lifetime elapsed.
+const tokenExpiration = time.Hour
+
+// A ServiceAccount identifies the service account a scoped client acts as.
+type ServiceAccount struct {
+	Namespace string
+	Name      string
+}
+
+// Username returns the username Kubernetes authenticates the service account as.
+func (sa ServiceAccount) Username() string {
+	return fmt.Sprintf("system:serviceaccount:%s:%s", sa.Namespace, sa.Name)
+}
+
+// ParseServiceAccount parses a `namespace/name` service account reference.
+func ParseServiceAccount(ref string) (ServiceAccount, error) {
+	namespace, name, ok := strings.Cut(ref, "/")
+	if !ok || namespace == "" || name == "" {
+		return ServiceAccount{}, fmt.Errorf("invalid service account %q, expected namespace/name", ref)
+	}
+	return ServiceAccount{Namespace: namespace, Name: name}, nil
+}
+
+// ParseMode parses a [Mode].
+func ParseMode(mode string) (Mode, error) {
+	switch Mode(mode) {
+	case ModeToken, ModeImpersonate:
+		return Mode(mode), nil
+	default:
+		return "", fmt.Errorf("invalid scoped clients mode %q, only %q and %q are supported", mode, ModeToken, ModeImpersonate)
+	}
+}
+
+// Config returns a copy of `base` acting as `sa` with `mode`. `tokens` creates tokens of `sa` in [ModeToken], using the
+// identity of `base`.
+func Config(base *rest.Config, tokens typedcorev1.ServiceAccountsGetter, sa ServiceAccount, mode Mode) *rest.Config {
+	config := rest.CopyConfig(base)
+	switch mode {
+	case ModeImpersonate:
+		config.Impersonate = rest.ImpersonationConfig{
+			UserName: sa.Username(),
+		}
+	case ModeToken:
+		// Drop the identity of `base`, requests are authenticated with tokens of `sa` only
+		config.BearerToken = ""
+		config.BearerTokenFile = ""
+		config.Username = ""
+		config.Password = ""
+		config.CertData = nil
+		config.CertFile = ""
+		config.KeyData = nil
+		config.KeyFile = ""
+		config.WrapTransport = transport.ResettableTokenSourceWrapTransport(
+			transport.NewCachedTokenSource(&tokenSource{tokens: tokens, sa: sa}))
+	}
+	return config
+}
+
+// A tokenSource requests tokens of a service account with the TokenRequest API.
+type tokenSource struct {
+	tokens typedcorev1.ServiceAccountsGetter
+	sa     ServiceAccount
+}
+
+// Token implements [oauth2.TokenSource].
+func (ts *tokenSource) Token() (*oauth2.Token, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	request, err := ts.tokens.ServiceAccounts(ts.sa.Namespace).CreateToken(ctx, ts.sa.Name, &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{
+			ExpirationSeconds: ptr.To(int64(tokenExpiration.Seconds())),
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to request token of service account %s/%s: %w", ts.sa.Namespace, ts.sa.Name, err)
+	}
+
+	// Renew tokens before they expire, the API server might shorten their lifetime
+	expiry := request.Status.ExpirationTimestamp.Time
+	lifetime := time.Until(expiry)
+	return &oauth2.Token{
+		AccessToken: request.Status.Token,
+		TokenType:   "Bearer",
+		Expiry:      expiry.Add(-lifetime / 5),
+	}, nil
+}
@@ -0,0 +1,111 @@
+package scopedclient_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/scopedclient"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+// apiServer records the authentication headers of requests, and responds with an empty Secret.
+type apiServer struct {
+	mu      sync.Mutex
+	headers []http.Header
+}
+
+func (s *apiServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.headers = append(s.headers, r.Header.Clone())
+	s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, `{"apiVersion":"v1","kind":"Secret","metadata":{"name":"s3-credentials","namespace":"default"}}`)
+}
+
+func getSecret(t *testing.T, config *rest.Config) {
+	t.Helper()
+	client, err := kubernetes.NewForConfig(config)
+	assert.NoError(t, err)
+	_, err = client.CoreV1().Secrets("default").Get(context.Background(), "s3-credentials", metav1.GetOptions{})
+	assert.NoError(t, err)
+}
+
+func TestConfig(t *testing.T) {
+	sa := scopedclient.ServiceAccount{Namespace: "kube-system", Name: "s3-csi-node-secrets-reader"}
+
+	t.Run("token", func(t *testing.T) {
+		server := &apiServer{}
+		httpServer := httptest.NewServer(server)
+		defer httpServer.Close()
+
+		var requested []string
+		tokens := fake.NewClientset()
+		tokens.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			create := action.(k8stesting.CreateActionImpl)
+			if create.GetSubresource() != "token" {
+				return false, nil, nil
+			}
+			requested = append(requested, create.Namespace+"/"+create.Name)
+			return true, &authenticationv1.TokenRequest{Status: authenticationv1.TokenRequestStatus{
+				Token:               fmt.Sprintf("scoped-token-%d", len(requested)),
+				ExpirationTimestamp: metav1.NewTime(time.Now().Add(time.Hour)),
+			}}, nil
+		})
+
+		base := &rest.Config{Host: httpServer.URL, BearerToken: "node-token"}
+		config := scopedclient.Config(base, tokens.CoreV1(), sa, scopedclient.ModeToken)
+		getSecret(t, config)
+		getSecret(t, config)
+
+		// Tokens are cached until they need to be renewed
+		assert.Equals(t, []string{"kube-system/s3-csi-node-secrets-reader"}, requested)
+		for _, header := range server.headers {
+			assert.Equals(t, "Bearer scoped-token-1", header.Get("Authorization"))
+		}
+		assert.Equals(t, "node-token", base.BearerToken)
+	})
+
+	t.Run("impersonate", func(t *testing.T) {
+		server := &apiServer{}
+		httpServer := httptest.NewServer(server)
+		defer httpServer.Close()
+
+		base := &rest.Config{Host: httpServer.URL, BearerToken: "node-token"}
+		getSecret(t, scopedclient.Config(base, nil, sa, scopedclient.ModeImpersonate))
+
+		assert.Equals(t, 1, len(server.headers))
+		assert.Equals(t, "Bearer node-token", server.headers[0].Get("Authorization"))
+		assert.Equals(t, "system:serviceaccount:kube-system:s3-csi-node-secrets-reader", server.headers[0].Get("Impersonate-User"))
+	})
+}
+
+func TestParse(t *testing.T) {
+	sa, err := scopedclient.ParseServiceAccount("kube-system/s3-csi-node-attachments")
+	assert.NoError(t, err)
+	assert.Equals(t, scopedclient.ServiceAccount{Namespace: "kube-system", Name: "s3-csi-node-attachments"}, sa)
+	for _, ref := range []string{"", "s3-csi-node-attachments", "kube-system/", "/s3-csi-node-attachments"} {
+		if _, err := scopedclient.ParseServiceAccount(ref); err == nil {
+			t.Errorf("Expected %q to be rejected", ref)
+		}
+	}
+
+	mode, err := scopedclient.ParseMode("impersonate")
+	assert.NoError(t, err)
+	assert.Equals(t, scopedclient.ModeImpersonate, mode)
+	if _, err := scopedclient.ParseMode("kubeconfig"); err == nil {
+		t.Errorf("Expected unknown modes to be rejected")
+	}
+}
@@ -22,7 +22,7 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util"
 	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -201,13 +201,13 @@ func checkDriverRegistered(ctx context.Context, clientset kubernetes.Interface,
 	}
 
 	for _, driver := range csiNode.Spec.Drivers {
-		if driver.Name == constants.DriverName {
+		if driver.Name == util.DriverName() {
 			return nil
 		}
 	}
 
 	return fmt.Errorf("driver %q not found in CSINode %q (registered drivers: %v)",
-		constants.DriverName, nodeID, driverNames(csiNode))
+		util.DriverName(), nodeID, driverNames(csiNode))
 }
 
 // driverNames returns the list of driver names from a CSINode for logging.
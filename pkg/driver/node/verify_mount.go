@@ -0,0 +1,119 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+)
+
+// verifyMountTimeout is how long the probe of [volumecontext.VerifyMount] is given to access a new mount.
+const verifyMountTimeout = 10 * time.Second
+
+// verifyMountProbeName is the name looked up by the [volumecontext.VerifyMountHead] probe, it does not need to exist.
+const verifyMountProbeName = ".s3-csi-verify-mount"
+
+// verifyMount accesses the volume mounted at `target` with the probe of [volumecontext.VerifyMount], and unmounts it
+// if the probe fails. Mountpoint only checks the bucket can be listed when it starts, so volumes with a wrong region,
+// or credentials allowed to list the bucket but not to read its objects, would otherwise mount and fail every I/O.
+func (ns *S3NodeServer) verifyMount(ctx context.Context, volumeID, bucket, target, probe string) error {
+	if probe == volumecontext.VerifyMountOff {
+		return nil
+	}
+
+	err := probeMount(ctx, target, probe)
+	if err == nil {
+		klog.V(4).Infof("NodePublishVolume: %s was verified with a %s probe", target, probe)
+		return nil
+	}
+
+	klog.Warningf("NodePublishVolume: %s probe of %s failed, unmounting it: %v", probe, target, err)
+	if ns.EndpointFailover != nil {
+		ns.EndpointFailover.Untrack(target)
+	}
+	if ns.MountHealth != nil {
+		ns.MountHealth.Untrack(target)
+	}
+	podID, _ := podIDFromTargetPath(target)
+	credentialCtx := credentialprovider.CleanupContext{VolumeID: volumeID, PodID: podID}
+	if unmountErr := ns.Mounter.Unmount(ctx, target, credentialCtx); unmountErr != nil {
+		klog.Errorf("NodePublishVolume: failed to unmount %s after its %s probe failed: %v", target, probe, unmountErr)
+	} else {
+		_ = os.Remove(target)
+	}
+
+	return status.Errorf(verifyMountErrorCode(err), "Mounted %q at %q but could not access it with a %s probe, check the region, endpoint and credentials of the volume: %v", bucket, target, probe, err)
+}
+
+// probeMount runs `probe` on the mount at `target`. Accesses to unresponsive FUSE mounts cannot be interrupted, the
+// probe is abandoned once it times out.
+func probeMount(ctx context.Context, target, probe string) error {
+	ctx, cancel := context.WithTimeout(ctx, verifyMountTimeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		switch probe {
+		case volumecontext.VerifyMountList:
+			done <- listMount(target)
+		default:
+			done <- headMount(target)
+		}
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("probe did not complete: %w", context.Cause(ctx))
+	}
+}
+
+// listMount reads the first entry of the root directory of the mount at `target`.
+func listMount(target string) error {
+	dir, err := os.Open(target)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	if _, err := dir.ReadDir(1); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// headMount looks up [verifyMountProbeName] in the root directory of the mount at `target`, the name not existing
+// is a successful lookup.
+func headMount(target string) error {
+	if _, err := os.Stat(target); err != nil {
+		return err
+	}
+	_, err := os.Lstat(filepath.Join(target, verifyMountProbeName))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// verifyMountErrorCode returns the gRPC code of the failure `err` of a probe of [volumecontext.VerifyMount].
+func verifyMountErrorCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	case errors.Is(err, syscall.EACCES), errors.Is(err, syscall.EPERM):
+		return codes.PermissionDenied
+	default:
+		return codes.FailedPrecondition
+	}
+}
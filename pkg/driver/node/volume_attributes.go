@@ -0,0 +1,37 @@
+package node
+
+import (
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+)
+
+// ReasonUnknownVolumeAttributes is the reason of events recorded on workload Pods mounting volumes with volume
+// attributes the driver does not know, most likely misspelled.
+const ReasonUnknownVolumeAttributes = "UnknownVolumeAttributes"
+
+// validateVolumeContext checks the values of the volume attributes of `volumeCtx` against [volumecontext.Schema].
+// Unknown attributes are ignored, they are logged and reported with an event on the workload Pod.
+func (ns *S3NodeServer) validateVolumeContext(operation, volumeID string, volumeCtx map[string]string) error {
+	parsed := volumecontext.Schema.Parse(volumeCtx)
+	if err := parsed.Err(); err != nil {
+		return status.Errorf(codes.InvalidArgument, "Invalid volume attributes: %v", err)
+	}
+
+	warnings := parsed.Warnings()
+	if len(warnings) == 0 {
+		return nil
+	}
+	for _, warning := range warnings {
+		klog.Warningf("%s: volume %s: %s", operation, volumeID, warning)
+	}
+	if ns.Events != nil && volumeCtx[volumecontext.CSIPodName] != "" {
+		ns.Events.Eventf(workloadPodRef(volumeCtx), corev1.EventTypeWarning, ReasonUnknownVolumeAttributes, "Volume %s: %s", volumeID, strings.Join(warnings, "; "))
+	}
+	return nil
+}
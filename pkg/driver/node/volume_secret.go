@@ -0,0 +1,41 @@
+package node
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/credentialprovider"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
+)
+
+// applyVolumeSecret renames the keys of the Secret of a volume with `authenticationSource: secret` after its
+// [volumecontext.SecretKeyMapping], and mounts it with the region and S3 endpoint of its Secret unless its mount
+// options or volume attributes set them. Endpoints not allowed by the driver configuration are stripped by the
+// mounter, like `--endpoint-url` in mount options.
+func applyVolumeSecret(volumeCtx map[string]string, args *mountpoint.Args, credentialCtx *credentialprovider.ProvideContext) error {
+	mapping, err := volumecontext.ParseSecretKeyMapping(volumeCtx)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "Invalid Secret key mapping: %v", err)
+	}
+	if credentialCtx.AuthenticationSource != credentialprovider.AuthenticationSourceSecret {
+		return nil
+	}
+	credentialCtx.SecretData, err = credentialprovider.MapSecretKeys(credentialCtx.SecretData, mapping)
+	if err != nil {
+		return err
+	}
+
+	if region := credentialprovider.SecretRegion(credentialCtx.SecretData); region != "" && !args.Has(mountpoint.ArgRegion) {
+		klog.V(4).Infof("Volume %s is mounted with region %s of its Secret", credentialCtx.VolumeID, region)
+		args.Set(mountpoint.ArgRegion, region)
+		credentialCtx.BucketRegion = region
+	}
+	if endpointURL := credentialprovider.SecretEndpointURL(credentialCtx.SecretData); endpointURL != "" &&
+		!args.Has(mountpoint.ArgEndpointURL) && volumeCtx[volumecontext.EndpointURLs] == "" {
+		klog.V(4).Infof("Volume %s is mounted with endpoint %s of its Secret", credentialCtx.VolumeID, endpointURL)
+		setEndpointURL(args, endpointURL)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+// Package volumecondition checks the health of published volumes for the volume condition of `NodeGetVolumeStats`.
+//
+// kubelet reports abnormal volume conditions in events and metrics of the workload Pods using them, so volumes whose
+// Mountpoint process exited (`ENOTCONN`) or whose mount is hung are visible instead of applications silently blocking
+// on I/O. The check is a statfs of the target of the volume with a timeout, as FUSE mounts answer it from Mountpoint
+// without any S3 request.
+package volumecondition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// EnvEnabled is the environment variable enabling volume conditions in `NodeGetVolumeStats`.
+const EnvEnabled = "VOLUME_CONDITION_ENABLED"
+
+// CheckTimeout is how long a mount has to respond to statfs before its volume is reported abnormal.
+const CheckTimeout = 5 * time.Second
+
+// ErrVolumeNotFound is returned when there is nothing at the requested target.
+var ErrVolumeNotFound = errors.New("volume not found")
+
+// fuseSuperMagic is the filesystem type reported by statfs for FUSE mounts.
+const fuseSuperMagic = 0x65735546
+
+// A Condition is the health of a published volume.
+type Condition struct {
+	Abnormal bool
+	Message  string
+}
+
+// A Checker checks the condition of volumes published at their target.
+type Checker struct {
+	statfs  func(path string, stat *unix.Statfs_t) error
+	timeout time.Duration
+
+	mu sync.Mutex
+	// checking holds targets whose statfs has not returned, a hung mount blocks it indefinitely.
+	checking map[string]bool
+}
+
+// NewChecker returns a new checker failing checks of mounts not responding within `timeout`.
+func NewChecker(timeout time.Duration) *Checker {
+	if timeout <= 0 {
+		timeout = CheckTimeout
+	}
+	return &Checker{
+		statfs:   unix.Statfs,
+		timeout:  timeout,
+		checking: make(map[string]bool),
+	}
+}
+
+// Check returns the condition of the volume published at `target`.
+// It returns [ErrVolumeNotFound] if `target` does not exist.
+func (c *Checker) Check(ctx context.Context, target string) (Condition, error) {
+	if !c.startCheck(target) {
+		return Condition{Abnormal: true, Message: "Mount has not responded to statfs since a previous check"}, nil
+	}
+
+	type result struct {
+		stat unix.Statfs_t
+		err  error
+	}
+	results := make(chan result, 1)
+	go func() {
+		var r result
+		r.err = c.statfs(target, &r.stat)
+		c.finishCheck(target)
+		results <- r
+	}()
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+	select {
+	case r := <-results:
+		switch {
+		case errors.Is(r.err, unix.ENOENT):
+			return Condition{}, ErrVolumeNotFound
+		case errors.Is(r.err, unix.ENOTCONN):
+			return Condition{Abnormal: true, Message: fmt.Sprintf("Mount is disconnected, its Mountpoint process exited: %v", r.err)}, nil
+		case r.err != nil:
+			return Condition{Abnormal: true, Message: fmt.Sprintf("Mount failed to respond to statfs: %v", r.err)}, nil
+		case r.stat.Type != fuseSuperMagic:
+			return Condition{Abnormal: true, Message: "Volume is not mounted"}, nil
+		}
+		return Condition{Message: "Mount is responding"}, nil
+	case <-timer.C:
+		return Condition{Abnormal: true, Message: fmt.Sprintf("Mount did not respond to statfs within %v", c.timeout)}, nil
+	case <-ctx.Done():
+		return Condition{}, ctx.Err()
+	}
+}
+
+// startCheck marks a check of `target` as running, and returns false if a previous check is still running.
+func (c *Checker) startCheck(target string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.checking[target] {
+		return false
+	}
+	c.checking[target] = true
+	return true
+}
+
+func (c *Checker) finishCheck(target string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.checking, target)
+}
@@ -0,0 +1,105 @@
+package volumecondition
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestCheck(t *testing.T) {
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		name     string
+		statfs   func(path string, stat *unix.Statfs_t) error
+		abnormal bool
+		message  string
+	}{
+		{
+			name: "responding FUSE mount",
+			statfs: func(path string, stat *unix.Statfs_t) error {
+				stat.Type = fuseSuperMagic
+				return nil
+			},
+			message: "Mount is responding",
+		},
+		{
+			name:     "disconnected mount",
+			statfs:   func(path string, stat *unix.Statfs_t) error { return unix.ENOTCONN },
+			abnormal: true,
+			message:  "transport endpoint is not connected",
+		},
+		{
+			name:     "failing mount",
+			statfs:   func(path string, stat *unix.Statfs_t) error { return unix.EIO },
+			abnormal: true,
+			message:  "input/output error",
+		},
+		{
+			name:     "unmounted target",
+			statfs:   func(path string, stat *unix.Statfs_t) error { return nil },
+			abnormal: true,
+			message:  "not mounted",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			checker := NewChecker(time.Second)
+			checker.statfs = tc.statfs
+			condition, err := checker.Check(ctx, "/target")
+			assert.NoError(t, err)
+			assert.Equals(t, tc.abnormal, condition.Abnormal)
+			if !strings.Contains(condition.Message, tc.message) {
+				t.Errorf("Expected message to contain %q, got %q", tc.message, condition.Message)
+			}
+		})
+	}
+
+	t.Run("missing target", func(t *testing.T) {
+		checker := NewChecker(time.Second)
+		checker.statfs = func(path string, stat *unix.Statfs_t) error { return unix.ENOENT }
+		if _, err := checker.Check(ctx, "/target"); !errors.Is(err, ErrVolumeNotFound) {
+			t.Fatalf("Expected ErrVolumeNotFound, got %v", err)
+		}
+	})
+
+	t.Run("hung mount", func(t *testing.T) {
+		unblock := make(chan struct{})
+		checker := NewChecker(10 * time.Millisecond)
+		checker.statfs = func(path string, stat *unix.Statfs_t) error {
+			<-unblock
+			stat.Type = fuseSuperMagic
+			return nil
+		}
+
+		condition, err := checker.Check(ctx, "/target")
+		assert.NoError(t, err)
+		assert.Equals(t, true, condition.Abnormal)
+
+		// Checks are not stacked on a mount still blocking a previous one
+		condition, err = checker.Check(ctx, "/target")
+		assert.NoError(t, err)
+		assert.Equals(t, true, condition.Abnormal)
+		assert.Equals(t, true, strings.Contains(condition.Message, "previous check"))
+
+		close(unblock)
+		deadline := time.Now().Add(5 * time.Second)
+		for checker.isChecking("/target") && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		condition, err = checker.Check(ctx, "/target")
+		assert.NoError(t, err)
+		assert.Equals(t, false, condition.Abnormal)
+	})
+}
+
+func (c *Checker) isChecking(target string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.checking[target]
+}
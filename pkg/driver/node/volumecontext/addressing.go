@@ -0,0 +1,59 @@
+package volumecontext
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// AddressingStyle is how Mountpoint addresses the bucket of the volume on its S3 endpoint, [AddressingStylePath] or
+// [AddressingStyleVirtual].
+const AddressingStyle = "addressingStyle"
+
+// Values of [AddressingStyle].
+const (
+	// AddressingStylePath addresses the bucket in the path of requests, e.g. `https://s3.example.com/bucket/key`, the
+	// default. Mountpoint is run with `--force-path-style`.
+	AddressingStylePath = "path"
+	// AddressingStyleVirtual addresses the bucket in the host name of requests, e.g. `https://bucket.s3.example.com/key`.
+	// The S3 endpoint must be a host name whose subdomains resolve to it.
+	AddressingStyleVirtual = "virtual"
+)
+
+// BucketAlias is the name Mountpoint addresses the bucket of the volume with instead of its `bucketName`, e.g. an
+// alias of the bucket configured on the S3 endpoint, or a DNS-compatible alias of a bucket for virtual-hosted
+// addressing.
+const BucketAlias = "bucketAlias"
+
+// dnsBucketNameRegexp matches bucket names that can be used as a DNS label of virtual-hosted addressing.
+var dnsBucketNameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+
+// IsDNSCompatibleBucketName returns whether `bucket` can be addressed in the host name of requests.
+func IsDNSCompatibleBucketName(bucket string) bool {
+	return dnsBucketNameRegexp.MatchString(bucket)
+}
+
+// ParseAddressingStyle returns the [AddressingStyle] of `volumeCtx`, [AddressingStylePath] if it is not set.
+func ParseAddressingStyle(volumeCtx map[string]string) (string, error) {
+	style, ok := volumeCtx[AddressingStyle]
+	if !ok {
+		return AddressingStylePath, nil
+	}
+	switch style {
+	case AddressingStylePath, AddressingStyleVirtual:
+		return style, nil
+	default:
+		return "", fmt.Errorf("invalid %s %q, must be %s or %s", AddressingStyle, style, AddressingStylePath, AddressingStyleVirtual)
+	}
+}
+
+// ParseBucketAlias returns the [BucketAlias] of `volumeCtx`, empty if it is not set.
+func ParseBucketAlias(volumeCtx map[string]string) (string, error) {
+	alias, ok := volumeCtx[BucketAlias]
+	if !ok {
+		return "", nil
+	}
+	if !IsDNSCompatibleBucketName(alias) {
+		return "", fmt.Errorf("invalid %s %q, must be 3 to 63 lowercase letters, digits, dots and hyphens, starting and ending with a letter or a digit", BucketAlias, alias)
+	}
+	return alias, nil
+}
@@ -0,0 +1,85 @@
+package volumecontext
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// EnvAWSCompatibilityMode enables translation of volume attributes written for the AWS Mountpoint for Amazon S3
+// CSI Driver into their Scality equivalents.
+const EnvAWSCompatibilityMode = "AWS_COMPATIBILITY_MODE"
+
+// stsRegion is the AWS CSI Driver volume attribute configuring the region used for STS calls.
+const stsRegion = "stsRegion"
+
+// authenticationSourcePod is the AWS CSI Driver pod-level authentication source (IRSA or EKS Pod Identity).
+const authenticationSourcePod = "pod"
+
+// An alias describes how an AWS CSI Driver volume attribute is translated.
+type alias struct {
+	// values maps AWS attribute values to their translated values, values not listed are kept as is.
+	// The attribute is dropped if nil, unless values are rejected.
+	values map[string]string
+	// rejected lists AWS attribute values without equivalent, volumes using them fail to mount: translating them to
+	// another identity would grant access the manifest did not ask for.
+	rejected []string
+	// reason explains the translation in the emitted deprecation warning, or the rejection.
+	reason string
+}
+
+// awsAliases lists AWS CSI Driver volume attributes that differ from the ones understood by this driver.
+// Attributes sharing the same name and semantics in both drivers (e.g., `bucketName`) are not listed.
+var awsAliases = map[string]alias{
+	stsRegion: {
+		reason: "the STS endpoint is configured at driver level, credentials are taken from the driver, from a secret or from an assumed role",
+	},
+	AuthenticationSource: {
+		rejected: []string{authenticationSourcePod},
+		reason:   "pod-level credentials (IRSA or EKS Pod Identity) are not available with Scality S3, use `authenticationSource: webIdentity` with a `roleArn`, or `authenticationSource: secret`",
+	},
+}
+
+// TranslateAWSAliases returns a copy of `volumeCtx` where volume attributes specific to the AWS Mountpoint for
+// Amazon S3 CSI Driver are translated into their equivalents for this driver, along with a deprecation
+// warning for each translated attribute. `volumeCtx` is returned as is if there is nothing to translate.
+// An error is returned for attribute values without equivalent.
+func TranslateAWSAliases(volumeCtx map[string]string) (map[string]string, []string, error) {
+	var translated map[string]string
+	var warnings []string
+
+	// Sort keys to emit warnings in a deterministic order
+	for _, key := range slices.Sorted(maps.Keys(volumeCtx)) {
+		alias, ok := awsAliases[key]
+		if !ok {
+			continue
+		}
+		value := volumeCtx[key]
+		if slices.Contains(alias.rejected, value) {
+			return nil, nil, fmt.Errorf("volume attribute %s=%q is not supported: %s", key, value, alias.reason)
+		}
+		newValue, valueTranslated := alias.values[value]
+		dropped := alias.values == nil && alias.rejected == nil
+		if !dropped && !valueTranslated {
+			continue
+		}
+
+		if translated == nil {
+			translated = maps.Clone(volumeCtx)
+		}
+
+		if dropped {
+			delete(translated, key)
+			warnings = append(warnings, fmt.Sprintf("volume attribute %q is deprecated and ignored: %s", key, alias.reason))
+			continue
+		}
+
+		translated[key] = newValue
+		warnings = append(warnings, fmt.Sprintf("volume attribute %s=%q is deprecated, using %q: %s", key, value, newValue, alias.reason))
+	}
+
+	if translated == nil {
+		return volumeCtx, nil, nil
+	}
+	return translated, warnings, nil
+}
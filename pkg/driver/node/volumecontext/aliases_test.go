@@ -0,0 +1,73 @@
+package volumecontext_test
+
+import (
+	"testing"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestTranslateAWSAliases(t *testing.T) {
+	tests := []struct {
+		name         string
+		volumeCtx    map[string]string
+		want         map[string]string
+		wantWarnings int
+		wantErr      bool
+	}{
+		{
+			name:      "no AWS attributes",
+			volumeCtx: map[string]string{volumecontext.BucketName: "bucket", volumecontext.AuthenticationSource: "secret"},
+			want:      map[string]string{volumecontext.BucketName: "bucket", volumecontext.AuthenticationSource: "secret"},
+		},
+		{
+			name:      "pod authentication source is rejected",
+			volumeCtx: map[string]string{volumecontext.BucketName: "bucket", volumecontext.AuthenticationSource: "pod"},
+			wantErr:   true,
+		},
+		{
+			name:      "other authentication sources are kept",
+			volumeCtx: map[string]string{volumecontext.BucketName: "bucket", volumecontext.AuthenticationSource: "webIdentity"},
+			want:      map[string]string{volumecontext.BucketName: "bucket", volumecontext.AuthenticationSource: "webIdentity"},
+		},
+		{
+			name:         "STS region is dropped",
+			volumeCtx:    map[string]string{volumecontext.BucketName: "bucket", "stsRegion": "us-west-2"},
+			want:         map[string]string{volumecontext.BucketName: "bucket"},
+			wantWarnings: 1,
+		},
+		{
+			name: "multiple AWS attributes",
+			volumeCtx: map[string]string{
+				volumecontext.BucketName:           "bucket",
+				volumecontext.AuthenticationSource: "secret",
+				"stsRegion":                        "us-west-2",
+				"unknown":                          "kept",
+			},
+			want:         map[string]string{volumecontext.BucketName: "bucket", volumecontext.AuthenticationSource: "secret", "unknown": "kept"},
+			wantWarnings: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := make(map[string]string, len(tt.volumeCtx))
+			for k, v := range tt.volumeCtx {
+				original[k] = v
+			}
+
+			got, warnings, err := volumecontext.TranslateAWSAliases(tt.volumeCtx)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Expected an error, got %v", got)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equals(t, tt.want, got)
+			assert.Equals(t, tt.wantWarnings, len(warnings))
+			// Input must not be modified
+			assert.Equals(t, original, tt.volumeCtx)
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package volumecontext
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Attribute describes a volume attribute users can set on volumes of the driver.
+type Attribute struct {
+	Key         string `json:"key"`
+	Description string `json:"description"`
+	// Ephemeral is whether the attribute can be set on inline ephemeral volumes.
+	Ephemeral bool `json:"ephemeral"`
+	// Deprecated is the reason the attribute is deprecated, empty if it is supported.
+	Deprecated string `json:"deprecated,omitempty"`
+}
+
+// attributes are the volume attributes users can set, attributes set by kubelet or the driver itself are not listed.
+var attributes = []Attribute{
+	{Key: BucketName, Description: "Bucket to mount, defaults to the volume handle", Ephemeral: true},
+	{Key: AuthenticationSource, Description: "Credentials used to access the bucket: `driver`, `secret`, `role`, `file` or `webIdentity`. Inline ephemeral volumes only support `secret`", Ephemeral: true},
+	{Key: RoleARN, Description: "Role to assume with the driver credentials with `authenticationSource: role`, or with the service account token of the Pod with `authenticationSource: webIdentity`"},
+	{Key: CredentialsName, Description: "Credentials read from the credentials file directory of the node plugin with `authenticationSource: file`"},
+	{Key: SecretKeyMapping, Description: "Names of the keys of the Secret with `authenticationSource: secret`, e.g. `access_key_id=accessKeyID,secret_access_key=secretAccessKey`", Ephemeral: true},
+	{Key: ServiceAccountTokenAudience, Description: "Audience of the service account token the role is assumed with, with `authenticationSource: webIdentity`. Defaults to the audience of the node plugin"},
+	{Key: DualAuth, Description: "Side of a dual-auth pair of volumes: `read` with the driver credentials or a role, `write` with a secret", Ephemeral: true},
+	{Key: BucketAlias, Description: "Name the bucket is addressed with on the S3 endpoint instead of `bucketName`, e.g. an alias of the bucket", Ephemeral: true},
+	{Key: AddressingStyle, Description: "Addressing of the bucket on the S3 endpoint: `path`, the default, or `virtual` for virtual-hosted addressing", Ephemeral: true},
+	{Key: EndpointURL, Description: "S3 endpoint of the volume, it must be allowed by the cluster administrator", Ephemeral: true},
+	{Key: EndpointURLs, Description: "Comma-separated ordered list of S3 endpoints of the volume, mounted with the first reachable one. They must be allowed by the cluster administrator", Ephemeral: true},
+	{Key: CABundleSecretRef, Description: "Secret, as `[namespace/]name`, whose `ca-bundle.crt` is the CA bundle trusted by Mountpoint for the volume", Ephemeral: true},
+	{Key: ServerSideEncryption, Description: "Server-side encryption of objects written to the volume: `SSE-S3` or `SSE-KMS`", Ephemeral: true},
+	{Key: SSEKMSKeyID, Description: "KMS key encrypting objects written to the volume with `serverSideEncryption: SSE-KMS`", Ephemeral: true},
+	{Key: SecretName, Description: "Secret in the Pod's namespace holding the credentials of an inline ephemeral volume", Ephemeral: true},
+	{Key: Diagnostic, Description: "Mounts the bucket read-only with verbose logs to check whether a node can mount it", Ephemeral: true},
+	{Key: Logging, Description: "Log level and destination of Mountpoint for the volume as a JSON object, e.g. `{\"level\": \"debug\", \"destination\": \"file\"}`", Ephemeral: true},
+	{Key: PerformanceProfile, Description: "Metadata caching and concurrency of Mountpoint for the volume as a JSON object, e.g. `{\"profile\": \"throughput\", \"metadataTtl\": \"5m\"}`", Ephemeral: true},
+	{Key: Prefix, Description: "Bucket prefix to mount for volumes without mount options", Ephemeral: true},
+	{Key: EnsurePrefix, Description: "Creates the directory marker of the mounted prefix if it has no objects, read-only volumes fail to mount instead", Ephemeral: true},
+	{Key: VerifyMount, Description: "Probe accessing the volume through its new mount before it is published, failing the mount if it fails: `list`, `head` or `off`", Ephemeral: true},
+	{Key: MountpointPodServiceAccountName, Description: "Service account of the Mountpoint Pod"},
+	{Key: MountpointPodTolerations, Description: "Tolerations of the Mountpoint Pod as a JSON list, replacing the toleration of all taints"},
+	{Key: MountpointPodLabels, Description: "Labels added to the Mountpoint Pod as a JSON object"},
+	{Key: MountpointPodAnnotations, Description: "Annotations added to the Mountpoint Pod as a JSON object"},
+	{Key: MountpointPodTopologySpreadConstraints, Description: "Topology spread constraints of the Mountpoint Pod as a JSON list"},
+	{Key: Cache, Description: "Volume holding the Mountpoint cache: `emptyDir`, `memory` or `ephemeralPVC`"},
+	{Key: CacheSizeLimit, Description: "Size of the Mountpoint cache volume"},
+	{Key: MountpointContainerResourcesRequestsCpu, Description: "CPU request of the Mountpoint container"},
+	{Key: MountpointContainerResourcesRequestsMemory, Description: "Memory request of the Mountpoint container"},
+	{Key: MountpointContainerResourcesLimitsCpu, Description: "CPU limit of the Mountpoint container"},
+	{Key: MountpointContainerResourcesLimitsMemory, Description: "Memory limit of the Mountpoint container"},
+}
+
+// Attributes returns the volume attributes supported by the driver, including deprecated AWS attributes
+// translated or rejected by [TranslateAWSAliases], sorted by key.
+func Attributes() []Attribute {
+	all := slices.Clone(attributes)
+	for _, key := range slices.Sorted(maps.Keys(awsAliases)) {
+		if i := slices.IndexFunc(all, func(a Attribute) bool { return a.Key == key }); i >= 0 {
+			alias := awsAliases[key]
+			if len(alias.rejected) > 0 {
+				all[i].Deprecated = fmt.Sprintf("value `%s` is not supported: %s", strings.Join(alias.rejected, "`, `"), alias.reason)
+				continue
+			}
+			values := slices.Sorted(maps.Keys(alias.values))
+			all[i].Deprecated = fmt.Sprintf("value `%s` is deprecated: %s", strings.Join(values, "`, `"), alias.reason)
+			continue
+		}
+		all = append(all, Attribute{Key: key, Ephemeral: true, Deprecated: awsAliases[key].reason})
+	}
+	slices.SortFunc(all, func(a, b Attribute) int { return strings.Compare(a.Key, b.Key) })
+	return all
+}
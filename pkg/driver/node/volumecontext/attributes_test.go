@@ -0,0 +1,25 @@
+package volumecontext_test
+
+import (
+	"testing"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestAttributes(t *testing.T) {
+	attributes := volumecontext.Attributes()
+	keys := make(map[string]volumecontext.Attribute, len(attributes))
+	for _, attribute := range attributes {
+		if _, ok := keys[attribute.Key]; ok {
+			t.Errorf("Duplicate volume attribute %q", attribute.Key)
+		}
+		keys[attribute.Key] = attribute
+	}
+
+	assert.Equals(t, "", keys[volumecontext.BucketName].Deprecated)
+	assert.Equals(t, false, keys[volumecontext.MountpointPodServiceAccountName].Ephemeral)
+	if keys["stsRegion"].Deprecated == "" || keys[volumecontext.AuthenticationSource].Deprecated == "" {
+		t.Errorf("Expected AWS attributes to be deprecated, got %+v", attributes)
+	}
+}
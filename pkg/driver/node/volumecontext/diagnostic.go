@@ -0,0 +1,38 @@
+package volumecontext
+
+import (
+	"crypto/sha256"
+	"fmt"
+)
+
+// EnvDiagnosticMountNamespace is the environment variable configuring the only namespace where Pods can use
+// diagnostic mounts. Diagnostic mounts are disabled if unset.
+const EnvDiagnosticMountNamespace = "DIAGNOSTIC_MOUNT_NAMESPACE"
+
+const (
+	// CSIEphemeral is set to "true" by kubelet for inline ephemeral volumes.
+	CSIEphemeral = "csi.storage.k8s.io/ephemeral"
+
+	// Diagnostic marks an inline ephemeral volume as a diagnostic mount, which mounts a bucket read-only
+	// with verbose Mountpoint logs to check whether a node can mount it.
+	Diagnostic = "diagnostic"
+
+	// Prefix is the bucket prefix to mount for volumes without mount options, such as inline ephemeral volumes.
+	Prefix = "prefix"
+)
+
+// IsEphemeral returns whether `volumeCtx` is from an inline ephemeral volume.
+func IsEphemeral(volumeCtx map[string]string) bool {
+	return volumeCtx[CSIEphemeral] == "true"
+}
+
+// IsDiagnostic returns whether `volumeCtx` is from a diagnostic mount.
+func IsDiagnostic(volumeCtx map[string]string) bool {
+	return volumeCtx[Diagnostic] == "true"
+}
+
+// EphemeralVolumeID returns the volume ID kubelet generates for the inline ephemeral volume `volumeName`
+// of the Pod `podUID`.
+func EphemeralVolumeID(podUID, volumeName string) string {
+	return fmt.Sprintf("csi-%x", sha256.Sum256([]byte(podUID+volumeName)))
+}
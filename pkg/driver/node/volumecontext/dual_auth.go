@@ -0,0 +1,41 @@
+package volumecontext
+
+import "fmt"
+
+// DualAuth marks a volume as one side of a dual-auth pair: two volumes of the same bucket, one reading with the driver
+// credentials or an assumed role, the other writing with the narrowly scoped credentials of a secret. Mountpoint
+// signs all requests of a mount with a single identity, so reads and writes with different identities need two
+// mounts, each served by its own Mountpoint Pod.
+const DualAuth = "dualAuth"
+
+// Sides of a dual-auth pair, values of [DualAuth].
+const (
+	// DualAuthRead volumes are always mounted read-only, with `authenticationSource: driver` or `role`.
+	DualAuthRead = "read"
+	// DualAuthWrite volumes cannot be mounted read-only, and require `authenticationSource: secret`.
+	DualAuthWrite = "write"
+)
+
+// ParseDualAuth returns the side of the dual-auth pair of `volumeCtx`, empty if it is not part of one, after checking
+// its authentication source matches the side. `authenticationSource` must be set explicitly, so the identity of each
+// side is visible on the volume.
+func ParseDualAuth(volumeCtx map[string]string) (string, error) {
+	side, ok := volumeCtx[DualAuth]
+	if !ok {
+		return "", nil
+	}
+	source := volumeCtx[AuthenticationSource]
+	switch side {
+	case DualAuthRead:
+		if source != "driver" && source != "role" {
+			return "", fmt.Errorf("%s: %s requires %s: driver or role, got %q", DualAuth, DualAuthRead, AuthenticationSource, source)
+		}
+	case DualAuthWrite:
+		if source != "secret" {
+			return "", fmt.Errorf("%s: %s requires %s: secret, got %q", DualAuth, DualAuthWrite, AuthenticationSource, source)
+		}
+	default:
+		return "", fmt.Errorf("invalid %s %q, must be %s or %s", DualAuth, side, DualAuthRead, DualAuthWrite)
+	}
+	return side, nil
+}
@@ -0,0 +1,81 @@
+package volumecontext
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// ServerSideEncryption is the server-side encryption of objects written to the volume: `SSE-S3` or `SSE-KMS`.
+	// Objects are written with the default encryption of the bucket if unset.
+	ServerSideEncryption = "serverSideEncryption"
+	// SSEKMSKeyID is the KMS key encrypting objects written to the volume with `serverSideEncryption: SSE-KMS`.
+	// The default KMS key of the bucket is used if unset.
+	SSEKMSKeyID = "sseKmsKeyId"
+)
+
+// Server-side encryptions of [ServerSideEncryption].
+const (
+	SSES3  = "SSE-S3"
+	SSEKMS = "SSE-KMS"
+	// SSEC is rejected, Mountpoint does not support customer-provided keys.
+	SSEC = "SSE-C"
+)
+
+// sseCustomerKeyPrefix prefixes volume attributes users might try to pass SSE-C keys with, e.g. `sseCustomerKey`.
+const sseCustomerKeyPrefix = "sseCustomer"
+
+// maxSSEKMSKeyIDLength is the maximum length of [SSEKMSKeyID], the maximum length of KMS key ARNs.
+const maxSSEKMSKeyIDLength = 2048
+
+// An Encryption is the server-side encryption of objects written to a volume.
+type Encryption struct {
+	// SSE is the value of Mountpoint's `--sse`, empty to use the default encryption of the bucket.
+	SSE string
+	// KMSKeyID is the value of Mountpoint's `--sse-kms-key-id`, empty to use the default KMS key of the bucket.
+	KMSKeyID string
+}
+
+// ParseEncryption returns the server-side encryption configured by [ServerSideEncryption] and [SSEKMSKeyID] in
+// `volumeCtx`, after checking they form a valid combination.
+//
+// SSE-C keys are rejected: volume attributes are readable by anyone who can read the PersistentVolume or the Pod,
+// and Mountpoint does not support customer-provided keys.
+func ParseEncryption(volumeCtx map[string]string) (Encryption, error) {
+	for key := range volumeCtx {
+		if strings.HasPrefix(key, sseCustomerKeyPrefix) {
+			return Encryption{}, fmt.Errorf("volume attribute %s is not supported: SSE-C keys must never be set in volume attributes, which are readable by anyone who can read the volume, and %s is not supported by Mountpoint", key, SSEC)
+		}
+	}
+
+	sse := strings.TrimSpace(volumeCtx[ServerSideEncryption])
+	kmsKeyID := strings.TrimSpace(volumeCtx[SSEKMSKeyID])
+
+	var encryption Encryption
+	switch sse {
+	case "":
+		if kmsKeyID != "" {
+			return Encryption{}, fmt.Errorf("%s requires %s: %s", SSEKMSKeyID, ServerSideEncryption, SSEKMS)
+		}
+		return Encryption{}, nil
+	case SSES3:
+		if kmsKeyID != "" {
+			return Encryption{}, fmt.Errorf("%s is only supported with %s: %s, got %s", SSEKMSKeyID, ServerSideEncryption, SSEKMS, SSES3)
+		}
+		encryption.SSE = "AES256"
+	case SSEKMS:
+		encryption.SSE = "aws:kms"
+	case SSEC:
+		return Encryption{}, fmt.Errorf("%s %s is not supported by Mountpoint", ServerSideEncryption, SSEC)
+	default:
+		return Encryption{}, fmt.Errorf("invalid %s %q, must be %s or %s", ServerSideEncryption, sse, SSES3, SSEKMS)
+	}
+
+	if kmsKeyID != "" {
+		if len(kmsKeyID) > maxSSEKMSKeyIDLength || strings.ContainsAny(kmsKeyID, ", \t\n") {
+			return Encryption{}, fmt.Errorf("invalid %s %q, must be a KMS key ID or ARN of at most %d characters without spaces or commas", SSEKMSKeyID, kmsKeyID, maxSSEKMSKeyIDLength)
+		}
+		encryption.KMSKeyID = kmsKeyID
+	}
+	return encryption, nil
+}
@@ -0,0 +1,86 @@
+package volumecontext_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestParseEncryption(t *testing.T) {
+	testCases := []struct {
+		name      string
+		volumeCtx map[string]string
+		want      volumecontext.Encryption
+		wantErr   string
+	}{
+		{
+			name:      "default encryption of the bucket",
+			volumeCtx: map[string]string{"bucketName": "bucket"},
+		},
+		{
+			name:      "SSE-S3",
+			volumeCtx: map[string]string{"serverSideEncryption": "SSE-S3"},
+			want:      volumecontext.Encryption{SSE: "AES256"},
+		},
+		{
+			name:      "SSE-KMS with the default key of the bucket",
+			volumeCtx: map[string]string{"serverSideEncryption": "SSE-KMS"},
+			want:      volumecontext.Encryption{SSE: "aws:kms"},
+		},
+		{
+			name:      "SSE-KMS with a key",
+			volumeCtx: map[string]string{"serverSideEncryption": "SSE-KMS", "sseKmsKeyId": " arn:aws:kms:us-east-1:000000000000:key/volume "},
+			want:      volumecontext.Encryption{SSE: "aws:kms", KMSKeyID: "arn:aws:kms:us-east-1:000000000000:key/volume"},
+		},
+		{
+			name:      "KMS key without SSE-KMS",
+			volumeCtx: map[string]string{"sseKmsKeyId": "volume"},
+			wantErr:   "sseKmsKeyId requires serverSideEncryption: SSE-KMS",
+		},
+		{
+			name:      "KMS key with SSE-S3",
+			volumeCtx: map[string]string{"serverSideEncryption": "SSE-S3", "sseKmsKeyId": "volume"},
+			wantErr:   "sseKmsKeyId is only supported with serverSideEncryption: SSE-KMS",
+		},
+		{
+			name:      "invalid KMS key",
+			volumeCtx: map[string]string{"serverSideEncryption": "SSE-KMS", "sseKmsKeyId": "key-a,key-b"},
+			wantErr:   `invalid sseKmsKeyId "key-a,key-b"`,
+		},
+		{
+			name:      "unknown encryption",
+			volumeCtx: map[string]string{"serverSideEncryption": "aws:kms"},
+			wantErr:   `invalid serverSideEncryption "aws:kms"`,
+		},
+		{
+			name:      "SSE-C",
+			volumeCtx: map[string]string{"serverSideEncryption": "SSE-C"},
+			wantErr:   "SSE-C is not supported by Mountpoint",
+		},
+		{
+			name:      "SSE-C key in plain attributes",
+			volumeCtx: map[string]string{"serverSideEncryption": "SSE-C", "sseCustomerKey": "c2VjcmV0"},
+			wantErr:   "SSE-C keys must never be set in volume attributes",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			encryption, err := volumecontext.ParseEncryption(tc.volumeCtx)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				assert.Equals(t, tc.want, encryption)
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("Expected error containing %q, got %v", tc.wantErr, err)
+			}
+			// Rejected keys are never echoed back in errors
+			if key := tc.volumeCtx["sseCustomerKey"]; key != "" && strings.Contains(err.Error(), key) {
+				t.Fatalf("Expected SSE-C key not to be in error %q", err)
+			}
+		})
+	}
+}
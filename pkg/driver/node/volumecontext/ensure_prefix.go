@@ -0,0 +1,23 @@
+package volumecontext
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// EnsurePrefix makes the node plugin check the prefix of the volume has objects before mounting it, creating its
+// zero-byte directory marker if it has none, "true" or "false". Prefixes of read-only volumes are only checked.
+const EnsurePrefix = "ensurePrefix"
+
+// ParseEnsurePrefix returns whether the prefix of the volume must be ensured with [EnsurePrefix] in `volumeCtx`.
+func ParseEnsurePrefix(volumeCtx map[string]string) (bool, error) {
+	value, ok := volumeCtx[EnsurePrefix]
+	if !ok {
+		return false, nil
+	}
+	ensure, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q, must be true or false", EnsurePrefix, value)
+	}
+	return ensure, nil
+}
@@ -0,0 +1,9 @@
+package volumecontext
+
+// EnvEphemeralVolumesEnabled is the environment variable enabling inline ephemeral volumes, which mount a bucket
+// declared in the Pod spec without a PersistentVolume. Only diagnostic mounts are allowed if unset.
+const EnvEphemeralVolumesEnabled = "EPHEMERAL_VOLUMES_ENABLED"
+
+// SecretName is the Secret in the Pod's namespace holding the credentials of an inline ephemeral volume.
+// Inline volumes have no `nodePublishSecretRef`, the node plugin reads the Secret itself.
+const SecretName = "secretName"
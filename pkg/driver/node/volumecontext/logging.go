@@ -0,0 +1,86 @@
+package volumecontext
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Logging is the logging configuration of Mountpoint for the volume, JSON-encoded, e.g.
+// `{"level": "debug", "destination": "file", "filterCrtDebug": true}`. Mountpoint logs at the default level to
+// the logs of its container if unset.
+const Logging = "logging"
+
+// Log levels of [Logging].
+const (
+	LogLevelError = "error"
+	LogLevelWarn  = "warn"
+	LogLevelInfo  = "info"
+	LogLevelDebug = "debug"
+	LogLevelTrace = "trace"
+)
+
+// Log destinations of [Logging].
+const (
+	// LogDestinationStderr writes logs to the logs of the Mountpoint container.
+	LogDestinationStderr = "stderr"
+	// LogDestinationFile writes logs to files in a dedicated volume of the Mountpoint Pod.
+	LogDestinationFile = "file"
+)
+
+// A LogConfig is the logging configuration of Mountpoint for a volume.
+type LogConfig struct {
+	// Level is the log level of Mountpoint, [LogLevelInfo] if unset.
+	Level string `json:"level,omitempty"`
+	// Destination is where Mountpoint writes its logs, [LogDestinationStderr] if unset.
+	Destination string `json:"destination,omitempty"`
+	// FilterCRTDebug filters out debug logs of the AWS Common Runtime, which are very verbose. It defaults to true.
+	FilterCRTDebug *bool `json:"filterCrtDebug,omitempty"`
+}
+
+// Verbose returns whether `c` logs at the debug level or below.
+func (c LogConfig) Verbose() bool {
+	return c.Level == LogLevelDebug || c.Level == LogLevelTrace
+}
+
+// FiltersCRTDebug returns whether debug logs of the AWS Common Runtime are filtered out.
+func (c LogConfig) FiltersCRTDebug() bool {
+	return c.FilterCRTDebug == nil || *c.FilterCRTDebug
+}
+
+// ParseLogging returns the logging configuration set by [Logging] in `volumeCtx`, with its defaults filled in, after
+// checking its values are valid.
+func ParseLogging(volumeCtx map[string]string) (LogConfig, error) {
+	config := LogConfig{Level: LogLevelInfo, Destination: LogDestinationStderr}
+	value, ok := volumeCtx[Logging]
+	if !ok {
+		return config, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader([]byte(value)))
+	decoder.DisallowUnknownFields()
+	var parsed LogConfig
+	if err := decoder.Decode(&parsed); err != nil {
+		return LogConfig{}, fmt.Errorf("invalid %s %q, must be a JSON object with level, destination and filterCrtDebug: %w", Logging, value, err)
+	}
+
+	switch parsed.Level {
+	case "":
+	case LogLevelError, LogLevelWarn, LogLevelInfo, LogLevelDebug, LogLevelTrace:
+		config.Level = parsed.Level
+	default:
+		return LogConfig{}, fmt.Errorf("invalid %s level %q, must be %s, %s, %s, %s or %s", Logging, parsed.Level, LogLevelError, LogLevelWarn, LogLevelInfo, LogLevelDebug, LogLevelTrace)
+	}
+	switch parsed.Destination {
+	case "":
+	case LogDestinationStderr, LogDestinationFile:
+		config.Destination = parsed.Destination
+	default:
+		return LogConfig{}, fmt.Errorf("invalid %s destination %q, must be %s or %s", Logging, parsed.Destination, LogDestinationStderr, LogDestinationFile)
+	}
+	if parsed.FilterCRTDebug != nil && !*parsed.FilterCRTDebug && !config.Verbose() {
+		return LogConfig{}, fmt.Errorf("%s filterCrtDebug: false requires level %s or %s", Logging, LogLevelDebug, LogLevelTrace)
+	}
+	config.FilterCRTDebug = parsed.FilterCRTDebug
+	return config, nil
+}
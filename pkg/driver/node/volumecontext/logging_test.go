@@ -0,0 +1,90 @@
+package volumecontext_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestParseLogging(t *testing.T) {
+	testCases := []struct {
+		name          string
+		volumeCtx     map[string]string
+		wantLevel     string
+		wantDest      string
+		wantFilterCRT bool
+		wantErr       string
+	}{
+		{
+			name:          "default logging",
+			volumeCtx:     map[string]string{"bucketName": "bucket"},
+			wantLevel:     "info",
+			wantDest:      "stderr",
+			wantFilterCRT: true,
+		},
+		{
+			name:          "debug logs to files",
+			volumeCtx:     map[string]string{"logging": `{"level": "debug", "destination": "file"}`},
+			wantLevel:     "debug",
+			wantDest:      "file",
+			wantFilterCRT: true,
+		},
+		{
+			name:          "trace logs with CRT debug logs",
+			volumeCtx:     map[string]string{"logging": `{"level": "trace", "filterCrtDebug": false}`},
+			wantLevel:     "trace",
+			wantDest:      "stderr",
+			wantFilterCRT: false,
+		},
+		{
+			name:          "warnings only",
+			volumeCtx:     map[string]string{"logging": `{"level": "warn", "destination": "stderr", "filterCrtDebug": true}`},
+			wantLevel:     "warn",
+			wantDest:      "stderr",
+			wantFilterCRT: true,
+		},
+		{
+			name:      "invalid JSON",
+			volumeCtx: map[string]string{"logging": "debug"},
+			wantErr:   `invalid logging "debug"`,
+		},
+		{
+			name:      "unknown field",
+			volumeCtx: map[string]string{"logging": `{"level": "debug", "format": "json"}`},
+			wantErr:   `unknown field "format"`,
+		},
+		{
+			name:      "invalid level",
+			volumeCtx: map[string]string{"logging": `{"level": "verbose"}`},
+			wantErr:   `invalid logging level "verbose"`,
+		},
+		{
+			name:      "invalid destination",
+			volumeCtx: map[string]string{"logging": `{"destination": "syslog"}`},
+			wantErr:   `invalid logging destination "syslog"`,
+		},
+		{
+			name:      "CRT debug logs without debug level",
+			volumeCtx: map[string]string{"logging": `{"filterCrtDebug": false}`},
+			wantErr:   "logging filterCrtDebug: false requires level debug or trace",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := volumecontext.ParseLogging(tc.volumeCtx)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equals(t, tc.wantLevel, got.Level)
+			assert.Equals(t, tc.wantDest, got.Destination)
+			assert.Equals(t, tc.wantFilterCRT, got.FiltersCRTDebug())
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package volumecontext
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// PerformanceProfile tunes metadata caching and concurrency of Mountpoint for the volume, JSON-encoded, e.g.
+// `{"profile": "throughput", "metadataTtl": "5m", "maxThreads": 32}`. Mountpoint runs with its own defaults if unset.
+const PerformanceProfile = "performanceProfile"
+
+// Profiles of [PerformanceProfile], each with its own defaults for the fields left unset.
+const (
+	// ProfileConsistency always revalidates metadata with S3, so changes made by other clients are seen immediately.
+	ProfileConsistency = "consistency"
+	// ProfileThroughput caches metadata and runs more threads, for read-heavy workloads on buckets rarely changed
+	// by other clients.
+	ProfileThroughput = "throughput"
+)
+
+// Special values of the metadata TTL of [PerformanceProfile], other values are durations.
+const (
+	MetadataTTLMinimal    = "minimal"
+	MetadataTTLIndefinite = "indefinite"
+)
+
+// Bounds of [PerformanceProfile] values.
+const (
+	maxMetadataTTL = 24 * time.Hour
+	minMaxThreads  = 1
+	maxMaxThreads  = 256
+)
+
+// throughputMetadataTTL and throughputMaxThreads are the defaults of [ProfileThroughput].
+const (
+	throughputMetadataTTL = "60"
+	throughputMaxThreads  = 64
+)
+
+// A PerformanceConfig is the performance tuning of Mountpoint for a volume.
+type PerformanceConfig struct {
+	// Profile is the profile the defaults are taken from, empty if [PerformanceProfile] is unset.
+	Profile string
+	// MetadataTTL is the value of `--metadata-ttl`: seconds, [MetadataTTLMinimal] or [MetadataTTLIndefinite].
+	MetadataTTL string
+	// AllowOverwrite is whether existing objects can be overwritten, with `--allow-overwrite`.
+	AllowOverwrite bool
+	// MaxThreads is the value of `--max-threads`, zero to keep the default of Mountpoint.
+	MaxThreads int
+}
+
+// performanceProfile is the JSON encoding of [PerformanceProfile].
+type performanceProfile struct {
+	Profile        string `json:"profile,omitempty"`
+	MetadataTTL    string `json:"metadataTtl,omitempty"`
+	AllowOverwrite *bool  `json:"allowOverwrite,omitempty"`
+	MaxThreads     *int   `json:"maxThreads,omitempty"`
+}
+
+// ParsePerformanceProfile returns the performance tuning set by [PerformanceProfile] in `volumeCtx`, with the
+// defaults of its profile filled in, after checking its values are within bounds. The returned config is empty if
+// the attribute is unset.
+func ParsePerformanceProfile(volumeCtx map[string]string) (PerformanceConfig, error) {
+	value, ok := volumeCtx[PerformanceProfile]
+	if !ok {
+		return PerformanceConfig{}, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader([]byte(value)))
+	decoder.DisallowUnknownFields()
+	var parsed performanceProfile
+	if err := decoder.Decode(&parsed); err != nil {
+		return PerformanceConfig{}, fmt.Errorf("invalid %s %q, must be a JSON object with profile, metadataTtl, allowOverwrite and maxThreads: %w", PerformanceProfile, value, err)
+	}
+
+	var config PerformanceConfig
+	switch parsed.Profile {
+	case "", ProfileConsistency:
+		config = PerformanceConfig{Profile: ProfileConsistency, MetadataTTL: MetadataTTLMinimal}
+	case ProfileThroughput:
+		config = PerformanceConfig{Profile: ProfileThroughput, MetadataTTL: throughputMetadataTTL, MaxThreads: throughputMaxThreads}
+	default:
+		return PerformanceConfig{}, fmt.Errorf("invalid %s profile %q, must be %s or %s", PerformanceProfile, parsed.Profile, ProfileConsistency, ProfileThroughput)
+	}
+
+	if parsed.MetadataTTL != "" {
+		ttl, err := parseMetadataTTL(parsed.MetadataTTL)
+		if err != nil {
+			return PerformanceConfig{}, err
+		}
+		config.MetadataTTL = ttl
+	}
+	if parsed.AllowOverwrite != nil {
+		config.AllowOverwrite = *parsed.AllowOverwrite
+	}
+	if parsed.MaxThreads != nil {
+		if *parsed.MaxThreads < minMaxThreads || *parsed.MaxThreads > maxMaxThreads {
+			return PerformanceConfig{}, fmt.Errorf("invalid %s maxThreads %d, must be between %d and %d", PerformanceProfile, *parsed.MaxThreads, minMaxThreads, maxMaxThreads)
+		}
+		config.MaxThreads = *parsed.MaxThreads
+	}
+	return config, nil
+}
+
+// parseMetadataTTL returns the `--metadata-ttl` value of `ttl`, [MetadataTTLMinimal], [MetadataTTLIndefinite] or a
+// duration of whole seconds up to [maxMetadataTTL].
+func parseMetadataTTL(ttl string) (string, error) {
+	if ttl == MetadataTTLMinimal || ttl == MetadataTTLIndefinite {
+		return ttl, nil
+	}
+	duration, err := time.ParseDuration(ttl)
+	if err != nil || duration < 0 || duration > maxMetadataTTL || duration%time.Second != 0 {
+		return "", fmt.Errorf("invalid %s metadataTtl %q, must be %s, %s or a duration of whole seconds up to %s", PerformanceProfile, ttl, MetadataTTLMinimal, MetadataTTLIndefinite, maxMetadataTTL)
+	}
+	return strconv.Itoa(int(duration / time.Second)), nil
+}
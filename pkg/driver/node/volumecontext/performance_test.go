@@ -0,0 +1,92 @@
+package volumecontext_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestParsePerformanceProfile(t *testing.T) {
+	testCases := []struct {
+		name      string
+		volumeCtx map[string]string
+		want      volumecontext.PerformanceConfig
+		wantErr   string
+	}{
+		{
+			name:      "unset",
+			volumeCtx: map[string]string{"bucketName": "bucket"},
+		},
+		{
+			name:      "consistency defaults",
+			volumeCtx: map[string]string{"performanceProfile": `{}`},
+			want:      volumecontext.PerformanceConfig{Profile: "consistency", MetadataTTL: "minimal"},
+		},
+		{
+			name:      "throughput defaults",
+			volumeCtx: map[string]string{"performanceProfile": `{"profile": "throughput"}`},
+			want:      volumecontext.PerformanceConfig{Profile: "throughput", MetadataTTL: "60", MaxThreads: 64},
+		},
+		{
+			name:      "throughput with overrides",
+			volumeCtx: map[string]string{"performanceProfile": `{"profile": "throughput", "metadataTtl": "5m", "allowOverwrite": true, "maxThreads": 32}`},
+			want:      volumecontext.PerformanceConfig{Profile: "throughput", MetadataTTL: "300", AllowOverwrite: true, MaxThreads: 32},
+		},
+		{
+			name:      "indefinite metadata TTL",
+			volumeCtx: map[string]string{"performanceProfile": `{"profile": "throughput", "metadataTtl": "indefinite"}`},
+			want:      volumecontext.PerformanceConfig{Profile: "throughput", MetadataTTL: "indefinite", MaxThreads: 64},
+		},
+		{
+			name:      "invalid JSON",
+			volumeCtx: map[string]string{"performanceProfile": "throughput"},
+			wantErr:   `invalid performanceProfile "throughput"`,
+		},
+		{
+			name:      "unknown field",
+			volumeCtx: map[string]string{"performanceProfile": `{"negativeMetadataTtl": "1s"}`},
+			wantErr:   `unknown field "negativeMetadataTtl"`,
+		},
+		{
+			name:      "invalid profile",
+			volumeCtx: map[string]string{"performanceProfile": `{"profile": "latency"}`},
+			wantErr:   `invalid performanceProfile profile "latency"`,
+		},
+		{
+			name:      "metadata TTL too long",
+			volumeCtx: map[string]string{"performanceProfile": `{"metadataTtl": "48h"}`},
+			wantErr:   `invalid performanceProfile metadataTtl "48h"`,
+		},
+		{
+			name:      "metadata TTL not in whole seconds",
+			volumeCtx: map[string]string{"performanceProfile": `{"metadataTtl": "1500ms"}`},
+			wantErr:   `invalid performanceProfile metadataTtl "1500ms"`,
+		},
+		{
+			name:      "too many threads",
+			volumeCtx: map[string]string{"performanceProfile": `{"maxThreads": 1024}`},
+			wantErr:   "invalid performanceProfile maxThreads 1024, must be between 1 and 256",
+		},
+		{
+			name:      "no threads",
+			volumeCtx: map[string]string{"performanceProfile": `{"maxThreads": 0}`},
+			wantErr:   "invalid performanceProfile maxThreads 0",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := volumecontext.ParsePerformanceProfile(tc.volumeCtx)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equals(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package volumecontext
+
+import (
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/params"
+)
+
+// DynamicProvisioning is set by the driver on the volume context of provisioned volumes.
+const DynamicProvisioning = "dynamicProvisioning"
+
+// attributeTypes are the types of volume attributes whose values are validated by [Schema], other attributes are
+// validated when they are parsed.
+var attributeTypes = map[string]params.Param{
+	AddressingStyle:                         {Type: params.Enum, Values: []string{AddressingStylePath, AddressingStyleVirtual}},
+	DualAuth:                                {Type: params.Enum, Values: []string{DualAuthRead, DualAuthWrite}},
+	EnsurePrefix:                            {Type: params.Bool},
+	VerifyMount:                             {Type: params.Enum, Values: []string{VerifyMountList, VerifyMountHead, VerifyMountOff}},
+	Logging:                                 {Type: params.JSONObject},
+	PerformanceProfile:                      {Type: params.JSONObject},
+	MountpointPodTolerations:                {Type: params.JSONList},
+	MountpointPodLabels:                     {Type: params.JSONObject},
+	MountpointPodAnnotations:                {Type: params.JSONObject},
+	MountpointPodTopologySpreadConstraints:  {Type: params.JSONList},
+	Cache:                                   {Type: params.Enum, Values: []string{"emptyDir", "memory", "ephemeralPVC"}},
+	CacheSizeLimit:                          {Type: params.Quantity},
+	MountpointContainerResourcesRequestsCpu: {Type: params.Quantity},
+	MountpointContainerResourcesRequestsMemory: {Type: params.Quantity},
+	MountpointContainerResourcesLimitsCpu:      {Type: params.Quantity},
+	MountpointContainerResourcesLimitsMemory:   {Type: params.Quantity},
+}
+
+// Schema describes the volume attributes of the driver: the attributes users can set, deprecated AWS attributes,
+// and attributes set by kubelet, the external-provisioner and the driver itself.
+var Schema = newSchema()
+
+func newSchema() *params.Schema {
+	var all []params.Param
+	for _, attribute := range Attributes() {
+		p := attributeTypes[attribute.Key]
+		p.Key = attribute.Key
+		all = append(all, p)
+	}
+	all = append(all, params.Param{Key: DynamicProvisioning, Type: params.Bool})
+	return params.NewSchema(all, "csi.storage.k8s.io/", "storage.kubernetes.io/", PVCMetadataPrefix)
+}
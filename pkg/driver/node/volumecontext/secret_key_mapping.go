@@ -0,0 +1,48 @@
+package volumecontext
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// SecretKeyMapping maps the keys of the Secret of a volume with `authenticationSource: secret` to the keys the
+// driver expects, for Secrets using other names, e.g. `access_key_id=accessKeyID,secret_access_key=secretAccessKey`.
+const SecretKeyMapping = "secretKeyMapping"
+
+// maxSecretKeyMappingLength is the maximum length of [SecretKeyMapping].
+const maxSecretKeyMappingLength = 1024
+
+// secretKeyRe matches valid keys of Secret data.
+var secretKeyRe = regexp.MustCompile(`^[-._a-zA-Z0-9]{1,253}$`)
+
+// ParseSecretKeyMapping returns the mapping of [SecretKeyMapping] in `volumeCtx`, from the keys the driver expects to
+// the keys of the Secret, nil if unset.
+func ParseSecretKeyMapping(volumeCtx map[string]string) (map[string]string, error) {
+	value := strings.TrimSpace(volumeCtx[SecretKeyMapping])
+	if value == "" {
+		return nil, nil
+	}
+	if len(value) > maxSecretKeyMappingLength {
+		return nil, fmt.Errorf("%s is too long: %d characters, maximum is %d", SecretKeyMapping, len(value), maxSecretKeyMappingLength)
+	}
+
+	mapping := make(map[string]string)
+	names := make(map[string]bool)
+	for _, pair := range strings.Split(value, ",") {
+		key, name, ok := strings.Cut(pair, "=")
+		key, name = strings.TrimSpace(key), strings.TrimSpace(name)
+		if !ok || !secretKeyRe.MatchString(key) || !secretKeyRe.MatchString(name) {
+			return nil, fmt.Errorf("invalid %s entry %q, must be <key>=<Secret key> with valid Secret keys", SecretKeyMapping, strings.TrimSpace(pair))
+		}
+		if _, ok := mapping[key]; ok {
+			return nil, fmt.Errorf("%s maps key %s more than once", SecretKeyMapping, key)
+		}
+		if names[name] {
+			return nil, fmt.Errorf("%s maps Secret key %s more than once", SecretKeyMapping, name)
+		}
+		mapping[key] = name
+		names[name] = true
+	}
+	return mapping, nil
+}
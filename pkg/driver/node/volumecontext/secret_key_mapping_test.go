@@ -0,0 +1,67 @@
+package volumecontext_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestParseSecretKeyMapping(t *testing.T) {
+	testCases := []struct {
+		name      string
+		volumeCtx map[string]string
+		want      map[string]string
+		wantErr   string
+	}{
+		{
+			name:      "unset",
+			volumeCtx: map[string]string{"bucketName": "bucket"},
+		},
+		{
+			name:      "mapping",
+			volumeCtx: map[string]string{"secretKeyMapping": " access_key_id=accessKeyID , secret_access_key=secretAccessKey"},
+			want:      map[string]string{"access_key_id": "accessKeyID", "secret_access_key": "secretAccessKey"},
+		},
+		{
+			name:      "missing Secret key",
+			volumeCtx: map[string]string{"secretKeyMapping": "access_key_id"},
+			wantErr:   `invalid secretKeyMapping entry "access_key_id"`,
+		},
+		{
+			name:      "invalid Secret key",
+			volumeCtx: map[string]string{"secretKeyMapping": "access_key_id=access key"},
+			wantErr:   `invalid secretKeyMapping entry "access_key_id=access key"`,
+		},
+		{
+			name:      "key mapped twice",
+			volumeCtx: map[string]string{"secretKeyMapping": "access_key_id=accessKeyID,access_key_id=keyID"},
+			wantErr:   "secretKeyMapping maps key access_key_id more than once",
+		},
+		{
+			name:      "Secret key mapped twice",
+			volumeCtx: map[string]string{"secretKeyMapping": "access_key_id=key,secret_access_key=key"},
+			wantErr:   "secretKeyMapping maps Secret key key more than once",
+		},
+		{
+			name:      "too long",
+			volumeCtx: map[string]string{"secretKeyMapping": "access_key_id=" + strings.Repeat("a", 1024)},
+			wantErr:   "secretKeyMapping is too long",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mapping, err := volumecontext.ParseSecretKeyMapping(tc.volumeCtx)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("Expected an error containing %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equals(t, tc.want, mapping)
+		})
+	}
+}
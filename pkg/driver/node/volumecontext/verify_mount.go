@@ -0,0 +1,33 @@
+package volumecontext
+
+import "fmt"
+
+// VerifyMount makes the node plugin access a volume through its new mount before reporting it published, so volumes
+// mounted with a wrong region or credentials fail to mount instead of failing every I/O of their workload.
+const VerifyMount = "verifyMount"
+
+// Probes of [VerifyMount].
+const (
+	// VerifyMountList lists the root directory of the mount, i.e. a `ListObjectsV2` on the mounted prefix.
+	VerifyMountList = "list"
+	// VerifyMountHead looks up a name in the root directory of the mount, i.e. a `HeadObject` and a `ListObjectsV2`
+	// limited to one key, for credentials not allowed to list the whole prefix.
+	VerifyMountHead = "head"
+	// VerifyMountOff does not verify mounts, the default.
+	VerifyMountOff = "off"
+)
+
+// ParseVerifyMount returns the probe verifying the mount of `volumeCtx` with [VerifyMount], [VerifyMountOff] if it
+// is not set.
+func ParseVerifyMount(volumeCtx map[string]string) (string, error) {
+	probe, ok := volumeCtx[VerifyMount]
+	if !ok {
+		return VerifyMountOff, nil
+	}
+	switch probe {
+	case VerifyMountList, VerifyMountHead, VerifyMountOff:
+		return probe, nil
+	default:
+		return "", fmt.Errorf("invalid %s %q, must be %s, %s or %s", VerifyMount, probe, VerifyMountList, VerifyMountHead, VerifyMountOff)
+	}
+}
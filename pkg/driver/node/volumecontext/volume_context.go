@@ -4,8 +4,30 @@ package volumecontext
 const (
 	BucketName           = "bucketName"
 	AuthenticationSource = "authenticationSource"
+	// RoleARN is the role to assume with `authenticationSource: role` or `webIdentity`.
+	RoleARN = "roleArn"
+	// ServiceAccountTokenAudience is the audience of the service account token of the workload Pod used to assume
+	// the role with `authenticationSource: webIdentity`, it overrides the driver-level audience.
+	ServiceAccountTokenAudience = "serviceAccountTokenAudience"
+	// CredentialsName is the credentials to read from the credentials file directory of the node plugin with
+	// `authenticationSource: file`.
+	CredentialsName = "credentialsName"
+	// EndpointURL overrides the driver-level S3 endpoint, it must be allowed by the cluster administrator.
+	EndpointURL = "endpointUrl"
+	// EndpointURLs is a comma-separated ordered list of S3 endpoints of the volume, the first reachable one is used.
+	// They must be allowed by the cluster administrator.
+	EndpointURLs = "endpointUrls"
+	// CABundleSecretRef is the Secret, as `[namespace/]name`, holding the CA bundle Mountpoint trusts for the volume.
+	// The namespace defaults to the Pod's namespace.
+	CABundleSecretRef = "caBundleSecretRef"
 
 	MountpointPodServiceAccountName = "mountpointPodServiceAccountName"
+	// Options of Mountpoint Pods, JSON-encoded: tolerations (a list), extra labels and annotations (objects), and
+	// topology spread constraints (a list).
+	MountpointPodTolerations               = "mountpointPodTolerations"
+	MountpointPodLabels                    = "mountpointPodLabels"
+	MountpointPodAnnotations               = "mountpointPodAnnotations"
+	MountpointPodTopologySpreadConstraints = "mountpointPodTopologySpreadConstraints"
 
 	// Resource configuration for Mountpoint containers
 	MountpointContainerResourcesRequestsCpu    = "mountpointContainerResourcesRequestsCpu"
@@ -13,8 +35,32 @@ const (
 	MountpointContainerResourcesLimitsCpu      = "mountpointContainerResourcesLimitsCpu"
 	MountpointContainerResourcesLimitsMemory   = "mountpointContainerResourcesLimitsMemory"
 
+	// Cache is the type of the volume holding the Mountpoint cache of the volume: `emptyDir`, `memory` or `ephemeralPVC`.
+	// The Mountpoint cache is disabled if unset.
+	Cache = "cache"
+	// CacheSizeLimit is the size of the cache volume, it is required with `cache: ephemeralPVC`.
+	CacheSizeLimit = "cacheSizeLimit"
+
+	// PVCMetadataPrefix prefixes PVC labels/annotations propagated into the volume context during dynamic provisioning.
+	PVCMetadataPrefix = "pvcMetadata/"
+
 	CSIServiceAccountName   = "csi.storage.k8s.io/serviceAccount.name"
 	CSIServiceAccountTokens = "csi.storage.k8s.io/serviceAccount.tokens"
 	CSIPodNamespace         = "csi.storage.k8s.io/pod.namespace"
+	CSIPodName              = "csi.storage.k8s.io/pod.name"
 	CSIPodUID               = "csi.storage.k8s.io/pod.uid"
 )
+
+// MaxSize is the maximum total size in bytes of keys and values of a volume context accepted by the node plugin.
+// Volume attributes are propagated into Mountpoint Pods and their environment, larger contexts are most likely
+// a misconfiguration and would break these size limits later in the mount process.
+const MaxSize = 64 * 1024
+
+// Size returns the total size in bytes of keys and values of `volumeCtx`.
+func Size(volumeCtx map[string]string) int {
+	size := 0
+	for key, value := range volumeCtx {
+		size += len(key) + len(value)
+	}
+	return size
+}
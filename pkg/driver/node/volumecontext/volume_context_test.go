@@ -0,0 +1,21 @@
+package volumecontext_test
+
+import (
+	"testing"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestSize(t *testing.T) {
+	assert.Equals(t, 0, volumecontext.Size(nil))
+	assert.Equals(t, len("bucketName")+len("bucket")+len("authenticationSource")+len("driver"), volumecontext.Size(map[string]string{
+		volumecontext.BucketName:           "bucket",
+		volumecontext.AuthenticationSource: "driver",
+	}))
+}
+
+func TestEphemeralVolumeID(t *testing.T) {
+	// Generated the same way as kubelet
+	assert.Equals(t, "csi-92e46516884a4883ad0afc376551bf6070771b111d05a35f85671e53699885cc", volumecontext.EphemeralVolumeID("uid", "volume"))
+}
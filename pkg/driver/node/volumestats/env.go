@@ -0,0 +1,87 @@
+package volumestats
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"k8s.io/klog/v2"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/envprovider"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/utapi"
+)
+
+// Environment variables configuring volume statistics.
+const (
+	EnvEnabled       = "VOLUME_STATS_ENABLED"
+	EnvCacheTTL      = "VOLUME_STATS_CACHE_TTL"
+	EnvUTAPIEndpoint = "UTAPI_ENDPOINT_URL"
+)
+
+const defaultRegion = "us-east-1"
+
+// NewProviderFromEnv returns a new [Provider] configured from the driver's environment variables.
+// It returns nil if volume statistics are not enabled.
+//
+// Usage is computed with the driver-level credentials, as secrets used to publish volumes are not
+// available in `NodeGetVolumeStats` calls.
+func NewProviderFromEnv(ctx context.Context) (*Provider, error) {
+	if os.Getenv(EnvEnabled) != "true" {
+		return nil, nil
+	}
+
+	cacheTTL := DefaultCacheTTL
+	if value := os.Getenv(EnvCacheTTL); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", EnvCacheTTL, value, err)
+		}
+		cacheTTL = parsed
+	}
+
+	if os.Getenv(envprovider.EnvAccessKeyID) == "" || os.Getenv(envprovider.EnvSecretAccessKey) == "" {
+		return nil, fmt.Errorf("volume statistics require driver-level credentials via %s and %s", envprovider.EnvAccessKeyID, envprovider.EnvSecretAccessKey)
+	}
+	// Read credentials from the environment again every minute, as they might be rotated while the driver is running
+	creds := aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+		return aws.Credentials{
+			AccessKeyID:     os.Getenv(envprovider.EnvAccessKeyID),
+			SecretAccessKey: os.Getenv(envprovider.EnvSecretAccessKey),
+			SessionToken:    os.Getenv(envprovider.EnvSessionToken),
+			Source:          "DriverEnvironment",
+			CanExpire:       true,
+			Expires:         time.Now().Add(time.Minute),
+		}, nil
+	})
+
+	region := os.Getenv(envprovider.EnvRegion)
+	if region == "" {
+		region = defaultRegion
+	}
+
+	awsCfg, err := config.LoadDefaultConfig(ctx, config.WithCredentialsProvider(creds), config.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	var source Source = NewListObjectsSource(s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		o.UsePathStyle = true
+		o.BaseEndpoint = aws.String(os.Getenv(envprovider.EnvEndpointURL))
+	}))
+
+	if endpoint := os.Getenv(EnvUTAPIEndpoint); endpoint != "" {
+		client, err := utapi.New(utapi.Config{EndpointURL: endpoint, Region: region, Credentials: creds})
+		if err != nil {
+			return nil, err
+		}
+		source = NewUTAPISource(client, source)
+		klog.Infof("volumestats: Using UTAPI at %s for bucket-level usage", endpoint)
+	}
+
+	klog.Infof("volumestats: Volume statistics enabled with a cache TTL of %v", cacheTTL)
+	return NewProvider(source, cacheTTL), nil
+}
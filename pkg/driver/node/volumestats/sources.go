@@ -0,0 +1,80 @@
+package volumestats
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"k8s.io/klog/v2"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/utapi"
+)
+
+// A ListObjectsSource computes usage by listing all objects under the volume's prefix with ListObjectsV2.
+type ListObjectsSource struct {
+	client s3.ListObjectsV2APIClient
+}
+
+// NewListObjectsSource returns a new [ListObjectsSource] using `client`.
+func NewListObjectsSource(client s3.ListObjectsV2APIClient) *ListObjectsSource {
+	return &ListObjectsSource{client: client}
+}
+
+// Usage implements [Source].
+func (s *ListObjectsSource) Usage(ctx context.Context, volume Volume) (Usage, error) {
+	input := &s3.ListObjectsV2Input{Bucket: aws.String(volume.Bucket)}
+	if volume.Prefix != "" {
+		input.Prefix = aws.String(volume.Prefix)
+	}
+
+	var usage Usage
+	paginator := s3.NewListObjectsV2Paginator(s.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return Usage{}, err
+		}
+		for _, object := range page.Contents {
+			usage.Objects++
+			usage.UsedBytes += aws.ToInt64(object.Size)
+		}
+	}
+	return usage, nil
+}
+
+// bucketMetricsClient is the subset of [utapi.Client] used by [UTAPISource].
+type bucketMetricsClient interface {
+	ListBucketMetrics(ctx context.Context, bucket string, window time.Duration) (utapi.BucketMetrics, error)
+}
+
+// A UTAPISource computes usage from Scality UTAPI bucket metrics, which is much cheaper than listing large buckets.
+// UTAPI only reports metrics per bucket, so volumes restricted to a prefix, and failed UTAPI queries,
+// are served by `fallback`.
+type UTAPISource struct {
+	client   bucketMetricsClient
+	fallback Source
+}
+
+// NewUTAPISource returns a new [UTAPISource] using `client`, and `fallback` when UTAPI can't be used.
+func NewUTAPISource(client *utapi.Client, fallback Source) *UTAPISource {
+	return &UTAPISource{client: client, fallback: fallback}
+}
+
+// Usage implements [Source].
+func (s *UTAPISource) Usage(ctx context.Context, volume Volume) (Usage, error) {
+	if volume.Prefix != "" {
+		return s.fallback.Usage(ctx, volume)
+	}
+
+	metrics, err := s.client.ListBucketMetrics(ctx, volume.Bucket, time.Hour)
+	if err != nil {
+		klog.Warningf("volumestats: failed to query UTAPI for bucket %q, falling back to listing objects: %v", volume.Bucket, err)
+		return s.fallback.Usage(ctx, volume)
+	}
+
+	return Usage{
+		UsedBytes: metrics.CurrentStorageUtilized(),
+		Objects:   metrics.CurrentNumberOfObjects(),
+	}, nil
+}
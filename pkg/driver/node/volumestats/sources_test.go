@@ -0,0 +1,108 @@
+package volumestats
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/utapi"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+type mockListObjectsV2 struct {
+	pages  []*s3.ListObjectsV2Output
+	inputs []*s3.ListObjectsV2Input
+}
+
+func (m *mockListObjectsV2) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	m.inputs = append(m.inputs, params)
+	return m.pages[len(m.inputs)-1], nil
+}
+
+func TestListObjectsSource(t *testing.T) {
+	client := &mockListObjectsV2{pages: []*s3.ListObjectsV2Output{
+		{
+			Contents:              []types.Object{{Size: aws.Int64(100)}, {Size: aws.Int64(200)}},
+			IsTruncated:           aws.Bool(true),
+			NextContinuationToken: aws.String("token"),
+		},
+		{
+			Contents: []types.Object{{Size: aws.Int64(50)}},
+		},
+	}}
+
+	usage, err := NewListObjectsSource(client).Usage(context.Background(), Volume{Bucket: "bucket", Prefix: "data/"})
+	assert.NoError(t, err)
+	assert.Equals(t, Usage{UsedBytes: 350, Objects: 3}, usage)
+
+	assert.Equals(t, 2, len(client.inputs))
+	assert.Equals(t, "data/", aws.ToString(client.inputs[0].Prefix))
+	assert.Equals(t, "token", aws.ToString(client.inputs[1].ContinuationToken))
+}
+
+type mockBucketMetrics struct {
+	metrics utapi.BucketMetrics
+	err     error
+	calls   int
+}
+
+func (m *mockBucketMetrics) ListBucketMetrics(ctx context.Context, bucket string, window time.Duration) (utapi.BucketMetrics, error) {
+	m.calls++
+	return m.metrics, m.err
+}
+
+func TestUTAPISource(t *testing.T) {
+	fallbackUsage := Usage{UsedBytes: 1, Objects: 1}
+
+	tests := []struct {
+		name          string
+		volume        Volume
+		utapiErr      error
+		wantUsage     Usage
+		wantFallback  bool
+		wantUTAPICall bool
+	}{
+		{
+			name:          "bucket-level volume uses UTAPI",
+			volume:        Volume{Bucket: "bucket"},
+			wantUsage:     Usage{UsedBytes: 2048, Objects: 7},
+			wantUTAPICall: true,
+		},
+		{
+			name:         "prefixed volume falls back to listing",
+			volume:       Volume{Bucket: "bucket", Prefix: "data/"},
+			wantUsage:    fallbackUsage,
+			wantFallback: true,
+		},
+		{
+			name:          "UTAPI error falls back to listing",
+			volume:        Volume{Bucket: "bucket"},
+			utapiErr:      errors.New("connection refused"),
+			wantUsage:     fallbackUsage,
+			wantFallback:  true,
+			wantUTAPICall: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &mockBucketMetrics{
+				metrics: utapi.BucketMetrics{BucketName: "bucket", StorageUtilized: []int64{0, 2048}, NumberOfObjects: []int64{0, 7}},
+				err:     tt.utapiErr,
+			}
+			fallback := &countingSource{usage: fallbackUsage}
+			source := &UTAPISource{client: client, fallback: fallback}
+
+			usage, err := source.Usage(context.Background(), tt.volume)
+			assert.NoError(t, err)
+			assert.Equals(t, tt.wantUsage, usage)
+			assert.Equals(t, tt.wantFallback, fallback.calls == 1)
+			assert.Equals(t, tt.wantUTAPICall, client.calls == 1)
+		})
+	}
+}
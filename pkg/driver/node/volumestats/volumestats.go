@@ -0,0 +1,148 @@
+// Package volumestats provides usage statistics of S3 volumes for `NodeGetVolumeStats`.
+//
+// S3 has no notion of filesystem capacity, so only used bytes and object count (reported as inodes) are available.
+// Computing them requires listing the bucket (or querying Scality UTAPI), which is expensive for large buckets,
+// so results are cached per volume for a configurable TTL.
+package volumestats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// ErrVolumeNotFound is returned when there is no known volume published at the requested path.
+var ErrVolumeNotFound = errors.New("volume not found")
+
+// DefaultCacheTTL is the default duration usage statistics are cached for.
+const DefaultCacheTTL = 5 * time.Minute
+
+// Usage represents usage statistics of a volume.
+type Usage struct {
+	UsedBytes int64
+	Objects   int64
+}
+
+// A Volume identifies the S3 location backing a published volume.
+type Volume struct {
+	Bucket string
+	Prefix string
+}
+
+// A Source computes usage statistics of an S3 location.
+type Source interface {
+	Usage(ctx context.Context, volume Volume) (Usage, error)
+}
+
+type cacheEntry struct {
+	usage     Usage
+	fetchedAt time.Time
+}
+
+// A Provider tracks published volumes and serves their cached usage statistics.
+type Provider struct {
+	source   Source
+	cacheTTL time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	volumes map[string]Volume     // target path -> volume
+	cache   map[Volume]cacheEntry // shared between targets of the same volume
+	// fetchMu serializes fetches so concurrent stats calls for a volume don't list the bucket several times
+	fetchMu sync.Mutex
+}
+
+// NewProvider returns a new provider computing usage with `source` and caching it for `cacheTTL`.
+func NewProvider(source Source, cacheTTL time.Duration) *Provider {
+	if cacheTTL <= 0 {
+		cacheTTL = DefaultCacheTTL
+	}
+	return &Provider{
+		source:   source,
+		cacheTTL: cacheTTL,
+		now:      time.Now,
+		volumes:  make(map[string]Volume),
+		cache:    make(map[Volume]cacheEntry),
+	}
+}
+
+// Register records `volume` as published at `target`.
+func (p *Provider) Register(target string, volume Volume) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.volumes[target] = volume
+}
+
+// Unregister forgets the volume published at `target`, and its cached usage if no other target uses it.
+func (p *Provider) Unregister(target string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	volume, ok := p.volumes[target]
+	if !ok {
+		return
+	}
+	delete(p.volumes, target)
+
+	for _, v := range p.volumes {
+		if v == volume {
+			return
+		}
+	}
+	delete(p.cache, volume)
+}
+
+// Usage returns usage statistics of the volume published at `target`.
+// It returns [ErrVolumeNotFound] if no volume is registered at `target`.
+func (p *Provider) Usage(ctx context.Context, target string) (Usage, error) {
+	volume, ok := p.lookup(target)
+	if !ok {
+		return Usage{}, ErrVolumeNotFound
+	}
+
+	if usage, ok := p.cached(volume); ok {
+		return usage, nil
+	}
+
+	p.fetchMu.Lock()
+	defer p.fetchMu.Unlock()
+
+	// Another call might have populated the cache while we were waiting
+	if usage, ok := p.cached(volume); ok {
+		return usage, nil
+	}
+
+	start := p.now()
+	usage, err := p.source.Usage(ctx, volume)
+	if err != nil {
+		return Usage{}, fmt.Errorf("failed to compute usage of bucket %q with prefix %q: %w", volume.Bucket, volume.Prefix, err)
+	}
+	klog.V(5).Infof("volumestats: computed usage of bucket %q with prefix %q in %v: %+v", volume.Bucket, volume.Prefix, p.now().Sub(start), usage)
+
+	p.mu.Lock()
+	p.cache[volume] = cacheEntry{usage: usage, fetchedAt: p.now()}
+	p.mu.Unlock()
+
+	return usage, nil
+}
+
+func (p *Provider) lookup(target string) (Volume, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	volume, ok := p.volumes[target]
+	return volume, ok
+}
+
+func (p *Provider) cached(volume Volume) (Usage, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	entry, ok := p.cache[volume]
+	if !ok || p.now().Sub(entry.fetchedAt) >= p.cacheTTL {
+		return Usage{}, false
+	}
+	return entry.usage, true
+}
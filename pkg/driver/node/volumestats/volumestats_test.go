@@ -0,0 +1,96 @@
+package volumestats
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+type countingSource struct {
+	calls int
+	usage Usage
+	err   error
+}
+
+func (s *countingSource) Usage(ctx context.Context, volume Volume) (Usage, error) {
+	s.calls++
+	return s.usage, s.err
+}
+
+func TestProviderUsage(t *testing.T) {
+	ctx := context.Background()
+	volume := Volume{Bucket: "bucket", Prefix: "prefix/"}
+
+	t.Run("unknown target", func(t *testing.T) {
+		provider := NewProvider(&countingSource{}, time.Minute)
+		_, err := provider.Usage(ctx, "/unknown")
+		if !errors.Is(err, ErrVolumeNotFound) {
+			t.Fatalf("Expected ErrVolumeNotFound, got %v", err)
+		}
+	})
+
+	t.Run("caches usage for TTL", func(t *testing.T) {
+		source := &countingSource{usage: Usage{UsedBytes: 10, Objects: 2}}
+		provider := NewProvider(source, time.Minute)
+		now := time.Now()
+		provider.now = func() time.Time { return now }
+
+		provider.Register("/target-1", volume)
+		provider.Register("/target-2", volume)
+
+		usage, err := provider.Usage(ctx, "/target-1")
+		assert.NoError(t, err)
+		assert.Equals(t, Usage{UsedBytes: 10, Objects: 2}, usage)
+
+		_, err = provider.Usage(ctx, "/target-2")
+		assert.NoError(t, err)
+		assert.Equals(t, 1, source.calls)
+
+		now = now.Add(time.Minute)
+		_, err = provider.Usage(ctx, "/target-1")
+		assert.NoError(t, err)
+		assert.Equals(t, 2, source.calls)
+	})
+
+	t.Run("does not cache errors", func(t *testing.T) {
+		source := &countingSource{err: errors.New("access denied")}
+		provider := NewProvider(source, time.Minute)
+		provider.Register("/target", volume)
+
+		for range 2 {
+			if _, err := provider.Usage(ctx, "/target"); err == nil {
+				t.Fatal("Expected an error")
+			}
+		}
+		assert.Equals(t, 2, source.calls)
+	})
+}
+
+func TestProviderUnregister(t *testing.T) {
+	ctx := context.Background()
+	volume := Volume{Bucket: "bucket"}
+	source := &countingSource{}
+	provider := NewProvider(source, time.Minute)
+
+	provider.Register("/target-1", volume)
+	provider.Register("/target-2", volume)
+	_, err := provider.Usage(ctx, "/target-1")
+	assert.NoError(t, err)
+
+	provider.Unregister("/target-1")
+	if _, err := provider.Usage(ctx, "/target-1"); !errors.Is(err, ErrVolumeNotFound) {
+		t.Fatalf("Expected ErrVolumeNotFound after unregister, got %v", err)
+	}
+
+	// Cache is kept while another target uses the volume
+	_, err = provider.Usage(ctx, "/target-2")
+	assert.NoError(t, err)
+	assert.Equals(t, 1, source.calls)
+
+	provider.Unregister("/target-2")
+	provider.Unregister("/target-2")
+	assert.Equals(t, 0, len(provider.cache))
+}
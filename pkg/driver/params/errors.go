@@ -0,0 +1,47 @@
+package params
+
+import "fmt"
+
+// An UnknownKeyError reports a key that is not in the schema, it is ignored.
+type UnknownKeyError struct {
+	Key string
+	// Suggestion is the known key closest to Key, empty if none is close enough to be a misspelling of it.
+	Suggestion string
+}
+
+func (e *UnknownKeyError) Error() string {
+	if e.Suggestion != "" {
+		return fmt.Sprintf("unknown parameter %q is ignored, did you mean %q?", e.Key, e.Suggestion)
+	}
+	return fmt.Sprintf("unknown parameter %q is ignored", e.Key)
+}
+
+// An InvalidValueError reports a parameter whose value does not match its type.
+type InvalidValueError struct {
+	Key   string
+	Value string
+	Type  Type
+	// Expected describes the values accepted by the parameter, if its type does not.
+	Expected string
+	// Err is the parsing error of Value, if any.
+	Err error
+}
+
+func (e *InvalidValueError) Error() string {
+	kind := string(e.Type)
+	if e.Type == Enum || e.Type == String {
+		kind = "value"
+	}
+	msg := fmt.Sprintf("invalid %s %q for parameter %q", kind, e.Value, e.Key)
+	if e.Expected != "" {
+		msg += ", must be " + e.Expected
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *InvalidValueError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,223 @@
+// Package params parses and validates string-typed parameters, such as StorageClass parameters and volume
+// attributes, against a schema of their keys and value types.
+//
+// Unknown keys are reported with the closest known key rather than silently ignored, so misspelled parameters can be
+// surfaced to users, e.g. in events. Errors are typed, so callers can tell unknown keys, usually warnings, from
+// invalid values, usually failures.
+package params
+
+import (
+	"encoding/json"
+	"errors"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// A Type is the type of the value of a parameter.
+type Type string
+
+const (
+	// String accepts any value.
+	String Type = "string"
+	// Bool accepts a boolean, e.g. `true` or `false`.
+	Bool Type = "boolean"
+	// Int accepts a base 10 integer.
+	Int Type = "integer"
+	// Quantity accepts a Kubernetes quantity, e.g. `512Mi`.
+	Quantity Type = "quantity"
+	// Enum accepts one of the values of the parameter.
+	Enum Type = "enum"
+	// JSONObject accepts a JSON object.
+	JSONObject Type = "JSON object"
+	// JSONList accepts a JSON list.
+	JSONList Type = "JSON list"
+)
+
+// A Param describes a parameter of a [Schema].
+type Param struct {
+	Key  string
+	Type Type
+	// Values are the accepted values of an [Enum] parameter.
+	Values []string
+	// Default is the value of the parameter when it is not set, empty if it has no default.
+	Default string
+}
+
+// A Schema describes the known parameters of a map of parameters.
+type Schema struct {
+	params map[string]Param
+	// reservedPrefixes are prefixes of keys set by other components, e.g. `csi.storage.k8s.io/`, which are known
+	// without being validated.
+	reservedPrefixes []string
+}
+
+// NewSchema returns a schema of `params`, where keys starting with one of `reservedPrefixes` are also known.
+func NewSchema(params []Param, reservedPrefixes ...string) *Schema {
+	s := &Schema{params: make(map[string]Param, len(params)), reservedPrefixes: reservedPrefixes}
+	for _, p := range params {
+		if p.Type == "" {
+			p.Type = String
+		}
+		s.params[p.Key] = p
+	}
+	return s
+}
+
+// Keys returns the keys of the parameters of the schema, sorted.
+func (s *Schema) Keys() []string {
+	return slices.Sorted(maps.Keys(s.params))
+}
+
+// Param returns the parameter of `key`, false if it is not in the schema.
+func (s *Schema) Param(key string) (Param, bool) {
+	p, ok := s.params[key]
+	return p, ok
+}
+
+// A Result is the outcome of [Schema.Parse].
+type Result struct {
+	// Values are the known parameters, trimmed, with the defaults of unset parameters.
+	Values map[string]string
+	// Unknown are the errors of keys not in the schema, sorted by key.
+	Unknown []*UnknownKeyError
+	// Invalid are the errors of parameters with an invalid value, sorted by key.
+	Invalid []*InvalidValueError
+}
+
+// Err returns the errors of invalid values joined, nil if all values are valid.
+func (r *Result) Err() error {
+	errs := make([]error, len(r.Invalid))
+	for i, err := range r.Invalid {
+		errs[i] = err
+	}
+	return errors.Join(errs...)
+}
+
+// Warnings returns the messages of unknown keys, nil if all keys are known.
+func (r *Result) Warnings() []string {
+	var warnings []string
+	for _, err := range r.Unknown {
+		warnings = append(warnings, err.Error())
+	}
+	return warnings
+}
+
+// invalid returns whether the value of `key` is invalid.
+func (r *Result) invalid(key string) bool {
+	return slices.ContainsFunc(r.Invalid, func(err *InvalidValueError) bool { return err.Key == key })
+}
+
+// Parse validates `values` against the schema. Values of unknown keys are not returned in [Result.Values], keys
+// with a reserved prefix are returned as is.
+func (s *Schema) Parse(values map[string]string) *Result {
+	result := &Result{Values: make(map[string]string, len(values))}
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		p, ok := s.params[key]
+		if !ok {
+			if s.reserved(key) {
+				result.Values[key] = values[key]
+			} else {
+				result.Unknown = append(result.Unknown, &UnknownKeyError{Key: key, Suggestion: s.suggest(key)})
+			}
+			continue
+		}
+		value := strings.TrimSpace(values[key])
+		if value == "" {
+			continue
+		}
+		if err := p.validate(value); err != nil {
+			result.Invalid = append(result.Invalid, err)
+			continue
+		}
+		result.Values[key] = value
+	}
+	for key, p := range s.params {
+		if _, ok := result.Values[key]; !ok && p.Default != "" && !result.invalid(key) {
+			result.Values[key] = p.Default
+		}
+	}
+	return result
+}
+
+// reserved returns whether `key` starts with a reserved prefix.
+func (s *Schema) reserved(key string) bool {
+	return slices.ContainsFunc(s.reservedPrefixes, func(prefix string) bool { return strings.HasPrefix(key, prefix) })
+}
+
+// maxSuggestionDistance is the maximum edit distance between an unknown key and the known key suggested for it.
+const maxSuggestionDistance = 3
+
+// suggest returns the known key closest to `key`, empty if none is close enough to be a misspelling of it.
+func (s *Schema) suggest(key string) string {
+	suggestion, best := "", maxSuggestionDistance+1
+	for _, known := range s.Keys() {
+		if strings.EqualFold(known, key) {
+			return known
+		}
+		if d := distance(strings.ToLower(key), strings.ToLower(known)); d < best {
+			suggestion, best = known, d
+		}
+	}
+	return suggestion
+}
+
+// validate returns an error if `value` is not a valid value of `p`.
+func (p Param) validate(value string) *InvalidValueError {
+	invalid := func(expected string, err error) *InvalidValueError {
+		return &InvalidValueError{Key: p.Key, Value: value, Type: p.Type, Expected: expected, Err: err}
+	}
+	switch p.Type {
+	case Bool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return invalid("true or false", nil)
+		}
+	case Int:
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return invalid("", nil)
+		}
+	case Quantity:
+		if _, err := resource.ParseQuantity(value); err != nil {
+			return invalid("", err)
+		}
+	case Enum:
+		if !slices.Contains(p.Values, value) {
+			return invalid("one of "+strings.Join(p.Values, ", "), nil)
+		}
+	case JSONObject:
+		var object map[string]any
+		if err := json.Unmarshal([]byte(value), &object); err != nil {
+			return invalid("", err)
+		}
+	case JSONList:
+		var list []any
+		if err := json.Unmarshal([]byte(value), &list); err != nil {
+			return invalid("", err)
+		}
+	}
+	return nil
+}
+
+// distance returns the Levenshtein distance between `a` and `b`.
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
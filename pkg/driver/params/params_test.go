@@ -0,0 +1,118 @@
+package params_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/params"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+var testSchema = params.NewSchema([]params.Param{
+	{Key: "name"},
+	{Key: "enabled", Type: params.Bool},
+	{Key: "days", Type: params.Int},
+	{Key: "size", Type: params.Quantity},
+	{Key: "mode", Type: params.Enum, Values: []string{"fast", "safe"}, Default: "safe"},
+	{Key: "labels", Type: params.JSONObject},
+	{Key: "tolerations", Type: params.JSONList},
+}, "csi.storage.k8s.io/")
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		values      map[string]string
+		wantValues  map[string]string
+		wantUnknown []params.UnknownKeyError
+		wantInvalid []string
+	}{
+		{
+			name:       "defaults of unset parameters",
+			values:     nil,
+			wantValues: map[string]string{"mode": "safe"},
+		},
+		{
+			name: "valid values are trimmed",
+			values: map[string]string{
+				"name":        " data ",
+				"enabled":     "true",
+				"days":        "30",
+				"size":        "2Gi",
+				"mode":        "fast",
+				"labels":      `{"team": "a"}`,
+				"tolerations": `[{"operator": "Exists"}]`,
+			},
+			wantValues: map[string]string{
+				"name":        "data",
+				"enabled":     "true",
+				"days":        "30",
+				"size":        "2Gi",
+				"mode":        "fast",
+				"labels":      `{"team": "a"}`,
+				"tolerations": `[{"operator": "Exists"}]`,
+			},
+		},
+		{
+			name:       "reserved keys are kept",
+			values:     map[string]string{"csi.storage.k8s.io/pvc/name": "claim"},
+			wantValues: map[string]string{"csi.storage.k8s.io/pvc/name": "claim", "mode": "safe"},
+		},
+		{
+			name:       "unknown keys are reported with the closest known key",
+			values:     map[string]string{"Enabled": "true", "sise": "1Gi", "retention": "30"},
+			wantValues: map[string]string{"mode": "safe"},
+			wantUnknown: []params.UnknownKeyError{
+				{Key: "Enabled", Suggestion: "enabled"},
+				{Key: "retention"},
+				{Key: "sise", Suggestion: "size"},
+			},
+		},
+		{
+			name: "invalid values are reported without defaults",
+			values: map[string]string{
+				"enabled":     "yes",
+				"days":        "thirty",
+				"size":        "two",
+				"mode":        "slow",
+				"labels":      `["a"]`,
+				"tolerations": `{}`,
+			},
+			wantValues:  map[string]string{},
+			wantInvalid: []string{"days", "enabled", "labels", "mode", "size", "tolerations"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := testSchema.Parse(tt.values)
+			assert.Equals(t, tt.wantValues, result.Values)
+			var unknown []params.UnknownKeyError
+			for _, err := range result.Unknown {
+				unknown = append(unknown, *err)
+			}
+			assert.Equals(t, tt.wantUnknown, unknown)
+
+			var invalid []string
+			for _, err := range result.Invalid {
+				invalid = append(invalid, err.Key)
+			}
+			assert.Equals(t, tt.wantInvalid, invalid)
+			assert.Equals(t, len(tt.wantInvalid) > 0, result.Err() != nil)
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	result := testSchema.Parse(map[string]string{"mode": "slow", "labels": "{", "nmae": "data"})
+
+	assert.Equals(t, []string{`unknown parameter "nmae" is ignored, did you mean "name"?`}, result.Warnings())
+
+	var invalid *params.InvalidValueError
+	if !errors.As(result.Err(), &invalid) {
+		t.Fatalf("Expected an InvalidValueError, got %v", result.Err())
+	}
+	assert.Equals(t, `invalid value "slow" for parameter "mode", must be one of fast, safe`, result.Invalid[1].Error())
+	if result.Invalid[0].Unwrap() == nil {
+		t.Fatalf("Expected the JSON error of %q to be wrapped", result.Invalid[0].Key)
+	}
+}
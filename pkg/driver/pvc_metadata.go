@@ -0,0 +1,101 @@
+package driver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
+)
+
+// envPVCMetadataPropagationKeys is a comma separated allow-list of PVC label/annotation keys
+// to copy onto dynamically provisioned volumes and their buckets.
+const envPVCMetadataPropagationKeys = "PVC_METADATA_PROPAGATION_KEYS"
+
+// Limits of S3 bucket tagging, see https://docs.aws.amazon.com/AmazonS3/latest/userguide/CostAllocTagging.html.
+const (
+	maxBucketTags           = 50
+	maxBucketTagKeyLength   = 128
+	maxBucketTagValueLength = 256
+)
+
+// pvcMetadataPropagationKeysFromEnv returns the configured allow-list of PVC label/annotation keys.
+// It returns nil if propagation is not configured.
+func pvcMetadataPropagationKeysFromEnv() []string {
+	var keys []string
+	for key := range strings.SplitSeq(os.Getenv(envPVCMetadataPropagationKeys), ",") {
+		key = strings.TrimSpace(key)
+		if key != "" && !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// propagatedPVCMetadata returns allow-listed labels and annotations of the PVC being provisioned.
+//
+// The PVC is identified with the parameters the external-provisioner adds when running with
+// `--extra-create-metadata`. Labels take precedence over annotations if both contain the same key.
+// It returns nil if propagation is not configured.
+func (d *Driver) propagatedPVCMetadata(ctx context.Context, parameters map[string]string) (map[string]string, error) {
+	if len(d.pvcMetadataPropagationKeys) == 0 {
+		return nil, nil
+	}
+
+	pvcName := parameters[constants.PVCNameKey]
+	pvcNamespace := parameters[constants.PVCNamespaceKey]
+	if pvcName == "" || pvcNamespace == "" {
+		return nil, fmt.Errorf("PVC name and namespace are not provided, ensure the provisioner runs with --extra-create-metadata")
+	}
+
+	if d.Clientset == nil {
+		return nil, fmt.Errorf("kubernetes client is not configured")
+	}
+
+	pvc, err := d.Clientset.CoreV1().PersistentVolumeClaims(pvcNamespace).Get(ctx, pvcName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PVC %s/%s: %w", pvcNamespace, pvcName, err)
+	}
+
+	metadata := make(map[string]string)
+	for _, key := range d.pvcMetadataPropagationKeys {
+		if value, ok := pvc.Labels[key]; ok {
+			metadata[key] = value
+		} else if value, ok := pvc.Annotations[key]; ok {
+			metadata[key] = value
+		}
+	}
+
+	return metadata, nil
+}
+
+// bucketTagsFromPVCMetadata converts propagated PVC metadata into S3 bucket tags.
+// Entries exceeding S3 tagging limits are skipped with a warning.
+func bucketTagsFromPVCMetadata(metadata map[string]string) map[string]string {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	tags := make(map[string]string)
+	for _, key := range keys {
+		value := metadata[key]
+		if len(key) > maxBucketTagKeyLength || len(value) > maxBucketTagValueLength {
+			klog.Warningf("PVC metadata %q not propagated to bucket tags: key or value exceeds S3 tagging limits", key)
+			continue
+		}
+		if len(tags) == maxBucketTags {
+			klog.Warningf("PVC metadata %q not propagated to bucket tags: maximum of %d tags reached", key, maxBucketTags)
+			continue
+		}
+		tags[key] = value
+	}
+
+	return tags
+}
@@ -0,0 +1,176 @@
+package driver
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
+	controllerCredProvider "github.com/scality/mountpoint-s3-csi-driver/pkg/driver/controller/credentialprovider"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/s3client"
+)
+
+func TestPVCMetadataPropagationKeysFromEnv(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected []string
+	}{
+		{name: "unset", value: "", expected: nil},
+		{name: "single key", value: "project", expected: []string{"project"}},
+		{name: "trims and deduplicates", value: " project, data-classification ,project,,", expected: []string{"project", "data-classification"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(envPVCMetadataPropagationKeys, tc.value)
+			got := pvcMetadataPropagationKeysFromEnv()
+			if strings.Join(got, ",") != strings.Join(tc.expected, ",") {
+				t.Fatalf("Expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestBucketTagsFromPVCMetadata(t *testing.T) {
+	metadata := map[string]string{
+		"project":                   "apollo",
+		strings.Repeat("k", 129):    "too-long-key",
+		"long-value":                strings.Repeat("v", 257),
+		"app.kubernetes.io/part-of": "billing",
+	}
+
+	tags := bucketTagsFromPVCMetadata(metadata)
+	if len(tags) != 2 {
+		t.Fatalf("Expected 2 tags, got %d: %v", len(tags), tags)
+	}
+	if tags["project"] != "apollo" || tags["app.kubernetes.io/part-of"] != "billing" {
+		t.Fatalf("Unexpected tags: %v", tags)
+	}
+
+	many := make(map[string]string)
+	for i := range maxBucketTags + 5 {
+		many[strings.Repeat("k", i+1)] = "v"
+	}
+	if got := len(bucketTagsFromPVCMetadata(many)); got != maxBucketTags {
+		t.Fatalf("Expected tags to be capped at %d, got %d", maxBucketTags, got)
+	}
+}
+
+func TestCreateVolumePropagatesPVCMetadata(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "test-access-key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test-secret-key")
+	t.Setenv("AWS_REGION", "us-east-1")
+
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "data",
+			Namespace: "team-a",
+			Labels: map[string]string{
+				"project": "apollo",
+				"ignored": "not-allow-listed",
+			},
+			Annotations: map[string]string{
+				"project":             "annotation-value-loses",
+				"data-classification": "confidential",
+			},
+		},
+	}
+	fakeClient := fake.NewSimpleClientset(pvc)
+
+	var taggedBucket string
+	var tags map[string]string
+	mockS3 := &mockS3Client{
+		putTaggingFunc: func(ctx context.Context, bucket string, t map[string]string) error {
+			taggedBucket = bucket
+			tags = t
+			return nil
+		},
+	}
+
+	driver := &Driver{
+		Clientset:                  fakeClient,
+		controllerCredProvider:     controllerCredProvider.New(fakeClient),
+		pvcMetadataPropagationKeys: []string{"project", "data-classification", "missing"},
+		testS3ClientFactory: func(ctx context.Context, awsConfig *aws.Config) (s3client.Client, error) {
+			return mockS3, nil
+		},
+	}
+
+	resp, err := driver.CreateVolume(context.Background(), &csi.CreateVolumeRequest{
+		Name: "pvc-1234",
+		Parameters: map[string]string{
+			constants.PVCNameKey:      "data",
+			constants.PVCNamespaceKey: "team-a",
+		},
+		VolumeCapabilities: []*csi.VolumeCapability{{
+			AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	volumeCtx := resp.Volume.VolumeContext
+	if volumeCtx[volumecontext.PVCMetadataPrefix+"project"] != "apollo" {
+		t.Errorf("Expected project label to be propagated, got volume context %v", volumeCtx)
+	}
+	if volumeCtx[volumecontext.PVCMetadataPrefix+"data-classification"] != "confidential" {
+		t.Errorf("Expected data-classification annotation to be propagated, got volume context %v", volumeCtx)
+	}
+	if _, ok := volumeCtx[volumecontext.PVCMetadataPrefix+"ignored"]; ok {
+		t.Errorf("Expected non allow-listed label not to be propagated")
+	}
+
+	if taggedBucket != resp.Volume.VolumeId {
+		t.Errorf("Expected bucket %q to be tagged, got %q", resp.Volume.VolumeId, taggedBucket)
+	}
+	if len(tags) != 2 || tags["project"] != "apollo" || tags["data-classification"] != "confidential" {
+		t.Errorf("Unexpected bucket tags: %v", tags)
+	}
+}
+
+func TestCreateVolumeWithoutPVCMetadataParameters(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "test-access-key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test-secret-key")
+	t.Setenv("AWS_REGION", "us-east-1")
+
+	fakeClient := fake.NewSimpleClientset()
+	tagged := false
+	mockS3 := &mockS3Client{
+		putTaggingFunc: func(ctx context.Context, bucket string, tags map[string]string) error {
+			tagged = true
+			return nil
+		},
+	}
+
+	driver := &Driver{
+		Clientset:                  fakeClient,
+		controllerCredProvider:     controllerCredProvider.New(fakeClient),
+		pvcMetadataPropagationKeys: []string{"project"},
+		testS3ClientFactory: func(ctx context.Context, awsConfig *aws.Config) (s3client.Client, error) {
+			return mockS3, nil
+		},
+	}
+
+	// Propagation is best-effort, provisioning must succeed without --extra-create-metadata
+	_, err := driver.CreateVolume(context.Background(), &csi.CreateVolumeRequest{
+		Name: "pvc-1234",
+		VolumeCapabilities: []*csi.VolumeCapability{{
+			AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if tagged {
+		t.Fatal("Expected bucket not to be tagged")
+	}
+}
@@ -35,3 +35,12 @@ func ParseEndpoint(endpoint string) (string, string, error) {
 
 	return scheme, addr, nil
 }
+
+// unixSocketPath returns the path of the unix domain socket of `endpoint`, empty if it is not a unix endpoint.
+func unixSocketPath(endpoint string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil || strings.ToLower(u.Scheme) != "unix" {
+		return ""
+	}
+	return path.Join("/", u.Host, filepath.FromSlash(u.Path))
+}
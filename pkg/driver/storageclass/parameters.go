@@ -2,14 +2,91 @@ package storageclass
 
 import (
 	"fmt"
-	"maps"
+	"strconv"
 	"strings"
 
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/klog/v2"
 
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/params"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/s3client"
 )
 
+// StorageClass parameters configuring the data protection of provisioned buckets.
+const (
+	// VersioningParam enables versioning of provisioned buckets: `enabled` or `disabled` (default).
+	VersioningParam = "versioning"
+	// ObjectLockParam enables object lock on provisioned buckets with a default retention mode: `governance` or
+	// `compliance`. It requires [RetentionDaysParam].
+	ObjectLockParam = "objectLock"
+	// RetentionDaysParam is the default retention in days of objects written to buckets with object lock.
+	RetentionDaysParam = "retentionDays"
+)
+
+// maxRetentionDays is the maximum default retention of object lock, 100 years.
+const maxRetentionDays = 36500
+
+// mountpointContainerResourcesParams are StorageClass parameters configuring resources of Mountpoint Pods,
+// propagated as-is into the volume context of provisioned volumes.
+var mountpointContainerResourcesParams = []string{
+	volumecontext.MountpointContainerResourcesRequestsCpu,
+	volumecontext.MountpointContainerResourcesRequestsMemory,
+	volumecontext.MountpointContainerResourcesLimitsCpu,
+	volumecontext.MountpointContainerResourcesLimitsMemory,
+}
+
+// mountpointCacheParams are StorageClass parameters configuring the Mountpoint cache volume of Mountpoint Pods,
+// propagated as-is into the volume context of provisioned volumes.
+var mountpointCacheParams = []string{
+	volumecontext.Cache,
+	volumecontext.CacheSizeLimit,
+}
+
+// mountpointPodParams are StorageClass parameters configuring the tolerations, labels, annotations and topology spread
+// constraints of Mountpoint Pods, propagated as-is into the volume context of provisioned volumes.
+var mountpointPodParams = []string{
+	volumecontext.MountpointPodTolerations,
+	volumecontext.MountpointPodLabels,
+	volumecontext.MountpointPodAnnotations,
+	volumecontext.MountpointPodTopologySpreadConstraints,
+}
+
+// encryptionParams are StorageClass parameters configuring the server-side encryption of provisioned volumes,
+// propagated as-is into their volume context.
+var encryptionParams = []string{
+	volumecontext.ServerSideEncryption,
+	volumecontext.SSEKMSKeyID,
+}
+
+// Schema describes the StorageClass parameters supported by the CSI driver. Parameters of the external-provisioner,
+// prefixed with `csi.storage.k8s.io/`, are known.
+var Schema = params.NewSchema([]params.Param{
+	{Key: constants.ProvisionerSecretNameKey},
+	{Key: constants.ProvisionerSecretNamespaceKey},
+	{Key: constants.NodePublishSecretNameKey},
+	{Key: constants.NodePublishSecretNamespaceKey},
+	{Key: volumecontext.MountpointContainerResourcesRequestsCpu, Type: params.Quantity},
+	{Key: volumecontext.MountpointContainerResourcesRequestsMemory, Type: params.Quantity},
+	{Key: volumecontext.MountpointContainerResourcesLimitsCpu, Type: params.Quantity},
+	{Key: volumecontext.MountpointContainerResourcesLimitsMemory, Type: params.Quantity},
+	{Key: volumecontext.Cache, Type: params.Enum, Values: []string{mppod.CacheEmptyDir, mppod.CacheMemory, mppod.CacheEphemeralPVC}},
+	{Key: volumecontext.CacheSizeLimit, Type: params.Quantity},
+	{Key: volumecontext.MountpointPodTolerations, Type: params.JSONList},
+	{Key: volumecontext.MountpointPodLabels, Type: params.JSONObject},
+	{Key: volumecontext.MountpointPodAnnotations, Type: params.JSONObject},
+	{Key: volumecontext.MountpointPodTopologySpreadConstraints, Type: params.JSONList},
+	{Key: volumecontext.ServerSideEncryption},
+	{Key: volumecontext.SSEKMSKeyID},
+	{Key: VersioningParam, Type: params.Enum, Values: []string{"enabled", "disabled"}},
+	{Key: ObjectLockParam, Type: params.Enum, Values: []string{"governance", "compliance"}},
+	{Key: RetentionDaysParam, Type: params.Int},
+}, "csi.storage.k8s.io/")
+
 // Parameters represents parsed and validated StorageClass parameters for dynamic provisioning
 type Parameters struct {
 	// Provisioner secret configuration (used by CSI Controller for bucket operations)
@@ -22,6 +99,25 @@ type Parameters struct {
 
 	// Authentication tier automatically determined from parameter content
 	AuthTier AuthenticationTier
+
+	// Mountpoint Pod resources, keyed by volume attribute (e.g. `mountpointContainerResourcesRequestsMemory`)
+	MountpointContainerResources map[string]string
+
+	// Mountpoint cache volume, keyed by volume attribute (`cache` and `cacheSizeLimit`)
+	MountpointCache map[string]string
+
+	// Mountpoint Pod tolerations, labels, annotations and topology spread constraints, keyed by volume attribute
+	// (e.g. `mountpointPodTolerations`)
+	MountpointPodOptions map[string]string
+
+	// Server-side encryption, keyed by volume attribute (`serverSideEncryption` and `sseKmsKeyId`)
+	Encryption map[string]string
+
+	// Versioning and object lock of provisioned buckets
+	Bucket s3client.BucketOptions
+
+	// Warnings about ignored parameters, e.g. misspelled ones
+	Warnings []string
 }
 
 // AuthenticationTier represents the credential resolution strategy
@@ -40,12 +136,15 @@ func ParseAndValidate(parameters map[string]string) (*Parameters, error) {
 		return &Parameters{AuthTier: DriverCredentials}, nil
 	}
 
-	// Make a copy to avoid modifying the original
-	params := make(map[string]string)
-	maps.Copy(params, parameters)
-
-	// Apply CSI driver parameter policy - strip unsupported parameters
-	enforceCSIDriverParameterPolicy(params)
+	// Validate parameters against the schema, unknown parameters are ignored with a warning
+	parsed := Schema.Parse(parameters)
+	if err := parsed.Err(); err != nil {
+		return nil, err
+	}
+	params := parsed.Values
+	for _, warning := range parsed.Warnings() {
+		klog.V(4).Infof("StorageClass parameters: %s", warning)
+	}
 
 	// Parse and validate CSI secret parameters
 	provisionerSecretName := strings.TrimSpace(params[constants.ProvisionerSecretNameKey])
@@ -64,34 +163,169 @@ func ParseAndValidate(parameters map[string]string) (*Parameters, error) {
 	// Determine authentication tier based on parameter presence
 	authTier := determineAuthenticationTier(provisionerSecretName, provisionerSecretNamespace, nodePublishSecretName, nodePublishSecretNamespace)
 
+	mountpointContainerResources, err := parseMountpointContainerResources(params)
+	if err != nil {
+		return nil, err
+	}
+
+	mountpointCache, err := parseMountpointCache(params)
+	if err != nil {
+		return nil, err
+	}
+
+	mountpointPodOptions, err := parseMountpointPodOptions(params)
+	if err != nil {
+		return nil, err
+	}
+
+	encryption, err := parseEncryption(params)
+	if err != nil {
+		return nil, err
+	}
+
+	bucket, err := parseBucketOptions(params)
+	if err != nil {
+		return nil, err
+	}
+
 	result := &Parameters{
-		ProvisionerSecretName:      provisionerSecretName,
-		ProvisionerSecretNamespace: provisionerSecretNamespace,
-		NodePublishSecretName:      nodePublishSecretName,
-		NodePublishSecretNamespace: nodePublishSecretNamespace,
-		AuthTier:                   authTier,
+		ProvisionerSecretName:        provisionerSecretName,
+		ProvisionerSecretNamespace:   provisionerSecretNamespace,
+		NodePublishSecretName:        nodePublishSecretName,
+		NodePublishSecretNamespace:   nodePublishSecretNamespace,
+		AuthTier:                     authTier,
+		MountpointContainerResources: mountpointContainerResources,
+		MountpointCache:              mountpointCache,
+		MountpointPodOptions:         mountpointPodOptions,
+		Encryption:                   encryption,
+		Bucket:                       bucket,
+		Warnings:                     parsed.Warnings(),
 	}
 
 	return result, nil
 }
 
-// enforceCSIDriverParameterPolicy strips parameters that are not supported by the CSI driver
-// We only support CSI standard provisioner secret parameters, all others are silently ignored
-func enforceCSIDriverParameterPolicy(parameters map[string]string) {
-	supportedParams := map[string]bool{
-		constants.ProvisionerSecretNameKey:      true,
-		constants.ProvisionerSecretNamespaceKey: true,
-		constants.NodePublishSecretNameKey:      true,
-		constants.NodePublishSecretNamespaceKey: true,
+// SupportedParameters returns the StorageClass parameters supported by the CSI driver, sorted by name.
+func SupportedParameters() []string {
+	return Schema.Keys()
+}
+
+// parseMountpointContainerResources returns Mountpoint Pod resource parameters, after checking they are valid quantities
+func parseMountpointContainerResources(parameters map[string]string) (map[string]string, error) {
+	var resources map[string]string
+	for _, param := range mountpointContainerResourcesParams {
+		value := strings.TrimSpace(parameters[param])
+		if value == "" {
+			continue
+		}
+		if _, err := resource.ParseQuantity(value); err != nil {
+			return nil, fmt.Errorf("invalid quantity %q for %s: %w", value, param, err)
+		}
+		if resources == nil {
+			resources = make(map[string]string)
+		}
+		resources[param] = value
 	}
+	return resources, nil
+}
 
-	// Remove any parameters that are not in our supported list
-	for param := range parameters {
-		if !supportedParams[param] {
-			delete(parameters, param)
-			klog.V(4).Infof("StorageClass parameter %q ignored: only CSI provisioner secret parameters are supported", param)
+// parseMountpointCache returns Mountpoint cache parameters, after checking they configure a valid cache volume
+func parseMountpointCache(parameters map[string]string) (map[string]string, error) {
+	var cache map[string]string
+	for _, param := range mountpointCacheParams {
+		if value := strings.TrimSpace(parameters[param]); value != "" {
+			if cache == nil {
+				cache = make(map[string]string)
+			}
+			cache[param] = value
 		}
 	}
+	if _, err := mppod.ParseCache(cache); err != nil {
+		return nil, err
+	}
+	return cache, nil
+}
+
+// parseMountpointPodOptions returns Mountpoint Pod option parameters, after checking they are valid
+func parseMountpointPodOptions(parameters map[string]string) (map[string]string, error) {
+	var options map[string]string
+	for _, param := range mountpointPodParams {
+		if value := strings.TrimSpace(parameters[param]); value != "" {
+			if options == nil {
+				options = make(map[string]string)
+			}
+			options[param] = value
+		}
+	}
+	if _, err := mppod.ParsePodOptions(options); err != nil {
+		return nil, err
+	}
+	return options, nil
+}
+
+// parseEncryption returns server-side encryption parameters, after checking they form a valid combination
+func parseEncryption(parameters map[string]string) (map[string]string, error) {
+	var encryption map[string]string
+	for _, param := range encryptionParams {
+		if value := strings.TrimSpace(parameters[param]); value != "" {
+			if encryption == nil {
+				encryption = make(map[string]string)
+			}
+			encryption[param] = value
+		}
+	}
+	if _, err := volumecontext.ParseEncryption(encryption); err != nil {
+		return nil, err
+	}
+	return encryption, nil
+}
+
+// parseBucketOptions returns the versioning and object lock of provisioned buckets, object lock requires a retention
+// and cannot be used with versioning disabled
+func parseBucketOptions(parameters map[string]string) (s3client.BucketOptions, error) {
+	var opts s3client.BucketOptions
+
+	versioning := strings.TrimSpace(parameters[VersioningParam])
+	switch versioning {
+	case "", "disabled":
+	case "enabled":
+		opts.Versioning = true
+	default:
+		return opts, fmt.Errorf("invalid %s %q, must be enabled or disabled", VersioningParam, versioning)
+	}
+
+	objectLock := strings.TrimSpace(parameters[ObjectLockParam])
+	retentionDays := strings.TrimSpace(parameters[RetentionDaysParam])
+	if objectLock == "" {
+		if retentionDays != "" {
+			return opts, fmt.Errorf("%s requires %s", RetentionDaysParam, ObjectLockParam)
+		}
+		return opts, nil
+	}
+
+	var mode types.ObjectLockRetentionMode
+	switch objectLock {
+	case "governance":
+		mode = types.ObjectLockRetentionModeGovernance
+	case "compliance":
+		mode = types.ObjectLockRetentionModeCompliance
+	default:
+		return opts, fmt.Errorf("invalid %s %q, must be governance or compliance", ObjectLockParam, objectLock)
+	}
+	if versioning == "disabled" {
+		return opts, fmt.Errorf("%s requires versioning, it cannot be used with %s: disabled", ObjectLockParam, VersioningParam)
+	}
+	if retentionDays == "" {
+		return opts, fmt.Errorf("%s requires %s", ObjectLockParam, RetentionDaysParam)
+	}
+	days, err := strconv.ParseInt(retentionDays, 10, 32)
+	if err != nil || days < 1 || days > maxRetentionDays {
+		return opts, fmt.Errorf("invalid %s %q, must be a number of days between 1 and %d", RetentionDaysParam, retentionDays, maxRetentionDays)
+	}
+
+	opts.Versioning = true
+	opts.ObjectLock = &s3client.ObjectLock{Mode: mode, RetentionDays: int32(days)}
+	return opts, nil
 }
 
 // validateSecretParameterConsistency ensures both secret name and namespace are provided if either is specified
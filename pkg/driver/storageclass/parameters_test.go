@@ -1,9 +1,14 @@
 package storageclass
 
 import (
+	"maps"
+	"reflect"
 	"testing"
 
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/s3client"
 )
 
 func TestParseAndValidate(t *testing.T) {
@@ -93,6 +98,7 @@ func TestParseAndValidate(t *testing.T) {
 				constants.NodePublishSecretNamespaceKey: "default",
 				"customParam":                           "ignored-value",
 				"anotherParam":                          "also-ignored",
+				"VersioNing":                            "enabled",
 			},
 			expected: &Parameters{
 				ProvisionerSecretName:      "test-creds",
@@ -100,9 +106,154 @@ func TestParseAndValidate(t *testing.T) {
 				NodePublishSecretName:      "test-creds",
 				NodePublishSecretNamespace: "default",
 				AuthTier:                   SecretCredentials,
+				Warnings: []string{
+					`unknown parameter "VersioNing" is ignored, did you mean "versioning"?`,
+					`unknown parameter "anotherParam" is ignored`,
+					`unknown parameter "customParam" is ignored`,
+				},
+			},
+			shouldErr: false,
+		},
+		{
+			name: "mountpoint container resources",
+			parameters: map[string]string{
+				"mountpointContainerResourcesRequestsMemory": " 2Gi ",
+				"mountpointContainerResourcesLimitsMemory":   "4Gi",
+			},
+			expected: &Parameters{
+				AuthTier: DriverCredentials,
+				MountpointContainerResources: map[string]string{
+					"mountpointContainerResourcesRequestsMemory": "2Gi",
+					"mountpointContainerResourcesLimitsMemory":   "4Gi",
+				},
+			},
+			shouldErr: false,
+		},
+		{
+			name: "invalid mountpoint container resources - should error",
+			parameters: map[string]string{
+				"mountpointContainerResourcesLimitsCpu": "two",
+			},
+			expected:  nil,
+			shouldErr: true,
+		},
+		{
+			name: "mountpoint cache",
+			parameters: map[string]string{
+				"cache":          "emptyDir",
+				"cacheSizeLimit": " 10Gi ",
+			},
+			expected: &Parameters{
+				AuthTier:        DriverCredentials,
+				MountpointCache: map[string]string{"cache": "emptyDir", "cacheSizeLimit": "10Gi"},
+			},
+			shouldErr: false,
+		},
+		{
+			name: "invalid mountpoint cache - should error",
+			parameters: map[string]string{
+				"cache": "ephemeralPVC",
+			},
+			expected:  nil,
+			shouldErr: true,
+		},
+		{
+			name: "mountpoint pod options",
+			parameters: map[string]string{
+				"mountpointPodTolerations": `[{"key": "nvidia.com/gpu", "operator": "Exists"}]`,
+				"mountpointPodLabels":      `{"team": "ml"}`,
+			},
+			expected: &Parameters{
+				AuthTier: DriverCredentials,
+				MountpointPodOptions: map[string]string{
+					"mountpointPodTolerations": `[{"key": "nvidia.com/gpu", "operator": "Exists"}]`,
+					"mountpointPodLabels":      `{"team": "ml"}`,
+				},
+			},
+			shouldErr: false,
+		},
+		{
+			name: "invalid mountpoint pod options - should error",
+			parameters: map[string]string{
+				"mountpointPodLabels": `{"s3.csi.scality.com/volume-name": "other"}`,
+			},
+			expected:  nil,
+			shouldErr: true,
+		},
+		{
+			name: "server-side encryption",
+			parameters: map[string]string{
+				"serverSideEncryption": "SSE-KMS",
+				"sseKmsKeyId":          "arn:aws:kms:us-east-1:000000000000:key/volume",
+			},
+			expected: &Parameters{
+				AuthTier:   DriverCredentials,
+				Encryption: map[string]string{"serverSideEncryption": "SSE-KMS", "sseKmsKeyId": "arn:aws:kms:us-east-1:000000000000:key/volume"},
 			},
 			shouldErr: false,
 		},
+		{
+			name: "KMS key without SSE-KMS - should error",
+			parameters: map[string]string{
+				"serverSideEncryption": "SSE-S3",
+				"sseKmsKeyId":          "volume",
+			},
+			expected:  nil,
+			shouldErr: true,
+		},
+		{
+			name: "bucket versioning",
+			parameters: map[string]string{
+				"versioning": "enabled",
+			},
+			expected: &Parameters{
+				AuthTier: DriverCredentials,
+				Bucket:   s3client.BucketOptions{Versioning: true},
+			},
+			shouldErr: false,
+		},
+		{
+			name: "bucket object lock",
+			parameters: map[string]string{
+				"objectLock":    "governance",
+				"retentionDays": "90",
+			},
+			expected: &Parameters{
+				AuthTier: DriverCredentials,
+				Bucket: s3client.BucketOptions{
+					Versioning: true,
+					ObjectLock: &s3client.ObjectLock{Mode: types.ObjectLockRetentionModeGovernance, RetentionDays: 90},
+				},
+			},
+			shouldErr: false,
+		},
+		{
+			name: "object lock without retention - should error",
+			parameters: map[string]string{
+				"objectLock": "compliance",
+			},
+			expected:  nil,
+			shouldErr: true,
+		},
+		{
+			name: "object lock with versioning disabled - should error",
+			parameters: map[string]string{
+				"versioning":    "disabled",
+				"objectLock":    "compliance",
+				"retentionDays": "90",
+			},
+			expected:  nil,
+			shouldErr: true,
+		},
+		{
+			name: "invalid retention - should error",
+			parameters: map[string]string{
+				"objectLock":    "compliance",
+				"retentionDays": "0",
+			},
+			expected:  nil,
+			shouldErr: true,
+		},
 		{
 			name: "whitespace trimming",
 			parameters: map[string]string{
@@ -150,6 +301,25 @@ func TestParseAndValidate(t *testing.T) {
 			if result.AuthTier != tt.expected.AuthTier {
 				t.Errorf("Expected AuthTier %v, got %v", tt.expected.AuthTier, result.AuthTier)
 			}
+
+			if !maps.Equal(result.MountpointContainerResources, tt.expected.MountpointContainerResources) {
+				t.Errorf("Expected MountpointContainerResources %v, got %v", tt.expected.MountpointContainerResources, result.MountpointContainerResources)
+			}
+
+			if !maps.Equal(result.MountpointCache, tt.expected.MountpointCache) {
+				t.Errorf("Expected MountpointCache %v, got %v", tt.expected.MountpointCache, result.MountpointCache)
+			}
+
+			if !maps.Equal(result.Encryption, tt.expected.Encryption) {
+				t.Errorf("Expected Encryption %v, got %v", tt.expected.Encryption, result.Encryption)
+			}
+
+			if !reflect.DeepEqual(result.Bucket, tt.expected.Bucket) {
+				t.Errorf("Expected Bucket %+v, got %+v", tt.expected.Bucket, result.Bucket)
+			}
+			if !reflect.DeepEqual(result.Warnings, tt.expected.Warnings) {
+				t.Errorf("Expected Warnings %q, got %q", tt.expected.Warnings, result.Warnings)
+			}
 		})
 	}
 }
@@ -0,0 +1,206 @@
+// Package driverconfig loads the tunables of the driver from a single YAML file, typically a mounted ConfigMap read
+// by both the controller and the node plugin, and reloads them without restarts when the file changes.
+package driverconfig
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
+)
+
+// EnvConfigFile is the environment variable containing the path of the driver configuration file.
+// The configuration file is disabled if unset, and tunables keep the values of their flags and environment variables.
+const EnvConfigFile = "DRIVER_CONFIG_FILE"
+
+// EnvConfigMap is the environment variable containing the `namespace/name` of the ConfigMap the configuration file
+// is mounted from, the object of events reporting invalid configurations. No events are recorded if unset.
+const EnvConfigMap = "DRIVER_CONFIG_CONFIGMAP"
+
+// ReloadInterval is how often the configuration file is read again.
+const ReloadInterval = 10 * time.Second
+
+// MaxLogLevel is the highest log level of the configuration.
+const MaxLogLevel = 10
+
+// Reasons of events recorded on the ConfigMap of the configuration file.
+const (
+	ReasonInvalidConfig = "InvalidDriverConfig"
+)
+
+// Results of reloads, labels of [ReloadsTotal].
+const (
+	ResultApplied = "applied"
+	ResultInvalid = "invalid"
+)
+
+// Metrics about reloads of the configuration file, registered by the controller and the node plugin.
+var (
+	ReloadsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "scality_csi_config_reloads_total",
+		Help: "Number of changes of the driver configuration file, by result (applied, invalid).",
+	}, []string{"result"})
+	ConfigValid = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "scality_csi_config_valid",
+		Help: "Whether the driver configuration file in use is valid (1) or not (0), in which case the last valid configuration is kept.",
+	})
+)
+
+// Config holds the tunables of the driver that can change without restarting it. Unset fields keep the values of
+// the flags and environment variables of the driver.
+type Config struct {
+	// LogLevel is the verbosity of the logs of the controller and the node plugin, from 0 to [MaxLogLevel].
+	LogLevel *int `json:"logLevel,omitempty"`
+	// MaxConcurrentMounts is the maximum number of volumes the node plugin mounts at the same time, zero disables the
+	// limit. It overrides `--max-concurrent-mounts`.
+	MaxConcurrentMounts *int `json:"maxConcurrentMounts,omitempty"`
+	// AllowedEndpointURLs are the S3 endpoint URLs volumes can use with the `endpoint-url` mount option, in addition
+	// to the ones allowed by the `ALLOWED_ENDPOINT_URLS` environment variable.
+	AllowedEndpointURLs []string `json:"allowedEndpointURLs,omitempty"`
+	// DeniedMountArgs are Mountpoint args stripped from the mount options of volumes, e.g. `--cache`.
+	DeniedMountArgs []string `json:"deniedMountArgs,omitempty"`
+}
+
+// mountArgRe matches Mountpoint arg names, with or without their leading dashes.
+var mountArgRe = regexp.MustCompile(`^(--)?[a-z][a-z0-9-]*$`)
+
+// Parse parses and validates a configuration in YAML, rejecting unknown fields.
+func Parse(data []byte) (*Config, error) {
+	config := &Config{}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, fmt.Errorf("invalid driver configuration: %w", err)
+	}
+	if config.LogLevel != nil && (*config.LogLevel < 0 || *config.LogLevel > MaxLogLevel) {
+		return nil, fmt.Errorf("invalid logLevel %d, must be between 0 and %d", *config.LogLevel, MaxLogLevel)
+	}
+	if config.MaxConcurrentMounts != nil && *config.MaxConcurrentMounts < 0 {
+		return nil, fmt.Errorf("invalid maxConcurrentMounts %d, must not be negative", *config.MaxConcurrentMounts)
+	}
+	for _, endpointURL := range config.AllowedEndpointURLs {
+		if !mountpoint.IsAllowedEndpointURL(endpointURL, []string{endpointURL}) {
+			return nil, fmt.Errorf("invalid allowedEndpointURLs entry %q, must be an http or https URL", endpointURL)
+		}
+	}
+	for i, arg := range config.DeniedMountArgs {
+		if !mountArgRe.MatchString(arg) {
+			return nil, fmt.Errorf("invalid deniedMountArgs entry %q, must be a Mountpoint arg name", arg)
+		}
+		config.DeniedMountArgs[i] = "--" + strings.TrimPrefix(arg, "--")
+	}
+	return config, nil
+}
+
+// A Watcher reads the configuration file periodically, and calls its handlers with the new configuration once it
+// changes. Invalid configurations are reported with metrics, logs and events, and the last valid one is kept.
+type Watcher struct {
+	path string
+
+	events record.EventRecorder
+	ref    *corev1.ObjectReference
+
+	mu sync.Mutex
+	// data is the content of the file last read, valid or not, so each change is only handled once.
+	data     []byte
+	config   *Config
+	handlers []func(*Config)
+}
+
+// NewWatcher returns a watcher of the configuration file at `path`.
+func NewWatcher(path string) *Watcher {
+	return &Watcher{path: path, config: &Config{}}
+}
+
+// SetEventRecorder records events reporting invalid configurations on `configMap` with `events`.
+// `configMap` is the `namespace/name` of the ConfigMap the configuration file is mounted from, see [EnvConfigMap].
+func (w *Watcher) SetEventRecorder(events record.EventRecorder, configMap string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(configMap)
+	if err != nil || namespace == "" || name == "" {
+		return fmt.Errorf("invalid driver configuration ConfigMap %q, must be namespace/name", configMap)
+	}
+	w.events = events
+	w.ref = &corev1.ObjectReference{Kind: "ConfigMap", APIVersion: "v1", Namespace: namespace, Name: name}
+	return nil
+}
+
+// OnChange adds a handler called with each new valid configuration. Handlers are called in the order they were added,
+// and must be added before the first [Watcher.Reload].
+func (w *Watcher) OnChange(handler func(*Config)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers = append(w.handlers, handler)
+}
+
+// Config returns the configuration in use, empty until a valid configuration is read.
+func (w *Watcher) Config() *Config {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.config
+}
+
+// Reload reads the configuration file and, if it changed and is valid, calls the handlers with the new
+// configuration. It returns whether the configuration changed, and an error if the file is invalid or cannot be read.
+func (w *Watcher) Reload() (bool, error) {
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		ConfigValid.Set(0)
+		return false, fmt.Errorf("cannot read driver configuration: %w", err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.data != nil && bytes.Equal(data, w.data) {
+		return false, nil
+	}
+	w.data = data
+
+	config, err := Parse(data)
+	if err != nil {
+		ReloadsTotal.WithLabelValues(ResultInvalid).Inc()
+		ConfigValid.Set(0)
+		if w.events != nil {
+			w.events.Eventf(w.ref, corev1.EventTypeWarning, ReasonInvalidConfig, "Keeping the last valid driver configuration: %v", err)
+		}
+		return false, err
+	}
+
+	w.config = config
+	for _, handler := range w.handlers {
+		handler(config)
+	}
+	ReloadsTotal.WithLabelValues(ResultApplied).Inc()
+	ConfigValid.Set(1)
+	return true, nil
+}
+
+// Start reloads the configuration file every `interval` until `stopCh` is closed. See [Watcher.Reload].
+func (w *Watcher) Start(stopCh <-chan struct{}, interval time.Duration) {
+	klog.Infof("driverconfig: Watching driver configuration %s every %v", w.path, interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			if changed, err := w.Reload(); err != nil {
+				klog.Errorf("driverconfig: Failed to reload driver configuration from %s: %v", w.path, err)
+			} else if changed {
+				klog.Infof("driverconfig: Driver configuration reloaded from %s", w.path)
+			}
+		}
+	}
+}
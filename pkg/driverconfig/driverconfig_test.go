@@ -0,0 +1,103 @@
+package driverconfig_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/tools/record"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driverconfig"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+const testConfig = `
+logLevel: 4
+maxConcurrentMounts: 8
+allowedEndpointURLs:
+  - https://s3.site-b.example.com
+deniedMountArgs:
+  - cache
+  - --incremental-upload
+`
+
+func TestParse(t *testing.T) {
+	config, err := driverconfig.Parse([]byte(testConfig))
+	assert.NoError(t, err)
+	assert.Equals(t, 4, *config.LogLevel)
+	assert.Equals(t, 8, *config.MaxConcurrentMounts)
+	assert.Equals(t, []string{"https://s3.site-b.example.com"}, config.AllowedEndpointURLs)
+	assert.Equals(t, []string{"--cache", "--incremental-upload"}, config.DeniedMountArgs)
+
+	config, err = driverconfig.Parse(nil)
+	assert.NoError(t, err)
+	assert.Equals(t, &driverconfig.Config{}, config)
+}
+
+func TestParseInvalidConfig(t *testing.T) {
+	for _, data := range []string{
+		`logLevel: [4]`,
+		`logLevel: 11`,
+		`logLevel: -1`,
+		`maxConcurrentMounts: -1`,
+		`allowedEndpointURLs: ["ftp://s3.example.com"]`,
+		`deniedMountArgs: ["--cache /tmp"]`,
+		`unknownTunable: true`,
+	} {
+		if _, err := driverconfig.Parse([]byte(data)); err == nil {
+			t.Errorf("Expected an error for configuration %s", data)
+		}
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	events := record.NewFakeRecorder(10)
+	watcher := driverconfig.NewWatcher(configFile)
+	assert.NoError(t, watcher.SetEventRecorder(events, "kube-system/s3-csi-driver-config"))
+	var applied []*driverconfig.Config
+	watcher.OnChange(func(config *driverconfig.Config) { applied = append(applied, config) })
+
+	if _, err := watcher.Reload(); err == nil {
+		t.Fatal("Expected an error for a missing configuration file")
+	}
+
+	assert.NoError(t, os.WriteFile(configFile, []byte(testConfig), 0o600))
+	changed, err := watcher.Reload()
+	assert.NoError(t, err)
+	assert.Equals(t, true, changed)
+	assert.Equals(t, 1, len(applied))
+
+	// Handlers are only called once the file changes
+	changed, err = watcher.Reload()
+	assert.NoError(t, err)
+	assert.Equals(t, false, changed)
+	assert.Equals(t, 1, len(applied))
+
+	// Invalid configurations are reported once, and the last valid one is kept
+	assert.NoError(t, os.WriteFile(configFile, []byte(`logLevel: 42`), 0o600))
+	if _, err := watcher.Reload(); err == nil {
+		t.Fatal("Expected an error for an invalid configuration")
+	}
+	changed, err = watcher.Reload()
+	assert.NoError(t, err)
+	assert.Equals(t, false, changed)
+	assert.Equals(t, 1, len(applied))
+	assert.Equals(t, 4, *watcher.Config().LogLevel)
+	assert.Equals(t, 1, len(events.Events))
+	if event := <-events.Events; !strings.Contains(event, driverconfig.ReasonInvalidConfig) {
+		t.Errorf("Expected a %s event, got %q", driverconfig.ReasonInvalidConfig, event)
+	}
+
+	assert.NoError(t, os.WriteFile(configFile, []byte(`maxConcurrentMounts: 2`), 0o600))
+	changed, err = watcher.Reload()
+	assert.NoError(t, err)
+	assert.Equals(t, true, changed)
+	assert.Equals(t, 2, len(applied))
+	assert.Equals(t, 2, *applied[1].MaxConcurrentMounts)
+
+	if err := watcher.SetEventRecorder(events, "s3-csi-driver-config"); err == nil {
+		t.Error("Expected an error for a ConfigMap without namespace")
+	}
+}
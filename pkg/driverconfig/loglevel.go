@@ -0,0 +1,34 @@
+package driverconfig
+
+import (
+	"strconv"
+
+	"k8s.io/klog/v2"
+)
+
+// KlogLevelHandler returns a handler setting the verbosity of klog to the log level of the configuration, or back to
+// the verbosity klog had when the handler was created if the log level is unset.
+func KlogLevelHandler() func(*Config) {
+	defaultLevel := klogVerbosity()
+	return func(config *Config) {
+		level := defaultLevel
+		if config.LogLevel != nil {
+			level = *config.LogLevel
+		}
+		// Setting a klog.Level sets the verbosity of the global logger, as the `-v` flag does
+		var verbosity klog.Level
+		if err := verbosity.Set(strconv.Itoa(level)); err != nil {
+			klog.Errorf("driverconfig: Failed to set log level %d: %v", level, err)
+		}
+	}
+}
+
+// klogVerbosity returns the verbosity of klog, capped to [MaxLogLevel].
+func klogVerbosity() int {
+	for level := MaxLogLevel; level > 0; level-- {
+		if klog.V(klog.Level(level)).Enabled() {
+			return level
+		}
+	}
+	return 0
+}
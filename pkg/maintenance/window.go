@@ -0,0 +1,218 @@
+// Package maintenance provides scheduled read-only windows of volumes, during which mounts of a volume are
+// forced read-only, e.g. during backend maintenance or to take consistent backups.
+//
+// Windows are set on a PersistentVolume with the [AnnotationReadOnlyWindows] annotation. `scality-csi-controller`
+// evaluates them and marks the volume and its MountpointS3PodAttachments with [AnnotationReadOnlyUntil] while
+// a window is active, and the node plugin remounts the corresponding mounts read-only until the window ends.
+// Mounts of volumes marked with [AnnotationQuotaExceeded] are remounted read-only the same way.
+package maintenance
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
+)
+
+const (
+	// AnnotationReadOnlyWindows is the PersistentVolume annotation defining its read-only windows, see [ParseWindows].
+	AnnotationReadOnlyWindows = constants.DriverName + "/read-only-windows"
+	// AnnotationReadOnlyUntil is set by the controller on PersistentVolumes and MountpointS3PodAttachments
+	// in an active read-only window, with the end of the window in RFC 3339 format.
+	AnnotationReadOnlyUntil = constants.DriverName + "/read-only-until"
+	// AnnotationQuotaExceeded is set by the controller on PersistentVolumes and MountpointS3PodAttachments whose
+	// prefix usage is above its hard limit, with the usage in bytes.
+	AnnotationQuotaExceeded = constants.DriverName + "/quota-exceeded"
+)
+
+// MaxWindowDuration is the maximum duration of a read-only window.
+const MaxWindowDuration = 7 * 24 * time.Hour
+
+// A Window is a recurring read-only window, starting on a cron schedule and lasting a fixed duration.
+type Window struct {
+	schedule schedule
+	duration time.Duration
+}
+
+// ParseWindows parses read-only windows separated by `;` from `spec`.
+// Each window is a 5-field cron expression (minute, hour, day of month, month, day of week) in UTC followed
+// by the duration of the window, e.g. `0 2 * * 6 4h` for four hours from 02:00 every Saturday.
+func ParseWindows(spec string) ([]Window, error) {
+	var windows []Window
+	for part := range strings.SplitSeq(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		fields := strings.Fields(part)
+		if len(fields) != 6 {
+			return nil, fmt.Errorf("invalid read-only window %q: expected a 5-field cron expression followed by a duration", part)
+		}
+		schedule, err := parseSchedule(fields[:5])
+		if err != nil {
+			return nil, fmt.Errorf("invalid read-only window %q: %w", part, err)
+		}
+		duration, err := time.ParseDuration(fields[5])
+		if err != nil {
+			return nil, fmt.Errorf("invalid read-only window %q: %w", part, err)
+		}
+		if duration < time.Minute || duration > MaxWindowDuration {
+			return nil, fmt.Errorf("invalid read-only window %q: duration must be between 1m and %v", part, MaxWindowDuration)
+		}
+
+		windows = append(windows, Window{schedule: schedule, duration: duration})
+	}
+	if len(windows) == 0 {
+		return nil, fmt.Errorf("no read-only window in %q", spec)
+	}
+	return windows, nil
+}
+
+// ActiveUntil returns when the read-only windows active at `now` end, and whether any is active.
+func ActiveUntil(windows []Window, now time.Time) (time.Time, bool) {
+	now = now.UTC()
+	var until time.Time
+	for _, window := range windows {
+		// Look for the latest start of the window which might still be active
+		for start := now.Truncate(time.Minute); now.Sub(start) < window.duration; start = start.Add(-time.Minute) {
+			if window.schedule.matches(start) {
+				if end := start.Add(window.duration); end.After(until) {
+					until = end
+				}
+				break
+			}
+		}
+	}
+	return until, !until.IsZero()
+}
+
+// FormatUntil formats the end of a read-only window for [AnnotationReadOnlyUntil].
+func FormatUntil(until time.Time) string {
+	return until.UTC().Format(time.RFC3339)
+}
+
+// ReadOnly returns whether an object annotated with `annotations` is in a read-only window at `now`.
+func ReadOnly(annotations map[string]string, now time.Time) bool {
+	value, ok := annotations[AnnotationReadOnlyUntil]
+	if !ok {
+		return false
+	}
+	until, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return false
+	}
+	return now.Before(until)
+}
+
+// QuotaExceeded returns whether an object annotated with `annotations` is above the hard limit of its prefix.
+func QuotaExceeded(annotations map[string]string) bool {
+	return annotations[AnnotationQuotaExceeded] != ""
+}
+
+// A schedule is a parsed cron expression, each field being the set of matching values.
+type schedule struct {
+	minutes, hours, daysOfMonth, months, daysOfWeek uint64
+	// anyDayOfMonth and anyDayOfWeek are set for `*` fields, as days match either field if both are restricted.
+	anyDayOfMonth, anyDayOfWeek bool
+}
+
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = [5]cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+func parseSchedule(fields []string) (schedule, error) {
+	var bits [5]uint64
+	for i, field := range fields {
+		var err error
+		if bits[i], err = parseCronField(field, cronFields[i]); err != nil {
+			return schedule{}, err
+		}
+	}
+	// Both 0 and 7 are Sunday
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+
+	return schedule{
+		minutes:       bits[0],
+		hours:         bits[1],
+		daysOfMonth:   bits[2],
+		months:        bits[3],
+		daysOfWeek:    bits[4],
+		anyDayOfMonth: fields[2] == "*",
+		anyDayOfWeek:  fields[4] == "*",
+	}, nil
+}
+
+// parseCronField parses a comma separated list of `*`, values or ranges, each with an optional `/step`.
+func parseCronField(field string, f cronField) (uint64, error) {
+	var bits uint64
+	for part := range strings.SplitSeq(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s field", stepPart, f.name)
+			}
+		}
+
+		low, high := f.min, f.max
+		if rangePart != "*" {
+			lowPart, highPart, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = parseCronValue(lowPart, f); err != nil {
+				return 0, err
+			}
+			high = low
+			if isRange {
+				if high, err = parseCronValue(highPart, f); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				high = f.max
+			}
+			if high < low {
+				return 0, fmt.Errorf("invalid range %q in %s field", rangePart, f.name)
+			}
+		}
+
+		for v := low; v <= high; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func parseCronValue(value string, f cronField) (int, error) {
+	v, err := strconv.Atoi(value)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid value %q in %s field, must be between %d and %d", value, f.name, f.min, f.max)
+	}
+	return v, nil
+}
+
+// matches returns whether `t` matches the schedule, to the minute.
+func (s schedule) matches(t time.Time) bool {
+	if s.minutes&(1<<t.Minute()) == 0 || s.hours&(1<<t.Hour()) == 0 || s.months&(1<<int(t.Month())) == 0 {
+		return false
+	}
+	dayOfMonth := s.daysOfMonth&(1<<t.Day()) != 0
+	dayOfWeek := s.daysOfWeek&(1<<int(t.Weekday())) != 0
+	if s.anyDayOfMonth || s.anyDayOfWeek {
+		return dayOfMonth && dayOfWeek
+	}
+	return dayOfMonth || dayOfWeek
+}
@@ -0,0 +1,131 @@
+package maintenance_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/maintenance"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestParseWindowsErrors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		";",
+		"0 2 * * 6",
+		"0 2 * * 6 4h extra",
+		"60 2 * * 6 4h",
+		"0 24 * * 6 4h",
+		"0 2 0 * * 4h",
+		"0 2 * 13 * 4h",
+		"0 2 * * 8 4h",
+		"0 5-2 * * * 4h",
+		"*/0 * * * * 4h",
+		"a * * * * 4h",
+		"0 2 * * 6 4x",
+		"0 2 * * 6 30s",
+		"0 2 * * 6 200h",
+	} {
+		t.Run(spec, func(t *testing.T) {
+			if _, err := maintenance.ParseWindows(spec); err == nil {
+				t.Fatalf("Expected an error parsing %q", spec)
+			}
+		})
+	}
+}
+
+func TestActiveUntil(t *testing.T) {
+	// 2025-06-07 is a Saturday
+	saturday := time.Date(2025, time.June, 7, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		spec      string
+		now       time.Time
+		wantUntil time.Time
+	}{
+		{
+			name: "before window",
+			spec: "0 2 * * 6 4h",
+			now:  saturday.Add(time.Hour + 59*time.Minute),
+		},
+		{
+			name:      "window start",
+			spec:      "0 2 * * 6 4h",
+			now:       saturday.Add(2 * time.Hour),
+			wantUntil: saturday.Add(6 * time.Hour),
+		},
+		{
+			name:      "within window",
+			spec:      "0 2 * * 6 4h",
+			now:       saturday.Add(5*time.Hour + 59*time.Minute + 30*time.Second),
+			wantUntil: saturday.Add(6 * time.Hour),
+		},
+		{
+			name: "window end",
+			spec: "0 2 * * 6 4h",
+			now:  saturday.Add(6 * time.Hour),
+		},
+		{
+			name: "other day of week",
+			spec: "0 2 * * 6 4h",
+			now:  saturday.Add(26 * time.Hour),
+		},
+		{
+			name:      "Sunday as 7, window spanning midnight",
+			spec:      "0 22 * * 7 4h",
+			now:       saturday.Add(48*time.Hour + time.Hour),
+			wantUntil: saturday.Add(48 * time.Hour).Add(2 * time.Hour),
+		},
+		{
+			name:      "day of month or day of week",
+			spec:      "30 0 1 * 1 1h",
+			now:       saturday.Add(2*24*time.Hour + 45*time.Minute),
+			wantUntil: saturday.Add(2*24*time.Hour + 90*time.Minute),
+		},
+		{
+			name:      "steps and lists",
+			spec:      "*/15 8-18/2 * * 1-5 10m; 0 2 * * 6 1h",
+			now:       time.Date(2025, time.June, 9, 10, 50, 0, 0, time.UTC),
+			wantUntil: time.Date(2025, time.June, 9, 10, 55, 0, 0, time.UTC),
+		},
+		{
+			name:      "overlapping windows end with the latest",
+			spec:      "0 2 * * 6 1h; 30 2 * * * 2h",
+			now:       saturday.Add(2*time.Hour + 45*time.Minute),
+			wantUntil: saturday.Add(4*time.Hour + 30*time.Minute),
+		},
+		{
+			name:      "non-UTC time",
+			spec:      "0 2 * * 6 4h",
+			now:       saturday.Add(3 * time.Hour).In(time.FixedZone("UTC+2", 2*60*60)),
+			wantUntil: saturday.Add(6 * time.Hour),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			windows, err := maintenance.ParseWindows(test.spec)
+			assert.NoError(t, err)
+
+			until, active := maintenance.ActiveUntil(windows, test.now)
+			assert.Equals(t, !test.wantUntil.IsZero(), active)
+			assert.Equals(t, test.wantUntil, until)
+		})
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	now := time.Date(2025, time.June, 7, 3, 0, 0, 0, time.UTC)
+
+	assert.Equals(t, false, maintenance.ReadOnly(nil, now))
+	assert.Equals(t, false, maintenance.ReadOnly(map[string]string{maintenance.AnnotationReadOnlyUntil: "invalid"}, now))
+	assert.Equals(t, true, maintenance.ReadOnly(map[string]string{maintenance.AnnotationReadOnlyUntil: maintenance.FormatUntil(now.Add(time.Minute))}, now))
+	assert.Equals(t, false, maintenance.ReadOnly(map[string]string{maintenance.AnnotationReadOnlyUntil: maintenance.FormatUntil(now)}, now))
+}
+
+func TestQuotaExceeded(t *testing.T) {
+	assert.Equals(t, false, maintenance.QuotaExceeded(nil))
+	assert.Equals(t, false, maintenance.QuotaExceeded(map[string]string{maintenance.AnnotationQuotaExceeded: ""}))
+	assert.Equals(t, true, maintenance.QuotaExceeded(map[string]string{maintenance.AnnotationQuotaExceeded: "13958643712"}))
+}
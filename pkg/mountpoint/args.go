@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"slices"
 	"strings"
+	"unicode"
 
 	"k8s.io/apimachinery/pkg/util/sets"
 )
@@ -15,14 +16,25 @@ const (
 	ArgAllowRoot                       = "--allow-root"
 	ArgRegion                          = "--region"
 	ArgCache                           = "--cache"
+	ArgMaxCacheSize                    = "--max-cache-size"
 	ArgUserAgentPrefix                 = "--user-agent-prefix"
 	ArgAWSMaxAttempts                  = "--aws-max-attempts"
+	ArgUid                             = "--uid"
 	ArgGid                             = "--gid"
 	ArgDirMode                         = "--dir-mode"
 	ArgFileMode                        = "--file-mode"
 	ArgForcePathStyle                  = "--force-path-style"
 	ArgDebug                           = "--debug"
 	ArgDebugCRT                        = "--debug-crt"
+	ArgPrefix                          = "--prefix"
+	ArgLogDirectory                    = "--log-directory"
+	ArgLogMetrics                      = "--log-metrics"
+	ArgLogLevel                        = "--log-level" // driver-only – set from the `logging` volume attribute, moved to the environment of Mountpoint
+	ArgSSE                             = "--sse"
+	ArgSSEKMSKeyID                     = "--sse-kms-key-id"
+	ArgMaxThreads                      = "--max-threads"
+	ArgMetadataTTL                     = "--metadata-ttl"
+	ArgAllowOverwrite                  = "--allow-overwrite"
 	ArgProfile                         = "--profile"            // stripped – Driver only supports static Keys, profile is for EKS/EC2 environments
 	ArgEndpointURL                     = "--endpoint-url"       // stripped – cluster‑admin controls S3 endpoints
 	ArgStorageClass                    = "--storage-class"      // stripped – driver forces bucket default (STANDARD)
@@ -60,23 +72,22 @@ type Args struct {
 }
 
 // ParseArgs parses given list of unnormalized and returns a normalized [Args].
+// If a key is passed more than once, its last value is kept.
 func ParseArgs(passedArgs []string) Args {
-	args := sets.New[arg]()
+	args := Args{sets.New[arg]()}
 
 	for _, a := range passedArgs {
 		var key, value string
 
-		trimmed := strings.Trim(a, " ")
+		trimmed := strings.TrimSpace(a)
 
-		spacePos := strings.Index(trimmed, " ")
+		spacePos := strings.IndexFunc(trimmed, unicode.IsSpace)
 		equalsPos := strings.Index(trimmed, "=")
 
 		if spacePos != -1 && (equalsPos == -1 || spacePos < equalsPos) {
-			parts := strings.SplitN(trimmed, " ", 2)
-			key, value = parts[0], strings.Trim(parts[1], " ")
+			key, value = trimmed[:spacePos], strings.TrimSpace(trimmed[spacePos:])
 		} else if equalsPos != -1 {
-			parts := strings.SplitN(trimmed, "=", 2)
-			key, value = parts[0], strings.Trim(parts[1], " ")
+			key, value = trimmed[:equalsPos], strings.TrimSpace(trimmed[equalsPos+1:])
 		} else {
 			key = trimmed
 			value = ArgNoValue
@@ -91,10 +102,20 @@ func ParseArgs(passedArgs []string) Args {
 			continue
 		}
 
-		args.Insert(arg{key, value})
+		// Keep a single value per key, otherwise removing a key would leave its other values behind
+		args.Set(key, value)
 	}
 
-	return Args{args}
+	return args
+}
+
+// ParseMountOptions parses mount options joined with commas, as stored in MountpointS3PodAttachments, with
+// [ParseArgs].
+func ParseMountOptions(mountOptions string) Args {
+	if mountOptions == "" {
+		return ParseArgs(nil)
+	}
+	return ParseArgs(strings.Split(mountOptions, ","))
 }
 
 // Set sets or replaces value of given key.
@@ -142,6 +163,11 @@ func (a *Args) SortedList() []string {
 	return args
 }
 
+// Clone returns a copy of [Args] that can be changed independently.
+func (a *Args) Clone() Args {
+	return Args{a.args.Clone()}
+}
+
 // find tries to find given key from [Args], and returns whole entry, and whether the key was found.
 func (a *Args) find(key ArgKey) (arg, bool) {
 	key = normalizeKey(key)
@@ -1,7 +1,10 @@
 package mountpoint_test
 
 import (
+	"slices"
+	"strings"
 	"testing"
+	"unicode"
 
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
@@ -201,6 +204,32 @@ func TestParsingMountpointArgs(t *testing.T) {
 				"--read-only",
 			},
 		},
+		{
+			name: "with duplicated keys",
+			input: []string{
+				"--region=us-west-2",
+				"--endpoint-url=https://s3.site-a.example.com",
+				"region eu-west-1",
+				"endpoint-url=https://s3.site-b.example.com",
+			},
+			want: []string{
+				"--endpoint-url=https://s3.site-b.example.com",
+				"--region=eu-west-1",
+			},
+		},
+		{
+			name: "with tabs and newlines",
+			input: []string{
+				"\t--allow-other\n",
+				"--region\tus-west-2",
+				"--uid\n=1000 ",
+			},
+			want: []string{
+				"--allow-other",
+				"--region=us-west-2",
+				"--uid==1000",
+			},
+		},
 		{
 			name: "with unsupported options",
 			input: []string{
@@ -557,3 +586,106 @@ func TestCreatingMountpointArgsFromAlreadyParsedArgs(t *testing.T) {
 	parsedArgs := mountpoint.ParseArgs(args.SortedList())
 	assert.Equals(t, want, parsedArgs.SortedList())
 }
+
+func TestNormalizingPermissionsOfMountpointArgs(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input []string
+		want  []string
+	}{
+		{
+			name:  "three octal digits",
+			input: []string{"file-mode=644", "dir-mode=755"},
+			want:  []string{"--dir-mode=755", "--file-mode=644"},
+		},
+		{
+			name:  "leading zero and 0o prefix",
+			input: []string{"file-mode=0640", "dir-mode 0o750"},
+			want:  []string{"--dir-mode=750", "--file-mode=640"},
+		},
+		{
+			name:  "short modes are padded",
+			input: []string{"file-mode=4", "dir-mode=0"},
+			want:  []string{"--dir-mode=000", "--file-mode=004"},
+		},
+		{
+			name:  "ids",
+			input: []string{"uid=01000", "gid 4294967294", "allow-other"},
+			want:  []string{"--allow-other", "--gid=4294967294", "--uid=1000"},
+		},
+		{
+			name:  "no permission arguments",
+			input: []string{"allow-delete"},
+			want:  []string{"--allow-delete"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			args := mountpoint.ParseArgs(testCase.input)
+			assert.NoError(t, args.NormalizePermissions())
+			assert.Equals(t, testCase.want, args.SortedList())
+		})
+	}
+}
+
+func TestNormalizingInvalidPermissionsOfMountpointArgs(t *testing.T) {
+	for _, input := range []string{
+		"file-mode=964",
+		"file-mode=1777",
+		"file-mode=rw-r--r--",
+		"file-mode",
+		"dir-mode=0o",
+		"dir-mode=-755",
+		"uid=bob",
+		"uid=-1",
+		"uid=4294967295",
+		"gid=1.5",
+		"gid",
+	} {
+		t.Run(input, func(t *testing.T) {
+			args := mountpoint.ParseArgs([]string{input})
+			if err := args.NormalizePermissions(); err == nil {
+				t.Fatalf("Expected an error normalizing %q", input)
+			}
+		})
+	}
+}
+
+func FuzzParseArgs(f *testing.F) {
+	for _, seed := range []string{
+		"allow-delete,region us-west-2,uid=1000",
+		"--prefix my folder/sub=test/,--cache /tmp/s3-cache",
+		"--endpoint-url=https://s3.example.com,--endpoint-url https://s3.other.example.com",
+		"\t--foreground\n,-f,  --read-only  ,=,--,-",
+		"--région=ü\u00a0x,--uid\n=0,\"--gid 0\",'--dir-mode' 777",
+		"",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, mountOptions string) {
+		args := mountpoint.ParseArgs(strings.Split(mountOptions, ","))
+		list := args.SortedList()
+
+		keys := make(map[string]bool)
+		for _, a := range list {
+			key, _, _ := strings.Cut(a, "=")
+			if keys[key] {
+				t.Fatalf("Key %q is parsed more than once from %q: %v", key, mountOptions, list)
+			}
+			keys[key] = true
+
+			if !strings.HasPrefix(key, "-") || strings.IndexFunc(key, unicode.IsSpace) != -1 {
+				t.Fatalf("Key %q parsed from %q is not normalized", key, mountOptions)
+			}
+			if slices.Contains([]string{mountpoint.ArgForeground, "-f", "--help", "-h", "--version", "-v"}, key) {
+				t.Fatalf("Option %q not supported in CSI is parsed from %q", key, mountOptions)
+			}
+		}
+
+		// Parsed args round-trip
+		reparsed := mountpoint.ParseArgs(list)
+		assert.Equals(t, list, reparsed.SortedList())
+	})
+}
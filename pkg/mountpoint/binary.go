@@ -0,0 +1,193 @@
+package mountpoint
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// BinaryName is the name of the Mountpoint binary.
+const BinaryName = "mount-s3"
+
+// ChecksumManifest is the name of the manifest of SHA-256 checksums of the files of a Mountpoint binary directory,
+// in the format of `sha256sum`.
+const ChecksumManifest = "SHA256SUMS"
+
+// ErrNoBinaryForPlatform is returned when a multi-arch binary directory has no directory for the platform of the node.
+var ErrNoBinaryForPlatform = errors.New("no Mountpoint binary for platform")
+
+// ErrBinaryIntegrity is returned when the Mountpoint binary does not have its expected digest.
+var ErrBinaryIntegrity = errors.New("integrity verification of the Mountpoint binary failed")
+
+// platformDirPattern matches directories of a multi-arch binary directory, e.g. `linux-arm64`.
+var platformDirPattern = regexp.MustCompile(`^[a-z0-9]+-[a-z0-9]+$`)
+
+// Platform returns the platform of the running binary, in the `<os>-<arch>` format of multi-arch binary directories.
+func Platform() string {
+	return runtime.GOOS + "-" + runtime.GOARCH
+}
+
+// ResolveBinDir returns the directory containing the Mountpoint binary for `platform` in `binDir`.
+//
+// `binDir` either contains the Mountpoint binary and its libraries, or one directory per platform containing them,
+// e.g. `linux-amd64` and `linux-arm64`. [ErrNoBinaryForPlatform] is returned if `binDir` has a directory per platform
+// but none for `platform`.
+func ResolveBinDir(binDir, platform string) (string, error) {
+	entries, err := os.ReadDir(binDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read Mountpoint binary directory %q: %w", binDir, err)
+	}
+
+	var platforms []string
+	for _, entry := range entries {
+		if entry.IsDir() && platformDirPattern.MatchString(entry.Name()) {
+			platforms = append(platforms, entry.Name())
+		}
+	}
+	if len(platforms) == 0 {
+		return binDir, nil
+	}
+	if !slices.Contains(platforms, platform) {
+		return "", fmt.Errorf("%w %s in %q, available platforms: %s", ErrNoBinaryForPlatform, platform, binDir, strings.Join(platforms, ", "))
+	}
+	return filepath.Join(binDir, platform), nil
+}
+
+// VerifyChecksums verifies the files of `dir` against its [ChecksumManifest]. Every regular file of `dir` must be
+// listed in the manifest with a matching checksum. Directories without a manifest are not verified, and false is
+// returned.
+func VerifyChecksums(dir string) (bool, error) {
+	checksums, err := readChecksumManifest(filepath.Join(dir, ChecksumManifest))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false, fmt.Errorf("failed to read directory %q: %w", dir, err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || name == ChecksumManifest {
+			continue
+		}
+		want, ok := checksums[name]
+		if !ok {
+			return false, fmt.Errorf("file %q is not listed in %s of %q", name, ChecksumManifest, dir)
+		}
+		got, err := sha256File(filepath.Join(dir, name))
+		if err != nil {
+			return false, err
+		}
+		if got != want {
+			return false, fmt.Errorf("checksum mismatch for %q in %q: got %s, expected %s", name, dir, got, want)
+		}
+	}
+	return true, nil
+}
+
+// ParseBinaryDigests parses the expected SHA-256 digests of the Mountpoint binary in `digests`, and returns them by
+// platform. `digests` is either a single digest expected on all platforms, or a comma-separated list of
+// `<platform>=<digest>`, e.g. `linux-amd64=sha256:...,linux-arm64=sha256:...`. Digests are hex-encoded, optionally
+// prefixed with `sha256:`. A single digest is returned with the `*` platform.
+func ParseBinaryDigests(digests string) (map[string]string, error) {
+	parsed := make(map[string]string)
+	for _, entry := range strings.Split(digests, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		platform, digest, ok := strings.Cut(entry, "=")
+		if !ok {
+			platform, digest = "*", entry
+		}
+		digest = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(digest), "sha256:"))
+		if decoded, err := hex.DecodeString(digest); err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("invalid SHA-256 digest %q", entry)
+		}
+		parsed[strings.TrimSpace(platform)] = digest
+	}
+	if _, ok := parsed["*"]; ok && len(parsed) > 1 {
+		return nil, fmt.Errorf("invalid digests %q: a single digest cannot be combined with per-platform digests", digests)
+	}
+	return parsed, nil
+}
+
+// VerifyBinaryDigest verifies that the binary at `path` has the digest expected for `platform` in `digests`, parsed
+// with [ParseBinaryDigests]. Errors wrap [ErrBinaryIntegrity].
+func VerifyBinaryDigest(path, platform string, digests map[string]string) error {
+	want, ok := digests[platform]
+	if !ok {
+		want, ok = digests["*"]
+	}
+	if !ok {
+		return fmt.Errorf("%w: no expected digest for platform %s", ErrBinaryIntegrity, platform)
+	}
+	got, err := sha256File(path)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrBinaryIntegrity, err)
+	}
+	if got != want {
+		return fmt.Errorf("%w: %q has digest sha256:%s, expected sha256:%s", ErrBinaryIntegrity, path, got, want)
+	}
+	return nil
+}
+
+// readChecksumManifest parses the `<checksum>  <name>` lines of the manifest at `path`.
+func readChecksumManifest(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	checksums := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		checksum, name, ok := strings.Cut(text, " ")
+		// `sha256sum` prefixes names with `*` in binary mode
+		name = strings.TrimPrefix(strings.TrimSpace(name), "*")
+		if decoded, err := hex.DecodeString(checksum); !ok || err != nil || len(decoded) != sha256.Size || name == "" {
+			return nil, fmt.Errorf("invalid line %d of %q", line, path)
+		}
+		checksums[name] = strings.ToLower(checksum)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", path, err)
+	}
+	return checksums, nil
+}
+
+func sha256File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to read %q: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,195 @@
+package mountpoint_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestResolveBinDir(t *testing.T) {
+	t.Run("flat layout", func(t *testing.T) {
+		binDir := t.TempDir()
+		writeFile(t, filepath.Join(binDir, mountpoint.BinaryName), "mount-s3")
+
+		got, err := mountpoint.ResolveBinDir(binDir, "linux-arm64")
+		assert.NoError(t, err)
+		assert.Equals(t, binDir, got)
+	})
+
+	t.Run("multi-arch layout", func(t *testing.T) {
+		binDir := t.TempDir()
+		writeFile(t, filepath.Join(binDir, "linux-amd64", mountpoint.BinaryName), "amd64")
+		writeFile(t, filepath.Join(binDir, "linux-arm64", mountpoint.BinaryName), "arm64")
+
+		got, err := mountpoint.ResolveBinDir(binDir, "linux-arm64")
+		assert.NoError(t, err)
+		assert.Equals(t, filepath.Join(binDir, "linux-arm64"), got)
+	})
+
+	t.Run("multi-arch layout without the platform", func(t *testing.T) {
+		binDir := t.TempDir()
+		writeFile(t, filepath.Join(binDir, "linux-amd64", mountpoint.BinaryName), "amd64")
+
+		_, err := mountpoint.ResolveBinDir(binDir, "windows-amd64")
+		if !errors.Is(err, mountpoint.ErrNoBinaryForPlatform) {
+			t.Fatalf("Expected ErrNoBinaryForPlatform, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "linux-amd64") {
+			t.Fatalf("Expected error to list available platforms, got %v", err)
+		}
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		_, err := mountpoint.ResolveBinDir(filepath.Join(t.TempDir(), "missing"), "linux-amd64")
+		if err == nil {
+			t.Fatal("Expected an error")
+		}
+	})
+}
+
+func TestVerifyChecksums(t *testing.T) {
+	setup := func(t *testing.T, manifest func(files map[string]string) string) string {
+		dir := t.TempDir()
+		files := map[string]string{mountpoint.BinaryName: "mount-s3", "libfuse.so.2": "libfuse"}
+		for name, content := range files {
+			writeFile(t, filepath.Join(dir, name), content)
+		}
+		if manifest != nil {
+			writeFile(t, filepath.Join(dir, mountpoint.ChecksumManifest), manifest(files))
+		}
+		return dir
+	}
+	sha256sums := func(files map[string]string) string {
+		var lines []string
+		for name, content := range files {
+			sum := sha256.Sum256([]byte(content))
+			lines = append(lines, hex.EncodeToString(sum[:])+"  "+name)
+		}
+		return strings.Join(lines, "\n") + "\n"
+	}
+
+	t.Run("valid manifest", func(t *testing.T) {
+		dir := setup(t, sha256sums)
+		verified, err := mountpoint.VerifyChecksums(dir)
+		assert.NoError(t, err)
+		assert.Equals(t, true, verified)
+	})
+
+	t.Run("binary mode manifest", func(t *testing.T) {
+		dir := setup(t, func(files map[string]string) string {
+			return strings.ReplaceAll(sha256sums(files), "  ", " *")
+		})
+		verified, err := mountpoint.VerifyChecksums(dir)
+		assert.NoError(t, err)
+		assert.Equals(t, true, verified)
+	})
+
+	t.Run("no manifest", func(t *testing.T) {
+		dir := setup(t, nil)
+		verified, err := mountpoint.VerifyChecksums(dir)
+		assert.NoError(t, err)
+		assert.Equals(t, false, verified)
+	})
+
+	t.Run("modified file", func(t *testing.T) {
+		dir := setup(t, sha256sums)
+		writeFile(t, filepath.Join(dir, mountpoint.BinaryName), "tampered")
+		if _, err := mountpoint.VerifyChecksums(dir); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+			t.Fatalf("Expected checksum mismatch, got %v", err)
+		}
+	})
+
+	t.Run("unlisted file", func(t *testing.T) {
+		dir := setup(t, sha256sums)
+		writeFile(t, filepath.Join(dir, "extra.so"), "extra")
+		if _, err := mountpoint.VerifyChecksums(dir); err == nil || !strings.Contains(err.Error(), "not listed") {
+			t.Fatalf("Expected unlisted file error, got %v", err)
+		}
+	})
+
+	t.Run("malformed manifest", func(t *testing.T) {
+		dir := setup(t, func(map[string]string) string { return "not-a-checksum mount-s3\n" })
+		if _, err := mountpoint.VerifyChecksums(dir); err == nil {
+			t.Fatal("Expected an error")
+		}
+	})
+}
+
+func TestParseBinaryDigests(t *testing.T) {
+	digest := strings.Repeat("ab", 32)
+	other := strings.Repeat("cd", 32)
+
+	testCases := []struct {
+		name    string
+		digests string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "empty", digests: "", want: map[string]string{}},
+		{name: "single digest", digests: digest, want: map[string]string{"*": digest}},
+		{name: "single prefixed digest", digests: " sha256:" + strings.ToUpper(digest) + " ", want: map[string]string{"*": digest}},
+		{
+			name:    "per-platform digests",
+			digests: "linux-amd64=sha256:" + digest + ", linux-arm64=" + other,
+			want:    map[string]string{"linux-amd64": digest, "linux-arm64": other},
+		},
+		{name: "short digest", digests: "sha256:abcd", wantErr: true},
+		{name: "not hex", digests: strings.Repeat("zz", 32), wantErr: true},
+		{name: "single and per-platform digests", digests: digest + ",linux-arm64=" + other, wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := mountpoint.ParseBinaryDigests(tc.digests)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("Expected an error, got %v", got)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equals(t, tc.want, got)
+		})
+	}
+}
+
+func TestVerifyBinaryDigest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), mountpoint.BinaryName)
+	writeFile(t, path, "mount-s3")
+	sum := sha256.Sum256([]byte("mount-s3"))
+	digest := hex.EncodeToString(sum[:])
+	other := strings.Repeat("cd", 32)
+
+	assert.NoError(t, mountpoint.VerifyBinaryDigest(path, "linux-amd64", map[string]string{"*": digest}))
+	assert.NoError(t, mountpoint.VerifyBinaryDigest(path, "linux-amd64", map[string]string{"linux-amd64": digest, "linux-arm64": other}))
+
+	for name, digests := range map[string]map[string]string{
+		"mismatch":           {"*": other},
+		"platform mismatch":  {"linux-amd64": other, "linux-arm64": digest},
+		"no platform digest": {"linux-arm64": digest},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := mountpoint.VerifyBinaryDigest(path, "linux-amd64", digests)
+			if !errors.Is(err, mountpoint.ErrBinaryIntegrity) {
+				t.Fatalf("Expected ErrBinaryIntegrity, got %v", err)
+			}
+		})
+	}
+
+	err := mountpoint.VerifyBinaryDigest(filepath.Join(t.TempDir(), "missing"), "linux-amd64", map[string]string{"*": digest})
+	if !errors.Is(err, mountpoint.ErrBinaryIntegrity) {
+		t.Fatalf("Expected ErrBinaryIntegrity for a missing binary, got %v", err)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o755))
+}
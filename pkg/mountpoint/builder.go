@@ -0,0 +1,240 @@
+package mountpoint
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A ConflictError is returned by [ArgsBuilder.Build] when a setting is made by both mount options and another
+// source, e.g. a volume attribute.
+type ConflictError struct {
+	// Setting describes what is set twice, e.g. `Server-side encryption`.
+	Setting string
+	// Source is the other source of the setting, e.g. `the serverSideEncryption volume attribute`.
+	Source string
+	// Args are the arguments of mount options making the setting.
+	Args []ArgKey
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s is set by both %s and mount options (%s), only use one", e.Setting, e.Source, strings.Join(e.Args, ", "))
+}
+
+// An ArgsBuilder builds the arguments of a mount from the mount options of its volume and the settings of the driver,
+// volume attributes or mounter. Settings are chained, and errors are collected and returned by [ArgsBuilder.Build].
+//
+// Settings with an empty value are ignored, and settings made by the driver only apply when mount options do not
+// make them unless stated otherwise.
+type ArgsBuilder struct {
+	args Args
+	errs []error
+}
+
+// NewArgsBuilder returns a builder starting from `mountOptions`, parsed with [ParseArgs] and with their permissions
+// normalized with [Args.NormalizePermissions].
+func NewArgsBuilder(mountOptions []string) *ArgsBuilder {
+	b := &ArgsBuilder{args: ParseArgs(mountOptions)}
+	if err := b.args.NormalizePermissions(); err != nil {
+		b.errs = append(b.errs, err)
+	}
+	return b
+}
+
+// BuilderOf returns a builder starting from a copy of `args`.
+func BuilderOf(args Args) *ArgsBuilder {
+	return &ArgsBuilder{args: args.Clone()}
+}
+
+// Has returns whether the arguments built so far have `key`.
+func (b *ArgsBuilder) Has(key ArgKey) bool {
+	return b.args.Has(key)
+}
+
+// Value returns the value of `key` in the arguments built so far, and whether it is set.
+func (b *ArgsBuilder) Value(key ArgKey) (ArgValue, bool) {
+	return b.args.Value(key)
+}
+
+// Take removes `key` from the arguments and returns its value, for settings passed to Mountpoint another way, e.g.
+// in its environment.
+func (b *ArgsBuilder) Take(key ArgKey) (ArgValue, bool) {
+	return b.args.Remove(key)
+}
+
+// Apply applies `policy` to the arguments built so far, e.g. to remove arguments denied by the driver configuration.
+func (b *ArgsBuilder) Apply(policy func(args *Args)) *ArgsBuilder {
+	policy(&b.args)
+	return b
+}
+
+// Fail records `err`, returned by [ArgsBuilder.Build].
+func (b *ArgsBuilder) Fail(err error) *ArgsBuilder {
+	b.errs = append(b.errs, err)
+	return b
+}
+
+// Exclusive records a [ConflictError] if any of `keys` is set, as `setting` is also made by `source`. It must be
+// called before the settings of `source` are applied.
+func (b *ArgsBuilder) Exclusive(setting, source string, keys ...ArgKey) *ArgsBuilder {
+	var conflicting []ArgKey
+	for _, key := range keys {
+		if b.args.Has(key) {
+			conflicting = append(conflicting, normalizeKey(key))
+		}
+	}
+	if len(conflicting) > 0 {
+		b.errs = append(b.errs, &ConflictError{Setting: setting, Source: source, Args: conflicting})
+	}
+	return b
+}
+
+// WithReadOnly mounts read-only, whatever the mount options.
+func (b *ArgsBuilder) WithReadOnly() *ArgsBuilder {
+	b.args.Set(ArgReadOnly, ArgNoValue)
+	return b
+}
+
+// WithPrefix mounts the keys of the bucket under `prefix`.
+func (b *ArgsBuilder) WithPrefix(prefix string) *ArgsBuilder {
+	return b.withDefault(ArgPrefix, prefix)
+}
+
+// WithEndpointURL sets the S3 endpoint of the mount, overriding mount options.
+func (b *ArgsBuilder) WithEndpointURL(endpointURL string) *ArgsBuilder {
+	return b.with(ArgEndpointURL, endpointURL)
+}
+
+// WithCache caches objects in `dir`, overriding mount options, with at most `maxSizeMiB` unless mount options
+// set [ArgMaxCacheSize]. The size is not limited if `maxSizeMiB` is zero.
+func (b *ArgsBuilder) WithCache(dir string, maxSizeMiB int64) *ArgsBuilder {
+	if dir == "" {
+		return b
+	}
+	b.args.Set(ArgCache, dir)
+	if maxSizeMiB > 0 {
+		b.args.SetIfAbsent(ArgMaxCacheSize, strconv.FormatInt(maxSizeMiB, 10))
+	}
+	return b
+}
+
+// WithLogDirectory writes the log files of Mountpoint to `dir`, overriding mount options.
+func (b *ArgsBuilder) WithLogDirectory(dir string) *ArgsBuilder {
+	return b.with(ArgLogDirectory, dir)
+}
+
+// WithLogMetrics logs metrics of Mountpoint.
+func (b *ArgsBuilder) WithLogMetrics() *ArgsBuilder {
+	b.args.SetIfAbsent(ArgLogMetrics, ArgNoValue)
+	return b
+}
+
+// WithLogLevel sets the log level of Mountpoint, overriding mount options. It is moved to the environment of
+// Mountpoint by the mounter, see [ArgLogLevel].
+func (b *ArgsBuilder) WithLogLevel(level string) *ArgsBuilder {
+	return b.with(ArgLogLevel, level)
+}
+
+// WithUserAgentPrefix prefixes the user agent of S3 requests with `userAgent`, overriding mount options.
+func (b *ArgsBuilder) WithUserAgentPrefix(userAgent string) *ArgsBuilder {
+	return b.with(ArgUserAgentPrefix, userAgent)
+}
+
+// WithDebug enables debug logs of Mountpoint, and of the AWS Common Runtime if `crt`.
+func (b *ArgsBuilder) WithDebug(crt bool) *ArgsBuilder {
+	b.args.SetIfAbsent(ArgDebug, ArgNoValue)
+	if crt {
+		b.args.SetIfAbsent(ArgDebugCRT, ArgNoValue)
+	}
+	return b
+}
+
+// WithSSE encrypts uploaded objects with server-side encryption `sse`, using KMS key `kmsKeyID` if set, overriding
+// mount options. Use [ArgsBuilder.Exclusive] to reject mount options setting it too.
+func (b *ArgsBuilder) WithSSE(sse, kmsKeyID string) *ArgsBuilder {
+	if sse == "" {
+		return b
+	}
+	b.args.Set(ArgSSE, sse)
+	return b.with(ArgSSEKMSKeyID, kmsKeyID)
+}
+
+// WithMetadataTTL caches metadata for `ttl`, overriding mount options.
+func (b *ArgsBuilder) WithMetadataTTL(ttl string) *ArgsBuilder {
+	return b.with(ArgMetadataTTL, ttl)
+}
+
+// WithAllowOverwrite allows overwriting existing objects if `allow`.
+func (b *ArgsBuilder) WithAllowOverwrite(allow bool) *ArgsBuilder {
+	if allow {
+		b.args.Set(ArgAllowOverwrite, ArgNoValue)
+	}
+	return b
+}
+
+// WithMaxThreads limits the number of threads of Mountpoint to `maxThreads`, overriding mount options. The number of
+// threads is not limited if `maxThreads` is zero.
+func (b *ArgsBuilder) WithMaxThreads(maxThreads int) *ArgsBuilder {
+	if maxThreads <= 0 {
+		return b
+	}
+	return b.with(ArgMaxThreads, strconv.Itoa(maxThreads))
+}
+
+// WithFSGroup grants group `gid` access to the mount with `dirMode` and `fileMode`, unless mount options set
+// [ArgGid]. Modes must be normalized, e.g. `770`.
+func (b *ArgsBuilder) WithFSGroup(gid, dirMode, fileMode string) *ArgsBuilder {
+	if gid == "" || b.args.Has(ArgGid) {
+		return b
+	}
+	b.args.Set(ArgGid, gid)
+	b.args.SetIfAbsent(ArgAllowOther, ArgNoValue)
+	b.args.SetIfAbsent(ArgDirMode, dirMode)
+	b.args.SetIfAbsent(ArgFileMode, fileMode)
+	return b
+}
+
+// WithAllowRoot lets root access the mount, unless mount options let all users access it with [ArgAllowOther].
+func (b *ArgsBuilder) WithAllowRoot() *ArgsBuilder {
+	if !b.args.Has(ArgAllowOther) {
+		b.args.SetIfAbsent(ArgAllowRoot, ArgNoValue)
+	}
+	return b
+}
+
+// WithPathStyle uses path-style addressing of the bucket.
+func (b *ArgsBuilder) WithPathStyle() *ArgsBuilder {
+	b.args.SetIfAbsent(ArgForcePathStyle, ArgNoValue)
+	return b
+}
+
+// WithVirtualHostedStyle uses virtual-hosted-style addressing of the bucket as set by `source`, mount options cannot
+// force path-style addressing.
+func (b *ArgsBuilder) WithVirtualHostedStyle(source string) *ArgsBuilder {
+	return b.Exclusive("Addressing style", source, ArgForcePathStyle)
+}
+
+// Build returns the arguments, or the errors recorded while building them.
+func (b *ArgsBuilder) Build() (Args, error) {
+	if err := errors.Join(b.errs...); err != nil {
+		return Args{}, err
+	}
+	return b.args, nil
+}
+
+// with sets `key` to `value` if not empty.
+func (b *ArgsBuilder) with(key ArgKey, value ArgValue) *ArgsBuilder {
+	if value != "" {
+		b.args.Set(key, value)
+	}
+	return b
+}
+
+// withDefault sets `key` to `value` if not empty and `key` is not set.
+func (b *ArgsBuilder) withDefault(key ArgKey, value ArgValue) *ArgsBuilder {
+	if value != "" {
+		b.args.SetIfAbsent(key, value)
+	}
+	return b
+}
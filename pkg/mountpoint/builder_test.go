@@ -0,0 +1,116 @@
+package mountpoint_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestArgsBuilder(t *testing.T) {
+	t.Run("driver settings apply around mount options", func(t *testing.T) {
+		args, err := mountpoint.NewArgsBuilder([]string{"prefix=user/", "region us-east-1", "max-cache-size=64", "file-mode=0644"}).
+			WithReadOnly().
+			WithPrefix("driver/").
+			WithEndpointURL("https://s3.example.com").
+			WithCache("/cache", 128).
+			WithDebug(false).
+			WithAllowRoot().
+			WithPathStyle().
+			Build()
+		assert.NoError(t, err)
+		assert.Equals(t, []string{
+			"--allow-root",
+			"--cache=/cache",
+			"--debug",
+			"--endpoint-url=https://s3.example.com",
+			"--file-mode=644",
+			"--force-path-style",
+			"--max-cache-size=64",
+			"--prefix=user/",
+			"--read-only",
+			"--region=us-east-1",
+		}, args.SortedList())
+	})
+
+	t.Run("empty settings are ignored", func(t *testing.T) {
+		args, err := mountpoint.NewArgsBuilder(nil).
+			WithPrefix("").
+			WithUserAgentPrefix("").
+			WithEndpointURL("").
+			WithCache("", 128).
+			WithSSE("", "").
+			WithMaxThreads(0).
+			WithAllowOverwrite(false).
+			WithFSGroup("", "770", "660").
+			Build()
+		assert.NoError(t, err)
+		assert.Equals(t, []string{}, args.SortedList())
+	})
+
+	t.Run("mounter settings", func(t *testing.T) {
+		builder := mountpoint.NewArgsBuilder([]string{"aws-max-attempts=5", "log-directory=/tmp/logs", "allow-delete"})
+		maxAttempts, ok := builder.Take(mountpoint.ArgAWSMaxAttempts)
+		assert.Equals(t, true, ok)
+		assert.Equals(t, "5", maxAttempts)
+		logDir, _ := builder.Value(mountpoint.ArgLogDirectory)
+		assert.Equals(t, "/tmp/logs", logDir)
+
+		args, err := builder.
+			Apply(func(args *mountpoint.Args) { args.Remove("--allow-delete") }).
+			WithLogDirectory("/logs").
+			WithLogMetrics().
+			WithUserAgentPrefix("s3-csi-driver/1.0").
+			Build()
+		assert.NoError(t, err)
+		assert.Equals(t, []string{"--log-directory=/logs", "--log-metrics", "--user-agent-prefix=s3-csi-driver/1.0"}, args.SortedList())
+	})
+
+	t.Run("fsGroup", func(t *testing.T) {
+		args, err := mountpoint.NewArgsBuilder(nil).WithFSGroup("2000", "770", "660").WithAllowRoot().Build()
+		assert.NoError(t, err)
+		assert.Equals(t, []string{"--allow-other", "--dir-mode=770", "--file-mode=660", "--gid=2000"}, args.SortedList())
+
+		args, err = mountpoint.NewArgsBuilder([]string{"gid=3000"}).WithFSGroup("2000", "770", "660").WithAllowRoot().Build()
+		assert.NoError(t, err)
+		assert.Equals(t, []string{"--allow-root", "--gid=3000"}, args.SortedList())
+	})
+
+	t.Run("conflicts with mount options", func(t *testing.T) {
+		_, err := mountpoint.NewArgsBuilder([]string{"sse-kms-key-id=key", "force-path-style"}).
+			Exclusive("Server-side encryption", "the serverSideEncryption volume attribute", mountpoint.ArgSSE, mountpoint.ArgSSEKMSKeyID).
+			WithSSE("aws:kms", "other-key").
+			WithVirtualHostedStyle("the addressingStyle volume attribute").
+			Build()
+		var conflictErr *mountpoint.ConflictError
+		if !errors.As(err, &conflictErr) {
+			t.Fatalf("Expected a ConflictError, got %v", err)
+		}
+		assert.Equals(t, []string{"--sse-kms-key-id"}, conflictErr.Args)
+		assert.Equals(t, "Server-side encryption is set by both the serverSideEncryption volume attribute and mount options (--sse-kms-key-id), only use one\n"+
+			"Addressing style is set by both the addressingStyle volume attribute and mount options (--force-path-style), only use one", err.Error())
+	})
+
+	t.Run("invalid permissions", func(t *testing.T) {
+		if _, err := mountpoint.NewArgsBuilder([]string{"dir-mode=999"}).Build(); err == nil {
+			t.Error("Expected an error for an invalid mode")
+		}
+	})
+
+	t.Run("builder of args", func(t *testing.T) {
+		args := mountpoint.ParseArgs([]string{"--cache=/tmp"})
+		built, err := mountpoint.BuilderOf(args).WithCache("/cache", 0).Build()
+		assert.NoError(t, err)
+		assert.Equals(t, []string{"--cache=/cache"}, built.SortedList())
+		// The original arguments are left untouched
+		assert.Equals(t, []string{"--cache=/tmp"}, args.SortedList())
+	})
+}
+
+func TestParseMountOptions(t *testing.T) {
+	args := mountpoint.ParseMountOptions("read-only,prefix=logs/")
+	assert.Equals(t, []string{"--prefix=logs/", "--read-only"}, args.SortedList())
+	args = mountpoint.ParseMountOptions("")
+	assert.Equals(t, []string{}, args.SortedList())
+}
@@ -0,0 +1,137 @@
+package mountpoint
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A Version is a release of Mountpoint, as reported by `mount-s3 --version`.
+type Version struct {
+	Major, Minor, Patch int
+}
+
+// versionPattern matches the version in the output of `mount-s3 --version`, e.g. `mount-s3 1.18.0` or
+// `mount-s3 1.18.0-a1b2c3d` for unofficial builds.
+var versionPattern = regexp.MustCompile(`(\d+)\.(\d+)\.(\d+)`)
+
+// ParseVersion parses the first version found in `s`, e.g. the output of `mount-s3 --version`.
+func ParseVersion(s string) (Version, error) {
+	match := versionPattern.FindStringSubmatch(s)
+	if match == nil {
+		return Version{}, fmt.Errorf("no Mountpoint version found in %q", strings.TrimSpace(s))
+	}
+	var parts [3]int
+	for i := range parts {
+		n, err := strconv.Atoi(match[i+1])
+		if err != nil {
+			return Version{}, fmt.Errorf("invalid Mountpoint version %q: %w", match[0], err)
+		}
+		parts[i] = n
+	}
+	return Version{Major: parts[0], Minor: parts[1], Patch: parts[2]}, nil
+}
+
+// String returns the version in the `<major>.<minor>.<patch>` format.
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Compare returns -1, 0 or +1 depending on whether `v` is older than, the same as or newer than `other`.
+func (v Version) Compare(other Version) int {
+	return slices.Compare([]int{v.Major, v.Minor, v.Patch}, []int{other.Major, other.Minor, other.Patch})
+}
+
+// versionTimeout bounds `mount-s3 --version`, which does not access the network.
+const versionTimeout = 10 * time.Second
+
+// DetectVersion runs `mount-s3 --version` with the binary at `binaryPath` and returns its version.
+func DetectVersion(ctx context.Context, binaryPath string) (Version, error) {
+	ctx, cancel := context.WithTimeout(ctx, versionTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, binaryPath, "--version").Output()
+	if err != nil {
+		return Version{}, fmt.Errorf("failed to run %s --version: %w", binaryPath, err)
+	}
+	return ParseVersion(string(output))
+}
+
+// An argSupport is the first release of Mountpoint supporting an argument.
+type argSupport struct {
+	since Version
+	// strippable is set for arguments only tuning performance or observability, which are removed from mount options
+	// on older releases instead of failing the mount.
+	strippable bool
+}
+
+// argSupportMatrix lists the arguments accepted in mount options that were added after Mountpoint 1.0.0, the oldest
+// release supported by the CSI driver. Arguments missing from the matrix are supported by all releases.
+var argSupportMatrix = map[ArgKey]argSupport{
+	ArgCache:                           {since: Version{1, 1, 0}, strippable: true},
+	ArgMaxCacheSize:                    {since: Version{1, 1, 0}, strippable: true},
+	ArgMetadataTTL:                     {since: Version{1, 1, 0}, strippable: true},
+	ArgAllowOverwrite:                  {since: Version{1, 2, 0}},
+	ArgSSE:                             {since: Version{1, 4, 0}},
+	ArgSSEKMSKeyID:                     {since: Version{1, 4, 0}},
+	"--negative-metadata-ttl":          {since: Version{1, 6, 0}, strippable: true},
+	ArgExpressOneZoneCache:             {since: Version{1, 8, 0}, strippable: true},
+	"--read-part-size":                 {since: Version{1, 9, 0}, strippable: true},
+	"--write-part-size":                {since: Version{1, 9, 0}, strippable: true},
+	ArgExpressOneZoneIncrementalUpload: {since: Version{1, 12, 0}},
+	"--upload-checksums":               {since: Version{1, 13, 0}},
+	"--max-memory-target":              {since: Version{1, 16, 0}, strippable: true},
+}
+
+// SupportedSince returns the first release of Mountpoint supporting `key`, and false if all releases support it.
+func SupportedSince(key ArgKey) (Version, bool) {
+	support, ok := argSupportMatrix[key]
+	return support.since, ok
+}
+
+// An UnsupportedArgsError is returned for mount options that the running release of Mountpoint does not support and
+// that cannot be removed without changing the behavior of the mount.
+type UnsupportedArgsError struct {
+	Version Version
+	// Args are the unsupported arguments, sorted.
+	Args []ArgKey
+}
+
+func (e *UnsupportedArgsError) Error() string {
+	var reasons []string
+	for _, key := range e.Args {
+		reasons = append(reasons, fmt.Sprintf("%s requires Mountpoint %s or later", key, argSupportMatrix[key].since))
+	}
+	return fmt.Sprintf("mount options not supported by Mountpoint %s: %s", e.Version, strings.Join(reasons, ", "))
+}
+
+// GateArgs adapts `args` to Mountpoint `version`. Unsupported arguments only tuning performance or observability are
+// removed and returned, and an [UnsupportedArgsError] is returned if other arguments are not supported.
+func GateArgs(args *Args, version Version) ([]ArgKey, error) {
+	var stripped, unsupported []ArgKey
+	for _, a := range args.args.UnsortedList() {
+		support, ok := argSupportMatrix[a.key]
+		if !ok || version.Compare(support.since) >= 0 {
+			continue
+		}
+		if support.strippable {
+			stripped = append(stripped, a.key)
+		} else {
+			unsupported = append(unsupported, a.key)
+		}
+	}
+	slices.Sort(stripped)
+	slices.Sort(unsupported)
+
+	if len(unsupported) > 0 {
+		return nil, &UnsupportedArgsError{Version: version, Args: unsupported}
+	}
+	for _, key := range stripped {
+		args.Remove(key)
+	}
+	return stripped, nil
+}
@@ -0,0 +1,80 @@
+package mountpoint_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestParseVersion(t *testing.T) {
+	for output, want := range map[string]mountpoint.Version{
+		"mount-s3 1.18.0\n":        {Major: 1, Minor: 18, Patch: 0},
+		"mount-s3 1.9.1-a1b2c3d\n": {Major: 1, Minor: 9, Patch: 1},
+		"v2.0.10":                  {Major: 2, Minor: 0, Patch: 10},
+	} {
+		version, err := mountpoint.ParseVersion(output)
+		assert.NoError(t, err)
+		assert.Equals(t, want, version)
+	}
+
+	if _, err := mountpoint.ParseVersion("mount-s3 unknown"); err == nil {
+		t.Error("Expected an error for an output without version")
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	v := mountpoint.Version{Major: 1, Minor: 9, Patch: 0}
+	assert.Equals(t, -1, v.Compare(mountpoint.Version{Major: 1, Minor: 10, Patch: 0}))
+	assert.Equals(t, 0, v.Compare(mountpoint.Version{Major: 1, Minor: 9, Patch: 0}))
+	assert.Equals(t, 1, v.Compare(mountpoint.Version{Major: 1, Minor: 8, Patch: 5}))
+	assert.Equals(t, "1.9.0", v.String())
+}
+
+func TestDetectVersion(t *testing.T) {
+	binary := filepath.Join(t.TempDir(), mountpoint.BinaryName)
+	assert.NoError(t, os.WriteFile(binary, []byte("#!/bin/sh\necho \"mount-s3 1.12.0\"\n"), 0o755))
+
+	version, err := mountpoint.DetectVersion(context.Background(), binary)
+	assert.NoError(t, err)
+	assert.Equals(t, mountpoint.Version{Major: 1, Minor: 12, Patch: 0}, version)
+
+	if _, err := mountpoint.DetectVersion(context.Background(), filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Expected an error for a missing binary")
+	}
+}
+
+func TestGateArgs(t *testing.T) {
+	t.Run("supported arguments are kept", func(t *testing.T) {
+		args := mountpoint.ParseArgs([]string{"--allow-delete", "--sse=aws:kms", "--max-memory-target=1024"})
+		stripped, err := mountpoint.GateArgs(&args, mountpoint.Version{Major: 1, Minor: 18, Patch: 0})
+		assert.NoError(t, err)
+		assert.Equals(t, 0, len(stripped))
+		assert.Equals(t, []string{"--allow-delete", "--max-memory-target=1024", "--sse=aws:kms"}, args.SortedList())
+	})
+
+	t.Run("tuning arguments are stripped", func(t *testing.T) {
+		args := mountpoint.ParseArgs([]string{"--allow-delete", "--read-part-size=8388608", "--max-memory-target=1024"})
+		stripped, err := mountpoint.GateArgs(&args, mountpoint.Version{Major: 1, Minor: 8, Patch: 0})
+		assert.NoError(t, err)
+		assert.Equals(t, []string{"--max-memory-target", "--read-part-size"}, stripped)
+		assert.Equals(t, []string{"--allow-delete"}, args.SortedList())
+	})
+
+	t.Run("other arguments fail the mount", func(t *testing.T) {
+		args := mountpoint.ParseArgs([]string{"--allow-overwrite", "--sse=AES256", "--cache=/tmp/cache"})
+		_, err := mountpoint.GateArgs(&args, mountpoint.Version{Major: 1, Minor: 0, Patch: 0})
+		var unsupportedErr *mountpoint.UnsupportedArgsError
+		if !errors.As(err, &unsupportedErr) {
+			t.Fatalf("Expected an UnsupportedArgsError, got %v", err)
+		}
+		assert.Equals(t, []string{"--allow-overwrite", "--sse"}, unsupportedErr.Args)
+		assert.Equals(t, "mount options not supported by Mountpoint 1.0.0: --allow-overwrite requires Mountpoint 1.2.0 or later, --sse requires Mountpoint 1.4.0 or later", err.Error())
+		// Arguments are left untouched on errors
+		assert.Equals(t, 3, len(args.SortedList()))
+	})
+}
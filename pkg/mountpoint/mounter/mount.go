@@ -4,9 +4,12 @@
 package mounter
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"syscall"
 
 	"k8s.io/klog/v2"
 	"k8s.io/mount-utils"
@@ -108,6 +111,33 @@ func (m *Mounter) Unmount(target string) error {
 	return m.mountutils.Unmount(target)
 }
 
+// IsBusy returns whether unmount error `err` is caused by processes still using the mount, either as an `EBUSY`
+// error of the unmount syscall or as the output of the `umount` command.
+func IsBusy(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, syscall.EBUSY) {
+		return true
+	}
+	message := err.Error()
+	return strings.Contains(message, "target is busy") || strings.Contains(message, "device is busy")
+}
+
+// IsWedged returns whether `err` of an operation on a mount is caused by a FUSE mount whose process is gone or
+// stuck, e.g. `transport endpoint is not connected` or a stale file handle, either as an error of a syscall or as the
+// output of the `umount` command. Such mounts can only be detached.
+func IsWedged(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, syscall.ENOTCONN) || errors.Is(err, syscall.ESTALE) {
+		return true
+	}
+	message := err.Error()
+	return strings.Contains(message, "transport endpoint is not connected") || strings.Contains(message, "tale file handle")
+}
+
 // IsMountpointCorrupted checks if a mount point error indicates corruption.
 // A mount point is considered corrupted when it's in an inconsistent state.
 func (m *Mounter) IsMountpointCorrupted(err error) bool {
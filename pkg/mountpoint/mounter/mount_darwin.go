@@ -42,3 +42,13 @@ func CreateMountFlags(args mountpoint.Args) uintptr {
 func PerformMount(target string, options []string, flags uintptr) error {
 	return errors.New("mount syscall only supported on Linux")
 }
+
+// RemountBind returns an error on Darwin as mount syscall is Linux-specific.
+func RemountBind(target string, readOnly bool, mountOptions []string) error {
+	return errors.New("mount syscall only supported on Linux")
+}
+
+// UnmountLazy returns an error on Darwin as mount syscall is Linux-specific.
+func UnmountLazy(target string) error {
+	return errors.New("mount syscall only supported on Linux")
+}
@@ -93,3 +93,44 @@ func PerformMount(target string, options []string, flags uintptr) error {
 
 	return nil
 }
+
+// RemountBind changes whether the mount point at `target` is read-only, preserving its other per-mount flags
+// from `mountOptions` as listed in `/proc/self/mountinfo`. Only this mount point is affected, not the underlying
+// filesystem or other bind mounts of it.
+func RemountBind(target string, readOnly bool, mountOptions []string) error {
+	flags := uintptr(syscall.MS_REMOUNT | syscall.MS_BIND)
+	if readOnly {
+		flags |= syscall.MS_RDONLY
+	}
+	for _, option := range mountOptions {
+		switch option {
+		case "nosuid":
+			flags |= syscall.MS_NOSUID
+		case "nodev":
+			flags |= syscall.MS_NODEV
+		case "noexec":
+			flags |= syscall.MS_NOEXEC
+		case "noatime":
+			flags |= syscall.MS_NOATIME
+		case "nodiratime":
+			flags |= syscall.MS_NODIRATIME
+		case "relatime":
+			flags |= syscall.MS_RELATIME
+		}
+	}
+
+	klog.V(4).Infof("Remounting %s with read-only=%t", target, readOnly)
+	if err := syscall.Mount("", target, "", flags, ""); err != nil {
+		return fmt.Errorf("failed to remount %s: %w", target, err)
+	}
+	return nil
+}
+
+// UnmountLazy detaches the mount at `target` from the file system hierarchy right away, the mount is cleaned up
+// once no process uses it anymore.
+func UnmountLazy(target string) error {
+	if err := syscall.Unmount(target, syscall.MNT_DETACH); err != nil {
+		return fmt.Errorf("failed to lazily unmount %s: %w", target, err)
+	}
+	return nil
+}
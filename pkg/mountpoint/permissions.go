@@ -0,0 +1,71 @@
+package mountpoint
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxMode is the largest permission Mountpoint accepts for files and directories, special bits are not supported.
+const maxMode = 0o777
+
+// maxID is the largest user or group ID, 4294967295 is reserved as the invalid ID `(uid_t)-1`.
+const maxID = 1<<32 - 2
+
+// NormalizePermissions validates values of the permission and ownership arguments `--file-mode`, `--dir-mode`,
+// `--uid` and `--gid`, and normalizes them to the form Mountpoint expects: three octal digits for modes, and
+// decimal numbers for IDs. Malformed values would otherwise only fail once Mountpoint starts.
+func (a *Args) NormalizePermissions() error {
+	var errs []error
+	for _, key := range []ArgKey{ArgFileMode, ArgDirMode} {
+		value, ok := a.Value(key)
+		if !ok {
+			continue
+		}
+		mode, err := parseMode(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s %q: %w, e.g. %s=0644 or %s=750", key, value, err, key, key))
+			continue
+		}
+		a.Set(key, fmt.Sprintf("%03o", mode))
+	}
+	for _, key := range []ArgKey{ArgUid, ArgGid} {
+		value, ok := a.Value(key)
+		if !ok {
+			continue
+		}
+		id, err := parseID(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s %q: %w, e.g. %s=1000", key, value, err, key))
+			continue
+		}
+		a.Set(key, strconv.FormatUint(id, 10))
+	}
+	return errors.Join(errs...)
+}
+
+// parseMode parses an octal permission like `644`, `0644` or `0o644`.
+func parseMode(value string) (uint64, error) {
+	digits := strings.TrimPrefix(strings.TrimPrefix(value, "0o"), "0O")
+	if digits == "" {
+		return 0, errors.New("must be an octal permission")
+	}
+	mode, err := strconv.ParseUint(digits, 8, 32)
+	if err != nil {
+		return 0, errors.New("must be an octal permission")
+	}
+	if mode > maxMode {
+		return 0, fmt.Errorf("must be between 000 and %03o", maxMode)
+	}
+	return mode, nil
+}
+
+// parseID parses a numeric user or group ID.
+func parseID(value string) (uint64, error) {
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || id > maxID {
+		return 0, fmt.Errorf("must be a numeric ID between 0 and %d", maxID)
+	}
+	return id, nil
+}
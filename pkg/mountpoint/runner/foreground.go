@@ -2,11 +2,14 @@ package runner
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"syscall"
+	"time"
 
 	"k8s.io/klog/v2"
 
@@ -34,6 +37,13 @@ type ForegroundOptions struct {
 	Env []string
 	// Command runner to use, if nil, [DefaultCmdRunner] will be used.
 	CmdRunner CmdRunner
+	// Context to stop Mountpoint with, if nil, Mountpoint runs until it exits by itself.
+	// Once done, Mountpoint process receives SIGTERM, and SIGKILL if it's still running after `GracePeriod`.
+	Context context.Context
+	// GracePeriod is how long Mountpoint is given to exit after SIGTERM before it gets killed.
+	GracePeriod time.Duration
+	// Output receives a copy of Mountpoint processes's stdout and stderr if non-nil.
+	Output io.Writer
 }
 
 // RunInForeground runs Mountpoint in the foreground until completion.
@@ -72,15 +82,28 @@ func RunInForeground(opts ForegroundOptions) (ExitCode, []byte, error) {
 		"/dev/fd/3",
 	}, mountpointArgs.SortedList()...)
 
-	cmd := exec.Command(opts.BinaryPath, args...)
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	cmd := exec.CommandContext(ctx, opts.BinaryPath, args...)
 	cmd.ExtraFiles = []*os.File{fuseDev}
 	cmd.Env = opts.Env
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(syscall.SIGTERM)
+	}
+	cmd.WaitDelay = opts.GracePeriod
 
 	var stderrBuf bytes.Buffer
 	// Connect Mountpoint's stdout/stderr to this commands stdout/stderr,
 	// as we're running Mountpoint in the foreground.
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = io.MultiWriter(os.Stderr, &stderrBuf)
+	if opts.Output != nil {
+		cmd.Stdout = io.MultiWriter(cmd.Stdout, opts.Output)
+		cmd.Stderr = io.MultiWriter(cmd.Stderr, opts.Output)
+	}
 
 	exitCode, err := opts.CmdRunner(cmd)
 	if err != nil {
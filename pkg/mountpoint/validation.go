@@ -0,0 +1,150 @@
+package mountpoint
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// UnsupportedArgs are Mountpoint arguments the CSI driver removes from mount options, with the reason they are removed.
+// [ArgEndpointURL] is only removed for endpoints not allowed by the cluster administrator, see [IsAllowedEndpointURL].
+var UnsupportedArgs = map[ArgKey]string{
+	ArgProfile:                         "only static keys are supported by the CSI driver",
+	ArgExpressOneZoneCache:             "S3 Express One Zone cache is not supported by backend",
+	ArgExpressOneZoneIncrementalUpload: "S3 Express One Zone append not supported by backend",
+	ArgStorageClass:                    "only STANDARD is supported by the CSI driver",
+	ArgFsTab:                           "driver does not support fs-tab",
+}
+
+// optionArgs are the arguments of Mountpoint and the CSI driver that do not take a value.
+var optionArgs = sets.New[ArgKey](
+	ArgReadOnly, ArgAllowOther, ArgAllowRoot, ArgForcePathStyle, ArgDebug, ArgDebugCRT, ArgExpressOneZoneIncrementalUpload,
+	"--allow-delete", ArgAllowOverwrite, "--auto-unmount", "--transfer-acceleration", "--dual-stack",
+	"--requester-pays", "--no-sign-request", "--no-log", ArgLogMetrics,
+)
+
+// valueArgs are the arguments of Mountpoint and the CSI driver that take a value.
+var valueArgs = sets.New[ArgKey](
+	ArgRegion, ArgCache, ArgUserAgentPrefix, ArgAWSMaxAttempts, ArgUid, ArgGid, ArgDirMode, ArgFileMode, ArgPrefix,
+	ArgLogDirectory, ArgProfile, ArgEndpointURL, ArgStorageClass, ArgExpressOneZoneCache, ArgFsTab,
+	ArgMaxCacheSize, ArgMetadataTTL, "--negative-metadata-ttl", "--part-size", "--read-part-size",
+	"--write-part-size", ArgMaxThreads, "--maximum-throughput-gbps", "--max-memory-target", ArgSSE, ArgSSEKMSKeyID,
+	"--upload-checksums", "--expected-bucket-owner", "--bind",
+)
+
+// SupportedArg describes a Mountpoint argument accepted in mount options of volumes.
+type SupportedArg struct {
+	Key        ArgKey `json:"key"`
+	TakesValue bool   `json:"takesValue"`
+	// Ignored is the reason the CSI driver removes the argument from mount options, empty if it is passed to Mountpoint.
+	Ignored string `json:"ignored,omitempty"`
+	// Since is the first release of Mountpoint supporting the argument, empty if all releases support it.
+	Since string `json:"since,omitempty"`
+	// StrippedBefore is set if the argument is removed from mount options on older releases, which otherwise fail
+	// the mount, see [GateArgs].
+	StrippedBefore bool `json:"strippedBefore,omitempty"`
+}
+
+// SupportedArgs returns the arguments accepted in mount options of volumes, sorted by key.
+func SupportedArgs() []SupportedArg {
+	var args []SupportedArg
+	for _, key := range sets.List(optionArgs) {
+		args = append(args, SupportedArg{Key: key, Ignored: UnsupportedArgs[key]})
+	}
+	for _, key := range sets.List(valueArgs) {
+		args = append(args, SupportedArg{Key: key, TakesValue: true, Ignored: UnsupportedArgs[key]})
+	}
+	for i := range args {
+		if support, ok := argSupportMatrix[args[i].Key]; ok {
+			args[i].Since = support.since.String()
+			args[i].StrippedBefore = support.strippable
+		}
+	}
+	slices.SortFunc(args, func(a, b SupportedArg) int { return strings.Compare(a.Key, b.Key) })
+	return args
+}
+
+// ValidateMountOptions validates the mount options of a volume as the CSI driver would pass them to Mountpoint.
+// It returns an error for unknown arguments and malformed values, which would fail the mount, and warnings for
+// arguments the driver ignores. `allowedEndpointURLs` are the endpoints volumes can use with [ArgEndpointURL].
+func ValidateMountOptions(mountOptions []string, allowedEndpointURLs []string) ([]string, error) {
+	args := ParseArgs(mountOptions)
+
+	var warnings []string
+	var errs []error
+	for _, a := range args.args.UnsortedList() {
+		switch {
+		case optionArgs.Has(a.key):
+			if a.value != ArgNoValue {
+				errs = append(errs, fmt.Errorf("%s does not take a value, got %q", a.key, a.value))
+			}
+		case valueArgs.Has(a.key):
+			if a.value == ArgNoValue {
+				errs = append(errs, fmt.Errorf("%s requires a value, e.g. %s=<value>", a.key, strings.TrimPrefix(a.key, "--")))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("unknown mount option %s", a.key))
+			continue
+		}
+
+		if reason, ok := UnsupportedArgs[a.key]; ok {
+			warnings = append(warnings, fmt.Sprintf("%s is ignored: %s", a.key, reason))
+		}
+		if a.key == ArgEndpointURL && a.value != ArgNoValue && !IsAllowedEndpointURL(a.value, allowedEndpointURLs) {
+			warnings = append(warnings, fmt.Sprintf("%s is ignored: %q is not in the driver's allowed endpoint URLs", a.key, a.value))
+		}
+	}
+
+	if err := validateSSE(args); err != nil {
+		errs = append(errs, err)
+	}
+	if err := args.NormalizePermissions(); err != nil {
+		errs = append(errs, err)
+	}
+
+	slices.Sort(warnings)
+	slices.SortFunc(errs, func(a, b error) int { return strings.Compare(a.Error(), b.Error()) })
+	return warnings, errors.Join(errs...)
+}
+
+// sseValues are the server-side encryptions Mountpoint supports with [ArgSSE].
+var sseValues = []string{"AES256", "aws:kms", "aws:kms:dsse"}
+
+// validateSSE returns an error if the server-side encryption of `args` would be rejected by Mountpoint.
+func validateSSE(args Args) error {
+	sse, hasSSE := args.Value(ArgSSE)
+	if hasSSE && sse != ArgNoValue && !slices.Contains(sseValues, sse) {
+		return fmt.Errorf("invalid %s %q, must be one of %s", ArgSSE, sse, strings.Join(sseValues, ", "))
+	}
+	if args.Has(ArgSSEKMSKeyID) && !strings.HasPrefix(sse, "aws:kms") {
+		return fmt.Errorf("%s requires %s=aws:kms or %s=aws:kms:dsse", ArgSSEKMSKeyID, ArgSSE, ArgSSE)
+	}
+	return nil
+}
+
+// IsAllowedEndpointURL returns whether `endpointURL` is one of `allowedEndpointURLs`.
+// URLs are compared by scheme, host and path, ignoring case of the scheme and host and trailing slashes.
+func IsAllowedEndpointURL(endpointURL string, allowedEndpointURLs []string) bool {
+	normalized, ok := normalizeEndpointURL(endpointURL)
+	if !ok {
+		return false
+	}
+	for _, allowed := range allowedEndpointURLs {
+		if allowed, ok := normalizeEndpointURL(strings.TrimSpace(allowed)); ok && allowed == normalized {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeEndpointURL(endpointURL string) (string, bool) {
+	u, err := url.Parse(endpointURL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") || u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+		return "", false
+	}
+	return strings.ToLower(u.Scheme) + "://" + strings.ToLower(u.Host) + strings.TrimSuffix(u.Path, "/"), true
+}
@@ -0,0 +1,97 @@
+package mountpoint_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/mountpoint"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestValidateMountOptions(t *testing.T) {
+	allowed := []string{"https://s3.site-b.example.com"}
+
+	testCases := []struct {
+		name         string
+		mountOptions []string
+		wantWarnings []string
+		wantErrors   []string
+	}{
+		{
+			name:         "valid options",
+			mountOptions: []string{"allow-delete", "uid=1000", "file-mode=0640", "cache /mnt/cache", "--metadata-ttl 60", "endpoint-url=https://S3.site-b.example.com/"},
+		},
+		{
+			name:         "unknown option",
+			mountOptions: []string{"allow-delete", "allow-delte"},
+			wantErrors:   []string{"unknown mount option --allow-delte"},
+		},
+		{
+			name:         "value of an option without value",
+			mountOptions: []string{"allow-other=true"},
+			wantErrors:   []string{`--allow-other does not take a value, got "true"`},
+		},
+		{
+			name:         "missing value",
+			mountOptions: []string{"region"},
+			wantErrors:   []string{"--region requires a value"},
+		},
+		{
+			name:         "invalid permissions",
+			mountOptions: []string{"file-mode=964", "uid=nobody"},
+			wantErrors:   []string{`invalid --file-mode "964"`, `invalid --uid "nobody"`},
+		},
+		{
+			name:         "valid server-side encryption",
+			mountOptions: []string{"sse=aws:kms", "sse-kms-key-id=arn:aws:kms:us-east-1:000000000000:key/volume"},
+		},
+		{
+			name:         "invalid server-side encryption",
+			mountOptions: []string{"sse=SSE-KMS"},
+			wantErrors:   []string{`invalid --sse "SSE-KMS"`},
+		},
+		{
+			name:         "KMS key without SSE-KMS",
+			mountOptions: []string{"sse=AES256", "sse-kms-key-id=volume"},
+			wantErrors:   []string{"--sse-kms-key-id requires --sse=aws:kms"},
+		},
+		{
+			name:         "options ignored by the driver",
+			mountOptions: []string{"storage-class=GLACIER", "profile=default", "endpoint-url=https://s3.example.com"},
+			wantWarnings: []string{
+				`--endpoint-url is ignored: "https://s3.example.com" is not in the driver's allowed endpoint URLs`,
+				"--profile is ignored: only static keys are supported by the CSI driver",
+				"--storage-class is ignored: only STANDARD is supported by the CSI driver",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			warnings, err := mountpoint.ValidateMountOptions(tc.mountOptions, allowed)
+			assert.Equals(t, tc.wantWarnings, warnings)
+			if len(tc.wantErrors) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			if err == nil {
+				t.Fatalf("Expected errors %v, got nil", tc.wantErrors)
+			}
+			for _, want := range tc.wantErrors {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Expected error to contain %q, got %q", want, err.Error())
+				}
+			}
+		})
+	}
+}
+
+func TestIsAllowedEndpointURL(t *testing.T) {
+	allowed := []string{"https://s3.site-b.example.com/", " http://10.0.0.1:8000"}
+
+	assert.Equals(t, true, mountpoint.IsAllowedEndpointURL("HTTPS://S3.Site-B.example.com", allowed))
+	assert.Equals(t, true, mountpoint.IsAllowedEndpointURL("http://10.0.0.1:8000/", allowed))
+	assert.Equals(t, false, mountpoint.IsAllowedEndpointURL("http://s3.site-b.example.com", allowed))
+	assert.Equals(t, false, mountpoint.IsAllowedEndpointURL("https://user@s3.site-b.example.com", allowed))
+	assert.Equals(t, false, mountpoint.IsAllowedEndpointURL("https://s3.site-b.example.com", nil))
+}
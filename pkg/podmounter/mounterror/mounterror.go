@@ -0,0 +1,122 @@
+// Package mounterror provides the structured errors Mountpoint Pods write to `mount.err` when they fail to mount, to
+// let the CSI Driver Node Pod report why a mount failed.
+package mounterror
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// A Phase is the step of a Mountpoint Pod that failed.
+type Phase string
+
+const (
+	// PhaseSetup is the preparation of Mountpoint, e.g. finding and verifying its binary.
+	PhaseSetup Phase = "setup"
+	// PhaseMount is Mountpoint mounting the bucket.
+	PhaseMount Phase = "mount"
+)
+
+// A Classification is the most likely root cause of a mount failure.
+type Classification string
+
+const (
+	ClassificationBinary            Classification = "BINARY"
+	ClassificationCredentials       Classification = "CREDENTIALS"
+	ClassificationAccessDenied      Classification = "ACCESS_DENIED"
+	ClassificationBucketNotFound    Classification = "BUCKET_NOT_FOUND"
+	ClassificationTLS               Classification = "TLS"
+	ClassificationEndpoint          Classification = "ENDPOINT"
+	ClassificationUnsupportedOption Classification = "UNSUPPORTED_OPTION"
+	ClassificationUnknown           Classification = "UNKNOWN"
+)
+
+// classificationPatterns maps lowercase substrings of error output to their classification, checked in order.
+var classificationPatterns = []struct {
+	classification Classification
+	patterns       []string
+}{
+	{ClassificationBinary, []string{"integrity verification of the mountpoint binary failed", "no mountpoint binary for platform"}},
+	{ClassificationUnsupportedOption, []string{"mount options not supported by mountpoint"}},
+	{ClassificationCredentials, []string{"invalidaccesskeyid", "signaturedoesnotmatch", "no credentials"}},
+	{ClassificationAccessDenied, []string{"accessdenied", "access denied", "forbidden", "403"}},
+	{ClassificationBucketNotFound, []string{"nosuchbucket", "bucket does not exist"}},
+	{ClassificationTLS, []string{"certificate", "tls"}},
+	{ClassificationEndpoint, []string{"dns error", "connection refused", "timed out", "failed to connect", "no route to host"}},
+}
+
+// MaxOutputLength is the maximum length of the error output kept in an [Error]. Mount errors are returned in gRPC
+// status messages and Kubernetes events, which have limited sizes.
+const MaxOutputLength = 4096
+
+// An Error is a mount failure of a Mountpoint Pod, written as JSON to `mount.err`.
+type Error struct {
+	Phase Phase `json:"phase,omitempty"`
+	// ExitCode is the exit code of Mountpoint, zero if it did not run.
+	ExitCode       int            `json:"exitCode"`
+	Classification Classification `json:"classification"`
+	// Output is the tail of the error output of Mountpoint, or the error of the failed phase.
+	Output string `json:"output"`
+}
+
+// New returns the [Error] of a failed `phase` with error output `output`, classified from its output.
+func New(phase Phase, exitCode int, output []byte) *Error {
+	tail := truncate(output)
+	return &Error{Phase: phase, ExitCode: exitCode, Classification: Classify(tail), Output: tail}
+}
+
+// Error returns the classification and output of the error.
+func (e *Error) Error() string {
+	message := fmt.Sprintf("%s error", e.Classification)
+	if e.Phase != "" {
+		message = fmt.Sprintf("%s error during %s", e.Classification, e.Phase)
+	}
+	if e.ExitCode != 0 {
+		message += fmt.Sprintf(" (exit code %d)", e.ExitCode)
+	}
+	return message + ": " + e.Output
+}
+
+// Write writes `e` to `path`.
+func (e *Error) Write(path string) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// Parse parses the content of `mount.err`. Free-form error output written by Mountpoint Pods of older versions of the
+// CSI driver is classified from its text.
+func Parse(data []byte) *Error {
+	var e Error
+	if err := json.Unmarshal(data, &e); err == nil && e.Classification != "" {
+		e.Output = truncate([]byte(e.Output))
+		return &e
+	}
+	return New("", 0, data)
+}
+
+// Classify returns the classification of error output `output`.
+func Classify(output string) Classification {
+	output = strings.ToLower(output)
+	for _, p := range classificationPatterns {
+		for _, pattern := range p.patterns {
+			if strings.Contains(output, pattern) {
+				return p.classification
+			}
+		}
+	}
+	return ClassificationUnknown
+}
+
+// truncate returns `output`, truncated to [MaxOutputLength] while keeping its end, as the actual failure reason is
+// usually logged last.
+func truncate(output []byte) string {
+	if len(output) <= MaxOutputLength {
+		return string(output)
+	}
+	return "... (truncated) " + strings.ToValidUTF8(string(output[len(output)-MaxOutputLength:]), "")
+}
@@ -0,0 +1,72 @@
+package mounterror_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mounterror"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		output string
+		want   mounterror.Classification
+	}{
+		{"Error: Failed to create S3 client: InvalidAccessKeyId", mounterror.ClassificationCredentials},
+		{"Error: Failed to create mount process: HeadBucket failed: AccessDenied", mounterror.ClassificationAccessDenied},
+		{"Error: Failed to create mount process: bucket does not exist", mounterror.ClassificationBucketNotFound},
+		{"Error: client error: dns error: failed to lookup address", mounterror.ClassificationEndpoint},
+		{"Error: TLS negotiation failed: certificate not trusted", mounterror.ClassificationTLS},
+		{"integrity verification of the Mountpoint binary failed: no expected digest", mounterror.ClassificationBinary},
+		{"no Mountpoint binary for platform linux-arm64", mounterror.ClassificationBinary},
+		{"mount options not supported by Mountpoint 1.0.0: --sse requires Mountpoint 1.4.0 or later", mounterror.ClassificationUnsupportedOption},
+		{"Error: something unexpected", mounterror.ClassificationUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.want), func(t *testing.T) {
+			assert.Equals(t, tt.want, mounterror.Classify(tt.output))
+		})
+	}
+}
+
+func TestWriteAndParse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mount.err")
+	written := mounterror.New(mounterror.PhaseMount, 2, []byte("Error: NoSuchBucket"))
+	assert.NoError(t, written.Write(path))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	parsed := mounterror.Parse(data)
+	assert.Equals(t, *written, *parsed)
+	assert.Equals(t, mounterror.ClassificationBucketNotFound, parsed.Classification)
+	assert.Equals(t, "BUCKET_NOT_FOUND error during mount (exit code 2): Error: NoSuchBucket", parsed.Error())
+}
+
+func TestParseFreeFormOutput(t *testing.T) {
+	// Written by Mountpoint Pods of older versions of the CSI driver
+	parsed := mounterror.Parse([]byte("Error: Failed to create S3 client: SignatureDoesNotMatch"))
+	assert.Equals(t, mounterror.Error{
+		ExitCode:       0,
+		Classification: mounterror.ClassificationCredentials,
+		Output:         "Error: Failed to create S3 client: SignatureDoesNotMatch",
+	}, *parsed)
+	assert.Equals(t, "CREDENTIALS error: Error: Failed to create S3 client: SignatureDoesNotMatch", parsed.Error())
+
+	// JSON without a classification is not a structured error
+	parsed = mounterror.Parse([]byte(`{"message": "access denied"}`))
+	assert.Equals(t, mounterror.ClassificationAccessDenied, parsed.Classification)
+}
+
+func TestTruncatesOutput(t *testing.T) {
+	parsed := mounterror.New(mounterror.PhaseMount, 1, []byte(strings.Repeat("x", 1024*1024)+"access denied"))
+	if len(parsed.Output) > mounterror.MaxOutputLength+len("... (truncated) ") {
+		t.Fatalf("Expected output to be truncated, got %d bytes", len(parsed.Output))
+	}
+	if !strings.HasSuffix(parsed.Output, "access denied") {
+		t.Fatalf("Expected truncated output to keep its end, got %q", parsed.Output[len(parsed.Output)-32:])
+	}
+	assert.Equals(t, mounterror.ClassificationAccessDenied, parsed.Classification)
+}
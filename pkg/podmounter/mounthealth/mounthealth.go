@@ -0,0 +1,66 @@
+// Package mounthealth provides the health status the CSI Driver Node Pod writes to `mount.health` when the mount of
+// a Mountpoint Pod stops responding, failing the liveness probe of the Mountpoint container so it gets restarted.
+package mounthealth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// StatusFilePerm is the permission of `mount.health`. It is written by the CSI Driver Node Pod and read by the
+// Mountpoint container through the group of the `emptyDir` volume, owned by the `fsGroup` of the Mountpoint Pod.
+const StatusFilePerm = fs.FileMode(0o640)
+
+// ErrUnhealthy is returned by [Check] when the mount of the Mountpoint Pod is reported unhealthy.
+var ErrUnhealthy = errors.New("mount of Mountpoint is unhealthy")
+
+// A Status is the health of the mount of a Mountpoint Pod, written as JSON to `mount.health`.
+type Status struct {
+	Healthy bool `json:"healthy"`
+	// Reason is why the mount is unhealthy.
+	Reason string `json:"reason,omitempty"`
+	// Since is when the mount was first found unhealthy.
+	Since time.Time `json:"since"`
+}
+
+// Write writes `s` to `path`.
+func (s Status) Write(path string) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, StatusFilePerm)
+}
+
+// Check returns an error wrapping [ErrUnhealthy] if `path` reports an unhealthy mount. Mounts without status are
+// healthy, as the status is only written once a mount stops responding.
+func Check(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read mount health %q: %w", path, err)
+	}
+	var status Status
+	if err := json.Unmarshal(data, &status); err != nil {
+		return fmt.Errorf("failed to parse mount health %q: %w", path, err)
+	}
+	if !status.Healthy {
+		return fmt.Errorf("%w since %s: %s", ErrUnhealthy, status.Since.Format(time.RFC3339), status.Reason)
+	}
+	return nil
+}
+
+// Reset removes the status at `path`, if any. It is called by a restarted Mountpoint container, whose mount is not
+// the one that stopped responding.
+func Reset(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package mounthealth_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mounthealth"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestCheck(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mount.health")
+
+	// No status, the mount is healthy
+	assert.NoError(t, mounthealth.Check(path))
+
+	assert.NoError(t, mounthealth.Status{Healthy: true}.Write(path))
+	assert.NoError(t, mounthealth.Check(path))
+
+	status := mounthealth.Status{Reason: "statfs did not respond within 10s", Since: time.Now()}
+	assert.NoError(t, status.Write(path))
+	err := mounthealth.Check(path)
+	if !errors.Is(err, mounthealth.ErrUnhealthy) || !strings.Contains(err.Error(), status.Reason) {
+		t.Fatalf("Expected ErrUnhealthy with reason, got %v", err)
+	}
+
+	assert.NoError(t, mounthealth.Reset(path))
+	assert.NoError(t, mounthealth.Check(path))
+	// Resetting a missing status is not an error
+	assert.NoError(t, mounthealth.Reset(path))
+}
+
+func TestCheckInvalidStatus(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mount.health")
+	assert.NoError(t, os.WriteFile(path, []byte("not json"), 0o600))
+	if err := mounthealth.Check(path); err == nil || errors.Is(err, mounthealth.ErrUnhealthy) {
+		t.Fatalf("Expected a parse error, got %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+// Package mountmetrics relays the metrics Mountpoint logs with `--log-metrics` from Mountpoint Pods to the CSI Driver
+// Node Pod. `scality-s3-csi-mounter` records them from the output of Mountpoint with a [Recorder], and periodically
+// writes a [Snapshot] to `mount.metrics`, which the CSI Driver Node Pod exports on its metrics endpoint.
+package mountmetrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/renameio"
+)
+
+// SnapshotFilePerm is the permission of `mount.metrics`. It is written by the Mountpoint container and read by the
+// CSI Driver Node Pod, running as root.
+const SnapshotFilePerm = fs.FileMode(0o640)
+
+// metricsTarget is the suffix of the log target of metric lines, e.g. `mountpoint_s3::metrics`.
+const metricsTarget = "::metrics: "
+
+// A Sample is the value of a metric of Mountpoint with a given set of labels.
+type Sample struct {
+	// Name of the metric in Mountpoint, e.g. `s3.requests`.
+	Name string `json:"name"`
+	// Labels of the sample, e.g. `op=GetObject` for `s3.requests[op=GetObject]`.
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  float64           `json:"value"`
+}
+
+// key returns a key identifying the metric and labels of `s`.
+func (s Sample) key() string {
+	var b strings.Builder
+	b.WriteString(s.Name)
+	for _, label := range slices.Sorted(maps.Keys(s.Labels)) {
+		fmt.Fprintf(&b, ",%s=%s", label, s.Labels[label])
+	}
+	return b.String()
+}
+
+// A Snapshot holds the metrics of Mountpoint recorded by a [Recorder], written as JSON to `mount.metrics`.
+type Snapshot struct {
+	// UpdatedAt is when a metric was last recorded.
+	UpdatedAt time.Time `json:"updatedAt"`
+	// Counters are totals since the Mountpoint container started. Mountpoint logs the increase of its counters since
+	// its previous metric lines, which are summed up.
+	Counters []Sample `json:"counters,omitempty"`
+	// Gauges are the last values logged by Mountpoint.
+	Gauges []Sample `json:"gauges,omitempty"`
+}
+
+// Write atomically writes `s` to `path`, so readers never see a partial snapshot.
+func (s Snapshot) Write(path string) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return renameio.WriteFile(path, data, SnapshotFilePerm)
+}
+
+// Read returns the snapshot written at `path`.
+func Read(path string) (Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to parse metrics snapshot %q: %w", path, err)
+	}
+	return snapshot, nil
+}
+
+// A Recorder consumes Mountpoint's output and records the counters and gauges of its metric lines. Histograms are
+// not recorded.
+type Recorder struct {
+	mu        sync.Mutex
+	partial   []byte
+	updatedAt time.Time
+	counters  map[string]Sample
+	gauges    map[string]Sample
+	now       func() time.Time
+}
+
+// NewRecorder creates a new empty [Recorder].
+func NewRecorder() *Recorder {
+	return &Recorder{
+		counters: make(map[string]Sample),
+		gauges:   make(map[string]Sample),
+		now:      time.Now,
+	}
+}
+
+// Write implements [io.Writer].
+func (r *Recorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.partial = append(r.partial, p...)
+	for {
+		idx := bytes.IndexByte(r.partial, '\n')
+		if idx == -1 {
+			break
+		}
+		r.recordLine(string(r.partial[:idx]))
+		r.partial = r.partial[idx+1:]
+	}
+	return len(p), nil
+}
+
+// recordLine records the metric of `line`, if it is a counter or gauge line, `r.mu` must be held.
+func (r *Recorder) recordLine(line string) {
+	sample, counter, ok := parseLine(line)
+	if !ok {
+		return
+	}
+	key := sample.key()
+	if counter {
+		sample.Value += r.counters[key].Value
+		r.counters[key] = sample
+	} else {
+		r.gauges[key] = sample
+	}
+	r.updatedAt = r.now()
+}
+
+// Snapshot returns the metrics recorded so far, sorted by name and labels. It is empty if none was recorded.
+func (r *Recorder) Snapshot() Snapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return Snapshot{
+		UpdatedAt: r.updatedAt,
+		Counters:  sortedSamples(r.counters),
+		Gauges:    sortedSamples(r.gauges),
+	}
+}
+
+func sortedSamples(samples map[string]Sample) []Sample {
+	var sorted []Sample
+	for _, key := range slices.Sorted(maps.Keys(samples)) {
+		sorted = append(sorted, samples[key])
+	}
+	return sorted
+}
+
+// parseLine parses metric lines of Mountpoint formatted as `... <target>::metrics: <name>[<labels>]: <value>`.
+// Counters are logged as `<value> (n=<count>)`, gauges as `<value>`, and histograms as `n=<count>: ...`.
+func parseLine(line string) (Sample, bool, bool) {
+	idx := strings.Index(line, metricsTarget)
+	if idx == -1 {
+		return Sample{}, false, false
+	}
+	metric, value, ok := strings.Cut(line[idx+len(metricsTarget):], ": ")
+	if !ok || strings.HasPrefix(value, "n=") {
+		return Sample{}, false, false
+	}
+
+	counter := false
+	if before, _, found := strings.Cut(value, " (n="); found {
+		value = before
+		counter = true
+	}
+	parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return Sample{}, false, false
+	}
+
+	sample := Sample{Name: metric, Value: parsed}
+	if name, labels, found := strings.Cut(metric, "["); found {
+		labels, closed := strings.CutSuffix(labels, "]")
+		if !closed {
+			return Sample{}, false, false
+		}
+		sample.Name = name
+		sample.Labels = make(map[string]string)
+		for _, label := range strings.Split(labels, ",") {
+			key, value, ok := strings.Cut(label, "=")
+			if !ok || key == "" {
+				return Sample{}, false, false
+			}
+			sample.Labels[key] = value
+		}
+	}
+	return sample, counter, true
+}
@@ -0,0 +1,55 @@
+package mountmetrics_test
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mountmetrics"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestRecorder(t *testing.T) {
+	recorder := mountmetrics.NewRecorder()
+	assert.Equals(t, true, recorder.Snapshot().UpdatedAt.IsZero())
+
+	// Lines can be split across writes
+	_, err := recorder.Write([]byte("2025-01-01T00:00:00.000000Z  INFO mountpoint_s3::metrics: s3.requests[op=GetObject,type=Default]: 12 (n=12)\n" +
+		"2025-01-01T00:00:00.000000Z  INFO mountpoint_s3::metrics: s3.client.num_requests_being_processed: 3\n" +
+		"2025-01-01T00:00:00.000000Z  INFO mountpoint_s3::metrics: fuse.op_latency_us[op=read]: n=4: min=1 p10=1 p50=2 avg=2.0 p90=3 p99=3 p99.9=3 max=3\n" +
+		"2025-01-01T00:00:00.000000Z  INFO mountpoint_s3::fs: not a metric: 1\n" +
+		"2025-01-01T00:00:05.000000Z  INFO mountpoint_s3::metrics: s3.requests[op=GetObject,type=Default]: 8 (n=8)\n" +
+		"2025-01-01T00:00:05.000000Z  INFO mountpoint_s3::metrics: s3.client.num_requests_being"))
+	assert.NoError(t, err)
+	_, err = recorder.Write([]byte("_processed: 1\n2025-01-01T00:00:05.000000Z  INFO mountpoint_s3::metrics: s3.requests.failures[op=PutObject,status=503]: 2 (n=2)\n"))
+	assert.NoError(t, err)
+
+	snapshot := recorder.Snapshot()
+	assert.Equals(t, false, snapshot.UpdatedAt.IsZero())
+	assert.Equals(t, []mountmetrics.Sample{
+		{Name: "s3.requests", Labels: map[string]string{"op": "GetObject", "type": "Default"}, Value: 20},
+		{Name: "s3.requests.failures", Labels: map[string]string{"op": "PutObject", "status": "503"}, Value: 2},
+	}, snapshot.Counters)
+	assert.Equals(t, []mountmetrics.Sample{
+		{Name: "s3.client.num_requests_being_processed", Value: 1},
+	}, snapshot.Gauges)
+}
+
+func TestSnapshotWriteAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mount.metrics")
+	if _, err := mountmetrics.Read(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Expected a missing snapshot, got %v", err)
+	}
+
+	recorder := mountmetrics.NewRecorder()
+	_, err := recorder.Write([]byte("INFO mountpoint_s3::metrics: s3.requests[op=ListObjectsV2]: 5 (n=5)\n"))
+	assert.NoError(t, err)
+	snapshot := recorder.Snapshot()
+	assert.NoError(t, snapshot.Write(path))
+
+	got, err := mountmetrics.Read(path)
+	assert.NoError(t, err)
+	assert.Equals(t, true, snapshot.UpdatedAt.Equal(got.UpdatedAt))
+	assert.Equals(t, snapshot.Counters, got.Counters)
+}
@@ -0,0 +1,165 @@
+package mountoptions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// A CredentialFile is a credential file in the credentials directory of a Mountpoint Pod.
+type CredentialFile struct {
+	// Name of the file in the credentials directory, it cannot contain a path separator.
+	Name string      `json:"name"`
+	Data []byte      `json:"data"`
+	Perm fs.FileMode `json:"perm"`
+}
+
+// A CredentialUpdate is sent by the CSI Driver Node Pod to a running Mountpoint Pod on its control socket, with
+// [SendCredentialUpdate], when credentials of its mount are refreshed. The Mountpoint Pod atomically rewrites the files
+// in its credentials directory, where Mountpoint reads them from its AWS profile.
+type CredentialUpdate struct {
+	Files []CredentialFile `json:"files"`
+}
+
+// A controlReply is the reply of the Mountpoint Pod to a message on its control socket.
+type controlReply struct {
+	Error string `json:"error,omitempty"`
+}
+
+// controlReplyTimeout bounds the handling of a message on the control socket, after which it is abandoned.
+const controlReplyTimeout = 10 * time.Second
+
+// SendCredentialUpdate sends `update` to the control socket at `sockPath` served by [ServeControl], and returns the
+// error of the Mountpoint Pod applying it, if any.
+func SendCredentialUpdate(ctx context.Context, sockPath string, update CredentialUpdate) error {
+	sockPath = tryToMakeSockPathRelative(sockPath)
+
+	message, err := json.Marshal(&update)
+	if err != nil {
+		return fmt.Errorf("failed to marshal credential update to send %s: %w", sockPath, err)
+	}
+	if len(message) > MaxMessageSize {
+		return fmt.Errorf("failed to send credential update to %s: %w: %d bytes, maximum is %d bytes", sockPath, ErrMessageTooLarge, len(message), MaxMessageSize)
+	}
+
+	// The control socket is served as long as Mountpoint runs, it is not retried if it does not exist
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", sockPath)
+	if err != nil {
+		return fmt.Errorf("failed to dial to unix socket %s: %w", sockPath, err)
+	}
+	unixConn := conn.(*net.UnixConn)
+	defer func() {
+		if closeErr := unixConn.Close(); closeErr != nil {
+			klog.Errorf("failed to close unix connection: %v", closeErr)
+		}
+	}()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(controlReplyTimeout)
+	}
+	if err := unixConn.SetDeadline(deadline); err != nil {
+		return fmt.Errorf("failed to set deadline on unix socket %s: %w", sockPath, err)
+	}
+	defer interruptOnDone(ctx, unixConn)()
+
+	if _, err := unixConn.Write(message); err != nil {
+		return fmt.Errorf("failed to write to unix socket %s: %w", sockPath, contextError(ctx, err))
+	}
+	if err := unixConn.CloseWrite(); err != nil {
+		return fmt.Errorf("failed to close unix socket %s for writing: %w", sockPath, err)
+	}
+
+	var reply controlReply
+	data, err := io.ReadAll(io.LimitReader(unixConn, maxHandshakeSize))
+	if err == nil {
+		err = json.Unmarshal(data, &reply)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read reply from unix socket %s: %w", sockPath, contextError(ctx, err))
+	}
+	if reply.Error != "" {
+		return fmt.Errorf("mountpoint Pod failed to apply credential update: %s", reply.Error)
+	}
+	return nil
+}
+
+// ServeControl serves the control socket at `sockPath` until `ctx` is done, applying credential updates received from
+// [SendCredentialUpdate] with `handle`. Messages are handled one at a time.
+func ServeControl(ctx context.Context, sockPath string, handle func(CredentialUpdate) error) error {
+	sockPath = tryToMakeSockPathRelative(sockPath)
+
+	// A previous Mountpoint container might have left its socket
+	if err := os.Remove(sockPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove unix socket %s: %w", sockPath, err)
+	}
+
+	var lc net.ListenConfig
+	l, err := lc.Listen(ctx, "unix", sockPath)
+	if err != nil {
+		return fmt.Errorf("failed to listen unix socket %s: %w", sockPath, err)
+	}
+	go func() {
+		<-ctx.Done()
+		if closeErr := l.Close(); closeErr != nil {
+			klog.Errorf("failed to close unix listener: %v", closeErr)
+		}
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to accept connection from unix socket %s: %w", sockPath, err)
+		}
+		serveControlConn(conn.(*net.UnixConn), handle)
+	}
+}
+
+// serveControlConn reads a credential update from `conn`, applies it with `handle`, and replies with its outcome.
+func serveControlConn(conn *net.UnixConn, handle func(CredentialUpdate) error) {
+	defer func() {
+		if closeErr := conn.Close(); closeErr != nil {
+			klog.Errorf("failed to close unix connection: %v", closeErr)
+		}
+	}()
+	if err := conn.SetDeadline(time.Now().Add(controlReplyTimeout)); err != nil {
+		klog.Errorf("failed to set deadline on control connection: %v", err)
+		return
+	}
+
+	var reply controlReply
+	data, err := io.ReadAll(io.LimitReader(conn, MaxMessageSize+1))
+	switch {
+	case err != nil:
+		reply.Error = fmt.Sprintf("failed to read message: %v", err)
+	case len(data) > MaxMessageSize:
+		reply.Error = ErrMessageTooLarge.Error()
+	default:
+		var update CredentialUpdate
+		if err := json.Unmarshal(data, &update); err != nil {
+			reply.Error = fmt.Sprintf("failed to decode credential update: %v", err)
+		} else if err := handle(update); err != nil {
+			reply.Error = err.Error()
+		}
+	}
+
+	message, err := json.Marshal(&reply)
+	if err == nil {
+		_, err = conn.Write(message)
+	}
+	if err != nil {
+		klog.Errorf("failed to reply on control connection: %v", err)
+	}
+}
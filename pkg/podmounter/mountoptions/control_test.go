@@ -0,0 +1,70 @@
+package mountoptions_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mountoptions"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestControlSocket(t *testing.T) {
+	controlSock := filepath.Join(t.TempDir(), "c")
+
+	// The control socket is not served yet
+	err := mountoptions.SendCredentialUpdate(defaultContext(t), controlSock, mountoptions.CredentialUpdate{})
+	assert.Equals(t, true, err != nil)
+
+	updates := make(chan mountoptions.CredentialUpdate, 2)
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- mountoptions.ServeControl(ctx, controlSock, func(update mountoptions.CredentialUpdate) error {
+			if update.Files[0].Name == "invalid" {
+				return errors.New("invalid credential file")
+			}
+			updates <- update
+			return nil
+		})
+	}()
+
+	want := mountoptions.CredentialUpdate{Files: []mountoptions.CredentialFile{
+		{Name: "vol-s3-csi-credentials", Data: []byte("[profile]\naws_access_key_id=test\n"), Perm: 0o640},
+	}}
+	// The control socket serves any number of updates
+	for range 2 {
+		waitForControlSocket(t, controlSock, want)
+		assert.Equals(t, want, <-updates)
+	}
+
+	// Errors of the Mountpoint Pod are returned to the sender
+	err = mountoptions.SendCredentialUpdate(defaultContext(t), controlSock, mountoptions.CredentialUpdate{
+		Files: []mountoptions.CredentialFile{{Name: "invalid"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "invalid credential file") {
+		t.Fatalf("Expected error of the Mountpoint Pod, got %v", err)
+	}
+
+	cancel()
+	assert.NoError(t, <-served)
+}
+
+// waitForControlSocket sends `update` to `controlSock` once it is served.
+func waitForControlSocket(t *testing.T, controlSock string, update mountoptions.CredentialUpdate) {
+	t.Helper()
+	ctx := defaultContext(t)
+	for {
+		err := mountoptions.SendCredentialUpdate(ctx, controlSock, update)
+		if err == nil {
+			return
+		}
+		if ctx.Err() != nil {
+			t.Fatalf("Failed to send credential update: %v", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,147 @@
+package mountoptions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// ProtocolVersion is the version of the mount options protocol implemented by this package. It is increased on every
+// change of the protocol. Changes must be backward-compatible: each end handles messages of older versions, and ignores
+// fields and capabilities it does not know of newer versions.
+//
+// Version 0 is the protocol without handshake: the sender does not advertise its version, and the receiver does not
+// reply.
+const ProtocolVersion = 1
+
+// Capabilities is a bitmask of optional features supported by an end of the mount options protocol.
+type Capabilities uint64
+
+const (
+	// CapabilityStructuredErrors is set by Mountpoint Pods writing errors to `mount.err` as JSON.
+	CapabilityStructuredErrors Capabilities = 1 << iota
+	// CapabilityReconnect is set by ends supporting a new FUSE file descriptor to be passed on the reconnect socket
+	// after Mountpoint crashes.
+	CapabilityReconnect
+	// CapabilityCredentialRefresh is set by ends supporting refreshed credentials to be pushed to running Mountpoint
+	// Pods with [SendCredentialUpdate].
+	CapabilityCredentialRefresh
+)
+
+// localCapabilities are the capabilities implemented by this package's users.
+const localCapabilities = CapabilityStructuredErrors | CapabilityReconnect | CapabilityCredentialRefresh
+
+var capabilityNames = []struct {
+	capability Capabilities
+	name       string
+}{
+	{CapabilityStructuredErrors, "structured-errors"},
+	{CapabilityReconnect, "reconnect"},
+	{CapabilityCredentialRefresh, "credential-refresh"},
+}
+
+// Has returns whether all of `capabilities` are set in `c`.
+func (c Capabilities) Has(capabilities Capabilities) bool {
+	return c&capabilities == capabilities
+}
+
+// String returns the comma-separated names of `c`, unknown capabilities of newer versions are printed as a bitmask.
+func (c Capabilities) String() string {
+	var names []string
+	for _, n := range capabilityNames {
+		if c.Has(n.capability) {
+			names = append(names, n.name)
+			c &^= n.capability
+		}
+	}
+	if c != 0 {
+		names = append(names, fmt.Sprintf("%#x", uint64(c)))
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ",")
+}
+
+// A Handshake describes the protocol version and capabilities of an end of the mount options protocol. The sender
+// advertises its handshake with the mount options in [Send], and the receiver replies with its own in [Recv].
+type Handshake struct {
+	Version      int          `json:"version"`
+	Capabilities Capabilities `json:"capabilities"`
+}
+
+// LocalHandshake returns the handshake of this end of the protocol.
+func LocalHandshake() Handshake {
+	return Handshake{Version: ProtocolVersion, Capabilities: localCapabilities}
+}
+
+// Legacy returns whether `h` is the handshake of an end not implementing it, i.e. of protocol version 0 without any
+// capability.
+func (h Handshake) Legacy() bool {
+	return h.Version == 0
+}
+
+// Supports returns whether the end of `h` supports all of `capabilities`.
+func (h Handshake) Supports(capabilities Capabilities) bool {
+	return h.Capabilities.Has(capabilities)
+}
+
+func (h Handshake) String() string {
+	return fmt.Sprintf("version %d with capabilities %s", h.Version, h.Capabilities)
+}
+
+// handshakeReplyTimeout bounds the wait for the handshake reply of the receiver in [Exchange]. Receivers of protocol
+// version 0 never reply, and might not close the connection.
+const handshakeReplyTimeout = time.Second
+
+// maxHandshakeSize is the maximum size in bytes of a serialized handshake reply.
+const maxHandshakeSize = 4 * 1024
+
+// readHandshakeReply reads the handshake reply of the receiver on `conn`. A receiver closing the connection or not
+// replying within [handshakeReplyTimeout] implements protocol version 0.
+func readHandshakeReply(conn *net.UnixConn, deadline time.Time) (Handshake, error) {
+	if replyDeadline := time.Now().Add(handshakeReplyTimeout); deadline.IsZero() || replyDeadline.Before(deadline) {
+		deadline = replyDeadline
+	}
+	if err := conn.SetReadDeadline(deadline); err != nil {
+		return Handshake{}, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(conn, maxHandshakeSize+1))
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() && len(data) == 0 {
+		return Handshake{}, nil
+	}
+	if err != nil {
+		return Handshake{}, err
+	}
+	if len(data) == 0 {
+		return Handshake{}, nil
+	}
+	if len(data) > maxHandshakeSize {
+		return Handshake{}, fmt.Errorf("handshake reply is larger than %d bytes", maxHandshakeSize)
+	}
+
+	var handshake Handshake
+	if err := json.Unmarshal(data, &handshake); err != nil {
+		return Handshake{}, fmt.Errorf("failed to decode handshake reply: %w", err)
+	}
+	return handshake, nil
+}
+
+// writeHandshakeReply replies with the local handshake on `conn`.
+func writeHandshakeReply(conn *net.UnixConn, deadline time.Time) error {
+	data, err := json.Marshal(LocalHandshake())
+	if err != nil {
+		return err
+	}
+	if err := conn.SetWriteDeadline(deadline); err != nil {
+		return err
+	}
+	_, err = conn.Write(data)
+	return err
+}
@@ -0,0 +1,174 @@
+package mountoptions_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mountoptions"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestHandshake(t *testing.T) {
+	t.Run("Receives mount options of senders without handshake", func(t *testing.T) {
+		mountSock := filepath.Join(t.TempDir(), "m")
+		file, err := os.Open(os.DevNull)
+		assert.NoError(t, err)
+		defer file.Close()
+
+		// A sender of protocol version 0, closing the connection once mount options are sent
+		fd := int(file.Fd())
+		sent := make(chan struct{})
+		go func() {
+			defer close(sent)
+			conn, err := net.Dial("unix", mountSock)
+			for err != nil {
+				time.Sleep(5 * time.Millisecond)
+				conn, err = net.Dial("unix", mountSock)
+			}
+			message, _ := json.Marshal(map[string]any{"bucketName": "test-bucket", "args": []string{"--read-only"}})
+			_, _, _ = conn.(*net.UnixConn).WriteMsgUnix(message, syscall.UnixRights(fd), nil)
+			_ = conn.Close()
+		}()
+
+		options, err := mountoptions.Recv(defaultContext(t), mountSock)
+		assert.NoError(t, err)
+		<-sent
+		defer syscall.Close(options.Fd)
+		assert.Equals(t, "test-bucket", options.BucketName)
+		assert.Equals(t, []string{"--read-only"}, options.Args)
+		assert.Equals(t, true, options.Handshake.Legacy())
+		assert.Equals(t, false, options.Handshake.Supports(mountoptions.CapabilityReconnect))
+	})
+
+	t.Run("Sends mount options to receivers without handshake", func(t *testing.T) {
+		mountSock := filepath.Join(t.TempDir(), "m")
+		file, err := os.Open(os.DevNull)
+		assert.NoError(t, err)
+		defer file.Close()
+
+		l, err := net.Listen("unix", mountSock)
+		assert.NoError(t, err)
+		defer l.Close()
+
+		// A receiver of protocol version 0, reading mount options until the end of the connection without replying
+		received := make(chan map[string]any, 1)
+		go func() {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			message := make([]byte, mountoptions.MaxMessageSize)
+			oob := make([]byte, syscall.CmsgSpace(4))
+			var data []byte
+			for {
+				n, _, _, _, err := conn.(*net.UnixConn).ReadMsgUnix(message, oob)
+				data = append(data, message[:n]...)
+				if err == io.EOF || n == 0 {
+					break
+				}
+			}
+			var options map[string]any
+			_ = json.Unmarshal(data, &options)
+			received <- options
+		}()
+
+		peer, err := mountoptions.Exchange(defaultContext(t), mountSock, mountoptions.Options{
+			Fd:         int(file.Fd()),
+			BucketName: "test-bucket",
+		})
+		assert.NoError(t, err)
+		assert.Equals(t, true, peer.Legacy())
+		assert.Equals(t, "test-bucket", (<-received)["bucketName"])
+	})
+}
+
+func TestHandshakeCancellation(t *testing.T) {
+	t.Run("Sender gives up waiting for the reply once cancelled", func(t *testing.T) {
+		mountSock := filepath.Join(t.TempDir(), "m")
+		file, err := os.Open(os.DevNull)
+		assert.NoError(t, err)
+		defer file.Close()
+
+		l, err := net.Listen("unix", mountSock)
+		assert.NoError(t, err)
+		defer l.Close()
+
+		// A receiver reading mount options and then hanging, neither replying nor closing the connection
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			_, _ = io.Copy(io.Discard, conn)
+			<-done
+		}()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(100*time.Millisecond, cancel)
+		start := time.Now()
+		_, err = mountoptions.Exchange(ctx, mountSock, mountoptions.Options{Fd: int(file.Fd()), BucketName: "test-bucket"})
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected a cancellation error, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed >= time.Second {
+			t.Fatalf("Expected the exchange to be interrupted by the cancellation, it took %v", elapsed)
+		}
+	})
+
+	t.Run("Receiver gives up reading a partial message once cancelled", func(t *testing.T) {
+		mountSock := filepath.Join(t.TempDir(), "m")
+
+		// A sender writing part of its mount options and then hanging
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			conn, err := net.Dial("unix", mountSock)
+			for err != nil {
+				time.Sleep(5 * time.Millisecond)
+				conn, err = net.Dial("unix", mountSock)
+			}
+			defer conn.Close()
+			_, _ = conn.Write([]byte(`{"bucketName": "test-`))
+			<-done
+		}()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(100*time.Millisecond, cancel)
+		_, err := mountoptions.Recv(ctx, mountSock)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected a cancellation error, got %v", err)
+		}
+	})
+
+	t.Run("Receiver gives up waiting for a sender once cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(100*time.Millisecond, cancel)
+		_, err := mountoptions.Recv(ctx, filepath.Join(t.TempDir(), "m"))
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected a cancellation error, got %v", err)
+		}
+	})
+}
+
+func TestCapabilities(t *testing.T) {
+	capabilities := mountoptions.CapabilityStructuredErrors | mountoptions.CapabilityCredentialRefresh
+	assert.Equals(t, true, capabilities.Has(mountoptions.CapabilityStructuredErrors))
+	assert.Equals(t, false, capabilities.Has(mountoptions.CapabilityReconnect))
+	assert.Equals(t, false, capabilities.Has(mountoptions.CapabilityStructuredErrors|mountoptions.CapabilityReconnect))
+	assert.Equals(t, "structured-errors,credential-refresh", capabilities.String())
+	assert.Equals(t, "none", mountoptions.Capabilities(0).String())
+	// Capabilities of newer protocol versions
+	assert.Equals(t, "reconnect,0x100", (mountoptions.CapabilityReconnect | 1<<8).String())
+}
@@ -18,6 +18,13 @@ import (
 	"k8s.io/klog/v2"
 )
 
+// MaxMessageSize is the maximum size in bytes of serialized mount options accepted by [Send] and [Recv].
+// It is well above the size of mount options allowed for a volume, and bounds the memory used by [Recv].
+const MaxMessageSize = 256 * 1024
+
+// ErrMessageTooLarge is returned when serialized mount options exceed [MaxMessageSize].
+var ErrMessageTooLarge = errors.New("mount options message is too large")
+
 // An Options struct represents mount options to use while invoking Mountpoint.
 type Options struct {
 	// Fd will be passed over Unix socket using `SCM_RIGHTS`, not as part of the serialized JSON.
@@ -25,20 +32,34 @@ type Options struct {
 	BucketName string   `json:"bucketName"`
 	Args       []string `json:"args"`
 	Env        []string `json:"env"`
+	// Handshake of the sender, set by [Send]. Zero for senders of protocol version 0.
+	Handshake Handshake `json:"handshake"`
 }
 
 // Send sends given mount `options` to given `sockPath` to be received by `Recv` function on the other end.
 func Send(ctx context.Context, sockPath string, options Options) error {
+	_, err := Exchange(ctx, sockPath, options)
+	return err
+}
+
+// Exchange sends given mount `options` to given `sockPath` like [Send], advertising the local [Handshake], and returns
+// the handshake replied by [Recv] on the other end. The returned handshake is zero if the other end implements
+// protocol version 0.
+func Exchange(ctx context.Context, sockPath string, options Options) (Handshake, error) {
 	sockPath = tryToMakeSockPathRelative(sockPath)
 
+	options.Handshake = LocalHandshake()
 	message, err := json.Marshal(&options)
 	if err != nil {
-		return fmt.Errorf("failed to marshal message to send %s: %w", sockPath, err)
+		return Handshake{}, fmt.Errorf("failed to marshal message to send %s: %w", sockPath, err)
+	}
+	if len(message) > MaxMessageSize {
+		return Handshake{}, fmt.Errorf("failed to send mount options to %s: %w: %d bytes, maximum is %d bytes", sockPath, ErrMessageTooLarge, len(message), MaxMessageSize)
 	}
 
 	unixConn, err := dialWithRetry(ctx, sockPath)
 	if err != nil {
-		return fmt.Errorf("failed to dial to unix socket %s: %w", sockPath, err)
+		return Handshake{}, fmt.Errorf("failed to dial to unix socket %s: %w", sockPath, err)
 	}
 	defer func() {
 		if closeErr := unixConn.Close(); closeErr != nil {
@@ -48,24 +69,41 @@ func Send(ctx context.Context, sockPath string, options Options) error {
 	}()
 
 	// `unixConn.WriteMsgUnix` does not respect `ctx`'s deadline, we need to call `unixConn.SetDeadline` to ensure `unixConn.WriteMsgUnix` has a deadline.
-	if deadline, ok := ctx.Deadline(); ok {
+	deadline, ok := ctx.Deadline()
+	if ok {
 		err := unixConn.SetDeadline(deadline)
 		if err != nil {
-			return fmt.Errorf("failed to set deadline on unix socket %s: %w", sockPath, err)
+			return Handshake{}, fmt.Errorf("failed to set deadline on unix socket %s: %w", sockPath, err)
 		}
 	}
+	defer interruptOnDone(ctx, unixConn)()
 
 	unixRights := syscall.UnixRights(options.Fd)
 	messageN, unixRightsN, err := unixConn.WriteMsgUnix(message, unixRights, nil)
 	if err != nil {
-		return fmt.Errorf("failed to write to unix socket %s: %w", sockPath, err)
+		return Handshake{}, fmt.Errorf("failed to write to unix socket %s: %w", sockPath, contextError(ctx, err))
 	}
 	if len(message) != messageN || len(unixRights) != unixRightsN {
-		return fmt.Errorf("partial write to unix socket %s: message: size %d - written %d, unix rights: size %d - written %d",
+		return Handshake{}, fmt.Errorf("partial write to unix socket %s: message: size %d - written %d, unix rights: size %d - written %d",
 			sockPath, len(message), messageN, len(unixRights), unixRightsN)
 	}
 
-	return nil
+	// Signal the end of the message, the other end replies with its handshake once it read it
+	if err := unixConn.CloseWrite(); err != nil {
+		return Handshake{}, fmt.Errorf("failed to close unix socket %s for writing: %w", sockPath, err)
+	}
+
+	// Mount options are sent at this point, a missing or invalid reply is not an error unless the caller gave up on
+	// the mount: the receiver might not be serving it, the caller must clean up and retry it
+	peer, err := readHandshakeReply(unixConn, deadline)
+	if ctxErr := ctx.Err(); ctxErr != nil && (err != nil || peer.Legacy()) {
+		return Handshake{}, fmt.Errorf("interrupted while waiting for handshake reply from unix socket %s: %w", sockPath, contextError(ctx, ctxErr))
+	}
+	if err != nil {
+		klog.Warningf("Failed to read handshake reply from unix socket %s, assuming protocol version 0: %v", sockPath, err)
+		return Handshake{}, nil
+	}
+	return peer, nil
 }
 
 // unixSocketDialRetryInterval is the interval between retries on retryable errors in [dialWithRetry].
@@ -132,12 +170,27 @@ func Recv(ctx context.Context, sockPath string) (Options, error) {
 		}
 	}
 
+	stopInterruptingListener := interruptOnDone(ctx, l.(*net.UnixListener))
 	conn, err := l.Accept()
+	stopInterruptingListener()
 	if err != nil {
-		return Options{}, fmt.Errorf("failed to accept connection from unix socket %s: %w", sockPath, err)
+		return Options{}, fmt.Errorf("failed to accept connection from unix socket %s: %w", sockPath, contextError(ctx, err))
 	}
 
 	unixConn := conn.(*net.UnixConn)
+	defer func() {
+		if closeErr := unixConn.Close(); closeErr != nil {
+			klog.Errorf("failed to close unix connection: %v", closeErr)
+		}
+	}()
+
+	// The sender might stall before the end of its message, reads are bounded by `ctx` like the accept
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := unixConn.SetReadDeadline(deadline); err != nil {
+			return Options{}, fmt.Errorf("failed to set deadline on unix socket %s: %w", sockPath, err)
+		}
+	}
+	defer interruptOnDone(ctx, unixConn)()
 
 	messageBuf := make([]byte, 0)
 	unixRightsBuf := make([]byte, 0)
@@ -153,11 +206,15 @@ func Recv(ctx context.Context, sockPath string) (Options, error) {
 				break
 			}
 
-			return Options{}, fmt.Errorf("failed to read message from unix socket %s: %w", sockPath, err)
+			return Options{}, fmt.Errorf("failed to read message from unix socket %s: %w", sockPath, contextError(ctx, err))
 		}
 
 		messageBuf = append(messageBuf, message[:messageN]...)
 		unixRightsBuf = append(unixRightsBuf, unixRights[:unixRightsN]...)
+
+		if len(messageBuf) > MaxMessageSize {
+			return Options{}, fmt.Errorf("failed to read message from unix socket %s: %w", sockPath, ErrMessageTooLarge)
+		}
 	}
 
 	var options Options
@@ -176,9 +233,31 @@ func Recv(ctx context.Context, sockPath string) (Options, error) {
 	}
 
 	options.Fd = fds[0]
+
+	// Senders of protocol version 0 close the connection once mount options are sent, and do not read the reply
+	if err := writeHandshakeReply(unixConn, time.Now().Add(handshakeReplyTimeout)); err != nil {
+		klog.V(4).Infof("Failed to reply handshake on unix socket %s: %v", sockPath, err)
+	}
 	return options, nil
 }
 
+// interruptOnDone interrupts pending and future IO of `conn` once `ctx` is done, deadlines set from `ctx` do not
+// interrupt it when `ctx` is cancelled, e.g. when kubelet gives up on a call. The returned function stops it.
+func interruptOnDone(ctx context.Context, conn interface{ SetDeadline(time.Time) error }) func() bool {
+	return context.AfterFunc(ctx, func() {
+		_ = conn.SetDeadline(time.Now())
+	})
+}
+
+// contextError returns `err` of an IO interrupted by [interruptOnDone] or by the deadline of `ctx`, wrapping the
+// cause of `ctx` so callers can tell cancellations from IO errors.
+func contextError(ctx context.Context, err error) error {
+	if ctx.Err() == nil || errors.Is(err, ctx.Err()) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", context.Cause(ctx), err)
+}
+
 // parseUnixRights parses given socket control message to extract passed file descriptors.
 func parseUnixRights(buf []byte) ([]int, error) {
 	socketControlMessages, err := syscall.ParseSocketControlMessage(buf)
@@ -255,3 +334,37 @@ func relative(p string) (string, error) {
 	}
 	return filepath.Join(rel, filepath.Base(p)), nil
 }
+
+// A ReconnectRequest is written by a Mountpoint Pod whose Mountpoint process crashed, to request a new FUSE file
+// descriptor from the CSI Driver Node Pod. The CSI Driver Node Pod mounts the source of the Mountpoint Pod again, and
+// passes the new file descriptor with [Send] to the reconnect socket of the Mountpoint Pod. Other mount options are
+// ignored, the Mountpoint Pod restarts Mountpoint with its original mount options.
+type ReconnectRequest struct {
+	// Restart is the number of the restart of Mountpoint, starting at 1.
+	Restart int `json:"restart"`
+	// ExitCode of the crashed Mountpoint process.
+	ExitCode    int       `json:"exitCode"`
+	RequestedAt time.Time `json:"requestedAt"`
+}
+
+// Write writes `r` to `path`.
+func (r ReconnectRequest) Write(path string) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// ReadReconnectRequest reads the reconnect request at `path`.
+func ReadReconnectRequest(path string) (ReconnectRequest, error) {
+	var r ReconnectRequest
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return r, err
+	}
+	if err := json.Unmarshal(data, &r); err != nil {
+		return r, fmt.Errorf("failed to parse reconnect request %q: %w", path, err)
+	}
+	return r, nil
+}
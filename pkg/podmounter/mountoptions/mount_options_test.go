@@ -2,6 +2,7 @@ package mountoptions_test
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -45,6 +46,17 @@ func TestMountOptions(t *testing.T) {
 	})
 }
 
+func TestSendTooLargeMountOptions(t *testing.T) {
+	mountSock := filepath.Join(t.TempDir(), "m")
+	err := mountoptions.Send(defaultContext(t), mountSock, mountoptions.Options{
+		BucketName: "test-bucket",
+		Args:       []string{"--prefix=" + strings.Repeat("a", mountoptions.MaxMessageSize)},
+	})
+	if !errors.Is(err, mountoptions.ErrMessageTooLarge) {
+		t.Fatalf("Expected %v, got %v", mountoptions.ErrMessageTooLarge, err)
+	}
+}
+
 func testRoundtrip(t *testing.T, mountSock string) {
 	file, err := os.Open(os.DevNull)
 	assert.NoError(t, err)
@@ -69,8 +81,9 @@ func testRoundtrip(t *testing.T, mountSock string) {
 		Args:       []string{"--bucket=testing"},
 		Env:        []string{"TEST_ENV=testing"},
 	}
-	err = mountoptions.Send(defaultContext(t), mountSock, want)
+	peer, err := mountoptions.Exchange(defaultContext(t), mountSock, want)
 	assert.NoError(t, err)
+	assert.Equals(t, mountoptions.LocalHandshake(), peer)
 
 	got := <-c
 
@@ -87,6 +100,7 @@ func testRoundtrip(t *testing.T, mountSock string) {
 	// To verify underlying objects are the same, we need to compare "dev" and "ino" from "fstat" syscall.
 	got.Fd = 0
 	want.Fd = 0
+	want.Handshake = mountoptions.LocalHandshake()
 	assert.Equals(t, wantStat.Dev, gotStat.Dev)
 	assert.Equals(t, wantStat.Ino, gotStat.Ino)
 	assert.Equals(t, want, got)
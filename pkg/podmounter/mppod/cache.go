@@ -0,0 +1,127 @@
+package mppod
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+)
+
+// Types of the volume holding the Mountpoint cache, set with the [volumecontext.Cache] volume attribute.
+const (
+	// CacheEmptyDir keeps the cache in an `emptyDir` volume on the node's disk.
+	CacheEmptyDir = "emptyDir"
+	// CacheMemory keeps the cache in a memory-backed `emptyDir` volume, counted against the memory of the Mountpoint Pod.
+	CacheMemory = "memory"
+	// CacheEphemeralPVC keeps the cache in a generic ephemeral volume of the default StorageClass.
+	CacheEphemeralPVC = "ephemeralPVC"
+)
+
+// CacheVolumeName is the name of the volume holding the Mountpoint cache in Mountpoint Pods.
+const CacheVolumeName = "local-cache"
+
+// CacheDirPath is the path of the Mountpoint cache inside Mountpoint Pods, passed to Mountpoint with `--cache`.
+const CacheDirPath = "/" + CacheVolumeName
+
+// A Cache represents the volume holding the Mountpoint cache of a volume.
+type Cache struct {
+	Type string
+	// SizeLimit is the size of the cache volume, nil if unlimited.
+	SizeLimit *resource.Quantity
+}
+
+// ParseCache returns the cache volume configured in `volumeAttributes`, or nil if the Mountpoint cache is disabled.
+func ParseCache(volumeAttributes map[string]string) (*Cache, error) {
+	cacheType := volumeAttributes[volumecontext.Cache]
+	sizeLimit := volumeAttributes[volumecontext.CacheSizeLimit]
+	if cacheType == "" {
+		if sizeLimit != "" {
+			return nil, fmt.Errorf("%q requires %q", volumecontext.CacheSizeLimit, volumecontext.Cache)
+		}
+		return nil, nil
+	}
+
+	cache := &Cache{Type: cacheType}
+	switch cacheType {
+	case CacheEmptyDir, CacheMemory:
+	case CacheEphemeralPVC:
+		if sizeLimit == "" {
+			return nil, fmt.Errorf("%q is required with %s=%s", volumecontext.CacheSizeLimit, volumecontext.Cache, CacheEphemeralPVC)
+		}
+	default:
+		return nil, fmt.Errorf("unknown %q %q, must be %s, %s or %s", volumecontext.Cache, cacheType, CacheEmptyDir, CacheMemory, CacheEphemeralPVC)
+	}
+
+	if sizeLimit != "" {
+		quantity, err := resource.ParseQuantity(sizeLimit)
+		if err != nil {
+			return nil, failedToParseQuantityError(err, volumecontext.CacheSizeLimit, sizeLimit)
+		}
+		if quantity.Sign() <= 0 {
+			return nil, fmt.Errorf("%q must be positive, got %q", volumecontext.CacheSizeLimit, sizeLimit)
+		}
+		cache.SizeLimit = &quantity
+	}
+	return cache, nil
+}
+
+// volume returns the volume of Mountpoint Pods holding the cache. Ephemeral volumes are created with and deleted
+// along with the Mountpoint Pod, so caches never outlive it.
+func (c *Cache) volume() corev1.Volume {
+	volume := corev1.Volume{Name: CacheVolumeName}
+	switch c.Type {
+	case CacheEphemeralPVC:
+		volume.Ephemeral = &corev1.EphemeralVolumeSource{
+			VolumeClaimTemplate: &corev1.PersistentVolumeClaimTemplate{
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					Resources: corev1.VolumeResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: *c.SizeLimit},
+					},
+				},
+			},
+		}
+	case CacheMemory:
+		volume.EmptyDir = &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory, SizeLimit: c.SizeLimit}
+	default:
+		volume.EmptyDir = &corev1.EmptyDirVolumeSource{SizeLimit: c.SizeLimit}
+	}
+	return volume
+}
+
+// CacheOf returns the cache volume of `mpPod`, or nil if it has no cache volume.
+// The size limit of a cache volume is its `emptyDir` size limit or its storage request.
+func CacheOf(mpPod *corev1.Pod) *Cache {
+	for _, volume := range mpPod.Spec.Volumes {
+		if volume.Name != CacheVolumeName {
+			continue
+		}
+		switch {
+		case volume.Ephemeral != nil && volume.Ephemeral.VolumeClaimTemplate != nil:
+			size := volume.Ephemeral.VolumeClaimTemplate.Spec.Resources.Requests[corev1.ResourceStorage]
+			return &Cache{Type: CacheEphemeralPVC, SizeLimit: &size}
+		case volume.EmptyDir != nil && volume.EmptyDir.Medium == corev1.StorageMediumMemory:
+			return &Cache{Type: CacheMemory, SizeLimit: volume.EmptyDir.SizeLimit}
+		case volume.EmptyDir != nil:
+			return &Cache{Type: CacheEmptyDir, SizeLimit: volume.EmptyDir.SizeLimit}
+		}
+	}
+	return nil
+}
+
+// configureCache adds the cache volume configured in `volumeAttributes` to `mpPod`, if any.
+func configureCache(mpPod *corev1.Pod, volumeAttributes map[string]string) error {
+	cache, err := ParseCache(volumeAttributes)
+	if err != nil || cache == nil {
+		return err
+	}
+
+	mpPod.Spec.Volumes = append(mpPod.Spec.Volumes, cache.volume())
+	mpPod.Spec.Containers[0].VolumeMounts = append(mpPod.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name:      CacheVolumeName,
+		MountPath: CacheDirPath,
+	})
+	return nil
+}
@@ -0,0 +1,92 @@
+package mppod_test
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/cluster"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestCreatingMountpointPodsWithCache(t *testing.T) {
+	creator := mppod.NewCreator(createTestConfig(cluster.DefaultKubernetes))
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{UID: types.UID(testPodUID)},
+		Spec:       corev1.PodSpec{NodeName: testNode},
+	}
+	sizeLimit := resource.MustParse("2Gi")
+
+	tests := []struct {
+		name             string
+		volumeAttributes map[string]string
+		wantVolume       *corev1.VolumeSource
+		wantErr          bool
+	}{
+		{name: "no cache"},
+		{
+			name:             "emptyDir cache",
+			volumeAttributes: map[string]string{"cache": "emptyDir", "cacheSizeLimit": "2Gi"},
+			wantVolume:       &corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: &sizeLimit}},
+		},
+		{
+			name:             "memory cache without size limit",
+			volumeAttributes: map[string]string{"cache": "memory"},
+			wantVolume:       &corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory}},
+		},
+		{
+			name:             "ephemeral PVC cache",
+			volumeAttributes: map[string]string{"cache": "ephemeralPVC", "cacheSizeLimit": "2Gi"},
+			wantVolume: &corev1.VolumeSource{Ephemeral: &corev1.EphemeralVolumeSource{
+				VolumeClaimTemplate: &corev1.PersistentVolumeClaimTemplate{
+					Spec: corev1.PersistentVolumeClaimSpec{
+						AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+						Resources:   corev1.VolumeResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: sizeLimit}},
+					},
+				},
+			}},
+		},
+		{name: "ephemeral PVC cache without size limit", volumeAttributes: map[string]string{"cache": "ephemeralPVC"}, wantErr: true},
+		{name: "unknown cache type", volumeAttributes: map[string]string{"cache": "hostPath"}, wantErr: true},
+		{name: "invalid size limit", volumeAttributes: map[string]string{"cache": "emptyDir", "cacheSizeLimit": "a lot"}, wantErr: true},
+		{name: "size limit without cache", volumeAttributes: map[string]string{"cacheSizeLimit": "2Gi"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pv := &corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: testVolName},
+				Spec: corev1.PersistentVolumeSpec{
+					PersistentVolumeSource: corev1.PersistentVolumeSource{
+						CSI: &corev1.CSIPersistentVolumeSource{VolumeAttributes: tt.volumeAttributes},
+					},
+				},
+			}
+			mpPod, err := creator.Create(pod, pv)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected an error for an invalid cache configuration")
+				}
+				return
+			}
+			assert.NoError(t, err)
+
+			cache := mppod.CacheOf(mpPod)
+			if tt.wantVolume == nil {
+				if cache != nil {
+					t.Fatalf("Expected no cache volume, got %+v", mpPod.Spec.Volumes)
+				}
+				return
+			}
+			volume := mpPod.Spec.Volumes[len(mpPod.Spec.Volumes)-1]
+			assert.Equals(t, mppod.CacheVolumeName, volume.Name)
+			assert.Equals(t, *tt.wantVolume, volume.VolumeSource)
+			assert.Equals(t, corev1.VolumeMount{Name: mppod.CacheVolumeName, MountPath: mppod.CacheDirPath}, mpPod.Spec.Containers[0].VolumeMounts[1])
+			assert.Equals(t, tt.volumeAttributes["cache"], cache.Type)
+		})
+	}
+}
@@ -3,6 +3,8 @@ package mppod
 import (
 	"fmt"
 	"path/filepath"
+	"strconv"
+	"time"
 
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/cluster"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
@@ -22,6 +24,21 @@ const (
 	LabelCSIDriverVersion  = constants.DriverName + "/mounted-by-csi-driver-version"
 )
 
+// ContainerName is the name of the Mountpoint container in spawned Mountpoint Pods.
+const ContainerName = "mountpoint"
+
+// EnvBinaryDigests is the environment variable of Mountpoint containers containing the expected digests of the
+// Mountpoint binary, in the format parsed by `mountpoint.ParseBinaryDigests`.
+const EnvBinaryDigests = "MOUNTPOINT_BINARY_DIGESTS"
+
+// EnvMaxRestarts is the environment variable of Mountpoint containers containing the number of times Mountpoint is
+// restarted after crashing.
+const EnvMaxRestarts = "MOUNTPOINT_MAX_RESTARTS"
+
+// EnvShutdownTimeout is the environment variable of Mountpoint containers containing the time Mountpoint has to
+// flush pending uploads and exit after an unmount, as a Go duration. Mountpoint is waited for indefinitely if unset.
+const EnvShutdownTimeout = "MOUNTPOINT_SHUTDOWN_TIMEOUT"
+
 const EmptyDirSizeLimit = 10 * 1024 * 1024 // 10MiB
 
 const TLSEmptyDirSizeLimit = 2 * 1024 * 1024 // 2MiB — room for system CA bundle (~200KB) + custom CAs
@@ -39,6 +56,15 @@ type ContainerConfig struct {
 	Image           string
 	HeadroomImage   string // Image to use for headroom pods (typically a pause container)
 	ImagePullPolicy corev1.PullPolicy
+	// BinaryDigests are the expected digests of the Mountpoint binary in `Image`, verified before running it.
+	// Not verified if empty.
+	BinaryDigests string
+	// MaxRestarts is the number of times Mountpoint is restarted in its container after crashing, with a new FUSE
+	// file descriptor from the CSI Driver Node Pod. Zero disables restarts.
+	MaxRestarts int
+	// ShutdownTimeout bounds the time Mountpoint has to flush pending uploads and exit after an unmount. Zero waits
+	// for Mountpoint indefinitely.
+	ShutdownTimeout time.Duration
 }
 
 // TLSConfig holds TLS configuration for custom CA certificates in mounter pods.
@@ -62,6 +88,37 @@ type Config struct {
 	CSIDriverVersion            string
 	ClusterVariant              cluster.Variant
 	TLS                         *TLSConfig
+	// LingerDuration is how long a Mountpoint Pod and its mount are retained after its last workload
+	// is gone, so a quickly restarted workload can reuse them. Zero disables lingering.
+	LingerDuration time.Duration
+	// DrainTimeout is how long a deleted Mountpoint Pod keeps Mountpoint running for the workloads still using its
+	// mount, e.g. while its node is drained, before the node plugin detaches the mount from them lazily.
+	// Zero disables the wait, Mountpoint is killed at the end of the default grace period of the Mountpoint Pod.
+	DrainTimeout time.Duration
+	// HeadroomPodTTL is how long Headroom Pods are retained at most, Headroom Pods not consumed by then are
+	// deleted to release the capacity they reserve. Zero retains them until their Workload Pod starts or terminates.
+	HeadroomPodTTL time.Duration
+	// DiagnosticMountNamespace is the only namespace where workload Pods can use diagnostic mounts,
+	// which are inline ephemeral volumes. Diagnostic mounts are ignored if empty.
+	DiagnosticMountNamespace string
+	// EphemeralVolumes enables inline ephemeral volumes other than diagnostic mounts, which are ignored otherwise.
+	EphemeralVolumes bool
+	// MountHealthChecks adds a liveness probe to Mountpoint containers, failing once the CSI Driver Node Pod reports
+	// their mount unresponsive in [KnownPathMountHealth], so kubelet restarts them.
+	MountHealthChecks bool
+	// MountFailureBudget is the number of Mountpoint failures of a volume within MountFailureWindow after which
+	// the controller stops creating Mountpoint Pods for it and escalates to its PVC. Zero disables the budget.
+	MountFailureBudget int
+	MountFailureWindow time.Duration
+	// Resources are the default resource requests and limits of Mountpoint containers,
+	// overridden per volume by the `mountpointContainerResources*` volume attributes.
+	Resources corev1.ResourceRequirements
+	// PodOptions are the tolerations, labels, annotations and topology spread constraints of Mountpoint Pods,
+	// overridden per volume by the `mountpointPod*` volume attributes.
+	PodOptions PodOptions
+	// HostAliases are added to `/etc/hosts` of Mountpoint Pods if set. They can change at runtime, Mountpoint Pods
+	// get the host aliases current at their creation.
+	HostAliases *HostAliases
 }
 
 // A Creator allows creating specification for Mountpoint Pods to schedule.
@@ -78,7 +135,8 @@ func NewCreator(config Config) *Creator {
 //
 // It automatically assigns Mountpoint Pod to `pod`'s node.
 // The name of the Mountpoint Pod is consistently generated from `pod` and `pv` using `MountpointPodNameFor` function.
-func (c *Creator) Create(pod *corev1.Pod, pv *corev1.PersistentVolume) *corev1.Pod {
+// It returns an error if resources, the cache volume or logging specified in the volume attributes of `pv` cannot be parsed.
+func (c *Creator) Create(pod *corev1.Pod, pv *corev1.PersistentVolume) (*corev1.Pod, error) {
 	node := pod.Spec.NodeName
 	name := MountpointPodNameFor(string(pod.UID), pv.Name)
 
@@ -117,6 +175,7 @@ func (c *Creator) Create(pod *corev1.Pod, pv *corev1.PersistentVolume) *corev1.P
 				LabelVolumeName:        pv.Name,
 				LabelCSIDriverVersion:  c.config.CSIDriverVersion,
 			},
+			Finalizers: []string{FinalizerMountCleanup},
 		},
 		Spec: corev1.PodSpec{
 			// Mountpoint terminates with zero exit code on a successful termination,
@@ -129,10 +188,12 @@ func (c *Creator) Create(pod *corev1.Pod, pv *corev1.PersistentVolume) *corev1.P
 			},
 			InitContainers: initContainers,
 			Containers: []corev1.Container{{
-				Name:            "mountpoint",
+				Name:            ContainerName,
 				Image:           c.config.Container.Image,
 				ImagePullPolicy: c.config.Container.ImagePullPolicy,
 				Command:         []string{c.config.Container.Command},
+				// Report the tail of Mountpoint logs on failures, so the controller can classify them
+				TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
 				SecurityContext: &corev1.SecurityContext{
 					AllowPrivilegeEscalation: ptr.To(false),
 					Capabilities: &corev1.Capabilities{
@@ -144,9 +205,11 @@ func (c *Creator) Create(pod *corev1.Pod, pv *corev1.PersistentVolume) *corev1.P
 						Type: corev1.SeccompProfileTypeRuntimeDefault,
 					},
 				},
-				VolumeMounts: volumeMounts,
+				Env:           c.containerEnv(),
+				VolumeMounts:  volumeMounts,
+				LivenessProbe: c.livenessProbe(),
 			}},
-			PriorityClassName: c.config.PriorityClassName,
+			PriorityClassName: c.priorityClassName(pod),
 			Affinity: &corev1.Affinity{
 				NodeAffinity: &corev1.NodeAffinity{
 					// This is to making sure Mountpoint Pod gets scheduled into same node as the Workload Pod
@@ -163,27 +226,90 @@ func (c *Creator) Create(pod *corev1.Pod, pv *corev1.PersistentVolume) *corev1.P
 					},
 				},
 			},
-			Tolerations: []corev1.Toleration{
-				// Tolerate all taints.
-				// - "NoScheduled" – If the Workload Pod gets scheduled to a node, Mountpoint Pod should also get
-				//   scheduled into the same node to provide the volume.
-				// - "NoExecute" – If the Workload Pod tolerates a "NoExecute" taint, Mountpoint Pod should also
-				//   tolerate it to keep running and provide volume for the Workload Pod.
-				//   If the Workload Pod would get descheduled and then the corresponding Mountpoint Pod
-				//   would also get descheduled naturally due to CSI volume lifecycle.
-				{Operator: corev1.TolerationOpExists},
-			},
 			Volumes: volumes,
 		},
 	}
 
 	volumeAttributes := extractVolumeAttributes(pv)
 
+	podOptions, err := c.podOptions(volumeAttributes)
+	if err != nil {
+		return nil, err
+	}
+	podOptions.apply(mpPod)
+	c.configureDrain(mpPod)
+
+	if c.config.HostAliases != nil {
+		mpPod.Spec.HostAliases = c.config.HostAliases.Get()
+	}
+
 	if saName := volumeAttributes[volumecontext.MountpointPodServiceAccountName]; saName != "" {
 		mpPod.Spec.ServiceAccountName = saName
 	}
 
-	return mpPod
+	if err := c.configureResources(&mpPod.Spec.Containers[0], volumeAttributes); err != nil {
+		return nil, err
+	}
+
+	if err := configureCache(mpPod, volumeAttributes); err != nil {
+		return nil, err
+	}
+
+	if err := configureLogs(mpPod, volumeAttributes); err != nil {
+		return nil, err
+	}
+
+	return mpPod, nil
+}
+
+// containerEnv returns the environment variables of Mountpoint containers.
+func (c *Creator) containerEnv() []corev1.EnvVar {
+	var env []corev1.EnvVar
+	if c.config.Container.BinaryDigests != "" {
+		env = append(env, corev1.EnvVar{Name: EnvBinaryDigests, Value: c.config.Container.BinaryDigests})
+	}
+	if c.config.Container.MaxRestarts > 0 {
+		env = append(env, corev1.EnvVar{Name: EnvMaxRestarts, Value: strconv.Itoa(c.config.Container.MaxRestarts)})
+	}
+	if c.config.Container.ShutdownTimeout > 0 {
+		env = append(env, corev1.EnvVar{Name: EnvShutdownTimeout, Value: c.config.Container.ShutdownTimeout.String()})
+	}
+	return env
+}
+
+// livenessProbe returns the liveness probe of Mountpoint containers, nil if mount health checks are disabled.
+// The CSI Driver Node Pod already waits for the mount to stay unresponsive before reporting it, a single failure of
+// the probe restarts the container.
+func (c *Creator) livenessProbe() *corev1.Probe {
+	if !c.config.MountHealthChecks {
+		return nil
+	}
+	return &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			Exec: &corev1.ExecAction{Command: []string{c.config.Container.Command, "--check-health"}},
+		},
+		PeriodSeconds:    10,
+		TimeoutSeconds:   5,
+		FailureThreshold: 1,
+	}
+}
+
+// podOptions returns the options of Mountpoint Pods of the volume with `volumeAttributes`.
+func (c *Creator) podOptions(volumeAttributes map[string]string) (PodOptions, error) {
+	volumeOptions, err := ParsePodOptions(volumeAttributes)
+	if err != nil {
+		return PodOptions{}, err
+	}
+	return c.config.PodOptions.Override(volumeOptions), nil
+}
+
+// priorityClassName returns the priority class of the Mountpoint Pod for `workloadPod`. Mountpoint Pods of Workload
+// Pods with Headroom Pods use the preempting priority class, to be scheduled in place of the Headroom Pods.
+func (c *Creator) priorityClassName(workloadPod *corev1.Pod) string {
+	if WorkloadHasLabelPodForHeadroomPod(workloadPod) && c.config.PreemptingPriorityClassName != "" {
+		return c.config.PreemptingPriorityClassName
+	}
+	return c.config.PriorityClassName
 }
 
 // configureTLS adds TLS-related volumes, volume mounts, and init containers for custom CA certificate support.
@@ -297,13 +423,26 @@ func ExtractVolumeAttributes(pv *corev1.PersistentVolume) map[string]string {
 	return extractVolumeAttributes(pv)
 }
 
+// configureResources configures resource requests and limits of the container from the default resources in
+// the config, overridden by the ones specified in the volume attributes.
+func (c *Creator) configureResources(mpContainer *corev1.Container, volumeAttributes map[string]string) error {
+	mpContainer.Resources = *c.config.Resources.DeepCopy()
+
+	if err := c.configureResourceRequests(mpContainer, volumeAttributes); err != nil {
+		return err
+	}
+	return c.configureResourceLimits(mpContainer, volumeAttributes)
+}
+
 // configureResourceRequests configures resource requests of the container if its specified in the volume attributes.
 func (c *Creator) configureResourceRequests(mpContainer *corev1.Container, volumeAttributes map[string]string) error {
 	resourceRequestsCpu := volumeAttributes[volumecontext.MountpointContainerResourcesRequestsCpu]
 	resourceRequestsMemory := volumeAttributes[volumecontext.MountpointContainerResourcesRequestsMemory]
 
 	if resourceRequestsCpu != "" || resourceRequestsMemory != "" {
-		mpContainer.Resources.Requests = make(corev1.ResourceList)
+		if mpContainer.Resources.Requests == nil {
+			mpContainer.Resources.Requests = make(corev1.ResourceList)
+		}
 
 		if resourceRequestsCpu != "" {
 			quantity, err := resource.ParseQuantity(resourceRequestsCpu)
@@ -331,7 +470,9 @@ func (c *Creator) configureResourceLimits(mpContainer *corev1.Container, volumeA
 	resourceLimitsMemory := volumeAttributes[volumecontext.MountpointContainerResourcesLimitsMemory]
 
 	if resourceLimitsCpu != "" || resourceLimitsMemory != "" {
-		mpContainer.Resources.Limits = make(corev1.ResourceList)
+		if mpContainer.Resources.Limits == nil {
+			mpContainer.Resources.Limits = make(corev1.ResourceList)
+		}
 
 		if resourceLimitsCpu != "" {
 			quantity, err := resource.ParseQuantity(resourceLimitsCpu)
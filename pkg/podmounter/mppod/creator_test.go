@@ -2,6 +2,7 @@ package mppod_test
 
 import (
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -55,6 +56,7 @@ func createAndVerifyPod(t *testing.T, clusterVariant cluster.Variant, expectedRu
 			mppod.LabelVolumeName:        testVolName,
 			mppod.LabelCSIDriverVersion:  csiDriverVersion,
 		}, mpPod.Labels)
+		assert.Equals(t, []string{mppod.FinalizerMountCleanup}, mpPod.Finalizers)
 
 		assert.Equals(t, priorityClassName, mpPod.Spec.PriorityClassName)
 		assert.Equals(t, corev1.RestartPolicyOnFailure, mpPod.Spec.RestartPolicy)
@@ -110,7 +112,7 @@ func createAndVerifyPod(t *testing.T, clusterVariant cluster.Variant, expectedRu
 	}
 
 	t.Run("Empty PV", func(t *testing.T) {
-		mpPod := creator.Create(&corev1.Pod{
+		mpPod, err := creator.Create(&corev1.Pod{
 			ObjectMeta: metav1.ObjectMeta{
 				UID: types.UID(testPodUID),
 			},
@@ -122,12 +124,13 @@ func createAndVerifyPod(t *testing.T, clusterVariant cluster.Variant, expectedRu
 				Name: testVolName,
 			},
 		})
+		assert.NoError(t, err)
 
 		verifyDefaultValues(mpPod)
 	})
 
 	t.Run("With ServiceAccountName specified in PV", func(t *testing.T) {
-		mpPod := creator.Create(&corev1.Pod{
+		mpPod, err := creator.Create(&corev1.Pod{
 			ObjectMeta: metav1.ObjectMeta{
 				UID: types.UID(testPodUID),
 			},
@@ -148,6 +151,7 @@ func createAndVerifyPod(t *testing.T, clusterVariant cluster.Variant, expectedRu
 				},
 			},
 		})
+		assert.NoError(t, err)
 
 		verifyDefaultValues(mpPod)
 		assert.Equals(t, "mount-s3-sa", mpPod.Spec.ServiceAccountName)
@@ -176,7 +180,7 @@ func TestCreatingMountpointPodsWithTLS(t *testing.T) {
 	config.TLS = tlsConfig
 	creator := mppod.NewCreator(config)
 
-	mpPod := creator.Create(&corev1.Pod{
+	mpPod, err := creator.Create(&corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			UID: types.UID(testPodUID),
 		},
@@ -188,6 +192,7 @@ func TestCreatingMountpointPodsWithTLS(t *testing.T) {
 			Name: testVolName,
 		},
 	})
+	assert.NoError(t, err)
 
 	// Verify 3 volumes: communication + ConfigMap + emptyDir
 	assert.Equals(t, 3, len(mpPod.Spec.Volumes))
@@ -254,7 +259,7 @@ func TestCreatingMountpointPodsWithoutTLS(t *testing.T) {
 	// TLS is nil by default
 	creator := mppod.NewCreator(config)
 
-	mpPod := creator.Create(&corev1.Pod{
+	mpPod, err := creator.Create(&corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			UID: types.UID(testPodUID),
 		},
@@ -266,6 +271,7 @@ func TestCreatingMountpointPodsWithoutTLS(t *testing.T) {
 			Name: testVolName,
 		},
 	})
+	assert.NoError(t, err)
 
 	// Verify 0 init containers
 	assert.Equals(t, 0, len(mpPod.Spec.InitContainers))
@@ -279,6 +285,77 @@ func TestCreatingMountpointPodsWithoutTLS(t *testing.T) {
 	assert.Equals(t, mppod.CommunicationDirName, mpPod.Spec.Containers[0].VolumeMounts[0].Name)
 }
 
+func TestCreatingMountpointPodsWithBinaryDigests(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{UID: types.UID(testPodUID)},
+		Spec:       corev1.PodSpec{NodeName: testNode},
+	}
+	pv := &corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: testVolName}}
+
+	config := createTestConfig(cluster.DefaultKubernetes)
+	mpPod, err := mppod.NewCreator(config).Create(pod, pv)
+	assert.NoError(t, err)
+	assert.Equals(t, 0, len(mpPod.Spec.Containers[0].Env))
+
+	config.Container.BinaryDigests = "linux-amd64=sha256:0123,linux-arm64=sha256:4567"
+	mpPod, err = mppod.NewCreator(config).Create(pod, pv)
+	assert.NoError(t, err)
+	assert.Equals(t, []corev1.EnvVar{{Name: mppod.EnvBinaryDigests, Value: config.Container.BinaryDigests}}, mpPod.Spec.Containers[0].Env)
+}
+
+func TestCreatingMountpointPodsWithMaxRestarts(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{UID: types.UID(testPodUID)},
+		Spec:       corev1.PodSpec{NodeName: testNode},
+	}
+	pv := &corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: testVolName}}
+
+	config := createTestConfig(cluster.DefaultKubernetes)
+	config.Container.MaxRestarts = 3
+	mpPod, err := mppod.NewCreator(config).Create(pod, pv)
+	assert.NoError(t, err)
+	assert.Equals(t, []corev1.EnvVar{{Name: mppod.EnvMaxRestarts, Value: "3"}}, mpPod.Spec.Containers[0].Env)
+}
+
+func TestCreatingMountpointPodsWithShutdownTimeout(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{UID: types.UID(testPodUID)},
+		Spec:       corev1.PodSpec{NodeName: testNode},
+	}
+	pv := &corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: testVolName}}
+
+	config := createTestConfig(cluster.DefaultKubernetes)
+	config.Container.ShutdownTimeout = 5 * time.Minute
+	mpPod, err := mppod.NewCreator(config).Create(pod, pv)
+	assert.NoError(t, err)
+	assert.Equals(t, []corev1.EnvVar{{Name: mppod.EnvShutdownTimeout, Value: "5m0s"}}, mpPod.Spec.Containers[0].Env)
+}
+
+func TestCreatingMountpointPodsWithMountHealthChecks(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{UID: types.UID(testPodUID)},
+		Spec:       corev1.PodSpec{NodeName: testNode},
+	}
+	pv := &corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: testVolName}}
+
+	config := createTestConfig(cluster.DefaultKubernetes)
+	mpPod, err := mppod.NewCreator(config).Create(pod, pv)
+	assert.NoError(t, err)
+	if mpPod.Spec.Containers[0].LivenessProbe != nil {
+		t.Fatalf("Expected no liveness probe, got %v", mpPod.Spec.Containers[0].LivenessProbe)
+	}
+
+	config.MountHealthChecks = true
+	mpPod, err = mppod.NewCreator(config).Create(pod, pv)
+	assert.NoError(t, err)
+	probe := mpPod.Spec.Containers[0].LivenessProbe
+	if probe == nil || probe.Exec == nil {
+		t.Fatalf("Expected an exec liveness probe, got %v", probe)
+	}
+	assert.Equals(t, []string{config.Container.Command, "--check-health"}, probe.Exec.Command)
+	assert.Equals(t, int32(1), probe.FailureThreshold)
+}
+
 func TestNewCreator(t *testing.T) {
 	config := mppod.Config{
 		Namespace:         "test-namespace",
@@ -314,7 +391,8 @@ func TestNewCreator(t *testing.T) {
 		},
 	}
 
-	mpPod := creator.Create(pod, pv)
+	mpPod, err := creator.Create(pod, pv)
+	assert.NoError(t, err)
 
 	// Verify config values are used
 	assert.Equals(t, config.Namespace, mpPod.Namespace)
@@ -326,3 +404,136 @@ func TestNewCreator(t *testing.T) {
 	assert.Equals(t, config.Container.ImagePullPolicy, mpPod.Spec.Containers[0].ImagePullPolicy)
 	assert.Equals(t, []string{config.Container.Command}, mpPod.Spec.Containers[0].Command)
 }
+
+func TestCreatingMountpointPodsWithResources(t *testing.T) {
+	config := createTestConfig(cluster.DefaultKubernetes)
+	config.Resources = corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("100m"),
+			corev1.ResourceMemory: resource.MustParse("128Mi"),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("1Gi"),
+		},
+	}
+	creator := mppod.NewCreator(config)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{UID: types.UID(testPodUID)},
+		Spec:       corev1.PodSpec{NodeName: testNode},
+	}
+	pvWithAttributes := func(volumeAttributes map[string]string) *corev1.PersistentVolume {
+		return &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: testVolName},
+			Spec: corev1.PersistentVolumeSpec{
+				PersistentVolumeSource: corev1.PersistentVolumeSource{
+					CSI: &corev1.CSIPersistentVolumeSource{VolumeAttributes: volumeAttributes},
+				},
+			},
+		}
+	}
+
+	t.Run("Default resources", func(t *testing.T) {
+		mpPod, err := creator.Create(pod, pvWithAttributes(nil))
+		assert.NoError(t, err)
+		assert.Equals(t, config.Resources, mpPod.Spec.Containers[0].Resources)
+	})
+
+	t.Run("Resources overridden in volume attributes", func(t *testing.T) {
+		mpPod, err := creator.Create(pod, pvWithAttributes(map[string]string{
+			"mountpointContainerResourcesRequestsMemory": "2Gi",
+			"mountpointContainerResourcesLimitsCpu":      "2",
+			"mountpointContainerResourcesLimitsMemory":   "4Gi",
+		}))
+		assert.NoError(t, err)
+		assert.Equals(t, corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("100m"),
+				corev1.ResourceMemory: resource.MustParse("2Gi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+		}, mpPod.Spec.Containers[0].Resources)
+
+		// Defaults of the config are not modified
+		assert.Equals(t, 1, len(config.Resources.Limits))
+	})
+
+	t.Run("Invalid resources in volume attributes", func(t *testing.T) {
+		_, err := creator.Create(pod, pvWithAttributes(map[string]string{
+			"mountpointContainerResourcesRequestsMemory": "a lot",
+		}))
+		if err == nil {
+			t.Fatal("Expected an error for an invalid quantity")
+		}
+	})
+}
+
+func TestCreatingMountpointPodsWithPodOptions(t *testing.T) {
+	config := createTestConfig(cluster.DefaultKubernetes)
+	config.PodOptions = mppod.PodOptions{
+		Tolerations: []corev1.Toleration{{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}},
+		Labels:      map[string]string{"app.kubernetes.io/part-of": "storage", "team": "platform"},
+		Annotations: map[string]string{"cost-center": "storage"},
+	}
+	creator := mppod.NewCreator(config)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{UID: types.UID(testPodUID)},
+		Spec:       corev1.PodSpec{NodeName: testNode},
+	}
+	pvWithAttributes := func(volumeAttributes map[string]string) *corev1.PersistentVolume {
+		return &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: testVolName},
+			Spec: corev1.PersistentVolumeSpec{
+				PersistentVolumeSource: corev1.PersistentVolumeSource{
+					CSI: &corev1.CSIPersistentVolumeSource{VolumeAttributes: volumeAttributes},
+				},
+			},
+		}
+	}
+
+	t.Run("Default options", func(t *testing.T) {
+		mpPod, err := creator.Create(pod, pvWithAttributes(nil))
+		assert.NoError(t, err)
+		assert.Equals(t, config.PodOptions.Tolerations, mpPod.Spec.Tolerations)
+		assert.Equals(t, "platform", mpPod.Labels["team"])
+		assert.Equals(t, testVolName, mpPod.Labels[mppod.LabelVolumeName])
+		assert.Equals(t, map[string]string{"cost-center": "storage"}, mpPod.Annotations)
+
+		hrPod, err := creator.HeadroomPod(pod, pvWithAttributes(nil))
+		assert.NoError(t, err)
+		assert.Equals(t, config.PodOptions.Tolerations, hrPod.Spec.Tolerations)
+	})
+
+	t.Run("Options overridden in volume attributes", func(t *testing.T) {
+		mpPod, err := creator.Create(pod, pvWithAttributes(map[string]string{
+			"mountpointPodTolerations":               `[{"operator": "Exists"}]`,
+			"mountpointPodLabels":                    `{"team": "ml"}`,
+			"mountpointPodTopologySpreadConstraints": `[{"maxSkew": 1, "topologyKey": "topology.kubernetes.io/zone", "whenUnsatisfiable": "ScheduleAnyway"}]`,
+		}))
+		assert.NoError(t, err)
+		assert.Equals(t, []corev1.Toleration{{Operator: corev1.TolerationOpExists}}, mpPod.Spec.Tolerations)
+		assert.Equals(t, "ml", mpPod.Labels["team"])
+		assert.Equals(t, "storage", mpPod.Labels["app.kubernetes.io/part-of"])
+		assert.Equals(t, 1, len(mpPod.Spec.TopologySpreadConstraints))
+
+		// Defaults of the config are not modified
+		assert.Equals(t, "platform", config.PodOptions.Labels["team"])
+	})
+
+	t.Run("Invalid options in volume attributes", func(t *testing.T) {
+		for _, attributes := range []map[string]string{
+			{"mountpointPodLabels": `{"s3.csi.scality.com/pod-uid": "other"}`},
+			{"mountpointPodLabels": `{"team": "not a valid value"}`},
+			{"mountpointPodTolerations": `{"key": "not a list"}`},
+			{"mountpointPodTopologySpreadConstraints": `[{"topologyKey": "kubernetes.io/hostname"}]`},
+		} {
+			if _, err := creator.Create(pod, pvWithAttributes(attributes)); err == nil {
+				t.Fatalf("Expected an error for invalid options %v", attributes)
+			}
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package mppod
+
+import (
+	"math"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ShutdownPeriod is how long deleted Mountpoint Pods give Mountpoint to flush pending uploads and exit once the node
+// plugin unmounted it, when no shutdown timeout bounds it.
+const ShutdownPeriod = 2*time.Minute + 10*time.Second
+
+// shutdownGracePeriod matches the default `--shutdown-grace-period` of `scality-s3-csi-mounter`, given to Mountpoint
+// after SIGTERM once its shutdown timeout is exceeded.
+const shutdownGracePeriod = 10 * time.Second
+
+// DrainDeadline returns when the drain timeout of the deleted `mpPod` expires, and false if `mpPod` is not deleted
+// or has no drain timeout. Until then, the node plugin waits for the workloads of `mpPod` to release its mount.
+func DrainDeadline(mpPod *corev1.Pod) (time.Time, bool) {
+	if mpPod.DeletionTimestamp == nil {
+		return time.Time{}, false
+	}
+	timeout, err := time.ParseDuration(mpPod.Annotations[AnnotationDrainTimeout])
+	if err != nil || timeout <= 0 {
+		return time.Time{}, false
+	}
+
+	// The deletion timestamp of Pods is the end of their grace period
+	deletedAt := mpPod.DeletionTimestamp.Time
+	if mpPod.DeletionGracePeriodSeconds != nil {
+		deletedAt = deletedAt.Add(-time.Duration(*mpPod.DeletionGracePeriodSeconds) * time.Second)
+	}
+	return deletedAt.Add(timeout), true
+}
+
+// configureDrain adds a preStop hook to the Mountpoint container of `mpPod`, keeping Mountpoint running once
+// `mpPod` is deleted until the node plugin unmounted it after its workloads released the mount, or until the drain
+// timeout. The termination grace period of `mpPod` covers the drain timeout and the shutdown of Mountpoint.
+func (c *Creator) configureDrain(mpPod *corev1.Pod) {
+	if c.config.DrainTimeout <= 0 {
+		return
+	}
+
+	metav1.SetMetaDataAnnotation(&mpPod.ObjectMeta, AnnotationDrainTimeout, c.config.DrainTimeout.String())
+	mpPod.Spec.Containers[0].Lifecycle = &corev1.Lifecycle{
+		PreStop: &corev1.LifecycleHandler{
+			Exec: &corev1.ExecAction{Command: []string{
+				c.config.Container.Command, "--pre-stop", "--pre-stop-timeout=" + c.config.DrainTimeout.String(),
+			}},
+		},
+	}
+	gracePeriod := int64(math.Ceil((c.config.DrainTimeout + c.shutdownPeriod()).Seconds()))
+	mpPod.Spec.TerminationGracePeriodSeconds = &gracePeriod
+}
+
+// shutdownPeriod returns how long Mountpoint has to flush pending uploads and exit once the node plugin unmounted it.
+func (c *Creator) shutdownPeriod() time.Duration {
+	if c.config.Container.ShutdownTimeout > 0 {
+		return c.config.Container.ShutdownTimeout + shutdownGracePeriod
+	}
+	return ShutdownPeriod
+}
@@ -0,0 +1,93 @@
+package mppod_test
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/cluster"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestCreatingMountpointPodsWithDrainTimeout(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{UID: types.UID(testPodUID)},
+		Spec:       corev1.PodSpec{NodeName: testNode},
+	}
+	pv := &corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: testVolName}}
+
+	config := createTestConfig(cluster.DefaultKubernetes)
+	mpPod, err := mppod.NewCreator(config).Create(pod, pv)
+	assert.NoError(t, err)
+	if mpPod.Spec.Containers[0].Lifecycle != nil || mpPod.Spec.TerminationGracePeriodSeconds != nil {
+		t.Fatalf("Expected no preStop hook nor termination grace period without drain timeout, got %v", mpPod.Spec.Containers[0].Lifecycle)
+	}
+
+	config.DrainTimeout = 3 * time.Minute
+	mpPod, err = mppod.NewCreator(config).Create(pod, pv)
+	assert.NoError(t, err)
+	lifecycle := mpPod.Spec.Containers[0].Lifecycle
+	if lifecycle == nil || lifecycle.PreStop == nil || lifecycle.PreStop.Exec == nil {
+		t.Fatalf("Expected an exec preStop hook, got %v", lifecycle)
+	}
+	assert.Equals(t, []string{config.Container.Command, "--pre-stop", "--pre-stop-timeout=3m0s"}, lifecycle.PreStop.Exec.Command)
+	assert.Equals(t, "3m0s", mpPod.Annotations[mppod.AnnotationDrainTimeout])
+	assert.Equals(t, ptr.To(int64(310)), mpPod.Spec.TerminationGracePeriodSeconds)
+
+	// The termination grace period covers the shutdown timeout of Mountpoint if set
+	config.Container.ShutdownTimeout = 5 * time.Minute
+	mpPod, err = mppod.NewCreator(config).Create(pod, pv)
+	assert.NoError(t, err)
+	assert.Equals(t, ptr.To(int64(490)), mpPod.Spec.TerminationGracePeriodSeconds)
+}
+
+func TestDrainDeadline(t *testing.T) {
+	deletionTimestamp := metav1.NewTime(time.Date(2026, 1, 1, 12, 10, 0, 0, time.UTC))
+
+	tests := []struct {
+		name         string
+		pod          *corev1.Pod
+		wantDeadline time.Time
+		wantOK       bool
+	}{
+		{
+			name: "not deleted",
+			pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{mppod.AnnotationDrainTimeout: "5m"},
+			}},
+		},
+		{
+			name: "no drain timeout",
+			pod:  &corev1.Pod{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &deletionTimestamp}},
+		},
+		{
+			name: "invalid drain timeout",
+			pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				DeletionTimestamp: &deletionTimestamp,
+				Annotations:       map[string]string{mppod.AnnotationDrainTimeout: "soon"},
+			}},
+		},
+		{
+			name: "deleted with a grace period",
+			pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				DeletionTimestamp:          &deletionTimestamp,
+				DeletionGracePeriodSeconds: ptr.To(int64(600)),
+				Annotations:                map[string]string{mppod.AnnotationDrainTimeout: "5m"},
+			}},
+			wantDeadline: time.Date(2026, 1, 1, 12, 5, 0, 0, time.UTC),
+			wantOK:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deadline, ok := mppod.DrainDeadline(tt.pod)
+			assert.Equals(t, tt.wantOK, ok)
+			assert.Equals(t, tt.wantDeadline, deadline)
+		})
+	}
+}
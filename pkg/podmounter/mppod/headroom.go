@@ -24,6 +24,10 @@ const (
 	LabelHeadroomForWorkload = constants.DriverName + "/headroom-for-workload"
 )
 
+// AnnotationHeadroomForWorkload is populated on spawned Headroom Pods with the `namespace/name` of their Workload Pod,
+// so unused Headroom Pods can be found and garbage collected once their Workload Pod is gone.
+const AnnotationHeadroomForWorkload = constants.DriverName + "/headroom-for-workload"
+
 // A scheduling gate can be used on Workload Pods using a volume backed by the CSI Driver to signal the CSI Driver
 // to reserve headroom for the Mountpoint Pod to serve volumes to workload.
 //
@@ -33,7 +37,9 @@ const (
 //  3. Ungates the scheduling gate from the Workload Pod to let it scheduled - alongside the Headroom Pods if possible
 //  4. Schedules Mountpoint Pod if necessary (i.e., the CSI Driver cannot share an existing Mountpoint Pod) into the same node as the Workload and Headroom Pods using a preempting priority class
 //  5. Mountpoint Pod most likely preempts the Headroom Pods if there is no space in the node - as the Headroom Pods uses a negative priority -, or just gets scheduled if there is enough space for all pods
-//  6. Deletes the Headroom Pods as soon as the Workload Pod is running or terminated - as Mountpoint Pods are already scheduled or no longer needed
+//  6. Deletes the Headroom Pods as soon as Mountpoint Pods of the Workload Pod are scheduled or the Workload Pod is running or terminated - as they are no longer needed
+//
+// Headroom Pods not consumed within [Config.HeadroomPodTTL] are deleted as well.
 const SchedulingGateReserveHeadroomForMountpointPod = constants.DriverName + "/reserve-headroom-for-mppod"
 
 const headroomPodNamePrefix = "hr-"
@@ -49,6 +55,9 @@ func (c *Creator) HeadroomPod(workloadPod *corev1.Pod, pv *corev1.PersistentVolu
 				LabelHeadroomForPod:    string(workloadPod.UID),
 				LabelHeadroomForVolume: pv.Name,
 			},
+			Annotations: map[string]string{
+				AnnotationHeadroomForWorkload: workloadPod.Namespace + "/" + workloadPod.Name,
+			},
 		},
 		Spec: corev1.PodSpec{
 			PriorityClassName: c.config.HeadroomPriorityClassName,
@@ -89,27 +98,31 @@ func (c *Creator) HeadroomPod(workloadPod *corev1.Pod, pv *corev1.PersistentVolu
 					},
 				},
 			},
-			Tolerations: []corev1.Toleration{
-				// Tolerate all taints, so this Headroom Pod would be scheduled to any node alongside the Workload Pod.
-				{Operator: corev1.TolerationOpExists},
-			},
 		},
 	}
 
 	hrContainer := &hrPod.Spec.Containers[0]
 	volumeAttributes := ExtractVolumeAttributes(pv)
 
-	if err := c.configureResourceRequests(hrContainer, volumeAttributes); err != nil {
+	// Reserve the same resources as the Mountpoint Pod
+	if err := c.configureResources(hrContainer, volumeAttributes); err != nil {
 		return nil, err
 	}
-	if err := c.configureResourceLimits(hrContainer, volumeAttributes); err != nil {
+
+	// Tolerate the same taints as the Mountpoint Pod, all taints by default, so this Headroom Pod would be scheduled
+	// to the node of the Workload Pod
+	podOptions, err := c.podOptions(volumeAttributes)
+	if err != nil {
 		return nil, err
 	}
+	hrPod.Spec.Tolerations = podOptions.tolerations()
 
 	return hrPod, nil
 }
 
 // HeadroomPodNameFor returns a consistent name for the Headroom Pod for given `workloadPod` and `pv`.
+// The name is tied to the pending attachment of `pv` to `workloadPod`, so an existing Headroom Pod is adopted instead of
+// duplicated if the attachment is reconciled again.
 func HeadroomPodNameFor(workloadPod *corev1.Pod, pv *corev1.PersistentVolume) string {
 	return fmt.Sprintf("%s%x", headroomPodNamePrefix, sha256.Sum224(fmt.Appendf(nil, "%s%s", workloadPod.UID, pv.Name)))
 }
@@ -0,0 +1,88 @@
+package mppod
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// HostAliases are hostname to IP overrides added to `/etc/hosts` of Mountpoint Pods, e.g. to resolve S3 endpoints
+// absent from the cluster DNS. They are safe for concurrent use, and updated at runtime from a ConfigMap whose keys
+// are hostnames and values are IPs.
+type HostAliases struct {
+	mu      sync.RWMutex
+	aliases []corev1.HostAlias
+}
+
+// NewHostAliases creates empty [HostAliases].
+func NewHostAliases() *HostAliases {
+	return &HostAliases{}
+}
+
+// Get returns the current host aliases, sorted by IP with hostnames sorted.
+func (h *HostAliases) Get() []corev1.HostAlias {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	var aliases []corev1.HostAlias
+	for _, alias := range h.aliases {
+		aliases = append(aliases, *alias.DeepCopy())
+	}
+	return aliases
+}
+
+// Set replaces the host aliases with the `hostname: IP` entries of `data`, and returns whether they changed.
+// The host aliases are left unchanged if an entry is invalid.
+func (h *HostAliases) Set(data map[string]string) (bool, error) {
+	aliases, err := ParseHostAliases(data)
+	if err != nil {
+		return false, err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if equality.Semantic.DeepEqual(aliases, h.aliases) {
+		return false, nil
+	}
+	h.aliases = aliases
+	return true, nil
+}
+
+// UpToDate returns whether `mpPod` was created with the current host aliases.
+func (h *HostAliases) UpToDate(mpPod *corev1.Pod) bool {
+	current := h.Get()
+	if len(current) == 0 && len(mpPod.Spec.HostAliases) == 0 {
+		return true
+	}
+	return equality.Semantic.DeepEqual(current, mpPod.Spec.HostAliases)
+}
+
+// ParseHostAliases returns the host aliases of the `hostname: IP` entries of `data`, grouped by IP.
+func ParseHostAliases(data map[string]string) ([]corev1.HostAlias, error) {
+	hostnames := make(map[string][]string)
+	for hostname, ip := range data {
+		if errs := validation.IsDNS1123Subdomain(hostname); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid hostname %q: %v", hostname, errs)
+		}
+		parsed := net.ParseIP(ip)
+		if parsed == nil {
+			return nil, fmt.Errorf("invalid IP %q for hostname %q", ip, hostname)
+		}
+		hostnames[parsed.String()] = append(hostnames[parsed.String()], hostname)
+	}
+
+	var aliases []corev1.HostAlias
+	for ip, names := range hostnames {
+		slices.Sort(names)
+		aliases = append(aliases, corev1.HostAlias{IP: ip, Hostnames: names})
+	}
+	slices.SortFunc(aliases, func(a, b corev1.HostAlias) int {
+		return strings.Compare(a.IP, b.IP)
+	})
+	return aliases, nil
+}
@@ -0,0 +1,67 @@
+package mppod_test
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestParseHostAliases(t *testing.T) {
+	aliases, err := mppod.ParseHostAliases(map[string]string{
+		"s3.example.com":    "10.0.0.2",
+		"iam.example.com":   "10.0.0.2",
+		"utapi.example.com": "10.0.0.1",
+		"s3-v6.example.com": "fd00::1",
+		"s3.other.test":     "10.0.0.10",
+	})
+	assert.NoError(t, err)
+	assert.Equals(t, []corev1.HostAlias{
+		{IP: "10.0.0.1", Hostnames: []string{"utapi.example.com"}},
+		{IP: "10.0.0.10", Hostnames: []string{"s3.other.test"}},
+		{IP: "10.0.0.2", Hostnames: []string{"iam.example.com", "s3.example.com"}},
+		{IP: "fd00::1", Hostnames: []string{"s3-v6.example.com"}},
+	}, aliases)
+
+	for _, data := range []map[string]string{
+		{"s3.example.com": "not-an-ip"},
+		{"S3_Endpoint": "10.0.0.1"},
+	} {
+		if _, err := mppod.ParseHostAliases(data); err == nil {
+			t.Errorf("Expected %v to be invalid", data)
+		}
+	}
+}
+
+func TestHostAliases(t *testing.T) {
+	hostAliases := mppod.NewHostAliases()
+	mpPod := &corev1.Pod{}
+	assert.Equals(t, true, hostAliases.UpToDate(mpPod))
+
+	changed, err := hostAliases.Set(map[string]string{"s3.example.com": "10.0.0.1"})
+	assert.NoError(t, err)
+	assert.Equals(t, true, changed)
+	assert.Equals(t, false, hostAliases.UpToDate(mpPod))
+
+	mpPod.Spec.HostAliases = hostAliases.Get()
+	assert.Equals(t, true, hostAliases.UpToDate(mpPod))
+
+	// Invalid entries keep the previous host aliases
+	_, err = hostAliases.Set(map[string]string{"s3.example.com": "10.0.0.300"})
+	if err == nil {
+		t.Fatalf("Expected an invalid IP to be rejected")
+	}
+	assert.Equals(t, true, hostAliases.UpToDate(mpPod))
+
+	changed, err = hostAliases.Set(map[string]string{"s3.example.com": "10.0.0.1"})
+	assert.NoError(t, err)
+	assert.Equals(t, false, changed)
+
+	changed, err = hostAliases.Set(nil)
+	assert.NoError(t, err)
+	assert.Equals(t, true, changed)
+	assert.Equals(t, false, hostAliases.UpToDate(mpPod))
+	assert.Equals(t, true, hostAliases.UpToDate(&corev1.Pod{}))
+}
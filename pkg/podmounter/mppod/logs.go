@@ -0,0 +1,53 @@
+package mppod
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+)
+
+// LogsVolumeName is the name of the volume holding Mountpoint log files in Mountpoint Pods of volumes logging to
+// files, see [volumecontext.LogDestinationFile].
+const LogsVolumeName = "mountpoint-logs"
+
+// LogsDirPath is the path of Mountpoint log files inside Mountpoint Pods, passed to Mountpoint with `--log-directory`.
+const LogsDirPath = "/" + LogsVolumeName
+
+// LogsEmptyDirSizeLimit is the size limit of the volume holding Mountpoint log files. Mountpoint does not rotate its
+// log files, the Mountpoint Pod is evicted once they exceed it.
+const LogsEmptyDirSizeLimit = 1024 * 1024 * 1024 // 1GiB
+
+// HasLogsVolume returns whether `mpPod` has a volume holding Mountpoint log files.
+func HasLogsVolume(mpPod *corev1.Pod) bool {
+	for _, volume := range mpPod.Spec.Volumes {
+		if volume.Name == LogsVolumeName {
+			return true
+		}
+	}
+	return false
+}
+
+// configureLogs adds the volume holding Mountpoint log files to `mpPod` if `volumeAttributes` configure Mountpoint to
+// log to files.
+func configureLogs(mpPod *corev1.Pod, volumeAttributes map[string]string) error {
+	logging, err := volumecontext.ParseLogging(volumeAttributes)
+	if err != nil || logging.Destination != volumecontext.LogDestinationFile {
+		return err
+	}
+
+	// Log files are kept on the node's disk, debug logs would quickly fill the memory of the Mountpoint Pod
+	mpPod.Spec.Volumes = append(mpPod.Spec.Volumes, corev1.Volume{
+		Name: LogsVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{
+				SizeLimit: resource.NewQuantity(LogsEmptyDirSizeLimit, resource.BinarySI),
+			},
+		},
+	})
+	mpPod.Spec.Containers[0].VolumeMounts = append(mpPod.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name:      LogsVolumeName,
+		MountPath: LogsDirPath,
+	})
+	return nil
+}
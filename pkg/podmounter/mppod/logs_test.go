@@ -0,0 +1,65 @@
+package mppod_test
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/cluster"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestCreatingMountpointPodsWithLogs(t *testing.T) {
+	creator := mppod.NewCreator(createTestConfig(cluster.DefaultKubernetes))
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{UID: types.UID(testPodUID)},
+		Spec:       corev1.PodSpec{NodeName: testNode},
+	}
+
+	tests := []struct {
+		name             string
+		volumeAttributes map[string]string
+		wantLogsVolume   bool
+		wantErr          bool
+	}{
+		{name: "default logging"},
+		{name: "debug logs to stderr", volumeAttributes: map[string]string{"logging": `{"level": "debug"}`}},
+		{name: "debug logs to files", volumeAttributes: map[string]string{"logging": `{"level": "debug", "destination": "file"}`}, wantLogsVolume: true},
+		{name: "invalid logging", volumeAttributes: map[string]string{"logging": `{"destination": "syslog"}`}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pv := &corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: testVolName},
+				Spec: corev1.PersistentVolumeSpec{
+					PersistentVolumeSource: corev1.PersistentVolumeSource{
+						CSI: &corev1.CSIPersistentVolumeSource{VolumeAttributes: tt.volumeAttributes},
+					},
+				},
+			}
+			mpPod, err := creator.Create(pod, pv)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected an error for an invalid logging configuration")
+				}
+				return
+			}
+			assert.NoError(t, err)
+
+			assert.Equals(t, tt.wantLogsVolume, mppod.HasLogsVolume(mpPod))
+			if !tt.wantLogsVolume {
+				return
+			}
+			volume := mpPod.Spec.Volumes[len(mpPod.Spec.Volumes)-1]
+			assert.Equals(t, mppod.LogsVolumeName, volume.Name)
+			// Debug logs must not be counted against the memory of the Mountpoint Pod
+			assert.Equals(t, corev1.StorageMediumDefault, volume.EmptyDir.Medium)
+			assert.Equals(t, int64(mppod.LogsEmptyDirSizeLimit), volume.EmptyDir.SizeLimit.Value())
+			assert.Equals(t, corev1.VolumeMount{Name: mppod.LogsVolumeName, MountPath: mppod.LogsDirPath}, mpPod.Spec.Containers[0].VolumeMounts[1])
+		})
+	}
+}
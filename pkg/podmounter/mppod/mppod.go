@@ -14,6 +14,19 @@ const (
 	AnnotationNeedsUnmount = constants.DriverName + "/needs-unmount"
 	// AnnotationNoNewWorkload is the annotation used to prevent new workloads from being assigned
 	AnnotationNoNewWorkload = constants.DriverName + "/no-new-workload"
+	// AnnotationLingeringSince records the time (RFC 3339) a Mountpoint Pod lost its last workload
+	// and started lingering to be reused by a republished volume
+	AnnotationLingeringSince = constants.DriverName + "/lingering-since"
+	// AnnotationDrainTimeout records how long a deleted Mountpoint Pod waits for its workloads to release its
+	// mount, before the node plugin detaches the mount from the workloads still using it
+	AnnotationDrainTimeout = constants.DriverName + "/drain-timeout"
+)
+
+// Pod finalizers
+const (
+	// FinalizerMountCleanup is added to Mountpoint Pods so they are only removed once the node plugin unmounted
+	// their source mount and cleaned up their credentials
+	FinalizerMountCleanup = constants.DriverName + "/mount-cleanup"
 )
 
 // Pod labels
@@ -17,6 +17,27 @@ const KnownPathMountError = "mount.err"
 // Mountpoint Pod is no longer needed and can cleany exit.
 const KnownPathMountExit = "mount.exit"
 
+// KnownPathMountHealth is the path of the health status file that's created by CSI Driver Node Pod when the mount
+// of Mountpoint stops responding. The liveness probe of the Mountpoint container fails while this file reports an
+// unhealthy mount, so kubelet restarts the container.
+const KnownPathMountHealth = "mount.health"
+
+// KnownPathMountReconnect is the path of the reconnect request file that's created by `scality-s3-csi-mounter` when
+// Mountpoint crashes, to request a new FUSE file descriptor from the CSI Driver Node Pod on [KnownPathMountReconnectSock].
+const KnownPathMountReconnect = "mount.reconnect"
+
+// KnownPathMountReconnectSock is the path of Unix socket that's used to pass a new FUSE file descriptor to the
+// Mountpoint Pod after a crash of Mountpoint.
+const KnownPathMountReconnectSock = "mount.reconnect.sock"
+
+// KnownPathMountControlSock is the path of Unix socket that's served by `scality-s3-csi-mounter` while Mountpoint runs,
+// for the CSI Driver Node Pod to push refreshed credentials to the Mountpoint Pod.
+const KnownPathMountControlSock = "mount.control.sock"
+
+// KnownPathMountMetrics is the path of the metrics snapshot file that's written by `scality-s3-csi-mounter` with the
+// metrics Mountpoint logs, for the CSI Driver Node Pod to export them per volume.
+const KnownPathMountMetrics = "mount.metrics"
+
 // KnownPathCredentials is the base directory for storing credential files.
 const KnownPathCredentials = "credentials"
 
@@ -0,0 +1,134 @@
+package mppod
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/volumecontext"
+)
+
+// PodOptions are the tolerations, extra labels and annotations, and topology spread constraints of Mountpoint Pods.
+// They are configured for all Mountpoint Pods and overridden per volume by the `mountpointPod*` volume attributes.
+type PodOptions struct {
+	// Tolerations of Mountpoint Pods and their Headroom Pods. Mountpoint Pods tolerate all taints if nil.
+	Tolerations []corev1.Toleration
+	// Labels are added to Mountpoint Pods, labels of the CSI driver cannot be overridden.
+	Labels map[string]string
+	// Annotations are added to Mountpoint Pods.
+	Annotations map[string]string
+	// TopologySpreadConstraints of Mountpoint Pods.
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint
+}
+
+// ParsePodOptions returns the options of Mountpoint Pods in the JSON-encoded `mountpointPod*` attributes of
+// `volumeAttributes`. Unset attributes are left empty.
+func ParsePodOptions(volumeAttributes map[string]string) (PodOptions, error) {
+	var options PodOptions
+	for _, attribute := range []struct {
+		key   string
+		value any
+	}{
+		{volumecontext.MountpointPodTolerations, &options.Tolerations},
+		{volumecontext.MountpointPodLabels, &options.Labels},
+		{volumecontext.MountpointPodAnnotations, &options.Annotations},
+		{volumecontext.MountpointPodTopologySpreadConstraints, &options.TopologySpreadConstraints},
+	} {
+		key := attribute.key
+		encoded := strings.TrimSpace(volumeAttributes[key])
+		if encoded == "" {
+			continue
+		}
+		decoder := json.NewDecoder(bytes.NewReader([]byte(encoded)))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(attribute.value); err != nil {
+			return PodOptions{}, fmt.Errorf("failed to parse %q: %w", key, err)
+		}
+	}
+	if err := options.validate(); err != nil {
+		return PodOptions{}, err
+	}
+	return options, nil
+}
+
+// validate returns an error if labels or annotations are not valid, or override labels of the CSI driver.
+func (o PodOptions) validate() error {
+	for key, value := range o.Labels {
+		if strings.HasPrefix(key, constants.DriverName+"/") {
+			return fmt.Errorf("label %q of %q is reserved for the CSI driver", key, volumecontext.MountpointPodLabels)
+		}
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid label %q of %q: %s", key, volumecontext.MountpointPodLabels, strings.Join(errs, ", "))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return fmt.Errorf("invalid value %q of label %q of %q: %s", value, key, volumecontext.MountpointPodLabels, strings.Join(errs, ", "))
+		}
+	}
+	for key := range o.Annotations {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid annotation %q of %q: %s", key, volumecontext.MountpointPodAnnotations, strings.Join(errs, ", "))
+		}
+	}
+	for _, constraint := range o.TopologySpreadConstraints {
+		if constraint.MaxSkew <= 0 || constraint.TopologyKey == "" || constraint.WhenUnsatisfiable == "" {
+			return fmt.Errorf("topology spread constraints of %q require maxSkew, topologyKey and whenUnsatisfiable", volumecontext.MountpointPodTopologySpreadConstraints)
+		}
+	}
+	return nil
+}
+
+// Override returns `o` overridden by the options of a volume: tolerations and topology spread constraints are
+// replaced if set, labels and annotations are merged.
+func (o PodOptions) Override(volume PodOptions) PodOptions {
+	if volume.Tolerations != nil {
+		o.Tolerations = volume.Tolerations
+	}
+	if volume.TopologySpreadConstraints != nil {
+		o.TopologySpreadConstraints = volume.TopologySpreadConstraints
+	}
+	o.Labels = merge(o.Labels, volume.Labels)
+	o.Annotations = merge(o.Annotations, volume.Annotations)
+	return o
+}
+
+// tolerations returns the tolerations of Mountpoint Pods and Headroom Pods.
+func (o PodOptions) tolerations() []corev1.Toleration {
+	if o.Tolerations != nil {
+		return o.Tolerations
+	}
+	return []corev1.Toleration{
+		// Tolerate all taints.
+		// - "NoScheduled" – If the Workload Pod gets scheduled to a node, Mountpoint Pod should also get
+		//   scheduled into the same node to provide the volume.
+		// - "NoExecute" – If the Workload Pod tolerates a "NoExecute" taint, Mountpoint Pod should also
+		//   tolerate it to keep running and provide volume for the Workload Pod.
+		//   If the Workload Pod would get descheduled and then the corresponding Mountpoint Pod
+		//   would also get descheduled naturally due to CSI volume lifecycle.
+		{Operator: corev1.TolerationOpExists},
+	}
+}
+
+// apply sets the options on `mpPod`, without overriding its existing labels.
+func (o PodOptions) apply(mpPod *corev1.Pod) {
+	mpPod.Spec.Tolerations = o.tolerations()
+	mpPod.Spec.TopologySpreadConstraints = o.TopologySpreadConstraints
+	mpPod.Labels = merge(o.Labels, mpPod.Labels)
+	mpPod.Annotations = merge(mpPod.Annotations, o.Annotations)
+}
+
+// merge returns the entries of `base` and `override`, `override` taking precedence.
+func merge(base, override map[string]string) map[string]string {
+	if len(base) == 0 && len(override) == 0 {
+		return base
+	}
+	merged := make(map[string]string, len(base)+len(override))
+	maps.Copy(merged, base)
+	maps.Copy(merged, override)
+	return merged
+}
@@ -0,0 +1,92 @@
+package mppod
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
+)
+
+// AnnotationSchedulingRetries records how many times a Mountpoint Pod stuck before running was recreated.
+const AnnotationSchedulingRetries = constants.DriverName + "/scheduling-retries"
+
+// A StuckCause is the cause of a Mountpoint Pod not starting.
+type StuckCause string
+
+// Causes of Mountpoint Pods not starting.
+const (
+	// StuckInsufficientResources means no node has enough resources for the Mountpoint Pod.
+	StuckInsufficientResources StuckCause = "InsufficientResources"
+	// StuckUnschedulable means the Mountpoint Pod cannot be scheduled for other reasons, like taints.
+	StuckUnschedulable StuckCause = "Unschedulable"
+	// StuckImagePull means the image of a container of the Mountpoint Pod cannot be pulled.
+	StuckImagePull StuckCause = "ImagePull"
+)
+
+// imagePullFailureReasons are reasons of waiting containers whose image cannot be pulled.
+var imagePullFailureReasons = map[string]bool{
+	"ErrImagePull":     true,
+	"ImagePullBackOff": true,
+	"InvalidImageName": true,
+}
+
+// insufficientResourcesMarkers are parts of scheduler messages telling a node lacks resources for a Pod.
+var insufficientResourcesMarkers = []string{"Insufficient ", "Too many pods"}
+
+// A Stuck describes why a Mountpoint Pod does not start.
+type Stuck struct {
+	Cause   StuckCause
+	Message string
+	// Since is when the Mountpoint Pod was last seen progressing, zero if unknown.
+	Since time.Time
+}
+
+func (s *Stuck) Error() string {
+	return fmt.Sprintf("Mountpoint Pod is stuck (%s): %s", s.Cause, s.Message)
+}
+
+// StuckState returns why `mpPod` does not start if it cannot be scheduled or its images cannot be pulled,
+// or nil if it is starting normally or already started.
+func StuckState(mpPod *corev1.Pod) *Stuck {
+	if mpPod.Status.Phase != corev1.PodPending {
+		return nil
+	}
+
+	for _, condition := range mpPod.Status.Conditions {
+		if condition.Type != corev1.PodScheduled || condition.Status != corev1.ConditionFalse || condition.Reason != corev1.PodReasonUnschedulable {
+			continue
+		}
+		cause := StuckUnschedulable
+		for _, marker := range insufficientResourcesMarkers {
+			if strings.Contains(condition.Message, marker) {
+				cause = StuckInsufficientResources
+				break
+			}
+		}
+		return &Stuck{Cause: cause, Message: condition.Message, Since: since(condition.LastTransitionTime, mpPod)}
+	}
+
+	statuses := append(append([]corev1.ContainerStatus{}, mpPod.Status.InitContainerStatuses...), mpPod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if waiting := status.State.Waiting; waiting != nil && imagePullFailureReasons[waiting.Reason] {
+			return &Stuck{
+				Cause:   StuckImagePull,
+				Message: fmt.Sprintf("container %s: %s: %s", status.Name, waiting.Reason, waiting.Message),
+				Since:   since(metav1.Time{}, mpPod),
+			}
+		}
+	}
+	return nil
+}
+
+// since returns `transition` if set, or the creation time of `mpPod` otherwise.
+func since(transition metav1.Time, mpPod *corev1.Pod) time.Time {
+	if !transition.IsZero() {
+		return transition.Time
+	}
+	return mpPod.CreationTimestamp.Time
+}
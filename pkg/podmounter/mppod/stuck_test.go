@@ -0,0 +1,76 @@
+package mppod_test
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestStuckState(t *testing.T) {
+	created := metav1.NewTime(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	transition := metav1.NewTime(created.Add(time.Minute))
+	unschedulable := func(message string) corev1.PodStatus {
+		return corev1.PodStatus{
+			Phase: corev1.PodPending,
+			Conditions: []corev1.PodCondition{{
+				Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonUnschedulable,
+				Message: message, LastTransitionTime: transition,
+			}},
+		}
+	}
+	waiting := func(reason string) corev1.PodStatus {
+		return corev1.PodStatus{
+			Phase: corev1.PodPending,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  mppod.ContainerName,
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason, Message: "pull failed"}},
+			}},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		status corev1.PodStatus
+		want   *mppod.Stuck
+	}{
+		{name: "running", status: corev1.PodStatus{Phase: corev1.PodRunning}},
+		{name: "pending to be scheduled", status: corev1.PodStatus{Phase: corev1.PodPending}},
+		{
+			name:   "insufficient resources",
+			status: unschedulable("0/3 nodes are available: 1 Insufficient memory."),
+			want:   &mppod.Stuck{Cause: mppod.StuckInsufficientResources, Message: "0/3 nodes are available: 1 Insufficient memory.", Since: transition.Time},
+		},
+		{
+			name:   "untolerated taint",
+			status: unschedulable("0/3 nodes are available: 1 node(s) had untolerated taint {dedicated: gpu}."),
+			want:   &mppod.Stuck{Cause: mppod.StuckUnschedulable, Message: "0/3 nodes are available: 1 node(s) had untolerated taint {dedicated: gpu}.", Since: transition.Time},
+		},
+		{
+			name:   "image pull back-off",
+			status: waiting("ImagePullBackOff"),
+			want:   &mppod.Stuck{Cause: mppod.StuckImagePull, Message: "container mountpoint: ImagePullBackOff: pull failed", Since: created.Time},
+		},
+		{name: "container creating", status: waiting("ContainerCreating")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created}, Status: tt.status}
+			got := mppod.StuckState(pod)
+			if tt.want == nil || got == nil {
+				assert.Equals(t, tt.want == nil, got == nil)
+				return
+			}
+			assert.Equals(t, tt.want.Cause, got.Cause)
+			assert.Equals(t, tt.want.Message, got.Message)
+			if !got.Since.Equal(tt.want.Since) {
+				t.Errorf("Expected stuck since %v, got %v", tt.want.Since, got.Since)
+			}
+		})
+	}
+}
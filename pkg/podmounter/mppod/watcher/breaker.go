@@ -0,0 +1,94 @@
+package watcher
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/driver/node/metrics"
+)
+
+// BreakerFailureThreshold is the number of consecutive failed list and watch requests after which the API server is
+// considered unavailable, and the circuit breaker of the [Watcher] opens.
+const BreakerFailureThreshold = 3
+
+// stalenessUpdateInterval is the interval between updates of the cache staleness metric.
+const stalenessUpdateInterval = 5 * time.Second
+
+// A breaker is the circuit breaker of a [Watcher], tracking whether the API server answers its list and watch
+// requests. Client-go retries failed requests with a backoff, the breaker only lets the watcher degrade gracefully:
+// Mountpoint Pods are served from the cache, and mounts waiting for new Pods fail with [ErrAPIServerUnavailable]
+// so kubelet retries them later.
+type breaker struct {
+	mu sync.Mutex
+	// failures is the number of consecutive failed requests.
+	failures int
+	// lastSuccess is the time of the last successful request, zero before the first one.
+	lastSuccess time.Time
+	// open is whether the API server is considered unavailable.
+	open bool
+	now  func() time.Time
+}
+
+func newBreaker() *breaker {
+	metrics.PodWatcherAPIAvailable.Set(1)
+	return &breaker{now: time.Now}
+}
+
+// record records the outcome of a list or watch request to the API server.
+func (b *breaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err == nil {
+		b.lastSuccess = b.now()
+		if b.open {
+			klog.Infof("mppod/watcher: Kubernetes API server is available again after %d failed requests", b.failures)
+			b.open = false
+			metrics.PodWatcherAPIAvailable.Set(1)
+			metrics.PodWatcherCacheStalenessSeconds.Set(0)
+		}
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	metrics.PodWatcherAPIFailuresTotal.Inc()
+	if b.failures == BreakerFailureThreshold {
+		klog.Warningf("mppod/watcher: Kubernetes API server is unavailable after %d failed requests, serving Mountpoint Pods from the cache: %v", b.failures, err)
+		b.open = true
+		metrics.PodWatcherAPIAvailable.Set(0)
+	}
+}
+
+// isOpen returns whether the API server is considered unavailable.
+func (b *breaker) isOpen() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.open
+}
+
+// staleness returns the time since the cache was last synced with the API server, zero while it is available.
+func (b *breaker) staleness() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.open || b.lastSuccess.IsZero() {
+		return 0
+	}
+	return b.now().Sub(b.lastSuccess)
+}
+
+// updateStaleness updates the cache staleness metric every [stalenessUpdateInterval] until `stopCh` is closed.
+func (b *breaker) updateStaleness(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(stalenessUpdateInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			metrics.PodWatcherCacheStalenessSeconds.Set(b.staleness().Seconds())
+		}
+	}
+}
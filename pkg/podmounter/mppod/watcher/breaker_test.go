@@ -0,0 +1,75 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := newBreaker()
+	b.now = func() time.Time { return now }
+	errUnavailable := errors.New("connection refused")
+
+	b.record(nil)
+	for range BreakerFailureThreshold - 1 {
+		b.record(errUnavailable)
+	}
+	assert.Equals(t, false, b.isOpen())
+	assert.Equals(t, time.Duration(0), b.staleness())
+
+	b.record(errUnavailable)
+	assert.Equals(t, true, b.isOpen())
+	now = now.Add(time.Minute)
+	assert.Equals(t, time.Minute, b.staleness())
+
+	b.record(nil)
+	assert.Equals(t, false, b.isOpen())
+	assert.Equals(t, time.Duration(0), b.staleness())
+
+	// Failures must be consecutive to open the breaker
+	b.record(errUnavailable)
+	b.record(nil)
+	for range BreakerFailureThreshold - 1 {
+		b.record(errUnavailable)
+	}
+	assert.Equals(t, false, b.isOpen())
+}
+
+func TestWaitWithAPIServerUnavailable(t *testing.T) {
+	client := fake.NewClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "mp-running", Namespace: "mount-s3"},
+		Spec:       corev1.PodSpec{NodeName: "test-node"},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	})
+	w := New(client, "mount-s3", "test-node", 10*time.Second)
+	stopCh := make(chan struct{})
+	t.Cleanup(func() { close(stopCh) })
+	assert.NoError(t, w.Start(stopCh))
+
+	for range BreakerFailureThreshold {
+		w.breaker.record(errors.New("connection refused"))
+	}
+	assert.Equals(t, false, w.APIServerAvailable())
+
+	// Mountpoint Pods are served from the cache
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	pod, err := w.Wait(ctx, "mp-running")
+	assert.NoError(t, err)
+	assert.Equals(t, "mp-running", pod.Name)
+
+	// Mountpoint Pods missing from the cache might exist
+	_, err = w.Wait(ctx, "mp-missing")
+	if !errors.Is(err, ErrAPIServerUnavailable) {
+		t.Fatalf("Expected %v, got %v", ErrAPIServerUnavailable, err)
+	}
+}
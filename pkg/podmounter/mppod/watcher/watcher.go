@@ -5,15 +5,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/client-go/informers"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 	listerv1 "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
 )
 
 // ErrPodNotFound returned when the Mountpoint Pod could not be found in the cluster.
@@ -25,13 +31,58 @@ var ErrPodNotReady = errors.New("mppod/watcher: mountpoint pod not ready")
 // ErrCacheDesync returned when the Pod informer cache failed to synchronize within the specified timeout.
 var ErrCacheDesync = errors.New("mppod/watcher: failed to sync pod informer cache within the timeout")
 
+// ErrAPIServerUnavailable returned when the Mountpoint Pod is not in the cache and the Kubernetes API server is
+// unavailable, see [BreakerFailureThreshold].
+var ErrAPIServerUnavailable = errors.New("mppod/watcher: kubernetes API server unavailable, mountpoint pod not in the cache")
+
 // Watcher provides functionality to watch and wait for Mountpoint Pods in the cluster.
-// It uses the Kubernetes informer to watch and cache Pod events.
-// It filters pods to only those scheduled on the specified node to reduce API server load.
+// It uses Kubernetes informers to watch and cache Pod events.
+// It filters pods to only those scheduled on the specified node, or not scheduled yet, to reduce API server load:
+// the informers list and watch Pods of the Mountpoint Pod namespace with a field selector on their node.
+//
+// All pending [Watcher.Wait] calls share a single event handler of the informers, so Pods mounting many volumes
+// do not register, and get a replay of the informer cache for, a handler per volume.
+//
+// While the API server is unavailable, Mountpoint Pods are served from the cache, see [breaker].
 type Watcher struct {
-	informer cache.SharedIndexInformer
-	lister   listerv1.PodNamespaceLister
-	nodeID   string // Node ID to filter pods (required)
+	// informer watches Pods scheduled on the node, unscheduled watches Pods not scheduled yet.
+	informer    cache.SharedIndexInformer
+	unscheduled cache.SharedIndexInformer
+	lister      listerv1.PodNamespaceLister
+	// unscheduledLister lists Pods not scheduled yet.
+	unscheduledLister listerv1.PodNamespaceLister
+	nodeID            string // Node ID to filter pods (required)
+	breaker           *breaker
+
+	mu sync.Mutex
+	// waiters are the pending [Watcher.Wait] calls by Mountpoint Pod name.
+	waiters map[string]map[*waiter]struct{}
+}
+
+// A waiter is a pending [Watcher.Wait] or [Watcher.WaitScheduled] call.
+type waiter struct {
+	// done returns whether the waited for Pod, scheduled on the node, is in the expected state.
+	done     func(pod *corev1.Pod) bool
+	podFound atomic.Bool
+	podChan  chan *corev1.Pod
+	// stuck is the last observed reason of the Pod not starting, nil if it was not stuck.
+	stuck atomic.Pointer[mppod.Stuck]
+	// stuckChan receives reasons of the Pod not starting that are not worth waiting for.
+	stuckChan chan *mppod.Stuck
+}
+
+// observeStuck records whether `pod` is stuck, and notifies the waiter if it is not worth waiting for.
+// Image pulls are retried by kubelet with a backoff longer than callers wait, unlike scheduling failures which can be
+// resolved quickly by preemption or autoscaling.
+func (wt *waiter) observeStuck(pod *corev1.Pod) {
+	stuck := mppod.StuckState(pod)
+	wt.stuck.Store(stuck)
+	if stuck != nil && stuck.Cause == mppod.StuckImagePull {
+		select {
+		case wt.stuckChan <- stuck:
+		default:
+		}
+	}
 }
 
 // New creates a new [Watcher] with the given Kubernetes client, Mountpoint Pod namespace, nodeID, and resync duration.
@@ -40,26 +91,69 @@ func New(client kubernetes.Interface, namespace string, nodeID string, defaultRe
 	if nodeID == "" {
 		panic("watcher: nodeID is required and cannot be empty")
 	}
-	factory := informers.NewSharedInformerFactoryWithOptions(client, defaultResync, informers.WithNamespace(namespace))
-	informer := factory.Core().V1().Pods().Informer()
-	lister := factory.Core().V1().Pods().Lister().Pods(namespace)
-	return &Watcher{informer, lister, nodeID}
+	w := &Watcher{nodeID: nodeID, breaker: newBreaker(), waiters: make(map[string]map[*waiter]struct{})}
+	w.informer = w.newInformer(client, namespace, fields.OneTermEqualSelector("spec.nodeName", nodeID), defaultResync)
+	w.unscheduled = w.newInformer(client, namespace, fields.OneTermEqualSelector("spec.nodeName", ""), defaultResync)
+	w.lister = listerv1.NewPodLister(w.informer.GetIndexer()).Pods(namespace)
+	w.unscheduledLister = listerv1.NewPodLister(w.unscheduled.GetIndexer()).Pods(namespace)
+	return w
+}
+
+// newInformer returns an informer of Pods of `namespace` matching `selector`, whose requests to the API server are
+// recorded by the circuit breaker of the watcher.
+func (w *Watcher) newInformer(client kubernetes.Interface, namespace string, selector fields.Selector, defaultResync time.Duration) cache.SharedIndexInformer {
+	pods := client.CoreV1().Pods(namespace)
+	listWatch := &cache.ListWatch{
+		ListWithContextFunc: func(ctx context.Context, options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = selector.String()
+			list, err := pods.List(ctx, options)
+			w.breaker.record(err)
+			return list, err
+		},
+		WatchFuncWithContext: func(ctx context.Context, options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = selector.String()
+			watcher, err := pods.Watch(ctx, options)
+			w.breaker.record(err)
+			return watcher, err
+		},
+	}
+	return cache.NewSharedIndexInformer(listWatch, &corev1.Pod{}, defaultResync, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
 }
 
 // Start begins watching for Pod events in the cluster.
 // It returns [ErrCacheDesync] if the informer cache fails to sync before [stopCh] is cancalled.
 // The provided [stopCh] can be used to stop the watching process.
 func (w *Watcher) Start(stopCh <-chan struct{}) error {
-	go w.informer.Run(stopCh)
-	if !cache.WaitForCacheSync(stopCh, w.informer.HasSynced) {
+	// Set a watcher for Pod create & update events, shared by all waiters
+	for _, informer := range []cache.SharedIndexInformer{w.informer, w.unscheduled} {
+		_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc: w.notifyWaiters,
+			UpdateFunc: func(old, new any) {
+				w.notifyWaiters(new)
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add event handler: %w", err)
+		}
+		go informer.Run(stopCh)
+	}
+	go w.breaker.updateStaleness(stopCh)
+
+	if !cache.WaitForCacheSync(stopCh, w.informer.HasSynced, w.unscheduled.HasSynced) {
 		return ErrCacheDesync
 	}
 	return nil
 }
 
+// APIServerAvailable returns whether the Kubernetes API server is available, Mountpoint Pods are served from a
+// possibly stale cache otherwise.
+func (w *Watcher) APIServerAvailable() bool {
+	return !w.breaker.isOpen()
+}
+
 // Get retrieves a Mountpoint Pod by name from the cache
 func (w *Watcher) Get(name string) (*corev1.Pod, error) {
-	pod, err := w.lister.Get(name)
+	pod, err := w.getCached(name)
 	if err != nil {
 		return nil, err
 	}
@@ -71,64 +165,66 @@ func (w *Watcher) Get(name string) (*corev1.Pod, error) {
 }
 
 // Wait blocks until the specified Mountpoint Pod is found and ready, or until the context is cancelled.
+// It returns a [*mppod.Stuck] error if the Pod cannot be scheduled or its images cannot be pulled, as soon as
+// image pulls fail or once the context is cancelled for scheduling failures.
 func (w *Watcher) Wait(ctx context.Context, name string) (*corev1.Pod, error) {
-	// Set a watcher for Pod create & update events
-	var podFound atomic.Bool
-	podChan := make(chan *corev1.Pod, 1)
-	handle, err := w.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc: func(obj any) {
-			pod := obj.(*corev1.Pod)
-			if pod.Name == name && w.isNodeMatch(pod) {
-				podFound.Store(true)
-				if w.isPodReady(pod) {
-					podChan <- pod
-				}
-			}
-		},
-		UpdateFunc: func(old, new any) {
-			pod := new.(*corev1.Pod)
-			if pod.Name == name && w.isNodeMatch(pod) {
-				podFound.Store(true)
-				if w.isPodReady(pod) {
-					podChan <- pod
-				}
-			}
-		},
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to add event handler for %s: %w", name, err)
-	}
+	return w.wait(ctx, name, w.isPodReady)
+}
+
+// WaitScheduled blocks until the specified Mountpoint Pod is scheduled on the node, or until the context is
+// cancelled. Like [Watcher.Wait], it returns a [*mppod.Stuck] error if the Pod cannot be scheduled once the context is
+// cancelled, and [ErrPodNotFound] if it was not scheduled on the node.
+func (w *Watcher) WaitScheduled(ctx context.Context, name string) (*corev1.Pod, error) {
+	return w.wait(ctx, name, func(*corev1.Pod) bool { return true })
+}
+
+// wait blocks until the specified Mountpoint Pod is scheduled on the node and `done` returns true for it, or until
+// the context is cancelled.
+func (w *Watcher) wait(ctx context.Context, name string, done func(pod *corev1.Pod) bool) (*corev1.Pod, error) {
+	wt := &waiter{done: done, podChan: make(chan *corev1.Pod, 1), stuckChan: make(chan *mppod.Stuck, 1)}
+	w.addWaiter(name, wt)
 
-	// Ensure to remove event handler at the end
-	defer func() {
-		_ = w.informer.RemoveEventHandler(handle)
-	}()
+	// Ensure to remove the waiter at the end
+	defer w.removeWaiter(name, wt)
 
 	// Check if the Pod already exists
-	pod, err := w.lister.Get(name)
+	pod, err := w.getCached(name)
 	if err == nil && w.isNodeMatch(pod) {
-		podFound.Store(true)
-		if w.isPodReady(pod) {
-			// Pod already exists and ready
+		wt.podFound.Store(true)
+		if wt.done(pod) {
+			// Pod already exists and is in the expected state
 			return pod, nil
 		}
 	}
+	if err == nil && w.isNodeMatchOrUnscheduled(pod) {
+		wt.observeStuck(pod)
+	}
 
 	if err != nil && !apierrors.IsNotFound(err) {
 		// We got a different error than "not found", just propagate it
 		return nil, fmt.Errorf("failed to get pod %s: %w", name, err)
 	}
 
-	// Pod does not exists or not ready yet. We set a watcher for create & update events,
-	// and will receive the Pod from `podChan` once its ready.
+	// Pod does not exists or not ready yet. We'll receive the Pod from `podChan` once its ready.
 	select {
-	case pod := <-podChan:
+	case pod := <-wt.podChan:
 		// Pod found and ready
 		return pod, nil
+	case stuck := <-wt.stuckChan:
+		return nil, stuck
 	case <-ctx.Done():
 		// We didn't received the Pod within the timeout
 
-		if podFound.Load() {
+		if stuck := wt.stuck.Load(); stuck != nil {
+			return nil, stuck
+		}
+
+		if w.breaker.isOpen() {
+			// The Pod might have been created or become ready without the watcher knowing
+			return nil, ErrAPIServerUnavailable
+		}
+
+		if wt.podFound.Load() {
 			// Pod was found, but was not ready
 			return nil, ErrPodNotReady
 		}
@@ -137,6 +233,60 @@ func (w *Watcher) Wait(ctx context.Context, name string) (*corev1.Pod, error) {
 	}
 }
 
+// getCached returns Mountpoint Pod `name` from the cache of Pods scheduled on the node, or of unscheduled Pods.
+func (w *Watcher) getCached(name string) (*corev1.Pod, error) {
+	pod, err := w.lister.Get(name)
+	if apierrors.IsNotFound(err) {
+		return w.unscheduledLister.Get(name)
+	}
+	return pod, err
+}
+
+// addWaiter registers `wt` to be notified about Mountpoint Pod `name`.
+func (w *Watcher) addWaiter(name string, wt *waiter) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.waiters[name] == nil {
+		w.waiters[name] = make(map[*waiter]struct{})
+	}
+	w.waiters[name][wt] = struct{}{}
+}
+
+// removeWaiter unregisters `wt` from notifications about Mountpoint Pod `name`.
+func (w *Watcher) removeWaiter(name string, wt *waiter) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.waiters[name], wt)
+	if len(w.waiters[name]) == 0 {
+		delete(w.waiters, name)
+	}
+}
+
+// notifyWaiters notifies waiters of the created or updated Pod `obj`.
+func (w *Watcher) notifyWaiters(obj any) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok || !w.isNodeMatchOrUnscheduled(pod) {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for wt := range w.waiters[pod.Name] {
+		wt.observeStuck(pod)
+		if !w.isNodeMatch(pod) {
+			continue
+		}
+		wt.podFound.Store(true)
+		if wt.done(pod) {
+			// Do not block the informer if the waiter already got the Pod
+			select {
+			case wt.podChan <- pod:
+			default:
+			}
+		}
+	}
+}
+
 // isPodReady returns whether the given Mountpoint Pod is ready.
 func (w *Watcher) isPodReady(pod *corev1.Pod) bool {
 	return pod.Status.Phase == corev1.PodRunning
@@ -147,7 +297,14 @@ func (w *Watcher) isNodeMatch(pod *corev1.Pod) bool {
 	return pod.Spec.NodeName == w.nodeID
 }
 
-// AddEventHandler adds an event handler to the underlying informer.
+// isNodeMatchOrUnscheduled returns whether the given pod is scheduled on this watcher's node or not scheduled yet.
+// Mountpoint Pods are named after the workload they are created for, so an unscheduled Pod waited for by name is
+// bound to be scheduled on this node.
+func (w *Watcher) isNodeMatchOrUnscheduled(pod *corev1.Pod) bool {
+	return pod.Spec.NodeName == "" || w.isNodeMatch(pod)
+}
+
+// AddEventHandler adds an event handler to the underlying informer of Pods scheduled on the node.
 // This allows external components to register callbacks for pod events.
 // Returns the registration handle and any error that occurred.
 func (w *Watcher) AddEventHandler(handler cache.ResourceEventHandler) (cache.ResourceEventHandlerRegistration, error) {
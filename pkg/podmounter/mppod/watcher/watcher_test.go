@@ -2,16 +2,23 @@ package watcher_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/podmounter/mppod/watcher"
 	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
 )
@@ -84,6 +91,74 @@ func TestGettingNotYetScheduledPod(t *testing.T) {
 	assert.Equals(t, mpPod.pod, pod)
 }
 
+func TestWaitingForScheduledPod(t *testing.T) {
+	client := fake.NewClientset()
+	mpPod := createMountpointPod(t, client, testMountpointPodName)
+	mpPod.pod.Spec.NodeName = ""
+	mpPod.pod, _ = client.CoreV1().Pods(testMountpointPodNamespace).Update(context.Background(), mpPod.pod, metav1.UpdateOptions{})
+
+	mpPodWatcher := createAndStartWatcher(t, client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := mpPodWatcher.WaitScheduled(ctx, testMountpointPodName)
+	assert.Equals(t, watcher.ErrPodNotFound, err)
+
+	// Scheduled Pods are returned before they are ready
+	mpPod.pod.Spec.NodeName = "test-node-1"
+	mpPod.pod, err = client.CoreV1().Pods(testMountpointPodNamespace).Update(context.Background(), mpPod.pod, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	pod, err := mpPodWatcher.WaitScheduled(context.Background(), testMountpointPodName)
+	assert.NoError(t, err)
+	assert.Equals(t, corev1.PodPhase(""), pod.Status.Phase)
+}
+
+func TestWaitingForStuckPod(t *testing.T) {
+	t.Run("image cannot be pulled", func(t *testing.T) {
+		client := fake.NewClientset()
+		mpPod := createMountpointPod(t, client, testMountpointPodName)
+		mpPod.setStatus(corev1.PodStatus{
+			Phase: corev1.PodPending,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  mppod.ContainerName,
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}},
+			}},
+		})
+		mpPodWatcher := createAndStartWatcher(t, client)
+
+		// Image pull failures are reported without waiting for the context to be done
+		_, err := mpPodWatcher.Wait(context.Background(), testMountpointPodName)
+		var stuck *mppod.Stuck
+		if !errors.As(err, &stuck) || stuck.Cause != mppod.StuckImagePull {
+			t.Fatalf("Expected the Pod to be stuck pulling its image, got %v", err)
+		}
+	})
+
+	t.Run("pod cannot be scheduled", func(t *testing.T) {
+		client := fake.NewClientset()
+		mpPod := createMountpointPod(t, client, testMountpointPodName)
+		mpPod.pod.Spec.NodeName = ""
+		mpPod.pod, _ = client.CoreV1().Pods(testMountpointPodNamespace).Update(context.Background(), mpPod.pod, metav1.UpdateOptions{})
+		mpPod.setStatus(corev1.PodStatus{
+			Phase: corev1.PodPending,
+			Conditions: []corev1.PodCondition{{
+				Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonUnschedulable,
+				Message: "0/3 nodes are available: 3 Insufficient cpu.",
+			}},
+		})
+		mpPodWatcher := createAndStartWatcher(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err := mpPodWatcher.Wait(ctx, testMountpointPodName)
+		var stuck *mppod.Stuck
+		if !errors.As(err, &stuck) || stuck.Cause != mppod.StuckInsufficientResources {
+			t.Fatalf("Expected the Pod to be stuck on insufficient resources, got %v", err)
+		}
+	})
+}
+
 func TestGet(t *testing.T) {
 	t.Run("get existing pod", func(t *testing.T) {
 		client := fake.NewClientset()
@@ -135,6 +210,58 @@ func TestGettingPodsConcurrently(t *testing.T) {
 	}
 }
 
+func TestWaitingForManyPodsConcurrently(t *testing.T) {
+	client := fake.NewClientset()
+
+	mpPodWatcher := createAndStartWatcher(t, client)
+
+	const numPods = 12
+	foundPods := make(chan *corev1.Pod)
+	for i := range numPods {
+		go func() {
+			pod, err := mpPodWatcher.Wait(context.Background(), fmt.Sprintf("%s-%d", testMountpointPodName, i))
+			assert.NoError(t, err)
+			foundPods <- pod
+		}()
+	}
+
+	expected := make(map[string]bool)
+	for i := range numPods {
+		mpPod := createMountpointPod(t, client, fmt.Sprintf("%s-%d", testMountpointPodName, i))
+		mpPod.run()
+		expected[mpPod.pod.Name] = true
+	}
+
+	for range numPods {
+		foundPod := <-foundPods
+		if !expected[foundPod.Name] {
+			t.Fatalf("Unexpected or duplicate Mountpoint Pod %s", foundPod.Name)
+		}
+		delete(expected, foundPod.Name)
+	}
+}
+
+func TestWatcherListsPodsOfTheNodeOnly(t *testing.T) {
+	client := fake.NewClientset()
+	var mu sync.Mutex
+	var selectors []string
+	client.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		list := action.(k8stesting.ListAction)
+		assert.Equals(t, testMountpointPodNamespace, list.GetNamespace())
+		selectors = append(selectors, list.GetListRestrictions().Fields.String())
+		return false, nil, nil
+	})
+
+	createAndStartWatcher(t, client)
+
+	mu.Lock()
+	defer mu.Unlock()
+	slices.Sort(selectors)
+	assert.Equals(t, []string{"spec.nodeName=", "spec.nodeName=test-node-1"}, selectors)
+}
+
 func createAndStartWatcher(t *testing.T, client kubernetes.Interface) *watcher.Watcher {
 	mpPodWatcher := watcher.New(client, testMountpointPodNamespace, "test-node-1", 10*time.Second)
 
@@ -178,6 +305,14 @@ func createMountpointPod(t *testing.T, client kubernetes.Interface, name string)
 	return &mountpointPod{t, client, pod}
 }
 
+func (mp *mountpointPod) setStatus(status corev1.PodStatus) {
+	mp.t.Helper()
+	mp.pod.Status = status
+	var err error
+	mp.pod, err = mp.client.CoreV1().Pods(testMountpointPodNamespace).UpdateStatus(context.Background(), mp.pod, metav1.UpdateOptions{})
+	assert.NoError(mp.t, err)
+}
+
 func (mp *mountpointPod) run() {
 	mp.t.Helper()
 	mp.pod.Status.Phase = corev1.PodRunning
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -14,8 +15,23 @@ import (
 )
 
 type Client interface {
-	CreateBucket(ctx context.Context, bucket string) error
+	CreateBucket(ctx context.Context, bucket string, opts BucketOptions) error
 	DeleteBucket(ctx context.Context, bucket string) error
+	PutBucketTagging(ctx context.Context, bucket string, tags map[string]string) error
+}
+
+// BucketOptions configures the data protection of buckets created by [Client.CreateBucket].
+type BucketOptions struct {
+	// Versioning enables versioning of the bucket. It is always enabled with ObjectLock.
+	Versioning bool
+	// ObjectLock enables object lock on the bucket with a default retention, disabled if nil.
+	ObjectLock *ObjectLock
+}
+
+// ObjectLock is the default retention of objects written to a bucket with object lock.
+type ObjectLock struct {
+	Mode          types.ObjectLockRetentionMode
+	RetentionDays int32
 }
 
 type Config struct {
@@ -28,6 +44,9 @@ type Config struct {
 type S3API interface {
 	CreateBucket(ctx context.Context, params *s3.CreateBucketInput, optFns ...func(*s3.Options)) (*s3.CreateBucketOutput, error)
 	DeleteBucket(ctx context.Context, params *s3.DeleteBucketInput, optFns ...func(*s3.Options)) (*s3.DeleteBucketOutput, error)
+	PutBucketTagging(ctx context.Context, params *s3.PutBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.PutBucketTaggingOutput, error)
+	PutBucketVersioning(ctx context.Context, params *s3.PutBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.PutBucketVersioningOutput, error)
+	PutObjectLockConfiguration(ctx context.Context, params *s3.PutObjectLockConfigurationInput, optFns ...func(*s3.Options)) (*s3.PutObjectLockConfigurationOutput, error)
 }
 
 type client struct {
@@ -60,22 +79,64 @@ func New(ctx context.Context, cfg Config) (Client, error) {
 	}, nil
 }
 
-func (c *client) CreateBucket(ctx context.Context, bucket string) error {
+// CreateBucket creates `bucket` and configures its versioning and object lock with `opts`. Object lock can only be
+// enabled when the bucket is created.
+func (c *client) CreateBucket(ctx context.Context, bucket string, opts BucketOptions) error {
 	klog.V(4).Infof("Creating S3 bucket: %s", bucket)
-	_, err := c.s3.CreateBucket(ctx, &s3.CreateBucketInput{
+	input := &s3.CreateBucketInput{
 		Bucket: aws.String(bucket),
-	})
+	}
+	if opts.ObjectLock != nil {
+		input.ObjectLockEnabledForBucket = aws.Bool(true)
+	}
+	_, err := c.s3.CreateBucket(ctx, input)
 	if err != nil {
 		var bucketAlreadyExists *types.BucketAlreadyExists
 		var bucketAlreadyOwnedByYou *types.BucketAlreadyOwnedByYou
 		if errors.As(err, &bucketAlreadyExists) || errors.As(err, &bucketAlreadyOwnedByYou) {
 			klog.V(4).Infof("Bucket %s already exists, continuing", bucket)
-			return nil
+			return c.configureBucket(ctx, bucket, opts)
 		}
 		klog.Errorf("Failed to create bucket %s: %v", bucket, err)
 		return fmt.Errorf("failed to create bucket %s: %w", bucket, err)
 	}
 	klog.V(4).Infof("Successfully created bucket: %s", bucket)
+	return c.configureBucket(ctx, bucket, opts)
+}
+
+// configureBucket enables versioning of `bucket` and sets the default retention of its object lock with `opts`.
+// Buckets with object lock are versioned by S3, versioning is not set explicitly.
+func (c *client) configureBucket(ctx context.Context, bucket string, opts BucketOptions) error {
+	if opts.ObjectLock != nil {
+		klog.V(4).Infof("Setting default %s retention of %d days on S3 bucket %s", opts.ObjectLock.Mode, opts.ObjectLock.RetentionDays, bucket)
+		_, err := c.s3.PutObjectLockConfiguration(ctx, &s3.PutObjectLockConfigurationInput{
+			Bucket: aws.String(bucket),
+			ObjectLockConfiguration: &types.ObjectLockConfiguration{
+				ObjectLockEnabled: types.ObjectLockEnabledEnabled,
+				Rule: &types.ObjectLockRule{
+					DefaultRetention: &types.DefaultRetention{
+						Mode: opts.ObjectLock.Mode,
+						Days: aws.Int32(opts.ObjectLock.RetentionDays),
+					},
+				},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to configure object lock of bucket %s: %w", bucket, err)
+		}
+		return nil
+	}
+
+	if opts.Versioning {
+		klog.V(4).Infof("Enabling versioning of S3 bucket %s", bucket)
+		_, err := c.s3.PutBucketVersioning(ctx, &s3.PutBucketVersioningInput{
+			Bucket:                  aws.String(bucket),
+			VersioningConfiguration: &types.VersioningConfiguration{Status: types.BucketVersioningStatusEnabled},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to enable versioning of bucket %s: %w", bucket, err)
+		}
+	}
 	return nil
 }
 
@@ -104,3 +165,32 @@ func (c *client) DeleteBucket(ctx context.Context, bucket string) error {
 	klog.V(4).Infof("Successfully deleted bucket: %s", bucket)
 	return nil
 }
+
+func (c *client) PutBucketTagging(ctx context.Context, bucket string, tags map[string]string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	tagSet := make([]types.Tag, 0, len(keys))
+	for _, k := range keys {
+		tagSet = append(tagSet, types.Tag{Key: aws.String(k), Value: aws.String(tags[k])})
+	}
+
+	klog.V(4).Infof("Tagging S3 bucket %s with %d tags", bucket, len(tagSet))
+	_, err := c.s3.PutBucketTagging(ctx, &s3.PutBucketTaggingInput{
+		Bucket:  aws.String(bucket),
+		Tagging: &types.Tagging{TagSet: tagSet},
+	})
+	if err != nil {
+		klog.Errorf("Failed to tag bucket %s: %v", bucket, err)
+		return fmt.Errorf("failed to tag bucket %s: %w", bucket, err)
+	}
+	klog.V(4).Infof("Successfully tagged bucket: %s", bucket)
+	return nil
+}
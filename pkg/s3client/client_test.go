@@ -43,6 +43,10 @@ func TestNew(t *testing.T) {
 type mockS3API struct {
 	createBucketFunc func(ctx context.Context, params *s3.CreateBucketInput, optFns ...func(*s3.Options)) (*s3.CreateBucketOutput, error)
 	deleteBucketFunc func(ctx context.Context, params *s3.DeleteBucketInput, optFns ...func(*s3.Options)) (*s3.DeleteBucketOutput, error)
+	putTaggingFunc   func(ctx context.Context, params *s3.PutBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.PutBucketTaggingOutput, error)
+	versioning       []*s3.PutBucketVersioningInput
+	objectLock       []*s3.PutObjectLockConfigurationInput
+	objectLockErr    error
 }
 
 func (m *mockS3API) CreateBucket(ctx context.Context, params *s3.CreateBucketInput, optFns ...func(*s3.Options)) (*s3.CreateBucketOutput, error) {
@@ -59,6 +63,23 @@ func (m *mockS3API) DeleteBucket(ctx context.Context, params *s3.DeleteBucketInp
 	return &s3.DeleteBucketOutput{}, nil
 }
 
+func (m *mockS3API) PutBucketTagging(ctx context.Context, params *s3.PutBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.PutBucketTaggingOutput, error) {
+	if m.putTaggingFunc != nil {
+		return m.putTaggingFunc(ctx, params, optFns...)
+	}
+	return &s3.PutBucketTaggingOutput{}, nil
+}
+
+func (m *mockS3API) PutBucketVersioning(ctx context.Context, params *s3.PutBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.PutBucketVersioningOutput, error) {
+	m.versioning = append(m.versioning, params)
+	return &s3.PutBucketVersioningOutput{}, nil
+}
+
+func (m *mockS3API) PutObjectLockConfiguration(ctx context.Context, params *s3.PutObjectLockConfigurationInput, optFns ...func(*s3.Options)) (*s3.PutObjectLockConfigurationOutput, error) {
+	m.objectLock = append(m.objectLock, params)
+	return &s3.PutObjectLockConfigurationOutput{}, m.objectLockErr
+}
+
 func TestCreateBucket(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -111,14 +132,68 @@ func TestCreateBucket(t *testing.T) {
 			}
 			client := &client{s3: mockAPI}
 
-			err := client.CreateBucket(context.Background(), tt.bucketName)
+			err := client.CreateBucket(context.Background(), tt.bucketName, BucketOptions{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("CreateBucket() error = %v, wantErr %v", err, tt.wantErr)
 			}
+			if len(mockAPI.versioning) > 0 || len(mockAPI.objectLock) > 0 {
+				t.Errorf("Expected no versioning or object lock configuration, got %v and %v", mockAPI.versioning, mockAPI.objectLock)
+			}
 		})
 	}
 }
 
+func TestCreateBucketWithProtection(t *testing.T) {
+	t.Run("versioning", func(t *testing.T) {
+		mockAPI := &mockS3API{}
+		client := &client{s3: mockAPI}
+		if err := client.CreateBucket(context.Background(), "versioned", BucketOptions{Versioning: true}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(mockAPI.versioning) != 1 || mockAPI.versioning[0].VersioningConfiguration.Status != types.BucketVersioningStatusEnabled {
+			t.Fatalf("Expected versioning to be enabled, got %v", mockAPI.versioning)
+		}
+	})
+
+	t.Run("object lock", func(t *testing.T) {
+		var created *s3.CreateBucketInput
+		mockAPI := &mockS3API{
+			createBucketFunc: func(ctx context.Context, params *s3.CreateBucketInput, optFns ...func(*s3.Options)) (*s3.CreateBucketOutput, error) {
+				created = params
+				return &s3.CreateBucketOutput{}, nil
+			},
+		}
+		client := &client{s3: mockAPI}
+		opts := BucketOptions{Versioning: true, ObjectLock: &ObjectLock{Mode: types.ObjectLockRetentionModeGovernance, RetentionDays: 7}}
+		if err := client.CreateBucket(context.Background(), "locked", opts); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !aws.ToBool(created.ObjectLockEnabledForBucket) {
+			t.Fatal("Expected object lock to be enabled on creation")
+		}
+		if len(mockAPI.objectLock) != 1 {
+			t.Fatalf("Expected object lock to be configured once, got %v", mockAPI.objectLock)
+		}
+		retention := mockAPI.objectLock[0].ObjectLockConfiguration.Rule.DefaultRetention
+		if retention.Mode != types.ObjectLockRetentionModeGovernance || aws.ToInt32(retention.Days) != 7 {
+			t.Fatalf("Expected a 7 days governance retention, got %+v", retention)
+		}
+		// Buckets with object lock are always versioned
+		if len(mockAPI.versioning) != 0 {
+			t.Fatalf("Expected versioning not to be set explicitly, got %v", mockAPI.versioning)
+		}
+	})
+
+	t.Run("object lock not supported", func(t *testing.T) {
+		mockAPI := &mockS3API{objectLockErr: errors.New("NotImplemented")}
+		client := &client{s3: mockAPI}
+		opts := BucketOptions{ObjectLock: &ObjectLock{Mode: types.ObjectLockRetentionModeCompliance, RetentionDays: 1}}
+		if err := client.CreateBucket(context.Background(), "locked", opts); err == nil {
+			t.Fatal("Expected an error")
+		}
+	})
+}
+
 func TestDeleteBucket(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -184,3 +259,67 @@ func TestDeleteBucket(t *testing.T) {
 		})
 	}
 }
+
+func TestPutBucketTagging(t *testing.T) {
+	t.Run("no tags does not call S3", func(t *testing.T) {
+		called := false
+		mockAPI := &mockS3API{
+			putTaggingFunc: func(ctx context.Context, params *s3.PutBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.PutBucketTaggingOutput, error) {
+				called = true
+				return &s3.PutBucketTaggingOutput{}, nil
+			},
+		}
+		client := &client{s3: mockAPI}
+
+		if err := client.PutBucketTagging(context.Background(), "test-bucket", nil); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if called {
+			t.Fatal("Expected PutBucketTagging not to be called for empty tags")
+		}
+	})
+
+	t.Run("tags are sent sorted by key", func(t *testing.T) {
+		var got []types.Tag
+		mockAPI := &mockS3API{
+			putTaggingFunc: func(ctx context.Context, params *s3.PutBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.PutBucketTaggingOutput, error) {
+				if aws.ToString(params.Bucket) != "test-bucket" {
+					t.Errorf("Expected bucket %q, got %q", "test-bucket", aws.ToString(params.Bucket))
+				}
+				got = params.Tagging.TagSet
+				return &s3.PutBucketTaggingOutput{}, nil
+			},
+		}
+		client := &client{s3: mockAPI}
+
+		err := client.PutBucketTagging(context.Background(), "test-bucket", map[string]string{
+			"project":             "apollo",
+			"data-classification": "internal",
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(got) != 2 {
+			t.Fatalf("Expected 2 tags, got %d", len(got))
+		}
+		if aws.ToString(got[0].Key) != "data-classification" || aws.ToString(got[0].Value) != "internal" {
+			t.Errorf("Unexpected first tag: %s=%s", aws.ToString(got[0].Key), aws.ToString(got[0].Value))
+		}
+		if aws.ToString(got[1].Key) != "project" || aws.ToString(got[1].Value) != "apollo" {
+			t.Errorf("Unexpected second tag: %s=%s", aws.ToString(got[1].Key), aws.ToString(got[1].Value))
+		}
+	})
+
+	t.Run("S3 error is propagated", func(t *testing.T) {
+		mockAPI := &mockS3API{
+			putTaggingFunc: func(ctx context.Context, params *s3.PutBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.PutBucketTaggingOutput, error) {
+				return nil, errors.New("access denied")
+			},
+		}
+		client := &client{s3: mockAPI}
+
+		if err := client.PutBucketTagging(context.Background(), "test-bucket", map[string]string{"k": "v"}); err == nil {
+			t.Fatal("Expected error but got none")
+		}
+	})
+}
@@ -0,0 +1,173 @@
+// Package utapi provides a minimal client for Scality UTAPI, the utilization API of Scality S3 servers.
+package utapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// UTAPI aggregates metrics in 15 minutes intervals, time ranges must start on an interval boundary.
+const metricsInterval = 15 * time.Minute
+
+// signingService is the SigV4 service name UTAPI expects requests to be signed with.
+const signingService = "s3"
+
+// BucketMetrics represents the metrics of a single bucket as returned by the ListMetrics action.
+// Counters are two-element ranges `[start, end]` covering the requested time range.
+type BucketMetrics struct {
+	BucketName      string           `json:"bucketName"`
+	TimeRange       []int64          `json:"timeRange"`
+	StorageUtilized []int64          `json:"storageUtilized"`
+	NumberOfObjects []int64          `json:"numberOfObjects"`
+	IncomingBytes   int64            `json:"incomingBytes"`
+	OutgoingBytes   int64            `json:"outgoingBytes"`
+	Operations      map[string]int64 `json:"operations"`
+}
+
+// CurrentStorageUtilized returns the storage utilized at the end of the metrics time range.
+func (m BucketMetrics) CurrentStorageUtilized() int64 {
+	return lastOrZero(m.StorageUtilized)
+}
+
+// CurrentNumberOfObjects returns the number of objects at the end of the metrics time range.
+func (m BucketMetrics) CurrentNumberOfObjects() int64 {
+	return lastOrZero(m.NumberOfObjects)
+}
+
+// Duration returns the duration of the metrics time range.
+func (m BucketMetrics) Duration() time.Duration {
+	if len(m.TimeRange) != 2 || m.TimeRange[1] <= m.TimeRange[0] {
+		return 0
+	}
+	return time.Duration(m.TimeRange[1]-m.TimeRange[0]) * time.Millisecond
+}
+
+// TotalOperations returns the number of operations of all kinds over the metrics time range.
+func (m BucketMetrics) TotalOperations() int64 {
+	var total int64
+	for _, count := range m.Operations {
+		total += count
+	}
+	return total
+}
+
+// Config holds the configuration of a UTAPI client.
+type Config struct {
+	EndpointURL string
+	Region      string
+	Credentials aws.CredentialsProvider
+	HTTPClient  *http.Client
+}
+
+// A Client queries UTAPI metrics.
+type Client struct {
+	config Config
+	signer *v4.Signer
+	now    func() time.Time
+}
+
+// New returns a new UTAPI client for given `config`.
+func New(config Config) (*Client, error) {
+	if config.EndpointURL == "" {
+		return nil, fmt.Errorf("utapi: endpoint URL is required")
+	}
+	if config.Credentials == nil {
+		return nil, fmt.Errorf("utapi: credentials are required")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	config.EndpointURL = strings.TrimSuffix(config.EndpointURL, "/")
+
+	return &Client{config: config, signer: v4.NewSigner(), now: time.Now}, nil
+}
+
+// ListBucketMetrics returns metrics of `bucket` over the last `window`.
+func (c *Client) ListBucketMetrics(ctx context.Context, bucket string, window time.Duration) (BucketMetrics, error) {
+	end := c.now().UTC()
+	start := end.Add(-window).Truncate(metricsInterval)
+
+	body, err := json.Marshal(map[string]any{
+		"buckets":   []string{bucket},
+		"timeRange": []int64{start.UnixMilli(), end.UnixMilli()},
+	})
+	if err != nil {
+		return BucketMetrics{}, fmt.Errorf("utapi: failed to encode request: %w", err)
+	}
+
+	var metrics []BucketMetrics
+	if err := c.do(ctx, "/buckets?Action=ListMetrics", body, &metrics); err != nil {
+		return BucketMetrics{}, err
+	}
+
+	for _, m := range metrics {
+		if m.BucketName == bucket {
+			if len(m.TimeRange) != 2 {
+				m.TimeRange = []int64{start.UnixMilli(), end.UnixMilli()}
+			}
+			return m, nil
+		}
+	}
+	return BucketMetrics{}, fmt.Errorf("utapi: no metrics returned for bucket %q", bucket)
+}
+
+// do sends a signed POST request with `body` to `path` and decodes the JSON response into `out`.
+func (c *Client) do(ctx context.Context, path string, body []byte, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.EndpointURL+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("utapi: failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	creds, err := c.config.Credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("utapi: failed to retrieve credentials: %w", err)
+	}
+
+	payloadHash := sha256.Sum256(body)
+	err = c.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(payloadHash[:]), signingService, c.config.Region, c.now().UTC())
+	if err != nil {
+		return fmt.Errorf("utapi: failed to sign request: %w", err)
+	}
+
+	resp, err := c.config.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("utapi: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("utapi: failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("utapi: unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("utapi: failed to decode response: %w", err)
+	}
+	return nil
+}
+
+func lastOrZero(values []int64) int64 {
+	if len(values) == 0 {
+		return 0
+	}
+	return values[len(values)-1]
+}
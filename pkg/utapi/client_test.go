@@ -0,0 +1,139 @@
+package utapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+func testClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := New(Config{
+		EndpointURL: server.URL + "/",
+		Credentials: credentials.NewStaticCredentialsProvider("access", "secret", ""),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.now = func() time.Time { return time.Date(2025, 1, 1, 12, 7, 0, 0, time.UTC) }
+	return client
+}
+
+func TestNew(t *testing.T) {
+	creds := credentials.NewStaticCredentialsProvider("access", "secret", "")
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{name: "valid", config: Config{EndpointURL: "http://utapi:8100", Credentials: creds}},
+		{name: "missing endpoint", config: Config{Credentials: creds}, wantErr: true},
+		{name: "missing credentials", config: Config{EndpointURL: "http://utapi:8100"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestListBucketMetrics(t *testing.T) {
+	client := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/buckets" || r.URL.Query().Get("Action") != "ListMetrics" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+		}
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=access/") {
+			t.Errorf("Expected SigV4 signed request, got Authorization %q", r.Header.Get("Authorization"))
+		}
+
+		var body struct {
+			Buckets   []string `json:"buckets"`
+			TimeRange []int64  `json:"timeRange"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode body: %v", err)
+		}
+		if len(body.Buckets) != 1 || body.Buckets[0] != "my-bucket" {
+			t.Errorf("Unexpected buckets %v", body.Buckets)
+		}
+		wantStart := time.Date(2025, 1, 1, 11, 0, 0, 0, time.UTC).UnixMilli()
+		if len(body.TimeRange) != 2 || body.TimeRange[0] != wantStart {
+			t.Errorf("Expected time range to start at %d, got %v", wantStart, body.TimeRange)
+		}
+
+		_, _ = w.Write([]byte(`[{"bucketName":"my-bucket","storageUtilized":[10,2048],"numberOfObjects":[1,7],"operations":{"s3:GetObject":3}}]`))
+	})
+
+	metrics, err := client.ListBucketMetrics(context.Background(), "my-bucket", time.Hour)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if metrics.CurrentStorageUtilized() != 2048 {
+		t.Errorf("Expected storage utilized 2048, got %d", metrics.CurrentStorageUtilized())
+	}
+	if metrics.CurrentNumberOfObjects() != 7 {
+		t.Errorf("Expected 7 objects, got %d", metrics.CurrentNumberOfObjects())
+	}
+	if metrics.Operations["s3:GetObject"] != 3 {
+		t.Errorf("Expected 3 GetObject operations, got %v", metrics.Operations)
+	}
+	if metrics.Duration() != time.Hour+7*time.Minute {
+		t.Errorf("Expected the requested time range when none is returned, got %v", metrics.Duration())
+	}
+}
+
+func TestBucketMetricsRates(t *testing.T) {
+	metrics := BucketMetrics{
+		TimeRange:  []int64{0, 15 * 60 * 1000},
+		Operations: map[string]int64{"s3:GetObject": 900, "s3:PutObject": 90, "s3:ListBucket": 0},
+	}
+	if metrics.Duration() != 15*time.Minute {
+		t.Errorf("Expected a 15m time range, got %v", metrics.Duration())
+	}
+	if metrics.TotalOperations() != 990 {
+		t.Errorf("Expected 990 operations, got %d", metrics.TotalOperations())
+	}
+	if (BucketMetrics{TimeRange: []int64{10, 5}}).Duration() != 0 {
+		t.Errorf("Expected an invalid time range to have no duration")
+	}
+}
+
+func TestListBucketMetricsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{name: "non-200 status", status: http.StatusForbidden, body: "AccessDenied", wantErr: "unexpected status 403"},
+		{name: "invalid JSON", status: http.StatusOK, body: "not json", wantErr: "failed to decode response"},
+		{name: "bucket missing from response", status: http.StatusOK, body: `[]`, wantErr: "no metrics returned"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			})
+
+			_, err := client.ListBucketMetrics(context.Background(), "my-bucket", time.Hour)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package util
+
+import (
+	"os"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
+)
+
+// DriverName returns the name of this instance of the driver, registered with kubelet and referenced by the
+// CSIDriver object, PersistentVolumes and StorageClasses.
+// It looks for `CSI_DRIVER_NAME` variable, and returns [constants.DriverName] if its not defined. Distinct names
+// allow installing several instances of the driver in a cluster, e.g. one per S3 endpoint.
+func DriverName() string {
+	driverName := os.Getenv("CSI_DRIVER_NAME")
+	if driverName == "" {
+		return constants.DriverName
+	}
+	return driverName
+}
+
+// IsDefaultDriverName returns whether this instance of the driver uses the default [constants.DriverName].
+func IsDefaultDriverName() bool {
+	return DriverName() == constants.DriverName
+}
@@ -0,0 +1,35 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+
+	"k8s.io/client-go/rest"
+)
+
+// Environment variables configuring the client-side rate limit of requests to the Kubernetes API server, the default
+// of the client is kept if unset, e.g. 5 requests per second with bursts of 10 for client-go.
+const (
+	EnvKubeAPIQPS   = "KUBE_API_QPS"
+	EnvKubeAPIBurst = "KUBE_API_BURST"
+)
+
+// SetKubeAPIRateLimit sets the client-side rate limit of `config` to `qps` requests per second with bursts of
+// `burst` requests, keeping the current limits for empty values.
+func SetKubeAPIRateLimit(config *rest.Config, qps, burst string) error {
+	if qps != "" {
+		value, err := strconv.ParseFloat(qps, 32)
+		if err != nil || value <= 0 {
+			return fmt.Errorf("invalid Kubernetes API QPS %q, must be a positive number", qps)
+		}
+		config.QPS = float32(value)
+	}
+	if burst != "" {
+		value, err := strconv.Atoi(burst)
+		if err != nil || value <= 0 {
+			return fmt.Errorf("invalid Kubernetes API burst %q, must be a positive integer", burst)
+		}
+		config.Burst = value
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package util_test
+
+import (
+	"testing"
+
+	"k8s.io/client-go/rest"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+)
+
+func TestSetKubeAPIRateLimit(t *testing.T) {
+	config := &rest.Config{QPS: 5, Burst: 10}
+	assert.NoError(t, util.SetKubeAPIRateLimit(config, "", ""))
+	assert.Equals(t, float32(5), config.QPS)
+	assert.Equals(t, 10, config.Burst)
+
+	assert.NoError(t, util.SetKubeAPIRateLimit(config, "2.5", "20"))
+	assert.Equals(t, float32(2.5), config.QPS)
+	assert.Equals(t, 20, config.Burst)
+
+	for _, limit := range [][2]string{{"0", ""}, {"fast", ""}, {"", "-1"}, {"", "1.5"}} {
+		if err := util.SetKubeAPIRateLimit(config, limit[0], limit[1]); err == nil {
+			t.Errorf("Expected an error for QPS %q and burst %q", limit[0], limit[1])
+		}
+	}
+}
@@ -0,0 +1,279 @@
+// Package bench benchmarks the data path of a mounted volume with fio-like workloads, to track performance
+// regressions of the driver and Mountpoint across version bumps.
+//
+// Workloads only use file operations supported by Mountpoint: files are written sequentially once and never
+// overwritten, so they can run against any volume mounted with `allow-delete`.
+package bench
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	mathrand "math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Workloads run by [Run], in order.
+const (
+	WorkloadSequentialWrite = "sequentialWrite"
+	WorkloadSequentialRead  = "sequentialRead"
+	WorkloadRandomRead      = "randomRead"
+	WorkloadSmallFiles      = "smallFiles"
+)
+
+// Config configures the benchmark.
+type Config struct {
+	// Dir is the directory of the mounted volume the workloads run in, in a subdirectory removed once done.
+	Dir string `json:"dir"`
+	// FileSize is the size in bytes of the file written, then read sequentially and randomly.
+	FileSize int64 `json:"fileSize"`
+	// BlockSize is the size in bytes of each read or write.
+	BlockSize int `json:"blockSize"`
+	// RandomReads is the number of blocks read at random offsets of the file.
+	RandomReads int `json:"randomReads"`
+	// SmallFiles is the number of files created by the small files workload.
+	SmallFiles int `json:"smallFiles"`
+	// SmallFileSize is the size in bytes of each small file.
+	SmallFileSize int `json:"smallFileSize"`
+}
+
+// DefaultConfig returns the default [Config] benchmarking `dir`.
+func DefaultConfig(dir string) Config {
+	return Config{
+		Dir:           dir,
+		FileSize:      1 << 30,
+		BlockSize:     256 << 10,
+		RandomReads:   1000,
+		SmallFiles:    500,
+		SmallFileSize: 4 << 10,
+	}
+}
+
+// Latency holds percentiles of the latency of the operations of a workload, in milliseconds.
+type Latency struct {
+	P50 float64 `json:"p50Ms"`
+	P90 float64 `json:"p90Ms"`
+	P99 float64 `json:"p99Ms"`
+	Max float64 `json:"maxMs"`
+}
+
+// WorkloadResult is the result of a workload.
+type WorkloadResult struct {
+	Name       string  `json:"name"`
+	Operations int     `json:"operations"`
+	Bytes      int64   `json:"bytes"`
+	DurationS  float64 `json:"durationSeconds"`
+	// ThroughputMiBs is the number of MiB transferred per second.
+	ThroughputMiBs float64 `json:"throughputMiBs"`
+	// OperationsPerS is the number of operations per second, files created per second for small files.
+	OperationsPerS float64 `json:"operationsPerSecond"`
+	Latency        Latency `json:"latency"`
+}
+
+// Results are the results of all workloads of a benchmark.
+type Results struct {
+	// Labels identify the benchmarked setup, e.g. the versions of the driver and Mountpoint.
+	Labels    map[string]string `json:"labels,omitempty"`
+	StartTime time.Time         `json:"startTime"`
+	Config    Config            `json:"config"`
+	Workloads []WorkloadResult  `json:"workloads"`
+}
+
+// Run runs all workloads in a new subdirectory of `config.Dir`, and removes it once done.
+func Run(config Config) (*Results, error) {
+	if config.FileSize <= 0 || config.BlockSize <= 0 || config.SmallFileSize <= 0 {
+		return nil, errors.New("file, block and small file sizes must be positive")
+	}
+	dir, err := os.MkdirTemp(config.Dir, "bench-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create benchmark directory in %q: %w", config.Dir, err)
+	}
+	defer os.RemoveAll(dir)
+
+	results := &Results{StartTime: time.Now().UTC(), Config: config}
+	file := filepath.Join(dir, "data")
+	for _, workload := range []struct {
+		name string
+		run  func() (*recorder, error)
+	}{
+		{WorkloadSequentialWrite, func() (*recorder, error) { return sequentialWrite(file, config) }},
+		{WorkloadSequentialRead, func() (*recorder, error) { return sequentialRead(file, config) }},
+		{WorkloadRandomRead, func() (*recorder, error) { return randomRead(file, config) }},
+		{WorkloadSmallFiles, func() (*recorder, error) { return smallFiles(filepath.Join(dir, "small"), config) }},
+	} {
+		recorder, err := workload.run()
+		if err != nil {
+			return nil, fmt.Errorf("workload %s failed: %w", workload.name, err)
+		}
+		results.Workloads = append(results.Workloads, recorder.result(workload.name))
+	}
+	return results, nil
+}
+
+// sequentialWrite writes `config.FileSize` bytes to a new file at `path`, one block at a time.
+func sequentialWrite(path string, config Config) (*recorder, error) {
+	block := make([]byte, config.BlockSize)
+	if _, err := rand.Read(block); err != nil {
+		return nil, err
+	}
+	r := newRecorder()
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	for written := int64(0); written < config.FileSize; {
+		n := int(min(int64(len(block)), config.FileSize-written))
+		start := time.Now()
+		if _, err := f.Write(block[:n]); err != nil {
+			f.Close()
+			return nil, err
+		}
+		r.record(time.Since(start), n)
+		written += int64(n)
+	}
+	// Mountpoint uploads the last part of the file on close, which is part of the write
+	start := time.Now()
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	r.extend(time.Since(start))
+	r.stop()
+	return r, nil
+}
+
+// sequentialRead reads the file at `path` from start to end, one block at a time.
+func sequentialRead(path string, config Config) (*recorder, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	block := make([]byte, config.BlockSize)
+	r := newRecorder()
+	for {
+		start := time.Now()
+		n, err := f.Read(block)
+		if n > 0 {
+			r.record(time.Since(start), n)
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	r.stop()
+	return r, nil
+}
+
+// randomRead reads `config.RandomReads` blocks at random offsets of the file at `path`.
+func randomRead(path string, config Config) (*recorder, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	block := make([]byte, config.BlockSize)
+	maxOffset := max(config.FileSize-int64(config.BlockSize), 0)
+	r := newRecorder()
+	for range config.RandomReads {
+		offset := mathrand.Int64N(maxOffset + 1)
+		start := time.Now()
+		n, err := f.ReadAt(block, offset)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		r.record(time.Since(start), n)
+	}
+	r.stop()
+	return r, nil
+}
+
+// smallFiles creates `config.SmallFiles` files of `config.SmallFileSize` bytes in `dir`, each operation being the
+// creation, write and close of a file.
+func smallFiles(dir string, config Config) (*recorder, error) {
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		return nil, err
+	}
+	data := make([]byte, config.SmallFileSize)
+	if _, err := rand.Read(data); err != nil {
+		return nil, err
+	}
+	r := newRecorder()
+	for i := range config.SmallFiles {
+		start := time.Now()
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("file-%06d", i)), data, 0o644); err != nil {
+			return nil, err
+		}
+		r.record(time.Since(start), len(data))
+	}
+	r.stop()
+	return r, nil
+}
+
+// A recorder records the latency and size of the operations of a workload.
+type recorder struct {
+	start     time.Time
+	duration  time.Duration
+	latencies []time.Duration
+	bytes     int64
+}
+
+func newRecorder() *recorder {
+	return &recorder{start: time.Now()}
+}
+
+func (r *recorder) record(latency time.Duration, bytes int) {
+	r.latencies = append(r.latencies, latency)
+	r.bytes += int64(bytes)
+}
+
+// extend adds `latency` to the last operation, e.g. for the upload of the last part of a file on close.
+func (r *recorder) extend(latency time.Duration) {
+	if len(r.latencies) > 0 {
+		r.latencies[len(r.latencies)-1] += latency
+	}
+}
+
+func (r *recorder) stop() {
+	r.duration = time.Since(r.start)
+}
+
+func (r *recorder) result(name string) WorkloadResult {
+	seconds := r.duration.Seconds()
+	result := WorkloadResult{
+		Name:       name,
+		Operations: len(r.latencies),
+		Bytes:      r.bytes,
+		DurationS:  seconds,
+	}
+	if seconds > 0 {
+		result.ThroughputMiBs = float64(r.bytes) / (1 << 20) / seconds
+		result.OperationsPerS = float64(len(r.latencies)) / seconds
+	}
+	sorted := slices.Clone(r.latencies)
+	slices.Sort(sorted)
+	result.Latency = Latency{
+		P50: percentile(sorted, 50),
+		P90: percentile(sorted, 90),
+		P99: percentile(sorted, 99),
+		Max: percentile(sorted, 100),
+	}
+	return result
+}
+
+// percentile returns the `p`th percentile of `sorted` latencies in milliseconds, with the nearest-rank method.
+func percentile(sorted []time.Duration, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	rank = min(max(rank, 1), len(sorted))
+	return float64(sorted[rank-1]) / float64(time.Millisecond)
+}
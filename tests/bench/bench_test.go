@@ -0,0 +1,50 @@
+package bench_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/util/testutil/assert"
+	"github.com/scality/mountpoint-s3-csi-driver/tests/bench"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	config := bench.Config{
+		Dir:           dir,
+		FileSize:      1<<20 + 123,
+		BlockSize:     64 << 10,
+		RandomReads:   10,
+		SmallFiles:    5,
+		SmallFileSize: 512,
+	}
+
+	results, err := bench.Run(config)
+	assert.NoError(t, err)
+
+	names := []string{}
+	for _, workload := range results.Workloads {
+		names = append(names, workload.Name)
+		if workload.Latency.P50 > workload.Latency.P99 || workload.Latency.P99 > workload.Latency.Max {
+			t.Errorf("Expected ordered latency percentiles of %s, got %+v", workload.Name, workload.Latency)
+		}
+	}
+	assert.Equals(t, []string{bench.WorkloadSequentialWrite, bench.WorkloadSequentialRead, bench.WorkloadRandomRead, bench.WorkloadSmallFiles}, names)
+
+	write, read := results.Workloads[0], results.Workloads[1]
+	assert.Equals(t, config.FileSize, write.Bytes)
+	assert.Equals(t, config.FileSize, read.Bytes)
+	assert.Equals(t, 17, write.Operations)
+	assert.Equals(t, 10, results.Workloads[2].Operations)
+	assert.Equals(t, 5, results.Workloads[3].Operations)
+	assert.Equals(t, int64(5*512), results.Workloads[3].Bytes)
+
+	// The benchmark directory is removed once done
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Equals(t, 0, len(entries))
+	if time.Since(results.StartTime) > time.Minute {
+		t.Errorf("Expected start time of the benchmark, got %v", results.StartTime)
+	}
+}
@@ -0,0 +1,72 @@
+// Command bench benchmarks the data path of a mounted volume and prints the results as JSON, see package bench.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/scality/mountpoint-s3-csi-driver/tests/bench"
+)
+
+// labels are `key=value` flags labelling the results.
+type labels map[string]string
+
+func (l labels) String() string {
+	return fmt.Sprint(map[string]string(l))
+}
+
+func (l labels) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("invalid label %q, must be key=value", value)
+	}
+	l[key] = val
+	return nil
+}
+
+func main() {
+	config := bench.DefaultConfig("")
+	resultLabels := labels{}
+	flag.StringVar(&config.Dir, "dir", "", "Directory of the mounted volume to benchmark.")
+	flag.Int64Var(&config.FileSize, "file-size", config.FileSize, "Size in bytes of the file written and read.")
+	flag.IntVar(&config.BlockSize, "block-size", config.BlockSize, "Size in bytes of each read or write.")
+	flag.IntVar(&config.RandomReads, "random-reads", config.RandomReads, "Number of blocks read at random offsets.")
+	flag.IntVar(&config.SmallFiles, "small-files", config.SmallFiles, "Number of small files created.")
+	flag.IntVar(&config.SmallFileSize, "small-file-size", config.SmallFileSize, "Size in bytes of each small file.")
+	flag.Var(resultLabels, "label", "Label of the results as key=value, e.g. the driver version. Can be repeated.")
+	output := flag.String("output", "-", "File to write the JSON results to, - for standard output.")
+	flag.Parse()
+
+	if config.Dir == "" {
+		fmt.Fprintln(os.Stderr, "--dir is required")
+		os.Exit(2)
+	}
+
+	results, err := bench.Run(config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Benchmark failed: %v\n", err)
+		os.Exit(1)
+	}
+	if len(resultLabels) > 0 {
+		results.Labels = resultLabels
+	}
+
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to encode results: %v\n", err)
+		os.Exit(1)
+	}
+	data = append(data, '\n')
+	if *output == "-" {
+		_, err = os.Stdout.Write(data)
+	} else {
+		err = os.WriteFile(*output, data, 0o644)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write results: %v\n", err)
+		os.Exit(1)
+	}
+}
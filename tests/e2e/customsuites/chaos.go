@@ -0,0 +1,408 @@
+// This file implements a chaos test suite, which randomly deletes the Mountpoint Pods serving a volume while
+// workloads continuously write and read it, to validate that I/O either fails fast with clear errors or recovers,
+// and that completed writes are never corrupted.
+// This test suite is disabled by default and can be enabled with the --chaos flag.
+package customsuites
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
+	e2evolume "k8s.io/kubernetes/test/e2e/framework/volume"
+	storageframework "k8s.io/kubernetes/test/e2e/storage/framework"
+	admissionapi "k8s.io/pod-security-admission/api"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// DefaultChaosDuration is the default duration Mountpoint Pods are deleted for
+	DefaultChaosDuration = 5 * time.Minute
+	// DefaultChaosKillInterval is the default average interval between two deletions of Mountpoint Pods
+	DefaultChaosKillInterval = 30 * time.Second
+	// DefaultChaosWorkloads is the default number of workload Pods performing I/O on the volume
+	DefaultChaosWorkloads = 2
+
+	// chaosFailFast is how long a failed I/O operation may take, slower failures are considered hung
+	chaosFailFast = 30 * time.Second
+	// chaosIOTimeout is how long workloads wait for an I/O operation before killing it, it must exceed chaosFailFast
+	// for hung operations to be reported
+	chaosIOTimeout = 2 * chaosFailFast
+	// chaosRecoveryTimeout is how long workloads are given to complete or fail an I/O operation once chaos stops
+	chaosRecoveryTimeout = 5 * time.Minute
+	// chaosFileSize is the size of the files written by workloads
+	chaosFileSize = 256 * 1024
+	// chaosLogDir is the directory of the workload Pods where I/O operations are logged, outside of the volume
+	chaosLogDir = "/chaos"
+)
+
+// chaosWorkloadScript writes files of random data to the volume in a loop and reads each of them back, logging to
+// chaosLogDir:
+// - completed: "<time> <name> <md5>" for each write that succeeded
+// - failures: "<time> <read|write> <name> <exit code> <seconds> <error>" for each operation that failed
+// - corrupt: "<time> <name> <expected md5> <actual md5>" for each read returning other data than written
+const chaosWorkloadScript = `trap exit TERM
+cd "$LOG_DIR"
+fail() { echo "$(date +%s) $1 $2 $3 $(($(date +%s) - start)) $(echo $4)" >> failures; }
+i=0
+while true; do
+  i=$((i+1))
+  name=$(hostname)-$i
+  head -c "$FILE_SIZE" /dev/urandom > data
+  sum=$(md5sum data | cut -d' ' -f1)
+  start=$(date +%s)
+  out=$(timeout "$IO_TIMEOUT" cp data "/mnt/volume1/$name" 2>&1); rc=$?
+  if [ $rc -ne 0 ]; then fail write "$name" $rc "$out"; sleep 1; continue; fi
+  echo "$(date +%s) $name $sum" >> completed
+  start=$(date +%s)
+  out=$(timeout "$IO_TIMEOUT" md5sum "/mnt/volume1/$name" 2>&1); rc=$?
+  if [ $rc -ne 0 ]; then fail read "$name" $rc "$out"
+  elif [ "${out%% *}" != "$sum" ]; then echo "$(date +%s) $name $sum ${out%% *}" >> corrupt; fi
+  sleep 1
+done`
+
+// ChaosConfig configures the chaos test suite.
+type ChaosConfig struct {
+	// Duration is how long Mountpoint Pods are deleted for
+	Duration time.Duration
+	// KillInterval is the average interval between two deletions of Mountpoint Pods, each interval is picked
+	// randomly between half and one and a half of it
+	KillInterval time.Duration
+	// Workloads is the number of workload Pods performing I/O on the volume
+	Workloads int
+	// Seed seeds the random choices of the suite, 0 picks a random seed which is logged to reproduce the run
+	Seed int64
+}
+
+// chaosWrite is a write logged as completed by a workload.
+type chaosWrite struct {
+	at   int64
+	name string
+	sum  string
+}
+
+// chaosFailure is an I/O operation logged as failed by a workload.
+type chaosFailure struct {
+	at       int64
+	op       string
+	name     string
+	exitCode int
+	seconds  int
+	message  string
+}
+
+// hung returns whether the operation was killed after chaosIOTimeout, or took longer than chaosFailFast to fail.
+func (f chaosFailure) hung() bool {
+	return f.exitCode == 124 || f.exitCode == 143 || time.Duration(f.seconds)*time.Second > chaosFailFast
+}
+
+// chaosLog holds the I/O operations logged by a workload.
+type chaosLog struct {
+	completed []chaosWrite
+	failures  []chaosFailure
+	corrupt   []string
+}
+
+// lastAt returns the time of the last operation completed or failed, 0 if there is none.
+func (l chaosLog) lastAt() int64 {
+	var last int64
+	for _, w := range l.completed {
+		last = max(last, w.at)
+	}
+	for _, f := range l.failures {
+		last = max(last, f.at)
+	}
+	return last
+}
+
+// recoveredAfter returns whether a write completed after `at`.
+func (l chaosLog) recoveredAfter(at int64) bool {
+	for _, w := range l.completed {
+		if w.at > at {
+			return true
+		}
+	}
+	return false
+}
+
+// parseChaosLog parses the `completed`, `failures` and `corrupt` logs of a workload, see chaosWorkloadScript.
+func parseChaosLog(completed, failures, corrupt string) (chaosLog, error) {
+	var l chaosLog
+	for _, line := range strings.Split(strings.TrimSpace(completed), "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return l, fmt.Errorf("malformed completed write %q", line)
+		}
+		at, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return l, fmt.Errorf("malformed completed write %q: %w", line, err)
+		}
+		l.completed = append(l.completed, chaosWrite{at: at, name: fields[1], sum: fields[2]})
+	}
+	for _, line := range strings.Split(strings.TrimSpace(failures), "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.SplitN(line, " ", 6)
+		if len(fields) < 5 {
+			return l, fmt.Errorf("malformed failure %q", line)
+		}
+		var failure chaosFailure
+		var errs []error
+		var err error
+		failure.at, err = strconv.ParseInt(fields[0], 10, 64)
+		errs = append(errs, err)
+		failure.op, failure.name = fields[1], fields[2]
+		failure.exitCode, err = strconv.Atoi(fields[3])
+		errs = append(errs, err)
+		failure.seconds, err = strconv.Atoi(fields[4])
+		errs = append(errs, err)
+		if err := errors.NewAggregate(errs); err != nil {
+			return l, fmt.Errorf("malformed failure %q: %w", line, err)
+		}
+		if len(fields) == 6 {
+			failure.message = strings.TrimSpace(fields[5])
+		}
+		l.failures = append(l.failures, failure)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(corrupt), "\n") {
+		if line != "" {
+			l.corrupt = append(l.corrupt, line)
+		}
+	}
+	return l, nil
+}
+
+// s3ChaosTestSuite implements a test suite deleting Mountpoint Pods while workloads use their volume.
+type s3ChaosTestSuite struct {
+	tsInfo storageframework.TestSuiteInfo
+	config ChaosConfig
+}
+
+// InitS3ChaosTestSuite returns a function initializing a test suite that runs `config.Workloads` Pods continuously
+// writing and reading a volume, while deleting a random Mountpoint Pod of the volume every `config.KillInterval` on
+// average for `config.Duration`. It asserts that:
+// - Failed I/O operations fail within chaosFailFast with an error message, instead of hanging
+// - Once chaos stops, each workload either completes writes again or keeps failing fast
+// - Every completed write reads back with the data written, during chaos and from a new mount once it stopped
+func InitS3ChaosTestSuite(config ChaosConfig) func() storageframework.TestSuite {
+	return func() storageframework.TestSuite {
+		return &s3ChaosTestSuite{
+			tsInfo: storageframework.TestSuiteInfo{
+				Name: "chaos",
+				TestPatterns: []storageframework.TestPattern{
+					storageframework.DefaultFsPreprovisionedPV,
+				},
+			},
+			config: config,
+		}
+	}
+}
+
+// GetTestSuiteInfo returns information about the test suite.
+func (t *s3ChaosTestSuite) GetTestSuiteInfo() storageframework.TestSuiteInfo {
+	return t.tsInfo
+}
+
+// SkipUnsupportedTests is a no-op, the suite only uses pre-provisioned volumes.
+func (t *s3ChaosTestSuite) SkipUnsupportedTests(_ storageframework.TestDriver, _ storageframework.TestPattern) {
+}
+
+// DefineTests defines the test deleting Mountpoint Pods and checking the workloads.
+func (t *s3ChaosTestSuite) DefineTests(driver storageframework.TestDriver, pattern storageframework.TestPattern) {
+	type local struct {
+		resource *storageframework.VolumeResource
+		config   *storageframework.PerTestConfig
+	}
+	var l local
+	f := framework.NewFrameworkWithCustomTimeouts("chaos", storageframework.GetDriverTimeouts(driver))
+	f.NamespacePodSecurityLevel = admissionapi.LevelBaseline
+
+	cleanup := func(ctx context.Context) {
+		// The volume cannot be deleted while Pods use it
+		framework.ExpectNoError(deleteAllPodsWithWait(ctx, f, chaosRecoveryTimeout), "while deleting pods")
+		if l.resource != nil {
+			framework.ExpectNoError(l.resource.CleanupResource(ctx), "while cleanup resource")
+		}
+	}
+	ginkgo.BeforeEach(func(ctx context.Context) {
+		l = local{}
+		l.config = driver.PrepareTest(ctx, f)
+		ginkgo.DeferCleanup(cleanup)
+	})
+
+	makeWorkloadPod := func(i int) *v1.Pod {
+		pod := e2epod.MakePod(f.Namespace.Name, nil, []*v1.PersistentVolumeClaim{l.resource.Pvc}, admissionapi.LevelBaseline, chaosWorkloadScript)
+		pod.Name = fmt.Sprintf("chaos-workload-%d", i)
+		pod.Spec.Volumes = append(pod.Spec.Volumes, v1.Volume{
+			Name:         "chaos-log",
+			VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}},
+		})
+		container := &pod.Spec.Containers[0]
+		container.VolumeMounts = append(container.VolumeMounts, v1.VolumeMount{Name: "chaos-log", MountPath: chaosLogDir})
+		container.Env = append(container.Env,
+			v1.EnvVar{Name: "LOG_DIR", Value: chaosLogDir},
+			v1.EnvVar{Name: "FILE_SIZE", Value: strconv.Itoa(chaosFileSize)},
+			v1.EnvVar{Name: "IO_TIMEOUT", Value: strconv.Itoa(int(chaosIOTimeout.Seconds()))},
+		)
+		return pod
+	}
+
+	readLog := func(pod *v1.Pod) chaosLog {
+		cat := func(name string) string {
+			stdout, stderr, err := e2evolume.PodExec(f, pod, fmt.Sprintf("cat %s/%s 2>/dev/null || true", chaosLogDir, name))
+			framework.ExpectNoError(err, "while reading %s of pod %s: %s", name, pod.Name, stderr)
+			return stdout
+		}
+		log, err := parseChaosLog(cat("completed"), cat("failures"), cat("corrupt"))
+		framework.ExpectNoError(err, "while parsing logs of pod %s", pod.Name)
+		return log
+	}
+
+	// podNow returns the current time of `pod`, the clock workloads log operations with.
+	podNow := func(pod *v1.Pod) int64 {
+		stdout, stderr, err := e2evolume.PodExec(f, pod, "date +%s")
+		framework.ExpectNoError(err, "while reading the time of pod %s: %s", pod.Name, stderr)
+		now, err := strconv.ParseInt(strings.TrimSpace(stdout), 10, 64)
+		framework.ExpectNoError(err)
+		return now
+	}
+
+	// killMountpointPod deletes a random Mountpoint Pod of the volume without grace period, as if it crashed.
+	killMountpointPod := func(ctx context.Context, rng *rand.Rand) (string, error) {
+		pods, err := f.ClientSet.CoreV1().Pods(mounterPodNamespace).List(ctx, metav1.ListOptions{
+			LabelSelector: fmt.Sprintf("%s=%s", mounterPodLabelVolumeName, l.resource.Pv.Name),
+		})
+		if err != nil {
+			return "", err
+		}
+		var running []v1.Pod
+		for _, pod := range pods.Items {
+			if pod.DeletionTimestamp == nil && pod.Status.Phase == v1.PodRunning {
+				running = append(running, pod)
+			}
+		}
+		if len(running) == 0 {
+			return "", nil
+		}
+		victim := running[rng.IntN(len(running))]
+		err = f.ClientSet.CoreV1().Pods(mounterPodNamespace).Delete(ctx, victim.Name, metav1.DeleteOptions{GracePeriodSeconds: ptr.To(int64(0))})
+		return victim.Name, err
+	}
+
+	ginkgo.It("should fail fast or recover without corrupting completed writes when Mountpoint Pods are deleted", func(ctx context.Context) {
+		if t.config.Workloads < 1 || t.config.Duration <= 0 || t.config.KillInterval <= 0 {
+			framework.Failf("Chaos tests need at least one workload, a duration and a kill interval, got %+v", t.config)
+		}
+		seed := t.config.Seed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		framework.Logf("Chaos seed: %d", seed)
+		rng := rand.New(rand.NewPCG(uint64(seed), 0))
+
+		l.resource = createVolumeResourceWithMountOptions(ctx, l.config, pattern, []string{})
+
+		ginkgo.By(fmt.Sprintf("Starting %d workload pods", t.config.Workloads))
+		var workloads []*v1.Pod
+		for i := 0; i < t.config.Workloads; i++ {
+			pod, err := createPod(ctx, f.ClientSet, f.Namespace.Name, makeWorkloadPod(i))
+			framework.ExpectNoError(err)
+			workloads = append(workloads, pod)
+		}
+
+		ginkgo.By("Waiting for workloads to complete writes before chaos")
+		for _, pod := range workloads {
+			gomega.Eventually(func() int {
+				return len(readLog(pod).completed)
+			}, chaosRecoveryTimeout, 5*time.Second).Should(gomega.BeNumerically(">", 0), "pod %s should complete writes", pod.Name)
+		}
+
+		ginkgo.By(fmt.Sprintf("Deleting Mountpoint Pods every %v on average for %v", t.config.KillInterval, t.config.Duration))
+		kills := 0
+		deadline := time.Now().Add(t.config.Duration)
+		for time.Now().Before(deadline) {
+			interval := t.config.KillInterval/2 + time.Duration(rng.Int64N(int64(t.config.KillInterval)))
+			select {
+			case <-ctx.Done():
+				framework.Failf("Chaos interrupted: %v", ctx.Err())
+			case <-time.After(min(interval, time.Until(deadline))):
+			}
+			if time.Now().After(deadline) {
+				break
+			}
+			name, err := killMountpointPod(ctx, rng)
+			framework.ExpectNoError(err, "while deleting a Mountpoint Pod")
+			if name == "" {
+				framework.Logf("No running Mountpoint Pod for volume %s, skipping", l.resource.Pv.Name)
+				continue
+			}
+			kills++
+			framework.Logf("Deleted Mountpoint Pod %s (%d so far)", name, kills)
+		}
+		gomega.Expect(kills).To(gomega.BeNumerically(">", 0), "at least one Mountpoint Pod should be deleted")
+
+		ginkgo.By("Waiting for workloads to complete or fail I/O after chaos")
+		logs := make([]chaosLog, len(workloads))
+		for i, pod := range workloads {
+			chaosEnd := podNow(pod)
+			gomega.Eventually(func() int64 {
+				logs[i] = readLog(pod)
+				return logs[i].lastAt()
+			}, chaosRecoveryTimeout, 5*time.Second).Should(gomega.BeNumerically(">", chaosEnd),
+				"pod %s should complete or fail I/O after chaos instead of hanging", pod.Name)
+			if logs[i].recoveredAfter(chaosEnd) {
+				framework.Logf("Pod %s recovered: %d writes completed, %d operations failed", pod.Name, len(logs[i].completed), len(logs[i].failures))
+			} else {
+				framework.Logf("Pod %s fails fast: %d writes completed, %d operations failed", pod.Name, len(logs[i].completed), len(logs[i].failures))
+			}
+		}
+
+		ginkgo.By("Checking failed operations failed fast with an error")
+		expected := map[string]string{}
+		for i, pod := range workloads {
+			for _, failure := range logs[i].failures {
+				gomega.Expect(failure.hung()).To(gomega.BeFalse(),
+					"pod %s: %s of %s should fail within %v, took %ds (exit code %d)", pod.Name, failure.op, failure.name, chaosFailFast, failure.seconds, failure.exitCode)
+				gomega.Expect(failure.message).NotTo(gomega.BeEmpty(),
+					"pod %s: %s of %s should fail with an error message (exit code %d)", pod.Name, failure.op, failure.name, failure.exitCode)
+			}
+			gomega.Expect(logs[i].corrupt).To(gomega.BeEmpty(), "pod %s should read back the data it wrote", pod.Name)
+			for _, write := range logs[i].completed {
+				expected[write.name] = write.sum
+			}
+		}
+
+		ginkgo.By(fmt.Sprintf("Verifying %d completed writes from a new mount", len(expected)))
+		framework.ExpectNoError(deleteAllPodsWithWait(ctx, f, chaosRecoveryTimeout), "while deleting workload pods")
+		verifier := e2epod.MakePod(f.Namespace.Name, nil, []*v1.PersistentVolumeClaim{l.resource.Pvc}, admissionapi.LevelBaseline, "")
+		verifier.Name = "chaos-verifier"
+		verifier, err := createPod(ctx, f.ClientSet, f.Namespace.Name, verifier)
+		framework.ExpectNoError(err)
+		stdout, stderr, err := e2evolume.PodExec(f, verifier, "md5sum /mnt/volume1/*")
+		framework.ExpectNoError(err, "while reading completed writes: %s", stderr)
+		actual := map[string]string{}
+		for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 2 {
+				actual[strings.TrimPrefix(fields[1], "/mnt/volume1/")] = fields[0]
+			}
+		}
+		for name, sum := range expected {
+			gomega.Expect(actual).To(gomega.HaveKeyWithValue(name, sum), "completed write %s should be readable with the data written", name)
+		}
+	})
+}
@@ -0,0 +1,280 @@
+// This file contains negative-path tests for CSI secret templating: the secret a StorageClass template
+// resolves to does not exist, does not hold the expected keys, or the template resolves the namespace
+// with a variable that is not allowed for it.
+//
+// Failures surface in different places depending on the secret:
+//   - Provisioner secrets are read by the CSI external-provisioner before CreateVolume, failures are
+//     reported as ProvisioningFailed events on the PVC, which must not bind.
+//   - Node-publish secret templates are resolved at provision time, but the secrets themselves are
+//     only read by kubelet when mounting. Invalid templates fail provisioning like provisioner secrets,
+//     missing secrets or wrong keys bind the PVC and are reported as FailedMount events on the Pod.
+//
+// Reference: https://kubernetes-csi.github.io/docs/secrets-and-credentials-storage-class.html
+package customsuites
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/onsi/ginkgo/v2"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/test/e2e/framework"
+	storageframework "k8s.io/kubernetes/test/e2e/storage/framework"
+	"k8s.io/utils/ptr"
+
+	"github.com/scality/mountpoint-s3-csi-driver/tests/e2e/constants"
+)
+
+const (
+	// templatingFailureEventTimeout is how long failures are given to be reported as events
+	templatingFailureEventTimeout = 2 * time.Minute
+	// templatingFailureUnboundPeriod is how long PVCs of failed provisioning must stay unbound
+	templatingFailureUnboundPeriod = 30 * time.Second
+)
+
+// s3DynamicProvisioningTemplatingFailuresTestSuite implements TestSuite for testing failures of CSI secret templating
+type s3DynamicProvisioningTemplatingFailuresTestSuite struct {
+	tsInfo storageframework.TestSuiteInfo
+}
+
+// InitS3DynamicProvisioningTemplatingFailuresTestSuite creates the test suite for secret templating failures
+func InitS3DynamicProvisioningTemplatingFailuresTestSuite() storageframework.TestSuite {
+	return &s3DynamicProvisioningTemplatingFailuresTestSuite{
+		tsInfo: storageframework.TestSuiteInfo{
+			Name: "s3DynamicProvisioningTemplatingFailures",
+			TestPatterns: []storageframework.TestPattern{
+				{
+					Name:    "Dynamic PV Templating Failures Test",
+					VolType: storageframework.DynamicPV,
+				},
+			},
+		},
+	}
+}
+
+func (t *s3DynamicProvisioningTemplatingFailuresTestSuite) GetTestSuiteInfo() storageframework.TestSuiteInfo {
+	return t.tsInfo
+}
+
+func (t *s3DynamicProvisioningTemplatingFailuresTestSuite) SkipUnsupportedTests(driver storageframework.TestDriver, pattern storageframework.TestPattern) {
+	if pattern.VolType != storageframework.DynamicPV {
+		ginkgo.Skip("Templating failure tests only apply to dynamic provisioning")
+	}
+}
+
+func (t *s3DynamicProvisioningTemplatingFailuresTestSuite) DefineTests(driver storageframework.TestDriver, pattern storageframework.TestPattern) {
+	// Local struct to track resources for proper cleanup
+	type local struct {
+		storageClasses []*storagev1.StorageClass
+		pvcs           []*v1.PersistentVolumeClaim
+		secrets        []*v1.Secret
+		pods           []*v1.Pod
+	}
+	var l local
+
+	f := framework.NewFrameworkWithCustomTimeouts("s3-templating-failures", storageframework.GetDriverTimeouts(driver))
+	f.NamespacePodSecurityEnforceLevel = "privileged"
+
+	cleanup := func(ctx context.Context) {
+		for _, pod := range l.pods {
+			_ = CleanupPodInErrorState(ctx, f, pod.Name)
+		}
+		for _, pvc := range l.pvcs {
+			_ = f.ClientSet.CoreV1().PersistentVolumeClaims(pvc.Namespace).Delete(ctx, pvc.Name, metav1.DeleteOptions{})
+		}
+		for _, sc := range l.storageClasses {
+			_ = f.ClientSet.StorageV1().StorageClasses().Delete(ctx, sc.Name, metav1.DeleteOptions{})
+		}
+		for _, secret := range l.secrets {
+			_ = f.ClientSet.CoreV1().Secrets(secret.Namespace).Delete(ctx, secret.Name, metav1.DeleteOptions{})
+		}
+		l = local{}
+	}
+
+	// createStorageClass creates a StorageClass of the driver with the given secret parameters
+	createStorageClass := func(ctx context.Context, namePrefix string, parameters map[string]string) *storagev1.StorageClass {
+		sc, err := f.ClientSet.StorageV1().StorageClasses().Create(ctx, &storagev1.StorageClass{
+			ObjectMeta:        metav1.ObjectMeta{Name: fmt.Sprintf("%s-%s", namePrefix, uuid.NewString()[:8])},
+			Provisioner:       constants.DriverName,
+			Parameters:        parameters,
+			ReclaimPolicy:     ptr.To(v1.PersistentVolumeReclaimDelete),
+			VolumeBindingMode: ptr.To(storagev1.VolumeBindingImmediate),
+		}, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "Failed to create StorageClass")
+		l.storageClasses = append(l.storageClasses, sc)
+		return sc
+	}
+
+	// createPVC creates a PVC of the StorageClass with the given annotations
+	createPVC := func(ctx context.Context, namePrefix string, sc *storagev1.StorageClass, annotations map[string]string) *v1.PersistentVolumeClaim {
+		pvc, err := f.ClientSet.CoreV1().PersistentVolumeClaims(f.Namespace.Name).Create(ctx, &v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        fmt.Sprintf("%s-%s", namePrefix, uuid.NewString()[:8]),
+				Namespace:   f.Namespace.Name,
+				Annotations: annotations,
+			},
+			Spec: v1.PersistentVolumeClaimSpec{
+				AccessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteMany},
+				Resources: v1.VolumeResourceRequirements{
+					Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse("1Gi")},
+				},
+				StorageClassName: &sc.Name,
+			},
+		}, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "Failed to create PVC")
+		l.pvcs = append(l.pvcs, pvc)
+		return pvc
+	}
+
+	// createPod creates a Pod mounting the PVC
+	createPod := func(ctx context.Context, pvc *v1.PersistentVolumeClaim) *v1.Pod {
+		pod, err := f.ClientSet.CoreV1().Pods(f.Namespace.Name).Create(ctx, &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("templating-failure-pod-%s", uuid.NewString()[:8]),
+				Namespace: f.Namespace.Name,
+			},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Name:         "test-container",
+						Image:        "busybox:1.35",
+						Command:      []string{"sh", "-c", "sleep 3600"},
+						VolumeMounts: []v1.VolumeMount{{Name: "test-volume", MountPath: "/mnt"}},
+					},
+				},
+				Volumes: []v1.Volume{
+					{
+						Name: "test-volume",
+						VolumeSource: v1.VolumeSource{
+							PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: pvc.Name},
+						},
+					},
+				},
+				RestartPolicy: v1.RestartPolicyNever,
+			},
+		}, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "Failed to create pod")
+		l.pods = append(l.pods, pod)
+		return pod
+	}
+
+	// expectProvisioningFailure checks the PVC reports a ProvisioningFailed event containing `substrings` and does not bind
+	expectProvisioningFailure := func(ctx context.Context, pvc *v1.PersistentVolumeClaim, substrings ...string) {
+		ginkgo.By("Verifying the PVC reports an actionable ProvisioningFailed event")
+		_, err := WaitForEventMessage(ctx, f, pvc.Namespace, "PersistentVolumeClaim", pvc.Name, "ProvisioningFailed", substrings, templatingFailureEventTimeout)
+		framework.ExpectNoError(err)
+
+		ginkgo.By("Verifying the PVC does not bind")
+		ExpectPVCNotBound(ctx, f, pvc.Name, pvc.Namespace, templatingFailureUnboundPeriod)
+	}
+
+	// expectMountFailure checks the Pod reports a FailedMount event containing `substrings`
+	expectMountFailure := func(ctx context.Context, pod *v1.Pod, substrings ...string) {
+		ginkgo.By("Verifying the Pod reports an actionable FailedMount event")
+		_, err := WaitForEventMessage(ctx, f, pod.Namespace, "Pod", pod.Name, "FailedMount", substrings, templatingFailureEventTimeout)
+		framework.ExpectNoError(err)
+	}
+
+	ginkgo.Context("CSI Secret Templating Failures - Provisioner Secrets", func() {
+		ginkgo.AfterEach(func(ctx context.Context) {
+			cleanup(ctx)
+		})
+
+		ginkgo.It("should not bind when the resolved provisioner secret does not exist", func(ctx context.Context) {
+			sc := createStorageClass(ctx, "prov-missing", map[string]string{
+				"csi.storage.k8s.io/provisioner-secret-name":      "${pvc.name}-provisioner",
+				"csi.storage.k8s.io/provisioner-secret-namespace": "${pvc.namespace}",
+			})
+			pvc := createPVC(ctx, "prov-missing-pvc", sc, nil)
+
+			expectProvisioningFailure(ctx, pvc, pvc.Name+"-provisioner", "not found")
+		})
+
+		ginkgo.It("should not bind when the resolved provisioner secret has wrong keys", func(ctx context.Context) {
+			sc := createStorageClass(ctx, "prov-wrong-keys", map[string]string{
+				"csi.storage.k8s.io/provisioner-secret-name":      "${pvc.namespace}-wrong-keys",
+				"csi.storage.k8s.io/provisioner-secret-namespace": "${pvc.namespace}",
+			})
+
+			ginkgo.By("Creating a secret with AWS CLI style keys instead of the keys of the driver")
+			secret, err := CreateSecretWithData(ctx, f, f.Namespace.Name+"-wrong-keys", f.Namespace.Name, map[string]string{
+				"aws_access_key_id":     GetEnv("ACCOUNT1_ACCESS_KEY", "accessKey1"),
+				"aws_secret_access_key": GetEnv("ACCOUNT1_SECRET_KEY", "verySecretKey1"),
+			})
+			framework.ExpectNoError(err, "Failed to create secret")
+			l.secrets = append(l.secrets, secret)
+
+			pvc := createPVC(ctx, "prov-wrong-keys-pvc", sc, nil)
+
+			expectProvisioningFailure(ctx, pvc, "missing required AWS credentials", "access_key_id")
+		})
+
+		ginkgo.It("should not bind when the provisioner secret namespace uses a forbidden template", func(ctx context.Context) {
+			// Annotations can only be used in node-publish secret names
+			sc := createStorageClass(ctx, "prov-forbidden-ns", map[string]string{
+				"csi.storage.k8s.io/provisioner-secret-name":      "${pvc.name}-provisioner",
+				"csi.storage.k8s.io/provisioner-secret-namespace": "${pvc.annotations['tenant']}",
+			})
+			pvc := createPVC(ctx, "prov-forbidden-ns-pvc", sc, map[string]string{"tenant": f.Namespace.Name})
+
+			expectProvisioningFailure(ctx, pvc, "invalid tokens", "pvc.annotations['tenant']")
+		})
+	})
+
+	ginkgo.Context("CSI Secret Templating Failures - Node-Publish Secrets", func() {
+		ginkgo.AfterEach(func(ctx context.Context) {
+			cleanup(ctx)
+		})
+
+		ginkgo.It("should not bind when the node-publish secret namespace uses a forbidden template", func(ctx context.Context) {
+			// Annotations can only be used in node-publish secret names, not namespaces
+			sc := createStorageClass(ctx, "node-forbidden-ns", map[string]string{
+				"csi.storage.k8s.io/node-publish-secret-name":      "${pvc.name}-node",
+				"csi.storage.k8s.io/node-publish-secret-namespace": "${pvc.annotations['tenant']}",
+			})
+			pvc := createPVC(ctx, "node-forbidden-ns-pvc", sc, map[string]string{"tenant": f.Namespace.Name})
+
+			expectProvisioningFailure(ctx, pvc, "invalid tokens", "pvc.annotations['tenant']")
+		})
+
+		ginkgo.It("should fail to mount when the resolved node-publish secret does not exist", func(ctx context.Context) {
+			sc := createStorageClass(ctx, "node-missing", map[string]string{
+				"csi.storage.k8s.io/node-publish-secret-name":      "${pvc.name}-node",
+				"csi.storage.k8s.io/node-publish-secret-namespace": "${pvc.namespace}",
+			})
+			pvc := createPVC(ctx, "node-missing-pvc", sc, nil)
+
+			ginkgo.By("Waiting for the PVC to bind, node-publish secrets are only read when mounting")
+			WaitForPVCToBeBound(ctx, f, pvc.Name, pvc.Namespace)
+
+			pod := createPod(ctx, pvc)
+			expectMountFailure(ctx, pod, pvc.Name+"-node", "not found")
+		})
+
+		ginkgo.It("should fail to mount when the resolved node-publish secret has wrong keys", func(ctx context.Context) {
+			sc := createStorageClass(ctx, "node-wrong-keys", map[string]string{
+				"csi.storage.k8s.io/node-publish-secret-name":      "${pvc.namespace}-node-wrong-keys",
+				"csi.storage.k8s.io/node-publish-secret-namespace": "${pvc.namespace}",
+			})
+
+			ginkgo.By("Creating a secret with AWS CLI style keys instead of the keys of the driver")
+			secret, err := CreateSecretWithData(ctx, f, f.Namespace.Name+"-node-wrong-keys", f.Namespace.Name, map[string]string{
+				"aws_access_key_id":     GetEnv("ACCOUNT1_ACCESS_KEY", "accessKey1"),
+				"aws_secret_access_key": GetEnv("ACCOUNT1_SECRET_KEY", "verySecretKey1"),
+			})
+			framework.ExpectNoError(err, "Failed to create secret")
+			l.secrets = append(l.secrets, secret)
+
+			pvc := createPVC(ctx, "node-wrong-keys-pvc", sc, nil)
+			WaitForPVCToBeBound(ctx, f, pvc.Name, pvc.Namespace)
+
+			pod := createPod(ctx, pvc)
+			expectMountFailure(ctx, pod, "missing or invalid keys in Kubernetes Secret", "access_key_id", "secret_access_key")
+		})
+	})
+}
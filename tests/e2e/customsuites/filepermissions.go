@@ -6,6 +6,7 @@ Covered behaviors:
 - chmod: Verifies that chmod operations fail (EPERM/ENOTSUP) and don't change mode.
 - chown: Verifies that ownership changes fail post-creation.
 - umask: Ensures umask does not alter driver-enforced permissions on new files.
+- permission matrix: Verifies modes, ownership and access for combinations of modes, groups and read-only mounts.
 
 These behaviors align with Mountpoint-S3's design: metadata is immutable after mount.
 */
@@ -1309,4 +1310,14 @@ func (suite *s3CSIFilePermissionsTestSuite) DefineTests(driver storageframework.
 		_, _, err := e2evolume.PodExec(testFramework, pod, fmt.Sprintf("test -r %[1]s && test -w %[1]s && ! test -x %[1]s", fpath))
 		framework.ExpectNoError(err, "access() bits disagree with stat permissions")
 	})
+
+	// Permission matrix: one spec per combination of [permissionMatrix], labeled to be run or skipped as a whole
+	// with `--ginkgo.label-filter`.
+	for _, tc := range permissionMatrix() {
+		ginkgo.It("permission matrix: "+tc.String(), ginkgo.Label("permission-matrix"), func(ctx context.Context) {
+			res := createVolumeWithOptions(ctx, testRegistry.config, pattern,
+				tc.identity.volumeUID, tc.identity.volumeGID, tc.fileMode, tc.mountOptions()...)
+			runPermissionMatrixCase(ctx, testFramework, res, tc)
+		})
+	}
 }
@@ -0,0 +1,213 @@
+// filepermissions_matrix.go — generates the file permission matrix of the filepermissions suite.
+//
+// Each case combines a file-mode, a dir-mode, the identity the workload accesses the volume with, and whether the
+// volume is mounted read-only. Expected modes, ownership and access are derived from the combination, so a new
+// dimension only requires adding its values here.
+package customsuites
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
+	e2evolume "k8s.io/kubernetes/test/e2e/framework/volume"
+	storageframework "k8s.io/kubernetes/test/e2e/storage/framework"
+	admissionapi "k8s.io/pod-security-admission/api"
+	"k8s.io/utils/ptr"
+
+	"github.com/scality/mountpoint-s3-csi-driver/tests/e2e/pkg/s3client"
+)
+
+// Default modes of Mountpoint when no file-mode or dir-mode mount option is set.
+const (
+	defaultFileMode = "644"
+	defaultDirMode  = "755"
+)
+
+// Identities used by the permission matrix.
+const (
+	// matrixFSGroup is the fsGroup of the workload and the gid of the volume in fsGroup cases.
+	matrixFSGroup = int64(4000)
+	// matrixSupplementalGroup is a supplemental group of the workload and the gid of the volume in supplemental
+	// group cases, whose volumes are owned by matrixOtherUser.
+	matrixSupplementalGroup = int64(5000)
+	matrixOtherUser         = int64(3000)
+)
+
+// A permissionIdentity is how a workload is granted access to a volume.
+type permissionIdentity struct {
+	name string
+	// group is whether the workload accesses the volume through its group permission bits, instead of its owner ones.
+	group bool
+	// volumeUID and volumeGID are the uid and gid mount options of the volume.
+	volumeUID, volumeGID int64
+	// configure sets the security context of the workload.
+	configure func(*v1.PodSecurityContext)
+}
+
+var permissionIdentities = []permissionIdentity{
+	{
+		name:      "owner",
+		volumeUID: DefaultNonRootUser,
+		volumeGID: DefaultNonRootGroup,
+		configure: func(*v1.PodSecurityContext) {},
+	},
+	{
+		name:      "fsGroup with Always change policy",
+		volumeUID: DefaultNonRootUser,
+		volumeGID: matrixFSGroup,
+		configure: func(sc *v1.PodSecurityContext) {
+			sc.FSGroup = ptr.To(matrixFSGroup)
+			sc.FSGroupChangePolicy = ptr.To(v1.FSGroupChangeAlways)
+		},
+	},
+	{
+		name:      "fsGroup with OnRootMismatch change policy",
+		volumeUID: DefaultNonRootUser,
+		volumeGID: matrixFSGroup,
+		configure: func(sc *v1.PodSecurityContext) {
+			sc.FSGroup = ptr.To(matrixFSGroup)
+			sc.FSGroupChangePolicy = ptr.To(v1.FSGroupChangeOnRootMismatch)
+		},
+	},
+	{
+		name:      "supplemental group",
+		group:     true,
+		volumeUID: matrixOtherUser,
+		volumeGID: matrixSupplementalGroup,
+		configure: func(sc *v1.PodSecurityContext) {
+			sc.SupplementalGroups = []int64{matrixSupplementalGroup}
+		},
+	},
+}
+
+// A permissionMatrixCase is a combination of the permission matrix.
+type permissionMatrixCase struct {
+	// fileMode and dirMode are the file-mode and dir-mode mount options, Mountpoint defaults are used if empty.
+	fileMode, dirMode string
+	identity          permissionIdentity
+	readOnly          bool
+}
+
+// permissionMatrix returns all combinations of file modes, dir modes, identities and read-only mounts.
+func permissionMatrix() []permissionMatrixCase {
+	var cases []permissionMatrixCase
+	for _, fileMode := range []string{"", "0600", "0660"} {
+		for _, dirMode := range []string{"", "0770"} {
+			for _, identity := range permissionIdentities {
+				for _, readOnly := range []bool{false, true} {
+					cases = append(cases, permissionMatrixCase{fileMode, dirMode, identity, readOnly})
+				}
+			}
+		}
+	}
+	return cases
+}
+
+func (c permissionMatrixCase) String() string {
+	option := func(name, value string) string {
+		if value == "" {
+			return name + "=default"
+		}
+		return name + "=" + value
+	}
+	access := "read-write"
+	if c.readOnly {
+		access = "read-only"
+	}
+	return fmt.Sprintf("%s, %s, %s, %s", option("file-mode", c.fileMode), option("dir-mode", c.dirMode), c.identity.name, access)
+}
+
+// mountOptions returns the mount options of the volume besides uid, gid and file-mode.
+func (c permissionMatrixCase) mountOptions() []string {
+	var options []string
+	if c.dirMode != "" {
+		options = append(options, "dir-mode="+c.dirMode)
+	}
+	if c.readOnly {
+		options = append(options, "read-only")
+	}
+	return options
+}
+
+// expectedModes returns the modes files and directories of the volume are expected to have, as printed by `stat -c %a`.
+func (c permissionMatrixCase) expectedModes() (string, string) {
+	mode := func(option, fallback string) string {
+		if option == "" {
+			return fallback
+		}
+		return strings.TrimPrefix(option, "0")
+	}
+	return mode(c.fileMode, defaultFileMode), mode(c.dirMode, defaultDirMode)
+}
+
+// permits returns whether `mode` grants `bit` (4: read, 2: write, 1: execute) to the identity of the case.
+func (c permissionMatrixCase) permits(mode string, bit uint64) bool {
+	bits, err := strconv.ParseUint(mode, 8, 32)
+	framework.ExpectNoError(err)
+	if c.identity.group {
+		bits >>= 3
+	} else {
+		bits >>= 6
+	}
+	return bits&bit != 0
+}
+
+// expectedAccess returns whether the workload is expected to read existing files and create new ones.
+func (c permissionMatrixCase) expectedAccess() (bool, bool) {
+	fileMode, dirMode := c.expectedModes()
+	traverse := c.permits(dirMode, 1)
+	readable := traverse && c.permits(fileMode, 4)
+	writable := !c.readOnly && traverse && c.permits(dirMode, 2)
+	return readable, writable
+}
+
+// runPermissionMatrixCase seeds the bucket of `resource` with an object, mounts it in a workload with the identity
+// of `c`, and verifies modes, ownership and access of the workload.
+func runPermissionMatrixCase(ctx context.Context, f *framework.Framework, resource *storageframework.VolumeResource, c permissionMatrixCase) {
+	// Seed the bucket directly, read-only volumes cannot be written through the mount
+	seedDir, seedFile := "matrix", "matrix/seed.txt"
+	bucketName := GetBucketNameFromVolumeResource(resource)
+	framework.ExpectNoError(s3client.New("", "", "").PutObject(ctx, bucketName, seedFile, "seed"))
+
+	ginkgo.By(fmt.Sprintf("Creating pod accessing the volume as %s", c.identity.name))
+	pod := e2epod.MakePod(f.Namespace.Name, nil, []*v1.PersistentVolumeClaim{resource.Pvc}, admissionapi.LevelRestricted, "")
+	if pod.Spec.SecurityContext == nil {
+		pod.Spec.SecurityContext = &v1.PodSecurityContext{}
+	}
+	pod.Spec.SecurityContext.RunAsUser = ptr.To(DefaultNonRootUser)
+	pod.Spec.SecurityContext.RunAsGroup = ptr.To(DefaultNonRootGroup)
+	pod.Spec.SecurityContext.RunAsNonRoot = ptr.To(true)
+	c.identity.configure(pod.Spec.SecurityContext)
+	pod, err := createPod(ctx, f.ClientSet, f.Namespace.Name, pod)
+	framework.ExpectNoError(err)
+	defer func() {
+		framework.ExpectNoError(e2epod.DeletePodWithWait(ctx, f.ClientSet, pod))
+	}()
+
+	volPath := "/mnt/volume1"
+	fileMode, dirMode := c.expectedModes()
+	owner := fmt.Sprintf("%d %d", c.identity.volumeUID, c.identity.volumeGID)
+
+	ginkgo.By(fmt.Sprintf("Verifying modes %s/%s and ownership %s", fileMode, dirMode, owner))
+	out, _, err := e2evolume.PodExec(f, pod, fmt.Sprintf("stat -c '%%a %%u %%g' %s/%s %s/%s", volPath, seedFile, volPath, seedDir))
+	framework.ExpectNoError(err)
+	gomega.Expect(strings.Fields(out)).To(gomega.Equal(strings.Fields(
+		fmt.Sprintf("%s %s\n%s %s", fileMode, owner, dirMode, owner))))
+
+	readable, writable := c.expectedAccess()
+
+	ginkgo.By(fmt.Sprintf("Verifying the seeded file is readable=%t", readable))
+	_, _, err = e2evolume.PodExec(f, pod, fmt.Sprintf("cat %s/%s", volPath, seedFile))
+	gomega.Expect(err == nil).To(gomega.Equal(readable), "unexpected read access, error: %v", err)
+
+	ginkgo.By(fmt.Sprintf("Verifying new files can be created=%t", writable))
+	_, _, err = e2evolume.PodExec(f, pod, fmt.Sprintf("touch %s/%s/created.txt", volPath, seedDir))
+	gomega.Expect(err == nil).To(gomega.Equal(writable), "unexpected write access, error: %v", err)
+}
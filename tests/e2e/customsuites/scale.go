@@ -0,0 +1,287 @@
+// This file implements a scalability test suite, which mounts hundreds of volumes concurrently to validate
+// the mount concurrency limits of the node plugin and the sharing of Mountpoint Pods between workloads.
+// This test suite is disabled by default and can be enabled with the --scale flag.
+package customsuites
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
+	storageframework "k8s.io/kubernetes/test/e2e/storage/framework"
+	admissionapi "k8s.io/pod-security-admission/api"
+)
+
+const (
+	// DefaultScalePods is the default number of Pods mounting volumes concurrently
+	DefaultScalePods = 200
+	// DefaultScaleVolumes is the default number of volumes shared by the Pods
+	DefaultScaleVolumes = 10
+	// DefaultScaleOutputPath is the default path of the JSON results of the suite
+	DefaultScaleOutputPath = "test-results/scale.json"
+
+	// scaleReadyTimeout is how long all Pods are given to be ready
+	scaleReadyTimeout = 15 * time.Minute
+	// scalePollInterval is how often the readiness of Pods and the number of Mountpoint Pods are polled
+	scalePollInterval = 5 * time.Second
+)
+
+// ScaleConfig configures the scalability test suite.
+type ScaleConfig struct {
+	// Pods is the number of Pods created concurrently
+	Pods int
+	// Volumes is the number of volumes mounted by the Pods, Pod i mounts volume i modulo Volumes
+	Volumes int
+	// OutputPath is the path the JSON results are written to
+	OutputPath string
+}
+
+// ScaleResult holds the results of a run of the scalability test suite, written as a JSON artifact.
+type ScaleResult struct {
+	Pods    int `json:"pods"`
+	Volumes int `json:"volumes"`
+	// ReadyPods is the number of Pods that were ready within the timeout
+	ReadyPods int `json:"readyPods"`
+	// FailedPods is the number of Pods that were not ready within the timeout
+	FailedPods int `json:"failedPods"`
+	// MountFailureRate is the ratio of FailedPods to Pods
+	MountFailureRate float64 `json:"mountFailureRate"`
+	// FailedMountEvents is the number of FailedMount events on the Pods, including retried mounts
+	FailedMountEvents int `json:"failedMountEvents"`
+	// TimeToReadySeconds summarizes the time from the creation of ready Pods to their readiness
+	TimeToReadySeconds DurationSummary `json:"timeToReadySeconds"`
+	// MaxMountpointPods is the highest number of Mountpoint Pods observed during the run
+	MaxMountpointPods int `json:"maxMountpointPods"`
+	// MountpointPods is the number of Mountpoint Pods once all Pods were ready or the timeout expired
+	MountpointPods int `json:"mountpointPods"`
+	// DurationSeconds is the time from the creation of the first Pod to the end of the run
+	DurationSeconds float64 `json:"durationSeconds"`
+}
+
+// DurationSummary summarizes a distribution of durations in seconds.
+type DurationSummary struct {
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P99 float64 `json:"p99"`
+	Max float64 `json:"max"`
+}
+
+// summarizeDurations returns the percentiles of `durations`, zero if it is empty.
+func summarizeDurations(durations []time.Duration) DurationSummary {
+	if len(durations) == 0 {
+		return DurationSummary{}
+	}
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+	percentile := func(p float64) float64 {
+		i := int(math.Ceil(p*float64(len(sorted)))) - 1
+		return sorted[max(i, 0)].Seconds()
+	}
+	return DurationSummary{
+		P50: percentile(0.50),
+		P90: percentile(0.90),
+		P99: percentile(0.99),
+		Max: sorted[len(sorted)-1].Seconds(),
+	}
+}
+
+// s3ScaleTestSuite implements a test suite for measuring how the driver copes with many concurrent mounts.
+type s3ScaleTestSuite struct {
+	tsInfo storageframework.TestSuiteInfo
+	config ScaleConfig
+}
+
+// InitS3ScaleTestSuite returns a function initializing a test suite that creates `config.Pods` Pods mounting
+// `config.Volumes` volumes concurrently, and measures:
+// - The time from the creation of each Pod to its readiness
+// - The ratio of Pods that are not ready within the timeout
+// - The number of Mountpoint Pods serving the mounts
+//
+// Results are stored in a JSON output file for later analysis.
+func InitS3ScaleTestSuite(config ScaleConfig) func() storageframework.TestSuite {
+	return func() storageframework.TestSuite {
+		return &s3ScaleTestSuite{
+			tsInfo: storageframework.TestSuiteInfo{
+				Name: "scale",
+				TestPatterns: []storageframework.TestPattern{
+					storageframework.DefaultFsPreprovisionedPV,
+				},
+			},
+			config: config,
+		}
+	}
+}
+
+// GetTestSuiteInfo returns information about the test suite.
+func (t *s3ScaleTestSuite) GetTestSuiteInfo() storageframework.TestSuiteInfo {
+	return t.tsInfo
+}
+
+// SkipUnsupportedTests is a no-op, the suite only uses pre-provisioned volumes.
+func (t *s3ScaleTestSuite) SkipUnsupportedTests(_ storageframework.TestDriver, _ storageframework.TestPattern) {
+}
+
+// DefineTests defines the test creating the Pods and measuring their mounts.
+func (t *s3ScaleTestSuite) DefineTests(driver storageframework.TestDriver, pattern storageframework.TestPattern) {
+	type local struct {
+		resources []*storageframework.VolumeResource
+		config    *storageframework.PerTestConfig
+	}
+	var l local
+	f := framework.NewFrameworkWithCustomTimeouts("scale", storageframework.GetDriverTimeouts(driver))
+	f.NamespacePodSecurityLevel = admissionapi.LevelBaseline
+
+	cleanup := func(ctx context.Context) {
+		// Volumes cannot be deleted while Pods use them
+		framework.ExpectNoError(deleteAllPodsWithWait(ctx, f, scaleReadyTimeout), "while deleting pods")
+		var errs []error
+		for _, resource := range l.resources {
+			errs = append(errs, resource.CleanupResource(ctx))
+		}
+		framework.ExpectNoError(errors.NewAggregate(errs), "while cleanup resource")
+	}
+	ginkgo.BeforeEach(func(ctx context.Context) {
+		l = local{}
+		l.config = driver.PrepareTest(ctx, f)
+		ginkgo.DeferCleanup(cleanup)
+	})
+
+	writeOutput := func(result ScaleResult) {
+		framework.ExpectNoError(os.MkdirAll(filepath.Dir(t.config.OutputPath), 0o755))
+		data, err := json.MarshalIndent(result, "", "  ")
+		framework.ExpectNoError(err)
+		framework.ExpectNoError(os.WriteFile(t.config.OutputPath, data, 0o644))
+		framework.Logf("Scale results written to %s: %s", t.config.OutputPath, data)
+	}
+
+	ginkgo.It(fmt.Sprintf("should mount %d volumes in %d concurrent pods", t.config.Volumes, t.config.Pods), func(ctx context.Context) {
+		if t.config.Pods < 1 || t.config.Volumes < 1 {
+			framework.Failf("Scale tests need at least one pod and one volume, got %d pods and %d volumes", t.config.Pods, t.config.Volumes)
+		}
+
+		ginkgo.By(fmt.Sprintf("Creating %d volumes", t.config.Volumes))
+		for i := 0; i < t.config.Volumes; i++ {
+			l.resources = append(l.resources, createVolumeResourceWithMountOptions(ctx, l.config, pattern, []string{}))
+		}
+
+		ginkgo.By(fmt.Sprintf("Creating %d pods concurrently", t.config.Pods))
+		start := time.Now()
+		var wg sync.WaitGroup
+		createErrs := make([]error, t.config.Pods)
+		for i := 0; i < t.config.Pods; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer ginkgo.GinkgoRecover()
+				defer wg.Done()
+				pvc := l.resources[i%len(l.resources)].Pvc
+				pod := e2epod.MakePod(f.Namespace.Name, nil, []*v1.PersistentVolumeClaim{pvc}, admissionapi.LevelBaseline, "")
+				pod.Name = fmt.Sprintf("scale-%d", i)
+				_, createErrs[i] = f.ClientSet.CoreV1().Pods(f.Namespace.Name).Create(ctx, pod, metav1.CreateOptions{})
+			}(i)
+		}
+		wg.Wait()
+		framework.ExpectNoError(errors.NewAggregate(createErrs), "while creating pods")
+
+		ginkgo.By("Waiting for pods to be ready")
+		maxMountpointPods := 0
+		var pods []v1.Pod
+		err := wait.PollUntilContextTimeout(ctx, scalePollInterval, scaleReadyTimeout, true, func(ctx context.Context) (bool, error) {
+			mountpointPods, err := countMountpointPods(ctx, f)
+			if err != nil {
+				return false, err
+			}
+			maxMountpointPods = max(maxMountpointPods, mountpointPods)
+
+			list, err := f.ClientSet.CoreV1().Pods(f.Namespace.Name).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return false, err
+			}
+			pods = list.Items
+			ready := 0
+			for i := range pods {
+				if _, ok := podReadyTime(&pods[i]); ok {
+					ready++
+				}
+			}
+			framework.Logf("%d/%d pods ready, %d Mountpoint Pods", ready, t.config.Pods, mountpointPods)
+			return ready == t.config.Pods, nil
+		})
+		if err != nil && !wait.Interrupted(err) {
+			framework.Failf("Failed to wait for pods: %v", err)
+		}
+
+		result := ScaleResult{Pods: t.config.Pods, Volumes: t.config.Volumes, MaxMountpointPods: maxMountpointPods}
+		result.DurationSeconds = time.Since(start).Seconds()
+		var timesToReady []time.Duration
+		for i := range pods {
+			if readyAt, ok := podReadyTime(&pods[i]); ok {
+				timesToReady = append(timesToReady, readyAt.Sub(pods[i].CreationTimestamp.Time))
+			}
+		}
+		result.ReadyPods = len(timesToReady)
+		result.FailedPods = t.config.Pods - result.ReadyPods
+		result.MountFailureRate = float64(result.FailedPods) / float64(t.config.Pods)
+		result.TimeToReadySeconds = summarizeDurations(timesToReady)
+		result.MountpointPods, err = countMountpointPods(ctx, f)
+		framework.ExpectNoError(err)
+
+		events, err := f.ClientSet.CoreV1().Events(f.Namespace.Name).List(ctx, metav1.ListOptions{
+			FieldSelector: "involvedObject.kind=Pod,reason=FailedMount",
+		})
+		framework.ExpectNoError(err)
+		for _, event := range events.Items {
+			result.FailedMountEvents += int(max(event.Count, 1))
+		}
+
+		writeOutput(result)
+		gomega.Expect(result.FailedPods).To(gomega.BeZero(), "all pods should be ready within %v", scaleReadyTimeout)
+	})
+}
+
+// podReadyTime returns when `pod` became ready, false if it is not ready.
+func podReadyTime(pod *v1.Pod) (time.Time, bool) {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == v1.PodReady && cond.Status == v1.ConditionTrue {
+			return cond.LastTransitionTime.Time, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// countMountpointPods returns the number of Mountpoint Pods in the cluster.
+func countMountpointPods(ctx context.Context, f *framework.Framework) (int, error) {
+	list, err := f.ClientSet.CoreV1().Pods(mounterPodNamespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return 0, err
+	}
+	return len(list.Items), nil
+}
+
+// deleteAllPodsWithWait deletes all Pods of the test namespace and waits until they are gone.
+func deleteAllPodsWithWait(ctx context.Context, f *framework.Framework, timeout time.Duration) error {
+	err := f.ClientSet.CoreV1().Pods(f.Namespace.Name).DeleteCollection(ctx, metav1.DeleteOptions{}, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	return wait.PollUntilContextTimeout(ctx, scalePollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		list, err := f.ClientSet.CoreV1().Pods(f.Namespace.Name).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return false, err
+		}
+		return len(list.Items) == 0, nil
+	})
+}
@@ -19,6 +19,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/kubernetes/test/e2e/framework"
 	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
@@ -538,6 +539,77 @@ func WaitForPVCToBeBoundWithTimeout(ctx context.Context, f *framework.Framework,
 	}, timeout, interval).WithContext(ctx).Should(gomega.Equal(v1.ClaimBound))
 }
 
+// ExpectPVCNotBound checks that a PVC stays Pending without a bound PV for `duration`
+func ExpectPVCNotBound(ctx context.Context, f *framework.Framework, pvcName, namespace string, duration time.Duration) {
+	gomega.Consistently(func(ctx context.Context) error {
+		pvc, err := f.ClientSet.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, pvcName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if pvc.Status.Phase != v1.ClaimPending || pvc.Spec.VolumeName != "" {
+			return fmt.Errorf("PVC %s/%s is %s with volume %q", namespace, pvcName, pvc.Status.Phase, pvc.Spec.VolumeName)
+		}
+		return nil
+	}, duration, 5*time.Second).WithContext(ctx).Should(gomega.Succeed(), "PVC should not bind")
+}
+
+// WaitForEventMessage waits until an event with `reason` on the object `kind`/`name` in `namespace` has a message
+// containing all of `substrings`, and returns the message. Events with the reason but another message are logged, so
+// failures show which message was emitted instead.
+func WaitForEventMessage(
+	ctx context.Context,
+	f *framework.Framework,
+	namespace, kind, name, reason string,
+	substrings []string,
+	timeout time.Duration,
+) (string, error) {
+	framework.Logf("Waiting up to %v for %s event on %s %s/%s containing %q", timeout, reason, kind, namespace, name, substrings)
+	var message string
+	err := wait.PollUntilContextTimeout(ctx, 5*time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+		events, err := f.ClientSet.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
+			FieldSelector: fmt.Sprintf("involvedObject.kind=%s,involvedObject.name=%s,reason=%s", kind, name, reason),
+		})
+		if err != nil {
+			return false, err
+		}
+		for _, ev := range events.Items {
+			if containsAll(ev.Message, substrings) {
+				message = ev.Message
+				return true, nil
+			}
+			framework.Logf("Ignoring %s event on %s %s/%s: %s", reason, kind, namespace, name, ev.Message)
+		}
+		return false, nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("no %s event on %s %s/%s containing %q: %w", reason, kind, namespace, name, substrings, err)
+	}
+	framework.Logf("Found expected %s event: %s", reason, message)
+	return message, nil
+}
+
+// containsAll returns whether `s` contains all of `substrings`
+func containsAll(s string, substrings []string) bool {
+	for _, substring := range substrings {
+		if !strings.Contains(s, substring) {
+			return false
+		}
+	}
+	return true
+}
+
+// CreateSecretWithData creates an opaque Secret with the given name, namespace and data
+func CreateSecretWithData(ctx context.Context, f *framework.Framework, secretName, namespace string, data map[string]string) (*v1.Secret, error) {
+	return f.ClientSet.CoreV1().Secrets(namespace).Create(ctx, &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretName,
+			Namespace: namespace,
+		},
+		Type:       v1.SecretTypeOpaque,
+		StringData: data,
+	}, metav1.CreateOptions{})
+}
+
 // WaitForPVToBeDeleted waits for a PersistentVolume to be deleted
 func WaitForPVToBeDeleted(ctx context.Context, f *framework.Framework, pvName string, timeout time.Duration) error {
 	return WaitForPVToBeDeletedWithInterval(ctx, f, pvName, timeout, 5*time.Second)
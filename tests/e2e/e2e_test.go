@@ -30,6 +30,15 @@ func init() {
 	flag.StringVar(&SecretAccessKey, "secret-access-key", "", "S3 secret access key (or use ACCOUNT1_SECRET_KEY env var)")
 	flag.StringVar(&S3EndpointUrl, "s3-endpoint-url", "", "S3 endpoint URL, e.g. https://s3.example.com:8000")
 	flag.BoolVar(&Performance, "performance", false, "run performance tests")
+	flag.BoolVar(&Scale, "scale", false, "run scalability tests")
+	flag.IntVar(&ScaleConfig.Pods, "scale-pods", customsuites.DefaultScalePods, "number of pods created concurrently by scalability tests")
+	flag.IntVar(&ScaleConfig.Volumes, "scale-volumes", customsuites.DefaultScaleVolumes, "number of volumes mounted by the pods of scalability tests")
+	flag.StringVar(&ScaleConfig.OutputPath, "scale-output", customsuites.DefaultScaleOutputPath, "path of the JSON results of scalability tests")
+	flag.BoolVar(&Chaos, "chaos", false, "run chaos tests")
+	flag.DurationVar(&ChaosConfig.Duration, "chaos-duration", customsuites.DefaultChaosDuration, "how long chaos tests delete Mountpoint Pods for")
+	flag.DurationVar(&ChaosConfig.KillInterval, "chaos-kill-interval", customsuites.DefaultChaosKillInterval, "average interval between two deletions of Mountpoint Pods by chaos tests")
+	flag.IntVar(&ChaosConfig.Workloads, "chaos-workloads", customsuites.DefaultChaosWorkloads, "number of pods performing I/O during chaos tests")
+	flag.Int64Var(&ChaosConfig.Seed, "chaos-seed", 0, "seed of the random choices of chaos tests, 0 for a random seed")
 	flag.Parse()
 
 	// Try to get configuration from environment variables if not provided via flags
@@ -108,16 +117,24 @@ var CSITestSuites = []func() framework.TestSuite{
 	customsuites.InitS3AdvancedPatternsTestSuite,
 	customsuites.InitS3DynamicProvisioningMountOptionsTestSuite,
 	customsuites.InitS3DynamicProvisioningTemplatingTestSuite,
+	customsuites.InitS3DynamicProvisioningTemplatingFailuresTestSuite,
 	customsuites.InitS3MounterPodTestSuite,
 }
 
 // CSI test suite registration and execution.
 // This registers the CSI driver with the Kubernetes E2E framework and defines which test suites to run.
-// In performance mode, only performance tests are executed.
+// In performance mode, only performance tests are executed, in scale mode only scalability tests, and in chaos mode
+// only chaos tests.
 var _ = utils.SIGDescribe("CSI Volumes", func() {
 	if Performance {
 		CSITestSuites = []func() framework.TestSuite{customsuites.InitS3PerformanceTestSuite}
 	}
+	if Scale {
+		CSITestSuites = []func() framework.TestSuite{customsuites.InitS3ScaleTestSuite(ScaleConfig)}
+	}
+	if Chaos {
+		CSITestSuites = []func() framework.TestSuite{customsuites.InitS3ChaosTestSuite(ChaosConfig)}
+	}
 	curDriver := initS3Driver()
 
 	args := framework.GetDriverNameWithFeatureTags(curDriver)
@@ -26,6 +26,10 @@ var (
 	SecretAccessKey string
 	S3EndpointUrl   string
 	Performance     bool
+	Scale           bool
+	ScaleConfig     customsuites.ScaleConfig
+	Chaos           bool
+	ChaosConfig     customsuites.ChaosConfig
 )
 
 type s3Driver struct {
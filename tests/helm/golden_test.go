@@ -0,0 +1,425 @@
+// Package helm tests the manifests rendered by the Helm chart of the driver.
+//
+// The chart is rendered with each values file of `testdata/values`, and the objects deploying the driver are compared
+// with the golden files of `testdata/golden`. Run `make test-helm-update` to update golden files after a change of the
+// chart. Rendered objects are also validated against the code of the driver, to catch drifts like renamed flags or
+// environment variables before installing the chart.
+package helm
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	crdv2 "github.com/scality/mountpoint-s3-csi-driver/pkg/api/v2"
+	"github.com/scality/mountpoint-s3-csi-driver/pkg/constants"
+)
+
+var update = flag.Bool("update", false, "Update golden files with the rendered manifests")
+
+const (
+	repoRoot       = "../.."
+	chartDir       = repoRoot + "/charts/scality-mountpoint-s3-csi-driver"
+	releaseName    = "s3-csi"
+	releaseNS      = "kube-system"
+	kubeVersion    = "v1.33.0"
+	valuesDir      = "testdata/values"
+	goldenDir      = "testdata/golden"
+	driverImageRef = "ghcr.io/scality/mountpoint-s3-csi-driver:"
+)
+
+// goldenKinds are the kinds of the objects compared with golden files.
+var goldenKinds = []string{"CSIDriver", "DaemonSet", "Deployment"}
+
+// generatedValues match values generated at each rendering, like checksums of generated certificates, which are
+// replaced by `<generated>` in golden files.
+var generatedValues = regexp.MustCompile(`(checksum/tls: ).*`)
+
+// externalEnv are environment variables set by the chart on driver containers which are not read by the driver
+// code, but by the libraries it uses.
+var externalEnv = map[string]string{
+	"AWS_ACCESS_KEY_ID":     "AWS SDK",
+	"AWS_SECRET_ACCESS_KEY": "AWS SDK",
+	"AWS_SESSION_TOKEN":     "AWS SDK",
+	"AWS_CA_BUNDLE":         "AWS SDK",
+}
+
+// externalFlags are flags set by the chart on driver containers which are defined by the libraries the driver uses.
+var externalFlags = map[string]string{
+	"v": "klog",
+}
+
+// A renderedObject is an object of the rendered manifests.
+type renderedObject struct {
+	template string
+	manifest string
+	kind     string
+	name     string
+}
+
+func TestChart(t *testing.T) {
+	valuesFiles, err := filepath.Glob(filepath.Join(valuesDir, "*.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(valuesFiles) == 0 {
+		t.Fatalf("No values files found in %s", valuesDir)
+	}
+
+	literals := sourceStringLiterals(t)
+	for _, valuesFile := range valuesFiles {
+		name := strings.TrimSuffix(filepath.Base(valuesFile), ".yaml")
+		t.Run(name, func(t *testing.T) {
+			objects := render(t, valuesFile)
+
+			checkGolden(t, filepath.Join(goldenDir, name+".yaml"), objects)
+			checkCSIDriver(t, objects)
+			checkDriverContainers(t, objects, literals)
+		})
+	}
+}
+
+func TestChartCRDs(t *testing.T) {
+	c, err := loader.Load(chartDir)
+	if err != nil {
+		t.Fatalf("Failed to load chart: %v", err)
+	}
+
+	scheme := runtime.NewScheme()
+	if err := crdv2.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	found := map[schema.GroupVersionKind]bool{}
+	for _, crdObject := range c.CRDObjects() {
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := yaml.UnmarshalStrict(crdObject.File.Data, crd); err != nil {
+			t.Fatalf("Failed to parse CRD %s: %v", crdObject.Filename, err)
+		}
+		for _, version := range crd.Spec.Versions {
+			gvk := schema.GroupVersionKind{Group: crd.Spec.Group, Version: version.Name, Kind: crd.Spec.Names.Kind}
+			if !scheme.Recognizes(gvk) {
+				t.Errorf("CRD %s defines %v, which is not a type of the driver", crdObject.Filename, gvk)
+			}
+			found[gvk] = true
+		}
+	}
+
+	// The scheme also knows the option types of the API machinery, which are not resources
+	pkgPath := reflect.TypeFor[crdv2.MountpointS3PodAttachment]().PkgPath()
+	for kind, typ := range scheme.KnownTypes(crdv2.GroupVersion) {
+		if typ.PkgPath() != pkgPath || strings.HasSuffix(kind, "List") {
+			continue
+		}
+		if !found[crdv2.GroupVersion.WithKind(kind)] {
+			t.Errorf("Type %s of the driver has no CRD in the chart", kind)
+		}
+	}
+}
+
+// render renders the chart with the values of `valuesFile`, and returns the rendered objects.
+func render(t *testing.T, valuesFile string) []renderedObject {
+	t.Helper()
+
+	c, err := loader.Load(chartDir)
+	if err != nil {
+		t.Fatalf("Failed to load chart: %v", err)
+	}
+	values, err := chartutil.ReadValuesFile(valuesFile)
+	if err != nil {
+		t.Fatalf("Failed to read values %s: %v", valuesFile, err)
+	}
+
+	capabilities := chartutil.DefaultCapabilities.Copy()
+	capabilities.KubeVersion = chartutil.KubeVersion{Version: kubeVersion, Major: "1", Minor: "33"}
+	if err := chartutil.ValidateAgainstSchema(c, values); err != nil {
+		t.Fatalf("Values %s are not valid: %v", valuesFile, err)
+	}
+	renderValues, err := chartutil.ToRenderValues(c, values, chartutil.ReleaseOptions{
+		Name:      releaseName,
+		Namespace: releaseNS,
+		IsInstall: true,
+	}, capabilities)
+	if err != nil {
+		t.Fatalf("Failed to compute values of %s: %v", valuesFile, err)
+	}
+
+	manifests, err := engine.Render(c, renderValues)
+	if err != nil {
+		t.Fatalf("Failed to render chart with %s: %v", valuesFile, err)
+	}
+	return parseManifests(t, c, manifests)
+}
+
+// parseManifests splits rendered `manifests` of chart `c` into objects, sorted by template, kind and name.
+func parseManifests(t *testing.T, c *chart.Chart, manifests map[string]string) []renderedObject {
+	t.Helper()
+
+	var objects []renderedObject
+	for template, content := range manifests {
+		if !strings.HasSuffix(template, ".yaml") {
+			continue
+		}
+		for _, manifest := range strings.Split(content, "\n---") {
+			manifest = strings.TrimSpace(manifest)
+			if manifest == "" || manifest == "---" {
+				continue
+			}
+			var meta struct {
+				Kind     string `json:"kind"`
+				Metadata struct {
+					Name string `json:"name"`
+				} `json:"metadata"`
+			}
+			if err := yaml.Unmarshal([]byte(manifest), &meta); err != nil {
+				t.Fatalf("Failed to parse manifest of %s: %v\n%s", template, err, manifest)
+			}
+			if meta.Kind == "" {
+				continue
+			}
+			objects = append(objects, renderedObject{
+				template: strings.TrimPrefix(template, c.Name()+"/"),
+				manifest: manifest,
+				kind:     meta.Kind,
+				name:     meta.Metadata.Name,
+			})
+		}
+	}
+
+	sort.Slice(objects, func(i, j int) bool {
+		a, b := objects[i], objects[j]
+		if a.template != b.template {
+			return a.template < b.template
+		}
+		if a.kind != b.kind {
+			return a.kind < b.kind
+		}
+		return a.name < b.name
+	})
+	return objects
+}
+
+// checkGolden compares objects of [goldenKinds] with the golden file at `path`, or updates it with `-update`.
+func checkGolden(t *testing.T, path string, objects []renderedObject) {
+	t.Helper()
+
+	var b bytes.Buffer
+	for _, object := range objects {
+		if !slices.Contains(goldenKinds, object.kind) {
+			continue
+		}
+		fmt.Fprintf(&b, "---\n# Source: %s\n%s\n", object.template, object.manifest)
+	}
+	got := generatedValues.ReplaceAllString(b.String(), "${1}<generated>")
+
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("Failed to update golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read golden file, run `make test-helm-update` to create it: %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("Rendered manifests differ from %s, run `make test-helm-update` if the change is expected:\n%s", path, firstDifference(string(want), got))
+	}
+}
+
+// firstDifference describes the first line differing between `want` and `got`.
+func firstDifference(want, got string) string {
+	wantLines, gotLines := strings.Split(want, "\n"), strings.Split(got, "\n")
+	for i := 0; i < max(len(wantLines), len(gotLines)); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			return fmt.Sprintf("line %d:\n- %s\n+ %s", i+1, w, g)
+		}
+	}
+	return ""
+}
+
+// checkCSIDriver checks the CSIDriver object registers the driver with the name the node plugin serves.
+func checkCSIDriver(t *testing.T, objects []renderedObject) {
+	t.Helper()
+
+	var drivers []storagev1.CSIDriver
+	for _, object := range objectsOfKind(objects, "CSIDriver") {
+		driver := storagev1.CSIDriver{}
+		decode(t, object, &driver)
+		drivers = append(drivers, driver)
+	}
+	if len(drivers) != 1 {
+		t.Fatalf("Expected a single CSIDriver, got %d", len(drivers))
+	}
+
+	driver := drivers[0]
+	if driverName := nodePluginDriverName(t, objects); driver.Name != driverName {
+		t.Errorf("Expected CSIDriver %q, got %q", driverName, driver.Name)
+	}
+	if driver.Spec.AttachRequired == nil || *driver.Spec.AttachRequired {
+		t.Errorf("Expected CSIDriver not to require attachments, the driver has no ControllerPublishVolume")
+	}
+	if driver.Spec.PodInfoOnMount == nil || !*driver.Spec.PodInfoOnMount {
+		t.Errorf("Expected CSIDriver with podInfoOnMount, the node plugin needs Pod information to find Mountpoint Pods")
+	}
+}
+
+// nodePluginDriverName returns the driver name the node plugin serves, set by `CSI_DRIVER_NAME` or the default one.
+func nodePluginDriverName(t *testing.T, objects []renderedObject) string {
+	t.Helper()
+
+	for _, object := range objectsOfKind(objects, "DaemonSet") {
+		daemonSet := appsv1.DaemonSet{}
+		decode(t, object, &daemonSet)
+		for _, container := range daemonSet.Spec.Template.Spec.Containers {
+			for _, env := range container.Env {
+				if env.Name == "CSI_DRIVER_NAME" {
+					return env.Value
+				}
+			}
+		}
+	}
+	return constants.DriverName
+}
+
+// checkDriverContainers checks the environment variables and flags of containers running the driver image are read
+// by the driver, i.e. they appear as string literals in its code.
+func checkDriverContainers(t *testing.T, objects []renderedObject, literals map[string]bool) {
+	t.Helper()
+
+	var podSpecs []corev1.PodSpec
+	for _, object := range objectsOfKind(objects, "DaemonSet") {
+		daemonSet := appsv1.DaemonSet{}
+		decode(t, object, &daemonSet)
+		podSpecs = append(podSpecs, daemonSet.Spec.Template.Spec)
+	}
+	for _, object := range objectsOfKind(objects, "Deployment") {
+		deployment := appsv1.Deployment{}
+		decode(t, object, &deployment)
+		podSpecs = append(podSpecs, deployment.Spec.Template.Spec)
+	}
+
+	checked := 0
+	for _, podSpec := range podSpecs {
+		for _, container := range slices.Concat(podSpec.InitContainers, podSpec.Containers) {
+			if !strings.HasPrefix(container.Image, driverImageRef) {
+				continue
+			}
+			checked++
+			args := slices.Concat(container.Command[min(1, len(container.Command)):], container.Args)
+			for _, env := range container.Env {
+				if _, ok := externalEnv[env.Name]; ok {
+					continue
+				}
+				// Variables referenced in arguments are expanded by kubelet
+				if slices.ContainsFunc(args, func(arg string) bool { return strings.Contains(arg, "$("+env.Name+")") }) {
+					continue
+				}
+				if !literals[env.Name] {
+					t.Errorf("Container %s sets environment variable %s, which is not read by the driver", container.Name, env.Name)
+				}
+			}
+			for _, arg := range args {
+				name, ok := flagName(arg)
+				if _, external := externalFlags[name]; ok && !external && !literals[name] {
+					t.Errorf("Container %s sets flag --%s, which is not defined by the driver", container.Name, name)
+				}
+			}
+		}
+	}
+	if checked == 0 {
+		t.Errorf("No container runs the driver image %s*", driverImageRef)
+	}
+}
+
+// flagName returns the name of flag `arg`, if it is a flag.
+func flagName(arg string) (string, bool) {
+	if !strings.HasPrefix(arg, "-") {
+		return "", false
+	}
+	name := strings.TrimLeft(arg, "-")
+	name, _, _ = strings.Cut(name, "=")
+	return name, name != ""
+}
+
+// sourceStringLiterals returns the string literals of the Go code of the driver, excluding tests.
+func sourceStringLiterals(t *testing.T) map[string]bool {
+	t.Helper()
+
+	literals := map[string]bool{}
+	fset := token.NewFileSet()
+	for _, dir := range []string{"cmd", "pkg"} {
+		err := filepath.WalkDir(filepath.Join(repoRoot, dir), func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+				return err
+			}
+			file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+			if err != nil {
+				return err
+			}
+			ast.Inspect(file, func(n ast.Node) bool {
+				if lit, ok := n.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+					if value, err := strconv.Unquote(lit.Value); err == nil {
+						literals[value] = true
+					}
+				}
+				return true
+			})
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Failed to parse driver code: %v", err)
+		}
+	}
+	return literals
+}
+
+func objectsOfKind(objects []renderedObject, kind string) []renderedObject {
+	var result []renderedObject
+	for _, object := range objects {
+		if object.kind == kind {
+			result = append(result, object)
+		}
+	}
+	return result
+}
+
+func decode(t *testing.T, object renderedObject, into any) {
+	t.Helper()
+	if err := yaml.UnmarshalStrict([]byte(object.manifest), into); err != nil {
+		t.Fatalf("Failed to decode %s %s of %s: %v", object.kind, object.name, object.template, err)
+	}
+}